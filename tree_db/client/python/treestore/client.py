@@ -5,8 +5,9 @@ High-level Python client for TreeStore gRPC service.
 """
 
 import grpc
+import json
 from datetime import datetime
-from typing import List, Dict, Optional, Any
+from typing import List, Dict, Optional, Any, Iterator
 
 from . import treestore_pb2 as pb
 from . import treestore_pb2_grpc as pb_grpc
@@ -84,6 +85,9 @@ class TreeStoreClient:
                 section_path=node.get("section_path", ""),
                 child_ids=node.get("child_ids", []),
                 depth=node.get("depth", 0),
+                language=node.get("language", ""),
+                checksum=node.get("checksum", ""),
+                token_count=node.get("token_count", 0),
             )
             node_msgs.append(node_msg)
 
@@ -93,6 +97,7 @@ class TreeStoreClient:
         return {
             "success": response.success,
             "message": response.message,
+            "references_extracted": response.references_extracted,
         }
 
     def get_document(self, policy_id: str) -> Dict[str, Any]:
@@ -131,6 +136,131 @@ class TreeStoreClient:
             "message": response.message,
         }
 
+    def clone_document(
+        self, src_policy_id: str, dst_policy_id: str, src_version_id: str = ""
+    ) -> Dict[str, Any]:
+        """
+        Copy a policy's node tree into a new policy under new node IDs.
+
+        Args:
+            src_policy_id: Policy to copy
+            dst_policy_id: Policy to create; it must not have nodes yet
+            src_version_id: Optional version the source must be at
+
+        Returns:
+            Dict with root_node_ids and node_id_map (new node ID by source node ID)
+        """
+        request = pb.CloneDocumentRequest(
+            src_policy_id=src_policy_id,
+            src_version_id=src_version_id,
+            dst_policy_id=dst_policy_id,
+        )
+        response = self.stub.CloneDocument(request)
+
+        return {
+            "root_node_ids": list(response.root_node_ids),
+            "node_id_map": dict(response.node_id_map),
+        }
+
+    def recompute_section_paths(self, policy_id: str, dry_run: bool = False) -> Dict[str, Any]:
+        """
+        Renumber a policy's section paths from its tree.
+
+        Args:
+            policy_id: Policy document ID
+            dry_run: Only report mismatches without fixing them
+
+        Returns:
+            Dict with nodes_checked, applied and changes (node_id, stored, expected)
+        """
+        request = pb.RecomputeSectionPathsRequest(policy_id=policy_id, dry_run=dry_run)
+        response = self.stub.RecomputeSectionPaths(request)
+
+        return {
+            "nodes_checked": response.nodes_checked,
+            "applied": response.applied,
+            "changes": [
+                {"node_id": c.node_id, "stored": c.stored, "expected": c.expected}
+                for c in response.changes
+            ],
+        }
+
+    def validate_document(self, policy_id: str) -> Dict[str, Any]:
+        """
+        Check a policy's tree for orphans, cycles, index drift and bad page ranges.
+
+        Args:
+            policy_id: Policy document ID
+
+        Returns:
+            Dict with valid, nodes_checked and issues (kind, node_id, detail)
+        """
+        request = pb.ValidateDocumentRequest(policy_id=policy_id)
+        response = self.stub.ValidateDocument(request)
+
+        return {
+            "valid": response.valid,
+            "nodes_checked": response.nodes_checked,
+            "issues": [
+                {"kind": i.kind, "node_id": i.node_id, "detail": i.detail}
+                for i in response.issues
+            ],
+        }
+
+    def find_duplicate_nodes(self, policy_id: str = "", cross_policy: bool = False) -> List[Dict[str, Any]]:
+        """
+        Find nodes whose text is the same, such as sections copied between policies.
+
+        Args:
+            policy_id: Policy document ID; required unless cross_policy is set
+            cross_policy: Return groups spanning policies instead of groups within policy_id
+
+        Returns:
+            List of groups with checksum and nodes (policy_id, node_id)
+        """
+        request = pb.FindDuplicateNodesRequest(policy_id=policy_id, cross_policy=cross_policy)
+        response = self.stub.FindDuplicateNodes(request)
+
+        return [
+            {
+                "checksum": g.checksum,
+                "nodes": [{"policy_id": n.policy_id, "node_id": n.node_id} for n in g.nodes],
+            }
+            for g in response.groups
+        ]
+
+    def refresh_summaries(
+        self,
+        policy_id: str,
+        node_ids: Optional[List[str]] = None,
+        section_path: str = "",
+        force: bool = False,
+        batch_size: int = 0,
+    ) -> Iterator[Dict[str, Any]]:
+        """
+        Recompute stale node summaries with the server's configured summarizer.
+
+        Args:
+            policy_id: Policy document ID
+            node_ids: Only these nodes (default: every node)
+            section_path: Only this section and those under it
+            force: Refresh current summaries too, not just stale ones
+            batch_size: Nodes per summarizer call (0 uses the server default)
+
+        Returns:
+            Iterator of progress dicts (total, done, updated, skipped), one per batch written
+        """
+        request = pb.RefreshSummariesRequest(
+            policy_id=policy_id,
+            node_ids=node_ids or [],
+            section_path=section_path,
+            force=force,
+            batch_size=batch_size,
+        )
+
+        for p in self.stub.RefreshSummaries(request):
+            yield {"total": p.total, "done": p.done, "updated": p.updated, "skipped": p.skipped}
+
     # ========== Node Operations ==========
 
     def get_node(self, policy_id: str, node_id: str) -> Dict[str, Any]:
@@ -149,13 +279,16 @@ class TreeStoreClient:
 
         return self._pb_node_to_dict(response.node)
 
-    def get_children(self, policy_id: str, parent_id: Optional[str] = None) -> List[Dict[str, Any]]:
+    def get_children(
+        self, policy_id: str, parent_id: Optional[str] = None, fields: Optional[List[str]] = None
+    ) -> List[Dict[str, Any]]:
         """
         Get child nodes of a parent (or root nodes if parent_id is None).
 
         Args:
             policy_id: Policy document ID
             parent_id: Parent node ID (None for root nodes)
+            fields: Node fields to return, e.g. ["title"] (None for all)
 
         Returns:
             List of node dicts
@@ -163,12 +296,15 @@ class TreeStoreClient:
         request = pb.GetChildrenRequest(
             policy_id=policy_id,
             parent_id=parent_id or "",
+            fields=fields or [],
         )
         response = self.stub.GetChildren(request)
 
         return [self._pb_node_to_dict(node) for node in response.children]
 
-    def get_subtree(self, policy_id: str, node_id: str, max_depth: int = 0) -> List[Dict[str, Any]]:
+    def get_subtree(
+        self, policy_id: str, node_id: str, max_depth: int = 0, fields: Optional[List[str]] = None
+    ) -> List[Dict[str, Any]]:
         """
         Get a node and all its descendants.
 
@@ -176,6 +312,7 @@ class TreeStoreClient:
             policy_id: Policy document ID
             node_id: Root node ID for subtree
             max_depth: Maximum depth to traverse (0 = unlimited)
+            fields: Node fields to return, e.g. ["title"] (None for all)
 
         Returns:
             List of node dicts in BFS order
@@ -184,11 +321,43 @@ class TreeStoreClient:
             policy_id=policy_id,
             node_id=node_id,
             max_depth=max_depth,
+            fields=fields or [],
         )
         response = self.stub.GetSubtree(request)
 
         return [self._pb_node_to_dict(node) for node in response.nodes]
 
+    def get_subtree_within_budget(
+        self, policy_id: str, node_id: str, max_tokens: int, max_depth: int = 0
+    ) -> Dict[str, Any]:
+        """
+        Get as much of a subtree as fits in a token budget.
+
+        Levels are kept before detail: nodes fall back from their text to their
+        summary before deeper levels are left out.
+
+        Args:
+            policy_id: Policy document ID
+            node_id: Root node ID for subtree
+            max_tokens: Estimated tokens of the titles, texts and summaries returned
+            max_depth: Maximum depth to traverse (0 = unlimited)
+
+        Returns:
+            Dict with nodes (BFS order), summarized_ids, depth, token_count and truncated
+        """
+        request = pb.GetSubtreeWithinBudgetRequest(
+            policy_id=policy_id, node_id=node_id, max_tokens=max_tokens, max_depth=max_depth
+        )
+        response = self.stub.GetSubtreeWithinBudget(request)
+
+        return {
+            "nodes": [self._pb_node_to_dict(node) for node in response.nodes],
+            "summarized_ids": list(response.summarized_ids),
+            "depth": response.depth,
+            "token_count": response.token_count,
+            "truncated": response.truncated,
+        }
+
     def get_ancestor_path(self, policy_id: str, node_id: str) -> List[Dict[str, Any]]:
         """
         Get the path from root to a node.
@@ -205,9 +374,187 @@ class TreeStoreClient:
 
         return [self._pb_node_to_dict(node) for node in response.ancestors]
 
+    def batch_get_ancestor_paths(
+        self, policy_id: str, node_ids: List[str], fields: Optional[List[str]] = None
+    ) -> List[Optional[List[Dict[str, Any]]]]:
+        """
+        Get the paths from root to several nodes at once, such as search hits.
+
+        Args:
+            policy_id: Policy document ID
+            node_ids: Target node IDs
+            fields: Node fields to return, e.g. ["title"] (None for all)
+
+        Returns:
+            List aligned with node_ids of ancestor node lists from root to
+            target, or None where the node or an ancestor does not exist
+        """
+        request = pb.BatchGetAncestorPathsRequest(policy_id=policy_id, node_ids=node_ids, fields=fields or [])
+        response = self.stub.BatchGetAncestorPaths(request)
+
+        return [
+            [self._pb_node_to_dict(node) for node in path.ancestors] if path.found else None
+            for path in response.paths
+        ]
+
+    def get_siblings(
+        self, policy_id: str, node_id: str, fields: Optional[List[str]] = None
+    ) -> Dict[str, Any]:
+        """
+        Get the children of a node's parent in document order.
+
+        Args:
+            policy_id: Policy document ID
+            node_id: Node whose siblings to get
+            fields: Node fields to return, e.g. ["title"] (None for all)
+
+        Returns:
+            Dict with siblings (node dicts, the node among them) and position,
+            the node's index in siblings
+        """
+        request = pb.GetSiblingsRequest(policy_id=policy_id, node_id=node_id, fields=fields or [])
+        response = self.stub.GetSiblings(request)
+
+        return {
+            "siblings": [self._pb_node_to_dict(node) for node in response.siblings],
+            "position": response.position,
+        }
+
+    def get_next_section(
+        self, policy_id: str, node_id: str, fields: Optional[List[str]] = None
+    ) -> Optional[Dict[str, Any]]:
+        """
+        Get the section after a node in document order, reading depth first.
+
+        Args:
+            policy_id: Policy document ID
+            node_id: Node to step from
+            fields: Node fields to return, e.g. ["title"] (None for all)
+
+        Returns:
+            Node dict, or None at the end of the document
+        """
+        request = pb.AdjacentSectionRequest(policy_id=policy_id, node_id=node_id, fields=fields or [])
+        response = self.stub.GetNextSection(request)
+
+        return self._pb_node_to_dict(response.node) if response.HasField("node") else None
+
+    def get_previous_section(
+        self, policy_id: str, node_id: str, fields: Optional[List[str]] = None
+    ) -> Optional[Dict[str, Any]]:
+        """
+        Get the section before a node in document order.
+
+        Args:
+            policy_id: Policy document ID
+            node_id: Node to step from
+            fields: Node fields to return, e.g. ["title"] (None for all)
+
+        Returns:
+            Node dict, or None at the start of the document
+        """
+        request = pb.AdjacentSectionRequest(policy_id=policy_id, node_id=node_id, fields=fields or [])
+        response = self.stub.GetPreviousSection(request)
+
+        return self._pb_node_to_dict(response.node) if response.HasField("node") else None
+
+    def get_reading_order(
+        self, policy_id: str, fields: Optional[List[str]] = None
+    ) -> Iterator[Dict[str, Any]]:
+        """
+        Stream every node of a policy depth first in document order.
+
+        Args:
+            policy_id: Policy document ID
+            fields: Node fields to return, e.g. ["title"] (None for all)
+
+        Returns:
+            Iterator of node dicts: each section, then its subsections,
+            then the section after it
+        """
+        request = pb.GetReadingOrderRequest(policy_id=policy_id, fields=fields or [])
+
+        for node in self.stub.GetReadingOrder(request):
+            yield self._pb_node_to_dict(node)
+
+    def get_context_window(self, policy_id: str, node_id: str, token_budget: int = 0) -> Dict[str, Any]:
+        """
+        Get a node with its ancestor titles, sibling and children summaries.
+
+        Args:
+            policy_id: Policy document ID
+            node_id: Node ID
+            token_budget: Maximum estimated tokens (0 for unlimited)
+
+        Returns:
+            Context window dict with node, ancestors, siblings, children and token count
+        """
+        request = pb.GetContextWindowRequest(
+            policy_id=policy_id, node_id=node_id, token_budget=token_budget
+        )
+        response = self.stub.GetContextWindow(request)
+
+        def entries(items):
+            return [
+                {"node_id": e.node_id, "title": e.title, "summary": e.summary}
+                for e in items
+            ]
+
+        return {
+            "node": self._pb_node_to_dict(response.node),
+            "ancestors": entries(response.ancestors),
+            "siblings": entries(response.siblings),
+            "children": entries(response.children),
+            "token_count": response.token_count,
+            "truncated": response.truncated,
+        }
+
+    def export_graph(
+        self,
+        policy_id: str,
+        graph: str = "tree",
+        format: str = "dot",
+        root_node_id: str = "",
+        max_depth: int = 0,
+        label: str = "title",
+        max_label_length: int = 0,
+    ) -> str:
+        """
+        Draw a policy's node tree or cross-reference graph as a diagram.
+
+        Args:
+            policy_id: Policy document ID
+            graph: "tree" for parent-child edges, "references" for cross-references
+            format: "dot" (Graphviz) or "mermaid"
+            root_node_id: Tree to draw below this node, or references to start from it
+            max_depth: Tree levels or reference hops to include (0 for unlimited)
+            label: Node labels: "title", "section_path" or "node_id"
+            max_label_length: Cut longer labels (0 for no limit)
+
+        Returns:
+            DOT or Mermaid source
+        """
+        request = pb.ExportGraphRequest(
+            policy_id=policy_id,
+            graph=graph,
+            format=format,
+            root_node_id=root_node_id,
+            max_depth=max_depth,
+            label=label,
+            max_label_length=max_label_length,
+        )
+        return self.stub.ExportGraph(request).content
+
     # ========== Search Operations ==========
 
-    def search(self, policy_id: str, query: str, limit: int = 10) -> List[Dict[str, Any]]:
+    def search(
+        self,
+        policy_id: str,
+        query: str,
+        limit: int = 10,
+        filter: Optional[Dict[str, Any]] = None,
+        snippet_length: int = 0,
+    ) -> List[Dict[str, Any]]:
         """
         Full-text search within a policy document.
 
@@ -215,20 +562,21 @@ class TreeStoreClient:
             policy_id: Policy document ID
             query: Search query string
             limit: Maximum results to return
+            filter: Optional filters (page_from, page_to, max_depth,
+                section_path_prefix, metadata)
+            snippet_length: Snippet size in bytes (0 for the server default)
 
         Returns:
-            List of search results with node and score
+            List of search results with node, score, snippet and highlights
         """
-        request = pb.SearchRequest(policy_id=policy_id, query=query, limit=limit)
+        request = pb.SearchRequest(
+            policy_id=policy_id, query=query, limit=limit, snippet_length=snippet_length
+        )
+        if filter:
+            request.filter.CopyFrom(pb.SearchFilter(**filter))
         response = self.stub.SearchByKeyword(request)
 
-        return [
-            {
-                "node": self._pb_node_to_dict(result.node),
-                "score": result.score,
-            }
-            for result in response.results
-        ]
+        return [self._pb_search_result_to_dict(result) for result in response.results]
 
     def get_nodes_by_page(self, policy_id: str, page_number: int) -> List[Dict[str, Any]]:
         """
@@ -246,6 +594,135 @@ class TreeStoreClient:
 
         return [self._pb_node_to_dict(node) for node in response.nodes]
 
+    def global_search(
+        self,
+        query: str,
+        per_policy_limit: int = 10,
+        max_policies: int = 0,
+        snippet_length: int = 0,
+        collection: str = "",
+    ) -> List[Dict[str, Any]]:
+        """
+        Keyword search across every policy, grouped by policy.
+
+        Args:
+            query: Search query string
+            per_policy_limit: Maximum results to return per policy
+            max_policies: Maximum policies to return (0 for all)
+            snippet_length: Snippet size in bytes (0 for the server default)
+            collection: Search only this collection's member policies (optional)
+
+        Returns:
+            List of per-policy groups ordered by best score
+        """
+        request = pb.GlobalSearchRequest(
+            query=query,
+            per_policy_limit=per_policy_limit,
+            max_policies=max_policies,
+            snippet_length=snippet_length,
+            collection=collection,
+        )
+        response = self.stub.GlobalSearch(request)
+
+        return [
+            {
+                "policy_id": group.policy_id,
+                "total_hits": group.total_hits,
+                "results": [self._pb_search_result_to_dict(result) for result in group.results],
+            }
+            for group in response.policies
+        ]
+
+    def retrieve(
+        self, query: str, top_k: int = 0, filter: Optional[Dict[str, Any]] = None
+    ) -> List[Dict[str, Any]]:
+        """
+        Retrieve nodes as LangChain-style documents.
+
+        Args:
+            query: Search query string
+            top_k: Maximum documents to return (0 for the server default of 4)
+            filter: Optional filters (policy_id or collection, section_path_prefix,
+                page_from, page_to, max_depth; other keys match node metadata)
+
+        Returns:
+            List of documents with id, page_content, metadata and score, best first
+        """
+        request = pb.RetrieveRequest(
+            query=query,
+            top_k=top_k,
+            filter={
+                key: str(value).lower() if isinstance(value, bool) else str(value)
+                for key, value in (filter or {}).items()
+            },
+        )
+        response = self.stub.Retrieve(request)
+
+        return [
+            {
+                "id": doc.id,
+                "page_content": doc.page_content,
+                "metadata": dict(doc.metadata),
+                "score": doc.score,
+            }
+            for doc in response.documents
+        ]
+
+    def join_nodes(
+        self,
+        policy_id: str,
+        metadata: Optional[Dict[str, str]] = None,
+        references_to: str = "",
+        referenced_by: str = "",
+        reference_type: str = "",
+        limit: int = 100,
+        offset: int = 0,
+    ) -> List[Dict[str, Any]]:
+        """
+        Find nodes of a policy by node metadata and cross references in one call.
+
+        Args:
+            policy_id: Policy whose nodes are returned
+            metadata: Required node metadata key/value pairs
+            references_to: Nodes must reference a node of this policy
+            referenced_by: Nodes must be referenced from a node of this policy
+            reference_type: Restrict reference conditions to one type (e.g. "cites")
+            limit: Maximum results
+            offset: Results to skip
+
+        Returns:
+            List of dicts with "node", "metadata" and "references", ordered by page
+        """
+        request = pb.JoinNodesRequest(
+            policy_id=policy_id,
+            metadata=metadata or {},
+            references_to=references_to,
+            referenced_by=referenced_by,
+            reference_type=reference_type,
+            limit=limit,
+            offset=offset,
+        )
+        response = self.stub.JoinNodes(request)
+
+        return [
+            {
+                "node": self._pb_node_to_dict(result.node),
+                "metadata": dict(result.metadata),
+                "references": [
+                    {
+                        "source_policy_id": ref.source_policy_id,
+                        "source_node_id": ref.source_node_id,
+                        "target_policy_id": ref.target_policy_id,
+                        "target_node_id": ref.target_node_id,
+                        "reference_type": ref.reference_type,
+                        "context": ref.context,
+                    }
+                    for ref in result.references
+                ],
+            }
+            for result in response.results
+        ]
+
     # ========== Version Operations ==========
 
     def get_version_as_of(self, policy_id: str, as_of_time: datetime) -> Dict[str, Any]:
@@ -268,6 +745,29 @@ class TreeStoreClient:
 
         return self._pb_version_to_dict(response)
 
+    def batch_get_versions_as_of(self, policy_ids: List[str], as_of_time: datetime) -> Dict[str, Any]:
+        """
+        Resolve the versions of many policies active at one point in time.
+
+        Args:
+            policy_ids: Policy document IDs
+            as_of_time: Point in time
+
+        Returns:
+            Dict with "versions" (policy ID to version dict) and "missing_policy_ids"
+        """
+        from google.protobuf.timestamp_pb2 import Timestamp
+        ts = Timestamp()
+        ts.FromDatetime(as_of_time)
+
+        request = pb.BatchGetVersionsAsOfRequest(policy_ids=policy_ids, as_of_time=ts)
+        response = self.stub.BatchGetVersionsAsOf(request)
+
+        return {
+            "versions": {pid: self._pb_version_to_dict(v) for pid, v in response.versions.items()},
+            "missing_policy_ids": list(response.missing_policy_ids),
+        }
+
     def list_versions(self, policy_id: str, limit: int = 100) -> List[Dict[str, Any]]:
         """
         List all versions of a policy document.
@@ -284,6 +784,90 @@ class TreeStoreClient:
 
         return [self._pb_version_to_dict(v) for v in response.versions]
 
+    def delete_version(self, policy_id: str, version_id: str) -> bool:
+        """
+        Delete a policy version.
+
+        Args:
+            policy_id: Policy document ID
+            version_id: Version to delete
+
+        Returns:
+            True on success
+        """
+        request = pb.DeleteVersionRequest(policy_id=policy_id, version_id=version_id)
+        response = self.stub.DeleteVersion(request)
+
+        return response.success
+
+    def prune_versions(
+        self,
+        policy_id: str,
+        keep_last: int = 0,
+        older_than: Optional[datetime] = None,
+        protect_tags: Optional[List[str]] = None,
+        dry_run: bool = False,
+    ) -> List[str]:
+        """
+        Delete obsolete versions of a policy. The latest version is always kept.
+
+        Args:
+            policy_id: Policy document ID
+            keep_last: Number of newest versions to keep
+            older_than: Only prune versions created before this time
+            protect_tags: Keep versions carrying any of these tags
+            dry_run: Report what would be pruned without deleting
+
+        Returns:
+            IDs of pruned versions, oldest first
+        """
+        request = pb.PruneVersionsRequest(
+            policy_id=policy_id,
+            keep_last=keep_last,
+            protect_tags=protect_tags or [],
+            dry_run=dry_run,
+        )
+        if older_than is not None:
+            request.older_than.FromDatetime(older_than)
+        response = self.stub.PruneVersions(request)
+
+        return list(response.pruned_version_ids)
+
+    def tag_version(self, policy_id: str, version_id: str, tag: str, unique: bool = False) -> bool:
+        """
+        Add a tag to a version.
+
+        Args:
+            policy_id: Policy document ID
+            version_id: Version to tag
+            tag: Tag name (e.g. "approved")
+            unique: Move the tag, removing it from every other version of the policy
+
+        Returns:
+            True on success
+        """
+        request = pb.TagVersionRequest(policy_id=policy_id, version_id=version_id, tag=tag, unique=unique)
+        response = self.stub.TagVersion(request)
+
+        return response.success
+
+    def untag_version(self, policy_id: str, version_id: str, tag: str) -> bool:
+        """
+        Remove a tag from a version.
+
+        Args:
+            policy_id: Policy document ID
+            version_id: Version to untag
+            tag: Tag name
+
+        Returns:
+            True on success
+        """
+        request = pb.UntagVersionRequest(policy_id=policy_id, version_id=version_id, tag=tag)
+        response = self.stub.UntagVersion(request)
+
+        return response.success
+
     # ========== Metadata Operations ==========
 
     def store_tool_result(self, result: Dict[str, Any]) -> Dict[str, Any]:
@@ -335,11 +919,704 @@ class TreeStoreClient:
 
         return [self._pb_tool_result_to_dict(r) for r in response.results]
 
-    # ========== Health & Status ==========
-
-    def health(self) -> Dict[str, Any]:
+    def store_trajectory(self, trajectory: Dict[str, Any]) -> Dict[str, Any]:
         """
-        Check server health.
+        Store an agent trajectory with its steps.
+
+        Args:
+            trajectory: Dict with keys: trajectory_id, case_id, steps (list of dicts with
+                step_number, tool_name, action, observation, thought), started_at, completed_at
+
+        Returns:
+            Response dict with success status
+        """
+        traj = pb.Trajectory(trajectory_id=trajectory["trajectory_id"], case_id=trajectory.get("case_id", ""))
+        for step in trajectory.get("steps", []):
+            traj.steps.add(
+                step_number=step.get("step_number", 0),
+                tool_name=step.get("tool_name", ""),
+                action=step.get("action", ""),
+                observation=step.get("observation", ""),
+                thought=step.get("thought", ""),
+            )
+        if trajectory.get("started_at"):
+            traj.started_at.FromDatetime(trajectory["started_at"])
+        if trajectory.get("completed_at"):
+            traj.completed_at.FromDatetime(trajectory["completed_at"])
+
+        response = self.stub.StoreTrajectory(pb.StoreTrajectoryRequest(trajectory=traj))
+
+        return {
+            "success": response.success,
+            "message": response.message,
+        }
+
+    def replay_trajectory(
+        self,
+        trajectory_id: str,
+        policy_id: str = "",
+        version_id: str = "",
+        as_of_time: Optional[datetime] = None,
+    ) -> Dict[str, Any]:
+        """
+        Re-run the retrieval steps of a trajectory and compare the nodes returned.
+
+        Args:
+            trajectory_id: Trajectory ID
+            policy_id: Policy to redirect to version_id (optional)
+            version_id: Version of policy_id to replay against (optional)
+            as_of_time: Replay every policy as of this time instead (optional)
+
+        Returns:
+            Dict with "steps" (per-step outcome and node differences), the outcome
+            counts, and "documents" (document replayed for each redirected policy)
+        """
+        request = pb.ReplayTrajectoryRequest(trajectory_id=trajectory_id, policy_id=policy_id, version_id=version_id)
+        if as_of_time is not None:
+            request.as_of_time.FromDatetime(as_of_time)
+        response = self.stub.ReplayTrajectory(request)
+
+        return {
+            "steps": [
+                {
+                    "step_number": s.step_number,
+                    "tool_name": s.tool_name,
+                    "outcome": s.outcome,
+                    "reason": s.reason,
+                    "missing_node_ids": list(s.missing_node_ids),
+                    "added_node_ids": list(s.added_node_ids),
+                    "changed_node_ids": list(s.changed_node_ids),
+                    "observation": s.observation,
+                }
+                for s in response.steps
+            ],
+            "matched": response.matched,
+            "diverged": response.diverged,
+            "failed": response.failed,
+            "skipped": response.skipped,
+            "documents": dict(response.documents),
+        }
+
+    def batch_set_metadata(
+        self, entity_type: str, entity_id: str, attributes: Dict[str, str], value_type: str = ""
+    ) -> Dict[str, Any]:
+        """
+        Set several metadata attributes on one entity in a single transaction.
+
+        Args:
+            entity_type: Entity type (e.g. "document", "node")
+            entity_id: Entity ID
+            attributes: Key/value pairs to set
+            value_type: Type hint applied to every attribute (optional)
+
+        Returns:
+            Dict with success and number of attributes written
+        """
+        request = pb.BatchSetMetadataRequest(
+            entity_type=entity_type,
+            entity_id=entity_id,
+            attributes=attributes,
+            value_type=value_type,
+        )
+        response = self.stub.BatchSetMetadata(request)
+
+        return {"success": response.success, "count": response.count}
+
+    # ========== Collection Operations ==========
+
+    def put_collection(
+        self,
+        name: str,
+        policy_ids: List[str],
+        description: str = "",
+        metadata: Optional[Dict[str, str]] = None,
+    ) -> Dict[str, Any]:
+        """
+        Create a collection of policies, or replace an existing one.
+
+        Args:
+            name: Collection name (e.g. "cardiology-2024")
+            policy_ids: Member policy IDs
+            description: Free-form description (optional)
+            metadata: Free-form attributes (optional)
+
+        Returns:
+            The collection as stored
+        """
+        request = pb.PutCollectionRequest(
+            collection=pb.Collection(
+                name=name,
+                description=description,
+                policy_ids=policy_ids,
+                metadata=metadata or {},
+            )
+        )
+        response = self.stub.PutCollection(request)
+
+        return self._pb_collection_to_dict(response.collection)
+
+    def get_collection(self, name: str) -> Dict[str, Any]:
+        """
+        Get a collection with its members.
+
+        Args:
+            name: Collection name
+
+        Returns:
+            Collection dict
+        """
+        response = self.stub.GetCollection(pb.GetCollectionRequest(name=name))
+
+        return self._pb_collection_to_dict(response.collection)
+
+    def list_collections(self, policy_id: str = "") -> List[Dict[str, Any]]:
+        """
+        List collections by name.
+
+        Args:
+            policy_id: Only collections containing this policy (optional)
+
+        Returns:
+            List of collection dicts
+        """
+        response = self.stub.ListCollections(pb.ListCollectionsRequest(policy_id=policy_id))
+
+        return [self._pb_collection_to_dict(c) for c in response.collections]
+
+    def update_collection_members(
+        self, name: str, add: Optional[List[str]] = None, remove: Optional[List[str]] = None
+    ) -> Dict[str, Any]:
+        """
+        Add, then remove, members of an existing collection.
+
+        Args:
+            name: Collection name
+            add: Policy IDs to add
+            remove: Policy IDs to remove
+
+        Returns:
+            The collection as stored
+        """
+        request = pb.UpdateCollectionMembersRequest(name=name, add=add or [], remove=remove or [])
+        response = self.stub.UpdateCollectionMembers(request)
+
+        return self._pb_collection_to_dict(response.collection)
+
+    def delete_collection(self, name: str) -> bool:
+        """
+        Delete a collection; its member policies are untouched.
+
+        Args:
+            name: Collection name
+
+        Returns:
+            True if deleted
+        """
+        response = self.stub.DeleteCollection(pb.DeleteCollectionRequest(name=name))
+
+        return response.success
+
+    # ========== Prompt Experiment Operations ==========
+
+    def store_prompt_experiment(
+        self,
+        experiment_id: str,
+        variants: List[Dict[str, Any]],
+        name: str = "",
+        success_outcomes: Optional[List[str]] = None,
+    ) -> Dict[str, Any]:
+        """
+        Create or replace a prompt A/B experiment.
+
+        Args:
+            experiment_id: Experiment ID
+            variants: Variant dicts with keys: name, prompt_id, weight (traffic buckets)
+            name: Experiment name
+            success_outcomes: Outcome labels counted as successes (default ["success"])
+
+        Returns:
+            Response dict with success status
+        """
+        experiment = pb.PromptExperiment(
+            experiment_id=experiment_id,
+            name=name,
+            variants=[pb.PromptVariant(name=v["name"], prompt_id=v["prompt_id"], weight=v.get("weight", 1)) for v in variants],
+            success_outcomes=success_outcomes or [],
+        )
+        response = self.stub.StorePromptExperiment(pb.StorePromptExperimentRequest(experiment=experiment))
+
+        return {
+            "success": response.success,
+            "message": response.message,
+        }
+
+    def assign_prompt_variant(self, experiment_id: str, unit: str) -> Dict[str, Any]:
+        """
+        Get the variant serving a unit of traffic; the same unit keeps its variant.
+
+        Args:
+            experiment_id: Experiment ID
+            unit: User, case or session ID
+
+        Returns:
+            Variant dict with name, prompt_id and weight
+        """
+        response = self.stub.AssignPromptVariant(pb.AssignPromptVariantRequest(experiment_id=experiment_id, unit=unit))
+
+        return {
+            "name": response.variant.name,
+            "prompt_id": response.variant.prompt_id,
+            "weight": response.variant.weight,
+        }
+
+    def record_prompt_usage(
+        self,
+        usage_id: str,
+        prompt_id: str,
+        experiment_id: str = "",
+        variant: str = "",
+        outcome: str = "",
+        used_at: Optional[datetime] = None,
+    ) -> Dict[str, Any]:
+        """
+        Record a use of a prompt, counted in an experiment when one is given.
+
+        Args:
+            usage_id: Usage ID
+            prompt_id: Prompt template used
+            experiment_id: Experiment the usage belongs to (optional)
+            variant: Variant served, as returned by assign_prompt_variant
+            outcome: Outcome label, if already known
+            used_at: When the prompt was used (default now)
+
+        Returns:
+            Response dict with success status
+        """
+        usage = pb.PromptUsage(
+            usage_id=usage_id,
+            prompt_id=prompt_id,
+            experiment_id=experiment_id,
+            variant=variant,
+            outcome=outcome,
+        )
+        usage.used_at.FromDatetime(used_at or datetime.utcnow())
+        response = self.stub.RecordPromptUsage(pb.RecordPromptUsageRequest(usage=usage))
+
+        return {
+            "success": response.success,
+            "message": response.message,
+        }
+
+    def label_prompt_usage(self, experiment_id: str, usage_id: str, outcome: str) -> bool:
+        """
+        Set the outcome of a recorded experiment usage.
+
+        Args:
+            experiment_id: Experiment ID
+            usage_id: Usage ID
+            outcome: Outcome label
+
+        Returns:
+            True if labeled
+        """
+        request = pb.LabelPromptUsageRequest(experiment_id=experiment_id, usage_id=usage_id, outcome=outcome)
+        response = self.stub.LabelPromptUsage(request)
+
+        return response.success
+
+    def compare_prompt_variants(
+        self,
+        experiment_id: str,
+        since: Optional[datetime] = None,
+        until: Optional[datetime] = None,
+    ) -> List[Dict[str, Any]]:
+        """
+        Aggregate an experiment's usages by variant.
+
+        Args:
+            experiment_id: Experiment ID
+            since: Only usages at or after this time (optional)
+            until: Only usages before this time (optional)
+
+        Returns:
+            List of dicts with variant, prompt_id, usages, labeled, successes,
+            success_rate and outcomes (usages by label)
+        """
+        request = pb.ComparePromptVariantsRequest(experiment_id=experiment_id)
+        if since is not None:
+            request.since.FromDatetime(since)
+        if until is not None:
+            request.until.FromDatetime(until)
+        response = self.stub.ComparePromptVariants(request)
+
+        return [
+            {
+                "variant": v.variant,
+                "prompt_id": v.prompt_id,
+                "usages": v.usages,
+                "labeled": v.labeled,
+                "successes": v.successes,
+                "success_rate": v.success_rate,
+                "outcomes": dict(v.outcomes),
+            }
+            for v in response.variants
+        ]
+
+    # ========== Conversation Operations ==========
+
+    def get_messages_page(
+        self,
+        conversation_id: str,
+        after_message_id: str = "",
+        after_timestamp: Optional[datetime] = None,
+        limit: int = 50,
+    ) -> Dict[str, Any]:
+        """
+        Get one page of conversation messages, oldest first.
+
+        Args:
+            conversation_id: Conversation ID
+            after_message_id: Cursor returned by the previous page
+            after_timestamp: Only messages after this time (if no cursor)
+            limit: Maximum messages per page
+
+        Returns:
+            Dict with messages, has_more and next_cursor
+        """
+        request = pb.GetMessagesPageRequest(
+            conversation_id=conversation_id,
+            after_message_id=after_message_id,
+            limit=limit,
+        )
+        if after_timestamp is not None:
+            request.after_timestamp.FromDatetime(after_timestamp)
+        response = self.stub.GetMessagesPage(request)
+
+        return {
+            "messages": [self._pb_message_to_dict(m) for m in response.messages],
+            "has_more": response.has_more,
+            "next_cursor": response.next_cursor,
+        }
+
+    def get_recent_messages(self, conversation_id: str, count: int) -> List[Dict[str, Any]]:
+        """
+        Get the last messages of a conversation, oldest first.
+
+        Args:
+            conversation_id: Conversation ID
+            count: Number of messages
+
+        Returns:
+            List of message dicts
+        """
+        request = pb.GetRecentMessagesRequest(conversation_id=conversation_id, count=count)
+        response = self.stub.GetRecentMessages(request)
+
+        return [self._pb_message_to_dict(m) for m in response.messages]
+
+    def search_conversations(
+        self, query: str, user_id: str = "", limit: int = 20
+    ) -> List[Dict[str, Any]]:
+        """
+        Search conversations by message content.
+
+        Args:
+            query: Search terms
+            user_id: Restrict to one user's conversations (empty for all users)
+            limit: Maximum conversations to return
+
+        Returns:
+            List of dicts with conversation, score and matching messages
+        """
+        request = pb.SearchConversationsRequest(user_id=user_id, query=query, limit=limit)
+        response = self.stub.SearchConversations(request)
+
+        return [
+            {
+                "conversation": self._pb_conversation_to_dict(r.conversation),
+                "score": r.score,
+                "matches": [self._pb_message_to_dict(m) for m in r.matches],
+            }
+            for r in response.results
+        ]
+
+    def export_conversation(
+        self, conversation_id: str, format: str = "openai", omit_metadata: bool = False
+    ) -> Dict[str, Any]:
+        """
+        Export a conversation as a chat API message array.
+
+        Args:
+            conversation_id: Conversation ID
+            format: "openai" (system messages inline) or "anthropic" (system messages in "system")
+            omit_metadata: Leave out metadata, for payloads an API accepts as they are
+
+        Returns:
+            Dict with "messages" (role and content), "system" for anthropic,
+            and "metadata" unless omitted
+        """
+        request = pb.ExportConversationRequest(
+            conversation_id=conversation_id,
+            format=format,
+            omit_metadata=omit_metadata,
+        )
+        response = self.stub.ExportConversation(request)
+
+        return json.loads(response.content)
+
+    def list_conversations_by_user(
+        self, user_id: str, after_conversation_id: str = "", limit: int = 50
+    ) -> Dict[str, Any]:
+        """
+        Get one page of a user's conversations, oldest first.
+
+        Args:
+            user_id: User ID
+            after_conversation_id: Cursor returned by the previous page
+            limit: Maximum conversations per page
+
+        Returns:
+            Dict with conversations, has_more and next_cursor
+        """
+        request = pb.ListConversationsByUserRequest(
+            user_id=user_id,
+            after_conversation_id=after_conversation_id,
+            limit=limit,
+        )
+        response = self.stub.ListConversationsByUser(request)
+
+        return {
+            "conversations": [self._pb_conversation_to_dict(c) for c in response.conversations],
+            "has_more": response.has_more,
+            "next_cursor": response.next_cursor,
+        }
+
+    def get_user_conversation_usage(self, user_id: str) -> Dict[str, Any]:
+        """
+        Get a user's conversation and message counts and the quota that applies.
+
+        Args:
+            user_id: User ID
+
+        Returns:
+            Dict with conversations, messages, quota (0 is unlimited) and custom_quota
+        """
+        request = pb.GetUserConversationUsageRequest(user_id=user_id)
+        response = self.stub.GetUserConversationUsage(request)
+
+        return {
+            "user_id": response.user_id,
+            "conversations": response.conversations,
+            "messages": response.messages,
+            "quota": {
+                "max_conversations": response.quota.max_conversations,
+                "max_messages": response.quota.max_messages,
+                "max_conversation_messages": response.quota.max_conversation_messages,
+            },
+            "custom_quota": response.custom_quota,
+        }
+
+    def set_user_conversation_quota(
+        self,
+        user_id: str,
+        max_conversations: int = 0,
+        max_messages: int = 0,
+        max_conversation_messages: int = 0,
+        use_default: bool = False,
+    ) -> bool:
+        """
+        Give a user a conversation quota of their own in place of the server default.
+
+        Args:
+            user_id: User ID
+            max_conversations: Conversations the user may have at once (0 is unlimited)
+            max_messages: Messages across the user's conversations (0 is unlimited)
+            max_conversation_messages: Messages in any one conversation (0 is unlimited)
+            use_default: Return the user to the server default instead
+
+        Returns:
+            True if successful
+        """
+        request = pb.SetUserConversationQuotaRequest(user_id=user_id)
+        if not use_default:
+            request.quota.CopyFrom(
+                pb.ConversationQuota(
+                    max_conversations=max_conversations,
+                    max_messages=max_messages,
+                    max_conversation_messages=max_conversation_messages,
+                )
+            )
+        response = self.stub.SetUserConversationQuota(request)
+
+        return response.success
+
+    # ========== Query Operations ==========
+
+    def stream_query(self, query: str) -> Iterator[Dict[str, Any]]:
+        """
+        Run a query and stream its rows as the server produces them.
+
+        Args:
+            query: Text (FROM ... WHERE ...) or JSON query
+
+        Returns:
+            Iterator of dicts with "type" (node, version, metadata,
+            conversation, or group for SELECT/GROUP BY queries) and "data"
+        """
+        request = pb.StreamQueryRequest(query=query)
+
+        for row in self.stub.StreamQuery(request):
+            converted = self._pb_query_row_to_dict(row)
+            if converted is not None:
+                yield converted
+
+    def save_query(self, name: str, query: str, refresh_on_change: bool = False) -> Dict[str, Any]:
+        """
+        Save a query under a name and materialize its results.
+
+        Args:
+            name: Saved query name; saving again replaces it
+            query: Text (FROM ... WHERE ...) or JSON query
+            refresh_on_change: Recompute the results on the next execution after
+                writes to the queried store (otherwise only refresh_saved_query does)
+
+        Returns:
+            Saved query dict
+        """
+        request = pb.SaveQueryRequest(name=name, query=query, refresh_on_change=refresh_on_change)
+        response = self.stub.SaveQuery(request)
+
+        return self._pb_saved_query_to_dict(response.saved)
+
+    def execute_saved_query(self, name: str) -> Dict[str, Any]:
+        """
+        Read the materialized results of a saved query, refreshing them first if stale.
+
+        Args:
+            name: Saved query name
+
+        Returns:
+            Dict with "saved", "rows" (as from stream_query) and "refreshed"
+        """
+        response = self.stub.ExecuteSavedQuery(pb.ExecuteSavedQueryRequest(name=name))
+
+        rows = [self._pb_query_row_to_dict(row) for row in response.rows]
+        return {
+            "saved": self._pb_saved_query_to_dict(response.saved),
+            "rows": [row for row in rows if row is not None],
+            "refreshed": response.refreshed,
+        }
+
+    def refresh_saved_query(self, name: str) -> Dict[str, Any]:
+        """
+        Re-run a saved query and replace its materialized results.
+
+        Args:
+            name: Saved query name
+
+        Returns:
+            Saved query dict
+        """
+        response = self.stub.RefreshSavedQuery(pb.RefreshSavedQueryRequest(name=name))
+
+        return self._pb_saved_query_to_dict(response.saved)
+
+    def list_saved_queries(self) -> List[Dict[str, Any]]:
+        """
+        List every saved query in name order.
+
+        Returns:
+            List of saved query dicts
+        """
+        response = self.stub.ListSavedQueries(pb.ListSavedQueriesRequest())
+
+        return [self._pb_saved_query_to_dict(sq) for sq in response.saved]
+
+    def delete_saved_query(self, name: str) -> bool:
+        """
+        Delete a saved query and its materialized results.
+
+        Args:
+            name: Saved query name
+
+        Returns:
+            True if deleted
+        """
+        response = self.stub.DeleteSavedQuery(pb.DeleteSavedQueryRequest(name=name))
+
+        return response.success
+
+    # ========== Change Feed ==========
+
+    def watch_changes(self, prefixes: Optional[List[str]] = None) -> Iterator[Dict[str, Any]]:
+        """
+        Stream change events for committed writes as they happen.
+
+        The stream ends with RESOURCE_EXHAUSTED if the watcher falls behind;
+        resubscribe and reconcile in that case.
+
+        Args:
+            prefixes: Topic prefixes such as "version/LCD-" or "metadata/tool_result/"
+                (None watches everything)
+
+        Returns:
+            Iterator of change event dicts
+        """
+        request = pb.WatchChangesRequest(prefixes=prefixes or [])
+
+        for ev in self.stub.WatchChanges(request):
+            yield {
+                "seq": ev.seq,
+                "entity": ev.entity,
+                "op": ev.op,
+                "policy_id": ev.policy_id,
+                "entity_id": ev.entity_id,
+                "topic": ev.topic,
+                "timestamp": ev.timestamp.ToDatetime() if ev.HasField("timestamp") else None,
+            }
+
+    # ========== Analysis Jobs ==========
+
+    def list_job_runs(self, job: str = "", policy_id: str = "", state: str = "") -> Dict[str, Any]:
+        """
+        List the latest run of each analysis job over each policy.
+
+        Args:
+            job: Job name (empty for every job)
+            policy_id: Policy document ID (empty for every policy)
+            state: pending, running, retrying, succeeded or failed (empty for every state)
+
+        Returns:
+            Dict with runs and the jobs the server runs (empty on followers)
+        """
+        request = pb.ListJobRunsRequest(job=job, policy_id=policy_id, state=state)
+        response = self.stub.ListJobRuns(request)
+
+        return {
+            "runs": [self._pb_job_run_to_dict(run) for run in response.runs],
+            "jobs": list(response.jobs),
+        }
+
+    def trigger_job(self, job: str, policy_id: str) -> Dict[str, Any]:
+        """
+        Queue an analysis job over a policy now, including one that failed.
+
+        Args:
+            job: Job name
+            policy_id: Policy document ID
+
+        Returns:
+            Job run dict as queued
+        """
+        response = self.stub.TriggerJob(pb.TriggerJobRequest(job=job, policy_id=policy_id))
+
+        return self._pb_job_run_to_dict(response.run)
+
+    # ========== Health & Status ==========
+
+    def health(self) -> Dict[str, Any]:
+        """
+        Check server health.
 
         Returns:
             Health status dict
@@ -369,6 +1646,39 @@ class TreeStoreClient:
             "total_versions": response.total_versions,
             "db_size_bytes": response.db_size_bytes,
             "operation_counts": dict(response.operation_counts),
+            "sync_policy": response.sync_policy,
+            "sync_interval_ms": response.sync_interval_ms,
+            "unflushed_commits": response.unflushed_commits,
+            "last_flush_error": response.last_flush_error,
+        }
+
+    def storage_breakdown(self, policy_id: str = "", limit: int = 0) -> Dict[str, Any]:
+        """
+        Report the bytes of keys and values held by each store and policy.
+
+        Reads every key in the database, so it costs about a full scan.
+
+        Args:
+            policy_id: Report only this policy (empty for all)
+            limit: Most policies to report, largest first (0 for all)
+
+        Returns:
+            Dict with per-store and per-policy usage, the total and the file size
+        """
+        request = pb.StorageBreakdownRequest(policy_id=policy_id, limit=limit)
+        response = self.stub.StorageBreakdown(request)
+
+        def stores(usages):
+            return [{"store": u.store, "keys": u.keys, "bytes": u.bytes} for u in usages]
+
+        return {
+            "stores": stores(response.stores),
+            "policies": [
+                {"policy_id": p.policy_id, "bytes": p.bytes, "stores": stores(p.stores)}
+                for p in response.policies
+            ],
+            "total_bytes": response.total_bytes,
+            "db_size_bytes": response.db_size_bytes,
         }
 
     # ========== Helper Methods ==========
@@ -385,6 +1695,65 @@ class TreeStoreClient:
             "updated_at": doc.updated_at.ToDatetime() if doc.HasField("updated_at") else None,
         }
 
+    def _pb_collection_to_dict(self, collection: pb.Collection) -> Dict[str, Any]:
+        """Convert protobuf Collection to dict."""
+        return {
+            "name": collection.name,
+            "description": collection.description,
+            "policy_ids": list(collection.policy_ids),
+            "metadata": dict(collection.metadata),
+            "created_at": collection.created_at.ToDatetime() if collection.HasField("created_at") else None,
+            "updated_at": collection.updated_at.ToDatetime() if collection.HasField("updated_at") else None,
+        }
+
+    def _pb_job_run_to_dict(self, run: pb.JobRun) -> Dict[str, Any]:
+        """Convert protobuf JobRun to dict."""
+        return {
+            "job": run.job,
+            "policy_id": run.policy_id,
+            "state": run.state,
+            "attempts": run.attempts,
+            "last_error": run.last_error,
+            "queued_at": run.queued_at.ToDatetime() if run.HasField("queued_at") else None,
+            "started_at": run.started_at.ToDatetime() if run.HasField("started_at") else None,
+            "finished_at": run.finished_at.ToDatetime() if run.HasField("finished_at") else None,
+            "next_attempt": run.next_attempt.ToDatetime() if run.HasField("next_attempt") else None,
+            "outputs": run.outputs,
+            "rerun": run.rerun,
+        }
+
+    def _pb_saved_query_to_dict(self, sq: pb.SavedQuery) -> Dict[str, Any]:
+        """Convert protobuf SavedQuery to dict."""
+        return {
+            "name": sq.name,
+            "query": sq.query,
+            "refresh_on_change": sq.refresh_on_change,
+            "refreshed_at": sq.refreshed_at.ToDatetime() if sq.HasField("refreshed_at") else None,
+            "rows": sq.rows,
+            "stale": sq.stale,
+        }
+
+    def _pb_query_row_to_dict(self, row: pb.QueryRow) -> Optional[Dict[str, Any]]:
+        """Convert protobuf QueryRow to a dict with "type" and "data", or None if empty."""
+        kind = row.WhichOneof("row")
+        if kind == "node":
+            data = self._pb_node_to_dict(row.node)
+        elif kind == "version":
+            data = self._pb_version_to_dict(row.version)
+        elif kind == "metadata":
+            data = self._pb_metadata_entry_to_dict(row.metadata)
+        elif kind == "conversation":
+            data = self._pb_conversation_to_dict(row.conversation)
+        elif kind == "group":
+            data = {
+                "key": list(row.group.key),
+                "count": row.group.count,
+                "values": dict(row.group.values),
+            }
+        else:
+            return None
+        return {"type": kind, "data": data}
+
     def _pb_node_to_dict(self, node: pb.Node) -> Dict[str, Any]:
         """Convert protobuf Node to dict."""
         return {
@@ -399,10 +1768,50 @@ class TreeStoreClient:
             "section_path": node.section_path,
             "child_ids": list(node.child_ids),
             "depth": node.depth,
+            "language": node.language,
+            "checksum": node.checksum,
+            "token_count": node.token_count,
+            "summary_checksum": node.summary_checksum,
             "created_at": node.created_at.ToDatetime() if node.HasField("created_at") else None,
             "updated_at": node.updated_at.ToDatetime() if node.HasField("updated_at") else None,
         }
 
+    def _pb_search_result_to_dict(self, result: pb.SearchResult) -> Dict[str, Any]:
+        """Convert protobuf SearchResult to dict."""
+        return {
+            "node": self._pb_node_to_dict(result.node),
+            "score": result.score,
+            "snippet": result.snippet,
+            "highlights": [(h.start, h.end) for h in result.highlights],
+        }
+
+    def _pb_message_to_dict(self, msg: pb.Message) -> Dict[str, Any]:
+        """Convert protobuf Message to dict."""
+        return {
+            "message_id": msg.message_id,
+            "conversation_id": msg.conversation_id,
+            "role": msg.role,
+            "content": msg.content,
+            "timestamp": msg.timestamp.ToDatetime() if msg.HasField("timestamp") else None,
+            "metadata": dict(msg.metadata),
+            "edited_at": msg.edited_at.ToDatetime() if msg.HasField("edited_at") else None,
+            "deleted": msg.deleted,
+        }
+
+    def _pb_conversation_to_dict(self, conv: pb.Conversation) -> Dict[str, Any]:
+        """Convert protobuf Conversation to dict."""
+        return {
+            "conversation_id": conv.conversation_id,
+            "user_id": conv.user_id,
+            "title": conv.title,
+            "started_at": conv.started_at.ToDatetime() if conv.HasField("started_at") else None,
+            "last_message_at": conv.last_message_at.ToDatetime() if conv.HasField("last_message_at") else None,
+            "message_count": conv.message_count,
+            "tags": list(conv.tags),
+            "metadata": dict(conv.metadata),
+            "archived": conv.archived,
+        }
+
     def _pb_version_to_dict(self, version: pb.PolicyVersion) -> Dict[str, Any]:
         """Convert protobuf PolicyVersion to dict."""
         return {
@@ -413,6 +1822,21 @@ class TreeStoreClient:
             "created_by": version.created_by,
             "description": version.description,
             "tags": list(version.tags),
+            "effective_from": version.effective_from.ToDatetime() if version.HasField("effective_from") else None,
+            "effective_to": version.effective_to.ToDatetime() if version.HasField("effective_to") else None,
+            "metadata": dict(version.metadata),
+        }
+
+    def _pb_metadata_entry_to_dict(self, entry: pb.MetadataEntry) -> Dict[str, Any]:
+        """Convert protobuf MetadataEntry to dict."""
+        return {
+            "entity_type": entry.entity_type,
+            "entity_id": entry.entity_id,
+            "key": entry.key,
+            "value": entry.value,
+            "value_type": entry.value_type,
+            "created_at": entry.created_at.ToDatetime() if entry.HasField("created_at") else None,
+            "updated_at": entry.updated_at.ToDatetime() if entry.HasField("updated_at") else None,
         }
 
     def _pb_tool_result_to_dict(self, result: pb.ToolResult) -> Dict[str, Any]:
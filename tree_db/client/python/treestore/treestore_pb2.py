@@ -25,7 +25,7 @@ _sym_db = _symbol_database.Default()
 from google.protobuf import timestamp_pb2 as google_dot_protobuf_dot_timestamp__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0ftreestore.proto\x12\ttreestore\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa7\x02\n\x08\x44ocument\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x12\n\nversion_id\x18\x02 \x01(\t\x12\x18\n\x10pageindex_doc_id\x18\x03 \x01(\t\x12\x14\n\x0croot_node_id\x18\x04 \x01(\t\x12\x33\n\x08metadata\x18\x05 \x03(\x0b\x32!.treestore.Document.MetadataEntry\x12.\n\ncreated_at\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12.\n\nupdated_at\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x1a/\n\rMetadataEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"\xa9\x02\n\x04Node\x12\x0f\n\x07node_id\x18\x01 \x01(\t\x12\x11\n\tpolicy_id\x18\x02 \x01(\t\x12\x11\n\tparent_id\x18\x03 \x01(\t\x12\r\n\x05title\x18\x04 \x01(\t\x12\x12\n\npage_start\x18\x05 \x01(\x05\x12\x10\n\x08page_end\x18\x06 \x01(\x05\x12\x0f\n\x07summary\x18\x07 \x01(\t\x12\x0c\n\x04text\x18\x08 \x01(\t\x12\x14\n\x0csection_path\x18\t \x01(\t\x12\x11\n\tchild_ids\x18\n \x03(\t\x12\r\n\x05\x64\x65pth\x18\x0b \x01(\x05\x12.\n\ncreated_at\x18\x0c \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12.\n\nupdated_at\x18\r \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xb2\x01\n\rPolicyVersion\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x12\n\nversion_id\x18\x02 \x01(\t\x12\x13\n\x0b\x64ocument_id\x18\x03 \x01(\t\x12.\n\ncreated_at\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x12\n\ncreated_by\x18\x05 \x01(\t\x12\x13\n\x0b\x64\x65scription\x18\x06 \x01(\t\x12\x0c\n\x04tags\x18\x07 \x03(\t\"\xc7\x01\n\nToolResult\x12\x11\n\ttool_name\x18\x01 \x01(\t\x12\x14\n\x0c\x65xecution_id\x18\x02 \x01(\t\x12\x11\n\tpolicy_id\x18\x03 \x01(\t\x12\x0f\n\x07node_id\x18\x04 \x01(\t\x12\x13\n\x0bresult_data\x18\x05 \x01(\t\x12\x0f\n\x07success\x18\x06 \x01(\x08\x12\x15\n\rerror_message\x18\x07 \x01(\t\x12/\n\x0b\x65xecuted_at\x18\x08 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xc0\x01\n\nTrajectory\x12\x15\n\rtrajectory_id\x18\x01 \x01(\t\x12\x0f\n\x07\x63\x61se_id\x18\x02 \x01(\t\x12(\n\x05steps\x18\x03 \x03(\x0b\x32\x19.treestore.TrajectoryStep\x12.\n\nstarted_at\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x30\n\x0c\x63ompleted_at\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\x9d\x01\n\x0eTrajectoryStep\x12\x13\n\x0bstep_number\x18\x01 \x01(\x05\x12\x11\n\ttool_name\x18\x02 \x01(\t\x12\x0e\n\x06\x61\x63tion\x18\x03 \x01(\t\x12\x13\n\x0bobservation\x18\x04 \x01(\t\x12\x0f\n\x07thought\x18\x05 \x01(\t\x12-\n\ttimestamp\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xcd\x01\n\x0e\x43rossReference\x12\x18\n\x10source_policy_id\x18\x01 \x01(\t\x12\x16\n\x0esource_node_id\x18\x02 \x01(\t\x12\x18\n\x10target_policy_id\x18\x03 \x01(\t\x12\x16\n\x0etarget_node_id\x18\x04 \x01(\t\x12\x16\n\x0ereference_type\x18\x05 \x01(\t\x12\x0f\n\x07\x63ontext\x18\x06 \x01(\t\x12.\n\ncreated_at\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xd1\x01\n\rContradiction\x12\x18\n\x10\x63ontradiction_id\x18\x01 \x01(\t\x12\x13\n\x0bpolicy_id_a\x18\x02 \x01(\t\x12\x11\n\tnode_id_a\x18\x03 \x01(\t\x12\x13\n\x0bpolicy_id_b\x18\x04 \x01(\t\x12\x11\n\tnode_id_b\x18\x05 \x01(\t\x12\x13\n\x0b\x64\x65scription\x18\x06 \x01(\t\x12\x10\n\x08severity\x18\x07 \x01(\t\x12/\n\x0b\x64\x65tected_at\x18\x08 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\x97\x01\n\x0ePromptTemplate\x12\x11\n\tprompt_id\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x10\n\x08template\x18\x03 \x01(\t\x12\x11\n\tvariables\x18\x04 \x03(\t\x12\x0f\n\x07version\x18\x05 \x01(\t\x12.\n\ncreated_at\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xf0\x01\n\x0bPromptUsage\x12\x10\n\x08usage_id\x18\x01 \x01(\t\x12\x11\n\tprompt_id\x18\x02 \x01(\t\x12\x45\n\x10\x66illed_variables\x18\x03 \x03(\x0b\x32+.treestore.PromptUsage.FilledVariablesEntry\x12\x10\n\x08response\x18\x04 \x01(\t\x12+\n\x07used_at\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x1a\x36\n\x14\x46illedVariablesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"]\n\x14StoreDocumentRequest\x12%\n\x08\x64ocument\x18\x01 \x01(\x0b\x32\x13.treestore.Document\x12\x1e\n\x05nodes\x18\x02 \x03(\x0b\x32\x0f.treestore.Node\"9\n\x15StoreDocumentResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"\'\n\x12GetDocumentRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\"\\\n\x13GetDocumentResponse\x12%\n\x08\x64ocument\x18\x01 \x01(\x0b\x32\x13.treestore.Document\x12\x1e\n\x05nodes\x18\x02 \x03(\x0b\x32\x0f.treestore.Node\"*\n\x15\x44\x65leteDocumentRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\":\n\x16\x44\x65leteDocumentResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"4\n\x0eGetNodeRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\"0\n\x0fGetNodeResponse\x12\x1d\n\x04node\x18\x01 \x01(\x0b\x32\x0f.treestore.Node\":\n\x12GetChildrenRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x11\n\tparent_id\x18\x02 \x01(\t\"8\n\x13GetChildrenResponse\x12!\n\x08\x63hildren\x18\x01 \x03(\x0b\x32\x0f.treestore.Node\"J\n\x11GetSubtreeRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\x12\x11\n\tmax_depth\x18\x03 \x01(\x05\"4\n\x12GetSubtreeResponse\x12\x1e\n\x05nodes\x18\x01 \x03(\x0b\x32\x0f.treestore.Node\"<\n\x16GetAncestorPathRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\"=\n\x17GetAncestorPathResponse\x12\"\n\tancestors\x18\x01 \x03(\x0b\x32\x0f.treestore.Node\"@\n\rSearchRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\r\n\x05query\x18\x02 \x01(\t\x12\r\n\x05limit\x18\x03 \x01(\x05\":\n\x0eSearchResponse\x12(\n\x07results\x18\x01 \x03(\x0b\x32\x17.treestore.SearchResult\"<\n\x0cSearchResult\x12\x1d\n\x04node\x18\x01 \x01(\x0b\x32\x0f.treestore.Node\x12\r\n\x05score\x18\x02 \x01(\x02\"?\n\x15GetNodesByPageRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x13\n\x0bpage_number\x18\x02 \x01(\x05\"8\n\x16GetNodesByPageResponse\x12\x1e\n\x05nodes\x18\x01 \x03(\x0b\x32\x0f.treestore.Node\"Z\n\x15GetVersionAsOfRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12.\n\nas_of_time\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"7\n\x13ListVersionsRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\r\n\x05limit\x18\x02 \x01(\x05\"B\n\x14ListVersionsResponse\x12*\n\x08versions\x18\x01 \x03(\x0b\x32\x18.treestore.PolicyVersion\"?\n\x16StoreToolResultRequest\x12%\n\x06result\x18\x01 \x01(\x0b\x32\x15.treestore.ToolResult\";\n\x17StoreToolResultResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"L\n\x15GetToolResultsRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x11\n\ttool_name\x18\x02 \x01(\t\x12\r\n\x05limit\x18\x03 \x01(\x05\"@\n\x16GetToolResultsResponse\x12&\n\x07results\x18\x01 \x03(\x0b\x32\x15.treestore.ToolResult\"C\n\x16StoreTrajectoryRequest\x12)\n\ntrajectory\x18\x01 \x01(\x0b\x32\x15.treestore.Trajectory\";\n\x17StoreTrajectoryResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"8\n\x16GetTrajectoriesRequest\x12\x0f\n\x07\x63\x61se_id\x18\x01 \x01(\t\x12\r\n\x05limit\x18\x02 \x01(\x05\"F\n\x17GetTrajectoriesResponse\x12+\n\x0ctrajectories\x18\x01 \x03(\x0b\x32\x15.treestore.Trajectory\"P\n\x1aStoreCrossReferenceRequest\x12\x32\n\x0f\x63ross_reference\x18\x01 \x01(\x0b\x32\x19.treestore.CrossReference\"?\n\x1bStoreCrossReferenceResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"?\n\x19GetCrossReferencesRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\"K\n\x1aGetCrossReferencesResponse\x12-\n\nreferences\x18\x01 \x03(\x0b\x32\x19.treestore.CrossReference\"L\n\x19StoreContradictionRequest\x12/\n\rcontradiction\x18\x01 \x01(\x0b\x32\x18.treestore.Contradiction\">\n\x1aStoreContradictionResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"?\n\x12StorePromptRequest\x12)\n\x06prompt\x18\x01 \x01(\x0b\x32\x19.treestore.PromptTemplate\"7\n\x13StorePromptResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"%\n\x10GetPromptRequest\x12\x11\n\tprompt_id\x18\x01 \x01(\t\">\n\x11GetPromptResponse\x12)\n\x06prompt\x18\x01 \x01(\x0b\x32\x19.treestore.PromptTemplate\"A\n\x18RecordPromptUsageRequest\x12%\n\x05usage\x18\x01 \x01(\x0b\x32\x16.treestore.PromptUsage\"=\n\x19RecordPromptUsageResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"\x0f\n\rHealthRequest\"J\n\x0eHealthResponse\x12\x0f\n\x07healthy\x18\x01 \x01(\x08\x12\x0f\n\x07version\x18\x02 \x01(\t\x12\x16\n\x0euptime_seconds\x18\x03 \x01(\x03\"\x0e\n\x0cStatsRequest\"\xed\x01\n\rStatsResponse\x12\x17\n\x0ftotal_documents\x18\x01 \x01(\x03\x12\x13\n\x0btotal_nodes\x18\x02 \x01(\x03\x12\x16\n\x0etotal_versions\x18\x03 \x01(\x03\x12\x15\n\rdb_size_bytes\x18\x04 \x01(\x03\x12G\n\x10operation_counts\x18\x05 \x03(\x0b\x32-.treestore.StatsResponse.OperationCountsEntry\x1a\x36\n\x14OperationCountsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x03:\x02\x38\x01\x32\x80\x0f\n\x10TreeStoreService\x12R\n\rStoreDocument\x12\x1f.treestore.StoreDocumentRequest\x1a .treestore.StoreDocumentResponse\x12L\n\x0bGetDocument\x12\x1d.treestore.GetDocumentRequest\x1a\x1e.treestore.GetDocumentResponse\x12U\n\x0e\x44\x65leteDocument\x12 .treestore.DeleteDocumentRequest\x1a!.treestore.DeleteDocumentResponse\x12@\n\x07GetNode\x12\x19.treestore.GetNodeRequest\x1a\x1a.treestore.GetNodeResponse\x12L\n\x0bGetChildren\x12\x1d.treestore.GetChildrenRequest\x1a\x1e.treestore.GetChildrenResponse\x12I\n\nGetSubtree\x12\x1c.treestore.GetSubtreeRequest\x1a\x1d.treestore.GetSubtreeResponse\x12X\n\x0fGetAncestorPath\x12!.treestore.GetAncestorPathRequest\x1a\".treestore.GetAncestorPathResponse\x12\x46\n\x0fSearchByKeyword\x12\x18.treestore.SearchRequest\x1a\x19.treestore.SearchResponse\x12U\n\x0eGetNodesByPage\x12 .treestore.GetNodesByPageRequest\x1a!.treestore.GetNodesByPageResponse\x12L\n\x0eGetVersionAsOf\x12 .treestore.GetVersionAsOfRequest\x1a\x18.treestore.PolicyVersion\x12O\n\x0cListVersions\x12\x1e.treestore.ListVersionsRequest\x1a\x1f.treestore.ListVersionsResponse\x12X\n\x0fStoreToolResult\x12!.treestore.StoreToolResultRequest\x1a\".treestore.StoreToolResultResponse\x12U\n\x0eGetToolResults\x12 .treestore.GetToolResultsRequest\x1a!.treestore.GetToolResultsResponse\x12X\n\x0fStoreTrajectory\x12!.treestore.StoreTrajectoryRequest\x1a\".treestore.StoreTrajectoryResponse\x12X\n\x0fGetTrajectories\x12!.treestore.GetTrajectoriesRequest\x1a\".treestore.GetTrajectoriesResponse\x12\x64\n\x13StoreCrossReference\x12%.treestore.StoreCrossReferenceRequest\x1a&.treestore.StoreCrossReferenceResponse\x12\x61\n\x12GetCrossReferences\x12$.treestore.GetCrossReferencesRequest\x1a%.treestore.GetCrossReferencesResponse\x12\x61\n\x12StoreContradiction\x12$.treestore.StoreContradictionRequest\x1a%.treestore.StoreContradictionResponse\x12L\n\x0bStorePrompt\x12\x1d.treestore.StorePromptRequest\x1a\x1e.treestore.StorePromptResponse\x12\x46\n\tGetPrompt\x12\x1b.treestore.GetPromptRequest\x1a\x1c.treestore.GetPromptResponse\x12^\n\x11RecordPromptUsage\x12#.treestore.RecordPromptUsageRequest\x1a$.treestore.RecordPromptUsageResponse\x12=\n\x06Health\x12\x18.treestore.HealthRequest\x1a\x19.treestore.HealthResponse\x12:\n\x05Stats\x12\x17.treestore.StatsRequest\x1a\x18.treestore.StatsResponseB#Z!github.com/nainya/treestore/protob\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0ftreestore.proto\x12\ttreestore\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa7\x02\n\x08\x44ocument\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x12\n\nversion_id\x18\x02 \x01(\t\x12\x18\n\x10pageindex_doc_id\x18\x03 \x01(\t\x12\x14\n\x0croot_node_id\x18\x04 \x01(\t\x12\x33\n\x08metadata\x18\x05 \x03(\x0b\x32!.treestore.Document.MetadataEntry\x12.\n\ncreated_at\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12.\n\nupdated_at\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x1a/\n\rMetadataEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"\x8d\x03\n\x04Node\x12\x0f\n\x07node_id\x18\x01 \x01(\t\x12\x11\n\tpolicy_id\x18\x02 \x01(\t\x12\x11\n\tparent_id\x18\x03 \x01(\t\x12\r\n\x05title\x18\x04 \x01(\t\x12\x12\n\npage_start\x18\x05 \x01(\x05\x12\x10\n\x08page_end\x18\x06 \x01(\x05\x12\x0f\n\x07summary\x18\x07 \x01(\t\x12\x0c\n\x04text\x18\x08 \x01(\t\x12\x14\n\x0csection_path\x18\t \x01(\t\x12\x11\n\tchild_ids\x18\n \x03(\t\x12\r\n\x05\x64\x65pth\x18\x0b \x01(\x05\x12.\n\ncreated_at\x18\x0c \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12.\n\nupdated_at\x18\r \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0f\n\x07version\x18\x0e \x01(\x04\x12\x10\n\x08language\x18\x0f \x01(\t\x12\x10\n\x08\x63hecksum\x18\x10 \x01(\t\x12\x13\n\x0btoken_count\x18\x11 \x01(\x05\x12\x18\n\x10summary_checksum\x18\x12 \x01(\t\"\x83\x03\n\rPolicyVersion\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x12\n\nversion_id\x18\x02 \x01(\t\x12\x13\n\x0b\x64ocument_id\x18\x03 \x01(\t\x12.\n\ncreated_at\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x12\n\ncreated_by\x18\x05 \x01(\t\x12\x13\n\x0b\x64\x65scription\x18\x06 \x01(\t\x12\x0c\n\x04tags\x18\x07 \x03(\t\x12\x32\n\x0e\x65\x66\x66\x65\x63tive_from\x18\x08 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x30\n\x0c\x65\x66\x66\x65\x63tive_to\x18\t \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x38\n\x08metadata\x18\n \x03(\x0b\x32&.treestore.PolicyVersion.MetadataEntry\x1a/\n\rMetadataEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"\xc7\x01\n\nToolResult\x12\x11\n\ttool_name\x18\x01 \x01(\t\x12\x14\n\x0c\x65xecution_id\x18\x02 \x01(\t\x12\x11\n\tpolicy_id\x18\x03 \x01(\t\x12\x0f\n\x07node_id\x18\x04 \x01(\t\x12\x13\n\x0bresult_data\x18\x05 \x01(\t\x12\x0f\n\x07success\x18\x06 \x01(\x08\x12\x15\n\rerror_message\x18\x07 \x01(\t\x12/\n\x0b\x65xecuted_at\x18\x08 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xc0\x01\n\nTrajectory\x12\x15\n\rtrajectory_id\x18\x01 \x01(\t\x12\x0f\n\x07\x63\x61se_id\x18\x02 \x01(\t\x12(\n\x05steps\x18\x03 \x03(\x0b\x32\x19.treestore.TrajectoryStep\x12.\n\nstarted_at\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x30\n\x0c\x63ompleted_at\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\x9d\x01\n\x0eTrajectoryStep\x12\x13\n\x0bstep_number\x18\x01 \x01(\x05\x12\x11\n\ttool_name\x18\x02 \x01(\t\x12\x0e\n\x06\x61\x63tion\x18\x03 \x01(\t\x12\x13\n\x0bobservation\x18\x04 \x01(\t\x12\x0f\n\x07thought\x18\x05 \x01(\t\x12-\n\ttimestamp\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xcd\x01\n\x0e\x43rossReference\x12\x18\n\x10source_policy_id\x18\x01 \x01(\t\x12\x16\n\x0esource_node_id\x18\x02 \x01(\t\x12\x18\n\x10target_policy_id\x18\x03 \x01(\t\x12\x16\n\x0etarget_node_id\x18\x04 \x01(\t\x12\x16\n\x0ereference_type\x18\x05 \x01(\t\x12\x0f\n\x07\x63ontext\x18\x06 \x01(\t\x12.\n\ncreated_at\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xd1\x01\n\rContradiction\x12\x18\n\x10\x63ontradiction_id\x18\x01 \x01(\t\x12\x13\n\x0bpolicy_id_a\x18\x02 \x01(\t\x12\x11\n\tnode_id_a\x18\x03 \x01(\t\x12\x13\n\x0bpolicy_id_b\x18\x04 \x01(\t\x12\x11\n\tnode_id_b\x18\x05 \x01(\t\x12\x13\n\x0b\x64\x65scription\x18\x06 \x01(\t\x12\x10\n\x08severity\x18\x07 \x01(\t\x12/\n\x0b\x64\x65tected_at\x18\x08 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\x97\x01\n\x0ePromptTemplate\x12\x11\n\tprompt_id\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x10\n\x08template\x18\x03 \x01(\t\x12\x11\n\tvariables\x18\x04 \x03(\t\x12\x0f\n\x07version\x18\x05 \x01(\t\x12.\n\ncreated_at\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xa9\x02\n\x0bPromptUsage\x12\x10\n\x08usage_id\x18\x01 \x01(\t\x12\x11\n\tprompt_id\x18\x02 \x01(\t\x12\x45\n\x10\x66illed_variables\x18\x03 \x03(\x0b\x32+.treestore.PromptUsage.FilledVariablesEntry\x12\x10\n\x08response\x18\x04 \x01(\t\x12+\n\x07used_at\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x15\n\rexperiment_id\x18\x06 \x01(\t\x12\x0f\n\x07variant\x18\x07 \x01(\t\x12\x0f\n\x07outcome\x18\x08 \x01(\t\x1a\x36\n\x14\x46illedVariablesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"\xad\x01\n\x10PromptExperiment\x12\x15\n\rexperiment_id\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12*\n\x08variants\x18\x03 \x03(\x0b\x32\x18.treestore.PromptVariant\x12\x18\n\x10success_outcomes\x18\x04 \x03(\t\x12.\n\ncreated_at\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"@\n\rPromptVariant\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x11\n\tprompt_id\x18\x02 \x01(\t\x12\x0e\n\x06weight\x18\x03 \x01(\x05\"\xe6\x01\n\x0cVariantStats\x12\x0f\n\x07variant\x18\x01 \x01(\t\x12\x11\n\tprompt_id\x18\x02 \x01(\t\x12\x0e\n\x06usages\x18\x03 \x01(\x05\x12\x0f\n\x07labeled\x18\x04 \x01(\x05\x12\x11\n\tsuccesses\x18\x05 \x01(\x05\x12\x14\n\x0csuccess_rate\x18\x06 \x01(\x01\x12\x37\n\x08outcomes\x18\x07 \x03(\x0b\x32%.treestore.VariantStats.OutcomesEntry\x1a/\n\rOutcomesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x05:\x02\x38\x01\"\xa9\x02\n\x07Message\x12\x12\n\nmessage_id\x18\x01 \x01(\t\x12\x17\n\x0f\x63onversation_id\x18\x02 \x01(\t\x12\x0c\n\x04role\x18\x03 \x01(\t\x12\x0f\n\x07\x63ontent\x18\x04 \x01(\t\x12-\n\ttimestamp\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x32\n\x08metadata\x18\x06 \x03(\x0b\x32 .treestore.Message.MetadataEntry\x12-\n\tedited_at\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0f\n\x07\x64\x65leted\x18\x08 \x01(\x08\x1a/\n\rMetadataEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"\xcd\x02\n\x0c\x43onversation\x12\x17\n\x0f\x63onversation_id\x18\x01 \x01(\t\x12\x0f\n\x07user_id\x18\x02 \x01(\t\x12\r\n\x05title\x18\x03 \x01(\t\x12.\n\nstarted_at\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x33\n\x0flast_message_at\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x15\n\rmessage_count\x18\x06 \x01(\x05\x12\x0c\n\x04tags\x18\x07 \x03(\t\x12\x37\n\x08metadata\x18\x08 \x03(\x0b\x32%.treestore.Conversation.MetadataEntry\x12\x10\n\x08\x61rchived\x18\t \x01(\x08\x1a/\n\rMetadataEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"g\n\x11\x43onversationQuota\x12\x19\n\x11max_conversations\x18\x01 \x01(\x03\x12\x14\n\x0cmax_messages\x18\x02 \x01(\x03\x12!\n\x19max_conversation_messages\x18\x03 \x01(\x03\"]\n\x14StoreDocumentRequest\x12%\n\x08\x64ocument\x18\x01 \x01(\x0b\x32\x13.treestore.Document\x12\x1e\n\x05nodes\x18\x02 \x03(\x0b\x32\x0f.treestore.Node\"W\n\x15StoreDocumentResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\x12\x1c\n\x14references_extracted\x18\x03 \x01(\x05\"7\n\x12GetDocumentRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0e\n\x06\x66ields\x18\x02 \x03(\t\"\\\n\x13GetDocumentResponse\x12%\n\x08\x64ocument\x18\x01 \x01(\x0b\x32\x13.treestore.Document\x12\x1e\n\x05nodes\x18\x02 \x03(\x0b\x32\x0f.treestore.Node\"*\n\x15\x44\x65leteDocumentRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\":\n\x16\x44\x65leteDocumentResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"\\\n\x14\x43loneDocumentRequest\x12\x15\n\rsrc_policy_id\x18\x01 \x01(\t\x12\x16\n\x0esrc_version_id\x18\x02 \x01(\t\x12\x15\n\rdst_policy_id\x18\x03 \x01(\t\"\xa6\x01\n\x15\x43loneDocumentResponse\x12\x15\n\rroot_node_ids\x18\x01 \x03(\t\x12\x44\n\x0bnode_id_map\x18\x02 \x03(\x0b\x32/.treestore.CloneDocumentResponse.NodeIdMapEntry\x1a\x30\n\x0eNodeIdMapEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"B\n\x1cRecomputeSectionPathsRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07\x64ry_run\x18\x02 \x01(\x08\"F\n\x11SectionPathChange\x12\x0f\n\x07node_id\x18\x01 \x01(\t\x12\x0e\n\x06stored\x18\x02 \x01(\t\x12\x10\n\x08\x65xpected\x18\x03 \x01(\t\"v\n\x1dRecomputeSectionPathsResponse\x12\x15\n\rnodes_checked\x18\x01 \x01(\x05\x12-\n\x07\x63hanges\x18\x02 \x03(\x0b\x32\x1c.treestore.SectionPathChange\x12\x0f\n\x07\x61pplied\x18\x03 \x01(\x08\",\n\x17ValidateDocumentRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\">\n\rDocumentIssue\x12\x0c\n\x04kind\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\x12\x0e\n\x06\x64\x65tail\x18\x03 \x01(\t\"j\n\x18ValidateDocumentResponse\x12\r\n\x05valid\x18\x01 \x01(\x08\x12\x15\n\rnodes_checked\x18\x02 \x01(\x05\x12(\n\x06issues\x18\x03 \x03(\x0b\x32\x18.treestore.DocumentIssue\"D\n\x19\x46indDuplicateNodesRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x14\n\x0c\x63ross_policy\x18\x02 \x01(\x08\"-\n\x07NodeRef\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\"E\n\x0e\x44uplicateGroup\x12\x10\n\x08\x63hecksum\x18\x01 \x01(\t\x12!\n\x05nodes\x18\x02 \x03(\x0b\x32\x12.treestore.NodeRef\"G\n\x1a\x46indDuplicateNodesResponse\x12)\n\x06groups\x18\x01 \x03(\x0b\x32\x19.treestore.DuplicateGroup\"w\n\x17RefreshSummariesRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x10\n\x08node_ids\x18\x02 \x03(\t\x12\x14\n\x0csection_path\x18\x03 \x01(\t\x12\r\n\x05\x66orce\x18\x04 \x01(\x08\x12\x12\n\nbatch_size\x18\x05 \x01(\x05\"Y\n\x18RefreshSummariesProgress\x12\r\n\x05total\x18\x01 \x01(\x05\x12\x0c\n\x04\x64one\x18\x02 \x01(\x05\x12\x0f\n\x07updated\x18\x03 \x01(\x05\x12\x0f\n\x07skipped\x18\x04 \x01(\x05\"2\n\x10SummarizeRequest\x12\x1e\n\x05nodes\x18\x01 \x03(\x0b\x32\x0f.treestore.Node\"&\n\x11SummarizeResponse\x12\x11\n\tsummaries\x18\x01 \x03(\t\"4\n\x0eGetNodeRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\"0\n\x0fGetNodeResponse\x12\x1d\n\x04node\x18\x01 \x01(\x0b\x32\x0f.treestore.Node\"2\n\x11UpdateNodeRequest\x12\x1d\n\x04node\x18\x01 \x01(\x0b\x32\x0f.treestore.Node\"3\n\x12UpdateNodeResponse\x12\x1d\n\x04node\x18\x01 \x01(\x0b\x32\x0f.treestore.Node\"J\n\x12GetChildrenRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x11\n\tparent_id\x18\x02 \x01(\t\x12\x0e\n\x06\x66ields\x18\x03 \x03(\t\"8\n\x13GetChildrenResponse\x12!\n\x08\x63hildren\x18\x01 \x03(\x0b\x32\x0f.treestore.Node\"n\n\x11GetSubtreeRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\x12\x11\n\tmax_depth\x18\x03 \x01(\x05\x12\x0e\n\x06\x66ields\x18\x04 \x03(\t\x12\x12\n\ncollection\x18\x05 \x01(\t\"4\n\x12GetSubtreeResponse\x12\x1e\n\x05nodes\x18\x01 \x03(\x0b\x32\x0f.treestore.Node\"j\n\x1dGetSubtreeWithinBudgetRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\x12\x12\n\nmax_tokens\x18\x03 \x01(\x05\x12\x11\n\tmax_depth\x18\x04 \x01(\x05\"\x8f\x01\n\x1eGetSubtreeWithinBudgetResponse\x12\x1e\n\x05nodes\x18\x01 \x03(\x0b\x32\x0f.treestore.Node\x12\x16\n\x0esummarized_ids\x18\x02 \x03(\t\x12\r\n\x05\x64\x65pth\x18\x03 \x01(\x05\x12\x13\n\x0btoken_count\x18\x04 \x01(\x05\x12\x11\n\ttruncated\x18\x05 \x01(\x08\"<\n\x16GetAncestorPathRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\"=\n\x17GetAncestorPathResponse\x12\"\n\tancestors\x18\x01 \x03(\x0b\x32\x0f.treestore.Node\"S\n\x1c\x42\x61tchGetAncestorPathsRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x10\n\x08node_ids\x18\x02 \x03(\t\x12\x0e\n\x06\x66ields\x18\x03 \x03(\t\"R\n\x0c\x41ncestorPath\x12\x0f\n\x07node_id\x18\x01 \x01(\t\x12\"\n\tancestors\x18\x02 \x03(\x0b\x32\x0f.treestore.Node\x12\r\n\x05\x66ound\x18\x03 \x01(\x08\"G\n\x1d\x42\x61tchGetAncestorPathsResponse\x12&\n\x05paths\x18\x01 \x03(\x0b\x32\x17.treestore.AncestorPath\"H\n\x12GetSiblingsRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\x12\x0e\n\x06\x66ields\x18\x03 \x03(\t\"J\n\x13GetSiblingsResponse\x12!\n\x08siblings\x18\x01 \x03(\x0b\x32\x0f.treestore.Node\x12\x10\n\x08position\x18\x02 \x01(\x05\"L\n\x16\x41\x64jacentSectionRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\x12\x0e\n\x06\x66ields\x18\x03 \x03(\t\"8\n\x17\x41\x64jacentSectionResponse\x12\x1d\n\x04node\x18\x01 \x01(\x0b\x32\x0f.treestore.Node\";\n\x16GetReadingOrderRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0e\n\x06\x66ields\x18\x02 \x03(\t\"S\n\x17GetContextWindowRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\x12\x14\n\x0ctoken_budget\x18\x03 \x01(\x05\"?\n\x0c\x43ontextEntry\x12\x0f\n\x07node_id\x18\x01 \x01(\t\x12\r\n\x05title\x18\x02 \x01(\t\x12\x0f\n\x07summary\x18\x03 \x01(\t\"\xe3\x01\n\x18GetContextWindowResponse\x12\x1d\n\x04node\x18\x01 \x01(\x0b\x32\x0f.treestore.Node\x12*\n\tancestors\x18\x02 \x03(\x0b\x32\x17.treestore.ContextEntry\x12)\n\x08siblings\x18\x03 \x03(\x0b\x32\x17.treestore.ContextEntry\x12)\n\x08\x63hildren\x18\x04 \x03(\x0b\x32\x17.treestore.ContextEntry\x12\x13\n\x0btoken_count\x18\x05 \x01(\x05\x12\x11\n\ttruncated\x18\x06 \x01(\x08\"\x98\x01\n\x12\x45xportGraphRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\r\n\x05graph\x18\x02 \x01(\t\x12\x0e\n\x06\x66ormat\x18\x03 \x01(\t\x12\x14\n\x0croot_node_id\x18\x04 \x01(\t\x12\x11\n\tmax_depth\x18\x05 \x01(\x05\x12\r\n\x05label\x18\x06 \x01(\t\x12\x18\n\x10max_label_length\x18\x07 \x01(\x05\"N\n\x13\x45xportGraphResponse\x12\x0f\n\x07\x63ontent\x18\x01 \x01(\t\x12\x12\n\nnode_count\x18\x02 \x01(\x05\x12\x12\n\nedge_count\x18\x03 \x01(\x05\"\x95\x01\n\rSearchRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\r\n\x05query\x18\x02 \x01(\t\x12\r\n\x05limit\x18\x03 \x01(\x05\x12\'\n\x06\x66ilter\x18\x04 \x01(\x0b\x32\x17.treestore.SearchFilter\x12\x16\n\x0esnippet_length\x18\x05 \x01(\x05\x12\x12\n\ncollection\x18\x06 \x01(\t\"\xdf\x01\n\x0cSearchFilter\x12\x11\n\tpage_from\x18\x01 \x01(\x05\x12\x0f\n\x07page_to\x18\x02 \x01(\x05\x12\x16\n\tmax_depth\x18\x03 \x01(\x05H\x00\x88\x01\x01\x12\x1b\n\x13section_path_prefix\x18\x04 \x01(\t\x12\x37\n\x08metadata\x18\x05 \x03(\x0b\x32%.treestore.SearchFilter.MetadataEntry\x1a/\n\rMetadataEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\x42\x0c\n\n_max_depth\":\n\x0eSearchResponse\x12(\n\x07results\x18\x01 \x03(\x0b\x32\x17.treestore.SearchResult\"w\n\x0cSearchResult\x12\x1d\n\x04node\x18\x01 \x01(\x0b\x32\x0f.treestore.Node\x12\r\n\x05score\x18\x02 \x01(\x02\x12\x0f\n\x07snippet\x18\x03 \x01(\t\x12(\n\nhighlights\x18\x04 \x03(\x0b\x32\x14.treestore.Highlight\"\'\n\tHighlight\x12\r\n\x05start\x18\x01 \x01(\x05\x12\x0b\n\x03\x65nd\x18\x02 \x01(\x05\"\x96\x01\n\x0fRetrieveRequest\x12\r\n\x05query\x18\x01 \x01(\t\x12\r\n\x05top_k\x18\x02 \x01(\x05\x12\x36\n\x06\x66ilter\x18\x03 \x03(\x0b\x32&.treestore.RetrieveRequest.FilterEntry\x1a-\n\x0b\x46ilterEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"C\n\x10RetrieveResponse\x12/\n\tdocuments\x18\x01 \x03(\x0b\x32\x1c.treestore.RetrievedDocument\"\xb3\x01\n\x11RetrievedDocument\x12\n\n\x02id\x18\x01 \x01(\t\x12\x14\n\x0cpage_content\x18\x02 \x01(\t\x12<\n\x08metadata\x18\x03 \x03(\x0b\x32*.treestore.RetrievedDocument.MetadataEntry\x12\r\n\x05score\x18\x04 \x01(\x02\x1a/\n\rMetadataEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"\x80\x01\n\x13GlobalSearchRequest\x12\r\n\x05query\x18\x01 \x01(\t\x12\x18\n\x10per_policy_limit\x18\x02 \x01(\x05\x12\x14\n\x0cmax_policies\x18\x03 \x01(\x05\x12\x16\n\x0esnippet_length\x18\x04 \x01(\x05\x12\x12\n\ncollection\x18\x05 \x01(\t\"H\n\x14GlobalSearchResponse\x12\x30\n\x08policies\x18\x01 \x03(\x0b\x32\x1e.treestore.PolicySearchResults\"f\n\x13PolicySearchResults\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12(\n\x07results\x18\x02 \x03(\x0b\x32\x17.treestore.SearchResult\x12\x12\n\ntotal_hits\x18\x03 \x01(\x05\"\xf8\x01\n\x10JoinNodesRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12;\n\x08metadata\x18\x02 \x03(\x0b\x32).treestore.JoinNodesRequest.MetadataEntry\x12\x15\n\rreferences_to\x18\x03 \x01(\t\x12\x15\n\rreferenced_by\x18\x04 \x01(\t\x12\x16\n\x0ereference_type\x18\x05 \x01(\t\x12\r\n\x05limit\x18\x06 \x01(\x05\x12\x0e\n\x06offset\x18\x07 \x01(\x05\x1a/\n\rMetadataEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\";\n\x11JoinNodesResponse\x12&\n\x07results\x18\x01 \x03(\x0b\x32\x15.treestore.JoinedNode\"\xc2\x01\n\nJoinedNode\x12\x1d\n\x04node\x18\x01 \x01(\x0b\x32\x0f.treestore.Node\x12\x35\n\x08metadata\x18\x02 \x03(\x0b\x32#.treestore.JoinedNode.MetadataEntry\x12-\n\nreferences\x18\x03 \x03(\x0b\x32\x19.treestore.CrossReference\x1a/\n\rMetadataEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"?\n\x15GetNodesByPageRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x13\n\x0bpage_number\x18\x02 \x01(\x05\"8\n\x16GetNodesByPageResponse\x12\x1e\n\x05nodes\x18\x01 \x03(\x0b\x32\x0f.treestore.Node\"Z\n\x15GetVersionAsOfRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12.\n\nas_of_time\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"a\n\x1b\x42\x61tchGetVersionsAsOfRequest\x12\x12\n\npolicy_ids\x18\x01 \x03(\t\x12.\n\nas_of_time\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xce\x01\n\x1c\x42\x61tchGetVersionsAsOfResponse\x12G\n\x08versions\x18\x01 \x03(\x0b\x32\x35.treestore.BatchGetVersionsAsOfResponse.VersionsEntry\x12\x1a\n\x12missing_policy_ids\x18\x02 \x03(\t\x1aI\n\rVersionsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\'\n\x05value\x18\x02 \x01(\x0b\x32\x18.treestore.PolicyVersion:\x02\x38\x01\"7\n\x13ListVersionsRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\r\n\x05limit\x18\x02 \x01(\x05\"B\n\x14ListVersionsResponse\x12*\n\x08versions\x18\x01 \x03(\x0b\x32\x18.treestore.PolicyVersion\"=\n\x14\x44\x65leteVersionRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x12\n\nversion_id\x18\x02 \x01(\t\"(\n\x15\x44\x65leteVersionResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"\x93\x01\n\x14PruneVersionsRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x11\n\tkeep_last\x18\x02 \x01(\x05\x12.\n\nolder_than\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x14\n\x0cprotect_tags\x18\x04 \x03(\t\x12\x0f\n\x07\x64ry_run\x18\x05 \x01(\x08\"D\n\x15PruneVersionsResponse\x12\x1a\n\x12pruned_version_ids\x18\x01 \x03(\t\x12\x0f\n\x07\x64ry_run\x18\x02 \x01(\x08\"W\n\x11TagVersionRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x12\n\nversion_id\x18\x02 \x01(\t\x12\x0b\n\x03tag\x18\x03 \x01(\t\x12\x0e\n\x06unique\x18\x04 \x01(\x08\"%\n\x12TagVersionResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"I\n\x13UntagVersionRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x12\n\nversion_id\x18\x02 \x01(\t\x12\x0b\n\x03tag\x18\x03 \x01(\t\"\'\n\x14UntagVersionResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"?\n\x16StoreToolResultRequest\x12%\n\x06result\x18\x01 \x01(\x0b\x32\x15.treestore.ToolResult\";\n\x17StoreToolResultResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"L\n\x15GetToolResultsRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x11\n\ttool_name\x18\x02 \x01(\t\x12\r\n\x05limit\x18\x03 \x01(\x05\"@\n\x16GetToolResultsResponse\x12&\n\x07results\x18\x01 \x03(\x0b\x32\x15.treestore.ToolResult\"C\n\x16StoreTrajectoryRequest\x12)\n\ntrajectory\x18\x01 \x01(\x0b\x32\x15.treestore.Trajectory\";\n\x17StoreTrajectoryResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"8\n\x16GetTrajectoriesRequest\x12\x0f\n\x07\x63\x61se_id\x18\x01 \x01(\t\x12\r\n\x05limit\x18\x02 \x01(\x05\"F\n\x17GetTrajectoriesResponse\x12+\n\x0ctrajectories\x18\x01 \x03(\x0b\x32\x15.treestore.Trajectory\"\x87\x01\n\x17ReplayTrajectoryRequest\x12\x15\n\rtrajectory_id\x18\x01 \x01(\t\x12\x11\n\tpolicy_id\x18\x02 \x01(\t\x12\x12\n\nversion_id\x18\x03 \x01(\t\x12.\n\nas_of_time\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xfd\x01\n\x18ReplayTrajectoryResponse\x12$\n\x05steps\x18\x01 \x03(\x0b\x32\x15.treestore.StepReplay\x12\x0f\n\x07matched\x18\x02 \x01(\x05\x12\x10\n\x08\x64iverged\x18\x03 \x01(\x05\x12\x0e\n\x06\x66\x61iled\x18\x04 \x01(\x05\x12\x0f\n\x07skipped\x18\x05 \x01(\x05\x12\x45\n\tdocuments\x18\x06 \x03(\x0b\x32\x32.treestore.ReplayTrajectoryResponse.DocumentsEntry\x1a\x30\n\x0e\x44ocumentsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"\xb6\x01\n\nStepReplay\x12\x13\n\x0bstep_number\x18\x01 \x01(\x05\x12\x11\n\ttool_name\x18\x02 \x01(\t\x12\x0f\n\x07outcome\x18\x03 \x01(\t\x12\x0e\n\x06reason\x18\x04 \x01(\t\x12\x18\n\x10missing_node_ids\x18\x05 \x03(\t\x12\x16\n\x0e\x61\x64\x64\x65\x64_node_ids\x18\x06 \x03(\t\x12\x18\n\x10\x63hanged_node_ids\x18\x07 \x03(\t\x12\x13\n\x0bobservation\x18\x08 \x01(\t\"P\n\x1aStoreCrossReferenceRequest\x12\x32\n\x0f\x63ross_reference\x18\x01 \x01(\x0b\x32\x19.treestore.CrossReference\"?\n\x1bStoreCrossReferenceResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"?\n\x19GetCrossReferencesRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\x0f\n\x07node_id\x18\x02 \x01(\t\"K\n\x1aGetCrossReferencesResponse\x12-\n\nreferences\x18\x01 \x03(\x0b\x32\x19.treestore.CrossReference\"L\n\x19StoreContradictionRequest\x12/\n\rcontradiction\x18\x01 \x01(\x0b\x32\x18.treestore.Contradiction\">\n\x1aStoreContradictionResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"\xde\x02\n\x17\x42\x61tchSetMetadataRequest\x12\x13\n\x0b\x65ntity_type\x18\x01 \x01(\t\x12\x11\n\tentity_id\x18\x02 \x01(\t\x12\x46\n\nattributes\x18\x03 \x03(\x0b\x32\x32.treestore.BatchSetMetadataRequest.AttributesEntry\x12\x12\n\nvalue_type\x18\x04 \x01(\t\x12S\n\x11\x65xpected_versions\x18\x05 \x03(\x0b\x32\x38.treestore.BatchSetMetadataRequest.ExpectedVersionsEntry\x1a\x31\n\x0f\x41ttributesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\x1a\x37\n\x15\x45xpectedVersionsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x04:\x02\x38\x01\"\xb0\x01\n\x18\x42\x61tchSetMetadataResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\r\n\x05\x63ount\x18\x02 \x01(\x05\x12\x43\n\x08versions\x18\x03 \x03(\x0b\x32\x31.treestore.BatchSetMetadataResponse.VersionsEntry\x1a/\n\rVersionsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x04:\x02\x38\x01\"\x8b\x02\n\nCollection\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x13\n\x0b\x64\x65scription\x18\x02 \x01(\t\x12\x12\n\npolicy_ids\x18\x03 \x03(\t\x12\x35\n\x08metadata\x18\x04 \x03(\x0b\x32#.treestore.Collection.MetadataEntry\x12.\n\ncreated_at\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12.\n\nupdated_at\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x1a/\n\rMetadataEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"A\n\x14PutCollectionRequest\x12)\n\ncollection\x18\x01 \x01(\x0b\x32\x15.treestore.Collection\"B\n\x15PutCollectionResponse\x12)\n\ncollection\x18\x01 \x01(\x0b\x32\x15.treestore.Collection\"$\n\x14GetCollectionRequest\x12\x0c\n\x04name\x18\x01 \x01(\t\"B\n\x15GetCollectionResponse\x12)\n\ncollection\x18\x01 \x01(\x0b\x32\x15.treestore.Collection\"+\n\x16ListCollectionsRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\"E\n\x17ListCollectionsResponse\x12*\n\x0b\x63ollections\x18\x01 \x03(\x0b\x32\x15.treestore.Collection\"K\n\x1eUpdateCollectionMembersRequest\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x0b\n\x03\x61\x64\x64\x18\x02 \x03(\t\x12\x0e\n\x06remove\x18\x03 \x03(\t\"L\n\x1fUpdateCollectionMembersResponse\x12)\n\ncollection\x18\x01 \x01(\x0b\x32\x15.treestore.Collection\"\'\n\x17\x44\x65leteCollectionRequest\x12\x0c\n\x04name\x18\x01 \x01(\t\"+\n\x18\x44\x65leteCollectionResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"?\n\x12StorePromptRequest\x12)\n\x06prompt\x18\x01 \x01(\x0b\x32\x19.treestore.PromptTemplate\"7\n\x13StorePromptResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"%\n\x10GetPromptRequest\x12\x11\n\tprompt_id\x18\x01 \x01(\t\">\n\x11GetPromptResponse\x12)\n\x06prompt\x18\x01 \x01(\x0b\x32\x19.treestore.PromptTemplate\"A\n\x18RecordPromptUsageRequest\x12%\n\x05usage\x18\x01 \x01(\x0b\x32\x16.treestore.PromptUsage\"=\n\x19RecordPromptUsageResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"O\n\x1cStorePromptExperimentRequest\x12/\n\nexperiment\x18\x01 \x01(\x0b\x32\x1b.treestore.PromptExperiment\"A\n\x1dStorePromptExperimentResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"3\n\x1aGetPromptExperimentRequest\x12\x15\n\rexperiment_id\x18\x01 \x01(\t\"N\n\x1bGetPromptExperimentResponse\x12/\n\nexperiment\x18\x01 \x01(\x0b\x32\x1b.treestore.PromptExperiment\"A\n\x1a\x41ssignPromptVariantRequest\x12\x15\n\rexperiment_id\x18\x01 \x01(\t\x12\x0c\n\x04unit\x18\x02 \x01(\t\"H\n\x1b\x41ssignPromptVariantResponse\x12)\n\x07variant\x18\x01 \x01(\x0b\x32\x18.treestore.PromptVariant\"S\n\x17LabelPromptUsageRequest\x12\x15\n\rexperiment_id\x18\x01 \x01(\t\x12\x10\n\x08usage_id\x18\x02 \x01(\t\x12\x0f\n\x07outcome\x18\x03 \x01(\t\"<\n\x18LabelPromptUsageResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"\x8b\x01\n\x1c\x43omparePromptVariantsRequest\x12\x15\n\rexperiment_id\x18\x01 \x01(\t\x12)\n\x05since\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12)\n\x05until\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"J\n\x1d\x43omparePromptVariantsResponse\x12)\n\x08variants\x18\x01 \x03(\x0b\x32\x17.treestore.VariantStats\"\x8f\x01\n\x16GetMessagesPageRequest\x12\x17\n\x0f\x63onversation_id\x18\x01 \x01(\t\x12\x18\n\x10\x61\x66ter_message_id\x18\x02 \x01(\t\x12\x33\n\x0f\x61\x66ter_timestamp\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\r\n\x05limit\x18\x04 \x01(\x05\"f\n\x17GetMessagesPageResponse\x12$\n\x08messages\x18\x01 \x03(\x0b\x32\x12.treestore.Message\x12\x10\n\x08has_more\x18\x02 \x01(\x08\x12\x13\n\x0bnext_cursor\x18\x03 \x01(\t\"B\n\x18GetRecentMessagesRequest\x12\x17\n\x0f\x63onversation_id\x18\x01 \x01(\t\x12\r\n\x05\x63ount\x18\x02 \x01(\x05\"A\n\x19GetRecentMessagesResponse\x12$\n\x08messages\x18\x01 \x03(\x0b\x32\x12.treestore.Message\"K\n\x1aSearchConversationsRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\t\x12\r\n\x05query\x18\x02 \x01(\t\x12\r\n\x05limit\x18\x03 \x01(\x05\"}\n\x18\x43onversationSearchResult\x12-\n\x0c\x63onversation\x18\x01 \x01(\x0b\x32\x17.treestore.Conversation\x12\r\n\x05score\x18\x02 \x01(\x01\x12#\n\x07matches\x18\x03 \x03(\x0b\x32\x12.treestore.Message\"S\n\x1bSearchConversationsResponse\x12\x34\n\x07results\x18\x01 \x03(\x0b\x32#.treestore.ConversationSearchResult\"[\n\x19\x45xportConversationRequest\x12\x17\n\x0f\x63onversation_id\x18\x01 \x01(\t\x12\x0e\n\x06\x66ormat\x18\x02 \x01(\t\x12\x15\n\romit_metadata\x18\x03 \x01(\x08\"D\n\x1a\x45xportConversationResponse\x12\x0f\n\x07\x63ontent\x18\x01 \x01(\t\x12\x15\n\rmessage_count\x18\x02 \x01(\x05\"_\n\x1eListConversationsByUserRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\t\x12\x1d\n\x15\x61\x66ter_conversation_id\x18\x02 \x01(\t\x12\r\n\x05limit\x18\x03 \x01(\x05\"x\n\x1fListConversationsByUserResponse\x12.\n\rconversations\x18\x01 \x03(\x0b\x32\x17.treestore.Conversation\x12\x10\n\x08has_more\x18\x02 \x01(\x08\x12\x13\n\x0bnext_cursor\x18\x03 \x01(\t\"2\n\x1fGetUserConversationUsageRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\t\"\x9f\x01\n GetUserConversationUsageResponse\x12\x0f\n\x07user_id\x18\x01 \x01(\t\x12\x15\n\rconversations\x18\x02 \x01(\x03\x12\x10\n\x08messages\x18\x03 \x01(\x03\x12+\n\x05quota\x18\x04 \x01(\x0b\x32\x1c.treestore.ConversationQuota\x12\x14\n\x0c\x63ustom_quota\x18\x05 \x01(\x08\"_\n\x1fSetUserConversationQuotaRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\t\x12+\n\x05quota\x18\x02 \x01(\x0b\x32\x1c.treestore.ConversationQuota\"D\n SetUserConversationQuotaResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t\"#\n\x12StreamQueryRequest\x12\r\n\x05query\x18\x01 \x01(\t\"\xd8\x01\n\rMetadataEntry\x12\x13\n\x0b\x65ntity_type\x18\x01 \x01(\t\x12\x11\n\tentity_id\x18\x02 \x01(\t\x12\x0b\n\x03key\x18\x03 \x01(\t\x12\r\n\x05value\x18\x04 \x01(\t\x12\x12\n\nvalue_type\x18\x05 \x01(\t\x12.\n\ncreated_at\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12.\n\nupdated_at\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0f\n\x07version\x18\x08 \x01(\x04\"\xe6\x01\n\x08QueryRow\x12\x1f\n\x04node\x18\x01 \x01(\x0b\x32\x0f.treestore.NodeH\x00\x12+\n\x07version\x18\x02 \x01(\x0b\x32\x18.treestore.PolicyVersionH\x00\x12,\n\x08metadata\x18\x03 \x01(\x0b\x32\x18.treestore.MetadataEntryH\x00\x12/\n\x0c\x63onversation\x18\x04 \x01(\x0b\x32\x17.treestore.ConversationH\x00\x12&\n\x05group\x18\x05 \x01(\x0b\x32\x15.treestore.QueryGroupH\x00\x42\x05\n\x03row\"\x8a\x01\n\nQueryGroup\x12\x0b\n\x03key\x18\x01 \x03(\t\x12\r\n\x05\x63ount\x18\x02 \x01(\x03\x12\x31\n\x06values\x18\x03 \x03(\x0b\x32!.treestore.QueryGroup.ValuesEntry\x1a-\n\x0bValuesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x01:\x02\x38\x01\"\x93\x01\n\nSavedQuery\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\r\n\x05query\x18\x02 \x01(\t\x12\x19\n\x11refresh_on_change\x18\x03 \x01(\x08\x12\x30\n\x0crefreshed_at\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0c\n\x04rows\x18\x05 \x01(\x03\x12\r\n\x05stale\x18\x06 \x01(\x08\"J\n\x10SaveQueryRequest\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\r\n\x05query\x18\x02 \x01(\t\x12\x19\n\x11refresh_on_change\x18\x03 \x01(\x08\"9\n\x11SaveQueryResponse\x12$\n\x05saved\x18\x01 \x01(\x0b\x32\x15.treestore.SavedQuery\"(\n\x18\x45xecuteSavedQueryRequest\x12\x0c\n\x04name\x18\x01 \x01(\t\"w\n\x19\x45xecuteSavedQueryResponse\x12$\n\x05saved\x18\x01 \x01(\x0b\x32\x15.treestore.SavedQuery\x12!\n\x04rows\x18\x02 \x03(\x0b\x32\x13.treestore.QueryRow\x12\x11\n\trefreshed\x18\x03 \x01(\x08\"(\n\x18RefreshSavedQueryRequest\x12\x0c\n\x04name\x18\x01 \x01(\t\"A\n\x19RefreshSavedQueryResponse\x12$\n\x05saved\x18\x01 \x01(\x0b\x32\x15.treestore.SavedQuery\"\x19\n\x17ListSavedQueriesRequest\"@\n\x18ListSavedQueriesResponse\x12$\n\x05saved\x18\x01 \x03(\x0b\x32\x15.treestore.SavedQuery\"\'\n\x17\x44\x65leteSavedQueryRequest\x12\x0c\n\x04name\x18\x01 \x01(\t\"+\n\x18\x44\x65leteSavedQueryResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"\'\n\x13WatchChangesRequest\x12\x10\n\x08prefixes\x18\x01 \x03(\t\"\x9a\x01\n\x0b\x43hangeEvent\x12\x0b\n\x03seq\x18\x01 \x01(\x04\x12\x0e\n\x06\x65ntity\x18\x02 \x01(\t\x12\n\n\x02op\x18\x03 \x01(\t\x12\x11\n\tpolicy_id\x18\x04 \x01(\t\x12\x11\n\tentity_id\x18\x05 \x01(\t\x12-\n\ttimestamp\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\r\n\x05topic\x18\x07 \x01(\t\"%\n\x10StreamWALRequest\x12\x11\n\tafter_lsn\x18\x01 \x01(\x04\"~\n\x08WALEntry\x12\x0b\n\x03lsn\x18\x01 \x01(\x04\x12\x0e\n\x06txn_id\x18\x02 \x01(\x04\x12\n\n\x02op\x18\x03 \x01(\r\x12\x0b\n\x03key\x18\x04 \x01(\x0c\x12\r\n\x05value\x18\x05 \x01(\x0c\x12-\n\ttimestamp\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\x92\x01\n\x14QueryAuditLogRequest\x12.\n\nstart_time\x18\x01 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12,\n\x08\x65nd_time\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\r\n\x05\x61\x63tor\x18\x03 \x01(\t\x12\r\n\x05limit\x18\x04 \x01(\x05\"\xbc\x01\n\x0b\x41uditRecord\x12(\n\x04time\x18\x01 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0b\n\x03seq\x18\x02 \x01(\x04\x12\x0e\n\x06method\x18\x03 \x01(\t\x12\r\n\x05\x61\x63tor\x18\x04 \x01(\t\x12\x10\n\x08\x65ntities\x18\x05 \x03(\t\x12\x14\n\x0crequest_hash\x18\x06 \x01(\t\x12\x0c\n\x04\x63ode\x18\x07 \x01(\t\x12\r\n\x05\x65rror\x18\x08 \x01(\t\x12\x12\n\nrequest_id\x18\t \x01(\t\"@\n\x15QueryAuditLogResponse\x12\'\n\x07records\x18\x01 \x03(\x0b\x32\x16.treestore.AuditRecord\"\xbf\x02\n\x06JobRun\x12\x0b\n\x03job\x18\x01 \x01(\t\x12\x11\n\tpolicy_id\x18\x02 \x01(\t\x12\r\n\x05state\x18\x03 \x01(\t\x12\x10\n\x08\x61ttempts\x18\x04 \x01(\x05\x12\x12\n\nlast_error\x18\x05 \x01(\t\x12-\n\tqueued_at\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12.\n\nstarted_at\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12/\n\x0b\x66inished_at\x18\x08 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x30\n\x0cnext_attempt\x18\t \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0f\n\x07outputs\x18\n \x01(\x05\x12\r\n\x05rerun\x18\x0b \x01(\x08\"C\n\x12ListJobRunsRequest\x12\x0b\n\x03job\x18\x01 \x01(\t\x12\x11\n\tpolicy_id\x18\x02 \x01(\t\x12\r\n\x05state\x18\x03 \x01(\t\"D\n\x13ListJobRunsResponse\x12\x1f\n\x04runs\x18\x01 \x03(\x0b\x32\x11.treestore.JobRun\x12\x0c\n\x04jobs\x18\x02 \x03(\t\"3\n\x11TriggerJobRequest\x12\x0b\n\x03job\x18\x01 \x01(\t\x12\x11\n\tpolicy_id\x18\x02 \x01(\t\"4\n\x12TriggerJobResponse\x12\x1e\n\x03run\x18\x01 \x01(\x0b\x32\x11.treestore.JobRun\"\x0f\n\rHealthRequest\"J\n\x0eHealthResponse\x12\x0f\n\x07healthy\x18\x01 \x01(\x08\x12\x0f\n\x07version\x18\x02 \x01(\t\x12\x16\n\x0euptime_seconds\x18\x03 \x01(\x03\"6\n\x0cStatsRequest\x12\x13\n\x0b\x61pproximate\x18\x01 \x01(\x08\x12\x11\n\tpolicy_id\x18\x02 \x01(\t\"\x9f\x03\n\rStatsResponse\x12\x17\n\x0ftotal_documents\x18\x01 \x01(\x03\x12\x13\n\x0btotal_nodes\x18\x02 \x01(\x03\x12\x16\n\x0etotal_versions\x18\x03 \x01(\x03\x12\x15\n\rdb_size_bytes\x18\x04 \x01(\x03\x12G\n\x10operation_counts\x18\x05 \x03(\x0b\x32-.treestore.StatsResponse.OperationCountsEntry\x12\x13\n\x0bsync_policy\x18\x06 \x01(\t\x12\x18\n\x10sync_interval_ms\x18\x07 \x01(\x03\x12\x19\n\x11unflushed_commits\x18\x08 \x01(\x03\x12\x18\n\x10last_flush_error\x18\t \x01(\t\x12\x13\n\x0b\x61pproximate\x18\n \x01(\x08\x12\x19\n\x11total_nodes_error\x18\x0b \x01(\x03\x12\x1c\n\x14total_versions_error\x18\x0c \x01(\x03\x1a\x36\n\x14OperationCountsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x03:\x02\x38\x01\";\n\x17StorageBreakdownRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\r\n\x05limit\x18\x02 \x01(\x05\"8\n\nStoreUsage\x12\r\n\x05store\x18\x01 \x01(\t\x12\x0c\n\x04keys\x18\x02 \x01(\x03\x12\r\n\x05\x62ytes\x18\x03 \x01(\x03\"V\n\x0bPolicyUsage\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\x12\r\n\x05\x62ytes\x18\x02 \x01(\x03\x12%\n\x06stores\x18\x03 \x03(\x0b\x32\x15.treestore.StoreUsage\"\x97\x01\n\x18StorageBreakdownResponse\x12%\n\x06stores\x18\x01 \x03(\x0b\x32\x15.treestore.StoreUsage\x12(\n\x08policies\x18\x02 \x03(\x0b\x32\x16.treestore.PolicyUsage\x12\x13\n\x0btotal_bytes\x18\x03 \x01(\x03\x12\x15\n\rdb_size_bytes\x18\x04 \x01(\x03\"\x13\n\x11\x43heckpointRequest\";\n\x12\x43heckpointResponse\x12\x10\n\x08last_lsn\x18\x01 \x01(\x04\x12\x13\n\x0b\x64uration_ms\x18\x02 \x01(\x03\"!\n\x0e\x43ompactRequest\x12\x0f\n\x07\x64ry_run\x18\x01 \x01(\x08\"\x92\x01\n\x0f\x43ompactResponse\x12\r\n\x05pages\x18\x01 \x01(\x04\x12\x12\n\ntree_pages\x18\x02 \x01(\x03\x12\x12\n\nfree_pages\x18\x03 \x01(\x03\x12\x14\n\x0cleaked_pages\x18\x04 \x01(\x03\x12\x17\n\x0freclaimed_pages\x18\x05 \x01(\x03\x12\x19\n\x11\x63onflicting_pages\x18\x06 \x01(\x03\"#\n\x0eReindexRequest\x12\x11\n\tpolicy_id\x18\x01 \x01(\t\"(\n\x0fReindexResponse\x12\x15\n\rnodes_indexed\x18\x01 \x01(\x03\"\x0e\n\x0c\x46lushRequest\"(\n\rFlushResponse\x12\x17\n\x0f\x66lushed_commits\x18\x01 \x01(\x03\"1\n\rBackupRequest\x12\x0b\n\x03\x64ir\x18\x01 \x01(\t\x12\x13\n\x0bincremental\x18\x02 \x01(\x08\"Q\n\x0e\x42\x61\x63kupResponse\x12\x10\n\x08\x66rom_lsn\x18\x01 \x01(\x04\x12\x0e\n\x06to_lsn\x18\x02 \x01(\x04\x12\x0f\n\x07\x65ntries\x18\x03 \x01(\x03\x12\x0c\n\x04\x66ile\x18\x04 \x01(\t\"#\n\x12SetLogLevelRequest\x12\r\n\x05level\x18\x01 \x01(\t\"-\n\x13SetLogLevelResponse\x12\x16\n\x0eprevious_level\x18\x01 \x01(\t\"T\n\x0fTailLogsRequest\x12\x11\n\tmin_level\x18\x01 \x01(\t\x12\x0e\n\x06method\x18\x02 \x01(\t\x12\x0e\n\x06recent\x18\x03 \x01(\x05\x12\x0e\n\x06\x66ollow\x18\x04 \x01(\x08\"\x83\x01\n\x08LogEvent\x12(\n\x04time\x18\x01 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\r\n\x05level\x18\x02 \x01(\t\x12\x0f\n\x07message\x18\x03 \x01(\t\x12\x0e\n\x06method\x18\x04 \x01(\t\x12\x0c\n\x04json\x18\x05 \x01(\t\x12\x0f\n\x07\x64ropped\x18\x06 \x01(\x04\"\x12\n\x10\x44umpStateRequest\"\x99\t\n\x11\x44umpStateResponse\x12\x0f\n\x07\x64\x62_path\x18\x01 \x01(\t\x12\x11\n\tin_memory\x18\x02 \x01(\x08\x12\x0c\n\x04heap\x18\x03 \x01(\x08\x12\x0e\n\x06legacy\x18\x04 \x01(\x08\x12\x11\n\tencrypted\x18\x05 \x01(\x08\x12\r\n\x05pages\x18\x06 \x01(\x04\x12\x14\n\x0cmapped_bytes\x18\x07 \x01(\x03\x12\x17\n\x0fmeta_generation\x18\x08 \x01(\x04\x12\x10\n\x08last_lsn\x18\t \x01(\x04\x12\x13\n\x0bsync_policy\x18\n \x01(\t\x12\x19\n\x11unflushed_commits\x18\x0b \x01(\x03\x12\x0f\n\x07\x66lushes\x18\x0c \x01(\x04\x12.\n\nlast_flush\x18\r \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x18\n\x10last_flush_error\x18\x0e \x01(\t\x12\x11\n\tread_only\x18\x0f \x01(\x08\x12\x11\n\tlog_level\x18\x10 \x01(\t\x12\x16\n\x0euptime_seconds\x18\x11 \x01(\x03\x12\x12\n\ngoroutines\x18\x12 \x01(\x03\x12\x18\n\x10heap_alloc_bytes\x18\x13 \x01(\x04\x12\x18\n\x10retention_sweeps\x18\x14 \x01(\x03\x12K\n\x10operation_counts\x18\x15 \x03(\x0b\x32\x31.treestore.DumpStateResponse.OperationCountsEntry\x12\x16\n\x0e\x66ormat_version\x18\x16 \x01(\r\x12\x1c\n\x14migrated_from_format\x18\x17 \x01(\r\x12\x1a\n\x12wal_archived_files\x18\x18 \x01(\x04\x12\x1c\n\x14wal_archive_failures\x18\x19 \x01(\x04\x12\x19\n\x11wal_archive_error\x18\x1a \x01(\t\x12\x13\n\x0b\x66lushed_lsn\x18\x1b \x01(\x04\x12\x1e\n\x16\x63heckpoint_lag_entries\x18\x1c \x01(\x04\x12\x19\n\x11\x63heckpoint_lag_ms\x18\x1d \x01(\x03\x12\x13\n\x0b\x63heckpoints\x18\x1e \x01(\x04\x12\x33\n\x0flast_checkpoint\x18\x1f \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x18\n\x10\x63heckpoint_error\x18  \x01(\t\x12\x11\n\twal_files\x18! \x01(\x03\x12\x19\n\x11\x63ompaction_passes\x18\" \x01(\x03\x12\x1f\n\x17\x63ompaction_leaves_moved\x18# \x01(\x03\x12\x14\n\x0c\x62loom_checks\x18$ \x01(\x03\x12\x18\n\x10\x62loom_rejections\x18% \x01(\x03\x12\x18\n\x10query_cache_hits\x18& \x01(\x03\x12\x1a\n\x12query_cache_misses\x18\' \x01(\x03\x12!\n\x19query_cache_invalidations\x18( \x01(\x03\x12\x17\n\x0fpath_cache_hits\x18) \x01(\x03\x12\x19\n\x11path_cache_misses\x18* \x01(\x03\x1a\x36\n\x14OperationCountsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x03:\x02\x38\x01\x32\xcf\x34\n\x10TreeStoreService\x12R\n\rStoreDocument\x12\x1f.treestore.StoreDocumentRequest\x1a .treestore.StoreDocumentResponse\x12L\n\x0bGetDocument\x12\x1d.treestore.GetDocumentRequest\x1a\x1e.treestore.GetDocumentResponse\x12U\n\x0e\x44\x65leteDocument\x12 .treestore.DeleteDocumentRequest\x1a!.treestore.DeleteDocumentResponse\x12R\n\rCloneDocument\x12\x1f.treestore.CloneDocumentRequest\x1a .treestore.CloneDocumentResponse\x12j\n\x15RecomputeSectionPaths\x12\'.treestore.RecomputeSectionPathsRequest\x1a(.treestore.RecomputeSectionPathsResponse\x12[\n\x10ValidateDocument\x12\".treestore.ValidateDocumentRequest\x1a#.treestore.ValidateDocumentResponse\x12\x61\n\x12\x46indDuplicateNodes\x12$.treestore.FindDuplicateNodesRequest\x1a%.treestore.FindDuplicateNodesResponse\x12]\n\x10RefreshSummaries\x12\".treestore.RefreshSummariesRequest\x1a#.treestore.RefreshSummariesProgress0\x01\x12@\n\x07GetNode\x12\x19.treestore.GetNodeRequest\x1a\x1a.treestore.GetNodeResponse\x12I\n\nUpdateNode\x12\x1c.treestore.UpdateNodeRequest\x1a\x1d.treestore.UpdateNodeResponse\x12L\n\x0bGetChildren\x12\x1d.treestore.GetChildrenRequest\x1a\x1e.treestore.GetChildrenResponse\x12I\n\nGetSubtree\x12\x1c.treestore.GetSubtreeRequest\x1a\x1d.treestore.GetSubtreeResponse\x12m\n\x16GetSubtreeWithinBudget\x12(.treestore.GetSubtreeWithinBudgetRequest\x1a).treestore.GetSubtreeWithinBudgetResponse\x12X\n\x0fGetAncestorPath\x12!.treestore.GetAncestorPathRequest\x1a\".treestore.GetAncestorPathResponse\x12j\n\x15\x42\x61tchGetAncestorPaths\x12\'.treestore.BatchGetAncestorPathsRequest\x1a(.treestore.BatchGetAncestorPathsResponse\x12L\n\x0bGetSiblings\x12\x1d.treestore.GetSiblingsRequest\x1a\x1e.treestore.GetSiblingsResponse\x12W\n\x0eGetNextSection\x12!.treestore.AdjacentSectionRequest\x1a\".treestore.AdjacentSectionResponse\x12[\n\x12GetPreviousSection\x12!.treestore.AdjacentSectionRequest\x1a\".treestore.AdjacentSectionResponse\x12G\n\x0fGetReadingOrder\x12!.treestore.GetReadingOrderRequest\x1a\x0f.treestore.Node0\x01\x12[\n\x10GetContextWindow\x12\".treestore.GetContextWindowRequest\x1a#.treestore.GetContextWindowResponse\x12L\n\x0b\x45xportGraph\x12\x1d.treestore.ExportGraphRequest\x1a\x1e.treestore.ExportGraphResponse\x12\x46\n\x0fSearchByKeyword\x12\x18.treestore.SearchRequest\x1a\x19.treestore.SearchResponse\x12U\n\x0eGetNodesByPage\x12 .treestore.GetNodesByPageRequest\x1a!.treestore.GetNodesByPageResponse\x12O\n\x0cGlobalSearch\x12\x1e.treestore.GlobalSearchRequest\x1a\x1f.treestore.GlobalSearchResponse\x12\x46\n\tJoinNodes\x12\x1b.treestore.JoinNodesRequest\x1a\x1c.treestore.JoinNodesResponse\x12\x43\n\x08Retrieve\x12\x1a.treestore.RetrieveRequest\x1a\x1b.treestore.RetrieveResponse\x12L\n\x0eGetVersionAsOf\x12 .treestore.GetVersionAsOfRequest\x1a\x18.treestore.PolicyVersion\x12g\n\x14\x42\x61tchGetVersionsAsOf\x12&.treestore.BatchGetVersionsAsOfRequest\x1a\'.treestore.BatchGetVersionsAsOfResponse\x12O\n\x0cListVersions\x12\x1e.treestore.ListVersionsRequest\x1a\x1f.treestore.ListVersionsResponse\x12R\n\rDeleteVersion\x12\x1f.treestore.DeleteVersionRequest\x1a .treestore.DeleteVersionResponse\x12R\n\rPruneVersions\x12\x1f.treestore.PruneVersionsRequest\x1a .treestore.PruneVersionsResponse\x12I\n\nTagVersion\x12\x1c.treestore.TagVersionRequest\x1a\x1d.treestore.TagVersionResponse\x12O\n\x0cUntagVersion\x12\x1e.treestore.UntagVersionRequest\x1a\x1f.treestore.UntagVersionResponse\x12X\n\x0fStoreToolResult\x12!.treestore.StoreToolResultRequest\x1a\".treestore.StoreToolResultResponse\x12U\n\x0eGetToolResults\x12 .treestore.GetToolResultsRequest\x1a!.treestore.GetToolResultsResponse\x12X\n\x0fStoreTrajectory\x12!.treestore.StoreTrajectoryRequest\x1a\".treestore.StoreTrajectoryResponse\x12X\n\x0fGetTrajectories\x12!.treestore.GetTrajectoriesRequest\x1a\".treestore.GetTrajectoriesResponse\x12[\n\x10ReplayTrajectory\x12\".treestore.ReplayTrajectoryRequest\x1a#.treestore.ReplayTrajectoryResponse\x12\x64\n\x13StoreCrossReference\x12%.treestore.StoreCrossReferenceRequest\x1a&.treestore.StoreCrossReferenceResponse\x12\x61\n\x12GetCrossReferences\x12$.treestore.GetCrossReferencesRequest\x1a%.treestore.GetCrossReferencesResponse\x12\x61\n\x12StoreContradiction\x12$.treestore.StoreContradictionRequest\x1a%.treestore.StoreContradictionResponse\x12[\n\x10\x42\x61tchSetMetadata\x12\".treestore.BatchSetMetadataRequest\x1a#.treestore.BatchSetMetadataResponse\x12R\n\rPutCollection\x12\x1f.treestore.PutCollectionRequest\x1a .treestore.PutCollectionResponse\x12R\n\rGetCollection\x12\x1f.treestore.GetCollectionRequest\x1a .treestore.GetCollectionResponse\x12X\n\x0fListCollections\x12!.treestore.ListCollectionsRequest\x1a\".treestore.ListCollectionsResponse\x12p\n\x17UpdateCollectionMembers\x12).treestore.UpdateCollectionMembersRequest\x1a*.treestore.UpdateCollectionMembersResponse\x12[\n\x10\x44\x65leteCollection\x12\".treestore.DeleteCollectionRequest\x1a#.treestore.DeleteCollectionResponse\x12L\n\x0bStorePrompt\x12\x1d.treestore.StorePromptRequest\x1a\x1e.treestore.StorePromptResponse\x12\x46\n\tGetPrompt\x12\x1b.treestore.GetPromptRequest\x1a\x1c.treestore.GetPromptResponse\x12^\n\x11RecordPromptUsage\x12#.treestore.RecordPromptUsageRequest\x1a$.treestore.RecordPromptUsageResponse\x12j\n\x15StorePromptExperiment\x12\'.treestore.StorePromptExperimentRequest\x1a(.treestore.StorePromptExperimentResponse\x12\x64\n\x13GetPromptExperiment\x12%.treestore.GetPromptExperimentRequest\x1a&.treestore.GetPromptExperimentResponse\x12\x64\n\x13\x41ssignPromptVariant\x12%.treestore.AssignPromptVariantRequest\x1a&.treestore.AssignPromptVariantResponse\x12[\n\x10LabelPromptUsage\x12\".treestore.LabelPromptUsageRequest\x1a#.treestore.LabelPromptUsageResponse\x12j\n\x15\x43omparePromptVariants\x12\'.treestore.ComparePromptVariantsRequest\x1a(.treestore.ComparePromptVariantsResponse\x12X\n\x0fGetMessagesPage\x12!.treestore.GetMessagesPageRequest\x1a\".treestore.GetMessagesPageResponse\x12^\n\x11GetRecentMessages\x12#.treestore.GetRecentMessagesRequest\x1a$.treestore.GetRecentMessagesResponse\x12\x64\n\x13SearchConversations\x12%.treestore.SearchConversationsRequest\x1a&.treestore.SearchConversationsResponse\x12\x61\n\x12\x45xportConversation\x12$.treestore.ExportConversationRequest\x1a%.treestore.ExportConversationResponse\x12p\n\x17ListConversationsByUser\x12).treestore.ListConversationsByUserRequest\x1a*.treestore.ListConversationsByUserResponse\x12s\n\x18GetUserConversationUsage\x12*.treestore.GetUserConversationUsageRequest\x1a+.treestore.GetUserConversationUsageResponse\x12s\n\x18SetUserConversationQuota\x12*.treestore.SetUserConversationQuotaRequest\x1a+.treestore.SetUserConversationQuotaResponse\x12\x43\n\x0bStreamQuery\x12\x1d.treestore.StreamQueryRequest\x1a\x13.treestore.QueryRow0\x01\x12\x46\n\tSaveQuery\x12\x1b.treestore.SaveQueryRequest\x1a\x1c.treestore.SaveQueryResponse\x12^\n\x11\x45xecuteSavedQuery\x12#.treestore.ExecuteSavedQueryRequest\x1a$.treestore.ExecuteSavedQueryResponse\x12^\n\x11RefreshSavedQuery\x12#.treestore.RefreshSavedQueryRequest\x1a$.treestore.RefreshSavedQueryResponse\x12[\n\x10ListSavedQueries\x12\".treestore.ListSavedQueriesRequest\x1a#.treestore.ListSavedQueriesResponse\x12[\n\x10\x44\x65leteSavedQuery\x12\".treestore.DeleteSavedQueryRequest\x1a#.treestore.DeleteSavedQueryResponse\x12H\n\x0cWatchChanges\x12\x1e.treestore.WatchChangesRequest\x1a\x16.treestore.ChangeEvent0\x01\x12?\n\tStreamWAL\x12\x1b.treestore.StreamWALRequest\x1a\x13.treestore.WALEntry0\x01\x12R\n\rQueryAuditLog\x12\x1f.treestore.QueryAuditLogRequest\x1a .treestore.QueryAuditLogResponse\x12L\n\x0bListJobRuns\x12\x1d.treestore.ListJobRunsRequest\x1a\x1e.treestore.ListJobRunsResponse\x12I\n\nTriggerJob\x12\x1c.treestore.TriggerJobRequest\x1a\x1d.treestore.TriggerJobResponse\x12=\n\x06Health\x12\x18.treestore.HealthRequest\x1a\x19.treestore.HealthResponse\x12:\n\x05Stats\x12\x17.treestore.StatsRequest\x1a\x18.treestore.StatsResponse\x12[\n\x10StorageBreakdown\x12\".treestore.StorageBreakdownRequest\x1a#.treestore.StorageBreakdownResponse2\xaf\x04\n\x0eTreeStoreAdmin\x12I\n\nCheckpoint\x12\x1c.treestore.CheckpointRequest\x1a\x1d.treestore.CheckpointResponse\x12@\n\x07\x43ompact\x12\x19.treestore.CompactRequest\x1a\x1a.treestore.CompactResponse\x12@\n\x07Reindex\x12\x19.treestore.ReindexRequest\x1a\x1a.treestore.ReindexResponse\x12:\n\x05\x46lush\x12\x17.treestore.FlushRequest\x1a\x18.treestore.FlushResponse\x12=\n\x06\x42\x61\x63kup\x12\x18.treestore.BackupRequest\x1a\x19.treestore.BackupResponse\x12L\n\x0bSetLogLevel\x12\x1d.treestore.SetLogLevelRequest\x1a\x1e.treestore.SetLogLevelResponse\x12\x46\n\tDumpState\x12\x1b.treestore.DumpStateRequest\x1a\x1c.treestore.DumpStateResponse\x12=\n\x08TailLogs\x12\x1a.treestore.TailLogsRequest\x1a\x13.treestore.LogEvent0\x01\x32T\n\nSummarizer\x12\x46\n\tSummarize\x12\x1b.treestore.SummarizeRequest\x1a\x1c.treestore.SummarizeResponseB#Z!github.com/nainya/treestore/protob\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -35,128 +35,500 @@ if not _descriptor._USE_C_DESCRIPTORS:
   _globals['DESCRIPTOR']._serialized_options = b'Z!github.com/nainya/treestore/proto'
   _globals['_DOCUMENT_METADATAENTRY']._loaded_options = None
   _globals['_DOCUMENT_METADATAENTRY']._serialized_options = b'8\001'
+  _globals['_POLICYVERSION_METADATAENTRY']._loaded_options = None
+  _globals['_POLICYVERSION_METADATAENTRY']._serialized_options = b'8\001'
   _globals['_PROMPTUSAGE_FILLEDVARIABLESENTRY']._loaded_options = None
   _globals['_PROMPTUSAGE_FILLEDVARIABLESENTRY']._serialized_options = b'8\001'
+  _globals['_VARIANTSTATS_OUTCOMESENTRY']._loaded_options = None
+  _globals['_VARIANTSTATS_OUTCOMESENTRY']._serialized_options = b'8\001'
+  _globals['_MESSAGE_METADATAENTRY']._loaded_options = None
+  _globals['_MESSAGE_METADATAENTRY']._serialized_options = b'8\001'
+  _globals['_CONVERSATION_METADATAENTRY']._loaded_options = None
+  _globals['_CONVERSATION_METADATAENTRY']._serialized_options = b'8\001'
+  _globals['_CLONEDOCUMENTRESPONSE_NODEIDMAPENTRY']._loaded_options = None
+  _globals['_CLONEDOCUMENTRESPONSE_NODEIDMAPENTRY']._serialized_options = b'8\001'
+  _globals['_SEARCHFILTER_METADATAENTRY']._loaded_options = None
+  _globals['_SEARCHFILTER_METADATAENTRY']._serialized_options = b'8\001'
+  _globals['_RETRIEVEREQUEST_FILTERENTRY']._loaded_options = None
+  _globals['_RETRIEVEREQUEST_FILTERENTRY']._serialized_options = b'8\001'
+  _globals['_RETRIEVEDDOCUMENT_METADATAENTRY']._loaded_options = None
+  _globals['_RETRIEVEDDOCUMENT_METADATAENTRY']._serialized_options = b'8\001'
+  _globals['_JOINNODESREQUEST_METADATAENTRY']._loaded_options = None
+  _globals['_JOINNODESREQUEST_METADATAENTRY']._serialized_options = b'8\001'
+  _globals['_JOINEDNODE_METADATAENTRY']._loaded_options = None
+  _globals['_JOINEDNODE_METADATAENTRY']._serialized_options = b'8\001'
+  _globals['_BATCHGETVERSIONSASOFRESPONSE_VERSIONSENTRY']._loaded_options = None
+  _globals['_BATCHGETVERSIONSASOFRESPONSE_VERSIONSENTRY']._serialized_options = b'8\001'
+  _globals['_REPLAYTRAJECTORYRESPONSE_DOCUMENTSENTRY']._loaded_options = None
+  _globals['_REPLAYTRAJECTORYRESPONSE_DOCUMENTSENTRY']._serialized_options = b'8\001'
+  _globals['_BATCHSETMETADATAREQUEST_ATTRIBUTESENTRY']._loaded_options = None
+  _globals['_BATCHSETMETADATAREQUEST_ATTRIBUTESENTRY']._serialized_options = b'8\001'
+  _globals['_BATCHSETMETADATAREQUEST_EXPECTEDVERSIONSENTRY']._loaded_options = None
+  _globals['_BATCHSETMETADATAREQUEST_EXPECTEDVERSIONSENTRY']._serialized_options = b'8\001'
+  _globals['_BATCHSETMETADATARESPONSE_VERSIONSENTRY']._loaded_options = None
+  _globals['_BATCHSETMETADATARESPONSE_VERSIONSENTRY']._serialized_options = b'8\001'
+  _globals['_COLLECTION_METADATAENTRY']._loaded_options = None
+  _globals['_COLLECTION_METADATAENTRY']._serialized_options = b'8\001'
+  _globals['_QUERYGROUP_VALUESENTRY']._loaded_options = None
+  _globals['_QUERYGROUP_VALUESENTRY']._serialized_options = b'8\001'
   _globals['_STATSRESPONSE_OPERATIONCOUNTSENTRY']._loaded_options = None
   _globals['_STATSRESPONSE_OPERATIONCOUNTSENTRY']._serialized_options = b'8\001'
+  _globals['_DUMPSTATERESPONSE_OPERATIONCOUNTSENTRY']._loaded_options = None
+  _globals['_DUMPSTATERESPONSE_OPERATIONCOUNTSENTRY']._serialized_options = b'8\001'
   _globals['_DOCUMENT']._serialized_start=64
   _globals['_DOCUMENT']._serialized_end=359
   _globals['_DOCUMENT_METADATAENTRY']._serialized_start=312
   _globals['_DOCUMENT_METADATAENTRY']._serialized_end=359
   _globals['_NODE']._serialized_start=362
-  _globals['_NODE']._serialized_end=659
-  _globals['_POLICYVERSION']._serialized_start=662
-  _globals['_POLICYVERSION']._serialized_end=840
-  _globals['_TOOLRESULT']._serialized_start=843
-  _globals['_TOOLRESULT']._serialized_end=1042
-  _globals['_TRAJECTORY']._serialized_start=1045
-  _globals['_TRAJECTORY']._serialized_end=1237
-  _globals['_TRAJECTORYSTEP']._serialized_start=1240
-  _globals['_TRAJECTORYSTEP']._serialized_end=1397
-  _globals['_CROSSREFERENCE']._serialized_start=1400
-  _globals['_CROSSREFERENCE']._serialized_end=1605
-  _globals['_CONTRADICTION']._serialized_start=1608
-  _globals['_CONTRADICTION']._serialized_end=1817
-  _globals['_PROMPTTEMPLATE']._serialized_start=1820
-  _globals['_PROMPTTEMPLATE']._serialized_end=1971
-  _globals['_PROMPTUSAGE']._serialized_start=1974
-  _globals['_PROMPTUSAGE']._serialized_end=2214
-  _globals['_PROMPTUSAGE_FILLEDVARIABLESENTRY']._serialized_start=2160
-  _globals['_PROMPTUSAGE_FILLEDVARIABLESENTRY']._serialized_end=2214
-  _globals['_STOREDOCUMENTREQUEST']._serialized_start=2216
-  _globals['_STOREDOCUMENTREQUEST']._serialized_end=2309
-  _globals['_STOREDOCUMENTRESPONSE']._serialized_start=2311
-  _globals['_STOREDOCUMENTRESPONSE']._serialized_end=2368
-  _globals['_GETDOCUMENTREQUEST']._serialized_start=2370
-  _globals['_GETDOCUMENTREQUEST']._serialized_end=2409
-  _globals['_GETDOCUMENTRESPONSE']._serialized_start=2411
-  _globals['_GETDOCUMENTRESPONSE']._serialized_end=2503
-  _globals['_DELETEDOCUMENTREQUEST']._serialized_start=2505
-  _globals['_DELETEDOCUMENTREQUEST']._serialized_end=2547
-  _globals['_DELETEDOCUMENTRESPONSE']._serialized_start=2549
-  _globals['_DELETEDOCUMENTRESPONSE']._serialized_end=2607
-  _globals['_GETNODEREQUEST']._serialized_start=2609
-  _globals['_GETNODEREQUEST']._serialized_end=2661
-  _globals['_GETNODERESPONSE']._serialized_start=2663
-  _globals['_GETNODERESPONSE']._serialized_end=2711
-  _globals['_GETCHILDRENREQUEST']._serialized_start=2713
-  _globals['_GETCHILDRENREQUEST']._serialized_end=2771
-  _globals['_GETCHILDRENRESPONSE']._serialized_start=2773
-  _globals['_GETCHILDRENRESPONSE']._serialized_end=2829
-  _globals['_GETSUBTREEREQUEST']._serialized_start=2831
-  _globals['_GETSUBTREEREQUEST']._serialized_end=2905
-  _globals['_GETSUBTREERESPONSE']._serialized_start=2907
-  _globals['_GETSUBTREERESPONSE']._serialized_end=2959
-  _globals['_GETANCESTORPATHREQUEST']._serialized_start=2961
-  _globals['_GETANCESTORPATHREQUEST']._serialized_end=3021
-  _globals['_GETANCESTORPATHRESPONSE']._serialized_start=3023
-  _globals['_GETANCESTORPATHRESPONSE']._serialized_end=3084
-  _globals['_SEARCHREQUEST']._serialized_start=3086
-  _globals['_SEARCHREQUEST']._serialized_end=3150
-  _globals['_SEARCHRESPONSE']._serialized_start=3152
-  _globals['_SEARCHRESPONSE']._serialized_end=3210
-  _globals['_SEARCHRESULT']._serialized_start=3212
-  _globals['_SEARCHRESULT']._serialized_end=3272
-  _globals['_GETNODESBYPAGEREQUEST']._serialized_start=3274
-  _globals['_GETNODESBYPAGEREQUEST']._serialized_end=3337
-  _globals['_GETNODESBYPAGERESPONSE']._serialized_start=3339
-  _globals['_GETNODESBYPAGERESPONSE']._serialized_end=3395
-  _globals['_GETVERSIONASOFREQUEST']._serialized_start=3397
-  _globals['_GETVERSIONASOFREQUEST']._serialized_end=3487
-  _globals['_LISTVERSIONSREQUEST']._serialized_start=3489
-  _globals['_LISTVERSIONSREQUEST']._serialized_end=3544
-  _globals['_LISTVERSIONSRESPONSE']._serialized_start=3546
-  _globals['_LISTVERSIONSRESPONSE']._serialized_end=3612
-  _globals['_STORETOOLRESULTREQUEST']._serialized_start=3614
-  _globals['_STORETOOLRESULTREQUEST']._serialized_end=3677
-  _globals['_STORETOOLRESULTRESPONSE']._serialized_start=3679
-  _globals['_STORETOOLRESULTRESPONSE']._serialized_end=3738
-  _globals['_GETTOOLRESULTSREQUEST']._serialized_start=3740
-  _globals['_GETTOOLRESULTSREQUEST']._serialized_end=3816
-  _globals['_GETTOOLRESULTSRESPONSE']._serialized_start=3818
-  _globals['_GETTOOLRESULTSRESPONSE']._serialized_end=3882
-  _globals['_STORETRAJECTORYREQUEST']._serialized_start=3884
-  _globals['_STORETRAJECTORYREQUEST']._serialized_end=3951
-  _globals['_STORETRAJECTORYRESPONSE']._serialized_start=3953
-  _globals['_STORETRAJECTORYRESPONSE']._serialized_end=4012
-  _globals['_GETTRAJECTORIESREQUEST']._serialized_start=4014
-  _globals['_GETTRAJECTORIESREQUEST']._serialized_end=4070
-  _globals['_GETTRAJECTORIESRESPONSE']._serialized_start=4072
-  _globals['_GETTRAJECTORIESRESPONSE']._serialized_end=4142
-  _globals['_STORECROSSREFERENCEREQUEST']._serialized_start=4144
-  _globals['_STORECROSSREFERENCEREQUEST']._serialized_end=4224
-  _globals['_STORECROSSREFERENCERESPONSE']._serialized_start=4226
-  _globals['_STORECROSSREFERENCERESPONSE']._serialized_end=4289
-  _globals['_GETCROSSREFERENCESREQUEST']._serialized_start=4291
-  _globals['_GETCROSSREFERENCESREQUEST']._serialized_end=4354
-  _globals['_GETCROSSREFERENCESRESPONSE']._serialized_start=4356
-  _globals['_GETCROSSREFERENCESRESPONSE']._serialized_end=4431
-  _globals['_STORECONTRADICTIONREQUEST']._serialized_start=4433
-  _globals['_STORECONTRADICTIONREQUEST']._serialized_end=4509
-  _globals['_STORECONTRADICTIONRESPONSE']._serialized_start=4511
-  _globals['_STORECONTRADICTIONRESPONSE']._serialized_end=4573
-  _globals['_STOREPROMPTREQUEST']._serialized_start=4575
-  _globals['_STOREPROMPTREQUEST']._serialized_end=4638
-  _globals['_STOREPROMPTRESPONSE']._serialized_start=4640
-  _globals['_STOREPROMPTRESPONSE']._serialized_end=4695
-  _globals['_GETPROMPTREQUEST']._serialized_start=4697
-  _globals['_GETPROMPTREQUEST']._serialized_end=4734
-  _globals['_GETPROMPTRESPONSE']._serialized_start=4736
-  _globals['_GETPROMPTRESPONSE']._serialized_end=4798
-  _globals['_RECORDPROMPTUSAGEREQUEST']._serialized_start=4800
-  _globals['_RECORDPROMPTUSAGEREQUEST']._serialized_end=4865
-  _globals['_RECORDPROMPTUSAGERESPONSE']._serialized_start=4867
-  _globals['_RECORDPROMPTUSAGERESPONSE']._serialized_end=4928
-  _globals['_HEALTHREQUEST']._serialized_start=4930
-  _globals['_HEALTHREQUEST']._serialized_end=4945
-  _globals['_HEALTHRESPONSE']._serialized_start=4947
-  _globals['_HEALTHRESPONSE']._serialized_end=5021
-  _globals['_STATSREQUEST']._serialized_start=5023
-  _globals['_STATSREQUEST']._serialized_end=5037
-  _globals['_STATSRESPONSE']._serialized_start=5040
-  _globals['_STATSRESPONSE']._serialized_end=5277
-  _globals['_STATSRESPONSE_OPERATIONCOUNTSENTRY']._serialized_start=5223
-  _globals['_STATSRESPONSE_OPERATIONCOUNTSENTRY']._serialized_end=5277
-  _globals['_TREESTORESERVICE']._serialized_start=5280
-  _globals['_TREESTORESERVICE']._serialized_end=7200
+  _globals['_NODE']._serialized_end=759
+  _globals['_POLICYVERSION']._serialized_start=762
+  _globals['_POLICYVERSION']._serialized_end=1149
+  _globals['_POLICYVERSION_METADATAENTRY']._serialized_start=312
+  _globals['_POLICYVERSION_METADATAENTRY']._serialized_end=359
+  _globals['_TOOLRESULT']._serialized_start=1152
+  _globals['_TOOLRESULT']._serialized_end=1351
+  _globals['_TRAJECTORY']._serialized_start=1354
+  _globals['_TRAJECTORY']._serialized_end=1546
+  _globals['_TRAJECTORYSTEP']._serialized_start=1549
+  _globals['_TRAJECTORYSTEP']._serialized_end=1706
+  _globals['_CROSSREFERENCE']._serialized_start=1709
+  _globals['_CROSSREFERENCE']._serialized_end=1914
+  _globals['_CONTRADICTION']._serialized_start=1917
+  _globals['_CONTRADICTION']._serialized_end=2126
+  _globals['_PROMPTTEMPLATE']._serialized_start=2129
+  _globals['_PROMPTTEMPLATE']._serialized_end=2280
+  _globals['_PROMPTUSAGE']._serialized_start=2283
+  _globals['_PROMPTUSAGE']._serialized_end=2580
+  _globals['_PROMPTUSAGE_FILLEDVARIABLESENTRY']._serialized_start=2526
+  _globals['_PROMPTUSAGE_FILLEDVARIABLESENTRY']._serialized_end=2580
+  _globals['_PROMPTEXPERIMENT']._serialized_start=2583
+  _globals['_PROMPTEXPERIMENT']._serialized_end=2756
+  _globals['_PROMPTVARIANT']._serialized_start=2758
+  _globals['_PROMPTVARIANT']._serialized_end=2822
+  _globals['_VARIANTSTATS']._serialized_start=2825
+  _globals['_VARIANTSTATS']._serialized_end=3055
+  _globals['_VARIANTSTATS_OUTCOMESENTRY']._serialized_start=3008
+  _globals['_VARIANTSTATS_OUTCOMESENTRY']._serialized_end=3055
+  _globals['_MESSAGE']._serialized_start=3058
+  _globals['_MESSAGE']._serialized_end=3355
+  _globals['_MESSAGE_METADATAENTRY']._serialized_start=312
+  _globals['_MESSAGE_METADATAENTRY']._serialized_end=359
+  _globals['_CONVERSATION']._serialized_start=3358
+  _globals['_CONVERSATION']._serialized_end=3691
+  _globals['_CONVERSATION_METADATAENTRY']._serialized_start=312
+  _globals['_CONVERSATION_METADATAENTRY']._serialized_end=359
+  _globals['_CONVERSATIONQUOTA']._serialized_start=3693
+  _globals['_CONVERSATIONQUOTA']._serialized_end=3796
+  _globals['_STOREDOCUMENTREQUEST']._serialized_start=3798
+  _globals['_STOREDOCUMENTREQUEST']._serialized_end=3891
+  _globals['_STOREDOCUMENTRESPONSE']._serialized_start=3893
+  _globals['_STOREDOCUMENTRESPONSE']._serialized_end=3980
+  _globals['_GETDOCUMENTREQUEST']._serialized_start=3982
+  _globals['_GETDOCUMENTREQUEST']._serialized_end=4037
+  _globals['_GETDOCUMENTRESPONSE']._serialized_start=4039
+  _globals['_GETDOCUMENTRESPONSE']._serialized_end=4131
+  _globals['_DELETEDOCUMENTREQUEST']._serialized_start=4133
+  _globals['_DELETEDOCUMENTREQUEST']._serialized_end=4175
+  _globals['_DELETEDOCUMENTRESPONSE']._serialized_start=4177
+  _globals['_DELETEDOCUMENTRESPONSE']._serialized_end=4235
+  _globals['_CLONEDOCUMENTREQUEST']._serialized_start=4237
+  _globals['_CLONEDOCUMENTREQUEST']._serialized_end=4329
+  _globals['_CLONEDOCUMENTRESPONSE']._serialized_start=4332
+  _globals['_CLONEDOCUMENTRESPONSE']._serialized_end=4498
+  _globals['_CLONEDOCUMENTRESPONSE_NODEIDMAPENTRY']._serialized_start=4450
+  _globals['_CLONEDOCUMENTRESPONSE_NODEIDMAPENTRY']._serialized_end=4498
+  _globals['_RECOMPUTESECTIONPATHSREQUEST']._serialized_start=4500
+  _globals['_RECOMPUTESECTIONPATHSREQUEST']._serialized_end=4566
+  _globals['_SECTIONPATHCHANGE']._serialized_start=4568
+  _globals['_SECTIONPATHCHANGE']._serialized_end=4638
+  _globals['_RECOMPUTESECTIONPATHSRESPONSE']._serialized_start=4640
+  _globals['_RECOMPUTESECTIONPATHSRESPONSE']._serialized_end=4758
+  _globals['_VALIDATEDOCUMENTREQUEST']._serialized_start=4760
+  _globals['_VALIDATEDOCUMENTREQUEST']._serialized_end=4804
+  _globals['_DOCUMENTISSUE']._serialized_start=4806
+  _globals['_DOCUMENTISSUE']._serialized_end=4868
+  _globals['_VALIDATEDOCUMENTRESPONSE']._serialized_start=4870
+  _globals['_VALIDATEDOCUMENTRESPONSE']._serialized_end=4976
+  _globals['_FINDDUPLICATENODESREQUEST']._serialized_start=4978
+  _globals['_FINDDUPLICATENODESREQUEST']._serialized_end=5046
+  _globals['_NODEREF']._serialized_start=5048
+  _globals['_NODEREF']._serialized_end=5093
+  _globals['_DUPLICATEGROUP']._serialized_start=5095
+  _globals['_DUPLICATEGROUP']._serialized_end=5164
+  _globals['_FINDDUPLICATENODESRESPONSE']._serialized_start=5166
+  _globals['_FINDDUPLICATENODESRESPONSE']._serialized_end=5237
+  _globals['_REFRESHSUMMARIESREQUEST']._serialized_start=5239
+  _globals['_REFRESHSUMMARIESREQUEST']._serialized_end=5358
+  _globals['_REFRESHSUMMARIESPROGRESS']._serialized_start=5360
+  _globals['_REFRESHSUMMARIESPROGRESS']._serialized_end=5449
+  _globals['_SUMMARIZEREQUEST']._serialized_start=5451
+  _globals['_SUMMARIZEREQUEST']._serialized_end=5501
+  _globals['_SUMMARIZERESPONSE']._serialized_start=5503
+  _globals['_SUMMARIZERESPONSE']._serialized_end=5541
+  _globals['_GETNODEREQUEST']._serialized_start=5543
+  _globals['_GETNODEREQUEST']._serialized_end=5595
+  _globals['_GETNODERESPONSE']._serialized_start=5597
+  _globals['_GETNODERESPONSE']._serialized_end=5645
+  _globals['_UPDATENODEREQUEST']._serialized_start=5647
+  _globals['_UPDATENODEREQUEST']._serialized_end=5697
+  _globals['_UPDATENODERESPONSE']._serialized_start=5699
+  _globals['_UPDATENODERESPONSE']._serialized_end=5750
+  _globals['_GETCHILDRENREQUEST']._serialized_start=5752
+  _globals['_GETCHILDRENREQUEST']._serialized_end=5826
+  _globals['_GETCHILDRENRESPONSE']._serialized_start=5828
+  _globals['_GETCHILDRENRESPONSE']._serialized_end=5884
+  _globals['_GETSUBTREEREQUEST']._serialized_start=5886
+  _globals['_GETSUBTREEREQUEST']._serialized_end=5996
+  _globals['_GETSUBTREERESPONSE']._serialized_start=5998
+  _globals['_GETSUBTREERESPONSE']._serialized_end=6050
+  _globals['_GETSUBTREEWITHINBUDGETREQUEST']._serialized_start=6052
+  _globals['_GETSUBTREEWITHINBUDGETREQUEST']._serialized_end=6158
+  _globals['_GETSUBTREEWITHINBUDGETRESPONSE']._serialized_start=6161
+  _globals['_GETSUBTREEWITHINBUDGETRESPONSE']._serialized_end=6304
+  _globals['_GETANCESTORPATHREQUEST']._serialized_start=6306
+  _globals['_GETANCESTORPATHREQUEST']._serialized_end=6366
+  _globals['_GETANCESTORPATHRESPONSE']._serialized_start=6368
+  _globals['_GETANCESTORPATHRESPONSE']._serialized_end=6429
+  _globals['_BATCHGETANCESTORPATHSREQUEST']._serialized_start=6431
+  _globals['_BATCHGETANCESTORPATHSREQUEST']._serialized_end=6514
+  _globals['_ANCESTORPATH']._serialized_start=6516
+  _globals['_ANCESTORPATH']._serialized_end=6598
+  _globals['_BATCHGETANCESTORPATHSRESPONSE']._serialized_start=6600
+  _globals['_BATCHGETANCESTORPATHSRESPONSE']._serialized_end=6671
+  _globals['_GETSIBLINGSREQUEST']._serialized_start=6673
+  _globals['_GETSIBLINGSREQUEST']._serialized_end=6745
+  _globals['_GETSIBLINGSRESPONSE']._serialized_start=6747
+  _globals['_GETSIBLINGSRESPONSE']._serialized_end=6821
+  _globals['_ADJACENTSECTIONREQUEST']._serialized_start=6823
+  _globals['_ADJACENTSECTIONREQUEST']._serialized_end=6899
+  _globals['_ADJACENTSECTIONRESPONSE']._serialized_start=6901
+  _globals['_ADJACENTSECTIONRESPONSE']._serialized_end=6957
+  _globals['_GETREADINGORDERREQUEST']._serialized_start=6959
+  _globals['_GETREADINGORDERREQUEST']._serialized_end=7018
+  _globals['_GETCONTEXTWINDOWREQUEST']._serialized_start=7020
+  _globals['_GETCONTEXTWINDOWREQUEST']._serialized_end=7103
+  _globals['_CONTEXTENTRY']._serialized_start=7105
+  _globals['_CONTEXTENTRY']._serialized_end=7168
+  _globals['_GETCONTEXTWINDOWRESPONSE']._serialized_start=7171
+  _globals['_GETCONTEXTWINDOWRESPONSE']._serialized_end=7398
+  _globals['_EXPORTGRAPHREQUEST']._serialized_start=7401
+  _globals['_EXPORTGRAPHREQUEST']._serialized_end=7553
+  _globals['_EXPORTGRAPHRESPONSE']._serialized_start=7555
+  _globals['_EXPORTGRAPHRESPONSE']._serialized_end=7633
+  _globals['_SEARCHREQUEST']._serialized_start=7636
+  _globals['_SEARCHREQUEST']._serialized_end=7785
+  _globals['_SEARCHFILTER']._serialized_start=7788
+  _globals['_SEARCHFILTER']._serialized_end=8011
+  _globals['_SEARCHFILTER_METADATAENTRY']._serialized_start=312
+  _globals['_SEARCHFILTER_METADATAENTRY']._serialized_end=359
+  _globals['_SEARCHRESPONSE']._serialized_start=8013
+  _globals['_SEARCHRESPONSE']._serialized_end=8071
+  _globals['_SEARCHRESULT']._serialized_start=8073
+  _globals['_SEARCHRESULT']._serialized_end=8192
+  _globals['_HIGHLIGHT']._serialized_start=8194
+  _globals['_HIGHLIGHT']._serialized_end=8233
+  _globals['_RETRIEVEREQUEST']._serialized_start=8236
+  _globals['_RETRIEVEREQUEST']._serialized_end=8386
+  _globals['_RETRIEVEREQUEST_FILTERENTRY']._serialized_start=8341
+  _globals['_RETRIEVEREQUEST_FILTERENTRY']._serialized_end=8386
+  _globals['_RETRIEVERESPONSE']._serialized_start=8388
+  _globals['_RETRIEVERESPONSE']._serialized_end=8455
+  _globals['_RETRIEVEDDOCUMENT']._serialized_start=8458
+  _globals['_RETRIEVEDDOCUMENT']._serialized_end=8637
+  _globals['_RETRIEVEDDOCUMENT_METADATAENTRY']._serialized_start=312
+  _globals['_RETRIEVEDDOCUMENT_METADATAENTRY']._serialized_end=359
+  _globals['_GLOBALSEARCHREQUEST']._serialized_start=8640
+  _globals['_GLOBALSEARCHREQUEST']._serialized_end=8768
+  _globals['_GLOBALSEARCHRESPONSE']._serialized_start=8770
+  _globals['_GLOBALSEARCHRESPONSE']._serialized_end=8842
+  _globals['_POLICYSEARCHRESULTS']._serialized_start=8844
+  _globals['_POLICYSEARCHRESULTS']._serialized_end=8946
+  _globals['_JOINNODESREQUEST']._serialized_start=8949
+  _globals['_JOINNODESREQUEST']._serialized_end=9197
+  _globals['_JOINNODESREQUEST_METADATAENTRY']._serialized_start=312
+  _globals['_JOINNODESREQUEST_METADATAENTRY']._serialized_end=359
+  _globals['_JOINNODESRESPONSE']._serialized_start=9199
+  _globals['_JOINNODESRESPONSE']._serialized_end=9258
+  _globals['_JOINEDNODE']._serialized_start=9261
+  _globals['_JOINEDNODE']._serialized_end=9455
+  _globals['_JOINEDNODE_METADATAENTRY']._serialized_start=312
+  _globals['_JOINEDNODE_METADATAENTRY']._serialized_end=359
+  _globals['_GETNODESBYPAGEREQUEST']._serialized_start=9457
+  _globals['_GETNODESBYPAGEREQUEST']._serialized_end=9520
+  _globals['_GETNODESBYPAGERESPONSE']._serialized_start=9522
+  _globals['_GETNODESBYPAGERESPONSE']._serialized_end=9578
+  _globals['_GETVERSIONASOFREQUEST']._serialized_start=9580
+  _globals['_GETVERSIONASOFREQUEST']._serialized_end=9670
+  _globals['_BATCHGETVERSIONSASOFREQUEST']._serialized_start=9672
+  _globals['_BATCHGETVERSIONSASOFREQUEST']._serialized_end=9769
+  _globals['_BATCHGETVERSIONSASOFRESPONSE']._serialized_start=9772
+  _globals['_BATCHGETVERSIONSASOFRESPONSE']._serialized_end=9978
+  _globals['_BATCHGETVERSIONSASOFRESPONSE_VERSIONSENTRY']._serialized_start=9905
+  _globals['_BATCHGETVERSIONSASOFRESPONSE_VERSIONSENTRY']._serialized_end=9978
+  _globals['_LISTVERSIONSREQUEST']._serialized_start=9980
+  _globals['_LISTVERSIONSREQUEST']._serialized_end=10035
+  _globals['_LISTVERSIONSRESPONSE']._serialized_start=10037
+  _globals['_LISTVERSIONSRESPONSE']._serialized_end=10103
+  _globals['_DELETEVERSIONREQUEST']._serialized_start=10105
+  _globals['_DELETEVERSIONREQUEST']._serialized_end=10166
+  _globals['_DELETEVERSIONRESPONSE']._serialized_start=10168
+  _globals['_DELETEVERSIONRESPONSE']._serialized_end=10208
+  _globals['_PRUNEVERSIONSREQUEST']._serialized_start=10211
+  _globals['_PRUNEVERSIONSREQUEST']._serialized_end=10358
+  _globals['_PRUNEVERSIONSRESPONSE']._serialized_start=10360
+  _globals['_PRUNEVERSIONSRESPONSE']._serialized_end=10428
+  _globals['_TAGVERSIONREQUEST']._serialized_start=10430
+  _globals['_TAGVERSIONREQUEST']._serialized_end=10517
+  _globals['_TAGVERSIONRESPONSE']._serialized_start=10519
+  _globals['_TAGVERSIONRESPONSE']._serialized_end=10556
+  _globals['_UNTAGVERSIONREQUEST']._serialized_start=10558
+  _globals['_UNTAGVERSIONREQUEST']._serialized_end=10631
+  _globals['_UNTAGVERSIONRESPONSE']._serialized_start=10633
+  _globals['_UNTAGVERSIONRESPONSE']._serialized_end=10672
+  _globals['_STORETOOLRESULTREQUEST']._serialized_start=10674
+  _globals['_STORETOOLRESULTREQUEST']._serialized_end=10737
+  _globals['_STORETOOLRESULTRESPONSE']._serialized_start=10739
+  _globals['_STORETOOLRESULTRESPONSE']._serialized_end=10798
+  _globals['_GETTOOLRESULTSREQUEST']._serialized_start=10800
+  _globals['_GETTOOLRESULTSREQUEST']._serialized_end=10876
+  _globals['_GETTOOLRESULTSRESPONSE']._serialized_start=10878
+  _globals['_GETTOOLRESULTSRESPONSE']._serialized_end=10942
+  _globals['_STORETRAJECTORYREQUEST']._serialized_start=10944
+  _globals['_STORETRAJECTORYREQUEST']._serialized_end=11011
+  _globals['_STORETRAJECTORYRESPONSE']._serialized_start=11013
+  _globals['_STORETRAJECTORYRESPONSE']._serialized_end=11072
+  _globals['_GETTRAJECTORIESREQUEST']._serialized_start=11074
+  _globals['_GETTRAJECTORIESREQUEST']._serialized_end=11130
+  _globals['_GETTRAJECTORIESRESPONSE']._serialized_start=11132
+  _globals['_GETTRAJECTORIESRESPONSE']._serialized_end=11202
+  _globals['_REPLAYTRAJECTORYREQUEST']._serialized_start=11205
+  _globals['_REPLAYTRAJECTORYREQUEST']._serialized_end=11340
+  _globals['_REPLAYTRAJECTORYRESPONSE']._serialized_start=11343
+  _globals['_REPLAYTRAJECTORYRESPONSE']._serialized_end=11596
+  _globals['_REPLAYTRAJECTORYRESPONSE_DOCUMENTSENTRY']._serialized_start=11548
+  _globals['_REPLAYTRAJECTORYRESPONSE_DOCUMENTSENTRY']._serialized_end=11596
+  _globals['_STEPREPLAY']._serialized_start=11599
+  _globals['_STEPREPLAY']._serialized_end=11781
+  _globals['_STORECROSSREFERENCEREQUEST']._serialized_start=11783
+  _globals['_STORECROSSREFERENCEREQUEST']._serialized_end=11863
+  _globals['_STORECROSSREFERENCERESPONSE']._serialized_start=11865
+  _globals['_STORECROSSREFERENCERESPONSE']._serialized_end=11928
+  _globals['_GETCROSSREFERENCESREQUEST']._serialized_start=11930
+  _globals['_GETCROSSREFERENCESREQUEST']._serialized_end=11993
+  _globals['_GETCROSSREFERENCESRESPONSE']._serialized_start=11995
+  _globals['_GETCROSSREFERENCESRESPONSE']._serialized_end=12070
+  _globals['_STORECONTRADICTIONREQUEST']._serialized_start=12072
+  _globals['_STORECONTRADICTIONREQUEST']._serialized_end=12148
+  _globals['_STORECONTRADICTIONRESPONSE']._serialized_start=12150
+  _globals['_STORECONTRADICTIONRESPONSE']._serialized_end=12212
+  _globals['_BATCHSETMETADATAREQUEST']._serialized_start=12215
+  _globals['_BATCHSETMETADATAREQUEST']._serialized_end=12565
+  _globals['_BATCHSETMETADATAREQUEST_ATTRIBUTESENTRY']._serialized_start=12459
+  _globals['_BATCHSETMETADATAREQUEST_ATTRIBUTESENTRY']._serialized_end=12508
+  _globals['_BATCHSETMETADATAREQUEST_EXPECTEDVERSIONSENTRY']._serialized_start=12510
+  _globals['_BATCHSETMETADATAREQUEST_EXPECTEDVERSIONSENTRY']._serialized_end=12565
+  _globals['_BATCHSETMETADATARESPONSE']._serialized_start=12568
+  _globals['_BATCHSETMETADATARESPONSE']._serialized_end=12744
+  _globals['_BATCHSETMETADATARESPONSE_VERSIONSENTRY']._serialized_start=12697
+  _globals['_BATCHSETMETADATARESPONSE_VERSIONSENTRY']._serialized_end=12744
+  _globals['_COLLECTION']._serialized_start=12747
+  _globals['_COLLECTION']._serialized_end=13014
+  _globals['_COLLECTION_METADATAENTRY']._serialized_start=312
+  _globals['_COLLECTION_METADATAENTRY']._serialized_end=359
+  _globals['_PUTCOLLECTIONREQUEST']._serialized_start=13016
+  _globals['_PUTCOLLECTIONREQUEST']._serialized_end=13081
+  _globals['_PUTCOLLECTIONRESPONSE']._serialized_start=13083
+  _globals['_PUTCOLLECTIONRESPONSE']._serialized_end=13149
+  _globals['_GETCOLLECTIONREQUEST']._serialized_start=13151
+  _globals['_GETCOLLECTIONREQUEST']._serialized_end=13187
+  _globals['_GETCOLLECTIONRESPONSE']._serialized_start=13189
+  _globals['_GETCOLLECTIONRESPONSE']._serialized_end=13255
+  _globals['_LISTCOLLECTIONSREQUEST']._serialized_start=13257
+  _globals['_LISTCOLLECTIONSREQUEST']._serialized_end=13300
+  _globals['_LISTCOLLECTIONSRESPONSE']._serialized_start=13302
+  _globals['_LISTCOLLECTIONSRESPONSE']._serialized_end=13371
+  _globals['_UPDATECOLLECTIONMEMBERSREQUEST']._serialized_start=13373
+  _globals['_UPDATECOLLECTIONMEMBERSREQUEST']._serialized_end=13448
+  _globals['_UPDATECOLLECTIONMEMBERSRESPONSE']._serialized_start=13450
+  _globals['_UPDATECOLLECTIONMEMBERSRESPONSE']._serialized_end=13526
+  _globals['_DELETECOLLECTIONREQUEST']._serialized_start=13528
+  _globals['_DELETECOLLECTIONREQUEST']._serialized_end=13567
+  _globals['_DELETECOLLECTIONRESPONSE']._serialized_start=13569
+  _globals['_DELETECOLLECTIONRESPONSE']._serialized_end=13612
+  _globals['_STOREPROMPTREQUEST']._serialized_start=13614
+  _globals['_STOREPROMPTREQUEST']._serialized_end=13677
+  _globals['_STOREPROMPTRESPONSE']._serialized_start=13679
+  _globals['_STOREPROMPTRESPONSE']._serialized_end=13734
+  _globals['_GETPROMPTREQUEST']._serialized_start=13736
+  _globals['_GETPROMPTREQUEST']._serialized_end=13773
+  _globals['_GETPROMPTRESPONSE']._serialized_start=13775
+  _globals['_GETPROMPTRESPONSE']._serialized_end=13837
+  _globals['_RECORDPROMPTUSAGEREQUEST']._serialized_start=13839
+  _globals['_RECORDPROMPTUSAGEREQUEST']._serialized_end=13904
+  _globals['_RECORDPROMPTUSAGERESPONSE']._serialized_start=13906
+  _globals['_RECORDPROMPTUSAGERESPONSE']._serialized_end=13967
+  _globals['_STOREPROMPTEXPERIMENTREQUEST']._serialized_start=13969
+  _globals['_STOREPROMPTEXPERIMENTREQUEST']._serialized_end=14048
+  _globals['_STOREPROMPTEXPERIMENTRESPONSE']._serialized_start=14050
+  _globals['_STOREPROMPTEXPERIMENTRESPONSE']._serialized_end=14115
+  _globals['_GETPROMPTEXPERIMENTREQUEST']._serialized_start=14117
+  _globals['_GETPROMPTEXPERIMENTREQUEST']._serialized_end=14168
+  _globals['_GETPROMPTEXPERIMENTRESPONSE']._serialized_start=14170
+  _globals['_GETPROMPTEXPERIMENTRESPONSE']._serialized_end=14248
+  _globals['_ASSIGNPROMPTVARIANTREQUEST']._serialized_start=14250
+  _globals['_ASSIGNPROMPTVARIANTREQUEST']._serialized_end=14315
+  _globals['_ASSIGNPROMPTVARIANTRESPONSE']._serialized_start=14317
+  _globals['_ASSIGNPROMPTVARIANTRESPONSE']._serialized_end=14389
+  _globals['_LABELPROMPTUSAGEREQUEST']._serialized_start=14391
+  _globals['_LABELPROMPTUSAGEREQUEST']._serialized_end=14474
+  _globals['_LABELPROMPTUSAGERESPONSE']._serialized_start=14476
+  _globals['_LABELPROMPTUSAGERESPONSE']._serialized_end=14536
+  _globals['_COMPAREPROMPTVARIANTSREQUEST']._serialized_start=14539
+  _globals['_COMPAREPROMPTVARIANTSREQUEST']._serialized_end=14678
+  _globals['_COMPAREPROMPTVARIANTSRESPONSE']._serialized_start=14680
+  _globals['_COMPAREPROMPTVARIANTSRESPONSE']._serialized_end=14754
+  _globals['_GETMESSAGESPAGEREQUEST']._serialized_start=14757
+  _globals['_GETMESSAGESPAGEREQUEST']._serialized_end=14900
+  _globals['_GETMESSAGESPAGERESPONSE']._serialized_start=14902
+  _globals['_GETMESSAGESPAGERESPONSE']._serialized_end=15004
+  _globals['_GETRECENTMESSAGESREQUEST']._serialized_start=15006
+  _globals['_GETRECENTMESSAGESREQUEST']._serialized_end=15072
+  _globals['_GETRECENTMESSAGESRESPONSE']._serialized_start=15074
+  _globals['_GETRECENTMESSAGESRESPONSE']._serialized_end=15139
+  _globals['_SEARCHCONVERSATIONSREQUEST']._serialized_start=15141
+  _globals['_SEARCHCONVERSATIONSREQUEST']._serialized_end=15216
+  _globals['_CONVERSATIONSEARCHRESULT']._serialized_start=15218
+  _globals['_CONVERSATIONSEARCHRESULT']._serialized_end=15343
+  _globals['_SEARCHCONVERSATIONSRESPONSE']._serialized_start=15345
+  _globals['_SEARCHCONVERSATIONSRESPONSE']._serialized_end=15428
+  _globals['_EXPORTCONVERSATIONREQUEST']._serialized_start=15430
+  _globals['_EXPORTCONVERSATIONREQUEST']._serialized_end=15521
+  _globals['_EXPORTCONVERSATIONRESPONSE']._serialized_start=15523
+  _globals['_EXPORTCONVERSATIONRESPONSE']._serialized_end=15591
+  _globals['_LISTCONVERSATIONSBYUSERREQUEST']._serialized_start=15593
+  _globals['_LISTCONVERSATIONSBYUSERREQUEST']._serialized_end=15688
+  _globals['_LISTCONVERSATIONSBYUSERRESPONSE']._serialized_start=15690
+  _globals['_LISTCONVERSATIONSBYUSERRESPONSE']._serialized_end=15810
+  _globals['_GETUSERCONVERSATIONUSAGEREQUEST']._serialized_start=15812
+  _globals['_GETUSERCONVERSATIONUSAGEREQUEST']._serialized_end=15862
+  _globals['_GETUSERCONVERSATIONUSAGERESPONSE']._serialized_start=15865
+  _globals['_GETUSERCONVERSATIONUSAGERESPONSE']._serialized_end=16024
+  _globals['_SETUSERCONVERSATIONQUOTAREQUEST']._serialized_start=16026
+  _globals['_SETUSERCONVERSATIONQUOTAREQUEST']._serialized_end=16121
+  _globals['_SETUSERCONVERSATIONQUOTARESPONSE']._serialized_start=16123
+  _globals['_SETUSERCONVERSATIONQUOTARESPONSE']._serialized_end=16191
+  _globals['_STREAMQUERYREQUEST']._serialized_start=16193
+  _globals['_STREAMQUERYREQUEST']._serialized_end=16228
+  _globals['_METADATAENTRY']._serialized_start=16231
+  _globals['_METADATAENTRY']._serialized_end=16447
+  _globals['_QUERYROW']._serialized_start=16450
+  _globals['_QUERYROW']._serialized_end=16680
+  _globals['_QUERYGROUP']._serialized_start=16683
+  _globals['_QUERYGROUP']._serialized_end=16821
+  _globals['_QUERYGROUP_VALUESENTRY']._serialized_start=16776
+  _globals['_QUERYGROUP_VALUESENTRY']._serialized_end=16821
+  _globals['_SAVEDQUERY']._serialized_start=16824
+  _globals['_SAVEDQUERY']._serialized_end=16971
+  _globals['_SAVEQUERYREQUEST']._serialized_start=16973
+  _globals['_SAVEQUERYREQUEST']._serialized_end=17047
+  _globals['_SAVEQUERYRESPONSE']._serialized_start=17049
+  _globals['_SAVEQUERYRESPONSE']._serialized_end=17106
+  _globals['_EXECUTESAVEDQUERYREQUEST']._serialized_start=17108
+  _globals['_EXECUTESAVEDQUERYREQUEST']._serialized_end=17148
+  _globals['_EXECUTESAVEDQUERYRESPONSE']._serialized_start=17150
+  _globals['_EXECUTESAVEDQUERYRESPONSE']._serialized_end=17269
+  _globals['_REFRESHSAVEDQUERYREQUEST']._serialized_start=17271
+  _globals['_REFRESHSAVEDQUERYREQUEST']._serialized_end=17311
+  _globals['_REFRESHSAVEDQUERYRESPONSE']._serialized_start=17313
+  _globals['_REFRESHSAVEDQUERYRESPONSE']._serialized_end=17378
+  _globals['_LISTSAVEDQUERIESREQUEST']._serialized_start=17380
+  _globals['_LISTSAVEDQUERIESREQUEST']._serialized_end=17405
+  _globals['_LISTSAVEDQUERIESRESPONSE']._serialized_start=17407
+  _globals['_LISTSAVEDQUERIESRESPONSE']._serialized_end=17471
+  _globals['_DELETESAVEDQUERYREQUEST']._serialized_start=17473
+  _globals['_DELETESAVEDQUERYREQUEST']._serialized_end=17512
+  _globals['_DELETESAVEDQUERYRESPONSE']._serialized_start=17514
+  _globals['_DELETESAVEDQUERYRESPONSE']._serialized_end=17557
+  _globals['_WATCHCHANGESREQUEST']._serialized_start=17559
+  _globals['_WATCHCHANGESREQUEST']._serialized_end=17598
+  _globals['_CHANGEEVENT']._serialized_start=17601
+  _globals['_CHANGEEVENT']._serialized_end=17755
+  _globals['_STREAMWALREQUEST']._serialized_start=17757
+  _globals['_STREAMWALREQUEST']._serialized_end=17794
+  _globals['_WALENTRY']._serialized_start=17796
+  _globals['_WALENTRY']._serialized_end=17922
+  _globals['_QUERYAUDITLOGREQUEST']._serialized_start=17925
+  _globals['_QUERYAUDITLOGREQUEST']._serialized_end=18071
+  _globals['_AUDITRECORD']._serialized_start=18074
+  _globals['_AUDITRECORD']._serialized_end=18262
+  _globals['_QUERYAUDITLOGRESPONSE']._serialized_start=18264
+  _globals['_QUERYAUDITLOGRESPONSE']._serialized_end=18328
+  _globals['_JOBRUN']._serialized_start=18331
+  _globals['_JOBRUN']._serialized_end=18650
+  _globals['_LISTJOBRUNSREQUEST']._serialized_start=18652
+  _globals['_LISTJOBRUNSREQUEST']._serialized_end=18719
+  _globals['_LISTJOBRUNSRESPONSE']._serialized_start=18721
+  _globals['_LISTJOBRUNSRESPONSE']._serialized_end=18789
+  _globals['_TRIGGERJOBREQUEST']._serialized_start=18791
+  _globals['_TRIGGERJOBREQUEST']._serialized_end=18842
+  _globals['_TRIGGERJOBRESPONSE']._serialized_start=18844
+  _globals['_TRIGGERJOBRESPONSE']._serialized_end=18896
+  _globals['_HEALTHREQUEST']._serialized_start=18898
+  _globals['_HEALTHREQUEST']._serialized_end=18913
+  _globals['_HEALTHRESPONSE']._serialized_start=18915
+  _globals['_HEALTHRESPONSE']._serialized_end=18989
+  _globals['_STATSREQUEST']._serialized_start=18991
+  _globals['_STATSREQUEST']._serialized_end=19045
+  _globals['_STATSRESPONSE']._serialized_start=19048
+  _globals['_STATSRESPONSE']._serialized_end=19463
+  _globals['_STATSRESPONSE_OPERATIONCOUNTSENTRY']._serialized_start=19409
+  _globals['_STATSRESPONSE_OPERATIONCOUNTSENTRY']._serialized_end=19463
+  _globals['_STORAGEBREAKDOWNREQUEST']._serialized_start=19465
+  _globals['_STORAGEBREAKDOWNREQUEST']._serialized_end=19524
+  _globals['_STOREUSAGE']._serialized_start=19526
+  _globals['_STOREUSAGE']._serialized_end=19582
+  _globals['_POLICYUSAGE']._serialized_start=19584
+  _globals['_POLICYUSAGE']._serialized_end=19670
+  _globals['_STORAGEBREAKDOWNRESPONSE']._serialized_start=19673
+  _globals['_STORAGEBREAKDOWNRESPONSE']._serialized_end=19824
+  _globals['_CHECKPOINTREQUEST']._serialized_start=19826
+  _globals['_CHECKPOINTREQUEST']._serialized_end=19845
+  _globals['_CHECKPOINTRESPONSE']._serialized_start=19847
+  _globals['_CHECKPOINTRESPONSE']._serialized_end=19906
+  _globals['_COMPACTREQUEST']._serialized_start=19908
+  _globals['_COMPACTREQUEST']._serialized_end=19941
+  _globals['_COMPACTRESPONSE']._serialized_start=19944
+  _globals['_COMPACTRESPONSE']._serialized_end=20090
+  _globals['_REINDEXREQUEST']._serialized_start=20092
+  _globals['_REINDEXREQUEST']._serialized_end=20127
+  _globals['_REINDEXRESPONSE']._serialized_start=20129
+  _globals['_REINDEXRESPONSE']._serialized_end=20169
+  _globals['_FLUSHREQUEST']._serialized_start=20171
+  _globals['_FLUSHREQUEST']._serialized_end=20185
+  _globals['_FLUSHRESPONSE']._serialized_start=20187
+  _globals['_FLUSHRESPONSE']._serialized_end=20227
+  _globals['_BACKUPREQUEST']._serialized_start=20229
+  _globals['_BACKUPREQUEST']._serialized_end=20278
+  _globals['_BACKUPRESPONSE']._serialized_start=20280
+  _globals['_BACKUPRESPONSE']._serialized_end=20361
+  _globals['_SETLOGLEVELREQUEST']._serialized_start=20363
+  _globals['_SETLOGLEVELREQUEST']._serialized_end=20398
+  _globals['_SETLOGLEVELRESPONSE']._serialized_start=20400
+  _globals['_SETLOGLEVELRESPONSE']._serialized_end=20445
+  _globals['_TAILLOGSREQUEST']._serialized_start=20447
+  _globals['_TAILLOGSREQUEST']._serialized_end=20531
+  _globals['_LOGEVENT']._serialized_start=20534
+  _globals['_LOGEVENT']._serialized_end=20665
+  _globals['_DUMPSTATEREQUEST']._serialized_start=20667
+  _globals['_DUMPSTATEREQUEST']._serialized_end=20685
+  _globals['_DUMPSTATERESPONSE']._serialized_start=20688
+  _globals['_DUMPSTATERESPONSE']._serialized_end=21865
+  _globals['_DUMPSTATERESPONSE_OPERATIONCOUNTSENTRY']._serialized_start=19409
+  _globals['_DUMPSTATERESPONSE_OPERATIONCOUNTSENTRY']._serialized_end=19463
+  _globals['_TREESTORESERVICE']._serialized_start=21868
+  _globals['_TREESTORESERVICE']._serialized_end=28603
+  _globals['_TREESTOREADMIN']._serialized_start=28606
+  _globals['_TREESTOREADMIN']._serialized_end=29165
+  _globals['_SUMMARIZER']._serialized_start=29167
+  _globals['_SUMMARIZER']._serialized_end=29251
 # @@protoc_insertion_point(module_scope)
@@ -50,11 +50,41 @@ class TreeStoreServiceStub(object):
                 request_serializer=treestore__pb2.DeleteDocumentRequest.SerializeToString,
                 response_deserializer=treestore__pb2.DeleteDocumentResponse.FromString,
                 _registered_method=True)
+        self.CloneDocument = channel.unary_unary(
+                '/treestore.TreeStoreService/CloneDocument',
+                request_serializer=treestore__pb2.CloneDocumentRequest.SerializeToString,
+                response_deserializer=treestore__pb2.CloneDocumentResponse.FromString,
+                _registered_method=True)
+        self.RecomputeSectionPaths = channel.unary_unary(
+                '/treestore.TreeStoreService/RecomputeSectionPaths',
+                request_serializer=treestore__pb2.RecomputeSectionPathsRequest.SerializeToString,
+                response_deserializer=treestore__pb2.RecomputeSectionPathsResponse.FromString,
+                _registered_method=True)
+        self.ValidateDocument = channel.unary_unary(
+                '/treestore.TreeStoreService/ValidateDocument',
+                request_serializer=treestore__pb2.ValidateDocumentRequest.SerializeToString,
+                response_deserializer=treestore__pb2.ValidateDocumentResponse.FromString,
+                _registered_method=True)
+        self.FindDuplicateNodes = channel.unary_unary(
+                '/treestore.TreeStoreService/FindDuplicateNodes',
+                request_serializer=treestore__pb2.FindDuplicateNodesRequest.SerializeToString,
+                response_deserializer=treestore__pb2.FindDuplicateNodesResponse.FromString,
+                _registered_method=True)
+        self.RefreshSummaries = channel.unary_stream(
+                '/treestore.TreeStoreService/RefreshSummaries',
+                request_serializer=treestore__pb2.RefreshSummariesRequest.SerializeToString,
+                response_deserializer=treestore__pb2.RefreshSummariesProgress.FromString,
+                _registered_method=True)
         self.GetNode = channel.unary_unary(
                 '/treestore.TreeStoreService/GetNode',
                 request_serializer=treestore__pb2.GetNodeRequest.SerializeToString,
                 response_deserializer=treestore__pb2.GetNodeResponse.FromString,
                 _registered_method=True)
+        self.UpdateNode = channel.unary_unary(
+                '/treestore.TreeStoreService/UpdateNode',
+                request_serializer=treestore__pb2.UpdateNodeRequest.SerializeToString,
+                response_deserializer=treestore__pb2.UpdateNodeResponse.FromString,
+                _registered_method=True)
         self.GetChildren = channel.unary_unary(
                 '/treestore.TreeStoreService/GetChildren',
                 request_serializer=treestore__pb2.GetChildrenRequest.SerializeToString,
@@ -65,11 +95,51 @@ class TreeStoreServiceStub(object):
                 request_serializer=treestore__pb2.GetSubtreeRequest.SerializeToString,
                 response_deserializer=treestore__pb2.GetSubtreeResponse.FromString,
                 _registered_method=True)
+        self.GetSubtreeWithinBudget = channel.unary_unary(
+                '/treestore.TreeStoreService/GetSubtreeWithinBudget',
+                request_serializer=treestore__pb2.GetSubtreeWithinBudgetRequest.SerializeToString,
+                response_deserializer=treestore__pb2.GetSubtreeWithinBudgetResponse.FromString,
+                _registered_method=True)
         self.GetAncestorPath = channel.unary_unary(
                 '/treestore.TreeStoreService/GetAncestorPath',
                 request_serializer=treestore__pb2.GetAncestorPathRequest.SerializeToString,
                 response_deserializer=treestore__pb2.GetAncestorPathResponse.FromString,
                 _registered_method=True)
+        self.BatchGetAncestorPaths = channel.unary_unary(
+                '/treestore.TreeStoreService/BatchGetAncestorPaths',
+                request_serializer=treestore__pb2.BatchGetAncestorPathsRequest.SerializeToString,
+                response_deserializer=treestore__pb2.BatchGetAncestorPathsResponse.FromString,
+                _registered_method=True)
+        self.GetSiblings = channel.unary_unary(
+                '/treestore.TreeStoreService/GetSiblings',
+                request_serializer=treestore__pb2.GetSiblingsRequest.SerializeToString,
+                response_deserializer=treestore__pb2.GetSiblingsResponse.FromString,
+                _registered_method=True)
+        self.GetNextSection = channel.unary_unary(
+                '/treestore.TreeStoreService/GetNextSection',
+                request_serializer=treestore__pb2.AdjacentSectionRequest.SerializeToString,
+                response_deserializer=treestore__pb2.AdjacentSectionResponse.FromString,
+                _registered_method=True)
+        self.GetPreviousSection = channel.unary_unary(
+                '/treestore.TreeStoreService/GetPreviousSection',
+                request_serializer=treestore__pb2.AdjacentSectionRequest.SerializeToString,
+                response_deserializer=treestore__pb2.AdjacentSectionResponse.FromString,
+                _registered_method=True)
+        self.GetReadingOrder = channel.unary_stream(
+                '/treestore.TreeStoreService/GetReadingOrder',
+                request_serializer=treestore__pb2.GetReadingOrderRequest.SerializeToString,
+                response_deserializer=treestore__pb2.Node.FromString,
+                _registered_method=True)
+        self.GetContextWindow = channel.unary_unary(
+                '/treestore.TreeStoreService/GetContextWindow',
+                request_serializer=treestore__pb2.GetContextWindowRequest.SerializeToString,
+                response_deserializer=treestore__pb2.GetContextWindowResponse.FromString,
+                _registered_method=True)
+        self.ExportGraph = channel.unary_unary(
+                '/treestore.TreeStoreService/ExportGraph',
+                request_serializer=treestore__pb2.ExportGraphRequest.SerializeToString,
+                response_deserializer=treestore__pb2.ExportGraphResponse.FromString,
+                _registered_method=True)
         self.SearchByKeyword = channel.unary_unary(
                 '/treestore.TreeStoreService/SearchByKeyword',
                 request_serializer=treestore__pb2.SearchRequest.SerializeToString,
@@ -80,16 +150,56 @@ class TreeStoreServiceStub(object):
                 request_serializer=treestore__pb2.GetNodesByPageRequest.SerializeToString,
                 response_deserializer=treestore__pb2.GetNodesByPageResponse.FromString,
                 _registered_method=True)
+        self.GlobalSearch = channel.unary_unary(
+                '/treestore.TreeStoreService/GlobalSearch',
+                request_serializer=treestore__pb2.GlobalSearchRequest.SerializeToString,
+                response_deserializer=treestore__pb2.GlobalSearchResponse.FromString,
+                _registered_method=True)
+        self.JoinNodes = channel.unary_unary(
+                '/treestore.TreeStoreService/JoinNodes',
+                request_serializer=treestore__pb2.JoinNodesRequest.SerializeToString,
+                response_deserializer=treestore__pb2.JoinNodesResponse.FromString,
+                _registered_method=True)
+        self.Retrieve = channel.unary_unary(
+                '/treestore.TreeStoreService/Retrieve',
+                request_serializer=treestore__pb2.RetrieveRequest.SerializeToString,
+                response_deserializer=treestore__pb2.RetrieveResponse.FromString,
+                _registered_method=True)
         self.GetVersionAsOf = channel.unary_unary(
                 '/treestore.TreeStoreService/GetVersionAsOf',
                 request_serializer=treestore__pb2.GetVersionAsOfRequest.SerializeToString,
                 response_deserializer=treestore__pb2.PolicyVersion.FromString,
                 _registered_method=True)
+        self.BatchGetVersionsAsOf = channel.unary_unary(
+                '/treestore.TreeStoreService/BatchGetVersionsAsOf',
+                request_serializer=treestore__pb2.BatchGetVersionsAsOfRequest.SerializeToString,
+                response_deserializer=treestore__pb2.BatchGetVersionsAsOfResponse.FromString,
+                _registered_method=True)
         self.ListVersions = channel.unary_unary(
                 '/treestore.TreeStoreService/ListVersions',
                 request_serializer=treestore__pb2.ListVersionsRequest.SerializeToString,
                 response_deserializer=treestore__pb2.ListVersionsResponse.FromString,
                 _registered_method=True)
+        self.DeleteVersion = channel.unary_unary(
+                '/treestore.TreeStoreService/DeleteVersion',
+                request_serializer=treestore__pb2.DeleteVersionRequest.SerializeToString,
+                response_deserializer=treestore__pb2.DeleteVersionResponse.FromString,
+                _registered_method=True)
+        self.PruneVersions = channel.unary_unary(
+                '/treestore.TreeStoreService/PruneVersions',
+                request_serializer=treestore__pb2.PruneVersionsRequest.SerializeToString,
+                response_deserializer=treestore__pb2.PruneVersionsResponse.FromString,
+                _registered_method=True)
+        self.TagVersion = channel.unary_unary(
+                '/treestore.TreeStoreService/TagVersion',
+                request_serializer=treestore__pb2.TagVersionRequest.SerializeToString,
+                response_deserializer=treestore__pb2.TagVersionResponse.FromString,
+                _registered_method=True)
+        self.UntagVersion = channel.unary_unary(
+                '/treestore.TreeStoreService/UntagVersion',
+                request_serializer=treestore__pb2.UntagVersionRequest.SerializeToString,
+                response_deserializer=treestore__pb2.UntagVersionResponse.FromString,
+                _registered_method=True)
         self.StoreToolResult = channel.unary_unary(
                 '/treestore.TreeStoreService/StoreToolResult',
                 request_serializer=treestore__pb2.StoreToolResultRequest.SerializeToString,
@@ -110,6 +220,11 @@ class TreeStoreServiceStub(object):
                 request_serializer=treestore__pb2.GetTrajectoriesRequest.SerializeToString,
                 response_deserializer=treestore__pb2.GetTrajectoriesResponse.FromString,
                 _registered_method=True)
+        self.ReplayTrajectory = channel.unary_unary(
+                '/treestore.TreeStoreService/ReplayTrajectory',
+                request_serializer=treestore__pb2.ReplayTrajectoryRequest.SerializeToString,
+                response_deserializer=treestore__pb2.ReplayTrajectoryResponse.FromString,
+                _registered_method=True)
         self.StoreCrossReference = channel.unary_unary(
                 '/treestore.TreeStoreService/StoreCrossReference',
                 request_serializer=treestore__pb2.StoreCrossReferenceRequest.SerializeToString,
@@ -125,6 +240,36 @@ class TreeStoreServiceStub(object):
                 request_serializer=treestore__pb2.StoreContradictionRequest.SerializeToString,
                 response_deserializer=treestore__pb2.StoreContradictionResponse.FromString,
                 _registered_method=True)
+        self.BatchSetMetadata = channel.unary_unary(
+                '/treestore.TreeStoreService/BatchSetMetadata',
+                request_serializer=treestore__pb2.BatchSetMetadataRequest.SerializeToString,
+                response_deserializer=treestore__pb2.BatchSetMetadataResponse.FromString,
+                _registered_method=True)
+        self.PutCollection = channel.unary_unary(
+                '/treestore.TreeStoreService/PutCollection',
+                request_serializer=treestore__pb2.PutCollectionRequest.SerializeToString,
+                response_deserializer=treestore__pb2.PutCollectionResponse.FromString,
+                _registered_method=True)
+        self.GetCollection = channel.unary_unary(
+                '/treestore.TreeStoreService/GetCollection',
+                request_serializer=treestore__pb2.GetCollectionRequest.SerializeToString,
+                response_deserializer=treestore__pb2.GetCollectionResponse.FromString,
+                _registered_method=True)
+        self.ListCollections = channel.unary_unary(
+                '/treestore.TreeStoreService/ListCollections',
+                request_serializer=treestore__pb2.ListCollectionsRequest.SerializeToString,
+                response_deserializer=treestore__pb2.ListCollectionsResponse.FromString,
+                _registered_method=True)
+        self.UpdateCollectionMembers = channel.unary_unary(
+                '/treestore.TreeStoreService/UpdateCollectionMembers',
+                request_serializer=treestore__pb2.UpdateCollectionMembersRequest.SerializeToString,
+                response_deserializer=treestore__pb2.UpdateCollectionMembersResponse.FromString,
+                _registered_method=True)
+        self.DeleteCollection = channel.unary_unary(
+                '/treestore.TreeStoreService/DeleteCollection',
+                request_serializer=treestore__pb2.DeleteCollectionRequest.SerializeToString,
+                response_deserializer=treestore__pb2.DeleteCollectionResponse.FromString,
+                _registered_method=True)
         self.StorePrompt = channel.unary_unary(
                 '/treestore.TreeStoreService/StorePrompt',
                 request_serializer=treestore__pb2.StorePromptRequest.SerializeToString,
@@ -140,6 +285,121 @@ class TreeStoreServiceStub(object):
                 request_serializer=treestore__pb2.RecordPromptUsageRequest.SerializeToString,
                 response_deserializer=treestore__pb2.RecordPromptUsageResponse.FromString,
                 _registered_method=True)
+        self.StorePromptExperiment = channel.unary_unary(
+                '/treestore.TreeStoreService/StorePromptExperiment',
+                request_serializer=treestore__pb2.StorePromptExperimentRequest.SerializeToString,
+                response_deserializer=treestore__pb2.StorePromptExperimentResponse.FromString,
+                _registered_method=True)
+        self.GetPromptExperiment = channel.unary_unary(
+                '/treestore.TreeStoreService/GetPromptExperiment',
+                request_serializer=treestore__pb2.GetPromptExperimentRequest.SerializeToString,
+                response_deserializer=treestore__pb2.GetPromptExperimentResponse.FromString,
+                _registered_method=True)
+        self.AssignPromptVariant = channel.unary_unary(
+                '/treestore.TreeStoreService/AssignPromptVariant',
+                request_serializer=treestore__pb2.AssignPromptVariantRequest.SerializeToString,
+                response_deserializer=treestore__pb2.AssignPromptVariantResponse.FromString,
+                _registered_method=True)
+        self.LabelPromptUsage = channel.unary_unary(
+                '/treestore.TreeStoreService/LabelPromptUsage',
+                request_serializer=treestore__pb2.LabelPromptUsageRequest.SerializeToString,
+                response_deserializer=treestore__pb2.LabelPromptUsageResponse.FromString,
+                _registered_method=True)
+        self.ComparePromptVariants = channel.unary_unary(
+                '/treestore.TreeStoreService/ComparePromptVariants',
+                request_serializer=treestore__pb2.ComparePromptVariantsRequest.SerializeToString,
+                response_deserializer=treestore__pb2.ComparePromptVariantsResponse.FromString,
+                _registered_method=True)
+        self.GetMessagesPage = channel.unary_unary(
+                '/treestore.TreeStoreService/GetMessagesPage',
+                request_serializer=treestore__pb2.GetMessagesPageRequest.SerializeToString,
+                response_deserializer=treestore__pb2.GetMessagesPageResponse.FromString,
+                _registered_method=True)
+        self.GetRecentMessages = channel.unary_unary(
+                '/treestore.TreeStoreService/GetRecentMessages',
+                request_serializer=treestore__pb2.GetRecentMessagesRequest.SerializeToString,
+                response_deserializer=treestore__pb2.GetRecentMessagesResponse.FromString,
+                _registered_method=True)
+        self.SearchConversations = channel.unary_unary(
+                '/treestore.TreeStoreService/SearchConversations',
+                request_serializer=treestore__pb2.SearchConversationsRequest.SerializeToString,
+                response_deserializer=treestore__pb2.SearchConversationsResponse.FromString,
+                _registered_method=True)
+        self.ExportConversation = channel.unary_unary(
+                '/treestore.TreeStoreService/ExportConversation',
+                request_serializer=treestore__pb2.ExportConversationRequest.SerializeToString,
+                response_deserializer=treestore__pb2.ExportConversationResponse.FromString,
+                _registered_method=True)
+        self.ListConversationsByUser = channel.unary_unary(
+                '/treestore.TreeStoreService/ListConversationsByUser',
+                request_serializer=treestore__pb2.ListConversationsByUserRequest.SerializeToString,
+                response_deserializer=treestore__pb2.ListConversationsByUserResponse.FromString,
+                _registered_method=True)
+        self.GetUserConversationUsage = channel.unary_unary(
+                '/treestore.TreeStoreService/GetUserConversationUsage',
+                request_serializer=treestore__pb2.GetUserConversationUsageRequest.SerializeToString,
+                response_deserializer=treestore__pb2.GetUserConversationUsageResponse.FromString,
+                _registered_method=True)
+        self.SetUserConversationQuota = channel.unary_unary(
+                '/treestore.TreeStoreService/SetUserConversationQuota',
+                request_serializer=treestore__pb2.SetUserConversationQuotaRequest.SerializeToString,
+                response_deserializer=treestore__pb2.SetUserConversationQuotaResponse.FromString,
+                _registered_method=True)
+        self.StreamQuery = channel.unary_stream(
+                '/treestore.TreeStoreService/StreamQuery',
+                request_serializer=treestore__pb2.StreamQueryRequest.SerializeToString,
+                response_deserializer=treestore__pb2.QueryRow.FromString,
+                _registered_method=True)
+        self.SaveQuery = channel.unary_unary(
+                '/treestore.TreeStoreService/SaveQuery',
+                request_serializer=treestore__pb2.SaveQueryRequest.SerializeToString,
+                response_deserializer=treestore__pb2.SaveQueryResponse.FromString,
+                _registered_method=True)
+        self.ExecuteSavedQuery = channel.unary_unary(
+                '/treestore.TreeStoreService/ExecuteSavedQuery',
+                request_serializer=treestore__pb2.ExecuteSavedQueryRequest.SerializeToString,
+                response_deserializer=treestore__pb2.ExecuteSavedQueryResponse.FromString,
+                _registered_method=True)
+        self.RefreshSavedQuery = channel.unary_unary(
+                '/treestore.TreeStoreService/RefreshSavedQuery',
+                request_serializer=treestore__pb2.RefreshSavedQueryRequest.SerializeToString,
+                response_deserializer=treestore__pb2.RefreshSavedQueryResponse.FromString,
+                _registered_method=True)
+        self.ListSavedQueries = channel.unary_unary(
+                '/treestore.TreeStoreService/ListSavedQueries',
+                request_serializer=treestore__pb2.ListSavedQueriesRequest.SerializeToString,
+                response_deserializer=treestore__pb2.ListSavedQueriesResponse.FromString,
+                _registered_method=True)
+        self.DeleteSavedQuery = channel.unary_unary(
+                '/treestore.TreeStoreService/DeleteSavedQuery',
+                request_serializer=treestore__pb2.DeleteSavedQueryRequest.SerializeToString,
+                response_deserializer=treestore__pb2.DeleteSavedQueryResponse.FromString,
+                _registered_method=True)
+        self.WatchChanges = channel.unary_stream(
+                '/treestore.TreeStoreService/WatchChanges',
+                request_serializer=treestore__pb2.WatchChangesRequest.SerializeToString,
+                response_deserializer=treestore__pb2.ChangeEvent.FromString,
+                _registered_method=True)
+        self.StreamWAL = channel.unary_stream(
+                '/treestore.TreeStoreService/StreamWAL',
+                request_serializer=treestore__pb2.StreamWALRequest.SerializeToString,
+                response_deserializer=treestore__pb2.WALEntry.FromString,
+                _registered_method=True)
+        self.QueryAuditLog = channel.unary_unary(
+                '/treestore.TreeStoreService/QueryAuditLog',
+                request_serializer=treestore__pb2.QueryAuditLogRequest.SerializeToString,
+                response_deserializer=treestore__pb2.QueryAuditLogResponse.FromString,
+                _registered_method=True)
+        self.ListJobRuns = channel.unary_unary(
+                '/treestore.TreeStoreService/ListJobRuns',
+                request_serializer=treestore__pb2.ListJobRunsRequest.SerializeToString,
+                response_deserializer=treestore__pb2.ListJobRunsResponse.FromString,
+                _registered_method=True)
+        self.TriggerJob = channel.unary_unary(
+                '/treestore.TreeStoreService/TriggerJob',
+                request_serializer=treestore__pb2.TriggerJobRequest.SerializeToString,
+                response_deserializer=treestore__pb2.TriggerJobResponse.FromString,
+                _registered_method=True)
         self.Health = channel.unary_unary(
                 '/treestore.TreeStoreService/Health',
                 request_serializer=treestore__pb2.HealthRequest.SerializeToString,
@@ -150,6 +410,11 @@ class TreeStoreServiceStub(object):
                 request_serializer=treestore__pb2.StatsRequest.SerializeToString,
                 response_deserializer=treestore__pb2.StatsResponse.FromString,
                 _registered_method=True)
+        self.StorageBreakdown = channel.unary_unary(
+                '/treestore.TreeStoreService/StorageBreakdown',
+                request_serializer=treestore__pb2.StorageBreakdownRequest.SerializeToString,
+                response_deserializer=treestore__pb2.StorageBreakdownResponse.FromString,
+                _registered_method=True)
 
 
 class TreeStoreServiceServicer(object):
@@ -157,7 +422,7 @@ class TreeStoreServiceServicer(object):
     """
 
     def StoreDocument(self, request, context):
-        """========== Document Operations (3 methods) ==========
+        """========== Document Operations (8 methods) ==========
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
@@ -175,13 +440,49 @@ class TreeStoreServiceServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def CloneDocument(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RecomputeSectionPaths(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ValidateDocument(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def FindDuplicateNodes(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RefreshSummaries(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def GetNode(self, request, context):
-        """========== Node Operations (4 methods) ==========
+        """========== Node Operations (13 methods) ==========
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def UpdateNode(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def GetChildren(self, request, context):
         """Missing associated documentation comment in .proto file."""
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
@@ -194,14 +495,62 @@ class TreeStoreServiceServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def GetSubtreeWithinBudget(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def GetAncestorPath(self, request, context):
         """Missing associated documentation comment in .proto file."""
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def BatchGetAncestorPaths(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetSiblings(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetNextSection(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetPreviousSection(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetReadingOrder(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetContextWindow(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ExportGraph(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def SearchByKeyword(self, request, context):
-        """========== Search Operations (2 methods) ==========
+        """========== Search Operations (5 methods) ==========
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
@@ -213,21 +562,69 @@ class TreeStoreServiceServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def GlobalSearch(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def JoinNodes(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Retrieve(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def GetVersionAsOf(self, request, context):
-        """========== Version Operations (2 methods) ==========
+        """========== Version Operations (7 methods) ==========
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def BatchGetVersionsAsOf(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def ListVersions(self, request, context):
         """Missing associated documentation comment in .proto file."""
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def DeleteVersion(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def PruneVersions(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def TagVersion(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def UntagVersion(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def StoreToolResult(self, request, context):
-        """========== Metadata Operations (7 methods) ==========
+        """========== Metadata Operations (8 methods) ==========
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
@@ -251,6 +648,12 @@ class TreeStoreServiceServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def ReplayTrajectory(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def StoreCrossReference(self, request, context):
         """Missing associated documentation comment in .proto file."""
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
@@ -269,8 +672,45 @@ class TreeStoreServiceServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def BatchSetMetadata(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def PutCollection(self, request, context):
+        """========== Collection Operations (5 methods) ==========
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetCollection(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListCollections(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def UpdateCollectionMembers(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeleteCollection(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def StorePrompt(self, request, context):
-        """========== Prompt Operations (3 methods) ==========
+        """========== Prompt Operations (8 methods) ==========
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
@@ -288,57 +728,277 @@ class TreeStoreServiceServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
-    def Health(self, request, context):
-        """========== Health & Status (2 methods) ==========
+    def StorePromptExperiment(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetPromptExperiment(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def AssignPromptVariant(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def LabelPromptUsage(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ComparePromptVariants(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetMessagesPage(self, request, context):
+        """========== Conversation Operations (7 methods) ==========
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
-    def Stats(self, request, context):
+    def GetRecentMessages(self, request, context):
         """Missing associated documentation comment in .proto file."""
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def SearchConversations(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
 
-def add_TreeStoreServiceServicer_to_server(servicer, server):
-    rpc_method_handlers = {
-            'StoreDocument': grpc.unary_unary_rpc_method_handler(
-                    servicer.StoreDocument,
-                    request_deserializer=treestore__pb2.StoreDocumentRequest.FromString,
-                    response_serializer=treestore__pb2.StoreDocumentResponse.SerializeToString,
-            ),
-            'GetDocument': grpc.unary_unary_rpc_method_handler(
-                    servicer.GetDocument,
-                    request_deserializer=treestore__pb2.GetDocumentRequest.FromString,
-                    response_serializer=treestore__pb2.GetDocumentResponse.SerializeToString,
-            ),
-            'DeleteDocument': grpc.unary_unary_rpc_method_handler(
-                    servicer.DeleteDocument,
-                    request_deserializer=treestore__pb2.DeleteDocumentRequest.FromString,
-                    response_serializer=treestore__pb2.DeleteDocumentResponse.SerializeToString,
-            ),
-            'GetNode': grpc.unary_unary_rpc_method_handler(
-                    servicer.GetNode,
-                    request_deserializer=treestore__pb2.GetNodeRequest.FromString,
-                    response_serializer=treestore__pb2.GetNodeResponse.SerializeToString,
-            ),
-            'GetChildren': grpc.unary_unary_rpc_method_handler(
-                    servicer.GetChildren,
-                    request_deserializer=treestore__pb2.GetChildrenRequest.FromString,
-                    response_serializer=treestore__pb2.GetChildrenResponse.SerializeToString,
-            ),
+    def ExportConversation(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListConversationsByUser(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetUserConversationUsage(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SetUserConversationQuota(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def StreamQuery(self, request, context):
+        """========== Query Operations (6 methods) ==========
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SaveQuery(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ExecuteSavedQuery(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RefreshSavedQuery(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListSavedQueries(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeleteSavedQuery(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def WatchChanges(self, request, context):
+        """========== Change Feed (1 method) ==========
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def StreamWAL(self, request, context):
+        """========== Replication (1 method) ==========
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def QueryAuditLog(self, request, context):
+        """========== Audit (1 method) ==========
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListJobRuns(self, request, context):
+        """========== Analysis Jobs (2 methods) ==========
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def TriggerJob(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Health(self, request, context):
+        """========== Health & Status (3 methods) ==========
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Stats(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def StorageBreakdown(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+
+def add_TreeStoreServiceServicer_to_server(servicer, server):
+    rpc_method_handlers = {
+            'StoreDocument': grpc.unary_unary_rpc_method_handler(
+                    servicer.StoreDocument,
+                    request_deserializer=treestore__pb2.StoreDocumentRequest.FromString,
+                    response_serializer=treestore__pb2.StoreDocumentResponse.SerializeToString,
+            ),
+            'GetDocument': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetDocument,
+                    request_deserializer=treestore__pb2.GetDocumentRequest.FromString,
+                    response_serializer=treestore__pb2.GetDocumentResponse.SerializeToString,
+            ),
+            'DeleteDocument': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteDocument,
+                    request_deserializer=treestore__pb2.DeleteDocumentRequest.FromString,
+                    response_serializer=treestore__pb2.DeleteDocumentResponse.SerializeToString,
+            ),
+            'CloneDocument': grpc.unary_unary_rpc_method_handler(
+                    servicer.CloneDocument,
+                    request_deserializer=treestore__pb2.CloneDocumentRequest.FromString,
+                    response_serializer=treestore__pb2.CloneDocumentResponse.SerializeToString,
+            ),
+            'RecomputeSectionPaths': grpc.unary_unary_rpc_method_handler(
+                    servicer.RecomputeSectionPaths,
+                    request_deserializer=treestore__pb2.RecomputeSectionPathsRequest.FromString,
+                    response_serializer=treestore__pb2.RecomputeSectionPathsResponse.SerializeToString,
+            ),
+            'ValidateDocument': grpc.unary_unary_rpc_method_handler(
+                    servicer.ValidateDocument,
+                    request_deserializer=treestore__pb2.ValidateDocumentRequest.FromString,
+                    response_serializer=treestore__pb2.ValidateDocumentResponse.SerializeToString,
+            ),
+            'FindDuplicateNodes': grpc.unary_unary_rpc_method_handler(
+                    servicer.FindDuplicateNodes,
+                    request_deserializer=treestore__pb2.FindDuplicateNodesRequest.FromString,
+                    response_serializer=treestore__pb2.FindDuplicateNodesResponse.SerializeToString,
+            ),
+            'RefreshSummaries': grpc.unary_stream_rpc_method_handler(
+                    servicer.RefreshSummaries,
+                    request_deserializer=treestore__pb2.RefreshSummariesRequest.FromString,
+                    response_serializer=treestore__pb2.RefreshSummariesProgress.SerializeToString,
+            ),
+            'GetNode': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetNode,
+                    request_deserializer=treestore__pb2.GetNodeRequest.FromString,
+                    response_serializer=treestore__pb2.GetNodeResponse.SerializeToString,
+            ),
+            'UpdateNode': grpc.unary_unary_rpc_method_handler(
+                    servicer.UpdateNode,
+                    request_deserializer=treestore__pb2.UpdateNodeRequest.FromString,
+                    response_serializer=treestore__pb2.UpdateNodeResponse.SerializeToString,
+            ),
+            'GetChildren': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetChildren,
+                    request_deserializer=treestore__pb2.GetChildrenRequest.FromString,
+                    response_serializer=treestore__pb2.GetChildrenResponse.SerializeToString,
+            ),
             'GetSubtree': grpc.unary_unary_rpc_method_handler(
                     servicer.GetSubtree,
                     request_deserializer=treestore__pb2.GetSubtreeRequest.FromString,
                     response_serializer=treestore__pb2.GetSubtreeResponse.SerializeToString,
             ),
+            'GetSubtreeWithinBudget': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetSubtreeWithinBudget,
+                    request_deserializer=treestore__pb2.GetSubtreeWithinBudgetRequest.FromString,
+                    response_serializer=treestore__pb2.GetSubtreeWithinBudgetResponse.SerializeToString,
+            ),
             'GetAncestorPath': grpc.unary_unary_rpc_method_handler(
                     servicer.GetAncestorPath,
                     request_deserializer=treestore__pb2.GetAncestorPathRequest.FromString,
                     response_serializer=treestore__pb2.GetAncestorPathResponse.SerializeToString,
             ),
+            'BatchGetAncestorPaths': grpc.unary_unary_rpc_method_handler(
+                    servicer.BatchGetAncestorPaths,
+                    request_deserializer=treestore__pb2.BatchGetAncestorPathsRequest.FromString,
+                    response_serializer=treestore__pb2.BatchGetAncestorPathsResponse.SerializeToString,
+            ),
+            'GetSiblings': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetSiblings,
+                    request_deserializer=treestore__pb2.GetSiblingsRequest.FromString,
+                    response_serializer=treestore__pb2.GetSiblingsResponse.SerializeToString,
+            ),
+            'GetNextSection': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetNextSection,
+                    request_deserializer=treestore__pb2.AdjacentSectionRequest.FromString,
+                    response_serializer=treestore__pb2.AdjacentSectionResponse.SerializeToString,
+            ),
+            'GetPreviousSection': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetPreviousSection,
+                    request_deserializer=treestore__pb2.AdjacentSectionRequest.FromString,
+                    response_serializer=treestore__pb2.AdjacentSectionResponse.SerializeToString,
+            ),
+            'GetReadingOrder': grpc.unary_stream_rpc_method_handler(
+                    servicer.GetReadingOrder,
+                    request_deserializer=treestore__pb2.GetReadingOrderRequest.FromString,
+                    response_serializer=treestore__pb2.Node.SerializeToString,
+            ),
+            'GetContextWindow': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetContextWindow,
+                    request_deserializer=treestore__pb2.GetContextWindowRequest.FromString,
+                    response_serializer=treestore__pb2.GetContextWindowResponse.SerializeToString,
+            ),
+            'ExportGraph': grpc.unary_unary_rpc_method_handler(
+                    servicer.ExportGraph,
+                    request_deserializer=treestore__pb2.ExportGraphRequest.FromString,
+                    response_serializer=treestore__pb2.ExportGraphResponse.SerializeToString,
+            ),
             'SearchByKeyword': grpc.unary_unary_rpc_method_handler(
                     servicer.SearchByKeyword,
                     request_deserializer=treestore__pb2.SearchRequest.FromString,
@@ -349,16 +1009,56 @@ def add_TreeStoreServiceServicer_to_server(servicer, server):
                     request_deserializer=treestore__pb2.GetNodesByPageRequest.FromString,
                     response_serializer=treestore__pb2.GetNodesByPageResponse.SerializeToString,
             ),
+            'GlobalSearch': grpc.unary_unary_rpc_method_handler(
+                    servicer.GlobalSearch,
+                    request_deserializer=treestore__pb2.GlobalSearchRequest.FromString,
+                    response_serializer=treestore__pb2.GlobalSearchResponse.SerializeToString,
+            ),
+            'JoinNodes': grpc.unary_unary_rpc_method_handler(
+                    servicer.JoinNodes,
+                    request_deserializer=treestore__pb2.JoinNodesRequest.FromString,
+                    response_serializer=treestore__pb2.JoinNodesResponse.SerializeToString,
+            ),
+            'Retrieve': grpc.unary_unary_rpc_method_handler(
+                    servicer.Retrieve,
+                    request_deserializer=treestore__pb2.RetrieveRequest.FromString,
+                    response_serializer=treestore__pb2.RetrieveResponse.SerializeToString,
+            ),
             'GetVersionAsOf': grpc.unary_unary_rpc_method_handler(
                     servicer.GetVersionAsOf,
                     request_deserializer=treestore__pb2.GetVersionAsOfRequest.FromString,
                     response_serializer=treestore__pb2.PolicyVersion.SerializeToString,
             ),
+            'BatchGetVersionsAsOf': grpc.unary_unary_rpc_method_handler(
+                    servicer.BatchGetVersionsAsOf,
+                    request_deserializer=treestore__pb2.BatchGetVersionsAsOfRequest.FromString,
+                    response_serializer=treestore__pb2.BatchGetVersionsAsOfResponse.SerializeToString,
+            ),
             'ListVersions': grpc.unary_unary_rpc_method_handler(
                     servicer.ListVersions,
                     request_deserializer=treestore__pb2.ListVersionsRequest.FromString,
                     response_serializer=treestore__pb2.ListVersionsResponse.SerializeToString,
             ),
+            'DeleteVersion': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteVersion,
+                    request_deserializer=treestore__pb2.DeleteVersionRequest.FromString,
+                    response_serializer=treestore__pb2.DeleteVersionResponse.SerializeToString,
+            ),
+            'PruneVersions': grpc.unary_unary_rpc_method_handler(
+                    servicer.PruneVersions,
+                    request_deserializer=treestore__pb2.PruneVersionsRequest.FromString,
+                    response_serializer=treestore__pb2.PruneVersionsResponse.SerializeToString,
+            ),
+            'TagVersion': grpc.unary_unary_rpc_method_handler(
+                    servicer.TagVersion,
+                    request_deserializer=treestore__pb2.TagVersionRequest.FromString,
+                    response_serializer=treestore__pb2.TagVersionResponse.SerializeToString,
+            ),
+            'UntagVersion': grpc.unary_unary_rpc_method_handler(
+                    servicer.UntagVersion,
+                    request_deserializer=treestore__pb2.UntagVersionRequest.FromString,
+                    response_serializer=treestore__pb2.UntagVersionResponse.SerializeToString,
+            ),
             'StoreToolResult': grpc.unary_unary_rpc_method_handler(
                     servicer.StoreToolResult,
                     request_deserializer=treestore__pb2.StoreToolResultRequest.FromString,
@@ -379,6 +1079,11 @@ def add_TreeStoreServiceServicer_to_server(servicer, server):
                     request_deserializer=treestore__pb2.GetTrajectoriesRequest.FromString,
                     response_serializer=treestore__pb2.GetTrajectoriesResponse.SerializeToString,
             ),
+            'ReplayTrajectory': grpc.unary_unary_rpc_method_handler(
+                    servicer.ReplayTrajectory,
+                    request_deserializer=treestore__pb2.ReplayTrajectoryRequest.FromString,
+                    response_serializer=treestore__pb2.ReplayTrajectoryResponse.SerializeToString,
+            ),
             'StoreCrossReference': grpc.unary_unary_rpc_method_handler(
                     servicer.StoreCrossReference,
                     request_deserializer=treestore__pb2.StoreCrossReferenceRequest.FromString,
@@ -394,6 +1099,36 @@ def add_TreeStoreServiceServicer_to_server(servicer, server):
                     request_deserializer=treestore__pb2.StoreContradictionRequest.FromString,
                     response_serializer=treestore__pb2.StoreContradictionResponse.SerializeToString,
             ),
+            'BatchSetMetadata': grpc.unary_unary_rpc_method_handler(
+                    servicer.BatchSetMetadata,
+                    request_deserializer=treestore__pb2.BatchSetMetadataRequest.FromString,
+                    response_serializer=treestore__pb2.BatchSetMetadataResponse.SerializeToString,
+            ),
+            'PutCollection': grpc.unary_unary_rpc_method_handler(
+                    servicer.PutCollection,
+                    request_deserializer=treestore__pb2.PutCollectionRequest.FromString,
+                    response_serializer=treestore__pb2.PutCollectionResponse.SerializeToString,
+            ),
+            'GetCollection': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetCollection,
+                    request_deserializer=treestore__pb2.GetCollectionRequest.FromString,
+                    response_serializer=treestore__pb2.GetCollectionResponse.SerializeToString,
+            ),
+            'ListCollections': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListCollections,
+                    request_deserializer=treestore__pb2.ListCollectionsRequest.FromString,
+                    response_serializer=treestore__pb2.ListCollectionsResponse.SerializeToString,
+            ),
+            'UpdateCollectionMembers': grpc.unary_unary_rpc_method_handler(
+                    servicer.UpdateCollectionMembers,
+                    request_deserializer=treestore__pb2.UpdateCollectionMembersRequest.FromString,
+                    response_serializer=treestore__pb2.UpdateCollectionMembersResponse.SerializeToString,
+            ),
+            'DeleteCollection': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteCollection,
+                    request_deserializer=treestore__pb2.DeleteCollectionRequest.FromString,
+                    response_serializer=treestore__pb2.DeleteCollectionResponse.SerializeToString,
+            ),
             'StorePrompt': grpc.unary_unary_rpc_method_handler(
                     servicer.StorePrompt,
                     request_deserializer=treestore__pb2.StorePromptRequest.FromString,
@@ -409,6 +1144,121 @@ def add_TreeStoreServiceServicer_to_server(servicer, server):
                     request_deserializer=treestore__pb2.RecordPromptUsageRequest.FromString,
                     response_serializer=treestore__pb2.RecordPromptUsageResponse.SerializeToString,
             ),
+            'StorePromptExperiment': grpc.unary_unary_rpc_method_handler(
+                    servicer.StorePromptExperiment,
+                    request_deserializer=treestore__pb2.StorePromptExperimentRequest.FromString,
+                    response_serializer=treestore__pb2.StorePromptExperimentResponse.SerializeToString,
+            ),
+            'GetPromptExperiment': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetPromptExperiment,
+                    request_deserializer=treestore__pb2.GetPromptExperimentRequest.FromString,
+                    response_serializer=treestore__pb2.GetPromptExperimentResponse.SerializeToString,
+            ),
+            'AssignPromptVariant': grpc.unary_unary_rpc_method_handler(
+                    servicer.AssignPromptVariant,
+                    request_deserializer=treestore__pb2.AssignPromptVariantRequest.FromString,
+                    response_serializer=treestore__pb2.AssignPromptVariantResponse.SerializeToString,
+            ),
+            'LabelPromptUsage': grpc.unary_unary_rpc_method_handler(
+                    servicer.LabelPromptUsage,
+                    request_deserializer=treestore__pb2.LabelPromptUsageRequest.FromString,
+                    response_serializer=treestore__pb2.LabelPromptUsageResponse.SerializeToString,
+            ),
+            'ComparePromptVariants': grpc.unary_unary_rpc_method_handler(
+                    servicer.ComparePromptVariants,
+                    request_deserializer=treestore__pb2.ComparePromptVariantsRequest.FromString,
+                    response_serializer=treestore__pb2.ComparePromptVariantsResponse.SerializeToString,
+            ),
+            'GetMessagesPage': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetMessagesPage,
+                    request_deserializer=treestore__pb2.GetMessagesPageRequest.FromString,
+                    response_serializer=treestore__pb2.GetMessagesPageResponse.SerializeToString,
+            ),
+            'GetRecentMessages': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetRecentMessages,
+                    request_deserializer=treestore__pb2.GetRecentMessagesRequest.FromString,
+                    response_serializer=treestore__pb2.GetRecentMessagesResponse.SerializeToString,
+            ),
+            'SearchConversations': grpc.unary_unary_rpc_method_handler(
+                    servicer.SearchConversations,
+                    request_deserializer=treestore__pb2.SearchConversationsRequest.FromString,
+                    response_serializer=treestore__pb2.SearchConversationsResponse.SerializeToString,
+            ),
+            'ExportConversation': grpc.unary_unary_rpc_method_handler(
+                    servicer.ExportConversation,
+                    request_deserializer=treestore__pb2.ExportConversationRequest.FromString,
+                    response_serializer=treestore__pb2.ExportConversationResponse.SerializeToString,
+            ),
+            'ListConversationsByUser': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListConversationsByUser,
+                    request_deserializer=treestore__pb2.ListConversationsByUserRequest.FromString,
+                    response_serializer=treestore__pb2.ListConversationsByUserResponse.SerializeToString,
+            ),
+            'GetUserConversationUsage': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetUserConversationUsage,
+                    request_deserializer=treestore__pb2.GetUserConversationUsageRequest.FromString,
+                    response_serializer=treestore__pb2.GetUserConversationUsageResponse.SerializeToString,
+            ),
+            'SetUserConversationQuota': grpc.unary_unary_rpc_method_handler(
+                    servicer.SetUserConversationQuota,
+                    request_deserializer=treestore__pb2.SetUserConversationQuotaRequest.FromString,
+                    response_serializer=treestore__pb2.SetUserConversationQuotaResponse.SerializeToString,
+            ),
+            'StreamQuery': grpc.unary_stream_rpc_method_handler(
+                    servicer.StreamQuery,
+                    request_deserializer=treestore__pb2.StreamQueryRequest.FromString,
+                    response_serializer=treestore__pb2.QueryRow.SerializeToString,
+            ),
+            'SaveQuery': grpc.unary_unary_rpc_method_handler(
+                    servicer.SaveQuery,
+                    request_deserializer=treestore__pb2.SaveQueryRequest.FromString,
+                    response_serializer=treestore__pb2.SaveQueryResponse.SerializeToString,
+            ),
+            'ExecuteSavedQuery': grpc.unary_unary_rpc_method_handler(
+                    servicer.ExecuteSavedQuery,
+                    request_deserializer=treestore__pb2.ExecuteSavedQueryRequest.FromString,
+                    response_serializer=treestore__pb2.ExecuteSavedQueryResponse.SerializeToString,
+            ),
+            'RefreshSavedQuery': grpc.unary_unary_rpc_method_handler(
+                    servicer.RefreshSavedQuery,
+                    request_deserializer=treestore__pb2.RefreshSavedQueryRequest.FromString,
+                    response_serializer=treestore__pb2.RefreshSavedQueryResponse.SerializeToString,
+            ),
+            'ListSavedQueries': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListSavedQueries,
+                    request_deserializer=treestore__pb2.ListSavedQueriesRequest.FromString,
+                    response_serializer=treestore__pb2.ListSavedQueriesResponse.SerializeToString,
+            ),
+            'DeleteSavedQuery': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteSavedQuery,
+                    request_deserializer=treestore__pb2.DeleteSavedQueryRequest.FromString,
+                    response_serializer=treestore__pb2.DeleteSavedQueryResponse.SerializeToString,
+            ),
+            'WatchChanges': grpc.unary_stream_rpc_method_handler(
+                    servicer.WatchChanges,
+                    request_deserializer=treestore__pb2.WatchChangesRequest.FromString,
+                    response_serializer=treestore__pb2.ChangeEvent.SerializeToString,
+            ),
+            'StreamWAL': grpc.unary_stream_rpc_method_handler(
+                    servicer.StreamWAL,
+                    request_deserializer=treestore__pb2.StreamWALRequest.FromString,
+                    response_serializer=treestore__pb2.WALEntry.SerializeToString,
+            ),
+            'QueryAuditLog': grpc.unary_unary_rpc_method_handler(
+                    servicer.QueryAuditLog,
+                    request_deserializer=treestore__pb2.QueryAuditLogRequest.FromString,
+                    response_serializer=treestore__pb2.QueryAuditLogResponse.SerializeToString,
+            ),
+            'ListJobRuns': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListJobRuns,
+                    request_deserializer=treestore__pb2.ListJobRunsRequest.FromString,
+                    response_serializer=treestore__pb2.ListJobRunsResponse.SerializeToString,
+            ),
+            'TriggerJob': grpc.unary_unary_rpc_method_handler(
+                    servicer.TriggerJob,
+                    request_deserializer=treestore__pb2.TriggerJobRequest.FromString,
+                    response_serializer=treestore__pb2.TriggerJobResponse.SerializeToString,
+            ),
             'Health': grpc.unary_unary_rpc_method_handler(
                     servicer.Health,
                     request_deserializer=treestore__pb2.HealthRequest.FromString,
@@ -419,6 +1269,11 @@ def add_TreeStoreServiceServicer_to_server(servicer, server):
                     request_deserializer=treestore__pb2.StatsRequest.FromString,
                     response_serializer=treestore__pb2.StatsResponse.SerializeToString,
             ),
+            'StorageBreakdown': grpc.unary_unary_rpc_method_handler(
+                    servicer.StorageBreakdown,
+                    request_deserializer=treestore__pb2.StorageBreakdownRequest.FromString,
+                    response_serializer=treestore__pb2.StorageBreakdownResponse.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'treestore.TreeStoreService', rpc_method_handlers)
@@ -432,7 +1287,1627 @@ class TreeStoreService(object):
     """
 
     @staticmethod
-    def StoreDocument(request,
+    def StoreDocument(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/StoreDocument',
+            treestore__pb2.StoreDocumentRequest.SerializeToString,
+            treestore__pb2.StoreDocumentResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetDocument(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetDocument',
+            treestore__pb2.GetDocumentRequest.SerializeToString,
+            treestore__pb2.GetDocumentResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def DeleteDocument(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/DeleteDocument',
+            treestore__pb2.DeleteDocumentRequest.SerializeToString,
+            treestore__pb2.DeleteDocumentResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def CloneDocument(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/CloneDocument',
+            treestore__pb2.CloneDocumentRequest.SerializeToString,
+            treestore__pb2.CloneDocumentResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def RecomputeSectionPaths(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/RecomputeSectionPaths',
+            treestore__pb2.RecomputeSectionPathsRequest.SerializeToString,
+            treestore__pb2.RecomputeSectionPathsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ValidateDocument(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/ValidateDocument',
+            treestore__pb2.ValidateDocumentRequest.SerializeToString,
+            treestore__pb2.ValidateDocumentResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def FindDuplicateNodes(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/FindDuplicateNodes',
+            treestore__pb2.FindDuplicateNodesRequest.SerializeToString,
+            treestore__pb2.FindDuplicateNodesResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def RefreshSummaries(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(
+            request,
+            target,
+            '/treestore.TreeStoreService/RefreshSummaries',
+            treestore__pb2.RefreshSummariesRequest.SerializeToString,
+            treestore__pb2.RefreshSummariesProgress.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetNode(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetNode',
+            treestore__pb2.GetNodeRequest.SerializeToString,
+            treestore__pb2.GetNodeResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def UpdateNode(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/UpdateNode',
+            treestore__pb2.UpdateNodeRequest.SerializeToString,
+            treestore__pb2.UpdateNodeResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetChildren(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetChildren',
+            treestore__pb2.GetChildrenRequest.SerializeToString,
+            treestore__pb2.GetChildrenResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetSubtree(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetSubtree',
+            treestore__pb2.GetSubtreeRequest.SerializeToString,
+            treestore__pb2.GetSubtreeResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetSubtreeWithinBudget(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetSubtreeWithinBudget',
+            treestore__pb2.GetSubtreeWithinBudgetRequest.SerializeToString,
+            treestore__pb2.GetSubtreeWithinBudgetResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetAncestorPath(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetAncestorPath',
+            treestore__pb2.GetAncestorPathRequest.SerializeToString,
+            treestore__pb2.GetAncestorPathResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def BatchGetAncestorPaths(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/BatchGetAncestorPaths',
+            treestore__pb2.BatchGetAncestorPathsRequest.SerializeToString,
+            treestore__pb2.BatchGetAncestorPathsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetSiblings(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetSiblings',
+            treestore__pb2.GetSiblingsRequest.SerializeToString,
+            treestore__pb2.GetSiblingsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetNextSection(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetNextSection',
+            treestore__pb2.AdjacentSectionRequest.SerializeToString,
+            treestore__pb2.AdjacentSectionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetPreviousSection(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetPreviousSection',
+            treestore__pb2.AdjacentSectionRequest.SerializeToString,
+            treestore__pb2.AdjacentSectionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetReadingOrder(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetReadingOrder',
+            treestore__pb2.GetReadingOrderRequest.SerializeToString,
+            treestore__pb2.Node.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetContextWindow(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetContextWindow',
+            treestore__pb2.GetContextWindowRequest.SerializeToString,
+            treestore__pb2.GetContextWindowResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ExportGraph(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/ExportGraph',
+            treestore__pb2.ExportGraphRequest.SerializeToString,
+            treestore__pb2.ExportGraphResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def SearchByKeyword(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/SearchByKeyword',
+            treestore__pb2.SearchRequest.SerializeToString,
+            treestore__pb2.SearchResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetNodesByPage(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetNodesByPage',
+            treestore__pb2.GetNodesByPageRequest.SerializeToString,
+            treestore__pb2.GetNodesByPageResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GlobalSearch(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GlobalSearch',
+            treestore__pb2.GlobalSearchRequest.SerializeToString,
+            treestore__pb2.GlobalSearchResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def JoinNodes(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/JoinNodes',
+            treestore__pb2.JoinNodesRequest.SerializeToString,
+            treestore__pb2.JoinNodesResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def Retrieve(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/Retrieve',
+            treestore__pb2.RetrieveRequest.SerializeToString,
+            treestore__pb2.RetrieveResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetVersionAsOf(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetVersionAsOf',
+            treestore__pb2.GetVersionAsOfRequest.SerializeToString,
+            treestore__pb2.PolicyVersion.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def BatchGetVersionsAsOf(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/BatchGetVersionsAsOf',
+            treestore__pb2.BatchGetVersionsAsOfRequest.SerializeToString,
+            treestore__pb2.BatchGetVersionsAsOfResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ListVersions(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/ListVersions',
+            treestore__pb2.ListVersionsRequest.SerializeToString,
+            treestore__pb2.ListVersionsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def DeleteVersion(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/DeleteVersion',
+            treestore__pb2.DeleteVersionRequest.SerializeToString,
+            treestore__pb2.DeleteVersionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def PruneVersions(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/PruneVersions',
+            treestore__pb2.PruneVersionsRequest.SerializeToString,
+            treestore__pb2.PruneVersionsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def TagVersion(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/TagVersion',
+            treestore__pb2.TagVersionRequest.SerializeToString,
+            treestore__pb2.TagVersionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def UntagVersion(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/UntagVersion',
+            treestore__pb2.UntagVersionRequest.SerializeToString,
+            treestore__pb2.UntagVersionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def StoreToolResult(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/StoreToolResult',
+            treestore__pb2.StoreToolResultRequest.SerializeToString,
+            treestore__pb2.StoreToolResultResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetToolResults(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetToolResults',
+            treestore__pb2.GetToolResultsRequest.SerializeToString,
+            treestore__pb2.GetToolResultsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def StoreTrajectory(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/StoreTrajectory',
+            treestore__pb2.StoreTrajectoryRequest.SerializeToString,
+            treestore__pb2.StoreTrajectoryResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetTrajectories(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetTrajectories',
+            treestore__pb2.GetTrajectoriesRequest.SerializeToString,
+            treestore__pb2.GetTrajectoriesResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ReplayTrajectory(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/ReplayTrajectory',
+            treestore__pb2.ReplayTrajectoryRequest.SerializeToString,
+            treestore__pb2.ReplayTrajectoryResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def StoreCrossReference(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/StoreCrossReference',
+            treestore__pb2.StoreCrossReferenceRequest.SerializeToString,
+            treestore__pb2.StoreCrossReferenceResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetCrossReferences(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetCrossReferences',
+            treestore__pb2.GetCrossReferencesRequest.SerializeToString,
+            treestore__pb2.GetCrossReferencesResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def StoreContradiction(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/StoreContradiction',
+            treestore__pb2.StoreContradictionRequest.SerializeToString,
+            treestore__pb2.StoreContradictionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def BatchSetMetadata(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/BatchSetMetadata',
+            treestore__pb2.BatchSetMetadataRequest.SerializeToString,
+            treestore__pb2.BatchSetMetadataResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def PutCollection(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/PutCollection',
+            treestore__pb2.PutCollectionRequest.SerializeToString,
+            treestore__pb2.PutCollectionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetCollection(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetCollection',
+            treestore__pb2.GetCollectionRequest.SerializeToString,
+            treestore__pb2.GetCollectionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ListCollections(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/ListCollections',
+            treestore__pb2.ListCollectionsRequest.SerializeToString,
+            treestore__pb2.ListCollectionsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def UpdateCollectionMembers(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/UpdateCollectionMembers',
+            treestore__pb2.UpdateCollectionMembersRequest.SerializeToString,
+            treestore__pb2.UpdateCollectionMembersResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def DeleteCollection(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/DeleteCollection',
+            treestore__pb2.DeleteCollectionRequest.SerializeToString,
+            treestore__pb2.DeleteCollectionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def StorePrompt(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/StorePrompt',
+            treestore__pb2.StorePromptRequest.SerializeToString,
+            treestore__pb2.StorePromptResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetPrompt(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetPrompt',
+            treestore__pb2.GetPromptRequest.SerializeToString,
+            treestore__pb2.GetPromptResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def RecordPromptUsage(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/RecordPromptUsage',
+            treestore__pb2.RecordPromptUsageRequest.SerializeToString,
+            treestore__pb2.RecordPromptUsageResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def StorePromptExperiment(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/StorePromptExperiment',
+            treestore__pb2.StorePromptExperimentRequest.SerializeToString,
+            treestore__pb2.StorePromptExperimentResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetPromptExperiment(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetPromptExperiment',
+            treestore__pb2.GetPromptExperimentRequest.SerializeToString,
+            treestore__pb2.GetPromptExperimentResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def AssignPromptVariant(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/AssignPromptVariant',
+            treestore__pb2.AssignPromptVariantRequest.SerializeToString,
+            treestore__pb2.AssignPromptVariantResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def LabelPromptUsage(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/LabelPromptUsage',
+            treestore__pb2.LabelPromptUsageRequest.SerializeToString,
+            treestore__pb2.LabelPromptUsageResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ComparePromptVariants(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/ComparePromptVariants',
+            treestore__pb2.ComparePromptVariantsRequest.SerializeToString,
+            treestore__pb2.ComparePromptVariantsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetMessagesPage(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetMessagesPage',
+            treestore__pb2.GetMessagesPageRequest.SerializeToString,
+            treestore__pb2.GetMessagesPageResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetRecentMessages(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/GetRecentMessages',
+            treestore__pb2.GetRecentMessagesRequest.SerializeToString,
+            treestore__pb2.GetRecentMessagesResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def SearchConversations(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/SearchConversations',
+            treestore__pb2.SearchConversationsRequest.SerializeToString,
+            treestore__pb2.SearchConversationsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ExportConversation(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/ExportConversation',
+            treestore__pb2.ExportConversationRequest.SerializeToString,
+            treestore__pb2.ExportConversationResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ListConversationsByUser(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/treestore.TreeStoreService/ListConversationsByUser',
+            treestore__pb2.ListConversationsByUserRequest.SerializeToString,
+            treestore__pb2.ListConversationsByUserResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetUserConversationUsage(request,
             target,
             options=(),
             channel_credentials=None,
@@ -445,9 +2920,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/StoreDocument',
-            treestore__pb2.StoreDocumentRequest.SerializeToString,
-            treestore__pb2.StoreDocumentResponse.FromString,
+            '/treestore.TreeStoreService/GetUserConversationUsage',
+            treestore__pb2.GetUserConversationUsageRequest.SerializeToString,
+            treestore__pb2.GetUserConversationUsageResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -459,7 +2934,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def GetDocument(request,
+    def SetUserConversationQuota(request,
             target,
             options=(),
             channel_credentials=None,
@@ -472,9 +2947,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/GetDocument',
-            treestore__pb2.GetDocumentRequest.SerializeToString,
-            treestore__pb2.GetDocumentResponse.FromString,
+            '/treestore.TreeStoreService/SetUserConversationQuota',
+            treestore__pb2.SetUserConversationQuotaRequest.SerializeToString,
+            treestore__pb2.SetUserConversationQuotaResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -486,7 +2961,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def DeleteDocument(request,
+    def StreamQuery(request,
             target,
             options=(),
             channel_credentials=None,
@@ -496,12 +2971,12 @@ class TreeStoreService(object):
             wait_for_ready=None,
             timeout=None,
             metadata=None):
-        return grpc.experimental.unary_unary(
+        return grpc.experimental.unary_stream(
             request,
             target,
-            '/treestore.TreeStoreService/DeleteDocument',
-            treestore__pb2.DeleteDocumentRequest.SerializeToString,
-            treestore__pb2.DeleteDocumentResponse.FromString,
+            '/treestore.TreeStoreService/StreamQuery',
+            treestore__pb2.StreamQueryRequest.SerializeToString,
+            treestore__pb2.QueryRow.FromString,
             options,
             channel_credentials,
             insecure,
@@ -513,7 +2988,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def GetNode(request,
+    def SaveQuery(request,
             target,
             options=(),
             channel_credentials=None,
@@ -526,9 +3001,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/GetNode',
-            treestore__pb2.GetNodeRequest.SerializeToString,
-            treestore__pb2.GetNodeResponse.FromString,
+            '/treestore.TreeStoreService/SaveQuery',
+            treestore__pb2.SaveQueryRequest.SerializeToString,
+            treestore__pb2.SaveQueryResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -540,7 +3015,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def GetChildren(request,
+    def ExecuteSavedQuery(request,
             target,
             options=(),
             channel_credentials=None,
@@ -553,9 +3028,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/GetChildren',
-            treestore__pb2.GetChildrenRequest.SerializeToString,
-            treestore__pb2.GetChildrenResponse.FromString,
+            '/treestore.TreeStoreService/ExecuteSavedQuery',
+            treestore__pb2.ExecuteSavedQueryRequest.SerializeToString,
+            treestore__pb2.ExecuteSavedQueryResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -567,7 +3042,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def GetSubtree(request,
+    def RefreshSavedQuery(request,
             target,
             options=(),
             channel_credentials=None,
@@ -580,9 +3055,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/GetSubtree',
-            treestore__pb2.GetSubtreeRequest.SerializeToString,
-            treestore__pb2.GetSubtreeResponse.FromString,
+            '/treestore.TreeStoreService/RefreshSavedQuery',
+            treestore__pb2.RefreshSavedQueryRequest.SerializeToString,
+            treestore__pb2.RefreshSavedQueryResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -594,7 +3069,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def GetAncestorPath(request,
+    def ListSavedQueries(request,
             target,
             options=(),
             channel_credentials=None,
@@ -607,9 +3082,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/GetAncestorPath',
-            treestore__pb2.GetAncestorPathRequest.SerializeToString,
-            treestore__pb2.GetAncestorPathResponse.FromString,
+            '/treestore.TreeStoreService/ListSavedQueries',
+            treestore__pb2.ListSavedQueriesRequest.SerializeToString,
+            treestore__pb2.ListSavedQueriesResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -621,7 +3096,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def SearchByKeyword(request,
+    def DeleteSavedQuery(request,
             target,
             options=(),
             channel_credentials=None,
@@ -634,9 +3109,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/SearchByKeyword',
-            treestore__pb2.SearchRequest.SerializeToString,
-            treestore__pb2.SearchResponse.FromString,
+            '/treestore.TreeStoreService/DeleteSavedQuery',
+            treestore__pb2.DeleteSavedQueryRequest.SerializeToString,
+            treestore__pb2.DeleteSavedQueryResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -648,7 +3123,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def GetNodesByPage(request,
+    def WatchChanges(request,
             target,
             options=(),
             channel_credentials=None,
@@ -658,12 +3133,12 @@ class TreeStoreService(object):
             wait_for_ready=None,
             timeout=None,
             metadata=None):
-        return grpc.experimental.unary_unary(
+        return grpc.experimental.unary_stream(
             request,
             target,
-            '/treestore.TreeStoreService/GetNodesByPage',
-            treestore__pb2.GetNodesByPageRequest.SerializeToString,
-            treestore__pb2.GetNodesByPageResponse.FromString,
+            '/treestore.TreeStoreService/WatchChanges',
+            treestore__pb2.WatchChangesRequest.SerializeToString,
+            treestore__pb2.ChangeEvent.FromString,
             options,
             channel_credentials,
             insecure,
@@ -675,7 +3150,34 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def GetVersionAsOf(request,
+    def StreamWAL(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(
+            request,
+            target,
+            '/treestore.TreeStoreService/StreamWAL',
+            treestore__pb2.StreamWALRequest.SerializeToString,
+            treestore__pb2.WALEntry.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def QueryAuditLog(request,
             target,
             options=(),
             channel_credentials=None,
@@ -688,9 +3190,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/GetVersionAsOf',
-            treestore__pb2.GetVersionAsOfRequest.SerializeToString,
-            treestore__pb2.PolicyVersion.FromString,
+            '/treestore.TreeStoreService/QueryAuditLog',
+            treestore__pb2.QueryAuditLogRequest.SerializeToString,
+            treestore__pb2.QueryAuditLogResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -702,7 +3204,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def ListVersions(request,
+    def ListJobRuns(request,
             target,
             options=(),
             channel_credentials=None,
@@ -715,9 +3217,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/ListVersions',
-            treestore__pb2.ListVersionsRequest.SerializeToString,
-            treestore__pb2.ListVersionsResponse.FromString,
+            '/treestore.TreeStoreService/ListJobRuns',
+            treestore__pb2.ListJobRunsRequest.SerializeToString,
+            treestore__pb2.ListJobRunsResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -729,7 +3231,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def StoreToolResult(request,
+    def TriggerJob(request,
             target,
             options=(),
             channel_credentials=None,
@@ -742,9 +3244,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/StoreToolResult',
-            treestore__pb2.StoreToolResultRequest.SerializeToString,
-            treestore__pb2.StoreToolResultResponse.FromString,
+            '/treestore.TreeStoreService/TriggerJob',
+            treestore__pb2.TriggerJobRequest.SerializeToString,
+            treestore__pb2.TriggerJobResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -756,7 +3258,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def GetToolResults(request,
+    def Health(request,
             target,
             options=(),
             channel_credentials=None,
@@ -769,9 +3271,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/GetToolResults',
-            treestore__pb2.GetToolResultsRequest.SerializeToString,
-            treestore__pb2.GetToolResultsResponse.FromString,
+            '/treestore.TreeStoreService/Health',
+            treestore__pb2.HealthRequest.SerializeToString,
+            treestore__pb2.HealthResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -783,7 +3285,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def StoreTrajectory(request,
+    def Stats(request,
             target,
             options=(),
             channel_credentials=None,
@@ -796,9 +3298,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/StoreTrajectory',
-            treestore__pb2.StoreTrajectoryRequest.SerializeToString,
-            treestore__pb2.StoreTrajectoryResponse.FromString,
+            '/treestore.TreeStoreService/Stats',
+            treestore__pb2.StatsRequest.SerializeToString,
+            treestore__pb2.StatsResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -810,7 +3312,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def GetTrajectories(request,
+    def StorageBreakdown(request,
             target,
             options=(),
             channel_credentials=None,
@@ -823,9 +3325,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/GetTrajectories',
-            treestore__pb2.GetTrajectoriesRequest.SerializeToString,
-            treestore__pb2.GetTrajectoriesResponse.FromString,
+            '/treestore.TreeStoreService/StorageBreakdown',
+            treestore__pb2.StorageBreakdownRequest.SerializeToString,
+            treestore__pb2.StorageBreakdownResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -836,8 +3338,174 @@ class TreeStoreService(object):
             metadata,
             _registered_method=True)
 
+
+class TreeStoreAdminStub(object):
+    """TreeStoreAdmin runs operational commands on a live server. It is served on
+    the admin port, apart from client traffic.
+    """
+
+    def __init__(self, channel):
+        """Constructor.
+
+        Args:
+            channel: A grpc.Channel.
+        """
+        self.Checkpoint = channel.unary_unary(
+                '/treestore.TreeStoreAdmin/Checkpoint',
+                request_serializer=treestore__pb2.CheckpointRequest.SerializeToString,
+                response_deserializer=treestore__pb2.CheckpointResponse.FromString,
+                _registered_method=True)
+        self.Compact = channel.unary_unary(
+                '/treestore.TreeStoreAdmin/Compact',
+                request_serializer=treestore__pb2.CompactRequest.SerializeToString,
+                response_deserializer=treestore__pb2.CompactResponse.FromString,
+                _registered_method=True)
+        self.Reindex = channel.unary_unary(
+                '/treestore.TreeStoreAdmin/Reindex',
+                request_serializer=treestore__pb2.ReindexRequest.SerializeToString,
+                response_deserializer=treestore__pb2.ReindexResponse.FromString,
+                _registered_method=True)
+        self.Flush = channel.unary_unary(
+                '/treestore.TreeStoreAdmin/Flush',
+                request_serializer=treestore__pb2.FlushRequest.SerializeToString,
+                response_deserializer=treestore__pb2.FlushResponse.FromString,
+                _registered_method=True)
+        self.Backup = channel.unary_unary(
+                '/treestore.TreeStoreAdmin/Backup',
+                request_serializer=treestore__pb2.BackupRequest.SerializeToString,
+                response_deserializer=treestore__pb2.BackupResponse.FromString,
+                _registered_method=True)
+        self.SetLogLevel = channel.unary_unary(
+                '/treestore.TreeStoreAdmin/SetLogLevel',
+                request_serializer=treestore__pb2.SetLogLevelRequest.SerializeToString,
+                response_deserializer=treestore__pb2.SetLogLevelResponse.FromString,
+                _registered_method=True)
+        self.DumpState = channel.unary_unary(
+                '/treestore.TreeStoreAdmin/DumpState',
+                request_serializer=treestore__pb2.DumpStateRequest.SerializeToString,
+                response_deserializer=treestore__pb2.DumpStateResponse.FromString,
+                _registered_method=True)
+        self.TailLogs = channel.unary_stream(
+                '/treestore.TreeStoreAdmin/TailLogs',
+                request_serializer=treestore__pb2.TailLogsRequest.SerializeToString,
+                response_deserializer=treestore__pb2.LogEvent.FromString,
+                _registered_method=True)
+
+
+class TreeStoreAdminServicer(object):
+    """TreeStoreAdmin runs operational commands on a live server. It is served on
+    the admin port, apart from client traffic.
+    """
+
+    def Checkpoint(self, request, context):
+        """========== Storage Maintenance (4 methods) ==========
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Compact(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Reindex(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Flush(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Backup(self, request, context):
+        """========== Backup (1 method) ==========
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SetLogLevel(self, request, context):
+        """========== Diagnostics (3 methods) ==========
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DumpState(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def TailLogs(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+
+def add_TreeStoreAdminServicer_to_server(servicer, server):
+    rpc_method_handlers = {
+            'Checkpoint': grpc.unary_unary_rpc_method_handler(
+                    servicer.Checkpoint,
+                    request_deserializer=treestore__pb2.CheckpointRequest.FromString,
+                    response_serializer=treestore__pb2.CheckpointResponse.SerializeToString,
+            ),
+            'Compact': grpc.unary_unary_rpc_method_handler(
+                    servicer.Compact,
+                    request_deserializer=treestore__pb2.CompactRequest.FromString,
+                    response_serializer=treestore__pb2.CompactResponse.SerializeToString,
+            ),
+            'Reindex': grpc.unary_unary_rpc_method_handler(
+                    servicer.Reindex,
+                    request_deserializer=treestore__pb2.ReindexRequest.FromString,
+                    response_serializer=treestore__pb2.ReindexResponse.SerializeToString,
+            ),
+            'Flush': grpc.unary_unary_rpc_method_handler(
+                    servicer.Flush,
+                    request_deserializer=treestore__pb2.FlushRequest.FromString,
+                    response_serializer=treestore__pb2.FlushResponse.SerializeToString,
+            ),
+            'Backup': grpc.unary_unary_rpc_method_handler(
+                    servicer.Backup,
+                    request_deserializer=treestore__pb2.BackupRequest.FromString,
+                    response_serializer=treestore__pb2.BackupResponse.SerializeToString,
+            ),
+            'SetLogLevel': grpc.unary_unary_rpc_method_handler(
+                    servicer.SetLogLevel,
+                    request_deserializer=treestore__pb2.SetLogLevelRequest.FromString,
+                    response_serializer=treestore__pb2.SetLogLevelResponse.SerializeToString,
+            ),
+            'DumpState': grpc.unary_unary_rpc_method_handler(
+                    servicer.DumpState,
+                    request_deserializer=treestore__pb2.DumpStateRequest.FromString,
+                    response_serializer=treestore__pb2.DumpStateResponse.SerializeToString,
+            ),
+            'TailLogs': grpc.unary_stream_rpc_method_handler(
+                    servicer.TailLogs,
+                    request_deserializer=treestore__pb2.TailLogsRequest.FromString,
+                    response_serializer=treestore__pb2.LogEvent.SerializeToString,
+            ),
+    }
+    generic_handler = grpc.method_handlers_generic_handler(
+            'treestore.TreeStoreAdmin', rpc_method_handlers)
+    server.add_generic_rpc_handlers((generic_handler,))
+    server.add_registered_method_handlers('treestore.TreeStoreAdmin', rpc_method_handlers)
+
+
+ # This class is part of an EXPERIMENTAL API.
+class TreeStoreAdmin(object):
+    """TreeStoreAdmin runs operational commands on a live server. It is served on
+    the admin port, apart from client traffic.
+    """
+
     @staticmethod
-    def StoreCrossReference(request,
+    def Checkpoint(request,
             target,
             options=(),
             channel_credentials=None,
@@ -850,9 +3518,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/StoreCrossReference',
-            treestore__pb2.StoreCrossReferenceRequest.SerializeToString,
-            treestore__pb2.StoreCrossReferenceResponse.FromString,
+            '/treestore.TreeStoreAdmin/Checkpoint',
+            treestore__pb2.CheckpointRequest.SerializeToString,
+            treestore__pb2.CheckpointResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -864,7 +3532,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def GetCrossReferences(request,
+    def Compact(request,
             target,
             options=(),
             channel_credentials=None,
@@ -877,9 +3545,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/GetCrossReferences',
-            treestore__pb2.GetCrossReferencesRequest.SerializeToString,
-            treestore__pb2.GetCrossReferencesResponse.FromString,
+            '/treestore.TreeStoreAdmin/Compact',
+            treestore__pb2.CompactRequest.SerializeToString,
+            treestore__pb2.CompactResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -891,7 +3559,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def StoreContradiction(request,
+    def Reindex(request,
             target,
             options=(),
             channel_credentials=None,
@@ -904,9 +3572,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/StoreContradiction',
-            treestore__pb2.StoreContradictionRequest.SerializeToString,
-            treestore__pb2.StoreContradictionResponse.FromString,
+            '/treestore.TreeStoreAdmin/Reindex',
+            treestore__pb2.ReindexRequest.SerializeToString,
+            treestore__pb2.ReindexResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -918,7 +3586,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def StorePrompt(request,
+    def Flush(request,
             target,
             options=(),
             channel_credentials=None,
@@ -931,9 +3599,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/StorePrompt',
-            treestore__pb2.StorePromptRequest.SerializeToString,
-            treestore__pb2.StorePromptResponse.FromString,
+            '/treestore.TreeStoreAdmin/Flush',
+            treestore__pb2.FlushRequest.SerializeToString,
+            treestore__pb2.FlushResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -945,7 +3613,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def GetPrompt(request,
+    def Backup(request,
             target,
             options=(),
             channel_credentials=None,
@@ -958,9 +3626,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/GetPrompt',
-            treestore__pb2.GetPromptRequest.SerializeToString,
-            treestore__pb2.GetPromptResponse.FromString,
+            '/treestore.TreeStoreAdmin/Backup',
+            treestore__pb2.BackupRequest.SerializeToString,
+            treestore__pb2.BackupResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -972,7 +3640,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def RecordPromptUsage(request,
+    def SetLogLevel(request,
             target,
             options=(),
             channel_credentials=None,
@@ -985,9 +3653,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/RecordPromptUsage',
-            treestore__pb2.RecordPromptUsageRequest.SerializeToString,
-            treestore__pb2.RecordPromptUsageResponse.FromString,
+            '/treestore.TreeStoreAdmin/SetLogLevel',
+            treestore__pb2.SetLogLevelRequest.SerializeToString,
+            treestore__pb2.SetLogLevelResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -999,7 +3667,7 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def Health(request,
+    def DumpState(request,
             target,
             options=(),
             channel_credentials=None,
@@ -1012,9 +3680,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/Health',
-            treestore__pb2.HealthRequest.SerializeToString,
-            treestore__pb2.HealthResponse.FromString,
+            '/treestore.TreeStoreAdmin/DumpState',
+            treestore__pb2.DumpStateRequest.SerializeToString,
+            treestore__pb2.DumpStateResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -1026,7 +3694,85 @@ class TreeStoreService(object):
             _registered_method=True)
 
     @staticmethod
-    def Stats(request,
+    def TailLogs(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(
+            request,
+            target,
+            '/treestore.TreeStoreAdmin/TailLogs',
+            treestore__pb2.TailLogsRequest.SerializeToString,
+            treestore__pb2.LogEvent.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+
+class SummarizerStub(object):
+    """Summarizer is implemented by external summarization services that
+    RefreshSummaries calls with a grpc:// summarizer target
+    """
+
+    def __init__(self, channel):
+        """Constructor.
+
+        Args:
+            channel: A grpc.Channel.
+        """
+        self.Summarize = channel.unary_unary(
+                '/treestore.Summarizer/Summarize',
+                request_serializer=treestore__pb2.SummarizeRequest.SerializeToString,
+                response_deserializer=treestore__pb2.SummarizeResponse.FromString,
+                _registered_method=True)
+
+
+class SummarizerServicer(object):
+    """Summarizer is implemented by external summarization services that
+    RefreshSummaries calls with a grpc:// summarizer target
+    """
+
+    def Summarize(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+
+def add_SummarizerServicer_to_server(servicer, server):
+    rpc_method_handlers = {
+            'Summarize': grpc.unary_unary_rpc_method_handler(
+                    servicer.Summarize,
+                    request_deserializer=treestore__pb2.SummarizeRequest.FromString,
+                    response_serializer=treestore__pb2.SummarizeResponse.SerializeToString,
+            ),
+    }
+    generic_handler = grpc.method_handlers_generic_handler(
+            'treestore.Summarizer', rpc_method_handlers)
+    server.add_generic_rpc_handlers((generic_handler,))
+    server.add_registered_method_handlers('treestore.Summarizer', rpc_method_handlers)
+
+
+ # This class is part of an EXPERIMENTAL API.
+class Summarizer(object):
+    """Summarizer is implemented by external summarization services that
+    RefreshSummaries calls with a grpc:// summarizer target
+    """
+
+    @staticmethod
+    def Summarize(request,
             target,
             options=(),
             channel_credentials=None,
@@ -1039,9 +3785,9 @@ class TreeStoreService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/treestore.TreeStoreService/Stats',
-            treestore__pb2.StatsRequest.SerializeToString,
-            treestore__pb2.StatsResponse.FromString,
+            '/treestore.Summarizer/Summarize',
+            treestore__pb2.SummarizeRequest.SerializeToString,
+            treestore__pb2.SummarizeResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -0,0 +1,419 @@
+// TreeStore administration tool
+// Takes and restores backups, dumps and loads records, checks page checksums and accounting, and upgrades or migrates old files
+// Commands with -addr are run by a live server through its admin or service port
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nainya/treestore/pkg/backup"
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/dump"
+	"github.com/nainya/treestore/pkg/storage"
+	"github.com/nainya/treestore/pkg/version"
+	"github.com/nainya/treestore/pkg/wal"
+)
+
+const usage = `Usage: treestore-admin <command> [flags]
+
+Commands:
+  backup-full         Write a base snapshot (stop the server first, or use a replica)
+  backup-incremental  Append WAL entries written since the last backup (safe while serving)
+  restore             Rebuild a database from a snapshot, its WAL segments and archives, optionally to a point in time
+  dump                Write every record as a portable JSONL dump (stop the server first, or use a replica)
+  load                Create a database from a dump
+  verify              Check the checksum of every page (stop the server first)
+  audit               Find pages neither in the tree nor free, optionally reclaiming them
+  upgrade             Copy a database created before page checksums into a new file
+  migrate             Bring a database of an older format, and its node records, to the current one (stop the server first)
+
+Commands run by a live server through its admin port (-addr):
+  checkpoint          Make every commit durable in the database file and trim the WAL
+  compact             Return leaked pages to the free list (-dry-run only reports them)
+  reindex             Rebuild the keyword index of one policy or all
+  flush               Sync commits waiting under -sync=interval
+  server-backup       Write a full or incremental backup on the server's host
+  log-level           Change the server's log level
+  logs                Print recent log events as JSON lines, -f to keep following them
+  state               Print the server's internal state
+
+Commands run by a live server through its service port (-addr):
+  graph               Export a policy's tree or cross-references as Graphviz DOT or Mermaid
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "backup-full":
+		err = backupFull(os.Args[2:])
+	case "backup-incremental":
+		err = backupIncremental(os.Args[2:])
+	case "restore":
+		err = restore(os.Args[2:])
+	case "dump":
+		err = dumpDB(os.Args[2:])
+	case "load":
+		err = loadDB(os.Args[2:])
+	case "verify":
+		err = verify(os.Args[2:])
+	case "audit":
+		err = audit(os.Args[2:])
+	case "upgrade":
+		err = upgrade(os.Args[2:])
+	case "migrate":
+		err = migrate(os.Args[2:])
+	case "checkpoint":
+		err = checkpoint(os.Args[2:])
+	case "compact":
+		err = compact(os.Args[2:])
+	case "reindex":
+		err = reindex(os.Args[2:])
+	case "flush":
+		err = flush(os.Args[2:])
+	case "server-backup":
+		err = serverBackup(os.Args[2:])
+	case "log-level":
+		err = setLogLevel(os.Args[2:])
+	case "logs":
+		err = tailLogs(os.Args[2:])
+	case "state":
+		err = dumpState(os.Args[2:])
+	case "graph":
+		err = exportGraph(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func backupFull(args []string) error {
+	fs := flag.NewFlagSet("backup-full", flag.ExitOnError)
+	dbPath := fs.String("db", "treestore.db", "Database file path")
+	dir := fs.String("dir", "", "Backup directory")
+	fs.Parse(args)
+	if *dir == "" {
+		return fmt.Errorf("-dir is required")
+	}
+
+	kv := &storage.KV{Path: *dbPath}
+	if err := kv.Open(); err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	m, err := backup.Full(kv, *dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Base snapshot written to %s at LSN %d\n", *dir, m.BaseLSN)
+	return nil
+}
+
+func backupIncremental(args []string) error {
+	fs := flag.NewFlagSet("backup-incremental", flag.ExitOnError)
+	dbPath := fs.String("db", "treestore.db", "Database file path")
+	dir := fs.String("dir", "", "Backup directory holding a base snapshot")
+	fs.Parse(args)
+	if *dir == "" {
+		return fmt.Errorf("-dir is required")
+	}
+
+	seg, err := backup.Incremental(*dbPath, *dir)
+	if err != nil {
+		return err
+	}
+	if seg.Entries == 0 {
+		fmt.Printf("No changes since LSN %d\n", seg.FromLSN)
+		return nil
+	}
+	fmt.Printf("Wrote %s: %d entries, LSN %d to %d\n", seg.File, seg.Entries, seg.FromLSN, seg.ToLSN)
+	return nil
+}
+
+func restore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	dir := fs.String("dir", "", "Backup directory")
+	dbPath := fs.String("db", "", "Path of the database to create")
+	source := fs.String("source-db", "", "Backed-up database whose WAL files (archived, then live) are replayed after the segments")
+	archiveDir := fs.String("archive-dir", "", "Directory of the source's archived WAL files")
+	toLSN := fs.Uint64("to-lsn", 0, "Stop after the commit at this LSN")
+	toTime := fs.String("to-time", "", "Stop after the last commit at or before this time (RFC 3339, e.g. 2026-03-01T02:12:59Z)")
+	fs.Parse(args)
+	if *dir == "" || *dbPath == "" {
+		return fmt.Errorf("-dir and -db are required")
+	}
+
+	opts := backup.RestoreOptions{SourceDB: *source, ArchiveDir: *archiveDir, Target: wal.Target{LSN: *toLSN}}
+	if *toTime != "" {
+		t, err := time.Parse(time.RFC3339, *toTime)
+		if err != nil {
+			return fmt.Errorf("-to-time: %w", err)
+		}
+		opts.Target.Time = t
+	}
+
+	report, err := backup.RestoreTo(*dir, *dbPath, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s up to LSN %d (%d transactions after the snapshot)\n", *dbPath, report.LastLSN, report.Commits)
+	if !report.LastCommit.IsZero() {
+		fmt.Printf("Last restored commit at %s\n", report.LastCommit.Format(time.RFC3339))
+	}
+	if report.StoppedAt != 0 {
+		fmt.Printf("Stopped before the commit at LSN %d\n", report.StoppedAt)
+	} else if !opts.Target.IsZero() {
+		fmt.Println("The log ended before the target; everything available was restored")
+	}
+	return nil
+}
+
+func verify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	dbPath := fs.String("db", "treestore.db", "Database file path")
+	fs.Parse(args)
+
+	kv := &storage.KV{Path: *dbPath}
+	if err := kv.Open(); err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	report, err := kv.Verify()
+	if err != nil {
+		return err
+	}
+	if report.Legacy {
+		return fmt.Errorf("%s predates page checksums; run treestore-admin upgrade", *dbPath)
+	}
+	for _, ptr := range report.Corrupt {
+		fmt.Printf("Page %d: checksum mismatch\n", ptr)
+	}
+	if len(report.Corrupt) > 0 {
+		return fmt.Errorf("%d of %d pages corrupt", len(report.Corrupt), report.Pages)
+	}
+	fmt.Printf("%s: %d pages OK\n", *dbPath, report.Pages)
+	return nil
+}
+
+func audit(args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	dbPath := fs.String("db", "treestore.db", "Database file path")
+	reclaim := fs.Bool("reclaim", false, "Return leaked pages to the free list")
+	fs.Parse(args)
+
+	kv := &storage.KV{Path: *dbPath}
+	if err := kv.Open(); err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	report, err := kv.Audit(*reclaim)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d pages (%d tree, %d free list, %d free)\n",
+		*dbPath, report.Pages, report.Tree, report.FreeList, report.Free)
+	if len(report.Leaked) > 0 {
+		fmt.Printf("Leaked pages: %v\n", report.Leaked)
+	}
+	if report.Reclaimed > 0 {
+		fmt.Printf("Reclaimed %d pages\n", report.Reclaimed)
+	}
+	if len(report.Conflicts) > 0 {
+		return fmt.Errorf("pages counted more than once: %v", report.Conflicts)
+	}
+	return nil
+}
+
+func upgrade(args []string) error {
+	fs := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	src := fs.String("db", "treestore.db", "Database file to upgrade")
+	dst := fs.String("out", "", "Path of the upgraded database to create")
+	fs.Parse(args)
+	if *dst == "" {
+		return fmt.Errorf("-out is required")
+	}
+
+	copied, err := storage.Upgrade(*src, *dst)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Copied %d keys into %s\n", copied, *dst)
+	return nil
+}
+
+func migrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dbPath := fs.String("db", "treestore.db", "Database file path")
+	fs.Parse(args)
+
+	// Opening runs any migration rewriting records; Migrate writes the rest
+	kv, err := openEncrypted(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	if err := kv.Migrate(); err != nil {
+		return err
+	}
+	report := kv.State().Migration
+	if report.From == 0 {
+		fmt.Printf("%s: already format %d\n", *dbPath, kv.State().Format)
+	} else {
+		for _, m := range storage.PendingMigrations(report.From) {
+			fmt.Printf("Format %d: %s\n", m.To, m.Description)
+		}
+		fmt.Printf("Migrated %s from format %d to %d\n", *dbPath, report.From, report.To)
+		if report.Backup != "" {
+			fmt.Printf("The file as it was is kept at %s\n", report.Backup)
+		}
+	}
+
+	// Node records of earlier schemas read fine, but are upgraded here too
+	docs := document.NewSimpleStore(kv)
+	n, err := docs.UpgradeNodes("")
+	if err != nil {
+		return fmt.Errorf("upgrade nodes: %w", err)
+	}
+	if n > 0 {
+		fmt.Printf("Upgraded %d nodes to the current record schema\n", n)
+	}
+
+	// Nodes stored before the term index join it, for cross-policy search
+	n, err = docs.Reindex("")
+	if err != nil {
+		return fmt.Errorf("reindex nodes: %w", err)
+	}
+	if n > 0 {
+		fmt.Printf("Indexed the terms of %d nodes\n", n)
+	}
+
+	// Versions stored before effective dates join their index
+	n, err = version.NewVersionStore(kv).IndexEffectiveDates()
+	if err != nil {
+		return fmt.Errorf("index effective dates: %w", err)
+	}
+	if n > 0 {
+		fmt.Printf("Indexed the effective dates of %d versions\n", n)
+	}
+	return nil
+}
+
+// openEncrypted opens a database with the keys of TREESTORE_ENCRYPTION_KEYS, if set
+func openEncrypted(path string) (*storage.KV, error) {
+	enc, err := storage.EncryptionFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	kv := &storage.KV{Path: path, Encryption: enc}
+	if err := kv.Open(); err != nil {
+		return nil, err
+	}
+	return kv, nil
+}
+
+func dumpDB(args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	dbPath := fs.String("db", "treestore.db", "Database file path")
+	out := fs.String("out", "", "Dump file to create (default: standard output)")
+	stores := fs.String("stores", "", "Comma-separated stores to dump (default: all)")
+	fs.Parse(args)
+
+	kv, err := openEncrypted(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	w := os.Stdout
+	if *out != "" {
+		if w, err = os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600); err != nil {
+			return err
+		}
+	}
+	var only []string
+	if *stores != "" {
+		only = strings.Split(*stores, ",")
+	}
+
+	stats, err := dump.Write(kv, w, only)
+	if err == nil && *out != "" {
+		err = w.Close()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Dumped %d records: %s\n", stats.Records, formatStoreCounts(stats.Stores))
+	return nil
+}
+
+func loadDB(args []string) error {
+	fs := flag.NewFlagSet("load", flag.ExitOnError)
+	in := fs.String("in", "", "Dump file to load (default: standard input)")
+	dbPath := fs.String("db", "", "Path of the database to create")
+	fs.Parse(args)
+	if *dbPath == "" {
+		return fmt.Errorf("-db is required")
+	}
+	if _, err := os.Stat(*dbPath); err == nil {
+		return fmt.Errorf("%s already exists", *dbPath)
+	}
+
+	r := os.Stdin
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	kv, err := openEncrypted(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	header, stats, err := dump.Load(r, kv)
+	if err != nil {
+		return fmt.Errorf("%v (delete the partial %s before retrying)", err, *dbPath)
+	}
+	fmt.Printf("Loaded %d records into %s from a version %d dump of %s: %s\n",
+		stats.Records, *dbPath, header.Version, header.Source, formatStoreCounts(stats.Stores))
+	return nil
+}
+
+// formatStoreCounts lists record counts by store, in name order
+func formatStoreCounts(counts map[string]int64) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "no stores"
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, counts[name])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,291 @@
+// Commands run by a live server through its admin or service port
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/nainya/treestore/internal/server"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// remote holds the flags every remote command shares
+type remote struct {
+	addr    *string
+	apiKey  *string
+	timeout *time.Duration
+}
+
+// remoteFlags defines the flags of a command run through the admin port
+func remoteFlags(fs *flag.FlagSet) *remote {
+	return &remote{
+		addr:    fs.String("addr", "localhost:50052", "Admin port of the server"),
+		apiKey:  fs.String("api-key", "", "API key sent as "+server.DefaultAPIKeyHeader+" (needs admin access to system under RBAC)"),
+		timeout: fs.Duration("timeout", 10*time.Minute, "Time to wait for the command (0 waits until it ends)"),
+	}
+}
+
+// serviceFlags defines the flags of a command run through the service port
+func serviceFlags(fs *flag.FlagSet, access string) *remote {
+	return &remote{
+		addr:    fs.String("addr", "localhost:50051", "Service port of the server"),
+		apiKey:  fs.String("api-key", "", "API key sent as "+server.DefaultAPIKeyHeader+" (needs "+access+" under RBAC)"),
+		timeout: fs.Duration("timeout", time.Minute, "Time to wait for the command (0 waits until it ends)"),
+	}
+}
+
+// dial connects to the server and runs fn with a context carrying the API key
+func (r *remote) dial(fn func(ctx context.Context, conn *grpc.ClientConn) error) error {
+	conn, err := grpc.NewClient(*r.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	if *r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *r.timeout)
+		defer cancel()
+	}
+	if *r.apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, server.DefaultAPIKeyHeader, *r.apiKey)
+	}
+	return fn(ctx, conn)
+}
+
+// call runs fn with a client of the admin service
+func (r *remote) call(fn func(ctx context.Context, client pb.TreeStoreAdminClient) error) error {
+	return r.dial(func(ctx context.Context, conn *grpc.ClientConn) error {
+		return fn(ctx, pb.NewTreeStoreAdminClient(conn))
+	})
+}
+
+// callService runs fn with a client of the main service
+func (r *remote) callService(fn func(ctx context.Context, client pb.TreeStoreServiceClient) error) error {
+	return r.dial(func(ctx context.Context, conn *grpc.ClientConn) error {
+		return fn(ctx, pb.NewTreeStoreServiceClient(conn))
+	})
+}
+
+func checkpoint(args []string) error {
+	fs := flag.NewFlagSet("checkpoint", flag.ExitOnError)
+	r := remoteFlags(fs)
+	fs.Parse(args)
+
+	return r.call(func(ctx context.Context, client pb.TreeStoreAdminClient) error {
+		resp, err := client.Checkpoint(ctx, &pb.CheckpointRequest{})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Checkpoint at LSN %d took %dms\n", resp.LastLsn, resp.DurationMs)
+		return nil
+	})
+}
+
+func compact(args []string) error {
+	fs := flag.NewFlagSet("compact", flag.ExitOnError)
+	r := remoteFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Report leaked pages without reclaiming them")
+	fs.Parse(args)
+
+	return r.call(func(ctx context.Context, client pb.TreeStoreAdminClient) error {
+		resp, err := client.Compact(ctx, &pb.CompactRequest{DryRun: *dryRun})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d pages (%d tree, %d free), %d leaked, %d reclaimed\n",
+			resp.Pages, resp.TreePages, resp.FreePages, resp.LeakedPages, resp.ReclaimedPages)
+		if resp.ConflictingPages > 0 {
+			return fmt.Errorf("%d pages counted more than once; run verify and audit on a copy", resp.ConflictingPages)
+		}
+		return nil
+	})
+}
+
+func reindex(args []string) error {
+	fs := flag.NewFlagSet("reindex", flag.ExitOnError)
+	r := remoteFlags(fs)
+	policyID := fs.String("policy", "", "Policy to reindex (default: all)")
+	fs.Parse(args)
+
+	return r.call(func(ctx context.Context, client pb.TreeStoreAdminClient) error {
+		resp, err := client.Reindex(ctx, &pb.ReindexRequest{PolicyId: *policyID})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Indexed %d nodes\n", resp.NodesIndexed)
+		return nil
+	})
+}
+
+func flush(args []string) error {
+	fs := flag.NewFlagSet("flush", flag.ExitOnError)
+	r := remoteFlags(fs)
+	fs.Parse(args)
+
+	return r.call(func(ctx context.Context, client pb.TreeStoreAdminClient) error {
+		resp, err := client.Flush(ctx, &pb.FlushRequest{})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Flushed %d commits\n", resp.FlushedCommits)
+		return nil
+	})
+}
+
+func serverBackup(args []string) error {
+	fs := flag.NewFlagSet("server-backup", flag.ExitOnError)
+	r := remoteFlags(fs)
+	dir := fs.String("dir", "", "Backup directory on the server's host")
+	incremental := fs.Bool("incremental", false, "Append WAL entries written since the last backup")
+	fs.Parse(args)
+	if *dir == "" {
+		return fmt.Errorf("-dir is required")
+	}
+
+	return r.call(func(ctx context.Context, client pb.TreeStoreAdminClient) error {
+		resp, err := client.Backup(ctx, &pb.BackupRequest{Dir: *dir, Incremental: *incremental})
+		if err != nil {
+			return err
+		}
+		if !*incremental {
+			fmt.Printf("Base snapshot written to %s at LSN %d\n", *dir, resp.ToLsn)
+		} else if resp.Entries == 0 {
+			fmt.Printf("No changes since LSN %d\n", resp.FromLsn)
+		} else {
+			fmt.Printf("Wrote %s: %d entries, LSN %d to %d\n", resp.File, resp.Entries, resp.FromLsn, resp.ToLsn)
+		}
+		return nil
+	})
+}
+
+func setLogLevel(args []string) error {
+	fs := flag.NewFlagSet("log-level", flag.ExitOnError)
+	r := remoteFlags(fs)
+	level := fs.String("level", "", "New log level: debug, info, warn or error")
+	fs.Parse(args)
+	if *level == "" {
+		return fmt.Errorf("-level is required")
+	}
+
+	return r.call(func(ctx context.Context, client pb.TreeStoreAdminClient) error {
+		resp, err := client.SetLogLevel(ctx, &pb.SetLogLevelRequest{Level: *level})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Log level %s (was %s)\n", *level, resp.PreviousLevel)
+		return nil
+	})
+}
+
+func tailLogs(args []string) error {
+	fs := flag.NewFlagSet("logs", flag.ExitOnError)
+	r := remoteFlags(fs)
+	level := fs.String("level", "", "Lowest level printed: debug, info, warn or error (default: all the server logs)")
+	method := fs.String("method", "", "Only events of gRPC methods containing this, such as GetNode")
+	recent := fs.Int("recent", 100, "Kept events printed first (0 for all, negative for none)")
+	follow := fs.Bool("f", false, "Keep printing new events until interrupted")
+	fs.Parse(args)
+	timeoutSet := false
+	fs.Visit(func(f *flag.Flag) { timeoutSet = timeoutSet || f.Name == "timeout" })
+	if *follow && !timeoutSet {
+		*r.timeout = 0
+	}
+
+	return r.call(func(ctx context.Context, client pb.TreeStoreAdminClient) error {
+		stream, err := client.TailLogs(ctx, &pb.TailLogsRequest{
+			MinLevel: *level,
+			Method:   *method,
+			Recent:   int32(*recent),
+			Follow:   *follow,
+		})
+		if err != nil {
+			return err
+		}
+		for {
+			ev, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if ev.Dropped > 0 {
+				fmt.Fprintf(os.Stderr, "(%d events dropped)\n", ev.Dropped)
+			}
+			fmt.Println(strings.TrimSpace(ev.Json))
+		}
+	})
+}
+
+func dumpState(args []string) error {
+	fs := flag.NewFlagSet("state", flag.ExitOnError)
+	r := remoteFlags(fs)
+	fs.Parse(args)
+
+	return r.call(func(ctx context.Context, client pb.TreeStoreAdminClient) error {
+		resp, err := client.DumpState(ctx, &pb.DumpStateRequest{})
+		if err != nil {
+			return err
+		}
+		out, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	})
+}
+
+func exportGraph(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	r := serviceFlags(fs, "read access to documents")
+	policyID := fs.String("policy", "", "Policy to draw")
+	kind := fs.String("graph", "tree", "Graph to draw: tree or references")
+	format := fs.String("format", "dot", "Output format: dot or mermaid")
+	root := fs.String("root", "", "Draw the tree below this node, or the references starting from it")
+	depth := fs.Int("depth", 0, "Tree levels or reference hops to draw (0 for all)")
+	label := fs.String("label", "title", "Node labels: title, section_path or node_id")
+	labelLength := fs.Int("label-length", 0, "Cut longer labels (0 for no limit)")
+	out := fs.String("out", "", "Write to this file instead of standard output")
+	fs.Parse(args)
+	if *policyID == "" {
+		return fmt.Errorf("-policy is required")
+	}
+
+	return r.callService(func(ctx context.Context, client pb.TreeStoreServiceClient) error {
+		resp, err := client.ExportGraph(ctx, &pb.ExportGraphRequest{
+			PolicyId:       *policyID,
+			Graph:          *kind,
+			Format:         *format,
+			RootNodeId:     *root,
+			MaxDepth:       int32(*depth),
+			Label:          *label,
+			MaxLabelLength: int32(*labelLength),
+		})
+		if err != nil {
+			return err
+		}
+		if *out == "" {
+			fmt.Print(resp.Content)
+			return nil
+		}
+		if err := os.WriteFile(*out, []byte(resp.Content), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d nodes and %d edges to %s\n", resp.NodeCount, resp.EdgeCount, *out)
+		return nil
+	})
+}
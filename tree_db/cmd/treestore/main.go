@@ -3,41 +3,197 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"flag"
 	"fmt"
 	"net"
+	"io"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 
+	"github.com/nainya/treestore/internal/config"
 	"github.com/nainya/treestore/internal/logger"
 	"github.com/nainya/treestore/internal/metrics"
 	"github.com/nainya/treestore/internal/server"
+	"github.com/nainya/treestore/pkg/canary"
+	"github.com/nainya/treestore/pkg/compaction"
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/jobs"
+	"github.com/nainya/treestore/pkg/prompt"
+	"github.com/nainya/treestore/pkg/query"
+	"github.com/nainya/treestore/pkg/retention"
+	"github.com/nainya/treestore/pkg/storage"
+	"github.com/nainya/treestore/pkg/summarize"
+	"github.com/nainya/treestore/pkg/wal"
+	"github.com/nainya/treestore/pkg/xref"
 	pb "github.com/nainya/treestore/proto"
 )
 
 var (
+	configPath     = flag.String("config", "", "YAML file of flag settings (flags and TREESTORE_* variables override it)")
 	grpcPort       = flag.Int("port", 50051, "The gRPC server port")
 	metricsPort    = flag.Int("metrics-port", 9090, "The metrics/observability HTTP port")
+	metricsHost    = flag.String("metrics-host", "", "Interface the metrics/observability server binds (127.0.0.1 for local access only, empty for all)")
+	adminPort      = flag.Int("admin-port", 50052, "The admin gRPC port (0 disables the admin service)")
 	dbPath         = flag.String("db", "treestore.db", "Database file path")
+	syncPolicy     = flag.String("sync", "always", "When commits are fsynced: always, interval or never")
+	syncInterval   = flag.Duration("sync-interval", storage.DefaultSyncInterval, "Flush period of -sync=interval")
+	noMmap         = flag.Bool("no-mmap", false, "Read the database from a copy in memory instead of mapping it")
+	migrate        = flag.Bool("migrate", true, "Migrate a database of an older format when opening it (false refuses to start)")
+	subtreeWorkers = flag.Int("subtree-workers", 1, "Fetch the children of this many nodes at once when reading a subtree (1 is sequential)")
+	bloomFilters   = flag.Bool("bloom-filters", false, "Keep per-policy bloom filters of node IDs to answer lookups of missing nodes")
+	pathCacheEntries = flag.Int("path-cache-entries", document.DefaultPathCacheEntries, "Cache the ancestor IDs of this many nodes for ancestor path reads (0 disables the cache)")
+	queryCacheEntries = flag.Int("query-cache-entries", 0, "Cache this many query results until a write invalidates them (0 disables the cache)")
+	queryCacheTTL     = flag.Duration("query-cache-ttl", query.DefaultCacheTTL, "Recompute cached query results older than this")
 	logLevel       = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
 	logPretty      = flag.Bool("log-pretty", true, "Pretty-print logs (disable for production)")
+	logTailSize    = flag.Int("log-tail-size", 1000, "Recent log events kept in memory for the admin service's TailLogs (0 disables it)")
 	enableProfiling = flag.Bool("enable-profiling", true, "Enable pprof profiling endpoints")
+	grpcReflection  = flag.Bool("grpc-reflection", true, "Serve gRPC reflection for grpcurl and grpcui")
+	lockedDown      = flag.Bool("locked-down", false, "Production profile: unless set explicitly, -grpc-reflection, -enable-profiling, -debug-vars and -log-pretty default to false and -metrics-host to 127.0.0.1")
+
+	// WAL archiving (without a dir or command, retired WAL files are deleted)
+	walArchiveDir      = flag.String("wal-archive-dir", "", "Move retired WAL files into this directory")
+	walArchiveCommand  = flag.String("wal-archive-command", "", "Shell command run on each retired WAL file (%p: path, %f: archive name)")
+	walArchiveMaxAge   = flag.Duration("wal-archive-max-age", 0, "Delete archived WAL files older than this (0 keeps them)")
+	walArchiveMaxBytes = flag.Int64("wal-archive-max-bytes", 0, "Delete the oldest archived WAL files beyond this total size (0 keeps them)")
+
+	// Retention (0 keeps entities forever)
+	conversationTTL   = flag.Duration("retention-conversations", 0, "Delete conversations inactive for this long")
+	toolResultTTL     = flag.Duration("retention-tool-results", 0, "Delete tool results older than this")
+	trajectoryTTL     = flag.Duration("retention-trajectories", 0, "Delete trajectories older than this")
+	retentionInterval = flag.Duration("retention-interval", time.Hour, "Time between retention sweeps")
+	retentionBatch    = flag.Int("retention-batch", 100, "Maximum deletions per entity type per sweep")
+	retentionDryRun   = flag.Bool("retention-dry-run", false, "Report expired entities without deleting them")
+
+	// Conversation quotas of users without their own (0 is unlimited)
+	quotaConversations        = flag.Int64("quota-conversations", 0, "Conversations a user may have at once")
+	quotaMessages             = flag.Int64("quota-messages", 0, "Messages a user may have across their conversations")
+	quotaConversationMessages = flag.Int64("quota-conversation-messages", 0, "Messages any one conversation may have")
+
+	// Background compaction (0 interval disables it)
+	compactInterval         = flag.Duration("compact-interval", 0, "Time between background compaction passes")
+	compactIdle             = flag.Duration("compact-idle", compaction.DefaultIdleAfter, "Only compact once no key was written for this long")
+	compactMaxLeaves        = flag.Int("compact-max-leaves", compaction.DefaultMaxLeaves, "Maximum leaf pages rewritten per compaction pass")
+	compactMinFragmentation = flag.Float64("compact-min-fragmentation", compaction.DefaultMinFragmentation, "Only compact when this share of leaves is out of order")
+
+	// Canary reads of recent writes (0 interval disables them)
+	canaryInterval = flag.Duration("canary-interval", 0, "Time between canary passes re-reading recent writes")
+	canarySample   = flag.Int("canary-sample", canary.DefaultSample, "Maximum records re-read per canary pass")
+	canaryWindow   = flag.Int("canary-window", canary.DefaultWindow, "Number of recent writes canary passes sample from")
+
+	// Replication (empty runs as a leader)
+	replicateFrom    = flag.String("replicate-from", "", "Leader address (host:port) to follow as a read-only replica")
+	replicationRetry = flag.Duration("replication-retry", server.DefaultRetryInterval, "Wait before reconnecting to the leader")
+	replicationKey   = flag.String("replication-api-key", "", "API key sent to the leader (needs admin access to replication under RBAC)")
+
+	// Cross-references found in node text as documents are stored
+	extractReferences = flag.Bool("extract-references", false, "Store cross-references found in node text, such as \"see Section 4.2\", on StoreDocument")
+	referenceRules    = flag.String("reference-rules", "", "YAML file of the rules -extract-references finds references with (default: built-in section and policy rules)")
+
+	// Node text token counts, used by GetSubtreeWithinBudget and context windows
+	tokenEstimator = flag.String("token-estimator", "bytes", "How node text tokens are counted on write: bytes (four bytes per token) or words (four tokens per three words)")
+
+	// Summaries recomputed by an external service with RefreshSummaries
+	summarizerAddr    = flag.String("summarizer", "", "Summarization service RefreshSummaries calls: an http(s):// URL or a grpc://host:port address")
+	summarizerTimeout = flag.Duration("summarizer-timeout", summarize.DefaultTimeout, "Limit on one call to the summarization service")
+
+	// Analysis jobs run over documents as they change
+	analysisJobs       = flag.String("analysis-jobs", "", "YAML file of HTTP analysis jobs, such as contradiction detection, run over documents as they change")
+	analysisInterval   = flag.Duration("analysis-interval", jobs.DefaultInterval, "Time between passes over queued analysis jobs")
+	analysisTimeout    = flag.Duration("analysis-timeout", jobs.DefaultTimeout, "Limit on one attempt of an analysis job")
+	analysisAttempts   = flag.Int("analysis-attempts", jobs.DefaultMaxAttempts, "Attempts before an analysis job's run fails")
+	analysisRetryDelay = flag.Duration("analysis-retry-delay", jobs.DefaultRetryDelay, "Wait before the first retry of a failed analysis job; it doubles with every further attempt")
+
+	// Request limits (0 disables a check)
+	maxNodesPerDocument = flag.Int("max-nodes-per-document", server.DefaultLimits.MaxNodesPerDocument, "Maximum nodes in one StoreDocument request")
+	maxNodeBytes        = flag.Int("max-node-bytes", server.DefaultLimits.MaxNodeBytes, "Maximum title, summary, text and section path bytes per node")
+	maxDepth            = flag.Int("max-depth", server.DefaultLimits.MaxDepth, "Maximum node depth")
+	maxIDBytes          = flag.Int("max-id-bytes", server.DefaultLimits.MaxIDBytes, "Maximum length of any ID")
+	maxAttributes       = flag.Int("max-attributes", server.DefaultLimits.MaxAttributes, "Maximum attributes in one BatchSetMetadata request")
+
+	// Rate limiting per API key or peer IP (0 leaves a budget unlimited)
+	readRate     = flag.Float64("rate-limit-read", 0, "Read requests per second per client")
+	readBurst    = flag.Int("rate-limit-read-burst", 0, "Read burst per client (default: one second of reads)")
+	writeRate    = flag.Float64("rate-limit-write", 0, "Write requests per second per client")
+	writeBurst   = flag.Int("rate-limit-write-burst", 0, "Write burst per client (default: one second of writes)")
+	apiKeyHeader = flag.String("rate-limit-key-header", server.DefaultAPIKeyHeader, "Metadata key carrying a client's API key, recorded by the audit log and sent by the retriever and followers")
+
+	// Audit log of write RPCs
+	auditEnabled = flag.Bool("audit", true, "Record every write RPC in the audit log")
+	auditFile    = flag.String("audit-log-file", "", "Also append audit records to this JSONL file")
+
+	// Access control (empty allows every caller everything)
+	rbacConfig = flag.String("rbac-config", "", "YAML file mapping API keys to roles; callers without a granting role are denied")
+
+	// Slow requests and deadlines (0 disables)
+	slowQueryThreshold = flag.Duration("slow-query-threshold", time.Second, "Log requests taking at least this long, with their arguments")
+	rpcTimeout         = flag.Duration("rpc-timeout", 30*time.Second, "Deadline for unary requests sent without one")
+
+	// Document browser (no access control)
+	webUI = flag.Bool("web-ui", false, "Serve a read-only document browser at /ui/ on the metrics port")
+
+	// Retriever for LLM frameworks, calling the gRPC port so access control applies
+	retrieverHTTP = flag.Bool("retriever-http", false, "Serve LangChain-style retrieval at /v1/retrieve on the metrics port")
+
+	// MCP tools for LLM agents (no access control)
+	mcpMode = flag.String("mcp", "", "Serve MCP tools for LLM agents: \"stdio\" on stdin and stdout, or \"http\" at /mcp on the metrics port")
+
+	// Debug endpoints under /debug/ on the metrics port (empty settings leave them open)
+	debugVars     = flag.Bool("debug-vars", true, "Serve a JSON snapshot of database internals at /debug/vars")
+	debugUser     = flag.String("debug-user", "", "Basic auth user required for /debug/ endpoints")
+	debugPassword = flag.String("debug-password", "", "Basic auth password required for /debug/ endpoints (prefer TREESTORE_DEBUG_PASSWORD)")
+	debugAllow    = flag.String("debug-allow", "", "Comma-separated IPs and CIDR ranges allowed to reach /debug/ endpoints")
 )
 
 func main() {
 	flag.Parse()
+	if err := config.Apply(flag.CommandLine, "config", os.LookupEnv); err != nil {
+		fmt.Fprintf(os.Stderr, "treestore: %v\n", err)
+		os.Exit(2)
+	}
+	if *lockedDown {
+		err := config.Defaults(flag.CommandLine, map[string]string{
+			"grpc-reflection":  "false",
+			"enable-profiling": "false",
+			"debug-vars":       "false",
+			"log-pretty":       "false",
+			"metrics-host":     "127.0.0.1",
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "treestore: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	if *mcpMode != "" && *mcpMode != "stdio" && *mcpMode != "http" {
+		fmt.Fprintf(os.Stderr, "treestore: -mcp must be stdio or http, not %q\n", *mcpMode)
+		os.Exit(2)
+	}
 
-	// Initialize structured logger
+	// Initialize structured logger; MCP over stdio owns stdout, so logs move to stderr
+	var logOutput io.Writer
+	if *mcpMode == "stdio" {
+		logOutput = os.Stderr
+	}
+	var logRing *logger.Ring
+	if *logTailSize > 0 {
+		logRing = logger.NewRing(*logTailSize)
+	}
 	logger.InitGlobalLogger(logger.Config{
 		Level:      *logLevel,
 		Pretty:     *logPretty,
 		WithCaller: false,
+		Output:     logOutput,
+		Ring:       logRing,
 	})
 	log := logger.GetGlobalLogger()
 
@@ -58,29 +214,395 @@ func main() {
 	}
 
 	// Initialize TreeStore server
-	log.Info("Initializing TreeStore database").Str("path", *dbPath).Send()
-	treeStoreServer, err := server.NewServer(*dbPath)
+	policy, err := storage.ParseSyncPolicy(*syncPolicy)
+	if err != nil {
+		log.Fatal("Invalid -sync").Err(err).Send()
+	}
+
+	var archive *wal.Archive
+	if *walArchiveDir != "" || *walArchiveCommand != "" {
+		archive = &wal.Archive{
+			Dir:      *walArchiveDir,
+			Command:  *walArchiveCommand,
+			MaxAge:   *walArchiveMaxAge,
+			MaxBytes: *walArchiveMaxBytes,
+		}
+		log.Info("WAL archiving enabled").Str("dir", *walArchiveDir).Str("command", *walArchiveCommand).Send()
+	}
+
+	var references *xref.Extractor
+	if *extractReferences {
+		rules := xref.DefaultRules()
+		if *referenceRules != "" {
+			if rules, err = xref.LoadRules(*referenceRules); err != nil {
+				log.Fatal("Failed to load reference rules").Str("path", *referenceRules).Err(err).Send()
+			}
+		}
+		if references, err = xref.NewExtractor(rules); err != nil {
+			log.Fatal("Invalid reference rules").Err(err).Send()
+		}
+		log.Info("Reference extraction enabled").Int("rules", len(rules.Rules)).Send()
+	}
+
+	estimate, ok := document.TokenEstimators[*tokenEstimator]
+	if !ok {
+		log.Fatal("Unknown token estimator").Str("estimator", *tokenEstimator).Send()
+	}
+
+	var summarizer summarize.Client
+	if *summarizerAddr != "" {
+		if summarizer, err = summarize.New(*summarizerAddr, *summarizerTimeout); err != nil {
+			log.Fatal("Invalid summarizer").Str("summarizer", *summarizerAddr).Err(err).Send()
+		}
+		defer summarizer.Close()
+		log.Info("Summary refresh enabled").Str("summarizer", *summarizerAddr).Send()
+	}
+
+	log.Info("Initializing TreeStore database").Str("path", *dbPath).Str("sync", policy.String()).Send()
+	treeStoreServer, err := server.NewServerWithOptions(*dbPath, server.Options{
+		SyncPolicy:   policy,
+		SyncInterval: *syncInterval,
+		NoMmap:       *noMmap,
+		NoMigrate:    !*migrate,
+		WALArchive:   archive,
+		BloomFilters: *bloomFilters,
+		PathCacheEntries: *pathCacheEntries,
+		SubtreeWorkers: *subtreeWorkers,
+		QueryCacheEntries: *queryCacheEntries,
+		QueryCacheTTL:  *queryCacheTTL,
+		LogRing:        logRing,
+		References:     references,
+		Summarizer:     summarizer,
+		TokenEstimator: estimate,
+		ConversationQuota: prompt.Quota{
+			MaxConversations:        *quotaConversations,
+			MaxMessages:             *quotaMessages,
+			MaxConversationMessages: *quotaConversationMessages,
+		},
+	})
 	if err != nil {
 		log.Fatal("Failed to create TreeStore server").Err(err).Send()
 	}
 	defer treeStoreServer.Close()
+	if mig := treeStoreServer.Migration(); mig.From != 0 {
+		log.Info("Migrated database format").
+			Uint32("from", mig.From).
+			Uint32("to", mig.To).
+			Str("backup", mig.Backup).
+			Send()
+	}
+	if n := treeStoreServer.Recovery().TruncatedBytes; n > 0 {
+		log.Warn("Truncated a torn WAL tail").Int64("bytes", n).Send()
+	}
+	m.ObserveCheckpoints(func() metrics.CheckpointLag {
+		stats := treeStoreServer.CheckpointStats()
+		return metrics.CheckpointLag{Entries: stats.LagEntries, Age: stats.LagAge, LogFiles: stats.LogFiles}
+	})
+	m.ObserveQueryCache(func() metrics.QueryCacheStats {
+		stats := treeStoreServer.QueryCacheStats()
+		return metrics.QueryCacheStats{Hits: stats.Hits, Misses: stats.Misses, Invalidations: stats.Invalidations, Entries: stats.Entries}
+	})
+
+	// Start retention sweeper when any TTL is configured
+	if *conversationTTL > 0 || *toolResultTTL > 0 || *trajectoryTTL > 0 {
+		treeStoreServer.StartRetention(retention.Config{
+			TTLs: map[string]time.Duration{
+				retention.EntityConversation: *conversationTTL,
+				retention.EntityToolResult:   *toolResultTTL,
+				retention.EntityTrajectory:   *trajectoryTTL,
+			},
+			Interval:  *retentionInterval,
+			BatchSize: *retentionBatch,
+			DryRun:    *retentionDryRun,
+			OnSweep: func(report *retention.SweepReport) {
+				m.RecordRetentionSweep(report.Reclaimed, report.DryRun)
+				for entityType, err := range report.Errors {
+					log.Error("Retention sweep failed").Str("entity_type", entityType).Err(err).Send()
+				}
+			},
+		})
+		log.Info("Retention sweeper started").
+			Dur("interval", *retentionInterval).
+			Bool("dry_run", *retentionDryRun).
+			Send()
+	}
+
+	// Start background compaction when an interval is configured
+	if *compactInterval > 0 {
+		treeStoreServer.StartCompaction(compaction.Config{
+			Interval:         *compactInterval,
+			IdleAfter:        *compactIdle,
+			MaxLeaves:        *compactMaxLeaves,
+			MinFragmentation: *compactMinFragmentation,
+			OnPass: func(report *compaction.PassReport) {
+				if report.Err != nil {
+					log.Error("Compaction pass failed").Err(report.Err).Send()
+					return
+				}
+				log.Info("Compaction pass").
+					Int("leaves_moved", report.Moved).
+					Float64("fragmentation_before", report.Before.Ratio()).
+					Float64("fragmentation_after", report.After.Ratio()).
+					Dur("duration", report.Duration).
+					Send()
+			},
+		})
+		log.Info("Background compaction started").
+			Dur("interval", *compactInterval).
+			Dur("idle", *compactIdle).
+			Send()
+	}
+
+	// Start canary reads when an interval is configured
+	if *canaryInterval > 0 {
+		treeStoreServer.StartCanary(canary.Config{
+			Interval: *canaryInterval,
+			Sample:   *canarySample,
+			Window:   *canaryWindow,
+			OnCheck: func(report *canary.CheckReport) {
+				anomalies := make(map[string]int)
+				for _, a := range report.Anomalies {
+					anomalies[a.Kind]++
+					log.Error("Canary read found an undecodable record").
+						Str("record", a.Kind).
+						Hex("key", a.Key).
+						Err(a.Err).
+						Send()
+				}
+				m.RecordCanaryCheck(report.Checked, anomalies)
+			},
+		})
+		log.Info("Canary reads started").
+			Dur("interval", *canaryInterval).
+			Int("sample", *canarySample).
+			Send()
+	}
+
+	// Run analysis jobs on the leader; followers list the runs it replicates
+	if *analysisJobs != "" && *replicateFrom != "" {
+		log.Warn("Analysis jobs only run on the leader; ignoring -analysis-jobs").Send()
+	} else if *analysisJobs != "" {
+		registered, err := jobs.LoadJobs(*analysisJobs)
+		if err != nil {
+			log.Fatal("Failed to load analysis jobs").Str("path", *analysisJobs).Err(err).Send()
+		}
+		_, err = treeStoreServer.StartJobs(jobs.Config{
+			Jobs:        registered,
+			Interval:    *analysisInterval,
+			Timeout:     *analysisTimeout,
+			MaxAttempts: *analysisAttempts,
+			RetryDelay:  *analysisRetryDelay,
+			OnPass: func(report *jobs.PassReport) {
+				if report.Err != nil {
+					log.Error("Analysis job pass failed").Err(report.Err).Send()
+				}
+				for _, run := range report.Runs {
+					m.RecordJobAttempt(run.Job, string(run.State))
+					if run.State != jobs.StateSucceeded {
+						log.Warn("Analysis job failed").
+							Str("job", run.Job).
+							Str("policy_id", run.PolicyID).
+							Int("attempts", run.Attempts).
+							Str("state", string(run.State)).
+							Str("error", run.LastError).
+							Send()
+					}
+				}
+			},
+		})
+		if err != nil {
+			log.Fatal("Failed to start analysis jobs").Str("path", *analysisJobs).Err(err).Send()
+		}
+		log.Info("Analysis jobs started").
+			Int("jobs", len(registered)).
+			Dur("interval", *analysisInterval).
+			Send()
+	}
+
+	limits := server.Limits{
+		MaxNodesPerDocument: *maxNodesPerDocument,
+		MaxNodeBytes:        *maxNodeBytes,
+		MaxDepth:            *maxDepth,
+		MaxIDBytes:          *maxIDBytes,
+		MaxAttributes:       *maxAttributes,
+	}
+
+	limiter := server.NewRateLimiter(server.RateLimitConfig{
+		ReadRate:   *readRate,
+		ReadBurst:  *readBurst,
+		WriteRate:  *writeRate,
+		WriteBurst: *writeBurst,
+		OnThrottle: m.RecordRateLimited,
+	})
+	// Request IDs come first so every later interceptor logs them. Recovery
+	// sits just inside metrics so a recovered panic is counted as an error,
+	// and the slow query log outside the deadline so it sees its code
+	unary := []grpc.UnaryServerInterceptor{
+		server.RequestIDInterceptor(log),
+		server.GrpcMetricsInterceptor(m, log),
+		server.RecoveryInterceptor(log, m.RecordPanic),
+		server.SlowQueryInterceptor(log, *slowQueryThreshold),
+		server.DeadlineInterceptor(*rpcTimeout),
+	}
+
+	// Audit writes ahead of access control and validation so rejected
+	// attempts are recorded too
+	if *auditEnabled {
+		if *auditFile != "" {
+			f, err := os.OpenFile(*auditFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+			if err != nil {
+				log.Fatal("Failed to open audit log file").Str("path", *auditFile).Err(err).Send()
+			}
+			defer f.Close()
+			treeStoreServer.SetAuditMirror(f)
+		}
+		unary = append(unary, treeStoreServer.AuditInterceptor(*apiKeyHeader, func(err error) {
+			log.Error("Failed to record audit entry").Err(err).Send()
+		}))
+	}
+	stream := []grpc.StreamServerInterceptor{
+		server.RequestIDStreamInterceptor(log),
+		server.GrpcMetricsStreamInterceptor(m, log),
+		server.RecoveryStreamInterceptor(log, m.RecordPanic),
+		server.SlowQueryStreamInterceptor(log, *slowQueryThreshold),
+	}
+	admin := []grpc.UnaryServerInterceptor{
+		server.RequestIDInterceptor(log),
+		server.GrpcMetricsInterceptor(m, log),
+		server.RecoveryInterceptor(log, m.RecordPanic),
+	}
+	adminStream := []grpc.StreamServerInterceptor{
+		server.RequestIDStreamInterceptor(log),
+		server.GrpcMetricsStreamInterceptor(m, log),
+		server.RecoveryStreamInterceptor(log, m.RecordPanic),
+	}
+	if *rbacConfig != "" {
+		rbac, err := server.LoadRBAC(*rbacConfig)
+		if err != nil {
+			log.Fatal("Failed to load RBAC config").Str("path", *rbacConfig).Err(err).Send()
+		}
+		admin = append(admin, rbac.UnaryInterceptor())
+		adminStream = append(adminStream, rbac.StreamInterceptor())
+		unary = append(unary, rbac.UnaryInterceptor())
+		stream = append(stream, rbac.StreamInterceptor())
+		log.Info("Role-based access control enabled").Str("config", *rbacConfig).Send()
+		if redactor := rbac.Redactor(); redactor != nil {
+			unary = append(unary, server.RedactionInterceptor(redactor))
+			stream = append(stream, server.RedactionStreamInterceptor(redactor))
+			log.Info("Redaction of read responses enabled").Send()
+		}
+	}
+	// Rate limits follow access control, which authenticates the clients they count
+	unary = append(unary, limiter.UnaryInterceptor(), server.ValidationInterceptor(limits), treeStoreServer.ReadOnlyInterceptor())
+	stream = append(stream, limiter.StreamInterceptor(), server.ValidationStreamInterceptor(limits), treeStoreServer.ReadOnlyStreamInterceptor())
 
 	// Create gRPC server with interceptors
 	grpcServer := grpc.NewServer(
 		grpc.MaxRecvMsgSize(100*1024*1024), // 100 MB
 		grpc.MaxSendMsgSize(100*1024*1024), // 100 MB
-		grpc.UnaryInterceptor(server.GrpcMetricsInterceptor(m, log)),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
 	)
 
 	// Register service
 	pb.RegisterTreeStoreServiceServer(grpcServer, treeStoreServer)
 
+	// Follow a leader's WAL when running as a replica
+	replicationCtx, stopReplication := context.WithCancel(context.Background())
+	defer stopReplication()
+	if *replicateFrom != "" {
+		opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+		if *replicationKey != "" {
+			opts = append(opts, grpc.WithStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+				ctx = metadata.AppendToOutgoingContext(ctx, *apiKeyHeader, *replicationKey)
+				return streamer(ctx, desc, cc, method, callOpts...)
+			}))
+		}
+		conn, err := grpc.NewClient(*replicateFrom, opts...)
+		if err != nil {
+			log.Fatal("Failed to connect to leader").Str("leader", *replicateFrom).Err(err).Send()
+		}
+		defer conn.Close()
+
+		go func() {
+			err := treeStoreServer.Follow(replicationCtx, server.FollowerConfig{
+				Leader:        pb.NewTreeStoreServiceClient(conn),
+				RetryInterval: *replicationRetry,
+				OnError: func(err error) {
+					log.Warn("Replication stream interrupted").Str("leader", *replicateFrom).Err(err).Send()
+				},
+			})
+			if err != nil && replicationCtx.Err() == nil {
+				log.Fatal("Replication stopped").Str("leader", *replicateFrom).Err(err).Send()
+			}
+		}()
+		log.Info("Following leader as read-only replica").Str("leader", *replicateFrom).Send()
+	}
+
 	// Register reflection service for grpcurl/grpcui
-	reflection.Register(grpcServer)
-	log.Info("gRPC reflection enabled").Send()
+	if *grpcReflection {
+		if registerReflection(grpcServer) {
+			log.Info("gRPC reflection enabled").Send()
+		} else {
+			log.Warn("gRPC reflection requested but this binary was built without it").Send()
+		}
+	}
+
+	// Serve operational commands on their own port, away from client traffic
+	var adminServer *grpc.Server
+	if *adminPort != 0 {
+		adminLis, err := net.Listen("tcp", fmt.Sprintf(":%d", *adminPort))
+		if err != nil {
+			log.Fatal("Failed to create admin listener").Err(err).Send()
+		}
+		adminServer = grpc.NewServer(grpc.ChainUnaryInterceptor(admin...), grpc.ChainStreamInterceptor(adminStream...))
+		pb.RegisterTreeStoreAdminServer(adminServer, treeStoreServer.Admin())
+		if *grpcReflection {
+			registerReflection(adminServer)
+		}
+		go func() {
+			if err := adminServer.Serve(adminLis); err != nil {
+				log.Error("Admin server failed").Err(err).Send()
+			}
+		}()
+		log.Info("Admin service started").Int("admin_port", *adminPort).Send()
+	}
 
 	// Start observability HTTP server (metrics + pprof)
-	obsServer := server.NewObservabilityServer(*metricsPort, log)
+	allow, err := server.ParseAllowlist(*debugAllow)
+	if err != nil {
+		log.Fatal("Invalid -debug-allow").Err(err).Send()
+	}
+	obsServer := server.NewObservabilityServer(server.ObservabilityConfig{
+		Host:          *metricsHost,
+		Port:          *metricsPort,
+		Profiling:     *enableProfiling,
+		DebugUser:     *debugUser,
+		DebugPassword: *debugPassword,
+		DebugAllow:    allow,
+	}, log)
+	obsURL := "http://" + net.JoinHostPort(cmp.Or(*metricsHost, "localhost"), strconv.Itoa(*metricsPort))
+	if *debugVars {
+		obsServer.HandleDebug(server.DebugVarsPath, treeStoreServer.DebugVars())
+		log.Info("Debug vars enabled").Str("url", obsURL+server.DebugVarsPath).Send()
+	}
+	if *webUI {
+		obsServer.Handle(server.WebUIPath, treeStoreServer.WebUI())
+		log.Info("Web UI enabled").Str("url", obsURL+server.WebUIPath).Send()
+	}
+	if *retrieverHTTP {
+		conn, err := grpc.NewClient(net.JoinHostPort("localhost", strconv.Itoa(*grpcPort)), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			log.Fatal("Failed to connect retriever to gRPC port").Err(err).Send()
+		}
+		defer conn.Close()
+		obsServer.Handle(server.RetrieverPath, server.RetrieverHTTP(pb.NewTreeStoreServiceClient(conn), *apiKeyHeader))
+		log.Info("Retriever enabled").Str("url", obsURL+server.RetrieverPath).Send()
+	}
+	if *mcpMode == "http" {
+		obsServer.Handle(server.MCPPath, treeStoreServer.MCPHandler())
+		log.Info("MCP enabled").Str("url", obsURL+server.MCPPath).Send()
+	}
 	go func() {
 		if err := obsServer.Start(); err != nil {
 			log.Error("Observability server failed").Err(err).Send()
@@ -94,6 +616,17 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	// The MCP client closing stdin stops the server, as it expects of a server it started
+	if *mcpMode == "stdio" {
+		log.Info("MCP enabled on stdio").Send()
+		go func() {
+			if err := treeStoreServer.ServeMCP(context.Background(), os.Stdin, os.Stdout); err != nil {
+				log.Error("MCP stdio transport failed").Err(err).Send()
+			}
+			sigChan <- syscall.SIGTERM
+		}()
+	}
+
 	go func() {
 		<-sigChan
 		log.Info("Received shutdown signal").Send()
@@ -101,7 +634,12 @@ func main() {
 
 		// Graceful shutdown
 		log.Info("Stopping gRPC server...").Send()
+		stopReplication()
+		treeStoreServer.StopWatches()
 		grpcServer.GracefulStop()
+		if adminServer != nil {
+			adminServer.GracefulStop()
+		}
 
 		// Shutdown observability server
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -116,13 +654,15 @@ func main() {
 	log.Info("gRPC server starting").Int("port", *grpcPort).Send()
 	log.LogServerReady(*grpcPort)
 
-	log.Info("TreeStore server ready to accept connections").
+	ready := log.Info("TreeStore server ready to accept connections").
 		Int("grpc_port", *grpcPort).
 		Int("metrics_port", *metricsPort).
-		Str("metrics_endpoint", fmt.Sprintf("http://localhost:%d/metrics", *metricsPort)).
-		Str("health_endpoint", fmt.Sprintf("http://localhost:%d/health", *metricsPort)).
-		Str("pprof_endpoint", fmt.Sprintf("http://localhost:%d/debug/pprof/", *metricsPort)).
-		Msg("Server ready")
+		Str("metrics_endpoint", obsURL+"/metrics").
+		Str("health_endpoint", obsURL+"/health")
+	if obsServer.Profiling() {
+		ready = ready.Str("pprof_endpoint", obsURL+"/debug/pprof/")
+	}
+	ready.Msg("Server ready")
 
 	if err := grpcServer.Serve(lis); err != nil {
 		log.Fatal("Failed to serve gRPC").Err(err).Send()
@@ -0,0 +1,15 @@
+//go:build !nodebug
+
+// gRPC reflection, left out of binaries built with the nodebug tag
+package main
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// registerReflection serves reflection on s for grpcurl and grpcui and reports that it did
+func registerReflection(s *grpc.Server) bool {
+	reflection.Register(s)
+	return true
+}
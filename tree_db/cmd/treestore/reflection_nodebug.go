@@ -0,0 +1,11 @@
+//go:build nodebug
+
+// Stub for binaries built with the nodebug tag, which leave out gRPC reflection
+package main
+
+import "google.golang.org/grpc"
+
+// registerReflection reports that reflection is not available
+func registerReflection(*grpc.Server) bool {
+	return false
+}
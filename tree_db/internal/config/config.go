@@ -0,0 +1,159 @@
+// Package config sets server flags from a YAML file and the environment, so a
+// deployment can keep its settings in one file instead of a long command line.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// EnvPrefix starts the environment variable of every flag: -sync-interval is
+// read from TREESTORE_SYNC_INTERVAL
+const EnvPrefix = "TREESTORE_"
+
+// EnvName returns the environment variable that sets a flag
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// Apply sets the flags of fs that were not given on the command line, first
+// from the environment, then from the YAML file named by configFlag. The
+// command line overrides the environment, which overrides the file. Call it
+// after fs.Parse; lookupEnv is normally os.LookupEnv.
+//
+// File keys are flag names. Nested mappings join their keys with "-" and "_"
+// may stand for "-", so "retention: {tool_results: 24h}" sets
+// -retention-tool-results. Lists are joined with commas. Keys that name no
+// flag are an error, so a typo is not silently ignored.
+func Apply(fs *flag.FlagSet, configFlag string, lookupEnv func(string) (string, bool)) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || set[f.Name] {
+			return
+		}
+		name := EnvName(f.Name)
+		if val, ok := lookupEnv(name); ok {
+			if setErr := fs.Set(f.Name, val); setErr != nil {
+				err = fmt.Errorf("%s: %v", name, setErr)
+			}
+			set[f.Name] = true
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	path := ""
+	if f := fs.Lookup(configFlag); f != nil {
+		path = f.Value.String()
+	}
+	if path == "" {
+		return nil
+	}
+	settings, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == configFlag || fs.Lookup(name) == nil {
+			return fmt.Errorf("%s: unknown setting %q", path, name)
+		}
+		if set[name] {
+			continue
+		}
+		if err := fs.Set(name, settings[name]); err != nil {
+			return fmt.Errorf("%s: %s: %v", path, name, err)
+		}
+	}
+	return nil
+}
+
+// Load reads a YAML config file into flag values keyed by flag name
+func Load(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[interface{}]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	settings := make(map[string]string)
+	if err := flatten(settings, "", doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return settings, nil
+}
+
+// flatten adds the settings of a mapping, prefixing nested keys with theirs
+func flatten(settings map[string]string, prefix string, m map[interface{}]interface{}) error {
+	for k, v := range m {
+		name := prefix + strings.ReplaceAll(fmt.Sprint(k), "_", "-")
+		if sub, ok := v.(map[interface{}]interface{}); ok {
+			if err := flatten(settings, name+"-", sub); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, dup := settings[name]; dup {
+			return fmt.Errorf("%q is set more than once", name)
+		}
+		settings[name] = value(v)
+	}
+	return nil
+}
+
+// value formats a YAML scalar or list as a flag value
+func value(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = value(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Defaults sets the flags of fs named in defaults that have not been set, so a
+// profile flag can change other defaults while explicit settings still win.
+// Call it after Apply, which marks flags taken from the environment or file
+// as set.
+func Defaults(fs *flag.FlagSet, defaults map[string]string) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if set[name] {
+			continue
+		}
+		if err := fs.Set(name, defaults[name]); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
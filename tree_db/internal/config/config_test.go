@@ -0,0 +1,133 @@
+// Tests for loading flags from a config file and the environment
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFlags() (*flag.FlagSet, map[string]interface{}) {
+	fs := flag.NewFlagSet("treestore", flag.ContinueOnError)
+	return fs, map[string]interface{}{
+		"config":                 fs.String("config", "", ""),
+		"port":                   fs.Int("port", 50051, ""),
+		"sync":                   fs.String("sync", "always", ""),
+		"retention-tool-results": fs.Duration("retention-tool-results", 0, ""),
+		"audit":                  fs.Bool("audit", true, ""),
+		"rate-limit-read":        fs.Float64("rate-limit-read", 0, ""),
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "treestore.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestApply(t *testing.T) {
+	path := writeConfig(t, `
+port: 6000
+sync: never
+audit: false
+retention:
+  tool_results: 24h
+rate-limit:
+  read: 2.5
+`)
+	fs, flags := testFlags()
+	env := map[string]string{"TREESTORE_SYNC": "interval"}
+	if err := fs.Parse([]string{"-config", path, "-port", "7000"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	err := Apply(fs, "config", func(name string) (string, bool) {
+		val, ok := env[name]
+		return val, ok
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	// The command line beats the environment, which beats the file
+	if got := *flags["port"].(*int); got != 7000 {
+		t.Errorf("Expected the command line port, got %d", got)
+	}
+	if got := *flags["sync"].(*string); got != "interval" {
+		t.Errorf("Expected the environment's sync policy, got %s", got)
+	}
+	if got := *flags["retention-tool-results"].(*time.Duration); got != 24*time.Hour {
+		t.Errorf("Expected the nested retention setting, got %v", got)
+	}
+	if *flags["audit"].(*bool) || *flags["rate-limit-read"].(*float64) != 2.5 {
+		t.Errorf("Expected audit off and a read rate of 2.5, got %v and %v", *flags["audit"].(*bool), *flags["rate-limit-read"].(*float64))
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	noEnv := func(string) (string, bool) { return "", false }
+	for name, tc := range map[string]struct {
+		config string
+		want   string
+	}{
+		"unknown key":  {"prot: 6000\n", `unknown setting "prot"`},
+		"bad value":    {"port: many\n", "port"},
+		"set twice":    {"rate_limit_read: 1\nrate-limit:\n  read: 2\n", "more than once"},
+		"config":       {"config: other.yaml\n", `unknown setting "config"`},
+		"invalid yaml": {"port: [\n", "treestore.yaml"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fs, _ := testFlags()
+			fs.Parse([]string{"-config", writeConfig(t, tc.config)})
+			if err := Apply(fs, "config", noEnv); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected an error mentioning %s, got %v", tc.want, err)
+			}
+		})
+	}
+
+	// The config file can be named by the environment too
+	fs, flags := testFlags()
+	fs.Parse(nil)
+	path := writeConfig(t, "port: 6000\n")
+	err := Apply(fs, "config", func(name string) (string, bool) { return path, name == EnvName("config") })
+	if err != nil || *flags["port"].(*int) != 6000 {
+		t.Errorf("Expected the config from TREESTORE_CONFIG, got port %d (%v)", *flags["port"].(*int), err)
+	}
+
+	fs, _ = testFlags()
+	fs.Parse(nil)
+	err = Apply(fs, "config", func(name string) (string, bool) { return "soon", name == "TREESTORE_RETENTION_TOOL_RESULTS" })
+	if err == nil || !strings.Contains(err.Error(), "TREESTORE_RETENTION_TOOL_RESULTS") {
+		t.Errorf("Expected an error naming the variable, got %v", err)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	fs, flags := testFlags()
+	if err := fs.Parse([]string{"-port", "7000"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	err := Apply(fs, "config", func(name string) (string, bool) {
+		return "interval", name == "TREESTORE_SYNC"
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	// Only flags left at their defaults change
+	if err := Defaults(fs, map[string]string{"port": "1", "sync": "never", "audit": "false"}); err != nil {
+		t.Fatalf("Defaults failed: %v", err)
+	}
+	if *flags["port"].(*int) != 7000 || *flags["sync"].(*string) != "interval" || *flags["audit"].(*bool) {
+		t.Errorf("Expected port 7000, sync interval and audit off, got %d, %s and %v",
+			*flags["port"].(*int), *flags["sync"].(*string), *flags["audit"].(*bool))
+	}
+
+	if err := Defaults(fs, map[string]string{"missing": "1"}); err == nil {
+		t.Error("Expected an unknown flag to fail")
+	}
+}
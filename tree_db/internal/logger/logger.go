@@ -2,6 +2,8 @@
 package logger
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"os"
 	"time"
@@ -21,6 +23,7 @@ type Config struct {
 	Pretty     bool   // pretty-print for development
 	Output     io.Writer
 	WithCaller bool
+	Ring       *Ring // Also receives every event as JSON, for tailing
 }
 
 // NewLogger creates a new structured logger
@@ -53,6 +56,11 @@ func NewLogger(cfg Config) *Logger {
 		}
 	}
 
+	// Keep recent events for tailing, in JSON whether or not output is pretty
+	if cfg.Ring != nil {
+		output = zerolog.MultiLevelWriter(output, cfg.Ring)
+	}
+
 	// Create logger
 	zlog := zerolog.New(output).
 		With().
@@ -107,6 +115,23 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 	return &Logger{zlog: ctx.Logger()}
 }
 
+// contextKey is the context key under which a call carries its logger
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l, for FromContext
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, such as one with the call's
+// request ID, or fallback when it carries none
+func FromContext(ctx context.Context, fallback *Logger) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return fallback
+}
+
 // GrpcLogger returns a logger for gRPC operations
 func (l *Logger) GrpcLogger(method string) *Logger {
 	return &Logger{
@@ -188,6 +213,33 @@ func (l *Logger) LogServerShutdown() {
 		Msg("TreeStore server shutting down")
 }
 
+// levels are the log levels accepted by Config.Level and SetLevel
+var levels = map[string]zerolog.Level{
+	"debug": zerolog.DebugLevel,
+	"info":  zerolog.InfoLevel,
+	"warn":  zerolog.WarnLevel,
+	"error": zerolog.ErrorLevel,
+}
+
+// SetLevel changes the level of every logger while running
+func SetLevel(level string) error {
+	l, ok := levels[level]
+	if !ok {
+		return unknownLevel(level)
+	}
+	zerolog.SetGlobalLevel(l)
+	return nil
+}
+
+func unknownLevel(level string) error {
+	return fmt.Errorf("unknown log level %q (want debug, info, warn or error)", level)
+}
+
+// Level returns the current log level
+func Level() string {
+	return zerolog.GlobalLevel().String()
+}
+
 // Global logger instance
 var globalLogger *Logger
 
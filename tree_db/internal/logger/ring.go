@@ -0,0 +1,186 @@
+// In-memory ring buffer of recent log events, for tailing a running server
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultTailBuffer is the number of events a Tail holds for a slow reader
+// before it starts dropping them
+const DefaultTailBuffer = 256
+
+// Entry is one log event kept by a Ring
+type Entry struct {
+	Time    time.Time
+	Level   zerolog.Level
+	Message string // The event's message, or its "msg" field when it has none
+	Method  string // The "method" field of gRPC events
+	JSON    []byte // The event as written
+}
+
+// Filter selects log events
+type Filter struct {
+	MinLevel zerolog.Level // Events below it are skipped; the zero value is debug
+	Method   string        // Substring of the method, such as "GetNode"; empty matches every event
+}
+
+// Match reports whether e passes the filter
+func (f Filter) Match(e Entry) bool {
+	if e.Level < f.MinLevel {
+		return false
+	}
+	return f.Method == "" || strings.Contains(e.Method, f.Method)
+}
+
+// ParseFilter builds a filter from a level name (empty for every level) and
+// a method substring
+func ParseFilter(level, method string) (Filter, error) {
+	f := Filter{MinLevel: zerolog.DebugLevel, Method: method}
+	if level == "" {
+		return f, nil
+	}
+	l, ok := levels[level]
+	if !ok {
+		return Filter{}, unknownLevel(level)
+	}
+	f.MinLevel = l
+	return f, nil
+}
+
+// Ring keeps the most recent log events and passes new ones to tails. Set it
+// as Config.Ring; events below the global level never reach it.
+type Ring struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int // Slot of the next event
+	full    bool
+	tails   map[*Tail]struct{}
+}
+
+// NewRing returns a ring keeping the last size events
+func NewRing(size int) *Ring {
+	return &Ring{
+		entries: make([]Entry, size),
+		tails:   make(map[*Tail]struct{}),
+	}
+}
+
+// Write records one JSON event; zerolog writes each event in a single call
+// It never blocks on tails, so logging does not wait for a slow reader.
+func (r *Ring) Write(p []byte) (int, error) {
+	var fields struct {
+		Time    time.Time `json:"time"`
+		Level   string    `json:"level"`
+		Message string    `json:"message"`
+		Msg     string    `json:"msg"`
+		Method  string    `json:"method"`
+	}
+	// Events that do not parse are still kept, with what could be read
+	json.Unmarshal(p, &fields)
+	level, err := zerolog.ParseLevel(fields.Level)
+	if err != nil {
+		level = zerolog.NoLevel
+	}
+	e := Entry{
+		Time:    fields.Time,
+		Level:   level,
+		Message: fields.Message,
+		Method:  fields.Method,
+		JSON:    append([]byte(nil), p...),
+	}
+	if e.Message == "" {
+		e.Message = fields.Msg
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) > 0 {
+		r.entries[r.next] = e
+		r.next = (r.next + 1) % len(r.entries)
+		r.full = r.full || r.next == 0
+	}
+	for t := range r.tails {
+		if !t.filter.Match(e) {
+			continue
+		}
+		select {
+		case t.c <- e:
+		default:
+			t.dropped++
+		}
+	}
+	return len(p), nil
+}
+
+// Recent returns up to limit of the kept events passing f, oldest first;
+// limit 0 returns all of them
+func (r *Ring) Recent(f Filter, limit int) []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.recent(f, limit)
+}
+
+func (r *Ring) recent(f Filter, limit int) []Entry {
+	var kept []Entry
+	if r.full {
+		kept = append(kept, r.entries[r.next:]...)
+	}
+	kept = append(kept, r.entries[:r.next]...)
+
+	var out []Entry
+	for _, e := range kept {
+		if f.Match(e) {
+			out = append(out, e)
+		}
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out
+}
+
+// Tail returns up to limit kept events passing f, as Recent does, and a tail
+// receiving the events written after them. Close the tail when done.
+func (r *Ring) Tail(f Filter, limit, buffer int) ([]Entry, *Tail) {
+	if buffer <= 0 {
+		buffer = DefaultTailBuffer
+	}
+	t := &Tail{ring: r, filter: f, c: make(chan Entry, buffer)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tails[t] = struct{}{}
+	return r.recent(f, limit), t
+}
+
+// Tail receives new events from a Ring
+type Tail struct {
+	ring    *Ring
+	filter  Filter
+	c       chan Entry
+	dropped uint64 // Guarded by ring.mu
+}
+
+// Events delivers new events passing the tail's filter
+func (t *Tail) Events() <-chan Entry {
+	return t.c
+}
+
+// Dropped returns the events skipped so far because Events was full
+func (t *Tail) Dropped() uint64 {
+	t.ring.mu.Lock()
+	defer t.ring.mu.Unlock()
+	return t.dropped
+}
+
+// Close stops delivery to the tail
+func (t *Tail) Close() {
+	t.ring.mu.Lock()
+	defer t.ring.mu.Unlock()
+	delete(t.ring.tails, t)
+}
@@ -0,0 +1,65 @@
+// Tests for the ring buffer of recent log events
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRing(t *testing.T) {
+	defer SetLevel(Level())
+	ring := NewRing(3)
+	var out bytes.Buffer
+	log := NewLogger(Config{Level: "debug", Pretty: true, Output: &out, Ring: ring})
+
+	log.Debug("starting").Send()
+	log.LogGrpcRequest("/treestore.TreeStoreService/GetNode", 0, nil)
+	log.Warn("slow").Str("method", "/treestore.TreeStoreService/Search").Send()
+	log.Error("failed").Send()
+
+	// The oldest event is overwritten; the ring gets JSON while output is pretty
+	all := ring.Recent(Filter{}, 0)
+	if len(all) != 3 || all[0].Message != "gRPC request completed" || all[2].Message != "failed" {
+		t.Fatalf("Expected the last 3 events oldest first, got %+v", all)
+	}
+	if !strings.HasPrefix(string(all[2].JSON), "{") || strings.Contains(out.String(), `"level"`) {
+		t.Errorf("Expected JSON in the ring and pretty output, got %s and %s", all[2].JSON, out.String())
+	}
+
+	filter, err := ParseFilter("warn", "")
+	if err != nil {
+		t.Fatalf("ParseFilter failed: %v", err)
+	}
+	if got := ring.Recent(filter, 0); len(got) != 2 || got[0].Level != zerolog.WarnLevel {
+		t.Errorf("Expected the warning and the error, got %+v", got)
+	}
+	if got := ring.Recent(Filter{Method: "GetNode"}, 0); len(got) != 1 || got[0].Level != zerolog.InfoLevel {
+		t.Errorf("Expected the GetNode request, got %+v", got)
+	}
+	if got := ring.Recent(Filter{}, 1); len(got) != 1 || got[0].Message != "failed" {
+		t.Errorf("Expected the newest event, got %+v", got)
+	}
+	if _, err := ParseFilter("loud", ""); err == nil {
+		t.Error("Expected an unknown level rejected")
+	}
+
+	// Tails receive later events passing their filter and drop what they cannot hold
+	recent, tail := ring.Tail(Filter{Method: "Search"}, 0, 1)
+	if len(recent) != 1 {
+		t.Errorf("Expected the buffered Search event, got %+v", recent)
+	}
+	log.Info("unrelated").Send()
+	log.Info("first").Str("method", "Search").Send()
+	log.Info("second").Str("method", "Search").Send()
+	if e := <-tail.Events(); e.Message != "first" || tail.Dropped() != 1 {
+		t.Errorf("Expected the first Search event and one dropped, got %+v and %d", e, tail.Dropped())
+	}
+	tail.Close()
+	log.Info("third").Str("method", "Search").Send()
+	if len(tail.Events()) != 0 {
+		t.Error("Expected no events after Close")
+	}
+}
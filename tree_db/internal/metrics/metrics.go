@@ -2,6 +2,8 @@
 package metrics
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -29,15 +31,97 @@ type Metrics struct {
 	SearchResultsTotal   prometheus.Counter
 	SubtreeQueriesTotal  prometheus.Counter
 
+	// Latency of reads labelled by the size of their result, so a breached
+	// SLO can be traced to a slow server or to a huge query
+	SearchDuration  *prometheus.HistogramVec
+	SubtreeDuration *prometheus.HistogramVec
+
 	// Version metrics
 	VersionQueriesTotal prometheus.Counter
 	TemporalLookupsTotal prometheus.Counter
 
+	// Retention metrics
+	RetentionSweepsTotal    prometheus.Counter
+	RetentionReclaimedTotal *prometheus.CounterVec
+
+	// Canary metrics
+	CanaryChecksTotal    *prometheus.CounterVec
+	CanaryAnomaliesTotal *prometheus.CounterVec
+
+	// Analysis job metrics
+	JobAttemptsTotal *prometheus.CounterVec
+
+	// Rate limiting metrics
+	RateLimitedTotal *prometheus.CounterVec
+
+	// Panic recovery metrics
+	PanicsRecoveredTotal *prometheus.CounterVec
+
+	// Checkpoint metrics, registered by ObserveCheckpoints
+	WalCheckpointLagEntries prometheus.GaugeFunc
+	WalCheckpointLagSeconds prometheus.GaugeFunc
+	WalLogFiles             prometheus.GaugeFunc
+
+	// Query cache metrics, registered by ObserveQueryCache
+	QueryCacheHitsTotal          prometheus.CounterFunc
+	QueryCacheMissesTotal        prometheus.CounterFunc
+	QueryCacheInvalidationsTotal prometheus.CounterFunc
+	QueryCacheEntries            prometheus.GaugeFunc
+
 	// Server metrics
 	ServerUptimeSeconds prometheus.Gauge
 	ServerStartTime     time.Time
 }
 
+// CheckpointLag is how far the WAL runs ahead of the database file
+type CheckpointLag struct {
+	Entries  uint64        // WAL entries not yet flushed to the database file
+	Age      time.Duration // Time since the last flush, zero without lag
+	LogFiles int           // Live WAL files kept
+}
+
+// QueryCacheStats counts query result cache activity
+type QueryCacheStats struct {
+	Hits          int64
+	Misses        int64
+	Invalidations int64 // Results dropped by change events
+	Entries       int
+}
+
+// latencyBuckets are the histogram buckets of storage and read latencies
+var latencyBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// sizeBuckets bound the result size labels of read latencies; each order of
+// magnitude is its own series
+var sizeBuckets = []int{0, 10, 100, 1000, 10000}
+
+// maxDepthLabel is the deepest subtree with a label of its own; deeper ones
+// share one
+const maxDepthLabel = 9
+
+// sizeLabel returns the size bucket of n results, such as "11-100"
+func sizeLabel(n int) string {
+	low := 0
+	for _, high := range sizeBuckets {
+		if n <= high {
+			if high == 0 {
+				return "0"
+			}
+			return fmt.Sprintf("%d-%d", low, high)
+		}
+		low = high + 1
+	}
+	return fmt.Sprintf(">%d", sizeBuckets[len(sizeBuckets)-1])
+}
+
+// depthLabel returns the label of a subtree spanning depth levels below its root
+func depthLabel(depth int) string {
+	if depth > maxDepthLabel {
+		return fmt.Sprintf(">%d", maxDepthLabel)
+	}
+	return strconv.Itoa(depth)
+}
+
 // NewMetrics creates and registers all Prometheus metrics
 func NewMetrics() *Metrics {
 	m := &Metrics{
@@ -82,7 +166,7 @@ func NewMetrics() *Metrics {
 		prometheus.HistogramOpts{
 			Name:    "treestore_db_operation_duration_seconds",
 			Help:    "Duration of database operations in seconds",
-			Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+			Buckets: latencyBuckets,
 		},
 		[]string{"operation"},
 	)
@@ -144,6 +228,24 @@ func NewMetrics() *Metrics {
 		},
 	)
 
+	m.SearchDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "treestore_search_duration_seconds",
+			Help:    "Duration of keyword searches in seconds, by method and bucket of results returned",
+			Buckets: latencyBuckets,
+		},
+		[]string{"method", "results"},
+	)
+
+	m.SubtreeDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "treestore_subtree_duration_seconds",
+			Help:    "Duration of subtree reads in seconds, by bucket of nodes returned and levels below the root",
+			Buckets: latencyBuckets,
+		},
+		[]string{"nodes", "depth"},
+	)
+
 	// Version metrics
 	m.VersionQueriesTotal = promauto.NewCounter(
 		prometheus.CounterOpts{
@@ -159,6 +261,66 @@ func NewMetrics() *Metrics {
 		},
 	)
 
+	// Retention metrics
+	m.RetentionSweepsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "treestore_retention_sweeps_total",
+			Help: "Total number of retention sweeps",
+		},
+	)
+
+	m.RetentionReclaimedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "treestore_retention_reclaimed_total",
+			Help: "Total number of expired entities reclaimed by retention (or found, in dry-run mode)",
+		},
+		[]string{"entity_type", "mode"},
+	)
+
+	// Canary metrics
+	m.CanaryChecksTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "treestore_canary_checks_total",
+			Help: "Total number of recently written records re-read by the canary",
+		},
+		[]string{"record"},
+	)
+
+	m.CanaryAnomaliesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "treestore_canary_anomalies_total",
+			Help: "Total number of re-read records that failed to decode",
+		},
+		[]string{"record"},
+	)
+
+	// Analysis job metrics
+	m.JobAttemptsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "treestore_job_attempts_total",
+			Help: "Total number of analysis job attempts by outcome (succeeded, retrying or failed)",
+		},
+		[]string{"job", "outcome"},
+	)
+
+	// Rate limiting metrics
+	m.RateLimitedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "treestore_rate_limited_total",
+			Help: "Total number of requests rejected by the rate limiter",
+		},
+		[]string{"method", "budget"},
+	)
+
+	// Panic recovery metrics
+	m.PanicsRecoveredTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "treestore_panics_recovered_total",
+			Help: "Total number of panics in gRPC handlers converted to Internal errors",
+		},
+		[]string{"method"},
+	)
+
 	// Server metrics
 	m.ServerUptimeSeconds = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -201,3 +363,123 @@ func (m *Metrics) UpdateDbStats(sizeBytes int64, nodeCount int64, docCount int64
 	m.DbNodesTotal.Set(float64(nodeCount))
 	m.DbDocumentsTotal.Set(float64(docCount))
 }
+
+// RecordSearch records a keyword search by the results it returned
+func (m *Metrics) RecordSearch(method string, results int, duration time.Duration) {
+	m.SearchQueriesTotal.Inc()
+	m.SearchResultsTotal.Add(float64(results))
+	m.SearchDuration.WithLabelValues(method, sizeLabel(results)).Observe(duration.Seconds())
+}
+
+// RecordSubtree records a subtree read by the nodes it returned and the levels
+// they span below the root
+func (m *Metrics) RecordSubtree(nodes, depth int, duration time.Duration) {
+	m.SubtreeQueriesTotal.Inc()
+	m.SubtreeDuration.WithLabelValues(sizeLabel(nodes), depthLabel(depth)).Observe(duration.Seconds())
+}
+
+// RecordRetentionSweep records the entities reclaimed by one retention sweep
+func (m *Metrics) RecordRetentionSweep(reclaimed map[string]int, dryRun bool) {
+	mode := "delete"
+	if dryRun {
+		mode = "dry_run"
+	}
+
+	m.RetentionSweepsTotal.Inc()
+	for entityType, n := range reclaimed {
+		m.RetentionReclaimedTotal.WithLabelValues(entityType, mode).Add(float64(n))
+	}
+}
+
+// RecordCanaryCheck records the records one canary pass re-read and the
+// anomalies it found, by record kind
+func (m *Metrics) RecordCanaryCheck(checked, anomalies map[string]int) {
+	for kind, n := range checked {
+		m.CanaryChecksTotal.WithLabelValues(kind).Add(float64(n))
+	}
+	for kind, n := range anomalies {
+		m.CanaryAnomaliesTotal.WithLabelValues(kind).Add(float64(n))
+	}
+}
+
+// RecordJobAttempt records one attempt of an analysis job and the state it
+// left the run in
+func (m *Metrics) RecordJobAttempt(job, outcome string) {
+	m.JobAttemptsTotal.WithLabelValues(job, outcome).Inc()
+}
+
+// ObserveCheckpoints exports the checkpoint lag reported by lag, read at each scrape
+func (m *Metrics) ObserveCheckpoints(lag func() CheckpointLag) {
+	m.WalCheckpointLagEntries = promauto.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "treestore_wal_checkpoint_lag_entries",
+			Help: "WAL entries written since the last flush of the database file",
+		},
+		func() float64 { return float64(lag().Entries) },
+	)
+
+	m.WalCheckpointLagSeconds = promauto.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "treestore_wal_checkpoint_lag_seconds",
+			Help: "Seconds since the last flush of the database file while WAL entries wait",
+		},
+		func() float64 { return lag().Age.Seconds() },
+	)
+
+	m.WalLogFiles = promauto.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "treestore_wal_log_files",
+			Help: "Live WAL files, kept past the usual count while flushes lag",
+		},
+		func() float64 { return float64(lag().LogFiles) },
+	)
+}
+
+// ObserveQueryCache exports the query cache counters reported by stats, read at each scrape
+func (m *Metrics) ObserveQueryCache(stats func() QueryCacheStats) {
+	m.QueryCacheHitsTotal = promauto.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: "treestore_query_cache_hits_total",
+			Help: "Queries answered from the result cache",
+		},
+		func() float64 { return float64(stats().Hits) },
+	)
+
+	m.QueryCacheMissesTotal = promauto.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: "treestore_query_cache_misses_total",
+			Help: "Queries run against the stores because no fresh result was cached",
+		},
+		func() float64 { return float64(stats().Misses) },
+	)
+
+	m.QueryCacheInvalidationsTotal = promauto.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: "treestore_query_cache_invalidations_total",
+			Help: "Cached query results dropped by writes reported on the change feed",
+		},
+		func() float64 { return float64(stats().Invalidations) },
+	)
+
+	m.QueryCacheEntries = promauto.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "treestore_query_cache_entries",
+			Help: "Query results currently cached",
+		},
+		func() float64 { return float64(stats().Entries) },
+	)
+}
+
+// RecordRateLimited records a request rejected by the rate limiter
+func (m *Metrics) RecordRateLimited(method string, write bool) {
+	budget := "read"
+	if write {
+		budget = "write"
+	}
+	m.RateLimitedTotal.WithLabelValues(method, budget).Inc()
+}
+
+// RecordPanic records a handler panic that was converted to an error
+func (m *Metrics) RecordPanic(method string) {
+	m.PanicsRecoveredTotal.WithLabelValues(method).Inc()
+}
@@ -0,0 +1,31 @@
+// Tests for metric label bucketing
+package metrics
+
+import "testing"
+
+func TestSizeLabel(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0"},
+		{1, "1-10"},
+		{10, "1-10"},
+		{11, "11-100"},
+		{100, "11-100"},
+		{101, "101-1000"},
+		{1000, "101-1000"},
+		{1001, "1001-10000"},
+		{1023, "1001-10000"},
+		{1024, "1001-10000"},
+		{10000, "1001-10000"},
+		{10001, ">10000"},
+		{1 << 20, ">10000"},
+		{1 << 30, ">10000"},
+	}
+	for _, tt := range tests {
+		if got := sizeLabel(tt.n); got != tt.want {
+			t.Errorf("sizeLabel(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+// Operational commands of a running server, served apart from client traffic
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nainya/treestore/internal/logger"
+	"github.com/nainya/treestore/pkg/backup"
+	"github.com/nainya/treestore/pkg/storage"
+	"github.com/nainya/treestore/pkg/wal"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// AdminServer implements the TreeStoreAdmin service of a Server
+type AdminServer struct {
+	pb.UnimplementedTreeStoreAdminServer
+
+	s *Server
+}
+
+// Admin returns the admin service of s, to register on the admin port
+func (s *Server) Admin() *AdminServer {
+	return &AdminServer{s: s}
+}
+
+// maintenance runs fn while no write request, retention sweep or replicated
+// transaction is in progress, holding new ones off until it returns
+func (s *Server) maintenance(fn func() error) error {
+	s.maintMu.Lock()
+	defer s.maintMu.Unlock()
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+	return fn()
+}
+
+// maintenanceLocker holds off writes like maintenance, for background work
+// that takes a sync.Locker
+type maintenanceLocker struct{ s *Server }
+
+func (l maintenanceLocker) Lock() {
+	l.s.maintMu.Lock()
+	l.s.applyMu.Lock()
+}
+
+func (l maintenanceLocker) Unlock() {
+	l.s.applyMu.Unlock()
+	l.s.maintMu.Unlock()
+}
+
+// adminError converts the error of an admin command to a status
+func adminError(err error, action string) error {
+	switch {
+	case errors.Is(err, storage.ErrInMemory),
+		errors.Is(err, storage.ErrLegacyFormat),
+		errors.Is(err, backup.ErrBackupExists),
+		errors.Is(err, backup.ErrNoBaseBackup),
+		errors.Is(err, wal.ErrLSNUnavailable):
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", action, err)
+	}
+	return status.Errorf(codes.Internal, "%s: %v", action, err)
+}
+
+func (a *AdminServer) Checkpoint(ctx context.Context, req *pb.CheckpointRequest) (*pb.CheckpointResponse, error) {
+	a.s.countOp("Checkpoint")
+
+	start := time.Now()
+	if err := a.s.kv.Checkpoint(); err != nil {
+		return nil, adminError(err, "checkpoint failed")
+	}
+	return &pb.CheckpointResponse{
+		LastLsn:    a.s.kv.State().LastLSN,
+		DurationMs: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+func (a *AdminServer) Compact(ctx context.Context, req *pb.CompactRequest) (*pb.CompactResponse, error) {
+	a.s.countOp("Compact")
+
+	var report *storage.AuditReport
+	err := a.s.maintenance(func() (err error) {
+		report, err = a.s.kv.Audit(!req.DryRun)
+		return err
+	})
+	if err != nil {
+		return nil, adminError(err, "compaction failed")
+	}
+	return &pb.CompactResponse{
+		Pages:            report.Pages,
+		TreePages:        int64(report.Tree),
+		FreePages:        int64(report.Free),
+		LeakedPages:      int64(len(report.Leaked)),
+		ReclaimedPages:   int64(report.Reclaimed),
+		ConflictingPages: int64(len(report.Conflicts)),
+	}, nil
+}
+
+func (a *AdminServer) Reindex(ctx context.Context, req *pb.ReindexRequest) (*pb.ReindexResponse, error) {
+	a.s.countOp("Reindex")
+
+	if a.s.readOnly.Load() {
+		return nil, status.Error(codes.FailedPrecondition, "server is a read-only follower; reindex the leader")
+	}
+	a.s.maintMu.RLock()
+	n, err := a.s.docStore.Reindex(req.PolicyId)
+	a.s.maintMu.RUnlock()
+	if err != nil {
+		return nil, adminError(err, "reindex failed")
+	}
+	return &pb.ReindexResponse{NodesIndexed: int64(n)}, nil
+}
+
+func (a *AdminServer) Flush(ctx context.Context, req *pb.FlushRequest) (*pb.FlushResponse, error) {
+	a.s.countOp("Flush")
+
+	pending := a.s.kv.SyncStats().Pending
+	if err := a.s.kv.Flush(); err != nil {
+		return nil, adminError(err, "flush failed")
+	}
+	return &pb.FlushResponse{FlushedCommits: int64(pending)}, nil
+}
+
+func (a *AdminServer) Backup(ctx context.Context, req *pb.BackupRequest) (*pb.BackupResponse, error) {
+	a.s.countOp("Backup")
+
+	if req.Dir == "" {
+		return nil, status.Error(codes.InvalidArgument, "dir is required")
+	}
+	if a.s.kv.InMemory() {
+		return nil, adminError(storage.ErrInMemory, "backup failed")
+	}
+
+	if req.Incremental {
+		seg, err := backup.Incremental(a.s.kv.Path, req.Dir)
+		if err != nil {
+			return nil, adminError(err, "backup failed")
+		}
+		return &pb.BackupResponse{
+			FromLsn: seg.FromLSN,
+			ToLsn:   seg.ToLSN,
+			Entries: int64(seg.Entries),
+			File:    seg.File,
+		}, nil
+	}
+
+	// The snapshot is a copy of the file, so every commit the WAL position
+	// covers must be published in it first
+	var m *backup.Manifest
+	err := a.s.maintenance(func() (err error) {
+		if err := a.s.kv.Flush(); err != nil {
+			return err
+		}
+		m, err = backup.Full(a.s.kv, req.Dir)
+		return err
+	})
+	if err != nil {
+		return nil, adminError(err, "backup failed")
+	}
+	return &pb.BackupResponse{ToLsn: m.BaseLSN, File: backup.SnapshotFile}, nil
+}
+
+func (a *AdminServer) SetLogLevel(ctx context.Context, req *pb.SetLogLevelRequest) (*pb.SetLogLevelResponse, error) {
+	a.s.countOp("SetLogLevel")
+
+	previous := logger.Level()
+	if err := logger.SetLevel(req.Level); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &pb.SetLogLevelResponse{PreviousLevel: previous}, nil
+}
+
+// TailLogs sends the kept log events passing the request's filter and, when
+// following, the new ones until the call ends
+func (a *AdminServer) TailLogs(req *pb.TailLogsRequest, stream pb.TreeStoreAdmin_TailLogsServer) error {
+	a.s.countOp("TailLogs")
+
+	if a.s.logRing == nil {
+		return status.Error(codes.FailedPrecondition, "log tailing is disabled (-log-tail-size=0)")
+	}
+	filter, err := logger.ParseFilter(req.MinLevel, req.Method)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	limit := int(req.Recent)
+	var recent []logger.Entry
+	var tail *logger.Tail
+	if req.Follow {
+		recent, tail = a.s.logRing.Tail(filter, limit, 0)
+		defer tail.Close()
+	} else {
+		recent = a.s.logRing.Recent(filter, limit)
+	}
+	if limit >= 0 {
+		for _, e := range recent {
+			if err := stream.Send(logEventToPb(e, 0)); err != nil {
+				return err
+			}
+		}
+	}
+	if tail == nil {
+		return nil
+	}
+
+	var reported uint64
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-a.s.stopped:
+			return status.Error(codes.Unavailable, "server shutting down")
+		case e := <-tail.Events():
+			dropped := tail.Dropped()
+			if err := stream.Send(logEventToPb(e, dropped-reported)); err != nil {
+				return err
+			}
+			reported = dropped
+		}
+	}
+}
+
+func logEventToPb(e logger.Entry, dropped uint64) *pb.LogEvent {
+	ev := &pb.LogEvent{
+		Level:   e.Level.String(),
+		Message: e.Message,
+		Method:  e.Method,
+		Json:    string(e.JSON),
+		Dropped: dropped,
+	}
+	if !e.Time.IsZero() {
+		ev.Time = timestamppb.New(e.Time)
+	}
+	return ev
+}
+
+func (a *AdminServer) DumpState(ctx context.Context, req *pb.DumpStateRequest) (*pb.DumpStateResponse, error) {
+	a.s.countOp("DumpState")
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return a.s.state(&mem), nil
+}
+
+// DebugVarsPath is where the observability server mounts DebugVars
+const DebugVarsPath = "/debug/vars"
+
+// DebugVars returns a handler writing, in the manner of expvar, the command
+// line, Go memory statistics and the DumpState response as one JSON object
+func (s *Server) DebugVars() http.Handler {
+	marshal := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+		state, err := marshal.Marshal(s.state(&mem))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(map[string]any{
+			"cmdline":   os.Args,
+			"memstats":  &mem,
+			"treestore": json.RawMessage(state),
+		})
+	})
+}
+
+// state reports the database's internals and the server's counters
+func (s *Server) state(mem *runtime.MemStats) *pb.DumpStateResponse {
+	state := s.kv.State()
+
+	resp := &pb.DumpStateResponse{
+		DbPath:             state.Path,
+		InMemory:           state.InMemory,
+		Heap:               state.Heap,
+		Legacy:             state.Legacy,
+		FormatVersion:      state.Format,
+		MigratedFromFormat: state.Migration.From,
+		Encrypted:          state.Encrypted,
+		Pages:              state.Pages,
+		MappedBytes:        int64(state.MappedBytes),
+		MetaGeneration:     state.Generation,
+		LastLsn:            state.LastLSN,
+		SyncPolicy:         state.Sync.Policy.String(),
+		UnflushedCommits:   int64(state.Sync.Pending),
+		Flushes:            state.Sync.Flushes,
+		ReadOnly:           s.readOnly.Load(),
+		LogLevel:           logger.Level(),
+		UptimeSeconds:      int64(time.Since(s.startTime).Seconds()),
+		Goroutines:         int64(runtime.NumGoroutine()),
+		HeapAllocBytes:     mem.HeapAlloc,
+		OperationCounts:    s.operationCounts(),
+	}
+	if !state.Sync.LastFlush.IsZero() {
+		resp.LastFlush = timestamppb.New(state.Sync.LastFlush)
+	}
+	if state.Sync.LastError != nil {
+		resp.LastFlushError = state.Sync.LastError.Error()
+	}
+	resp.WalArchivedFiles = state.WALArchive.Archived
+	resp.WalArchiveFailures = state.WALArchive.Failures
+	if state.WALArchive.LastError != nil {
+		resp.WalArchiveError = state.WALArchive.LastError.Error()
+	}
+	resp.FlushedLsn = state.Checkpoint.FlushedLSN
+	resp.CheckpointLagEntries = state.Checkpoint.LagEntries
+	resp.CheckpointLagMs = state.Checkpoint.LagAge.Milliseconds()
+	resp.Checkpoints = state.Checkpoint.Checkpoints
+	resp.WalFiles = int64(state.Checkpoint.LogFiles)
+	if !state.Checkpoint.LastCheckpoint.IsZero() {
+		resp.LastCheckpoint = timestamppb.New(state.Checkpoint.LastCheckpoint)
+	}
+	if state.Checkpoint.LastError != nil {
+		resp.CheckpointError = state.Checkpoint.LastError.Error()
+	}
+	if s.sweeper != nil {
+		resp.RetentionSweeps = s.sweeper.Stats().Sweeps
+	}
+	if s.compactor != nil {
+		stats := s.compactor.Stats()
+		resp.CompactionPasses = stats.Passes
+		resp.CompactionLeavesMoved = stats.Moved
+	}
+	bloom := s.docStore.BloomStats()
+	resp.BloomChecks = bloom.Checks
+	resp.BloomRejections = bloom.Rejected
+	paths := s.docStore.PathCacheStats()
+	resp.PathCacheHits = paths.Hits
+	resp.PathCacheMisses = paths.Misses
+	cache := s.engine.CacheStats()
+	resp.QueryCacheHits = cache.Hits
+	resp.QueryCacheMisses = cache.Misses
+	resp.QueryCacheInvalidations = cache.Invalidations
+	return resp
+}
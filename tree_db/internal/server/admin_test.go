@@ -0,0 +1,309 @@
+// Tests for the admin service
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nainya/treestore/internal/logger"
+	"github.com/nainya/treestore/pkg/backup"
+	"github.com/nainya/treestore/pkg/query"
+	"github.com/nainya/treestore/pkg/storage"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestAdminService(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "admin.db")
+	server, err := NewServer(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+	admin := server.Admin()
+
+	ctx := context.Background()
+	now := timestamppb.Now()
+	store := func(policyID string) {
+		_, err := server.StoreDocument(ctx, &pb.StoreDocumentRequest{
+			Document: &pb.Document{PolicyId: policyID, RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+			Nodes:    []*pb.Node{{NodeId: "root", PolicyId: policyID, Title: "Prior authorization", CreatedAt: now, UpdatedAt: now}},
+		})
+		if err != nil {
+			t.Fatalf("StoreDocument failed: %v", err)
+		}
+	}
+	store("POL-A")
+
+	checkpoint, err := admin.Checkpoint(ctx, &pb.CheckpointRequest{})
+	if err != nil || checkpoint.LastLsn == 0 {
+		t.Errorf("Checkpoint = %v, %v", checkpoint, err)
+	}
+
+	compact, err := admin.Compact(ctx, &pb.CompactRequest{DryRun: true})
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if compact.Pages == 0 || compact.TreePages == 0 || compact.ConflictingPages != 0 {
+		t.Errorf("Unexpected compaction report %v", compact)
+	}
+
+	if reindex, err := admin.Reindex(ctx, &pb.ReindexRequest{}); err != nil || reindex.NodesIndexed != 1 {
+		t.Errorf("Reindex = %v, %v", reindex, err)
+	}
+	if _, err := admin.Flush(ctx, &pb.FlushRequest{}); err != nil {
+		t.Errorf("Flush failed: %v", err)
+	}
+
+	// A full backup, then one segment of the writes after it
+	dir := filepath.Join(t.TempDir(), "backup")
+	if _, err := admin.Backup(ctx, &pb.BackupRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without a dir, got %v", err)
+	}
+	full, err := admin.Backup(ctx, &pb.BackupRequest{Dir: dir})
+	if err != nil {
+		t.Fatalf("Full backup failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, full.File)); err != nil || full.File != backup.SnapshotFile {
+		t.Errorf("Expected the snapshot at %s: %v", full.File, err)
+	}
+	if _, err := admin.Backup(ctx, &pb.BackupRequest{Dir: dir}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition over an existing backup, got %v", err)
+	}
+	store("POL-B")
+	seg, err := admin.Backup(ctx, &pb.BackupRequest{Dir: dir, Incremental: true})
+	if err != nil {
+		t.Fatalf("Incremental backup failed: %v", err)
+	}
+	if seg.Entries == 0 || seg.FromLsn != full.ToLsn {
+		t.Errorf("Expected a segment following the snapshot, got %v", seg)
+	}
+
+	// The previous level is returned so it can be restored
+	level, err := admin.SetLogLevel(ctx, &pb.SetLogLevelRequest{Level: "warn"})
+	if err != nil || logger.Level() != "warn" {
+		t.Fatalf("SetLogLevel = %v, %v (level %s)", level, err, logger.Level())
+	}
+	defer logger.SetLevel(level.PreviousLevel)
+	if _, err := admin.SetLogLevel(ctx, &pb.SetLogLevelRequest{Level: "loud"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown level, got %v", err)
+	}
+
+	state, err := admin.DumpState(ctx, &pb.DumpStateRequest{})
+	if err != nil {
+		t.Fatalf("DumpState failed: %v", err)
+	}
+	if state.DbPath != dbPath || state.LastLsn < seg.ToLsn || state.LogLevel != "warn" || state.Goroutines == 0 {
+		t.Errorf("Unexpected state %v", state)
+	}
+	if state.OperationCounts["Checkpoint"] != 1 || state.OperationCounts["Backup"] != 4 {
+		t.Errorf("Expected admin commands counted, got %v", state.OperationCounts)
+	}
+}
+
+func TestAdminInMemory(t *testing.T) {
+	server, err := NewServer(storage.MemoryPath)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+	admin := server.Admin()
+	ctx := context.Background()
+
+	if _, err := admin.Checkpoint(ctx, &pb.CheckpointRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for a checkpoint in memory, got %v", err)
+	}
+	if _, err := admin.Backup(ctx, &pb.BackupRequest{Dir: t.TempDir()}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for a backup in memory, got %v", err)
+	}
+	if compact, err := admin.Compact(ctx, &pb.CompactRequest{}); err != nil || compact.LeakedPages != 0 {
+		t.Errorf("Compact = %v, %v", compact, err)
+	}
+}
+
+func TestAdminQueryCacheStats(t *testing.T) {
+	server, err := NewServerWithOptions(storage.MemoryPath, Options{QueryCacheEntries: 8})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+	ctx := context.Background()
+	now := timestamppb.Now()
+
+	store := func(title string) {
+		_, err := server.StoreDocument(ctx, &pb.StoreDocumentRequest{
+			Document: &pb.Document{PolicyId: "POL-Q", RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+			Nodes:    []*pb.Node{{NodeId: "root", PolicyId: "POL-Q", Title: title, CreatedAt: now, UpdatedAt: now}},
+		})
+		if err != nil {
+			t.Fatalf("StoreDocument failed: %v", err)
+		}
+	}
+	store("Prior authorization")
+
+	q := query.NewQueryBuilder(query.QueryDocument).Where("policyID", "POL-Q").Where("nodeID", "root").Build()
+	for i := 0; i < 2; i++ {
+		if _, err := server.engine.Execute(q); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+	}
+
+	// The rewrite reaches the cache through the change feed
+	store("Step therapy")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		state, err := server.Admin().DumpState(ctx, &pb.DumpStateRequest{})
+		if err != nil {
+			t.Fatalf("DumpState failed: %v", err)
+		}
+		if state.QueryCacheInvalidations == 1 {
+			if state.QueryCacheHits != 1 || state.QueryCacheMisses != 1 {
+				t.Errorf("Expected 1 hit and 1 miss, got %v", state)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the cached result invalidated, got %v", state)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	res, err := server.engine.Execute(q)
+	if err != nil || len(res.Documents) != 1 || res.Documents[0].Title != "Step therapy" {
+		t.Errorf("Expected the rewritten node, got %+v (%v)", res, err)
+	}
+}
+
+func TestDebugVars(t *testing.T) {
+	server, err := NewServer(storage.MemoryPath)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+
+	rec := httptest.NewRecorder()
+	server.DebugVars().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DebugVarsPath, nil))
+	var vars struct {
+		Cmdline   []string
+		Memstats  struct{ HeapAlloc uint64 }
+		Treestore map[string]any
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &vars); err != nil {
+		t.Fatalf("Expected JSON, got %v: %s", err, rec.Body)
+	}
+	if len(vars.Cmdline) == 0 || vars.Memstats.HeapAlloc == 0 {
+		t.Errorf("Expected the command line and memory statistics, got %+v", vars)
+	}
+	if vars.Treestore["in_memory"] != true || vars.Treestore["sync_policy"] == nil || vars.Treestore["query_cache_hits"] == nil {
+		t.Errorf("Expected the state dump with every field, got %v", vars.Treestore)
+	}
+}
+
+func TestAdminTailLogs(t *testing.T) {
+	defer logger.SetLevel(logger.Level())
+	ring := logger.NewRing(10)
+	log := logger.NewLogger(logger.Config{Level: "info", Output: io.Discard, Ring: ring})
+	server, err := NewServerWithOptions(storage.MemoryPath, Options{LogRing: ring})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+
+	lis := bufconn.Listen(bufSize)
+	grpcServer := grpc.NewServer()
+	pb.RegisterTreeStoreAdminServer(grpcServer, server.Admin())
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial bufnet: %v", err)
+	}
+	defer conn.Close()
+	admin := pb.NewTreeStoreAdminClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	log.LogGrpcRequest("/treestore.TreeStoreService/GetNode", time.Millisecond, nil)
+	log.LogGrpcRequest("/treestore.TreeStoreService/Search", time.Millisecond, errors.New("boom"))
+	log.Warn("disk nearly full").Send()
+
+	recv := func(stream grpc.ServerStreamingClient[pb.LogEvent]) []*pb.LogEvent {
+		var events []*pb.LogEvent
+		for {
+			ev, err := stream.Recv()
+			if err == io.EOF {
+				return events
+			}
+			if err != nil {
+				t.Fatalf("Recv failed: %v", err)
+			}
+			events = append(events, ev)
+		}
+	}
+
+	// Without follow, the kept events passing the filter are sent and the stream ends
+	stream, err := admin.TailLogs(ctx, &pb.TailLogsRequest{MinLevel: "warn"})
+	if err != nil {
+		t.Fatalf("TailLogs failed: %v", err)
+	}
+	events := recv(stream)
+	if len(events) != 2 || events[0].Level != "error" || events[0].Method != "/treestore.TreeStoreService/Search" ||
+		events[1].Message != "disk nearly full" || events[1].Time == nil || !strings.Contains(events[1].Json, `"level":"warn"`) {
+		t.Errorf("Expected the error and the warning, got %v", events)
+	}
+	stream, err = admin.TailLogs(ctx, &pb.TailLogsRequest{Method: "GetNode"})
+	if err != nil {
+		t.Fatalf("TailLogs failed: %v", err)
+	}
+	if events := recv(stream); len(events) != 1 || events[0].Level != "info" {
+		t.Errorf("Expected the GetNode request, got %v", events)
+	}
+
+	// Following sends the newest kept event, then new ones
+	follow, err := admin.TailLogs(ctx, &pb.TailLogsRequest{Recent: 1, Follow: true})
+	if err != nil {
+		t.Fatalf("TailLogs failed: %v", err)
+	}
+	if ev, err := follow.Recv(); err != nil || ev.Message != "disk nearly full" {
+		t.Fatalf("Expected the newest kept event, got %v (%v)", ev, err)
+	}
+	log.Info("checkpoint done").Send()
+	if ev, err := follow.Recv(); err != nil || ev.Message != "checkpoint done" {
+		t.Errorf("Expected the new event, got %v (%v)", ev, err)
+	}
+	server.StopWatches()
+	if _, err := follow.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected the tail ended on shutdown, got %v", err)
+	}
+
+	bad, _ := admin.TailLogs(ctx, &pb.TailLogsRequest{MinLevel: "loud"})
+	if _, err := bad.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown level, got %v", err)
+	}
+	off, err := NewServer(storage.MemoryPath)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer off.Close()
+	if err := off.Admin().TailLogs(&pb.TailLogsRequest{}, nil); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition without a ring, got %v", err)
+	}
+}
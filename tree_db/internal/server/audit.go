@@ -0,0 +1,156 @@
+// Audit logging of mutating RPCs and caller identification
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/nainya/treestore/pkg/audit"
+)
+
+// Bounds that keep one audit record within a storage value
+const (
+	maxAuditEntities = 8
+	maxAuditID       = 256
+	maxAuditError    = 512
+)
+
+// callerID identifies the caller by a fingerprint of the API key it sends,
+// validated or not, or by peer IP when it sends none. The key itself is never
+// kept. It matches the principal RBAC grants the key, see PrincipalFromContext.
+func callerID(ctx context.Context, keyHeader string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if keys := md.Get(keyHeader); len(keys) > 0 && keys[0] != "" {
+			return keyFingerprint(keyHash(keys[0]))
+		}
+	}
+	return peerID(ctx)
+}
+
+// keyFingerprint shortens the hex SHA-256 of an API key to name its caller
+func keyFingerprint(hash string) string {
+	return "key:" + hash[:12]
+}
+
+// peerID identifies the caller by peer IP, or "unknown" without a peer
+func peerID(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr := p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			addr = host
+		}
+		return "ip:" + addr
+	}
+	return "unknown"
+}
+
+// AuditInterceptor records every mutating RPC, whether or not it succeeds
+// Callers are identified by keyHeader as for rate limiting. A record that
+// cannot be stored is passed to onError and does not fail the request.
+func (s *Server) AuditInterceptor(keyHeader string, onError func(error)) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		method := path.Base(info.FullMethod)
+		// Followers reject writes and must not write to their own database
+		if !mutatingMethods[method] || s.readOnly.Load() {
+			return handler(ctx, req)
+		}
+
+		resp, err := handler(ctx, req)
+
+		record := &audit.Record{
+			Method:    method,
+			Actor:     callerID(ctx, keyHeader),
+			Code:      status.Code(err).String(),
+			RequestID: RequestIDFromContext(ctx),
+		}
+		if err != nil {
+			record.Error = truncate(strings.ReplaceAll(status.Convert(err).Message(), "\x00", `\x00`), maxAuditError)
+		}
+		if msg, ok := req.(proto.Message); ok {
+			record.Entities = requestEntities(msg)
+			record.RequestHash = requestHash(msg)
+		}
+		s.maintMu.RLock()
+		auditErr := s.auditLog.Append(record)
+		s.maintMu.RUnlock()
+		if auditErr != nil && onError != nil {
+			onError(auditErr)
+		}
+
+		return resp, err
+	}
+}
+
+// SetAuditMirror also writes every audit record to w as JSON lines
+func (s *Server) SetAuditMirror(w io.Writer) {
+	s.auditLog.SetMirror(w)
+}
+
+// requestEntities lists the IDs set on a request and its directly nested
+// messages, such as "policy_id=LCD-1" or "document.policy_id=LCD-1"
+func requestEntities(msg proto.Message) []string {
+	var entities []string
+	var walk func(prefix string, m protoreflect.Message)
+	walk = func(prefix string, m protoreflect.Message) {
+		m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+			if len(entities) >= maxAuditEntities {
+				return false
+			}
+			name := prefix + string(fd.Name())
+			switch {
+			case fd.IsList() || fd.IsMap():
+			case fd.Kind() == protoreflect.MessageKind:
+				if prefix == "" {
+					walk(name+".", v.Message())
+				}
+			case isIDField(fd):
+				// Rejected requests may carry IDs that would not decode
+				id := strings.ReplaceAll(v.String(), "\x00", `\x00`)
+				entities = append(entities, name+"="+truncate(id, maxAuditID))
+			}
+			return true
+		})
+	}
+	walk("", msg.ProtoReflect())
+	return entities
+}
+
+// requestHash fingerprints a request so identical writes can be matched up
+func requestHash(msg proto.Message) string {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s + "…"
+}
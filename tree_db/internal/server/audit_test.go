@@ -0,0 +1,85 @@
+// Tests for audit logging of write RPCs
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestAuditInterceptor(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	var auditErrs []error
+	intercept := server.AuditInterceptor(DefaultAPIKeyHeader, func(err error) { auditErrs = append(auditErrs, err) })
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(DefaultAPIKeyHeader, "secret-key"))
+
+	store := &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/StoreDocument"}
+	storeHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return server.StoreDocument(ctx, req.(*pb.StoreDocumentRequest))
+	}
+	req := &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "POL-A", VersionId: "v1"},
+		Nodes:    []*pb.Node{{NodeId: "root", PolicyId: "POL-A", Title: "Root"}},
+	}
+	if _, err := intercept(ctx, req, store, storeHandler); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	// Failed writes are recorded with their status; reads are not recorded
+	del := &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/DeleteDocument"}
+	delHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return server.DeleteDocument(ctx, req.(*pb.DeleteDocumentRequest))
+	}
+	intercept(context.Background(), &pb.DeleteDocumentRequest{}, del, delHandler)
+
+	get := &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/GetNode"}
+	getHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return server.GetNode(ctx, req.(*pb.GetNodeRequest))
+	}
+	if _, err := intercept(ctx, &pb.GetNodeRequest{PolicyId: "POL-A", NodeId: "root"}, get, getHandler); err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+
+	if len(auditErrs) != 0 {
+		t.Fatalf("Unexpected audit errors: %v", auditErrs)
+	}
+
+	resp, err := client.QueryAuditLog(context.Background(), &pb.QueryAuditLogRequest{})
+	if err != nil {
+		t.Fatalf("QueryAuditLog failed: %v", err)
+	}
+	if len(resp.Records) != 2 {
+		t.Fatalf("Expected 2 audit records, got %d: %v", len(resp.Records), resp.Records)
+	}
+
+	first := resp.Records[0]
+	if first.Method != "StoreDocument" || first.Code != "OK" || len(first.RequestHash) != 64 {
+		t.Errorf("Unexpected StoreDocument record: %v", first)
+	}
+	if !strings.HasPrefix(first.Actor, "key:") || strings.Contains(first.Actor, "secret") {
+		t.Errorf("Expected a fingerprinted API key actor, got %q", first.Actor)
+	}
+	if strings.Join(first.Entities, ",") != "document.policy_id=POL-A,document.version_id=v1" {
+		t.Errorf("Unexpected entities: %v", first.Entities)
+	}
+
+	second := resp.Records[1]
+	if second.Method != "DeleteDocument" || second.Code != "InvalidArgument" || second.Error == "" || second.Actor != "unknown" {
+		t.Errorf("Unexpected DeleteDocument record: %v", second)
+	}
+
+	byActor, err := client.QueryAuditLog(context.Background(), &pb.QueryAuditLogRequest{Actor: first.Actor})
+	if err != nil {
+		t.Fatalf("QueryAuditLog by actor failed: %v", err)
+	}
+	if len(byActor.Records) != 1 || byActor.Records[0].Seq != first.Seq {
+		t.Errorf("Expected only the StoreDocument record for %s, got %v", first.Actor, byActor.Records)
+	}
+}
@@ -0,0 +1,149 @@
+// Collections of policies that searches and subtree reads can cover together
+package server
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/metadata"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func (s *Server) PutCollection(ctx context.Context, req *pb.PutCollectionRequest) (*pb.PutCollectionResponse, error) {
+	s.countOp("PutCollection")
+
+	if req.Collection == nil || req.Collection.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "collection with a name is required")
+	}
+
+	c := &metadata.Collection{
+		Name:        req.Collection.Name,
+		Description: req.Collection.Description,
+		PolicyIDs:   req.Collection.PolicyIds,
+		Metadata:    req.Collection.Metadata,
+	}
+	if err := s.metaStore.PutCollection(c); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to store collection: %v", err)
+	}
+
+	return &pb.PutCollectionResponse{Collection: collectionToPb(c)}, nil
+}
+
+func (s *Server) GetCollection(ctx context.Context, req *pb.GetCollectionRequest) (*pb.GetCollectionResponse, error) {
+	s.countOp("GetCollection")
+
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	c, err := s.metaStore.GetCollection(req.Name)
+	if err != nil {
+		return nil, collectionError(err)
+	}
+
+	return &pb.GetCollectionResponse{Collection: collectionToPb(c)}, nil
+}
+
+func (s *Server) ListCollections(ctx context.Context, req *pb.ListCollectionsRequest) (*pb.ListCollectionsResponse, error) {
+	s.countOp("ListCollections")
+
+	collections, err := s.metaStore.ListCollections(req.PolicyId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list collections: %v", err)
+	}
+
+	pbCollections := make([]*pb.Collection, len(collections))
+	for i, c := range collections {
+		pbCollections[i] = collectionToPb(c)
+	}
+	return &pb.ListCollectionsResponse{Collections: pbCollections}, nil
+}
+
+func (s *Server) UpdateCollectionMembers(ctx context.Context, req *pb.UpdateCollectionMembersRequest) (*pb.UpdateCollectionMembersResponse, error) {
+	s.countOp("UpdateCollectionMembers")
+
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	c, err := s.metaStore.UpdateCollectionMembers(req.Name, req.Add, req.Remove)
+	if err != nil {
+		return nil, collectionError(err)
+	}
+
+	return &pb.UpdateCollectionMembersResponse{Collection: collectionToPb(c)}, nil
+}
+
+func (s *Server) DeleteCollection(ctx context.Context, req *pb.DeleteCollectionRequest) (*pb.DeleteCollectionResponse, error) {
+	s.countOp("DeleteCollection")
+
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	if err := s.metaStore.DeleteCollection(req.Name); err != nil {
+		return nil, collectionError(err)
+	}
+
+	return &pb.DeleteCollectionResponse{Success: true}, nil
+}
+
+// collectionPolicies returns the member policies of a collection for a read
+// that covers all of them
+func (s *Server) collectionPolicies(name string) ([]string, error) {
+	c, err := s.metaStore.GetCollection(name)
+	if err != nil {
+		return nil, collectionError(err)
+	}
+	return c.PolicyIDs, nil
+}
+
+// collectionSubtrees returns the trees of every member policy of a
+// collection from their roots, members in order
+func (s *Server) collectionSubtrees(store *document.SimpleStore, name string, opts document.QueryOptions) ([]*document.Node, error) {
+	policyIDs, err := s.collectionPolicies(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*document.Node
+	for _, policyID := range policyIDs {
+		roots, err := store.GetChildren(policyID, nil)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get roots of %s: %v", policyID, err)
+		}
+		for _, root := range roots {
+			subtree, err := store.GetSubtree(policyID, root.NodeID, opts)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to get subtree of %s: %v", policyID, err)
+			}
+			nodes = append(nodes, subtree...)
+		}
+	}
+	return nodes, nil
+}
+
+// collectionError maps a collection store error to a gRPC status
+func collectionError(err error) error {
+	if errors.Is(err, metadata.ErrCollectionNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Errorf(codes.Internal, "collection operation failed: %v", err)
+}
+
+// collectionToPb converts a collection to its protobuf form
+func collectionToPb(c *metadata.Collection) *pb.Collection {
+	return &pb.Collection{
+		Name:        c.Name,
+		Description: c.Description,
+		PolicyIds:   c.PolicyIDs,
+		Metadata:    c.Metadata,
+		CreatedAt:   timestamppb.New(c.CreatedAt),
+		UpdatedAt:   timestamppb.New(c.UpdatedAt),
+	}
+}
@@ -0,0 +1,116 @@
+// Prompt experiment handlers: variant assignment, usage outcomes and per-variant comparison
+package server
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nainya/treestore/pkg/convert"
+	"github.com/nainya/treestore/pkg/prompt"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func (s *Server) StorePromptExperiment(ctx context.Context, req *pb.StorePromptExperimentRequest) (*pb.StorePromptExperimentResponse, error) {
+	s.countOp("StorePromptExperiment")
+
+	if req.Experiment == nil {
+		return nil, status.Error(codes.InvalidArgument, "experiment is required")
+	}
+	if err := s.promptStore.PutExperiment(convert.ExperimentFromPb(req.Experiment)); err != nil {
+		return nil, experimentError(err)
+	}
+
+	return &pb.StorePromptExperimentResponse{
+		Success: true,
+		Message: "Experiment stored successfully",
+	}, nil
+}
+
+func (s *Server) GetPromptExperiment(ctx context.Context, req *pb.GetPromptExperimentRequest) (*pb.GetPromptExperimentResponse, error) {
+	s.countOp("GetPromptExperiment")
+
+	if req.ExperimentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "experiment_id is required")
+	}
+	exp, err := s.promptStore.GetExperiment(req.ExperimentId)
+	if err != nil {
+		return nil, experimentError(err)
+	}
+	return &pb.GetPromptExperimentResponse{Experiment: convert.ExperimentToPb(exp)}, nil
+}
+
+func (s *Server) AssignPromptVariant(ctx context.Context, req *pb.AssignPromptVariantRequest) (*pb.AssignPromptVariantResponse, error) {
+	s.countOp("AssignPromptVariant")
+
+	if req.ExperimentId == "" || req.Unit == "" {
+		return nil, status.Error(codes.InvalidArgument, "experiment_id and unit are required")
+	}
+	v, err := s.promptStore.AssignVariant(req.ExperimentId, req.Unit)
+	if err != nil {
+		return nil, experimentError(err)
+	}
+	return &pb.AssignPromptVariantResponse{Variant: convert.VariantToPb(v)}, nil
+}
+
+func (s *Server) LabelPromptUsage(ctx context.Context, req *pb.LabelPromptUsageRequest) (*pb.LabelPromptUsageResponse, error) {
+	s.countOp("LabelPromptUsage")
+
+	if req.ExperimentId == "" || req.UsageId == "" {
+		return nil, status.Error(codes.InvalidArgument, "experiment_id and usage_id are required")
+	}
+	if err := s.promptStore.LabelUsage(req.ExperimentId, req.UsageId, req.Outcome); err != nil {
+		return nil, experimentError(err)
+	}
+
+	return &pb.LabelPromptUsageResponse{
+		Success: true,
+		Message: "Usage labeled successfully",
+	}, nil
+}
+
+func (s *Server) ComparePromptVariants(ctx context.Context, req *pb.ComparePromptVariantsRequest) (*pb.ComparePromptVariantsResponse, error) {
+	s.countOp("ComparePromptVariants")
+
+	if req.ExperimentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "experiment_id is required")
+	}
+	stats, err := s.promptStore.WithContext(ctx).CompareVariants(req.ExperimentId,
+		convert.TimeFromPb(req.Since), convert.TimeFromPb(req.Until))
+	if err != nil {
+		return nil, experimentError(err)
+	}
+	return &pb.ComparePromptVariantsResponse{Variants: convert.VariantStatsListToPb(stats)}, nil
+}
+
+// recordExperimentUsage counts a prompt usage in its experiment, if it names one
+func (s *Server) recordExperimentUsage(usage *pb.PromptUsage) error {
+	if usage.ExperimentId == "" {
+		return nil
+	}
+	err := s.promptStore.RecordExperimentUsage(&prompt.ExperimentUsage{
+		UsageID:      usage.UsageId,
+		ExperimentID: usage.ExperimentId,
+		Variant:      usage.Variant,
+		PromptID:     usage.PromptId,
+		Outcome:      usage.Outcome,
+		UsedAt:       convert.TimeFromPb(usage.UsedAt),
+	})
+	if err != nil {
+		return experimentError(err)
+	}
+	return nil
+}
+
+// experimentError maps an experiment store error to a gRPC status
+func experimentError(err error) error {
+	switch {
+	case errors.Is(err, prompt.ErrExperimentNotFound), errors.Is(err, prompt.ErrUsageNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, prompt.ErrInvalidExperiment), errors.Is(err, prompt.ErrUnknownVariant):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Errorf(codes.Internal, "prompt experiment: %v", err)
+}
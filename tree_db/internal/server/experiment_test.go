@@ -0,0 +1,88 @@
+// Tests for the prompt experiment handlers
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestPromptExperiment(t *testing.T) {
+	s, err := NewServer(filepath.Join(t.TempDir(), "experiment.db"))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	_, err = s.StorePromptExperiment(ctx, &pb.StorePromptExperimentRequest{Experiment: &pb.PromptExperiment{
+		ExperimentId: "exp1",
+		Name:         "Criteria extraction",
+		Variants: []*pb.PromptVariant{
+			{Name: "control", PromptId: "extract-v1", Weight: 1},
+			{Name: "stepwise", PromptId: "extract-v2", Weight: 1},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("StorePromptExperiment failed: %v", err)
+	}
+
+	// Every usage succeeds under stepwise and fails under control
+	for i := 0; i < 20; i++ {
+		assigned, err := s.AssignPromptVariant(ctx, &pb.AssignPromptVariantRequest{ExperimentId: "exp1", Unit: fmt.Sprintf("case-%d", i)})
+		if err != nil {
+			t.Fatalf("AssignPromptVariant failed: %v", err)
+		}
+		usageID := fmt.Sprintf("usage-%d", i)
+		_, err = s.RecordPromptUsage(ctx, &pb.RecordPromptUsageRequest{Usage: &pb.PromptUsage{
+			UsageId:      usageID,
+			PromptId:     assigned.Variant.PromptId,
+			UsedAt:       timestamppb.Now(),
+			ExperimentId: "exp1",
+			Variant:      assigned.Variant.Name,
+		}})
+		if err != nil {
+			t.Fatalf("RecordPromptUsage failed: %v", err)
+		}
+		outcome := "failure"
+		if assigned.Variant.Name == "stepwise" {
+			outcome = "success"
+		}
+		if _, err := s.LabelPromptUsage(ctx, &pb.LabelPromptUsageRequest{ExperimentId: "exp1", UsageId: usageID, Outcome: outcome}); err != nil {
+			t.Fatalf("LabelPromptUsage failed: %v", err)
+		}
+	}
+
+	resp, err := s.ComparePromptVariants(ctx, &pb.ComparePromptVariantsRequest{ExperimentId: "exp1"})
+	if err != nil {
+		t.Fatalf("ComparePromptVariants failed: %v", err)
+	}
+	if len(resp.Variants) != 2 {
+		t.Fatalf("Expected two variants, got %v", resp.Variants)
+	}
+	control, stepwise := resp.Variants[0], resp.Variants[1]
+	if control.Usages+stepwise.Usages != 20 || control.Usages == 0 || stepwise.Usages == 0 {
+		t.Errorf("Expected 20 usages split across both variants, got %v", resp.Variants)
+	}
+	if control.SuccessRate != 0 || stepwise.SuccessRate != 1 || stepwise.Outcomes["success"] != stepwise.Usages {
+		t.Errorf("Unexpected success rates %v", resp.Variants)
+	}
+
+	_, err = s.RecordPromptUsage(ctx, &pb.RecordPromptUsageRequest{Usage: &pb.PromptUsage{UsageId: "u", ExperimentId: "exp1", Variant: "verbose"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown variant, got %v", err)
+	}
+	if _, err := s.GetPromptExperiment(ctx, &pb.GetPromptExperimentRequest{ExperimentId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+	if _, err := s.LabelPromptUsage(ctx, &pb.LabelPromptUsageRequest{ExperimentId: "exp1", UsageId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown usage, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+// Diagram export of document trees and cross-reference graphs
+package server
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/graph"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// Graphs ExportGraph draws
+const (
+	graphTree       = "tree"
+	graphReferences = "references"
+)
+
+// graphLabel returns the label of a node under an ExportGraph label option
+func graphLabel(node *document.Node, label string, maxLen int) (string, error) {
+	var text string
+	switch label {
+	case "", "title":
+		text = node.Title
+	case "section_path":
+		text = node.Title
+		if node.SectionPath != "" {
+			text = node.SectionPath + " " + node.Title
+		}
+	case "node_id":
+		text = node.NodeID
+	default:
+		return "", status.Errorf(codes.InvalidArgument, "unknown label %q (want title, section_path or node_id)", label)
+	}
+	if text == "" {
+		text = node.NodeID
+	}
+	if maxLen > 0 && len([]rune(text)) > maxLen {
+		text = string([]rune(text)[:maxLen]) + "..."
+	}
+	return text, nil
+}
+
+func (s *Server) ExportGraph(ctx context.Context, req *pb.ExportGraphRequest) (*pb.ExportGraphResponse, error) {
+	s.countOp("ExportGraph")
+
+	if req.PolicyId == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id is required")
+	}
+	if req.MaxDepth < 0 || req.MaxLabelLength < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_depth and max_label_length must not be negative")
+	}
+	format := req.Format
+	if format == "" {
+		format = graph.FormatDOT
+	}
+	if format != graph.FormatDOT && format != graph.FormatMermaid {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown format %q (want %s or %s)", format, graph.FormatDOT, graph.FormatMermaid)
+	}
+	if _, err := graphLabel(&document.Node{}, req.Label, 0); err != nil {
+		return nil, err
+	}
+
+	var g *graph.Graph
+	var err error
+	switch req.Graph {
+	case "", graphTree:
+		g, err = s.treeGraph(ctx, req)
+	case graphReferences:
+		g, err = s.referenceGraph(ctx, req)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown graph %q (want %s or %s)", req.Graph, graphTree, graphReferences)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := g.Render(format)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &pb.ExportGraphResponse{
+		Content:   content,
+		NodeCount: int32(len(g.Nodes)),
+		EdgeCount: int32(len(g.Edges)),
+	}, nil
+}
+
+// treeGraph draws the parent-child edges of a policy's subtree
+func (s *Server) treeGraph(ctx context.Context, req *pb.ExportGraphRequest) (*graph.Graph, error) {
+	// Labels never show summaries or text, so they are not read
+	docs := s.docStore.WithContext(ctx).WithoutFields(document.FieldSummary | document.FieldText)
+
+	rootID := req.RootNodeId
+	if rootID == "" {
+		root, err := docs.GetRoot(req.PolicyId)
+		if errors.Is(err, document.ErrDocumentNotFound) {
+			return nil, status.Errorf(codes.NotFound, "document %s not found", req.PolicyId)
+		}
+		if errors.Is(err, document.ErrMultipleRoots) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%v; pass root_node_id", err)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to find document root: %v", err)
+		}
+		rootID = root.NodeID
+	}
+
+	nodes, err := docs.GetSubtree(req.PolicyId, rootID, document.QueryOptions{MaxDepth: int(req.MaxDepth), Workers: s.subtreeWorkers})
+	if errors.Is(err, document.ErrNodeNotFound) {
+		return nil, status.Errorf(codes.NotFound, "node %s not found in %s", rootID, req.PolicyId)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get subtree: %v", err)
+	}
+
+	g := &graph.Graph{Name: req.PolicyId}
+	for _, node := range nodes {
+		label, _ := graphLabel(node, req.Label, int(req.MaxLabelLength))
+		g.AddNode(graph.Node{ID: node.NodeID, Label: label})
+	}
+	for _, node := range nodes {
+		if node.ParentID != nil && node.NodeID != rootID && g.HasNode(*node.ParentID) {
+			g.AddEdge(graph.Edge{From: *node.ParentID, To: node.NodeID})
+		}
+	}
+	return g, nil
+}
+
+// referenceGraph draws the cross-references reachable from a policy's nodes,
+// or from one of them, following references both ways for max_depth hops.
+// Nodes are grouped by policy.
+func (s *Server) referenceGraph(ctx context.Context, req *pb.ExportGraphRequest) (*graph.Graph, error) {
+	type key struct{ policyID, nodeID string }
+	id := func(k key) string { return k.policyID + "/" + k.nodeID }
+
+	g := &graph.Graph{Name: req.PolicyId + " references"}
+	addNode := func(k key) error {
+		if g.HasNode(id(k)) {
+			return nil
+		}
+		node, err := s.docStore.GetNode(k.policyID, k.nodeID)
+		if errors.Is(err, document.ErrNodeNotFound) {
+			// A reference may outlive its node, or point at one never stored
+			node, err = &document.Node{NodeID: k.nodeID}, nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to get node: %v", err)
+		}
+		label, _ := graphLabel(node, req.Label, int(req.MaxLabelLength))
+		g.AddNode(graph.Node{ID: id(k), Label: label, Group: k.policyID})
+		return nil
+	}
+
+	// The first hop reads the references of the whole policy, or of the root node
+	frontier := []key{{req.PolicyId, req.RootNodeId}}
+	seen := map[key]bool{frontier[0]: true}
+	edges := map[[2]string]bool{}
+	for depth := 0; len(frontier) > 0 && (req.MaxDepth == 0 || depth < int(req.MaxDepth)); depth++ {
+		var next []key
+		for _, k := range frontier {
+			if err := ctx.Err(); err != nil {
+				return nil, status.FromContextError(err).Err()
+			}
+			from, err := s.metaStore.ReferencesFrom(k.policyID, k.nodeID)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to get cross references: %v", err)
+			}
+			to, err := s.metaStore.ReferencesTo(k.policyID, k.nodeID)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to get cross references: %v", err)
+			}
+
+			for _, ref := range append(from, to...) {
+				src := key{ref.SourcePolicyID, ref.SourceNodeID}
+				dst := key{ref.TargetPolicyID, ref.TargetNodeID}
+				if edges[[2]string{id(src), id(dst)}] {
+					continue
+				}
+				edges[[2]string{id(src), id(dst)}] = true
+
+				for _, end := range []key{src, dst} {
+					if err := addNode(end); err != nil {
+						return nil, err
+					}
+					if !seen[end] {
+						seen[end] = true
+						next = append(next, end)
+					}
+				}
+				g.AddEdge(graph.Edge{From: id(src), To: id(dst), Label: ref.ReferenceType})
+			}
+		}
+		frontier = next
+	}
+
+	if req.RootNodeId != "" && !g.HasNode(id(key{req.PolicyId, req.RootNodeId})) {
+		if err := addNode(key{req.PolicyId, req.RootNodeId}); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
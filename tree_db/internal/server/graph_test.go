@@ -0,0 +1,115 @@
+// Tests for graph export
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nainya/treestore/pkg/metadata"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestExportGraph(t *testing.T) {
+	server, err := NewServer(filepath.Join(t.TempDir(), "graph.db"))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+
+	ctx := context.Background()
+	now := timestamppb.Now()
+	store := func(policyID string, nodes ...*pb.Node) {
+		for _, n := range nodes {
+			n.PolicyId, n.CreatedAt, n.UpdatedAt = policyID, now, now
+		}
+		_, err := server.StoreDocument(ctx, &pb.StoreDocumentRequest{
+			Document: &pb.Document{PolicyId: policyID, RootNodeId: nodes[0].NodeId, CreatedAt: now, UpdatedAt: now},
+			Nodes:    nodes,
+		})
+		if err != nil {
+			t.Fatalf("StoreDocument failed: %v", err)
+		}
+	}
+	store("POL-A",
+		&pb.Node{NodeId: "root", Title: "Coverage", ChildIds: []string{"s1", "s2"}},
+		&pb.Node{NodeId: "s1", ParentId: "root", Title: "Prior authorization", SectionPath: "1", Depth: 1, ChildIds: []string{"s1a"}},
+		&pb.Node{NodeId: "s1a", ParentId: "s1", Title: "Imaging", SectionPath: "1.1", Depth: 2},
+		&pb.Node{NodeId: "s2", ParentId: "root", Title: "Exclusions", SectionPath: "2", Depth: 1},
+	)
+	store("POL-B", &pb.Node{NodeId: "root", Title: "Radiology"})
+	store("POL-C", &pb.Node{NodeId: "root", Title: "Oncology"})
+	for _, ref := range []*metadata.CrossReference{
+		{SourcePolicyID: "POL-A", SourceNodeID: "s1a", TargetPolicyID: "POL-B", TargetNodeID: "root", ReferenceType: "cites"},
+		{SourcePolicyID: "POL-C", SourceNodeID: "root", TargetPolicyID: "POL-B", TargetNodeID: "root", ReferenceType: "supports"},
+	} {
+		if err := server.metaStore.AddCrossReference(ref); err != nil {
+			t.Fatalf("AddCrossReference failed: %v", err)
+		}
+	}
+
+	tree, err := server.ExportGraph(ctx, &pb.ExportGraphRequest{PolicyId: "POL-A", Label: "section_path"})
+	if err != nil {
+		t.Fatalf("ExportGraph failed: %v", err)
+	}
+	if tree.NodeCount != 4 || tree.EdgeCount != 3 {
+		t.Errorf("Expected 4 nodes and 3 edges, got %d and %d", tree.NodeCount, tree.EdgeCount)
+	}
+	for _, want := range []string{`"s1a" [label="1.1 Imaging"]`, `"s1" -> "s1a";`} {
+		if !strings.Contains(tree.Content, want) {
+			t.Errorf("Tree lacks %q:\n%s", want, tree.Content)
+		}
+	}
+
+	// One level below s1, in Mermaid with cut labels
+	sub, err := server.ExportGraph(ctx, &pb.ExportGraphRequest{
+		PolicyId: "POL-A", RootNodeId: "s1", MaxDepth: 1, Format: "mermaid", MaxLabelLength: 5,
+	})
+	if err != nil {
+		t.Fatalf("ExportGraph failed: %v", err)
+	}
+	if sub.NodeCount != 2 || !strings.Contains(sub.Content, `n0["Prior..."]`) || !strings.Contains(sub.Content, "n0 --> n1") {
+		t.Errorf("Unexpected subtree:\n%s", sub.Content)
+	}
+
+	// One hop reaches POL-B; a second reaches POL-C through POL-B
+	refs, err := server.ExportGraph(ctx, &pb.ExportGraphRequest{PolicyId: "POL-A", Graph: "references", MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("ExportGraph failed: %v", err)
+	}
+	if refs.NodeCount != 2 || refs.EdgeCount != 1 || !strings.Contains(refs.Content, `"POL-A/s1a" -> "POL-B/root" [label="cites"]`) {
+		t.Errorf("Unexpected reference graph:\n%s", refs.Content)
+	}
+	refs, err = server.ExportGraph(ctx, &pb.ExportGraphRequest{PolicyId: "POL-A", Graph: "references"})
+	if err != nil {
+		t.Fatalf("ExportGraph failed: %v", err)
+	}
+	if refs.NodeCount != 3 || refs.EdgeCount != 2 || !strings.Contains(refs.Content, `label="POL-C"`) {
+		t.Errorf("Unexpected reference graph:\n%s", refs.Content)
+	}
+
+	for _, req := range []*pb.ExportGraphRequest{
+		{},
+		{PolicyId: "POL-A", Format: "svg"},
+		{PolicyId: "POL-A", Graph: "citations"},
+		{PolicyId: "POL-A", Label: "summary"},
+		{PolicyId: "POL-A", MaxDepth: -1},
+	} {
+		if _, err := server.ExportGraph(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got %v", req, err)
+		}
+	}
+	for _, req := range []*pb.ExportGraphRequest{
+		{PolicyId: "POL-MISSING"},
+		{PolicyId: "POL-A", RootNodeId: "missing"},
+	} {
+		if _, err := server.ExportGraph(ctx, req); status.Code(err) != codes.NotFound {
+			t.Errorf("Expected NotFound for %v, got %v", req, err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Analysis jobs that run over changed documents and store what they find as tool results and contradictions
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nainya/treestore/pkg/jobs"
+	"github.com/nainya/treestore/pkg/metadata"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// StartJobs starts a background runner of cfg.Jobs over the documents the
+// change feed reports written. Outputs are stored as StoreToolResult and
+// StoreContradiction store them; only a leader should run jobs.
+func (s *Server) StartJobs(cfg jobs.Config) (*jobs.Runner, error) {
+	cfg.Lock = s.maintMu.RLocker()
+	if cfg.Policies == nil {
+		cfg.Policies = func() ([]string, error) { return s.docStore.ListPolicies("", 0) }
+	}
+	runner, err := jobs.NewRunner(s.kv, s.feed, jobs.SinkFunc(s.storeJobOutput), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.jobRunner != nil {
+		s.jobRunner.Stop()
+	}
+	s.jobRunner = runner
+	s.jobRunner.Start()
+	return s.jobRunner, nil
+}
+
+// storeJobOutput stores the findings of a job's run over a policy in one batch
+func (s *Server) storeJobOutput(job, policyID string, out *jobs.Output, at time.Time) error {
+	entries := make([]*metadata.MetadataEntry, 0, len(out.ToolResults)+len(out.Contradictions))
+	for _, r := range out.ToolResults {
+		entries = append(entries, toolResultEntry(r.ExecutionID, r.ToolName, policyID, r.NodeID, r.Data, at))
+	}
+	for _, c := range out.Contradictions {
+		entries = append(entries, contradictionEntry(c.ContradictionID, c.Severity, at))
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return s.metaStore.SetMetadataBatch(entries)
+}
+
+func (s *Server) ListJobRuns(ctx context.Context, req *pb.ListJobRunsRequest) (*pb.ListJobRunsResponse, error) {
+	s.countOp("ListJobRuns")
+
+	state := jobs.State(req.State)
+	switch state {
+	case "", jobs.StatePending, jobs.StateRunning, jobs.StateRetrying, jobs.StateSucceeded, jobs.StateFailed:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown state %q", req.State)
+	}
+
+	// Followers list the runs their leader replicated
+	filter := jobs.RunFilter{Job: req.Job, PolicyID: req.PolicyId, State: state}
+	var runs []*jobs.Run
+	var err error
+	if s.jobRunner != nil {
+		runs, err = s.jobRunner.Runs(filter)
+	} else {
+		runs, err = jobs.ListRuns(s.kv, filter)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list job runs: %v", err)
+	}
+
+	resp := &pb.ListJobRunsResponse{Runs: make([]*pb.JobRun, len(runs))}
+	for i, run := range runs {
+		resp.Runs[i] = jobRunToPb(run)
+	}
+	if s.jobRunner != nil {
+		resp.Jobs = s.jobRunner.Jobs()
+	}
+	return resp, nil
+}
+
+func (s *Server) TriggerJob(ctx context.Context, req *pb.TriggerJobRequest) (*pb.TriggerJobResponse, error) {
+	s.countOp("TriggerJob")
+
+	if req.Job == "" || req.PolicyId == "" {
+		return nil, status.Error(codes.InvalidArgument, "job and policy_id are required")
+	}
+	if s.jobRunner == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no analysis jobs are configured")
+	}
+
+	run, err := s.jobRunner.Trigger(req.Job, req.PolicyId, time.Now())
+	if errors.Is(err, jobs.ErrUnknownJob) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to queue job: %v", err)
+	}
+	return &pb.TriggerJobResponse{Run: jobRunToPb(run)}, nil
+}
+
+// jobRunToPb converts a run, leaving unset times unset
+func jobRunToPb(run *jobs.Run) *pb.JobRun {
+	ts := func(t time.Time) *timestamppb.Timestamp {
+		if t.IsZero() {
+			return nil
+		}
+		return timestamppb.New(t)
+	}
+	return &pb.JobRun{
+		Job:         run.Job,
+		PolicyId:    run.PolicyID,
+		State:       string(run.State),
+		Attempts:    int32(run.Attempts),
+		LastError:   run.LastError,
+		QueuedAt:    ts(run.QueuedAt),
+		StartedAt:   ts(run.StartedAt),
+		FinishedAt:  ts(run.FinishedAt),
+		NextAttempt: ts(run.NextAttempt),
+		Outputs:     int32(run.Outputs),
+		Rerun:       run.Rerun,
+	}
+}
@@ -0,0 +1,88 @@
+// Tests for analysis jobs run over stored documents
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nainya/treestore/pkg/jobs"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestAnalysisJobs(t *testing.T) {
+	s, err := NewServer(filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	// Without jobs, runs can be listed but not triggered
+	if resp, err := s.ListJobRuns(ctx, &pb.ListJobRunsRequest{}); err != nil || len(resp.Runs) != 0 {
+		t.Errorf("Expected no runs, got %v (%v)", resp, err)
+	}
+	if _, err := s.TriggerJob(ctx, &pb.TriggerJobRequest{Job: "contradictions", PolicyId: "LCD-1"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition without jobs, got %v", err)
+	}
+
+	detect := jobs.JobFunc{JobName: "contradictions", Func: func(ctx context.Context, policyID string) (*jobs.Output, error) {
+		return &jobs.Output{
+			ToolResults:    []jobs.ToolResult{{NodeID: "scope", Data: `{"checked":2}`}},
+			Contradictions: []jobs.Contradiction{{ContradictionID: "c-" + policyID, Severity: "high"}},
+		}, nil
+	}}
+	if _, err := s.StartJobs(jobs.Config{Jobs: []jobs.Job{detect}, Interval: 20 * time.Millisecond}); err != nil {
+		t.Fatalf("StartJobs failed: %v", err)
+	}
+
+	// Storing a document queues the job, and a trigger queues it too
+	_, err = s.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "LCD-1"},
+		Nodes:    []*pb.Node{{NodeId: "root", PolicyId: "LCD-1"}},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	if _, err := s.TriggerJob(ctx, &pb.TriggerJobRequest{Job: "contradictions", PolicyId: "LCD-1"}); err != nil {
+		t.Fatalf("TriggerJob failed: %v", err)
+	}
+	var run *pb.JobRun
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		resp, err := s.ListJobRuns(ctx, &pb.ListJobRunsRequest{PolicyId: "LCD-1", State: string(jobs.StateSucceeded)})
+		if err != nil {
+			t.Fatalf("ListJobRuns failed: %v", err)
+		}
+		if len(resp.Runs) == 1 {
+			run = resp.Runs[0]
+			if len(resp.Jobs) != 1 || resp.Jobs[0] != "contradictions" {
+				t.Errorf("Expected the registered job listed, got %v", resp.Jobs)
+			}
+			break
+		}
+	}
+	if run == nil || run.Job != "contradictions" || run.Attempts != 1 || run.Outputs != 2 || run.FinishedAt == nil {
+		t.Fatalf("Expected a successful run with 2 outputs, got %v", run)
+	}
+
+	// Outputs are stored as StoreToolResult and StoreContradiction store them
+	entry, err := s.metaStore.GetMetadata("contradiction", "c-LCD-1", "severity")
+	if err != nil || entry.Value != "high" {
+		t.Errorf("Expected the contradiction stored, got %v (%v)", entry, err)
+	}
+	entry, err = s.metaStore.GetMetadata("tool_result", "contradictions/LCD-1/0", "tool_result")
+	if err != nil || entry.Value != `contradictions|LCD-1|scope|{"checked":2}` {
+		t.Errorf("Expected the tool result stored, got %v (%v)", entry, err)
+	}
+
+	if _, err := s.TriggerJob(ctx, &pb.TriggerJobRequest{Job: "missing", PolicyId: "LCD-1"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown job, got %v", err)
+	}
+	if _, err := s.ListJobRuns(ctx, &pb.ListJobRunsRequest{State: "stuck"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown state, got %v", err)
+	}
+}
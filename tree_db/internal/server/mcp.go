@@ -0,0 +1,498 @@
+// Model Context Protocol front-end exposing document navigation as tools and resources for LLM agents
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+// MCPPath is where MCPHandler is mounted
+const MCPPath = "/mcp"
+
+// mcpProtocolVersions are the MCP revisions served, newest first
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// maxMCPMessage bounds one JSON-RPC message read from a client
+const maxMCPMessage = 1 << 20
+
+// mcpResourcePage is how many policies resources/list returns at a time
+const mcpResourcePage = 100
+
+// JSON-RPC error codes, and MCP's for a resource that does not exist
+const (
+	rpcParseError       = -32700
+	rpcInvalidRequest   = -32600
+	rpcMethodNotFound   = -32601
+	rpcInvalidParams    = -32602
+	rpcInternalError    = -32603
+	mcpResourceNotFound = -32002
+)
+
+// rpcMessage is a JSON-RPC 2.0 request, notification or response
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is the error of a JSON-RPC response
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// mcpTool is a tool offered to agents, taking the JSON form of an RPC request
+type mcpTool struct {
+	name        string
+	description string
+	schema      map[string]interface{}
+	call        func(ctx context.Context, args json.RawMessage) (interface{}, error)
+}
+
+// mcpArgs reads tool arguments into an RPC request by proto field name
+var mcpArgs = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// mcpCall adapts an RPC handler to a tool
+func mcpCall[Req proto.Message, Resp any](newReq func() Req, handler func(context.Context, Req) (Resp, error)) func(context.Context, json.RawMessage) (interface{}, error) {
+	return func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		req := newReq()
+		if len(args) > 0 {
+			if err := mcpArgs.Unmarshal(args, req); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid arguments: %v", err)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// mcpSchema builds the input schema of a tool from its properties, each a
+// JSON type and description, and its required properties
+func mcpSchema(props map[string][2]string, required ...string) map[string]interface{} {
+	properties := make(map[string]interface{}, len(props))
+	for name, p := range props {
+		properties[name] = map[string]string{"type": p[0], "description": p[1]}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// mcpTools lists the tools served, in the order agents see them
+func (s *Server) mcpTools() []mcpTool {
+	return []mcpTool{
+		{
+			name:        "list_policies",
+			description: "List the IDs of stored policy documents in order. Pass the last ID returned as after to page.",
+			schema: mcpSchema(map[string][2]string{
+				"after": {"string", "Return policies after this ID"},
+				"limit": {"integer", "Maximum policies to return (default 100)"},
+			}),
+			call: func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+				var in struct {
+					After string `json:"after"`
+					Limit int    `json:"limit"`
+				}
+				if len(args) > 0 {
+					if err := json.Unmarshal(args, &in); err != nil {
+						return nil, status.Errorf(codes.InvalidArgument, "invalid arguments: %v", err)
+					}
+				}
+				if in.Limit <= 0 {
+					in.Limit = mcpResourcePage
+				}
+				policies, err := s.docStore.WithContext(ctx).ListPolicies(in.After, in.Limit)
+				if err != nil {
+					return nil, status.Errorf(codes.Internal, "failed to list policies: %v", err)
+				}
+				if policies == nil {
+					policies = []string{}
+				}
+				return map[string]interface{}{"policies": policies, "more": len(policies) == in.Limit}, nil
+			},
+		},
+		{
+			name:        "search",
+			description: "Keyword search over node titles, summaries and text. Searches one policy or collection when given, otherwise every policy, grouped by policy.",
+			schema: mcpSchema(map[string][2]string{
+				"query":      {"string", "Keywords to search for"},
+				"policy_id":  {"string", "Search only this policy"},
+				"collection": {"string", "Search only this collection's policies"},
+				"limit":      {"integer", "Maximum hits to return, per policy when searching every policy (default 10)"},
+			}, "query"),
+			call: func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+				var req pb.SearchRequest
+				if len(args) > 0 {
+					if err := mcpArgs.Unmarshal(args, &req); err != nil {
+						return nil, status.Errorf(codes.InvalidArgument, "invalid arguments: %v", err)
+					}
+				}
+				if req.PolicyId == "" && req.Collection == "" {
+					return s.GlobalSearch(ctx, &pb.GlobalSearchRequest{Query: req.Query, PerPolicyLimit: req.Limit})
+				}
+				return s.SearchByKeyword(ctx, &req)
+			},
+		},
+		{
+			name:        "get_node",
+			description: "Get one node of a policy with its title, text, summary, section path, pages and child IDs.",
+			schema: mcpSchema(map[string][2]string{
+				"policy_id": {"string", "Policy the node belongs to"},
+				"node_id":   {"string", "Node to get"},
+			}, "policy_id", "node_id"),
+			call: mcpCall(func() *pb.GetNodeRequest { return &pb.GetNodeRequest{} }, s.GetNode),
+		},
+		{
+			name:        "get_children",
+			description: "Get the direct children of a node, or the top-level nodes of a policy when parent_id is empty.",
+			schema: mcpSchema(map[string][2]string{
+				"policy_id": {"string", "Policy the node belongs to"},
+				"parent_id": {"string", "Node whose children to get"},
+			}, "policy_id"),
+			call: mcpCall(func() *pb.GetChildrenRequest { return &pb.GetChildrenRequest{} }, s.GetChildren),
+		},
+		{
+			name:        "get_subtree",
+			description: "Get a node and its descendants level by level, down to max_depth levels below it.",
+			schema: mcpSchema(map[string][2]string{
+				"policy_id": {"string", "Policy the node belongs to"},
+				"node_id":   {"string", "Root of the subtree"},
+				"max_depth": {"integer", "Levels below the node to return (0 for all)"},
+			}, "policy_id", "node_id"),
+			call: mcpCall(func() *pb.GetSubtreeRequest { return &pb.GetSubtreeRequest{} }, s.GetSubtree),
+		},
+		{
+			name:        "list_versions",
+			description: "List the stored versions of a policy, newest first.",
+			schema: mcpSchema(map[string][2]string{
+				"policy_id": {"string", "Policy whose versions to list"},
+				"limit":     {"integer", "Maximum versions to return"},
+			}, "policy_id"),
+			call: mcpCall(func() *pb.ListVersionsRequest { return &pb.ListVersionsRequest{} }, s.ListVersions),
+		},
+	}
+}
+
+// mcpSession answers the messages of one client. Stdio clients hold one for
+// the life of the process; HTTP requests each get a fresh one, as the
+// protocol keeps no state between calls beyond the initialize handshake.
+type mcpSession struct {
+	s     *Server
+	tools []mcpTool
+}
+
+// handle answers one JSON-RPC message, returning nil for notifications and
+// responses, which get no reply
+func (m *mcpSession) handle(ctx context.Context, data []byte) *rpcMessage {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return rpcFailure(nil, &rpcError{Code: rpcInvalidRequest, Message: "batches are not supported"})
+	}
+	var msg rpcMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return rpcFailure(nil, &rpcError{Code: rpcParseError, Message: err.Error()})
+	}
+	if msg.JSONRPC != "2.0" || (msg.Method == "" && msg.ID == nil) {
+		return rpcFailure(msg.ID, &rpcError{Code: rpcInvalidRequest, Message: "not a JSON-RPC 2.0 request"})
+	}
+	if msg.Method == "" || msg.ID == nil {
+		return nil
+	}
+
+	result, err := m.dispatch(ctx, msg.Method, msg.Params)
+	if err != nil {
+		var rerr *rpcError
+		if !errors.As(err, &rerr) {
+			rerr = &rpcError{Code: rpcInternalError, Message: err.Error()}
+		}
+		return rpcFailure(msg.ID, rerr)
+	}
+	return &rpcMessage{JSONRPC: "2.0", ID: msg.ID, Result: result}
+}
+
+// rpcFailure is the response reporting an error
+func rpcFailure(id json.RawMessage, err *rpcError) *rpcMessage {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &rpcMessage{JSONRPC: "2.0", ID: id, Error: err}
+}
+
+// dispatch runs a request's method
+func (m *mcpSession) dispatch(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	decode := func(v interface{}) error {
+		if len(params) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(params, v); err != nil {
+			return &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		return nil
+	}
+
+	switch method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if err := decode(&p); err != nil {
+			return nil, err
+		}
+		version := mcpProtocolVersions[0]
+		if slices.Contains(mcpProtocolVersions, p.ProtocolVersion) {
+			version = p.ProtocolVersion
+		}
+		return map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities": map[string]interface{}{
+				"tools":     map[string]interface{}{},
+				"resources": map[string]interface{}{},
+			},
+			"serverInfo": map[string]string{"name": "treestore", "version": "1.0.0"},
+			"instructions": "Policy documents are trees of sections. Find policies with list_policies or search, " +
+				"then walk down with get_children or get_subtree and read sections with get_node.",
+		}, nil
+
+	case "ping":
+		return map[string]interface{}{}, nil
+
+	case "tools/list":
+		tools := make([]map[string]interface{}, len(m.tools))
+		for i, t := range m.tools {
+			tools[i] = map[string]interface{}{"name": t.name, "description": t.description, "inputSchema": t.schema}
+		}
+		return map[string]interface{}{"tools": tools}, nil
+
+	case "tools/call":
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := decode(&p); err != nil {
+			return nil, err
+		}
+		for _, t := range m.tools {
+			if t.name == p.Name {
+				return mcpToolResult(t.call(ctx, p.Arguments))
+			}
+		}
+		return nil, &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("unknown tool %q", p.Name)}
+
+	case "resources/list":
+		var p struct {
+			Cursor string `json:"cursor"`
+		}
+		if err := decode(&p); err != nil {
+			return nil, err
+		}
+		return m.listResources(ctx, p.Cursor)
+
+	case "resources/templates/list":
+		return map[string]interface{}{"resourceTemplates": []map[string]string{
+			{"uriTemplate": "treestore://policy/{policy_id}", "name": "Policy outline", "mimeType": "application/json",
+				"description": "Every node of a policy with its title, section path and pages"},
+			{"uriTemplate": "treestore://policy/{policy_id}/node/{node_id}", "name": "Policy node", "mimeType": "application/json",
+				"description": "One node of a policy with its text"},
+		}}, nil
+
+	case "resources/read":
+		var p struct {
+			URI string `json:"uri"`
+		}
+		if err := decode(&p); err != nil {
+			return nil, err
+		}
+		return m.readResource(ctx, p.URI)
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method %q not found", method)}
+}
+
+// mcpToolResult wraps a tool's result as JSON text; a failed call is a
+// result flagged as an error, so the agent sees why
+func mcpToolResult(result interface{}, err error) (interface{}, error) {
+	var text string
+	if err == nil {
+		var data []byte
+		if data, err = marshalResult(result); err == nil {
+			text = string(data)
+		}
+	}
+	if err != nil {
+		return map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": status.Convert(err).Message()}},
+			"isError": true,
+		}, nil
+	}
+	return map[string]interface{}{"content": []map[string]string{{"type": "text", "text": text}}}, nil
+}
+
+// listResources lists policies as outline resources, a page at a time
+func (m *mcpSession) listResources(ctx context.Context, cursor string) (interface{}, error) {
+	policies, err := m.s.docStore.WithContext(ctx).ListPolicies(cursor, mcpResourcePage)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]map[string]string, len(policies))
+	for i, policyID := range policies {
+		resources[i] = map[string]string{
+			"uri":      "treestore://policy/" + url.PathEscape(policyID),
+			"name":     policyID,
+			"mimeType": "application/json",
+		}
+	}
+	result := map[string]interface{}{"resources": resources}
+	if len(policies) == mcpResourcePage {
+		result["nextCursor"] = policies[len(policies)-1]
+	}
+	return result, nil
+}
+
+// readResource reads a policy outline or one node
+func (m *mcpSession) readResource(ctx context.Context, uri string) (interface{}, error) {
+	parts := strings.Split(strings.TrimPrefix(uri, "treestore://policy/"), "/")
+	for i, part := range parts {
+		parts[i], _ = url.PathUnescape(part)
+	}
+
+	var result proto.Message
+	var err error
+	switch {
+	case !strings.HasPrefix(uri, "treestore://policy/"):
+		return nil, &rpcError{Code: mcpResourceNotFound, Message: fmt.Sprintf("unknown resource %q", uri)}
+	case len(parts) == 1:
+		result, err = m.s.GetDocument(ctx, &pb.GetDocumentRequest{
+			PolicyId: parts[0],
+			Fields:   []string{"title", "section_path", "page_start", "page_end", "child_ids"},
+		})
+	case len(parts) == 3 && parts[1] == "node":
+		result, err = m.s.GetNode(ctx, &pb.GetNodeRequest{PolicyId: parts[0], NodeId: parts[2]})
+	default:
+		return nil, &rpcError{Code: mcpResourceNotFound, Message: fmt.Sprintf("unknown resource %q", uri)}
+	}
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		return nil, &rpcError{Code: mcpResourceNotFound, Message: status.Convert(err).Message()}
+	case codes.InvalidArgument:
+		return nil, &rpcError{Code: rpcInvalidParams, Message: status.Convert(err).Message()}
+	default:
+		return nil, &rpcError{Code: rpcInternalError, Message: status.Convert(err).Message()}
+	}
+
+	data, err := uiJSON.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"contents": []map[string]string{
+		{"uri": uri, "mimeType": "application/json", "text": string(data)},
+	}}, nil
+}
+
+// newMCPSession starts answering a client
+func (s *Server) newMCPSession() *mcpSession {
+	return &mcpSession{s: s, tools: s.mcpTools()}
+}
+
+// ServeMCP speaks MCP over the stdio transport: JSON-RPC messages, one per
+// line, are read from r and answered on w until r ends or ctx is done. Tools
+// run the RPC handlers directly, bypassing the interceptors, so there is no
+// access control; the client is the process that started the server.
+func (s *Server) ServeMCP(ctx context.Context, r io.Reader, w io.Writer) error {
+	session := s.newMCPSession()
+	in := bufio.NewReaderSize(r, 64<<10)
+	enc := json.NewEncoder(w)
+	for ctx.Err() == nil {
+		line, err := in.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if reply := session.handle(ctx, line); reply != nil {
+				if err := enc.Encode(reply); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// MCPHandler speaks MCP over the streamable HTTP transport at MCPPath: each
+// POST carries one JSON-RPC message and requests are answered with JSON.
+// The server never streams, so GET is refused. Requests from browser pages
+// of other origins are rejected, against DNS rebinding. Like the web UI, it
+// bypasses the interceptors: mount it only where trusted agents alone reach
+// it.
+func (s *Server) MCPHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !mcpOriginAllowed(r) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxMCPMessage))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reply := s.newMCPSession().handle(r.Context(), body)
+		if reply == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(reply)
+	})
+}
+
+// mcpOriginAllowed accepts requests without an Origin, as agents send, and
+// those from the host they were sent to or from loopback
+func mcpOriginAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if u.Host == r.Host {
+		return true
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,200 @@
+// Tests for the MCP front-end
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+// newMCPTestServer returns a server holding one small policy
+func newMCPTestServer(t *testing.T) *Server {
+	t.Helper()
+	s, err := NewServer(filepath.Join(t.TempDir(), "mcp.db"))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	now := timestamppb.Now()
+	_, err = s.StoreDocument(context.Background(), &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "POL-MCP", RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "POL-MCP", Title: "Coverage", ChildIds: []string{"sec1"}, SectionPath: "1", CreatedAt: now, UpdatedAt: now},
+			{NodeId: "sec1", PolicyId: "POL-MCP", ParentId: "root", Title: "Imaging", Text: "Imaging requires prior authorization", SectionPath: "1.1", Depth: 1, CreatedAt: now, UpdatedAt: now},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	return s
+}
+
+// mcpReply is a JSON-RPC response as a client reads it
+type mcpReply struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+func TestServeMCP(t *testing.T) {
+	s := newMCPTestServer(t)
+
+	in := strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-03-26", "capabilities": {}, "clientInfo": {"name": "test"}}}`,
+		`{"jsonrpc": "2.0", "method": "notifications/initialized"}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "get_node", "arguments": {"policy_id": "POL-MCP", "node_id": "sec1"}}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "tools/call", "params": {"name": "search", "arguments": {"query": "imaging"}}}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "tools/call", "params": {"name": "get_node", "arguments": {"policy_id": "POL-MCP", "node_id": "missing"}}}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "tools/call", "params": {"name": "drop_tables"}}`,
+		``,
+		`{"jsonrpc": "2.0", "id": 7, "method": "resources/list"}`,
+		`{"jsonrpc": "2.0", "id": 8, "method": "resources/read", "params": {"uri": "treestore://policy/POL-MCP"}}`,
+		`{"jsonrpc": "2.0", "id": 9, "method": "resources/read", "params": {"uri": "treestore://policy/POL-MCP/node/missing"}}`,
+		`{"jsonrpc": "2.0", "id": 10, "method": "prompts/list"}`,
+		`not json`,
+		`{"jsonrpc": "2.0", "id": 11, "method": "ping"}`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := s.ServeMCP(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatalf("ServeMCP failed: %v", err)
+	}
+
+	replies := map[string]mcpReply{}
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var reply mcpReply
+		if err := dec.Decode(&reply); err != nil {
+			t.Fatalf("Bad reply: %v", err)
+		}
+		replies[string(reply.ID)] = reply
+	}
+	if len(replies) != 12 {
+		t.Fatalf("Expected 12 replies (none for the notification), got %d", len(replies))
+	}
+
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	json.Unmarshal(replies["1"].Result, &init)
+	if init.ProtocolVersion != "2025-03-26" {
+		t.Errorf("Expected the client's protocol version, got %q", init.ProtocolVersion)
+	}
+
+	var tools struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	json.Unmarshal(replies["2"].Result, &tools)
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "list_policies,search,get_node,get_children,get_subtree,list_versions" {
+		t.Errorf("Unexpected tools %v", names)
+	}
+
+	type toolResult struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	var node toolResult
+	json.Unmarshal(replies["3"].Result, &node)
+	if node.IsError || len(node.Content) != 1 || !strings.Contains(node.Content[0].Text, "Imaging requires prior authorization") {
+		t.Errorf("Unexpected get_node result %+v", node)
+	}
+	var search toolResult
+	json.Unmarshal(replies["4"].Result, &search)
+	if search.IsError || len(search.Content) != 1 || !strings.Contains(search.Content[0].Text, `"policy_id":"POL-MCP"`) {
+		t.Errorf("Unexpected search result %+v", search)
+	}
+	var missing toolResult
+	json.Unmarshal(replies["5"].Result, &missing)
+	if !missing.IsError {
+		t.Errorf("Expected a missing node to be a tool error, got %+v", missing)
+	}
+	if replies["6"].Error == nil || replies["6"].Error.Code != rpcInvalidParams {
+		t.Errorf("Expected invalid params for an unknown tool, got %+v", replies["6"])
+	}
+
+	if !strings.Contains(string(replies["7"].Result), "treestore://policy/POL-MCP") {
+		t.Errorf("Expected the policy listed as a resource, got %s", replies["7"].Result)
+	}
+	if !strings.Contains(string(replies["8"].Result), `\"section_path\":\"1.1\"`) {
+		t.Errorf("Expected the policy outline, got %s", replies["8"].Result)
+	}
+	if replies["9"].Error == nil || replies["9"].Error.Code != mcpResourceNotFound {
+		t.Errorf("Expected resource not found, got %+v", replies["9"])
+	}
+	if replies["10"].Error == nil || replies["10"].Error.Code != rpcMethodNotFound {
+		t.Errorf("Expected method not found, got %+v", replies["10"])
+	}
+	if replies["null"].Error == nil || replies["null"].Error.Code != rpcParseError {
+		t.Errorf("Expected a parse error, got %+v", replies["null"])
+	}
+	if replies["11"].Error != nil {
+		t.Errorf("Expected ping to succeed, got %+v", replies["11"].Error)
+	}
+}
+
+func TestMCPHandler(t *testing.T) {
+	s := newMCPTestServer(t)
+	srv := httptest.NewServer(s.MCPHandler())
+	defer srv.Close()
+
+	post := func(body, origin string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := post(`{"jsonrpc": "2.0", "id": "a", "method": "tools/call", "params": {"name": "get_children", "arguments": {"policy_id": "POL-MCP", "parent_id": "root"}}}`, "")
+	var reply mcpReply
+	json.NewDecoder(resp.Body).Decode(&reply)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(reply.ID) != `"a"` || !strings.Contains(string(reply.Result), "sec1") {
+		t.Errorf("Unexpected reply %d %s", resp.StatusCode, reply.Result)
+	}
+
+	resp = post(`{"jsonrpc": "2.0", "method": "notifications/initialized"}`, "http://localhost:3000")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 for a notification, got %d", resp.StatusCode)
+	}
+
+	resp = post(`{"jsonrpc": "2.0", "id": 1, "method": "ping"}`, "https://evil.example")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 for a foreign origin, got %d", resp.StatusCode)
+	}
+
+	get, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	get.Body.Close()
+	if get.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", get.StatusCode)
+	}
+}
@@ -3,9 +3,14 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
+	"net"
 	"net/http"
-	"net/http/pprof"
+	"net/netip"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -13,6 +18,7 @@ import (
 
 	"github.com/nainya/treestore/internal/logger"
 	"github.com/nainya/treestore/internal/metrics"
+	pb "github.com/nainya/treestore/proto"
 )
 
 // GrpcMetricsInterceptor creates a gRPC interceptor for metrics and logging
@@ -38,22 +44,119 @@ func GrpcMetricsInterceptor(m *metrics.Metrics, log *logger.Logger) grpc.UnarySe
 		}
 
 		m.RecordGrpcRequest(info.FullMethod, status, duration)
+		if err == nil {
+			recordReadShape(m, info.FullMethod, resp, duration)
+		}
 
 		// Log request
-		log.LogGrpcRequest(info.FullMethod, duration, err)
+		logger.FromContext(ctx, log).LogGrpcRequest(info.FullMethod, duration, err)
 
 		return resp, err
 	}
 }
 
+// recordReadShape records the latency of searches and subtree reads by the
+// size of what they returned
+func recordReadShape(m *metrics.Metrics, fullMethod string, resp interface{}, duration time.Duration) {
+	switch resp := resp.(type) {
+	case *pb.SearchResponse:
+		m.RecordSearch(path.Base(fullMethod), len(resp.Results), duration)
+	case *pb.GlobalSearchResponse:
+		results := 0
+		for _, p := range resp.Policies {
+			results += len(p.Results)
+		}
+		m.RecordSearch(path.Base(fullMethod), results, duration)
+	case *pb.GetSubtreeResponse:
+		m.RecordSubtree(len(resp.Nodes), subtreeDepth(resp.Nodes), duration)
+	}
+}
+
+// subtreeDepth returns the levels below their roots that nodes span. Levels
+// follow parent links, since a projection may leave out the depth field;
+// subtree reads return parents before their children.
+func subtreeDepth(nodes []*pb.Node) int {
+	levels := make(map[string]int, len(nodes))
+	deepest := 0
+	for _, n := range nodes {
+		level := 0
+		if parent, ok := levels[n.PolicyId+"/"+n.ParentId]; ok && n.ParentId != "" {
+			level = parent + 1
+		}
+		levels[n.PolicyId+"/"+n.NodeId] = level
+		deepest = max(deepest, level)
+	}
+	return deepest
+}
+
+// GrpcMetricsStreamInterceptor creates a gRPC stream interceptor for metrics and logging
+// A stream is recorded once, when it ends.
+func GrpcMetricsStreamInterceptor(m *metrics.Metrics, log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		m.GrpcRequestsInFlight.Inc()
+		defer m.GrpcRequestsInFlight.Dec()
+
+		err := handler(srv, ss)
+
+		duration := time.Since(start)
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		m.RecordGrpcRequest(info.FullMethod, status, duration)
+		logger.FromContext(ss.Context(), log).LogGrpcRequest(info.FullMethod, duration, err)
+
+		return err
+	}
+}
+
+// ObservabilityConfig configures the observability HTTP server
+type ObservabilityConfig struct {
+	Host      string // Interface to bind; "127.0.0.1" keeps the endpoints local, empty binds every interface
+	Port      int
+	Profiling bool // Serve pprof under /debug/pprof/, unless the binary was built without it
+
+	// Endpoints under /debug/ are guarded by these; the zero values leave them open
+	DebugUser     string         // Basic auth user; a request must send DebugUser and DebugPassword when either is set
+	DebugPassword string         // Basic auth password
+	DebugAllow    []netip.Prefix // Client addresses allowed, from ParseAllowlist; empty allows any
+}
+
+// ParseAllowlist parses a comma-separated list of IP addresses and CIDR
+// ranges, such as "127.0.0.1,10.0.0.0/8"
+func ParseAllowlist(s string) ([]netip.Prefix, error) {
+	var allow []netip.Prefix
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(item); err == nil {
+			allow = append(allow, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address or CIDR %q", item)
+		}
+		allow = append(allow, prefix.Masked())
+	}
+	return allow, nil
+}
+
 // ObservabilityServer provides HTTP endpoints for metrics and profiling
 type ObservabilityServer struct {
-	server *http.Server
-	log    *logger.Logger
+	server    *http.Server
+	mux       *http.ServeMux
+	debug     *http.ServeMux // Endpoints under /debug/, behind the guard
+	cfg       ObservabilityConfig
+	log       *logger.Logger
+	profiling bool // pprof is mounted
 }
 
 // NewObservabilityServer creates a new HTTP server for observability
-func NewObservabilityServer(port int, log *logger.Logger) *ObservabilityServer {
+func NewObservabilityServer(cfg ObservabilityConfig, log *logger.Logger) *ObservabilityServer {
 	mux := http.NewServeMux()
 
 	// Prometheus metrics endpoint
@@ -74,30 +177,87 @@ func NewObservabilityServer(port int, log *logger.Logger) *ObservabilityServer {
 	})
 
 	// pprof endpoints for profiling
-	mux.HandleFunc("/debug/pprof/", pprof.Index)
-	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
-	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
-	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
-	mux.Handle("/debug/pprof/heap", pprof.Handler("heap"))
-	mux.Handle("/debug/pprof/goroutine", pprof.Handler("goroutine"))
-	mux.Handle("/debug/pprof/threadcreate", pprof.Handler("threadcreate"))
-	mux.Handle("/debug/pprof/block", pprof.Handler("block"))
-	mux.Handle("/debug/pprof/mutex", pprof.Handler("mutex"))
-	mux.Handle("/debug/pprof/allocs", pprof.Handler("allocs"))
+	debug := http.NewServeMux()
+	profiling := false
+	if cfg.Profiling {
+		profiling = mountPprof(debug)
+		if !profiling {
+			log.Warn("Profiling requested but this binary was built without pprof").Send()
+		}
+	}
 
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", port),
+		Addr:         net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
 		Handler:      mux,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
-	return &ObservabilityServer{
-		server: server,
-		log:    log,
+	o := &ObservabilityServer{
+		server:    server,
+		mux:       mux,
+		debug:     debug,
+		cfg:       cfg,
+		log:       log,
+		profiling: profiling,
+	}
+	mux.Handle("/debug/", o.guard(debug))
+	return o
+}
+
+// guard rejects requests to the debug endpoints from addresses outside the
+// allowlist or without the basic auth credentials
+func (o *ObservabilityServer) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(o.cfg.DebugAllow) > 0 && !o.allowed(r.RemoteAddr) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if o.cfg.DebugUser != "" || o.cfg.DebugPassword != "" {
+			user, password, ok := r.BasicAuth()
+			if !ok || subtle.ConstantTimeCompare([]byte(user), []byte(o.cfg.DebugUser)) != 1 ||
+				subtle.ConstantTimeCompare([]byte(password), []byte(o.cfg.DebugPassword)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="treestore debug"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowed reports whether a request's peer address is in the allowlist
+// Forwarding headers are ignored, so behind a proxy the proxy's address counts.
+func (o *ObservabilityServer) allowed(remoteAddr string) bool {
+	addrPort, err := netip.ParseAddrPort(remoteAddr)
+	if err != nil {
+		return false
 	}
+	addr := addrPort.Addr().Unmap()
+	for _, prefix := range o.cfg.DebugAllow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle serves h for pattern alongside the built-in endpoints; call it
+// before Start
+func (o *ObservabilityServer) Handle(pattern string, h http.Handler) {
+	o.mux.Handle(pattern, h)
+}
+
+// HandleDebug serves h for a pattern under /debug/ behind the same allowlist
+// and credentials as pprof; call it before Start
+func (o *ObservabilityServer) HandleDebug(pattern string, h http.Handler) {
+	o.debug.Handle(pattern, h)
+}
+
+// Profiling reports whether pprof is served
+func (o *ObservabilityServer) Profiling() bool {
+	return o.profiling
 }
 
 // Start starts the observability HTTP server
@@ -106,11 +266,13 @@ func (o *ObservabilityServer) Start() error {
 		Str("addr", o.server.Addr).
 		Msg("Observability endpoints available")
 
-	o.log.Info("Endpoints:").
+	endpoints := o.log.Info("Endpoints:").
 		Str("metrics", fmt.Sprintf("http://%s/metrics", o.server.Addr)).
-		Str("health", fmt.Sprintf("http://%s/health", o.server.Addr)).
-		Str("pprof", fmt.Sprintf("http://%s/debug/pprof/", o.server.Addr)).
-		Send()
+		Str("health", fmt.Sprintf("http://%s/health", o.server.Addr))
+	if o.profiling {
+		endpoints = endpoints.Str("pprof", fmt.Sprintf("http://%s/debug/pprof/", o.server.Addr))
+	}
+	endpoints.Send()
 
 	if err := o.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("observability server failed: %w", err)
@@ -0,0 +1,107 @@
+// Tests for the observability HTTP server
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nainya/treestore/internal/logger"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestObservabilityProfiling(t *testing.T) {
+	log := logger.NewLogger(logger.Config{Output: io.Discard})
+	get := func(o *ObservabilityServer, path string) int {
+		rec := httptest.NewRecorder()
+		o.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	off := NewObservabilityServer(ObservabilityConfig{Host: "127.0.0.1", Port: 9090}, log)
+	if code := get(off, "/debug/pprof/"); code != http.StatusNotFound || off.Profiling() {
+		t.Errorf("Expected pprof off by default, got %d", code)
+	}
+	if code := get(off, "/health"); code != http.StatusOK {
+		t.Errorf("Expected health served, got %d", code)
+	}
+	if off.server.Addr != "127.0.0.1:9090" {
+		t.Errorf("Expected a localhost address, got %s", off.server.Addr)
+	}
+
+	// Binaries built with the nodebug tag have no pprof to serve
+	on := NewObservabilityServer(ObservabilityConfig{Port: 9090, Profiling: true}, log)
+	want := http.StatusOK
+	if !mountPprof(http.NewServeMux()) {
+		want = http.StatusNotFound
+	}
+	if code := get(on, "/debug/pprof/"); code != want || on.Profiling() != (want == http.StatusOK) {
+		t.Errorf("Expected pprof status %d, got %d", want, code)
+	}
+	if on.server.Addr != ":9090" {
+		t.Errorf("Expected every interface, got %s", on.server.Addr)
+	}
+}
+
+func TestObservabilityDebugGuard(t *testing.T) {
+	allow, err := ParseAllowlist("127.0.0.1, 10.0.0.0/8")
+	if err != nil || len(allow) != 2 {
+		t.Fatalf("ParseAllowlist = %v, %v", allow, err)
+	}
+	if _, err := ParseAllowlist("10.0.0.0/33"); err == nil {
+		t.Error("Expected an invalid CIDR rejected")
+	}
+
+	o := NewObservabilityServer(ObservabilityConfig{DebugUser: "ops", DebugPassword: "secret", DebugAllow: allow},
+		logger.NewLogger(logger.Config{Output: io.Discard}))
+	o.HandleDebug("/debug/ping", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	get := func(path, remoteAddr, user, password string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		if user != "" {
+			req.SetBasicAuth(user, password)
+		}
+		rec := httptest.NewRecorder()
+		o.server.Handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, tc := range []struct {
+		path, remoteAddr, user, password string
+		want                             int
+	}{
+		{"/debug/ping", "10.1.2.3:4000", "ops", "secret", http.StatusOK},
+		{"/debug/ping", "[::ffff:127.0.0.1]:4000", "ops", "secret", http.StatusOK},
+		{"/debug/ping", "192.168.1.5:4000", "ops", "secret", http.StatusForbidden},
+		{"/debug/ping", "10.1.2.3:4000", "ops", "wrong", http.StatusUnauthorized},
+		{"/debug/ping", "10.1.2.3:4000", "", "", http.StatusUnauthorized},
+		{"/debug/pprof/", "10.1.2.3:4000", "ops", "secret", http.StatusNotFound},
+		{"/health", "192.168.1.5:4000", "", "", http.StatusOK},
+	} {
+		if got := get(tc.path, tc.remoteAddr, tc.user, tc.password); got != tc.want {
+			t.Errorf("GET %s from %s as %q = %d, want %d", tc.path, tc.remoteAddr, tc.user, got, tc.want)
+		}
+	}
+}
+
+func TestSubtreeDepth(t *testing.T) {
+	node := func(policy, id, parent string) *pb.Node {
+		return &pb.Node{PolicyId: policy, NodeId: id, ParentId: parent}
+	}
+	tests := []struct {
+		name  string
+		nodes []*pb.Node
+		want  int
+	}{
+		{"empty", nil, 0},
+		{"root only", []*pb.Node{node("P", "root", "")}, 0},
+		{"subtree of a child", []*pb.Node{node("P", "a", "root"), node("P", "b", "a"), node("P", "c", "b"), node("P", "d", "a")}, 2},
+		{"collection of trees", []*pb.Node{node("P", "root", ""), node("Q", "root", ""), node("Q", "a", "root"), node("P", "a", "")}, 1},
+	}
+	for _, tt := range tests {
+		if got := subtreeDepth(tt.nodes); got != tt.want {
+			t.Errorf("%s: expected depth %d, got %d", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build nodebug
+
+// Stub for binaries built with the nodebug tag, which leave out pprof
+package server
+
+import "net/http"
+
+// mountPprof reports that pprof is not available
+func mountPprof(*http.ServeMux) bool {
+	return false
+}
@@ -0,0 +1,74 @@
+// Field masks that trim the nodes of tree reads to the fields a caller asks for
+package server
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/nainya/treestore/pkg/document"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// alwaysReturned are the node fields a mask cannot leave out, so callers can
+// still place every node in the tree
+var alwaysReturned = []protoreflect.Name{"node_id", "policy_id", "parent_id"}
+
+// nodeMask is the set of pb.Node fields a read returns; nil returns them all
+type nodeMask map[protoreflect.Name]bool
+
+// parseNodeMask builds a mask from the field names of a request, rejecting
+// names pb.Node does not have
+func parseNodeMask(fields []string) (nodeMask, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	desc := (&pb.Node{}).ProtoReflect().Descriptor().Fields()
+	mask := make(nodeMask, len(fields)+len(alwaysReturned))
+	for _, name := range fields {
+		if desc.ByName(protoreflect.Name(name)) == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown node field %q", name)
+		}
+		mask[protoreflect.Name(name)] = true
+	}
+	for _, name := range alwaysReturned {
+		mask[name] = true
+	}
+	return mask, nil
+}
+
+// omitted is the node fields the store need not decode for the mask
+func (m nodeMask) omitted() document.NodeFields {
+	var omit document.NodeFields
+	if m == nil {
+		return omit
+	}
+	if !m["summary"] {
+		omit |= document.FieldSummary
+	}
+	if !m["text"] {
+		omit |= document.FieldText
+	}
+	return omit
+}
+
+// apply clears the fields of each node outside the mask
+func (m nodeMask) apply(nodes []*pb.Node) {
+	if m == nil {
+		return
+	}
+	for _, node := range nodes {
+		msg := node.ProtoReflect()
+		var unset []protoreflect.FieldDescriptor
+		msg.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			if !m[fd.Name()] {
+				unset = append(unset, fd)
+			}
+			return true
+		})
+		for _, fd := range unset {
+			msg.Clear(fd)
+		}
+	}
+}
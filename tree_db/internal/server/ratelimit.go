@@ -0,0 +1,235 @@
+// Per-client token bucket rate limiting for gRPC requests
+package server
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// DefaultAPIKeyHeader is the metadata key carrying a client's API key
+const DefaultAPIKeyHeader = "x-api-key"
+
+// defaultIdleTTL is how long an unused client's buckets are kept
+const defaultIdleTTL = 10 * time.Minute
+
+// minPruneSize is the number of clients below which growth alone does not prune
+const minPruneSize = 1024
+
+// RateLimitConfig configures a RateLimiter
+// Writes are the methods rejected on read-only followers; every other method,
+// including each new stream, draws from the read budget. Clients are the
+// principals RBAC authenticated, so the limiter runs after it; callers
+// without a validated API key are limited by peer IP.
+type RateLimitConfig struct {
+	ReadRate   float64                         // Read requests per second per client; 0 leaves reads unlimited
+	ReadBurst  int                             // Reads a client may make at once; defaults to one second's worth
+	WriteRate  float64                         // Write requests per second per client; 0 leaves writes unlimited
+	WriteBurst int                             // Writes a client may make at once; defaults to one second's worth
+	IdleTTL    time.Duration                   // Buckets unused for this long are dropped
+	OnThrottle func(method string, write bool) // Called for each rejected request
+}
+
+// RateLimiter throttles each client with separate read and write token buckets
+type RateLimiter struct {
+	cfg       RateLimitConfig
+	now       func() time.Time
+	mu        sync.Mutex
+	clients   map[string]*clientBuckets
+	lastPrune time.Time
+	pruneSize int // Clients kept by the last prune
+}
+
+// clientBuckets holds one client's budgets
+type clientBuckets struct {
+	read     tokenBucket
+	write    tokenBucket
+	lastSeen time.Time
+}
+
+// tokenBucket refills at rate tokens per second up to burst
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a rate limiter from cfg
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	if cfg.ReadBurst <= 0 {
+		cfg.ReadBurst = burstFor(cfg.ReadRate)
+	}
+	if cfg.WriteBurst <= 0 {
+		cfg.WriteBurst = burstFor(cfg.WriteRate)
+	}
+	if cfg.IdleTTL <= 0 {
+		cfg.IdleTTL = defaultIdleTTL
+	}
+
+	return &RateLimiter{
+		cfg:     cfg,
+		now:     time.Now,
+		clients: make(map[string]*clientBuckets),
+	}
+}
+
+// burstFor returns one second's worth of requests, at least one
+func burstFor(rate float64) int {
+	if rate < 1 {
+		return 1
+	}
+	return int(rate)
+}
+
+// Allow takes a token from the client's read or write bucket
+// When the bucket is empty it returns false and the wait until the next token.
+func (rl *RateLimiter) Allow(client string, write bool) (bool, time.Duration) {
+	rate, burst := rl.cfg.ReadRate, rl.cfg.ReadBurst
+	if write {
+		rate, burst = rl.cfg.WriteRate, rl.cfg.WriteBurst
+	}
+	if rate <= 0 {
+		return true, 0
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.prune(now)
+
+	c, ok := rl.clients[client]
+	if !ok {
+		c = &clientBuckets{
+			read:  tokenBucket{tokens: float64(rl.cfg.ReadBurst), last: now},
+			write: tokenBucket{tokens: float64(rl.cfg.WriteBurst), last: now},
+		}
+		rl.clients[client] = c
+	}
+	c.lastSeen = now
+
+	b := &c.read
+	if write {
+		b = &c.write
+	}
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// prune forgets idle clients every IdleTTL, and whenever the clients have
+// doubled since the last prune. A client is idle once its buckets have
+// refilled, as new ones would start full, or after IdleTTL at the latest.
+func (rl *RateLimiter) prune(now time.Time) {
+	if rl.lastPrune.IsZero() {
+		rl.lastPrune = now
+	}
+	if now.Sub(rl.lastPrune) < rl.cfg.IdleTTL && len(rl.clients) < max(2*rl.pruneSize, minPruneSize) {
+		return
+	}
+
+	idle := rl.cfg.IdleTTL
+	if refill := rl.refillTime(); refill < idle {
+		idle = refill
+	}
+	for key, c := range rl.clients {
+		if now.Sub(c.lastSeen) >= idle {
+			delete(rl.clients, key)
+		}
+	}
+	rl.lastPrune, rl.pruneSize = now, len(rl.clients)
+}
+
+// refillTime returns how long an empty client takes to refill every limited bucket
+func (rl *RateLimiter) refillTime() time.Duration {
+	var refill time.Duration
+	for _, b := range []struct {
+		rate  float64
+		burst int
+	}{{rl.cfg.ReadRate, rl.cfg.ReadBurst}, {rl.cfg.WriteRate, rl.cfg.WriteBurst}} {
+		if b.rate > 0 {
+			refill = max(refill, time.Duration(float64(b.burst)/b.rate*float64(time.Second)))
+		}
+	}
+	return refill
+}
+
+// clientID names the client a call is limited as: the principal RBAC
+// authenticated, else the peer's IP. An API key no one validated is ignored,
+// so a client cannot escape its budget by sending a new one with each call.
+func clientID(ctx context.Context) string {
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		return principal
+	}
+	return peerID(ctx)
+}
+
+// check applies the limiter to one call and builds the ResourceExhausted error
+func (rl *RateLimiter) check(ctx context.Context, fullMethod string) error {
+	method := path.Base(fullMethod)
+	write := mutatingMethods[method]
+
+	ok, wait := rl.Allow(clientID(ctx), write)
+	if ok {
+		return nil
+	}
+	if rl.cfg.OnThrottle != nil {
+		rl.cfg.OnThrottle(method, write)
+	}
+
+	budget := "read"
+	if write {
+		budget = "write"
+	}
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("%s rate limit exceeded; retry in %s", budget, wait.Round(time.Millisecond)))
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(wait)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// UnaryInterceptor rejects unary calls over the client's budget with ResourceExhausted
+func (rl *RateLimiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := rl.check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor charges one token when a stream is opened
+func (rl *RateLimiter) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if err := rl.check(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
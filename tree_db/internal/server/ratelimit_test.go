@@ -0,0 +1,153 @@
+// Tests for per-client rate limiting
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimiterBuckets(t *testing.T) {
+	rl := NewRateLimiter(RateLimitConfig{ReadRate: 2, ReadBurst: 3, WriteRate: 1})
+	now := time.Unix(1000, 0)
+	rl.now = func() time.Time { return now }
+
+	// The burst is available immediately, then the bucket is empty
+	for i := 0; i < 3; i++ {
+		if ok, _ := rl.Allow("a", false); !ok {
+			t.Fatalf("Read %d should be allowed within burst", i)
+		}
+	}
+	ok, wait := rl.Allow("a", false)
+	if ok {
+		t.Fatal("Expected read beyond burst to be throttled")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("Expected 500ms wait at 2/s, got %v", wait)
+	}
+
+	// Writes and other clients have their own buckets
+	if ok, _ := rl.Allow("a", true); !ok {
+		t.Error("Expected write to use a separate budget")
+	}
+	if ok, _ := rl.Allow("a", true); ok {
+		t.Error("Expected second write to exceed a burst of 1")
+	}
+	if ok, _ := rl.Allow("b", false); !ok {
+		t.Error("Expected another client to have its own budget")
+	}
+
+	// Tokens refill with time, up to the burst
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := rl.Allow("a", false); !ok {
+		t.Error("Expected a token after 500ms")
+	}
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		rl.Allow("a", false)
+	}
+	if ok, _ := rl.Allow("a", false); ok {
+		t.Error("Expected refill to be capped at the burst")
+	}
+
+	// Idle clients are forgotten
+	now = now.Add(defaultIdleTTL)
+	rl.Allow("a", false)
+	if _, ok := rl.clients["b"]; ok {
+		t.Error("Expected idle client to be pruned")
+	}
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	var throttled []string
+	rl := NewRateLimiter(RateLimitConfig{
+		WriteRate: 1,
+		OnThrottle: func(method string, write bool) {
+			if write {
+				throttled = append(throttled, method)
+			}
+		},
+	})
+
+	intercept := rl.UnaryInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	write := &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/StoreDocument"}
+	read := &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/GetNode"}
+
+	fromIP := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4000}})
+	samePeerOtherPort := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4001}})
+	withKey := metadata.NewIncomingContext(fromIP, metadata.Pairs(DefaultAPIKeyHeader, "agent-7"))
+	authenticated := grant{role: "writer", principal: keyFingerprint(keyHash("agent-7"))}.context(withKey)
+
+	if _, err := intercept(fromIP, nil, write, handler); err != nil {
+		t.Fatalf("First write failed: %v", err)
+	}
+
+	_, err := intercept(samePeerOtherPort, nil, write, handler)
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted for the same IP, got %v", err)
+	}
+	var retry *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		if r, ok := d.(*errdetails.RetryInfo); ok {
+			retry = r
+		}
+	}
+	if retry == nil || retry.RetryDelay.AsDuration() <= 0 {
+		t.Errorf("Expected RetryInfo with a positive delay, got %v", st.Details())
+	}
+	if len(throttled) != 1 || throttled[0] != "StoreDocument" {
+		t.Errorf("Expected OnThrottle for StoreDocument, got %v", throttled)
+	}
+
+	// An API key no one validated does not escape the IP's budget
+	if _, err := intercept(withKey, nil, write, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected an unvalidated API key limited by IP, got %v", err)
+	}
+
+	// An authenticated principal is its own client, and reads are unlimited here
+	if _, err := intercept(authenticated, nil, write, handler); err != nil {
+		t.Errorf("Expected an authenticated principal to have its own budget, got %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := intercept(fromIP, nil, read, handler); err != nil {
+			t.Fatalf("Expected unlimited reads, got %v", err)
+		}
+	}
+}
+
+func TestRateLimiterPrunesGrowth(t *testing.T) {
+	rl := NewRateLimiter(RateLimitConfig{ReadRate: 10, WriteRate: 1})
+	now := time.Unix(1000, 0)
+	rl.now = func() time.Time { return now }
+
+	// Clients whose buckets refilled are dropped once the map doubles, well
+	// before IdleTTL
+	for i := 0; i < minPruneSize; i++ {
+		rl.Allow(fmt.Sprintf("ip:10.0.%d.%d", i/256, i%256), false)
+	}
+	now = now.Add(2 * time.Second)
+	rl.Allow("ip:10.1.0.1", false)
+	if len(rl.clients) != 1 {
+		t.Errorf("Expected only the active client kept, got %d", len(rl.clients))
+	}
+
+	// Clients still refilling are kept
+	rl.Allow("ip:10.1.0.2", true)
+	for i := 0; i < minPruneSize; i++ {
+		rl.Allow(fmt.Sprintf("ip:10.2.%d.%d", i/256, i%256), false)
+	}
+	if _, ok := rl.clients["ip:10.1.0.2"]; !ok {
+		t.Error("Expected a client with an unfilled bucket kept")
+	}
+}
@@ -0,0 +1,421 @@
+// Role-based access control for gRPC methods by action and entity type
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"go.yaml.in/yaml/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+// Actions a role may be granted on an entity type
+const (
+	ActionRead  = "read"
+	ActionWrite = "write"
+	ActionAdmin = "admin"
+)
+
+// Entity types that methods act on; "*" in a role grants every type
+const (
+	EntityDocument     = "document"     // Documents, nodes and search
+	EntityVersion      = "version"      // Policy versions and tags
+	EntityMetadata     = "metadata"     // Metadata, tool results, trajectories, cross references and collections
+	EntityPrompt       = "prompt"       // Prompt templates and usage
+	EntityConversation = "conversation" // Conversations and messages
+	EntityQuery        = "query"        // Ad-hoc queries across every store
+	EntityChanges      = "changes"      // The change feed
+	EntitySystem       = "system"       // Health and stats
+	EntityAudit        = "audit"        // The audit log
+	EntityReplication  = "replication"  // WAL streaming to followers
+)
+
+// permission is what a method requires of the caller's role
+type permission struct {
+	action string
+	entity string
+}
+
+// methodPermissions lists every method of the services in rbacServices;
+// anything absent, such as reflection, needs admin on every entity type
+var methodPermissions = map[string]permission{
+	"StoreDocument":          {ActionWrite, EntityDocument},
+	"GetDocument":            {ActionRead, EntityDocument},
+	"DeleteDocument":         {ActionWrite, EntityDocument},
+	"CloneDocument":          {ActionWrite, EntityDocument},
+	"RecomputeSectionPaths":  {ActionWrite, EntityDocument},
+	"RefreshSummaries":       {ActionWrite, EntityDocument},
+	"ValidateDocument":       {ActionRead, EntityDocument},
+	"FindDuplicateNodes":     {ActionRead, EntityDocument},
+	"GetNode":                {ActionRead, EntityDocument},
+	"UpdateNode":             {ActionWrite, EntityDocument},
+	"GetChildren":            {ActionRead, EntityDocument},
+	"GetSubtree":             {ActionRead, EntityDocument},
+	"GetSubtreeWithinBudget": {ActionRead, EntityDocument},
+	"GetAncestorPath":        {ActionRead, EntityDocument},
+	"BatchGetAncestorPaths":  {ActionRead, EntityDocument},
+	"GetSiblings":            {ActionRead, EntityDocument},
+	"GetNextSection":         {ActionRead, EntityDocument},
+	"GetPreviousSection":     {ActionRead, EntityDocument},
+	"GetReadingOrder":        {ActionRead, EntityDocument},
+	"GetContextWindow":       {ActionRead, EntityDocument},
+	"ExportGraph":            {ActionRead, EntityDocument},
+	"SearchByKeyword":        {ActionRead, EntityDocument},
+	"GetNodesByPage":         {ActionRead, EntityDocument},
+	"GlobalSearch":           {ActionRead, EntityDocument},
+	"Retrieve":               {ActionRead, EntityDocument},
+	"JoinNodes":              {ActionRead, EntityDocument},
+
+	"GetVersionAsOf":       {ActionRead, EntityVersion},
+	"BatchGetVersionsAsOf": {ActionRead, EntityVersion},
+	"ListVersions":         {ActionRead, EntityVersion},
+	"DeleteVersion":        {ActionWrite, EntityVersion},
+	"PruneVersions":        {ActionWrite, EntityVersion},
+	"TagVersion":           {ActionWrite, EntityVersion},
+	"UntagVersion":         {ActionWrite, EntityVersion},
+
+	"StoreToolResult":     {ActionWrite, EntityMetadata},
+	"GetToolResults":      {ActionRead, EntityMetadata},
+	"StoreTrajectory":     {ActionWrite, EntityMetadata},
+	"GetTrajectories":     {ActionRead, EntityMetadata},
+	"ReplayTrajectory":    {ActionRead, EntityDocument},
+	"StoreCrossReference": {ActionWrite, EntityMetadata},
+	"GetCrossReferences":  {ActionRead, EntityMetadata},
+	"StoreContradiction":  {ActionWrite, EntityMetadata},
+	"BatchSetMetadata":    {ActionWrite, EntityMetadata},
+
+	"PutCollection":           {ActionWrite, EntityMetadata},
+	"GetCollection":           {ActionRead, EntityMetadata},
+	"ListCollections":         {ActionRead, EntityMetadata},
+	"UpdateCollectionMembers": {ActionWrite, EntityMetadata},
+	"DeleteCollection":        {ActionWrite, EntityMetadata},
+
+	"StorePrompt":       {ActionWrite, EntityPrompt},
+	"GetPrompt":         {ActionRead, EntityPrompt},
+	"RecordPromptUsage": {ActionWrite, EntityPrompt},
+
+	"StorePromptExperiment": {ActionWrite, EntityPrompt},
+	"GetPromptExperiment":   {ActionRead, EntityPrompt},
+	"AssignPromptVariant":   {ActionRead, EntityPrompt},
+	"LabelPromptUsage":      {ActionWrite, EntityPrompt},
+	"ComparePromptVariants": {ActionRead, EntityPrompt},
+
+	"GetMessagesPage":     {ActionRead, EntityConversation},
+	"GetRecentMessages":   {ActionRead, EntityConversation},
+	"SearchConversations": {ActionRead, EntityConversation},
+	"ExportConversation":  {ActionRead, EntityConversation},
+
+	"ListConversationsByUser":  {ActionRead, EntityConversation},
+	"GetUserConversationUsage": {ActionRead, EntityConversation},
+	"SetUserConversationQuota": {ActionAdmin, EntityConversation},
+
+	"SaveQuery":         {ActionWrite, EntityQuery},
+	"ExecuteSavedQuery": {ActionRead, EntityQuery},
+	"RefreshSavedQuery": {ActionWrite, EntityQuery},
+	"ListSavedQueries":  {ActionRead, EntityQuery},
+	"DeleteSavedQuery":  {ActionWrite, EntityQuery},
+
+	"ListJobRuns": {ActionRead, EntityMetadata},
+	"TriggerJob":  {ActionWrite, EntityMetadata},
+
+	"StreamQuery":   {ActionRead, EntityQuery},
+	"WatchChanges":  {ActionRead, EntityChanges},
+	"StreamWAL":     {ActionAdmin, EntityReplication},
+	"QueryAuditLog": {ActionAdmin, EntityAudit},
+	"Health":        {ActionRead, EntitySystem},
+	"Stats":         {ActionRead, EntitySystem},
+
+	"StorageBreakdown": {ActionAdmin, EntitySystem},
+
+	// TreeStoreAdmin
+	"Checkpoint":  {ActionAdmin, EntitySystem},
+	"Compact":     {ActionAdmin, EntitySystem},
+	"Reindex":     {ActionAdmin, EntitySystem},
+	"Flush":       {ActionAdmin, EntitySystem},
+	"Backup":      {ActionAdmin, EntitySystem},
+	"SetLogLevel": {ActionAdmin, EntitySystem},
+	"DumpState":   {ActionAdmin, EntitySystem},
+	"TailLogs":    {ActionAdmin, EntitySystem},
+}
+
+// rbacServices are the services whose methods methodPermissions names
+var rbacServices = map[string]bool{
+	pb.TreeStoreService_ServiceDesc.ServiceName: true,
+	pb.TreeStoreAdmin_ServiceDesc.ServiceName:   true,
+}
+
+// Role grants actions on entity types
+type Role struct {
+	Read  []string `yaml:"read"`
+	Write []string `yaml:"write"`
+	Admin []string `yaml:"admin"`
+}
+
+// BuiltinRoles are available without being declared in the RBAC config
+var BuiltinRoles = map[string]Role{
+	"reader":      {Read: []string{"*"}},
+	"writer":      {Read: []string{"*"}, Write: []string{"*"}},
+	"admin":       {Read: []string{"*"}, Write: []string{"*"}, Admin: []string{"*"}},
+	"prompt-only": {Read: []string{EntityPrompt, EntityConversation, EntitySystem}, Write: []string{EntityPrompt, EntityConversation}},
+}
+
+// allows reports whether the role grants action on entity
+func (r Role) allows(action, entity string) bool {
+	var granted []string
+	switch action {
+	case ActionRead:
+		granted = r.Read
+	case ActionWrite:
+		granted = r.Write
+	case ActionAdmin:
+		granted = r.Admin
+	}
+	for _, e := range granted {
+		if e == "*" || e == entity {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal maps an API key to a role
+// Set KeySHA256 (hex) instead of Key to keep secrets out of the config file.
+type Principal struct {
+	Name      string `yaml:"name"`
+	Key       string `yaml:"key"`
+	KeySHA256 string `yaml:"key_sha256"`
+	Role      string `yaml:"role"`
+}
+
+// RBACConfig is the YAML access control configuration
+type RBACConfig struct {
+	KeyHeader     string          `yaml:"key_header"`     // Defaults to DefaultAPIKeyHeader
+	AnonymousRole string          `yaml:"anonymous_role"` // Role for callers without a key; empty denies them
+	Roles         map[string]Role `yaml:"roles"`          // Custom roles, added to BuiltinRoles
+	Principals    []Principal     `yaml:"principals"`
+	Redaction     RedactionConfig `yaml:"redaction"` // Text masked in read responses, by role
+}
+
+// RBAC enforces an RBACConfig; every call not granted by a role is denied
+type RBAC struct {
+	keyHeader string
+	anonymous string            // Role name for callers without a key, if any
+	roles     map[string]Role   // Built-in and custom roles by name
+	byKeyHash map[string]string // Role names by hex SHA-256 of the API key
+	redactor  *PatternRedactor  // Nil without redaction patterns
+}
+
+// LoadRBAC reads an RBAC config from a YAML file
+func LoadRBAC(path string) (*RBAC, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RBAC config: %w", err)
+	}
+
+	var cfg RBACConfig
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse RBAC config %s: %w", path, err)
+	}
+	return NewRBAC(cfg)
+}
+
+// NewRBAC validates a config and builds its key lookup
+func NewRBAC(cfg RBACConfig) (*RBAC, error) {
+	roles := make(map[string]Role, len(BuiltinRoles)+len(cfg.Roles))
+	for name, role := range BuiltinRoles {
+		roles[name] = role
+	}
+	for name, role := range cfg.Roles {
+		if _, ok := BuiltinRoles[name]; ok {
+			return nil, fmt.Errorf("role %q redefines a built-in role", name)
+		}
+		roles[name] = role
+	}
+
+	r := &RBAC{
+		keyHeader: cfg.KeyHeader,
+		anonymous: cfg.AnonymousRole,
+		roles:     roles,
+		byKeyHash: make(map[string]string, len(cfg.Principals)),
+	}
+	if r.keyHeader == "" {
+		r.keyHeader = DefaultAPIKeyHeader
+	}
+	if _, ok := roles[cfg.AnonymousRole]; cfg.AnonymousRole != "" && !ok {
+		return nil, fmt.Errorf("anonymous_role: unknown role %q", cfg.AnonymousRole)
+	}
+
+	for i, p := range cfg.Principals {
+		if _, ok := roles[p.Role]; !ok {
+			return nil, fmt.Errorf("principal %d (%s): unknown role %q", i, p.Name, p.Role)
+		}
+		hash := strings.ToLower(p.KeySHA256)
+		if (p.Key == "") == (hash == "") {
+			return nil, fmt.Errorf("principal %d (%s): set exactly one of key and key_sha256", i, p.Name)
+		}
+		if p.Key != "" {
+			hash = keyHash(p.Key)
+		}
+		if _, dup := r.byKeyHash[hash]; dup {
+			return nil, fmt.Errorf("principal %d (%s): key is already assigned", i, p.Name)
+		}
+		r.byKeyHash[hash] = p.Role
+	}
+
+	if len(cfg.Redaction.Patterns) > 0 {
+		var err error
+		if r.redactor, err = NewPatternRedactor(cfg.Redaction, roles); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Redactor returns the redaction configured alongside the roles, or nil
+func (r *RBAC) Redactor() Redactor {
+	if r.redactor == nil {
+		return nil
+	}
+	return r.redactor
+}
+
+// roleKey is the context key under which authorized calls carry their role name
+type roleKey struct{}
+
+// principalKey is the context key under which authorized calls carry the
+// principal whose API key they sent
+type principalKey struct{}
+
+// RoleFromContext returns the role an authorized call was granted under
+func RoleFromContext(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(roleKey{}).(string)
+	return role, ok
+}
+
+// PrincipalFromContext returns the principal an authorized call authenticated
+// as, a fingerprint of its API key; anonymous calls have none
+func PrincipalFromContext(ctx context.Context) (string, bool) {
+	principal, ok := ctx.Value(principalKey{}).(string)
+	return principal, ok
+}
+
+// grant is what authorize found out about a granted call
+type grant struct {
+	role      string
+	principal string // Empty for anonymous callers
+}
+
+// context returns ctx carrying the grant, see RoleFromContext and PrincipalFromContext
+func (g grant) context(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, roleKey{}, g.role)
+	if g.principal != "" {
+		ctx = context.WithValue(ctx, principalKey{}, g.principal)
+	}
+	return ctx
+}
+
+// contextStream serves a stream with a context derived from its own, such as
+// one carrying the caller's role
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// keyHash returns the hex SHA-256 of an API key
+func keyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authorize checks that the caller's role grants the method
+func (r *RBAC) Authorize(ctx context.Context, fullMethod string) error {
+	_, err := r.authorize(ctx, fullMethod)
+	return err
+}
+
+// authorize returns the caller's role and principal if the role grants the method
+func (r *RBAC) authorize(ctx context.Context, fullMethod string) (grant, error) {
+	method := path.Base(fullMethod)
+	perm, known := methodPermissions[method]
+	if !known || !rbacServices[strings.TrimPrefix(path.Dir(fullMethod), "/")] {
+		perm = permission{ActionAdmin, "*"}
+	}
+
+	var key string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if keys := md.Get(r.keyHeader); len(keys) > 0 {
+			key = keys[0]
+		}
+	}
+
+	var g grant
+	switch {
+	case key != "":
+		hash := keyHash(key)
+		var ok bool
+		if g.role, ok = r.byKeyHash[hash]; !ok {
+			return grant{}, status.Error(codes.Unauthenticated, "unknown API key")
+		}
+		g.principal = keyFingerprint(hash)
+	case r.anonymous != "":
+		g.role = r.anonymous
+	default:
+		return grant{}, status.Errorf(codes.Unauthenticated, "missing %s", r.keyHeader)
+	}
+
+	if !r.roles[g.role].allows(perm.action, perm.entity) {
+		return grant{}, status.Errorf(codes.PermissionDenied, "%s requires %s access to %s", method, perm.action, perm.entity)
+	}
+	return g, nil
+}
+
+// UnaryInterceptor rejects unary calls the caller's role does not grant
+// Granted calls carry the role name and principal, see RoleFromContext and
+// PrincipalFromContext.
+func (r *RBAC) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		g, err := r.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(g.context(ctx), req)
+	}
+}
+
+// StreamInterceptor rejects streams the caller's role does not grant
+// Granted streams carry the role name and principal, see RoleFromContext and
+// PrincipalFromContext.
+func (r *RBAC) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		g, err := r.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: g.context(ss.Context())})
+	}
+}
@@ -0,0 +1,141 @@
+// Tests for role-based access control
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestMethodPermissionsCoverService(t *testing.T) {
+	for _, desc := range []grpc.ServiceDesc{pb.TreeStoreService_ServiceDesc, pb.TreeStoreAdmin_ServiceDesc} {
+		for _, m := range desc.Methods {
+			if _, ok := methodPermissions[m.MethodName]; !ok {
+				t.Errorf("No permission defined for %s", m.MethodName)
+			}
+		}
+		for _, s := range desc.Streams {
+			if _, ok := methodPermissions[s.StreamName]; !ok {
+				t.Errorf("No permission defined for %s", s.StreamName)
+			}
+		}
+	}
+}
+
+func TestRBACAuthorize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rbac.yaml")
+	config := `
+anonymous_role: health-only
+roles:
+  health-only:
+    read: [system]
+  claims-editor:
+    read: [document, metadata]
+    write: [metadata]
+  operator:
+    read: [system]
+    admin: [system]
+principals:
+  - name: ingest
+    key: writer-key
+    role: writer
+  - name: agent
+    key_sha256: ` + keyHash("agent-key") + `
+    role: prompt-only
+  - name: claims
+    key: claims-key
+    role: claims-editor
+  - name: ops
+    key: admin-key
+    role: admin
+  - name: oncall
+    key: operator-key
+    role: operator
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rbac, err := LoadRBAC(path)
+	if err != nil {
+		t.Fatalf("LoadRBAC failed: %v", err)
+	}
+
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(DefaultAPIKeyHeader, key))
+	}
+	method := func(name string) string { return "/treestore.TreeStoreService/" + name }
+	adminMethod := func(name string) string { return "/treestore.TreeStoreAdmin/" + name }
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		want   codes.Code
+	}{
+		{"writer stores documents", withKey("writer-key"), method("StoreDocument"), codes.OK},
+		{"writer cannot read audit log", withKey("writer-key"), method("QueryAuditLog"), codes.PermissionDenied},
+		{"prompt-only stores prompts", withKey("agent-key"), method("StorePrompt"), codes.OK},
+		{"prompt-only cannot read documents", withKey("agent-key"), method("GetNode"), codes.PermissionDenied},
+		{"custom role writes metadata", withKey("claims-key"), method("BatchSetMetadata"), codes.OK},
+		{"custom role cannot write documents", withKey("claims-key"), method("StoreDocument"), codes.PermissionDenied},
+		{"custom role cannot read versions", withKey("claims-key"), method("ListVersions"), codes.PermissionDenied},
+		{"admin streams the WAL", withKey("admin-key"), method("StreamWAL"), codes.OK},
+		{"custom role checkpoints", withKey("operator-key"), adminMethod("Checkpoint"), codes.OK},
+		{"custom role tails logs", withKey("operator-key"), adminMethod("TailLogs"), codes.OK},
+		{"custom role cannot stream the WAL", withKey("operator-key"), method("StreamWAL"), codes.PermissionDenied},
+		{"writer cannot compact", withKey("writer-key"), adminMethod("Compact"), codes.PermissionDenied},
+		{"admin compacts", withKey("admin-key"), adminMethod("Compact"), codes.OK},
+		{"admin uses reflection", withKey("admin-key"), "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", codes.OK},
+		{"writer cannot use reflection", withKey("writer-key"), "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", codes.PermissionDenied},
+		{"unknown methods are denied", withKey("writer-key"), method("DropEverything"), codes.PermissionDenied},
+		{"unknown key", withKey("nope"), method("Health"), codes.Unauthenticated},
+		{"anonymous health check", context.Background(), method("Health"), codes.OK},
+		{"anonymous read", context.Background(), method("GetNode"), codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(rbac.Authorize(tt.ctx, tt.method)); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// Without an anonymous role, callers must send a key
+	strict, err := NewRBAC(RBACConfig{})
+	if err != nil {
+		t.Fatalf("NewRBAC failed: %v", err)
+	}
+	if got := status.Code(strict.Authorize(context.Background(), method("Health"))); got != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without a key, got %v", got)
+	}
+}
+
+func TestRBACConfigErrors(t *testing.T) {
+	bad := []RBACConfig{
+		{AnonymousRole: "missing"},
+		{Principals: []Principal{{Name: "a", Key: "k", Role: "missing"}}},
+		{Principals: []Principal{{Name: "a", Role: "reader"}}},
+		{Principals: []Principal{{Name: "a", Key: "k", KeySHA256: keyHash("k"), Role: "reader"}}},
+		{Principals: []Principal{{Name: "a", Key: "k", Role: "reader"}, {Name: "b", KeySHA256: keyHash("k"), Role: "writer"}}},
+		{Roles: map[string]Role{"admin": {}}},
+	}
+	for i, cfg := range bad {
+		if _, err := NewRBAC(cfg); err == nil {
+			t.Errorf("Config %d: expected an error", i)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "rbac.yaml")
+	os.WriteFile(path, []byte("principal: []\n"), 0o600)
+	if _, err := LoadRBAC(path); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
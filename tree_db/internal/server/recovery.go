@@ -0,0 +1,60 @@
+// Panic recovery for gRPC handlers, so a failed tree read fails one request instead of the process
+package server
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nainya/treestore/internal/logger"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// RecoveryInterceptor converts a panic in a later interceptor or handler into
+// an Internal error. Storage corruption that escapes as a panic (a corrupt page
+// or node, or an undecryptable value) keeps its message; anything else is
+// reported to the caller as a bare internal error.
+// onPanic, if set, is called with the method after the panic is logged.
+func RecoveryInterceptor(log *logger.Logger, onPanic func(method string)) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoveredError(logger.FromContext(ctx, log), onPanic, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamInterceptor is RecoveryInterceptor for streaming RPCs
+func RecoveryStreamInterceptor(log *logger.Logger, onPanic func(method string)) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoveredError(logger.FromContext(ss.Context(), log), onPanic, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recoveredError logs a recovered panic and returns the status sent to the caller
+func recoveredError(log *logger.Logger, onPanic func(string), method string, r interface{}) error {
+	log.Error("Recovered from panic in gRPC handler").
+		Str("method", method).
+		Str("panic", fmt.Sprint(r)).
+		Bytes("stack", debug.Stack()).
+		Send()
+	if onPanic != nil {
+		onPanic(method)
+	}
+
+	if err, ok := r.(error); ok && storage.IsCorruption(err) {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,62 @@
+// Tests for converting handler panics into Internal errors
+package server
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nainya/treestore/internal/logger"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+func TestRecoveryInterceptor(t *testing.T) {
+	log := logger.NewLogger(logger.Config{Output: io.Discard})
+	var panicked []string
+	intercept := RecoveryInterceptor(log, func(method string) { panicked = append(panicked, method) })
+	info := &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/GetNode"}
+
+	resp, err := intercept(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	if err != nil || resp != "ok" {
+		t.Fatalf("Expected the handler result to pass through, got %v (%v)", resp, err)
+	}
+
+	_, err = intercept(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("index out of range")
+	})
+	if status.Code(err) != codes.Internal || strings.Contains(err.Error(), "index out of range") {
+		t.Errorf("Expected a bare Internal error, got %v", err)
+	}
+
+	// Storage panics keep their message so operators can find the bad page
+	_, err = intercept(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic(&storage.CorruptPageError{Path: "tree.db", Ptr: 7})
+	})
+	if status.Code(err) != codes.Internal || !strings.Contains(err.Error(), "page 7") {
+		t.Errorf("Expected the corrupt page in the error, got %v", err)
+	}
+
+	if len(panicked) != 2 || panicked[0] != info.FullMethod {
+		t.Errorf("Expected 2 recorded panics, got %v", panicked)
+	}
+}
+
+func TestRecoveryStreamInterceptor(t *testing.T) {
+	log := logger.NewLogger(logger.Config{Output: io.Discard})
+	intercept := RecoveryStreamInterceptor(log, nil)
+	info := &grpc.StreamServerInfo{FullMethod: "/treestore.TreeStoreService/StreamQuery"}
+
+	err := intercept(nil, &contextStream{ctx: context.Background()}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		panic(&storage.SealError{Key: []byte("k"), Err: storage.ErrUnknownKey})
+	})
+	if status.Code(err) != codes.Internal || !strings.Contains(err.Error(), "cannot decrypt") {
+		t.Errorf("Expected an Internal decrypt error, got %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+// Redaction of sensitive text in read responses based on the caller's role
+package server
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+// redactedMethods are the reads whose node text, summaries, titles and
+// snippets pass through the Redactor
+var redactedMethods = map[string]bool{
+	"GetNode":                true,
+	"GetChildren":            true,
+	"GetSubtree":             true,
+	"GetSubtreeWithinBudget": true,
+	"GetAncestorPath":        true,
+	"GetContextWindow":       true,
+	"SearchByKeyword":        true,
+	"GetNodesByPage":         true,
+	"GlobalSearch":           true,
+	"Retrieve":               true,
+	"JoinNodes":              true,
+	"StreamQuery":            true,
+	"ExecuteSavedQuery":      true,
+	"ReplayTrajectory":       true,
+}
+
+// Redaction replaces the bytes [Start, End) of a text with Replacement
+type Redaction struct {
+	Start, End  int
+	Replacement string
+}
+
+// Redactor decides what a role may not see of a text
+// Implementations return the spans to mask in any order; overlapping spans
+// are resolved in favour of the one starting first.
+type Redactor interface {
+	Redactions(role, text string) []Redaction
+}
+
+// BuiltinRedactionPatterns are used for patterns that give a name but no regex
+var BuiltinRedactionPatterns = map[string]string{
+	"ssn": `\b\d{3}-\d{2}-\d{4}\b`,
+	"mrn": `(?i)\bMRN[:#\s]*\d{6,10}\b`,
+}
+
+// RedactionPattern masks every match of a regular expression
+type RedactionPattern struct {
+	Name        string   `yaml:"name"`
+	Regex       string   `yaml:"regex"`       // Defaults to the built-in pattern of the same name
+	Replacement string   `yaml:"replacement"` // Defaults to "[REDACTED <NAME>]"
+	Roles       []string `yaml:"roles"`       // Roles it applies to; empty applies it to every role not exempt
+}
+
+// RedactionConfig lists the patterns masked in read responses
+type RedactionConfig struct {
+	ExemptRoles []string           `yaml:"exempt_roles"` // Roles that always see text as stored
+	Patterns    []RedactionPattern `yaml:"patterns"`
+}
+
+// compiledPattern is a RedactionPattern ready to match
+type compiledPattern struct {
+	re          *regexp.Regexp
+	replacement string
+	roles       map[string]bool // Nil for every role
+}
+
+// PatternRedactor is a Redactor over regular expressions
+type PatternRedactor struct {
+	exempt   map[string]bool
+	patterns []compiledPattern
+}
+
+// NewPatternRedactor compiles a config, checking its role names against roles
+func NewPatternRedactor(cfg RedactionConfig, roles map[string]Role) (*PatternRedactor, error) {
+	checkRole := func(where, name string) error {
+		if _, ok := roles[name]; !ok {
+			return fmt.Errorf("redaction %s: unknown role %q", where, name)
+		}
+		return nil
+	}
+
+	r := &PatternRedactor{exempt: make(map[string]bool, len(cfg.ExemptRoles))}
+	for _, name := range cfg.ExemptRoles {
+		if err := checkRole("exempt_roles", name); err != nil {
+			return nil, err
+		}
+		r.exempt[name] = true
+	}
+
+	for i, p := range cfg.Patterns {
+		expr := p.Regex
+		if expr == "" {
+			var ok bool
+			if expr, ok = BuiltinRedactionPatterns[p.Name]; !ok {
+				return nil, fmt.Errorf("redaction pattern %d (%s): regex is required", i, p.Name)
+			}
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("redaction pattern %d (%s): %w", i, p.Name, err)
+		}
+
+		cp := compiledPattern{re: re, replacement: p.Replacement}
+		if cp.replacement == "" {
+			cp.replacement = "[REDACTED]"
+			if p.Name != "" {
+				cp.replacement = "[REDACTED " + strings.ToUpper(p.Name) + "]"
+			}
+		}
+		if len(p.Roles) > 0 {
+			cp.roles = make(map[string]bool, len(p.Roles))
+			for _, name := range p.Roles {
+				if err := checkRole(fmt.Sprintf("pattern %d (%s)", i, p.Name), name); err != nil {
+					return nil, err
+				}
+				cp.roles[name] = true
+			}
+		}
+		r.patterns = append(r.patterns, cp)
+	}
+
+	return r, nil
+}
+
+// Redactions returns every match of the patterns that apply to role
+func (r *PatternRedactor) Redactions(role, text string) []Redaction {
+	if r.exempt[role] {
+		return nil
+	}
+	var spans []Redaction
+	for _, p := range r.patterns {
+		if p.roles != nil && !p.roles[role] {
+			continue
+		}
+		for _, m := range p.re.FindAllStringIndex(text, -1) {
+			spans = append(spans, Redaction{Start: m[0], End: m[1], Replacement: p.replacement})
+		}
+	}
+	return spans
+}
+
+// redactText applies a Redactor to text, returning the masked text and a
+// function mapping byte offsets in text to offsets in the result; offsets
+// inside a masked span map to -1
+func redactText(r Redactor, role, text string) (string, func(int) int) {
+	spans := r.Redactions(role, text)
+	if len(spans) == 0 {
+		return text, func(off int) int { return off }
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+
+	var out strings.Builder
+	var applied []Redaction
+	prev := 0
+	for _, s := range spans {
+		if s.Start < prev || s.Start > s.End || s.End > len(text) {
+			continue
+		}
+		out.WriteString(text[prev:s.Start])
+		out.WriteString(s.Replacement)
+		applied = append(applied, s)
+		prev = s.End
+	}
+	out.WriteString(text[prev:])
+
+	shift := func(off int) int {
+		delta := 0
+		for _, s := range applied {
+			if off < s.Start {
+				break
+			}
+			if off < s.End {
+				return -1
+			}
+			delta += len(s.Replacement) - (s.End - s.Start)
+		}
+		return off + delta
+	}
+	return out.String(), shift
+}
+
+// redactMessage masks node text, context entries and search snippets anywhere
+// in a response
+func redactMessage(r Redactor, role string, msg proto.Message) {
+	var walk func(m protoreflect.Message)
+	walk = func(m protoreflect.Message) {
+		switch v := m.Interface().(type) {
+		case *pb.Node:
+			v.Title, _ = redactText(r, role, v.Title)
+			v.Summary, _ = redactText(r, role, v.Summary)
+			v.Text, _ = redactText(r, role, v.Text)
+		case *pb.ContextEntry:
+			v.Title, _ = redactText(r, role, v.Title)
+			v.Summary, _ = redactText(r, role, v.Summary)
+		case *pb.SearchResult:
+			redactSnippet(r, role, v)
+		case *pb.StepReplay:
+			v.Observation, _ = redactText(r, role, v.Observation)
+		case *pb.RetrievedDocument:
+			v.PageContent, _ = redactText(r, role, v.PageContent)
+			if title, ok := v.Metadata["title"]; ok {
+				v.Metadata["title"], _ = redactText(r, role, title)
+			}
+		}
+
+		m.Range(func(fd protoreflect.FieldDescriptor, val protoreflect.Value) bool {
+			switch {
+			case fd.IsMap():
+				if fd.MapValue().Kind() == protoreflect.MessageKind {
+					val.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+						walk(mv.Message())
+						return true
+					})
+				}
+			case fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind:
+			case fd.IsList():
+				for i := 0; i < val.List().Len(); i++ {
+					walk(val.List().Get(i).Message())
+				}
+			default:
+				walk(val.Message())
+			}
+			return true
+		})
+	}
+	walk(msg.ProtoReflect())
+}
+
+// redactSnippet masks a search snippet, moving its highlights to match and
+// dropping those that fell inside a masked span
+func redactSnippet(r Redactor, role string, res *pb.SearchResult) {
+	snippet, shift := redactText(r, role, res.Snippet)
+	if snippet == res.Snippet {
+		return
+	}
+	res.Snippet = snippet
+
+	kept := res.Highlights[:0]
+	for _, h := range res.Highlights {
+		start, end := shift(int(h.Start)), shift(int(h.End)-1)
+		if start < 0 || end < 0 {
+			continue
+		}
+		kept = append(kept, &pb.Highlight{Start: int32(start), End: int32(end + 1)})
+	}
+	res.Highlights = kept
+}
+
+// RedactionInterceptor masks text in the responses of redactedMethods for
+// callers whose role the Redactor restricts. It reads the role set by the RBAC
+// interceptor, so it must follow it in the chain; calls without a role are
+// redacted as the empty role.
+func RedactionInterceptor(r Redactor) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil || !redactedMethods[path.Base(info.FullMethod)] {
+			return resp, err
+		}
+		if msg, ok := resp.(proto.Message); ok {
+			role, _ := RoleFromContext(ctx)
+			redactMessage(r, role, msg)
+		}
+		return resp, nil
+	}
+}
+
+// RedactionStreamInterceptor masks text in streamed responses like RedactionInterceptor
+func RedactionStreamInterceptor(r Redactor) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if !redactedMethods[path.Base(info.FullMethod)] {
+			return handler(srv, ss)
+		}
+		role, _ := RoleFromContext(ss.Context())
+		return handler(srv, &redactingStream{ServerStream: ss, redactor: r, role: role})
+	}
+}
+
+// redactingStream masks each message sent to the client
+type redactingStream struct {
+	grpc.ServerStream
+	redactor Redactor
+	role     string
+}
+
+func (rs *redactingStream) SendMsg(m interface{}) error {
+	if msg, ok := m.(proto.Message); ok {
+		redactMessage(rs.redactor, rs.role, msg)
+	}
+	return rs.ServerStream.SendMsg(m)
+}
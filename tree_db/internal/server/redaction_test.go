@@ -0,0 +1,157 @@
+// Tests for role-based redaction of read responses
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+func testRedactor(t *testing.T) *PatternRedactor {
+	roles := map[string]Role{"reader": BuiltinRoles["reader"], "admin": BuiltinRoles["admin"], "auditor": {}}
+	r, err := NewPatternRedactor(RedactionConfig{
+		ExemptRoles: []string{"admin"},
+		Patterns: []RedactionPattern{
+			{Name: "ssn"},
+			{Name: "mrn"},
+			{Name: "member", Regex: `M\d{5}`, Replacement: "M*****", Roles: []string{"auditor"}},
+		},
+	}, roles)
+	if err != nil {
+		t.Fatalf("NewPatternRedactor failed: %v", err)
+	}
+	return r
+}
+
+func TestRedactText(t *testing.T) {
+	r := testRedactor(t)
+	text := "Member M12345, SSN 078-05-1120, MRN: 00412345 is covered"
+
+	tests := []struct {
+		role string
+		want string
+	}{
+		{"admin", text},
+		{"reader", "Member M12345, SSN [REDACTED SSN], [REDACTED MRN] is covered"},
+		{"auditor", "Member M*****, SSN [REDACTED SSN], [REDACTED MRN] is covered"},
+	}
+	for _, tt := range tests {
+		if got, _ := redactText(r, tt.role, text); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.role, got, tt.want)
+		}
+	}
+
+	_, shift := redactText(r, "reader", text)
+	if got := shift(strings.Index(text, "covered")); got != strings.Index("Member M12345, SSN [REDACTED SSN], [REDACTED MRN] is covered", "covered") {
+		t.Errorf("Offset after the redactions shifted to %d", got)
+	}
+	if got := shift(strings.Index(text, "05-1120")); got != -1 {
+		t.Errorf("Expected an offset inside a redaction to map to -1, got %d", got)
+	}
+
+	bad := []RedactionConfig{
+		{Patterns: []RedactionPattern{{Name: "custom"}}},
+		{Patterns: []RedactionPattern{{Name: "broken", Regex: "("}}},
+		{ExemptRoles: []string{"missing"}, Patterns: []RedactionPattern{{Name: "ssn"}}},
+		{Patterns: []RedactionPattern{{Name: "ssn", Roles: []string{"missing"}}}},
+	}
+	for i, cfg := range bad {
+		if _, err := NewPatternRedactor(cfg, BuiltinRoles); err == nil {
+			t.Errorf("Config %d: expected an error", i)
+		}
+	}
+}
+
+func TestRedactionInterceptor(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "POL-R", VersionId: "v1", RootNodeId: "root"},
+		Nodes: []*pb.Node{{
+			NodeId: "root", PolicyId: "POL-R", Title: "Appeals",
+			Text: "Appeals for SSN 078-05-1120 must include the denial letter",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	intercept := RedactionInterceptor(testRedactor(t))
+	call := func(role, method string, req interface{}, handler grpc.UnaryHandler) interface{} {
+		ctx := context.WithValue(context.Background(), roleKey{}, role)
+		resp, err := intercept(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/" + method}, handler)
+		if err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+		return resp
+	}
+	getNode := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return server.GetNode(ctx, req.(*pb.GetNodeRequest))
+	}
+	search := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return server.SearchByKeyword(ctx, req.(*pb.SearchRequest))
+	}
+
+	node := call("reader", "GetNode", &pb.GetNodeRequest{PolicyId: "POL-R", NodeId: "root"}, getNode).(*pb.GetNodeResponse).Node
+	if strings.Contains(node.Text, "078-05-1120") || !strings.Contains(node.Text, "[REDACTED SSN]") {
+		t.Errorf("Expected the SSN to be masked for readers, got %q", node.Text)
+	}
+	node = call("admin", "GetNode", &pb.GetNodeRequest{PolicyId: "POL-R", NodeId: "root"}, getNode).(*pb.GetNodeResponse).Node
+	if !strings.Contains(node.Text, "078-05-1120") {
+		t.Errorf("Expected admins to see the stored text, got %q", node.Text)
+	}
+
+	results := call("reader", "SearchByKeyword", &pb.SearchRequest{PolicyId: "POL-R", Query: "denial"}, search).(*pb.SearchResponse).Results
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 search result, got %d", len(results))
+	}
+	res := results[0]
+	if strings.Contains(res.Snippet, "078-05-1120") || strings.Contains(res.Node.Text, "078-05-1120") {
+		t.Errorf("Expected the snippet and node to be masked, got %q", res.Snippet)
+	}
+	if len(res.Highlights) != 1 || res.Snippet[res.Highlights[0].Start:res.Highlights[0].End] != "denial" {
+		t.Errorf("Expected the highlight to follow the masked snippet, got %v in %q", res.Highlights, res.Snippet)
+	}
+
+	// Only responses are masked, never the stored text
+	stored, _ := server.GetNode(context.Background(), &pb.GetNodeRequest{PolicyId: "POL-R", NodeId: "root"})
+	if !strings.Contains(stored.Node.Text, "078-05-1120") {
+		t.Errorf("Redaction must not change stored text, got %q", stored.Node.Text)
+	}
+}
+
+func TestRBACRedactionConfig(t *testing.T) {
+	rbac, err := NewRBAC(RBACConfig{
+		Principals: []Principal{{Name: "ops", Key: "admin-key", Role: "admin"}},
+		Redaction:  RedactionConfig{ExemptRoles: []string{"admin"}, Patterns: []RedactionPattern{{Name: "ssn"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewRBAC failed: %v", err)
+	}
+	if rbac.Redactor() == nil {
+		t.Fatal("Expected a redactor")
+	}
+
+	// The RBAC interceptor hands the role to later interceptors
+	var role string
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(DefaultAPIKeyHeader, "admin-key"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/GetNode"}
+	rbac.UnaryInterceptor()(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		role, _ = RoleFromContext(ctx)
+		return nil, nil
+	})
+	if role != "admin" {
+		t.Errorf("Expected role admin in the context, got %q", role)
+	}
+
+	if plain, _ := NewRBAC(RBACConfig{}); plain.Redactor() != nil {
+		t.Error("Expected no redactor without patterns")
+	}
+}
@@ -0,0 +1,261 @@
+// Replay of the retrieval steps of stored trajectories against current or versioned documents
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nainya/treestore/pkg/metadata"
+	"github.com/nainya/treestore/pkg/version"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// Outcomes of a replayed step
+const (
+	replayMatched  = "matched"
+	replayDiverged = "diverged"
+	replayFailed   = "failed"
+	replaySkipped  = "skipped"
+)
+
+// replayTools are the MCP tools whose recorded calls are replayed, by the
+// tool names and RPC names agents record them under
+var replayTools = map[string]string{
+	"search":          "search",
+	"get_node":        "get_node",
+	"get_children":    "get_children",
+	"get_subtree":     "get_subtree",
+	"SearchByKeyword": "search",
+	"GetNode":         "get_node",
+	"GetChildren":     "get_children",
+	"GetSubtree":      "get_subtree",
+}
+
+func (s *Server) ReplayTrajectory(ctx context.Context, req *pb.ReplayTrajectoryRequest) (*pb.ReplayTrajectoryResponse, error) {
+	s.countOp("ReplayTrajectory")
+
+	switch {
+	case req.TrajectoryId == "":
+		return nil, status.Error(codes.InvalidArgument, "trajectory_id is required")
+	case (req.PolicyId == "") != (req.VersionId == ""):
+		return nil, status.Error(codes.InvalidArgument, "policy_id and version_id are given together")
+	case req.VersionId != "" && req.AsOfTime != nil:
+		return nil, status.Error(codes.InvalidArgument, "version_id and as_of_time are exclusive")
+	}
+
+	steps, err := s.metaStore.TrajectorySteps(req.TrajectoryId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get trajectory steps: %v", err)
+	}
+	if len(steps) == 0 {
+		attrs, err := s.metaStore.GetAllMetadata(metadata.EntityTrajectory, req.TrajectoryId)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get trajectory: %v", err)
+		}
+		if len(attrs) == 0 {
+			return nil, status.Errorf(codes.NotFound, "trajectory %s not found", req.TrajectoryId)
+		}
+	}
+
+	target := &replayTarget{s: s, documents: make(map[string]string), errs: make(map[string]error)}
+	if req.AsOfTime != nil {
+		asOf := req.AsOfTime.AsTime()
+		target.asOf = &asOf
+	}
+	if req.VersionId != "" {
+		ver, err := s.verStore.GetVersion(req.PolicyId, req.VersionId)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "version not found: %v", err)
+		}
+		doc, err := s.versionDocument(ver)
+		if err != nil {
+			return nil, err
+		}
+		target.documents[req.PolicyId] = doc
+	}
+
+	tools := make(map[string]mcpTool)
+	for _, tool := range s.mcpTools() {
+		tools[tool.name] = tool
+	}
+	resp := &pb.ReplayTrajectoryResponse{Documents: make(map[string]string)}
+	for _, step := range steps {
+		replayed := s.replayStep(ctx, step, tools, target)
+		resp.Steps = append(resp.Steps, replayed)
+		switch replayed.Outcome {
+		case replayMatched:
+			resp.Matched++
+		case replayDiverged:
+			resp.Diverged++
+		case replayFailed:
+			resp.Failed++
+		default:
+			resp.Skipped++
+		}
+	}
+	for policyID, doc := range target.documents {
+		if doc != policyID {
+			resp.Documents[policyID] = doc
+		}
+	}
+	return resp, nil
+}
+
+// replayTarget resolves the document each policy's steps replay against
+type replayTarget struct {
+	s         *Server
+	asOf      *time.Time        // Resolve each policy's version as of this time; nil keeps current documents
+	documents map[string]string // Resolved document by policy
+	errs      map[string]error  // Resolution failures by policy
+}
+
+// document returns the document a policy's steps run against
+func (t *replayTarget) document(policyID string) (string, error) {
+	if doc, ok := t.documents[policyID]; ok {
+		return doc, nil
+	}
+	if err, ok := t.errs[policyID]; ok {
+		return "", err
+	}
+	if t.asOf == nil {
+		return policyID, nil
+	}
+
+	ver, err := t.s.verStore.GetVersionAsOf(policyID, *t.asOf)
+	var doc string
+	if err != nil {
+		err = fmt.Errorf("no version of %s as of %s", policyID, t.asOf.Format(time.RFC3339))
+	} else {
+		doc, err = t.s.versionDocument(ver)
+	}
+	if err != nil {
+		t.errs[policyID] = err
+		return "", err
+	}
+	t.documents[policyID] = doc
+	return doc, nil
+}
+
+// versionDocument returns the document holding a version's nodes: the stored
+// document its document_id names, or the policy itself while the version is
+// its latest
+func (s *Server) versionDocument(ver *version.Version) (string, error) {
+	if ver.DocumentID != "" && ver.DocumentID != ver.PolicyID {
+		if roots, err := s.docStore.GetChildren(ver.DocumentID, nil); err == nil && len(roots) > 0 {
+			return ver.DocumentID, nil
+		}
+	}
+	latest, err := s.verStore.GetLatestVersion(ver.PolicyID)
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "failed to get latest version: %v", err)
+	}
+	if latest.VersionID != ver.VersionID {
+		return "", status.Errorf(codes.FailedPrecondition,
+			"version %s of %s is superseded and its document_id names no stored document", ver.VersionID, ver.PolicyID)
+	}
+	return ver.PolicyID, nil
+}
+
+// replayStep re-runs one step and compares the nodes it returns with those
+// the step recorded
+func (s *Server) replayStep(ctx context.Context, step *metadata.TrajectoryStep, tools map[string]mcpTool, target *replayTarget) *pb.StepReplay {
+	out := &pb.StepReplay{StepNumber: int32(step.StepNumber), ToolName: step.ToolName}
+	skip := func(reason string) *pb.StepReplay {
+		out.Outcome, out.Reason = replaySkipped, reason
+		return out
+	}
+	fail := func(err error) *pb.StepReplay {
+		out.Outcome, out.Reason = replayFailed, status.Convert(err).Message()
+		return out
+	}
+
+	tool, ok := tools[replayTools[step.ToolName]]
+	if !ok {
+		return skip("not a retrieval tool")
+	}
+	var args map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(step.Action), &args); err != nil {
+		return skip("action is not a JSON object of arguments")
+	}
+	var recorded interface{}
+	if err := json.Unmarshal([]byte(step.Observation), &recorded); err != nil {
+		return skip("observation is not JSON")
+	}
+
+	// Steps on a redirected policy read its version's document instead
+	var policyID string
+	if raw, ok := args["policy_id"]; ok && json.Unmarshal(raw, &policyID) == nil && policyID != "" {
+		doc, err := target.document(policyID)
+		if err != nil {
+			return fail(err)
+		}
+		args["policy_id"], _ = json.Marshal(doc)
+	}
+	call, _ := json.Marshal(args)
+
+	result, err := tool.call(ctx, call)
+	var data []byte
+	if err == nil {
+		data, err = marshalResult(result)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	out.Observation = string(data)
+
+	var replayed interface{}
+	json.Unmarshal(data, &replayed)
+	before, after := make(map[string]string), make(map[string]string)
+	collectNodes(recorded, before)
+	collectNodes(replayed, after)
+	for nodeID, checksum := range before {
+		now, ok := after[nodeID]
+		switch {
+		case !ok:
+			out.MissingNodeIds = append(out.MissingNodeIds, nodeID)
+		case checksum != "" && now != "" && checksum != now:
+			out.ChangedNodeIds = append(out.ChangedNodeIds, nodeID)
+		}
+	}
+	for nodeID := range after {
+		if _, ok := before[nodeID]; !ok {
+			out.AddedNodeIds = append(out.AddedNodeIds, nodeID)
+		}
+	}
+	sort.Strings(out.MissingNodeIds)
+	sort.Strings(out.AddedNodeIds)
+	sort.Strings(out.ChangedNodeIds)
+
+	out.Outcome = replayMatched
+	if len(out.MissingNodeIds)+len(out.AddedNodeIds)+len(out.ChangedNodeIds) > 0 {
+		out.Outcome = replayDiverged
+	}
+	return out
+}
+
+// collectNodes finds the objects with a node_id in a decoded JSON value,
+// recording each node's checksum, empty when it has none
+func collectNodes(v interface{}, nodes map[string]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if nodeID, ok := v["node_id"].(string); ok && nodeID != "" {
+			checksum, _ := v["checksum"].(string)
+			if nodes[nodeID] == "" {
+				nodes[nodeID] = checksum
+			}
+		}
+		for _, child := range v {
+			collectNodes(child, nodes)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectNodes(child, nodes)
+		}
+	}
+}
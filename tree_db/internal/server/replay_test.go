@@ -0,0 +1,163 @@
+// Tests for trajectory replay
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nainya/treestore/pkg/version"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestReplayTrajectory(t *testing.T) {
+	s := newMCPTestServer(t)
+	ctx := context.Background()
+
+	tools := make(map[string]mcpTool)
+	for _, tool := range s.mcpTools() {
+		tools[tool.name] = tool
+	}
+	// step records a tool call as an agent would, with its result as the observation
+	step := func(n int32, name, args string) *pb.TrajectoryStep {
+		t.Helper()
+		result, err := tools[replayTools[name]].call(ctx, json.RawMessage(args))
+		if err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		data, _ := marshalResult(result)
+		return &pb.TrajectoryStep{StepNumber: n, ToolName: name, Action: args, Observation: string(data)}
+	}
+	_, err := s.StoreTrajectory(ctx, &pb.StoreTrajectoryRequest{Trajectory: &pb.Trajectory{
+		TrajectoryId: "traj-1",
+		CaseId:       "case-1",
+		StartedAt:    timestamppb.Now(),
+		CompletedAt:  timestamppb.Now(),
+		Steps: []*pb.TrajectoryStep{
+			step(1, "get_node", `{"policy_id": "POL-MCP", "node_id": "sec1"}`),
+			step(2, "GetChildren", `{"policy_id": "POL-MCP", "parent_id": "root"}`),
+			{StepNumber: 3, ToolName: "final_answer", Action: "Prior authorization is required"},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("StoreTrajectory failed: %v", err)
+	}
+
+	trajs, err := s.GetTrajectories(ctx, &pb.GetTrajectoriesRequest{CaseId: "case-1"})
+	if err != nil || len(trajs.Trajectories) != 1 || len(trajs.Trajectories[0].Steps) != 3 {
+		t.Fatalf("Expected the trajectory with its steps, got %v (%v)", trajs, err)
+	}
+
+	resp, err := s.ReplayTrajectory(ctx, &pb.ReplayTrajectoryRequest{TrajectoryId: "traj-1"})
+	if err != nil {
+		t.Fatalf("ReplayTrajectory failed: %v", err)
+	}
+	if resp.Matched != 2 || resp.Skipped != 1 || resp.Steps[2].Outcome != replaySkipped {
+		t.Fatalf("Expected two matched steps and one skipped, got %+v", resp.Steps)
+	}
+
+	// Keep the original text as the snapshot of v1 before changing the policy
+	now := timestamppb.Now()
+	_, err = s.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "POL-MCP@v1", RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "POL-MCP@v1", Title: "Coverage", ChildIds: []string{"sec1"}, SectionPath: "1", CreatedAt: now, UpdatedAt: now},
+			{NodeId: "sec1", PolicyId: "POL-MCP@v1", ParentId: "root", Title: "Imaging", Text: "Imaging requires prior authorization", SectionPath: "1.1", Depth: 1, CreatedAt: now, UpdatedAt: now},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	base := time.Now().Add(-3 * time.Hour)
+	for _, v := range []*version.Version{
+		{PolicyID: "POL-MCP", VersionID: "v0", CreatedAt: base},
+		{PolicyID: "POL-MCP", VersionID: "v1", DocumentID: "POL-MCP@v1", CreatedAt: base.Add(time.Hour)},
+		{PolicyID: "POL-MCP", VersionID: "v2", CreatedAt: base.Add(2 * time.Hour)},
+	} {
+		if err := s.verStore.CreateVersion(v); err != nil {
+			t.Fatalf("CreateVersion failed: %v", err)
+		}
+	}
+
+	_, err = s.UpdateNode(ctx, &pb.UpdateNodeRequest{Node: &pb.Node{
+		NodeId: "sec1", PolicyId: "POL-MCP", ParentId: "root", Title: "Imaging",
+		Text: "Imaging no longer requires prior authorization", SectionPath: "1.1", Depth: 1, Version: 1,
+	}})
+	if err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+
+	resp, err = s.ReplayTrajectory(ctx, &pb.ReplayTrajectoryRequest{TrajectoryId: "traj-1"})
+	if err != nil {
+		t.Fatalf("ReplayTrajectory failed: %v", err)
+	}
+	if resp.Diverged != 2 {
+		t.Fatalf("Expected both retrievals to diverge, got %+v", resp.Steps)
+	}
+	if got := resp.Steps[0].ChangedNodeIds; len(got) != 1 || got[0] != "sec1" {
+		t.Errorf("Expected sec1 changed, got %+v", resp.Steps[0])
+	}
+	if !strings.Contains(resp.Steps[0].Observation, "no longer requires") {
+		t.Errorf("Expected the replayed node in the observation, got %s", resp.Steps[0].Observation)
+	}
+
+	// The recorded version reads its snapshot and matches again
+	resp, err = s.ReplayTrajectory(ctx, &pb.ReplayTrajectoryRequest{TrajectoryId: "traj-1", PolicyId: "POL-MCP", VersionId: "v1"})
+	if err != nil {
+		t.Fatalf("ReplayTrajectory failed: %v", err)
+	}
+	if resp.Matched != 2 || resp.Documents["POL-MCP"] != "POL-MCP@v1" {
+		t.Errorf("Expected a match against the v1 snapshot, got %+v %v", resp.Steps, resp.Documents)
+	}
+	resp, err = s.ReplayTrajectory(ctx, &pb.ReplayTrajectoryRequest{
+		TrajectoryId: "traj-1", AsOfTime: timestamppb.New(base.Add(90 * time.Minute)),
+	})
+	if err != nil {
+		t.Fatalf("ReplayTrajectory failed: %v", err)
+	}
+	if resp.Matched != 2 {
+		t.Errorf("Expected v1 as of its effective time, got %+v", resp.Steps)
+	}
+
+	// A superseded version without a snapshot fails its steps, or the call
+	resp, err = s.ReplayTrajectory(ctx, &pb.ReplayTrajectoryRequest{
+		TrajectoryId: "traj-1", AsOfTime: timestamppb.New(base.Add(30 * time.Minute)),
+	})
+	if err != nil {
+		t.Fatalf("ReplayTrajectory failed: %v", err)
+	}
+	if resp.Failed != 2 || resp.Steps[0].Reason == "" {
+		t.Errorf("Expected the steps to fail against v0, got %+v", resp.Steps)
+	}
+	_, err = s.ReplayTrajectory(ctx, &pb.ReplayTrajectoryRequest{TrajectoryId: "traj-1", PolicyId: "POL-MCP", VersionId: "v0"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for v0, got %v", err)
+	}
+
+	for _, req := range []*pb.ReplayTrajectoryRequest{
+		{},
+		{TrajectoryId: "traj-1", PolicyId: "POL-MCP"},
+		{TrajectoryId: "traj-1", PolicyId: "POL-MCP", VersionId: "v1", AsOfTime: timestamppb.Now()},
+	} {
+		if _, err := s.ReplayTrajectory(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got %v", req, err)
+		}
+	}
+	if _, err := s.ReplayTrajectory(ctx, &pb.ReplayTrajectoryRequest{TrajectoryId: "unknown"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+
+	_, err = s.StoreTrajectory(ctx, &pb.StoreTrajectoryRequest{Trajectory: &pb.Trajectory{
+		TrajectoryId: "traj-2",
+		Steps:        []*pb.TrajectoryStep{{StepNumber: 1, Observation: strings.Repeat("x", 4096)}},
+	}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an oversized step, got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Follower-side WAL replication and read-only enforcement
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nainya/treestore/pkg/replication"
+	"github.com/nainya/treestore/pkg/wal"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// DefaultRetryInterval is the wait before reconnecting to a leader
+const DefaultRetryInterval = 2 * time.Second
+
+// FollowerConfig configures Server.Follow
+type FollowerConfig struct {
+	Leader        pb.TreeStoreServiceClient // Connection to the leader
+	RetryInterval time.Duration             // Wait before reconnecting after a stream error
+	OnError       func(error)               // Called for each stream error before retrying
+}
+
+// streamedReads are streaming RPCs that read the database while they run
+// WatchChanges and StreamWAL are absent: they never touch the tree and may run
+// indefinitely, which would stall replication.
+var streamedReads = map[string]bool{
+	"StreamQuery":     true,
+	"GetReadingOrder": true,
+}
+
+// mutatingMethods are rejected by ReadOnlyInterceptor and
+// ReadOnlyStreamInterceptor while following a leader
+var mutatingMethods = map[string]bool{
+	"StoreDocument":            true,
+	"UpdateNode":               true,
+	"DeleteDocument":           true,
+	"CloneDocument":            true,
+	"RecomputeSectionPaths":    true,
+	"RefreshSummaries":         true,
+	"DeleteVersion":            true,
+	"PruneVersions":            true,
+	"TagVersion":               true,
+	"UntagVersion":             true,
+	"StoreToolResult":          true,
+	"StoreTrajectory":          true,
+	"StoreCrossReference":      true,
+	"StoreContradiction":       true,
+	"BatchSetMetadata":         true,
+	"PutCollection":            true,
+	"UpdateCollectionMembers":  true,
+	"DeleteCollection":         true,
+	"StorePrompt":              true,
+	"RecordPromptUsage":        true,
+	"StorePromptExperiment":    true,
+	"LabelPromptUsage":         true,
+	"SetUserConversationQuota": true,
+	"SaveQuery":                true,
+	"RefreshSavedQuery":        true,
+	"DeleteSavedQuery":         true,
+	"TriggerJob":               true,
+}
+
+// Follow makes the server a read-only follower of a leader
+// It streams the leader's WAL from the last applied LSN and applies each
+// committed transaction, reconnecting after errors until ctx is cancelled.
+// Register ReadOnlyInterceptor and ReadOnlyStreamInterceptor so reads do not
+// run while a transaction is being applied.
+// It returns early with FailedPrecondition when the leader no longer has the
+// entries needed to resume; the follower must then be reseeded. Writes applied
+// this way do not publish change feed events on the follower, and since they
+// bypass the document store's cached node ID filters and ancestor paths, those
+// are turned off.
+func (s *Server) Follow(ctx context.Context, cfg FollowerConfig) error {
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = DefaultRetryInterval
+	}
+
+	applier, err := replication.NewApplier(s.kv, "leader")
+	if err != nil {
+		return err
+	}
+	s.readOnly.Store(true)
+	s.docStore.SetBloomFilters(false)
+	s.docStore.SetPathCache(0)
+
+	for {
+		err := s.followStream(ctx, cfg.Leader, applier)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if status.Code(err) == codes.FailedPrecondition {
+			return err
+		}
+		if cfg.OnError != nil {
+			cfg.OnError(err)
+		}
+
+		applier.Reset()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(cfg.RetryInterval):
+		}
+	}
+}
+
+// followStream applies one WAL stream until it breaks
+func (s *Server) followStream(ctx context.Context, leader pb.TreeStoreServiceClient, applier *replication.Applier) error {
+	stream, err := leader.StreamWAL(ctx, &pb.StreamWALRequest{AfterLsn: applier.AppliedLSN()})
+	if err != nil {
+		return err
+	}
+
+	for {
+		entry, err := stream.Recv()
+		if err == io.EOF {
+			return errors.New("leader closed the WAL stream")
+		}
+		if err != nil {
+			return err
+		}
+
+		s.applyMu.Lock()
+		err = applier.Apply(&wal.Entry{
+			LSN:       entry.Lsn,
+			TxnID:     entry.TxnId,
+			OpType:    wal.OpType(entry.Op),
+			Key:       entry.Key,
+			Value:     entry.Value,
+			Timestamp: entry.Timestamp.AsTime(),
+		})
+		s.applyMu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ReadOnly reports whether the server is following a leader
+func (s *Server) ReadOnly() bool {
+	return s.readOnly.Load()
+}
+
+// ReadOnlyInterceptor rejects mutating RPCs while the server follows a leader,
+// and keeps reads from overlapping with applied transactions and writes from
+// overlapping with maintenance run through the admin service
+func (s *Server) ReadOnlyInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		mutating := mutatingMethods[path.Base(info.FullMethod)]
+		if !s.readOnly.Load() {
+			if mutating {
+				s.maintMu.RLock()
+				defer s.maintMu.RUnlock()
+			}
+			return handler(ctx, req)
+		}
+		if mutating {
+			return nil, status.Error(codes.FailedPrecondition, "server is a read-only follower; send writes to the leader")
+		}
+
+		s.applyMu.RLock()
+		defer s.applyMu.RUnlock()
+		return handler(ctx, req)
+	}
+}
+
+// ReadOnlyStreamInterceptor rejects mutating streams while the server follows
+// a leader, and keeps streamed reads from overlapping with applied transactions
+func (s *Server) ReadOnlyStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		method := path.Base(info.FullMethod)
+		if s.readOnly.Load() && mutatingMethods[method] {
+			return status.Error(codes.FailedPrecondition, "server is a read-only follower; send writes to the leader")
+		}
+		if s.readOnly.Load() && streamedReads[method] {
+			s.applyMu.RLock()
+			defer s.applyMu.RUnlock()
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,97 @@
+// Integration tests for WAL replication between a leader and a follower
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestFollowerReplicatesLeader(t *testing.T) {
+	_, leader, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	follower, err := NewServer(filepath.Join(t.TempDir(), "follower.db"))
+	if err != nil {
+		t.Fatalf("Failed to create follower: %v", err)
+	}
+	defer follower.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- follower.Follow(ctx, FollowerConfig{Leader: leader, RetryInterval: 10 * time.Millisecond})
+	}()
+
+	if _, err := leader.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "POL-R"},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "POL-R", Title: "Replicated root"},
+			{NodeId: "child", PolicyId: "POL-R", ParentId: "root", Title: "Replicated child"},
+		},
+	}); err != nil {
+		t.Fatalf("StoreDocument on leader failed: %v", err)
+	}
+
+	// Reads go through the interceptor, as they would over gRPC
+	intercept := follower.ReadOnlyInterceptor()
+	getNode := &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/GetNode"}
+	read := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return follower.GetNode(ctx, req.(*pb.GetNodeRequest))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := intercept(ctx, &pb.GetNodeRequest{PolicyId: "POL-R", NodeId: "child"}, getNode, read)
+		if err == nil {
+			if node := resp.(*pb.GetNodeResponse).Node; node.Title != "Replicated child" || node.ParentId != "root" {
+				t.Errorf("Unexpected replicated node: %v", node)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Node was not replicated: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if !follower.ReadOnly() {
+		t.Error("Follower should be read-only")
+	}
+	write := &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/StoreDocument"}
+	if _, err := intercept(ctx, nil, write, read); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for a write on the follower, got %v", err)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Follow returned %v, want context.Canceled", err)
+	}
+}
+
+func TestStreamWALUnavailableLSN(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	stream, err := client.StreamWAL(ctx, &pb.StreamWALRequest{AfterLsn: 0})
+	if err != nil {
+		t.Fatalf("StreamWAL failed: %v", err)
+	}
+	server.StopWatches()
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable after StopWatches, got %v", err)
+	}
+}
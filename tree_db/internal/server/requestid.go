@@ -0,0 +1,89 @@
+// Request IDs that correlate a call's logs, audit record and response across systems
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/nainya/treestore/internal/logger"
+)
+
+// RequestIDHeader carries a call's request ID in request and response metadata
+const RequestIDHeader = "x-request-id"
+
+// maxRequestID bounds an incoming request ID; longer ones are replaced
+const maxRequestID = 128
+
+// requestIDKey is the context key under which a call carries its request ID
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the ID the request ID interceptor gave a call,
+// or "" outside one
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDInterceptor gives every call a request ID: the caller's
+// RequestIDHeader when it sends a usable one, otherwise a random one. The ID is
+// returned in the response header, added to the logger FromContext returns
+// and recorded in the audit log. Put it first in the chain so every later
+// interceptor sees it.
+func RequestIDInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, id := withRequestID(ctx, log)
+		grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+		return handler(ctx, req)
+	}
+}
+
+// RequestIDStreamInterceptor is RequestIDInterceptor for streaming RPCs
+func RequestIDStreamInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, id := withRequestID(ss.Context(), log)
+		ss.SetHeader(metadata.Pairs(RequestIDHeader, id))
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// withRequestID returns ctx carrying the call's request ID and a logger
+// that adds it to every event
+func withRequestID(ctx context.Context, log *logger.Logger) (context.Context, string) {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDHeader); len(ids) > 0 && validRequestID(ids[0]) {
+			id = ids[0]
+		}
+	}
+	if id == "" {
+		id = newRequestID()
+	}
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	ctx = logger.NewContext(ctx, log.WithFields(map[string]interface{}{"request_id": id}))
+	return ctx, id
+}
+
+// validRequestID accepts IDs of printable ASCII without spaces, so a caller
+// cannot forge log lines or bloat audit records through one
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 128-bit ID in hex
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
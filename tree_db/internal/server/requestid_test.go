@@ -0,0 +1,96 @@
+// Tests for request ID propagation
+package server
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/nainya/treestore/internal/logger"
+	"github.com/nainya/treestore/pkg/storage"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestRequestID(t *testing.T) {
+	server, err := NewServer(storage.MemoryPath)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+
+	var buf bytes.Buffer
+	log := logger.NewLogger(logger.Config{Output: &buf})
+	lis := bufconn.Listen(bufSize)
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(RequestIDInterceptor(log), SlowQueryInterceptor(log, time.Nanosecond),
+			server.AuditInterceptor(DefaultAPIKeyHeader, nil)),
+		grpc.ChainStreamInterceptor(RequestIDStreamInterceptor(log)),
+	)
+	pb.RegisterTreeStoreServiceServer(grpcServer, server)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial bufnet: %v", err)
+	}
+	defer conn.Close()
+	client := pb.NewTreeStoreServiceClient(conn)
+
+	// A caller's ID is kept, returned, logged and audited
+	ctx := metadata.AppendToOutgoingContext(context.Background(), RequestIDHeader, "ingest-42")
+	var header metadata.MD
+	_, err = client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "POL-R"},
+		Nodes:    []*pb.Node{{NodeId: "root", PolicyId: "POL-R", Title: "Root"}},
+	}, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	if got := header.Get(RequestIDHeader); len(got) != 1 || got[0] != "ingest-42" {
+		t.Errorf("Expected the caller's ID returned, got %v", got)
+	}
+	if !strings.Contains(buf.String(), `"request_id":"ingest-42"`) {
+		t.Errorf("Expected the ID logged, got %s", buf.String())
+	}
+	records, err := client.QueryAuditLog(context.Background(), &pb.QueryAuditLogRequest{})
+	if err != nil || len(records.Records) != 1 || records.Records[0].RequestId != "ingest-42" {
+		t.Errorf("Expected the ID audited, got %v (%v)", records, err)
+	}
+
+	// Calls without a usable ID get a new one, streams included
+	for _, sent := range []string{"", "has space", strings.Repeat("x", maxRequestID+1)} {
+		ctx := context.Background()
+		if sent != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, RequestIDHeader, sent)
+		}
+		var header metadata.MD
+		if _, err := client.Health(ctx, &pb.HealthRequest{}, grpc.Header(&header)); err != nil {
+			t.Fatalf("Health failed: %v", err)
+		}
+		if got := header.Get(RequestIDHeader); len(got) != 1 || len(got[0]) != 32 {
+			t.Errorf("Expected a generated ID for %q, got %v", sent, got)
+		}
+	}
+	stream, err := client.StreamQuery(context.Background(), &pb.StreamQueryRequest{Query: "FROM nodes WHERE policyID = 'POL-R'"})
+	if err != nil {
+		t.Fatalf("StreamQuery failed: %v", err)
+	}
+	header, err = stream.Header()
+	if got := header.Get(RequestIDHeader); err != nil || len(got) != 1 || len(got[0]) != 32 {
+		t.Errorf("Expected a generated ID for the stream, got %v (%v)", got, err)
+	}
+
+	if id := RequestIDFromContext(context.Background()); id != "" {
+		t.Errorf("Expected no ID outside a call, got %q", id)
+	}
+}
@@ -0,0 +1,267 @@
+// Retrieval in the request and response shapes LLM framework retrievers use, over gRPC and HTTP
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/nainya/treestore/pkg/document"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// RetrieverPath is where RetrieverHTTP is mounted
+const RetrieverPath = "/v1/retrieve"
+
+// defaultTopK is how many documents Retrieve returns when top_k is unset,
+// as LangChain retrievers default to
+const defaultTopK = 4
+
+// maxRetrieveRequest bounds the JSON body RetrieverHTTP reads
+const maxRetrieveRequest = 1 << 20
+
+func (s *Server) Retrieve(ctx context.Context, req *pb.RetrieveRequest) (*pb.RetrieveResponse, error) {
+	s.countOp("Retrieve")
+
+	if req.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	if req.TopK < 0 {
+		return nil, status.Error(codes.InvalidArgument, "top_k must not be negative")
+	}
+	topK := int(req.TopK)
+	if topK == 0 {
+		topK = defaultTopK
+	}
+
+	scope, filter, err := retrieveFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	store := s.docStore.WithContext(ctx)
+	var results []*document.SearchResult
+	if scope == (retrieveScope{}) && filter == nil {
+		// Nothing to filter on, so the term index answers for every policy at once
+		groups, err := store.SearchAll(req.Query, topK, 0)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "search failed: %v", err)
+		}
+		for _, group := range groups {
+			results = append(results, group.Results...)
+		}
+	} else {
+		var policyIDs []string
+		switch {
+		case scope.policyID != "":
+			policyIDs = []string{scope.policyID}
+		case scope.collection != "":
+			if policyIDs, err = s.collectionPolicies(scope.collection); err != nil {
+				return nil, err
+			}
+		default:
+			if policyIDs, err = store.ListPolicies("", 0); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to list policies: %v", err)
+			}
+		}
+		for _, policyID := range policyIDs {
+			hits, err := store.SearchFiltered(policyID, req.Query, topK, s.searchFilter(filter))
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "search failed: %v", err)
+			}
+			results = append(results, hits...)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > topK {
+		results = results[:topK]
+	}
+
+	nodes, err := s.resultNodes(results)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.RetrieveResponse{Documents: make([]*pb.RetrievedDocument, 0, len(results))}
+	for i, node := range nodes {
+		if node != nil {
+			resp.Documents = append(resp.Documents, retrievedDocument(node, results[i].Score))
+		}
+	}
+	return resp, nil
+}
+
+// retrieveScope is where a retrieval searches
+type retrieveScope struct {
+	policyID   string
+	collection string
+}
+
+// retrieveFilter splits a retriever filter into its scope and the search
+// filter of the remaining keys, nil when there are none
+func retrieveFilter(f map[string]string) (retrieveScope, *pb.SearchFilter, error) {
+	var scope retrieveScope
+	var filter pb.SearchFilter
+	filtered := false
+	number := func(key, value string) (int32, error) {
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || n < 0 {
+			return 0, status.Errorf(codes.InvalidArgument, "filter %s must be a non-negative integer, not %q", key, value)
+		}
+		return int32(n), nil
+	}
+
+	for key, value := range f {
+		var err error
+		switch key {
+		case "policy_id":
+			scope.policyID = value
+		case "collection":
+			scope.collection = value
+		case "section_path_prefix":
+			filter.SectionPathPrefix = value
+		case "page_from":
+			filter.PageFrom, err = number(key, value)
+		case "page_to":
+			filter.PageTo, err = number(key, value)
+		case "max_depth":
+			var depth int32
+			depth, err = number(key, value)
+			filter.MaxDepth = &depth
+		default:
+			if filter.Metadata == nil {
+				filter.Metadata = make(map[string]string)
+			}
+			filter.Metadata[key] = value
+		}
+		if err != nil {
+			return scope, nil, err
+		}
+		if key != "policy_id" && key != "collection" {
+			filtered = true
+		}
+	}
+	if scope.policyID != "" && scope.collection != "" {
+		return scope, nil, status.Error(codes.InvalidArgument, "filter takes policy_id or collection, not both")
+	}
+	if !filtered {
+		return scope, nil, nil
+	}
+	return scope, &filter, nil
+}
+
+// retrievedDocument converts a search hit to a retriever's document
+func retrievedDocument(node *document.Node, score float64) *pb.RetrievedDocument {
+	content := node.Text
+	if strings.TrimSpace(content) == "" {
+		content = node.Summary
+	}
+	if strings.TrimSpace(content) == "" {
+		content = node.Title
+	}
+	return &pb.RetrievedDocument{
+		Id:          node.PolicyID + "/" + node.NodeID,
+		PageContent: content,
+		Metadata: map[string]string{
+			"policy_id":    node.PolicyID,
+			"node_id":      node.NodeID,
+			"title":        node.Title,
+			"section_path": node.SectionPath,
+			"page_start":   strconv.Itoa(node.PageStart),
+			"page_end":     strconv.Itoa(node.PageEnd),
+			"source":       node.PolicyID,
+		},
+		Score: float32(score),
+	}
+}
+
+// retrieverRequest is the JSON body RetrieverHTTP reads. Keys it doesn't
+// know are ignored, as frameworks add their own; k is taken for top_k as
+// LangChain's search_kwargs name it, and filter values may be numbers or
+// booleans as well as strings.
+type retrieverRequest struct {
+	Query  string         `json:"query"`
+	TopK   int32          `json:"top_k"`
+	K      int32          `json:"k"`
+	Filter map[string]any `json:"filter"`
+}
+
+// toPb converts the request, rejecting filter values that are lists or objects
+func (r *retrieverRequest) toPb() (*pb.RetrieveRequest, error) {
+	req := &pb.RetrieveRequest{Query: r.Query, TopK: r.TopK}
+	if req.TopK == 0 {
+		req.TopK = r.K
+	}
+	if len(r.Filter) > 0 {
+		req.Filter = make(map[string]string, len(r.Filter))
+	}
+	for key, value := range r.Filter {
+		switch v := value.(type) {
+		case string:
+			req.Filter[key] = v
+		case float64, bool:
+			req.Filter[key] = fmt.Sprint(v)
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "filter %s must be a string, number or boolean", key)
+		}
+	}
+	return req, nil
+}
+
+// RetrieverHTTP returns a handler taking POSTs of a RetrieveRequest as JSON,
+// such as {"query": "...", "top_k": 4, "filter": {"policy_id": "LCD-1"}}, and
+// answering with the RetrieveResponse as JSON. Calls go through client, a
+// connection to the server's own gRPC port, so access control, redaction and
+// rate limits apply as to any other call. The caller's API key is forwarded
+// from the keyHeader HTTP header, or from an "Authorization: Bearer" header.
+func RetrieverHTTP(client pb.TreeStoreServiceClient, keyHeader string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRetrieveRequest))
+		var req *pb.RetrieveRequest
+		if err == nil {
+			var in retrieverRequest
+			if err = json.Unmarshal(body, &in); err != nil {
+				err = status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+			} else {
+				req, err = in.toPb()
+			}
+		}
+
+		var data []byte
+		if err == nil {
+			ctx := r.Context()
+			key := r.Header.Get(keyHeader)
+			if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); key == "" && ok {
+				key = bearer
+			}
+			if key != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, keyHeader, key)
+			}
+			var resp *pb.RetrieveResponse
+			if resp, err = client.Retrieve(ctx, req); err == nil {
+				data, err = uiJSON.Marshal(resp)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			writeJSONError(w, err)
+			return
+		}
+		w.Write(data)
+	})
+}
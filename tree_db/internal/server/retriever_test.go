@@ -0,0 +1,208 @@
+// Tests for the retriever RPC and its HTTP route
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pkgmetadata "github.com/nainya/treestore/pkg/metadata"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// storeRetrieverPolicies stores two policies mentioning imaging, one with a
+// summary-only section
+func storeRetrieverPolicies(t *testing.T, s *Server) {
+	t.Helper()
+	now := timestamppb.Now()
+	for _, policyID := range []string{"RET-1", "RET-2"} {
+		_, err := s.StoreDocument(context.Background(), &pb.StoreDocumentRequest{
+			Document: &pb.Document{PolicyId: policyID, RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+			Nodes: []*pb.Node{
+				{NodeId: "root", PolicyId: policyID, Title: "Coverage", ChildIds: []string{"imaging", "notes"}, SectionPath: "1", CreatedAt: now, UpdatedAt: now},
+				{NodeId: "imaging", PolicyId: policyID, ParentId: "root", Title: "Imaging", Text: "Imaging imaging requires prior authorization", SectionPath: "1.1", PageStart: 2, PageEnd: 3, Depth: 1, CreatedAt: now, UpdatedAt: now},
+				{NodeId: "notes", PolicyId: policyID, ParentId: "root", Title: "Notes", Summary: "Imaging notes", SectionPath: "1.2", PageStart: 4, PageEnd: 4, Depth: 1, CreatedAt: now, UpdatedAt: now},
+			},
+		})
+		if err != nil {
+			t.Fatalf("StoreDocument %s failed: %v", policyID, err)
+		}
+	}
+}
+
+func TestRetrieve(t *testing.T) {
+	s, client, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+	storeRetrieverPolicies(t, s)
+
+	resp, err := client.Retrieve(ctx, &pb.RetrieveRequest{Query: "imaging"})
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if len(resp.Documents) != defaultTopK {
+		t.Fatalf("Expected %d documents by default, got %d", defaultTopK, len(resp.Documents))
+	}
+	for i := 1; i < len(resp.Documents); i++ {
+		if resp.Documents[i].Score > resp.Documents[i-1].Score {
+			t.Errorf("Documents not ordered by score: %v", resp.Documents)
+		}
+	}
+
+	resp, err = client.Retrieve(ctx, &pb.RetrieveRequest{Query: "imaging", TopK: 10, Filter: map[string]string{"policy_id": "RET-2"}})
+	if err != nil {
+		t.Fatalf("Retrieve by policy failed: %v", err)
+	}
+	docs := map[string]*pb.RetrievedDocument{}
+	for _, doc := range resp.Documents {
+		docs[doc.Id] = doc
+	}
+	if len(docs) != 2 || docs["RET-2/imaging"] == nil || docs["RET-2/notes"] == nil {
+		t.Fatalf("Expected RET-2's two sections, got %v", resp.Documents)
+	}
+	imaging := docs["RET-2/imaging"]
+	if imaging.PageContent != "Imaging imaging requires prior authorization" || imaging.Metadata["section_path"] != "1.1" ||
+		imaging.Metadata["page_start"] != "2" || imaging.Metadata["source"] != "RET-2" {
+		t.Errorf("Unexpected document %v", imaging)
+	}
+	if docs["RET-2/notes"].PageContent != "Imaging notes" {
+		t.Errorf("Expected the summary for a section without text, got %q", docs["RET-2/notes"].PageContent)
+	}
+
+	if err := s.metaStore.SetMetadata(&pkgmetadata.MetadataEntry{EntityType: "node", EntityID: "notes", Key: "reviewer", Value: "clinical", ValueType: "string"}); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	resp, err = client.Retrieve(ctx, &pb.RetrieveRequest{Query: "imaging", TopK: 10, Filter: map[string]string{"reviewer": "clinical", "page_from": "4"}})
+	if err != nil {
+		t.Fatalf("Retrieve by metadata failed: %v", err)
+	}
+	for _, doc := range resp.Documents {
+		if doc.Metadata["node_id"] != "notes" {
+			t.Errorf("Expected only notes sections, got %s", doc.Id)
+		}
+	}
+	if len(resp.Documents) == 0 {
+		t.Error("Expected notes sections to match the metadata filter")
+	}
+
+	if _, err := client.PutCollection(ctx, &pb.PutCollectionRequest{Collection: &pb.Collection{Name: "ret", PolicyIds: []string{"RET-1"}}}); err != nil {
+		t.Fatalf("PutCollection failed: %v", err)
+	}
+	resp, err = client.Retrieve(ctx, &pb.RetrieveRequest{Query: "imaging", TopK: 10, Filter: map[string]string{"collection": "ret"}})
+	if err != nil {
+		t.Fatalf("Retrieve by collection failed: %v", err)
+	}
+	if len(resp.Documents) != 2 || resp.Documents[0].Metadata["policy_id"] != "RET-1" || resp.Documents[1].Metadata["policy_id"] != "RET-1" {
+		t.Errorf("Expected RET-1's sections, got %v", resp.Documents)
+	}
+
+	for name, req := range map[string]*pb.RetrieveRequest{
+		"no query":              {},
+		"negative top_k":        {Query: "imaging", TopK: -1},
+		"bad page":              {Query: "imaging", Filter: map[string]string{"page_from": "two"}},
+		"policy and collection": {Query: "imaging", Filter: map[string]string{"policy_id": "RET-1", "collection": "ret"}},
+	} {
+		if _, err := client.Retrieve(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, got %v", name, err)
+		}
+	}
+}
+
+func TestRetrieverHTTP(t *testing.T) {
+	s, client, cleanup := setupTestServer(t)
+	defer cleanup()
+	storeRetrieverPolicies(t, s)
+
+	srv := httptest.NewServer(RetrieverHTTP(client, DefaultAPIKeyHeader))
+	defer srv.Close()
+
+	post := func(body string, wantStatus int, out interface{}) {
+		t.Helper()
+		resp, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", body, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("POST %s: expected status %d, got %d", body, wantStatus, resp.StatusCode)
+		}
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				t.Fatalf("POST %s: bad JSON: %v", body, err)
+			}
+		}
+	}
+
+	var out struct {
+		Documents []struct {
+			PageContent string            `json:"page_content"`
+			Metadata    map[string]string `json:"metadata"`
+			Score       float64           `json:"score"`
+		} `json:"documents"`
+	}
+	post(`{"query": "imaging", "k": 1, "filter": {"policy_id": "RET-1", "max_depth": 1}, "run_name": "chain"}`, http.StatusOK, &out)
+	if len(out.Documents) != 1 || out.Documents[0].Metadata["policy_id"] != "RET-1" || out.Documents[0].Score <= 0 {
+		t.Errorf("Unexpected documents %+v", out.Documents)
+	}
+
+	var failure struct {
+		Error string `json:"error"`
+	}
+	post(`{"query": ""}`, http.StatusBadRequest, &failure)
+	if failure.Error == "" {
+		t.Error("Expected an error message for a missing query")
+	}
+	post(`{"query": "imaging", "filter": {"policy_id": ["RET-1"]}}`, http.StatusBadRequest, nil)
+	post(`not json`, http.StatusBadRequest, nil)
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", resp.StatusCode)
+	}
+}
+
+// keyRecorder records the API key sent with a Retrieve call
+type keyRecorder struct {
+	pb.TreeStoreServiceClient
+	key string
+}
+
+func (k *keyRecorder) Retrieve(ctx context.Context, req *pb.RetrieveRequest, _ ...grpc.CallOption) (*pb.RetrieveResponse, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if keys := md.Get(DefaultAPIKeyHeader); len(keys) > 0 {
+		k.key = keys[0]
+	}
+	return &pb.RetrieveResponse{}, nil
+}
+
+func TestRetrieverHTTPForwardsKey(t *testing.T) {
+	rec := &keyRecorder{}
+	srv := httptest.NewServer(RetrieverHTTP(rec, DefaultAPIKeyHeader))
+	defer srv.Close()
+
+	for header, value := range map[string]string{DefaultAPIKeyHeader: "key-1", "Authorization": "Bearer key-2"} {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"query": "imaging"}`))
+		req.Header.Set(header, value)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		resp.Body.Close()
+		if want := strings.TrimPrefix(value, "Bearer "); rec.key != want {
+			t.Errorf("%s: expected key %q forwarded, got %q", header, want, rec.key)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Saved queries whose materialized results dashboards can re-read cheaply
+package server
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nainya/treestore/pkg/query"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func (s *Server) SaveQuery(ctx context.Context, req *pb.SaveQueryRequest) (*pb.SaveQueryResponse, error) {
+	s.countOp("SaveQuery")
+
+	if req.Name == "" || req.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "name and query are required")
+	}
+
+	refresh := query.RefreshOnDemand
+	if req.RefreshOnChange {
+		refresh = query.RefreshOnChange
+	}
+	if _, err := query.ParseQuery(req.Query); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid query: %v", err)
+	}
+	sq, err := s.engine.WithContext(ctx).SaveQuery(req.Name, req.Query, refresh)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "query failed: %v", err)
+	}
+	if req.RefreshOnChange {
+		s.watchSavedQueries()
+	}
+
+	return &pb.SaveQueryResponse{Saved: savedQueryToPb(sq)}, nil
+}
+
+func (s *Server) ExecuteSavedQuery(ctx context.Context, req *pb.ExecuteSavedQueryRequest) (*pb.ExecuteSavedQueryResponse, error) {
+	s.countOp("ExecuteSavedQuery")
+
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	// A follower serves what the leader materialized rather than writing
+	engine := s.engine.WithContext(ctx)
+	execute := engine.ExecuteSaved
+	if s.readOnly.Load() {
+		execute = engine.ReadSaved
+	}
+	res, err := execute(req.Name)
+	if err != nil {
+		return nil, savedQueryError(err)
+	}
+
+	rows := make([]*pb.QueryRow, len(res.Rows))
+	for i, row := range res.Rows {
+		rows[i] = queryRowToPb(row)
+	}
+	return &pb.ExecuteSavedQueryResponse{
+		Saved:     savedQueryToPb(res.Saved),
+		Rows:      rows,
+		Refreshed: res.Refreshed,
+	}, nil
+}
+
+func (s *Server) RefreshSavedQuery(ctx context.Context, req *pb.RefreshSavedQueryRequest) (*pb.RefreshSavedQueryResponse, error) {
+	s.countOp("RefreshSavedQuery")
+
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	sq, err := s.engine.WithContext(ctx).RefreshSaved(req.Name)
+	if err != nil {
+		return nil, savedQueryError(err)
+	}
+
+	return &pb.RefreshSavedQueryResponse{Saved: savedQueryToPb(sq)}, nil
+}
+
+func (s *Server) ListSavedQueries(ctx context.Context, req *pb.ListSavedQueriesRequest) (*pb.ListSavedQueriesResponse, error) {
+	s.countOp("ListSavedQueries")
+
+	saved, err := s.engine.ListSaved()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list saved queries: %v", err)
+	}
+
+	pbSaved := make([]*pb.SavedQuery, len(saved))
+	for i, sq := range saved {
+		pbSaved[i] = savedQueryToPb(sq)
+	}
+	return &pb.ListSavedQueriesResponse{Saved: pbSaved}, nil
+}
+
+func (s *Server) DeleteSavedQuery(ctx context.Context, req *pb.DeleteSavedQueryRequest) (*pb.DeleteSavedQueryResponse, error) {
+	s.countOp("DeleteSavedQuery")
+
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	if err := s.engine.DeleteSaved(req.Name); err != nil {
+		return nil, savedQueryError(err)
+	}
+
+	return &pb.DeleteSavedQueryResponse{Success: true}, nil
+}
+
+// watchSavedQueries starts marking refresh-on-change queries stale from the
+// change feed. It runs at most once, from the first such query on, and ends
+// when the feed closes.
+func (s *Server) watchSavedQueries() {
+	s.savedWatch.Do(func() { s.engine.WatchSaved(s.feed) })
+}
+
+// savedQueryError maps a saved query error to a gRPC status
+func savedQueryError(err error) error {
+	if errors.Is(err, query.ErrSavedQueryNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Errorf(codes.Internal, "saved query failed: %v", err)
+}
+
+// savedQueryToPb converts a saved query to its protobuf form
+func savedQueryToPb(sq *query.SavedQuery) *pb.SavedQuery {
+	return &pb.SavedQuery{
+		Name:            sq.Name,
+		Query:           sq.Query,
+		RefreshOnChange: sq.Refresh == query.RefreshOnChange,
+		RefreshedAt:     timestamppb.New(sq.RefreshedAt),
+		Rows:            int64(sq.Rows),
+		Stale:           sq.Stale,
+	}
+}
@@ -2,20 +2,38 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/nainya/treestore/internal/logger"
+	"github.com/nainya/treestore/pkg/audit"
+	"github.com/nainya/treestore/pkg/canary"
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/convert"
+	"github.com/nainya/treestore/pkg/compaction"
 	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/jobs"
 	"github.com/nainya/treestore/pkg/metadata"
 	"github.com/nainya/treestore/pkg/prompt"
+	"github.com/nainya/treestore/pkg/query"
+	"github.com/nainya/treestore/pkg/retention"
 	"github.com/nainya/treestore/pkg/storage"
 	"github.com/nainya/treestore/pkg/version"
+	"github.com/nainya/treestore/pkg/wal"
+	"github.com/nainya/treestore/pkg/xref"
 	pb "github.com/nainya/treestore/proto"
 )
 
@@ -28,110 +46,268 @@ type Server struct {
 	verStore    *version.VersionStore
 	metaStore   *metadata.MetadataStore
 	promptStore *prompt.PromptStore
+	engine      *query.Engine
+	sweeper     *retention.Sweeper
+	compactor   *compaction.Compactor
+	canary      *canary.Canary
+	jobRunner   *jobs.Runner
+	feed        *changefeed.Feed
+	auditLog    *audit.Log
+	readOnly    atomic.Bool   // Set while following a leader
+	applyMu     sync.RWMutex  // Held by the follower while applying; reads hold it shared
+	maintMu     sync.RWMutex  // Held by admin maintenance; writes and sweeps hold it shared
+	stopOnce    sync.Once
+	stopped     chan struct{} // Closed by StopWatches to end StreamWAL
+	savedWatch  sync.Once     // Starts the saved query watcher; see watchSavedQueries
+	subtreeWorkers int        // Parents whose children subtree reads fetch at once
+	logRing     *logger.Ring  // Recent log events for TailLogs; nil disables it
+	references  *xref.Extractor // Finds cross-references in stored node text; nil finds none
+	summarizer  document.Summarizer // Writes summaries for RefreshSummaries; nil disables it
 
 	startTime   time.Time
+	opMu        sync.Mutex
 	opCounts    map[string]int64
 }
 
+// Options configures how NewServerWithOptions opens the database
+type Options struct {
+	SyncPolicy   storage.SyncPolicy // When commits are fsynced; see storage.SyncPolicy
+	SyncInterval time.Duration      // Flush period of storage.SyncInterval
+	NoMmap       bool               // Read pages from a copy of the file in memory; see storage.KV
+	NoMigrate    bool               // Refuse to open an older format instead of migrating it; see storage.KV
+	WALArchive   *wal.Archive       // Keep retired WAL files instead of deleting them
+	BloomFilters bool               // Answer lookups of missing nodes from per-policy filters; see document.SimpleStore.SetBloomFilters
+	PathCacheEntries int            // Cache the ancestor IDs of this many nodes for ancestor path reads; 0 disables the cache
+	SubtreeWorkers int              // Fetch the children of this many parents at once in subtree reads; 0 or 1 is sequential
+	QueryCacheEntries int           // Cache this many query results until a write invalidates them; 0 disables the cache
+	QueryCacheTTL  time.Duration    // Age at which a cached result is recomputed anyway (default query.DefaultCacheTTL)
+	LogRing        *logger.Ring     // Log events the admin service's TailLogs serves; nil disables it
+	References     *xref.Extractor  // Stores the cross-references found in node text on StoreDocument; nil finds none
+	Summarizer     document.Summarizer // Writes the summaries RefreshSummaries recomputes; nil disables it
+	TokenEstimator document.TokenEstimator // Counts the tokens of node text on write; nil uses document.EstimateTokens
+	ConversationQuota prompt.Quota         // Caps the conversations and messages of users without a quota of their own; zero is unlimited
+}
+
 // NewServer creates a new gRPC server instance
+// Values are encrypted when storage.EncryptionKeysEnv is set.
 func NewServer(dbPath string) (*Server, error) {
-	kv := &storage.KV{Path: dbPath}
+	return NewServerWithOptions(dbPath, Options{})
+}
+
+// NewServerWithOptions creates a server whose database is opened with opts
+func NewServerWithOptions(dbPath string, opts Options) (*Server, error) {
+	enc, err := storage.EncryptionFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	kv := &storage.KV{
+		Path:         dbPath,
+		Encryption:   enc,
+		SyncPolicy:   opts.SyncPolicy,
+		SyncInterval: opts.SyncInterval,
+		NoMmap:       opts.NoMmap,
+		NoMigrate:    opts.NoMigrate,
+		WALArchive:   opts.WALArchive,
+	}
 	if err := kv.Open(); err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	return &Server{
+	s := &Server{
 		kv:          kv,
 		docStore:    document.NewSimpleStore(kv),
 		verStore:    version.NewVersionStore(kv),
 		metaStore:   metadata.NewMetadataStore(kv),
 		promptStore: prompt.NewPromptStore(kv),
+		feed:        changefeed.NewFeed(changefeed.DefaultBufferSize),
+		auditLog:    audit.NewLog(kv),
+		stopped:     make(chan struct{}),
+		subtreeWorkers: opts.SubtreeWorkers,
+		logRing:     opts.LogRing,
+		references:  opts.References,
+		summarizer:  opts.Summarizer,
 		startTime:   time.Now(),
 		opCounts:    make(map[string]int64),
-	}, nil
+	}
+	s.docStore.SetChangeFeed(s.feed)
+	s.docStore.SetBloomFilters(opts.BloomFilters)
+	s.docStore.SetPathCache(opts.PathCacheEntries)
+	s.docStore.SetTokenEstimator(opts.TokenEstimator)
+	s.promptStore.SetDefaultQuota(opts.ConversationQuota)
+	s.verStore.SetChangeFeed(s.feed)
+	s.metaStore.SetChangeFeed(s.feed)
+	s.engine = query.NewEngineWithStores(kv, s.docStore, s.verStore, s.metaStore, s.promptStore)
+	if opts.QueryCacheEntries > 0 {
+		// Invalidation ends when Close closes the feed
+		s.engine.EnableCache(query.CacheConfig{MaxEntries: opts.QueryCacheEntries, TTL: opts.QueryCacheTTL}, s.feed)
+	}
+
+	saved, err := s.engine.ListSaved()
+	if err != nil {
+		kv.Close()
+		return nil, fmt.Errorf("failed to load saved queries: %w", err)
+	}
+	for _, sq := range saved {
+		if sq.Refresh == query.RefreshOnChange {
+			s.watchSavedQueries()
+			break
+		}
+	}
+
+	return s, nil
+}
+
+// Migration reports the format migration run when the database was opened
+func (s *Server) Migration() storage.MigrationReport {
+	return s.kv.State().Migration
+}
+
+// Recovery reports the WAL recovery run when the database was opened
+func (s *Server) Recovery() wal.RecoveryStats {
+	return s.kv.State().Recovery
+}
+
+// QueryCacheStats reports the query result cache counters, all zero when it is disabled
+func (s *Server) QueryCacheStats() query.CacheStats {
+	return s.engine.CacheStats()
+}
+
+// CheckpointStats reports the checkpoints taken and how far the WAL runs
+// ahead of the database file
+func (s *Server) CheckpointStats() wal.CheckpointStats {
+	return s.kv.State().Checkpoint
+}
+
+// StartRetention starts a background sweeper that deletes expired conversations,
+// tool results and trajectories according to cfg
+func (s *Server) StartRetention(cfg retention.Config) *retention.Sweeper {
+	if s.sweeper != nil {
+		s.sweeper.Stop()
+	}
+
+	cfg.Lock = s.maintMu.RLocker()
+	s.sweeper = retention.NewSweeper(s.promptStore, s.metaStore, cfg)
+	s.sweeper.Start()
+	return s.sweeper
+}
+
+// StartCompaction starts a background compactor that rewrites fragmented runs
+// of leaves according to cfg; each pass runs as maintenance
+func (s *Server) StartCompaction(cfg compaction.Config) *compaction.Compactor {
+	if s.compactor != nil {
+		s.compactor.Stop()
+	}
+
+	cfg.Lock = maintenanceLocker{s}
+	s.compactor = compaction.NewCompactor(s.kv, cfg)
+	s.compactor.Start()
+	return s.compactor
 }
 
-// Close closes the database connection
+// StartCanary starts a background canary that re-reads a sample of recent
+// writes according to cfg, checking the records of every store
+func (s *Server) StartCanary(cfg canary.Config) *canary.Canary {
+	if s.canary != nil {
+		s.canary.Stop()
+	}
+
+	cfg.Checks = map[uint32]canary.Check{
+		document.PREFIX_NODE:       {Name: "node", Validate: document.CheckNodeRecord},
+		version.PREFIX_VERSION:     {Name: "version", Validate: version.CheckRecord},
+		metadata.PREFIX_METADATA:   {Name: "metadata", Validate: metadata.CheckEntryRecord},
+		prompt.PREFIX_CONVERSATION: {Name: "conversation", Validate: prompt.CheckConversationRecord},
+		prompt.PREFIX_MESSAGE:      {Name: "message", Validate: prompt.CheckMessageRecord},
+	}
+	cfg.Lock = s.applyMu.RLocker()
+	s.canary = canary.NewCanary(s.kv, cfg)
+	s.canary.Start()
+	return s.canary
+}
+
+// StopWatches ends every WatchChanges, StreamWAL and TailLogs stream so a
+// graceful stop does not wait on them
+func (s *Server) StopWatches() {
+	s.feed.Close()
+	s.stopOnce.Do(func() { close(s.stopped) })
+}
+
+// Close stops background work and closes the database connection
 func (s *Server) Close() error {
+	if s.sweeper != nil {
+		s.sweeper.Stop()
+	}
+	if s.compactor != nil {
+		s.compactor.Stop()
+	}
+	if s.canary != nil {
+		s.canary.Stop()
+	}
+	if s.jobRunner != nil {
+		s.jobRunner.Stop()
+	}
+	s.StopWatches()
 	return s.kv.Close()
 }
 
 // ========== Document Operations ==========
 
 func (s *Server) StoreDocument(ctx context.Context, req *pb.StoreDocumentRequest) (*pb.StoreDocumentResponse, error) {
-	s.opCounts["StoreDocument"]++
+	s.countOp("StoreDocument")
 
 	if req.Document == nil {
 		return nil, status.Error(codes.InvalidArgument, "document is required")
 	}
 
-	// Convert protobuf Document to internal type
-	doc := &document.Document{
-		PolicyID:       req.Document.PolicyId,
-		VersionID:      req.Document.VersionId,
-		PageIndexDocID: req.Document.PageindexDocId,
-		RootNodeID:     req.Document.RootNodeId,
-		Metadata:       req.Document.Metadata,
-		CreatedAt:      req.Document.CreatedAt.AsTime(),
-		UpdatedAt:      req.Document.UpdatedAt.AsTime(),
-	}
-
-	// Convert protobuf Nodes to internal type
-	nodes := make([]*document.Node, len(req.Nodes))
-	for i, pbNode := range req.Nodes {
-		var parentID *string
-		if pbNode.ParentId != "" {
-			parentID = &pbNode.ParentId
-		}
-
-		nodes[i] = &document.Node{
-			NodeID:      pbNode.NodeId,
-			PolicyID:    pbNode.PolicyId,
-			ParentID:    parentID,
-			Title:       pbNode.Title,
-			PageStart:   int(pbNode.PageStart),
-			PageEnd:     int(pbNode.PageEnd),
-			Summary:     pbNode.Summary,
-			Text:        pbNode.Text,
-			SectionPath: pbNode.SectionPath,
-			ChildIDs:    pbNode.ChildIds,
-			Depth:       int(pbNode.Depth),
-			CreatedAt:   pbNode.CreatedAt.AsTime(),
-			UpdatedAt:   pbNode.UpdatedAt.AsTime(),
-		}
-	}
+	doc := convert.DocumentFromPb(req.Document)
+	nodes := convert.NodesFromPb(req.Nodes)
 
 	if err := s.docStore.StoreDocument(doc, nodes); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to store document: %v", err)
 	}
 
+	var extracted int
+	if s.references != nil {
+		var err error
+		if extracted, err = s.extractReferences(nodes); err != nil {
+			return nil, status.Errorf(codes.Internal, "stored document %s, but failed to store its references: %v", doc.PolicyID, err)
+		}
+	}
+
 	return &pb.StoreDocumentResponse{
-		Success: true,
-		Message: fmt.Sprintf("Stored document %s with %d nodes", doc.PolicyID, len(nodes)),
+		Success:             true,
+		Message:             fmt.Sprintf("Stored document %s with %d nodes", doc.PolicyID, len(nodes)),
+		ReferencesExtracted: int32(extracted),
 	}, nil
 }
 
 func (s *Server) GetDocument(ctx context.Context, req *pb.GetDocumentRequest) (*pb.GetDocumentResponse, error) {
-	s.opCounts["GetDocument"]++
+	s.countOp("GetDocument")
 
 	if req.PolicyId == "" {
 		return nil, status.Error(codes.InvalidArgument, "policy_id is required")
 	}
 
-	// Get root node first to find document structure
-	// Note: SimpleStore doesn't have GetDocument, so we need to scan for the root
-	var rootNode *document.Node
-	var err error
+	mask, err := parseNodeMask(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+	store := s.docStore.WithContext(ctx).WithoutFields(mask.omitted())
 
-	// Try to find root by getting children with nil parent
-	children, err := s.docStore.GetChildren(req.PolicyId, nil)
-	if err != nil || len(children) == 0 {
-		return nil, status.Errorf(codes.NotFound, "document not found: %v", err)
+	// Get root node first to find document structure
+	rootNode, err := store.GetRoot(req.PolicyId)
+	if errors.Is(err, document.ErrDocumentNotFound) {
+		return nil, status.Errorf(codes.NotFound, "document %s not found", req.PolicyId)
+	}
+	if errors.Is(err, document.ErrMultipleRoots) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find document root: %v", err)
 	}
-	rootNode = children[0]
 
 	// Get all nodes for this document
-	nodes, err := s.docStore.GetSubtree(req.PolicyId, rootNode.NodeID, document.QueryOptions{})
+	nodes, err := store.GetSubtree(req.PolicyId, rootNode.NodeID, document.QueryOptions{Workers: s.subtreeWorkers})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get nodes: %v", err)
 	}
@@ -147,29 +323,8 @@ func (s *Server) GetDocument(ctx context.Context, req *pb.GetDocumentRequest) (*
 		UpdatedAt:       timestamppb.New(rootNode.UpdatedAt),
 	}
 
-	pbNodes := make([]*pb.Node, len(nodes))
-	for i, node := range nodes {
-		parentID := ""
-		if node.ParentID != nil {
-			parentID = *node.ParentID
-		}
-
-		pbNodes[i] = &pb.Node{
-			NodeId:      node.NodeID,
-			PolicyId:    node.PolicyID,
-			ParentId:    parentID,
-			Title:       node.Title,
-			PageStart:   int32(node.PageStart),
-			PageEnd:     int32(node.PageEnd),
-			Summary:     node.Summary,
-			Text:        node.Text,
-			SectionPath: node.SectionPath,
-			ChildIds:    node.ChildIDs,
-			Depth:       int32(node.Depth),
-			CreatedAt:   timestamppb.New(node.CreatedAt),
-			UpdatedAt:   timestamppb.New(node.UpdatedAt),
-		}
-	}
+	pbNodes := convert.NodesToPb(nodes)
+	mask.apply(pbNodes)
 
 	return &pb.GetDocumentResponse{
 		Document: pbDoc,
@@ -178,7 +333,7 @@ func (s *Server) GetDocument(ctx context.Context, req *pb.GetDocumentRequest) (*
 }
 
 func (s *Server) DeleteDocument(ctx context.Context, req *pb.DeleteDocumentRequest) (*pb.DeleteDocumentResponse, error) {
-	s.opCounts["DeleteDocument"]++
+	s.countOp("DeleteDocument")
 
 	if req.PolicyId == "" {
 		return nil, status.Error(codes.InvalidArgument, "policy_id is required")
@@ -189,10 +344,124 @@ func (s *Server) DeleteDocument(ctx context.Context, req *pb.DeleteDocumentReque
 	return nil, status.Error(codes.Unimplemented, "DeleteDocument not yet implemented in SimpleStore")
 }
 
+func (s *Server) CloneDocument(ctx context.Context, req *pb.CloneDocumentRequest) (*pb.CloneDocumentResponse, error) {
+	s.countOp("CloneDocument")
+
+	if req.SrcPolicyId == "" || req.DstPolicyId == "" {
+		return nil, status.Error(codes.InvalidArgument, "src_policy_id and dst_policy_id are required")
+	}
+
+	// Only the latest version's tree is stored, so older versions cannot be cloned
+	if req.SrcVersionId != "" {
+		latest, err := s.verStore.GetLatestVersion(req.SrcPolicyId)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "version %s of %s not found: %v", req.SrcVersionId, req.SrcPolicyId, err)
+		}
+		if latest.VersionID != req.SrcVersionId {
+			return nil, status.Errorf(codes.FailedPrecondition,
+				"only the latest version of %s (%s) can be cloned, not %s", req.SrcPolicyId, latest.VersionID, req.SrcVersionId)
+		}
+	}
+
+	result, err := s.docStore.CloneDocument(req.SrcPolicyId, req.DstPolicyId)
+	switch {
+	case errors.Is(err, document.ErrDocumentNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, document.ErrDocumentExists):
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to clone document: %v", err)
+	}
+
+	return &pb.CloneDocumentResponse{RootNodeIds: result.RootNodeIDs, NodeIdMap: result.NodeIDs}, nil
+}
+
+func (s *Server) RecomputeSectionPaths(ctx context.Context, req *pb.RecomputeSectionPathsRequest) (*pb.RecomputeSectionPathsResponse, error) {
+	s.countOp("RecomputeSectionPaths")
+
+	if req.PolicyId == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id is required")
+	}
+
+	recompute := s.docStore.RecomputeSectionPaths
+	if req.DryRun {
+		recompute = s.docStore.CheckSectionPaths
+	}
+	report, err := recompute(req.PolicyId)
+	if errors.Is(err, document.ErrDocumentNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to recompute section paths: %v", err)
+	}
+
+	resp := &pb.RecomputeSectionPathsResponse{
+		NodesChecked: int32(report.NodesChecked),
+		Applied:      report.Applied,
+	}
+	for _, c := range report.Changes {
+		resp.Changes = append(resp.Changes, &pb.SectionPathChange{NodeId: c.NodeID, Stored: c.Stored, Expected: c.Expected})
+	}
+	return resp, nil
+}
+
+func (s *Server) ValidateDocument(ctx context.Context, req *pb.ValidateDocumentRequest) (*pb.ValidateDocumentResponse, error) {
+	s.countOp("ValidateDocument")
+
+	if req.PolicyId == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id is required")
+	}
+
+	report, err := s.docStore.WithContext(ctx).ValidateDocument(req.PolicyId)
+	if errors.Is(err, document.ErrDocumentNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to validate document: %v", err)
+	}
+
+	resp := &pb.ValidateDocumentResponse{
+		Valid:        report.Valid(),
+		NodesChecked: int32(report.NodesChecked),
+	}
+	for _, issue := range report.Issues {
+		resp.Issues = append(resp.Issues, &pb.DocumentIssue{Kind: string(issue.Kind), NodeId: issue.NodeID, Detail: issue.Detail})
+	}
+	return resp, nil
+}
+
+func (s *Server) FindDuplicateNodes(ctx context.Context, req *pb.FindDuplicateNodesRequest) (*pb.FindDuplicateNodesResponse, error) {
+	s.countOp("FindDuplicateNodes")
+
+	if req.PolicyId == "" && !req.CrossPolicy {
+		return nil, status.Error(codes.InvalidArgument, "policy_id is required unless cross_policy is set")
+	}
+
+	store := s.docStore.WithContext(ctx)
+	find := store.FindDuplicateNodes
+	if req.CrossPolicy {
+		find = store.FindCrossPolicyDuplicates
+	}
+	groups, err := find(req.PolicyId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find duplicate nodes: %v", err)
+	}
+
+	resp := &pb.FindDuplicateNodesResponse{}
+	for _, group := range groups {
+		pbGroup := &pb.DuplicateGroup{Checksum: group.Checksum}
+		for _, ref := range group.Nodes {
+			pbGroup.Nodes = append(pbGroup.Nodes, &pb.NodeRef{PolicyId: ref.PolicyID, NodeId: ref.NodeID})
+		}
+		resp.Groups = append(resp.Groups, pbGroup)
+	}
+	return resp, nil
+}
+
 // ========== Node Operations ==========
 
 func (s *Server) GetNode(ctx context.Context, req *pb.GetNodeRequest) (*pb.GetNodeResponse, error) {
-	s.opCounts["GetNode"]++
+	s.countOp("GetNode")
 
 	if req.PolicyId == "" || req.NodeId == "" {
 		return nil, status.Error(codes.InvalidArgument, "policy_id and node_id are required")
@@ -200,122 +469,139 @@ func (s *Server) GetNode(ctx context.Context, req *pb.GetNodeRequest) (*pb.GetNo
 
 	node, err := s.docStore.GetNode(req.PolicyId, req.NodeId)
 	if err != nil {
+		if storage.IsCorruption(err) {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
 		return nil, status.Errorf(codes.NotFound, "node not found: %v", err)
 	}
 
-	parentID := ""
-	if node.ParentID != nil {
-		parentID = *node.ParentID
+	return &pb.GetNodeResponse{Node: convert.NodeToPb(node)}, nil
+}
+
+func (s *Server) UpdateNode(ctx context.Context, req *pb.UpdateNodeRequest) (*pb.UpdateNodeResponse, error) {
+	s.countOp("UpdateNode")
+
+	if req.Node == nil || req.Node.PolicyId == "" || req.Node.NodeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "node with policy_id and node_id is required")
 	}
 
-	pbNode := &pb.Node{
-		NodeId:      node.NodeID,
-		PolicyId:    node.PolicyID,
-		ParentId:    parentID,
-		Title:       node.Title,
-		PageStart:   int32(node.PageStart),
-		PageEnd:     int32(node.PageEnd),
-		Summary:     node.Summary,
-		Text:        node.Text,
-		SectionPath: node.SectionPath,
-		ChildIds:    node.ChildIDs,
-		Depth:       int32(node.Depth),
-		CreatedAt:   timestamppb.New(node.CreatedAt),
-		UpdatedAt:   timestamppb.New(node.UpdatedAt),
+	node := convert.NodeFromPb(req.Node)
+	node.UpdatedAt = time.Now()
+	if err := s.docStore.UpdateNode(node); err != nil {
+		if errors.Is(err, document.ErrVersionConflict) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		if storage.IsCorruption(err) {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return nil, status.Errorf(codes.NotFound, "node not found: %v", err)
 	}
 
-	return &pb.GetNodeResponse{Node: pbNode}, nil
+	return &pb.UpdateNodeResponse{Node: convert.NodeToPb(node)}, nil
 }
 
 func (s *Server) GetChildren(ctx context.Context, req *pb.GetChildrenRequest) (*pb.GetChildrenResponse, error) {
-	s.opCounts["GetChildren"]++
+	s.countOp("GetChildren")
 
 	if req.PolicyId == "" {
 		return nil, status.Error(codes.InvalidArgument, "policy_id is required")
 	}
 
+	mask, err := parseNodeMask(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
 	var parentID *string
 	if req.ParentId != "" {
 		parentID = &req.ParentId
 	}
 
-	children, err := s.docStore.GetChildren(req.PolicyId, parentID)
+	children, err := s.docStore.WithContext(ctx).WithoutFields(mask.omitted()).GetChildren(req.PolicyId, parentID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get children: %v", err)
 	}
 
-	pbChildren := make([]*pb.Node, len(children))
-	for i, node := range children {
-		parentIDStr := ""
-		if node.ParentID != nil {
-			parentIDStr = *node.ParentID
+	pbChildren := convert.NodesToPb(children)
+	mask.apply(pbChildren)
+
+	return &pb.GetChildrenResponse{Children: pbChildren}, nil
+}
+
+func (s *Server) GetSubtree(ctx context.Context, req *pb.GetSubtreeRequest) (*pb.GetSubtreeResponse, error) {
+	s.countOp("GetSubtree")
+
+	if req.Collection != "" {
+		if req.PolicyId != "" || req.NodeId != "" {
+			return nil, status.Error(codes.InvalidArgument, "collection replaces policy_id and node_id")
 		}
+	} else if req.PolicyId == "" || req.NodeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id and node_id, or collection, are required")
+	}
+
+	mask, err := parseNodeMask(req.Fields)
+	if err != nil {
+		return nil, err
+	}
 
-		pbChildren[i] = &pb.Node{
-			NodeId:      node.NodeID,
-			PolicyId:    node.PolicyID,
-			ParentId:    parentIDStr,
-			Title:       node.Title,
-			PageStart:   int32(node.PageStart),
-			PageEnd:     int32(node.PageEnd),
-			Summary:     node.Summary,
-			Text:        node.Text,
-			SectionPath: node.SectionPath,
-			ChildIds:    node.ChildIDs,
-			Depth:       int32(node.Depth),
-			CreatedAt:   timestamppb.New(node.CreatedAt),
-			UpdatedAt:   timestamppb.New(node.UpdatedAt),
+	opts := document.QueryOptions{
+		MaxDepth: int(req.MaxDepth),
+		Workers:  s.subtreeWorkers,
+	}
+
+	store := s.docStore.WithContext(ctx).WithoutFields(mask.omitted())
+	var nodes []*document.Node
+	if req.Collection != "" {
+		nodes, err = s.collectionSubtrees(store, req.Collection, opts)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		nodes, err = store.GetSubtree(req.PolicyId, req.NodeId, opts)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get subtree: %v", err)
 		}
 	}
 
-	return &pb.GetChildrenResponse{Children: pbChildren}, nil
+	pbNodes := convert.NodesToPb(nodes)
+	mask.apply(pbNodes)
+
+	return &pb.GetSubtreeResponse{Nodes: pbNodes}, nil
 }
 
-func (s *Server) GetSubtree(ctx context.Context, req *pb.GetSubtreeRequest) (*pb.GetSubtreeResponse, error) {
-	s.opCounts["GetSubtree"]++
+func (s *Server) GetSubtreeWithinBudget(ctx context.Context, req *pb.GetSubtreeWithinBudgetRequest) (*pb.GetSubtreeWithinBudgetResponse, error) {
+	s.countOp("GetSubtreeWithinBudget")
 
 	if req.PolicyId == "" || req.NodeId == "" {
 		return nil, status.Error(codes.InvalidArgument, "policy_id and node_id are required")
 	}
+	if req.MaxTokens <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_tokens must be positive")
+	}
 
 	opts := document.QueryOptions{
 		MaxDepth: int(req.MaxDepth),
+		Workers:  s.subtreeWorkers,
+	}
+	sub, err := s.docStore.WithContext(ctx).GetSubtreeWithinBudget(req.PolicyId, req.NodeId, int(req.MaxTokens), opts)
+	if errors.Is(err, document.ErrBudgetTooSmall) {
+		return nil, status.Error(codes.OutOfRange, err.Error())
 	}
-
-	nodes, err := s.docStore.GetSubtree(req.PolicyId, req.NodeId, opts)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get subtree: %v", err)
 	}
 
-	pbNodes := make([]*pb.Node, len(nodes))
-	for i, node := range nodes {
-		parentID := ""
-		if node.ParentID != nil {
-			parentID = *node.ParentID
-		}
-
-		pbNodes[i] = &pb.Node{
-			NodeId:      node.NodeID,
-			PolicyId:    node.PolicyID,
-			ParentId:    parentID,
-			Title:       node.Title,
-			PageStart:   int32(node.PageStart),
-			PageEnd:     int32(node.PageEnd),
-			Summary:     node.Summary,
-			Text:        node.Text,
-			SectionPath: node.SectionPath,
-			ChildIds:    node.ChildIDs,
-			Depth:       int32(node.Depth),
-			CreatedAt:   timestamppb.New(node.CreatedAt),
-			UpdatedAt:   timestamppb.New(node.UpdatedAt),
-		}
-	}
-
-	return &pb.GetSubtreeResponse{Nodes: pbNodes}, nil
+	return &pb.GetSubtreeWithinBudgetResponse{
+		Nodes:         convert.NodesToPb(sub.Nodes),
+		SummarizedIds: sub.Summarized,
+		Depth:         int32(sub.Depth),
+		TokenCount:    int32(sub.TokenCount),
+		Truncated:     sub.Truncated,
+	}, nil
 }
 
 func (s *Server) GetAncestorPath(ctx context.Context, req *pb.GetAncestorPathRequest) (*pb.GetAncestorPathResponse, error) {
-	s.opCounts["GetAncestorPath"]++
+	s.countOp("GetAncestorPath")
 
 	if req.PolicyId == "" || req.NodeId == "" {
 		return nil, status.Error(codes.InvalidArgument, "policy_id and node_id are required")
@@ -326,40 +612,171 @@ func (s *Server) GetAncestorPath(ctx context.Context, req *pb.GetAncestorPathReq
 		return nil, status.Errorf(codes.Internal, "failed to get ancestor path: %v", err)
 	}
 
-	pbPath := make([]*pb.Node, len(path))
-	for i, node := range path {
-		parentID := ""
-		if node.ParentID != nil {
-			parentID = *node.ParentID
-		}
+	pbPath := convert.NodesToPb(path)
 
-		pbPath[i] = &pb.Node{
-			NodeId:      node.NodeID,
-			PolicyId:    node.PolicyID,
-			ParentId:    parentID,
-			Title:       node.Title,
-			PageStart:   int32(node.PageStart),
-			PageEnd:     int32(node.PageEnd),
-			Summary:     node.Summary,
-			Text:        node.Text,
-			SectionPath: node.SectionPath,
-			ChildIds:    node.ChildIDs,
-			Depth:       int32(node.Depth),
-			CreatedAt:   timestamppb.New(node.CreatedAt),
-			UpdatedAt:   timestamppb.New(node.UpdatedAt),
+	return &pb.GetAncestorPathResponse{Ancestors: pbPath}, nil
+}
+
+func (s *Server) BatchGetAncestorPaths(ctx context.Context, req *pb.BatchGetAncestorPathsRequest) (*pb.BatchGetAncestorPathsResponse, error) {
+	s.countOp("BatchGetAncestorPaths")
+
+	if req.PolicyId == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id is required")
+	}
+
+	mask, err := parseNodeMask(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := s.docStore.WithContext(ctx).WithoutFields(mask.omitted()).BatchGetAncestorPaths(req.PolicyId, req.NodeIds)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get ancestor paths: %v", err)
+	}
+
+	resp := &pb.BatchGetAncestorPathsResponse{Paths: make([]*pb.AncestorPath, len(paths))}
+	for i, path := range paths {
+		ancestors := convert.NodesToPb(path)
+		mask.apply(ancestors)
+		resp.Paths[i] = &pb.AncestorPath{NodeId: req.NodeIds[i], Ancestors: ancestors, Found: path != nil}
+	}
+	return resp, nil
+}
+
+func (s *Server) GetSiblings(ctx context.Context, req *pb.GetSiblingsRequest) (*pb.GetSiblingsResponse, error) {
+	s.countOp("GetSiblings")
+
+	if req.PolicyId == "" || req.NodeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id and node_id are required")
+	}
+
+	mask, err := parseNodeMask(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	siblings, position, err := s.docStore.WithContext(ctx).WithoutFields(mask.omitted()).GetSiblings(req.PolicyId, req.NodeId)
+	if errors.Is(err, document.ErrNodeNotFound) {
+		return nil, status.Errorf(codes.NotFound, "node %s not found in %s", req.NodeId, req.PolicyId)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get siblings: %v", err)
+	}
+
+	pbSiblings := convert.NodesToPb(siblings)
+	mask.apply(pbSiblings)
+
+	return &pb.GetSiblingsResponse{Siblings: pbSiblings, Position: int32(position)}, nil
+}
+
+func (s *Server) GetNextSection(ctx context.Context, req *pb.AdjacentSectionRequest) (*pb.AdjacentSectionResponse, error) {
+	s.countOp("GetNextSection")
+	return s.adjacentSection(ctx, req, (*document.SimpleStore).GetNextSection)
+}
+
+func (s *Server) GetPreviousSection(ctx context.Context, req *pb.AdjacentSectionRequest) (*pb.AdjacentSectionResponse, error) {
+	s.countOp("GetPreviousSection")
+	return s.adjacentSection(ctx, req, (*document.SimpleStore).GetPreviousSection)
+}
+
+// adjacentSection answers GetNextSection and GetPreviousSection with step
+func (s *Server) adjacentSection(ctx context.Context, req *pb.AdjacentSectionRequest, step func(*document.SimpleStore, string, string) (*document.Node, error)) (*pb.AdjacentSectionResponse, error) {
+	if req.PolicyId == "" || req.NodeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id and node_id are required")
+	}
+
+	mask, err := parseNodeMask(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := step(s.docStore.WithContext(ctx).WithoutFields(mask.omitted()), req.PolicyId, req.NodeId)
+	if errors.Is(err, document.ErrNodeNotFound) {
+		return nil, status.Errorf(codes.NotFound, "node %s not found in %s", req.NodeId, req.PolicyId)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get section: %v", err)
+	}
+	if node == nil {
+		return &pb.AdjacentSectionResponse{}, nil
+	}
+
+	pbNodes := convert.NodesToPb([]*document.Node{node})
+	mask.apply(pbNodes)
+
+	return &pb.AdjacentSectionResponse{Node: pbNodes[0]}, nil
+}
+
+func (s *Server) GetReadingOrder(req *pb.GetReadingOrderRequest, stream pb.TreeStoreService_GetReadingOrderServer) error {
+	s.countOp("GetReadingOrder")
+
+	if req.PolicyId == "" {
+		return status.Error(codes.InvalidArgument, "policy_id is required")
+	}
+
+	mask, err := parseNodeMask(req.Fields)
+	if err != nil {
+		return err
+	}
+
+	// A failed send stops the walk and is returned as it is
+	var sendErr error
+	store := s.docStore.WithContext(stream.Context()).WithoutFields(mask.omitted())
+	err = store.GetReadingOrder(req.PolicyId, func(node *document.Node) error {
+		pbNodes := convert.NodesToPb([]*document.Node{node})
+		mask.apply(pbNodes)
+		sendErr = stream.Send(pbNodes[0])
+		return sendErr
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if errors.Is(err, document.ErrDocumentNotFound) {
+		return status.Errorf(codes.NotFound, "document %s not found", req.PolicyId)
+	}
+	if err != nil {
+		if ctxErr := stream.Context().Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
 		}
+		return status.Errorf(codes.Internal, "failed to read document: %v", err)
 	}
+	return nil
+}
 
-	return &pb.GetAncestorPathResponse{Ancestors: pbPath}, nil
+func (s *Server) GetContextWindow(ctx context.Context, req *pb.GetContextWindowRequest) (*pb.GetContextWindowResponse, error) {
+	s.countOp("GetContextWindow")
+
+	if req.PolicyId == "" || req.NodeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id and node_id are required")
+	}
+	if req.TokenBudget < 0 {
+		return nil, status.Error(codes.InvalidArgument, "token_budget must not be negative")
+	}
+
+	window, err := s.docStore.WithContext(ctx).GetContextWindow(req.PolicyId, req.NodeId, document.ContextOptions{
+		TokenBudget: int(req.TokenBudget),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "failed to build context window: %v", err)
+	}
+
+	return &pb.GetContextWindowResponse{
+		Node:       convert.NodeToPb(window.Node),
+		Ancestors:  contextEntriesToPb(window.Ancestors),
+		Siblings:   contextEntriesToPb(window.Siblings),
+		Children:   contextEntriesToPb(window.Children),
+		TokenCount: int32(window.TokenCount),
+		Truncated:  window.Truncated,
+	}, nil
 }
 
 // ========== Search Operations ==========
 
 func (s *Server) SearchByKeyword(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
-	s.opCounts["SearchByKeyword"]++
+	s.countOp("SearchByKeyword")
 
-	if req.PolicyId == "" || req.Query == "" {
-		return nil, status.Error(codes.InvalidArgument, "policy_id and query are required")
+	if req.Query == "" || (req.PolicyId == "") == (req.Collection == "") {
+		return nil, status.Error(codes.InvalidArgument, "query and one of policy_id or collection are required")
 	}
 
 	limit := int(req.Limit)
@@ -367,16 +784,40 @@ func (s *Server) SearchByKeyword(ctx context.Context, req *pb.SearchRequest) (*p
 		limit = 10
 	}
 
-	results, err := s.docStore.Search(req.PolicyId, req.Query, limit)
+	policyIDs := []string{req.PolicyId}
+	if req.Collection != "" {
+		var err error
+		if policyIDs, err = s.collectionPolicies(req.Collection); err != nil {
+			return nil, err
+		}
+	}
+
+	var results []*document.SearchResult
+	store := s.docStore.WithContext(ctx)
+	for _, policyID := range policyIDs {
+		hits, err := store.SearchFiltered(policyID, req.Query, limit, s.searchFilter(req.Filter))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "search failed: %v", err)
+		}
+		results = append(results, hits...)
+	}
+	if len(policyIDs) > 1 {
+		// Each member's hits are in scan order; merge them best first
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+		if len(results) > limit {
+			results = results[:limit]
+		}
+	}
+
+	nodes, err := s.resultNodes(results)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "search failed: %v", err)
+		return nil, err
 	}
 
 	pbResults := make([]*pb.SearchResult, len(results))
 	for i, result := range results {
-		// Get full node details for the search result
-		node, err := s.docStore.GetNode(result.PolicyID, result.NodeID)
-		if err != nil {
+		node := nodes[i]
+		if node == nil {
 			// If we can't get the node, just use what we have from search
 			pbResults[i] = &pb.SearchResult{
 				Node: &pb.Node{
@@ -385,41 +826,51 @@ func (s *Server) SearchByKeyword(ctx context.Context, req *pb.SearchRequest) (*p
 					Title:    result.Title,
 					Summary:  result.Summary,
 				},
-				Score: float32(result.Score),
+				Score:   float32(result.Score),
+				Snippet: result.Snippet,
 			}
 			continue
 		}
 
-		parentID := ""
-		if node.ParentID != nil {
-			parentID = *node.ParentID
-		}
+		snippet, highlights := document.NodeSnippet(node, req.Query, int(req.SnippetLength))
 
 		pbResults[i] = &pb.SearchResult{
-			Node: &pb.Node{
-				NodeId:      node.NodeID,
-				PolicyId:    node.PolicyID,
-				ParentId:    parentID,
-				Title:       node.Title,
-				PageStart:   int32(node.PageStart),
-				PageEnd:     int32(node.PageEnd),
-				Summary:     node.Summary,
-				Text:        node.Text,
-				SectionPath: node.SectionPath,
-				ChildIds:    node.ChildIDs,
-				Depth:       int32(node.Depth),
-				CreatedAt:   timestamppb.New(node.CreatedAt),
-				UpdatedAt:   timestamppb.New(node.UpdatedAt),
-			},
-			Score: float32(result.Score),
+			Node:       convert.NodeToPb(node),
+			Score:      float32(result.Score),
+			Snippet:    snippet,
+			Highlights: highlightsToPb(highlights),
 		}
 	}
 
 	return &pb.SearchResponse{Results: pbResults}, nil
 }
 
+// resultNodes hydrates search results with one batched lookup per policy
+// The nodes are aligned with results; nodes deleted since are left nil.
+func (s *Server) resultNodes(results []*document.SearchResult) ([]*document.Node, error) {
+	byPolicy := make(map[string][]int)
+	for i, result := range results {
+		byPolicy[result.PolicyID] = append(byPolicy[result.PolicyID], i)
+	}
+	nodes := make([]*document.Node, len(results))
+	for policyID, at := range byPolicy {
+		nodeIDs := make([]string, len(at))
+		for j, i := range at {
+			nodeIDs[j] = results[i].NodeID
+		}
+		found, err := s.docStore.GetNodes(policyID, nodeIDs)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to load search results: %v", err)
+		}
+		for j, i := range at {
+			nodes[i] = found[j]
+		}
+	}
+	return nodes, nil
+}
+
 func (s *Server) GetNodesByPage(ctx context.Context, req *pb.GetNodesByPageRequest) (*pb.GetNodesByPageResponse, error) {
-	s.opCounts["GetNodesByPage"]++
+	s.countOp("GetNodesByPage")
 
 	if req.PolicyId == "" {
 		return nil, status.Error(codes.InvalidArgument, "policy_id is required")
@@ -431,10 +882,95 @@ func (s *Server) GetNodesByPage(ctx context.Context, req *pb.GetNodesByPageReque
 	return nil, status.Error(codes.Unimplemented, "GetNodesByPage not yet implemented in SimpleStore")
 }
 
+func (s *Server) GlobalSearch(ctx context.Context, req *pb.GlobalSearchRequest) (*pb.GlobalSearchResponse, error) {
+	s.countOp("GlobalSearch")
+
+	if req.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+
+	perPolicy := int(req.PerPolicyLimit)
+	if perPolicy == 0 {
+		perPolicy = 10
+	}
+
+	var groups []*document.PolicySearchResults
+	var err error
+	if req.Collection != "" {
+		policyIDs, cerr := s.collectionPolicies(req.Collection)
+		if cerr != nil {
+			return nil, cerr
+		}
+		groups, err = s.docStore.WithContext(ctx).SearchPolicies(req.Query, policyIDs, perPolicy, int(req.MaxPolicies))
+	} else {
+		groups, err = s.docStore.WithContext(ctx).SearchAll(req.Query, perPolicy, int(req.MaxPolicies))
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "global search failed: %v", err)
+	}
+
+	pbGroups := make([]*pb.PolicySearchResults, 0, len(groups))
+	for _, group := range groups {
+		pbResults := make([]*pb.SearchResult, 0, len(group.Results))
+		for _, result := range group.Results {
+			snippet, highlights := document.NodeSnippet(result.Node, req.Query, int(req.SnippetLength))
+			pbResults = append(pbResults, &pb.SearchResult{
+				Node:       convert.NodeToPb(result.Node),
+				Score:      float32(result.Score),
+				Snippet:    snippet,
+				Highlights: highlightsToPb(highlights),
+			})
+		}
+
+		pbGroups = append(pbGroups, &pb.PolicySearchResults{
+			PolicyId:  group.PolicyID,
+			Results:   pbResults,
+			TotalHits: int32(group.TotalHits),
+		})
+	}
+
+	return &pb.GlobalSearchResponse{Policies: pbGroups}, nil
+}
+
+func (s *Server) JoinNodes(ctx context.Context, req *pb.JoinNodesRequest) (*pb.JoinNodesResponse, error) {
+	s.countOp("JoinNodes")
+
+	if req.PolicyId == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id is required")
+	}
+	if len(req.Metadata) == 0 && req.ReferencesTo == "" && req.ReferencedBy == "" {
+		return nil, status.Error(codes.InvalidArgument, "metadata, references_to or referenced_by is required")
+	}
+
+	results, err := s.engine.WithContext(ctx).JoinNodes(query.NodeJoin{
+		PolicyID:      req.PolicyId,
+		Metadata:      req.Metadata,
+		ReferencesTo:  req.ReferencesTo,
+		ReferencedBy:  req.ReferencedBy,
+		ReferenceType: req.ReferenceType,
+		Limit:         int(req.Limit),
+		Offset:        int(req.Offset),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "join failed: %v", err)
+	}
+
+	pbResults := make([]*pb.JoinedNode, len(results))
+	for i, r := range results {
+		pbResults[i] = &pb.JoinedNode{
+			Node:       convert.NodeToPb(r.Node),
+			Metadata:   r.Metadata,
+			References: convert.CrossReferencesToPb(r.References),
+		}
+	}
+
+	return &pb.JoinNodesResponse{Results: pbResults}, nil
+}
+
 // ========== Version Operations ==========
 
 func (s *Server) GetVersionAsOf(ctx context.Context, req *pb.GetVersionAsOfRequest) (*pb.PolicyVersion, error) {
-	s.opCounts["GetVersionAsOf"]++
+	s.countOp("GetVersionAsOf")
 
 	if req.PolicyId == "" || req.AsOfTime == nil {
 		return nil, status.Error(codes.InvalidArgument, "policy_id and as_of_time are required")
@@ -445,70 +981,150 @@ func (s *Server) GetVersionAsOf(ctx context.Context, req *pb.GetVersionAsOfReque
 		return nil, status.Errorf(codes.NotFound, "version not found: %v", err)
 	}
 
-	return &pb.PolicyVersion{
-		PolicyId:    ver.PolicyID,
-		VersionId:   ver.VersionID,
-		DocumentId:  ver.DocumentID,
-		CreatedAt:   timestamppb.New(ver.CreatedAt),
-		CreatedBy:   ver.CreatedBy,
-		Description: ver.Description,
-		Tags:        ver.Tags,
-	}, nil
+	return convert.VersionToPb(ver), nil
+}
+
+func (s *Server) BatchGetVersionsAsOf(ctx context.Context, req *pb.BatchGetVersionsAsOfRequest) (*pb.BatchGetVersionsAsOfResponse, error) {
+	s.countOp("BatchGetVersionsAsOf")
+
+	if len(req.PolicyIds) == 0 || req.AsOfTime == nil {
+		return nil, status.Error(codes.InvalidArgument, "policy_ids and as_of_time are required")
+	}
+
+	versions, err := s.verStore.GetVersionsAsOf(req.PolicyIds, req.AsOfTime.AsTime())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to resolve versions: %v", err)
+	}
+
+	resp := &pb.BatchGetVersionsAsOfResponse{Versions: make(map[string]*pb.PolicyVersion, len(versions))}
+	for _, policyID := range req.PolicyIds {
+		if ver, ok := versions[policyID]; ok {
+			resp.Versions[policyID] = convert.VersionToPb(ver)
+		} else {
+			resp.MissingPolicyIds = append(resp.MissingPolicyIds, policyID)
+		}
+	}
+
+	return resp, nil
 }
 
 func (s *Server) ListVersions(ctx context.Context, req *pb.ListVersionsRequest) (*pb.ListVersionsResponse, error) {
-	s.opCounts["ListVersions"]++
+	s.countOp("ListVersions")
+
+	if req.PolicyId == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id is required")
+	}
+
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = 100
+	}
+
+	versions, err := s.verStore.WithContext(ctx).ListVersions(req.PolicyId, limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list versions: %v", err)
+	}
+
+	pbVersions := make([]*pb.PolicyVersion, len(versions))
+	for i, ver := range versions {
+		pbVersions[i] = convert.VersionToPb(ver)
+	}
+
+	return &pb.ListVersionsResponse{Versions: pbVersions}, nil
+}
+
+func (s *Server) DeleteVersion(ctx context.Context, req *pb.DeleteVersionRequest) (*pb.DeleteVersionResponse, error) {
+	s.countOp("DeleteVersion")
+
+	if req.PolicyId == "" || req.VersionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id and version_id are required")
+	}
+
+	if _, err := s.verStore.GetVersion(req.PolicyId, req.VersionId); err != nil {
+		return nil, status.Errorf(codes.NotFound, "version not found: %v", err)
+	}
+
+	if err := s.verStore.DeleteVersion(req.PolicyId, req.VersionId); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete version: %v", err)
+	}
+
+	return &pb.DeleteVersionResponse{Success: true}, nil
+}
+
+func (s *Server) PruneVersions(ctx context.Context, req *pb.PruneVersionsRequest) (*pb.PruneVersionsResponse, error) {
+	s.countOp("PruneVersions")
 
 	if req.PolicyId == "" {
 		return nil, status.Error(codes.InvalidArgument, "policy_id is required")
 	}
+	if req.KeepLast < 0 {
+		return nil, status.Error(codes.InvalidArgument, "keep_last must not be negative")
+	}
+
+	opts := version.PruneOptions{
+		KeepLast:    int(req.KeepLast),
+		ProtectTags: req.ProtectTags,
+		DryRun:      req.DryRun,
+	}
+	if req.OlderThan != nil {
+		opts.OlderThan = req.OlderThan.AsTime()
+	}
+
+	pruned, err := s.verStore.PruneVersions(req.PolicyId, opts)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to prune versions: %v", err)
+	}
+
+	return &pb.PruneVersionsResponse{PrunedVersionIds: pruned, DryRun: req.DryRun}, nil
+}
+
+func (s *Server) TagVersion(ctx context.Context, req *pb.TagVersionRequest) (*pb.TagVersionResponse, error) {
+	s.countOp("TagVersion")
+
+	if req.PolicyId == "" || req.VersionId == "" || req.Tag == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id, version_id and tag are required")
+	}
+
+	if _, err := s.verStore.GetVersion(req.PolicyId, req.VersionId); err != nil {
+		return nil, status.Errorf(codes.NotFound, "version not found: %v", err)
+	}
+
+	if err := s.verStore.TagVersion(req.PolicyId, req.VersionId, req.Tag, req.Unique); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to tag version: %v", err)
+	}
+
+	return &pb.TagVersionResponse{Success: true}, nil
+}
+
+func (s *Server) UntagVersion(ctx context.Context, req *pb.UntagVersionRequest) (*pb.UntagVersionResponse, error) {
+	s.countOp("UntagVersion")
 
-	limit := int(req.Limit)
-	if limit == 0 {
-		limit = 100
+	if req.PolicyId == "" || req.VersionId == "" || req.Tag == "" {
+		return nil, status.Error(codes.InvalidArgument, "policy_id, version_id and tag are required")
 	}
 
-	versions, err := s.verStore.ListVersions(req.PolicyId, limit)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to list versions: %v", err)
+	if _, err := s.verStore.GetVersion(req.PolicyId, req.VersionId); err != nil {
+		return nil, status.Errorf(codes.NotFound, "version not found: %v", err)
 	}
 
-	pbVersions := make([]*pb.PolicyVersion, len(versions))
-	for i, ver := range versions {
-		pbVersions[i] = &pb.PolicyVersion{
-			PolicyId:    ver.PolicyID,
-			VersionId:   ver.VersionID,
-			DocumentId:  ver.DocumentID,
-			CreatedAt:   timestamppb.New(ver.CreatedAt),
-			CreatedBy:   ver.CreatedBy,
-			Description: ver.Description,
-			Tags:        ver.Tags,
-		}
+	if err := s.verStore.UntagVersion(req.PolicyId, req.VersionId, req.Tag); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to untag version: %v", err)
 	}
 
-	return &pb.ListVersionsResponse{Versions: pbVersions}, nil
+	return &pb.UntagVersionResponse{Success: true}, nil
 }
 
 // ========== Metadata Operations ==========
 
 func (s *Server) StoreToolResult(ctx context.Context, req *pb.StoreToolResultRequest) (*pb.StoreToolResultResponse, error) {
-	s.opCounts["StoreToolResult"]++
+	s.countOp("StoreToolResult")
 
 	if req.Result == nil {
 		return nil, status.Error(codes.InvalidArgument, "result is required")
 	}
 
-	// Store as metadata entry
-	entry := &metadata.MetadataEntry{
-		EntityType: "tool_result",
-		EntityID:   req.Result.ExecutionId,
-		Key:        "tool_result",
-		Value:      fmt.Sprintf("%s|%s|%s|%s", req.Result.ToolName, req.Result.PolicyId, req.Result.NodeId, req.Result.ResultData),
-		ValueType:  "json",
-		CreatedAt:  req.Result.ExecutedAt.AsTime(),
-		UpdatedAt:  req.Result.ExecutedAt.AsTime(),
-	}
-
+	r := req.Result
+	entry := toolResultEntry(r.ExecutionId, r.ToolName, r.PolicyId, r.NodeId, r.ResultData, r.ExecutedAt.AsTime())
 	if err := s.metaStore.SetMetadata(entry); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to store tool result: %v", err)
 	}
@@ -520,7 +1136,7 @@ func (s *Server) StoreToolResult(ctx context.Context, req *pb.StoreToolResultReq
 }
 
 func (s *Server) GetToolResults(ctx context.Context, req *pb.GetToolResultsRequest) (*pb.GetToolResultsResponse, error) {
-	s.opCounts["GetToolResults"]++
+	s.countOp("GetToolResults")
 
 	if req.PolicyId == "" {
 		return nil, status.Error(codes.InvalidArgument, "policy_id is required")
@@ -546,7 +1162,7 @@ func (s *Server) GetToolResults(ctx context.Context, req *pb.GetToolResultsReque
 }
 
 func (s *Server) StoreTrajectory(ctx context.Context, req *pb.StoreTrajectoryRequest) (*pb.StoreTrajectoryResponse, error) {
-	s.opCounts["StoreTrajectory"]++
+	s.countOp("StoreTrajectory")
 
 	if req.Trajectory == nil {
 		return nil, status.Error(codes.InvalidArgument, "trajectory is required")
@@ -563,6 +1179,13 @@ func (s *Server) StoreTrajectory(ctx context.Context, req *pb.StoreTrajectoryReq
 		UpdatedAt:  req.Trajectory.CompletedAt.AsTime(),
 	}
 
+	// Steps go first, so a step over the size limit stores nothing
+	steps := convert.TrajectoryStepsFromPb(req.Trajectory.Steps)
+	if err := s.metaStore.PutTrajectorySteps(req.Trajectory.TrajectoryId, steps); errors.Is(err, metadata.ErrStepTooLarge) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to store trajectory steps: %v", err)
+	}
 	if err := s.metaStore.SetMetadata(entry); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to store trajectory: %v", err)
 	}
@@ -574,7 +1197,7 @@ func (s *Server) StoreTrajectory(ctx context.Context, req *pb.StoreTrajectoryReq
 }
 
 func (s *Server) GetTrajectories(ctx context.Context, req *pb.GetTrajectoriesRequest) (*pb.GetTrajectoriesResponse, error) {
-	s.opCounts["GetTrajectories"]++
+	s.countOp("GetTrajectories")
 
 	if req.CaseId == "" {
 		return nil, status.Error(codes.InvalidArgument, "case_id is required")
@@ -588,9 +1211,14 @@ func (s *Server) GetTrajectories(ctx context.Context, req *pb.GetTrajectoriesReq
 
 	trajectories := make([]*pb.Trajectory, len(entries))
 	for i, entry := range entries {
+		steps, err := s.metaStore.TrajectorySteps(entry.EntityID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get trajectory steps: %v", err)
+		}
 		trajectories[i] = &pb.Trajectory{
 			TrajectoryId: entry.EntityID,
 			CaseId:       entry.Value,
+			Steps:        convert.TrajectoryStepsToPb(steps),
 			StartedAt:    timestamppb.New(entry.CreatedAt),
 			CompletedAt:  timestamppb.New(entry.UpdatedAt),
 		}
@@ -600,27 +1228,18 @@ func (s *Server) GetTrajectories(ctx context.Context, req *pb.GetTrajectoriesReq
 }
 
 func (s *Server) StoreCrossReference(ctx context.Context, req *pb.StoreCrossReferenceRequest) (*pb.StoreCrossReferenceResponse, error) {
-	s.opCounts["StoreCrossReference"]++
+	s.countOp("StoreCrossReference")
 
 	if req.CrossReference == nil {
 		return nil, status.Error(codes.InvalidArgument, "cross_reference is required")
 	}
 
-	refID := fmt.Sprintf("%s:%s->%s:%s",
-		req.CrossReference.SourcePolicyId, req.CrossReference.SourceNodeId,
-		req.CrossReference.TargetPolicyId, req.CrossReference.TargetNodeId)
-
-	entry := &metadata.MetadataEntry{
-		EntityType: "cross_reference",
-		EntityID:   refID,
-		Key:        "reference_type",
-		Value:      req.CrossReference.ReferenceType,
-		ValueType:  "string",
-		CreatedAt:  req.CrossReference.CreatedAt.AsTime(),
-		UpdatedAt:  req.CrossReference.CreatedAt.AsTime(),
+	ref := req.CrossReference
+	if ref.SourcePolicyId == "" || ref.SourceNodeId == "" || ref.TargetPolicyId == "" || ref.TargetNodeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "source and target policy_id and node_id are required")
 	}
 
-	if err := s.metaStore.SetMetadata(entry); err != nil {
+	if err := s.metaStore.AddCrossReference(convert.CrossReferenceFromPb(ref)); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to store cross reference: %v", err)
 	}
 
@@ -631,46 +1250,33 @@ func (s *Server) StoreCrossReference(ctx context.Context, req *pb.StoreCrossRefe
 }
 
 func (s *Server) GetCrossReferences(ctx context.Context, req *pb.GetCrossReferencesRequest) (*pb.GetCrossReferencesResponse, error) {
-	s.opCounts["GetCrossReferences"]++
+	s.countOp("GetCrossReferences")
 
 	if req.PolicyId == "" || req.NodeId == "" {
 		return nil, status.Error(codes.InvalidArgument, "policy_id and node_id are required")
 	}
 
-	var entityType = "cross_reference"
-	entries, err := s.metaStore.QueryByKey("reference_type", &entityType, 0)
+	outgoing, err := s.metaStore.ReferencesFrom(req.PolicyId, req.NodeId)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get cross references: %v", err)
 	}
-
-	references := make([]*pb.CrossReference, 0)
-	for _, entry := range entries {
-		references = append(references, &pb.CrossReference{
-			ReferenceType: entry.Value,
-			CreatedAt:     timestamppb.New(entry.CreatedAt),
-		})
+	incoming, err := s.metaStore.ReferencesTo(req.PolicyId, req.NodeId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get cross references: %v", err)
 	}
 
-	return &pb.GetCrossReferencesResponse{References: references}, nil
+	return &pb.GetCrossReferencesResponse{References: convert.CrossReferencesToPb(append(outgoing, incoming...))}, nil
 }
 
 func (s *Server) StoreContradiction(ctx context.Context, req *pb.StoreContradictionRequest) (*pb.StoreContradictionResponse, error) {
-	s.opCounts["StoreContradiction"]++
+	s.countOp("StoreContradiction")
 
 	if req.Contradiction == nil {
 		return nil, status.Error(codes.InvalidArgument, "contradiction is required")
 	}
 
-	entry := &metadata.MetadataEntry{
-		EntityType: "contradiction",
-		EntityID:   req.Contradiction.ContradictionId,
-		Key:        "severity",
-		Value:      req.Contradiction.Severity,
-		ValueType:  "string",
-		CreatedAt:  req.Contradiction.DetectedAt.AsTime(),
-		UpdatedAt:  req.Contradiction.DetectedAt.AsTime(),
-	}
-
+	c := req.Contradiction
+	entry := contradictionEntry(c.ContradictionId, c.Severity, c.DetectedAt.AsTime())
 	if err := s.metaStore.SetMetadata(entry); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to store contradiction: %v", err)
 	}
@@ -681,10 +1287,94 @@ func (s *Server) StoreContradiction(ctx context.Context, req *pb.StoreContradict
 	}, nil
 }
 
+// toolResultEntry returns the metadata entry a tool result is stored as
+func toolResultEntry(executionID, toolName, policyID, nodeID, data string, at time.Time) *metadata.MetadataEntry {
+	return &metadata.MetadataEntry{
+		EntityType: "tool_result",
+		EntityID:   executionID,
+		Key:        "tool_result",
+		Value:      fmt.Sprintf("%s|%s|%s|%s", toolName, policyID, nodeID, data),
+		ValueType:  "json",
+		CreatedAt:  at,
+		UpdatedAt:  at,
+	}
+}
+
+// contradictionEntry returns the metadata entry a contradiction is stored as
+func contradictionEntry(contradictionID, severity string, at time.Time) *metadata.MetadataEntry {
+	return &metadata.MetadataEntry{
+		EntityType: "contradiction",
+		EntityID:   contradictionID,
+		Key:        "severity",
+		Value:      severity,
+		ValueType:  "string",
+		CreatedAt:  at,
+		UpdatedAt:  at,
+	}
+}
+
+func (s *Server) BatchSetMetadata(ctx context.Context, req *pb.BatchSetMetadataRequest) (*pb.BatchSetMetadataResponse, error) {
+	s.countOp("BatchSetMetadata")
+
+	if req.EntityType == "" || req.EntityId == "" {
+		return nil, status.Error(codes.InvalidArgument, "entity_type and entity_id are required")
+	}
+	if len(req.Attributes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "attributes are required")
+	}
+	checked := len(req.ExpectedVersions) > 0
+	for key := range req.Attributes {
+		if _, ok := req.ExpectedVersions[key]; checked && !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "expected_versions is missing attribute %q", key)
+		}
+	}
+
+	now := time.Now()
+	entries := make([]*metadata.MetadataEntry, 0, len(req.Attributes))
+	for key, value := range req.Attributes {
+		entries = append(entries, &metadata.MetadataEntry{
+			EntityType: req.EntityType,
+			EntityID:   req.EntityId,
+			Key:        key,
+			Value:      value,
+			ValueType:  req.ValueType,
+			Version:    req.ExpectedVersions[key],
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+
+	setBatch := s.metaStore.SetMetadataBatch
+	if checked {
+		setBatch = s.metaStore.CompareAndSetMetadata
+	}
+	if err := setBatch(entries); err != nil {
+		var verr *metadata.ValidationError
+		if errors.As(err, &verr) {
+			return nil, status.Error(codes.InvalidArgument, verr.Error())
+		}
+		if errors.Is(err, metadata.ErrVersionConflict) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to set metadata: %v", err)
+	}
+
+	versions := make(map[string]uint64, len(entries))
+	for _, entry := range entries {
+		versions[entry.Key] = entry.Version
+	}
+
+	return &pb.BatchSetMetadataResponse{
+		Success:  true,
+		Count:    int32(len(entries)),
+		Versions: versions,
+	}, nil
+}
+
 // ========== Prompt Operations ==========
 
 func (s *Server) StorePrompt(ctx context.Context, req *pb.StorePromptRequest) (*pb.StorePromptResponse, error) {
-	s.opCounts["StorePrompt"]++
+	s.countOp("StorePrompt")
 
 	if req.Prompt == nil {
 		return nil, status.Error(codes.InvalidArgument, "prompt is required")
@@ -712,7 +1402,7 @@ func (s *Server) StorePrompt(ctx context.Context, req *pb.StorePromptRequest) (*
 }
 
 func (s *Server) GetPrompt(ctx context.Context, req *pb.GetPromptRequest) (*pb.GetPromptResponse, error) {
-	s.opCounts["GetPrompt"]++
+	s.countOp("GetPrompt")
 
 	if req.PromptId == "" {
 		return nil, status.Error(codes.InvalidArgument, "prompt_id is required")
@@ -733,11 +1423,14 @@ func (s *Server) GetPrompt(ctx context.Context, req *pb.GetPromptRequest) (*pb.G
 }
 
 func (s *Server) RecordPromptUsage(ctx context.Context, req *pb.RecordPromptUsageRequest) (*pb.RecordPromptUsageResponse, error) {
-	s.opCounts["RecordPromptUsage"]++
+	s.countOp("RecordPromptUsage")
 
 	if req.Usage == nil {
 		return nil, status.Error(codes.InvalidArgument, "usage is required")
 	}
+	if err := s.recordExperimentUsage(req.Usage); err != nil {
+		return nil, err
+	}
 
 	entry := &metadata.MetadataEntry{
 		EntityType: "prompt_usage",
@@ -759,6 +1452,298 @@ func (s *Server) RecordPromptUsage(ctx context.Context, req *pb.RecordPromptUsag
 	}, nil
 }
 
+// ========== Conversation Operations ==========
+
+func (s *Server) GetMessagesPage(ctx context.Context, req *pb.GetMessagesPageRequest) (*pb.GetMessagesPageResponse, error) {
+	s.countOp("GetMessagesPage")
+
+	if req.ConversationId == "" {
+		return nil, status.Error(codes.InvalidArgument, "conversation_id is required")
+	}
+
+	cursor := prompt.MessageCursor{AfterMessageID: req.AfterMessageId}
+	if req.AfterTimestamp != nil {
+		cursor.AfterTimestamp = req.AfterTimestamp.AsTime()
+	}
+
+	page, err := s.promptStore.WithContext(ctx).GetMessagesPage(req.ConversationId, cursor, int(req.Limit))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to get messages: %v", err)
+	}
+
+	resp := &pb.GetMessagesPageResponse{
+		Messages: convert.MessagesToPb(page.Messages),
+		HasMore:  page.HasMore,
+	}
+	if page.HasMore && len(page.Messages) > 0 {
+		resp.NextCursor = page.Messages[len(page.Messages)-1].MessageID
+	}
+
+	return resp, nil
+}
+
+func (s *Server) GetRecentMessages(ctx context.Context, req *pb.GetRecentMessagesRequest) (*pb.GetRecentMessagesResponse, error) {
+	s.countOp("GetRecentMessages")
+
+	if req.ConversationId == "" || req.Count <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "conversation_id and a positive count are required")
+	}
+
+	messages, err := s.promptStore.WithContext(ctx).GetRecentMessages(req.ConversationId, int(req.Count))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get messages: %v", err)
+	}
+
+	return &pb.GetRecentMessagesResponse{Messages: convert.MessagesToPb(messages)}, nil
+}
+
+func (s *Server) SearchConversations(ctx context.Context, req *pb.SearchConversationsRequest) (*pb.SearchConversationsResponse, error) {
+	s.countOp("SearchConversations")
+
+	if req.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+
+	results, err := s.promptStore.WithContext(ctx).SearchConversations(req.UserId, req.Query, int(req.Limit))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "conversation search failed: %v", err)
+	}
+
+	pbResults := make([]*pb.ConversationSearchResult, len(results))
+	for i, r := range results {
+		pbResults[i] = &pb.ConversationSearchResult{
+			Conversation: convert.ConversationToPb(r.Conversation),
+			Score:        r.Score,
+			Matches:      convert.MessagesToPb(r.Matches),
+		}
+	}
+
+	return &pb.SearchConversationsResponse{Results: pbResults}, nil
+}
+
+func (s *Server) ExportConversation(ctx context.Context, req *pb.ExportConversationRequest) (*pb.ExportConversationResponse, error) {
+	s.countOp("ExportConversation")
+
+	if req.ConversationId == "" {
+		return nil, status.Error(codes.InvalidArgument, "conversation_id is required")
+	}
+
+	export, err := s.promptStore.WithContext(ctx).ExportConversation(req.ConversationId, prompt.ExportOptions{
+		Format:       req.Format,
+		OmitMetadata: req.OmitMetadata,
+	})
+	switch {
+	case errors.Is(err, prompt.ErrConversationNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, prompt.ErrUnsupportedFormat), errors.Is(err, prompt.ErrUnsupportedRole):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to export conversation: %v", err)
+	}
+
+	content, err := json.Marshal(export)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode export: %v", err)
+	}
+	return &pb.ExportConversationResponse{Content: string(content), MessageCount: int32(len(export.Messages))}, nil
+}
+
+func (s *Server) ListConversationsByUser(ctx context.Context, req *pb.ListConversationsByUserRequest) (*pb.ListConversationsByUserResponse, error) {
+	s.countOp("ListConversationsByUser")
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	page, err := s.promptStore.WithContext(ctx).ListConversationsByUserPage(req.UserId, req.AfterConversationId, int(req.Limit))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to list conversations: %v", err)
+	}
+
+	resp := &pb.ListConversationsByUserResponse{
+		Conversations: convert.ConversationsToPb(page.Conversations),
+		HasMore:       page.HasMore,
+	}
+	if page.HasMore && len(page.Conversations) > 0 {
+		resp.NextCursor = page.Conversations[len(page.Conversations)-1].ConversationID
+	}
+
+	return resp, nil
+}
+
+func (s *Server) GetUserConversationUsage(ctx context.Context, req *pb.GetUserConversationUsageRequest) (*pb.GetUserConversationUsageResponse, error) {
+	s.countOp("GetUserConversationUsage")
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	usage, err := s.promptStore.WithContext(ctx).GetUserUsage(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get usage: %v", err)
+	}
+
+	return &pb.GetUserConversationUsageResponse{
+		UserId:        usage.UserID,
+		Conversations: usage.Conversations,
+		Messages:      usage.Messages,
+		Quota:         convert.QuotaToPb(&usage.Quota),
+		CustomQuota:   usage.CustomQuota,
+	}, nil
+}
+
+func (s *Server) SetUserConversationQuota(ctx context.Context, req *pb.SetUserConversationQuotaRequest) (*pb.SetUserConversationQuotaResponse, error) {
+	s.countOp("SetUserConversationQuota")
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	if err := s.promptStore.SetUserQuota(req.UserId, convert.QuotaFromPb(req.Quota)); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to set quota: %v", err)
+	}
+
+	return &pb.SetUserConversationQuotaResponse{
+		Success: true,
+		Message: "Quota set successfully",
+	}, nil
+}
+
+// ========== Query Operations ==========
+
+func (s *Server) StreamQuery(req *pb.StreamQueryRequest, stream pb.TreeStoreService_StreamQueryServer) error {
+	s.countOp("StreamQuery")
+
+	q, err := query.ParseQuery(req.Query)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid query: %v", err)
+	}
+
+	cur, err := s.engine.WithContext(stream.Context()).Cursor(q)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "query failed: %v", err)
+	}
+	defer cur.Close()
+
+	for cur.Next() {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		if err := stream.Send(queryRowToPb(cur.Row())); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		if ctxErr := stream.Context().Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
+		return status.Errorf(codes.Internal, "query failed: %v", err)
+	}
+
+	return nil
+}
+
+// ========== Change Feed ==========
+
+func (s *Server) WatchChanges(req *pb.WatchChangesRequest, stream pb.TreeStoreService_WatchChangesServer) error {
+	s.countOp("WatchChanges")
+
+	sub := s.feed.Subscribe(req.Prefixes)
+	defer sub.Close()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case ev, ok := <-sub.Events():
+			if !ok {
+				if errors.Is(sub.Err(), changefeed.ErrLagged) {
+					return status.Error(codes.ResourceExhausted, "watcher fell behind the change feed; resubscribe")
+				}
+				return status.Error(codes.Unavailable, "change feed closed")
+			}
+			if err := stream.Send(changeEventToPb(ev)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ========== Replication ==========
+
+func (s *Server) StreamWAL(req *pb.StreamWALRequest, stream pb.TreeStoreService_StreamWALServer) error {
+	s.countOp("StreamWAL")
+
+	log := s.kv.WAL()
+	if log == nil {
+		return status.Error(codes.FailedPrecondition, "an in-memory database has no WAL to stream")
+	}
+	tailer, err := log.Tail(req.AfterLsn)
+	if errors.Is(err, wal.ErrLSNUnavailable) {
+		return status.Errorf(codes.FailedPrecondition,
+			"LSN %d is no longer in the WAL; reseed the follower from a copy of the database", req.AfterLsn+1)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to open WAL: %v", err)
+	}
+	defer tailer.Close()
+
+	for {
+		changed := log.Changed()
+		for {
+			entry, err := tailer.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return status.Errorf(codes.Internal, "failed to read WAL: %v", err)
+			}
+			if err := stream.Send(walEntryToPb(entry)); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-s.stopped:
+			return status.Error(codes.Unavailable, "server shutting down")
+		case <-changed:
+		}
+	}
+}
+
+// ========== Audit ==========
+
+// maxAuditQueryLimit caps the records one QueryAuditLog call returns
+const maxAuditQueryLimit = 1000
+
+func (s *Server) QueryAuditLog(ctx context.Context, req *pb.QueryAuditLogRequest) (*pb.QueryAuditLogResponse, error) {
+	s.countOp("QueryAuditLog")
+
+	if req.Limit < 0 || req.Limit > maxAuditQueryLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 0 and %d", maxAuditQueryLimit)
+	}
+	filter := audit.Filter{Actor: req.Actor, Limit: int(req.Limit)}
+	if req.StartTime != nil {
+		filter.Start = req.StartTime.AsTime()
+	}
+	if req.EndTime != nil {
+		filter.End = req.EndTime.AsTime()
+	}
+
+	records, err := s.auditLog.Query(filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to query audit log: %v", err)
+	}
+
+	resp := &pb.QueryAuditLogResponse{Records: make([]*pb.AuditRecord, len(records))}
+	for i, r := range records {
+		resp.Records[i] = auditRecordToPb(r)
+	}
+	return resp, nil
+}
+
 // ========== Health & Status ==========
 
 func (s *Server) Health(ctx context.Context, req *pb.HealthRequest) (*pb.HealthResponse, error) {
@@ -770,37 +1755,224 @@ func (s *Server) Health(ctx context.Context, req *pb.HealthRequest) (*pb.HealthR
 }
 
 func (s *Server) Stats(ctx context.Context, req *pb.StatsRequest) (*pb.StatsResponse, error) {
-	// Count nodes by scanning with PREFIX_NODE (2000)
-	nodeCount := int64(0)
-	nodePrefix := []byte{0x00, 0x00, 0x07, 0xD0} // PREFIX_NODE = 2000 encoded
-	s.kv.Scan(nodePrefix, func(key, val []byte) bool {
-		if len(key) >= 4 {
-			// Check if key starts with PREFIX_NODE
-			prefix := uint32(key[0])<<24 | uint32(key[1])<<16 | uint32(key[2])<<8 | uint32(key[3])
-			if prefix == 2000 {
-				nodeCount++
-			} else {
-				return false // Stop scanning when we hit different prefix
+	resp := &pb.StatsResponse{OperationCounts: s.operationCounts(), Approximate: req.Approximate}
+
+	// Count from the stored records, so the totals hold across restarts
+	if req.PolicyId == "" && !req.Approximate {
+		docCount, nodeCount, err := s.docStore.WithContext(ctx).Counts()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to count nodes: %v", err)
+		}
+		versionCount, err := s.verStore.WithContext(ctx).Count()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to count versions: %v", err)
+		}
+		resp.TotalDocuments, resp.TotalNodes, resp.TotalVersions = docCount, nodeCount, versionCount
+	} else {
+		var scope []storage.Value
+		if req.PolicyId != "" {
+			scope = []storage.Value{storage.NewBytesValue([]byte(req.PolicyId))}
+		}
+		var err error
+		resp.TotalNodes, resp.TotalNodesError, err = s.countKeys(ctx, storage.PrefixRange(document.PREFIX_NODE, scope), req.Approximate)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to count nodes: %v", err)
+		}
+		resp.TotalVersions, resp.TotalVersionsError, err = s.countKeys(ctx, storage.PrefixRange(version.PREFIX_VERSION, scope), req.Approximate)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to count versions: %v", err)
+		}
+
+		// Listing policies seeks past each one's nodes, so it stays cheap
+		if req.PolicyId != "" {
+			if resp.TotalNodes > 0 {
+				resp.TotalDocuments = 1
 			}
+		} else {
+			policies, err := s.docStore.WithContext(ctx).ListPolicies("", 0)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to count documents: %v", err)
+			}
+			resp.TotalDocuments = int64(len(policies))
 		}
-		return true
-	})
+	}
 
 	// Get database file size
-	var dbSize int64
 	if fileInfo, err := os.Stat(s.kv.Path); err == nil {
-		dbSize = fileInfo.Size()
+		resp.DbSizeBytes = fileInfo.Size()
 	}
 
-	// Estimate documents (rough estimate - 1 document per unique policy ID)
-	// For simplicity, we'll use opCounts["StoreDocument"]
-	docCount := s.opCounts["StoreDocument"]
+	sync := s.kv.SyncStats()
+	resp.SyncPolicy = sync.Policy.String()
+	resp.SyncIntervalMs = sync.Interval.Milliseconds()
+	resp.UnflushedCommits = int64(sync.Pending)
+	if sync.LastError != nil {
+		resp.LastFlushError = sync.LastError.Error()
+	}
+	return resp, nil
+}
 
-	return &pb.StatsResponse{
-		TotalDocuments:  docCount,
-		TotalNodes:      nodeCount,
-		TotalVersions:   0, // Would need to scan version keys
-		DbSizeBytes:     dbSize,
-		OperationCounts: s.opCounts,
-	}, nil
+// countKeys counts the keys in r by scanning them or, when approximate, from a
+// sample of the leaves holding them, returning the estimate's 95% error bound
+func (s *Server) countKeys(ctx context.Context, r storage.KeyRange, approximate bool) (count, bound int64, err error) {
+	if approximate {
+		estimate, err := s.kv.EstimateKeys(r)
+		return estimate.Keys, estimate.Error, err
+	}
+	err = storage.WithContext(ctx, s.kv).Scan(r.Start, func(key, val []byte) bool {
+		if bytes.Compare(key, r.End) >= 0 {
+			return false
+		}
+		count++
+		return true
+	})
+	return count, 0, err
+}
+
+// countOp records one call of an RPC; handlers run concurrently
+func (s *Server) countOp(name string) {
+	s.opMu.Lock()
+	s.opCounts[name]++
+	s.opMu.Unlock()
+}
+
+// operationCounts returns a snapshot of the per-RPC call counts
+func (s *Server) operationCounts() map[string]int64 {
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+
+	counts := make(map[string]int64, len(s.opCounts))
+	for name, n := range s.opCounts {
+		counts[name] = n
+	}
+	return counts
+}
+
+// ========== Helpers ==========
+
+// searchFilter converts a protobuf search filter to its document form
+// Metadata criteria are checked against the node's metadata entries
+func (s *Server) searchFilter(f *pb.SearchFilter) document.SearchFilter {
+	if f == nil {
+		return document.SearchFilter{}
+	}
+
+	filter := document.SearchFilter{
+		PageFrom:          int(f.PageFrom),
+		PageTo:            int(f.PageTo),
+		SectionPathPrefix: f.SectionPathPrefix,
+	}
+	if f.MaxDepth != nil {
+		maxDepth := int(*f.MaxDepth)
+		filter.MaxDepth = &maxDepth
+	}
+
+	if len(f.Metadata) > 0 {
+		required := f.Metadata
+		filter.Match = func(node *document.Node) bool {
+			attrs, err := s.metaStore.GetAllMetadata("node", node.NodeID)
+			if err != nil {
+				return false
+			}
+			for key, value := range required {
+				if attrs[key] != value {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	return filter
+}
+
+// queryRowToPb converts a cursor row to its protobuf form
+func queryRowToPb(row query.Row) *pb.QueryRow {
+	switch {
+	case row.Document != nil:
+		return &pb.QueryRow{Row: &pb.QueryRow_Node{Node: convert.NodeToPb(row.Document)}}
+	case row.Version != nil:
+		return &pb.QueryRow{Row: &pb.QueryRow_Version{Version: convert.VersionToPb(row.Version)}}
+	case row.Metadata != nil:
+		return &pb.QueryRow{Row: &pb.QueryRow_Metadata{Metadata: convert.MetadataEntryToPb(row.Metadata)}}
+	case row.Conversation != nil:
+		return &pb.QueryRow{Row: &pb.QueryRow_Conversation{Conversation: convert.ConversationToPb(row.Conversation)}}
+	case row.Group != nil:
+		return &pb.QueryRow{Row: &pb.QueryRow_Group{Group: queryGroupToPb(row.Group)}}
+	}
+	return &pb.QueryRow{}
+}
+
+// queryGroupToPb converts a group of an aggregated query to its protobuf form
+func queryGroupToPb(g *query.Group) *pb.QueryGroup {
+	key := make([]string, len(g.Key))
+	for i, v := range g.Key {
+		switch v := v.(type) {
+		case nil:
+		case time.Time:
+			key[i] = v.Format(time.RFC3339)
+		default:
+			key[i] = fmt.Sprint(v)
+		}
+	}
+	return &pb.QueryGroup{Key: key, Count: int64(g.Count), Values: g.Values}
 }
+
+// changeEventToPb converts a change feed event to its protobuf form
+func changeEventToPb(ev changefeed.Event) *pb.ChangeEvent {
+	return &pb.ChangeEvent{
+		Seq:       ev.Seq,
+		Entity:    ev.Entity,
+		Op:        string(ev.Op),
+		PolicyId:  ev.PolicyID,
+		EntityId:  ev.EntityID,
+		Timestamp: timestamppb.New(ev.Timestamp),
+		Topic:     ev.Topic(),
+	}
+}
+
+// auditRecordToPb converts an audit record to its protobuf form
+func auditRecordToPb(r *audit.Record) *pb.AuditRecord {
+	return &pb.AuditRecord{
+		Time:        timestamppb.New(r.Time),
+		Seq:         r.Seq,
+		Method:      r.Method,
+		Actor:       r.Actor,
+		Entities:    r.Entities,
+		RequestHash: r.RequestHash,
+		Code:        r.Code,
+		Error:       r.Error,
+		RequestId:   r.RequestID,
+	}
+}
+
+// walEntryToPb converts a WAL entry to its protobuf form
+func walEntryToPb(entry *wal.Entry) *pb.WALEntry {
+	return &pb.WALEntry{
+		Lsn:       entry.LSN,
+		TxnId:     entry.TxnID,
+		Op:        uint32(entry.OpType),
+		Key:       entry.Key,
+		Value:     entry.Value,
+		Timestamp: timestamppb.New(entry.Timestamp),
+	}
+}
+
+// highlightsToPb converts snippet highlights to their protobuf form
+func highlightsToPb(highlights []document.Highlight) []*pb.Highlight {
+	pbHighlights := make([]*pb.Highlight, len(highlights))
+	for i, h := range highlights {
+		pbHighlights[i] = &pb.Highlight{Start: int32(h.Start), End: int32(h.End)}
+	}
+	return pbHighlights
+}
+
+// contextEntriesToPb converts context window outline entries to their protobuf form
+func contextEntriesToPb(entries []document.ContextEntry) []*pb.ContextEntry {
+	pbEntries := make([]*pb.ContextEntry, len(entries))
+	for i, e := range entries {
+		pbEntries[i] = &pb.ContextEntry{NodeId: e.NodeID, Title: e.Title, Summary: e.Summary}
+	}
+	return pbEntries
+}
+
@@ -3,16 +3,29 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/nainya/treestore/pkg/canary"
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/metadata"
+	"github.com/nainya/treestore/pkg/prompt"
+	"github.com/nainya/treestore/pkg/version"
 	pb "github.com/nainya/treestore/proto"
 )
 
@@ -146,6 +159,129 @@ func TestStoreAndGetDocument(t *testing.T) {
 	}
 }
 
+func TestGetDocumentMultipleRoots(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store := func(nodeIDs ...string) {
+		var nodes []*pb.Node
+		for _, id := range nodeIDs {
+			nodes = append(nodes, &pb.Node{NodeId: id, PolicyId: "TEST-ROOTS", Title: id})
+		}
+		if _, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{Document: &pb.Document{PolicyId: "TEST-ROOTS"}, Nodes: nodes}); err != nil {
+			t.Fatalf("StoreDocument failed: %v", err)
+		}
+	}
+
+	store("root-b")
+	resp, err := client.GetDocument(ctx, &pb.GetDocumentRequest{PolicyId: "TEST-ROOTS"})
+	if err != nil || resp.Document.RootNodeId != "root-b" {
+		t.Fatalf("Expected root-b, got %v, %v", resp, err)
+	}
+
+	store("root-a")
+	_, err = client.GetDocument(ctx, &pb.GetDocumentRequest{PolicyId: "TEST-ROOTS"})
+	if status.Code(err) != codes.FailedPrecondition || !strings.Contains(err.Error(), "root-a, root-b") {
+		t.Errorf("Expected FailedPrecondition naming both roots, got %v", err)
+	}
+	if _, err := client.GetDocument(ctx, &pb.GetDocumentRequest{PolicyId: "TEST-NONE"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+}
+
+func TestBatchGetAncestorPaths(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	nodes := []*pb.Node{
+		{NodeId: "root", PolicyId: "TEST-PATHS", Title: "Policy"},
+		{NodeId: "sec1", PolicyId: "TEST-PATHS", ParentId: "root", Title: "Indications", Text: "Covered"},
+		{NodeId: "sec1a", PolicyId: "TEST-PATHS", ParentId: "sec1", Title: "Imaging"},
+	}
+	if _, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{Document: &pb.Document{PolicyId: "TEST-PATHS"}, Nodes: nodes}); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	resp, err := client.BatchGetAncestorPaths(ctx, &pb.BatchGetAncestorPathsRequest{
+		PolicyId: "TEST-PATHS", NodeIds: []string{"sec1a", "missing", "root"}, Fields: []string{"title"},
+	})
+	if err != nil || len(resp.Paths) != 3 {
+		t.Fatalf("BatchGetAncestorPaths = %v, %v", resp, err)
+	}
+	if p := resp.Paths[0]; !p.Found || len(p.Ancestors) != 3 || p.Ancestors[1].Title != "Indications" || p.Ancestors[1].Text != "" {
+		t.Errorf("Unexpected path of sec1a: %v", p)
+	}
+	if p := resp.Paths[1]; p.Found || p.NodeId != "missing" || len(p.Ancestors) != 0 {
+		t.Errorf("Expected missing not found, got %v", p)
+	}
+	if p := resp.Paths[2]; !p.Found || len(p.Ancestors) != 1 {
+		t.Errorf("Unexpected path of root: %v", p)
+	}
+}
+
+func TestSectionNavigation(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	root, sec1 := "root", "sec1"
+	nodes := []*pb.Node{
+		{NodeId: root, PolicyId: "TEST-NAV", Title: "Policy", SectionPath: "1"},
+		{NodeId: "sec2", PolicyId: "TEST-NAV", ParentId: root, Title: "Limitations", SectionPath: "1.2", Text: "Twice a year"},
+		{NodeId: sec1, PolicyId: "TEST-NAV", ParentId: root, Title: "Indications", SectionPath: "1.1"},
+		{NodeId: "sec1a", PolicyId: "TEST-NAV", ParentId: sec1, Title: "Imaging", SectionPath: "1.1.1"},
+	}
+	if _, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{Document: &pb.Document{PolicyId: "TEST-NAV"}, Nodes: nodes}); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	siblings, err := client.GetSiblings(ctx, &pb.GetSiblingsRequest{PolicyId: "TEST-NAV", NodeId: "sec2", Fields: []string{"title"}})
+	if err != nil || len(siblings.Siblings) != 2 || siblings.Siblings[0].NodeId != sec1 || siblings.Position != 1 {
+		t.Fatalf("Expected sec1 then sec2 at 1, got %v, %v", siblings, err)
+	}
+	if siblings.Siblings[1].Text != "" {
+		t.Error("Expected text left out")
+	}
+
+	next, err := client.GetNextSection(ctx, &pb.AdjacentSectionRequest{PolicyId: "TEST-NAV", NodeId: "sec1a"})
+	if err != nil || next.Node.GetNodeId() != "sec2" || next.Node.Text != "Twice a year" {
+		t.Errorf("Expected sec2 after sec1a, got %v, %v", next, err)
+	}
+	if end, err := client.GetNextSection(ctx, &pb.AdjacentSectionRequest{PolicyId: "TEST-NAV", NodeId: "sec2"}); err != nil || end.Node != nil {
+		t.Errorf("Expected no section after sec2, got %v, %v", end, err)
+	}
+	if prev, err := client.GetPreviousSection(ctx, &pb.AdjacentSectionRequest{PolicyId: "TEST-NAV", NodeId: "sec2"}); err != nil || prev.Node.GetNodeId() != "sec1a" {
+		t.Errorf("Expected sec1a before sec2, got %v, %v", prev, err)
+	}
+	if _, err := client.GetPreviousSection(ctx, &pb.AdjacentSectionRequest{PolicyId: "TEST-NAV", NodeId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+
+	stream, err := client.GetReadingOrder(ctx, &pb.GetReadingOrderRequest{PolicyId: "TEST-NAV", Fields: []string{"title"}})
+	if err != nil {
+		t.Fatalf("GetReadingOrder failed: %v", err)
+	}
+	var read []string
+	for {
+		node, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if node.Text != "" {
+			t.Errorf("Expected text left out of %s", node.NodeId)
+		}
+		read = append(read, node.NodeId)
+	}
+	if strings.Join(read, ",") != "root,sec1,sec1a,sec2" {
+		t.Errorf("Unexpected reading order %v", read)
+	}
+}
+
 func TestGetNode(t *testing.T) {
 	_, client, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -202,6 +338,52 @@ func TestGetNode(t *testing.T) {
 	}
 }
 
+func TestUpdateNodeVersionConflict(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := timestamppb.Now()
+
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "TEST-OCC", VersionId: "v1.0", RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+		Nodes:    []*pb.Node{{NodeId: "root", PolicyId: "TEST-OCC", Title: "Root Node", CreatedAt: now, UpdatedAt: now}},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	getResp, err := client.GetNode(ctx, &pb.GetNodeRequest{PolicyId: "TEST-OCC", NodeId: "root"})
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if getResp.Node.Version != 1 {
+		t.Fatalf("Expected version 1, got %d", getResp.Node.Version)
+	}
+
+	getResp.Node.Title = "Renamed"
+	updResp, err := client.UpdateNode(ctx, &pb.UpdateNodeRequest{Node: getResp.Node})
+	if err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if updResp.Node.Version != 2 || updResp.Node.Title != "Renamed" {
+		t.Errorf("Unexpected updated node: %+v", updResp.Node)
+	}
+
+	// Retrying with the version we read is now stale
+	getResp.Node.Title = "Lost"
+	if _, err := client.UpdateNode(ctx, &pb.UpdateNodeRequest{Node: getResp.Node}); status.Code(err) != codes.Aborted {
+		t.Errorf("Expected Aborted for stale version, got %v", err)
+	}
+
+	if _, err := client.UpdateNode(ctx, &pb.UpdateNodeRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for missing node, got %v", err)
+	}
+	if _, err := client.UpdateNode(ctx, &pb.UpdateNodeRequest{Node: &pb.Node{PolicyId: "TEST-OCC", NodeId: "nope", Version: 1}}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for unknown node, got %v", err)
+	}
+}
+
 func TestGetChildren(t *testing.T) {
 	_, client, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -368,213 +550,1107 @@ func TestSearch(t *testing.T) {
 	if !foundDiabetes {
 		t.Error("Expected to find diabetes-related results")
 	}
+
+	// Results carry a highlighted snippet
+	for _, result := range searchResp.Results {
+		if result.Snippet == "" || len(result.Highlights) == 0 {
+			t.Errorf("Expected snippet with highlights for %s", result.Node.NodeId)
+			continue
+		}
+		h := result.Highlights[0]
+		if got := strings.ToLower(result.Snippet[h.Start:h.End]); got != "diabetes" {
+			t.Errorf("Expected highlight on 'diabetes', got %q", got)
+		}
+	}
 }
 
-func TestGetSubtree(t *testing.T) {
+func TestGetContextWindow(t *testing.T) {
 	_, client, cleanup := setupTestServer(t)
 	defer cleanup()
 
 	ctx := context.Background()
 	now := timestamppb.Now()
 
-	// Store multi-level hierarchy
-	doc := &pb.Document{
-		PolicyId:   "TEST-005",
-		VersionId:  "v1.0",
-		RootNodeId: "root",
-		CreatedAt:  now,
-		UpdatedAt:  now,
-	}
-
-	nodes := []*pb.Node{
-		{
-			NodeId:    "root",
-			PolicyId:  "TEST-005",
-			Title:     "Root",
-			Depth:     0,
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
-		{
-			NodeId:    "level1",
-			PolicyId:  "TEST-005",
-			ParentId:  "root",
-			Title:     "Level 1",
-			Depth:     1,
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
-		{
-			NodeId:    "level2",
-			PolicyId:  "TEST-005",
-			ParentId:  "level1",
-			Title:     "Level 2",
-			Depth:     2,
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
-	}
-
 	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
-		Document: doc,
-		Nodes:    nodes,
+		Document: &pb.Document{PolicyId: "CTX-001", RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "CTX-001", Title: "Policy", CreatedAt: now, UpdatedAt: now},
+			{NodeId: "a", PolicyId: "CTX-001", ParentId: "root", Title: "Section A", Summary: "About A", Text: "Body of section A", Depth: 1, CreatedAt: now, UpdatedAt: now},
+			{NodeId: "b", PolicyId: "CTX-001", ParentId: "root", Title: "Section B", Summary: "About B", Depth: 1, CreatedAt: now, UpdatedAt: now},
+		},
 	})
 	if err != nil {
 		t.Fatalf("StoreDocument failed: %v", err)
 	}
 
-	// Get subtree from root
-	subtreeReq := &pb.GetSubtreeRequest{
-		PolicyId: "TEST-005",
-		NodeId:   "root",
-		MaxDepth: 0, // Unlimited
-	}
-
-	subtreeResp, err := client.GetSubtree(ctx, subtreeReq)
+	resp, err := client.GetContextWindow(ctx, &pb.GetContextWindowRequest{PolicyId: "CTX-001", NodeId: "a"})
 	if err != nil {
-		t.Fatalf("GetSubtree failed: %v", err)
+		t.Fatalf("GetContextWindow failed: %v", err)
 	}
 
-	if len(subtreeResp.Nodes) != 3 {
-		t.Errorf("Expected 3 nodes in subtree, got %d", len(subtreeResp.Nodes))
+	if resp.Node.Text != "Body of section A" {
+		t.Errorf("Expected node text, got %q", resp.Node.Text)
 	}
-
-	// Get subtree with max_depth=1
-	subtreeReq.MaxDepth = 1
-	subtreeResp, err = client.GetSubtree(ctx, subtreeReq)
-	if err != nil {
-		t.Fatalf("GetSubtree with max_depth failed: %v", err)
+	if len(resp.Ancestors) != 1 || resp.Ancestors[0].Title != "Policy" {
+		t.Errorf("Expected root ancestor, got %v", resp.Ancestors)
+	}
+	if len(resp.Siblings) != 1 || resp.Siblings[0].Summary != "About B" {
+		t.Errorf("Expected sibling B, got %v", resp.Siblings)
 	}
 
-	if len(subtreeResp.Nodes) != 2 {
-		t.Errorf("Expected 2 nodes with max_depth=1, got %d", len(subtreeResp.Nodes))
+	_, err = client.GetContextWindow(ctx, &pb.GetContextWindowRequest{PolicyId: "CTX-001", NodeId: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
 	}
 }
 
-func TestGetAncestorPath(t *testing.T) {
-	_, client, cleanup := setupTestServer(t)
+func TestMessagePagination(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
 	defer cleanup()
 
 	ctx := context.Background()
-	now := timestamppb.Now()
-
-	// Store multi-level hierarchy
-	doc := &pb.Document{
-		PolicyId:   "TEST-006",
-		VersionId:  "v1.0",
-		RootNodeId: "root",
-		CreatedAt:  now,
-		UpdatedAt:  now,
+	base := time.Unix(1700000000, 0)
+
+	if err := server.promptStore.CreateConversation(&prompt.Conversation{
+		ConversationID: "conv-page",
+		UserID:         "user1",
+		StartedAt:      base,
+	}); err != nil {
+		t.Fatalf("CreateConversation failed: %v", err)
+	}
+	for i := 0; i < 7; i++ {
+		if err := server.promptStore.AddMessage(&prompt.Message{
+			MessageID:      fmt.Sprintf("m%02d", i),
+			ConversationID: "conv-page",
+			Role:           "user",
+			Content:        fmt.Sprintf("message %d", i),
+			Timestamp:      base.Add(time.Duration(i) * time.Second),
+		}); err != nil {
+			t.Fatalf("AddMessage failed: %v", err)
+		}
 	}
 
-	nodes := []*pb.Node{
-		{
-			NodeId:    "root",
-			PolicyId:  "TEST-006",
-			Title:     "Root",
-			Depth:     0,
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
-		{
-			NodeId:    "parent",
-			PolicyId:  "TEST-006",
-			ParentId:  "root",
-			Title:     "Parent",
-			Depth:     1,
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
-		{
-			NodeId:    "child",
-			PolicyId:  "TEST-006",
-			ParentId:  "parent",
-			Title:     "Child",
-			Depth:     2,
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
+	page, err := client.GetMessagesPage(ctx, &pb.GetMessagesPageRequest{ConversationId: "conv-page", Limit: 4})
+	if err != nil {
+		t.Fatalf("GetMessagesPage failed: %v", err)
+	}
+	if len(page.Messages) != 4 || !page.HasMore || page.NextCursor != "m03" {
+		t.Fatalf("Unexpected first page: %d messages, has_more=%v, cursor=%q", len(page.Messages), page.HasMore, page.NextCursor)
 	}
 
-	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
-		Document: doc,
-		Nodes:    nodes,
+	page, err = client.GetMessagesPage(ctx, &pb.GetMessagesPageRequest{
+		ConversationId: "conv-page",
+		AfterMessageId: page.NextCursor,
+		Limit:          4,
 	})
 	if err != nil {
-		t.Fatalf("StoreDocument failed: %v", err)
+		t.Fatalf("GetMessagesPage failed: %v", err)
 	}
-
-	// Get ancestor path for deepest child
-	pathReq := &pb.GetAncestorPathRequest{
-		PolicyId: "TEST-006",
-		NodeId:   "child",
+	if len(page.Messages) != 3 || page.HasMore || page.Messages[0].MessageId != "m04" {
+		t.Errorf("Unexpected second page: %v", page.Messages)
 	}
 
-	pathResp, err := client.GetAncestorPath(ctx, pathReq)
+	recent, err := client.GetRecentMessages(ctx, &pb.GetRecentMessagesRequest{ConversationId: "conv-page", Count: 2})
 	if err != nil {
-		t.Fatalf("GetAncestorPath failed: %v", err)
+		t.Fatalf("GetRecentMessages failed: %v", err)
 	}
-
-	if len(pathResp.Ancestors) != 3 {
-		t.Errorf("Expected 3 ancestors (root, parent, child), got %d", len(pathResp.Ancestors))
+	if len(recent.Messages) != 2 || recent.Messages[0].MessageId != "m05" || recent.Messages[1].MessageId != "m06" {
+		t.Errorf("Expected m05, m06; got %v", recent.Messages)
 	}
 
-	// Verify order: root -> parent -> child
-	expectedOrder := []string{"root", "parent", "child"}
-	for i, expected := range expectedOrder {
-		if pathResp.Ancestors[i].NodeId != expected {
-			t.Errorf("Expected ancestor[%d]=%s, got %s", i, expected, pathResp.Ancestors[i].NodeId)
-		}
+	if _, err := client.GetRecentMessages(ctx, &pb.GetRecentMessagesRequest{ConversationId: "conv-page"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for zero count, got %v", err)
 	}
 }
 
-func TestHealth(t *testing.T) {
-	_, client, cleanup := setupTestServer(t)
+func TestCrossReferencesAndJoin(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
 	defer cleanup()
 
 	ctx := context.Background()
+	now := time.Now()
 
-	req := &pb.HealthRequest{}
-	resp, err := client.Health(ctx, req)
-	if err != nil {
-		t.Fatalf("Health check failed: %v", err)
+	for _, policyID := range []string{"POL-P", "POL-Q"} {
+		nodes := []*document.Node{
+			{NodeID: policyID + "-1", PolicyID: policyID, Title: "One", PageStart: 1, CreatedAt: now, UpdatedAt: now},
+			{NodeID: policyID + "-2", PolicyID: policyID, Title: "Two", PageStart: 2, CreatedAt: now, UpdatedAt: now},
+		}
+		if err := server.docStore.StoreDocument(&document.Document{PolicyID: policyID}, nodes); err != nil {
+			t.Fatalf("StoreDocument failed: %v", err)
+		}
+	}
+	for _, nodeID := range []string{"POL-P-1", "POL-P-2"} {
+		if err := server.metaStore.SetMetadata(&metadata.MetadataEntry{
+			EntityType: "node", EntityID: nodeID, Key: "status", Value: "active", ValueType: "string", CreatedAt: now, UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("SetMetadata failed: %v", err)
+		}
 	}
 
-	if !resp.Healthy {
-		t.Error("Expected healthy=true")
+	if _, err := client.StoreCrossReference(ctx, &pb.StoreCrossReferenceRequest{CrossReference: &pb.CrossReference{
+		SourcePolicyId: "POL-P", SourceNodeId: "POL-P-2", TargetPolicyId: "POL-Q", TargetNodeId: "POL-Q-1",
+		ReferenceType: "cites", Context: "see Q section 1", CreatedAt: timestamppb.New(now),
+	}}); err != nil {
+		t.Fatalf("StoreCrossReference failed: %v", err)
+	}
+	if _, err := client.StoreCrossReference(ctx, &pb.StoreCrossReferenceRequest{CrossReference: &pb.CrossReference{SourcePolicyId: "POL-P"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for incomplete reference, got %v", err)
 	}
 
-	if resp.Version == "" {
-		t.Error("Expected non-empty version")
+	refs, err := client.GetCrossReferences(ctx, &pb.GetCrossReferencesRequest{PolicyId: "POL-Q", NodeId: "POL-Q-1"})
+	if err != nil {
+		t.Fatalf("GetCrossReferences failed: %v", err)
+	}
+	if len(refs.References) != 1 || refs.References[0].SourceNodeId != "POL-P-2" || refs.References[0].Context != "see Q section 1" {
+		t.Errorf("Unexpected references: %v", refs.References)
 	}
 
-	if resp.UptimeSeconds < 0 {
-		t.Errorf("Expected non-negative uptime, got %d", resp.UptimeSeconds)
+	resp, err := client.JoinNodes(ctx, &pb.JoinNodesRequest{
+		PolicyId:     "POL-P",
+		Metadata:     map[string]string{"status": "active"},
+		ReferencesTo: "POL-Q",
+	})
+	if err != nil {
+		t.Fatalf("JoinNodes failed: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Node.NodeId != "POL-P-2" {
+		t.Fatalf("Expected [POL-P-2], got %v", resp.Results)
+	}
+	if resp.Results[0].Metadata["status"] != "active" || len(resp.Results[0].References) != 1 {
+		t.Errorf("Unexpected joined data: %v", resp.Results[0])
+	}
+
+	if _, err := client.JoinNodes(ctx, &pb.JoinNodesRequest{PolicyId: "POL-P"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without conditions, got %v", err)
 	}
 }
 
-func TestStats(t *testing.T) {
-	_, client, cleanup := setupTestServer(t)
+func TestStreamQuery(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
 	defer cleanup()
 
 	ctx := context.Background()
+	now := time.Now()
 
-	// First store some data
-	now := timestamppb.Now()
-	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
-		Document: &pb.Document{
-			PolicyId:   "TEST-007",
-			RootNodeId: "root",
-			CreatedAt:  now,
-			UpdatedAt:  now,
-		},
-		Nodes: []*pb.Node{
-			{
-				NodeId:    "root",
-				PolicyId:  "TEST-007",
-				Title:     "Test",
-				CreatedAt: now,
-				UpdatedAt: now,
+	nodes := make([]*document.Node, 3)
+	for i := range nodes {
+		nodes[i] = &document.Node{NodeID: fmt.Sprintf("POL-S-%d", i+1), PolicyID: "POL-S", Title: "Node", PageStart: i + 1, CreatedAt: now, UpdatedAt: now}
+	}
+	if err := server.docStore.StoreDocument(&document.Document{PolicyID: "POL-S"}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	if err := server.metaStore.SetMetadata(&metadata.MetadataEntry{
+		EntityType: "node", EntityID: "POL-S-1", Key: "status", Value: "active", ValueType: "string", CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+
+	collect := func(q string) ([]*pb.QueryRow, error) {
+		stream, err := client.StreamQuery(ctx, &pb.StreamQueryRequest{Query: q})
+		if err != nil {
+			return nil, err
+		}
+		var rows []*pb.QueryRow
+		for {
+			row, err := stream.Recv()
+			if err == io.EOF {
+				return rows, nil
+			}
+			if err != nil {
+				return rows, err
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	rows, err := collect("FROM documents WHERE policyID='POL-S' AND pageStart>=2 ORDER BY pageStart DESC")
+	if err != nil {
+		t.Fatalf("StreamQuery failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0].GetNode().GetNodeId() != "POL-S-3" || rows[1].GetNode().GetNodeId() != "POL-S-2" {
+		t.Errorf("Unexpected document rows: %v", rows)
+	}
+
+	rows, err = collect(`{"from": "metadata", "where": {"key": "status", "value": "active"}}`)
+	if err != nil {
+		t.Fatalf("StreamQuery failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0].GetMetadata().GetEntityId() != "POL-S-1" {
+		t.Errorf("Unexpected metadata rows: %v", rows)
+	}
+
+	rows, err = collect("SELECT count, max(pageStart) FROM documents WHERE policyID='POL-S' GROUP BY metadata.status")
+	if err != nil {
+		t.Fatalf("StreamQuery failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0].GetGroup().GetKey()[0] != "" || rows[0].GetGroup().GetCount() != 2 ||
+		rows[0].GetGroup().GetValues()["max(pageStart)"] != 3 || rows[1].GetGroup().GetKey()[0] != "active" {
+		t.Errorf("Unexpected group rows: %v", rows)
+	}
+
+	if _, err := collect("FROM nowhere"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for bad query, got %v", err)
+	}
+	if _, err := collect("FROM documents WHERE nodeID='POL-S-1'"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without policyID, got %v", err)
+	}
+}
+
+func TestWatchChanges(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchChanges(ctx, &pb.WatchChangesRequest{Prefixes: []string{"version/POL-W", "metadata/node/"}})
+	if err != nil {
+		t.Fatalf("WatchChanges failed: %v", err)
+	}
+	for server.feed.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	now := time.Now()
+	if err := server.docStore.StoreDocument(&document.Document{PolicyID: "POL-W"}, []*document.Node{
+		{NodeID: "n1", PolicyID: "POL-W", Title: "Filtered out", CreatedAt: now, UpdatedAt: now},
+	}); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	if err := server.verStore.CreateVersion(&version.Version{PolicyID: "POL-W", VersionID: "v1", CreatedAt: now}); err != nil {
+		t.Fatalf("CreateVersion failed: %v", err)
+	}
+	if _, err := client.TagVersion(ctx, &pb.TagVersionRequest{PolicyId: "POL-W", VersionId: "v1", Tag: "approved"}); err != nil {
+		t.Fatalf("TagVersion failed: %v", err)
+	}
+	if _, err := client.BatchSetMetadata(ctx, &pb.BatchSetMetadataRequest{
+		EntityType: "node",
+		EntityId:   "n1",
+		Attributes: map[string]string{"status": "active", "owner": "legal"},
+	}); err != nil {
+		t.Fatalf("BatchSetMetadata failed: %v", err)
+	}
+
+	var topics []string
+	for i := 0; i < 3; i++ {
+		ev, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		topics = append(topics, ev.Op+" "+ev.Topic)
+	}
+	want := "[put version/POL-W/v1 put version/POL-W/v1 put metadata/node/n1]"
+	if fmt.Sprint(topics) != want {
+		t.Errorf("Events = %v, want %v", topics, want)
+	}
+
+	server.StopWatches()
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable after StopWatches, got %v", err)
+	}
+}
+
+func TestBatchGetVersionsAsOf(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	base := time.Unix(1700000000, 0)
+
+	for i, policyID := range []string{"POL-A", "POL-B"} {
+		for j := 0; j < 2; j++ {
+			if err := server.verStore.CreateVersion(&version.Version{
+				PolicyID:  policyID,
+				VersionID: fmt.Sprintf("v%d", j),
+				CreatedAt: base.Add(time.Duration(i+2*j) * time.Hour),
+			}); err != nil {
+				t.Fatalf("CreateVersion failed: %v", err)
+			}
+		}
+	}
+
+	resp, err := client.BatchGetVersionsAsOf(ctx, &pb.BatchGetVersionsAsOfRequest{
+		PolicyIds: []string{"POL-A", "POL-B", "POL-C"},
+		AsOfTime:  timestamppb.New(base.Add(2 * time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("BatchGetVersionsAsOf failed: %v", err)
+	}
+
+	if resp.Versions["POL-A"].GetVersionId() != "v1" || resp.Versions["POL-B"].GetVersionId() != "v0" {
+		t.Errorf("Unexpected versions: %v", resp.Versions)
+	}
+	if got := resp.Versions["POL-A"].GetEffectiveFrom().AsTime(); !got.Equal(base.Add(2 * time.Hour)) {
+		t.Errorf("Expected effective_from to default to created_at, got %s", got)
+	}
+	if resp.Versions["POL-A"].EffectiveTo != nil {
+		t.Errorf("Expected unset effective_to, got %v", resp.Versions["POL-A"].EffectiveTo)
+	}
+	if len(resp.MissingPolicyIds) != 1 || resp.MissingPolicyIds[0] != "POL-C" {
+		t.Errorf("Expected POL-C missing, got %v", resp.MissingPolicyIds)
+	}
+
+	if _, err := client.BatchGetVersionsAsOf(ctx, &pb.BatchGetVersionsAsOfRequest{PolicyIds: []string{"POL-A"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without as_of_time, got %v", err)
+	}
+}
+
+func TestVersionRetirement(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	base := time.Unix(1700000000, 0)
+
+	for i := 0; i < 4; i++ {
+		if err := server.verStore.CreateVersion(&version.Version{
+			PolicyID:  "POL-V",
+			VersionID: fmt.Sprintf("v%d", i),
+			CreatedAt: base.Add(time.Duration(i) * time.Hour),
+		}); err != nil {
+			t.Fatalf("CreateVersion failed: %v", err)
+		}
+	}
+
+	resp, err := client.PruneVersions(ctx, &pb.PruneVersionsRequest{PolicyId: "POL-V", KeepLast: 2})
+	if err != nil {
+		t.Fatalf("PruneVersions failed: %v", err)
+	}
+	if len(resp.PrunedVersionIds) != 2 || resp.PrunedVersionIds[0] != "v0" {
+		t.Errorf("Expected v0, v1 pruned, got %v", resp.PrunedVersionIds)
+	}
+
+	if _, err := client.DeleteVersion(ctx, &pb.DeleteVersionRequest{PolicyId: "POL-V", VersionId: "v3"}); err != nil {
+		t.Fatalf("DeleteVersion failed: %v", err)
+	}
+	latest, err := server.verStore.GetLatestVersion("POL-V")
+	if err != nil || latest.VersionID != "v2" {
+		t.Errorf("Expected latest v2, got %v (%v)", latest, err)
+	}
+
+	if _, err := client.DeleteVersion(ctx, &pb.DeleteVersionRequest{PolicyId: "POL-V", VersionId: "v0"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for pruned version, got %v", err)
+	}
+
+	if _, err := client.TagVersion(ctx, &pb.TagVersionRequest{PolicyId: "POL-V", VersionId: "v2", Tag: "approved", Unique: true}); err != nil {
+		t.Fatalf("TagVersion failed: %v", err)
+	}
+	if v, err := server.verStore.GetVersionByTag("POL-V", "approved"); err != nil || v.VersionID != "v2" {
+		t.Errorf("Expected approved on v2, got %v (%v)", v, err)
+	}
+	if _, err := client.UntagVersion(ctx, &pb.UntagVersionRequest{PolicyId: "POL-V", VersionId: "v2", Tag: "approved"}); err != nil {
+		t.Fatalf("UntagVersion failed: %v", err)
+	}
+	if _, err := client.TagVersion(ctx, &pb.TagVersionRequest{PolicyId: "POL-V", VersionId: "v2"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for missing tag, got %v", err)
+	}
+}
+
+func TestBatchSetMetadata(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	resp, err := client.BatchSetMetadata(ctx, &pb.BatchSetMetadataRequest{
+		EntityType: "document",
+		EntityId:   "POL-1",
+		Attributes: map[string]string{"department": "claims", "status": "active", "region": "west"},
+	})
+	if err != nil {
+		t.Fatalf("BatchSetMetadata failed: %v", err)
+	}
+	if !resp.Success || resp.Count != 3 {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	attrs, err := server.metaStore.GetAllMetadata("document", "POL-1")
+	if err != nil {
+		t.Fatalf("GetAllMetadata failed: %v", err)
+	}
+	if len(attrs) != 3 || attrs["status"] != "active" {
+		t.Errorf("Unexpected attributes: %v", attrs)
+	}
+
+	if _, err := client.BatchSetMetadata(ctx, &pb.BatchSetMetadataRequest{EntityType: "document", EntityId: "POL-1"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for empty attributes, got %v", err)
+	}
+
+	if resp.Versions["status"] != 1 {
+		t.Errorf("Expected status at version 1, got %v", resp.Versions)
+	}
+
+	_, err = client.BatchSetMetadata(ctx, &pb.BatchSetMetadataRequest{
+		EntityType:       "document",
+		EntityId:         "POL-1",
+		Attributes:       map[string]string{"status": "retired"},
+		ExpectedVersions: map[string]uint64{"status": 5},
+	})
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("Expected Aborted for stale version, got %v", err)
+	}
+	_, err = client.BatchSetMetadata(ctx, &pb.BatchSetMetadataRequest{
+		EntityType:       "document",
+		EntityId:         "POL-1",
+		Attributes:       map[string]string{"status": "retired", "region": "east"},
+		ExpectedVersions: map[string]uint64{"status": 1},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for attribute without expected version, got %v", err)
+	}
+	resp, err = client.BatchSetMetadata(ctx, &pb.BatchSetMetadataRequest{
+		EntityType:       "document",
+		EntityId:         "POL-1",
+		Attributes:       map[string]string{"status": "retired"},
+		ExpectedVersions: map[string]uint64{"status": 1},
+	})
+	if err != nil {
+		t.Fatalf("BatchSetMetadata with expected versions failed: %v", err)
+	}
+	if resp.Versions["status"] != 2 {
+		t.Errorf("Expected status at version 2, got %v", resp.Versions)
+	}
+
+	if err := server.metaStore.RegisterSchema(&metadata.EntitySchema{
+		EntityType: "document",
+		Fields:     map[string]metadata.FieldSchema{"status": {Enum: []string{"active", "retired"}}},
+	}); err != nil {
+		t.Fatalf("RegisterSchema failed: %v", err)
+	}
+	_, err = client.BatchSetMetadata(ctx, &pb.BatchSetMetadataRequest{
+		EntityType: "document",
+		EntityId:   "POL-2",
+		Attributes: map[string]string{"statuss": "active"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for schema violation, got %v", err)
+	}
+}
+
+func TestSearchConversations(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	base := time.Unix(1700000000, 0)
+
+	if err := server.promptStore.CreateConversation(&prompt.Conversation{
+		ConversationID: "conv-search",
+		UserID:         "user1",
+		Title:          "Appeals",
+		StartedAt:      base,
+	}); err != nil {
+		t.Fatalf("CreateConversation failed: %v", err)
+	}
+	if err := server.promptStore.AddMessage(&prompt.Message{
+		MessageID:      "m1",
+		ConversationID: "conv-search",
+		Role:           "user",
+		Content:        "How long does an appeal take?",
+		Timestamp:      base,
+	}); err != nil {
+		t.Fatalf("AddMessage failed: %v", err)
+	}
+
+	resp, err := client.SearchConversations(ctx, &pb.SearchConversationsRequest{UserId: "user1", Query: "appeal"})
+	if err != nil {
+		t.Fatalf("SearchConversations failed: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Conversation.ConversationId != "conv-search" {
+		t.Fatalf("Expected conv-search, got %v", resp.Results)
+	}
+	if len(resp.Results[0].Matches) != 1 || resp.Results[0].Matches[0].MessageId != "m1" {
+		t.Errorf("Expected m1 as match, got %v", resp.Results[0].Matches)
+	}
+
+	if _, err := client.SearchConversations(ctx, &pb.SearchConversationsRequest{UserId: "user1"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for empty query, got %v", err)
+	}
+}
+
+func TestExportConversation(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	base := time.Unix(1700000000, 0)
+
+	server.promptStore.CreateConversation(&prompt.Conversation{ConversationID: "conv-export", UserID: "user1", StartedAt: base})
+	for i, m := range []*prompt.Message{
+		{MessageID: "m1", Role: "system", Content: "Answer from policy text only."},
+		{MessageID: "m2", Role: "user", Content: "Is an MRI covered?"},
+		{MessageID: "m3", Role: "assistant", Content: "Yes, with prior authorization."},
+	} {
+		m.ConversationID = "conv-export"
+		m.Timestamp = base.Add(time.Duration(i) * time.Second)
+		if err := server.promptStore.AddMessage(m); err != nil {
+			t.Fatalf("AddMessage failed: %v", err)
+		}
+	}
+
+	resp, err := client.ExportConversation(ctx, &pb.ExportConversationRequest{ConversationId: "conv-export", Format: "anthropic", OmitMetadata: true})
+	if err != nil {
+		t.Fatalf("ExportConversation failed: %v", err)
+	}
+	var body struct {
+		System   string `json:"system"`
+		Messages []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal([]byte(resp.Content), &body); err != nil {
+		t.Fatalf("Bad export %q: %v", resp.Content, err)
+	}
+	if resp.MessageCount != 2 || body.System != "Answer from policy text only." || len(body.Messages) != 2 || body.Messages[0].Role != "user" {
+		t.Errorf("Unexpected export %s", resp.Content)
+	}
+
+	if _, err := client.ExportConversation(ctx, &pb.ExportConversationRequest{ConversationId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+	if _, err := client.ExportConversation(ctx, &pb.ExportConversationRequest{ConversationId: "conv-export", Format: "csv"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown format, got %v", err)
+	}
+}
+
+func TestConversationQuota(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	base := time.Unix(1700000000, 0)
+	server.promptStore.SetDefaultQuota(prompt.Quota{MaxConversations: 3})
+
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprintf("conv-%d", i)
+		if err := server.promptStore.CreateConversation(&prompt.Conversation{ConversationID: id, UserID: "agent1", StartedAt: base.Add(time.Duration(i) * time.Minute)}); err != nil {
+			t.Fatalf("CreateConversation failed: %v", err)
+		}
+	}
+	err := server.promptStore.CreateConversation(&prompt.Conversation{ConversationID: "conv-3", UserID: "agent1", StartedAt: base})
+	if !errors.Is(err, prompt.ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+	}
+
+	page, err := client.ListConversationsByUser(ctx, &pb.ListConversationsByUserRequest{UserId: "agent1", Limit: 2})
+	if err != nil {
+		t.Fatalf("ListConversationsByUser failed: %v", err)
+	}
+	if len(page.Conversations) != 2 || !page.HasMore || page.NextCursor != "conv-1" {
+		t.Fatalf("Unexpected first page %v", page)
+	}
+	page, err = client.ListConversationsByUser(ctx, &pb.ListConversationsByUserRequest{UserId: "agent1", AfterConversationId: page.NextCursor, Limit: 2})
+	if err != nil || len(page.Conversations) != 1 || page.HasMore || page.Conversations[0].ConversationId != "conv-2" {
+		t.Fatalf("Unexpected last page %v (%v)", page, err)
+	}
+
+	_, err = client.SetUserConversationQuota(ctx, &pb.SetUserConversationQuotaRequest{UserId: "agent1", Quota: &pb.ConversationQuota{MaxConversations: 10}})
+	if err != nil {
+		t.Fatalf("SetUserConversationQuota failed: %v", err)
+	}
+	if err := server.promptStore.CreateConversation(&prompt.Conversation{ConversationID: "conv-3", UserID: "agent1", StartedAt: base}); err != nil {
+		t.Errorf("CreateConversation under the raised quota failed: %v", err)
+	}
+
+	usage, err := client.GetUserConversationUsage(ctx, &pb.GetUserConversationUsageRequest{UserId: "agent1"})
+	if err != nil {
+		t.Fatalf("GetUserConversationUsage failed: %v", err)
+	}
+	if usage.Conversations != 4 || !usage.CustomQuota || usage.Quota.GetMaxConversations() != 10 {
+		t.Errorf("Unexpected usage %v", usage)
+	}
+}
+
+func TestSearchWithFilter(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := timestamppb.Now()
+
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "FILTER-001", RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "FILTER-001", Title: "Imaging", PageStart: 1, PageEnd: 50, CreatedAt: now, UpdatedAt: now},
+			{NodeId: "active", PolicyId: "FILTER-001", ParentId: "root", Title: "Imaging criteria", SectionPath: "3.1", PageStart: 12, PageEnd: 14, Depth: 1, CreatedAt: now, UpdatedAt: now},
+			{NodeId: "retired", PolicyId: "FILTER-001", ParentId: "root", Title: "Imaging legacy", SectionPath: "3.2", PageStart: 15, PageEnd: 18, Depth: 1, CreatedAt: now, UpdatedAt: now},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	for nodeID, value := range map[string]string{"active": "active", "retired": "retired"} {
+		if err := server.metaStore.SetMetadata(&metadata.MetadataEntry{
+			EntityType: "node",
+			EntityID:   nodeID,
+			Key:        "status",
+			Value:      value,
+		}); err != nil {
+			t.Fatalf("SetMetadata failed: %v", err)
+		}
+	}
+
+	resp, err := client.SearchByKeyword(ctx, &pb.SearchRequest{
+		PolicyId: "FILTER-001",
+		Query:    "imaging",
+		Filter: &pb.SearchFilter{
+			PageFrom:          10,
+			PageTo:            40,
+			SectionPathPrefix: "3.",
+			Metadata:          map[string]string{"status": "active"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SearchByKeyword failed: %v", err)
+	}
+
+	if len(resp.Results) != 1 || resp.Results[0].Node.NodeId != "active" {
+		t.Errorf("Expected only the active node, got %v", resp.Results)
+	}
+}
+
+func TestGlobalSearch(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := timestamppb.Now()
+
+	for _, policyID := range []string{"GS-001", "GS-002"} {
+		_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+			Document: &pb.Document{PolicyId: policyID, RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+			Nodes: []*pb.Node{
+				{
+					NodeId:    "root",
+					PolicyId:  policyID,
+					Title:     "Imaging Policy " + policyID,
+					Text:      "CT scans require prior authorization",
+					CreatedAt: now,
+					UpdatedAt: now,
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("StoreDocument failed: %v", err)
+		}
+	}
+
+	resp, err := client.GlobalSearch(ctx, &pb.GlobalSearchRequest{Query: "prior authorization"})
+	if err != nil {
+		t.Fatalf("GlobalSearch failed: %v", err)
+	}
+
+	if len(resp.Policies) != 2 {
+		t.Fatalf("Expected 2 policies, got %d", len(resp.Policies))
+	}
+	for _, group := range resp.Policies {
+		if len(group.Results) != 1 || group.Results[0].Node.Text == "" {
+			t.Errorf("Expected hydrated result for %s", group.PolicyId)
+		}
+	}
+
+	if _, err := client.GlobalSearch(ctx, &pb.GlobalSearchRequest{}); err == nil {
+		t.Error("Expected error for empty query")
+	}
+}
+
+func TestCollectionReads(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := timestamppb.Now()
+
+	for i, policyID := range []string{"CARD-1", "CARD-2", "ORTHO-1"} {
+		_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+			Document: &pb.Document{PolicyId: policyID, RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+			Nodes: []*pb.Node{
+				{NodeId: "root", PolicyId: policyID, Title: "Policy " + policyID, CreatedAt: now, UpdatedAt: now},
+				{NodeId: "crit", PolicyId: policyID, ParentId: "root", Title: "Criteria", Depth: 1,
+					Text: strings.Repeat("prior authorization ", i+1), CreatedAt: now, UpdatedAt: now},
+			},
+		})
+		if err != nil {
+			t.Fatalf("StoreDocument failed: %v", err)
+		}
+	}
+
+	put, err := client.PutCollection(ctx, &pb.PutCollectionRequest{Collection: &pb.Collection{
+		Name:      "cardiology",
+		PolicyIds: []string{"CARD-2", "CARD-1"},
+		Metadata:  map[string]string{"year": "2024"},
+	}})
+	if err != nil {
+		t.Fatalf("PutCollection failed: %v", err)
+	}
+	if strings.Join(put.Collection.PolicyIds, ",") != "CARD-1,CARD-2" {
+		t.Errorf("Expected sorted members, got %v", put.Collection.PolicyIds)
+	}
+
+	global, err := client.GlobalSearch(ctx, &pb.GlobalSearchRequest{Query: "authorization", Collection: "cardiology"})
+	if err != nil {
+		t.Fatalf("GlobalSearch failed: %v", err)
+	}
+	if len(global.Policies) != 2 {
+		t.Fatalf("Expected hits from the 2 members only, got %d policies", len(global.Policies))
+	}
+	for _, group := range global.Policies {
+		if !strings.HasPrefix(group.PolicyId, "CARD-") {
+			t.Errorf("Expected only members, got %s", group.PolicyId)
+		}
+	}
+
+	search, err := client.SearchByKeyword(ctx, &pb.SearchRequest{Query: "authorization", Collection: "cardiology"})
+	if err != nil {
+		t.Fatalf("SearchByKeyword failed: %v", err)
+	}
+	if len(search.Results) != 2 || search.Results[0].Node.PolicyId == search.Results[1].Node.PolicyId ||
+		search.Results[0].Node.Text == "" || search.Results[0].Score < search.Results[1].Score {
+		t.Errorf("Expected one hydrated hit per member, best first, got %v", search.Results)
+	}
+	if _, err := client.SearchByKeyword(ctx, &pb.SearchRequest{Query: "x", PolicyId: "CARD-1", Collection: "cardiology"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for both policy_id and collection, got %v", err)
+	}
+
+	subtree, err := client.GetSubtree(ctx, &pb.GetSubtreeRequest{Collection: "cardiology", Fields: []string{"title"}})
+	if err != nil {
+		t.Fatalf("GetSubtree failed: %v", err)
+	}
+	var ids []string
+	for _, node := range subtree.Nodes {
+		ids = append(ids, node.PolicyId+"/"+node.NodeId)
+	}
+	if strings.Join(ids, ",") != "CARD-1/root,CARD-1/crit,CARD-2/root,CARD-2/crit" {
+		t.Errorf("Expected each member's tree in order, got %v", ids)
+	}
+
+	updated, err := client.UpdateCollectionMembers(ctx, &pb.UpdateCollectionMembersRequest{Name: "cardiology", Add: []string{"ORTHO-1"}, Remove: []string{"CARD-1"}})
+	if err != nil || strings.Join(updated.Collection.PolicyIds, ",") != "CARD-2,ORTHO-1" {
+		t.Fatalf("UpdateCollectionMembers returned %v (%v)", updated, err)
+	}
+	listed, err := client.ListCollections(ctx, &pb.ListCollectionsRequest{PolicyId: "ORTHO-1"})
+	if err != nil || len(listed.Collections) != 1 || listed.Collections[0].Metadata["year"] != "2024" {
+		t.Errorf("Expected ORTHO-1 listed under cardiology, got %v (%v)", listed, err)
+	}
+
+	if _, err := client.DeleteCollection(ctx, &pb.DeleteCollectionRequest{Name: "cardiology"}); err != nil {
+		t.Fatalf("DeleteCollection failed: %v", err)
+	}
+	if _, err := client.GetCollection(ctx, &pb.GetCollectionRequest{Name: "cardiology"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound after delete, got %v", err)
+	}
+	if _, err := client.GlobalSearch(ctx, &pb.GlobalSearchRequest{Query: "authorization", Collection: "cardiology"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound searching a deleted collection, got %v", err)
+	}
+}
+
+func TestGetSubtree(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := timestamppb.Now()
+
+	// Store multi-level hierarchy
+	doc := &pb.Document{
+		PolicyId:   "TEST-005",
+		VersionId:  "v1.0",
+		RootNodeId: "root",
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	nodes := []*pb.Node{
+		{
+			NodeId:    "root",
+			PolicyId:  "TEST-005",
+			Title:     "Root",
+			Depth:     0,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		{
+			NodeId:    "level1",
+			PolicyId:  "TEST-005",
+			ParentId:  "root",
+			Title:     "Level 1",
+			Depth:     1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		{
+			NodeId:    "level2",
+			PolicyId:  "TEST-005",
+			ParentId:  "level1",
+			Title:     "Level 2",
+			Depth:     2,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: doc,
+		Nodes:    nodes,
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	// Get subtree from root
+	subtreeReq := &pb.GetSubtreeRequest{
+		PolicyId: "TEST-005",
+		NodeId:   "root",
+		MaxDepth: 0, // Unlimited
+	}
+
+	subtreeResp, err := client.GetSubtree(ctx, subtreeReq)
+	if err != nil {
+		t.Fatalf("GetSubtree failed: %v", err)
+	}
+
+	if len(subtreeResp.Nodes) != 3 {
+		t.Errorf("Expected 3 nodes in subtree, got %d", len(subtreeResp.Nodes))
+	}
+
+	// Get subtree with max_depth=1
+	subtreeReq.MaxDepth = 1
+	subtreeResp, err = client.GetSubtree(ctx, subtreeReq)
+	if err != nil {
+		t.Fatalf("GetSubtree with max_depth failed: %v", err)
+	}
+
+	if len(subtreeResp.Nodes) != 2 {
+		t.Errorf("Expected 2 nodes with max_depth=1, got %d", len(subtreeResp.Nodes))
+	}
+}
+
+func TestGetSubtreeWithinBudget(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	text := strings.Repeat("Prior authorization is required. ", 10)
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "TEST-BUDGET"},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "TEST-BUDGET", Title: "Root", Summary: "Overview", Text: text},
+			{NodeId: "child", PolicyId: "TEST-BUDGET", ParentId: "root", Title: "Child", Summary: "Details", Text: text, TokenCount: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	// Token counts are set by the server, whatever the client sent
+	node, err := client.GetNode(ctx, &pb.GetNodeRequest{PolicyId: "TEST-BUDGET", NodeId: "child"})
+	if err != nil || int(node.Node.TokenCount) != document.EstimateTokens(text) {
+		t.Fatalf("Expected the text's tokens counted, got %v (%v)", node, err)
+	}
+
+	resp, err := client.GetSubtreeWithinBudget(ctx, &pb.GetSubtreeWithinBudgetRequest{PolicyId: "TEST-BUDGET", NodeId: "root", MaxTokens: 120})
+	if err != nil {
+		t.Fatalf("GetSubtreeWithinBudget failed: %v", err)
+	}
+	if len(resp.Nodes) != 2 || resp.Depth != 1 || !resp.Truncated || len(resp.SummarizedIds) != 1 || resp.SummarizedIds[0] != "child" {
+		t.Errorf("Expected the root's text and the child's summary, got %v", resp)
+	}
+	if resp.Nodes[1].Summary != "Details" || resp.Nodes[1].Text != "" || resp.TokenCount > 120 {
+		t.Errorf("Expected the child summarized within budget, got %v", resp)
+	}
+
+	if _, err := client.GetSubtreeWithinBudget(ctx, &pb.GetSubtreeWithinBudgetRequest{PolicyId: "TEST-BUDGET", NodeId: "root", MaxTokens: 1}); status.Code(err) != codes.OutOfRange {
+		t.Errorf("Expected OutOfRange for a budget below the root, got %v", err)
+	}
+}
+
+func TestNodeFieldMask(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := timestamppb.Now()
+
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "TEST-MASK", RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "TEST-MASK", Title: "Root", Summary: "Overview", Text: "Root text", PageStart: 1, PageEnd: 4, CreatedAt: now, UpdatedAt: now},
+			{NodeId: "child", PolicyId: "TEST-MASK", ParentId: "root", Title: "Child", Summary: "Detail", Text: "Child text", PageStart: 2, PageEnd: 3, Depth: 1, CreatedAt: now, UpdatedAt: now},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	fields := []string{"title", "page_start"}
+	subtree, err := client.GetSubtree(ctx, &pb.GetSubtreeRequest{PolicyId: "TEST-MASK", NodeId: "root", Fields: fields})
+	if err != nil {
+		t.Fatalf("GetSubtree failed: %v", err)
+	}
+	children, err := client.GetChildren(ctx, &pb.GetChildrenRequest{PolicyId: "TEST-MASK", ParentId: "root", Fields: fields})
+	if err != nil {
+		t.Fatalf("GetChildren failed: %v", err)
+	}
+	doc, err := client.GetDocument(ctx, &pb.GetDocumentRequest{PolicyId: "TEST-MASK", Fields: fields})
+	if err != nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+
+	for name, nodes := range map[string][]*pb.Node{"GetSubtree": subtree.Nodes, "GetChildren": children.Children, "GetDocument": doc.Nodes} {
+		if len(nodes) == 0 {
+			t.Fatalf("%s returned no nodes", name)
+		}
+		for _, node := range nodes {
+			if node.Title == "" || node.PageStart == 0 || node.NodeId == "" || node.PolicyId == "" {
+				t.Errorf("%s: expected requested and identifying fields, got %v", name, node)
+			}
+			if node.Text != "" || node.Summary != "" || node.PageEnd != 0 || node.CreatedAt != nil {
+				t.Errorf("%s: expected unrequested fields left out, got %v", name, node)
+			}
+			if node.NodeId == "child" && node.ParentId != "root" {
+				t.Errorf("%s: expected parent_id always returned, got %q", name, node.ParentId)
+			}
+		}
+	}
+
+	// Without a mask every field comes back
+	full, err := client.GetChildren(ctx, &pb.GetChildrenRequest{PolicyId: "TEST-MASK", ParentId: "root"})
+	if err != nil || len(full.Children) != 1 || full.Children[0].Text != "Child text" || full.Children[0].Summary != "Detail" {
+		t.Errorf("Expected the full node without a mask, got %v (%v)", full, err)
+	}
+
+	_, err = client.GetSubtree(ctx, &pb.GetSubtreeRequest{PolicyId: "TEST-MASK", NodeId: "root", Fields: []string{"txt"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown field, got %v", err)
+	}
+}
+
+func TestGetAncestorPath(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := timestamppb.Now()
+
+	// Store multi-level hierarchy
+	doc := &pb.Document{
+		PolicyId:   "TEST-006",
+		VersionId:  "v1.0",
+		RootNodeId: "root",
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	nodes := []*pb.Node{
+		{
+			NodeId:    "root",
+			PolicyId:  "TEST-006",
+			Title:     "Root",
+			Depth:     0,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		{
+			NodeId:    "parent",
+			PolicyId:  "TEST-006",
+			ParentId:  "root",
+			Title:     "Parent",
+			Depth:     1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		{
+			NodeId:    "child",
+			PolicyId:  "TEST-006",
+			ParentId:  "parent",
+			Title:     "Child",
+			Depth:     2,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: doc,
+		Nodes:    nodes,
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	// Get ancestor path for deepest child
+	pathReq := &pb.GetAncestorPathRequest{
+		PolicyId: "TEST-006",
+		NodeId:   "child",
+	}
+
+	pathResp, err := client.GetAncestorPath(ctx, pathReq)
+	if err != nil {
+		t.Fatalf("GetAncestorPath failed: %v", err)
+	}
+
+	if len(pathResp.Ancestors) != 3 {
+		t.Errorf("Expected 3 ancestors (root, parent, child), got %d", len(pathResp.Ancestors))
+	}
+
+	// Verify order: root -> parent -> child
+	expectedOrder := []string{"root", "parent", "child"}
+	for i, expected := range expectedOrder {
+		if pathResp.Ancestors[i].NodeId != expected {
+			t.Errorf("Expected ancestor[%d]=%s, got %s", i, expected, pathResp.Ancestors[i].NodeId)
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	req := &pb.HealthRequest{}
+	resp, err := client.Health(ctx, req)
+	if err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+
+	if !resp.Healthy {
+		t.Error("Expected healthy=true")
+	}
+
+	if resp.Version == "" {
+		t.Error("Expected non-empty version")
+	}
+
+	if resp.UptimeSeconds < 0 {
+		t.Errorf("Expected non-negative uptime, got %d", resp.UptimeSeconds)
+	}
+}
+
+func TestStats(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// First store some data
+	now := timestamppb.Now()
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{
+			PolicyId:   "TEST-007",
+			RootNodeId: "root",
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		},
+		Nodes: []*pb.Node{
+			{
+				NodeId:    "root",
+				PolicyId:  "TEST-007",
+				Title:     "Test",
+				CreatedAt: now,
+				UpdatedAt: now,
 			},
 		},
 	})
@@ -593,6 +1669,140 @@ func TestStats(t *testing.T) {
 	if resp.OperationCounts["StoreDocument"] == 0 {
 		t.Error("Expected StoreDocument operation count > 0")
 	}
+	if resp.SyncPolicy != "always" || resp.UnflushedCommits != 0 {
+		t.Errorf("Expected the default sync policy, got %q with %d unflushed", resp.SyncPolicy, resp.UnflushedCommits)
+	}
+	if resp.TotalDocuments != 1 || resp.TotalNodes != 1 || resp.TotalVersions != 0 {
+		t.Errorf("Expected 1 document with 1 node, got %d, %d and %d versions", resp.TotalDocuments, resp.TotalNodes, resp.TotalVersions)
+	}
+}
+
+func TestStatsSurviveRestart(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "stats.db")
+	server, err := NewServer(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	ctx := context.Background()
+	now := timestamppb.Now()
+	for _, policyID := range []string{"POL-A", "POL-B"} {
+		_, err := server.StoreDocument(ctx, &pb.StoreDocumentRequest{
+			Document: &pb.Document{PolicyId: policyID, RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+			Nodes: []*pb.Node{
+				{NodeId: "root", PolicyId: policyID, CreatedAt: now, UpdatedAt: now},
+				{NodeId: "child", PolicyId: policyID, ParentId: "root", CreatedAt: now, UpdatedAt: now},
+			},
+		})
+		if err != nil {
+			t.Fatalf("StoreDocument failed: %v", err)
+		}
+	}
+	base := time.Unix(1700000000, 0)
+	for i := 0; i < 3; i++ {
+		v := &version.Version{PolicyID: "POL-A", VersionID: fmt.Sprintf("v%d", i), CreatedAt: base.Add(time.Duration(i) * time.Hour)}
+		if err := server.verStore.CreateVersion(v); err != nil {
+			t.Fatalf("CreateVersion failed: %v", err)
+		}
+	}
+	server.Close()
+
+	server, err = NewServer(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen server: %v", err)
+	}
+	defer server.Close()
+	resp, err := server.Stats(ctx, &pb.StatsRequest{})
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if resp.TotalDocuments != 2 || resp.TotalNodes != 4 || resp.TotalVersions != 3 {
+		t.Errorf("Expected 2 documents, 4 nodes and 3 versions after restart, got %d, %d and %d",
+			resp.TotalDocuments, resp.TotalNodes, resp.TotalVersions)
+	}
+
+	// One policy, counted exactly and estimated; a tree this small is sampled whole
+	for _, approximate := range []bool{false, true} {
+		resp, err := server.Stats(ctx, &pb.StatsRequest{PolicyId: "POL-A", Approximate: approximate})
+		if err != nil {
+			t.Fatalf("Stats failed: %v", err)
+		}
+		if resp.TotalDocuments != 1 || resp.TotalNodes != 2 || resp.TotalVersions != 3 || resp.TotalNodesError != 0 || resp.Approximate != approximate {
+			t.Errorf("Approximate %v: unexpected counts for POL-A: %+v", approximate, resp)
+		}
+	}
+	resp, err = server.Stats(ctx, &pb.StatsRequest{Approximate: true})
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if resp.TotalDocuments != 2 || resp.TotalNodes != 4 || resp.TotalVersions != 3 {
+		t.Errorf("Expected estimates of 2 documents, 4 nodes and 3 versions, got %+v", resp)
+	}
+}
+
+func TestStorageBreakdown(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := timestamppb.Now()
+	for policyID, nodes := range map[string]int{"POL-BIG": 20, "POL-SMALL": 1} {
+		req := &pb.StoreDocumentRequest{Document: &pb.Document{PolicyId: policyID, RootNodeId: "n0", CreatedAt: now, UpdatedAt: now}}
+		for i := 0; i < nodes; i++ {
+			node := &pb.Node{NodeId: fmt.Sprintf("n%d", i), PolicyId: policyID, Title: "Coverage rules", Text: "Prior authorization is required", CreatedAt: now, UpdatedAt: now}
+			if i > 0 {
+				node.ParentId = "n0"
+			}
+			req.Nodes = append(req.Nodes, node)
+		}
+		if _, err := client.StoreDocument(ctx, req); err != nil {
+			t.Fatalf("StoreDocument failed: %v", err)
+		}
+	}
+	if err := server.verStore.CreateVersion(&version.Version{PolicyID: "POL-SMALL", VersionID: "v1", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("CreateVersion failed: %v", err)
+	}
+
+	resp, err := client.StorageBreakdown(ctx, &pb.StorageBreakdownRequest{})
+	if err != nil {
+		t.Fatalf("StorageBreakdown failed: %v", err)
+	}
+	if len(resp.Policies) != 2 || resp.Policies[0].PolicyId != "POL-BIG" || resp.Policies[0].Bytes <= resp.Policies[1].Bytes {
+		t.Fatalf("Expected POL-BIG first, got %v", resp.Policies)
+	}
+	var sum int64
+	stores := make(map[string]int64)
+	for _, u := range resp.Stores {
+		sum += u.Bytes
+		stores[u.Store] = u.Bytes
+	}
+	if sum != resp.TotalBytes || stores["documents"] == 0 || stores["versions"] == 0 {
+		t.Errorf("Unexpected store totals: %v (total %d)", resp.Stores, resp.TotalBytes)
+	}
+	if resp.DbSizeBytes < resp.TotalBytes {
+		t.Errorf("Expected the file to hold at least %d bytes, got %d", resp.TotalBytes, resp.DbSizeBytes)
+	}
+
+	small := resp.Policies[1]
+	var sawVersions bool
+	for _, u := range small.Stores {
+		sawVersions = sawVersions || (u.Store == "versions" && u.Keys > 0)
+	}
+	if !sawVersions {
+		t.Errorf("Expected POL-SMALL's version in its breakdown, got %v", small.Stores)
+	}
+
+	resp, err = client.StorageBreakdown(ctx, &pb.StorageBreakdownRequest{PolicyId: "POL-SMALL"})
+	if err != nil {
+		t.Fatalf("StorageBreakdown failed: %v", err)
+	}
+	if len(resp.Policies) != 1 || resp.Policies[0].Bytes != small.Bytes {
+		t.Errorf("Expected only POL-SMALL, got %v", resp.Policies)
+	}
+	resp, _ = client.StorageBreakdown(ctx, &pb.StorageBreakdownRequest{Limit: 1})
+	if len(resp.Policies) != 1 || resp.Policies[0].PolicyId != "POL-BIG" {
+		t.Errorf("Expected the limit to keep the largest policy, got %v", resp.Policies)
+	}
 }
 
 func TestVersionOperations(t *testing.T) {
@@ -615,3 +1825,283 @@ func TestVersionOperations(t *testing.T) {
 		t.Error("Expected error for non-existent policy")
 	}
 }
+
+func TestCloneDocument(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "POL-SRC", VersionId: "v2"},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "POL-SRC", Title: "Root", SectionPath: "1"},
+			{NodeId: "child", PolicyId: "POL-SRC", ParentId: "root", Title: "Child", SectionPath: "1.1", Depth: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	for _, v := range []string{"v1", "v2"} {
+		created := time.Now().Add(-time.Hour)
+		if v == "v2" {
+			created = time.Now()
+		}
+		if err := server.verStore.CreateVersion(&version.Version{PolicyID: "POL-SRC", VersionID: v, CreatedAt: created}); err != nil {
+			t.Fatalf("CreateVersion failed: %v", err)
+		}
+	}
+
+	// Older versions' trees are not kept
+	_, err = client.CloneDocument(ctx, &pb.CloneDocumentRequest{SrcPolicyId: "POL-SRC", SrcVersionId: "v1", DstPolicyId: "POL-DST"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for an old version, got %v", err)
+	}
+
+	resp, err := client.CloneDocument(ctx, &pb.CloneDocumentRequest{SrcPolicyId: "POL-SRC", SrcVersionId: "v2", DstPolicyId: "POL-DST"})
+	if err != nil {
+		t.Fatalf("CloneDocument failed: %v", err)
+	}
+	if len(resp.NodeIdMap) != 2 || len(resp.RootNodeIds) != 1 {
+		t.Fatalf("Unexpected response: %v", resp)
+	}
+	child, err := client.GetNode(ctx, &pb.GetNodeRequest{PolicyId: "POL-DST", NodeId: resp.NodeIdMap["child"]})
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if child.Node.ParentId != resp.RootNodeIds[0] || child.Node.SectionPath != "1.1" {
+		t.Errorf("Unexpected cloned child: %v", child.Node)
+	}
+
+	_, err = client.CloneDocument(ctx, &pb.CloneDocumentRequest{SrcPolicyId: "POL-SRC", DstPolicyId: "POL-DST"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("Expected AlreadyExists, got %v", err)
+	}
+}
+
+func TestRecomputeSectionPaths(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "POL-SP"},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "POL-SP", SectionPath: "1"},
+			{NodeId: "moved", PolicyId: "POL-SP", ParentId: "root", SectionPath: "3.2", Depth: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	dry, err := client.RecomputeSectionPaths(ctx, &pb.RecomputeSectionPathsRequest{PolicyId: "POL-SP", DryRun: true})
+	if err != nil {
+		t.Fatalf("RecomputeSectionPaths failed: %v", err)
+	}
+	if dry.Applied || dry.NodesChecked != 2 || len(dry.Changes) != 1 || dry.Changes[0].Expected != "1.1" {
+		t.Fatalf("Unexpected dry run: %v", dry)
+	}
+
+	resp, err := client.RecomputeSectionPaths(ctx, &pb.RecomputeSectionPathsRequest{PolicyId: "POL-SP"})
+	if err != nil || !resp.Applied {
+		t.Fatalf("Expected the changes to be applied: %v (%v)", resp, err)
+	}
+	node, _ := client.GetNode(ctx, &pb.GetNodeRequest{PolicyId: "POL-SP", NodeId: "moved"})
+	if node.Node.SectionPath != "1.1" {
+		t.Errorf("Expected section path 1.1, got %s", node.Node.SectionPath)
+	}
+
+	_, err = client.RecomputeSectionPaths(ctx, &pb.RecomputeSectionPathsRequest{PolicyId: "POL-NONE"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+}
+
+func TestValidateDocument(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "POL-VD"},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "POL-VD", PageStart: 1, PageEnd: 5},
+			{NodeId: "sec", PolicyId: "POL-VD", ParentId: "root", Depth: 3, PageStart: 2, PageEnd: 3},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	resp, err := client.ValidateDocument(ctx, &pb.ValidateDocumentRequest{PolicyId: "POL-VD"})
+	if err != nil {
+		t.Fatalf("ValidateDocument failed: %v", err)
+	}
+	if resp.Valid || resp.NodesChecked != 2 || len(resp.Issues) != 1 {
+		t.Fatalf("Unexpected report: %v", resp)
+	}
+	if issue := resp.Issues[0]; issue.Kind != "depth_mismatch" || issue.NodeId != "sec" {
+		t.Errorf("Expected a depth mismatch on sec, got %v", issue)
+	}
+
+	_, err = client.ValidateDocument(ctx, &pb.ValidateDocumentRequest{PolicyId: "POL-NONE"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+}
+
+func TestFindDuplicateNodes(t *testing.T) {
+	_, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, policyID := range []string{"POL-A", "POL-B"} {
+		_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+			Document: &pb.Document{PolicyId: policyID},
+			Nodes: []*pb.Node{
+				{NodeId: "root", PolicyId: policyID},
+				{NodeId: "copy", PolicyId: policyID, ParentId: "root", Text: "Shared criteria"},
+				{NodeId: "own", PolicyId: policyID, ParentId: "root", Text: "Only in " + policyID},
+			},
+		})
+		if err != nil {
+			t.Fatalf("StoreDocument failed: %v", err)
+		}
+	}
+
+	within, err := client.FindDuplicateNodes(ctx, &pb.FindDuplicateNodesRequest{PolicyId: "POL-A"})
+	if err != nil || len(within.Groups) != 0 {
+		t.Errorf("Expected no copies within POL-A, got %v (%v)", within, err)
+	}
+	across, err := client.FindDuplicateNodes(ctx, &pb.FindDuplicateNodesRequest{CrossPolicy: true})
+	if err != nil || len(across.Groups) != 1 || len(across.Groups[0].Nodes) != 2 {
+		t.Fatalf("Expected one group across policies, got %v (%v)", across, err)
+	}
+	if n := across.Groups[0].Nodes[1]; n.PolicyId != "POL-B" || n.NodeId != "copy" {
+		t.Errorf("Expected POL-B's copy second, got %v", n)
+	}
+
+	_, err = client.FindDuplicateNodes(ctx, &pb.FindDuplicateNodesRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestSavedQueryRPCs(t *testing.T) {
+	s, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := timestamppb.Now()
+
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "LCD-1", RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "LCD-1", Title: "Root", CreatedAt: now, UpdatedAt: now},
+			{NodeId: "a", PolicyId: "LCD-1", ParentId: "root", Title: "Criteria", Depth: 1, CreatedAt: now, UpdatedAt: now},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	text := "FROM nodes WHERE policyID = 'LCD-1' AND depth = 1"
+	saved, err := client.SaveQuery(ctx, &pb.SaveQueryRequest{Name: "criteria", Query: text})
+	if err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+	if saved.Saved.Rows != 1 || saved.Saved.RefreshOnChange {
+		t.Errorf("Expected one on-demand row, got %+v", saved.Saved)
+	}
+
+	title := func() string {
+		res, err := client.ExecuteSavedQuery(ctx, &pb.ExecuteSavedQueryRequest{Name: "criteria"})
+		if err != nil {
+			t.Fatalf("ExecuteSavedQuery failed: %v", err)
+		}
+		if len(res.Rows) != 1 {
+			t.Fatalf("Expected 1 row, got %d", len(res.Rows))
+		}
+		return res.Rows[0].GetNode().Title
+	}
+	if got := title(); got != "Criteria" {
+		t.Errorf("Expected the materialized node, got %q", got)
+	}
+
+	node, err := client.GetNode(ctx, &pb.GetNodeRequest{PolicyId: "LCD-1", NodeId: "a"})
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	node.Node.Title = "Coverage criteria"
+	if _, err := client.UpdateNode(ctx, &pb.UpdateNodeRequest{Node: node.Node}); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if got := title(); got != "Criteria" {
+		t.Errorf("Expected results kept until refreshed, got %q", got)
+	}
+	if _, err := client.RefreshSavedQuery(ctx, &pb.RefreshSavedQueryRequest{Name: "criteria"}); err != nil {
+		t.Fatalf("RefreshSavedQuery failed: %v", err)
+	}
+	if got := title(); got != "Coverage criteria" {
+		t.Errorf("Expected the refreshed title, got %q", got)
+	}
+
+	list, err := client.ListSavedQueries(ctx, &pb.ListSavedQueriesRequest{})
+	if err != nil || len(list.Saved) != 1 || list.Saved[0].Query != text {
+		t.Errorf("Unexpected saved queries: %v (%v)", list, err)
+	}
+
+	if _, err := client.SaveQuery(ctx, &pb.SaveQueryRequest{Name: "bad", Query: "FROM nowhere"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a bad query, got %v", err)
+	}
+	if _, err := client.DeleteSavedQuery(ctx, &pb.DeleteSavedQueryRequest{Name: "criteria"}); err != nil {
+		t.Fatalf("DeleteSavedQuery failed: %v", err)
+	}
+	if _, err := client.ExecuteSavedQuery(ctx, &pb.ExecuteSavedQueryRequest{Name: "criteria"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound after delete, got %v", err)
+	}
+
+	// The first refresh-on-change query starts the watcher, once
+	watchers := s.feed.Subscribers()
+	for i := 0; i < 2; i++ {
+		if _, err := client.SaveQuery(ctx, &pb.SaveQueryRequest{Name: "live", Query: text, RefreshOnChange: true}); err != nil {
+			t.Fatalf("SaveQuery failed: %v", err)
+		}
+	}
+	if got := s.feed.Subscribers(); got != watchers+1 {
+		t.Errorf("Expected one watcher started, got %d subscribers (was %d)", got, watchers)
+	}
+}
+
+func TestStartCanary(t *testing.T) {
+	s, err := NewServer(filepath.Join(t.TempDir(), "canary.db"))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer s.Close()
+	c := s.StartCanary(canary.Config{Interval: time.Hour})
+
+	// Records of every store written through their APIs check out
+	now := time.Now()
+	_, err = s.StoreDocument(context.Background(), &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "POL-C"},
+		Nodes:    []*pb.Node{{NodeId: "root", PolicyId: "POL-C", Title: "Root", Text: "Covered"}},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	s.verStore.CreateVersion(&version.Version{PolicyID: "POL-C", VersionID: "v1", CreatedAt: now, Tags: []string{"latest"}})
+	s.metaStore.SetMetadata(&metadata.MetadataEntry{EntityType: "node", EntityID: "root", Key: "status", Value: "active"})
+	s.promptStore.CreateConversation(&prompt.Conversation{ConversationID: "c1", UserID: "u1", StartedAt: now, Tags: []string{"t"}})
+	s.promptStore.AddMessage(&prompt.Message{MessageID: "m1", ConversationID: "c1", Role: "user", Content: "Hi", Timestamp: now})
+
+	report := c.CheckOnce(now)
+	for _, kind := range []string{"node", "version", "metadata", "conversation", "message"} {
+		if report.Checked[kind] == 0 {
+			t.Errorf("Expected %s records checked, got %v", kind, report.Checked)
+		}
+	}
+	if len(report.Anomalies) != 0 {
+		t.Errorf("Expected no anomalies, got %+v", report.Anomalies)
+	}
+}
@@ -0,0 +1,119 @@
+// Server-side request deadlines and logging of requests that run long
+package server
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/nainya/treestore/internal/logger"
+)
+
+// maxSummaryValue bounds each string shown in a request summary
+const maxSummaryValue = 64
+
+// longLivedStreams run until the client leaves, so their duration says nothing
+// about their cost
+var longLivedStreams = map[string]bool{
+	"WatchChanges": true,
+	"StreamWAL":    true,
+}
+
+// DeadlineInterceptor bounds requests that arrive without a deadline to
+// timeout; 0 leaves them unbounded. Store scans stop once a request's context
+// ends, and a handler failing after that is reported as Canceled or
+// DeadlineExceeded rather than as an internal error.
+func DeadlineInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		resp, err := handler(ctx, req)
+		if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+			if code := status.Code(err); code == codes.Internal || code == codes.Unknown {
+				err = status.FromContextError(ctxErr).Err()
+			}
+		}
+		return resp, err
+	}
+}
+
+// SlowQueryInterceptor logs requests taking threshold or longer with their
+// method, a summary of their arguments and their duration; 0 disables it
+func SlowQueryInterceptor(log *logger.Logger, threshold time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		if elapsed := time.Since(start); threshold > 0 && elapsed >= threshold {
+			logSlowQuery(logger.FromContext(ctx, log), info.FullMethod, req, elapsed, err)
+		}
+		return resp, err
+	}
+}
+
+// SlowQueryStreamInterceptor is SlowQueryInterceptor for streaming RPCs.
+// Streams that follow changes until the client leaves are never logged.
+func SlowQueryStreamInterceptor(log *logger.Logger, threshold time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if threshold <= 0 || longLivedStreams[path.Base(info.FullMethod)] {
+			return handler(srv, ss)
+		}
+		start := time.Now()
+		err := handler(srv, ss)
+		if elapsed := time.Since(start); elapsed >= threshold {
+			logSlowQuery(logger.FromContext(ss.Context(), log), info.FullMethod, nil, elapsed, err)
+		}
+		return err
+	}
+}
+
+// logSlowQuery writes one slow query log entry
+func logSlowQuery(log *logger.Logger, method string, req interface{}, elapsed time.Duration, err error) {
+	event := log.Warn("Slow request").
+		Str("method", method).
+		Dur("duration", elapsed)
+	if msg, ok := req.(proto.Message); ok {
+		event = event.Str("args", summarizeRequest(msg))
+	}
+	if err != nil {
+		event = event.Str("code", status.Code(err).String())
+	}
+	event.Send()
+}
+
+// summarizeRequest describes the fields set in a request: scalars by value,
+// with long strings cut short, and lists, maps and messages by size only, so
+// document bodies stay out of the log
+func summarizeRequest(msg proto.Message) string {
+	var parts []string
+	msg.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+		switch {
+		case fd.IsList():
+			parts = append(parts, fmt.Sprintf("%s=[%d]", name, v.List().Len()))
+		case fd.IsMap():
+			parts = append(parts, fmt.Sprintf("%s={%d}", name, v.Map().Len()))
+		case fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind:
+			parts = append(parts, name+"={...}")
+		case fd.Kind() == protoreflect.StringKind:
+			parts = append(parts, fmt.Sprintf("%s=%q", name, truncate(v.String(), maxSummaryValue)))
+		case fd.Kind() == protoreflect.BytesKind:
+			parts = append(parts, fmt.Sprintf("%s=<%d bytes>", name, len(v.Bytes())))
+		default:
+			parts = append(parts, fmt.Sprintf("%s=%v", name, v.Interface()))
+		}
+		return true
+	})
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,109 @@
+// Tests for request deadlines and the slow query log
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nainya/treestore/internal/logger"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestDeadlineInterceptor(t *testing.T) {
+	intercept := DeadlineInterceptor(20 * time.Millisecond)
+	info := &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/GlobalSearch"}
+
+	// A request without a deadline gets one, and a handler that fails once it
+	// passes reports DeadlineExceeded
+	_, err := intercept(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("Expected the handler to get a deadline")
+		}
+		<-ctx.Done()
+		return nil, status.Errorf(codes.Internal, "search failed: %v", ctx.Err())
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+
+	// A client's own deadline is kept, even when later than the default
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	intercept(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if got, _ := ctx.Deadline(); !got.Equal(want) {
+			t.Errorf("Expected the client deadline %v, got %v", want, got)
+		}
+		return nil, nil
+	})
+
+	// Cancellation maps too, but errors the handler chose are left alone
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = intercept(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.New("scan stopped")
+	})
+	if status.Code(err) != codes.Canceled {
+		t.Errorf("Expected Canceled, got %v", err)
+	}
+	_, err = intercept(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "policy not found")
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound to pass through, got %v", err)
+	}
+}
+
+func TestSlowQueryInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewLogger(logger.Config{Output: &buf})
+	info := &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/GlobalSearch"}
+	req := &pb.SearchRequest{PolicyId: "POL-1", Query: strings.Repeat("q", 100), Limit: 5, Filter: &pb.SearchFilter{}}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		time.Sleep(5 * time.Millisecond)
+		return nil, nil
+	}
+
+	SlowQueryInterceptor(log, time.Hour)(context.Background(), req, info, handler)
+	if buf.Len() != 0 {
+		t.Errorf("Expected fast requests not logged, got %s", buf.String())
+	}
+
+	SlowQueryInterceptor(log, time.Millisecond)(context.Background(), req, info, handler)
+	out := buf.String()
+	for _, want := range []string{"Slow request", "GlobalSearch", `policy_id=\"POL-1\"`, "limit=5", "filter={...}"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %s in the slow query log, got %s", want, out)
+		}
+	}
+	if strings.Contains(out, strings.Repeat("q", 65)) {
+		t.Errorf("Expected the query cut short, got %s", out)
+	}
+}
+
+func TestSlowQueryStreamInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewLogger(logger.Config{Output: &buf})
+	intercept := SlowQueryStreamInterceptor(log, time.Millisecond)
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	}
+
+	intercept(nil, &contextStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/treestore.TreeStoreService/WatchChanges"}, handler)
+	if buf.Len() != 0 {
+		t.Errorf("Expected long-lived streams not logged, got %s", buf.String())
+	}
+	intercept(nil, &contextStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/treestore.TreeStoreService/StreamQuery"}, handler)
+	if !strings.Contains(buf.String(), "StreamQuery") {
+		t.Errorf("Expected a slow StreamQuery logged, got %s", buf.String())
+	}
+}
@@ -0,0 +1,67 @@
+// Summaries recomputed by the configured external summarizer, with progress streamed to the caller
+package server
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nainya/treestore/pkg/document"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func (s *Server) RefreshSummaries(req *pb.RefreshSummariesRequest, stream pb.TreeStoreService_RefreshSummariesServer) error {
+	s.countOp("RefreshSummaries")
+
+	if req.PolicyId == "" {
+		return status.Error(codes.InvalidArgument, "policy_id is required")
+	}
+	if s.summarizer == nil {
+		return status.Error(codes.FailedPrecondition, "no summarizer is configured")
+	}
+
+	// Progress is sent as each batch commits; a failed send ends the refresh
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	var sendErr error
+	opts := document.RefreshOptions{
+		BatchSize: int(req.BatchSize),
+		Lock:      s.maintMu.RLocker(),
+		OnProgress: func(p document.SummaryProgress) {
+			if sendErr == nil {
+				if sendErr = stream.Send(summaryProgressToPb(p)); sendErr != nil {
+					cancel()
+				}
+			}
+		},
+	}
+	sel := document.SummarySelector{NodeIDs: req.NodeIds, SectionPath: req.SectionPath, Force: req.Force}
+	progress, err := s.docStore.RefreshSummaries(ctx, req.PolicyId, sel, s.summarizer, opts)
+	if sendErr != nil {
+		return sendErr
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to refresh summaries: %v", err)
+	}
+
+	// Nothing selected still reports the empty refresh
+	if progress.Total == 0 {
+		return stream.Send(summaryProgressToPb(*progress))
+	}
+	return nil
+}
+
+// summaryProgressToPb converts a refresh's progress
+func summaryProgressToPb(p document.SummaryProgress) *pb.RefreshSummariesProgress {
+	return &pb.RefreshSummariesProgress{
+		Total:   int32(p.Total),
+		Done:    int32(p.Done),
+		Updated: int32(p.Updated),
+		Skipped: int32(p.Skipped),
+	}
+}
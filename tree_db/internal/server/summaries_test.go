@@ -0,0 +1,101 @@
+// Tests for recomputing summaries with an external summarizer
+package server
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nainya/treestore/pkg/document"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// titleSummarizer summarizes a node as its title and text
+type titleSummarizer struct{}
+
+func (titleSummarizer) Summarize(ctx context.Context, nodes []*document.Node) ([]string, error) {
+	summaries := make([]string, len(nodes))
+	for i, node := range nodes {
+		summaries[i] = node.Title + ": " + node.Text
+	}
+	return summaries, nil
+}
+
+func TestRefreshSummaries(t *testing.T) {
+	s, client, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	refresh := func(req *pb.RefreshSummariesRequest) ([]*pb.RefreshSummariesProgress, error) {
+		stream, err := client.RefreshSummaries(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		var updates []*pb.RefreshSummariesProgress
+		for {
+			p, err := stream.Recv()
+			if err == io.EOF {
+				return updates, nil
+			}
+			if err != nil {
+				return updates, err
+			}
+			updates = append(updates, p)
+		}
+	}
+
+	if _, err := refresh(&pb.RefreshSummariesRequest{PolicyId: "LCD-1"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition without a summarizer, got %v", err)
+	}
+	s.summarizer = titleSummarizer{}
+
+	_, err := client.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "LCD-1"},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "LCD-1", SectionPath: "1"},
+			{NodeId: "a", PolicyId: "LCD-1", ParentId: "root", SectionPath: "1.1", Title: "Scope", Text: "covered", Summary: "Old"},
+			{NodeId: "b", PolicyId: "LCD-1", ParentId: "root", SectionPath: "1.2", Title: "Codes", Text: "billing"},
+			{NodeId: "c", PolicyId: "LCD-1", ParentId: "root", SectionPath: "1.3", Title: "Limits", Text: "yearly"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	// Only b and c lack a current summary; each batch reports its progress
+	updates, err := refresh(&pb.RefreshSummariesRequest{PolicyId: "LCD-1", BatchSize: 1})
+	if err != nil || len(updates) != 2 || updates[1].Done != 2 || updates[1].Total != 2 || updates[1].Updated != 2 {
+		t.Fatalf("Expected two progress updates, got %v (%v)", updates, err)
+	}
+	resp, err := client.GetNode(ctx, &pb.GetNodeRequest{PolicyId: "LCD-1", NodeId: "b"})
+	if err != nil || resp.Node.Summary != "Codes: billing" || resp.Node.SummaryChecksum != resp.Node.Checksum {
+		t.Errorf("Expected b summarized, got %v (%v)", resp, err)
+	}
+
+	// Forcing a section refreshes its current summaries; nothing selected still reports
+	updates, err = refresh(&pb.RefreshSummariesRequest{PolicyId: "LCD-1", SectionPath: "1.1", Force: true})
+	if err != nil || len(updates) != 1 || updates[0].Updated != 1 {
+		t.Errorf("Expected a refreshed, got %v (%v)", updates, err)
+	}
+	updates, err = refresh(&pb.RefreshSummariesRequest{PolicyId: "LCD-1"})
+	if err != nil || len(updates) != 1 || updates[0].Total != 0 {
+		t.Errorf("Expected an empty refresh reported, got %v (%v)", updates, err)
+	}
+
+	// Followers reject the stream like any other write
+	s.readOnly.Store(true)
+	defer s.readOnly.Store(false)
+	info := &grpc.StreamServerInfo{FullMethod: "/treestore.TreeStoreService/RefreshSummaries"}
+	err = s.ReadOnlyStreamInterceptor()(nil, &contextStream{ctx: ctx}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		t.Error("Expected the handler not to run on a follower")
+		return nil
+	})
+	if status.Code(err) != codes.FailedPrecondition || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("Expected FailedPrecondition on a follower, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+// Storage usage by store and policy, for finding what fills the database file
+package server
+
+import (
+	"context"
+	"os"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/metadata"
+	"github.com/nainya/treestore/pkg/storage"
+	"github.com/nainya/treestore/pkg/version"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// Stores that usage is reported for
+const (
+	storeDocuments     = "documents"
+	storeVersions      = "versions"
+	storeMetadata      = "metadata"
+	storeConversations = "conversations"
+	storeOther         = "other"
+)
+
+// policyColumn gives, for key prefixes whose records belong to a policy, the
+// position of the policy ID among the key's values
+var policyColumn = map[uint32]int{
+	document.PREFIX_NODE:              0,
+	document.PREFIX_TEXT:              0,
+	document.PREFIX_CHILDREN:          0,
+	document.PREFIX_TERM:              1, // (term, policyID, nodeID)
+	document.PREFIX_EMBEDDING:         0,
+	document.PREFIX_BLOOM:             0,
+	document.PREFIX_ROOT:              0,
+	document.PREFIX_SIBLING_ORDER:     0,
+	version.PREFIX_VERSION:            0,
+	version.PREFIX_VERSION_TIME:       0,
+	version.PREFIX_VERSION_TAG:        0,
+	version.PREFIX_LATEST_VERSION:     0,
+	version.PREFIX_VERSION_EFFECT:     0,
+	metadata.PREFIX_REFERENCE:         0,
+	metadata.PREFIX_REFERENCE_TARGET:  2, // Counted against the source policy
+	metadata.PREFIX_COLLECTION_MEMBER: 1, // (name, policyID)
+	metadata.PREFIX_COLLECTION_POLICY: 0,
+}
+
+// storeOf names the store owning a key prefix; each store keeps its prefixes
+// within one block of a thousand
+func storeOf(prefix uint32) string {
+	switch prefix / 1000 {
+	case 1, 2, 3, 4, 5:
+		return storeDocuments
+	case 6:
+		return storeVersions
+	case 7:
+		return storeMetadata
+	case 8:
+		return storeConversations
+	default:
+		return storeOther
+	}
+}
+
+// usage accumulates the keys and bytes of one store
+type usage struct {
+	keys, bytes int64
+}
+
+func (s *Server) StorageBreakdown(ctx context.Context, req *pb.StorageBreakdownRequest) (*pb.StorageBreakdownResponse, error) {
+	s.countOp("StorageBreakdown")
+
+	stores := make(map[string]*usage)
+	policies := make(map[string]map[string]*usage)
+	add := func(m map[string]*usage, store string, n int64) {
+		u := m[store]
+		if u == nil {
+			u = &usage{}
+			m[store] = u
+		}
+		u.keys++
+		u.bytes += n
+	}
+
+	err := s.kv.Scan(nil, func(key, val []byte) bool {
+		if err := ctx.Err(); err != nil {
+			return false
+		}
+		n := int64(len(key) + len(val))
+		if len(key) < 4 {
+			add(stores, storeOther, n)
+			return true
+		}
+		prefix := storage.ExtractPrefix(key)
+		store := storeOf(prefix)
+		add(stores, store, n)
+
+		col, ok := policyColumn[prefix]
+		if !ok {
+			return true
+		}
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) <= col {
+			return true
+		}
+		policyID := string(vals[col].Str)
+		if req.PolicyId != "" && policyID != req.PolicyId {
+			return true
+		}
+		if policies[policyID] == nil {
+			policies[policyID] = make(map[string]*usage)
+		}
+		add(policies[policyID], store, n)
+		return true
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to scan database: %v", err)
+	}
+
+	resp := &pb.StorageBreakdownResponse{Stores: storeUsages(stores)}
+	for _, u := range resp.Stores {
+		resp.TotalBytes += u.Bytes
+	}
+	if !s.kv.InMemory() {
+		if fileInfo, err := os.Stat(s.kv.Path); err == nil {
+			resp.DbSizeBytes = fileInfo.Size()
+		}
+	}
+
+	for policyID, byStore := range policies {
+		p := &pb.PolicyUsage{PolicyId: policyID, Stores: storeUsages(byStore)}
+		for _, u := range p.Stores {
+			p.Bytes += u.Bytes
+		}
+		resp.Policies = append(resp.Policies, p)
+	}
+	sort.Slice(resp.Policies, func(i, j int) bool {
+		a, b := resp.Policies[i], resp.Policies[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return a.PolicyId < b.PolicyId
+	})
+	if req.Limit > 0 && len(resp.Policies) > int(req.Limit) {
+		resp.Policies = resp.Policies[:req.Limit]
+	}
+	return resp, nil
+}
+
+// storeUsages converts per-store totals to messages, largest first
+func storeUsages(m map[string]*usage) []*pb.StoreUsage {
+	out := make([]*pb.StoreUsage, 0, len(m))
+	for store, u := range m {
+		out = append(out, &pb.StoreUsage{Store: store, Keys: u.keys, Bytes: u.bytes})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Bytes != out[j].Bytes {
+			return out[i].Bytes > out[j].Bytes
+		}
+		return out[i].Store < out[j].Store
+	})
+	return out
+}
@@ -0,0 +1,238 @@
+// Request validation applied before handlers reach the storage layer
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+// Limits bounds the size and shape of incoming requests
+// A zero field disables that check.
+type Limits struct {
+	MaxNodesPerDocument int // Nodes in one StoreDocument request
+	MaxNodeBytes        int // Title, summary, text and section path of one node combined
+	MaxDepth            int // Depth of a node, whether declared or implied by its parents in the request
+	MaxIDBytes          int // Length of any ID field
+	MaxAttributes       int // Attributes in one BatchSetMetadata request
+}
+
+// DefaultLimits keeps every node record within what a B+Tree page can hold
+var DefaultLimits = Limits{
+	MaxNodesPerDocument: 10000,
+	MaxNodeBytes:        1024,
+	MaxDepth:            64,
+	MaxIDBytes:          128,
+	MaxAttributes:       256,
+}
+
+// ValidationInterceptor rejects malformed unary requests with InvalidArgument
+// The status carries a BadRequest detail listing every violating field.
+func ValidationInterceptor(limits Limits) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if msg, ok := req.(proto.Message); ok {
+			if err := limits.Validate(msg); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStreamInterceptor applies the same checks to streaming requests
+func ValidationStreamInterceptor(limits Limits) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &validatingStream{ServerStream: ss, limits: limits})
+	}
+}
+
+// validatingStream validates each message received from the client
+type validatingStream struct {
+	grpc.ServerStream
+	limits Limits
+}
+
+func (vs *validatingStream) RecvMsg(m interface{}) error {
+	if err := vs.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if msg, ok := m.(proto.Message); ok {
+		return vs.limits.Validate(msg)
+	}
+	return nil
+}
+
+// Validate checks a request against the limits
+// It returns nil or an InvalidArgument status with field-level details.
+func (l Limits) Validate(msg proto.Message) error {
+	v := &validator{limits: l}
+	v.checkIDs("", msg.ProtoReflect())
+
+	switch req := msg.(type) {
+	case *pb.StoreDocumentRequest:
+		v.checkDocument(req)
+	case *pb.UpdateNodeRequest:
+		if req.Node != nil {
+			v.checkNode("node", req.Node)
+		}
+	case *pb.BatchSetMetadataRequest:
+		if l.MaxAttributes > 0 && len(req.Attributes) > l.MaxAttributes {
+			v.add("attributes", fmt.Sprintf("has %d entries; at most %d allowed", len(req.Attributes), l.MaxAttributes))
+		}
+		for key := range req.Attributes {
+			v.checkID(fmt.Sprintf("attributes[%q]", key), key)
+		}
+	}
+
+	if len(v.violations) == 0 {
+		return nil
+	}
+	first := v.violations[0]
+	st := status.New(codes.InvalidArgument, fmt.Sprintf("invalid request: %s %s", first.Field, first.Description))
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: v.violations}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// validator collects field violations for one request
+type validator struct {
+	limits     Limits
+	violations []*errdetails.BadRequest_FieldViolation
+}
+
+func (v *validator) add(field, description string) {
+	v.violations = append(v.violations, &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	})
+}
+
+// isIDField reports whether a field holds an identifier that ends up in storage keys
+func isIDField(fd protoreflect.FieldDescriptor) bool {
+	if fd.Kind() != protoreflect.StringKind {
+		return false
+	}
+	name := string(fd.Name())
+	return strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "_ids") ||
+		strings.Contains(name, "_id_") || name == "entity_type"
+}
+
+// checkIDs walks a message and checks every ID field, including nested messages
+func (v *validator) checkIDs(prefix string, m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, val protoreflect.Value) bool {
+		path := string(fd.Name())
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Kind() == protoreflect.MessageKind {
+				val.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+					v.checkIDs(fmt.Sprintf("%s[%q]", path, k.String()), mv.Message())
+					return true
+				})
+			}
+		case fd.IsList():
+			list := val.List()
+			for i := 0; i < list.Len(); i++ {
+				elem := fmt.Sprintf("%s[%d]", path, i)
+				if fd.Kind() == protoreflect.MessageKind {
+					v.checkIDs(elem, list.Get(i).Message())
+				} else if isIDField(fd) {
+					v.checkID(elem, list.Get(i).String())
+				}
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			v.checkIDs(path, val.Message())
+		case isIDField(fd):
+			v.checkID(path, val.String())
+		}
+		return true
+	})
+}
+
+// checkID rejects IDs that would break key encoding or exceed the length limit
+func (v *validator) checkID(field, id string) {
+	if strings.IndexByte(id, 0) >= 0 {
+		v.add(field, "must not contain null bytes")
+	}
+	if v.limits.MaxIDBytes > 0 && len(id) > v.limits.MaxIDBytes {
+		v.add(field, fmt.Sprintf("is %d bytes; at most %d allowed", len(id), v.limits.MaxIDBytes))
+	}
+}
+
+// checkDocument validates the nodes of a StoreDocument request and the depth
+// their parent links imply
+func (v *validator) checkDocument(req *pb.StoreDocumentRequest) {
+	if v.limits.MaxNodesPerDocument > 0 && len(req.Nodes) > v.limits.MaxNodesPerDocument {
+		v.add("nodes", fmt.Sprintf("has %d entries; at most %d allowed", len(req.Nodes), v.limits.MaxNodesPerDocument))
+		return
+	}
+
+	parents := make(map[string]string, len(req.Nodes))
+	for _, n := range req.Nodes {
+		if n != nil && n.ParentId != "" {
+			parents[n.NodeId] = n.ParentId
+		}
+	}
+
+	for i, n := range req.Nodes {
+		field := fmt.Sprintf("nodes[%d]", i)
+		if n == nil {
+			continue
+		}
+		v.checkNode(field, n)
+
+		// Follow parent links within the request; a chain longer than the
+		// number of nodes can only be a cycle
+		limit := len(req.Nodes)
+		if v.limits.MaxDepth > 0 && v.limits.MaxDepth < limit {
+			limit = v.limits.MaxDepth
+		}
+		depth := 0
+		for id, ok := parents[n.NodeId]; ok && depth <= limit; id, ok = parents[id] {
+			depth++
+		}
+		if depth > limit {
+			if v.limits.MaxDepth > 0 && depth > v.limits.MaxDepth {
+				v.add(field+".parent_id", fmt.Sprintf("places the node deeper than %d levels", v.limits.MaxDepth))
+			} else {
+				v.add(field+".parent_id", "forms a cycle")
+			}
+		}
+	}
+}
+
+// checkNode validates the size and declared depth of a single node
+func (v *validator) checkNode(field string, n *pb.Node) {
+	size := len(n.Title) + len(n.Summary) + len(n.Text) + len(n.SectionPath)
+	if v.limits.MaxNodeBytes > 0 && size > v.limits.MaxNodeBytes {
+		v.add(field, fmt.Sprintf("has %d bytes of title, summary, text and section path; at most %d allowed", size, v.limits.MaxNodeBytes))
+	}
+	if n.Depth < 0 {
+		v.add(field+".depth", "must not be negative")
+	} else if v.limits.MaxDepth > 0 && int(n.Depth) > v.limits.MaxDepth {
+		v.add(field+".depth", fmt.Sprintf("is %d; at most %d allowed", n.Depth, v.limits.MaxDepth))
+	}
+}
@@ -0,0 +1,163 @@
+// Tests for request validation limits
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+// violations returns the field paths reported in a validation error
+func violations(t *testing.T, err error) []string {
+	t.Helper()
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument, got %v", err)
+	}
+	var fields []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.FieldViolations {
+				fields = append(fields, v.Field)
+			}
+		}
+	}
+	return fields
+}
+
+func TestValidateRejectsBadFields(t *testing.T) {
+	limits := Limits{MaxNodesPerDocument: 3, MaxNodeBytes: 16, MaxDepth: 2, MaxIDBytes: 8, MaxAttributes: 2}
+
+	tests := []struct {
+		name   string
+		req    proto.Message
+		fields []string
+	}{
+		{
+			name:   "null byte in nested id",
+			req:    &pb.StoreDocumentRequest{Document: &pb.Document{PolicyId: "P"}, Nodes: []*pb.Node{{NodeId: "a\x00b", PolicyId: "P"}}},
+			fields: []string{"nodes[0].node_id"},
+		},
+		{
+			name:   "long id",
+			req:    &pb.GetNodeRequest{PolicyId: "POLICY-123456", NodeId: "root"},
+			fields: []string{"policy_id"},
+		},
+		{
+			name:   "repeated ids",
+			req:    &pb.BatchGetVersionsAsOfRequest{PolicyIds: []string{"ok", "bad\x00"}},
+			fields: []string{"policy_ids[1]"},
+		},
+		{
+			name:   "too many nodes",
+			req:    &pb.StoreDocumentRequest{Nodes: []*pb.Node{{NodeId: "a"}, {NodeId: "b"}, {NodeId: "c"}, {NodeId: "d"}}},
+			fields: []string{"nodes"},
+		},
+		{
+			name:   "oversized node",
+			req:    &pb.UpdateNodeRequest{Node: &pb.Node{NodeId: "a", PolicyId: "P", Title: "title", Text: strings.Repeat("x", 12)}},
+			fields: []string{"node"},
+		},
+		{
+			name:   "declared depth",
+			req:    &pb.UpdateNodeRequest{Node: &pb.Node{NodeId: "a", PolicyId: "P", Depth: 3}},
+			fields: []string{"node.depth"},
+		},
+		{
+			name: "implied depth",
+			req: &pb.StoreDocumentRequest{Nodes: []*pb.Node{
+				{NodeId: "a"}, {NodeId: "b", ParentId: "a"}, {NodeId: "c", ParentId: "b"},
+			}},
+			fields: nil,
+		},
+		{
+			name: "cycle",
+			req: &pb.StoreDocumentRequest{Nodes: []*pb.Node{
+				{NodeId: "a", ParentId: "b"}, {NodeId: "b", ParentId: "a"},
+			}},
+			fields: []string{"nodes[0].parent_id", "nodes[1].parent_id"},
+		},
+		{
+			name:   "attribute key",
+			req:    &pb.BatchSetMetadataRequest{EntityType: "doc", EntityId: "1", Attributes: map[string]string{"k\x00": "v"}},
+			fields: []string{`attributes["k\x00"]`},
+		},
+		{
+			name:   "too many attributes",
+			req:    &pb.BatchSetMetadataRequest{EntityType: "doc", EntityId: "1", Attributes: map[string]string{"a": "1", "b": "2", "c": "3"}},
+			fields: []string{"attributes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := limits.Validate(tt.req)
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("Expected valid request, got %v", err)
+				}
+				return
+			}
+			got := violations(t, err)
+			if strings.Join(got, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("Expected violations %v, got %v", tt.fields, got)
+			}
+		})
+	}
+
+	// One more level pushes the chain past MaxDepth
+	deep := &pb.StoreDocumentRequest{Nodes: []*pb.Node{
+		{NodeId: "a"}, {NodeId: "b", ParentId: "a"}, {NodeId: "c", ParentId: "b"},
+	}}
+	limits.MaxDepth = 1
+	if got := violations(t, limits.Validate(deep)); len(got) != 1 || got[0] != "nodes[2].parent_id" {
+		t.Errorf("Expected depth violation on nodes[2], got %v", got)
+	}
+}
+
+func TestValidationInterceptor(t *testing.T) {
+	server, client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	intercept := ValidationInterceptor(DefaultLimits)
+	info := &grpc.UnaryServerInfo{FullMethod: "/treestore.TreeStoreService/StoreDocument"}
+	store := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return server.StoreDocument(ctx, req.(*pb.StoreDocumentRequest))
+	}
+
+	// The largest node the default limits admit must still fit in storage
+	policyID := strings.Repeat("p", DefaultLimits.MaxIDBytes)
+	rootID := strings.Repeat("r", DefaultLimits.MaxIDBytes)
+	childID := strings.Repeat("c", DefaultLimits.MaxIDBytes)
+	req := &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: policyID, RootNodeId: rootID},
+		Nodes: []*pb.Node{
+			{NodeId: rootID, PolicyId: policyID, Title: "Root"},
+			{NodeId: childID, PolicyId: policyID, ParentId: rootID, Depth: 1, Text: strings.Repeat("t", DefaultLimits.MaxNodeBytes)},
+		},
+	}
+	if _, err := intercept(ctx, req, info, store); err != nil {
+		t.Fatalf("StoreDocument at the limits failed: %v", err)
+	}
+	resp, err := client.GetNode(ctx, &pb.GetNodeRequest{PolicyId: policyID, NodeId: childID})
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if len(resp.Node.Text) != DefaultLimits.MaxNodeBytes {
+		t.Errorf("Expected %d bytes of text, got %d", DefaultLimits.MaxNodeBytes, len(resp.Node.Text))
+	}
+
+	req.Nodes[1].Text += "t"
+	if _, err := intercept(ctx, req, info, store); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument past the limit, got %v", err)
+	}
+}
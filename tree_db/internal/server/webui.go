@@ -0,0 +1,165 @@
+// Read-only web UI for browsing documents, searches and versions
+package server
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+// WebUIPath is where the web UI is mounted
+const WebUIPath = "/ui/"
+
+// defaultUIPolicies is how many policies the UI lists at a time
+const defaultUIPolicies = 100
+
+//go:embed webui/index.html
+var webUIPage []byte
+
+// uiJSON encodes API responses with the field names of the proto files
+var uiJSON = protojson.MarshalOptions{UseProtoNames: true}
+
+// httpStatus gives the HTTP status of a gRPC code returned to the UI
+var httpStatus = map[codes.Code]int{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.NotFound:           http.StatusNotFound,
+	codes.FailedPrecondition: http.StatusConflict,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+}
+
+// WebUI returns a handler serving a browser of policies, node trees, keyword
+// search and versions under WebUIPath. The page calls a small JSON API that
+// runs the RPC handlers directly, bypassing the interceptors, so there is no
+// access control: mount it only on a port reachable by trusted operators.
+func (s *Server) WebUI() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(WebUIPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != WebUIPath {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(webUIPage)
+	})
+
+	mux.HandleFunc(WebUIPath+"api/policies", uiHandler(func(ctx context.Context, q url.Values) (interface{}, error) {
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		if limit <= 0 {
+			limit = defaultUIPolicies
+		}
+		policies, err := s.docStore.WithContext(ctx).ListPolicies(q.Get("after"), limit)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to list policies: %v", err)
+		}
+		if policies == nil {
+			policies = []string{}
+		}
+		return map[string]interface{}{"policies": policies, "more": len(policies) == limit}, nil
+	}))
+
+	mux.HandleFunc(WebUIPath+"api/children", uiHandler(func(ctx context.Context, q url.Values) (interface{}, error) {
+		return s.GetChildren(ctx, &pb.GetChildrenRequest{PolicyId: q.Get("policy"), ParentId: q.Get("parent")})
+	}))
+
+	mux.HandleFunc(WebUIPath+"api/node", uiHandler(func(ctx context.Context, q url.Values) (interface{}, error) {
+		return s.uiNode(ctx, q.Get("policy"), q.Get("node"))
+	}))
+
+	mux.HandleFunc(WebUIPath+"api/search", uiHandler(func(ctx context.Context, q url.Values) (interface{}, error) {
+		if policyID := q.Get("policy"); policyID != "" {
+			return s.SearchByKeyword(ctx, &pb.SearchRequest{PolicyId: policyID, Query: q.Get("q"), Limit: 50})
+		}
+		return s.GlobalSearch(ctx, &pb.GlobalSearchRequest{Query: q.Get("q"), PerPolicyLimit: 10, MaxPolicies: 20})
+	}))
+
+	mux.HandleFunc(WebUIPath+"api/versions", uiHandler(func(ctx context.Context, q url.Values) (interface{}, error) {
+		return s.ListVersions(ctx, &pb.ListVersionsRequest{PolicyId: q.Get("policy"), Limit: 50})
+	}))
+
+	return mux
+}
+
+// uiNode gathers a node with its metadata and cross-references
+func (s *Server) uiNode(ctx context.Context, policyID, nodeID string) (interface{}, error) {
+	node, err := s.GetNode(ctx, &pb.GetNodeRequest{PolicyId: policyID, NodeId: nodeID})
+	if err != nil {
+		return nil, err
+	}
+	refs, err := s.GetCrossReferences(ctx, &pb.GetCrossReferencesRequest{PolicyId: policyID, NodeId: nodeID})
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := s.metaStore.GetAllMetadata("node", nodeID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read metadata: %v", err)
+	}
+
+	out := map[string]interface{}{"metadata": attrs}
+	for key, msg := range map[string]proto.Message{"node": node.Node, "references": refs} {
+		data, err := uiJSON.Marshal(msg)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to encode %s: %v", key, err)
+		}
+		out[key] = json.RawMessage(data)
+	}
+	return out, nil
+}
+
+// uiHandler serves the result of fn as JSON, and its error as a JSON object
+// with the HTTP status matching the gRPC code
+func uiHandler(fn func(ctx context.Context, q url.Values) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		result, err := fn(r.Context(), r.URL.Query())
+		var data []byte
+		if err == nil {
+			data, err = marshalResult(result)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			writeJSONError(w, err)
+			return
+		}
+		w.Write(data)
+	}
+}
+
+// marshalResult encodes a handler's result, with uiJSON when it is a message
+func marshalResult(result interface{}) ([]byte, error) {
+	if msg, ok := result.(proto.Message); ok {
+		return uiJSON.Marshal(msg)
+	}
+	return json.Marshal(result)
+}
+
+// writeJSONError writes an error as a JSON object with the HTTP status
+// matching its gRPC code
+func writeJSONError(w http.ResponseWriter, err error) {
+	code, ok := httpStatus[status.Code(err)]
+	if !ok {
+		code = http.StatusInternalServerError
+	}
+	w.WriteHeader(code)
+	data, _ := json.Marshal(map[string]string{"error": status.Convert(err).Message()})
+	w.Write(data)
+}
@@ -0,0 +1,131 @@
+// Tests for the web UI
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nainya/treestore/pkg/metadata"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestWebUI(t *testing.T) {
+	server, err := NewServer(filepath.Join(t.TempDir(), "webui.db"))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+
+	now := timestamppb.Now()
+	_, err = server.StoreDocument(context.Background(), &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "POL-UI", RootNodeId: "root", CreatedAt: now, UpdatedAt: now},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "POL-UI", Title: "Coverage", ChildIds: []string{"sec1"}, CreatedAt: now, UpdatedAt: now},
+			{NodeId: "sec1", PolicyId: "POL-UI", ParentId: "root", Title: "Prior authorization", Text: "Imaging requires prior authorization", Depth: 1, CreatedAt: now, UpdatedAt: now},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	if err := server.metaStore.SetMetadata(&metadata.MetadataEntry{EntityType: "node", EntityID: "sec1", Key: "reviewer", Value: "clinical", ValueType: "string"}); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+
+	ui := httptest.NewServer(server.WebUI())
+	defer ui.Close()
+
+	get := func(path string, wantStatus int, out interface{}) {
+		t.Helper()
+		resp, err := http.Get(ui.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("GET %s: expected status %d, got %d", path, wantStatus, resp.StatusCode)
+		}
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				t.Fatalf("GET %s: bad JSON: %v", path, err)
+			}
+		}
+	}
+
+	resp, err := http.Get(ui.URL + WebUIPath)
+	if err != nil {
+		t.Fatalf("GET page failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("Expected the HTML page, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	var policies struct {
+		Policies []string `json:"policies"`
+		More     bool     `json:"more"`
+	}
+	get("/ui/api/policies", http.StatusOK, &policies)
+	if len(policies.Policies) != 1 || policies.Policies[0] != "POL-UI" || policies.More {
+		t.Errorf("Unexpected policies %+v", policies)
+	}
+
+	var children struct {
+		Children []struct {
+			NodeID string `json:"node_id"`
+		} `json:"children"`
+	}
+	get("/ui/api/children?policy=POL-UI&parent=root", http.StatusOK, &children)
+	if len(children.Children) != 1 || children.Children[0].NodeID != "sec1" {
+		t.Errorf("Unexpected children %+v", children)
+	}
+
+	var node struct {
+		Node struct {
+			Text string `json:"text"`
+		} `json:"node"`
+		Metadata map[string]string `json:"metadata"`
+	}
+	get("/ui/api/node?policy=POL-UI&node=sec1", http.StatusOK, &node)
+	if node.Node.Text != "Imaging requires prior authorization" || node.Metadata["reviewer"] != "clinical" {
+		t.Errorf("Unexpected node %+v", node)
+	}
+
+	var search struct {
+		Results []struct {
+			Node struct {
+				NodeID string `json:"node_id"`
+			} `json:"node"`
+		} `json:"results"`
+	}
+	get("/ui/api/search?policy=POL-UI&q=imaging", http.StatusOK, &search)
+	if len(search.Results) != 1 || search.Results[0].Node.NodeID != "sec1" {
+		t.Errorf("Unexpected search results %+v", search)
+	}
+	get("/ui/api/search?q=imaging", http.StatusOK, nil)
+	get("/ui/api/versions?policy=POL-UI", http.StatusOK, nil)
+
+	var failure struct {
+		Error string `json:"error"`
+	}
+	get("/ui/api/node?policy=POL-UI&node=missing", http.StatusNotFound, &failure)
+	if failure.Error == "" {
+		t.Error("Expected an error message for a missing node")
+	}
+	get("/ui/api/children", http.StatusBadRequest, nil)
+
+	post, err := http.Post(ui.URL+"/ui/api/policies", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	post.Body.Close()
+	if post.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", post.StatusCode)
+	}
+}
@@ -0,0 +1,102 @@
+// References found in the text of nodes as their documents are stored
+package server
+
+import (
+	"time"
+
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/metadata"
+)
+
+// extractReferences stores the cross-references the server's extractor finds
+// in the text of nodes, returning how many it stored. Mentions of a section
+// or policy not stored, and of the node itself, are skipped; a mention of a
+// policy alone points at its root.
+func (s *Server) extractReferences(nodes []*document.Node) (int, error) {
+	targets := &referenceTargets{
+		store:    s.docStore.WithoutFields(document.FieldSummary | document.FieldText),
+		policies: make(map[string]*policyTargets),
+	}
+	now := time.Now()
+	stored := 0
+	for _, node := range nodes {
+		seen := make(map[[2]string]bool)
+		for _, m := range s.references.Find(node.Text) {
+			policyID := m.PolicyID
+			if policyID == "" {
+				policyID = node.PolicyID
+			}
+			targetID, ok := targets.resolve(policyID, m.Section)
+			if !ok || policyID == node.PolicyID && targetID == node.NodeID || seen[[2]string{policyID, targetID}] {
+				continue
+			}
+			seen[[2]string{policyID, targetID}] = true
+
+			err := s.metaStore.AddCrossReference(&metadata.CrossReference{
+				SourcePolicyID: node.PolicyID,
+				SourceNodeID:   node.NodeID,
+				TargetPolicyID: policyID,
+				TargetNodeID:   targetID,
+				ReferenceType:  m.Type,
+				Context:        m.Context,
+				CreatedAt:      now,
+			})
+			if err != nil {
+				return stored, err
+			}
+			stored++
+		}
+	}
+	return stored, nil
+}
+
+// referenceTargets resolves mentions to node IDs, reading each policy's tree once
+type referenceTargets struct {
+	store    *document.SimpleStore
+	policies map[string]*policyTargets // nil for policies without nodes
+}
+
+// policyTargets are the nodes of a policy mentions can point at
+type policyTargets struct {
+	root     string
+	sections map[string]string // Section path to node ID; the first node in tree order wins
+}
+
+// resolve returns the node of a policy at a section path, or its root when
+// section is empty
+func (t *referenceTargets) resolve(policyID, section string) (string, bool) {
+	p, ok := t.policies[policyID]
+	if !ok {
+		p = t.load(policyID)
+		t.policies[policyID] = p
+	}
+	if p == nil {
+		return "", false
+	}
+	if section == "" {
+		return p.root, true
+	}
+	nodeID, ok := p.sections[section]
+	return nodeID, ok
+}
+
+// load reads the section paths of a policy's nodes, returning nil if it has none
+func (t *referenceTargets) load(policyID string) *policyTargets {
+	roots, err := t.store.GetChildren(policyID, nil)
+	if err != nil || len(roots) == 0 {
+		return nil
+	}
+	p := &policyTargets{root: roots[0].NodeID, sections: make(map[string]string)}
+	for _, root := range roots {
+		nodes, err := t.store.GetSubtree(policyID, root.NodeID, document.QueryOptions{})
+		if err != nil {
+			continue
+		}
+		for _, node := range nodes {
+			if _, ok := p.sections[node.SectionPath]; node.SectionPath != "" && !ok {
+				p.sections[node.SectionPath] = node.NodeID
+			}
+		}
+	}
+	return p
+}
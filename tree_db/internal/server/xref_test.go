@@ -0,0 +1,69 @@
+// Tests for cross-references found in node text on StoreDocument
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/nainya/treestore/pkg/xref"
+	pb "github.com/nainya/treestore/proto"
+)
+
+func TestExtractReferences(t *testing.T) {
+	extractor, err := xref.NewExtractor(xref.DefaultRules())
+	if err != nil {
+		t.Fatalf("NewExtractor failed: %v", err)
+	}
+	s, err := NewServerWithOptions(filepath.Join(t.TempDir(), "xref.db"), Options{References: extractor})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	_, err = s.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "ABC-12"},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "ABC-12", SectionPath: "1"},
+			{NodeId: "terms", PolicyId: "ABC-12", ParentId: "root", SectionPath: "1.1", Text: "Definitions"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	resp, err := s.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: &pb.Document{PolicyId: "LCD-1"},
+		Nodes: []*pb.Node{
+			{NodeId: "root", PolicyId: "LCD-1", SectionPath: "1"},
+			{NodeId: "scope", PolicyId: "LCD-1", ParentId: "root", SectionPath: "4.2", Text: "As defined in Policy ABC-12."},
+			{NodeId: "limits", PolicyId: "LCD-1", ParentId: "root", SectionPath: "4.3",
+				Text: "See Section 4.2, Section 1.1 of Policy ABC-12, see Section 4.2 again and see Section 9."},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	if resp.ReferencesExtracted != 3 {
+		t.Errorf("Expected 3 references, got %d", resp.ReferencesExtracted)
+	}
+
+	refs, err := s.metaStore.ReferencesFrom("LCD-1", "limits")
+	if err != nil || len(refs) != 2 {
+		t.Fatalf("Expected 2 references from limits, got %v (%v)", refs, err)
+	}
+	targets := map[string]bool{}
+	for _, ref := range refs {
+		targets[ref.TargetPolicyID+"/"+ref.TargetNodeID] = true
+		if ref.ReferenceType != xref.DefaultType || ref.Context == "" {
+			t.Errorf("Expected a cites reference with context, got %+v", ref)
+		}
+	}
+	if !targets["LCD-1/scope"] || !targets["ABC-12/terms"] {
+		t.Errorf("Expected references to scope and ABC-12's 1.1, got %v", targets)
+	}
+	if refs, _ := s.metaStore.ReferencesTo("ABC-12", "root"); len(refs) != 1 || refs[0].SourceNodeID != "scope" {
+		t.Errorf("Expected the policy mention to point at ABC-12's root, got %v", refs)
+	}
+}
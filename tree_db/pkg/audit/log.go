@@ -0,0 +1,226 @@
+// ABOUTME: Append-only audit log of mutating requests
+// ABOUTME: Records are keyed by (time, sequence), indexed by actor and optionally mirrored to JSONL
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// Prefixes for audit storage
+const (
+	PREFIX_AUDIT       = uint32(9100) // Records by (unixNanos, seq)
+	PREFIX_AUDIT_ACTOR = uint32(9200) // Index by (actor, unixNanos, seq)
+)
+
+// DefaultQueryLimit caps Query results when the filter sets no limit
+const DefaultQueryLimit = 100
+
+// Record describes one mutating request and its outcome
+type Record struct {
+	Time        time.Time `json:"time"`
+	Seq         uint64    `json:"seq"`
+	Method      string    `json:"method"`
+	Actor       string    `json:"actor"`
+	Entities    []string  `json:"entities,omitempty"` // IDs named in the request, e.g. "policy_id=LCD-1"
+	RequestHash string    `json:"request_hash"`       // Hex SHA-256 of the deterministically marshalled request
+	Code        string    `json:"code"`               // gRPC status code name
+	Error       string    `json:"error,omitempty"`
+	RequestID   string    `json:"request_id,omitempty"` // Correlates the record with the request's log events
+}
+
+// recordLayout starts every record stored since request IDs were added.
+// Older records start with the method, a bytes value, and have no request ID.
+const recordLayout = uint64(2)
+
+// Filter selects records for Query
+type Filter struct {
+	Start time.Time // Inclusive; zero means the beginning
+	End   time.Time // Exclusive; zero means no upper bound
+	Actor string    // Empty matches every actor
+	Limit int       // Maximum records; 0 means DefaultQueryLimit
+}
+
+// Log stores audit records; there is no way to modify or delete them
+type Log struct {
+	kv     storage.Engine
+	mu     sync.Mutex
+	seq    uint64
+	mirror io.Writer
+}
+
+// NewLog creates an audit log over kv
+func NewLog(kv storage.Engine) *Log {
+	return &Log{kv: kv}
+}
+
+// SetMirror also writes every appended record to w as one JSON line
+func (l *Log) SetMirror(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mirror = w
+}
+
+// Append stores a record, filling in its time if unset and its sequence number
+// The record is committed before it is mirrored; a mirror error is returned
+// but does not undo the stored record.
+func (l *Log) Append(r *Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	l.seq++
+	r.Seq = l.seq
+
+	entities := make([]storage.Value, 0, 7+len(r.Entities))
+	entities = append(entities, storage.NewUint64Value(recordLayout))
+	for _, s := range []string{r.Method, r.Actor, r.RequestHash, r.Code, r.Error, r.RequestID} {
+		entities = append(entities, storage.NewBytesValue([]byte(s)))
+	}
+	for _, e := range r.Entities {
+		entities = append(entities, storage.NewBytesValue([]byte(e)))
+	}
+
+	tx := l.kv.Begin()
+	defer tx.Abort()
+	tx.Set(recordKey(r.Time, r.Seq), storage.EncodeValues(entities))
+	tx.Set(storage.EncodeKey(PREFIX_AUDIT_ACTOR, []storage.Value{
+		storage.NewBytesValue([]byte(r.Actor)),
+		storage.NewInt64Value(r.Time.UnixNano()),
+		storage.NewUint64Value(r.Seq),
+	}), []byte{})
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to store audit record: %w", err)
+	}
+
+	if l.mirror != nil {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		if _, err := l.mirror.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("failed to mirror audit record: %w", err)
+		}
+	}
+	return nil
+}
+
+// Query returns matching records in time order
+func (l *Log) Query(f Filter) ([]*Record, error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	inRange := func(nanos int64) bool {
+		return f.End.IsZero() || nanos < f.End.UnixNano()
+	}
+
+	var records []*Record
+	var scanErr error
+
+	if f.Actor == "" {
+		start := storage.EncodeKey(PREFIX_AUDIT, []storage.Value{storage.NewInt64Value(startNanos(f.Start))})
+		if err := storage.ScanPrefixFrom(l.kv, storage.EncodeKey(PREFIX_AUDIT, nil), start, func(key, val []byte) bool {
+			vals, err := storage.ExtractValues(key)
+			if err != nil || len(vals) != 2 {
+				return true
+			}
+			if !inRange(vals[0].I64) {
+				return false
+			}
+			r, err := parseRecord(vals[0].I64, vals[1].U64, val)
+			if err != nil {
+				scanErr = err
+				return false
+			}
+			records = append(records, r)
+			return len(records) < limit
+		}); err != nil {
+			return nil, err
+		}
+		return records, scanErr
+	}
+
+	actor := []storage.Value{storage.NewBytesValue([]byte(f.Actor))}
+	startKey := storage.EncodeKey(PREFIX_AUDIT_ACTOR, append(actor, storage.NewInt64Value(startNanos(f.Start))))
+	if err := storage.ScanPrefixFrom(l.kv, storage.EncodeKey(PREFIX_AUDIT_ACTOR, actor), startKey, func(key, _ []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) != 3 {
+			return false
+		}
+		if !inRange(vals[1].I64) {
+			return false
+		}
+		val, ok, err := l.kv.Lookup(storage.EncodeKey(PREFIX_AUDIT, vals[1:]))
+		if err != nil {
+			scanErr = err
+			return false
+		}
+		if !ok {
+			return true
+		}
+		r, err := parseRecord(vals[1].I64, vals[2].U64, val)
+		if err != nil {
+			scanErr = err
+			return false
+		}
+		records = append(records, r)
+		return len(records) < limit
+	}); err != nil {
+		return nil, err
+	}
+	return records, scanErr
+}
+
+// recordKey returns the primary key of a record
+func recordKey(t time.Time, seq uint64) []byte {
+	return storage.EncodeKey(PREFIX_AUDIT, []storage.Value{
+		storage.NewInt64Value(t.UnixNano()),
+		storage.NewUint64Value(seq),
+	})
+}
+
+// startNanos converts an inclusive start time to a key bound
+func startNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return -1 << 63
+	}
+	return t.UnixNano()
+}
+
+// parseRecord decodes a stored record
+func parseRecord(nanos int64, seq uint64, val []byte) (*Record, error) {
+	vals, err := storage.DecodeValues(val)
+	fields := 5
+	if err == nil && len(vals) > 0 && vals[0].Type == storage.TYPE_UINT64 {
+		vals, fields = vals[1:], 6
+	}
+	if err != nil || len(vals) < fields {
+		return nil, fmt.Errorf("corrupt audit record at %d/%d: %v", nanos, seq, err)
+	}
+
+	r := &Record{
+		Time:        time.Unix(0, nanos),
+		Seq:         seq,
+		Method:      string(vals[0].Str),
+		Actor:       string(vals[1].Str),
+		RequestHash: string(vals[2].Str),
+		Code:        string(vals[3].Str),
+		Error:       string(vals[4].Str),
+	}
+	if fields == 6 {
+		r.RequestID = string(vals[5].Str)
+	}
+	for _, v := range vals[fields:] {
+		r.Entities = append(r.Entities, string(v.Str))
+	}
+	return r, nil
+}
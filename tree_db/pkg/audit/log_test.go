@@ -0,0 +1,124 @@
+// ABOUTME: Tests for the audit log
+// ABOUTME: Verifies append, time and actor filtering, and the JSONL mirror
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+func setupTestLog(t *testing.T) (*Log, *storage.KV, string) {
+	path := storage.MemoryPath
+	kv := &storage.KV{Path: path}
+	if err := kv.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+
+	return NewLog(kv), kv, path
+}
+
+func TestAppendAndQuery(t *testing.T) {
+	log, kv, path := setupTestLog(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	var mirror bytes.Buffer
+	log.SetMirror(&mirror)
+
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	records := []*Record{
+		{Time: base, Method: "StoreDocument", Actor: "alice", Entities: []string{"document.policy_id=LCD-1"}, RequestHash: "aa", Code: "OK"},
+		{Time: base.Add(time.Minute), Method: "DeleteDocument", Actor: "bob", Entities: []string{"policy_id=LCD-1"}, RequestHash: "bb", Code: "NotFound", Error: "document not found"},
+		{Time: base.Add(2 * time.Minute), Method: "TagVersion", Actor: "alice", RequestHash: "cc", Code: "OK", RequestID: "req-7"},
+	}
+	for _, r := range records {
+		if err := log.Append(r); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	all, err := log.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(all) != 3 || all[0].Method != "StoreDocument" || all[2].Method != "TagVersion" {
+		t.Fatalf("Expected all records in time order, got %+v", all)
+	}
+	if got := all[1]; got.Error != "document not found" || got.Code != "NotFound" || len(got.Entities) != 1 || !got.Time.Equal(base.Add(time.Minute)) {
+		t.Errorf("Record did not round-trip: %+v", got)
+	}
+
+	byActor, err := log.Query(Filter{Actor: "alice"})
+	if err != nil {
+		t.Fatalf("Query by actor failed: %v", err)
+	}
+	if len(byActor) != 2 || byActor[0].RequestHash != "aa" || byActor[1].RequestHash != "cc" {
+		t.Errorf("Unexpected records for alice: %+v", byActor)
+	}
+
+	window, _ := log.Query(Filter{Start: base.Add(time.Minute), End: base.Add(2 * time.Minute)})
+	if len(window) != 1 || window[0].Actor != "bob" {
+		t.Errorf("Expected only bob's record in the window, got %+v", window)
+	}
+
+	window, _ = log.Query(Filter{Actor: "alice", Start: base.Add(time.Second)})
+	if len(window) != 1 || window[0].Method != "TagVersion" {
+		t.Errorf("Expected alice's later record, got %+v", window)
+	}
+
+	if limited, _ := log.Query(Filter{Limit: 2}); len(limited) != 2 {
+		t.Errorf("Expected limit of 2, got %d", len(limited))
+	}
+
+	lines := strings.Split(strings.TrimSpace(mirror.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 mirrored lines, got %d", len(lines))
+	}
+	var first Record
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("Mirror line is not JSON: %v", err)
+	}
+	if first.Method != "StoreDocument" || first.Actor != "alice" || first.Seq != 1 {
+		t.Errorf("Unexpected mirrored record: %+v", first)
+	}
+	if all[2].RequestID != "req-7" || all[2].RequestHash != "cc" || all[0].RequestID != "" {
+		t.Errorf("Expected the request ID round-tripped, got %+v and %+v", all[2], all[0])
+	}
+}
+
+func TestQueryLegacyRecords(t *testing.T) {
+	log, kv, path := setupTestLog(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	// Records stored before request IDs start with the method
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var vals []storage.Value
+	for _, s := range []string{"StoreDocument", "alice", "aa", "OK", "", "policy_id=LCD-1"} {
+		vals = append(vals, storage.NewBytesValue([]byte(s)))
+	}
+	if err := kv.Set(recordKey(at, 1), storage.EncodeValues(vals)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := log.Append(&Record{Method: "TagVersion", Actor: "bob", Code: "OK", RequestID: "req-1", Entities: []string{"policy_id=LCD-2"}}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	records, err := log.Query(Filter{})
+	if err != nil || len(records) != 2 {
+		t.Fatalf("Expected both records, got %+v (%v)", records, err)
+	}
+	if r := records[0]; r.Method != "StoreDocument" || r.RequestID != "" || len(r.Entities) != 1 || r.Entities[0] != "policy_id=LCD-1" {
+		t.Errorf("Unexpected legacy record %+v", r)
+	}
+	if r := records[1]; r.Method != "TagVersion" || r.RequestID != "req-1" || len(r.Entities) != 1 || r.Entities[0] != "policy_id=LCD-2" {
+		t.Errorf("Unexpected record %+v", r)
+	}
+}
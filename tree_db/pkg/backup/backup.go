@@ -0,0 +1,403 @@
+// ABOUTME: Full and incremental backups built from a base snapshot plus WAL segments
+// ABOUTME: Restore copies the snapshot and replays committed transactions, from segments and WAL archives, up to an optional target
+
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nainya/treestore/pkg/replication"
+	"github.com/nainya/treestore/pkg/storage"
+	"github.com/nainya/treestore/pkg/wal"
+)
+
+const (
+	// SnapshotFile is the base copy of the database file
+	SnapshotFile = "base.db"
+
+	// ManifestFile describes the snapshot and the segments that follow it
+	ManifestFile = "manifest.json"
+
+	// restoreSource names the applied LSN kept in restored databases
+	restoreSource = "backup"
+)
+
+var (
+	// ErrNoBaseBackup indicates a backup directory without a base snapshot
+	ErrNoBaseBackup = errors.New("backup: no base snapshot")
+
+	// ErrBackupExists indicates a base snapshot would overwrite an existing backup
+	ErrBackupExists = errors.New("backup: directory already holds a backup")
+)
+
+// Manifest records what a backup directory contains
+// Segments are ordered and contiguous: each starts where the previous one ended.
+type Manifest struct {
+	BaseLSN   uint64    `json:"base_lsn"` // Last LSN contained in the snapshot
+	CreatedAt time.Time `json:"created_at"`
+	Segments  []Segment `json:"segments"`
+}
+
+// Segment is a file of WAL entries covering (FromLSN, ToLSN]
+type Segment struct {
+	File      string    `json:"file"`
+	FromLSN   uint64    `json:"from_lsn"`
+	ToLSN     uint64    `json:"to_lsn"` // LSN of the last commit in the segment
+	Entries   int       `json:"entries"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LastLSN returns the LSN the next incremental backup starts after
+func (m *Manifest) LastLSN() uint64 {
+	if len(m.Segments) == 0 {
+		return m.BaseLSN
+	}
+	return m.Segments[len(m.Segments)-1].ToLSN
+}
+
+// Full writes a base snapshot of kv to dir
+// It copies the database file, so it must not run concurrently with writes.
+func Full(kv *storage.KV, dir string) (*Manifest, error) {
+	if kv.InMemory() {
+		return nil, storage.ErrInMemory
+	}
+	if _, err := os.Stat(filepath.Join(dir, ManifestFile)); err == nil {
+		return nil, ErrBackupExists
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{BaseLSN: kv.WAL().LastLSN(), CreatedAt: time.Now()}
+	if err := copyFile(kv.Path, filepath.Join(dir, SnapshotFile)); err != nil {
+		return nil, fmt.Errorf("copy snapshot: %w", err)
+	}
+	if err := writeManifest(dir, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Incremental appends the WAL entries written since the last backup in dir
+// It only reads the log files of dbPath, so it is safe against a running server.
+// A transaction still in progress is left for the next backup. Fails with
+// wal.ErrLSNUnavailable when the needed entries were rotated out, in which case
+// a new base snapshot is required.
+func Incremental(dbPath, dir string) (*Segment, error) {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	log := &wal.WAL{Path: storage.WALPath(dbPath)}
+	tailer, err := log.Tail(m.LastLSN())
+	if err != nil {
+		return nil, err
+	}
+	defer tailer.Close()
+
+	seg := Segment{
+		File:      fmt.Sprintf("segment-%020d.wal", m.LastLSN()),
+		FromLSN:   m.LastLSN(),
+		ToLSN:     m.LastLSN(),
+		CreatedAt: time.Now(),
+	}
+
+	// Entries are buffered so the segment ends on a commit
+	var committed, pending []byte
+	var pendingEntries int
+	for {
+		entry, err := tailer.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		pending = append(pending, entry.Encode()...)
+		pendingEntries++
+		if entry.OpType == wal.OpCommit {
+			committed = append(committed, pending...)
+			seg.Entries += pendingEntries
+			seg.ToLSN = entry.LSN
+			pending, pendingEntries = pending[:0], 0
+		}
+	}
+
+	if seg.Entries == 0 {
+		return &seg, nil
+	}
+	if err := writeFileSync(filepath.Join(dir, seg.File), committed); err != nil {
+		return nil, err
+	}
+	m.Segments = append(m.Segments, seg)
+	if err := writeManifest(dir, m); err != nil {
+		return nil, err
+	}
+	return &seg, nil
+}
+
+// Restore rebuilds the database in dir at dbPath, which must not exist
+// It returns the LSN of the last restored commit.
+func Restore(dir, dbPath string) (uint64, error) {
+	report, err := RestoreTo(dir, dbPath, RestoreOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return report.LastLSN, nil
+}
+
+// RestoreOptions extend a restore past the backup's segments and stop it at
+// a point in time
+type RestoreOptions struct {
+	// Target stops the restore after the last commit it includes
+	Target wal.Target
+
+	// SourceDB is the path of the backed-up database. Its archived log files
+	// in ArchiveDir, then its live log files if present, are replayed after
+	// the segments.
+	SourceDB   string
+	ArchiveDir string
+}
+
+// RestoreReport describes what a restore replayed
+type RestoreReport struct {
+	LastLSN    uint64    // LSN of the last restored commit
+	LastCommit time.Time // Its timestamp; zero if only the snapshot was restored
+	Commits    int       // Transactions replayed on top of the snapshot
+	StoppedAt  uint64    // First commit past the target, left out; 0 if none was read
+}
+
+// RestoreTo rebuilds the database in dir at dbPath, which must not exist,
+// replaying its segments, then any log files of opts.SourceDB, up to
+// opts.Target. A restore that runs out of log before the target stops at
+// the last commit read; a gap between log files fails it.
+func RestoreTo(dir, dbPath string, opts RestoreOptions) (*RestoreReport, error) {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dbPath); err == nil {
+		return nil, fmt.Errorf("restore target %s already exists", dbPath)
+	}
+	if opts.ArchiveDir != "" && opts.SourceDB == "" {
+		return nil, fmt.Errorf("restoring from WAL archives needs the source database path")
+	}
+	if opts.Target.LSN != 0 && opts.Target.LSN < m.BaseLSN {
+		return nil, fmt.Errorf("target LSN %d precedes the base snapshot at LSN %d", opts.Target.LSN, m.BaseLSN)
+	}
+
+	if err := copyFile(filepath.Join(dir, SnapshotFile), dbPath); err != nil {
+		return nil, fmt.Errorf("copy snapshot: %w", err)
+	}
+
+	kv := &storage.KV{Path: dbPath}
+	if err := kv.Open(); err != nil {
+		return nil, err
+	}
+	defer kv.Close()
+
+	applier, err := replication.NewApplier(kv, restoreSource)
+	if err != nil {
+		return nil, err
+	}
+	r := &restorer{applier: applier, target: opts.Target, seen: m.BaseLSN}
+	r.report.LastLSN = m.BaseLSN
+
+	lastLSN := m.BaseLSN
+	for _, seg := range m.Segments {
+		if seg.FromLSN != lastLSN {
+			return nil, fmt.Errorf("segment %s starts after LSN %d, want %d", seg.File, seg.FromLSN, lastLSN)
+		}
+
+		entries, err := wal.ReadAll([]string{filepath.Join(dir, seg.File)})
+		if err != nil {
+			return nil, fmt.Errorf("read segment %s: %w", seg.File, err)
+		}
+		if len(entries) != seg.Entries {
+			return nil, fmt.Errorf("segment %s has %d entries, manifest records %d", seg.File, len(entries), seg.Entries)
+		}
+
+		if err := r.replay(entries); err != nil {
+			return nil, err
+		}
+		if r.stopped() {
+			return &r.report, nil
+		}
+		if applier.AppliedLSN() != seg.ToLSN {
+			return nil, fmt.Errorf("segment %s ends at LSN %d, manifest records %d", seg.File, applier.AppliedLSN(), seg.ToLSN)
+		}
+		lastLSN = seg.ToLSN
+	}
+
+	if opts.SourceDB == "" {
+		return &r.report, nil
+	}
+	files, err := sourceLogFiles(opts.SourceDB, opts.ArchiveDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		entries, err := wal.ReadAll([]string{file})
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+		if len(entries) == 0 || entries[len(entries)-1].LSN <= r.seen {
+			continue // Already restored
+		}
+		if entries[0].LSN > r.seen+1 {
+			return nil, fmt.Errorf("%s starts at LSN %d, but the log before it ends at LSN %d; archives are missing", file, entries[0].LSN, r.seen)
+		}
+		if err := r.replay(entries); err != nil {
+			return nil, err
+		}
+		if r.stopped() {
+			break
+		}
+	}
+	return &r.report, nil
+}
+
+// restorer feeds log entries to an applier in LSN order until the target
+type restorer struct {
+	applier *replication.Applier
+	target  wal.Target
+	seen    uint64 // Highest LSN fed or restored in the snapshot
+	report  RestoreReport
+}
+
+func (r *restorer) stopped() bool {
+	return r.report.StoppedAt != 0
+}
+
+// replay applies the entries of one file that follow those already seen
+// A transaction whose commit was rolled back has its abort marker soon after,
+// in the same file, so it is left out entirely.
+func (r *restorer) replay(entries []*wal.Entry) error {
+	aborted := make(map[uint64]bool)
+	for _, entry := range entries {
+		if entry.OpType == wal.OpAbort {
+			aborted[entry.TxnID] = true
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.LSN <= r.seen {
+			continue
+		}
+		if aborted[entry.TxnID] && entry.OpType != wal.OpAbort {
+			r.seen = entry.LSN
+			continue
+		}
+		if entry.OpType == wal.OpCommit && !r.target.Includes(entry) {
+			r.report.StoppedAt = entry.LSN
+			return nil
+		}
+
+		if err := r.applier.Apply(entry); err != nil {
+			return fmt.Errorf("apply LSN %d: %w", entry.LSN, err)
+		}
+		r.seen = entry.LSN
+		if entry.OpType == wal.OpCommit {
+			r.report.LastLSN = entry.LSN
+			r.report.LastCommit = entry.Timestamp
+			r.report.Commits++
+		}
+	}
+	return nil
+}
+
+// sourceLogFiles lists the archived log files of a database, then its live
+// ones, in log order
+func sourceLogFiles(dbPath, archiveDir string) ([]string, error) {
+	walPath := storage.WALPath(dbPath)
+	var files []string
+	if archiveDir != "" {
+		archived, err := wal.ArchivedFiles(archiveDir, walPath)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, archived...)
+	}
+	live, err := wal.LogFiles(walPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return append(files, live...), nil
+}
+
+// ReadManifest loads the manifest of a backup directory
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if os.IsNotExist(err) {
+		return nil, ErrNoBaseBackup
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("corrupt manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// writeManifest replaces the manifest atomically, after the files it names are durable
+func writeManifest(dir string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(dir, ManifestFile+".tmp")
+	if err := writeFileSync(tmp, data); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, ManifestFile))
+}
+
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,219 @@
+// ABOUTME: Tests for full and incremental backups
+// ABOUTME: Verifies segment chaining, restore from snapshot plus segments or archives, targets and error cases
+
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+	"github.com/nainya/treestore/pkg/wal"
+)
+
+func openTestKV(t *testing.T, path string) *storage.KV {
+	t.Helper()
+	kv := &storage.KV{Path: path}
+	if err := kv.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	return kv
+}
+
+func TestIncrementalBackupRestore(t *testing.T) {
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "source.db")
+	dir := filepath.Join(tmp, "backup")
+
+	kv := openTestKV(t, dbPath)
+	defer kv.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := kv.Set([]byte(fmt.Sprintf("key%02d", i)), []byte("base")); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	m, err := Full(kv, dir)
+	if err != nil {
+		t.Fatalf("Full failed: %v", err)
+	}
+	if m.BaseLSN == 0 {
+		t.Error("Base snapshot should record the WAL position")
+	}
+	if _, err := Full(kv, dir); !errors.Is(err, ErrBackupExists) {
+		t.Errorf("Second Full error = %v, want ErrBackupExists", err)
+	}
+
+	// Nothing written since the snapshot
+	seg, err := Incremental(dbPath, dir)
+	if err != nil {
+		t.Fatalf("Incremental failed: %v", err)
+	}
+	if seg.Entries != 0 {
+		t.Errorf("Empty incremental has %d entries", seg.Entries)
+	}
+
+	// First increment: single writes
+	kv.Set([]byte("key00"), []byte("first"))
+	kv.Del([]byte("key01"))
+	first, err := Incremental(dbPath, dir)
+	if err != nil {
+		t.Fatalf("Incremental failed: %v", err)
+	}
+	if first.FromLSN != m.BaseLSN || first.Entries == 0 {
+		t.Errorf("First segment = %+v, want entries after LSN %d", first, m.BaseLSN)
+	}
+
+	// Second increment: a multi-key transaction
+	tx := kv.Begin()
+	tx.Set([]byte("key02"), []byte("second"))
+	tx.Set([]byte("key10"), []byte("second"))
+	tx.Del([]byte("key03"))
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	second, err := Incremental(dbPath, dir)
+	if err != nil {
+		t.Fatalf("Incremental failed: %v", err)
+	}
+	if second.FromLSN != first.ToLSN {
+		t.Errorf("Second segment starts after %d, want %d", second.FromLSN, first.ToLSN)
+	}
+
+	// Not captured by any backup
+	kv.Set([]byte("key04"), []byte("later"))
+
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("ReadManifest failed: %v", err)
+	}
+	if len(manifest.Segments) != 2 {
+		t.Fatalf("Manifest has %d segments, want 2", len(manifest.Segments))
+	}
+
+	restored := filepath.Join(tmp, "restored.db")
+	lsn, err := Restore(dir, restored)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if lsn != second.ToLSN {
+		t.Errorf("Restored to LSN %d, want %d", lsn, second.ToLSN)
+	}
+	if _, err := Restore(dir, restored); err == nil {
+		t.Error("Restore should refuse to overwrite an existing database")
+	}
+
+	out := openTestKV(t, restored)
+	defer out.Close()
+
+	want := map[string]string{
+		"key00": "first",
+		"key02": "second",
+		"key04": "base",
+		"key05": "base",
+		"key10": "second",
+	}
+	for key, val := range want {
+		got, ok := out.Get([]byte(key))
+		if !ok || string(got) != val {
+			t.Errorf("%s = %q (found %v), want %q", key, got, ok, val)
+		}
+	}
+	for _, key := range []string{"key01", "key03"} {
+		if _, ok := out.Get([]byte(key)); ok {
+			t.Errorf("%s should have been deleted", key)
+		}
+	}
+}
+
+func TestIncrementalWithoutBase(t *testing.T) {
+	tmp := t.TempDir()
+
+	if _, err := Incremental(filepath.Join(tmp, "source.db"), tmp); !errors.Is(err, ErrNoBaseBackup) {
+		t.Errorf("Incremental error = %v, want ErrNoBaseBackup", err)
+	}
+	if _, err := Restore(tmp, filepath.Join(tmp, "restored.db")); !errors.Is(err, ErrNoBaseBackup) {
+		t.Errorf("Restore error = %v, want ErrNoBaseBackup", err)
+	}
+}
+
+func TestRestoreToTarget(t *testing.T) {
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "source.db")
+	archiveDir := filepath.Join(tmp, "archive")
+	dir := filepath.Join(tmp, "backup")
+
+	kv := openTestKV(t, dbPath)
+	kv.Set([]byte("doc"), []byte("v0"))
+	m, err := Full(kv, dir)
+	if err != nil {
+		t.Fatalf("Full failed: %v", err)
+	}
+
+	// A good write, then the bad batch job to recover from
+	kv.Set([]byte("doc"), []byte("v1"))
+	good := kv.WAL().LastLSN()
+	tx := kv.Begin()
+	tx.Set([]byte("doc"), []byte("bad"))
+	tx.Del([]byte("other"))
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	kv.Close()
+
+	// The oldest log file has been archived; the rest are live
+	live, err := wal.LogFiles(storage.WALPath(dbPath))
+	if err != nil || len(live) != 1 {
+		t.Fatalf("Expected one live log file, got %v (%v)", live, err)
+	}
+	entries, _ := wal.ReadAll(live)
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(live[0], filepath.Join(archiveDir, wal.ArchiveName(storage.WALPath(dbPath), entries[0].LSN))); err != nil {
+		t.Fatal(err)
+	}
+
+	restore := func(name string, target wal.Target) (*RestoreReport, string) {
+		t.Helper()
+		restored := filepath.Join(tmp, name)
+		report, err := RestoreTo(dir, restored, RestoreOptions{Target: target, SourceDB: dbPath, ArchiveDir: archiveDir})
+		if err != nil {
+			t.Fatalf("RestoreTo %s failed: %v", name, err)
+		}
+		out := openTestKV(t, restored)
+		defer out.Close()
+		val, _ := out.Get([]byte("doc"))
+		return report, string(val)
+	}
+
+	report, val := restore("lsn.db", wal.Target{LSN: good})
+	if val != "v1" || report.LastLSN > good || report.Commits != 1 || report.StoppedAt <= good {
+		t.Errorf("LSN target restored %q with report %+v", val, report)
+	}
+
+	report, val = restore("all.db", wal.Target{})
+	if val != "bad" || report.Commits != 2 || report.StoppedAt != 0 {
+		t.Errorf("Full replay restored %q with report %+v", val, report)
+	}
+
+	// Every commit is later than an hour ago, so only the snapshot remains
+	report, val = restore("early.db", wal.Target{Time: time.Now().Add(-time.Hour)})
+	if val != "v0" || report.LastLSN != m.BaseLSN || report.Commits != 0 {
+		t.Errorf("Time target restored %q with report %+v", val, report)
+	}
+
+	// Without the archive, a later live file leaves a gap after the snapshot
+	later := &wal.Entry{LSN: good + 100, OpType: wal.OpCommit, Timestamp: time.Now()}
+	if err := os.WriteFile(filepath.Join(tmp, "source.db.wal.009"), later.Encode(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreTo(dir, filepath.Join(tmp, "gap.db"), RestoreOptions{SourceDB: dbPath, ArchiveDir: t.TempDir()}); err == nil {
+		t.Error("Expected an error for a gap in the log")
+	}
+}
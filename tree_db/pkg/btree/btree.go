@@ -5,20 +5,66 @@ package btree
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"sort"
 )
 
+// ErrCorruptNode indicates a page that does not hold a valid tree node
+var ErrCorruptNode = errors.New("btree: corrupt node")
+
+// CorruptNodeError reports a page whose node type is neither internal nor leaf
+// Tree operations panic with this error since they have no error path; the
+// storage layer recovers it into an error.
+type CorruptNodeError struct {
+	Type uint16
+}
+
+func (e *CorruptNodeError) Error() string {
+	return fmt.Sprintf("btree: corrupt node: bad node type %d", e.Type)
+}
+
+func (e *CorruptNodeError) Unwrap() error {
+	return ErrCorruptNode
+}
+
 // BTree represents the B+Tree data structure
 type BTree struct {
 	root uint64                      // pointer to root node (page number)
 	get  func(uint64) []byte         // dereference a pointer
 	new  func([]byte) uint64         // allocate a new page
 	del  func(uint64)                // deallocate a page
+
+	prefix bool // Pack the pages it writes with their keys' shared prefix
+}
+
+// SetPrefixCompression chooses whether pages written from now on store the
+// prefix their keys share once, which raises the fanout when keys repeat long
+// prefixes. Pages of either kind are always read.
+func (tree *BTree) SetPrefixCompression(on bool) {
+	tree.prefix = on
+}
+
+// PrefixCompression reports whether new pages are packed
+func (tree *BTree) PrefixCompression() bool {
+	return tree.prefix
+}
+
+// alloc packs an unpacked node that fits a page and allocates it
+func (tree *BTree) alloc(node BNode) uint64 {
+	return tree.new(nodePack(node, tree.prefix))
 }
 
 // Get retrieves a value by key
 func (tree *BTree) Get(key []byte) ([]byte, bool) {
+	val, _, found := tree.GetSealed(key)
+	return val, found
+}
+
+// GetSealed retrieves a value by key and reports whether it was inserted sealed
+func (tree *BTree) GetSealed(key []byte) (val []byte, sealed bool, found bool) {
 	if tree.root == 0 {
-		return nil, false
+		return nil, false, false
 	}
 
 	node := BNode(tree.get(tree.root))
@@ -26,27 +72,130 @@ func (tree *BTree) Get(key []byte) ([]byte, bool) {
 }
 
 // treeGet recursively searches for a key
-func treeGet(tree *BTree, node BNode, key []byte) ([]byte, bool) {
+func treeGet(tree *BTree, node BNode, key []byte) ([]byte, bool, bool) {
 	idx := nodeLookupLE(node, key)
 
 	switch node.btype() {
 	case BNODE_LEAF:
-		if bytes.Equal(key, node.getKey(idx)) {
-			return node.getVal(idx), true
+		if node.cmpKey(idx, key) == 0 {
+			return node.getVal(idx), node.valSealed(idx), true
 		}
-		return nil, false
+		return nil, false, false
 	case BNODE_NODE:
 		// Internal node - recurse to child
 		childPtr := node.getPtr(idx)
 		childNode := BNode(tree.get(childPtr))
 		return treeGet(tree, childNode, key)
 	default:
-		panic("bad node type")
+		panic(&CorruptNodeError{Type: node.btype()})
+	}
+}
+
+// GetBatch retrieves several keys in a single pass over the tree
+// Keys are visited in sorted order so that keys falling in the same leaf
+// reuse it instead of descending from the root again. Results are returned
+// in the order of the input keys.
+func (tree *BTree) GetBatch(keys [][]byte) ([][]byte, []bool) {
+	vals, _, found := tree.GetBatchSealed(keys)
+	return vals, found
+}
+
+// GetBatchSealed is GetBatch that also reports which values were inserted sealed
+func (tree *BTree) GetBatchSealed(keys [][]byte) (vals [][]byte, sealed []bool, found []bool) {
+	vals = make([][]byte, len(keys))
+	sealed = make([]bool, len(keys))
+	found = make([]bool, len(keys))
+	if tree.root == 0 || len(keys) == 0 {
+		return vals, sealed, found
+	}
+
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return bytes.Compare(keys[order[a]], keys[order[b]]) < 0
+	})
+
+	var leaf BNode
+	for _, i := range order {
+		key := keys[i]
+		if leaf == nil || !leafCovers(leaf, key) {
+			leaf = tree.findLeaf(key)
+		}
+
+		idx := nodeLookupLE(leaf, key)
+		if leaf.cmpKey(idx, key) == 0 {
+			vals[i] = leaf.getVal(idx)
+			sealed[i] = leaf.valSealed(idx)
+			found[i] = true
+		}
+	}
+
+	return vals, sealed, found
+}
+
+// findLeaf descends from the root to the leaf that may contain the key
+func (tree *BTree) findLeaf(key []byte) BNode {
+	node := BNode(tree.get(tree.root))
+	for {
+		switch node.btype() {
+		case BNODE_LEAF:
+			return node
+		case BNODE_NODE:
+			node = BNode(tree.get(node.getPtr(nodeLookupLE(node, key))))
+		default:
+			panic(&CorruptNodeError{Type: node.btype()})
+		}
+	}
+}
+
+// leafCovers reports whether the key lies between the first and last keys of the leaf
+// Leaves partition the key space, so such a key can only live in this leaf
+func leafCovers(leaf BNode, key []byte) bool {
+	n := leaf.nkeys()
+	if n == 0 {
+		return false
+	}
+	return leaf.cmpKey(0, key) <= 0 && leaf.cmpKey(n-1, key) >= 0
+}
+
+// Pages calls visit with the page number of every node reachable from the root
+func (tree *BTree) Pages(visit func(ptr uint64)) {
+	if tree.root != 0 {
+		treePages(tree, tree.root, visit)
+	}
+}
+
+// treePages visits a node and, for internal nodes, its subtrees
+func treePages(tree *BTree, ptr uint64, visit func(ptr uint64)) {
+	visit(ptr)
+	node := BNode(tree.get(ptr))
+	switch node.btype() {
+	case BNODE_LEAF:
+	case BNODE_NODE:
+		for i := uint16(0); i < node.nkeys(); i++ {
+			treePages(tree, node.getPtr(i), visit)
+		}
+	default:
+		panic(&CorruptNodeError{Type: node.btype()})
 	}
 }
 
 // Insert inserts or updates a key-value pair
 func (tree *BTree) Insert(key []byte, val []byte) {
+	tree.insert(key, val, 0)
+}
+
+// InsertSealed inserts or updates a key with an encrypted value
+// The value is stored as given and marked with VAL_SEALED in the page, so
+// readers know to decrypt it.
+func (tree *BTree) InsertSealed(key []byte, val []byte) {
+	tree.insert(key, val, VAL_SEALED)
+}
+
+// insert inserts or updates a key-value pair with the given value flags
+func (tree *BTree) insert(key []byte, val []byte, flags uint16) {
 	if tree.root == 0 {
 		// Create the first node
 		root := make([]byte, BTREE_PAGE_SIZE)
@@ -54,35 +203,38 @@ func (tree *BTree) Insert(key []byte, val []byte) {
 		node.setHeader(BNODE_LEAF, 2)
 		// Sentinel key (empty) - covers whole key space
 		nodeAppendKV(node, 0, 0, nil, nil)
-		nodeAppendKV(node, 1, 0, key, val)
-		tree.root = tree.new(root)
+		nodeAppendKVFlags(node, 1, 0, key, val, flags)
+		tree.root = tree.alloc(root)
 		return
 	}
 	
-	node := treeInsert(tree, BNode(tree.get(tree.root)), key, val)
-	nsplit, split := nodeSplit3(node)
+	node := treeInsert(tree, BNode(tree.get(tree.root)), key, val, flags)
 	tree.del(tree.root)
-	
-	if nsplit > 1 {
-		// Root was split, add new level
-		root := make([]byte, BTREE_PAGE_SIZE)
-		rootNode := BNode(root)
-		rootNode.setHeader(BNODE_NODE, nsplit)
-		
-		for i, knode := range split[:nsplit] {
-			ptr, key := tree.new(knode), knode.getKey(0)
-			nodeAppendKV(rootNode, uint16(i), ptr, key, nil)
-		}
-		tree.root = tree.new(root)
-	} else {
-		tree.root = tree.new(split[0])
+	tree.setRoot(node)
+}
+
+// setRoot allocates an unpacked node as the root, adding a level above it
+// while it needs more than one page
+func (tree *BTree) setRoot(node BNode) {
+	split := tree.nodeSplit(node)
+	if len(split) == 1 {
+		tree.root = tree.alloc(split[0])
+		return
+	}
+
+	root := BNode(make([]byte, BTREE_NODE_BUF))
+	root.setHeader(BNODE_NODE, uint16(len(split)))
+	for i, knode := range split {
+		ptr, key := tree.alloc(knode), knode.getKey(0)
+		nodeAppendKV(root, uint16(i), ptr, key, nil)
 	}
+	tree.setRoot(root)
 }
 
 // treeInsert inserts a KV into a node, result might be split
-func treeInsert(tree *BTree, node BNode, key []byte, val []byte) BNode {
+func treeInsert(tree *BTree, node BNode, key []byte, val []byte, flags uint16) BNode {
 	// Result node - allowed to be bigger than 1 page
-	new := make([]byte, 2*BTREE_PAGE_SIZE)
+	new := make([]byte, BTREE_NODE_BUF)
 	newNode := BNode(new)
 	
 	// Where to insert the key?
@@ -90,50 +242,50 @@ func treeInsert(tree *BTree, node BNode, key []byte, val []byte) BNode {
 	
 	switch node.btype() {
 	case BNODE_LEAF:
-		if bytes.Equal(key, node.getKey(idx)) {
+		if node.cmpKey(idx, key) == 0 {
 			// Update existing key
-			leafUpdate(newNode, node, idx, key, val)
+			leafUpdate(newNode, node, idx, key, val, flags)
 		} else {
 			// Insert after position
-			leafInsert(newNode, node, idx+1, key, val)
+			leafInsert(newNode, node, idx+1, key, val, flags)
 		}
 	case BNODE_NODE:
 		// Internal node - insert to kid node
-		nodeInsert(tree, newNode, node, idx, key, val)
+		nodeInsert(tree, newNode, node, idx, key, val, flags)
 	default:
-		panic("bad node type")
+		panic(&CorruptNodeError{Type: node.btype()})
 	}
 	
 	return newNode
 }
 
 // leafInsert adds a new key to a leaf node
-func leafInsert(new BNode, old BNode, idx uint16, key []byte, val []byte) {
+func leafInsert(new BNode, old BNode, idx uint16, key []byte, val []byte, flags uint16) {
 	new.setHeader(BNODE_LEAF, old.nkeys()+1)
 	nodeAppendRange(new, old, 0, 0, idx)
-	nodeAppendKV(new, idx, 0, key, val)
+	nodeAppendKVFlags(new, idx, 0, key, val, flags)
 	nodeAppendRange(new, old, idx+1, idx, old.nkeys()-idx)
 }
 
 // leafUpdate updates an existing key in a leaf node
-func leafUpdate(new BNode, old BNode, idx uint16, key []byte, val []byte) {
+func leafUpdate(new BNode, old BNode, idx uint16, key []byte, val []byte, flags uint16) {
 	new.setHeader(BNODE_LEAF, old.nkeys())
 	nodeAppendRange(new, old, 0, 0, idx)
-	nodeAppendKV(new, idx, 0, key, val)
+	nodeAppendKVFlags(new, idx, 0, key, val, flags)
 	nodeAppendRange(new, old, idx+1, idx+1, old.nkeys()-(idx+1))
 }
 
 // nodeInsert handles insertion to an internal node
-func nodeInsert(tree *BTree, new BNode, node BNode, idx uint16, key []byte, val []byte) {
+func nodeInsert(tree *BTree, new BNode, node BNode, idx uint16, key []byte, val []byte, flags uint16) {
 	kptr := node.getPtr(idx)
 	// Recursive insertion to kid node
-	knode := treeInsert(tree, BNode(tree.get(kptr)), key, val)
+	knode := treeInsert(tree, BNode(tree.get(kptr)), key, val, flags)
 	// Split the result
-	nsplit, split := nodeSplit3(knode)
+	split := tree.nodeSplit(knode)
 	// Deallocate the kid node
 	tree.del(kptr)
 	// Update the kid links
-	nodeReplaceKidN(tree, new, node, idx, split[:nsplit]...)
+	nodeReplaceKidN(tree, new, node, idx, split...)
 }
 
 // nodeReplaceKidN replaces a link with one or multiple links
@@ -143,57 +295,37 @@ func nodeReplaceKidN(tree *BTree, new BNode, old BNode, idx uint16, kids ...BNod
 	nodeAppendRange(new, old, 0, 0, idx)
 	
 	for i, node := range kids {
-		nodeAppendKV(new, idx+uint16(i), tree.new(node), node.getKey(0), nil)
+		nodeAppendKV(new, idx+uint16(i), tree.alloc(node), node.getKey(0), nil)
 	}
 	
 	nodeAppendRange(new, old, idx+inc, idx+1, old.nkeys()-(idx+1))
 }
 
-// nodeSplit3 splits a node if it's too big
-func nodeSplit3(old BNode) (uint16, [3]BNode) {
-	if old.nbytes() <= BTREE_PAGE_SIZE {
-		old = old[:BTREE_PAGE_SIZE]
-		return 1, [3]BNode{old}
-	}
-	
-	left := make([]byte, 2*BTREE_PAGE_SIZE)
-	right := make([]byte, BTREE_PAGE_SIZE)
-	nodeSplit2(BNode(left), BNode(right), old)
-	
-	if BNode(left).nbytes() <= BTREE_PAGE_SIZE {
-		left = left[:BTREE_PAGE_SIZE]
-		return 2, [3]BNode{BNode(left), BNode(right)}
+// nodeSplit splits an unpacked node into nodes that each fit a page once packed
+// Each node but the last is filled to ~75% of a page, leaving room for inserts.
+func (tree *BTree) nodeSplit(old BNode) []BNode {
+	nkeys := old.nkeys()
+	if nodeRunFits(old, 0, nkeys, tree.prefix, 1) {
+		return []BNode{old}
 	}
-	
-	// Need to split left again
-	leftleft := make([]byte, BTREE_PAGE_SIZE)
-	middle := make([]byte, BTREE_PAGE_SIZE)
-	nodeSplit2(BNode(leftleft), BNode(middle), BNode(left))
-	
-	return 3, [3]BNode{BNode(leftleft), BNode(middle), BNode(right)}
-}
 
-// nodeSplit2 splits an oversized node into 2
-func nodeSplit2(left BNode, right BNode, old BNode) {
-	// Distribute keys between left and right
-	// Target: fill left to ~75% of page size
-	nkeys := old.nkeys()
-	nleft := uint16(0)
-	
-	// Find split point
-	for i := uint16(0); i < nkeys; i++ {
-		nleft = i + 1
-		if old.kvPos(nleft) >= BTREE_PAGE_SIZE*3/4 {
-			break
+	var split []BNode
+	for from := uint16(0); from < nkeys; {
+		to := nkeys
+		if !nodeRunFits(old, from, to, tree.prefix, 1) {
+			to = from + 1
+			for to < nkeys && nodeRunFits(old, from, to+1, tree.prefix, 0.75) {
+				to++
+			}
 		}
+
+		node := BNode(make([]byte, BTREE_UNPACKED_MAX))
+		node.setHeader(old.btype(), to-from)
+		nodeAppendRange(node, old, 0, from, to-from)
+		split = append(split, node)
+		from = to
 	}
-	
-	// Copy to left and right
-	left.setHeader(old.btype(), nleft)
-	nodeAppendRange(left, old, 0, 0, nleft)
-	
-	right.setHeader(old.btype(), nkeys-nleft)
-	nodeAppendRange(right, old, 0, nleft, nkeys-nleft)
+	return split
 }
 
 // Delete deletes a key from the tree
@@ -213,7 +345,7 @@ func (tree *BTree) Delete(key []byte) bool {
 		// Remove a level if root has only 1 child
 		tree.root = updated.getPtr(0)
 	} else {
-		tree.root = tree.new(updated)
+		tree.setRoot(updated)
 	}
 	
 	return true
@@ -225,17 +357,17 @@ func treeDelete(tree *BTree, node BNode, key []byte) BNode {
 	
 	switch node.btype() {
 	case BNODE_LEAF:
-		if !bytes.Equal(key, node.getKey(idx)) {
+		if node.cmpKey(idx, key) != 0 {
 			return nil // not found
 		}
 		// Delete from leaf
-		new := make([]byte, BTREE_PAGE_SIZE)
+		new := make([]byte, BTREE_UNPACKED_MAX)
 		leafDelete(BNode(new), node, idx)
 		return BNode(new)
 	case BNODE_NODE:
 		return nodeDelete(tree, node, idx, key)
 	default:
-		panic("bad node type")
+		panic(&CorruptNodeError{Type: node.btype()})
 	}
 }
 
@@ -256,28 +388,29 @@ func nodeDelete(tree *BTree, node BNode, idx uint16, key []byte) BNode {
 	}
 	
 	tree.del(kptr)
-	new := make([]byte, BTREE_PAGE_SIZE)
+	new := make([]byte, BTREE_NODE_BUF)
 	
 	// Check for merging
 	mergeDir, sibling := shouldMerge(tree, node, idx, updated)
 	
 	switch {
 	case mergeDir < 0: // merge with left
-		merged := make([]byte, BTREE_PAGE_SIZE)
+		merged := make([]byte, BTREE_UNPACKED_MAX)
 		nodeMerge(BNode(merged), sibling, updated)
 		tree.del(node.getPtr(idx - 1))
-		nodeReplace2Kid(BNode(new), node, idx-1, tree.new(merged), BNode(merged).getKey(0))
+		nodeReplace2Kid(BNode(new), node, idx-1, tree.alloc(merged), BNode(merged).getKey(0))
 	case mergeDir > 0: // merge with right
-		merged := make([]byte, BTREE_PAGE_SIZE)
+		merged := make([]byte, BTREE_UNPACKED_MAX)
 		nodeMerge(BNode(merged), updated, sibling)
 		tree.del(node.getPtr(idx + 1))
-		nodeReplace2Kid(BNode(new), node, idx, tree.new(merged), BNode(merged).getKey(0))
+		nodeReplace2Kid(BNode(new), node, idx, tree.alloc(merged), BNode(merged).getKey(0))
 	case mergeDir == 0 && updated.nkeys() == 0:
 		// Empty child with no sibling
 		BNode(new).setHeader(BNODE_NODE, 0)
 	case mergeDir == 0 && updated.nkeys() > 0:
-		// No merge needed
-		nodeReplaceKidN(tree, BNode(new), node, idx, updated)
+		// No merge needed; a child whose keys share less of a prefix may
+		// need more than one page
+		nodeReplaceKidN(tree, BNode(new), node, idx, tree.nodeSplit(updated)...)
 	}
 	
 	return BNode(new)
@@ -285,15 +418,14 @@ func nodeDelete(tree *BTree, node BNode, idx uint16, key []byte) BNode {
 
 // shouldMerge checks if node should be merged with sibling
 func shouldMerge(tree *BTree, node BNode, idx uint16, updated BNode) (int, BNode) {
-	if updated.nbytes() > BTREE_PAGE_SIZE/4 {
+	if !nodeRunFits(updated, 0, updated.nkeys(), tree.prefix, 0.25) {
 		return 0, nil
 	}
 	
 	// Try left sibling
 	if idx > 0 {
 		sibling := BNode(tree.get(node.getPtr(idx - 1)))
-		merged := sibling.nbytes() + updated.nbytes() - HEADER
-		if merged <= BTREE_PAGE_SIZE {
+		if mergeFits(sibling, updated, tree.prefix) {
 			return -1, sibling
 		}
 	}
@@ -301,8 +433,7 @@ func shouldMerge(tree *BTree, node BNode, idx uint16, updated BNode) (int, BNode
 	// Try right sibling
 	if idx+1 < node.nkeys() {
 		sibling := BNode(tree.get(node.getPtr(idx + 1)))
-		merged := sibling.nbytes() + updated.nbytes() - HEADER
-		if merged <= BTREE_PAGE_SIZE {
+		if mergeFits(updated, sibling, tree.prefix) {
 			return +1, sibling
 		}
 	}
@@ -310,6 +441,27 @@ func shouldMerge(tree *BTree, node BNode, idx uint16, updated BNode) (int, BNode
 	return 0, nil
 }
 
+// mergeFits reports whether adjacent nodes, either of them packed, fit one page once merged
+func mergeFits(left BNode, right BNode, pack bool) bool {
+	size := left.unpackedSize() + right.unpackedSize() - HEADER
+	if size > BTREE_UNPACKED_MAX {
+		return false
+	}
+
+	nkeys := int(left.nkeys()) + int(right.nkeys())
+	if pack && nkeys > 0 {
+		first, last := left, right
+		if left.nkeys() == 0 {
+			first = right
+		}
+		if right.nkeys() == 0 {
+			last = left
+		}
+		size, _ = packedSize(nkeys, size, commonPrefix(first.getKey(0), last.getKey(last.nkeys()-1)))
+	}
+	return size <= BTREE_NODE_MAX
+}
+
 // nodeMerge merges two nodes into one
 func nodeMerge(new BNode, left BNode, right BNode) {
 	new.setHeader(left.btype(), left.nkeys()+right.nkeys())
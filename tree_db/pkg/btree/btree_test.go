@@ -29,7 +29,7 @@ func newTestContext() *TestContext {
 				return node
 			},
 			new: func(node []byte) uint64 {
-				if BNode(node).nbytes() > BTREE_PAGE_SIZE {
+				if BNode(node).nbytes() > BTREE_NODE_MAX {
 					panic("node too large")
 				}
 				ptr := uint64(uintptr(unsafe.Pointer(&node[0])))
@@ -274,3 +274,311 @@ func TestBTreeSentinelKey(t *testing.T) {
 		t.Error("Expected key '0' to not exist")
 	}
 }
+
+func TestBTreeGetBatch(t *testing.T) {
+	c := newTestContext()
+
+	for i := 0; i < 2000; i++ {
+		c.add(fmt.Sprintf("key%05d", i), fmt.Sprintf("val%d", i))
+	}
+
+	// Unsorted keys with duplicates and misses
+	keys := [][]byte{
+		[]byte("key01500"),
+		[]byte("key00003"),
+		[]byte("missing"),
+		[]byte("key00004"),
+		[]byte("key01500"),
+		[]byte("key01999"),
+	}
+
+	vals, found := c.tree.GetBatch(keys)
+	if len(vals) != len(keys) || len(found) != len(keys) {
+		t.Fatalf("Expected %d results, got %d/%d", len(keys), len(vals), len(found))
+	}
+
+	for i, key := range keys {
+		want, ok := c.ref[string(key)]
+		if found[i] != ok {
+			t.Errorf("Key %q: found=%v, want %v", key, found[i], ok)
+			continue
+		}
+		if ok && string(vals[i]) != want {
+			t.Errorf("Key %q: got %q, want %q", key, vals[i], want)
+		}
+	}
+}
+
+func TestBTreeGetBatchEmpty(t *testing.T) {
+	c := newTestContext()
+
+	vals, found := c.tree.GetBatch([][]byte{[]byte("a")})
+	if len(vals) != 1 || found[0] {
+		t.Error("Expected miss on empty tree")
+	}
+}
+
+func TestBTreePages(t *testing.T) {
+	c := newTestContext()
+	for i := 0; i < 1500; i++ {
+		c.add(fmt.Sprintf("key%05d", i), fmt.Sprintf("value%05d", i))
+	}
+	for i := 0; i < 1500; i += 3 {
+		c.del(fmt.Sprintf("key%05d", i))
+	}
+
+	// Every live page is reachable exactly once
+	seen := map[uint64]bool{}
+	c.tree.Pages(func(ptr uint64) {
+		if seen[ptr] {
+			t.Errorf("Page %d visited twice", ptr)
+		}
+		seen[ptr] = true
+	})
+	if len(seen) != len(c.pages) {
+		t.Errorf("Visited %d pages, want %d", len(seen), len(c.pages))
+	}
+	for ptr := range c.pages {
+		if !seen[ptr] {
+			t.Errorf("Live page %d not visited", ptr)
+		}
+	}
+}
+
+func TestBTreeRelocate(t *testing.T) {
+	c := newTestContext()
+	for i := 0; i < 1500; i++ {
+		c.add(fmt.Sprintf("key%05d", i), fmt.Sprintf("value%05d", i))
+	}
+
+	var before []uint64
+	c.tree.Leaves(func(ptr uint64) { before = append(before, ptr) })
+	if len(before) < 4 {
+		t.Fatalf("Expected several leaves, got %d", len(before))
+	}
+
+	// Move every other leaf; the others keep their pages
+	move := map[uint64]bool{}
+	for i := 0; i < len(before); i += 2 {
+		move[before[i]] = true
+	}
+	if n := c.tree.Relocate(func(ptr uint64) bool { return move[ptr] }, c.tree.new); n != len(move) {
+		t.Errorf("Moved %d leaves, want %d", n, len(move))
+	}
+
+	var after []uint64
+	c.tree.Leaves(func(ptr uint64) { after = append(after, ptr) })
+	if len(after) != len(before) {
+		t.Fatalf("Got %d leaves after relocating, want %d", len(after), len(before))
+	}
+	for i := range after {
+		if moved := after[i] != before[i]; moved != move[before[i]] {
+			t.Errorf("Leaf %d moved: %v, want %v", i, moved, move[before[i]])
+		}
+	}
+
+	// Old pages are freed and every key is still found
+	count := 0
+	c.tree.Pages(func(uint64) { count++ })
+	if count != len(c.pages) {
+		t.Errorf("Tree has %d pages, %d allocated", count, len(c.pages))
+	}
+	for key, val := range c.ref {
+		got, ok := c.tree.Get([]byte(key))
+		if !ok || string(got) != val {
+			t.Errorf("Get(%s) = %q, %v after relocating", key, got, ok)
+		}
+	}
+}
+
+func TestBTreeSealedValues(t *testing.T) {
+	c := newTestContext()
+
+	// Enough keys to split leaves, so flags must survive node copies
+	for i := 0; i < 500; i++ {
+		key := []byte(fmt.Sprintf("key%04d", i))
+		val := []byte(fmt.Sprintf("val%04d", i))
+		if i%2 == 0 {
+			c.tree.InsertSealed(key, val)
+		} else {
+			c.tree.Insert(key, val)
+		}
+	}
+
+	for i := 0; i < 500; i++ {
+		val, sealed, found := c.tree.GetSealed([]byte(fmt.Sprintf("key%04d", i)))
+		if !found || string(val) != fmt.Sprintf("val%04d", i) {
+			t.Fatalf("key%04d: got %q, found=%v", i, val, found)
+		}
+		if sealed != (i%2 == 0) {
+			t.Errorf("key%04d: sealed=%v", i, sealed)
+		}
+	}
+
+	// Overwriting replaces the flag along with the value
+	c.tree.Insert([]byte("key0000"), []byte("plain"))
+	c.tree.InsertSealed([]byte("key0001"), []byte("sealed"))
+	_, sealed0, _ := c.tree.GetSealed([]byte("key0000"))
+	_, sealed1, _ := c.tree.GetSealed([]byte("key0001"))
+	if sealed0 || !sealed1 {
+		t.Errorf("Expected flags to follow overwrites, got %v and %v", sealed0, sealed1)
+	}
+
+	_, flags, _ := c.tree.GetBatchSealed([][]byte{[]byte("key0002"), []byte("key0003")})
+	if !flags[0] || flags[1] {
+		t.Errorf("Unexpected batch flags: %v", flags)
+	}
+
+	count := 0
+	c.tree.ScanSealed([]byte("key0100"), func(key, val []byte, sealed bool) bool {
+		if sealed != (val[len(val)-1]%2 == 0) {
+			t.Errorf("%s: sealed=%v", key, sealed)
+		}
+		count++
+		return count < 10
+	})
+	if count != 10 {
+		t.Errorf("Expected to scan 10 keys, got %d", count)
+	}
+}
+
+// prefixedKey mimics store keys: a table prefix and a long shared ID
+func prefixedKey(i int) string {
+	return fmt.Sprintf("\x00\x00\x00\x07policy-clinical-guidelines-2026/node-%06d", i)
+}
+
+// checkRef verifies every key of the reference data and a full scan
+func (c *TestContext) checkRef(t *testing.T) {
+	t.Helper()
+	for key, val := range c.ref {
+		got, ok := c.tree.Get([]byte(key))
+		if !ok || string(got) != val {
+			t.Fatalf("Get(%q) = %q, %v", key, got, ok)
+		}
+	}
+	n := 0
+	var prev []byte
+	c.tree.Scan([]byte{0}, func(key, val []byte) bool {
+		if prev != nil && bytes.Compare(prev, key) >= 0 {
+			t.Fatalf("Scan out of order: %q after %q", key, prev)
+		}
+		prev = append(prev[:0], key...)
+		n++
+		return true
+	})
+	if want := len(c.ref); n != want {
+		t.Fatalf("Scanned %d keys, want %d", n, want)
+	}
+}
+
+func TestBTreePrefixCompression(t *testing.T) {
+	plain, packed := newTestContext(), newTestContext()
+	packed.tree.SetPrefixCompression(true)
+	for i := 0; i < 3000; i++ {
+		j := (i * 7919) % 3000
+		plain.add(prefixedKey(j), fmt.Sprintf("v%d", j))
+		packed.add(prefixedKey(j), fmt.Sprintf("v%d", j))
+	}
+	packed.checkRef(t)
+
+	prefixed := 0
+	for _, node := range packed.pages {
+		if node.prefixed() {
+			prefixed++
+		}
+	}
+	if prefixed == 0 {
+		t.Fatal("Expected packed pages")
+	}
+	if len(packed.pages)*3/2 > len(plain.pages) {
+		t.Errorf("Packed tree has %d pages, plain %d", len(packed.pages), len(plain.pages))
+	}
+
+	// Deletes merge packed pages and keep every page within bounds
+	for i := 0; i < 3000; i++ {
+		if i%3 != 0 {
+			packed.del(prefixedKey(i))
+		}
+	}
+	packed.checkRef(t)
+	for i := 0; i < 3000; i += 3 {
+		packed.del(prefixedKey(i))
+	}
+	packed.checkRef(t)
+	if len(packed.pages) != 1 {
+		t.Errorf("Expected the emptied tree in one page, got %d", len(packed.pages))
+	}
+}
+
+func TestBTreePrefixCompressionMixedPages(t *testing.T) {
+	c := newTestContext()
+	for i := 0; i < 1000; i++ {
+		c.add(prefixedKey(i), "plain")
+	}
+
+	// Pages written before compression was turned on stay readable, and
+	// keys sharing less of the prefix split packed pages apart
+	c.tree.SetPrefixCompression(true)
+	for i := 1000; i < 2000; i++ {
+		c.add(prefixedKey(i), "packed")
+	}
+	for i := 0; i < 2000; i += 5 {
+		c.add(fmt.Sprintf("%s/extra-%d", prefixedKey(i), i), "inside")
+		c.add(fmt.Sprintf("z%04d", i), "outside")
+	}
+	c.checkRef(t)
+
+	// Turning it off again reads the packed pages back
+	c.tree.SetPrefixCompression(false)
+	for i := 0; i < 2000; i += 2 {
+		c.del(prefixedKey(i))
+	}
+	c.checkRef(t)
+}
+
+func TestBTreeSampleRange(t *testing.T) {
+	c := newTestContext()
+	for i := 0; i < 20000; i++ {
+		c.add(fmt.Sprintf("key%06d", i), fmt.Sprintf("value%06d", i))
+	}
+
+	// Reading every leaf counts exactly
+	full := c.tree.SampleRange([]byte("key001000"), []byte("key015000"), 0)
+	total := full.Edge
+	for _, n := range full.Counts {
+		total += int64(n)
+	}
+	if total != 14000 || len(full.Counts) != full.Interior || full.Leaves != full.Interior+2 {
+		t.Fatalf("Full sample counted %d keys: %+v", total, full)
+	}
+
+	// A sample reads few leaves and scales their counts up
+	sample := c.tree.SampleRange([]byte("key001000"), []byte("key015000"), 8)
+	if sample.Leaves != full.Leaves || len(sample.Counts) != 8 {
+		t.Fatalf("Unexpected sample %+v", sample)
+	}
+	var sum int
+	for _, n := range sample.Counts {
+		sum += n
+	}
+	estimate := sample.Edge + int64(sum*sample.Interior/len(sample.Counts))
+	if estimate < 12000 || estimate > 16000 {
+		t.Errorf("Estimated %d keys, want about 14000", estimate)
+	}
+
+	// Ranges within one leaf, open ranges, and ranges past the last key
+	if s := c.tree.SampleRange([]byte("key000010"), []byte("key000015"), 8); s.Leaves != 1 || s.Edge != 5 {
+		t.Errorf("Range within a leaf: %+v", s)
+	}
+	all := c.tree.SampleRange(nil, nil, 0)
+	total = all.Edge
+	for _, n := range all.Counts {
+		total += int64(n)
+	}
+	if total != 20000 {
+		t.Errorf("Whole tree counted %d keys, want 20000 without the sentinel", total)
+	}
+	if s := c.tree.SampleRange([]byte("zzz"), nil, 8); s.Edge != 0 || s.Interior != 0 {
+		t.Errorf("Range past the last key: %+v", s)
+	}
+}
@@ -0,0 +1,109 @@
+// ABOUTME: Key count sampling over a key range from the tree's structure
+// ABOUTME: Lists the leaves a range spans from internal nodes alone and reads only a sample of them
+
+package btree
+
+// RangeSample describes the leaves holding the keys of a range. The keys of
+// the first and last leaf are counted exactly; of the leaves between, which
+// lie wholly inside the range, only a sample is read.
+type RangeSample struct {
+	Leaves   int   // Leaves the range spans
+	Edge     int64 // Keys of the range in its first and last leaf
+	Interior int   // Leaves between the first and last
+	Counts   []int // Key counts of the sampled interior leaves
+}
+
+// SampleRange samples the leaves holding keys from start up to, not
+// including, end; a nil end runs to the last key. It reads the internal nodes
+// above the range and at most samples+2 leaves, evenly spaced. The empty
+// sentinel key is not counted.
+func (tree *BTree) SampleRange(start, end []byte, samples int) RangeSample {
+	var sample RangeSample
+	if tree.root == 0 {
+		return sample
+	}
+
+	// The tree is balanced, so the leftmost path gives the depth of every leaf
+	depth := 0
+	for node := BNode(tree.get(tree.root)); node.btype() == BNODE_NODE; node = BNode(tree.get(node.getPtr(0))) {
+		depth++
+	}
+
+	var leaves []uint64
+	if depth == 0 {
+		leaves = []uint64{tree.root}
+	} else {
+		rangeLeaves(tree, tree.root, depth, start, end, &leaves)
+	}
+	sample.Leaves = len(leaves)
+	if len(leaves) == 0 {
+		return sample
+	}
+
+	sample.Edge = countInRange(BNode(tree.get(leaves[0])), start, end)
+	if len(leaves) > 1 {
+		sample.Edge += countInRange(BNode(tree.get(leaves[len(leaves)-1])), start, end)
+	}
+	if len(leaves) <= 2 {
+		return sample
+	}
+
+	interior := leaves[1 : len(leaves)-1]
+	sample.Interior = len(interior)
+	n := len(interior)
+	if samples > 0 && samples < n {
+		n = samples
+	}
+	sample.Counts = make([]int, n)
+	for i := range sample.Counts {
+		// Systematic sample from the middle of each of n equal strides
+		at := (2*i + 1) * len(interior) / (2 * n)
+		sample.Counts[i] = int(BNode(tree.get(interior[at])).nkeys())
+	}
+	return sample
+}
+
+// rangeLeaves appends the leaves under an internal node that may hold keys in
+// [start, end), reading only internal nodes; levels counts down to the leaves
+func rangeLeaves(tree *BTree, ptr uint64, levels int, start, end []byte, leaves *[]uint64) {
+	node := BNode(tree.get(ptr))
+	if node.btype() != BNODE_NODE {
+		panic(&CorruptNodeError{Type: node.btype()})
+	}
+	nkeys := node.nkeys()
+
+	// Child i holds keys from its first key up to the next child's
+	from := uint16(0)
+	if start != nil {
+		from = nodeLookupLE(node, start)
+	}
+	for i := from; i < nkeys; i++ {
+		if end != nil && i > from && node.cmpKey(i, end) >= 0 {
+			break
+		}
+		kid := node.getPtr(i)
+		if levels == 1 {
+			*leaves = append(*leaves, kid)
+		} else {
+			rangeLeaves(tree, kid, levels-1, start, end, leaves)
+		}
+	}
+}
+
+// countInRange counts the keys of a leaf in [start, end), less the sentinel
+func countInRange(node BNode, start, end []byte) int64 {
+	var count int64
+	for i := uint16(0); i < node.nkeys(); i++ {
+		if start != nil && node.cmpKey(i, start) < 0 {
+			continue
+		}
+		if end != nil && node.cmpKey(i, end) >= 0 {
+			break
+		}
+		if node.cmpKey(i, nil) == 0 {
+			continue
+		}
+		count++
+	}
+	return count
+}
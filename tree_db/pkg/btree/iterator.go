@@ -1,5 +1,5 @@
 // ABOUTME: B+Tree iterator for range scans
-// ABOUTME: Implements SeekLE and Next for forward iteration
+// ABOUTME: Implements SeekLE with Next/Prev for forward and reverse iteration
 
 package btree
 
@@ -50,6 +50,19 @@ func (iter *BIter) SeekLE(key []byte) bool {
 	return true
 }
 
+// Seek positions the iterator at the first key >= the given key, past the
+// empty sentinel key that starts the tree
+// Returns false if there is no such key
+func (iter *BIter) Seek(key []byte) bool {
+	if !iter.SeekLE(key) {
+		return false
+	}
+	if len(iter.Key()) == 0 || bytes.Compare(iter.Key(), key) < 0 {
+		return iter.Next()
+	}
+	return iter.Valid()
+}
+
 // Valid returns true if the iterator is positioned at a valid key
 func (iter *BIter) Valid() bool {
 	if len(iter.path) == 0 {
@@ -85,6 +98,17 @@ func (iter *BIter) Val() []byte {
 	return leaf.getVal(pos)
 }
 
+// Sealed reports whether the current value was inserted sealed
+func (iter *BIter) Sealed() bool {
+	if !iter.Valid() {
+		return false
+	}
+
+	leaf := iter.path[len(iter.path)-1]
+	pos := iter.pos[len(iter.pos)-1]
+	return leaf.valSealed(pos)
+}
+
 // Next advances the iterator to the next key
 // Returns false if there are no more keys
 func (iter *BIter) Next() bool {
@@ -151,9 +175,98 @@ func (iter *BIter) descendToLeftmost() bool {
 	}
 }
 
+// Prev moves the iterator to the previous key
+// Returns false if there are no more keys
+func (iter *BIter) Prev() bool {
+	if len(iter.path) == 0 {
+		return false
+	}
+
+	// Try to step back within current leaf
+	leafIdx := len(iter.pos) - 1
+	if iter.pos[leafIdx] > 0 {
+		iter.pos[leafIdx]--
+		return true
+	}
+
+	// Need to move to previous leaf - backtrack up the tree
+	iter.path = iter.path[:leafIdx]
+	iter.pos = iter.pos[:leafIdx]
+
+	// Backtrack to find a parent with an earlier child
+	for len(iter.pos) > 0 {
+		parentIdx := len(iter.pos) - 1
+		if iter.pos[parentIdx] > 0 {
+			iter.pos[parentIdx]--
+			return iter.descendToRightmost()
+		}
+
+		// This parent is exhausted too, pop it
+		iter.path = iter.path[:parentIdx]
+		iter.pos = iter.pos[:parentIdx]
+	}
+
+	// Reached start of tree
+	return false
+}
+
+// descendToRightmost descends from the current position to the rightmost leaf
+func (iter *BIter) descendToRightmost() bool {
+	for {
+		parentIdx := len(iter.path) - 1
+		parent := iter.path[parentIdx]
+		pos := iter.pos[parentIdx]
+
+		// Get child pointer
+		ptr := parent.getPtr(pos)
+		child := BNode(iter.tree.get(ptr))
+
+		// Add child to path, positioned at its last key
+		iter.path = append(iter.path, child)
+		iter.pos = append(iter.pos, child.nkeys()-1)
+
+		if child.btype() == BNODE_LEAF {
+			return true
+		}
+	}
+}
+
+// ScanReverse executes a descending range scan from the given start key
+// Visits keys <= start from largest to smallest until the callback returns false
+func (tree *BTree) ScanReverse(start []byte, callback func(key, val []byte) bool) {
+	tree.ScanReverseSealed(start, func(key, val []byte, _ bool) bool {
+		return callback(key, val)
+	})
+}
+
+// ScanReverseSealed is ScanReverse that also reports whether each value is sealed
+func (tree *BTree) ScanReverseSealed(start []byte, callback func(key, val []byte, sealed bool) bool) {
+	iter := tree.NewIterator()
+	if !iter.SeekLE(start) {
+		return
+	}
+
+	// The empty sentinel key is internal and marks the start of the tree
+	for iter.Valid() && len(iter.Key()) > 0 {
+		if !callback(iter.Key(), iter.Val(), iter.Sealed()) {
+			return
+		}
+		if !iter.Prev() {
+			return
+		}
+	}
+}
+
 // Scan executes a range scan from the given start key
 // Calls the callback for each key-value pair until callback returns false
 func (tree *BTree) Scan(start []byte, callback func(key, val []byte) bool) {
+	tree.ScanSealed(start, func(key, val []byte, _ bool) bool {
+		return callback(key, val)
+	})
+}
+
+// ScanSealed is Scan that also reports whether each value is sealed
+func (tree *BTree) ScanSealed(start []byte, callback func(key, val []byte, sealed bool) bool) {
 	iter := tree.NewIterator()
 	if !iter.SeekLE(start) {
 		return
@@ -168,7 +281,7 @@ func (tree *BTree) Scan(start []byte, callback func(key, val []byte) bool) {
 
 	// Iterate until callback returns false
 	for iter.Valid() {
-		if !callback(iter.Key(), iter.Val()) {
+		if !callback(iter.Key(), iter.Val(), iter.Sealed()) {
 			return
 		}
 		if !iter.Next() {
@@ -188,3 +188,89 @@ func TestIteratorPartialScan(t *testing.T) {
 		t.Errorf("Expected to scan 10 keys, got %d", count)
 	}
 }
+
+func TestIteratorSeek(t *testing.T) {
+	c := newTestContext()
+	for i := 0; i < 1000; i += 2 {
+		c.add(fmt.Sprintf("key%04d", i), fmt.Sprintf("val%04d", i))
+	}
+
+	iter := c.tree.NewIterator()
+
+	// The first key at or after the one sought
+	if !iter.Seek([]byte("key0101")) || string(iter.Key()) != "key0102" {
+		t.Errorf("Seek(key0101) = %s", iter.Key())
+	}
+	if !iter.Seek([]byte("key0500")) || string(iter.Key()) != "key0500" {
+		t.Errorf("Seek(key0500) = %s", iter.Key())
+	}
+
+	// Seeking from the start skips the sentinel
+	if !iter.Seek(nil) || string(iter.Key()) != "key0000" {
+		t.Errorf("Seek(nil) = %q", iter.Key())
+	}
+
+	// Nothing after the last key
+	if iter.Seek([]byte("key0999")) || iter.Valid() {
+		t.Errorf("Seek past the end found %s", iter.Key())
+	}
+}
+
+func TestIteratorPrev(t *testing.T) {
+	c := newTestContext()
+
+	// Enough keys to span several leaves
+	for i := 0; i < 1000; i++ {
+		c.add(fmt.Sprintf("key%04d", i), fmt.Sprintf("val%04d", i))
+	}
+
+	iter := c.tree.NewIterator()
+	if !iter.SeekLE([]byte("key0999")) {
+		t.Fatal("SeekLE failed")
+	}
+
+	// Walk backwards through all keys
+	expected := 999
+	for iter.Valid() && len(iter.Key()) > 0 {
+		want := fmt.Sprintf("key%04d", expected)
+		if string(iter.Key()) != want {
+			t.Fatalf("Expected %s, got %s", want, iter.Key())
+		}
+		expected--
+		if !iter.Prev() {
+			break
+		}
+	}
+
+	if expected != -1 {
+		t.Errorf("Expected to visit all keys, stopped before key%04d", expected)
+	}
+}
+
+func TestScanReverse(t *testing.T) {
+	c := newTestContext()
+
+	for i := 0; i < 20; i++ {
+		c.add(fmt.Sprintf("key%02d", i), fmt.Sprintf("val%02d", i))
+	}
+
+	var keys []string
+	c.tree.ScanReverse([]byte("key10"), func(key, val []byte) bool {
+		keys = append(keys, string(key))
+		return len(keys) < 3
+	})
+
+	if len(keys) != 3 || keys[0] != "key10" || keys[1] != "key09" || keys[2] != "key08" {
+		t.Errorf("Expected key10, key09, key08; got %v", keys)
+	}
+
+	// Scanning everything stops before the sentinel
+	count := 0
+	c.tree.ScanReverse([]byte("zzz"), func(key, val []byte) bool {
+		count++
+		return true
+	})
+	if count != 20 {
+		t.Errorf("Expected 20 keys, got %d", count)
+	}
+}
@@ -13,19 +13,62 @@ const (
 	BNODE_LEAF = 2 // leaf nodes with values
 )
 
+// BNODE_PREFIXED flags the type of a packed node: the prefix its keys share is
+// stored once, as a 2-byte length and the bytes, between the header and the
+// pointers, and each key holds only the rest
+const BNODE_PREFIXED = 0x100
+
 const (
 	HEADER            = 4
 	BTREE_PAGE_SIZE   = 4096
+	BTREE_PAGE_TRAILER = 4 // Reserved at the end of each page for the storage layer's checksum
+	BTREE_NODE_MAX    = BTREE_PAGE_SIZE - BTREE_PAGE_TRAILER
 	BTREE_MAX_KEY_SIZE = 1000
 	BTREE_MAX_VAL_SIZE = 3000
+
+	// Nodes are built unpacked, with whole keys, in buffers that may exceed a
+	// page until they are split. A packed page holds at most
+	// BTREE_UNPACKED_MAX bytes of unpacked node, so one update of it fits
+	// BTREE_NODE_BUF.
+	BTREE_UNPACKED_MAX = 3 * BTREE_PAGE_SIZE
+	BTREE_NODE_BUF     = 5 * BTREE_PAGE_SIZE
 )
 
+// VAL_SEALED marks an encrypted value in the vlen field of its KV header
+// Values never exceed BTREE_MAX_VAL_SIZE, so the top bit of vlen is free.
+const VAL_SEALED uint16 = 0x8000
+
 // BNode represents a B+Tree node as a byte slice
 type BNode []byte
 
 // btype returns the node type (internal or leaf)
 func (node BNode) btype() uint16 {
-	return binary.LittleEndian.Uint16(node[0:2])
+	return binary.LittleEndian.Uint16(node[0:2]) &^ BNODE_PREFIXED
+}
+
+// prefixed reports whether the node is packed with a shared key prefix
+func (node BNode) prefixed() bool {
+	return binary.LittleEndian.Uint16(node[0:2])&BNODE_PREFIXED != 0
+}
+
+// prefix returns the prefix every key of a packed node starts with
+func (node BNode) prefix() []byte {
+	if !node.prefixed() {
+		return nil
+	}
+	plen := binary.LittleEndian.Uint16(node[HEADER:])
+	if plen > BTREE_MAX_KEY_SIZE {
+		panic(&CorruptNodeError{Type: binary.LittleEndian.Uint16(node[0:2])})
+	}
+	return node[HEADER+2:][:plen]
+}
+
+// base returns the position of the pointers, after the header and any prefix
+func (node BNode) base() uint16 {
+	if !node.prefixed() {
+		return HEADER
+	}
+	return HEADER + 2 + uint16(len(node.prefix()))
 }
 
 // nkeys returns the number of keys in the node
@@ -44,7 +87,7 @@ func (node BNode) getPtr(idx uint16) uint64 {
 	if idx >= node.nkeys() {
 		panic("index out of range")
 	}
-	pos := HEADER + 8*idx
+	pos := node.base() + 8*idx
 	return binary.LittleEndian.Uint64(node[pos:])
 }
 
@@ -53,7 +96,7 @@ func (node BNode) setPtr(idx uint16, val uint64) {
 	if idx >= node.nkeys() {
 		panic("index out of range")
 	}
-	pos := HEADER + 8*idx
+	pos := node.base() + 8*idx
 	binary.LittleEndian.PutUint64(node[pos:], val)
 }
 
@@ -62,7 +105,7 @@ func offsetPos(node BNode, idx uint16) uint16 {
 	if idx < 1 || idx > node.nkeys() {
 		panic("index out of range")
 	}
-	return HEADER + 8*node.nkeys() + 2*(idx-1)
+	return node.base() + 8*node.nkeys() + 2*(idx-1)
 }
 
 // getOffset returns the offset for the given index
@@ -83,11 +126,23 @@ func (node BNode) kvPos(idx uint16) uint16 {
 	if idx > node.nkeys() {
 		panic("index out of range")
 	}
-	return HEADER + 8*node.nkeys() + 2*node.nkeys() + node.getOffset(idx)
+	return node.base() + 8*node.nkeys() + 2*node.nkeys() + node.getOffset(idx)
 }
 
 // getKey returns the key at the given index
+// Keys of a packed node are copied with their prefix; others point into the node.
 func (node BNode) getKey(idx uint16) []byte {
+	suffix := node.keySuffix(idx)
+	if !node.prefixed() {
+		return suffix
+	}
+	prefix := node.prefix()
+	key := make([]byte, 0, len(prefix)+len(suffix))
+	return append(append(key, prefix...), suffix...)
+}
+
+// keySuffix returns the key at the given index as stored, without the prefix of a packed node
+func (node BNode) keySuffix(idx uint16) []byte {
 	if idx >= node.nkeys() {
 		panic("index out of range")
 	}
@@ -96,6 +151,23 @@ func (node BNode) getKey(idx uint16) []byte {
 	return node[pos+4:][:klen]
 }
 
+// cmpKey compares the key at the given index with key, like bytes.Compare,
+// without copying the key out of a packed node
+func (node BNode) cmpKey(idx uint16, key []byte) int {
+	suffix := node.keySuffix(idx)
+	prefix := node.prefix()
+	if len(key) < len(prefix) {
+		if cmp := bytes.Compare(prefix[:len(key)], key); cmp != 0 {
+			return cmp
+		}
+		return 1 // key is a proper prefix of the prefix
+	}
+	if cmp := bytes.Compare(prefix, key[:len(prefix)]); cmp != 0 {
+		return cmp
+	}
+	return bytes.Compare(suffix, key[len(prefix):])
+}
+
 // getVal returns the value at the given index
 func (node BNode) getVal(idx uint16) []byte {
 	if idx >= node.nkeys() {
@@ -103,15 +175,116 @@ func (node BNode) getVal(idx uint16) []byte {
 	}
 	pos := node.kvPos(idx)
 	klen := binary.LittleEndian.Uint16(node[pos+0:])
-	vlen := binary.LittleEndian.Uint16(node[pos+2:])
+	vlen := binary.LittleEndian.Uint16(node[pos+2:]) &^ VAL_SEALED
 	return node[pos+4+klen:][:vlen]
 }
 
+// valSealed reports whether the value at the given index is encrypted
+func (node BNode) valSealed(idx uint16) bool {
+	if idx >= node.nkeys() {
+		panic("index out of range")
+	}
+	pos := node.kvPos(idx)
+	return binary.LittleEndian.Uint16(node[pos+2:])&VAL_SEALED != 0
+}
+
 // nbytes returns the node size in bytes
 func (node BNode) nbytes() uint16 {
 	return node.kvPos(node.nkeys())
 }
 
+// unpackedSize returns the size of the node with whole keys
+func (node BNode) unpackedSize() int {
+	return int(node.nbytes()) - int(node.base()-HEADER) + int(node.nkeys())*len(node.prefix())
+}
+
+// commonPrefix returns the length of the prefix a and b share
+func commonPrefix(a, b []byte) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// packedSize returns the page size of n keys taking size bytes unpacked and
+// sharing a prefix of plen bytes, and the prefix length worth storing once:
+// only when that saves more than the 2-byte length and the prefix take
+func packedSize(n, size, plen int) (int, int) {
+	if plen == 0 || n*plen <= 2+plen {
+		return size, 0
+	}
+	return size - n*plen + 2 + plen, plen
+}
+
+// nodeRunSize returns the page size of keys [from, to) of an unpacked node,
+// packed when pack is set, and their unpacked size
+func nodeRunSize(node BNode, from, to uint16, pack bool) (size int, unpacked int) {
+	unpacked = HEADER + 10*int(to-from) + int(node.getOffset(to)-node.getOffset(from))
+	if !pack || to == from {
+		return unpacked, unpacked
+	}
+	size, _ = packedSize(int(to-from), unpacked, commonPrefix(node.getKey(from), node.getKey(to-1)))
+	return size, unpacked
+}
+
+// nodeRunFits reports whether keys [from, to) of an unpacked node fit in the
+// given share of a page and of BTREE_UNPACKED_MAX
+func nodeRunFits(node BNode, from, to uint16, pack bool, share float64) bool {
+	size, unpacked := nodeRunSize(node, from, to, pack)
+	return float64(size) <= share*BTREE_NODE_MAX && float64(unpacked) <= share*BTREE_UNPACKED_MAX
+}
+
+// nodePack returns a page holding an unpacked node that fits one, packing it
+// when pack is set and its keys share a prefix worth storing once
+func nodePack(node BNode, pack bool) BNode {
+	nkeys := node.nkeys()
+	plen := 0
+	if pack && nkeys > 0 {
+		_, plen = packedSize(int(nkeys), int(node.nbytes()), commonPrefix(node.getKey(0), node.getKey(nkeys-1)))
+	}
+	if plen == 0 {
+		if len(node) >= BTREE_PAGE_SIZE {
+			return node[:BTREE_PAGE_SIZE]
+		}
+		page := make(BNode, BTREE_PAGE_SIZE)
+		copy(page, node)
+		return page
+	}
+
+	page := make(BNode, BTREE_PAGE_SIZE)
+	page.setHeader(node.btype()|BNODE_PREFIXED, nkeys)
+	binary.LittleEndian.PutUint16(page[HEADER:], uint16(plen))
+	copy(page[HEADER+2:], node.getKey(0)[:plen])
+	for i := uint16(0); i < nkeys; i++ {
+		page.setPtr(i, node.getPtr(i))
+		key, vfield, val := node.kvRaw(i)
+		nodeAppendRaw(page, i, nil, key[plen:], vfield, val)
+	}
+	return page
+}
+
+// kvRaw returns the stored key, vlen field (with flags) and value at the given index
+func (node BNode) kvRaw(idx uint16) ([]byte, uint16, []byte) {
+	pos := node.kvPos(idx)
+	klen := binary.LittleEndian.Uint16(node[pos+0:])
+	vfield := binary.LittleEndian.Uint16(node[pos+2:])
+	return node[pos+4:][:klen], vfield, node[pos+4+klen:][:vfield&^VAL_SEALED]
+}
+
+// nodeAppendRaw appends a KV whose key is prefix followed by suffix, with the
+// vlen field (and its flags) as stored
+func nodeAppendRaw(new BNode, idx uint16, prefix, suffix []byte, vfield uint16, val []byte) {
+	pos := new.kvPos(idx)
+	klen := uint16(len(prefix) + len(suffix))
+	binary.LittleEndian.PutUint16(new[pos+0:], klen)
+	binary.LittleEndian.PutUint16(new[pos+2:], vfield)
+	copy(new[pos+4:], prefix)
+	copy(new[pos+4+uint16(len(prefix)):], suffix)
+	copy(new[pos+4+klen:], val)
+	new.setOffset(idx+1, new.getOffset(idx)+4+klen+uint16(len(val)))
+}
+
 // nodeLookupLE returns the first kid node whose range intersects the key
 // Returns the index where key should be inserted or found
 func nodeLookupLE(node BNode, key []byte) uint16 {
@@ -121,7 +294,7 @@ func nodeLookupLE(node BNode, key []byte) uint16 {
 	// The first key is a copy from the parent node,
 	// thus it's always less than or equal to the key
 	for i := uint16(1); i < nkeys; i++ {
-		cmp := bytes.Compare(node.getKey(i), key)
+		cmp := node.cmpKey(i, key)
 		if cmp <= 0 {
 			found = i
 		}
@@ -154,6 +327,15 @@ func nodeAppendRange(
 			new.setPtr(dstNew+i, old.getPtr(srcOld+i))
 		}
 	}
+
+	// Keys of a packed node get their prefix back one at a time
+	if prefix := old.prefix(); len(prefix) > 0 {
+		for i := uint16(0); i < n; i++ {
+			key, vfield, val := old.kvRaw(srcOld + i)
+			nodeAppendRaw(new, dstNew+i, prefix, key, vfield, val)
+		}
+		return
+	}
 	
 	// Copy offsets
 	dstBegin := new.getOffset(dstNew)
@@ -172,13 +354,18 @@ func nodeAppendRange(
 
 // nodeAppendKV appends a single KV to the node
 func nodeAppendKV(new BNode, idx uint16, ptr uint64, key []byte, val []byte) {
+	nodeAppendKVFlags(new, idx, ptr, key, val, 0)
+}
+
+// nodeAppendKVFlags appends a single KV with flags such as VAL_SEALED in its vlen
+func nodeAppendKVFlags(new BNode, idx uint16, ptr uint64, key []byte, val []byte, flags uint16) {
 	// Set pointer for internal nodes
 	new.setPtr(idx, ptr)
 	
 	// KV
 	pos := new.kvPos(idx)
 	binary.LittleEndian.PutUint16(new[pos+0:], uint16(len(key)))
-	binary.LittleEndian.PutUint16(new[pos+2:], uint16(len(val)))
+	binary.LittleEndian.PutUint16(new[pos+2:], uint16(len(val))|flags)
 	copy(new[pos+4:], key)
 	copy(new[pos+4+uint16(len(key)):], val)
 	
@@ -188,7 +375,7 @@ func nodeAppendKV(new BNode, idx uint16, ptr uint64, key []byte, val []byte) {
 
 func init() {
 	node1max := HEADER + 8 + 2 + 4 + BTREE_MAX_KEY_SIZE + BTREE_MAX_VAL_SIZE
-	if node1max > BTREE_PAGE_SIZE {
+	if node1max > BTREE_NODE_MAX {
 		panic("node size exceeds page size")
 	}
 }
@@ -0,0 +1,94 @@
+// ABOUTME: Leaf layout inspection and copy-on-write relocation of tree pages
+// ABOUTME: Lets the storage layer rewrite scattered leaves onto consecutive pages
+
+package btree
+
+// Leaves calls visit with the page number of every leaf, in key order
+func (tree *BTree) Leaves(visit func(ptr uint64)) {
+	if tree.root != 0 {
+		treeLeaves(tree, tree.root, visit)
+	}
+}
+
+// treeLeaves visits the leaves under a node from left to right
+func treeLeaves(tree *BTree, ptr uint64, visit func(ptr uint64)) {
+	node := BNode(tree.get(ptr))
+	switch node.btype() {
+	case BNODE_LEAF:
+		visit(ptr)
+	case BNODE_NODE:
+		for i := uint16(0); i < node.nkeys(); i++ {
+			treeLeaves(tree, node.getPtr(i), visit)
+		}
+	default:
+		panic(&CorruptNodeError{Type: node.btype()})
+	}
+}
+
+// Relocate copies the leaves for which move returns true to pages from
+// alloc, in key order, then the internal nodes above them, whose child
+// pointers change. The old pages are freed once everything is copied, so
+// alloc hands out pages in the order the copies are laid out. It returns the
+// number of leaves moved.
+func (tree *BTree) Relocate(move func(ptr uint64) bool, alloc func(node []byte) uint64) int {
+	if tree.root == 0 {
+		return 0
+	}
+
+	moved := make(map[uint64]uint64)
+	tree.Leaves(func(ptr uint64) {
+		if move(ptr) {
+			moved[ptr] = alloc(clonePage(tree.get(ptr)))
+		}
+	})
+	if len(moved) == 0 {
+		return 0
+	}
+
+	var freed []uint64
+	tree.root = treeRelocate(tree, tree.root, alloc, moved, &freed)
+	for old := range moved {
+		tree.del(old)
+	}
+	for _, old := range freed {
+		tree.del(old)
+	}
+	return len(moved)
+}
+
+// treeRelocate returns the new page of a node once the leaves under it are
+// moved, copying it when any child changed and recording the page it left
+func treeRelocate(tree *BTree, ptr uint64, alloc func(node []byte) uint64, moved map[uint64]uint64, freed *[]uint64) uint64 {
+	node := BNode(tree.get(ptr))
+	if node.btype() == BNODE_LEAF {
+		if to, ok := moved[ptr]; ok {
+			return to
+		}
+		return ptr
+	}
+
+	var copied BNode
+	for i := uint16(0); i < node.nkeys(); i++ {
+		kid := node.getPtr(i)
+		to := treeRelocate(tree, kid, alloc, moved, freed)
+		if to == kid {
+			continue
+		}
+		if copied == nil {
+			copied = clonePage(node)
+		}
+		copied.setPtr(i, to)
+	}
+	if copied == nil {
+		return ptr
+	}
+	*freed = append(*freed, ptr)
+	return alloc(copied)
+}
+
+// clonePage copies a page, which may be mapped from the file, for rewriting
+func clonePage(page []byte) BNode {
+	node := make([]byte, BTREE_PAGE_SIZE)
+	copy(node, page)
+	return node
+}
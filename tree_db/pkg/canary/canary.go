@@ -0,0 +1,211 @@
+// ABOUTME: Background self-check that re-reads a sample of recently written records
+// ABOUTME: Flags values that no longer decode, an early warning for encoding regressions
+
+package canary
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// Defaults applied by NewCanary
+const (
+	DefaultInterval = time.Minute
+	DefaultSample   = 64
+	DefaultWindow   = 4096
+)
+
+// Check validates the value of one kind of record
+type Check struct {
+	Name     string             // Record kind, such as "version", for reports and metrics
+	Validate func([]byte) error // Fails when the value does not decode completely
+}
+
+// Config configures a Canary
+type Config struct {
+	Checks   map[uint32]Check   // Checks by key prefix; keys of other prefixes are not sampled
+	Interval time.Duration      // Time between passes
+	Sample   int                // Maximum records re-read per pass
+	Window   int                // Number of recent inserts sampled from
+	OnCheck  func(*CheckReport) // Called after every pass, e.g. to log or export metrics
+	Lock     sync.Locker        // Held while reading, e.g. to stay clear of applied transactions; nil takes none
+}
+
+// Anomaly is a recently written record that failed its check
+type Anomaly struct {
+	Key  []byte
+	Kind string // Name of the check it failed
+	Err  error
+}
+
+// CheckReport describes the outcome of one pass
+type CheckReport struct {
+	StartedAt time.Time
+	Duration  time.Duration
+	Checked   map[string]int // Records re-read per kind
+	Missing   int            // Sampled keys deleted since they were written
+	Anomalies []Anomaly
+}
+
+// Stats accumulates canary activity since it was created
+type Stats struct {
+	Passes    int64
+	Checked   int64
+	Anomalies int64
+	LastCheck *CheckReport
+}
+
+// Canary periodically re-reads a sample of the records written recently and
+// validates that they still decode
+type Canary struct {
+	kv  *storage.KV
+	cfg Config
+
+	mu    sync.Mutex
+	stats Stats
+	rand  *rand.Rand // Guarded by mu
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewCanary creates a canary over kv and starts tracking its writes
+func NewCanary(kv *storage.KV, cfg Config) *Canary {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Sample <= 0 {
+		cfg.Sample = DefaultSample
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultWindow
+	}
+
+	kv.TrackWrites(cfg.Window)
+	return &Canary{
+		kv:   kv,
+		cfg:  cfg,
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Start runs passes in a background goroutine until Stop is called
+func (c *Canary) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		return
+	}
+
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	go c.run(c.stop, c.done)
+}
+
+// Stop ends background checks, waits for an in-progress pass to finish and
+// stops tracking writes
+func (c *Canary) Stop() {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop, c.done = nil, nil
+	c.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+	c.kv.TrackWrites(0)
+}
+
+// Stats returns a snapshot of the canary's activity
+func (c *Canary) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// CheckOnce re-reads up to Sample of the recently written records that have
+// a check, as of now, and validates them
+func (c *Canary) CheckOnce(now time.Time) *CheckReport {
+	report := &CheckReport{StartedAt: now, Checked: make(map[string]int)}
+	start := time.Now()
+
+	var keys [][]byte
+	for _, key := range c.kv.RecentWrites() {
+		if _, ok := c.cfg.Checks[storage.ExtractPrefix(key)]; ok {
+			keys = append(keys, key)
+		}
+	}
+	c.mu.Lock()
+	c.rand.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+	c.mu.Unlock()
+	if len(keys) > c.cfg.Sample {
+		keys = keys[:c.cfg.Sample]
+	}
+
+	if c.cfg.Lock != nil {
+		c.cfg.Lock.Lock()
+	}
+	for _, key := range keys {
+		c.check(key, report)
+	}
+	if c.cfg.Lock != nil {
+		c.cfg.Lock.Unlock()
+	}
+
+	report.Duration = time.Since(start)
+
+	c.mu.Lock()
+	c.stats.Passes++
+	for _, n := range report.Checked {
+		c.stats.Checked += int64(n)
+	}
+	c.stats.Anomalies += int64(len(report.Anomalies))
+	c.stats.LastCheck = report
+	c.mu.Unlock()
+
+	if c.cfg.OnCheck != nil {
+		c.cfg.OnCheck(report)
+	}
+
+	return report
+}
+
+// check re-reads one record and adds the outcome to report
+// A read that reaches corrupt or undecryptable data is an anomaly too.
+func (c *Canary) check(key []byte, report *CheckReport) {
+	check := c.cfg.Checks[storage.ExtractPrefix(key)]
+	val, found, err := c.kv.Lookup(key)
+	if err == nil && !found {
+		report.Missing++
+		return
+	}
+	report.Checked[check.Name]++
+	if err == nil {
+		err = check.Validate(val)
+	}
+	if err != nil {
+		report.Anomalies = append(report.Anomalies, Anomaly{Key: key, Kind: check.Name, Err: err})
+	}
+}
+
+// run checks on every interval tick until stop is closed
+func (c *Canary) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			c.CheckOnce(now)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// ABOUTME: Tests for the background canary
+// ABOUTME: Verifies sampling of recent writes, anomaly reports, deleted keys and background runs
+
+package canary
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+const (
+	prefixGood  = uint32(100)
+	prefixOther = uint32(200)
+)
+
+func setupCanary(t *testing.T, cfg Config) (*storage.KV, *Canary) {
+	kv := &storage.KV{Path: storage.MemoryPath}
+	if err := kv.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	t.Cleanup(func() { kv.Close() })
+
+	cfg.Checks = map[uint32]Check{
+		prefixGood: {Name: "record", Validate: func(val []byte) error {
+			if string(val) != "ok" {
+				return errors.New("bad value")
+			}
+			return nil
+		}},
+	}
+	return kv, NewCanary(kv, cfg)
+}
+
+func key(prefix uint32, id string) []byte {
+	return storage.EncodeKey(prefix, []storage.Value{storage.NewBytesValue([]byte(id))})
+}
+
+func TestCheckOnce(t *testing.T) {
+	kv, c := setupCanary(t, Config{})
+
+	kv.Set(key(prefixGood, "a"), []byte("ok"))
+	kv.Set(key(prefixGood, "b"), []byte("truncated"))
+	kv.Set(key(prefixGood, "c"), []byte("ok"))
+	kv.Set(key(prefixOther, "x"), []byte("not checked"))
+	kv.Del(key(prefixGood, "c"))
+
+	report := c.CheckOnce(time.Now())
+	if report.Checked["record"] != 2 || report.Missing != 1 {
+		t.Errorf("Expected 2 records checked and 1 missing, got %+v", report)
+	}
+	if len(report.Anomalies) != 1 || string(report.Anomalies[0].Key) != string(key(prefixGood, "b")) ||
+		report.Anomalies[0].Kind != "record" {
+		t.Fatalf("Expected the truncated record flagged, got %+v", report.Anomalies)
+	}
+
+	stats := c.Stats()
+	if stats.Passes != 1 || stats.Checked != 2 || stats.Anomalies != 1 || stats.LastCheck != report {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestCheckOnceSamples(t *testing.T) {
+	kv, c := setupCanary(t, Config{Sample: 5, Window: 20})
+
+	for i := 0; i < 50; i++ {
+		kv.Set(key(prefixGood, fmt.Sprintf("k%02d", i)), []byte("ok"))
+	}
+	report := c.CheckOnce(time.Now())
+	if report.Checked["record"] != 5 || len(report.Anomalies) != 0 {
+		t.Errorf("Expected 5 healthy records checked, got %+v", report)
+	}
+	if got := len(kv.RecentWrites()); got != 20 {
+		t.Errorf("Expected a window of 20 keys, got %d", got)
+	}
+}
+
+func TestBackgroundChecks(t *testing.T) {
+	reports := make(chan *CheckReport, 10)
+	kv, c := setupCanary(t, Config{
+		Interval: 10 * time.Millisecond,
+		OnCheck:  func(r *CheckReport) { reports <- r },
+	})
+	kv.Set(key(prefixGood, "a"), []byte("bad"))
+
+	c.Start()
+	select {
+	case r := <-reports:
+		if len(r.Anomalies) != 1 {
+			t.Errorf("Expected an anomaly, got %+v", r)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No pass ran")
+	}
+	c.Stop()
+
+	if got := kv.RecentWrites(); len(got) != 0 {
+		t.Errorf("Expected tracking to stop with the canary, got %q", got)
+	}
+}
@@ -0,0 +1,207 @@
+// ABOUTME: In-process change feed that fans out write notifications to subscribers
+// ABOUTME: Stores publish after commit; watchers filter events by topic prefix
+
+package changefeed
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entities that publish change events
+const (
+	EntityDocument   = "document"
+	EntityEmbedding  = "embedding"
+	EntityVersion    = "version"
+	EntityMetadata   = "metadata"
+	EntityReference  = "reference"
+	EntityCollection = "collection"
+)
+
+// Op is the kind of change
+type Op string
+
+const (
+	OpPut    Op = "put"
+	OpDelete Op = "delete"
+)
+
+// DefaultBufferSize is the number of undelivered events a subscriber may hold
+const DefaultBufferSize = 256
+
+var (
+	// ErrLagged is reported when a subscriber fell too far behind and was dropped
+	ErrLagged = errors.New("changefeed: subscriber fell behind")
+
+	// ErrClosed is reported when the feed shuts down
+	ErrClosed = errors.New("changefeed: feed closed")
+)
+
+// Event describes one committed write
+type Event struct {
+	Seq       uint64 // Increases by one per published event
+	Entity    string
+	Op        Op
+	PolicyID  string // Empty for metadata and collections, which are not scoped to a policy
+	EntityID  string // Version, node, "entityType/entityID", "sourceNode/targetPolicy/targetNode" or collection name
+	Timestamp time.Time
+}
+
+// Topic returns the path filters match against: entity, policy and entity ID
+// joined by "/", skipping empty parts (e.g. "version/LCD-1/v2", "metadata/tool_result/exec-1")
+func (e Event) Topic() string {
+	parts := make([]string, 0, 3)
+	for _, p := range []string{e.Entity, e.PolicyID, e.EntityID} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// Feed fans published events out to subscribers
+// A nil *Feed is valid and discards everything, so stores can publish unconditionally.
+type Feed struct {
+	bufferSize int
+
+	mu     sync.Mutex
+	seq    uint64
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// NewFeed creates a feed; bufferSize <= 0 uses DefaultBufferSize
+func NewFeed(bufferSize int) *Feed {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Feed{bufferSize: bufferSize, subs: make(map[*Subscription]struct{})}
+}
+
+// Publish records a change and delivers it to matching subscribers
+// Publish never blocks: a subscriber whose buffer is full is dropped with ErrLagged.
+func (f *Feed) Publish(entity string, op Op, policyID, entityID string) {
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return
+	}
+
+	f.seq++
+	ev := Event{
+		Seq:       f.seq,
+		Entity:    entity,
+		Op:        op,
+		PolicyID:  policyID,
+		EntityID:  entityID,
+		Timestamp: time.Now(),
+	}
+
+	topic := ev.Topic()
+	for sub := range f.subs {
+		if !sub.matches(topic) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			f.drop(sub, ErrLagged)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for events whose topic starts with any of prefixes
+// No prefixes subscribes to every event.
+func (f *Feed) Subscribe(prefixes []string) *Subscription {
+	sub := &Subscription{
+		feed:     f,
+		prefixes: prefixes,
+		ch:       make(chan Event, f.bufferSize),
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		sub.err = ErrClosed
+		close(sub.ch)
+		return sub
+	}
+	f.subs[sub] = struct{}{}
+	return sub
+}
+
+// Close ends every subscription with ErrClosed; later publishes are discarded
+func (f *Feed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	for sub := range f.subs {
+		f.drop(sub, ErrClosed)
+	}
+}
+
+// Subscribers returns the number of active subscriptions
+func (f *Feed) Subscribers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subs)
+}
+
+// drop removes a subscriber and closes its channel; callers hold f.mu
+func (f *Feed) drop(sub *Subscription, err error) {
+	if _, ok := f.subs[sub]; !ok {
+		return
+	}
+	delete(f.subs, sub)
+	sub.err = err
+	close(sub.ch)
+}
+
+// Subscription receives events from a Feed
+type Subscription struct {
+	feed     *Feed
+	prefixes []string
+	ch       chan Event
+	err      error // Set under feed.mu before ch is closed
+}
+
+// Events returns the delivery channel; it is closed when the subscription ends
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Err returns why the subscription ended: ErrLagged, ErrClosed, or nil while
+// active and after the subscriber's own Close
+func (s *Subscription) Err() error {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	return s.err
+}
+
+// Close unsubscribes; pending events are discarded
+func (s *Subscription) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	s.feed.drop(s, nil)
+}
+
+func (s *Subscription) matches(topic string) bool {
+	if len(s.prefixes) == 0 {
+		return true
+	}
+	for _, p := range s.prefixes {
+		if strings.HasPrefix(topic, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Tests for the change feed
+// ABOUTME: Verifies prefix filtering, ordering, lagging subscribers and shutdown
+
+package changefeed
+
+import (
+	"testing"
+)
+
+func TestTopic(t *testing.T) {
+	tests := []struct {
+		event Event
+		want  string
+	}{
+		{Event{Entity: EntityDocument, PolicyID: "P1"}, "document/P1"},
+		{Event{Entity: EntityVersion, PolicyID: "P1", EntityID: "v2"}, "version/P1/v2"},
+		{Event{Entity: EntityMetadata, EntityID: "tool_result/e1"}, "metadata/tool_result/e1"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.event.Topic(); got != tt.want {
+			t.Errorf("Topic() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestSubscribeFilters(t *testing.T) {
+	feed := NewFeed(0)
+	all := feed.Subscribe(nil)
+	versions := feed.Subscribe([]string{"version/LCD-", "document/LCD-1"})
+
+	feed.Publish(EntityDocument, OpPut, "LCD-1", "")
+	feed.Publish(EntityVersion, OpPut, "NCD-1", "v1")
+	feed.Publish(EntityVersion, OpDelete, "LCD-2", "v1")
+	feed.Publish(EntityMetadata, OpPut, "", "node/n1")
+
+	if got := len(all.Events()); got != 4 {
+		t.Errorf("Unfiltered subscriber got %d events, want 4", got)
+	}
+
+	first := <-versions.Events()
+	second := <-versions.Events()
+	if first.Topic() != "document/LCD-1" || second.Topic() != "version/LCD-2/v1" || second.Op != OpDelete {
+		t.Errorf("Unexpected filtered events: %+v, %+v", first, second)
+	}
+	if first.Seq != 1 || second.Seq != 3 {
+		t.Errorf("Expected feed-wide sequence numbers 1 and 3, got %d and %d", first.Seq, second.Seq)
+	}
+	if len(versions.Events()) != 0 {
+		t.Errorf("Filtered subscriber has %d extra events", len(versions.Events()))
+	}
+}
+
+func TestLaggingSubscriberDropped(t *testing.T) {
+	feed := NewFeed(2)
+	slow := feed.Subscribe(nil)
+
+	for i := 0; i < 3; i++ {
+		feed.Publish(EntityDocument, OpPut, "P1", "")
+	}
+
+	n := 0
+	for range slow.Events() {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("Expected the 2 buffered events before the drop, got %d", n)
+	}
+	if slow.Err() != ErrLagged {
+		t.Errorf("Expected ErrLagged, got %v", slow.Err())
+	}
+	if feed.Subscribers() != 0 {
+		t.Errorf("Lagging subscriber still registered")
+	}
+}
+
+func TestCloseAndUnsubscribe(t *testing.T) {
+	feed := NewFeed(0)
+	a := feed.Subscribe(nil)
+	b := feed.Subscribe(nil)
+
+	a.Close()
+	if _, ok := <-a.Events(); ok || a.Err() != nil {
+		t.Errorf("Closed subscription should end cleanly, err=%v", a.Err())
+	}
+	a.Close() // Idempotent
+
+	feed.Close()
+	if _, ok := <-b.Events(); ok || b.Err() != ErrClosed {
+		t.Errorf("Expected ErrClosed after feed shutdown, got %v", b.Err())
+	}
+
+	late := feed.Subscribe(nil)
+	if _, ok := <-late.Events(); ok || late.Err() != ErrClosed {
+		t.Errorf("Subscribing to a closed feed should end immediately, got %v", late.Err())
+	}
+
+	var nilFeed *Feed
+	nilFeed.Publish(EntityDocument, OpPut, "P1", "") // Must not panic
+}
@@ -0,0 +1,173 @@
+// ABOUTME: Go SDK for the TreeStore gRPC service
+// ABOUTME: Pools connections, retries calls the server could not take and applies default deadlines
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/nainya/treestore/proto"
+)
+
+// Client defaults
+const (
+	DefaultAPIKeyHeader   = "x-api-key" // The server's default -api-key-header
+	DefaultPoolSize       = 1
+	DefaultTimeout        = 30 * time.Second
+	DefaultMaxRetries     = 3
+	DefaultInitialBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff     = 2 * time.Second
+)
+
+// Config configures a client
+type Config struct {
+	PoolSize       int               // Connections opened to the server; calls are spread across them (default DefaultPoolSize)
+	APIKey         string            // Sent with every call when set
+	APIKeyHeader   string            // Metadata key carrying APIKey (default DefaultAPIKeyHeader)
+	Timeout        time.Duration     // Deadline of unary calls whose context has none (default DefaultTimeout, negative for none)
+	MaxRetries     int               // Retries of a call failing with Unavailable (default DefaultMaxRetries, negative disables)
+	InitialBackoff time.Duration     // Wait before the first retry, doubled for each one after (default DefaultInitialBackoff)
+	MaxBackoff     time.Duration     // Longest wait between retries (default DefaultMaxBackoff)
+	DialOptions    []grpc.DialOption // Added to the defaults, which use plaintext transport
+}
+
+// Client is a connection pool to a TreeStore server
+// Service exposes every RPC; the helper methods convert to and from the store
+// types. Unary calls failing with codes.Unavailable are retried with backoff.
+// The server may have applied a write whose reply was lost, so writes that
+// append, such as AddMessage, can be duplicated by a retry; disable retries
+// where that matters.
+type Client struct {
+	Service pb.TreeStoreServiceClient
+	Admin   pb.TreeStoreAdminClient // Reaches the admin service only when addr is its port
+
+	pool *pool
+}
+
+// New opens a client to the server at addr, such as "localhost:50051"
+// Connections are made lazily, so an unreachable server fails the first call rather than New.
+func New(addr string, cfg Config) (*Client, error) {
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = DefaultPoolSize
+	}
+	if cfg.APIKeyHeader == "" {
+		cfg.APIKeyHeader = DefaultAPIKeyHeader
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = DefaultInitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+
+	opts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, cfg.DialOptions...)
+	p := &pool{cfg: cfg}
+	for i := 0; i < cfg.PoolSize; i++ {
+		conn, err := grpc.NewClient(addr, opts...)
+		if err != nil {
+			p.close()
+			return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+		}
+		p.conns = append(p.conns, conn)
+	}
+
+	return &Client{
+		Service: pb.NewTreeStoreServiceClient(p),
+		Admin:   pb.NewTreeStoreAdminClient(p),
+		pool:    p,
+	}, nil
+}
+
+// Close closes every pooled connection
+func (c *Client) Close() error {
+	return c.pool.close()
+}
+
+// pool spreads calls across connections round-robin and wraps them with the
+// client's API key, deadline and retries
+type pool struct {
+	cfg   Config
+	conns []*grpc.ClientConn
+	next  atomic.Uint64
+}
+
+// conn returns the next connection in turn
+func (p *pool) conn() *grpc.ClientConn {
+	return p.conns[p.next.Add(1)%uint64(len(p.conns))]
+}
+
+// Invoke runs a unary call, retrying while the server is unavailable
+func (p *pool) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	if _, ok := ctx.Deadline(); !ok && p.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.Timeout)
+		defer cancel()
+	}
+	ctx = p.outgoing(ctx)
+	return p.retry(ctx, func() error {
+		return p.conn().Invoke(ctx, method, args, reply, opts...)
+	})
+}
+
+// NewStream opens a stream
+// Streams get no default deadline, since watches are meant to last, and are
+// not retried here: a server-side stream reports Unavailable on its first
+// receive. The streaming helpers retry until the first message arrives.
+func (p *pool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.conn().NewStream(p.outgoing(ctx), desc, method, opts...)
+}
+
+// outgoing adds the API key to a call's metadata
+func (p *pool) outgoing(ctx context.Context) context.Context {
+	if p.cfg.APIKey == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, p.cfg.APIKeyHeader, p.cfg.APIKey)
+}
+
+// retry runs call until it succeeds, fails with other than Unavailable, runs
+// out of retries or ctx ends, backing off exponentially with jitter
+func (p *pool) retry(ctx context.Context, call func() error) error {
+	backoff := p.cfg.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if status.Code(err) != codes.Unavailable || attempt >= p.cfg.MaxRetries {
+			return err
+		}
+
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, p.cfg.MaxBackoff)
+	}
+}
+
+// close closes the connections opened so far
+func (p *pool) close() error {
+	var firstErr error
+	for _, conn := range p.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
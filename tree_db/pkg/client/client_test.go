@@ -0,0 +1,205 @@
+// ABOUTME: Tests for the Go client SDK
+// ABOUTME: Verifies retries, default deadlines, API keys, pooling, streaming and typed node helpers
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/nainya/treestore/internal/server"
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/storage"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// flakyServer fails the first calls of each RPC with Unavailable
+type flakyServer struct {
+	pb.UnimplementedTreeStoreServiceServer
+
+	mu       sync.Mutex
+	failures int // Calls of each RPC that fail before it succeeds
+	calls    map[string]int
+	deadline bool
+	apiKey   string
+}
+
+func (s *flakyServer) fail(method string, ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[method]++
+	_, s.deadline = ctx.Deadline()
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(DefaultAPIKeyHeader)) > 0 {
+		s.apiKey = md.Get(DefaultAPIKeyHeader)[0]
+	}
+	if s.calls[method] <= s.failures {
+		return status.Error(codes.Unavailable, "warming up")
+	}
+	return nil
+}
+
+func (s *flakyServer) GetNode(ctx context.Context, req *pb.GetNodeRequest) (*pb.GetNodeResponse, error) {
+	if err := s.fail("GetNode", ctx); err != nil {
+		return nil, err
+	}
+	if req.NodeId == "missing" {
+		return nil, status.Error(codes.NotFound, "node not found")
+	}
+	return &pb.GetNodeResponse{Node: &pb.Node{PolicyId: req.PolicyId, NodeId: req.NodeId, ParentId: "root"}}, nil
+}
+
+func (s *flakyServer) StreamQuery(req *pb.StreamQueryRequest, stream pb.TreeStoreService_StreamQueryServer) error {
+	if err := s.fail("StreamQuery", stream.Context()); err != nil {
+		return err
+	}
+	for _, id := range []string{"a", "b"} {
+		if err := stream.Send(&pb.QueryRow{Row: &pb.QueryRow_Node{Node: &pb.Node{NodeId: id}}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serve runs srv on an in-memory listener and returns a client of it
+func serve(t *testing.T, register func(*grpc.Server), cfg Config) *Client {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	register(grpcServer)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	cfg.DialOptions = append(cfg.DialOptions, grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	c, err := New("passthrough:///bufnet", cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClientRetries(t *testing.T) {
+	srv := &flakyServer{failures: 2, calls: make(map[string]int)}
+	c := serve(t, func(s *grpc.Server) { pb.RegisterTreeStoreServiceServer(s, srv) },
+		Config{PoolSize: 3, APIKey: "secret", InitialBackoff: time.Millisecond})
+	ctx := context.Background()
+
+	node, err := c.GetNode(ctx, "LCD-1", "a")
+	if err != nil {
+		t.Fatalf("Expected GetNode to succeed after retries, got %v", err)
+	}
+	if node.NodeID != "a" || node.ParentID == nil || *node.ParentID != "root" {
+		t.Errorf("Unexpected node %+v", node)
+	}
+	if srv.calls["GetNode"] != 3 || !srv.deadline || srv.apiKey != "secret" {
+		t.Errorf("Expected 3 calls with a deadline and the API key, got %d, %v, %q", srv.calls["GetNode"], srv.deadline, srv.apiKey)
+	}
+
+	// Other errors are returned at once
+	if _, err := c.GetNode(ctx, "LCD-1", "missing"); status.Code(err) != codes.NotFound || srv.calls["GetNode"] != 4 {
+		t.Errorf("Expected NotFound without a retry, got %v after %d calls", err, srv.calls["GetNode"])
+	}
+
+	var ids []string
+	err = c.StreamQuery(ctx, "FROM nodes WHERE policyID = 'LCD-1'", func(row *pb.QueryRow) error {
+		ids = append(ids, row.GetNode().NodeId)
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(ids, []string{"a", "b"}) || srv.calls["StreamQuery"] != 3 {
+		t.Errorf("Expected the stream reopened until it delivered [a b], got %v (%v) after %d calls", ids, err, srv.calls["StreamQuery"])
+	}
+	if srv.deadline {
+		t.Error("Expected streams opened without a default deadline")
+	}
+
+	stop := errors.New("stop")
+	err = c.StreamQuery(ctx, "FROM nodes WHERE policyID = 'LCD-1'", func(*pb.QueryRow) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected the callback's error, got %v", err)
+	}
+}
+
+func TestClientRetriesExhausted(t *testing.T) {
+	srv := &flakyServer{failures: 10, calls: make(map[string]int)}
+	c := serve(t, func(s *grpc.Server) { pb.RegisterTreeStoreServiceServer(s, srv) },
+		Config{MaxRetries: 2, InitialBackoff: time.Millisecond})
+
+	if _, err := c.GetNode(context.Background(), "LCD-1", "a"); status.Code(err) != codes.Unavailable || srv.calls["GetNode"] != 3 {
+		t.Errorf("Expected Unavailable after 3 calls, got %v after %d", err, srv.calls["GetNode"])
+	}
+
+	off := serve(t, func(s *grpc.Server) { pb.RegisterTreeStoreServiceServer(s, srv) }, Config{MaxRetries: -1})
+	srv.calls = map[string]int{}
+	if _, err := off.GetNode(context.Background(), "LCD-1", "a"); status.Code(err) != codes.Unavailable || srv.calls["GetNode"] != 1 {
+		t.Errorf("Expected a single call with retries disabled, got %v after %d", err, srv.calls["GetNode"])
+	}
+}
+
+func TestClientAgainstServer(t *testing.T) {
+	treeStore, err := server.NewServer(storage.MemoryPath)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	defer treeStore.Close()
+	c := serve(t, func(s *grpc.Server) { pb.RegisterTreeStoreServiceServer(s, treeStore) }, Config{})
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	rootID := "root"
+	nodes := []*document.Node{
+		{NodeID: rootID, PolicyID: "LCD-1", Title: "Root", ChildIDs: []string{"a"}, CreatedAt: now, UpdatedAt: now},
+		{NodeID: "a", PolicyID: "LCD-1", ParentID: &rootID, Title: "A", PageStart: 2, PageEnd: 3, Text: "Criteria", Depth: 1, CreatedAt: now, UpdatedAt: now},
+	}
+	if err := c.StoreDocument(ctx, &document.Document{PolicyID: "LCD-1", RootNodeID: rootID, CreatedAt: now, UpdatedAt: now}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	node, err := c.GetNode(ctx, "LCD-1", "a")
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if *node.ParentID != rootID || node.Text != "Criteria" || node.PageEnd != 3 || !node.CreatedAt.Equal(now) {
+		t.Errorf("Unexpected node %+v", node)
+	}
+
+	node.Title = "A, revised"
+	updated, err := c.UpdateNode(ctx, node)
+	if err != nil || updated.Title != "A, revised" {
+		t.Fatalf("UpdateNode = %+v, %v", updated, err)
+	}
+
+	subtree, err := c.GetSubtree(ctx, "LCD-1", rootID, 0)
+	if err != nil || len(subtree) != 2 || subtree[0].ParentID != nil {
+		t.Errorf("Expected the root and its child, got %v (%v)", subtree, err)
+	}
+	if children, err := c.GetChildren(ctx, "LCD-1", rootID); err != nil || len(children) != 1 || children[0].Title != "A, revised" {
+		t.Errorf("Expected the revised child, got %v (%v)", children, err)
+	}
+	if next, err := c.GetNextSection(ctx, "LCD-1", rootID); err != nil || next == nil || next.NodeID != "a" {
+		t.Errorf("Expected a after the root, got %v (%v)", next, err)
+	}
+	if prev, err := c.GetPreviousSection(ctx, "LCD-1", rootID); err != nil || prev != nil {
+		t.Errorf("Expected nothing before the root, got %v (%v)", prev, err)
+	}
+	var read []string
+	if err := c.GetReadingOrder(ctx, "LCD-1", func(node *document.Node) error {
+		read = append(read, node.NodeID)
+		return nil
+	}); err != nil || strings.Join(read, ",") != "root,a" {
+		t.Errorf("Expected root then a, got %v (%v)", read, err)
+	}
+}
@@ -0,0 +1,146 @@
+// ABOUTME: Typed helpers over the document and query RPCs
+// ABOUTME: Take and return store types, and stream results through callbacks
+
+package client
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+
+	"github.com/nainya/treestore/pkg/convert"
+	"github.com/nainya/treestore/pkg/document"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// StoreDocument stores a document and its nodes, replacing any stored under its policy
+func (c *Client) StoreDocument(ctx context.Context, doc *document.Document, nodes []*document.Node) error {
+	_, err := c.Service.StoreDocument(ctx, &pb.StoreDocumentRequest{
+		Document: convert.DocumentToPb(doc),
+		Nodes:    convert.NodesToPb(nodes),
+	})
+	return err
+}
+
+// GetNode returns one node
+func (c *Client) GetNode(ctx context.Context, policyID, nodeID string) (*document.Node, error) {
+	resp, err := c.Service.GetNode(ctx, &pb.GetNodeRequest{PolicyId: policyID, NodeId: nodeID})
+	if err != nil {
+		return nil, err
+	}
+	return convert.NodeFromPb(resp.Node), nil
+}
+
+// UpdateNode updates a node and returns it as stored, with its new version
+func (c *Client) UpdateNode(ctx context.Context, node *document.Node) (*document.Node, error) {
+	resp, err := c.Service.UpdateNode(ctx, &pb.UpdateNodeRequest{Node: convert.NodeToPb(node)})
+	if err != nil {
+		return nil, err
+	}
+	return convert.NodeFromPb(resp.Node), nil
+}
+
+// GetChildren returns the children of a node; an empty parentID returns the roots
+func (c *Client) GetChildren(ctx context.Context, policyID, parentID string) ([]*document.Node, error) {
+	resp, err := c.Service.GetChildren(ctx, &pb.GetChildrenRequest{PolicyId: policyID, ParentId: parentID})
+	if err != nil {
+		return nil, err
+	}
+	return convert.NodesFromPb(resp.Children), nil
+}
+
+// GetSubtree returns a node and its descendants down to maxDepth levels (0 for all)
+func (c *Client) GetSubtree(ctx context.Context, policyID, nodeID string, maxDepth int) ([]*document.Node, error) {
+	resp, err := c.Service.GetSubtree(ctx, &pb.GetSubtreeRequest{PolicyId: policyID, NodeId: nodeID, MaxDepth: int32(maxDepth)})
+	if err != nil {
+		return nil, err
+	}
+	return convert.NodesFromPb(resp.Nodes), nil
+}
+
+// GetSiblings returns the children of a node's parent in document order and
+// the node's position among them
+func (c *Client) GetSiblings(ctx context.Context, policyID, nodeID string) ([]*document.Node, int, error) {
+	resp, err := c.Service.GetSiblings(ctx, &pb.GetSiblingsRequest{PolicyId: policyID, NodeId: nodeID})
+	if err != nil {
+		return nil, 0, err
+	}
+	return convert.NodesFromPb(resp.Siblings), int(resp.Position), nil
+}
+
+// GetNextSection returns the section after a node in document order, or nil
+// at the end of the document
+func (c *Client) GetNextSection(ctx context.Context, policyID, nodeID string) (*document.Node, error) {
+	resp, err := c.Service.GetNextSection(ctx, &pb.AdjacentSectionRequest{PolicyId: policyID, NodeId: nodeID})
+	if err != nil || resp.Node == nil {
+		return nil, err
+	}
+	return convert.NodeFromPb(resp.Node), nil
+}
+
+// GetPreviousSection returns the section before a node in document order, or
+// nil at the start of the document
+func (c *Client) GetPreviousSection(ctx context.Context, policyID, nodeID string) (*document.Node, error) {
+	resp, err := c.Service.GetPreviousSection(ctx, &pb.AdjacentSectionRequest{PolicyId: policyID, NodeId: nodeID})
+	if err != nil || resp.Node == nil {
+		return nil, err
+	}
+	return convert.NodeFromPb(resp.Node), nil
+}
+
+// GetReadingOrder calls fn with each node of a policy in depth-first document
+// order. An error from fn stops the stream and is returned.
+func (c *Client) GetReadingOrder(ctx context.Context, policyID string, fn func(*document.Node) error) error {
+	return receive(ctx, c.pool, func(ctx context.Context) (grpc.ServerStreamingClient[pb.Node], error) {
+		return c.Service.GetReadingOrder(ctx, &pb.GetReadingOrderRequest{PolicyId: policyID})
+	}, func(node *pb.Node) error {
+		return fn(convert.NodeFromPb(node))
+	})
+}
+
+// StreamQuery runs a query in the text or JSON form and calls fn with each row
+// An error from fn stops the stream and is returned.
+func (c *Client) StreamQuery(ctx context.Context, query string, fn func(*pb.QueryRow) error) error {
+	return receive(ctx, c.pool, func(ctx context.Context) (grpc.ServerStreamingClient[pb.QueryRow], error) {
+		return c.Service.StreamQuery(ctx, &pb.StreamQueryRequest{Query: query})
+	}, fn)
+}
+
+// WatchChanges calls fn with each change event whose topic has one of
+// prefixes (all events when empty) until ctx ends, fn fails or the stream breaks
+// Events published while the stream is broken are lost, so callers that
+// reconnect should re-read what they track.
+func (c *Client) WatchChanges(ctx context.Context, prefixes []string, fn func(*pb.ChangeEvent) error) error {
+	return receive(ctx, c.pool, func(ctx context.Context) (grpc.ServerStreamingClient[pb.ChangeEvent], error) {
+		return c.Service.WatchChanges(ctx, &pb.WatchChangesRequest{Prefixes: prefixes})
+	}, fn)
+}
+
+// receive calls fn with each message of a server stream, reopening the stream
+// while the server is unavailable before it sends anything
+func receive[T any](ctx context.Context, p *pool, open func(ctx context.Context) (grpc.ServerStreamingClient[T], error), fn func(*T) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var stream grpc.ServerStreamingClient[T]
+	var msg *T
+	err := p.retry(ctx, func() error {
+		var err error
+		if stream, err = open(ctx); err != nil {
+			return err
+		}
+		msg, err = stream.Recv()
+		return err
+	})
+	for err == nil {
+		if err := fn(msg); err != nil {
+			return err
+		}
+		msg, err = stream.Recv()
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,190 @@
+// ABOUTME: Background compactor that rewrites fragmented runs of leaves while the database is idle
+// ABOUTME: Throttled by a pass interval, an idle period, a per-pass leaf budget and a fragmentation threshold
+
+package compaction
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// Defaults applied by NewCompactor
+const (
+	DefaultInterval         = time.Minute
+	DefaultIdleAfter        = 30 * time.Second
+	DefaultMaxLeaves        = 256
+	DefaultMinFragmentation = 0.2
+)
+
+// Config configures a Compactor
+type Config struct {
+	Interval         time.Duration     // Time between passes
+	IdleAfter        time.Duration     // Skip passes until no key was written for this long
+	MaxLeaves        int               // Maximum leaves rewritten per pass
+	MinFragmentation float64           // Skip passes below this share of out-of-order leaves
+	OnPass           func(*PassReport) // Called after every pass that ran, e.g. to log or export metrics
+	Lock             sync.Locker       // Held while rewriting, to keep writes out; nil takes none
+}
+
+// PassReport describes the outcome of one pass
+type PassReport struct {
+	StartedAt time.Time
+	Duration  time.Duration
+	Skipped   string // Why the pass did nothing: "busy" or "below threshold"; empty if it ran
+	Before    storage.FragmentationReport
+	After     storage.FragmentationReport
+	Moved     int // Leaves rewritten
+	Err       error
+}
+
+// Stats accumulates compactor activity since it was created
+type Stats struct {
+	Passes   int64 // Passes that rewrote leaves or failed
+	Skipped  int64 // Passes skipped as busy or below threshold
+	Moved    int64
+	LastPass *PassReport
+}
+
+// Compactor periodically rewrites the most fragmented run of leaves
+type Compactor struct {
+	kv  *storage.KV
+	cfg Config
+
+	mu    sync.Mutex
+	stats Stats
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewCompactor creates a compactor over kv
+func NewCompactor(kv *storage.KV, cfg Config) *Compactor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.IdleAfter <= 0 {
+		cfg.IdleAfter = DefaultIdleAfter
+	}
+	if cfg.MaxLeaves <= 0 {
+		cfg.MaxLeaves = DefaultMaxLeaves
+	}
+	if cfg.MinFragmentation <= 0 {
+		cfg.MinFragmentation = DefaultMinFragmentation
+	}
+
+	return &Compactor{kv: kv, cfg: cfg}
+}
+
+// Start runs passes in a background goroutine until Stop is called
+func (c *Compactor) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		return
+	}
+
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	go c.run(c.stop, c.done)
+}
+
+// Stop ends background compaction and waits for an in-progress pass to finish
+func (c *Compactor) Stop() {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop, c.done = nil, nil
+	c.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// Stats returns a snapshot of the compactor's activity
+func (c *Compactor) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// CompactOnce runs one pass as of now: unless a key was written within
+// IdleAfter or the leaves are fragmented below MinFragmentation, it rewrites
+// at most MaxLeaves of them
+func (c *Compactor) CompactOnce(now time.Time) *PassReport {
+	report := &PassReport{StartedAt: now}
+	start := time.Now()
+
+	if c.cfg.Lock != nil {
+		c.cfg.Lock.Lock()
+	}
+	c.pass(now, report)
+	if c.cfg.Lock != nil {
+		c.cfg.Lock.Unlock()
+	}
+
+	report.Duration = time.Since(start)
+
+	c.mu.Lock()
+	if report.Skipped != "" {
+		c.stats.Skipped++
+	} else {
+		c.stats.Passes++
+		c.stats.Moved += int64(report.Moved)
+	}
+	c.stats.LastPass = report
+	c.mu.Unlock()
+
+	if c.cfg.OnPass != nil && report.Skipped == "" {
+		c.cfg.OnPass(report)
+	}
+
+	return report
+}
+
+// pass measures fragmentation and rewrites one run if the database is idle
+// Callers hold the configured lock.
+func (c *Compactor) pass(now time.Time, report *PassReport) {
+	if last := c.kv.LastWrite(); !last.IsZero() && now.Sub(last) < c.cfg.IdleAfter {
+		report.Skipped = "busy"
+		return
+	}
+
+	before, err := c.kv.Fragmentation()
+	if err != nil {
+		report.Err = err
+		return
+	}
+	report.Before, report.After = before, before
+	if before.Ratio() < c.cfg.MinFragmentation {
+		report.Skipped = "below threshold"
+		return
+	}
+
+	defrag, err := c.kv.Defragment(c.cfg.MaxLeaves)
+	if defrag != nil {
+		report.After = defrag.After
+		report.Moved = defrag.Moved
+	}
+	report.Err = err
+}
+
+// run compacts on every interval tick until stop is closed
+func (c *Compactor) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			c.CompactOnce(now)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// ABOUTME: Tests for the background compactor
+// ABOUTME: Verifies idle detection, the fragmentation threshold, the leaf budget and background runs
+
+package compaction
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+func setupFragmented(t *testing.T) *storage.KV {
+	kv := &storage.KV{Path: filepath.Join(t.TempDir(), "compact.db")}
+	if err := kv.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	t.Cleanup(func() { kv.Close() })
+
+	// Keys written out of order split leaves all over the file
+	for i := 0; i < 2000; i++ {
+		key := fmt.Sprintf("key%05d", (i*7919)%2000)
+		if err := kv.Set([]byte(key), []byte("value-"+key)); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	return kv
+}
+
+func TestCompactOnceWaitsForIdle(t *testing.T) {
+	kv := setupFragmented(t)
+	c := NewCompactor(kv, Config{IdleAfter: time.Hour})
+
+	report := c.CompactOnce(time.Now())
+	if report.Skipped != "busy" || report.Moved != 0 {
+		t.Errorf("Expected a busy skip, got %+v", report)
+	}
+
+	// An hour without writes later the pass runs
+	report = c.CompactOnce(time.Now().Add(2 * time.Hour))
+	if report.Skipped != "" || report.Err != nil {
+		t.Fatalf("Expected the pass to run, got %+v", report)
+	}
+	if report.Moved == 0 || report.Moved > DefaultMaxLeaves {
+		t.Errorf("Moved %d leaves", report.Moved)
+	}
+	if report.After.Breaks >= report.Before.Breaks {
+		t.Errorf("Breaks %d -> %d", report.Before.Breaks, report.After.Breaks)
+	}
+
+	stats := c.Stats()
+	if stats.Passes != 1 || stats.Skipped != 1 || stats.Moved != int64(report.Moved) {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestCompactOnceBelowThreshold(t *testing.T) {
+	kv := setupFragmented(t)
+	if _, err := kv.Defragment(0); err != nil {
+		t.Fatalf("Defragment failed: %v", err)
+	}
+
+	var passes int
+	c := NewCompactor(kv, Config{IdleAfter: time.Nanosecond, OnPass: func(*PassReport) { passes++ }})
+	report := c.CompactOnce(time.Now().Add(time.Second))
+	if report.Skipped != "below threshold" || report.Moved != 0 {
+		t.Errorf("Expected a threshold skip, got %+v", report)
+	}
+	if passes != 0 {
+		t.Error("OnPass should only see passes that ran")
+	}
+}
+
+func TestCompactorBackgroundRuns(t *testing.T) {
+	kv := setupFragmented(t)
+	c := NewCompactor(kv, Config{
+		Interval:  10 * time.Millisecond,
+		IdleAfter: time.Nanosecond,
+		MaxLeaves: 16,
+	})
+	c.Start()
+	defer c.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Stats().Passes < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected background passes, got %+v", c.Stats())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Stop()
+
+	if moved := c.Stats().Moved; moved < 2 || moved > 16*c.Stats().Passes {
+		t.Errorf("Moved %d leaves in %d passes", moved, c.Stats().Passes)
+	}
+}
@@ -0,0 +1,35 @@
+// ABOUTME: Conversions between the protobuf messages and the store types
+// ABOUTME: Unset timestamps map to zero times and back, and empty parent IDs to root nodes
+
+package convert
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TimeToPb converts a time, leaving the zero time unset
+func TimeToPb(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// TimeFromPb converts a timestamp, returning the zero time when it is unset
+func TimeFromPb(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// convertSlice converts each element of a slice with fn
+func convertSlice[T, P any](in []*T, fn func(*T) *P) []*P {
+	out := make([]*P, len(in))
+	for i, v := range in {
+		out[i] = fn(v)
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+// ABOUTME: Tests for the protobuf converters
+// ABOUTME: Checks every field of both forms survives a round trip, and the handling of nil, roots and unset times
+
+package convert
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/metadata"
+	"github.com/nainya/treestore/pkg/prompt"
+	"github.com/nainya/treestore/pkg/version"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// checkRoundTrip converts a fully populated value to protobuf and back and
+// fails if a field of either form is lost. A field added to either type
+// without a conversion fails here until the sample and converters cover it.
+func checkRoundTrip[T any, P proto.Message](t *testing.T, in *T, toPb func(*T) P, fromPb func(P) *T) {
+	t.Helper()
+	name := reflect.TypeOf(in).Elem().Name()
+
+	v := reflect.ValueOf(in).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).IsZero() {
+			t.Errorf("%s sample leaves %s unset", name, v.Type().Field(i).Name)
+		}
+	}
+
+	msg := toPb(in)
+	fields := msg.ProtoReflect().Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if f := fields.Get(i); !msg.ProtoReflect().Has(f) {
+			t.Errorf("%s converts without %s", name, f.Name())
+		}
+	}
+
+	if out := fromPb(msg); !reflect.DeepEqual(out, in) {
+		t.Errorf("%s round trip changed it:\n got %+v\nwant %+v", name, out, in)
+	}
+}
+
+func TestRoundTrips(t *testing.T) {
+	created := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+	parentID := "root"
+
+	checkRoundTrip(t, &document.Node{
+		NodeID: "n-2", PolicyID: "LCD-1", ParentID: &parentID, Title: "Coverage", PageStart: 3, PageEnd: 5,
+		Summary: "Who is covered", Text: "Patients with", SectionPath: "1.2", ChildIDs: []string{"n-3"},
+		Depth: 1, CreatedAt: created, UpdatedAt: updated, Version: 7, Language: "en", Checksum: "9f86d081", TokenCount: 42,
+		SummaryChecksum: "60303ae2",
+	}, NodeToPb, NodeFromPb)
+
+	checkRoundTrip(t, &document.Document{
+		PolicyID: "LCD-1", VersionID: "v2", PageIndexDocID: "pi-1", RootNodeID: "root",
+		Metadata: map[string]string{"jurisdiction": "J5"}, CreatedAt: created, UpdatedAt: updated,
+	}, DocumentToPb, DocumentFromPb)
+
+	checkRoundTrip(t, &version.Version{
+		PolicyID: "LCD-1", VersionID: "v2", DocumentID: "doc-2", CreatedAt: created, CreatedBy: "ingest",
+		Description: "Annual revision", Tags: []string{"latest"}, Metadata: map[string]string{"source": "cms"},
+		EffectiveFrom: created, EffectiveTo: updated,
+	}, VersionToPb, VersionFromPb)
+
+	checkRoundTrip(t, &metadata.MetadataEntry{
+		EntityType: "node", EntityID: "n-2", Key: "status", Value: "active", ValueType: "string",
+		Version: 3, CreatedAt: created, UpdatedAt: updated,
+	}, MetadataEntryToPb, MetadataEntryFromPb)
+
+	checkRoundTrip(t, &metadata.CrossReference{
+		SourcePolicyID: "LCD-1", SourceNodeID: "n-2", TargetPolicyID: "LCD-2", TargetNodeID: "n-9",
+		ReferenceType: "cites", Context: "see also", CreatedAt: created,
+	}, CrossReferenceToPb, CrossReferenceFromPb)
+
+	checkRoundTrip(t, &metadata.TrajectoryStep{
+		StepNumber: 2, ToolName: "get_node", Action: `{"node_id":"n-2"}`, Observation: `{"node":{}}`,
+		Thought: "Read the coverage section", Timestamp: created,
+	}, TrajectoryStepToPb, TrajectoryStepFromPb)
+
+	checkRoundTrip(t, &prompt.Conversation{
+		ConversationID: "c-1", UserID: "u-1", Title: "Prior auth", StartedAt: created, LastMessageAt: updated,
+		MessageCount: 4, Tags: []string{"cardiology"}, Metadata: map[string]string{"case": "42"}, Archived: true,
+	}, ConversationToPb, ConversationFromPb)
+
+	checkRoundTrip(t, &prompt.Message{
+		MessageID: "m-1", ConversationID: "c-1", Role: "user", Content: "Is it covered?", Timestamp: created,
+		Metadata: map[string]string{"model": "x"}, EditedAt: updated, Deleted: true,
+	}, MessageToPb, MessageFromPb)
+
+	checkRoundTrip(t, &prompt.Quota{MaxConversations: 10, MaxMessages: 500, MaxConversationMessages: 100}, QuotaToPb, QuotaFromPb)
+
+	checkRoundTrip(t, &prompt.Experiment{
+		ExperimentID: "exp-1", Name: "Summary prompt", SuccessOutcomes: []string{"accepted"}, CreatedAt: created,
+		Variants: []*prompt.Variant{{Name: "control", PromptID: "p-1", Weight: 3}, {Name: "concise", PromptID: "p-2", Weight: 1}},
+	}, ExperimentToPb, ExperimentFromPb)
+}
+
+func TestConversionEdgeCases(t *testing.T) {
+	// Unset timestamps and zero times map to each other, not to 1970 or year 1
+	node := NodeToPb(&document.Node{NodeID: "root", PolicyID: "LCD-1"})
+	if node.CreatedAt != nil || node.UpdatedAt != nil || node.ParentId != "" {
+		t.Errorf("Expected a root with unset times, got %v", node)
+	}
+	back := NodeFromPb(node)
+	if back.ParentID != nil || !back.CreatedAt.IsZero() {
+		t.Errorf("Expected a root with zero times, got %+v", back)
+	}
+	if ver := VersionToPb(&version.Version{PolicyID: "LCD-1"}); ver.EffectiveTo != nil {
+		t.Error("Expected an open-ended version to leave effective_to unset")
+	}
+	if msg := MessageFromPb(&pb.Message{MessageId: "m-1"}); !msg.EditedAt.IsZero() {
+		t.Error("Expected an unedited message to have a zero EditedAt")
+	}
+
+	if NodeToPb(nil) != nil || NodeFromPb(nil) != nil || DocumentToPb(nil) != nil || DocumentFromPb(nil) != nil ||
+		VersionToPb(nil) != nil || VersionFromPb(nil) != nil || MetadataEntryToPb(nil) != nil ||
+		MetadataEntryFromPb(nil) != nil || CrossReferenceToPb(nil) != nil || CrossReferenceFromPb(nil) != nil ||
+		ConversationToPb(nil) != nil || ConversationFromPb(nil) != nil || MessageToPb(nil) != nil || MessageFromPb(nil) != nil {
+		t.Error("Expected nil to convert to nil")
+	}
+
+	nodes := NodesFromPb(NodesToPb([]*document.Node{{NodeID: "a"}, {NodeID: "b"}}))
+	if len(nodes) != 2 || nodes[1].NodeID != "b" {
+		t.Errorf("Expected both nodes converted in order, got %v", nodes)
+	}
+	if got := MessagesToPb(nil); got == nil || len(got) != 0 {
+		t.Errorf("Expected an empty, non-nil slice, got %#v", got)
+	}
+}
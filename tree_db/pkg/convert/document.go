@@ -0,0 +1,115 @@
+// ABOUTME: Conversions of documents and nodes
+// ABOUTME: Covers document.Document and document.Node in both directions
+
+package convert
+
+import (
+	"github.com/nainya/treestore/pkg/document"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// NodeToPb converts a document node to its protobuf form
+func NodeToPb(node *document.Node) *pb.Node {
+	if node == nil {
+		return nil
+	}
+	parentID := ""
+	if node.ParentID != nil {
+		parentID = *node.ParentID
+	}
+
+	return &pb.Node{
+		NodeId:          node.NodeID,
+		PolicyId:        node.PolicyID,
+		ParentId:        parentID,
+		Title:           node.Title,
+		PageStart:       int32(node.PageStart),
+		PageEnd:         int32(node.PageEnd),
+		Summary:         node.Summary,
+		Text:            node.Text,
+		SectionPath:     node.SectionPath,
+		ChildIds:        node.ChildIDs,
+		Depth:           int32(node.Depth),
+		CreatedAt:       TimeToPb(node.CreatedAt),
+		UpdatedAt:       TimeToPb(node.UpdatedAt),
+		Version:         node.Version,
+		Language:        node.Language,
+		Checksum:        node.Checksum,
+		TokenCount:      int32(node.TokenCount),
+		SummaryChecksum: node.SummaryChecksum,
+	}
+}
+
+// NodeFromPb converts a protobuf node to a document node
+func NodeFromPb(n *pb.Node) *document.Node {
+	if n == nil {
+		return nil
+	}
+	var parentID *string
+	if n.ParentId != "" {
+		parentID = &n.ParentId
+	}
+
+	return &document.Node{
+		NodeID:          n.NodeId,
+		PolicyID:        n.PolicyId,
+		ParentID:        parentID,
+		Title:           n.Title,
+		PageStart:       int(n.PageStart),
+		PageEnd:         int(n.PageEnd),
+		Summary:         n.Summary,
+		Text:            n.Text,
+		SectionPath:     n.SectionPath,
+		ChildIDs:        n.ChildIds,
+		Depth:           int(n.Depth),
+		Version:         n.Version,
+		Language:        n.Language,
+		Checksum:        n.Checksum,
+		TokenCount:      int(n.TokenCount),
+		SummaryChecksum: n.SummaryChecksum,
+		CreatedAt:       TimeFromPb(n.CreatedAt),
+		UpdatedAt:       TimeFromPb(n.UpdatedAt),
+	}
+}
+
+// NodesToPb converts document nodes to their protobuf form
+func NodesToPb(nodes []*document.Node) []*pb.Node {
+	return convertSlice(nodes, NodeToPb)
+}
+
+// NodesFromPb converts protobuf nodes to document nodes
+func NodesFromPb(nodes []*pb.Node) []*document.Node {
+	return convertSlice(nodes, NodeFromPb)
+}
+
+// DocumentToPb converts a document to its protobuf form
+func DocumentToPb(doc *document.Document) *pb.Document {
+	if doc == nil {
+		return nil
+	}
+	return &pb.Document{
+		PolicyId:       doc.PolicyID,
+		VersionId:      doc.VersionID,
+		PageindexDocId: doc.PageIndexDocID,
+		RootNodeId:     doc.RootNodeID,
+		Metadata:       doc.Metadata,
+		CreatedAt:      TimeToPb(doc.CreatedAt),
+		UpdatedAt:      TimeToPb(doc.UpdatedAt),
+	}
+}
+
+// DocumentFromPb converts a protobuf document to a document
+func DocumentFromPb(d *pb.Document) *document.Document {
+	if d == nil {
+		return nil
+	}
+	return &document.Document{
+		PolicyID:       d.PolicyId,
+		VersionID:      d.VersionId,
+		PageIndexDocID: d.PageindexDocId,
+		RootNodeID:     d.RootNodeId,
+		Metadata:       d.Metadata,
+		CreatedAt:      TimeFromPb(d.CreatedAt),
+		UpdatedAt:      TimeFromPb(d.UpdatedAt),
+	}
+}
@@ -0,0 +1,120 @@
+// ABOUTME: Conversions of metadata entries, cross references and trajectory steps
+// ABOUTME: Covers metadata.MetadataEntry, metadata.CrossReference and metadata.TrajectoryStep in both directions
+
+package convert
+
+import (
+	"github.com/nainya/treestore/pkg/metadata"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// MetadataEntryToPb converts a metadata entry to its protobuf form
+func MetadataEntryToPb(entry *metadata.MetadataEntry) *pb.MetadataEntry {
+	if entry == nil {
+		return nil
+	}
+	return &pb.MetadataEntry{
+		EntityType: entry.EntityType,
+		EntityId:   entry.EntityID,
+		Key:        entry.Key,
+		Value:      entry.Value,
+		ValueType:  entry.ValueType,
+		CreatedAt:  TimeToPb(entry.CreatedAt),
+		UpdatedAt:  TimeToPb(entry.UpdatedAt),
+		Version:    entry.Version,
+	}
+}
+
+// MetadataEntryFromPb converts a protobuf metadata entry to a metadata entry
+func MetadataEntryFromPb(e *pb.MetadataEntry) *metadata.MetadataEntry {
+	if e == nil {
+		return nil
+	}
+	return &metadata.MetadataEntry{
+		EntityType: e.EntityType,
+		EntityID:   e.EntityId,
+		Key:        e.Key,
+		Value:      e.Value,
+		ValueType:  e.ValueType,
+		Version:    e.Version,
+		CreatedAt:  TimeFromPb(e.CreatedAt),
+		UpdatedAt:  TimeFromPb(e.UpdatedAt),
+	}
+}
+
+// CrossReferenceToPb converts a cross reference to its protobuf form
+func CrossReferenceToPb(ref *metadata.CrossReference) *pb.CrossReference {
+	if ref == nil {
+		return nil
+	}
+	return &pb.CrossReference{
+		SourcePolicyId: ref.SourcePolicyID,
+		SourceNodeId:   ref.SourceNodeID,
+		TargetPolicyId: ref.TargetPolicyID,
+		TargetNodeId:   ref.TargetNodeID,
+		ReferenceType:  ref.ReferenceType,
+		Context:        ref.Context,
+		CreatedAt:      TimeToPb(ref.CreatedAt),
+	}
+}
+
+// CrossReferenceFromPb converts a protobuf cross reference to a cross reference
+func CrossReferenceFromPb(r *pb.CrossReference) *metadata.CrossReference {
+	if r == nil {
+		return nil
+	}
+	return &metadata.CrossReference{
+		SourcePolicyID: r.SourcePolicyId,
+		SourceNodeID:   r.SourceNodeId,
+		TargetPolicyID: r.TargetPolicyId,
+		TargetNodeID:   r.TargetNodeId,
+		ReferenceType:  r.ReferenceType,
+		Context:        r.Context,
+		CreatedAt:      TimeFromPb(r.CreatedAt),
+	}
+}
+
+// CrossReferencesToPb converts cross references to their protobuf form
+func CrossReferencesToPb(refs []*metadata.CrossReference) []*pb.CrossReference {
+	return convertSlice(refs, CrossReferenceToPb)
+}
+
+// TrajectoryStepToPb converts a trajectory step to its protobuf form
+func TrajectoryStepToPb(step *metadata.TrajectoryStep) *pb.TrajectoryStep {
+	if step == nil {
+		return nil
+	}
+	return &pb.TrajectoryStep{
+		StepNumber:  int32(step.StepNumber),
+		ToolName:    step.ToolName,
+		Action:      step.Action,
+		Observation: step.Observation,
+		Thought:     step.Thought,
+		Timestamp:   TimeToPb(step.Timestamp),
+	}
+}
+
+// TrajectoryStepFromPb converts a protobuf trajectory step to a trajectory step
+func TrajectoryStepFromPb(s *pb.TrajectoryStep) *metadata.TrajectoryStep {
+	if s == nil {
+		return nil
+	}
+	return &metadata.TrajectoryStep{
+		StepNumber:  int(s.StepNumber),
+		ToolName:    s.ToolName,
+		Action:      s.Action,
+		Observation: s.Observation,
+		Thought:     s.Thought,
+		Timestamp:   TimeFromPb(s.Timestamp),
+	}
+}
+
+// TrajectoryStepsToPb converts trajectory steps to their protobuf form
+func TrajectoryStepsToPb(steps []*metadata.TrajectoryStep) []*pb.TrajectoryStep {
+	return convertSlice(steps, TrajectoryStepToPb)
+}
+
+// TrajectoryStepsFromPb converts protobuf trajectory steps to trajectory steps
+func TrajectoryStepsFromPb(steps []*pb.TrajectoryStep) []*metadata.TrajectoryStep {
+	return convertSlice(steps, TrajectoryStepFromPb)
+}
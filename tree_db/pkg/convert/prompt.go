@@ -0,0 +1,182 @@
+// ABOUTME: Conversions of conversations, messages, conversation quotas and prompt experiments
+// ABOUTME: An unset edited_at marks a message never edited; an unset quota is the server default
+
+package convert
+
+import (
+	"github.com/nainya/treestore/pkg/prompt"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// ConversationToPb converts a conversation to its protobuf form
+func ConversationToPb(conv *prompt.Conversation) *pb.Conversation {
+	if conv == nil {
+		return nil
+	}
+	return &pb.Conversation{
+		ConversationId: conv.ConversationID,
+		UserId:         conv.UserID,
+		Title:          conv.Title,
+		StartedAt:      TimeToPb(conv.StartedAt),
+		LastMessageAt:  TimeToPb(conv.LastMessageAt),
+		MessageCount:   int32(conv.MessageCount),
+		Tags:           conv.Tags,
+		Metadata:       conv.Metadata,
+		Archived:       conv.Archived,
+	}
+}
+
+// ConversationFromPb converts a protobuf conversation to a conversation
+func ConversationFromPb(c *pb.Conversation) *prompt.Conversation {
+	if c == nil {
+		return nil
+	}
+	return &prompt.Conversation{
+		ConversationID: c.ConversationId,
+		UserID:         c.UserId,
+		Title:          c.Title,
+		StartedAt:      TimeFromPb(c.StartedAt),
+		LastMessageAt:  TimeFromPb(c.LastMessageAt),
+		MessageCount:   int(c.MessageCount),
+		Tags:           c.Tags,
+		Metadata:       c.Metadata,
+		Archived:       c.Archived,
+	}
+}
+
+// ConversationsToPb converts conversations to their protobuf form
+func ConversationsToPb(convs []*prompt.Conversation) []*pb.Conversation {
+	return convertSlice(convs, ConversationToPb)
+}
+
+// QuotaToPb converts a conversation quota to its protobuf form
+func QuotaToPb(q *prompt.Quota) *pb.ConversationQuota {
+	if q == nil {
+		return nil
+	}
+	return &pb.ConversationQuota{
+		MaxConversations:        q.MaxConversations,
+		MaxMessages:             q.MaxMessages,
+		MaxConversationMessages: q.MaxConversationMessages,
+	}
+}
+
+// QuotaFromPb converts a protobuf quota to a conversation quota
+func QuotaFromPb(q *pb.ConversationQuota) *prompt.Quota {
+	if q == nil {
+		return nil
+	}
+	return &prompt.Quota{
+		MaxConversations:        q.MaxConversations,
+		MaxMessages:             q.MaxMessages,
+		MaxConversationMessages: q.MaxConversationMessages,
+	}
+}
+
+// MessageToPb converts a conversation message to its protobuf form
+func MessageToPb(msg *prompt.Message) *pb.Message {
+	if msg == nil {
+		return nil
+	}
+	return &pb.Message{
+		MessageId:      msg.MessageID,
+		ConversationId: msg.ConversationID,
+		Role:           msg.Role,
+		Content:        msg.Content,
+		Timestamp:      TimeToPb(msg.Timestamp),
+		Metadata:       msg.Metadata,
+		EditedAt:       TimeToPb(msg.EditedAt),
+		Deleted:        msg.Deleted,
+	}
+}
+
+// MessageFromPb converts a protobuf message to a conversation message
+func MessageFromPb(m *pb.Message) *prompt.Message {
+	if m == nil {
+		return nil
+	}
+	return &prompt.Message{
+		MessageID:      m.MessageId,
+		ConversationID: m.ConversationId,
+		Role:           m.Role,
+		Content:        m.Content,
+		Timestamp:      TimeFromPb(m.Timestamp),
+		Metadata:       m.Metadata,
+		EditedAt:       TimeFromPb(m.EditedAt),
+		Deleted:        m.Deleted,
+	}
+}
+
+// MessagesToPb converts conversation messages to their protobuf form
+func MessagesToPb(messages []*prompt.Message) []*pb.Message {
+	return convertSlice(messages, MessageToPb)
+}
+
+// VariantToPb converts an experiment variant to its protobuf form
+func VariantToPb(v *prompt.Variant) *pb.PromptVariant {
+	if v == nil {
+		return nil
+	}
+	return &pb.PromptVariant{Name: v.Name, PromptId: v.PromptID, Weight: int32(v.Weight)}
+}
+
+// VariantFromPb converts a protobuf variant to an experiment variant
+func VariantFromPb(v *pb.PromptVariant) *prompt.Variant {
+	if v == nil {
+		return nil
+	}
+	return &prompt.Variant{Name: v.Name, PromptID: v.PromptId, Weight: int(v.Weight)}
+}
+
+// ExperimentToPb converts a prompt experiment to its protobuf form
+func ExperimentToPb(exp *prompt.Experiment) *pb.PromptExperiment {
+	if exp == nil {
+		return nil
+	}
+	return &pb.PromptExperiment{
+		ExperimentId:    exp.ExperimentID,
+		Name:            exp.Name,
+		Variants:        convertSlice(exp.Variants, VariantToPb),
+		SuccessOutcomes: exp.SuccessOutcomes,
+		CreatedAt:       TimeToPb(exp.CreatedAt),
+	}
+}
+
+// ExperimentFromPb converts a protobuf experiment to a prompt experiment
+func ExperimentFromPb(e *pb.PromptExperiment) *prompt.Experiment {
+	if e == nil {
+		return nil
+	}
+	return &prompt.Experiment{
+		ExperimentID:    e.ExperimentId,
+		Name:            e.Name,
+		Variants:        convertSlice(e.Variants, VariantFromPb),
+		SuccessOutcomes: e.SuccessOutcomes,
+		CreatedAt:       TimeFromPb(e.CreatedAt),
+	}
+}
+
+// VariantStatsToPb converts a variant's aggregated usages to their protobuf form
+func VariantStatsToPb(vs *prompt.VariantStats) *pb.VariantStats {
+	if vs == nil {
+		return nil
+	}
+	outcomes := make(map[string]int32, len(vs.Outcomes))
+	for outcome, n := range vs.Outcomes {
+		outcomes[outcome] = int32(n)
+	}
+	return &pb.VariantStats{
+		Variant:     vs.Variant,
+		PromptId:    vs.PromptID,
+		Usages:      int32(vs.Usages),
+		Labeled:     int32(vs.Labeled),
+		Successes:   int32(vs.Successes),
+		SuccessRate: vs.SuccessRate,
+		Outcomes:    outcomes,
+	}
+}
+
+// VariantStatsListToPb converts the stats of an experiment's variants to their protobuf form
+func VariantStatsListToPb(stats []*prompt.VariantStats) []*pb.VariantStats {
+	return convertSlice(stats, VariantStatsToPb)
+}
@@ -0,0 +1,52 @@
+// ABOUTME: Conversions of policy versions
+// ABOUTME: An unset effective_to keeps a version effective until superseded
+
+package convert
+
+import (
+	"github.com/nainya/treestore/pkg/version"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// VersionToPb converts a policy version to its protobuf form
+func VersionToPb(ver *version.Version) *pb.PolicyVersion {
+	if ver == nil {
+		return nil
+	}
+	return &pb.PolicyVersion{
+		PolicyId:      ver.PolicyID,
+		VersionId:     ver.VersionID,
+		DocumentId:    ver.DocumentID,
+		CreatedAt:     TimeToPb(ver.CreatedAt),
+		CreatedBy:     ver.CreatedBy,
+		Description:   ver.Description,
+		Tags:          ver.Tags,
+		EffectiveFrom: TimeToPb(ver.EffectiveFrom),
+		EffectiveTo:   TimeToPb(ver.EffectiveTo),
+		Metadata:      ver.Metadata,
+	}
+}
+
+// VersionFromPb converts a protobuf policy version to a version
+func VersionFromPb(v *pb.PolicyVersion) *version.Version {
+	if v == nil {
+		return nil
+	}
+	return &version.Version{
+		PolicyID:      v.PolicyId,
+		VersionID:     v.VersionId,
+		DocumentID:    v.DocumentId,
+		CreatedAt:     TimeFromPb(v.CreatedAt),
+		CreatedBy:     v.CreatedBy,
+		Description:   v.Description,
+		Tags:          v.Tags,
+		Metadata:      v.Metadata,
+		EffectiveFrom: TimeFromPb(v.EffectiveFrom),
+		EffectiveTo:   TimeFromPb(v.EffectiveTo),
+	}
+}
+
+// VersionsToPb converts policy versions to their protobuf form
+func VersionsToPb(vers []*version.Version) []*pb.PolicyVersion {
+	return convertSlice(vers, VersionToPb)
+}
@@ -0,0 +1,377 @@
+// ABOUTME: Optional per-policy bloom filters of node IDs, answering "no such node" without a tree lookup
+// ABOUTME: Persisted in fixed-size blocks beside the nodes, kept current by writes and rebuilt by Reindex
+
+package document
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// PREFIX_BLOOM keys a policy's filter header as (policyID) and its blocks as (policyID, block)
+const PREFIX_BLOOM = uint32(5300)
+
+// Filter sizing: ten bits per node and seven probes give about 1% false positives
+const (
+	bloomBlockSize   = 1024 // Bytes per persisted block, well under the value size limit
+	bloomBitsPerNode = 10
+	bloomHashes      = 7
+	bloomMinCapacity = 256
+)
+
+// BloomStats counts lookups that consulted a filter
+type BloomStats struct {
+	Checks   int64 // Lookups checked against a loaded filter
+	Rejected int64 // Lookups the filter answered as missing without reading the tree
+}
+
+// bloomFilter is a blocked bloom filter: each node ID sets bits in one block
+type bloomFilter struct {
+	count    int64 // Node IDs added
+	capacity int64 // Node IDs it was sized for; past this it is rebuilt larger
+	blocks   [][]byte
+	dirty    map[int]bool // Blocks copied and changed since the filter was loaded
+}
+
+// newBloomFilter creates an empty filter sized for capacity node IDs
+func newBloomFilter(capacity int64) *bloomFilter {
+	if capacity < bloomMinCapacity {
+		capacity = bloomMinCapacity
+	}
+	n := (capacity*bloomBitsPerNode + bloomBlockSize*8 - 1) / (bloomBlockSize * 8)
+	f := &bloomFilter{capacity: capacity, blocks: make([][]byte, n), dirty: make(map[int]bool)}
+	for i := range f.blocks {
+		f.blocks[i] = make([]byte, bloomBlockSize)
+		f.dirty[i] = true
+	}
+	return f
+}
+
+// probe returns the block a node ID falls in and the hash pair locating its bits
+func (f *bloomFilter) probe(nodeID string) (block int, h1, h2 uint32) {
+	h := fnv.New64a()
+	h.Write([]byte(nodeID))
+	sum := h.Sum64()
+	mixed := sum * 0x9E3779B97F4A7C15
+	return int((mixed >> 32) % uint64(len(f.blocks))), uint32(sum), uint32(sum>>32) | 1
+}
+
+// add sets a node ID's bits, copying its block first unless this filter already owns it
+func (f *bloomFilter) add(nodeID string) {
+	block, h1, h2 := f.probe(nodeID)
+	if !f.dirty[block] {
+		f.blocks[block] = append([]byte(nil), f.blocks[block]...)
+		f.dirty[block] = true
+	}
+	bits := f.blocks[block]
+	for i := uint32(0); i < bloomHashes; i++ {
+		pos := (h1 + i*h2) % (bloomBlockSize * 8)
+		bits[pos/8] |= 1 << (pos % 8)
+	}
+	f.count++
+}
+
+// mayContain reports whether a node ID may have been added
+func (f *bloomFilter) mayContain(nodeID string) bool {
+	block, h1, h2 := f.probe(nodeID)
+	bits := f.blocks[block]
+	for i := uint32(0); i < bloomHashes; i++ {
+		pos := (h1 + i*h2) % (bloomBlockSize * 8)
+		if bits[pos/8]&(1<<(pos%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// fork returns a copy to modify, sharing blocks with f until they change
+func (f *bloomFilter) fork() *bloomFilter {
+	return &bloomFilter{
+		count:    f.count,
+		capacity: f.capacity,
+		blocks:   append([][]byte(nil), f.blocks...),
+		dirty:    make(map[int]bool),
+	}
+}
+
+// bloomKey returns the header key of a policy's filter
+func bloomKey(policyID string) []byte {
+	return storage.EncodeKey(PREFIX_BLOOM, []storage.Value{storage.NewBytesValue([]byte(policyID))})
+}
+
+// bloomBlockKey returns the key of one block of a policy's filter
+func bloomBlockKey(policyID string, block int) []byte {
+	return storage.EncodeKey(PREFIX_BLOOM, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewInt64Value(int64(block)),
+	})
+}
+
+// loadBloom reads a policy's filter, returning nil if it has none or an
+// incomplete one, which lookups then ignore and writes rebuild
+func loadBloom(r storage.Scanner, policyID string) (*bloomFilter, error) {
+	var f *bloomFilter
+	blocks := 0
+	err := storage.ScanPrefix(r, bloomKey(policyID), func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) == 0 {
+			return false
+		}
+		if len(vals) == 1 {
+			header, err := storage.DecodeValues(val)
+			if err != nil || len(header) < 3 || header[2].I64 <= 0 {
+				return false
+			}
+			f = &bloomFilter{
+				count:    header[0].I64,
+				capacity: header[1].I64,
+				blocks:   make([][]byte, header[2].I64),
+				dirty:    make(map[int]bool),
+			}
+			return true
+		}
+		block := int(vals[1].I64)
+		if f == nil || block != blocks || block >= len(f.blocks) || len(val) != bloomBlockSize {
+			return false
+		}
+		f.blocks[block] = append([]byte(nil), val...)
+		blocks++
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if f == nil || blocks != len(f.blocks) {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// saveBloom writes a filter's header and the blocks it changed
+func saveBloom(tx storage.Txn, policyID string, f *bloomFilter) {
+	tx.Set(bloomKey(policyID), storage.EncodeValues([]storage.Value{
+		storage.NewInt64Value(f.count),
+		storage.NewInt64Value(f.capacity),
+		storage.NewInt64Value(int64(len(f.blocks))),
+	}))
+	for block := range f.dirty {
+		tx.Set(bloomBlockKey(policyID, block), f.blocks[block])
+	}
+	f.dirty = make(map[int]bool)
+}
+
+// deleteBloom removes a policy's filter, or every filter when policyID is empty
+func deleteBloom(tx storage.Txn, policyID string) {
+	var scope []storage.Value
+	if policyID != "" {
+		scope = []storage.Value{storage.NewBytesValue([]byte(policyID))}
+	}
+	var keys [][]byte
+	storage.ScanPrefix(tx, storage.EncodeKey(PREFIX_BLOOM, scope), func(key, val []byte) bool {
+		keys = append(keys, append([]byte(nil), key...))
+		return true
+	})
+	for _, key := range keys {
+		tx.Del(key)
+	}
+}
+
+// buildBloom creates a filter of a policy's stored node IDs, sized for twice
+// as many so it is not rebuilt again soon
+func buildBloom(tx storage.Txn, policyID string) *bloomFilter {
+	var nodeIDs []string
+	start := storage.EncodeKey(PREFIX_NODE, []storage.Value{storage.NewBytesValue([]byte(policyID))})
+	storage.ScanPrefix(tx, start, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 2 {
+			return false
+		}
+		nodeIDs = append(nodeIDs, string(vals[1].Str))
+		return true
+	})
+	f := newBloomFilter(2 * int64(len(nodeIDs)))
+	for _, nodeID := range nodeIDs {
+		f.add(nodeID)
+	}
+	return f
+}
+
+// bloomSet caches the filters of a SimpleStore; it is shared by its views
+type bloomSet struct {
+	enabled  atomic.Bool
+	checks   atomic.Int64
+	rejected atomic.Int64
+
+	mu      sync.Mutex
+	filters map[string]*bloomFilter // Loaded filters; a nil entry records a policy without one
+	gen     uint64                  // Bumped when a write publishes, so loads it overlapped are not cached
+	writing bool                    // A write between record and publish may have half-written filters
+}
+
+func newBloomSet() *bloomSet {
+	return &bloomSet{filters: make(map[string]*bloomFilter)}
+}
+
+// cached returns the loaded filter of a policy, loading it through r first.
+// Filters are never modified once cached, so callers may read them unlocked.
+// While a write is in progress an uncached filter is not read, since its
+// blocks may be half rewritten; ok is then false.
+func (b *bloomSet) cached(r storage.Scanner, policyID string) (f *bloomFilter, ok bool) {
+	b.mu.Lock()
+	f, ok = b.filters[policyID]
+	gen, writing := b.gen, b.writing
+	b.mu.Unlock()
+	if ok || writing {
+		return f, ok
+	}
+
+	f, err := loadBloom(r, policyID)
+	if err != nil {
+		return nil, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.gen != gen || b.writing {
+		return nil, false
+	}
+	b.filters[policyID] = f
+	return f, true
+}
+
+// mayContain reports whether a node may exist; it is true whenever filters are
+// off or the policy's filter cannot be read
+func (b *bloomSet) mayContain(r storage.Scanner, policyID, nodeID string) bool {
+	if !b.enabled.Load() {
+		return true
+	}
+	f, ok := b.cached(r, policyID)
+	if !ok || f == nil {
+		return true
+	}
+	b.checks.Add(1)
+	if f.mayContain(nodeID) {
+		return true
+	}
+	b.rejected.Add(1)
+	return false
+}
+
+// record adds the node IDs a transaction created to their policies' filters,
+// returning the filters to publish once it commits. A policy without a filter,
+// or whose filter is full, gets one built from its nodes. With filters off, a
+// policy's filter is deleted instead, since it would miss these nodes.
+// Callers hold the store's write lock and must call publish afterwards.
+func (b *bloomSet) record(tx storage.Txn, created map[string][]string) map[string]*bloomFilter {
+	b.mu.Lock()
+	b.writing = true
+	b.mu.Unlock()
+
+	updated := make(map[string]*bloomFilter, len(created))
+	for policyID, nodeIDs := range created {
+		if !b.enabled.Load() {
+			deleteBloom(tx, policyID)
+			updated[policyID] = nil
+			continue
+		}
+
+		b.mu.Lock()
+		f, ok := b.filters[policyID]
+		b.mu.Unlock()
+		if !ok {
+			f, _ = loadBloom(tx, policyID)
+		}
+		if f != nil && f.count+int64(len(nodeIDs)) <= f.capacity {
+			f = f.fork()
+			for _, nodeID := range nodeIDs {
+				f.add(nodeID)
+			}
+		} else {
+			// The transaction has already written the new nodes
+			deleteBloom(tx, policyID)
+			f = buildBloom(tx, policyID)
+		}
+		saveBloom(tx, policyID, f)
+		updated[policyID] = f
+	}
+	return updated
+}
+
+// publish caches the filters of a committed transaction, or forgets them so
+// they are loaded again when it failed
+func (b *bloomSet) publish(updated map[string]*bloomFilter, committed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.gen++
+	b.writing = false
+	for policyID, f := range updated {
+		if committed {
+			b.filters[policyID] = f
+		} else {
+			delete(b.filters, policyID)
+		}
+	}
+}
+
+// reset forgets every cached filter
+func (b *bloomSet) reset() {
+	b.mu.Lock()
+	b.gen++
+	b.filters = make(map[string]*bloomFilter)
+	b.mu.Unlock()
+}
+
+// SetBloomFilters turns the per-policy node ID filters on or off. When on,
+// GetNode and GetNodes skip the tree for node IDs a policy's filter rules
+// out, and writes keep the filters current; when off, writes that add nodes
+// delete their policy's filter. Reindex rebuilds them.
+func (ss *SimpleStore) SetBloomFilters(on bool) {
+	ss.blooms.enabled.Store(on)
+	ss.blooms.reset()
+}
+
+// BloomStats returns how many lookups consulted a filter and how many it answered
+func (ss *SimpleStore) BloomStats() BloomStats {
+	return BloomStats{Checks: ss.blooms.checks.Load(), Rejected: ss.blooms.rejected.Load()}
+}
+
+// commitCreated commits a transaction that created nodes, given by policy,
+// keeping their policies' filters current
+func (ss *SimpleStore) commitCreated(tx storage.Txn, created map[string][]string) error {
+	if len(created) == 0 {
+		return tx.Commit()
+	}
+	updated := ss.blooms.record(tx, created)
+	err := tx.Commit()
+	ss.blooms.publish(updated, err == nil)
+	return err
+}
+
+// rebuild replaces the filters of a policy, or of every policy when policyID
+// is empty, with ones built from the given node IDs by policy; with filters
+// off they are only deleted. Callers must call publish afterwards.
+func (b *bloomSet) rebuild(tx storage.Txn, policyID string, nodeIDs map[string][]string) map[string]*bloomFilter {
+	b.mu.Lock()
+	b.writing = true
+	b.mu.Unlock()
+
+	deleteBloom(tx, policyID)
+	updated := make(map[string]*bloomFilter, len(nodeIDs))
+	if policyID != "" {
+		updated[policyID] = nil
+	}
+	if !b.enabled.Load() {
+		return updated
+	}
+	for policy, ids := range nodeIDs {
+		f := newBloomFilter(2 * int64(len(ids)))
+		for _, nodeID := range ids {
+			f.add(nodeID)
+		}
+		saveBloom(tx, policy, f)
+		updated[policy] = f
+	}
+	return updated
+}
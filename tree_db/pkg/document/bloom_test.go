@@ -0,0 +1,138 @@
+// ABOUTME: Tests for the per-policy node ID bloom filters
+// ABOUTME: Verifies negative lookups skip the tree, growth, persistence, disabling and Reindex rebuilds
+
+package document
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// storeNodes stores n flat nodes of a policy with IDs prefixed by prefix
+func storeNodes(t *testing.T, ds *SimpleStore, policyID, prefix string, n int) {
+	t.Helper()
+	nodes := make([]*Node, n)
+	for i := range nodes {
+		nodes[i] = &Node{PolicyID: policyID, NodeID: fmt.Sprintf("%s-%d", prefix, i), Title: "Section"}
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: policyID}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+}
+
+func TestBloomFilterAnswersMissingNodes(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+	ds.SetBloomFilters(true)
+
+	storeNodes(t, ds, "LCD-1", "a", 100)
+	// A second write grows the filter past its first size
+	storeNodes(t, ds, "LCD-1", "b", 1000)
+
+	for _, id := range []string{"a-0", "a-99", "b-0", "b-999"} {
+		if _, err := ds.GetNode("LCD-1", id); err != nil {
+			t.Errorf("GetNode(%s) failed: %v", id, err)
+		}
+	}
+
+	for i := 0; i < 1000; i++ {
+		if _, err := ds.GetNode("LCD-1", fmt.Sprintf("missing-%d", i)); !errors.Is(err, ErrNodeNotFound) {
+			t.Fatalf("Expected ErrNodeNotFound, got %v", err)
+		}
+	}
+	stats := ds.BloomStats()
+	if stats.Checks < 1000 || stats.Rejected < 950 {
+		t.Errorf("Expected the filter to answer most misses, got %+v", stats)
+	}
+
+	nodes, err := ds.GetNodes("LCD-1", []string{"missing-1", "a-5", "missing-2", "b-7"})
+	if err != nil {
+		t.Fatalf("GetNodes failed: %v", err)
+	}
+	if nodes[0] != nil || nodes[1] == nil || nodes[1].NodeID != "a-5" || nodes[2] != nil || nodes[3] == nil || nodes[3].NodeID != "b-7" {
+		t.Errorf("GetNodes results misaligned: %v", nodes)
+	}
+}
+
+func TestBloomFilterPersisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bloom.db")
+	kv := &storage.KV{Path: path}
+	if err := kv.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	ds := NewSimpleStore(kv)
+	ds.SetBloomFilters(true)
+	storeNodes(t, ds, "LCD-1", "n", 300)
+	if _, err := ds.CloneDocument("LCD-1", "LCD-2"); err != nil {
+		t.Fatalf("CloneDocument failed: %v", err)
+	}
+	kv.Close()
+
+	kv = &storage.KV{Path: path}
+	if err := kv.Open(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	defer kv.Close()
+	ds = NewSimpleStore(kv)
+	ds.SetBloomFilters(true)
+
+	for _, policyID := range []string{"LCD-1", "LCD-2"} {
+		f, err := loadBloom(kv, policyID)
+		if err != nil || f == nil || f.count != 300 {
+			t.Fatalf("Expected a persisted filter of 300 nodes for %s, got %+v (%v)", policyID, f, err)
+		}
+	}
+	if _, err := ds.GetNode("LCD-1", "n-123"); err != nil {
+		t.Errorf("GetNode failed after reopen: %v", err)
+	}
+	ds.GetNode("LCD-1", "absent")
+	if ds.BloomStats().Rejected != 1 {
+		t.Errorf("Expected the reloaded filter to answer the miss, got %+v", ds.BloomStats())
+	}
+}
+
+func TestBloomFilterDisabledAndReindex(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	ds.SetBloomFilters(true)
+	storeNodes(t, ds, "LCD-1", "a", 10)
+
+	// Nodes added with filters off drop the filter rather than leave it stale
+	ds.SetBloomFilters(false)
+	storeNodes(t, ds, "LCD-1", "b", 10)
+	if f, _ := loadBloom(kv, "LCD-1"); f != nil {
+		t.Fatal("Expected the filter deleted by a write with filters off")
+	}
+
+	ds.SetBloomFilters(true)
+	if _, err := ds.GetNode("LCD-1", "b-3"); err != nil {
+		t.Errorf("GetNode without a filter failed: %v", err)
+	}
+	if ds.BloomStats().Checks != 0 {
+		t.Errorf("Expected no filter consulted, got %+v", ds.BloomStats())
+	}
+
+	if _, err := ds.Reindex(""); err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	f, err := loadBloom(kv, "LCD-1")
+	if err != nil || f == nil || f.count != 20 {
+		t.Fatalf("Expected Reindex to rebuild the filter of 20 nodes, got %+v (%v)", f, err)
+	}
+	for _, id := range []string{"a-3", "b-3"} {
+		if _, err := ds.GetNode("LCD-1", id); err != nil {
+			t.Errorf("GetNode(%s) after Reindex failed: %v", id, err)
+		}
+	}
+	if _, err := ds.GetNode("LCD-1", "c-3"); !errors.Is(err, ErrNodeNotFound) || ds.BloomStats().Rejected != 1 {
+		t.Errorf("Expected the rebuilt filter to answer the miss, got %v, %+v", err, ds.BloomStats())
+	}
+}
@@ -0,0 +1,134 @@
+// ABOUTME: BM25 keyword ranking within a single policy
+// ABOUTME: Computes document frequencies over the policy's nodes at query time
+
+package document
+
+import (
+	"math"
+	"sort"
+
+	"github.com/nainya/treestore/pkg/storage"
+	"github.com/nainya/treestore/pkg/textindex"
+)
+
+// BM25 tuning parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// BM25Search ranks every node of a policy against the query using Okapi BM25
+// Title, summary and text form one bag of terms per node. Results are sorted
+// by score, highest first; limit <= 0 returns all matches.
+func (ss *SimpleStore) BM25Search(policyID, query string, limit int) ([]*SearchResult, error) {
+	terms := textindex.UniqueTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	type candidate struct {
+		node   *Node
+		length int
+		tf     map[string]int
+	}
+
+	var candidates []*candidate
+	df := make(map[string]int)
+	totalLength := 0
+	numNodes := 0
+
+	startKey := storage.EncodeKey(PREFIX_NODE, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+	})
+
+	var textErr error
+	err := storage.ScanPrefix(ss.kv, startKey, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 2 {
+			return true
+		}
+
+		node, separate, err := decodeStoredNode(val, nil)
+		if err != nil {
+			return true
+		}
+		if separate {
+			if textErr = ss.readTexts([]*Node{node}); textErr != nil {
+				return false
+			}
+		}
+
+		tokens := textindex.Tokenize(node.Title + " " + node.Summary + " " + node.Text)
+		numNodes++
+		totalLength += len(tokens)
+
+		var tf map[string]int
+		for _, token := range tokens {
+			for _, term := range terms {
+				if token == term {
+					if tf == nil {
+						tf = make(map[string]int)
+					}
+					tf[term]++
+				}
+			}
+		}
+		if tf == nil {
+			return true
+		}
+
+		for term := range tf {
+			df[term]++
+		}
+		candidates = append(candidates, &candidate{node: node, length: len(tokens), tf: tf})
+		return true
+	})
+	if err == nil {
+		err = textErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	avgLength := float64(totalLength) / float64(numNodes)
+	results := make([]*SearchResult, 0, len(candidates))
+	for _, c := range candidates {
+		score := 0.0
+		for term, freq := range c.tf {
+			idf := math.Log(1 + (float64(numNodes)-float64(df[term])+0.5)/(float64(df[term])+0.5))
+			norm := 1 - bm25B + bm25B*float64(c.length)/avgLength
+			score += idf * float64(freq) * (bm25K1 + 1) / (float64(freq) + bm25K1*norm)
+		}
+
+		snippet, _ := NodeSnippet(c.node, query, DefaultSnippetLength)
+		results = append(results, &SearchResult{
+			NodeID:   c.node.NodeID,
+			PolicyID: c.node.PolicyID,
+			Title:    c.node.Title,
+			Summary:  c.node.Summary,
+			Score:    score,
+			Snippet:  snippet,
+		})
+	}
+
+	sortResults(results)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// sortResults orders results by score, highest first, breaking ties by node ID
+func sortResults(results []*SearchResult) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].NodeID < results[j].NodeID
+	})
+}
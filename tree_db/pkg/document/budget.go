@@ -0,0 +1,102 @@
+// ABOUTME: Subtree reads that fit a token budget, for prompts built from a section and everything under it
+// ABOUTME: Depth is kept before detail: nodes fall back from their text to their summary before a level is dropped
+
+package document
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrBudgetTooSmall is returned when not even the root of a subtree fits the budget
+var ErrBudgetTooSmall = errors.New("document: token budget too small")
+
+// BudgetedSubtree is a subtree cut down to a token budget
+type BudgetedSubtree struct {
+	Nodes      []*Node  // Level by level, as GetSubtree returns them
+	Summarized []string // Nodes returned with their summary in place of their text
+	Depth      int      // Deepest level returned, 0 for the root alone
+	TokenCount int      // Estimated tokens of the titles, texts and summaries returned
+	Truncated  bool     // Deeper levels were left out, or text was replaced by summaries
+}
+
+// GetSubtreeWithinBudget returns the deepest subtree under a node whose
+// titles and summaries fit in maxTokens, then spends what is left on full
+// text, shallowest nodes first. A node returned with its text has its summary
+// cleared; one returned with its summary has its text cleared and is listed
+// in Summarized, with its title alone when it has no summary. Text is
+// counted by the node's TokenCount, or estimated when it was never counted.
+func (ss *SimpleStore) GetSubtreeWithinBudget(policyID, nodeID string, maxTokens int, opts QueryOptions) (*BudgetedSubtree, error) {
+	estimate := ss.estimator()
+	brief := func(node *Node) int { return estimate(node.Title) + estimate(node.Summary) }
+	full := func(node *Node) int {
+		if node.TokenCount > 0 {
+			return estimate(node.Title) + node.TokenCount
+		}
+		return estimate(node.Title) + estimate(node.Text)
+	}
+	// A node costs at least its cheaper form; nodes without text have only their summary
+	cheapest := func(node *Node) int {
+		if node.Text == "" || brief(node) < full(node) {
+			return brief(node)
+		}
+		return full(node)
+	}
+
+	root, err := ss.GetNode(policyID, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if cheapest(root) > maxTokens {
+		return nil, fmt.Errorf("%w: %s/%s needs %d tokens, over %d", ErrBudgetTooSmall, policyID, nodeID, cheapest(root), maxTokens)
+	}
+
+	// Levels are read until one no longer fits in its cheaper form
+	result := &BudgetedSubtree{Nodes: []*Node{root}}
+	used := cheapest(root)
+	level := []*Node{root}
+	for len(level) > 0 && (opts.MaxDepth == 0 || result.Depth < opts.MaxDepth) {
+		children, err := ss.fetchChildren(policyID, level, opts.Workers)
+		if err != nil {
+			return nil, err
+		}
+		var next []*Node
+		cost := 0
+		for _, c := range children {
+			for _, child := range c {
+				next = append(next, child)
+				cost += cheapest(child)
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		if used+cost > maxTokens {
+			result.Truncated = true
+			break
+		}
+		result.Nodes = append(result.Nodes, next...)
+		used += cost
+		result.Depth++
+		level = next
+	}
+
+	// Nodes whose summary was cheaper get their text back while it fits
+	for _, node := range result.Nodes {
+		if node.Text == "" {
+			continue
+		}
+		if extra := full(node) - cheapest(node); extra > 0 {
+			if used+extra > maxTokens {
+				result.Summarized = append(result.Summarized, node.NodeID)
+				node.Text = ""
+				result.Truncated = true
+				continue
+			}
+			used += extra
+		}
+		node.Summary = ""
+	}
+	result.TokenCount = used
+	return result, nil
+}
@@ -0,0 +1,67 @@
+// ABOUTME: Tests for subtree reads within a token budget
+// ABOUTME: Verifies levels are dropped only after text falls back to summaries, and token counts come from the store's estimator
+
+package document
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetSubtreeWithinBudget(t *testing.T) {
+	ds, kv, _ := setupTestStore(t)
+	defer kv.Close()
+	ds.SetTokenEstimator(func(text string) int { return len(strings.Fields(text)) })
+
+	root, a := "root", "a"
+	long := strings.Repeat("word ", 20)
+	nodes := []*Node{
+		{NodeID: root, PolicyID: "LCD-1", Title: "Policy", Summary: "all of it", Text: long},
+		{NodeID: a, PolicyID: "LCD-1", ParentID: &root, Title: "Scope", Summary: "who", Text: long},
+		{NodeID: "b", PolicyID: "LCD-1", ParentID: &root, Title: "Codes", Text: "two words"},
+		{NodeID: "a1", PolicyID: "LCD-1", ParentID: &a, Title: "Limits", Summary: "how often", Text: long},
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	if nodes[0].TokenCount != 20 {
+		t.Errorf("Expected the store's estimator to count 20 tokens, got %d", nodes[0].TokenCount)
+	}
+
+	ids := func(nodes []*Node) []string {
+		var out []string
+		for _, node := range nodes {
+			out = append(out, node.NodeID)
+		}
+		return out
+	}
+
+	// Everything fits with its text
+	sub, err := ds.GetSubtreeWithinBudget("LCD-1", root, 1000, QueryOptions{})
+	if err != nil || sub.Depth != 2 || sub.Truncated || len(sub.Summarized) != 0 || sub.TokenCount != 4*1+20*3+2 {
+		t.Fatalf("Expected the whole subtree with text, got %+v (%v)", sub, err)
+	}
+
+	// Summaries keep every level; leftover tokens go to the shallowest text
+	sub, err = ds.GetSubtreeWithinBudget("LCD-1", root, 35, QueryOptions{})
+	if err != nil || sub.Depth != 2 || !sub.Truncated {
+		t.Fatalf("Expected every level with summaries, got %+v (%v)", sub, err)
+	}
+	if !reflect.DeepEqual(sub.Summarized, []string{"a", "a1"}) || sub.Nodes[0].Text != long || sub.Nodes[0].Summary != "" {
+		t.Errorf("Expected the root's text and summaries below, got %v", sub.Summarized)
+	}
+	if sub.Nodes[1].Text != "" || sub.Nodes[1].Summary != "who" || sub.TokenCount > 35 {
+		t.Errorf("Expected a's summary in place of its text, got %+v", sub.Nodes[1])
+	}
+
+	// Levels are dropped once even summaries do not fit
+	sub, err = ds.GetSubtreeWithinBudget("LCD-1", root, 9, QueryOptions{})
+	if err != nil || sub.Depth != 1 || !reflect.DeepEqual(ids(sub.Nodes), []string{"root", "a", "b"}) {
+		t.Errorf("Expected two levels, got %+v (%v)", sub, err)
+	}
+	if _, err := ds.GetSubtreeWithinBudget("LCD-1", root, 3, QueryOptions{}); !errors.Is(err, ErrBudgetTooSmall) {
+		t.Errorf("Expected ErrBudgetTooSmall, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+// ABOUTME: Content checksums of nodes and an index of them, for finding duplicated sections
+// ABOUTME: Sections copied within or between policies share a checksum, a frequent source of contradictions
+
+package document
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// PREFIX_CHECKSUM indexes nodes by content as (checksum, policyID, nodeID) -> empty
+const PREFIX_CHECKSUM = uint32(2200)
+
+// NodeRef names a node in a policy
+type NodeRef struct {
+	PolicyID string
+	NodeID   string
+}
+
+// DuplicateGroup is a set of nodes whose text is the same
+type DuplicateGroup struct {
+	Checksum string
+	Nodes    []NodeRef // Sorted by policy, then node ID
+}
+
+// ContentChecksum returns the checksum stored for a node's text: the hex
+// SHA-256 of the text with runs of whitespace collapsed, so copies that
+// differ only in line breaks or spacing match. Text that is empty or all
+// whitespace has no checksum.
+func ContentChecksum(text string) string {
+	normalized := strings.Join(strings.Fields(text), " ")
+	if normalized == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// checksumKey returns the checksum index key of a node
+func checksumKey(checksum, policyID, nodeID string) []byte {
+	return storage.EncodeKey(PREFIX_CHECKSUM, []storage.Value{
+		storage.NewBytesValue([]byte(checksum)),
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(nodeID)),
+	})
+}
+
+// writeChecksum sets node.Checksum from its text and moves its index entry
+// from old's checksum. It runs before the node record is encoded, and old
+// may be node itself.
+func writeChecksum(tx storage.Txn, old, node *Node) {
+	checksum := ContentChecksum(node.Text)
+	if old != nil && old.Checksum != "" && old.Checksum != checksum {
+		tx.Del(checksumKey(old.Checksum, old.PolicyID, old.NodeID))
+	}
+	node.Checksum = checksum
+	if checksum != "" {
+		tx.Set(checksumKey(checksum, node.PolicyID, node.NodeID), []byte{})
+	}
+}
+
+// FindDuplicateNodes returns the groups of nodes of a policy that share their
+// text, ordered by checksum. Nodes stored before checksums are not compared
+// until they are written again or upgraded with UpgradeNodes.
+func (ss *SimpleStore) FindDuplicateNodes(policyID string) ([]DuplicateGroup, error) {
+	nodes, err := ss.scanPolicyNodes(policyID)
+	if err != nil {
+		return nil, err
+	}
+	byChecksum := make(map[string][]NodeRef)
+	for _, node := range nodes {
+		if node.Checksum != "" {
+			byChecksum[node.Checksum] = append(byChecksum[node.Checksum], NodeRef{PolicyID: policyID, NodeID: node.NodeID})
+		}
+	}
+
+	var groups []DuplicateGroup
+	for checksum, refs := range byChecksum {
+		if len(refs) > 1 {
+			sortRefs(refs)
+			groups = append(groups, DuplicateGroup{Checksum: checksum, Nodes: refs})
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Checksum < groups[j].Checksum })
+	return groups, nil
+}
+
+// FindCrossPolicyDuplicates returns the groups of nodes sharing their text
+// that span more than one policy, ordered by checksum. With a policy ID, only
+// groups holding one of its nodes are returned; with none, every such group in
+// the store is.
+func (ss *SimpleStore) FindCrossPolicyDuplicates(policyID string) ([]DuplicateGroup, error) {
+	var checksums []string
+	if policyID != "" {
+		nodes, err := ss.scanPolicyNodes(policyID)
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		for _, node := range nodes {
+			if node.Checksum != "" && !seen[node.Checksum] {
+				seen[node.Checksum] = true
+				checksums = append(checksums, node.Checksum)
+			}
+		}
+		if len(checksums) == 0 {
+			return nil, nil
+		}
+		sort.Strings(checksums)
+	} else {
+		checksums = []string{""} // An empty prefix scans the whole index
+	}
+
+	var groups []DuplicateGroup
+	var current DuplicateGroup
+	flush := func() {
+		if spansPolicies(current.Nodes) {
+			groups = append(groups, current)
+		}
+		current = DuplicateGroup{}
+	}
+	for _, checksum := range checksums {
+		var scope []storage.Value
+		if checksum != "" {
+			scope = []storage.Value{storage.NewBytesValue([]byte(checksum))}
+		}
+		err := storage.ScanPrefix(ss.kv, storage.EncodeKey(PREFIX_CHECKSUM, scope), func(key, val []byte) bool {
+			vals, err := storage.ExtractValues(key)
+			if err != nil || len(vals) < 3 {
+				return true
+			}
+			if string(vals[0].Str) != current.Checksum {
+				flush()
+				current.Checksum = string(vals[0].Str)
+			}
+			current.Nodes = append(current.Nodes, NodeRef{PolicyID: string(vals[1].Str), NodeID: string(vals[2].Str)})
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	flush()
+	return groups, nil
+}
+
+// spansPolicies reports whether refs name nodes of more than one policy
+func spansPolicies(refs []NodeRef) bool {
+	for _, ref := range refs {
+		if ref.PolicyID != refs[0].PolicyID {
+			return true
+		}
+	}
+	return false
+}
+
+// sortRefs sorts node references by policy, then node ID
+func sortRefs(refs []NodeRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].PolicyID != refs[j].PolicyID {
+			return refs[i].PolicyID < refs[j].PolicyID
+		}
+		return refs[i].NodeID < refs[j].NodeID
+	})
+}
@@ -0,0 +1,68 @@
+// ABOUTME: Tests for node content checksums and duplicate detection
+// ABOUTME: Verifies checksums ignore whitespace, follow text changes, and group copies within and across policies
+
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindDuplicateNodes(t *testing.T) {
+	ds, kv, _ := setupTestStore(t)
+	defer kv.Close()
+
+	copied := "Prior authorization is required for all imaging."
+	root := "root"
+	store := func(policyID string, texts map[string]string) {
+		nodes := []*Node{{NodeID: root, PolicyID: policyID}}
+		for nodeID, text := range texts {
+			nodes = append(nodes, &Node{NodeID: nodeID, PolicyID: policyID, ParentID: &root, Text: text})
+		}
+		if err := ds.StoreDocument(&Document{PolicyID: policyID}, nodes); err != nil {
+			t.Fatalf("StoreDocument failed: %v", err)
+		}
+	}
+	store("LCD-1", map[string]string{"a": copied, "b": "Prior authorization\n  is required for all imaging.", "c": "Unique"})
+	store("LCD-2", map[string]string{"x": copied, "y": "Unique elsewhere"})
+
+	if ContentChecksum(" \n") != "" || ContentChecksum("a  b") != ContentChecksum("a\nb") {
+		t.Error("Expected checksums to ignore whitespace")
+	}
+
+	groups, err := ds.FindDuplicateNodes("LCD-1")
+	if err != nil {
+		t.Fatalf("FindDuplicateNodes failed: %v", err)
+	}
+	want := []NodeRef{{"LCD-1", "a"}, {"LCD-1", "b"}}
+	if len(groups) != 1 || groups[0].Checksum != ContentChecksum(copied) || !reflect.DeepEqual(groups[0].Nodes, want) {
+		t.Fatalf("Expected a and b grouped, got %+v", groups)
+	}
+
+	want = []NodeRef{{"LCD-1", "a"}, {"LCD-1", "b"}, {"LCD-2", "x"}}
+	for _, policyID := range []string{"LCD-2", ""} {
+		groups, err := ds.FindCrossPolicyDuplicates(policyID)
+		if err != nil || len(groups) != 1 || !reflect.DeepEqual(groups[0].Nodes, want) {
+			t.Errorf("Expected the copies in both policies for %q, got %+v (%v)", policyID, groups, err)
+		}
+	}
+
+	// Rewriting a copy moves it out of its group
+	node, err := ds.GetNode("LCD-2", "x")
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	node.Text = "Reworded"
+	if err := ds.UpdateNode(node); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if node.Checksum != ContentChecksum("Reworded") {
+		t.Errorf("Expected the caller's node to get the new checksum, got %q", node.Checksum)
+	}
+	if groups, _ := ds.FindCrossPolicyDuplicates(""); len(groups) != 0 {
+		t.Errorf("Expected no cross-policy copies left, got %+v", groups)
+	}
+	if groups, _ := ds.FindDuplicateNodes("LCD-3"); len(groups) != 0 {
+		t.Errorf("Expected nothing for an unknown policy, got %+v", groups)
+	}
+}
@@ -0,0 +1,131 @@
+// ABOUTME: Deep copy of a policy's node tree into another policy
+// ABOUTME: Regenerates node IDs while preserving structure, section paths and embeddings
+
+package document
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+var (
+	// ErrDocumentNotFound is returned when a policy has no nodes
+	ErrDocumentNotFound = errors.New("document: document not found")
+
+	// ErrDocumentExists is returned when cloning into a policy that already has nodes
+	ErrDocumentExists = errors.New("document: destination policy already has a document")
+)
+
+// CloneResult describes a cloned document
+type CloneResult struct {
+	RootNodeIDs []string          // New IDs of the source's root nodes
+	NodeIDs     map[string]string // New node ID by source node ID
+}
+
+// CloneDocument copies every node reachable from the roots of srcPolicyID into
+// dstPolicyID under newly generated IDs. Parent links, section paths, depths,
+// pages and embeddings are kept; versions restart at 1. The destination must
+// not have any nodes yet.
+func (ss *SimpleStore) CloneDocument(srcPolicyID, dstPolicyID string) (*CloneResult, error) {
+	if srcPolicyID == dstPolicyID {
+		return nil, fmt.Errorf("%w: cannot clone %s onto itself", ErrDocumentExists, srcPolicyID)
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	exists, err := ss.hasNodes(dstPolicyID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: %s", ErrDocumentExists, dstPolicyID)
+	}
+
+	roots, err := ss.GetChildren(srcPolicyID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDocumentNotFound, srcPolicyID)
+	}
+
+	result := &CloneResult{NodeIDs: make(map[string]string)}
+	var created []string
+	now := time.Now()
+	tx := ss.kv.Begin()
+	defer tx.Abort()
+
+	// Parents are visited before their children, so their new IDs are known
+	level := roots
+	for len(level) > 0 {
+		var next []*Node
+		for _, src := range level {
+			if _, seen := result.NodeIDs[src.NodeID]; seen {
+				continue // Guards against a cycle in the children index
+			}
+			clone := *src
+			clone.PolicyID = dstPolicyID
+			clone.NodeID = newNodeID()
+			clone.ChildIDs = nil
+			clone.Version = 1
+			clone.CreatedAt, clone.UpdatedAt = now, now
+			if src.ParentID != nil {
+				parentID := result.NodeIDs[*src.ParentID]
+				clone.ParentID = &parentID
+			} else {
+				result.RootNodeIDs = append(result.RootNodeIDs, clone.NodeID)
+			}
+			result.NodeIDs[src.NodeID] = clone.NodeID
+			created = append(created, clone.NodeID)
+
+			putNode(tx, nil, &clone)
+			setSummaryChecksum(tx, &clone, src.SummaryChecksum)
+			if vector, ok := tx.Get(embeddingKey(srcPolicyID, src.NodeID)); ok {
+				tx.Set(embeddingKey(dstPolicyID, clone.NodeID), append([]byte(nil), vector...))
+			}
+
+			children, err := ss.GetChildren(srcPolicyID, &src.NodeID)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, children...)
+		}
+		level = next
+	}
+
+	if err := ss.commitCreated(tx, map[string][]string{dstPolicyID: created}); err != nil {
+		return nil, err
+	}
+
+	ss.feed.Publish(changefeed.EntityDocument, changefeed.OpPut, dstPolicyID, "")
+	return result, nil
+}
+
+// hasNodes reports whether a policy has at least one stored node
+func (ss *SimpleStore) hasNodes(policyID string) (bool, error) {
+	start := storage.EncodeKey(PREFIX_NODE, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+	})
+	found := false
+	err := storage.ScanPrefix(ss.kv, start, func(key, val []byte) bool {
+		found = true
+		return false
+	})
+	return found, err
+}
+
+// newNodeID returns a random node ID
+func newNodeID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("document: read random node ID: %v", err))
+	}
+	return "node-" + hex.EncodeToString(b[:])
+}
@@ -0,0 +1,82 @@
+// ABOUTME: Tests for cloning a document into another policy
+// ABOUTME: Verifies new IDs, preserved structure and embeddings, and destination checks
+
+package document
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestCloneDocument(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	root, sec1, sec2 := "root", "sec1", "sec2"
+	nodes := []*Node{
+		{NodeID: root, PolicyID: "LCD-1", Title: "Coverage", SectionPath: "1", PageStart: 1, PageEnd: 9},
+		{NodeID: sec1, PolicyID: "LCD-1", ParentID: &root, Title: "Indications", SectionPath: "1.1", Depth: 1, Text: "Covered when medically necessary"},
+		{NodeID: sec2, PolicyID: "LCD-1", ParentID: &root, Title: "Limitations", SectionPath: "1.2", Depth: 1},
+		{NodeID: "sec2a", PolicyID: "LCD-1", ParentID: &sec2, Title: "Frequency", SectionPath: "1.2.1", Depth: 2},
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	if err := ds.SetEmbedding("LCD-1", sec1, []float32{1, 0, 0}); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+
+	result, err := ds.CloneDocument("LCD-1", "LCD-2")
+	if err != nil {
+		t.Fatalf("CloneDocument failed: %v", err)
+	}
+	if len(result.NodeIDs) != 4 || len(result.RootNodeIDs) != 1 || result.RootNodeIDs[0] != result.NodeIDs[root] {
+		t.Fatalf("Unexpected clone result: %+v", result)
+	}
+
+	for _, src := range nodes {
+		newID := result.NodeIDs[src.NodeID]
+		if newID == "" || newID == src.NodeID {
+			t.Fatalf("%s: expected a new node ID, got %q", src.NodeID, newID)
+		}
+		clone, err := ds.GetNode("LCD-2", newID)
+		if err != nil {
+			t.Fatalf("GetNode(%s) failed: %v", newID, err)
+		}
+		if clone.Title != src.Title || clone.SectionPath != src.SectionPath || clone.Depth != src.Depth || clone.Text != src.Text || clone.Version != 1 {
+			t.Errorf("%s: clone does not match the source: %+v", src.NodeID, clone)
+		}
+		if src.ParentID == nil {
+			if clone.ParentID != nil {
+				t.Errorf("%s: expected a root, got parent %s", src.NodeID, *clone.ParentID)
+			}
+		} else if clone.ParentID == nil || *clone.ParentID != result.NodeIDs[*src.ParentID] {
+			t.Errorf("%s: parent was not remapped: %v", src.NodeID, clone.ParentID)
+		}
+	}
+
+	subtree, err := ds.GetSubtree("LCD-2", result.RootNodeIDs[0], QueryOptions{})
+	if err != nil || len(subtree) != 4 {
+		t.Errorf("Expected the cloned tree to have 4 nodes, got %d (%v)", len(subtree), err)
+	}
+	if vector, err := ds.GetEmbedding("LCD-2", result.NodeIDs[sec1]); err != nil || len(vector) != 3 || vector[0] != 1 {
+		t.Errorf("Expected the embedding to be cloned, got %v (%v)", vector, err)
+	}
+	hits, _ := ds.Search("LCD-2", "medically", 10)
+	if len(hits) != 1 || hits[0].NodeID != result.NodeIDs[sec1] {
+		t.Errorf("Expected the clone to be searchable, got %+v", hits)
+	}
+
+	// The source is untouched and the destination cannot be cloned into again
+	if original, err := ds.GetNode("LCD-1", sec1); err != nil || original.Version != 1 {
+		t.Errorf("Source node changed: %+v (%v)", original, err)
+	}
+	if _, err := ds.CloneDocument("LCD-1", "LCD-2"); !errors.Is(err, ErrDocumentExists) {
+		t.Errorf("Expected ErrDocumentExists, got %v", err)
+	}
+	if _, err := ds.CloneDocument("LCD-404", "LCD-3"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Expected ErrDocumentNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+// ABOUTME: Context window assembly for LLM prompts around a single node
+// ABOUTME: Combines node text with ancestor, sibling and child outlines under a token budget
+
+package document
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TokenEstimator estimates how many LLM tokens a piece of text uses
+type TokenEstimator func(text string) int
+
+// EstimateTokens is the default estimator: roughly four bytes per token
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// EstimateWordTokens estimates four tokens per three words, which tracks
+// common tokenizers more closely for prose with long words or many numbers
+func EstimateWordTokens(text string) int {
+	return (len(strings.Fields(text))*4 + 2) / 3
+}
+
+// TokenEstimators are the built-in estimators by name
+var TokenEstimators = map[string]TokenEstimator{
+	"bytes": EstimateTokens,
+	"words": EstimateWordTokens,
+}
+
+// ContextOptions configures GetContextWindow
+type ContextOptions struct {
+	TokenBudget int            // Maximum tokens to return (0 for unlimited)
+	Estimator   TokenEstimator // Token estimator (nil for the store's)
+}
+
+// ContextEntry is the outline of a related node: its title and summary
+type ContextEntry struct {
+	NodeID  string
+	Title   string
+	Summary string // Empty for ancestors, which only contribute titles
+}
+
+// ContextWindow is a node with the surrounding structure an LLM needs to read it
+type ContextWindow struct {
+	Node       *Node          // Target node; Text may be trimmed to fit the budget
+	Ancestors  []ContextEntry // From root to parent
+	Siblings   []ContextEntry // Other children of the same parent
+	Children   []ContextEntry // Direct children
+	TokenCount int            // Estimated tokens used by the window
+	Truncated  bool           // Whether anything was dropped or trimmed
+}
+
+// GetContextWindow assembles a node with its ancestor titles, children and sibling summaries
+// Content is admitted in priority order - ancestor titles, the node title, the node
+// text, children summaries, then sibling summaries - until the token budget runs out.
+func (ss *SimpleStore) GetContextWindow(policyID, nodeID string, opts ContextOptions) (*ContextWindow, error) {
+	estimate := opts.Estimator
+	if estimate == nil {
+		estimate = ss.estimator()
+	}
+
+	path, err := ss.GetAncestorPath(policyID, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	node := path[len(path)-1]
+
+	children, err := ss.GetChildren(policyID, &node.NodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	siblings, err := ss.GetChildren(policyID, node.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	window := &ContextWindow{}
+	used := 0
+	fits := func(tokens int) bool {
+		if opts.TokenBudget > 0 && used+tokens > opts.TokenBudget {
+			window.Truncated = true
+			return false
+		}
+		used += tokens
+		return true
+	}
+
+	for _, ancestor := range path[:len(path)-1] {
+		if fits(estimate(ancestor.Title)) {
+			window.Ancestors = append(window.Ancestors, ContextEntry{NodeID: ancestor.NodeID, Title: ancestor.Title})
+		}
+	}
+
+	target := *node
+	target.Text = ""
+	if fits(estimate(node.Title)) {
+		text := node.Text
+		if opts.TokenBudget > 0 && used+estimate(text) > opts.TokenBudget {
+			text = trimToBudget(text, opts.TokenBudget-used, estimate)
+			window.Truncated = true
+		}
+		used += estimate(text)
+		target.Text = text
+	}
+	window.Node = &target
+
+	for _, child := range children {
+		if fits(estimate(child.Title) + estimate(child.Summary)) {
+			window.Children = append(window.Children, entryFor(child))
+		}
+	}
+
+	for _, sibling := range siblings {
+		if sibling.NodeID == node.NodeID {
+			continue
+		}
+		if fits(estimate(sibling.Title) + estimate(sibling.Summary)) {
+			window.Siblings = append(window.Siblings, entryFor(sibling))
+		}
+	}
+
+	window.TokenCount = used
+	return window, nil
+}
+
+// entryFor builds the outline entry of a node
+func entryFor(node *Node) ContextEntry {
+	return ContextEntry{NodeID: node.NodeID, Title: node.Title, Summary: node.Summary}
+}
+
+// trimToBudget returns the longest prefix of text that fits within tokens
+func trimToBudget(text string, tokens int, estimate TokenEstimator) string {
+	if tokens <= 0 {
+		return ""
+	}
+
+	// Binary search on byte length, then back off to a rune boundary
+	lo, hi := 0, len(text)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if estimate(text[:mid]) <= tokens {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	for lo > 0 && lo < len(text) && !utf8.RuneStart(text[lo]) {
+		lo--
+	}
+
+	return text[:lo]
+}
@@ -0,0 +1,117 @@
+// ABOUTME: Tests for LLM context window assembly
+// ABOUTME: Verifies structure, budget trimming and pluggable token estimation
+
+package document
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func storeContextFixture(t *testing.T, ds *SimpleStore) {
+	now := time.Now()
+	root, sec1 := "root", "sec1"
+	nodes := []*Node{
+		{NodeID: "root", Title: "Imaging Policy", Summary: "Root summary"},
+		{NodeID: "sec1", ParentID: &root, Title: "Criteria", Summary: "Coverage criteria", Depth: 1},
+		{NodeID: "sec2", ParentID: &root, Title: "Exclusions", Summary: "What is not covered", Depth: 1},
+		{NodeID: "sec1.1", ParentID: &sec1, Title: "CT", Summary: "CT scan criteria", Text: strings.Repeat("ct criteria text ", 50), Depth: 2},
+		{NodeID: "sec1.2", ParentID: &sec1, Title: "MRI", Summary: "MRI criteria", Depth: 2},
+		{NodeID: "sec1.1.1", ParentID: strPtr("sec1.1"), Title: "Contrast", Summary: "Contrast agents", Depth: 3},
+	}
+	for _, n := range nodes {
+		n.PolicyID = "policy1"
+		n.CreatedAt = now
+		n.UpdatedAt = now
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "policy1"}, nodes); err != nil {
+		t.Fatalf("Failed to store: %v", err)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestGetContextWindow(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	storeContextFixture(t, ds)
+
+	window, err := ds.GetContextWindow("policy1", "sec1.1", ContextOptions{})
+	if err != nil {
+		t.Fatalf("GetContextWindow failed: %v", err)
+	}
+
+	if window.Truncated {
+		t.Error("Expected no truncation without a budget")
+	}
+	if window.Node.Text != strings.Repeat("ct criteria text ", 50) {
+		t.Error("Expected full node text")
+	}
+	if len(window.Ancestors) != 2 || window.Ancestors[0].NodeID != "root" || window.Ancestors[1].NodeID != "sec1" {
+		t.Errorf("Expected ancestors root, sec1; got %v", window.Ancestors)
+	}
+	if len(window.Siblings) != 1 || window.Siblings[0].NodeID != "sec1.2" {
+		t.Errorf("Expected sibling sec1.2, got %v", window.Siblings)
+	}
+	if len(window.Children) != 1 || window.Children[0].Summary != "Contrast agents" {
+		t.Errorf("Expected child summary, got %v", window.Children)
+	}
+	if window.TokenCount <= 0 {
+		t.Error("Expected positive token count")
+	}
+}
+
+func TestGetContextWindowBudget(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	storeContextFixture(t, ds)
+
+	window, err := ds.GetContextWindow("policy1", "sec1.1", ContextOptions{TokenBudget: 50})
+	if err != nil {
+		t.Fatalf("GetContextWindow failed: %v", err)
+	}
+
+	if !window.Truncated {
+		t.Error("Expected truncation under a small budget")
+	}
+	if window.TokenCount > 50 {
+		t.Errorf("Token count %d exceeds budget", window.TokenCount)
+	}
+	if len(window.Ancestors) != 2 {
+		t.Error("Expected ancestor titles to be kept first")
+	}
+	if window.Node.Text == "" || len(window.Node.Text) >= len(strings.Repeat("ct criteria text ", 50)) {
+		t.Errorf("Expected trimmed node text, got %d bytes", len(window.Node.Text))
+	}
+
+	// A word-counting estimator changes how much text fits
+	words := func(text string) int { return len(strings.Fields(text)) }
+	window, err = ds.GetContextWindow("policy1", "sec1.1", ContextOptions{TokenBudget: 20, Estimator: words})
+	if err != nil {
+		t.Fatalf("GetContextWindow failed: %v", err)
+	}
+	if window.TokenCount > 20 {
+		t.Errorf("Token count %d exceeds budget", window.TokenCount)
+	}
+	if n := len(strings.Fields(window.Node.Text)); n == 0 || n > 20 {
+		t.Errorf("Expected a few words of node text, got %d", n)
+	}
+}
+
+func TestGetContextWindowMissingNode(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	if _, err := ds.GetContextWindow("policy1", "nope", ContextOptions{}); err == nil {
+		t.Error("Expected error for missing node")
+	}
+}
@@ -0,0 +1,152 @@
+// ABOUTME: Node embedding storage and brute-force vector similarity search
+// ABOUTME: Vectors are stored per (policyID, nodeID) as raw packed float32 values
+
+package document
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// PREFIX_EMBEDDING keys node vectors as (policyID, nodeID) -> packed float32s
+const PREFIX_EMBEDDING = uint32(5200)
+
+// SetEmbedding stores the embedding vector of a node, replacing any previous one
+func (ss *SimpleStore) SetEmbedding(policyID, nodeID string, vector []float32) error {
+	if len(vector) == 0 {
+		return fmt.Errorf("embedding vector is empty")
+	}
+
+	// Packed floats are stored raw: the value encoding does not escape 0xFE bytes
+	tx := ss.kv.Begin()
+	tx.Set(embeddingKey(policyID, nodeID), packVector(vector))
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	ss.feed.Publish(changefeed.EntityEmbedding, changefeed.OpPut, policyID, nodeID)
+	return nil
+}
+
+// GetEmbedding retrieves the embedding vector of a node
+func (ss *SimpleStore) GetEmbedding(policyID, nodeID string) ([]float32, error) {
+	val, ok, err := ss.kv.Lookup(embeddingKey(policyID, nodeID))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("embedding not found: %s/%s", policyID, nodeID)
+	}
+
+	return unpackVector(val)
+}
+
+// VectorSearch ranks the nodes of a policy by cosine similarity to the vector
+// Nodes whose embedding has a different dimension are skipped. Results are
+// sorted by similarity, highest first; limit <= 0 returns all nodes.
+func (ss *SimpleStore) VectorSearch(policyID string, vector []float32, limit int) ([]*SearchResult, error) {
+	if len(vector) == 0 {
+		return nil, fmt.Errorf("query vector is empty")
+	}
+
+	startKey := storage.EncodeKey(PREFIX_EMBEDDING, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+	})
+
+	scores := make(map[string]float64)
+	var nodeIDs []string
+
+	err := storage.ScanPrefix(ss.kv, startKey, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 2 {
+			return true
+		}
+
+		candidate, err := unpackVector(val)
+		if err != nil || len(candidate) != len(vector) {
+			return true
+		}
+
+		nodeID := string(vals[1].Str)
+		scores[nodeID] = cosineSimilarity(vector, candidate)
+		nodeIDs = append(nodeIDs, nodeID)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := ss.GetNodes(policyID, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*SearchResult, 0, len(nodes))
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		results = append(results, &SearchResult{
+			NodeID:   node.NodeID,
+			PolicyID: node.PolicyID,
+			Title:    node.Title,
+			Summary:  node.Summary,
+			Score:    scores[node.NodeID],
+		})
+	}
+
+	sortResults(results)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// embeddingKey builds the key of a node's embedding
+func embeddingKey(policyID, nodeID string) []byte {
+	return storage.EncodeKey(PREFIX_EMBEDDING, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(nodeID)),
+	})
+}
+
+// packVector encodes a vector as little-endian float32 values
+func packVector(vector []float32) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// unpackVector decodes a vector written by packVector
+func unpackVector(data []byte) ([]float32, error) {
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("invalid embedding length: %d", len(data))
+	}
+
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector, nil
+}
+
+// cosineSimilarity returns the cosine of the angle between two equal-length vectors
+func cosineSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
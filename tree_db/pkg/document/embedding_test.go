@@ -0,0 +1,123 @@
+// ABOUTME: Tests for node embeddings and ranking helpers
+// ABOUTME: Verifies vector round-trips, cosine ranking and BM25 scoring
+
+package document
+
+import (
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func storeRankingFixture(t *testing.T, ds *SimpleStore) {
+	now := time.Now()
+	nodes := []*Node{
+		{NodeID: "ct", Title: "CT scans", Text: "CT scans require prior authorization for outpatient imaging"},
+		{NodeID: "mri", Title: "MRI", Text: "MRI requires prior authorization"},
+		{NodeID: "labs", Title: "Laboratory", Text: "Routine labs are covered without review"},
+	}
+	for _, n := range nodes {
+		n.PolicyID = "policy1"
+		n.CreatedAt = now
+		n.UpdatedAt = now
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "policy1"}, nodes); err != nil {
+		t.Fatalf("Failed to store: %v", err)
+	}
+}
+
+func TestEmbeddingRoundTrip(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	// Values chosen so the packed bytes contain 0x00, 0xFE and 0xFF
+	vector := []float32{0, -1.5, float32(math.Inf(1)), 1e-38, 3.14159}
+	if err := ds.SetEmbedding("policy1", "node1", vector); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+
+	got, err := ds.GetEmbedding("policy1", "node1")
+	if err != nil {
+		t.Fatalf("GetEmbedding failed: %v", err)
+	}
+	if len(got) != len(vector) {
+		t.Fatalf("Expected %d dimensions, got %d", len(vector), len(got))
+	}
+	for i := range vector {
+		if got[i] != vector[i] {
+			t.Errorf("Dimension %d: expected %v, got %v", i, vector[i], got[i])
+		}
+	}
+
+	if _, err := ds.GetEmbedding("policy1", "missing"); err == nil {
+		t.Error("Expected error for missing embedding")
+	}
+	if err := ds.SetEmbedding("policy1", "node1", nil); err == nil {
+		t.Error("Expected error for empty vector")
+	}
+}
+
+func TestVectorSearch(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	storeRankingFixture(t, ds)
+
+	embeddings := map[string][]float32{
+		"ct":   {1, 0, 0},
+		"mri":  {0.7, 0.7, 0},
+		"labs": {0, 0, 1},
+	}
+	for nodeID, v := range embeddings {
+		if err := ds.SetEmbedding("policy1", nodeID, v); err != nil {
+			t.Fatalf("SetEmbedding failed: %v", err)
+		}
+	}
+	// Wrong dimension is ignored
+	ds.SetEmbedding("policy1", "odd", []float32{1, 0})
+
+	results, err := ds.VectorSearch("policy1", []float32{1, 0.1, 0}, 2)
+	if err != nil {
+		t.Fatalf("VectorSearch failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].NodeID != "ct" || results[1].NodeID != "mri" {
+		t.Errorf("Expected ct, mri; got %s, %s", results[0].NodeID, results[1].NodeID)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Error("Expected descending scores")
+	}
+}
+
+func TestBM25Search(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	storeRankingFixture(t, ds)
+
+	results, err := ds.BM25Search("policy1", "CT authorization", 10)
+	if err != nil {
+		t.Fatalf("BM25Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(results))
+	}
+	// "ct" is rarer than "authorization", so the CT node ranks first
+	if results[0].NodeID != "ct" {
+		t.Errorf("Expected ct first, got %s", results[0].NodeID)
+	}
+
+	results, err = ds.BM25Search("policy1", "dental", 10)
+	if err != nil {
+		t.Fatalf("BM25Search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no matches, got %d", len(results))
+	}
+}
@@ -0,0 +1,171 @@
+// ABOUTME: Encoding of node records, and the reader of nodes stored before records
+// ABOUTME: Nodes are tag-length-value records; older ones are positional tuples of values, upgraded when rewritten
+
+package document
+
+import (
+	"fmt"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// nodeSchema is the schema version of the records encodeNode writes. Fields
+// are only ever added, under new tags, so each schema reads the others'
+// records: a reader defaults the fields a record lacks and skips those it
+// does not know.
+//
+//	1: the fields of a node as first stored
+//	2: Language, Checksum and TokenCount
+//	3: Checksum computed by the store and indexed under PREFIX_CHECKSUM
+//	4: SummaryChecksum; earlier records' summaries read as written for their text
+const nodeSchema = 4
+
+// Field tags of a node record
+const (
+	nodeFieldPolicyID = iota + 1
+	nodeFieldNodeID
+	nodeFieldParentID
+	nodeFieldTitle
+	nodeFieldPageStart
+	nodeFieldPageEnd
+	nodeFieldSummary
+	nodeFieldSectionPath
+	nodeFieldDepth
+	nodeFieldCreatedAt
+	nodeFieldUpdatedAt
+	nodeFieldVersion
+	nodeFieldTextLen
+	nodeFieldLanguage
+	nodeFieldChecksum
+	nodeFieldTokenCount
+	nodeFieldSummaryChecksum
+)
+
+// encodeNode encodes a node as a record. Its text is not part of it; the
+// record holds the text's length, and the text is kept in its text record.
+func encodeNode(node *Node) []byte {
+	w := storage.NewRecordWriter(nodeSchema)
+	w.String(nodeFieldPolicyID, node.PolicyID)
+	w.String(nodeFieldNodeID, node.NodeID)
+	if node.ParentID != nil {
+		w.String(nodeFieldParentID, *node.ParentID)
+	}
+	w.String(nodeFieldTitle, node.Title)
+	w.Int(nodeFieldPageStart, int64(node.PageStart))
+	w.Int(nodeFieldPageEnd, int64(node.PageEnd))
+	w.String(nodeFieldSummary, node.Summary)
+	w.String(nodeFieldSectionPath, node.SectionPath)
+	w.Int(nodeFieldDepth, int64(node.Depth))
+	w.Time(nodeFieldCreatedAt, node.CreatedAt)
+	w.Time(nodeFieldUpdatedAt, node.UpdatedAt)
+	w.Uint(nodeFieldVersion, node.Version)
+	w.Int(nodeFieldTextLen, int64(len(node.Text)))
+	w.String(nodeFieldLanguage, node.Language)
+	w.String(nodeFieldChecksum, node.Checksum)
+	w.Int(nodeFieldTokenCount, int64(node.TokenCount))
+	w.String(nodeFieldSummaryChecksum, node.SummaryChecksum)
+	return w.Encode()
+}
+
+// decodeNodeRecord decodes a node record, leaving the summary empty when skip
+// reports nodeColumnSummary; separate reports whether the node has text
+func decodeNodeRecord(val []byte, skip func(i int) bool) (node *Node, separate bool, err error) {
+	r, err := storage.DecodeRecord(val)
+	if err != nil {
+		return nil, false, err
+	}
+
+	node = &Node{
+		PolicyID:    r.String(nodeFieldPolicyID),
+		NodeID:      r.String(nodeFieldNodeID),
+		Title:       r.String(nodeFieldTitle),
+		PageStart:   int(r.Int(nodeFieldPageStart)),
+		PageEnd:     int(r.Int(nodeFieldPageEnd)),
+		SectionPath: r.String(nodeFieldSectionPath),
+		Depth:       int(r.Int(nodeFieldDepth)),
+		CreatedAt:   r.Time(nodeFieldCreatedAt),
+		UpdatedAt:   r.Time(nodeFieldUpdatedAt),
+		Version:     r.Uint(nodeFieldVersion),
+		Language:    r.String(nodeFieldLanguage),
+		Checksum:    r.String(nodeFieldChecksum),
+		TokenCount:  int(r.Int(nodeFieldTokenCount)),
+	}
+	node.SummaryChecksum = r.String(nodeFieldSummaryChecksum)
+	if r.Schema < 4 && r.String(nodeFieldSummary) != "" {
+		node.SummaryChecksum = node.Checksum
+	}
+	if skip == nil || !skip(nodeColumnSummary) {
+		node.Summary = r.String(nodeFieldSummary)
+	}
+	if parentID := r.String(nodeFieldParentID); parentID != "" {
+		node.ParentID = &parentID
+	}
+	return node, r.Int(nodeFieldTextLen) > 0, nil
+}
+
+// outdatedNode reports whether a stored node predates nodeSchema, as a tuple
+// or a record of an earlier schema
+func outdatedNode(val []byte) bool {
+	if !storage.IsRecord(val) {
+		return true
+	}
+	r, err := storage.DecodeRecord(val)
+	return err == nil && r.Schema < nodeSchema
+}
+
+// UpgradeNodes rewrites the nodes of a policy, or of every policy if
+// policyID is empty, that are stored in an earlier format, returning how many
+// it rewrote. Reads never need it, as they default the fields old records
+// lack, and any write of a node upgrades its record; UpgradeNodes upgrades
+// the nodes no write reaches. Fields the old records lack keep their
+// defaults, and versions are unchanged.
+func (ss *SimpleStore) UpgradeNodes(policyID string) (int, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	tx := ss.kv.Begin()
+	defer tx.Abort()
+
+	var scope []storage.Value
+	if policyID != "" {
+		scope = []storage.Value{storage.NewBytesValue([]byte(policyID))}
+	}
+	var outdated []*Node
+	var decodeErr error
+	err := storage.ScanPrefix(tx, storage.EncodeKey(PREFIX_NODE, scope), func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 2 {
+			return true
+		}
+		if !outdatedNode(val) {
+			return true
+		}
+		node, separate, err := decodeStoredNode(val, nil)
+		if err != nil {
+			decodeErr = fmt.Errorf("node %s/%s: %w", vals[0].Str, vals[1].Str, err)
+			return false
+		}
+		if separate {
+			text, _ := tx.Get(textKey(node.PolicyID, node.NodeID))
+			node.Text = string(text)
+		}
+		outdated = append(outdated, node)
+		return true
+	})
+	if err == nil {
+		err = decodeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// Content is unchanged, so indexes are too; tuples' inline text moves to
+	// a text record
+	for _, node := range outdated {
+		writeNode(tx, node, node)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(outdated), nil
+}
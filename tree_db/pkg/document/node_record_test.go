@@ -0,0 +1,77 @@
+// ABOUTME: Tests for node records across schema versions
+// ABOUTME: Verifies records of an earlier schema read with defaults and are upgraded by writes and UpgradeNodes
+
+package document
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+func TestNodeSchemaUpgrade(t *testing.T) {
+	ds, kv, _ := setupTestStore(t)
+	defer kv.Close()
+
+	// A schema 1 record, before Language, Checksum and TokenCount
+	old := storage.NewRecordWriter(1)
+	old.String(nodeFieldPolicyID, "LCD-1")
+	old.String(nodeFieldNodeID, "root")
+	old.String(nodeFieldTitle, "Coverage")
+	old.String(nodeFieldSummary, "What is covered")
+	old.Time(nodeFieldCreatedAt, time.Unix(1700000000, 0))
+	old.Uint(nodeFieldVersion, 3)
+	old.Int(nodeFieldTextLen, 4)
+	tx := kv.Begin()
+	tx.Set(nodeKey("LCD-1", "root"), old.Encode())
+	tx.Set(textKey("LCD-1", "root"), []byte("Text"))
+	tx.Set(childKey("LCD-1", nil, "root"), []byte{})
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	node, err := ds.GetNode("LCD-1", "root")
+	if err != nil || node.Title != "Coverage" || node.Text != "Text" || node.Version != 3 {
+		t.Fatalf("Expected the schema 1 record read, got %+v (%v)", node, err)
+	}
+	if node.Language != "" || node.Checksum != "" || node.TokenCount != 0 {
+		t.Errorf("Expected defaults for the fields it lacks, got %+v", node)
+	}
+
+	// Fields a reader does not know are skipped
+	newer := storage.NewRecordWriter(nodeSchema + 1)
+	newer.String(nodeFieldPolicyID, "LCD-1")
+	newer.String(nodeFieldNodeID, "future")
+	newer.String(99, "unknown")
+	if node, _, err := decodeStoredNode(newer.Encode(), nil); err != nil || node.NodeID != "future" {
+		t.Errorf("Expected a newer record read, got %+v (%v)", node, err)
+	}
+
+	n, err := ds.UpgradeNodes("LCD-1")
+	if err != nil || n != 1 {
+		t.Fatalf("Expected one node upgraded, got %d (%v)", n, err)
+	}
+	val, _ := kv.Get(nodeKey("LCD-1", "root"))
+	if r, err := storage.DecodeRecord(val); err != nil || r.Schema != nodeSchema {
+		t.Errorf("Expected a schema %d record, got %v (%v)", nodeSchema, r, err)
+	}
+	if got, _ := ds.GetNode("LCD-1", "root"); got.Text != "Text" || got.Version != 3 || !got.CreatedAt.Equal(node.CreatedAt) {
+		t.Errorf("Expected the node unchanged by the upgrade, got %+v", got)
+	} else if SummaryStale(got) {
+		t.Errorf("Expected an earlier summary taken as current, got %+v", got)
+	}
+	if n, err := ds.UpgradeNodes(""); err != nil || n != 0 {
+		t.Errorf("Expected nothing left to upgrade, got %d (%v)", n, err)
+	}
+
+	// Writes store the new fields
+	node.Language = "en"
+	if err := ds.UpdateNode(node); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	got, err := ds.GetNode("LCD-1", "root")
+	if err != nil || got.Language != "en" || got.Checksum != ContentChecksum("Text") || got.TokenCount != EstimateTokens("Text") {
+		t.Errorf("Expected the new fields stored, got %+v (%v)", got, err)
+	}
+}
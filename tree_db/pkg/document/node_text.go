@@ -0,0 +1,84 @@
+// ABOUTME: Node text stored in a record of its own beside the node's structural fields
+// ABOUTME: Tree walks, children listings and validation read node records without paying for their text
+
+package document
+
+import "github.com/nainya/treestore/pkg/storage"
+
+// PREFIX_TEXT keys node text as (policyID, nodeID) -> raw text
+const PREFIX_TEXT = uint32(2100)
+
+// nodeColumnTextLen is the position in a node stored as a tuple of the length
+// of its text record. Tuples written before text was split out lack it and
+// hold their text in nodeColumnText instead.
+const nodeColumnTextLen = 13
+
+// skipLargeColumns skips the summary and any inline text of a node record
+func skipLargeColumns(i int) bool {
+	return i == nodeColumnSummary || i == nodeColumnText
+}
+
+// textKey returns the key of a node's text record
+func textKey(policyID, nodeID string) []byte {
+	return storage.EncodeKey(PREFIX_TEXT, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(nodeID)),
+	})
+}
+
+// writeText stores a node's text, removing the record of old when the text is
+// now empty. Text is stored raw, so it needs no escaping.
+func writeText(tx storage.Txn, old, node *Node) {
+	if node.Text != "" {
+		tx.Set(textKey(node.PolicyID, node.NodeID), []byte(node.Text))
+	} else if old != nil && old.Text != "" {
+		tx.Del(textKey(old.PolicyID, old.NodeID))
+	}
+}
+
+// decodeStoredNode decodes a node record, leaving the columns skip reports
+// empty; separate reports whether the node's text is in a text record still
+// to be read
+func decodeStoredNode(val []byte, skip func(i int) bool) (node *Node, separate bool, err error) {
+	if storage.IsRecord(val) {
+		return decodeNodeRecord(val, skip)
+	}
+	vals, err := storage.DecodeValuesSkipping(val, skip)
+	if err != nil {
+		return nil, false, err
+	}
+	node, err = parseNodeVals(vals)
+	if err != nil {
+		return nil, false, err
+	}
+	return node, len(vals) > nodeColumnTextLen && vals[nodeColumnTextLen].I64 > 0, nil
+}
+
+// CheckNodeRecord reports whether an encoded node record decodes, for
+// background checks that re-read recent writes
+func CheckNodeRecord(val []byte) error {
+	_, _, err := decodeStoredNode(val, nil)
+	return err
+}
+
+// readTexts fills in the text of nodes from their text records with one
+// batched lookup; a node whose record is missing keeps an empty text
+func (ss *SimpleStore) readTexts(nodes []*Node) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	keys := make([][]byte, len(nodes))
+	for i, node := range nodes {
+		keys[i] = textKey(node.PolicyID, node.NodeID)
+	}
+	vals, found, err := ss.kv.LookupBatch(keys)
+	if err != nil {
+		return err
+	}
+	for i, node := range nodes {
+		if found[i] {
+			node.Text = string(vals[i])
+		}
+	}
+	return nil
+}
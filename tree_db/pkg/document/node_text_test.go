@@ -0,0 +1,150 @@
+// ABOUTME: Tests for node text kept in records of its own
+// ABOUTME: Verifies text-free reads skip the records, cleared text, and nodes with text still inline
+
+package document
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// textCountingEngine counts the text records read through it
+type textCountingEngine struct {
+	storage.Engine
+	textReads int
+}
+
+func (e *textCountingEngine) LookupBatch(keys [][]byte) ([][]byte, []bool, error) {
+	for _, key := range keys {
+		if storage.ExtractPrefix(key) == PREFIX_TEXT {
+			e.textReads++
+		}
+	}
+	return e.Engine.LookupBatch(keys)
+}
+
+func TestNodeTextStoredSeparately(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	rootID := "root"
+	long := strings.Repeat("coverage criteria ", 100)
+	nodes := []*Node{
+		{NodeID: rootID, PolicyID: "LCD-1", Title: "Root", Text: long},
+		{NodeID: "a", PolicyID: "LCD-1", ParentID: &rootID, Title: "A", Summary: "First", Text: "Section A text", Depth: 1},
+		{NodeID: "b", PolicyID: "LCD-1", ParentID: &rootID, Title: "B", Depth: 1},
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	record, _ := kv.Get(nodeKey("LCD-1", rootID))
+	if strings.Contains(string(record), "coverage") {
+		t.Error("Expected the node record to leave its text out")
+	}
+	if text, ok := kv.Get(textKey("LCD-1", rootID)); !ok || string(text) != long {
+		t.Errorf("Expected the text in its own record, got %d bytes", len(text))
+	}
+
+	counting := &textCountingEngine{Engine: kv}
+	store := NewSimpleStore(counting)
+	subtree, err := store.WithoutFields(FieldText).GetSubtree("LCD-1", rootID, QueryOptions{})
+	if err != nil || len(subtree) != 3 {
+		t.Fatalf("GetSubtree failed: %v, %v", subtree, err)
+	}
+	if counting.textReads != 0 {
+		t.Errorf("Expected no text records read, read %d", counting.textReads)
+	}
+	if subtree[1].Summary != "First" {
+		t.Errorf("Expected the summary kept, got %q", subtree[1].Summary)
+	}
+
+	nodesByID, err := store.GetNodes("LCD-1", []string{rootID, "a", "b"})
+	if err != nil {
+		t.Fatalf("GetNodes failed: %v", err)
+	}
+	if nodesByID[0].Text != long || nodesByID[1].Text != "Section A text" || nodesByID[2].Text != "" {
+		t.Error("Expected GetNodes to load each node's text")
+	}
+	// Only the two nodes with text have a record to read
+	if counting.textReads != 2 {
+		t.Errorf("Expected 2 text records read, read %d", counting.textReads)
+	}
+
+	results, err := ds.Search("LCD-1", "section", 10)
+	if err != nil || len(results) != 1 || results[0].NodeID != "a" {
+		t.Errorf("Expected search to match the separately stored text, got %v (%v)", results, err)
+	}
+
+	// Clearing the text removes its record
+	node, _ := ds.GetNode("LCD-1", "a")
+	node.Text = ""
+	if err := ds.UpdateNode(node); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if _, ok := kv.Get(textKey("LCD-1", "a")); ok {
+		t.Error("Expected the cleared text's record deleted")
+	}
+	if results, _ := ds.SearchAll("section", 10, 0); len(results) != 0 {
+		t.Errorf("Expected the cleared text's postings removed, got %v", results)
+	}
+}
+
+func TestNodeTextInlineMovedByReindex(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	// A node as written before text records: text inline, no length column
+	tx := kv.Begin()
+	tx.Set(nodeKey("LCD-1", "old"), storage.EncodeValues([]storage.Value{
+		storage.NewBytesValue([]byte("LCD-1")),
+		storage.NewBytesValue([]byte("old")),
+		storage.NewBytesValue(nil),
+		storage.NewBytesValue([]byte("Old")),
+		storage.NewInt64Value(1),
+		storage.NewInt64Value(2),
+		storage.NewBytesValue(nil),
+		storage.NewBytesValue([]byte("Inline text")),
+		storage.NewBytesValue(nil),
+		storage.NewInt64Value(0),
+		storage.NewTimeValue(time.Unix(0, 0)),
+		storage.NewTimeValue(time.Unix(0, 0)),
+		storage.NewInt64Value(1),
+	}))
+	tx.Set(childKey("LCD-1", nil, "old"), []byte{})
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	node, err := ds.GetNode("LCD-1", "old")
+	if err != nil || node.Text != "Inline text" {
+		t.Fatalf("Expected the inline text read, got %v (%v)", node, err)
+	}
+	if node, _ := ds.WithoutFields(FieldText).GetNode("LCD-1", "old"); node.Text != "" {
+		t.Errorf("Expected inline text skipped, got %q", node.Text)
+	}
+
+	if _, err := ds.Reindex("LCD-1"); err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if text, ok := kv.Get(textKey("LCD-1", "old")); !ok || string(text) != "Inline text" {
+		t.Errorf("Expected Reindex to move the text to its own record, got %q", text)
+	}
+	record, _ := kv.Get(nodeKey("LCD-1", "old"))
+	if strings.Contains(string(record), "Inline") || !storage.IsRecord(record) {
+		t.Error("Expected Reindex to rewrite the node as a record without its text")
+	}
+	node, err = ds.GetNode("LCD-1", "old")
+	if err != nil || node.Text != "Inline text" || node.Version != 1 {
+		t.Errorf("Expected the node unchanged by the move, got %+v (%v)", node, err)
+	}
+	if results, _ := ds.SearchAll("inline", 10, 0); len(results) != 1 {
+		t.Errorf("Expected the moved text indexed, got %v", results)
+	}
+}
@@ -0,0 +1,242 @@
+// ABOUTME: Cache of the ancestor IDs of recently read nodes, and batched ancestor path lookups
+// ABOUTME: Turns a walk of one lookup per level into a single batched lookup for nodes read before
+
+package document
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultPathCacheEntries is a reasonable size for SetPathCache
+const DefaultPathCacheEntries = 4096
+
+// PathCacheStats counts ancestor path cache activity
+type PathCacheStats struct {
+	Hits    int64 // Paths read from cached ancestor IDs
+	Misses  int64 // Paths walked a level at a time
+	Entries int
+}
+
+// pathKey names a node in the path cache
+type pathKey struct {
+	policyID, nodeID string
+}
+
+// pathEntry holds the IDs of a node's ancestors, from its root to its parent
+type pathEntry struct {
+	key         pathKey
+	ancestorIDs []string
+}
+
+// pathCache is an LRU of nodes' ancestor IDs shared by a store and its views.
+// Only moving a node changes the ancestors of nodes already stored, so writes
+// that move one drop their policy's entries.
+type pathCache struct {
+	mu      sync.Mutex
+	max     int // 0 caches nothing
+	entries map[pathKey]*list.Element
+	lru     *list.List // Front is most recently used
+	gen     uint64     // Bumped by every drop, so walks it overlapped are not cached
+
+	hits, misses atomic.Int64
+}
+
+func newPathCache() *pathCache {
+	return &pathCache{entries: make(map[pathKey]*list.Element), lru: list.New()}
+}
+
+// generation returns the generation to pass to put for a walk starting now
+func (c *pathCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// get returns the cached ancestor IDs of a node
+func (c *pathCache) get(policyID, nodeID string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[pathKey{policyID, nodeID}]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*pathEntry).ancestorIDs, true
+}
+
+// put caches the ancestor IDs of a node read by a walk that began at gen,
+// unless a write has since dropped entries
+func (c *pathCache) put(gen uint64, policyID, nodeID string, ancestorIDs []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.max == 0 || c.gen != gen {
+		return
+	}
+
+	key := pathKey{policyID, nodeID}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*pathEntry).ancestorIDs = ancestorIDs
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&pathEntry{key: key, ancestorIDs: ancestorIDs})
+	for c.lru.Len() > c.max {
+		c.remove(c.lru.Back())
+	}
+}
+
+// drop forgets the entries of the given policies after a write moved nodes
+// in them
+func (c *pathCache) drop(policyIDs map[string]bool) {
+	if len(policyIDs) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if policyIDs[elem.Value.(*pathEntry).key.policyID] {
+			c.remove(elem)
+		}
+		elem = next
+	}
+}
+
+// resize sets the number of entries kept, forgetting every cached one
+func (c *pathCache) resize(entries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.max = max(entries, 0)
+	c.entries = make(map[pathKey]*list.Element)
+	c.lru.Init()
+}
+
+func (c *pathCache) remove(elem *list.Element) {
+	delete(c.entries, elem.Value.(*pathEntry).key)
+	c.lru.Remove(elem)
+}
+
+// SetPathCache caches the ancestor IDs of up to entries nodes read by
+// GetAncestorPath and BatchGetAncestorPaths, so a node read again has its
+// path looked up in one batch rather than a level at a time; 0, the
+// default, turns the cache off. Writes through the store that move a node
+// keep it current; writes that bypass the store, such as a follower
+// applying its leader's log, need it off.
+func (ss *SimpleStore) SetPathCache(entries int) {
+	ss.paths.resize(entries)
+}
+
+// PathCacheStats returns the ancestor path cache's activity
+func (ss *SimpleStore) PathCacheStats() PathCacheStats {
+	ss.paths.mu.Lock()
+	entries := ss.paths.lru.Len()
+	ss.paths.mu.Unlock()
+	return PathCacheStats{Hits: ss.paths.hits.Load(), Misses: ss.paths.misses.Load(), Entries: entries}
+}
+
+// BatchGetAncestorPaths returns the path from root to each node, aligned
+// with nodeIDs, such as to give search hits their section context. Nodes of
+// a level are looked up together, so shared ancestors are read once and a
+// batch takes one lookup per level of its deepest node, or a single lookup
+// when every path is cached. A path is nil when its node or one of its
+// ancestors does not exist.
+func (ss *SimpleStore) BatchGetAncestorPaths(policyID string, nodeIDs []string) ([][]*Node, error) {
+	gen := ss.paths.generation()
+
+	// Cached ancestors join the first lookup, so they cost no lookups of their own
+	known := make(map[string]*Node)
+	looked := make(map[string]bool)
+	var need []string
+	want := func(id string) {
+		if id != "" && !looked[id] {
+			looked[id] = true
+			need = append(need, id)
+		}
+	}
+	for _, nodeID := range nodeIDs {
+		want(nodeID)
+		ancestorIDs, ok := ss.paths.get(policyID, nodeID)
+		if ok {
+			ss.paths.hits.Add(1)
+		} else {
+			ss.paths.misses.Add(1)
+		}
+		for _, id := range ancestorIDs {
+			want(id)
+		}
+	}
+
+	for len(need) > 0 {
+		nodes, err := ss.GetNodes(policyID, need)
+		if err != nil {
+			return nil, err
+		}
+		need = nil
+		for _, node := range nodes {
+			if node != nil {
+				known[node.NodeID] = node
+				want(parentOf(node))
+			}
+		}
+	}
+
+	paths := make([][]*Node, len(nodeIDs))
+	for i, nodeID := range nodeIDs {
+		paths[i] = knownPath(known, nodeID)
+		ss.cachePath(gen, paths[i], 0)
+	}
+	return paths, nil
+}
+
+// knownPath returns the path from root to a node from nodes already read,
+// or nil if one is missing or parent links form a cycle
+func knownPath(known map[string]*Node, nodeID string) []*Node {
+	var path []*Node
+	for id := nodeID; id != ""; {
+		node := known[id]
+		if node == nil || len(path) > len(known) {
+			return nil
+		}
+		path = append(path, node)
+		id = parentOf(node)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// cachedPath returns the path from root to a node from its cached ancestor
+// IDs, or nil if they are not cached or no longer match the stored nodes
+func (ss *SimpleStore) cachedPath(policyID, nodeID string) []*Node {
+	ancestorIDs, ok := ss.paths.get(policyID, nodeID)
+	if !ok {
+		return nil
+	}
+	path, err := ss.GetNodes(policyID, append(ancestorIDs[:len(ancestorIDs):len(ancestorIDs)], nodeID))
+	if err != nil {
+		return nil
+	}
+	for i, node := range path {
+		if node == nil || (i == 0 && parentOf(node) != "") || (i > 0 && parentOf(node) != path[i-1].NodeID) {
+			return nil
+		}
+	}
+	return path
+}
+
+// cachePath caches the ancestor IDs of the nodes of a path read by a walk
+// that began at gen, from position from on
+func (ss *SimpleStore) cachePath(gen uint64, path []*Node, from int) {
+	ids := make([]string, len(path))
+	for i, node := range path {
+		ids[i] = node.NodeID
+	}
+	for i := from; i < len(path); i++ {
+		ss.paths.put(gen, path[i].PolicyID, path[i].NodeID, ids[:i:i])
+	}
+}
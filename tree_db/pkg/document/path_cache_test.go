@@ -0,0 +1,100 @@
+// ABOUTME: Tests for the ancestor path cache and batched ancestor paths
+// ABOUTME: Verifies cached reads, invalidation when nodes move, and batches with shared and missing nodes
+
+package document
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// pathIDs lists the node IDs of a path
+func pathIDs(path []*Node) string {
+	var ids []string
+	for _, node := range path {
+		ids = append(ids, node.NodeID)
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestPathCache(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+	ds.SetPathCache(DefaultPathCacheEntries)
+
+	root, a, b := "root", "a", "b"
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, []*Node{
+		{NodeID: root, PolicyID: "LCD-1"},
+		{NodeID: a, PolicyID: "LCD-1", ParentID: &root},
+		{NodeID: b, PolicyID: "LCD-1", ParentID: &root},
+		{NodeID: "a1", PolicyID: "LCD-1", ParentID: &a},
+	}); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := ds.GetAncestorPath("LCD-1", "a1")
+		if err != nil || pathIDs(got) != "[root a a1]" {
+			t.Fatalf("Expected [root a a1], got %s, %v", pathIDs(got), err)
+		}
+	}
+	if stats := ds.PathCacheStats(); stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 3 {
+		t.Errorf("Expected one walk, then a hit, got %+v", stats)
+	}
+
+	// Moving a section drops its policy's cached paths
+	moved, _ := ds.GetNode("LCD-1", a)
+	moved.ParentID = &b
+	if err := ds.UpdateNode(moved); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if got, err := ds.GetAncestorPath("LCD-1", "a1"); err != nil || pathIDs(got) != "[root b a a1]" {
+		t.Errorf("Expected [root b a a1] after the move, got %s, %v", pathIDs(got), err)
+	}
+
+	ds.SetPathCache(0)
+	if got, err := ds.GetAncestorPath("LCD-1", "a1"); err != nil || pathIDs(got) != "[root b a a1]" || ds.PathCacheStats().Entries != 0 {
+		t.Errorf("Expected the path uncached, got %s, %v", pathIDs(got), err)
+	}
+}
+
+func TestBatchGetAncestorPaths(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+	ds.SetPathCache(DefaultPathCacheEntries)
+
+	root, a, b := "root", "a", "b"
+	missing := "missing"
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, []*Node{
+		{NodeID: root, PolicyID: "LCD-1"},
+		{NodeID: a, PolicyID: "LCD-1", ParentID: &root},
+		{NodeID: b, PolicyID: "LCD-1", ParentID: &a},
+		{NodeID: "orphan", PolicyID: "LCD-1", ParentID: &missing},
+	}); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	ids := []string{b, root, "nope", "orphan", a}
+	for i := 0; i < 2; i++ {
+		paths, err := ds.BatchGetAncestorPaths("LCD-1", ids)
+		if err != nil {
+			t.Fatalf("BatchGetAncestorPaths failed: %v", err)
+		}
+		var got []string
+		for _, p := range paths {
+			got = append(got, pathIDs(p))
+		}
+		if fmt.Sprint(got) != "[[root a b] [root] [] [] [root a]]" {
+			t.Errorf("Unexpected paths %v", got)
+		}
+	}
+	if stats := ds.PathCacheStats(); stats.Hits != 3 || stats.Entries != 3 {
+		t.Errorf("Expected the second batch to hit for the three found paths, got %+v", stats)
+	}
+	if got, err := ds.GetAncestorPath("LCD-1", b); err != nil || pathIDs(got) != "[root a b]" {
+		t.Errorf("Expected [root a b] from the cache, got %s, %v", pathIDs(got), err)
+	}
+}
@@ -0,0 +1,122 @@
+// ABOUTME: Depth-first reading order of a policy's nodes
+// ABOUTME: Streams a document section by section, as text-chunking pipelines read it
+
+package document
+
+import (
+	"fmt"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// readingOrderBatch is the number of nodes GetReadingOrder looks up at once
+const readingOrderBatch = 64
+
+// GetReadingOrder calls fn with each node of a policy in document order,
+// depth first from the roots: a section, then its subsections, then the
+// section after it, as GetNextSection steps. The order comes from two key
+// scans of the sibling order and children indexes; only node IDs are held,
+// and nodes are read in batches as they are streamed. An error from fn stops
+// the walk and is returned. Nodes unreachable from a root are left out.
+func (ss *SimpleStore) GetReadingOrder(policyID string, fn func(*Node) error) error {
+	children, err := ss.childOrder(policyID)
+	if err != nil {
+		return err
+	}
+	if len(children[""]) == 0 {
+		return fmt.Errorf("%w: %s", ErrDocumentNotFound, policyID)
+	}
+
+	var batch []string
+	flush := func() error {
+		nodes, err := ss.GetNodes(policyID, batch)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+		for _, node := range nodes {
+			if node == nil {
+				continue // Left behind in the indexes by a removed node
+			}
+			if err := fn(node); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Each frame is the siblings still to visit at one level
+	visited := make(map[string]bool)
+	stack := [][]string{children[""]}
+	for len(stack) > 0 {
+		top := len(stack) - 1
+		if len(stack[top]) == 0 {
+			stack = stack[:top]
+			continue
+		}
+		nodeID := stack[top][0]
+		stack[top] = stack[top][1:]
+		if visited[nodeID] {
+			continue // Guards against a cycle in the children index
+		}
+		visited[nodeID] = true
+
+		batch = append(batch, nodeID)
+		if len(batch) == readingOrderBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if len(children[nodeID]) > 0 {
+			stack = append(stack, children[nodeID])
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return flush()
+}
+
+// childOrder returns the IDs of each parent's children of a policy in
+// document order, keyed by parent ID with the roots under the empty ID
+func (ss *SimpleStore) childOrder(policyID string) (map[string][]string, error) {
+	scope := []storage.Value{storage.NewBytesValue([]byte(policyID))}
+
+	ordered := make(map[string][]string)
+	err := storage.ScanPrefix(ss.kv, storage.EncodeKey(PREFIX_SIBLING_ORDER, scope), func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 5 {
+			return true
+		}
+		parentID := string(vals[1].Str)
+		ordered[parentID] = append(ordered[parentID], string(vals[4].Str))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	indexed := make(map[string]int)
+	err = storage.ScanPrefix(ss.kv, storage.EncodeKey(PREFIX_CHILDREN, scope), func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 3 {
+			return true
+		}
+		indexed[string(vals[1].Str)]++
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Parents with children the order index lacks are sorted as it would
+	for parentID, count := range indexed {
+		if len(ordered[parentID]) == count {
+			continue
+		}
+		if ordered[parentID], err = ss.orderedChildIDs(policyID, parentID); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,85 @@
+// ABOUTME: Tests for the depth-first reading order
+// ABOUTME: Verifies document order across batches and roots, early stops and the fallback for older nodes
+
+package document
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGetReadingOrder(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	// Two roots with more sections than fit in one batch, numbered so that
+	// node IDs and breadth-first order both differ from reading order
+	var nodes []*Node
+	var want []string
+	for r := 2; r >= 1; r-- {
+		rootID := fmt.Sprintf("r%d", r)
+		nodes = append(nodes, &Node{NodeID: rootID, PolicyID: "LCD-1", SectionPath: fmt.Sprint(r)})
+	}
+	for r := 1; r <= 2; r++ {
+		rootID := fmt.Sprintf("r%d", r)
+		want = append(want, rootID)
+		for s := 1; s <= 40; s++ {
+			secID := fmt.Sprintf("r%d-s%02d", r, 41-s)
+			parent := rootID
+			nodes = append(nodes, &Node{NodeID: secID, PolicyID: "LCD-1", ParentID: &parent, SectionPath: fmt.Sprintf("%d.%d", r, s)})
+			want = append(want, secID)
+			if s%10 == 0 {
+				leafParent := secID
+				nodes = append(nodes, &Node{NodeID: secID + "-a", PolicyID: "LCD-1", ParentID: &leafParent, SectionPath: fmt.Sprintf("%d.%d.1", r, s)})
+				want = append(want, secID+"-a")
+			}
+		}
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	read := func() string {
+		var ids []string
+		if err := ds.GetReadingOrder("LCD-1", func(node *Node) error {
+			ids = append(ids, node.NodeID)
+			return nil
+		}); err != nil {
+			t.Fatalf("GetReadingOrder failed: %v", err)
+		}
+		return strings.Join(ids, " ")
+	}
+	if got := read(); got != strings.Join(want, " ") {
+		t.Errorf("Unexpected reading order\n got %s\nwant %s", got, strings.Join(want, " "))
+	}
+
+	// Children written before the order index are sorted in
+	tx := kv.Begin()
+	for _, node := range nodes[2:5] {
+		tx.Del(siblingOrderKey(node))
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if got := read(); got != strings.Join(want, " ") {
+		t.Errorf("Unexpected reading order without order entries\n got %s", got)
+	}
+
+	stop := errors.New("stop")
+	count := 0
+	if err := ds.GetReadingOrder("LCD-1", func(node *Node) error {
+		if count++; count == 3 {
+			return stop
+		}
+		return nil
+	}); err != stop || count != 3 {
+		t.Errorf("Expected the walk to stop at the third node, got %v after %d", err, count)
+	}
+	if err := ds.GetReadingOrder("LCD-404", func(*Node) error { return nil }); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Expected ErrDocumentNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+// ABOUTME: Explicit index of each policy's root nodes
+// ABOUTME: Lets GetDocument and GetSubtree find a document's root without guessing from the children index
+
+package document
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// PREFIX_ROOT keys a policy's roots as (policyID, nodeID) -> empty. Policies
+// stored before it existed have no entries and are read from the children
+// index under the empty parent instead, until a root is written to them and
+// their existing roots are indexed along with it.
+const PREFIX_ROOT = uint32(5400)
+
+// ErrMultipleRoots is returned when a policy's root is asked for but it has
+// more than one
+var ErrMultipleRoots = errors.New("document: document has multiple roots")
+
+// rootKey returns the root index key of a node
+func rootKey(policyID, nodeID string) []byte {
+	return storage.EncodeKey(PREFIX_ROOT, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(nodeID)),
+	})
+}
+
+// putRoot keeps a node's root index entry in step with its parent link
+func putRoot(tx storage.Txn, old, node *Node) {
+	if old != nil && parentOf(old) == "" && parentOf(node) != "" {
+		tx.Del(rootKey(old.PolicyID, old.NodeID))
+	}
+	if parentOf(node) == "" {
+		indexLegacyRoots(tx, node.PolicyID)
+		tx.Set(rootKey(node.PolicyID, node.NodeID), []byte{})
+	}
+}
+
+// indexLegacyRoots adds the roots of a policy stored before the root index to
+// it, so that a root written later does not hide them. A policy with entries
+// already is left alone. A failed scan fails the transaction's commit.
+func indexLegacyRoots(tx storage.Txn, policyID string) {
+	scope := []storage.Value{storage.NewBytesValue([]byte(policyID))}
+	indexed := false
+	storage.ScanPrefix(tx, storage.EncodeKey(PREFIX_ROOT, scope), func(key, val []byte) bool {
+		indexed = true
+		return false
+	})
+	if indexed {
+		return
+	}
+
+	roots, _ := scanNodeIDs(tx, storage.EncodeKey(PREFIX_CHILDREN, append(scope, storage.NewBytesValue(nil))), 2)
+	for _, nodeID := range roots {
+		// Skip index entries left behind by a removed node
+		if _, ok := tx.Get(nodeKey(policyID, nodeID)); ok {
+			tx.Set(rootKey(policyID, nodeID), []byte{})
+		}
+	}
+}
+
+// GetRootIDs returns the IDs of a policy's root nodes in ID order; a policy
+// without nodes has none
+func (ss *SimpleStore) GetRootIDs(policyID string) ([]string, error) {
+	scope := []storage.Value{storage.NewBytesValue([]byte(policyID))}
+	rootIDs, err := scanNodeIDs(ss.kv, storage.EncodeKey(PREFIX_ROOT, scope), 1)
+	if err != nil || len(rootIDs) > 0 {
+		return rootIDs, err
+	}
+
+	// Fall back to the children index for policies stored before the root index
+	roots, err := ss.WithoutFields(FieldSummary|FieldText).GetChildren(policyID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, root := range roots {
+		rootIDs = append(rootIDs, root.NodeID)
+	}
+	return rootIDs, nil
+}
+
+// GetRoot returns the root node of a policy's document, failing with
+// ErrDocumentNotFound if it has none and ErrMultipleRoots if it has several
+func (ss *SimpleStore) GetRoot(policyID string) (*Node, error) {
+	rootIDs, err := ss.GetRootIDs(policyID)
+	if err != nil {
+		return nil, err
+	}
+	switch len(rootIDs) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrDocumentNotFound, policyID)
+	case 1:
+	default:
+		return nil, fmt.Errorf("%w: %s has roots %s", ErrMultipleRoots, policyID, strings.Join(rootIDs, ", "))
+	}
+
+	root, err := ss.GetNode(policyID, rootIDs[0])
+	if errors.Is(err, ErrNodeNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrDocumentNotFound, policyID)
+	}
+	return root, err
+}
+
+// scanNodeIDs returns the node IDs at position column of the keys beginning
+// with prefix
+func scanNodeIDs(s storage.Scanner, prefix []byte, column int) ([]string, error) {
+	var nodeIDs []string
+	err := storage.ScanPrefix(s, prefix, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) <= column {
+			return true
+		}
+		nodeIDs = append(nodeIDs, string(vals[column].Str))
+		return true
+	})
+	return nodeIDs, err
+}
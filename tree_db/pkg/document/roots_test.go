@@ -0,0 +1,85 @@
+// ABOUTME: Tests for the root index
+// ABOUTME: Verifies roots follow parent changes, multi-root errors and the fallback for older policies
+
+package document
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestGetRoot(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	// A policy whose ID begins with another's must not lend it a root
+	root := "root"
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, []*Node{
+		{NodeID: root, PolicyID: "LCD-1", Title: "Coverage"},
+		{NodeID: "sec1", PolicyID: "LCD-1", ParentID: &root, Title: "Indications", Depth: 1},
+	}); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-10"}, []*Node{
+		{NodeID: "a", PolicyID: "LCD-10", Title: "Other"},
+	}); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	got, err := ds.GetRoot("LCD-1")
+	if err != nil || got.NodeID != root {
+		t.Fatalf("Expected root, got %v, %v", got, err)
+	}
+	subtree, err := ds.GetSubtree("LCD-1", "", QueryOptions{})
+	if err != nil || len(subtree) != 2 || subtree[0].NodeID != root {
+		t.Fatalf("Expected the subtree from the root, got %v, %v", subtree, err)
+	}
+	if _, err := ds.GetRoot("LCD-404"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Expected ErrDocumentNotFound, got %v", err)
+	}
+
+	// A second root makes the document ambiguous until it is moved under the first
+	second := &Node{NodeID: "appendix", PolicyID: "LCD-1", Title: "Appendix"}
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, []*Node{second}); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	if _, err := ds.GetRoot("LCD-1"); !errors.Is(err, ErrMultipleRoots) {
+		t.Errorf("Expected ErrMultipleRoots, got %v", err)
+	}
+	if _, err := ds.GetSubtree("LCD-1", "", QueryOptions{}); !errors.Is(err, ErrMultipleRoots) {
+		t.Errorf("Expected ErrMultipleRoots from GetSubtree, got %v", err)
+	}
+	second.ParentID, second.Depth = &root, 1
+	if err := ds.UpdateNode(second); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if ids, err := ds.GetRootIDs("LCD-1"); err != nil || fmt.Sprint(ids) != "[root]" {
+		t.Errorf("Expected only root, got %v, %v", ids, err)
+	}
+
+	// Policies stored before the root index are read from the children index
+	tx := kv.Begin()
+	tx.Del(rootKey("LCD-1", root))
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if got, err := ds.GetRoot("LCD-1"); err != nil || got.NodeID != root {
+		t.Errorf("Expected root from the children index, got %v, %v", got, err)
+	}
+
+	// A second root written to such a policy indexes the first along with it
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, []*Node{
+		{NodeID: "errata", PolicyID: "LCD-1", Title: "Errata"},
+	}); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	if ids, err := ds.GetRootIDs("LCD-1"); err != nil || fmt.Sprint(ids) != "[errata root]" {
+		t.Errorf("Expected both roots, got %v, %v", ids, err)
+	}
+	if _, err := ds.GetRoot("LCD-1"); !errors.Is(err, ErrMultipleRoots) {
+		t.Errorf("Expected ErrMultipleRoots for the legacy policy, got %v", err)
+	}
+}
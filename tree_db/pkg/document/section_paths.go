@@ -0,0 +1,184 @@
+// ABOUTME: Section path integrity check and renumbering
+// ABOUTME: Rebuilds 1.2.3-style section paths from the parent links of a policy's tree
+
+package document
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+)
+
+// SectionPathChange is a node whose stored section path differs from its place in the tree
+type SectionPathChange struct {
+	NodeID   string
+	Stored   string
+	Expected string
+}
+
+// SectionPathReport lists the section paths that drifted from the tree
+type SectionPathReport struct {
+	NodesChecked int
+	Changes      []SectionPathChange // In tree order
+	Applied      bool                // Changes were written
+}
+
+// CheckSectionPaths reports section paths that do not match the tree, without changing them
+func (ss *SimpleStore) CheckSectionPaths(policyID string) (*SectionPathReport, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.sectionPaths(policyID, false)
+}
+
+// RecomputeSectionPaths renumbers a policy's sections from its tree
+// Roots are numbered 1, 2, ... and each child extends its parent's path.
+// Siblings are ordered by start page, then by their stored section path, then
+// by node ID, so renumbering keeps the existing order wherever it is
+// consistent. Nodes unreachable from a root are left alone.
+func (ss *SimpleStore) RecomputeSectionPaths(policyID string) (*SectionPathReport, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.sectionPaths(policyID, true)
+}
+
+// sectionPaths walks the tree breadth first, optionally writing corrected paths
+// The walk reads no summaries or text; only changed nodes are read in full.
+func (ss *SimpleStore) sectionPaths(policyID string, apply bool) (*SectionPathReport, error) {
+	walk := ss.WithoutFields(FieldSummary | FieldText)
+	roots, err := walk.GetChildren(policyID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDocumentNotFound, policyID)
+	}
+
+	report := &SectionPathReport{}
+	var changed []*Node
+	visited := make(map[string]bool)
+
+	type entry struct {
+		node *Node
+		path string
+	}
+	var level []entry
+	sortSiblings(roots)
+	for i, root := range roots {
+		level = append(level, entry{root, strconv.Itoa(i + 1)})
+	}
+
+	for len(level) > 0 {
+		var next []entry
+		for _, e := range level {
+			if visited[e.node.NodeID] {
+				continue // Guards against a cycle in the children index
+			}
+			visited[e.node.NodeID] = true
+			report.NodesChecked++
+
+			if e.node.SectionPath != e.path {
+				report.Changes = append(report.Changes, SectionPathChange{
+					NodeID:   e.node.NodeID,
+					Stored:   e.node.SectionPath,
+					Expected: e.path,
+				})
+				changed = append(changed, e.node)
+			}
+
+			children, err := walk.GetChildren(policyID, &e.node.NodeID)
+			if err != nil {
+				return nil, err
+			}
+			sortSiblings(children)
+			for i, child := range children {
+				next = append(next, entry{child, e.path + "." + strconv.Itoa(i+1)})
+			}
+		}
+		level = next
+	}
+
+	if !apply || len(changed) == 0 {
+		return report, nil
+	}
+
+	tx := ss.kv.Begin()
+	defer tx.Abort()
+	now := time.Now()
+	for i, node := range changed {
+		old := loadNode(tx, policyID, node.NodeID)
+		if old == nil {
+			continue
+		}
+		updated := *old
+		updated.SectionPath = report.Changes[i].Expected
+		updated.Version = old.Version + 1
+		updated.UpdatedAt = now
+		putNode(tx, old, &updated)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	report.Applied = true
+	ss.feed.Publish(changefeed.EntityDocument, changefeed.OpPut, policyID, "")
+	return report, nil
+}
+
+// sortSiblings orders nodes by start page, stored section path and node ID
+func sortSiblings(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if a.PageStart != b.PageStart {
+			return a.PageStart < b.PageStart
+		}
+		if c := compareSectionPaths(a.SectionPath, b.SectionPath); c != 0 {
+			return c < 0
+		}
+		return a.NodeID < b.NodeID
+	})
+}
+
+// compareSectionPaths compares paths segment by segment, numerically where possible,
+// so that "1.10" sorts after "1.9"; numbered segments sort before named ones
+func compareSectionPaths(a, b string) int {
+	if a == "" || b == "" {
+		return strings.Compare(a, b)
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aNum := sectionNumber(as[i])
+		bn, bNum := sectionNumber(bs[i])
+		switch {
+		case aNum && bNum:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aNum != bNum:
+			if aNum {
+				return -1
+			}
+			return 1
+		case as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return len(as) - len(bs)
+}
+
+// sectionNumber parses a section path segment made only of digits
+func sectionNumber(seg string) (int, bool) {
+	for i := 0; i < len(seg); i++ {
+		if seg[i] < '0' || seg[i] > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(seg)
+	return n, err == nil
+}
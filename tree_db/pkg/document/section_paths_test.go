@@ -0,0 +1,67 @@
+// ABOUTME: Tests for section path checking and renumbering
+// ABOUTME: Verifies drift detection, sibling ordering and that checks do not write
+
+package document
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRecomputeSectionPaths(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	root, a, b, c := "root", "a", "b", "c"
+	nodes := []*Node{
+		{NodeID: root, PolicyID: "LCD-1", SectionPath: "1"},
+		// b was moved under root from elsewhere and kept its old path
+		{NodeID: a, PolicyID: "LCD-1", ParentID: &root, SectionPath: "1.1", PageStart: 2},
+		{NodeID: b, PolicyID: "LCD-1", ParentID: &root, SectionPath: "4.7", PageStart: 5},
+		{NodeID: c, PolicyID: "LCD-1", ParentID: &b, SectionPath: "4.7.2", PageStart: 5},
+		// Siblings on the same page keep their numeric order, 1.10 after 1.9
+		{NodeID: "a9", PolicyID: "LCD-1", ParentID: &a, SectionPath: "1.1.9", PageStart: 3},
+		{NodeID: "a10", PolicyID: "LCD-1", ParentID: &a, SectionPath: "1.1.10", PageStart: 3},
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	want := map[string]string{"b": "1.2", "c": "1.2.1", "a9": "1.1.1", "a10": "1.1.2"}
+
+	check, err := ds.CheckSectionPaths("LCD-1")
+	if err != nil {
+		t.Fatalf("CheckSectionPaths failed: %v", err)
+	}
+	if check.NodesChecked != 6 || check.Applied || len(check.Changes) != len(want) {
+		t.Fatalf("Unexpected check report: %+v", check)
+	}
+	for _, change := range check.Changes {
+		if want[change.NodeID] != change.Expected {
+			t.Errorf("%s: expected %s, got %s", change.NodeID, want[change.NodeID], change.Expected)
+		}
+	}
+	if node, _ := ds.GetNode("LCD-1", b); node.SectionPath != "4.7" {
+		t.Errorf("Check must not write, got %s", node.SectionPath)
+	}
+
+	report, err := ds.RecomputeSectionPaths("LCD-1")
+	if err != nil {
+		t.Fatalf("RecomputeSectionPaths failed: %v", err)
+	}
+	if !report.Applied || len(report.Changes) != len(want) {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	for id, expected := range want {
+		node, err := ds.GetNode("LCD-1", id)
+		if err != nil || node.SectionPath != expected || node.Version != 2 {
+			t.Errorf("%s: expected path %s at version 2, got %+v (%v)", id, expected, node, err)
+		}
+	}
+
+	again, _ := ds.CheckSectionPaths("LCD-1")
+	if len(again.Changes) != 0 {
+		t.Errorf("Expected no drift after renumbering, got %+v", again.Changes)
+	}
+}
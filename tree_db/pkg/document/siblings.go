@@ -0,0 +1,240 @@
+// ABOUTME: Sibling order index and linear navigation between sections
+// ABOUTME: Walks a document in reading order without sorting each parent's children on every step
+
+package document
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// PREFIX_SIBLING_ORDER keys each node under its parent in document order as
+// (policyID, parentID, pageStart, sectionKey, nodeID) -> empty, the order
+// sortSiblings gives. Parents written before it existed, or whose children
+// it lists only some of, are ordered from the children index instead until
+// Reindex runs.
+const PREFIX_SIBLING_ORDER = uint32(5500)
+
+// siblingOrderKey returns the sibling order index key of a node
+func siblingOrderKey(node *Node) []byte {
+	return storage.EncodeKey(PREFIX_SIBLING_ORDER, []storage.Value{
+		storage.NewBytesValue([]byte(node.PolicyID)),
+		storage.NewBytesValue([]byte(parentOf(node))),
+		storage.NewInt64Value(int64(node.PageStart)),
+		storage.NewBytesValue(sectionKey(node.SectionPath)),
+		storage.NewBytesValue([]byte(node.NodeID)),
+	})
+}
+
+// sectionKey encodes a section path so that byte order is compareSectionPaths
+// order: numeric segments are tagged 1 and prefixed with their length, others
+// tagged 2, and segments are separated by a byte below any a path holds
+func sectionKey(path string) []byte {
+	if path == "" {
+		return nil
+	}
+	var key []byte
+	for i, seg := range strings.Split(path, ".") {
+		if i > 0 {
+			key = append(key, 0x01)
+		}
+		if n, ok := sectionNumber(seg); ok {
+			digits := strconv.Itoa(n)
+			key = append(append(key, 0x01, byte(len(digits))), digits...)
+		} else {
+			key = append(append(key, 0x02), seg...)
+		}
+	}
+	return key
+}
+
+// putSiblingOrder keeps a node's sibling order entry in step with its
+// parent, start page and section path
+func putSiblingOrder(tx storage.Txn, old, node *Node) {
+	key := siblingOrderKey(node)
+	if old != nil {
+		if oldKey := siblingOrderKey(old); !bytes.Equal(oldKey, key) {
+			tx.Del(oldKey)
+		}
+	}
+	tx.Set(key, []byte{})
+}
+
+// orderedChildIDs returns the IDs of a parent's children in document order;
+// an empty parentID gives the roots
+func (ss *SimpleStore) orderedChildIDs(policyID, parentID string) ([]string, error) {
+	scope := []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(parentID)),
+	}
+	ordered, err := scanNodeIDs(ss.kv, storage.EncodeKey(PREFIX_SIBLING_ORDER, scope), 4)
+	if err != nil {
+		return nil, err
+	}
+	indexed, err := scanNodeIDs(ss.kv, storage.EncodeKey(PREFIX_CHILDREN, scope), 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(ordered) == len(indexed) {
+		return ordered, nil
+	}
+
+	// Some children predate the order index, so sort them as it would
+	var pid *string
+	if parentID != "" {
+		pid = &parentID
+	}
+	children, err := ss.WithoutFields(FieldSummary|FieldText).GetChildren(policyID, pid)
+	if err != nil {
+		return nil, err
+	}
+	sortSiblings(children)
+	ids := make([]string, len(children))
+	for i, child := range children {
+		ids[i] = child.NodeID
+	}
+	return ids, nil
+}
+
+// GetSiblings returns the children of a node's parent in document order, the
+// node among them, and the node's position in the list. The siblings of a
+// root are the policy's roots.
+func (ss *SimpleStore) GetSiblings(policyID, nodeID string) ([]*Node, int, error) {
+	node, err := ss.WithoutFields(FieldSummary|FieldText).GetNode(policyID, nodeID)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids, err := ss.orderedChildIDs(policyID, parentOf(node))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	nodes, err := ss.GetNodes(policyID, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	siblings := make([]*Node, 0, len(nodes))
+	position := -1
+	for _, sibling := range nodes {
+		if sibling == nil {
+			continue
+		}
+		if sibling.NodeID == nodeID {
+			position = len(siblings)
+		}
+		siblings = append(siblings, sibling)
+	}
+	if position < 0 {
+		return nil, 0, fmt.Errorf("node %s/%s is missing from its parent's children index", policyID, nodeID)
+	}
+	return siblings, position, nil
+}
+
+// GetNextSection returns the section that follows a node in document order,
+// reading depth first: its first child, else its next sibling, else the next
+// sibling of its nearest ancestor that has one. It returns nil at the end of
+// the document.
+func (ss *SimpleStore) GetNextSection(policyID, nodeID string) (*Node, error) {
+	walk := ss.WithoutFields(FieldSummary | FieldText)
+	node, err := walk.GetNode(policyID, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := ss.orderedChildIDs(policyID, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(children) > 0 {
+		return ss.GetNode(policyID, children[0])
+	}
+
+	visited := map[string]bool{nodeID: true}
+	for {
+		siblings, err := ss.orderedChildIDs(policyID, parentOf(node))
+		if err != nil {
+			return nil, err
+		}
+		if i := indexOf(siblings, node.NodeID); i >= 0 && i+1 < len(siblings) {
+			return ss.GetNode(policyID, siblings[i+1])
+		}
+		if node, err = nextAncestor(walk, node, visited); node == nil || err != nil {
+			return nil, err
+		}
+	}
+}
+
+// GetPreviousSection returns the section that precedes a node in document
+// order: the last descendant of its previous sibling, else its parent. It
+// returns nil at the start of the document.
+func (ss *SimpleStore) GetPreviousSection(policyID, nodeID string) (*Node, error) {
+	node, err := ss.WithoutFields(FieldSummary|FieldText).GetNode(policyID, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	siblings, err := ss.orderedChildIDs(policyID, parentOf(node))
+	if err != nil {
+		return nil, err
+	}
+	i := indexOf(siblings, nodeID)
+	if i < 0 {
+		return nil, fmt.Errorf("node %s/%s is missing from its parent's children index", policyID, nodeID)
+	}
+	if i == 0 {
+		if parentOf(node) == "" {
+			return nil, nil
+		}
+		return ss.GetNode(policyID, parentOf(node))
+	}
+
+	// Descend through the last children of the previous sibling
+	last := siblings[i-1]
+	visited := map[string]bool{nodeID: true}
+	for !visited[last] {
+		visited[last] = true
+		children, err := ss.orderedChildIDs(policyID, last)
+		if err != nil {
+			return nil, err
+		}
+		if len(children) == 0 {
+			break
+		}
+		last = children[len(children)-1]
+	}
+	return ss.GetNode(policyID, last)
+}
+
+// nextAncestor returns a node's parent, nil for a root, failing if the walk
+// comes back to a node it has visited
+func nextAncestor(walk *SimpleStore, node *Node, visited map[string]bool) (*Node, error) {
+	parentID := parentOf(node)
+	if parentID == "" {
+		return nil, nil
+	}
+	if visited[parentID] {
+		return nil, fmt.Errorf("parent links of %s/%s form a cycle", node.PolicyID, node.NodeID)
+	}
+	visited[parentID] = true
+
+	parent, err := walk.GetNode(node.PolicyID, parentID)
+	if errors.Is(err, ErrNodeNotFound) {
+		return nil, nil // An orphan ends the document
+	}
+	return parent, err
+}
+
+// indexOf returns the position of id in ids, or -1
+func indexOf(ids []string, id string) int {
+	for i, v := range ids {
+		if v == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,98 @@
+// ABOUTME: Tests for sibling order and section navigation
+// ABOUTME: Verifies document order across levels and roots, reordering on update and the fallback for older nodes
+
+package document
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestSectionNavigation(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	// Node IDs run against document order, which follows pages and section paths
+	root, s1, s2 := "root", "z-1", "a-2"
+	nodes := []*Node{
+		{NodeID: root, PolicyID: "LCD-1", SectionPath: "1", PageStart: 1},
+		{NodeID: s2, PolicyID: "LCD-1", ParentID: &root, SectionPath: "1.10", PageStart: 4},
+		{NodeID: s1, PolicyID: "LCD-1", ParentID: &root, SectionPath: "1.9", PageStart: 4},
+		{NodeID: "y-1.1", PolicyID: "LCD-1", ParentID: &s1, SectionPath: "1.9.1", PageStart: 4},
+		{NodeID: "b-1.2", PolicyID: "LCD-1", ParentID: &s1, SectionPath: "1.9.2", PageStart: 5},
+		{NodeID: "appendix", PolicyID: "LCD-1", SectionPath: "2", PageStart: 9},
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	walk := func(step func(policyID, nodeID string) (*Node, error), from string) string {
+		var ids []string
+		for id := from; ; {
+			node, err := step("LCD-1", id)
+			if err != nil {
+				t.Fatalf("Step from %s failed: %v", id, err)
+			}
+			if node == nil {
+				return fmt.Sprint(ids)
+			}
+			id = node.NodeID
+			ids = append(ids, id)
+		}
+	}
+	if got := walk(ds.GetNextSection, root); got != "[z-1 y-1.1 b-1.2 a-2 appendix]" {
+		t.Errorf("Unexpected forward walk %s", got)
+	}
+	if got := walk(ds.GetPreviousSection, "appendix"); got != "[a-2 b-1.2 y-1.1 z-1 root]" {
+		t.Errorf("Unexpected backward walk %s", got)
+	}
+
+	siblings, position, err := ds.GetSiblings("LCD-1", s2)
+	if err != nil || len(siblings) != 2 || siblings[0].NodeID != s1 || position != 1 {
+		t.Fatalf("Expected [z-1 a-2] at 1, got %v at %d, %v", siblings, position, err)
+	}
+	if roots, position, _ := ds.GetSiblings("LCD-1", "appendix"); len(roots) != 2 || position != 1 {
+		t.Errorf("Expected the appendix second among the roots, got %v at %d", roots, position)
+	}
+
+	// Renumbering a section moves it among its siblings
+	moved, _ := ds.GetNode("LCD-1", s2)
+	moved.SectionPath = "1.8"
+	if err := ds.UpdateNode(moved); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if siblings, position, _ := ds.GetSiblings("LCD-1", s2); siblings[1].NodeID != s1 || position != 0 {
+		t.Errorf("Expected a-2 first after renumbering, got %v at %d", siblings, position)
+	}
+
+	// Nodes written before the order index are sorted until Reindex adds them
+	tx := kv.Begin()
+	tx.Del(siblingOrderKey(&Node{PolicyID: "LCD-1", NodeID: "b-1.2", ParentID: &s1, SectionPath: "1.9.2", PageStart: 5}))
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if next, err := ds.GetNextSection("LCD-1", "y-1.1"); err != nil || next.NodeID != "b-1.2" {
+		t.Errorf("Expected b-1.2 from the children index, got %v, %v", next, err)
+	}
+	if _, err := ds.Reindex("LCD-1"); err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if ids, _ := ds.orderedChildIDs("LCD-1", s1); fmt.Sprint(ids) != "[y-1.1 b-1.2]" {
+		t.Errorf("Expected Reindex to restore the order entry, got %v", ids)
+	}
+}
+
+func TestSectionKeyOrder(t *testing.T) {
+	paths := []string{"", "1", "1.2", "1.9", "1.10", "1.10.1", "1.a", "2", "10", "A", "A.1", "Ab"}
+	for i := 1; i < len(paths); i++ {
+		a, b := paths[i-1], paths[i]
+		if compareSectionPaths(a, b) >= 0 {
+			t.Errorf("Expected %q before %q", a, b)
+		}
+		if string(sectionKey(a)) >= string(sectionKey(b)) {
+			t.Errorf("Expected the key of %q before that of %q", a, b)
+		}
+	}
+}
@@ -4,9 +4,14 @@
 package document
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 
+	"github.com/nainya/treestore/pkg/changefeed"
 	"github.com/nainya/treestore/pkg/storage"
 )
 
@@ -19,79 +24,363 @@ const (
 	PREFIX_PAGE     = uint32(5000)
 )
 
+var (
+	// ErrVersionConflict is returned when a node changed since the caller read it
+	ErrVersionConflict = errors.New("document: node version conflict")
+
+	// ErrNodeNotFound is returned when a node does not exist
+	ErrNodeNotFound = errors.New("document: node not found")
+)
+
 // SimpleStore manages documents with direct KV access
 type SimpleStore struct {
-	kv *storage.KV
+	kv     storage.Engine
+	feed   *changefeed.Feed // Optional; nil publishes nothing
+	mu     *sync.Mutex      // Serializes writes so version checks cannot interleave; shared by views
+	blooms *bloomSet        // Per-policy node ID filters; shared by views
+	paths  *pathCache       // Ancestor IDs of recently read nodes; shared by views
+	omit   NodeFields       // Fields node reads leave empty; set on views by WithoutFields
+	tokens TokenEstimator   // Counts the tokens of node text on write; nil uses EstimateTokens
 }
 
 // NewSimpleStore creates a simplified document store
-func NewSimpleStore(kv *storage.KV) *SimpleStore {
-	return &SimpleStore{kv: kv}
+func NewSimpleStore(kv storage.Engine) *SimpleStore {
+	return &SimpleStore{kv: kv, mu: &sync.Mutex{}, blooms: newBloomSet(), paths: newPathCache()}
+}
+
+// WithContext returns a view of the store whose reads stop scanning once ctx
+// is done, failing with ctx.Err()
+func (ss *SimpleStore) WithContext(ctx context.Context) *SimpleStore {
+	view := *ss
+	view.kv = storage.WithContext(ctx, ss.kv)
+	return &view
+}
+
+// WithoutFields returns a view of the store whose node reads leave the given
+// fields empty without decoding them, for callers that only navigate the tree
+func (ss *SimpleStore) WithoutFields(fields NodeFields) *SimpleStore {
+	view := *ss
+	view.omit |= fields
+	return &view
+}
+
+// SetChangeFeed publishes committed writes to feed; call before the store is shared
+func (ss *SimpleStore) SetChangeFeed(feed *changefeed.Feed) {
+	ss.feed = feed
+}
+
+// SetTokenEstimator counts the tokens of node text with estimate on write,
+// such as a model's tokenizer; call before the store is shared
+func (ss *SimpleStore) SetTokenEstimator(estimate TokenEstimator) {
+	ss.tokens = estimate
+}
+
+// estimator returns the store's token estimator
+func (ss *SimpleStore) estimator() TokenEstimator {
+	if ss.tokens == nil {
+		return EstimateTokens
+	}
+	return ss.tokens
 }
 
 // StoreDocument stores a document and nodes atomically
+// Every node is written unconditionally; its Version is set to one past the
+// stored version, and its TokenCount to the estimated tokens of its text.
 func (ss *SimpleStore) StoreDocument(doc *Document, nodes []*Node) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
 	tx := ss.kv.Begin()
 
-	// Store each node with composite key (policyID, nodeID)
+	created := make(map[string][]string)
+	moved := make(map[string]bool)
 	for _, node := range nodes {
-		key := storage.EncodeKey(PREFIX_NODE, []storage.Value{
-			storage.NewBytesValue([]byte(node.PolicyID)),
-			storage.NewBytesValue([]byte(node.NodeID)),
-		})
+		old := loadNode(tx, node.PolicyID, node.NodeID)
+		node.TokenCount = ss.estimator()(node.Text)
+		node.Version = 1
+		if old != nil {
+			node.Version = old.Version + 1
+			if !sameParent(old.ParentID, node.ParentID) {
+				moved[node.PolicyID] = true
+			}
+		} else {
+			created[node.PolicyID] = append(created[node.PolicyID], node.NodeID)
+		}
+		putNode(tx, old, node)
+	}
+
+	err := ss.commitCreated(tx, created)
+	ss.paths.drop(moved)
+	if err != nil {
+		return err
+	}
+
+	ss.feed.Publish(changefeed.EntityDocument, changefeed.OpPut, doc.PolicyID, "")
+	return nil
+}
 
-		parentID := ""
-		if node.ParentID != nil {
-			parentID = *node.ParentID
+// UpdateNode replaces a stored node if it has not changed since it was read
+// node.Version must equal the stored version, otherwise ErrVersionConflict is
+// returned and nothing is written. On success node.Version is incremented and
+// node.TokenCount recounted.
+func (ss *SimpleStore) UpdateNode(node *Node) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	tx := ss.kv.Begin()
+	defer tx.Abort()
+
+	old := loadNode(tx, node.PolicyID, node.NodeID)
+	if err := tx.Err(); err != nil {
+		return err
+	}
+	if old == nil {
+		return fmt.Errorf("%w: %s/%s", ErrNodeNotFound, node.PolicyID, node.NodeID)
+	}
+	if old.Version != node.Version {
+		return fmt.Errorf("%w: %s/%s is at version %d, not %d",
+			ErrVersionConflict, node.PolicyID, node.NodeID, old.Version, node.Version)
+	}
+
+	updated := *node
+	updated.Version = old.Version + 1
+	updated.TokenCount = ss.estimator()(updated.Text)
+	putNode(tx, old, &updated)
+
+	err := tx.Commit()
+	if !sameParent(old.ParentID, updated.ParentID) {
+		ss.paths.drop(map[string]bool{node.PolicyID: true})
+	}
+	if err != nil {
+		return err
+	}
+
+	node.Version = updated.Version
+	node.TokenCount = updated.TokenCount
+	node.Checksum = updated.Checksum
+	node.SummaryChecksum = updated.SummaryChecksum
+	ss.feed.Publish(changefeed.EntityDocument, changefeed.OpPut, node.PolicyID, node.NodeID)
+	return nil
+}
+
+// Counts returns the number of documents, the policies with at least one
+// stored node, and the number of nodes, from one pass over the nodes
+func (ss *SimpleStore) Counts() (documents, nodes int64, err error) {
+	var last string
+	err = storage.ScanPrefix(ss.kv, storage.EncodeKey(PREFIX_NODE, nil), func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 2 {
+			return true
+		}
+		// Nodes are keyed by policy first, so each policy's nodes are adjacent
+		if policyID := string(vals[0].Str); documents == 0 || policyID != last {
+			documents++
+			last = policyID
 		}
+		nodes++
+		return true
+	})
+	return documents, nodes, err
+}
+
+// ListPolicies returns up to limit policies with stored nodes, in order,
+// starting after the policy ID after; limit <= 0 returns all. It seeks past
+// each policy's nodes rather than reading them.
+func (ss *SimpleStore) ListPolicies(after string, limit int) ([]string, error) {
+	start := storage.EncodeKey(PREFIX_NODE, nil)
+	if after != "" {
+		start = storage.EncodeKeyPartial(PREFIX_NODE, []storage.Value{
+			storage.NewBytesValue([]byte(after)),
+		}, storage.CMP_GT)
+	}
 
-		val := storage.EncodeValues([]storage.Value{
-			storage.NewBytesValue([]byte(node.PolicyID)),
-			storage.NewBytesValue([]byte(node.NodeID)),
-			storage.NewBytesValue([]byte(parentID)),
-			storage.NewBytesValue([]byte(node.Title)),
-			storage.NewInt64Value(int64(node.PageStart)),
-			storage.NewInt64Value(int64(node.PageEnd)),
-			storage.NewBytesValue([]byte(node.Summary)),
-			storage.NewBytesValue([]byte(node.Text)),
-			storage.NewBytesValue([]byte(node.SectionPath)),
-			storage.NewInt64Value(int64(node.Depth)),
-			storage.NewTimeValue(node.CreatedAt),
-			storage.NewTimeValue(node.UpdatedAt),
+	var policies []string
+	for limit <= 0 || len(policies) < limit {
+		found := false
+		err := storage.ScanPrefixFrom(ss.kv, storage.EncodeKey(PREFIX_NODE, nil), start, func(key, val []byte) bool {
+			vals, err := storage.ExtractValues(key)
+			if err != nil || len(vals) < 2 {
+				return true
+			}
+			policies = append(policies, string(vals[0].Str))
+			found = true
+			return false
 		})
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			break
+		}
+		start = storage.EncodeKeyPartial(PREFIX_NODE, []storage.Value{
+			storage.NewBytesValue([]byte(policies[len(policies)-1])),
+		}, storage.CMP_GT)
+	}
+	return policies, nil
+}
 
-		tx.Set(key, val)
+// nodeKey returns the primary key of a node
+func nodeKey(policyID, nodeID string) []byte {
+	return storage.EncodeKey(PREFIX_NODE, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(nodeID)),
+	})
+}
 
-		// Create secondary index for children lookup
-		childKey := storage.EncodeKey(PREFIX_CHILDREN, []storage.Value{
-			storage.NewBytesValue([]byte(node.PolicyID)),
-			storage.NewBytesValue([]byte(parentID)),
-			storage.NewBytesValue([]byte(node.NodeID)),
-		})
-		tx.Set(childKey, []byte{})
+// childKey returns the children index key of a node under its parent
+func childKey(policyID string, parentID *string, nodeID string) []byte {
+	pid := ""
+	if parentID != nil {
+		pid = *parentID
+	}
+	return storage.EncodeKey(PREFIX_CHILDREN, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(pid)),
+		storage.NewBytesValue([]byte(nodeID)),
+	})
+}
+
+// loadNode reads a node within a transaction, returning nil if it is absent or unreadable
+func loadNode(tx storage.Txn, policyID, nodeID string) *Node {
+	val, ok := tx.Get(nodeKey(policyID, nodeID))
+	if !ok {
+		return nil
+	}
+	node, separate, err := decodeStoredNode(val, nil)
+	if err != nil {
+		return nil
+	}
+	if separate {
+		text, _ := tx.Get(textKey(policyID, nodeID))
+		node.Text = string(text)
+	}
+	return node
+}
+
+// putNode writes a node with its text and its index entries
+// old is the previously stored node, if any, so stale entries can be removed.
+func putNode(tx storage.Txn, old, node *Node) {
+	writeNode(tx, old, node)
+
+	// Postings depend on the text fields only, so structural changes keep them
+	if old == nil || old.Title != node.Title || old.Summary != node.Summary || old.Text != node.Text {
+		indexNode(tx, old, node)
 	}
 
-	return tx.Commit()
+	// Move the children index entry if the node changed parents
+	if old != nil && !sameParent(old.ParentID, node.ParentID) {
+		tx.Del(childKey(old.PolicyID, old.ParentID, old.NodeID))
+	}
+	tx.Set(childKey(node.PolicyID, node.ParentID, node.NodeID), []byte{})
+	putRoot(tx, old, node)
+	putSiblingOrder(tx, old, node)
+}
+
+// writeNode writes a node's record, with its text in a record of its own and
+// its checksum indexed
+func writeNode(tx storage.Txn, old, node *Node) {
+	// A summary kept from old stays written for the text it was, except that a
+	// current one stays current when a node is rewritten in place
+	kept := old != nil && old.Summary == node.Summary && (old != node || old.SummaryChecksum != old.Checksum)
+	summaryFor := ""
+	if kept {
+		summaryFor = old.SummaryChecksum
+	}
+	writeChecksum(tx, old, node)
+	switch {
+	case node.Summary == "":
+		node.SummaryChecksum = ""
+	case kept:
+		node.SummaryChecksum = summaryFor
+	default:
+		node.SummaryChecksum = node.Checksum
+	}
+	tx.Set(nodeKey(node.PolicyID, node.NodeID), encodeNode(node))
+	writeText(tx, old, node)
+}
+
+// sameParent reports whether two parent IDs refer to the same node
+func sameParent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 // GetNode retrieves a node by ID
 func (ss *SimpleStore) GetNode(policyID, nodeID string) (*Node, error) {
+	if !ss.blooms.mayContain(ss.kv, policyID, nodeID) {
+		return nil, fmt.Errorf("%w: %s/%s", ErrNodeNotFound, policyID, nodeID)
+	}
+
 	key := storage.EncodeKey(PREFIX_NODE, []storage.Value{
 		storage.NewBytesValue([]byte(policyID)),
 		storage.NewBytesValue([]byte(nodeID)),
 	})
 
-	val, ok := ss.kv.Get(key)
+	val, ok, err := ss.kv.Lookup(key)
+	if err != nil {
+		return nil, err
+	}
 	if !ok {
-		return nil, fmt.Errorf("node not found: %s/%s", policyID, nodeID)
+		return nil, fmt.Errorf("%w: %s/%s", ErrNodeNotFound, policyID, nodeID)
+	}
+
+	node, separate, err := ss.decodeNode(val)
+	if err != nil {
+		return nil, err
+	}
+	if separate {
+		if err := ss.readTexts([]*Node{node}); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// GetNodes retrieves several nodes of a policy with a single batched lookup
+// The result is aligned with nodeIDs; missing nodes are left nil
+func (ss *SimpleStore) GetNodes(policyID string, nodeIDs []string) ([]*Node, error) {
+	// Only IDs the policy's filter cannot rule out are looked up
+	var keys [][]byte
+	var at []int
+	for i, nodeID := range nodeIDs {
+		if !ss.blooms.mayContain(ss.kv, policyID, nodeID) {
+			continue
+		}
+		keys = append(keys, storage.EncodeKey(PREFIX_NODE, []storage.Value{
+			storage.NewBytesValue([]byte(policyID)),
+			storage.NewBytesValue([]byte(nodeID)),
+		}))
+		at = append(at, i)
 	}
 
-	vals, err := storage.DecodeValues(val)
+	vals, found, err := ss.kv.LookupBatch(keys)
 	if err != nil {
 		return nil, err
 	}
 
-	return parseNodeVals(vals)
+	nodes := make([]*Node, len(nodeIDs))
+	var texts []*Node
+	for j, i := range at {
+		if !found[j] {
+			continue
+		}
+
+		node, separate, err := ss.decodeNode(vals[j])
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = node
+		if separate {
+			texts = append(texts, node)
+		}
+	}
+
+	if err := ss.readTexts(texts); err != nil {
+		return nil, err
+	}
+	return nodes, nil
 }
 
 // GetChildren returns children of a parent node
@@ -108,34 +397,53 @@ func (ss *SimpleStore) GetChildren(policyID string, parentID *string) ([]*Node,
 	})
 
 	var children []*Node
-	ss.kv.Scan(startKey, func(key, val []byte) bool {
+	var nodeErr error
+	err := storage.ScanPrefix(ss.kv, startKey, func(key, val []byte) bool {
 		// Extract nodeID from key
 		vals, err := storage.ExtractValues(key)
 		if err != nil || len(vals) < 3 {
 			return true
 		}
 
-		// Check if still in same policy/parent
-		if string(vals[0].Str) != policyID || string(vals[1].Str) != pid {
-			return false
-		}
-
-		// Get full node
+		// Get full node, skipping index entries left behind by a removed node
 		nodeID := string(vals[2].Str)
 		node, err := ss.GetNode(policyID, nodeID)
-		if err == nil {
-			children = append(children, node)
+		if errors.Is(err, ErrNodeNotFound) {
+			return true
+		}
+		if err != nil {
+			nodeErr = err
+			return false
 		}
+		children = append(children, node)
 
 		return true
 	})
+	if err == nil {
+		err = nodeErr
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	return children, nil
 }
 
-// GetSubtree retrieves a subtree
+// GetSubtree retrieves a subtree level by level, each level's nodes in the
+// order of their parents. With opts.Workers above 1 the children of that many
+// parents of a level are fetched concurrently, which needs an engine whose
+// reads can run in parallel. Reads are not taken from one snapshot, so a
+// subtree read while its policy is written may mix nodes from before and
+// after a write, with workers or without; otherwise the result is the same.
+// An empty nodeID starts at the document's root, as GetRoot finds it.
 func (ss *SimpleStore) GetSubtree(policyID, nodeID string, opts QueryOptions) ([]*Node, error) {
-	root, err := ss.GetNode(policyID, nodeID)
+	var root *Node
+	var err error
+	if nodeID == "" {
+		root, err = ss.GetRoot(policyID)
+	} else {
+		root, err = ss.GetNode(policyID, nodeID)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -145,14 +453,13 @@ func (ss *SimpleStore) GetSubtree(policyID, nodeID string, opts QueryOptions) ([
 	toVisit := []*Node{root}
 
 	for len(toVisit) > 0 && (opts.MaxDepth == 0 || currentDepth < opts.MaxDepth) {
-		nextLevel := []*Node{}
-
-		for _, parent := range toVisit {
-			children, err := ss.GetChildren(policyID, &parent.NodeID)
-			if err != nil {
-				continue
-			}
+		levels, err := ss.fetchChildren(policyID, toVisit, opts.Workers)
+		if err != nil {
+			return nil, err
+		}
 
+		nextLevel := []*Node{}
+		for _, children := range levels {
 			nodes = append(nodes, children...)
 			nextLevel = append(nextLevel, children...)
 		}
@@ -164,30 +471,100 @@ func (ss *SimpleStore) GetSubtree(policyID, nodeID string, opts QueryOptions) ([
 	return nodes, nil
 }
 
-// GetAncestorPath returns path from root to node
-func (ss *SimpleStore) GetAncestorPath(policyID, nodeID string) ([]*Node, error) {
-	var path []*Node
+// fetchChildren returns the children of each parent, aligned with parents,
+// using up to workers goroutines. On failure it returns the error of the
+// first parent that failed, so the outcome does not depend on scheduling.
+func (ss *SimpleStore) fetchChildren(policyID string, parents []*Node, workers int) ([][]*Node, error) {
+	children := make([][]*Node, len(parents))
+	errs := make([]error, len(parents))
+	if workers > len(parents) {
+		workers = len(parents)
+	}
 
-	currentID := nodeID
-	for currentID != "" {
-		node, err := ss.GetNode(policyID, currentID)
+	if workers <= 1 {
+		for i, parent := range parents {
+			if children[i], errs[i] = ss.GetChildren(policyID, &parent.NodeID); errs[i] != nil {
+				return nil, errs[i]
+			}
+		}
+		return children, nil
+	}
+
+	var next atomic.Int64
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !failed.Load() {
+				i := int(next.Add(1) - 1)
+				if i >= len(parents) {
+					return
+				}
+				if children[i], errs[i] = ss.GetChildren(policyID, &parents[i].NodeID); errs[i] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
 		if err != nil {
 			return nil, err
 		}
+	}
+	return children, nil
+}
 
-		path = append([]*Node{node}, path...)
+// GetAncestorPath returns path from root to node
+// The walk up stops at the first node whose ancestor IDs are cached, which
+// are then read in one batch; see SetPathCache.
+func (ss *SimpleStore) GetAncestorPath(policyID, nodeID string) ([]*Node, error) {
+	gen := ss.paths.generation()
 
-		if node.ParentID == nil {
+	var walked []*Node // From the node upward
+	var cached []*Node // From the root to where the walk stopped
+	visited := make(map[string]bool)
+	for currentID := nodeID; currentID != ""; {
+		if cached = ss.cachedPath(policyID, currentID); cached != nil {
 			break
 		}
-		currentID = *node.ParentID
+		if visited[currentID] {
+			return nil, fmt.Errorf("parent links of %s/%s form a cycle", policyID, nodeID)
+		}
+		visited[currentID] = true
+
+		node, err := ss.GetNode(policyID, currentID)
+		if err != nil {
+			return nil, err
+		}
+		walked = append(walked, node)
+		currentID = parentOf(node)
+	}
+	if len(walked) == 0 {
+		ss.paths.hits.Add(1)
+	} else {
+		ss.paths.misses.Add(1)
 	}
 
+	path := cached
+	for i := len(walked) - 1; i >= 0; i-- {
+		path = append(path, walked[i])
+	}
+	ss.cachePath(gen, path, len(cached))
 	return path, nil
 }
 
 // Search performs simple text search
 func (ss *SimpleStore) Search(policyID, query string, limit int) ([]*SearchResult, error) {
+	return ss.SearchFiltered(policyID, query, limit, SearchFilter{})
+}
+
+// SearchFiltered performs text search, keeping only nodes that pass the filter
+// Filtering happens during the scan so that limit counts filtered hits
+func (ss *SimpleStore) SearchFiltered(policyID, query string, limit int, filter SearchFilter) ([]*SearchResult, error) {
 	terms := strings.Fields(strings.ToLower(query))
 
 	startKey := storage.EncodeKey(PREFIX_NODE, []storage.Value{
@@ -196,8 +573,9 @@ func (ss *SimpleStore) Search(policyID, query string, limit int) ([]*SearchResul
 
 	var results []*SearchResult
 	count := 0
+	var textErr error
 
-	ss.kv.Scan(startKey, func(key, val []byte) bool {
+	err := storage.ScanPrefix(ss.kv, startKey, func(key, val []byte) bool {
 		if count >= limit {
 			return false
 		}
@@ -207,38 +585,88 @@ func (ss *SimpleStore) Search(policyID, query string, limit int) ([]*SearchResul
 			return true
 		}
 
-		if string(vals[0].Str) != policyID {
-			return false
-		}
-
-		nodeVals, err := storage.DecodeValues(val)
+		node, separate, err := decodeStoredNode(val, nil)
 		if err != nil {
 			return true
 		}
+		if separate {
+			if textErr = ss.readTexts([]*Node{node}); textErr != nil {
+				return false
+			}
+		}
 
-		node, err := parseNodeVals(nodeVals)
-		if err != nil {
+		if !filter.matches(node) {
 			return true
 		}
 
 		score := scoreNode(node, terms)
 		if score > 0 {
+			snippet, _ := NodeSnippet(node, query, DefaultSnippetLength)
 			results = append(results, &SearchResult{
 				NodeID:   node.NodeID,
 				PolicyID: node.PolicyID,
 				Title:    node.Title,
 				Summary:  node.Summary,
 				Score:    score,
+				Snippet:  snippet,
 			})
 			count++
 		}
 
 		return true
 	})
+	if err == nil {
+		err = textErr
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	return results, nil
 }
 
+// matches reports whether a node passes every filter criterion
+func (f SearchFilter) matches(node *Node) bool {
+	if f.PageFrom > 0 && node.PageEnd < f.PageFrom {
+		return false
+	}
+	if f.PageTo > 0 && node.PageStart > f.PageTo {
+		return false
+	}
+	if f.MaxDepth != nil && node.Depth > *f.MaxDepth {
+		return false
+	}
+	if f.SectionPathPrefix != "" && !strings.HasPrefix(node.SectionPath, f.SectionPathPrefix) {
+		return false
+	}
+	if f.Match != nil && !f.Match(node) {
+		return false
+	}
+	return true
+}
+
+// Positions of the large columns in a node stored as a tuple of values; skip
+// functions name columns by them for records too
+const (
+	nodeColumnSummary = 6
+	nodeColumnText    = 7
+)
+
+// decodeNode decodes a stored node, skipping the fields the store omits;
+// separate reports whether its text is still to be read from its text record
+func (ss *SimpleStore) decodeNode(val []byte) (node *Node, separate bool, err error) {
+	var skip func(i int) bool
+	if ss.omit != 0 {
+		skip = func(i int) bool {
+			return i == nodeColumnSummary && ss.omit&FieldSummary != 0 ||
+				i == nodeColumnText && ss.omit&FieldText != 0
+		}
+	}
+	node, separate, err = decodeStoredNode(val, skip)
+	return node, separate && ss.omit&FieldText == 0, err
+}
+
+// parseNodeVals reads a node stored as a tuple of values, before records
 func parseNodeVals(vals []storage.Value) (*Node, error) {
 	if len(vals) < 12 {
 		return nil, fmt.Errorf("incomplete node data")
@@ -258,6 +686,11 @@ func parseNodeVals(vals []storage.Value) (*Node, error) {
 		UpdatedAt:   vals[11].Time,
 	}
 
+	// Nodes written before versioning was added read as version 0
+	if len(vals) > 12 {
+		node.Version = uint64(vals[12].I64)
+	}
+
 	if len(vals[2].Str) > 0 {
 		pid := string(vals[2].Str)
 		node.ParentID = &pid
@@ -0,0 +1,171 @@
+// ABOUTME: Snippet extraction for search results
+// ABOUTME: Picks a text window around query matches and reports highlight offsets
+
+package document
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nainya/treestore/pkg/textindex"
+)
+
+// DefaultSnippetLength is the snippet size in bytes when none is requested
+const DefaultSnippetLength = 160
+
+// snippetEllipsis marks text trimmed from either side of a snippet
+const snippetEllipsis = "..."
+
+// Highlight marks a matched term inside a snippet as a byte range [Start, End)
+type Highlight struct {
+	Start int
+	End   int
+}
+
+// NodeSnippet builds a snippet for a node from the first field matching the query
+// Text is preferred, then summary, then title; with no match the text prefix is used
+func NodeSnippet(node *Node, query string, length int) (string, []Highlight) {
+	terms := textindex.Tokenize(query)
+	for _, field := range []string{node.Text, node.Summary, node.Title} {
+		if firstMatch(foldText(field), terms) >= 0 {
+			return BuildSnippet(field, terms, length)
+		}
+	}
+
+	text := node.Text
+	if text == "" {
+		text = node.Summary
+	}
+	return BuildSnippet(text, terms, length)
+}
+
+// BuildSnippet extracts a window of roughly length bytes around the first term match
+// Matches are case-insensitive; highlight offsets refer to the returned snippet
+func BuildSnippet(text string, terms []string, length int) (string, []Highlight) {
+	if length <= 0 {
+		length = DefaultSnippetLength
+	}
+
+	folded := foldText(text)
+	start, end := 0, len(text)
+	if len(text) > length {
+		if first := firstMatch(folded, terms); first > 0 {
+			// Keep some leading context before the first match
+			start = first - length/4
+			if start < 0 {
+				start = 0
+			}
+		}
+		end = start + length
+		if end > len(text) {
+			end = len(text)
+			start = end - length
+		}
+		start, end = snippetBounds(text, start, end)
+	}
+
+	var b strings.Builder
+	offset := 0
+	if start > 0 {
+		b.WriteString(snippetEllipsis)
+		offset = len(snippetEllipsis)
+	}
+	b.WriteString(text[start:end])
+	if end < len(text) {
+		b.WriteString(snippetEllipsis)
+	}
+
+	highlights := findHighlights(folded[start:end], terms)
+	for i := range highlights {
+		highlights[i].Start += offset
+		highlights[i].End += offset
+	}
+
+	return b.String(), highlights
+}
+
+// foldText lowercases text for matching, keeping byte offsets aligned with the original
+func foldText(text string) string {
+	folded := strings.ToLower(text)
+	if len(folded) != len(text) {
+		// Case folding changed byte lengths; match case-sensitively instead
+		return text
+	}
+	return folded
+}
+
+// firstMatch returns the earliest byte offset of any term, or -1
+func firstMatch(folded string, terms []string) int {
+	first := -1
+	for _, term := range terms {
+		if idx := strings.Index(folded, term); idx >= 0 && (first < 0 || idx < first) {
+			first = idx
+		}
+	}
+	return first
+}
+
+// snippetBounds shrinks [start, end) to rune and word boundaries
+func snippetBounds(text string, start, end int) (int, int) {
+	for start < end && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	for end > start && end < len(text) && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	if start > 0 {
+		if idx := strings.IndexByte(text[start:end], ' '); idx >= 0 && idx < (end-start)/4 {
+			start += idx + 1
+		}
+	}
+	if end < len(text) {
+		if idx := strings.LastIndexByte(text[start:end], ' '); idx >= 0 && idx > (end-start)*3/4 {
+			end = start + idx
+		}
+	}
+
+	return start, end
+}
+
+// findHighlights locates every term occurrence, merging overlapping ranges
+func findHighlights(folded string, terms []string) []Highlight {
+	var highlights []Highlight
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		for pos := 0; ; {
+			idx := strings.Index(folded[pos:], term)
+			if idx < 0 {
+				break
+			}
+			start := pos + idx
+			highlights = append(highlights, Highlight{Start: start, End: start + len(term)})
+			pos = start + len(term)
+		}
+	}
+
+	if len(highlights) == 0 {
+		return nil
+	}
+
+	sort.Slice(highlights, func(i, j int) bool {
+		return highlights[i].Start < highlights[j].Start
+	})
+
+	merged := highlights[:1]
+	for _, h := range highlights[1:] {
+		last := &merged[len(merged)-1]
+		if h.Start <= last.End {
+			if h.End > last.End {
+				last.End = h.End
+			}
+			continue
+		}
+		merged = append(merged, h)
+	}
+
+	return merged
+}
@@ -0,0 +1,77 @@
+// ABOUTME: Tests for search snippet extraction
+// ABOUTME: Verifies windowing around matches and highlight offsets
+
+package document
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildSnippetShortText(t *testing.T) {
+	snippet, highlights := BuildSnippet("Prior authorization is required", []string{"authorization"}, 100)
+
+	if snippet != "Prior authorization is required" {
+		t.Errorf("Expected full text, got %q", snippet)
+	}
+	if len(highlights) != 1 {
+		t.Fatalf("Expected 1 highlight, got %d", len(highlights))
+	}
+	if got := snippet[highlights[0].Start:highlights[0].End]; got != "authorization" {
+		t.Errorf("Expected highlight on 'authorization', got %q", got)
+	}
+}
+
+func TestBuildSnippetWindow(t *testing.T) {
+	text := strings.Repeat("filler words here ", 30) + "CT Scans need prior review. " + strings.Repeat("more trailing text ", 30)
+
+	snippet, highlights := BuildSnippet(text, []string{"ct", "scans"}, 80)
+
+	if len(snippet) > 80+2*len(snippetEllipsis) {
+		t.Errorf("Snippet too long: %d bytes", len(snippet))
+	}
+	if !strings.HasPrefix(snippet, snippetEllipsis) || !strings.HasSuffix(snippet, snippetEllipsis) {
+		t.Errorf("Expected ellipsis on both sides, got %q", snippet)
+	}
+	if !strings.Contains(snippet, "CT Scans") {
+		t.Errorf("Expected snippet to contain the match, got %q", snippet)
+	}
+
+	// Highlights keep original casing and point into the snippet
+	var matched []string
+	for _, h := range highlights {
+		matched = append(matched, snippet[h.Start:h.End])
+	}
+	if strings.Join(matched, ",") != "CT,Scans" {
+		t.Errorf("Expected highlights CT,Scans, got %v", matched)
+	}
+}
+
+func TestBuildSnippetMergesOverlaps(t *testing.T) {
+	_, highlights := BuildSnippet("preauthorization", []string{"auth", "authorization"}, 100)
+
+	if len(highlights) != 1 || highlights[0].Start != 3 || highlights[0].End != 16 {
+		t.Errorf("Expected single merged highlight [3,16), got %v", highlights)
+	}
+}
+
+func TestNodeSnippetFieldFallback(t *testing.T) {
+	node := &Node{
+		Title:   "Imaging",
+		Summary: "Covers MRI and CT",
+		Text:    "General coverage rules apply",
+	}
+
+	snippet, highlights := NodeSnippet(node, "mri", 100)
+	if snippet != node.Summary {
+		t.Errorf("Expected summary snippet, got %q", snippet)
+	}
+	if len(highlights) != 1 {
+		t.Errorf("Expected 1 highlight, got %d", len(highlights))
+	}
+
+	snippet, highlights = NodeSnippet(node, "dental", 100)
+	if snippet != node.Text || len(highlights) != 0 {
+		t.Errorf("Expected unhighlighted text fallback, got %q %v", snippet, highlights)
+	}
+}
@@ -4,7 +4,11 @@
 package document
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,7 +16,7 @@ import (
 )
 
 func setupTestStore(t *testing.T) (*SimpleStore, *storage.KV, string) {
-	path := "/tmp/test_docstore_" + t.Name() + ".db"
+	path := storage.MemoryPath
 	kv := &storage.KV{Path: path}
 	if err := kv.Open(); err != nil {
 		t.Fatalf("Failed to open: %v", err)
@@ -91,6 +95,104 @@ func TestStoreAndRetrieveDocument(t *testing.T) {
 	}
 }
 
+func TestGetNodes(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	rootID := "root"
+	nodes := []*Node{
+		{NodeID: "root", PolicyID: "policy1", Title: "Root", CreatedAt: now, UpdatedAt: now},
+		{NodeID: "a", PolicyID: "policy1", ParentID: &rootID, Title: "A", Depth: 1, CreatedAt: now, UpdatedAt: now},
+		{NodeID: "b", PolicyID: "policy1", ParentID: &rootID, Title: "B", Depth: 1, CreatedAt: now, UpdatedAt: now},
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "policy1"}, nodes); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	got, err := ds.GetNodes("policy1", []string{"b", "missing", "root", "a"})
+	if err != nil {
+		t.Fatalf("GetNodes failed: %v", err)
+	}
+
+	if len(got) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(got))
+	}
+	if got[0] == nil || got[0].Title != "B" {
+		t.Errorf("Expected node b first, got %+v", got[0])
+	}
+	if got[1] != nil {
+		t.Errorf("Expected nil for missing node, got %+v", got[1])
+	}
+	if got[2] == nil || got[2].Title != "Root" {
+		t.Errorf("Expected root node third, got %+v", got[2])
+	}
+	if got[3] == nil || got[3].ParentID == nil || *got[3].ParentID != "root" {
+		t.Errorf("Expected node a with parent root, got %+v", got[3])
+	}
+}
+
+func TestUpdateNodeVersionConflict(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	rootID := "root"
+	nodes := []*Node{
+		{NodeID: "root", PolicyID: "policy1", Title: "Root", CreatedAt: now, UpdatedAt: now},
+		{NodeID: "a", PolicyID: "policy1", ParentID: &rootID, Title: "A", Depth: 1, CreatedAt: now, UpdatedAt: now},
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "policy1"}, nodes); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	first, err := ds.GetNode("policy1", "a")
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if first.Version != 1 {
+		t.Fatalf("Expected version 1 after store, got %d", first.Version)
+	}
+	second := *first
+
+	first.Title = "A (edited)"
+	if err := ds.UpdateNode(first); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Expected version 2 after update, got %d", first.Version)
+	}
+
+	// A writer holding the stale read must be rejected
+	second.Title = "A (lost update)"
+	if err := ds.UpdateNode(&second); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Expected ErrVersionConflict, got %v", err)
+	}
+
+	got, err := ds.GetNode("policy1", "a")
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if got.Title != "A (edited)" || got.Version != 2 {
+		t.Errorf("Expected edited title at version 2, got %q at %d", got.Title, got.Version)
+	}
+
+	children, err := ds.GetChildren("policy1", &rootID)
+	if err != nil {
+		t.Fatalf("GetChildren failed: %v", err)
+	}
+	if len(children) != 1 || children[0].Title != "A (edited)" {
+		t.Errorf("Expected children index to reflect update, got %+v", children)
+	}
+
+	missing := &Node{NodeID: "nope", PolicyID: "policy1", Version: 1}
+	if err := ds.UpdateNode(missing); err == nil {
+		t.Error("Expected error updating missing node")
+	}
+}
+
 func TestGetChildren(t *testing.T) {
 	ds, kv, path := setupTestStore(t)
 	defer os.Remove(path)
@@ -256,6 +358,113 @@ func TestGetSubtree(t *testing.T) {
 	}
 }
 
+func TestGetSubtreeWorkers(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	// A wide tree: 20 sections of 10 subsections of 3 paragraphs
+	rootID := "root"
+	nodes := []*Node{{NodeID: rootID, PolicyID: "policy1", Title: "Root"}}
+	for s := 0; s < 20; s++ {
+		sectionID := fmt.Sprintf("s%02d", s)
+		nodes = append(nodes, &Node{NodeID: sectionID, PolicyID: "policy1", ParentID: &rootID, Depth: 1})
+		for u := 0; u < 10; u++ {
+			subID := fmt.Sprintf("%s.%02d", sectionID, u)
+			parent := sectionID
+			nodes = append(nodes, &Node{NodeID: subID, PolicyID: "policy1", ParentID: &parent, Depth: 2})
+			for p := 0; p < 3; p++ {
+				sub := subID
+				nodes = append(nodes, &Node{NodeID: fmt.Sprintf("%s.%d", subID, p), PolicyID: "policy1", ParentID: &sub, Depth: 3})
+			}
+		}
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "policy1"}, nodes); err != nil {
+		t.Fatalf("Failed to store: %v", err)
+	}
+
+	sequential, err := ds.GetSubtree("policy1", rootID, QueryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get subtree: %v", err)
+	}
+	if len(sequential) != len(nodes) {
+		t.Fatalf("Expected %d nodes, got %d", len(nodes), len(sequential))
+	}
+	for _, workers := range []int{2, 8, 1000} {
+		parallel, err := ds.GetSubtree("policy1", rootID, QueryOptions{Workers: workers})
+		if err != nil {
+			t.Fatalf("Workers %d: failed to get subtree: %v", workers, err)
+		}
+		if len(parallel) != len(sequential) {
+			t.Fatalf("Workers %d: got %d nodes, want %d", workers, len(parallel), len(sequential))
+		}
+		for i := range parallel {
+			if parallel[i].NodeID != sequential[i].NodeID {
+				t.Fatalf("Workers %d: node %d is %s, want %s", workers, i, parallel[i].NodeID, sequential[i].NodeID)
+			}
+		}
+	}
+
+	// A failing read stops the traversal with the store's error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ds.WithContext(ctx).GetSubtree("policy1", rootID, QueryOptions{Workers: 4}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the canceled context's error, got %v", err)
+	}
+}
+
+func TestWithoutFields(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	rootID := "root"
+	nodes := []*Node{
+		{NodeID: rootID, PolicyID: "policy1", Title: "Root", Summary: "Overview", Text: "Full text", PageStart: 1, PageEnd: 9},
+		{NodeID: "child", PolicyID: "policy1", ParentID: &rootID, Title: "Child", Summary: "Detail", Text: "More text", PageStart: 2, PageEnd: 3, Depth: 1},
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "policy1"}, nodes); err != nil {
+		t.Fatalf("Failed to store: %v", err)
+	}
+
+	view := ds.WithoutFields(FieldText)
+	subtree, err := view.GetSubtree("policy1", rootID, QueryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get subtree: %v", err)
+	}
+	if len(subtree) != 2 {
+		t.Fatalf("Expected 2 nodes, got %d", len(subtree))
+	}
+	for i, node := range subtree {
+		if node.Text != "" {
+			t.Errorf("Expected no text for %s, got %q", node.NodeID, node.Text)
+		}
+		if node.Summary != nodes[i].Summary || node.Title != nodes[i].Title || node.PageEnd != nodes[i].PageEnd {
+			t.Errorf("Expected the other fields of %s intact, got %+v", node.NodeID, node)
+		}
+	}
+	if subtree[1].ParentID == nil || *subtree[1].ParentID != rootID {
+		t.Errorf("Expected the child's parent kept, got %v", subtree[1].ParentID)
+	}
+
+	children, err := view.WithoutFields(FieldSummary).GetChildren("policy1", &rootID)
+	if err != nil || len(children) != 1 {
+		t.Fatalf("Failed to get children: %v, %v", children, err)
+	}
+	if children[0].Summary != "" || children[0].Text != "" || children[0].Title != "Child" {
+		t.Errorf("Expected summary and text left out, got %+v", children[0])
+	}
+
+	// The store the views came from still reads every field
+	node, err := ds.GetNode("policy1", "child")
+	if err != nil {
+		t.Fatalf("Failed to get node: %v", err)
+	}
+	if node.Text != "More text" || node.Summary != "Detail" {
+		t.Errorf("Expected full node from the original store, got %+v", node)
+	}
+}
+
 func TestGetAncestorPath(t *testing.T) {
 	ds, kv, path := setupTestStore(t)
 	defer os.Remove(path)
@@ -394,3 +603,213 @@ func TestSearch(t *testing.T) {
 		t.Error("Expected positive score")
 	}
 }
+
+func TestSearchAll(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	store := func(policyID string, nodes ...*Node) {
+		for _, n := range nodes {
+			n.PolicyID = policyID
+			n.CreatedAt = now
+			n.UpdatedAt = now
+		}
+		if err := ds.StoreDocument(&Document{PolicyID: policyID}, nodes); err != nil {
+			t.Fatalf("Failed to store %s: %v", policyID, err)
+		}
+	}
+
+	store("imaging",
+		&Node{NodeID: "n1", Title: "Prior Authorization for CT Scans", Text: "CT scans require prior authorization"},
+		&Node{NodeID: "n2", Title: "MRI", Text: "MRI requires prior authorization"},
+		&Node{NodeID: "n3", Title: "X-Ray", Text: "No authorization needed"},
+	)
+	store("pharmacy",
+		&Node{NodeID: "p1", Title: "Formulary", Summary: "Some drugs need prior authorization"},
+	)
+	store("dental",
+		&Node{NodeID: "d1", Title: "Cleanings", Text: "Covered twice a year"},
+	)
+
+	groups, err := ds.SearchAll("prior authorization CT", 2, 0)
+	if err != nil {
+		t.Fatalf("SearchAll failed: %v", err)
+	}
+
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 policy groups, got %d", len(groups))
+	}
+	if groups[0].PolicyID != "imaging" {
+		t.Errorf("Expected imaging first, got %s", groups[0].PolicyID)
+	}
+	if groups[0].TotalHits != 3 {
+		t.Errorf("Expected 3 total hits in imaging, got %d", groups[0].TotalHits)
+	}
+	if len(groups[0].Results) != 2 {
+		t.Errorf("Expected per-policy limit of 2, got %d", len(groups[0].Results))
+	}
+	if groups[0].Results[0].NodeID != "n1" {
+		t.Errorf("Expected n1 as best hit, got %s", groups[0].Results[0].NodeID)
+	}
+	if node := groups[0].Results[0].Node; node == nil || node.Text != "CT scans require prior authorization" {
+		t.Errorf("Expected the hit's node with its text, got %+v", node)
+	}
+
+	// Restoring a node with new text must drop its stale terms
+	store("pharmacy", &Node{NodeID: "p1", Title: "Formulary", Summary: "Generic drugs only"})
+
+	groups, err = ds.SearchAll("authorization", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchAll failed: %v", err)
+	}
+	for _, g := range groups {
+		if g.PolicyID == "pharmacy" {
+			t.Error("Expected stale pharmacy terms to be removed from the index")
+		}
+	}
+
+	groups, err = ds.SearchAll("authorization", 10, 1)
+	if err != nil {
+		t.Fatalf("SearchAll failed: %v", err)
+	}
+	if len(groups) != 1 {
+		t.Errorf("Expected max 1 policy, got %d", len(groups))
+	}
+}
+
+func TestListPolicies(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	for _, policyID := range []string{"pharmacy", "dental", "imaging", "imaging-2"} {
+		nodes := []*Node{
+			{NodeID: "a", PolicyID: policyID, CreatedAt: now, UpdatedAt: now},
+			{NodeID: "b", PolicyID: policyID, CreatedAt: now, UpdatedAt: now},
+		}
+		if err := ds.StoreDocument(&Document{PolicyID: policyID}, nodes); err != nil {
+			t.Fatalf("Failed to store %s: %v", policyID, err)
+		}
+	}
+
+	all, err := ds.ListPolicies("", 0)
+	if err != nil || strings.Join(all, ",") != "dental,imaging,imaging-2,pharmacy" {
+		t.Fatalf("ListPolicies = %v, %v", all, err)
+	}
+	page, err := ds.ListPolicies("imaging", 2)
+	if err != nil || strings.Join(page, ",") != "imaging-2,pharmacy" {
+		t.Errorf("ListPolicies after imaging = %v, %v", page, err)
+	}
+	if rest, _ := ds.ListPolicies("pharmacy", 2); len(rest) != 0 {
+		t.Errorf("Expected nothing after the last policy, got %v", rest)
+	}
+}
+
+func TestReindex(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	for _, policyID := range []string{"imaging", "pharmacy"} {
+		node := &Node{NodeID: "n1", PolicyID: policyID, Title: "Prior authorization", CreatedAt: now, UpdatedAt: now}
+		if err := ds.StoreDocument(&Document{PolicyID: policyID}, []*Node{node}); err != nil {
+			t.Fatalf("Failed to store %s: %v", policyID, err)
+		}
+	}
+
+	// Lose a posting of imaging and leave one behind for a node that is gone
+	tx := kv.Begin()
+	termIndex.Update(tx, nodeEntity("imaging", "n1"), map[string]int64{"prior": 1}, nil)
+	termIndex.Update(tx, nodeEntity("imaging", "gone"), nil, map[string]int64{"authorization": 1})
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	n, err := ds.Reindex("imaging")
+	if err != nil || n != 1 {
+		t.Fatalf("Reindex = %d, %v", n, err)
+	}
+	for _, query := range []string{"prior", "authorization"} {
+		groups, err := ds.SearchAll(query, 10, 0)
+		if err != nil {
+			t.Fatalf("SearchAll failed: %v", err)
+		}
+		if len(groups) != 2 || groups[0].TotalHits != 1 || groups[1].TotalHits != 1 {
+			t.Errorf("Expected one hit per policy for %q after reindexing, got %+v", query, groups)
+		}
+	}
+
+	if n, err := ds.Reindex(""); err != nil || n != 2 {
+		t.Errorf("Reindex of every policy = %d, %v", n, err)
+	}
+}
+
+func TestSearchFiltered(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	rootID := "root"
+	sec3 := "sec3"
+	nodes := []*Node{
+		{NodeID: "root", Title: "Coverage overview", PageStart: 1, PageEnd: 60, SectionPath: "", Depth: 0},
+		{NodeID: "sec2", ParentID: &rootID, Title: "Coverage exclusions", PageStart: 5, PageEnd: 9, SectionPath: "2", Depth: 1},
+		{NodeID: "sec3", ParentID: &rootID, Title: "Coverage criteria", PageStart: 10, PageEnd: 30, SectionPath: "3", Depth: 1},
+		{NodeID: "sec3.1", ParentID: &sec3, Title: "Coverage limits", PageStart: 12, PageEnd: 20, SectionPath: "3.1", Depth: 2},
+		{NodeID: "sec3.1.1", ParentID: &sec3, Title: "Coverage appeals", PageStart: 35, PageEnd: 45, SectionPath: "3.1.1", Depth: 3},
+	}
+	for _, n := range nodes {
+		n.PolicyID = "policy1"
+		n.CreatedAt = now
+		n.UpdatedAt = now
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "policy1"}, nodes); err != nil {
+		t.Fatalf("Failed to store: %v", err)
+	}
+
+	ids := func(results []*SearchResult) map[string]bool {
+		set := make(map[string]bool)
+		for _, r := range results {
+			set[r.NodeID] = true
+		}
+		return set
+	}
+
+	maxDepth := 2
+	tests := []struct {
+		name   string
+		filter SearchFilter
+		want   []string
+	}{
+		{"no filter", SearchFilter{}, []string{"root", "sec2", "sec3", "sec3.1", "sec3.1.1"}},
+		{"page range", SearchFilter{PageFrom: 10, PageTo: 40}, []string{"root", "sec3", "sec3.1", "sec3.1.1"}},
+		{"max depth", SearchFilter{MaxDepth: &maxDepth}, []string{"root", "sec2", "sec3", "sec3.1"}},
+		{"section prefix", SearchFilter{SectionPathPrefix: "3."}, []string{"sec3.1", "sec3.1.1"}},
+		{"combined", SearchFilter{PageFrom: 10, PageTo: 40, MaxDepth: &maxDepth, SectionPathPrefix: "3"}, []string{"sec3", "sec3.1"}},
+		{"predicate", SearchFilter{Match: func(n *Node) bool { return n.NodeID == "sec2" }}, []string{"sec2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := ds.SearchFiltered("policy1", "coverage", 10, tt.filter)
+			if err != nil {
+				t.Fatalf("SearchFiltered failed: %v", err)
+			}
+
+			got := ids(results)
+			if len(got) != len(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+			for _, id := range tt.want {
+				if !got[id] {
+					t.Errorf("Expected %s in results", id)
+				}
+			}
+		})
+	}
+}
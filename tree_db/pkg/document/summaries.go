@@ -0,0 +1,209 @@
+// ABOUTME: Node summaries recomputed by an external summarizer once the text they describe changes
+// ABOUTME: Each node records the checksum of the text its summary was written for, so stale summaries can be found
+
+package document
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// DefaultSummaryBatch is the number of nodes sent to a summarizer at once
+const DefaultSummaryBatch = 16
+
+// Summarizer writes summaries of nodes' text, such as a model behind an HTTP
+// or gRPC endpoint. It returns one summary per node, in order.
+type Summarizer interface {
+	Summarize(ctx context.Context, nodes []*Node) ([]string, error)
+}
+
+// SummarySelector chooses the nodes of a policy whose summaries are refreshed
+// Nodes without text are never summarized.
+type SummarySelector struct {
+	NodeIDs     []string // Only these nodes; empty selects every node
+	SectionPath string   // Only this section and those under it, such as "2.1"
+	Force       bool     // Refresh current summaries too, not just stale ones
+}
+
+// SummaryProgress counts the nodes a refresh has handled
+type SummaryProgress struct {
+	Total   int // Nodes selected
+	Done    int // Nodes handled so far
+	Updated int // Summaries written
+	Skipped int // Nodes deleted or rewritten while being summarized; a later refresh picks them up
+}
+
+// RefreshOptions tunes RefreshSummaries
+type RefreshOptions struct {
+	BatchSize  int                   // Nodes per summarizer call and transaction; 0 uses DefaultSummaryBatch
+	OnProgress func(SummaryProgress) // Called after each batch is written
+	Lock       sync.Locker           // Held while each batch is written, if set
+}
+
+// SummaryStale reports whether a node's summary was written for other text
+// than it now has, or it has text but no summary
+func SummaryStale(node *Node) bool {
+	return node.Checksum != "" && node.SummaryChecksum != node.Checksum
+}
+
+// matches reports whether a node is selected
+func (sel SummarySelector) matches(node *Node) bool {
+	if sel.SectionPath != "" && node.SectionPath != sel.SectionPath &&
+		!strings.HasPrefix(node.SectionPath, sel.SectionPath+".") {
+		return false
+	}
+	return node.Checksum != "" && (sel.Force || SummaryStale(node))
+}
+
+// RefreshSummaries asks s to summarize the selected nodes of a policy, batch
+// by batch, and writes each batch's summaries in one transaction. The store
+// is not locked while s runs; a node whose text changes meanwhile keeps its
+// summary and is counted as skipped. It stops between batches when ctx is
+// cancelled, keeping the batches already written.
+func (ss *SimpleStore) RefreshSummaries(ctx context.Context, policyID string, sel SummarySelector, s Summarizer, opts RefreshOptions) (*SummaryProgress, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultSummaryBatch
+	}
+
+	selected, err := ss.selectSummaries(policyID, sel)
+	if err != nil {
+		return nil, err
+	}
+	progress := &SummaryProgress{Total: len(selected)}
+	for start := 0; start < len(selected); start += opts.BatchSize {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+		end := start + opts.BatchSize
+		if end > len(selected) {
+			end = len(selected)
+		}
+
+		batch, err := ss.GetNodes(policyID, selected[start:end])
+		if err != nil {
+			return progress, err
+		}
+		var nodes []*Node
+		for _, node := range batch {
+			if node != nil {
+				nodes = append(nodes, node)
+			}
+		}
+		progress.Skipped += len(batch) - len(nodes)
+
+		if len(nodes) > 0 {
+			summaries, err := s.Summarize(ctx, nodes)
+			if err != nil {
+				return progress, fmt.Errorf("summarize %s: %w", policyID, err)
+			}
+			if len(summaries) != len(nodes) {
+				return progress, fmt.Errorf("summarize %s: got %d summaries for %d nodes", policyID, len(summaries), len(nodes))
+			}
+			updated, err := ss.writeSummaries(nodes, summaries, opts.Lock)
+			if err != nil {
+				return progress, err
+			}
+			progress.Updated += updated
+			progress.Skipped += len(nodes) - updated
+		}
+
+		progress.Done = end
+		if opts.OnProgress != nil {
+			opts.OnProgress(*progress)
+		}
+	}
+	return progress, nil
+}
+
+// selectSummaries returns the IDs of the selected nodes, ordered by section
+// path so neighbouring sections are summarized together
+func (ss *SimpleStore) selectSummaries(policyID string, sel SummarySelector) ([]string, error) {
+	nodes, err := ss.scanPolicyNodes(policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []*Node
+	if len(sel.NodeIDs) > 0 {
+		for _, nodeID := range sel.NodeIDs {
+			if node, ok := nodes[nodeID]; ok && sel.matches(node) {
+				selected = append(selected, node)
+				delete(nodes, nodeID) // A node listed twice is summarized once
+			}
+		}
+	} else {
+		for _, node := range nodes {
+			if sel.matches(node) {
+				selected = append(selected, node)
+			}
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].SectionPath != selected[j].SectionPath {
+			return selected[i].SectionPath < selected[j].SectionPath
+		}
+		return selected[i].NodeID < selected[j].NodeID
+	})
+
+	ids := make([]string, len(selected))
+	for i, node := range selected {
+		ids[i] = node.NodeID
+	}
+	return ids, nil
+}
+
+// writeSummaries stores the summaries of nodes in one transaction, skipping
+// nodes deleted or rewritten since they were read, and returns how many it
+// wrote
+func (ss *SimpleStore) writeSummaries(nodes []*Node, summaries []string, lock sync.Locker) (int, error) {
+	if lock != nil {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	tx := ss.kv.Begin()
+	defer tx.Abort()
+
+	var written []*Node
+	for i, node := range nodes {
+		old := loadNode(tx, node.PolicyID, node.NodeID)
+		if old == nil || old.Version != node.Version {
+			continue
+		}
+		updated := *old
+		updated.Summary = strings.TrimSpace(summaries[i])
+		updated.Version = old.Version + 1
+		putNode(tx, old, &updated)
+		setSummaryChecksum(tx, &updated, updated.Checksum) // An unchanged summary is now current too
+		written = append(written, &updated)
+	}
+	if err := tx.Err(); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	for _, node := range written {
+		ss.feed.Publish(changefeed.EntityDocument, changefeed.OpPut, node.PolicyID, node.NodeID)
+	}
+	return len(written), nil
+}
+
+// setSummaryChecksum rewrites the record of a node just written so its
+// summary is taken as written for the text with the given checksum
+func setSummaryChecksum(tx storage.Txn, node *Node, checksum string) {
+	if node.Summary == "" || node.SummaryChecksum == checksum {
+		return
+	}
+	node.SummaryChecksum = checksum
+	tx.Set(nodeKey(node.PolicyID, node.NodeID), encodeNode(node))
+}
@@ -0,0 +1,127 @@
+// ABOUTME: Tests for recomputing node summaries with an external summarizer
+// ABOUTME: Verifies stale summaries are found after text changes, selected, written back in batches and reported
+
+package document
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// upperSummarizer summarizes a node as its upper-cased text, counting calls
+type upperSummarizer struct {
+	calls  int
+	before func() // Runs before each batch is summarized
+}
+
+func (u *upperSummarizer) Summarize(ctx context.Context, nodes []*Node) ([]string, error) {
+	u.calls++
+	if u.before != nil {
+		u.before()
+	}
+	summaries := make([]string, len(nodes))
+	for i, node := range nodes {
+		summaries[i] = strings.ToUpper(node.Text)
+	}
+	return summaries, nil
+}
+
+func TestRefreshSummaries(t *testing.T) {
+	ds, kv, _ := setupTestStore(t)
+	defer kv.Close()
+
+	root := "root"
+	nodes := []*Node{
+		{NodeID: root, PolicyID: "LCD-1", SectionPath: "1"},
+		{NodeID: "a", PolicyID: "LCD-1", ParentID: &root, SectionPath: "1.1", Text: "scope", Summary: "Scope"},
+		{NodeID: "b", PolicyID: "LCD-1", ParentID: &root, SectionPath: "1.2", Text: "criteria"},
+		{NodeID: "c", PolicyID: "LCD-1", ParentID: &root, SectionPath: "1.10", Text: "codes"},
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	// A summary stored with its text is current; text without one is stale
+	a, _ := ds.GetNode("LCD-1", "a")
+	if SummaryStale(a) || a.SummaryChecksum != ContentChecksum("scope") {
+		t.Errorf("Expected a's summary current, got %+v", a)
+	}
+	a.Text = "scope and limits"
+	if err := ds.UpdateNode(a); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if !SummaryStale(a) {
+		t.Errorf("Expected a's summary stale once its text changed, got %+v", a)
+	}
+
+	var reports []SummaryProgress
+	s := &upperSummarizer{}
+	progress, err := ds.RefreshSummaries(context.Background(), "LCD-1", SummarySelector{SectionPath: "1.1"}, s,
+		RefreshOptions{OnProgress: func(p SummaryProgress) { reports = append(reports, p) }})
+	if err != nil || progress.Total != 1 || progress.Updated != 1 || len(reports) != 1 {
+		t.Fatalf("Expected only a under 1.1 refreshed, got %+v %v (%v)", progress, reports, err)
+	}
+	if got, _ := ds.GetNode("LCD-1", "a"); got.Summary != "SCOPE AND LIMITS" || SummaryStale(got) || got.Version != a.Version+1 {
+		t.Errorf("Expected a's summary rewritten, got %+v", got)
+	}
+
+	// Everything stale is refreshed in batches; the root has no text
+	reports = nil
+	progress, err = ds.RefreshSummaries(context.Background(), "LCD-1", SummarySelector{}, s,
+		RefreshOptions{BatchSize: 1, OnProgress: func(p SummaryProgress) { reports = append(reports, p) }})
+	if err != nil || progress.Total != 2 || progress.Updated != 2 || len(reports) != 2 || reports[0].Done != 1 {
+		t.Fatalf("Expected b and c refreshed one at a time, got %+v %v (%v)", progress, reports, err)
+	}
+	if progress, _ := ds.RefreshSummaries(context.Background(), "LCD-1", SummarySelector{}, s, RefreshOptions{}); progress.Total != 0 {
+		t.Errorf("Expected nothing stale left, got %+v", progress)
+	}
+
+	// Forcing refreshes current summaries, and a node rewritten meanwhile is skipped
+	s.before = func() {
+		b, _ := ds.GetNode("LCD-1", "b")
+		b.Text = "new criteria"
+		if err := ds.UpdateNode(b); err != nil {
+			t.Errorf("UpdateNode failed: %v", err)
+		}
+	}
+	progress, err = ds.RefreshSummaries(context.Background(), "LCD-1", SummarySelector{NodeIDs: []string{"b", "c", "b"}, Force: true}, s, RefreshOptions{})
+	if err != nil || progress.Total != 2 || progress.Updated != 1 || progress.Skipped != 1 {
+		t.Errorf("Expected c refreshed and b skipped, got %+v (%v)", progress, err)
+	}
+	if b, _ := ds.GetNode("LCD-1", "b"); b.Summary != "CRITERIA" || !SummaryStale(b) {
+		t.Errorf("Expected b's summary left stale, got %+v", b)
+	}
+
+	// Cancelling stops before the next batch
+	ctx, cancel := context.WithCancel(context.Background())
+	s.before = cancel
+	progress, err = ds.RefreshSummaries(ctx, "LCD-1", SummarySelector{Force: true}, s, RefreshOptions{BatchSize: 1})
+	if !errors.Is(err, context.Canceled) || progress.Done != 1 {
+		t.Errorf("Expected to stop after one batch, got %+v (%v)", progress, err)
+	}
+}
+
+func TestCloneKeepsStaleSummaries(t *testing.T) {
+	ds, kv, _ := setupTestStore(t)
+	defer kv.Close()
+
+	node := &Node{NodeID: "root", PolicyID: "LCD-1", Text: "old", Summary: "Old"}
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, []*Node{node}); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+	node.Text = "new"
+	if err := ds.UpdateNode(node); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+
+	result, err := ds.CloneDocument("LCD-1", "LCD-2")
+	if err != nil {
+		t.Fatalf("CloneDocument failed: %v", err)
+	}
+	clone, err := ds.GetNode("LCD-2", result.NodeIDs["root"])
+	if err != nil || !SummaryStale(clone) {
+		t.Errorf("Expected the clone's summary stale like its source, got %+v (%v)", clone, err)
+	}
+}
@@ -0,0 +1,257 @@
+// ABOUTME: Inverted term index over node titles, summaries and text
+// ABOUTME: Maintained by StoreDocument and used for cross-policy keyword search
+
+package document
+
+import (
+	"sort"
+
+	"github.com/nainya/treestore/pkg/storage"
+	"github.com/nainya/treestore/pkg/textindex"
+)
+
+// PREFIX_TERM keys posting entries as (term, policyID, nodeID) -> weight
+const PREFIX_TERM = uint32(5100)
+
+// Field weights, matching the scoring used by Search
+const (
+	weightTitle   = 3
+	weightSummary = 2
+	weightText    = 1
+)
+
+// PolicySearchResults groups search hits that belong to a single policy
+type PolicySearchResults struct {
+	PolicyID  string
+	Results   []*SearchResult // Highest score first, truncated to the per-policy limit
+	TotalHits int             // Number of matching nodes before truncation
+}
+
+// termIndex holds node postings keyed by (policyID, nodeID)
+var termIndex = textindex.New(PREFIX_TERM)
+
+// nodeTermWeights computes the index weight of every distinct term in a node
+func nodeTermWeights(node *Node) map[string]int64 {
+	return textindex.Weights(
+		textindex.Field{Text: node.Title, Weight: weightTitle},
+		textindex.Field{Text: node.Summary, Weight: weightSummary},
+		textindex.Field{Text: node.Text, Weight: weightText},
+	)
+}
+
+// nodeEntity builds the posting entity key columns of a node
+func nodeEntity(policyID, nodeID string) []storage.Value {
+	return []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(nodeID)),
+	}
+}
+
+// indexNode writes posting entries for a node, replacing those of the previous version
+func indexNode(tx storage.Txn, old, node *Node) {
+	var oldWeights map[string]int64
+	if old != nil {
+		oldWeights = nodeTermWeights(old)
+	}
+	termIndex.Update(tx, nodeEntity(node.PolicyID, node.NodeID), oldWeights, nodeTermWeights(node))
+}
+
+// scanPostings calls fn for every posting of a term across all policies, or
+// across policyIDs alone when it is not nil
+func (ss *SimpleStore) scanPostings(term string, policyIDs []string, fn func(policyID, nodeID string, weight int64)) error {
+	visit := func(entity []storage.Value, weight int64) bool {
+		if len(entity) >= 2 {
+			fn(string(entity[0].Str), string(entity[1].Str), weight)
+		}
+		return true
+	}
+	if policyIDs == nil {
+		return termIndex.Postings(ss.kv, term, nil, visit)
+	}
+	for _, policyID := range policyIDs {
+		scope := []storage.Value{storage.NewBytesValue([]byte(policyID))}
+		if err := termIndex.Postings(ss.kv, term, scope, visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchAll performs keyword search across every policy using the term index
+// Hits are grouped by policy, each group holds at most perPolicy results, and
+// groups are ordered by their best score. maxPolicies <= 0 returns all groups.
+func (ss *SimpleStore) SearchAll(query string, perPolicy, maxPolicies int) ([]*PolicySearchResults, error) {
+	return ss.searchPostings(query, nil, perPolicy, maxPolicies)
+}
+
+// SearchPolicies is SearchAll over the given policies only, such as the
+// members of a collection; it reads just their postings of each term
+func (ss *SimpleStore) SearchPolicies(query string, policyIDs []string, perPolicy, maxPolicies int) ([]*PolicySearchResults, error) {
+	if len(policyIDs) == 0 {
+		return nil, nil
+	}
+	return ss.searchPostings(query, policyIDs, perPolicy, maxPolicies)
+}
+
+// searchPostings ranks nodes by their postings, across every policy when
+// policyIDs is nil
+func (ss *SimpleStore) searchPostings(query string, policyIDs []string, perPolicy, maxPolicies int) ([]*PolicySearchResults, error) {
+	if perPolicy <= 0 {
+		perPolicy = 10
+	}
+
+	// Accumulate scores per policy and node
+	scores := make(map[string]map[string]float64)
+	for _, term := range textindex.UniqueTerms(query) {
+		err := ss.scanPostings(term, policyIDs, func(policyID, nodeID string, weight int64) {
+			if scores[policyID] == nil {
+				scores[policyID] = make(map[string]float64)
+			}
+			scores[policyID][nodeID] += float64(weight)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	groups := make([]*PolicySearchResults, 0, len(scores))
+	for policyID, nodeScores := range scores {
+		nodeIDs := make([]string, 0, len(nodeScores))
+		for nodeID := range nodeScores {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+		sort.Slice(nodeIDs, func(i, j int) bool {
+			si, sj := nodeScores[nodeIDs[i]], nodeScores[nodeIDs[j]]
+			if si != sj {
+				return si > sj
+			}
+			return nodeIDs[i] < nodeIDs[j]
+		})
+
+		group := &PolicySearchResults{PolicyID: policyID, TotalHits: len(nodeIDs)}
+		if len(nodeIDs) > perPolicy {
+			nodeIDs = nodeIDs[:perPolicy]
+		}
+
+		nodes, err := ss.GetNodes(policyID, nodeIDs)
+		if err != nil {
+			return nil, err
+		}
+		for i, node := range nodes {
+			if node == nil {
+				continue
+			}
+			snippet, _ := NodeSnippet(node, query, DefaultSnippetLength)
+			group.Results = append(group.Results, &SearchResult{
+				NodeID:   node.NodeID,
+				PolicyID: node.PolicyID,
+				Title:    node.Title,
+				Summary:  node.Summary,
+				Score:    nodeScores[nodeIDs[i]],
+				Snippet:  snippet,
+				Node:     node,
+			})
+		}
+
+		if len(group.Results) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		si, sj := groups[i].Results[0].Score, groups[j].Results[0].Score
+		if si != sj {
+			return si > sj
+		}
+		return groups[i].PolicyID < groups[j].PolicyID
+	})
+
+	if maxPolicies > 0 && len(groups) > maxPolicies {
+		groups = groups[:maxPolicies]
+	}
+
+	return groups, nil
+}
+
+// Reindex rebuilds the term index and node ID filters of a policy's nodes, or
+// of every policy when policyID is empty, dropping postings of nodes that no
+// longer exist and restoring missing ones. Nodes written before text was
+// stored separately have their text moved to a text record, and nodes written
+// before the root and sibling order indexes are added to them. It returns the
+// number of nodes indexed.
+func (ss *SimpleStore) Reindex(policyID string) (int, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	tx := ss.kv.Begin()
+	defer tx.Abort()
+
+	// Postings are keyed by term first, so a policy's are spread over the index
+	var postings [][]byte
+	err := storage.ScanPrefix(tx, storage.EncodeKey(PREFIX_TERM, nil), func(key, val []byte) bool {
+		if policyID != "" {
+			vals, err := storage.ExtractValues(key)
+			if err != nil || len(vals) < 2 || string(vals[1].Str) != policyID {
+				return true
+			}
+		}
+		postings = append(postings, append([]byte(nil), key...))
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, key := range postings {
+		tx.Del(key)
+	}
+
+	var scope []storage.Value
+	if policyID != "" {
+		scope = []storage.Value{storage.NewBytesValue([]byte(policyID))}
+	}
+	var nodes, separate, inline []*Node
+	nodeIDs := make(map[string][]string)
+	err = storage.ScanPrefix(tx, storage.EncodeKey(PREFIX_NODE, scope), func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 2 {
+			return true
+		}
+		nodeIDs[string(vals[0].Str)] = append(nodeIDs[string(vals[0].Str)], string(vals[1].Str))
+		if node, split, err := decodeStoredNode(val, nil); err == nil {
+			nodes = append(nodes, node)
+			if split {
+				separate = append(separate, node)
+			} else if node.Text != "" {
+				inline = append(inline, node)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, node := range separate {
+		text, _ := tx.Get(textKey(node.PolicyID, node.NodeID))
+		node.Text = string(text)
+	}
+	// Nodes from before text records still hold their text inline
+	for _, node := range inline {
+		writeNode(tx, node, node)
+	}
+	for _, node := range nodes {
+		indexNode(tx, nil, node)
+		putRoot(tx, nil, node)
+		putSiblingOrder(tx, nil, node)
+	}
+
+	updated := ss.blooms.rebuild(tx, policyID, nodeIDs)
+	err = tx.Commit()
+	if err == nil && policyID == "" {
+		ss.blooms.reset() // Filters of policies without nodes are gone too
+	}
+	ss.blooms.publish(updated, err == nil)
+	if err != nil {
+		return 0, err
+	}
+	return len(nodes), nil
+}
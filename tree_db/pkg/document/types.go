@@ -16,21 +16,34 @@ type Document struct {
 	UpdatedAt      time.Time         // Last update timestamp
 }
 
+// NodeFields is a set of the large fields of a Node that reads can leave out
+type NodeFields uint8
+
+const (
+	FieldSummary NodeFields = 1 << iota
+	FieldText
+)
+
 // Node represents a hierarchical section in a document
 type Node struct {
-	NodeID      string   // Unique node identifier
-	PolicyID    string   // Parent policy ID
-	ParentID    *string  // Parent node ID (nil for root)
-	Title       string   // Section title
-	PageStart   int      // Starting page number
-	PageEnd     int      // Ending page number
-	Summary     string   // Section summary
-	Text        string   // Full section text
-	SectionPath string   // Materialized path (e.g., "1.2.3")
-	ChildIDs    []string // Child node IDs
-	Depth       int      // Depth in hierarchy (0 for root)
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	NodeID          string   // Unique node identifier
+	PolicyID        string   // Parent policy ID
+	ParentID        *string  // Parent node ID (nil for root)
+	Title           string   // Section title
+	PageStart       int      // Starting page number
+	PageEnd         int      // Ending page number
+	Summary         string   // Section summary
+	Text            string   // Full section text
+	SectionPath     string   // Materialized path (e.g., "1.2.3")
+	ChildIDs        []string // Child node IDs
+	Depth           int      // Depth in hierarchy (0 for root)
+	Version         uint64   // Incremented on every write; checked by UpdateNode
+	Language        string   // Language of the text, such as "en"; empty if unknown
+	Checksum        string   // ContentChecksum of the text, set by the store on write; empty without text
+	SummaryChecksum string   // Checksum of the text the summary was written for, set by the store; see SummaryStale
+	TokenCount      int      // Estimated tokens in the text, set by the store on write; 0 if not counted
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
 // SearchResult represents a full-text search result
@@ -41,10 +54,22 @@ type SearchResult struct {
 	Summary  string
 	Score    float64 // BM25 score
 	Snippet  string  // Text snippet with matches
+	Node     *Node   // The matching node, set by SearchAll and SearchPolicies
+}
+
+// SearchFilter restricts search hits by structural attributes
+// Zero values disable the corresponding filter
+type SearchFilter struct {
+	PageFrom          int              // Hits must end on or after this page
+	PageTo            int              // Hits must start on or before this page
+	MaxDepth          *int             // Maximum node depth (nil for any)
+	SectionPathPrefix string           // Required section path prefix (e.g., "3.")
+	Match             func(*Node) bool // Extra caller-supplied predicate
 }
 
 // QueryOptions for hierarchical queries
 type QueryOptions struct {
 	MaxDepth   int  // Maximum depth to traverse
 	IncludeText bool // Include full text in results
+	Workers     int  // Parents whose children GetSubtree fetches at once; 0 or 1 fetches one at a time
 }
@@ -0,0 +1,292 @@
+// ABOUTME: Structural integrity check of a policy's node tree
+// ABOUTME: Reports orphans, cycles, children index drift, depth mismatches and bad page ranges
+
+package document
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// IssueKind names a class of tree integrity problem
+type IssueKind string
+
+const (
+	IssueOrphan             IssueKind = "orphan"               // Parent node does not exist
+	IssueCycle              IssueKind = "cycle"                // Parent links loop back on themselves
+	IssueDuplicateChild     IssueKind = "duplicate_child"      // Node is listed under more than one parent
+	IssueStaleChild         IssueKind = "stale_child"          // Index entry disagrees with the node's parent
+	IssueMissingChild       IssueKind = "missing_child"        // Node is absent from its parent's children index
+	IssueDepthMismatch      IssueKind = "depth_mismatch"       // Stored depth is not the node's distance from its root
+	IssueInvertedPages      IssueKind = "inverted_pages"       // Page range ends before it starts
+	IssueOverlappingPages   IssueKind = "overlapping_pages"    // Sibling page ranges overlap
+	IssuePagesOutsideParent IssueKind = "pages_outside_parent" // Page range extends past the parent's
+)
+
+// ValidationIssue is one integrity problem found in a tree
+type ValidationIssue struct {
+	Kind   IssueKind
+	NodeID string
+	Detail string
+}
+
+// ValidationReport lists the integrity problems of a policy's tree
+type ValidationReport struct {
+	NodesChecked int
+	Issues       []ValidationIssue // Ordered by node ID, then kind
+}
+
+// Valid reports whether no issues were found
+func (r *ValidationReport) Valid() bool {
+	return len(r.Issues) == 0
+}
+
+// ValidateDocument checks the structure of a policy's tree without changing it.
+// It reads every stored node and children index entry of the policy rather than
+// walking from the roots, so nodes left behind by a partially failed ingest are
+// checked too. Page ranges where both ends are zero are treated as unknown, and
+// siblings may share a boundary page.
+func (ss *SimpleStore) ValidateDocument(policyID string) (*ValidationReport, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	nodes, err := ss.scanPolicyNodes(policyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDocumentNotFound, policyID)
+	}
+
+	report := &ValidationReport{NodesChecked: len(nodes)}
+	add := func(kind IssueKind, nodeID, format string, args ...interface{}) {
+		report.Issues = append(report.Issues, ValidationIssue{Kind: kind, NodeID: nodeID, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if err := ss.validateChildrenIndex(policyID, nodes, add); err != nil {
+		return nil, err
+	}
+
+	siblings := make(map[string][]*Node)
+	for _, node := range nodes {
+		parentID := parentOf(node)
+		if parentID != "" && nodes[parentID] == nil {
+			add(IssueOrphan, node.NodeID, "parent %s does not exist", parentID)
+		}
+		siblings[parentID] = append(siblings[parentID], node)
+
+		if node.PageStart > node.PageEnd {
+			add(IssueInvertedPages, node.NodeID, "pages %d-%d", node.PageStart, node.PageEnd)
+		}
+	}
+
+	validateDepths(nodes, add)
+
+	for parentID, group := range siblings {
+		parent := nodes[parentID]
+		validatePages(parent, group, add)
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.NodeID != b.NodeID {
+			return a.NodeID < b.NodeID
+		}
+		return a.Kind < b.Kind
+	})
+	return report, nil
+}
+
+// scanPolicyNodes reads every stored node of a policy, keyed by node ID
+func (ss *SimpleStore) scanPolicyNodes(policyID string) (map[string]*Node, error) {
+	start := storage.EncodeKey(PREFIX_NODE, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+	})
+	nodes := make(map[string]*Node)
+	err := storage.ScanPrefix(ss.kv, start, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 2 {
+			return false
+		}
+		// Validation checks structure alone, so text is neither decoded nor read
+		if node, _, err := decodeStoredNode(val, skipLargeColumns); err == nil {
+			nodes[node.NodeID] = node
+		}
+		return true
+	})
+	return nodes, err
+}
+
+// validateChildrenIndex compares the children index with the nodes' parent links
+func (ss *SimpleStore) validateChildrenIndex(policyID string, nodes map[string]*Node, add func(IssueKind, string, string, ...interface{})) error {
+	start := storage.EncodeKey(PREFIX_CHILDREN, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+	})
+	listed := make(map[string][]string) // Parents each node is indexed under
+	err := storage.ScanPrefix(ss.kv, start, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 3 {
+			return false
+		}
+		parentID, nodeID := string(vals[1].Str), string(vals[2].Str)
+		listed[nodeID] = append(listed[nodeID], parentID)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for nodeID, parents := range listed {
+		node := nodes[nodeID]
+		if node == nil {
+			for _, parentID := range parents {
+				add(IssueStaleChild, nodeID, "indexed under %s but the node does not exist", describeParent(parentID))
+			}
+			continue
+		}
+		if len(parents) > 1 {
+			add(IssueDuplicateChild, nodeID, "indexed under %d parents", len(parents))
+		}
+		for _, parentID := range parents {
+			if parentID != parentOf(node) {
+				add(IssueStaleChild, nodeID, "indexed under %s but its parent is %s", describeParent(parentID), describeParent(parentOf(node)))
+			}
+		}
+	}
+
+	for nodeID, node := range nodes {
+		found := false
+		for _, parentID := range listed[nodeID] {
+			found = found || parentID == parentOf(node)
+		}
+		if !found {
+			add(IssueMissingChild, nodeID, "not indexed under %s", describeParent(parentOf(node)))
+		}
+	}
+	return nil
+}
+
+// validateDepths follows parent links from every node, reporting cycles and
+// depths that differ from the distance to the root. Nodes whose chain ends at an
+// orphan or a cycle have no known depth and are not compared.
+func validateDepths(nodes map[string]*Node, add func(IssueKind, string, string, ...interface{})) {
+	depths := make(map[string]int)
+	broken := make(map[string]bool)
+
+	ids := make([]string, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		var chain []string
+		onChain := make(map[string]int)
+		cur := id
+		depth, ok := 0, true
+		for {
+			if d, done := depths[cur]; done {
+				depth = d
+				break
+			}
+			if broken[cur] {
+				ok = false
+				break
+			}
+			if at, seen := onChain[cur]; seen {
+				// Report each cycle once, against its smallest node ID
+				loop := chain[at:]
+				first := loop[0]
+				for _, n := range loop {
+					if n < first {
+						first = n
+					}
+				}
+				add(IssueCycle, first, "cycle of %d nodes", len(loop))
+				ok = false
+				break
+			}
+			node := nodes[cur]
+			if node == nil {
+				ok = false // Orphan, already reported by the caller
+				break
+			}
+			onChain[cur] = len(chain)
+			chain = append(chain, cur)
+			if node.ParentID == nil {
+				depth = -1 // The root itself is pushed below and gets depth 0
+				break
+			}
+			cur = *node.ParentID
+		}
+
+		// Unwind the chain from the top, assigning depths on the way down
+		for i := len(chain) - 1; i >= 0; i-- {
+			n := chain[i]
+			if !ok {
+				broken[n] = true
+				continue
+			}
+			depth++
+			depths[n] = depth
+			if nodes[n].Depth != depth {
+				add(IssueDepthMismatch, n, "stored depth %d, expected %d", nodes[n].Depth, depth)
+			}
+		}
+	}
+}
+
+// validatePages checks one group of siblings against each other and their parent
+func validatePages(parent *Node, group []*Node, add func(IssueKind, string, string, ...interface{})) {
+	var ranged []*Node
+	for _, node := range group {
+		if hasPages(node) && node.PageStart <= node.PageEnd {
+			ranged = append(ranged, node)
+		}
+	}
+	sort.SliceStable(ranged, func(i, j int) bool {
+		if ranged[i].PageStart != ranged[j].PageStart {
+			return ranged[i].PageStart < ranged[j].PageStart
+		}
+		return ranged[i].NodeID < ranged[j].NodeID
+	})
+
+	var widest *Node // Earlier sibling reaching furthest
+	for _, node := range ranged {
+		if widest != nil && node.PageStart < widest.PageEnd {
+			add(IssueOverlappingPages, node.NodeID, "pages %d-%d overlap sibling %s at %d-%d",
+				node.PageStart, node.PageEnd, widest.NodeID, widest.PageStart, widest.PageEnd)
+		}
+		if widest == nil || node.PageEnd > widest.PageEnd {
+			widest = node
+		}
+		if parent != nil && hasPages(parent) && parent.PageStart <= parent.PageEnd &&
+			(node.PageStart < parent.PageStart || node.PageEnd > parent.PageEnd) {
+			add(IssuePagesOutsideParent, node.NodeID, "pages %d-%d outside parent %s at %d-%d",
+				node.PageStart, node.PageEnd, parent.NodeID, parent.PageStart, parent.PageEnd)
+		}
+	}
+}
+
+// hasPages reports whether a node's page range is known
+func hasPages(node *Node) bool {
+	return node.PageStart != 0 || node.PageEnd != 0
+}
+
+// parentOf returns a node's parent ID as stored in the children index, "" for roots
+func parentOf(node *Node) string {
+	if node.ParentID == nil {
+		return ""
+	}
+	return *node.ParentID
+}
+
+// describeParent names a children index parent for issue details
+func describeParent(parentID string) string {
+	if parentID == "" {
+		return "the roots"
+	}
+	return parentID
+}
@@ -0,0 +1,92 @@
+// ABOUTME: Tests for tree integrity validation
+// ABOUTME: Damages a stored tree directly and checks every kind of issue is reported
+
+package document
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestValidateDocument(t *testing.T) {
+	ds, kv, path := setupTestStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	root, a, b := "root", "a", "b"
+	nodes := []*Node{
+		{NodeID: root, PolicyID: "LCD-1", PageStart: 1, PageEnd: 10},
+		{NodeID: a, PolicyID: "LCD-1", ParentID: &root, Depth: 1, PageStart: 1, PageEnd: 4},
+		{NodeID: b, PolicyID: "LCD-1", ParentID: &root, Depth: 1, PageStart: 4, PageEnd: 10},
+		{NodeID: "a1", PolicyID: "LCD-1", ParentID: &a, Depth: 2},
+	}
+	if err := ds.StoreDocument(&Document{PolicyID: "LCD-1"}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	report, err := ds.ValidateDocument("LCD-1")
+	if err != nil {
+		t.Fatalf("ValidateDocument failed: %v", err)
+	}
+	if !report.Valid() || report.NodesChecked != 4 {
+		t.Fatalf("Expected a valid tree of 4 nodes, got %+v", report)
+	}
+
+	// Simulate what a partially failed ingest leaves behind
+	missing, x, y := "missing", "x", "y"
+	tx := kv.Begin()
+	putNode(tx, nil, &Node{NodeID: "orphan", PolicyID: "LCD-1", ParentID: &missing, Depth: 1})
+	putNode(tx, nil, &Node{NodeID: x, PolicyID: "LCD-1", ParentID: &y, Depth: 1})
+	putNode(tx, nil, &Node{NodeID: y, PolicyID: "LCD-1", ParentID: &x, Depth: 1})
+	putNode(tx, nil, &Node{NodeID: "deep", PolicyID: "LCD-1", ParentID: &a, Depth: 5, PageStart: 3, PageEnd: 2})
+	putNode(tx, nil, &Node{NodeID: "wide", PolicyID: "LCD-1", ParentID: &root, Depth: 1, PageStart: 2, PageEnd: 12})
+	tx.Set(childKey("LCD-1", &b, "a1"), []byte{})
+	tx.Del(childKey("LCD-1", &root, b))
+	tx.Set(childKey("LCD-1", &root, "ghost"), []byte{})
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	report, err = ds.ValidateDocument("LCD-1")
+	if err != nil {
+		t.Fatalf("ValidateDocument failed: %v", err)
+	}
+	if report.NodesChecked != 9 {
+		t.Errorf("Expected 9 nodes checked, got %d", report.NodesChecked)
+	}
+
+	got := make(map[IssueKind][]string)
+	for _, issue := range report.Issues {
+		got[issue.Kind] = append(got[issue.Kind], issue.NodeID)
+	}
+	want := map[IssueKind][]string{
+		IssueOrphan:             {"orphan"},
+		IssueCycle:              {x},
+		IssueDuplicateChild:     {"a1"},
+		IssueStaleChild:         {"a1", "ghost"},
+		IssueMissingChild:       {b},
+		IssueDepthMismatch:      {"deep"},
+		IssueInvertedPages:      {"deep"},
+		IssueOverlappingPages:   {b, "wide"},
+		IssuePagesOutsideParent: {"wide"},
+	}
+	for kind, ids := range want {
+		if len(got[kind]) != len(ids) {
+			t.Errorf("%s: expected %v, got %v", kind, ids, got[kind])
+			continue
+		}
+		for i := range ids {
+			if got[kind][i] != ids[i] {
+				t.Errorf("%s: expected %v, got %v", kind, ids, got[kind])
+			}
+		}
+	}
+	if len(report.Issues) != 11 {
+		t.Errorf("Expected 11 issues, got %+v", report.Issues)
+	}
+
+	if _, err := ds.ValidateDocument("LCD-404"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Expected ErrDocumentNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+// ABOUTME: Logical dump and load of every record, independent of the page layout
+// ABOUTME: A versioned JSONL stream of key-value pairs labelled by store, for migrations and copies
+
+package dump
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+const (
+	// Format identifies a dump in its header
+	Format = "treestore-dump"
+
+	// Version is the dump format written; Load reads this version and older
+	Version = 1
+
+	// loadBatchSize is the number of records loaded per transaction
+	loadBatchSize = 1000
+)
+
+var (
+	// ErrNotEmpty indicates a load into a database that already holds records
+	ErrNotEmpty = errors.New("dump: target database is not empty")
+
+	// ErrTruncated indicates a dump that ends before its trailer
+	ErrTruncated = errors.New("dump: truncated, no end record")
+)
+
+// Header is the first line of a dump
+type Header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Source    string    `json:"source,omitempty"`   // Path of the dumped database
+	LastLSN   uint64    `json:"last_lsn,omitempty"` // WAL position the dump reflects
+	Stores    []string  `json:"stores,omitempty"`   // Stores dumped; empty for all
+}
+
+// Record is one key-value pair. Keys and values are the bytes the stores
+// encode, so a dump loads into any database that reads those encodings,
+// whatever its page format. Encrypted values are written in plaintext.
+type Record struct {
+	Store  string `json:"store"`
+	Prefix uint32 `json:"prefix"`
+	Key    []byte `json:"key"`
+	Value  []byte `json:"value"`
+}
+
+// trailer is the last line of a dump, so a cut-off file is detected
+type trailer struct {
+	End     bool  `json:"end"`
+	Records int64 `json:"records"`
+}
+
+// line is any line of a dump, decoded before its kind is known
+type line struct {
+	Header
+	Record
+	trailer
+}
+
+// Stats counts the records of a dump by store
+type Stats struct {
+	Records int64
+	Stores  map[string]int64
+}
+
+func (s *Stats) add(store string) {
+	if s.Stores == nil {
+		s.Stores = make(map[string]int64)
+	}
+	s.Records++
+	s.Stores[store]++
+}
+
+// StoreOf names the store owning a key prefix; each store keeps its
+// prefixes within one block of a thousand
+func StoreOf(prefix uint32) string {
+	switch {
+	case prefix >= 1000 && prefix < 6000:
+		return "documents"
+	case prefix >= 6000 && prefix < 7000:
+		return "versions"
+	case prefix >= 7000 && prefix < 8000:
+		return "metadata"
+	case prefix >= 8000 && prefix < 9000:
+		return "conversations"
+	case prefix == 9000:
+		return "replication"
+	case prefix >= 9100 && prefix < 9300:
+		return "audit"
+	}
+	return "other"
+}
+
+// Write dumps the records of db to w, or only those of the named stores.
+// Records are written in key order; the database must not change meanwhile,
+// so dump a stopped server's file or a replica's.
+func Write(db *storage.KV, w io.Writer, stores []string) (*Stats, error) {
+	want := make(map[string]bool)
+	for _, s := range stores {
+		want[s] = true
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	header := Header{Format: Format, Version: Version, CreatedAt: time.Now().UTC(), Stores: stores}
+	if !db.InMemory() {
+		header.Source = db.Path
+		header.LastLSN = db.State().LastLSN
+	}
+	if err := enc.Encode(header); err != nil {
+		return nil, err
+	}
+
+	stats := &Stats{}
+	var err error
+	scanErr := db.Scan(nil, func(key, val []byte) bool {
+		if len(key) == 0 {
+			return true // B+Tree sentinel
+		}
+		rec := Record{Key: key, Value: val, Store: "other"}
+		if len(key) >= 4 {
+			rec.Prefix = storage.ExtractPrefix(key)
+			rec.Store = StoreOf(rec.Prefix)
+		}
+		if len(want) > 0 && !want[rec.Store] {
+			return true
+		}
+		if err = enc.Encode(rec); err != nil {
+			return false
+		}
+		stats.add(rec.Store)
+		return true
+	})
+	if err == nil {
+		err = scanErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(trailer{End: true, Records: stats.Records}); err != nil {
+		return nil, err
+	}
+	return stats, bw.Flush()
+}
+
+// Load writes the records of a dump into db, which must hold none yet.
+// Records are committed in batches, so a failed load leaves a partial
+// database that should be deleted.
+func Load(r io.Reader, db *storage.KV) (*Header, *Stats, error) {
+	empty := true
+	if err := db.Scan(nil, func(key, _ []byte) bool {
+		empty = len(key) == 0
+		return empty
+	}); err != nil {
+		return nil, nil, err
+	}
+	if !empty {
+		return nil, nil, ErrNotEmpty
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var header Header
+	if err := dec.Decode(&header); err != nil {
+		return nil, nil, fmt.Errorf("dump: reading header: %w", err)
+	}
+	if header.Format != Format {
+		return nil, nil, fmt.Errorf("dump: not a dump file (format %q)", header.Format)
+	}
+	if header.Version < 1 || header.Version > Version {
+		return nil, nil, fmt.Errorf("dump: unsupported version %d (this build reads up to %d)", header.Version, Version)
+	}
+
+	stats := &Stats{}
+	tx := db.Begin()
+	pending := 0
+	for {
+		var l line
+		err := dec.Decode(&l)
+		if err == io.EOF {
+			tx.Abort()
+			return nil, nil, ErrTruncated
+		}
+		if err != nil {
+			tx.Abort()
+			return nil, nil, fmt.Errorf("dump: record %d: %w", stats.Records+1, err)
+		}
+
+		if l.End {
+			if l.trailer.Records != stats.Records {
+				tx.Abort()
+				return nil, nil, fmt.Errorf("dump: end record counts %d records, read %d", l.trailer.Records, stats.Records)
+			}
+			break
+		}
+		if len(l.Key) == 0 {
+			tx.Abort()
+			return nil, nil, fmt.Errorf("dump: record %d has no key", stats.Records+1)
+		}
+
+		tx.Set(l.Key, l.Value)
+		stats.add(l.Record.Store)
+		if pending++; pending == loadBatchSize {
+			if err := tx.Commit(); err != nil {
+				return nil, nil, err
+			}
+			tx = db.Begin()
+			pending = 0
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return &header, stats, nil
+}
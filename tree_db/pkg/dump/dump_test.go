@@ -0,0 +1,173 @@
+// ABOUTME: Tests for logical dumps
+// ABOUTME: Verifies round trips, store filters, encrypted sources and damaged dumps
+
+package dump
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+func openTestKV(t *testing.T, path string) *storage.KV {
+	t.Helper()
+	kv := &storage.KV{Path: path}
+	if err := kv.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	t.Cleanup(func() { kv.Close() })
+	return kv
+}
+
+// fill writes records under a documents, a metadata and an audit prefix
+func fill(t *testing.T, kv *storage.KV, n int) {
+	t.Helper()
+	for _, prefix := range []uint32{2000, 7000, 9100} {
+		for i := 0; i < n; i++ {
+			key := storage.EncodeKey(prefix, []storage.Value{storage.NewBytesValue([]byte(fmt.Sprintf("id%04d", i)))})
+			if err := kv.Set(key, []byte(fmt.Sprintf("value %d/%d", prefix, i))); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+		}
+	}
+}
+
+// records returns every key and value of kv
+func records(t *testing.T, kv *storage.KV) map[string]string {
+	t.Helper()
+	out := make(map[string]string)
+	if err := kv.Scan(nil, func(key, val []byte) bool {
+		if len(key) > 0 {
+			out[string(key)] = string(val)
+		}
+		return true
+	}); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	return out
+}
+
+func TestDumpLoadRoundTrip(t *testing.T) {
+	tmp := t.TempDir()
+	src := openTestKV(t, filepath.Join(tmp, "src.db"))
+	fill(t, src, 1500) // More than one load batch per store
+
+	var buf bytes.Buffer
+	stats, err := Write(src, &buf, nil)
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if stats.Records != 4500 || stats.Stores["documents"] != 1500 || stats.Stores["audit"] != 1500 {
+		t.Errorf("Unexpected dump stats %+v", stats)
+	}
+
+	dst := openTestKV(t, filepath.Join(tmp, "dst.db"))
+	header, loaded, err := Load(bytes.NewReader(buf.Bytes()), dst)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if header.Version != Version || header.LastLSN == 0 || loaded.Records != stats.Records {
+		t.Errorf("Unexpected header %+v or load stats %+v", header, loaded)
+	}
+
+	want, got := records(t, src), records(t, dst)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d records, loaded %d", len(want), len(got))
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("Record %q: expected %q, got %q", k, v, got[k])
+		}
+	}
+
+	// A database with records refuses a second load
+	if _, _, err := Load(bytes.NewReader(buf.Bytes()), dst); !errors.Is(err, ErrNotEmpty) {
+		t.Errorf("Expected ErrNotEmpty, got %v", err)
+	}
+}
+
+func TestDumpStoreFilter(t *testing.T) {
+	src := openTestKV(t, filepath.Join(t.TempDir(), "src.db"))
+	fill(t, src, 10)
+
+	var buf bytes.Buffer
+	stats, err := Write(src, &buf, []string{"metadata"})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if stats.Records != 10 || len(stats.Stores) != 1 {
+		t.Errorf("Expected only the 10 metadata records, got %+v", stats)
+	}
+	if strings.Contains(buf.String(), `"store":"documents"`) {
+		t.Error("Dump holds records of an unselected store")
+	}
+}
+
+func TestDumpEncryptedSource(t *testing.T) {
+	keys, err := storage.ParseKeys("1:" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	if err != nil {
+		t.Fatalf("ParseKeys failed: %v", err)
+	}
+	enc, err := storage.NewEncryption(keys)
+	if err != nil {
+		t.Fatalf("NewEncryption failed: %v", err)
+	}
+
+	tmp := t.TempDir()
+	src := &storage.KV{Path: filepath.Join(tmp, "src.db"), Encryption: enc}
+	if err := src.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer src.Close()
+	fill(t, src, 3)
+
+	var buf bytes.Buffer
+	if _, err := Write(src, &buf, nil); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	// Values are dumped in plaintext, so the copy opens without keys
+	dst := openTestKV(t, filepath.Join(tmp, "dst.db"))
+	if _, _, err := Load(&buf, dst); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	for k, v := range records(t, src) {
+		if got, _ := dst.Get([]byte(k)); string(got) != v {
+			t.Fatalf("Record %q: expected %q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestLoadRejectsDamagedDumps(t *testing.T) {
+	src := openTestKV(t, filepath.Join(t.TempDir(), "src.db"))
+	fill(t, src, 5)
+	var buf bytes.Buffer
+	if _, err := Write(src, &buf, nil); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	lines := strings.SplitAfter(buf.String(), "\n")
+
+	cases := map[string]string{
+		"truncated":    strings.Join(lines[:len(lines)-2], ""),
+		"wrong format": `{"format":"other","version":1}` + "\n",
+		"newer":        strings.Replace(lines[0], `"version":1`, `"version":99`, 1),
+		"bad count":    strings.Join(lines[:len(lines)-3], "") + lines[len(lines)-2],
+	}
+	for name, data := range cases {
+		dst := openTestKV(t, filepath.Join(t.TempDir(), "dst.db"))
+		if _, _, err := Load(strings.NewReader(data), dst); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	dst := openTestKV(t, filepath.Join(t.TempDir(), "dst.db"))
+	if _, _, err := Load(strings.NewReader(cases["truncated"]), dst); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+}
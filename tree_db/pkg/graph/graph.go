@@ -0,0 +1,172 @@
+// ABOUTME: Renders node graphs as Graphviz DOT or Mermaid flowcharts
+// ABOUTME: Used to export document trees and cross-reference graphs as diagrams
+
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Output formats
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+// Node is a vertex of the graph. Nodes with the same Group are drawn together,
+// as a DOT cluster or a Mermaid subgraph; an empty Group is drawn at top level.
+type Node struct {
+	ID    string
+	Label string
+	Group string
+}
+
+// Edge connects two node IDs, with an optional label
+type Edge struct {
+	From  string
+	To    string
+	Label string
+}
+
+// Graph is a directed graph drawn in the order its nodes and edges were added
+type Graph struct {
+	Name  string
+	Nodes []Node
+	Edges []Edge
+
+	index map[string]int
+}
+
+// AddNode adds a node unless one with the same ID exists, reporting whether it was added
+func (g *Graph) AddNode(n Node) bool {
+	if g.index == nil {
+		g.index = make(map[string]int)
+	}
+	if _, ok := g.index[n.ID]; ok {
+		return false
+	}
+	g.index[n.ID] = len(g.Nodes)
+	g.Nodes = append(g.Nodes, n)
+	return true
+}
+
+// HasNode reports whether a node with the ID was added
+func (g *Graph) HasNode(id string) bool {
+	_, ok := g.index[id]
+	return ok
+}
+
+// AddEdge adds an edge; its endpoints should be added as nodes too
+func (g *Graph) AddEdge(e Edge) {
+	g.Edges = append(g.Edges, e)
+}
+
+// Render writes the graph in the given format
+func (g *Graph) Render(format string) (string, error) {
+	switch format {
+	case FormatDOT:
+		return g.DOT(), nil
+	case FormatMermaid:
+		return g.Mermaid(), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q (want %s or %s)", format, FormatDOT, FormatMermaid)
+}
+
+// groups returns the node indexes of each group in order of first appearance,
+// with the ungrouped nodes under ""
+func (g *Graph) groups() ([]string, map[string][]int) {
+	var order []string
+	members := make(map[string][]int)
+	for i, n := range g.Nodes {
+		if _, ok := members[n.Group]; !ok {
+			order = append(order, n.Group)
+		}
+		members[n.Group] = append(members[n.Group], i)
+	}
+	return order, members
+}
+
+// DOT renders the graph for Graphviz. Node IDs are quoted, so any ID is valid.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.Name))
+	b.WriteString("  node [shape=box];\n")
+
+	order, members := g.groups()
+	cluster := 0
+	for _, group := range order {
+		indent := "  "
+		if group != "" {
+			fmt.Fprintf(&b, "  subgraph cluster_%d {\n    label=%s;\n", cluster, dotQuote(group))
+			indent = "    "
+			cluster++
+		}
+		for _, i := range members[group] {
+			n := g.Nodes[i]
+			fmt.Fprintf(&b, "%s%s [label=%s];\n", indent, dotQuote(n.ID), dotQuote(n.Label))
+		}
+		if group != "" {
+			b.WriteString("  }\n")
+		}
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(e.From), dotQuote(e.To))
+		if e.Label != "" {
+			fmt.Fprintf(&b, " [label=%s]", dotQuote(e.Label))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a top-down flowchart. Mermaid node IDs must be
+// plain words, so nodes are numbered n0, n1, ... and shown by label.
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+
+	order, members := g.groups()
+	for s, group := range order {
+		indent := "  "
+		if group != "" {
+			fmt.Fprintf(&b, "  subgraph s%d[%s]\n", s, mermaidQuote(group))
+			indent = "    "
+		}
+		for _, i := range members[group] {
+			fmt.Fprintf(&b, "%sn%d[%s]\n", indent, i, mermaidQuote(g.Nodes[i].Label))
+		}
+		if group != "" {
+			b.WriteString("  end\n")
+		}
+	}
+
+	for _, e := range g.Edges {
+		from, ok := g.index[e.From]
+		to, ok2 := g.index[e.To]
+		if !ok || !ok2 {
+			continue
+		}
+		if e.Label != "" {
+			fmt.Fprintf(&b, "  n%d -->|%s| n%d\n", from, mermaidQuote(e.Label), to)
+		} else {
+			fmt.Fprintf(&b, "  n%d --> n%d\n", from, to)
+		}
+	}
+	return b.String()
+}
+
+// dotQuote returns s as a DOT string literal
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+	return `"` + r.Replace(s) + `"`
+}
+
+// mermaidQuote returns s as a quoted Mermaid label, with the characters that
+// would end it written as entity codes
+func mermaidQuote(s string) string {
+	r := strings.NewReplacer(`"`, "#quot;", "\n", " ", "\r", "")
+	return `"` + r.Replace(s) + `"`
+}
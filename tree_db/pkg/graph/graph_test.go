@@ -0,0 +1,70 @@
+// ABOUTME: Tests for DOT and Mermaid rendering
+// ABOUTME: Checks grouping, edge labels and escaping of awkward labels
+
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+func testGraph() *Graph {
+	g := &Graph{Name: "POL-1 references"}
+	g.AddNode(Node{ID: "POL-1/a", Label: `Say "prior auth"`, Group: "POL-1"})
+	g.AddNode(Node{ID: "POL-1/b", Label: "Imaging", Group: "POL-1"})
+	g.AddNode(Node{ID: "POL-2/x", Label: `C:\path`, Group: "POL-2"})
+	if g.AddNode(Node{ID: "POL-1/a", Label: "duplicate"}) {
+		panic("duplicate node added")
+	}
+	g.AddEdge(Edge{From: "POL-1/a", To: "POL-2/x", Label: "cites"})
+	g.AddEdge(Edge{From: "POL-1/a", To: "POL-1/b"})
+	return g
+}
+
+func TestDOT(t *testing.T) {
+	out, err := testGraph().Render(FormatDOT)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`digraph "POL-1 references" {`,
+		"  subgraph cluster_0 {\n    label=\"POL-1\";\n",
+		`    "POL-1/a" [label="Say \"prior auth\""];`,
+		`    "POL-2/x" [label="C:\\path"];`,
+		`  "POL-1/a" -> "POL-2/x" [label="cites"];`,
+		`  "POL-1/a" -> "POL-1/b";`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "duplicate") {
+		t.Error("Duplicate node was rendered")
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	out, err := testGraph().Render(FormatMermaid)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"flowchart TD\n",
+		"  subgraph s0[\"POL-1\"]\n    n0[\"Say #quot;prior auth#quot;\"]\n    n1[\"Imaging\"]\n  end\n",
+		"  subgraph s1[\"POL-2\"]\n    n2[\"C:\\path\"]\n  end\n",
+		"  n0 -->|\"cites\"| n2\n",
+		"  n0 --> n1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Mermaid output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	if _, err := testGraph().Render("svg"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,98 @@
+// ABOUTME: Jobs run by an external analysis service over HTTP, configured in YAML
+// ABOUTME: The service receives the job and policy and answers with the tool results and contradictions it found
+
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// maxResponse bounds the response read from an analysis service
+const maxResponse = 1 << 20
+
+// HTTPJob posts {"job": name, "policy_id": id} to a URL and reads the Output
+// the service answers with as JSON. Any status but 200 fails the attempt.
+type HTTPJob struct {
+	JobName string
+	URL     string
+	Client  *http.Client // nil uses http.DefaultClient; the runner's timeout applies either way
+}
+
+// Name returns the job's name
+func (j *HTTPJob) Name() string { return j.JobName }
+
+// Run asks the service to analyse a policy
+func (j *HTTPJob) Run(ctx context.Context, policyID string) (*Output, error) {
+	body, err := json.Marshal(map[string]string{"job": j.JobName, "policy_id": policyID})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := j.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(data) > 200 {
+			data = data[:200]
+		}
+		return nil, fmt.Errorf("%s answered %s: %s", j.URL, resp.Status, bytes.TrimSpace(data))
+	}
+	var out Output
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("%s answered with invalid output: %v", j.URL, err)
+	}
+	return &out, nil
+}
+
+// jobsFile is the YAML form LoadJobs reads
+type jobsFile struct {
+	Jobs []struct {
+		Name string `yaml:"name"`
+		URL  string `yaml:"url"`
+	} `yaml:"jobs"`
+}
+
+// LoadJobs reads HTTP jobs from a YAML file listing each job's name and url
+func LoadJobs(path string) ([]Job, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file jobsFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	jobs := make([]Job, 0, len(file.Jobs))
+	for i, j := range file.Jobs {
+		if j.Name == "" || j.URL == "" {
+			return nil, fmt.Errorf("%s: job %d needs a name and a url", path, i+1)
+		}
+		jobs = append(jobs, &HTTPJob{JobName: j.Name, URL: j.URL})
+	}
+	return jobs, nil
+}
@@ -0,0 +1,572 @@
+// ABOUTME: Analysis jobs, such as contradiction detection, run over documents as they change
+// ABOUTME: A runner queues jobs from the change feed, retries failures with backoff and keeps each run's status
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// Defaults applied by NewRunner
+const (
+	DefaultInterval    = 10 * time.Second
+	DefaultTimeout     = 5 * time.Minute
+	DefaultMaxAttempts = 3
+	DefaultRetryDelay  = 30 * time.Second
+)
+
+// ErrUnknownJob is returned when no job is registered under a name
+var ErrUnknownJob = errors.New("jobs: unknown job")
+
+// Job analyses one policy's document and returns what it found
+type Job interface {
+	Name() string
+	Run(ctx context.Context, policyID string) (*Output, error)
+}
+
+// JobFunc adapts a function to a Job
+type JobFunc struct {
+	JobName string
+	Func    func(ctx context.Context, policyID string) (*Output, error)
+}
+
+// Name returns the job's name
+func (f JobFunc) Name() string { return f.JobName }
+
+// Run calls the function
+func (f JobFunc) Run(ctx context.Context, policyID string) (*Output, error) {
+	return f.Func(ctx, policyID)
+}
+
+// Output is what one run of a job found in a policy
+type Output struct {
+	ToolResults    []ToolResult    `json:"tool_results"`
+	Contradictions []Contradiction `json:"contradictions"`
+}
+
+// ToolResult is a finding stored as a tool result of the run's policy
+type ToolResult struct {
+	ExecutionID string `json:"execution_id"` // Defaults to "job/policy/index", so a rerun replaces it
+	ToolName    string `json:"tool_name"`    // Defaults to the job's name
+	NodeID      string `json:"node_id"`
+	Data        string `json:"data"`
+}
+
+// Contradiction is a conflict a job detected
+type Contradiction struct {
+	ContradictionID string `json:"contradiction_id"` // Defaults to "job/policy/index", so a rerun replaces it
+	Severity        string `json:"severity"`
+}
+
+// Sink stores the output of successful runs
+type Sink interface {
+	Store(job, policyID string, out *Output, at time.Time) error
+}
+
+// SinkFunc adapts a function to a Sink
+type SinkFunc func(job, policyID string, out *Output, at time.Time) error
+
+// Store calls f
+func (f SinkFunc) Store(job, policyID string, out *Output, at time.Time) error {
+	return f(job, policyID, out, at)
+}
+
+// State is where a run is in its lifecycle
+type State string
+
+const (
+	StatePending   State = "pending"   // Queued for the next pass
+	StateRunning   State = "running"   // Being run; a restart requeues it
+	StateRetrying  State = "retrying"  // Failed, and queued again for NextAttempt
+	StateSucceeded State = "succeeded" // Its output is stored
+	StateFailed    State = "failed"    // Failed MaxAttempts times
+)
+
+// Run is the latest run of a job over a policy; each pair keeps one
+type Run struct {
+	Job         string
+	PolicyID    string
+	State       State
+	Attempts    int    // Attempts since it was last queued
+	LastError   string // Error of the last failed attempt, cleared on success
+	QueuedAt    time.Time
+	StartedAt   time.Time // Start of the last attempt
+	FinishedAt  time.Time // End of the last attempt
+	NextAttempt time.Time // When a retrying run is due
+	Outputs     int       // Tool results and contradictions stored by the last success
+	Rerun       bool      // The document changed while running; it is queued again once done
+}
+
+// Due reports whether a pass at now should run r
+// Running runs are due too: a pass only finds them after the runner was
+// interrupted mid-run.
+func (r *Run) Due(now time.Time) bool {
+	switch r.State {
+	case StatePending, StateRunning:
+		return true
+	case StateRetrying:
+		return !now.Before(r.NextAttempt)
+	}
+	return false
+}
+
+// RunFilter selects runs; empty fields match every run
+type RunFilter struct {
+	Job      string
+	PolicyID string
+	State    State
+}
+
+// Config configures a Runner
+type Config struct {
+	Jobs        []Job
+	Interval    time.Duration            // Time between passes over queued runs
+	Timeout     time.Duration            // Limit on one attempt
+	MaxAttempts int                      // Attempts before a run fails
+	RetryDelay  time.Duration            // Wait before the first retry; it doubles with every further attempt
+	Policies    func() ([]string, error) // Lists every policy, queued for every job after missed feed events; nil queues none
+	OnPass      func(*PassReport)        // Called after every pass that ran something, e.g. to log or export metrics
+	Lock        sync.Locker              // Held while writing runs and outputs, e.g. to keep out of maintenance; nil takes none
+}
+
+// PassReport describes the outcome of one pass
+type PassReport struct {
+	StartedAt time.Time
+	Duration  time.Duration
+	Runs      []*Run // Runs attempted, as they were left
+	Err       error  // Failure to read or write runs, which ends the pass
+}
+
+// Stats accumulates runner activity since it was created
+type Stats struct {
+	Passes    int64
+	Attempts  int64
+	Succeeded int64
+	Retried   int64
+	Failed    int64
+	Lagged    int64 // Times the runner fell behind the change feed
+	LastPass  *PassReport
+}
+
+// Runner runs jobs over the policies the change feed reports written
+type Runner struct {
+	kv   *storage.KV
+	feed *changefeed.Feed
+	sink Sink
+	cfg  Config
+	jobs map[string]Job
+
+	passMu sync.Mutex // Serializes passes
+	runMu  sync.Mutex // Serializes read-modify-write of run records
+
+	mu     sync.Mutex
+	stats  Stats
+	ctx    context.Context // Cancelled by Stop to abandon running attempts
+	cancel context.CancelFunc
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewRunner creates a runner of cfg.Jobs over kv that stores outputs in sink
+// and queues runs for the documents feed reports written
+func NewRunner(kv *storage.KV, feed *changefeed.Feed, sink Sink, cfg Config) (*Runner, error) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = DefaultRetryDelay
+	}
+
+	registered := make(map[string]Job, len(cfg.Jobs))
+	for _, job := range cfg.Jobs {
+		name := job.Name()
+		if name == "" {
+			return nil, fmt.Errorf("job needs a name")
+		}
+		if _, ok := registered[name]; ok {
+			return nil, fmt.Errorf("job %q is registered twice", name)
+		}
+		registered[name] = job
+	}
+
+	return &Runner{
+		kv:   kv,
+		feed: feed,
+		sink: sink,
+		cfg:  cfg,
+		jobs: registered,
+		ctx:  context.Background(),
+		wake: make(chan struct{}, 1),
+	}, nil
+}
+
+// Jobs returns the names of the registered jobs
+func (r *Runner) Jobs() []string {
+	names := make([]string, 0, len(r.cfg.Jobs))
+	for _, job := range r.cfg.Jobs {
+		names = append(names, job.Name())
+	}
+	return names
+}
+
+// Start watches the change feed and runs passes in the background until
+// Stop is called
+func (r *Runner) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		return
+	}
+
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.run(r.stop, r.done)
+}
+
+// Stop ends background work, cancelling a running attempt, and waits for
+// the pass to finish; the interrupted run is retried after the next Start
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	stop, done, cancel := r.stop, r.done, r.cancel
+	r.stop, r.done = nil, nil
+	r.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	cancel()
+	<-done
+}
+
+// Stats returns a snapshot of the runner's activity
+func (r *Runner) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// Enqueue queues every job for a policy as of now. A run already queued is
+// left alone, and one in progress is queued again once it is done.
+func (r *Runner) Enqueue(policyID string, now time.Time) error {
+	for _, job := range r.cfg.Jobs {
+		if _, err := r.enqueue(job.Name(), policyID, now, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Trigger queues a job for a policy for the next pass, which it starts
+// early. Unlike Enqueue it also brings forward a run waiting to retry.
+func (r *Runner) Trigger(job, policyID string, now time.Time) (*Run, error) {
+	if _, ok := r.jobs[job]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJob, job)
+	}
+	run, err := r.enqueue(job, policyID, now, true)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+	return run, nil
+}
+
+// enqueue queues one run and returns it as stored
+func (r *Runner) enqueue(job, policyID string, now time.Time, force bool) (*Run, error) {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+	r.lock()
+	defer r.unlock()
+
+	run, err := getRun(r.kv, job, policyID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case run == nil, run.State == StateSucceeded, run.State == StateFailed, force && run.State == StateRetrying:
+		run = &Run{Job: job, PolicyID: policyID, State: StatePending, QueuedAt: now}
+	case run.State == StateRunning:
+		if run.Rerun {
+			return run, nil
+		}
+		run.Rerun = true
+	default:
+		return run, nil
+	}
+	return run, putRun(r.kv, run)
+}
+
+// Runs returns the runs matching f, by job and then policy
+func (r *Runner) Runs(f RunFilter) ([]*Run, error) {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+	return ListRuns(r.kv, f)
+}
+
+// RunOnce attempts every run due as of now, one at a time
+// A pass stops at the first run record it cannot read or write; a failing
+// job only fails its own run.
+func (r *Runner) RunOnce(now time.Time) *PassReport {
+	r.passMu.Lock()
+	defer r.passMu.Unlock()
+
+	report := &PassReport{StartedAt: now}
+	start := time.Now()
+
+	runs, err := r.Runs(RunFilter{})
+	if err != nil {
+		report.Err = err
+	}
+	for _, run := range runs {
+		if _, ok := r.jobs[run.Job]; !ok || !run.Due(now) {
+			continue
+		}
+		done, err := r.attempt(run, now.Add(time.Since(start)))
+		if err != nil {
+			report.Err = err
+			break
+		}
+		report.Runs = append(report.Runs, done)
+	}
+	report.Duration = time.Since(start)
+
+	r.mu.Lock()
+	r.stats.Passes++
+	for _, run := range report.Runs {
+		r.stats.Attempts++
+		switch run.State {
+		case StateSucceeded:
+			r.stats.Succeeded++
+		case StateRetrying:
+			r.stats.Retried++
+		case StateFailed:
+			r.stats.Failed++
+		}
+	}
+	r.stats.LastPass = report
+	r.mu.Unlock()
+
+	if r.cfg.OnPass != nil && (len(report.Runs) > 0 || report.Err != nil) {
+		r.cfg.OnPass(report)
+	}
+	return report
+}
+
+// attempt runs a due job once and records the outcome, returning the run as
+// left: succeeded, retrying or failed. A change that arrived meanwhile queues
+// the run again, though the attempt's outcome is still returned.
+func (r *Runner) attempt(run *Run, now time.Time) (*Run, error) {
+	started := *run
+	started.State = StateRunning
+	started.Attempts++
+	started.StartedAt = now
+	started.Rerun = false
+	claimed, err := r.update(run.Job, run.PolicyID, func(cur *Run) *Run {
+		if cur != nil && !cur.Due(now) {
+			return nil
+		}
+		return &started
+	})
+	if err != nil || !claimed {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	ctx := r.ctx
+	r.mu.Unlock()
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	begin := time.Now()
+	out, err := r.jobs[run.Job].Run(ctx, run.PolicyID)
+	cancel()
+	end := now.Add(time.Since(begin))
+
+	if err == nil {
+		if out == nil {
+			out = &Output{}
+		}
+		fillDefaults(run.Job, run.PolicyID, out)
+		r.lock()
+		err = r.sink.Store(run.Job, run.PolicyID, out, end)
+		r.unlock()
+		if err != nil {
+			err = fmt.Errorf("storing output: %w", err)
+		}
+	}
+
+	done := started
+	done.FinishedAt = end
+	switch {
+	case err == nil:
+		done.State = StateSucceeded
+		done.LastError = ""
+		done.Outputs = len(out.ToolResults) + len(out.Contradictions)
+	case done.Attempts >= r.cfg.MaxAttempts:
+		done.State = StateFailed
+		done.LastError = truncateError(err.Error())
+	default:
+		done.State = StateRetrying
+		done.LastError = truncateError(err.Error())
+		done.NextAttempt = end.Add(r.cfg.RetryDelay << (done.Attempts - 1))
+	}
+
+	_, err = r.update(run.Job, run.PolicyID, func(cur *Run) *Run {
+		if cur == nil || !cur.Rerun {
+			return &done
+		}
+		queued := done
+		queued.State = StatePending
+		queued.Attempts = 0
+		queued.QueuedAt = end
+		queued.NextAttempt = time.Time{}
+		return &queued
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &done, nil
+}
+
+// update replaces the stored run of a job over a policy with what change
+// returns for it, nil when there is none; it reports whether change returned a run
+func (r *Runner) update(job, policyID string, change func(cur *Run) *Run) (bool, error) {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+	r.lock()
+	defer r.unlock()
+
+	cur, err := getRun(r.kv, job, policyID)
+	if err != nil {
+		return false, err
+	}
+	next := change(cur)
+	if next == nil {
+		return false, nil
+	}
+	return true, putRun(r.kv, next)
+}
+
+func (r *Runner) lock() {
+	if r.cfg.Lock != nil {
+		r.cfg.Lock.Lock()
+	}
+}
+
+func (r *Runner) unlock() {
+	if r.cfg.Lock != nil {
+		r.cfg.Lock.Unlock()
+	}
+}
+
+// fillDefaults gives the findings of a run the IDs and tool name they lack
+func fillDefaults(job, policyID string, out *Output) {
+	for i := range out.ToolResults {
+		if out.ToolResults[i].ExecutionID == "" {
+			out.ToolResults[i].ExecutionID = fmt.Sprintf("%s/%s/%d", job, policyID, i)
+		}
+		if out.ToolResults[i].ToolName == "" {
+			out.ToolResults[i].ToolName = job
+		}
+	}
+	for i := range out.Contradictions {
+		if out.Contradictions[i].ContradictionID == "" {
+			out.Contradictions[i].ContradictionID = fmt.Sprintf("%s/%s/%d", job, policyID, i)
+		}
+	}
+}
+
+// run watches the feed and runs a pass on every interval tick or trigger
+// until stop is closed
+func (r *Runner) run(stop, done chan struct{}) {
+	defer close(done)
+
+	var watching sync.WaitGroup
+	watching.Add(1)
+	go func() {
+		defer watching.Done()
+		r.watch(stop)
+	}()
+	defer watching.Wait()
+
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			r.RunOnce(now)
+		case <-r.wake:
+			r.RunOnce(time.Now())
+		}
+	}
+}
+
+// watch queues the jobs of every policy whose document the feed reports
+// written, until stop is closed or the feed closes. After falling behind it
+// resubscribes and queues every policy, since it cannot tell which it missed.
+func (r *Runner) watch(stop chan struct{}) {
+	if r.feed == nil {
+		return
+	}
+	prefixes := []string{changefeed.EntityDocument + "/"}
+	sub := r.feed.Subscribe(prefixes)
+	defer func() { sub.Close() }()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case ev, ok := <-sub.Events():
+			if ok {
+				if ev.Op == changefeed.OpPut && ev.PolicyID != "" {
+					r.Enqueue(ev.PolicyID, ev.Timestamp)
+				}
+				continue
+			}
+			if !errors.Is(sub.Err(), changefeed.ErrLagged) {
+				return
+			}
+			sub = r.feed.Subscribe(prefixes)
+			r.mu.Lock()
+			r.stats.Lagged++
+			r.mu.Unlock()
+			r.enqueueAll(time.Now())
+		}
+	}
+}
+
+// enqueueAll queues every job for every policy Config.Policies lists
+func (r *Runner) enqueueAll(now time.Time) {
+	if r.cfg.Policies == nil {
+		return
+	}
+	policies, err := r.cfg.Policies()
+	if err != nil {
+		return
+	}
+	for _, policyID := range policies {
+		if err := r.Enqueue(policyID, now); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,264 @@
+// ABOUTME: Tests for the analysis job runner and HTTP jobs
+// ABOUTME: Verifies queueing from the change feed, retries with backoff, reruns, triggers and loading jobs from YAML
+
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// recordingSink keeps the outputs it is given
+type recordingSink struct {
+	mu      sync.Mutex
+	outputs map[string]*Output // By "job/policy"
+}
+
+func (s *recordingSink) Store(job, policyID string, out *Output, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.outputs == nil {
+		s.outputs = make(map[string]*Output)
+	}
+	s.outputs[job+"/"+policyID] = out
+	return nil
+}
+
+func (s *recordingSink) get(key string) *Output {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.outputs[key]
+}
+
+func setupRunner(t *testing.T, feed *changefeed.Feed, cfg Config) (*storage.KV, *recordingSink, *Runner) {
+	kv := &storage.KV{Path: storage.MemoryPath}
+	if err := kv.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	t.Cleanup(func() { kv.Close() })
+
+	sink := &recordingSink{}
+	r, err := NewRunner(kv, feed, sink, cfg)
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+	return kv, sink, r
+}
+
+func getState(t *testing.T, r *Runner, job, policyID string) *Run {
+	t.Helper()
+	runs, err := r.Runs(RunFilter{Job: job, PolicyID: policyID})
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("Expected one run of %s over %s, got %v (%v)", job, policyID, runs, err)
+	}
+	return runs[0]
+}
+
+func TestRunOnce(t *testing.T) {
+	failures := 2
+	contradictions := JobFunc{JobName: "contradictions", Func: func(ctx context.Context, policyID string) (*Output, error) {
+		return &Output{
+			ToolResults:    []ToolResult{{NodeID: "n-1", Data: `{"pairs":1}`}},
+			Contradictions: []Contradiction{{Severity: "high"}},
+		}, nil
+	}}
+	flaky := JobFunc{JobName: "flaky", Func: func(ctx context.Context, policyID string) (*Output, error) {
+		if failures > 0 {
+			failures--
+			return nil, errors.New("service unavailable")
+		}
+		return nil, nil
+	}}
+	_, sink, r := setupRunner(t, nil, Config{Jobs: []Job{contradictions, flaky}, RetryDelay: time.Minute})
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := r.Enqueue("LCD-1", now); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	report := r.RunOnce(now)
+	if report.Err != nil || len(report.Runs) != 2 {
+		t.Fatalf("Expected both jobs run, got %+v", report)
+	}
+
+	// Outputs get IDs and the job's name as tool
+	out := sink.get("contradictions/LCD-1")
+	if out == nil || out.ToolResults[0].ExecutionID != "contradictions/LCD-1/0" ||
+		out.ToolResults[0].ToolName != "contradictions" || out.Contradictions[0].ContradictionID != "contradictions/LCD-1/0" {
+		t.Errorf("Expected defaulted outputs, got %+v", out)
+	}
+	if run := getState(t, r, "contradictions", "LCD-1"); run.State != StateSucceeded || run.Attempts != 1 || run.Outputs != 2 {
+		t.Errorf("Expected a success with 2 outputs, got %+v", run)
+	}
+
+	// Failures wait twice as long before each retry
+	run := getState(t, r, "flaky", "LCD-1")
+	if run.State != StateRetrying || run.LastError != "service unavailable" || !run.NextAttempt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("Expected a retry in a minute, got %+v", run)
+	}
+	if report := r.RunOnce(now.Add(30 * time.Second)); len(report.Runs) != 0 {
+		t.Errorf("Expected nothing due yet, got %+v", report.Runs)
+	}
+	r.RunOnce(now.Add(time.Minute))
+	if run := getState(t, r, "flaky", "LCD-1"); run.State != StateRetrying || !run.NextAttempt.Equal(now.Add(3*time.Minute)) {
+		t.Errorf("Expected a second retry two minutes later, got %+v", run)
+	}
+	r.RunOnce(now.Add(3 * time.Minute))
+	if run := getState(t, r, "flaky", "LCD-1"); run.State != StateSucceeded || run.Attempts != 3 || run.LastError != "" {
+		t.Errorf("Expected success on the third attempt, got %+v", run)
+	}
+
+	stats := r.Stats()
+	if stats.Attempts != 4 || stats.Succeeded != 2 || stats.Retried != 2 || stats.Failed != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if runs, err := r.Runs(RunFilter{State: StateSucceeded}); err != nil || len(runs) != 2 {
+		t.Errorf("Expected 2 succeeded runs, got %v (%v)", runs, err)
+	}
+}
+
+func TestRunFailsAndRetriggers(t *testing.T) {
+	broken := JobFunc{JobName: "broken", Func: func(ctx context.Context, policyID string) (*Output, error) {
+		return nil, errors.New("bad model")
+	}}
+	_, _, r := setupRunner(t, nil, Config{Jobs: []Job{broken}, MaxAttempts: 2, RetryDelay: time.Second})
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := r.Trigger("broken", "LCD-1", now); err != nil {
+		t.Fatalf("Trigger failed: %v", err)
+	}
+	r.RunOnce(now)
+	r.RunOnce(now.Add(time.Second))
+	if run := getState(t, r, "broken", "LCD-1"); run.State != StateFailed || run.Attempts != 2 {
+		t.Fatalf("Expected a failure after 2 attempts, got %+v", run)
+	}
+	if report := r.RunOnce(now.Add(time.Hour)); len(report.Runs) != 0 {
+		t.Errorf("Expected a failed run left alone, got %+v", report.Runs)
+	}
+
+	// Triggering starts over
+	run, err := r.Trigger("broken", "LCD-1", now.Add(time.Hour))
+	if err != nil || run.State != StatePending || run.Attempts != 0 {
+		t.Errorf("Expected a fresh pending run, got %+v (%v)", run, err)
+	}
+	if _, err := r.Trigger("missing", "LCD-1", now); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("Expected ErrUnknownJob, got %v", err)
+	}
+}
+
+func TestRerunAfterChange(t *testing.T) {
+	var r *Runner
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	runs := 0
+	job := JobFunc{JobName: "summaries", Func: func(ctx context.Context, policyID string) (*Output, error) {
+		runs++
+		if runs == 1 {
+			// The document changes while the job reads it
+			if err := r.Enqueue(policyID, now); err != nil {
+				t.Errorf("Enqueue failed: %v", err)
+			}
+		}
+		return nil, nil
+	}}
+	_, _, r = setupRunner(t, nil, Config{Jobs: []Job{job}})
+
+	r.Enqueue("LCD-1", now)
+	if report := r.RunOnce(now); len(report.Runs) != 1 || report.Runs[0].State != StateSucceeded {
+		t.Fatalf("Expected the first run to succeed, got %+v", report)
+	}
+	if run := getState(t, r, "summaries", "LCD-1"); run.State != StatePending {
+		t.Fatalf("Expected the run queued again, got %+v", run)
+	}
+	r.RunOnce(now.Add(time.Second))
+	if run := getState(t, r, "summaries", "LCD-1"); run.State != StateSucceeded || runs != 2 {
+		t.Errorf("Expected a second run, got %+v after %d runs", run, runs)
+	}
+}
+
+func TestRunnerWatchesFeed(t *testing.T) {
+	feed := changefeed.NewFeed(1)
+	ran := make(chan string, 10)
+	job := JobFunc{JobName: "contradictions", Func: func(ctx context.Context, policyID string) (*Output, error) {
+		ran <- policyID
+		return nil, nil
+	}}
+	_, _, r := setupRunner(t, feed, Config{
+		Jobs:     []Job{job},
+		Interval: 10 * time.Millisecond,
+		Policies: func() ([]string, error) { return []string{"LCD-1", "LCD-2"}, nil },
+	})
+	r.Start()
+	defer r.Stop()
+
+	// Wait for the subscription, then write a document and a metadata entry
+	for feed.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	feed.Publish(changefeed.EntityMetadata, changefeed.OpPut, "", "tool_result/x")
+	feed.Publish(changefeed.EntityDocument, changefeed.OpPut, "LCD-1", "")
+	select {
+	case policyID := <-ran:
+		if policyID != "LCD-1" {
+			t.Errorf("Expected LCD-1 analysed, got %s", policyID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the changed document analysed")
+	}
+
+	// Falling behind queues every policy
+	for i := 0; i < 5; i++ {
+		feed.Publish(changefeed.EntityDocument, changefeed.OpPut, "LCD-2", "")
+	}
+	deadline := time.After(5 * time.Second)
+	for seen := map[string]bool{}; !seen["LCD-2"]; {
+		select {
+		case policyID := <-ran:
+			seen[policyID] = true
+		case <-deadline:
+			t.Fatal("Expected LCD-2 analysed after the runner fell behind")
+		}
+	}
+}
+
+func TestHTTPJob(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body map[string]string
+		json.NewDecoder(req.Body).Decode(&body)
+		if body["policy_id"] == "down" {
+			http.Error(w, "model overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(Output{Contradictions: []Contradiction{{ContradictionID: body["job"] + "-" + body["policy_id"], Severity: "low"}}})
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "jobs.yaml")
+	os.WriteFile(path, []byte("jobs:\n  - name: contradictions\n    url: "+srv.URL+"\n"), 0o644)
+	jobs, err := LoadJobs(path)
+	if err != nil || len(jobs) != 1 || jobs[0].Name() != "contradictions" {
+		t.Fatalf("Expected one job loaded, got %v (%v)", jobs, err)
+	}
+
+	out, err := jobs[0].Run(context.Background(), "LCD-1")
+	if err != nil || len(out.Contradictions) != 1 || out.Contradictions[0].ContradictionID != "contradictions-LCD-1" {
+		t.Errorf("Expected the service's contradiction, got %+v (%v)", out, err)
+	}
+	if _, err := jobs[0].Run(context.Background(), "down"); err == nil {
+		t.Error("Expected an error status to fail the attempt")
+	}
+
+	os.WriteFile(path, []byte("jobs:\n  - name: no-url\n"), 0o644)
+	if _, err := LoadJobs(path); err == nil {
+		t.Error("Expected a job without a url rejected")
+	}
+}
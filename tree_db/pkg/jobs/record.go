@@ -0,0 +1,146 @@
+// ABOUTME: Storage of job runs, one tag-length-value record per job and policy
+// ABOUTME: Followers replicate the records, so they can report run status though they run nothing
+
+package jobs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// PREFIX_JOB_RUN keys the latest run of each job by (job, policyID)
+const PREFIX_JOB_RUN = uint32(9500)
+
+// runSchema is the schema version of run records
+const runSchema = 1
+
+// maxError bounds the error kept with a run, so its record stays within the
+// value size limit
+const maxError = 1024
+
+// Field tags of a run record
+const (
+	runFieldJob = iota + 1
+	runFieldPolicyID
+	runFieldState
+	runFieldAttempts
+	runFieldLastError
+	runFieldQueuedAt
+	runFieldStartedAt
+	runFieldFinishedAt
+	runFieldNextAttempt
+	runFieldOutputs
+	runFieldRerun
+)
+
+// runKey returns the key of a job's run over a policy
+func runKey(job, policyID string) []byte {
+	return storage.EncodeKey(PREFIX_JOB_RUN, []storage.Value{
+		storage.NewBytesValue([]byte(job)),
+		storage.NewBytesValue([]byte(policyID)),
+	})
+}
+
+// encodeRun encodes a run as a record; unset times are left out
+func encodeRun(run *Run) []byte {
+	w := storage.NewRecordWriter(runSchema)
+	w.String(runFieldJob, run.Job)
+	w.String(runFieldPolicyID, run.PolicyID)
+	w.String(runFieldState, string(run.State))
+	w.Int(runFieldAttempts, int64(run.Attempts))
+	w.String(runFieldLastError, run.LastError)
+	setTime(w, runFieldQueuedAt, run.QueuedAt)
+	setTime(w, runFieldStartedAt, run.StartedAt)
+	setTime(w, runFieldFinishedAt, run.FinishedAt)
+	setTime(w, runFieldNextAttempt, run.NextAttempt)
+	w.Int(runFieldOutputs, int64(run.Outputs))
+	w.Bool(runFieldRerun, run.Rerun)
+	return w.Encode()
+}
+
+// setTime adds a time field unless t is zero, which readers see as absent
+func setTime(w *storage.RecordWriter, tag int, t time.Time) {
+	if !t.IsZero() {
+		w.Time(tag, t)
+	}
+}
+
+// decodeRun decodes a run record
+func decodeRun(val []byte) (*Run, error) {
+	r, err := storage.DecodeRecord(val)
+	if err != nil {
+		return nil, err
+	}
+	return &Run{
+		Job:         r.String(runFieldJob),
+		PolicyID:    r.String(runFieldPolicyID),
+		State:       State(r.String(runFieldState)),
+		Attempts:    int(r.Int(runFieldAttempts)),
+		LastError:   r.String(runFieldLastError),
+		QueuedAt:    r.Time(runFieldQueuedAt),
+		StartedAt:   r.Time(runFieldStartedAt),
+		FinishedAt:  r.Time(runFieldFinishedAt),
+		NextAttempt: r.Time(runFieldNextAttempt),
+		Outputs:     int(r.Int(runFieldOutputs)),
+		Rerun:       r.Bool(runFieldRerun),
+	}, nil
+}
+
+// truncateError shortens an error message to maxError bytes
+func truncateError(msg string) string {
+	if len(msg) <= maxError {
+		return msg
+	}
+	return msg[:maxError-3] + "..."
+}
+
+// getRun reads a job's run over a policy, nil if it never ran
+func getRun(kv *storage.KV, job, policyID string) (*Run, error) {
+	val, ok, err := kv.Lookup(runKey(job, policyID))
+	if err != nil || !ok {
+		return nil, err
+	}
+	run, err := decodeRun(val)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt run of %s over %s: %v", job, policyID, err)
+	}
+	return run, nil
+}
+
+// putRun writes a run
+func putRun(kv *storage.KV, run *Run) error {
+	tx := kv.Begin()
+	defer tx.Abort()
+	tx.Set(runKey(run.Job, run.PolicyID), encodeRun(run))
+	return tx.Commit()
+}
+
+// ListRuns returns the runs stored in kv matching f, by job and then policy
+// It reads the records directly, so a follower can list the runs its leader
+// replicated.
+func ListRuns(kv *storage.KV, f RunFilter) ([]*Run, error) {
+	start := storage.EncodeKey(PREFIX_JOB_RUN, nil)
+	if f.Job != "" {
+		start = storage.EncodeKey(PREFIX_JOB_RUN, []storage.Value{storage.NewBytesValue([]byte(f.Job))})
+	}
+
+	var runs []*Run
+	var decodeErr error
+	err := storage.ScanPrefix(kv, start, func(key, val []byte) bool {
+		run, err := decodeRun(val)
+		if err != nil {
+			decodeErr = fmt.Errorf("corrupt run record: %v", err)
+			return false
+		}
+		if (f.PolicyID == "" || run.PolicyID == f.PolicyID) && (f.State == "" || run.State == f.State) {
+			runs = append(runs, run)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runs, decodeErr
+}
@@ -0,0 +1,291 @@
+// ABOUTME: Named collections of policies, such as every cardiology policy of a year
+// ABOUTME: Members are indexed by collection and by policy so either side can be listed without a scan
+
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// Prefixes for collections
+const (
+	PREFIX_COLLECTION        = uint32(7700) // Collections by (name)
+	PREFIX_COLLECTION_MEMBER = uint32(7800) // Members by (name, policyID)
+	PREFIX_COLLECTION_POLICY = uint32(7900) // Index by (policyID, name)
+)
+
+// ErrCollectionNotFound is returned when a collection does not exist
+var ErrCollectionNotFound = errors.New("metadata: collection not found")
+
+// PutCollection creates a collection or replaces the description, metadata and
+// members of an existing one, keeping its creation time. PolicyIDs are
+// stored sorted and without duplicates; c is updated to what was stored.
+func (ms *MetadataStore) PutCollection(c *Collection) error {
+	if c.Name == "" {
+		return fmt.Errorf("collection needs a name")
+	}
+
+	ms.writeMu.Lock()
+	defer ms.writeMu.Unlock()
+
+	tx := ms.kv.Begin()
+	defer tx.Abort()
+
+	now := time.Now().Truncate(time.Second) // As stored
+	written := *c
+	written.PolicyIDs = normalizeMembers(c.PolicyIDs)
+	written.CreatedAt, written.UpdatedAt = now, now
+
+	old := loadCollection(tx, c.Name)
+	if err := tx.Err(); err != nil {
+		return err
+	}
+	var oldMembers []string
+	if old != nil {
+		written.CreatedAt = old.CreatedAt
+		oldMembers = old.PolicyIDs
+	}
+
+	setCollection(tx, &written)
+	updateMembers(tx, c.Name, oldMembers, written.PolicyIDs)
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	*c = written
+	ms.feed.Publish(changefeed.EntityCollection, changefeed.OpPut, "", c.Name)
+	return nil
+}
+
+// UpdateCollectionMembers adds and then removes members of an existing
+// collection, returning it as stored
+func (ms *MetadataStore) UpdateCollectionMembers(name string, add, remove []string) (*Collection, error) {
+	ms.writeMu.Lock()
+	defer ms.writeMu.Unlock()
+
+	tx := ms.kv.Begin()
+	defer tx.Abort()
+
+	c := loadCollection(tx, name)
+	if err := tx.Err(); err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCollectionNotFound, name)
+	}
+
+	removed := make(map[string]bool, len(remove))
+	for _, policyID := range remove {
+		removed[policyID] = true
+	}
+	var members []string
+	for _, policyID := range append(c.PolicyIDs, add...) {
+		if !removed[policyID] {
+			members = append(members, policyID)
+		}
+	}
+
+	oldMembers := c.PolicyIDs
+	c.PolicyIDs = normalizeMembers(members)
+	c.UpdatedAt = time.Now().Truncate(time.Second)
+	setCollection(tx, c)
+	updateMembers(tx, name, oldMembers, c.PolicyIDs)
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	ms.feed.Publish(changefeed.EntityCollection, changefeed.OpPut, "", name)
+	return c, nil
+}
+
+// DeleteCollection removes a collection and its membership entries; the
+// member policies themselves are untouched
+func (ms *MetadataStore) DeleteCollection(name string) error {
+	ms.writeMu.Lock()
+	defer ms.writeMu.Unlock()
+
+	tx := ms.kv.Begin()
+	defer tx.Abort()
+
+	c := loadCollection(tx, name)
+	if err := tx.Err(); err != nil {
+		return err
+	}
+	if c == nil {
+		return fmt.Errorf("%w: %s", ErrCollectionNotFound, name)
+	}
+
+	tx.Del(collectionKey(name))
+	updateMembers(tx, name, c.PolicyIDs, nil)
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	ms.feed.Publish(changefeed.EntityCollection, changefeed.OpDelete, "", name)
+	return nil
+}
+
+// GetCollection returns a collection with its members
+func (ms *MetadataStore) GetCollection(name string) (*Collection, error) {
+	val, ok, err := ms.kv.Lookup(collectionKey(name))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCollectionNotFound, name)
+	}
+
+	c, err := parseCollection(name, val)
+	if err != nil {
+		return nil, err
+	}
+	c.PolicyIDs, err = ms.collectionMembers(name)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ListCollections returns every collection in name order, or only those
+// containing policyID when it is not empty
+func (ms *MetadataStore) ListCollections(policyID string) ([]*Collection, error) {
+	var names []string
+	if policyID != "" {
+		err := scanColumns(ms.kv, PREFIX_COLLECTION_POLICY, policyID, func(vals []storage.Value) {
+			names = append(names, string(vals[1].Str))
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		start := storage.EncodeKey(PREFIX_COLLECTION, nil)
+		err := storage.ScanPrefix(ms.kv, start, func(key, val []byte) bool {
+			if vals, err := storage.ExtractValues(key); err == nil && len(vals) == 1 {
+				names = append(names, string(vals[0].Str))
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	collections := make([]*Collection, 0, len(names))
+	for _, name := range names {
+		c, err := ms.GetCollection(name)
+		if errors.Is(err, ErrCollectionNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+	return collections, nil
+}
+
+// collectionMembers lists the policies of a collection in order
+func (ms *MetadataStore) collectionMembers(name string) ([]string, error) {
+	var members []string
+	err := scanColumns(ms.kv, PREFIX_COLLECTION_MEMBER, name, func(vals []storage.Value) {
+		members = append(members, string(vals[1].Str))
+	})
+	return members, err
+}
+
+// scanColumns visits the two-column keys under a prefix whose first column is first
+func scanColumns(kv storage.Engine, prefix uint32, first string, fn func(vals []storage.Value)) error {
+	start := storage.EncodeKey(prefix, []storage.Value{storage.NewBytesValue([]byte(first))})
+	return storage.ScanPrefix(kv, start, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 2 {
+			return true
+		}
+		fn(vals)
+		return true
+	})
+}
+
+// updateMembers moves the membership entries of a collection from old to cur
+func updateMembers(tx storage.Txn, name string, old, cur []string) {
+	keep := make(map[string]bool, len(cur))
+	for _, policyID := range cur {
+		keep[policyID] = true
+	}
+	for _, policyID := range old {
+		if !keep[policyID] {
+			tx.Del(memberKey(name, policyID))
+			tx.Del(memberPolicyKey(policyID, name))
+		}
+	}
+	for _, policyID := range cur {
+		tx.Set(memberKey(name, policyID), []byte{})
+		tx.Set(memberPolicyKey(policyID, name), []byte{})
+	}
+}
+
+// normalizeMembers sorts policy IDs and drops duplicates and empty IDs
+func normalizeMembers(policyIDs []string) []string {
+	sorted := append([]string(nil), policyIDs...)
+	sort.Strings(sorted)
+	out := sorted[:0]
+	for i, policyID := range sorted {
+		if policyID != "" && (i == 0 || policyID != sorted[i-1]) {
+			out = append(out, policyID)
+		}
+	}
+	return out
+}
+
+// loadCollection reads a collection with its members within a transaction,
+// returning nil if it is absent or unreadable
+func loadCollection(tx storage.Txn, name string) *Collection {
+	val, ok := tx.Get(collectionKey(name))
+	if !ok {
+		return nil
+	}
+	c, err := parseCollection(name, val)
+	if err != nil {
+		return nil
+	}
+
+	start := storage.EncodeKey(PREFIX_COLLECTION_MEMBER, []storage.Value{storage.NewBytesValue([]byte(name))})
+	storage.ScanPrefix(tx, start, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 2 {
+			return false
+		}
+		c.PolicyIDs = append(c.PolicyIDs, string(vals[1].Str))
+		return true
+	})
+	return c
+}
+
+// collectionKey returns the key of a collection's record
+func collectionKey(name string) []byte {
+	return storage.EncodeKey(PREFIX_COLLECTION, []storage.Value{
+		storage.NewBytesValue([]byte(name)),
+	})
+}
+
+// memberKey returns the membership key of a policy under its collection
+func memberKey(name, policyID string) []byte {
+	return storage.EncodeKey(PREFIX_COLLECTION_MEMBER, []storage.Value{
+		storage.NewBytesValue([]byte(name)),
+		storage.NewBytesValue([]byte(policyID)),
+	})
+}
+
+// memberPolicyKey returns the membership key of a collection under its policy
+func memberPolicyKey(policyID, name string) []byte {
+	return storage.EncodeKey(PREFIX_COLLECTION_POLICY, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(name)),
+	})
+}
@@ -0,0 +1,92 @@
+// ABOUTME: Tests for policy collections
+// ABOUTME: Verifies replacement, member updates, the by-policy index and deletion
+
+package metadata
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestCollections(t *testing.T) {
+	ms, kv, path := setupTestMetadataStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	cardio := &Collection{
+		Name:        "cardiology-2024",
+		Description: "Cardiology policies effective in 2024",
+		PolicyIDs:   []string{"LCD-3", "LCD-1", "LCD-3", ""},
+		Metadata:    map[string]string{"specialty": "cardiology", "year": "2024"},
+	}
+	if err := ms.PutCollection(cardio); err != nil {
+		t.Fatalf("PutCollection failed: %v", err)
+	}
+	if !reflect.DeepEqual(cardio.PolicyIDs, []string{"LCD-1", "LCD-3"}) || cardio.CreatedAt.IsZero() {
+		t.Errorf("Expected sorted unique members and times, got %+v", cardio)
+	}
+	if err := ms.PutCollection(&Collection{Name: "imaging", PolicyIDs: []string{"LCD-3", "NCD-9"}}); err != nil {
+		t.Fatalf("PutCollection failed: %v", err)
+	}
+
+	got, err := ms.GetCollection("cardiology-2024")
+	if err != nil {
+		t.Fatalf("GetCollection failed: %v", err)
+	}
+	if got.Description != cardio.Description || !reflect.DeepEqual(got.Metadata, cardio.Metadata) ||
+		!reflect.DeepEqual(got.PolicyIDs, cardio.PolicyIDs) || !got.CreatedAt.Equal(cardio.CreatedAt) {
+		t.Errorf("GetCollection returned %+v, want %+v", got, cardio)
+	}
+
+	updated, err := ms.UpdateCollectionMembers("cardiology-2024", []string{"LCD-2", "NCD-9"}, []string{"LCD-3"})
+	if err != nil {
+		t.Fatalf("UpdateCollectionMembers failed: %v", err)
+	}
+	if !reflect.DeepEqual(updated.PolicyIDs, []string{"LCD-1", "LCD-2", "NCD-9"}) {
+		t.Errorf("Unexpected members after update: %v", updated.PolicyIDs)
+	}
+
+	names := func(policyID string) []string {
+		collections, err := ms.ListCollections(policyID)
+		if err != nil {
+			t.Fatalf("ListCollections failed: %v", err)
+		}
+		var out []string
+		for _, c := range collections {
+			out = append(out, c.Name)
+		}
+		return out
+	}
+	if got := names(""); !reflect.DeepEqual(got, []string{"cardiology-2024", "imaging"}) {
+		t.Errorf("Expected both collections, got %v", got)
+	}
+	if got := names("NCD-9"); !reflect.DeepEqual(got, []string{"cardiology-2024", "imaging"}) {
+		t.Errorf("Expected NCD-9 in both collections, got %v", got)
+	}
+	if got := names("LCD-3"); !reflect.DeepEqual(got, []string{"imaging"}) {
+		t.Errorf("Expected the removed member indexed under imaging alone, got %v", got)
+	}
+
+	// Replacing a collection replaces its members too
+	if err := ms.PutCollection(&Collection{Name: "imaging", PolicyIDs: []string{"NCD-10"}}); err != nil {
+		t.Fatalf("PutCollection failed: %v", err)
+	}
+	if got := names("LCD-3"); len(got) != 0 {
+		t.Errorf("Expected LCD-3 in no collection, got %v", got)
+	}
+
+	if err := ms.DeleteCollection("cardiology-2024"); err != nil {
+		t.Fatalf("DeleteCollection failed: %v", err)
+	}
+	if _, err := ms.GetCollection("cardiology-2024"); !errors.Is(err, ErrCollectionNotFound) {
+		t.Errorf("Expected ErrCollectionNotFound, got %v", err)
+	}
+	if got := names("LCD-1"); len(got) != 0 {
+		t.Errorf("Expected the deleted collection's members unindexed, got %v", got)
+	}
+	if _, err := ms.UpdateCollectionMembers("missing", []string{"X"}, nil); !errors.Is(err, ErrCollectionNotFound) {
+		t.Errorf("Expected ErrCollectionNotFound updating a missing collection, got %v", err)
+	}
+}
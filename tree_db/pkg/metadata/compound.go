@@ -0,0 +1,205 @@
+// ABOUTME: Runtime-registered compound indexes over several metadata keys
+// ABOUTME: Lets QueryMultiple scan one index range instead of intersecting candidates
+
+package metadata
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// CompoundIndex indexes entities of one type by the values of several keys
+// Index entries are keyed (name, value1, ..., valueN, entityID) under
+// PREFIX_METADATA_COMPOUND and exist only for entities that have every key.
+type CompoundIndex struct {
+	EntityType string
+	Keys       []string
+}
+
+// name identifies the index inside the compound prefix
+func (ci *CompoundIndex) name() string {
+	return ci.EntityType + ":" + strings.Join(ci.Keys, ",")
+}
+
+// covers reports whether the index includes a key
+func (ci *CompoundIndex) covers(key string) bool {
+	for _, k := range ci.Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// entryKey builds the index key for an entity's attribute values
+// It returns nil when the entity lacks one of the indexed keys.
+func (ci *CompoundIndex) entryKey(entityID string, attrs map[string]string) []byte {
+	vals := make([]storage.Value, 0, len(ci.Keys)+2)
+	vals = append(vals, storage.NewBytesValue([]byte(ci.name())))
+	for _, k := range ci.Keys {
+		v, ok := attrs[k]
+		if !ok {
+			return nil
+		}
+		vals = append(vals, storage.NewBytesValue([]byte(v)))
+	}
+	vals = append(vals, storage.NewBytesValue([]byte(entityID)))
+	return storage.EncodeKey(PREFIX_METADATA_COMPOUND, vals)
+}
+
+// AddCompoundIndex registers a compound index and builds it from existing metadata
+// Registrations are not persisted: register indexes again after reopening the
+// store. Re-registering rebuilds the index from scratch, dropping stale entries.
+func (ms *MetadataStore) AddCompoundIndex(entityType string, keys ...string) error {
+	if entityType == "" || len(keys) < 2 {
+		return fmt.Errorf("compound index needs an entity type and at least two keys")
+	}
+
+	idx := &CompoundIndex{EntityType: entityType, Keys: append([]string(nil), keys...)}
+
+	// Collect stale entries and current attributes before writing
+	var stale [][]byte
+	namePrefix := storage.EncodeKey(PREFIX_METADATA_COMPOUND, []storage.Value{
+		storage.NewBytesValue([]byte(idx.name())),
+	})
+	if err := storage.ScanPrefix(ms.kv, namePrefix, func(key, val []byte) bool {
+		stale = append(stale, append([]byte(nil), key...))
+		return true
+	}); err != nil {
+		return err
+	}
+
+	entities, err := ms.entityIDs(entityType)
+	if err != nil {
+		return err
+	}
+
+	tx := ms.kv.Begin()
+	for _, key := range stale {
+		tx.Del(key)
+	}
+	for _, entityID := range entities {
+		attrs, err := ms.GetAllMetadata(entityType, entityID)
+		if err != nil {
+			tx.Abort()
+			return err
+		}
+		if key := idx.entryKey(entityID, attrs); key != nil {
+			tx.Set(key, []byte{})
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for i, existing := range ms.compound {
+		if existing.name() == idx.name() {
+			ms.compound[i] = idx
+			return nil
+		}
+	}
+	ms.compound = append(ms.compound, idx)
+	return nil
+}
+
+// CompoundIndexes returns the registered compound indexes
+func (ms *MetadataStore) CompoundIndexes() []*CompoundIndex {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return append([]*CompoundIndex(nil), ms.compound...)
+}
+
+// indexesFor returns the compound indexes covering a key of an entity type
+func (ms *MetadataStore) indexesFor(entityType, key string) []*CompoundIndex {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	var out []*CompoundIndex
+	for _, idx := range ms.compound {
+		if idx.EntityType == entityType && (key == "" || idx.covers(key)) {
+			out = append(out, idx)
+		}
+	}
+	return out
+}
+
+// updateCompound moves an entity's compound index entries from its old to its new attributes
+func updateCompound(tx storage.Txn, indexes []*CompoundIndex, entityID string, old, cur map[string]string) {
+	for _, idx := range indexes {
+		oldKey := idx.entryKey(entityID, old)
+		newKey := idx.entryKey(entityID, cur)
+		if oldKey != nil && (newKey == nil || string(oldKey) != string(newKey)) {
+			tx.Del(oldKey)
+		}
+		if newKey != nil {
+			tx.Set(newKey, []byte{})
+		}
+	}
+}
+
+// bestCompoundIndex picks the registered index covering the most filter keys
+func (ms *MetadataStore) bestCompoundIndex(entityType string, filters map[string]string) *CompoundIndex {
+	var best *CompoundIndex
+	for _, idx := range ms.indexesFor(entityType, "") {
+		usable := true
+		for _, k := range idx.Keys {
+			if _, ok := filters[k]; !ok {
+				usable = false
+				break
+			}
+		}
+		if usable && (best == nil || len(idx.Keys) > len(best.Keys)) {
+			best = idx
+		}
+	}
+	return best
+}
+
+// scanCompound returns the entity IDs whose indexed values equal the filters
+func (ms *MetadataStore) scanCompound(idx *CompoundIndex, filters map[string]string) ([]string, error) {
+	vals := []storage.Value{storage.NewBytesValue([]byte(idx.name()))}
+	for _, k := range idx.Keys {
+		vals = append(vals, storage.NewBytesValue([]byte(filters[k])))
+	}
+	startKey := storage.EncodeKey(PREFIX_METADATA_COMPOUND, vals)
+
+	var entityIDs []string
+	err := storage.ScanPrefix(ms.kv, startKey, func(key, val []byte) bool {
+		keyVals, err := storage.ExtractValues(key)
+		if err != nil || len(keyVals) != len(vals)+1 {
+			return false
+		}
+
+		entityIDs = append(entityIDs, string(keyVals[len(vals)].Str))
+		return true
+	})
+
+	return entityIDs, err
+}
+
+// entityIDs lists the distinct entities of a type that have metadata
+func (ms *MetadataStore) entityIDs(entityType string) ([]string, error) {
+	startKey := storage.EncodeKey(PREFIX_METADATA_ENTITY, []storage.Value{
+		storage.NewBytesValue([]byte(entityType)),
+	})
+
+	var ids []string
+	err := storage.ScanPrefix(ms.kv, startKey, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 3 {
+			return true
+		}
+
+		entityID := string(vals[1].Str)
+		if len(ids) == 0 || ids[len(ids)-1] != entityID {
+			ids = append(ids, entityID)
+		}
+		return true
+	})
+
+	return ids, err
+}
@@ -0,0 +1,97 @@
+// ABOUTME: Tests for runtime compound metadata indexes
+// ABOUTME: Verifies backfill, maintenance on set/delete and QueryMultiple routing
+
+package metadata
+
+import (
+	"os"
+	"sort"
+	"testing"
+	"time"
+)
+
+func setAttr(t *testing.T, ms *MetadataStore, entityID, key, value string) {
+	t.Helper()
+	now := time.Now()
+	if err := ms.SetMetadata(&MetadataEntry{
+		EntityType: "document",
+		EntityID:   entityID,
+		Key:        key,
+		Value:      value,
+		ValueType:  "string",
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+}
+
+func queryDocs(t *testing.T, ms *MetadataStore, filters map[string]string) []string {
+	t.Helper()
+	docType := "document"
+	results, err := ms.QueryMultiple(filters, &docType, 0)
+	if err != nil {
+		t.Fatalf("QueryMultiple failed: %v", err)
+	}
+	sort.Strings(results)
+	return results
+}
+
+func TestCompoundIndex(t *testing.T) {
+	ms, kv, path := setupTestMetadataStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	// Existing data is backfilled on registration
+	setAttr(t, ms, "doc1", "department", "claims")
+	setAttr(t, ms, "doc1", "status", "active")
+	setAttr(t, ms, "doc2", "department", "claims")
+	setAttr(t, ms, "doc2", "status", "draft")
+
+	if err := ms.AddCompoundIndex("document", "department", "status"); err != nil {
+		t.Fatalf("AddCompoundIndex failed: %v", err)
+	}
+	if len(ms.CompoundIndexes()) != 1 {
+		t.Fatalf("Expected 1 registered index, got %d", len(ms.CompoundIndexes()))
+	}
+
+	filters := map[string]string{"department": "claims", "status": "active"}
+	if got := queryDocs(t, ms, filters); len(got) != 1 || got[0] != "doc1" {
+		t.Errorf("Expected [doc1], got %v", got)
+	}
+
+	// Writes after registration maintain the index
+	setAttr(t, ms, "doc3", "status", "active")
+	setAttr(t, ms, "doc3", "department", "claims")
+	setAttr(t, ms, "doc2", "status", "active")
+	if got := queryDocs(t, ms, filters); len(got) != 3 {
+		t.Errorf("Expected 3 active claims docs, got %v", got)
+	}
+
+	setAttr(t, ms, "doc1", "status", "retired")
+	if err := ms.DeleteMetadata("document", "doc2", "department"); err != nil {
+		t.Fatalf("DeleteMetadata failed: %v", err)
+	}
+	if got := queryDocs(t, ms, filters); len(got) != 1 || got[0] != "doc3" {
+		t.Errorf("Expected [doc3], got %v", got)
+	}
+
+	// Filters outside the index are checked per candidate
+	setAttr(t, ms, "doc3", "owner", "alice")
+	filters["owner"] = "bob"
+	if got := queryDocs(t, ms, filters); len(got) != 0 {
+		t.Errorf("Expected no match for owner=bob, got %v", got)
+	}
+
+	if err := ms.DeleteAllMetadata("document", "doc3"); err != nil {
+		t.Fatalf("DeleteAllMetadata failed: %v", err)
+	}
+	delete(filters, "owner")
+	if got := queryDocs(t, ms, filters); len(got) != 0 {
+		t.Errorf("Expected index cleared after DeleteAllMetadata, got %v", got)
+	}
+
+	if err := ms.AddCompoundIndex("document", "status"); err == nil {
+		t.Error("Expected error for single-key compound index")
+	}
+}
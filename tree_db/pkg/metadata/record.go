@@ -0,0 +1,250 @@
+// ABOUTME: Encoding of metadata entries, cross references, collections and trajectory steps as stored
+// ABOUTME: They are tag-length-value records; readers also accept the tuples of values written before them
+
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// Schema versions of the records this package writes
+const (
+	entrySchema      = 1
+	referenceSchema  = 1
+	collectionSchema = 1
+	stepSchema       = 1
+)
+
+// Field tags of a metadata entry record
+const (
+	entryFieldEntityType = iota + 1
+	entryFieldEntityID
+	entryFieldKey
+	entryFieldValue
+	entryFieldValueType
+	entryFieldCreatedAt
+	entryFieldUpdatedAt
+	entryFieldVersion
+)
+
+// Field tags of a cross reference record; its nodes are in its key
+const (
+	referenceFieldType = iota + 1
+	referenceFieldContext
+	referenceFieldCreatedAt
+)
+
+// Field tags of a collection record; its name is in its key and its members
+// in records of their own
+const (
+	collectionFieldDescription = iota + 1
+	collectionFieldCreatedAt
+	collectionFieldUpdatedAt
+	collectionFieldMetadata
+)
+
+// Field tags of a trajectory step record; its trajectory and position are in its key
+const (
+	stepFieldNumber = iota + 1
+	stepFieldToolName
+	stepFieldAction
+	stepFieldObservation
+	stepFieldThought
+	stepFieldTimestamp
+)
+
+// encodeEntry encodes a metadata entry as a record
+func encodeEntry(entry *MetadataEntry) []byte {
+	w := storage.NewRecordWriter(entrySchema)
+	w.String(entryFieldEntityType, entry.EntityType)
+	w.String(entryFieldEntityID, entry.EntityID)
+	w.String(entryFieldKey, entry.Key)
+	w.String(entryFieldValue, entry.Value)
+	w.String(entryFieldValueType, entry.ValueType)
+	w.Time(entryFieldCreatedAt, entry.CreatedAt)
+	w.Time(entryFieldUpdatedAt, entry.UpdatedAt)
+	w.Uint(entryFieldVersion, entry.Version)
+	return w.Encode()
+}
+
+// decodeEntry decodes a stored metadata entry, a record or a tuple written before them
+func decodeEntry(val []byte) (*MetadataEntry, error) {
+	if !storage.IsRecord(val) {
+		vals, err := storage.DecodeValues(val)
+		if err != nil {
+			return nil, err
+		}
+		return parseMetadataVals(vals)
+	}
+
+	r, err := storage.DecodeRecord(val)
+	if err != nil {
+		return nil, err
+	}
+	return &MetadataEntry{
+		EntityType: r.String(entryFieldEntityType),
+		EntityID:   r.String(entryFieldEntityID),
+		Key:        r.String(entryFieldKey),
+		Value:      r.String(entryFieldValue),
+		ValueType:  r.String(entryFieldValueType),
+		CreatedAt:  r.Time(entryFieldCreatedAt),
+		UpdatedAt:  r.Time(entryFieldUpdatedAt),
+		Version:    r.Uint(entryFieldVersion),
+	}, nil
+}
+
+// CheckEntryRecord reports whether a stored metadata entry decodes, for
+// background checks that re-read recent writes
+func CheckEntryRecord(val []byte) error {
+	_, err := decodeEntry(val)
+	return err
+}
+
+// parseMetadataVals reads a metadata entry stored as a tuple of values, before records
+func parseMetadataVals(vals []storage.Value) (*MetadataEntry, error) {
+	if len(vals) < 7 {
+		return nil, fmt.Errorf("incomplete metadata data")
+	}
+
+	entry := &MetadataEntry{
+		EntityType: string(vals[0].Str),
+		EntityID:   string(vals[1].Str),
+		Key:        string(vals[2].Str),
+		Value:      string(vals[3].Str),
+		ValueType:  string(vals[4].Str),
+		CreatedAt:  vals[5].Time,
+		UpdatedAt:  vals[6].Time,
+	}
+
+	// Entries written before versioning was added read as version 0
+	if len(vals) > 7 {
+		entry.Version = uint64(vals[7].I64)
+	}
+
+	return entry, nil
+}
+
+// encodeReference encodes the value of a cross reference as a record
+func encodeReference(ref *CrossReference) []byte {
+	w := storage.NewRecordWriter(referenceSchema)
+	w.String(referenceFieldType, ref.ReferenceType)
+	w.String(referenceFieldContext, ref.Context)
+	w.Time(referenceFieldCreatedAt, ref.CreatedAt)
+	return w.Encode()
+}
+
+// parseReference decodes a reference from its primary key columns and value
+func parseReference(keyVals []storage.Value, val []byte) (*CrossReference, error) {
+	ref := &CrossReference{
+		SourcePolicyID: string(keyVals[0].Str),
+		SourceNodeID:   string(keyVals[1].Str),
+		TargetPolicyID: string(keyVals[2].Str),
+		TargetNodeID:   string(keyVals[3].Str),
+	}
+
+	if storage.IsRecord(val) {
+		r, err := storage.DecodeRecord(val)
+		if err != nil {
+			return nil, err
+		}
+		ref.ReferenceType = r.String(referenceFieldType)
+		ref.Context = r.String(referenceFieldContext)
+		ref.CreatedAt = r.Time(referenceFieldCreatedAt)
+		return ref, nil
+	}
+
+	vals, err := storage.DecodeValues(val)
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) < 3 {
+		return nil, fmt.Errorf("incomplete cross reference data")
+	}
+	ref.ReferenceType = string(vals[0].Str)
+	ref.Context = string(vals[1].Str)
+	ref.CreatedAt = vals[2].Time
+	return ref, nil
+}
+
+// setCollection writes a collection's record
+func setCollection(tx storage.Txn, c *Collection) {
+	w := storage.NewRecordWriter(collectionSchema)
+	w.String(collectionFieldDescription, c.Description)
+	w.Time(collectionFieldCreatedAt, c.CreatedAt)
+	w.Time(collectionFieldUpdatedAt, c.UpdatedAt)
+	w.Bytes(collectionFieldMetadata, storage.EncodeStringMap(c.Metadata))
+	tx.Set(collectionKey(c.Name), w.Encode())
+}
+
+// parseCollection decodes a collection record; members are read separately
+func parseCollection(name string, val []byte) (*Collection, error) {
+	if storage.IsRecord(val) {
+		r, err := storage.DecodeRecord(val)
+		if err != nil {
+			return nil, err
+		}
+		metadata, err := storage.DecodeStringMap(r.Bytes(collectionFieldMetadata))
+		if err != nil {
+			return nil, fmt.Errorf("collection metadata: %w", err)
+		}
+		return &Collection{
+			Name:        name,
+			Description: r.String(collectionFieldDescription),
+			Metadata:    metadata,
+			CreatedAt:   r.Time(collectionFieldCreatedAt),
+			UpdatedAt:   r.Time(collectionFieldUpdatedAt),
+		}, nil
+	}
+
+	// Tuples hold the description and times, then the metadata as
+	// alternating keys and values
+	vals, err := storage.DecodeValues(val)
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) < 3 || len(vals)%2 == 0 {
+		return nil, fmt.Errorf("incomplete collection data")
+	}
+
+	c := &Collection{
+		Name:        name,
+		Description: string(vals[0].Str),
+		Metadata:    make(map[string]string),
+		CreatedAt:   vals[1].Time,
+		UpdatedAt:   vals[2].Time,
+	}
+	for i := 3; i+1 < len(vals); i += 2 {
+		c.Metadata[string(vals[i].Str)] = string(vals[i+1].Str)
+	}
+	return c, nil
+}
+
+// encodeStep encodes a trajectory step as a record
+func encodeStep(step *TrajectoryStep) []byte {
+	w := storage.NewRecordWriter(stepSchema)
+	w.Int(stepFieldNumber, int64(step.StepNumber))
+	w.String(stepFieldToolName, step.ToolName)
+	w.String(stepFieldAction, step.Action)
+	w.String(stepFieldObservation, step.Observation)
+	w.String(stepFieldThought, step.Thought)
+	w.Time(stepFieldTimestamp, step.Timestamp)
+	return w.Encode()
+}
+
+// decodeStep decodes a trajectory step record
+func decodeStep(val []byte) (*TrajectoryStep, error) {
+	r, err := storage.DecodeRecord(val)
+	if err != nil {
+		return nil, err
+	}
+	return &TrajectoryStep{
+		StepNumber:  int(r.Int(stepFieldNumber)),
+		ToolName:    r.String(stepFieldToolName),
+		Action:      r.String(stepFieldAction),
+		Observation: r.String(stepFieldObservation),
+		Thought:     r.String(stepFieldThought),
+		Timestamp:   r.Time(stepFieldTimestamp),
+	}, nil
+}
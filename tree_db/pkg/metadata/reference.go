@@ -0,0 +1,135 @@
+// ABOUTME: Cross-reference storage between policy nodes
+// ABOUTME: Indexes references by source and by target for graph traversal and joins
+
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// AddCrossReference stores a reference, replacing any existing one between the same nodes
+func (ms *MetadataStore) AddCrossReference(ref *CrossReference) error {
+	if ref.SourcePolicyID == "" || ref.SourceNodeID == "" || ref.TargetPolicyID == "" || ref.TargetNodeID == "" {
+		return fmt.Errorf("cross reference needs source and target policy and node")
+	}
+
+	tx := ms.kv.Begin()
+	tx.Set(referenceKey(ref), encodeReference(ref))
+	tx.Set(referenceTargetKey(ref), []byte{})
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	ms.publishReference(changefeed.OpPut, ref)
+	return nil
+}
+
+// DeleteCrossReference removes the reference between two nodes
+func (ms *MetadataStore) DeleteCrossReference(sourcePolicyID, sourceNodeID, targetPolicyID, targetNodeID string) error {
+	ref := &CrossReference{
+		SourcePolicyID: sourcePolicyID,
+		SourceNodeID:   sourceNodeID,
+		TargetPolicyID: targetPolicyID,
+		TargetNodeID:   targetNodeID,
+	}
+
+	tx := ms.kv.Begin()
+	tx.Del(referenceKey(ref))
+	tx.Del(referenceTargetKey(ref))
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	ms.publishReference(changefeed.OpDelete, ref)
+	return nil
+}
+
+// publishReference reports a reference change under its source policy,
+// identified as "sourceNode/targetPolicy/targetNode"
+func (ms *MetadataStore) publishReference(op changefeed.Op, ref *CrossReference) {
+	ms.feed.Publish(changefeed.EntityReference, op, ref.SourcePolicyID,
+		ref.SourceNodeID+"/"+ref.TargetPolicyID+"/"+ref.TargetNodeID)
+}
+
+// ReferencesFrom returns the references made by a node, or by every node of
+// the policy when nodeID is empty
+func (ms *MetadataStore) ReferencesFrom(policyID, nodeID string) ([]*CrossReference, error) {
+	var refs []*CrossReference
+	err := ms.scanReferences(PREFIX_REFERENCE, policyID, nodeID, func(vals []storage.Value, val []byte) error {
+		if ref, err := parseReference(vals, val); err == nil {
+			refs = append(refs, ref)
+		}
+		return nil
+	})
+	return refs, err
+}
+
+// ReferencesTo returns the references pointing at a node, or at any node of
+// the policy when nodeID is empty
+func (ms *MetadataStore) ReferencesTo(policyID, nodeID string) ([]*CrossReference, error) {
+	var refs []*CrossReference
+	err := ms.scanReferences(PREFIX_REFERENCE_TARGET, policyID, nodeID, func(vals []storage.Value, _ []byte) error {
+		keyVals := []storage.Value{vals[2], vals[3], vals[0], vals[1]}
+		val, ok, err := ms.kv.Lookup(storage.EncodeKey(PREFIX_REFERENCE, keyVals))
+		if err != nil || !ok {
+			return err
+		}
+		if ref, err := parseReference(keyVals, val); err == nil {
+			refs = append(refs, ref)
+		}
+		return nil
+	})
+	return refs, err
+}
+
+// scanReferences visits the reference keys under a prefix whose leading
+// (policyID[, nodeID]) columns match, stopping at the first error fn returns
+func (ms *MetadataStore) scanReferences(prefix uint32, policyID, nodeID string, fn func(vals []storage.Value, val []byte) error) error {
+	startVals := []storage.Value{storage.NewBytesValue([]byte(policyID))}
+	if nodeID != "" {
+		startVals = append(startVals, storage.NewBytesValue([]byte(nodeID)))
+	}
+
+	var fnErr error
+	err := storage.ScanPrefix(ms.kv, storage.EncodeKey(prefix, startVals), func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 4 {
+			return true
+		}
+
+		fnErr = fn(vals, val)
+		return fnErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// referenceKey builds the primary key of a reference
+func referenceKey(ref *CrossReference) []byte {
+	return storage.EncodeKey(PREFIX_REFERENCE, referenceKeyVals(ref))
+}
+
+// referenceTargetKey builds the target index key of a reference
+func referenceTargetKey(ref *CrossReference) []byte {
+	return storage.EncodeKey(PREFIX_REFERENCE_TARGET, []storage.Value{
+		storage.NewBytesValue([]byte(ref.TargetPolicyID)),
+		storage.NewBytesValue([]byte(ref.TargetNodeID)),
+		storage.NewBytesValue([]byte(ref.SourcePolicyID)),
+		storage.NewBytesValue([]byte(ref.SourceNodeID)),
+	})
+}
+
+// referenceKeyVals returns the primary key columns of a reference
+func referenceKeyVals(ref *CrossReference) []storage.Value {
+	return []storage.Value{
+		storage.NewBytesValue([]byte(ref.SourcePolicyID)),
+		storage.NewBytesValue([]byte(ref.SourceNodeID)),
+		storage.NewBytesValue([]byte(ref.TargetPolicyID)),
+		storage.NewBytesValue([]byte(ref.TargetNodeID)),
+	}
+}
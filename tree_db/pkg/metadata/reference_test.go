@@ -0,0 +1,56 @@
+// ABOUTME: Tests for cross-reference storage
+// ABOUTME: Verifies lookups by source and target and deletion of both index entries
+
+package metadata
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCrossReferences(t *testing.T) {
+	ms, kv, path := setupTestMetadataStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Unix(1700000000, 0)
+	refs := []*CrossReference{
+		{SourcePolicyID: "P", SourceNodeID: "n1", TargetPolicyID: "Q", TargetNodeID: "q1", ReferenceType: "cites", Context: "see Q 1", CreatedAt: now},
+		{SourcePolicyID: "P", SourceNodeID: "n1", TargetPolicyID: "R", TargetNodeID: "r1", ReferenceType: "supports", CreatedAt: now},
+		{SourcePolicyID: "P", SourceNodeID: "n2", TargetPolicyID: "Q", TargetNodeID: "q1", ReferenceType: "contradicts", CreatedAt: now},
+		{SourcePolicyID: "PX", SourceNodeID: "n1", TargetPolicyID: "Q", TargetNodeID: "q2", ReferenceType: "cites", CreatedAt: now},
+	}
+	for _, ref := range refs {
+		if err := ms.AddCrossReference(ref); err != nil {
+			t.Fatalf("AddCrossReference failed: %v", err)
+		}
+	}
+
+	from, _ := ms.ReferencesFrom("P", "n1")
+	if len(from) != 2 || from[0].TargetPolicyID != "Q" || from[0].Context != "see Q 1" || !from[0].CreatedAt.Equal(now) {
+		t.Errorf("Unexpected references from P/n1: %+v", from)
+	}
+	if all, _ := ms.ReferencesFrom("P", ""); len(all) != 3 {
+		t.Errorf("Expected 3 references from policy P, got %d", len(all))
+	}
+
+	to, _ := ms.ReferencesTo("Q", "q1")
+	if len(to) != 2 || to[0].SourceNodeID != "n1" || to[1].ReferenceType != "contradicts" {
+		t.Errorf("Unexpected references to Q/q1: %+v", to)
+	}
+	if all, _ := ms.ReferencesTo("Q", ""); len(all) != 3 {
+		t.Errorf("Expected 3 references to policy Q, got %d", len(all))
+	}
+
+	if err := ms.DeleteCrossReference("P", "n2", "Q", "q1"); err != nil {
+		t.Fatalf("DeleteCrossReference failed: %v", err)
+	}
+	if to, _ := ms.ReferencesTo("Q", "q1"); len(to) != 1 {
+		t.Errorf("Expected 1 reference to Q/q1 after delete, got %d", len(to))
+	}
+
+	if err := ms.AddCrossReference(&CrossReference{SourcePolicyID: "P"}); err == nil {
+		t.Error("Expected error for incomplete reference")
+	}
+}
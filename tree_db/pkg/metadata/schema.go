@@ -0,0 +1,196 @@
+// ABOUTME: Optional per-entity-type metadata schemas enforced on write
+// ABOUTME: Restricts keys, value types and enumerated values with descriptive errors
+
+package metadata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Value types understood by schema validation
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeDate    = "date" // RFC 3339 timestamp or YYYY-MM-DD
+)
+
+// FieldSchema constrains the values of one metadata key
+type FieldSchema struct {
+	ValueType string   // One of the Type constants (empty for TypeString)
+	Enum      []string // Allowed values (empty allows any value of the type)
+}
+
+// EntitySchema lists the metadata keys allowed on an entity type
+type EntitySchema struct {
+	EntityType   string
+	Fields       map[string]FieldSchema
+	AllowUnknown bool // Accept keys missing from Fields without validation
+}
+
+// ValidationError describes a metadata entry rejected by a schema
+type ValidationError struct {
+	EntityType string
+	Key        string
+	Reason     string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid metadata %s.%s: %s", e.EntityType, e.Key, e.Reason)
+}
+
+// RegisterSchema enforces a schema on future writes to an entity type
+// Registering again replaces the previous schema. Existing entries are not checked.
+func (ms *MetadataStore) RegisterSchema(schema *EntitySchema) error {
+	if schema.EntityType == "" {
+		return fmt.Errorf("schema entity type is required")
+	}
+	for key, field := range schema.Fields {
+		switch field.ValueType {
+		case "", TypeString, TypeNumber, TypeBoolean, TypeDate:
+		default:
+			return fmt.Errorf("unknown value type %q for key %s", field.ValueType, key)
+		}
+		for _, allowed := range field.Enum {
+			if err := checkType(field.valueType(), allowed); err != nil {
+				return fmt.Errorf("enum value %q for key %s: %v", allowed, key, err)
+			}
+		}
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.schemas == nil {
+		ms.schemas = make(map[string]*EntitySchema)
+	}
+	ms.schemas[schema.EntityType] = schema
+	return nil
+}
+
+// UnregisterSchema stops enforcing the schema of an entity type
+func (ms *MetadataStore) UnregisterSchema(entityType string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.schemas, entityType)
+}
+
+// Schema returns the schema registered for an entity type
+func (ms *MetadataStore) Schema(entityType string) (*EntitySchema, bool) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	schema, ok := ms.schemas[entityType]
+	return schema, ok
+}
+
+// validate checks an entry against its entity type's schema, filling in an
+// empty ValueType from the schema
+func (ms *MetadataStore) validate(entry *MetadataEntry) error {
+	schema, ok := ms.Schema(entry.EntityType)
+	if !ok {
+		return nil
+	}
+
+	invalid := func(format string, args ...interface{}) error {
+		return &ValidationError{EntityType: entry.EntityType, Key: entry.Key, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	field, ok := schema.Fields[entry.Key]
+	if !ok {
+		if schema.AllowUnknown {
+			return nil
+		}
+		if suggestion := closestKey(entry.Key, schema.Fields); suggestion != "" {
+			return invalid("key is not allowed (did you mean %q?)", suggestion)
+		}
+		return invalid("key is not allowed")
+	}
+
+	valueType := field.valueType()
+	if entry.ValueType == "" {
+		entry.ValueType = valueType
+	} else if entry.ValueType != valueType {
+		return invalid("value type %s does not match schema type %s", entry.ValueType, valueType)
+	}
+
+	if err := checkType(valueType, entry.Value); err != nil {
+		return invalid("%v", err)
+	}
+
+	if len(field.Enum) > 0 {
+		for _, allowed := range field.Enum {
+			if entry.Value == allowed {
+				return nil
+			}
+		}
+		return invalid("value %q is not one of [%s]", entry.Value, strings.Join(field.Enum, ", "))
+	}
+
+	return nil
+}
+
+// valueType returns the field's type, defaulting to TypeString
+func (f FieldSchema) valueType() string {
+	if f.ValueType == "" {
+		return TypeString
+	}
+	return f.ValueType
+}
+
+// checkType reports whether a value parses as the given type
+func checkType(valueType, value string) error {
+	switch valueType {
+	case TypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("value %q is not a number", value)
+		}
+	case TypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("value %q is not a boolean", value)
+		}
+	case TypeDate:
+		if _, err := time.Parse(time.RFC3339, value); err == nil {
+			return nil
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("value %q is not a date", value)
+		}
+	}
+	return nil
+}
+
+// closestKey returns the schema key within two edits of key, if any
+func closestKey(key string, fields map[string]FieldSchema) string {
+	best, bestDist := "", 3
+	for candidate := range fields {
+		if d := editDistance(key, candidate); d < bestDist || (d == bestDist && candidate < best) {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
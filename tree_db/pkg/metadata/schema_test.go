@@ -0,0 +1,97 @@
+// ABOUTME: Tests for metadata schema validation
+// ABOUTME: Verifies key, type and enum checks and their error messages
+
+package metadata
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchemaValidation(t *testing.T) {
+	ms, kv, path := setupTestMetadataStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	err := ms.RegisterSchema(&EntitySchema{
+		EntityType: "document",
+		Fields: map[string]FieldSchema{
+			"status":    {Enum: []string{"active", "draft", "retired"}},
+			"pages":     {ValueType: TypeNumber},
+			"effective": {ValueType: TypeDate},
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterSchema failed: %v", err)
+	}
+
+	now := time.Now()
+	set := func(key, value string) error {
+		return ms.SetMetadata(&MetadataEntry{EntityType: "document", EntityID: "doc1", Key: key, Value: value, CreatedAt: now, UpdatedAt: now})
+	}
+
+	if err := set("status", "active"); err != nil {
+		t.Errorf("Expected valid status accepted: %v", err)
+	}
+	if err := set("pages", "42"); err != nil {
+		t.Errorf("Expected valid number accepted: %v", err)
+	}
+	if err := set("effective", "2025-01-01"); err != nil {
+		t.Errorf("Expected valid date accepted: %v", err)
+	}
+
+	entry, err := ms.GetMetadata("document", "doc1", "pages")
+	if err != nil || entry.ValueType != TypeNumber {
+		t.Errorf("Expected value type filled from schema, got %+v (%v)", entry, err)
+	}
+
+	cases := []struct {
+		key, value, want string
+	}{
+		{"statuss", "active", `did you mean "status"`},
+		{"colour", "red", "key is not allowed"},
+		{"status", "archived", "is not one of [active, draft, retired]"},
+		{"pages", "many", "is not a number"},
+		{"effective", "next week", "is not a date"},
+	}
+	for _, c := range cases {
+		err := set(c.key, c.value)
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s=%s: expected ValidationError, got %v", c.key, c.value, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s=%s: expected error containing %q, got %q", c.key, c.value, c.want, err)
+		}
+	}
+
+	// A failing entry rejects the whole batch
+	err = ms.SetMetadataBatch([]*MetadataEntry{
+		{EntityType: "document", EntityID: "doc2", Key: "status", Value: "draft"},
+		{EntityType: "document", EntityID: "doc2", Key: "pages", Value: "x"},
+	})
+	if err == nil {
+		t.Error("Expected batch with invalid entry to fail")
+	}
+	if attrs, _ := ms.GetAllMetadata("document", "doc2"); len(attrs) != 0 {
+		t.Errorf("Expected nothing written for failed batch, got %v", attrs)
+	}
+
+	// Other entity types are unaffected
+	if err := ms.SetMetadata(&MetadataEntry{EntityType: "node", EntityID: "n1", Key: "anything", Value: "goes"}); err != nil {
+		t.Errorf("Expected unschematized type accepted: %v", err)
+	}
+
+	ms.UnregisterSchema("document")
+	if err := set("colour", "red"); err != nil {
+		t.Errorf("Expected writes accepted after unregister: %v", err)
+	}
+
+	if err := ms.RegisterSchema(&EntitySchema{EntityType: "x", Fields: map[string]FieldSchema{"n": {ValueType: TypeNumber, Enum: []string{"one"}}}}); err == nil {
+		t.Error("Expected error for enum value of the wrong type")
+	}
+}
@@ -4,8 +4,12 @@
 package metadata
 
 import (
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/nainya/treestore/pkg/changefeed"
 	"github.com/nainya/treestore/pkg/storage"
 )
 
@@ -16,40 +20,167 @@ const (
 	PREFIX_METADATA_KEY      = uint32(7200) // Index by (key, entityType, entityID)
 	PREFIX_METADATA_VALUE    = uint32(7300) // Index by (key, value, entityType, entityID)
 	PREFIX_METADATA_COMPOUND = uint32(7400) // Compound index for multi-attribute queries
+	PREFIX_REFERENCE         = uint32(7500) // Cross references by (srcPolicy, srcNode, tgtPolicy, tgtNode)
+	PREFIX_REFERENCE_TARGET  = uint32(7600) // Index by (tgtPolicy, tgtNode, srcPolicy, srcNode)
 )
 
+// ErrVersionConflict is returned when a metadata entry changed since the caller read it
+var ErrVersionConflict = errors.New("metadata: version conflict")
+
 // MetadataStore manages custom metadata and attributes
 type MetadataStore struct {
-	kv *storage.KV
+	kv      storage.Engine
+	feed    *changefeed.Feed // Optional; nil publishes nothing
+	writeMu sync.Mutex       // Serializes writes so version checks cannot interleave
+
+	mu       sync.RWMutex
+	compound []*CompoundIndex         // Registered compound indexes
+	schemas  map[string]*EntitySchema // Registered schemas by entity type
 }
 
 // NewMetadataStore creates a new metadata store
-func NewMetadataStore(kv *storage.KV) *MetadataStore {
+func NewMetadataStore(kv storage.Engine) *MetadataStore {
 	return &MetadataStore{kv: kv}
 }
 
+// SetChangeFeed publishes committed writes to feed; call before the store is shared
+func (ms *MetadataStore) SetChangeFeed(feed *changefeed.Feed) {
+	ms.feed = feed
+}
+
 // SetMetadata stores or updates a metadata entry
 func (ms *MetadataStore) SetMetadata(entry *MetadataEntry) error {
+	return ms.SetMetadataBatch([]*MetadataEntry{entry})
+}
+
+// SetMetadataBatch stores or updates several metadata entries in a single transaction
+// Entries are checked against registered schemas first; nothing is written if any fails.
+// Each entry's Version is set to one past its stored version.
+func (ms *MetadataStore) SetMetadataBatch(entries []*MetadataEntry) error {
+	return ms.setMetadataBatch(entries, false)
+}
+
+// CompareAndSetMetadata is SetMetadataBatch for entries read earlier
+// Each entry's Version must equal its stored version (0 for an absent entry),
+// otherwise ErrVersionConflict is returned and nothing is written.
+func (ms *MetadataStore) CompareAndSetMetadata(entries []*MetadataEntry) error {
+	return ms.setMetadataBatch(entries, true)
+}
+
+func (ms *MetadataStore) setMetadataBatch(entries []*MetadataEntry, checkVersions bool) error {
+	for _, entry := range entries {
+		if err := ms.validate(entry); err != nil {
+			return err
+		}
+	}
+
+	// Compound indexes need each affected entity's other attributes
+	oldAttrs := make(map[entityRef]map[string]string)
+	for _, entry := range entries {
+		ref := entityRef{entry.EntityType, entry.EntityID}
+		if _, loaded := oldAttrs[ref]; loaded || len(ms.indexesFor(entry.EntityType, entry.Key)) == 0 {
+			continue
+		}
+		attrs, err := ms.GetAllMetadata(entry.EntityType, entry.EntityID)
+		if err != nil {
+			return err
+		}
+		oldAttrs[ref] = attrs
+	}
+
+	ms.writeMu.Lock()
+	defer ms.writeMu.Unlock()
+
 	tx := ms.kv.Begin()
+	defer tx.Abort()
 
-	// Primary key: (entityType, entityID, key)
-	key := storage.EncodeKey(PREFIX_METADATA, []storage.Value{
-		storage.NewBytesValue([]byte(entry.EntityType)),
-		storage.NewBytesValue([]byte(entry.EntityID)),
-		storage.NewBytesValue([]byte(entry.Key)),
-	})
+	versions := make([]uint64, len(entries))
+	for i, entry := range entries {
+		var stored uint64
+		if old := loadEntry(tx, entry.EntityType, entry.EntityID, entry.Key); old != nil {
+			stored = old.Version
+		}
+		if err := tx.Err(); err != nil {
+			return err
+		}
+		if checkVersions && stored != entry.Version {
+			return fmt.Errorf("%w: %s/%s/%s is at version %d, not %d",
+				ErrVersionConflict, entry.EntityType, entry.EntityID, entry.Key, stored, entry.Version)
+		}
+		versions[i] = stored + 1
+	}
 
-	val := storage.EncodeValues([]storage.Value{
-		storage.NewBytesValue([]byte(entry.EntityType)),
-		storage.NewBytesValue([]byte(entry.EntityID)),
-		storage.NewBytesValue([]byte(entry.Key)),
-		storage.NewBytesValue([]byte(entry.Value)),
-		storage.NewBytesValue([]byte(entry.ValueType)),
-		storage.NewTimeValue(entry.CreatedAt),
-		storage.NewTimeValue(entry.UpdatedAt),
+	for i, entry := range entries {
+		written := *entry
+		written.Version = versions[i]
+		setEntry(tx, &written)
+	}
+
+	for ref, old := range oldAttrs {
+		cur := make(map[string]string, len(old))
+		for k, v := range old {
+			cur[k] = v
+		}
+		for _, entry := range entries {
+			if entry.EntityType == ref.entityType && entry.EntityID == ref.entityID {
+				cur[entry.Key] = entry.Value
+			}
+		}
+		updateCompound(tx, ms.indexesFor(ref.entityType, ""), ref.entityID, old, cur)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		entry.Version = versions[i]
+	}
+
+	// One event per entity, however many of its keys changed
+	published := make(map[entityRef]bool)
+	for _, entry := range entries {
+		ref := entityRef{entry.EntityType, entry.EntityID}
+		if !published[ref] {
+			published[ref] = true
+			ms.publish(changefeed.OpPut, ref.entityType, ref.entityID)
+		}
+	}
+	return nil
+}
+
+// entityRef identifies an entity across entity types
+type entityRef struct {
+	entityType string
+	entityID   string
+}
+
+// metadataKey returns the primary key of a metadata entry: (entityType, entityID, key)
+func metadataKey(entityType, entityID, key string) []byte {
+	return storage.EncodeKey(PREFIX_METADATA, []storage.Value{
+		storage.NewBytesValue([]byte(entityType)),
+		storage.NewBytesValue([]byte(entityID)),
+		storage.NewBytesValue([]byte(key)),
 	})
+}
+
+// loadEntry reads a metadata entry within a transaction, returning nil if it is absent or unreadable
+func loadEntry(tx storage.Txn, entityType, entityID, key string) *MetadataEntry {
+	val, ok := tx.Get(metadataKey(entityType, entityID, key))
+	if !ok {
+		return nil
+	}
+	entry, err := decodeEntry(val)
+	if err != nil {
+		return nil
+	}
+	return entry
+}
+
+// setEntry writes a metadata entry and its index entries
+func setEntry(tx storage.Txn, entry *MetadataEntry) {
+	key := metadataKey(entry.EntityType, entry.EntityID, entry.Key)
 
-	tx.Set(key, val)
+	tx.Set(key, encodeEntry(entry))
 
 	// Entity index: (entityType, entityID, key)
 	entityKey := storage.EncodeKey(PREFIX_METADATA_ENTITY, []storage.Value{
@@ -75,8 +206,6 @@ func (ms *MetadataStore) SetMetadata(entry *MetadataEntry) error {
 		storage.NewBytesValue([]byte(entry.EntityID)),
 	})
 	tx.Set(valueIndex, []byte{})
-
-	return tx.Commit()
 }
 
 // GetMetadata retrieves a specific metadata entry
@@ -87,17 +216,15 @@ func (ms *MetadataStore) GetMetadata(entityType, entityID, key string) (*Metadat
 		storage.NewBytesValue([]byte(key)),
 	})
 
-	val, ok := ms.kv.Get(metaKey)
-	if !ok {
-		return nil, fmt.Errorf("metadata not found: %s/%s/%s", entityType, entityID, key)
-	}
-
-	vals, err := storage.DecodeValues(val)
+	val, ok, err := ms.kv.Lookup(metaKey)
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		return nil, fmt.Errorf("metadata not found: %s/%s/%s", entityType, entityID, key)
+	}
 
-	return parseMetadataVals(vals)
+	return decodeEntry(val)
 }
 
 // GetAllMetadata retrieves all metadata for an entity
@@ -109,25 +236,30 @@ func (ms *MetadataStore) GetAllMetadata(entityType, entityID string) (map[string
 
 	result := make(map[string]string)
 
-	ms.kv.Scan(startKey, func(key, val []byte) bool {
+	var scanErr error
+	if err := storage.ScanPrefix(ms.kv, startKey, func(key, val []byte) bool {
 		vals, err := storage.ExtractValues(key)
 		if err != nil || len(vals) < 3 {
 			return true
 		}
 
-		// Check if still in same entity
-		if string(vals[0].Str) != entityType || string(vals[1].Str) != entityID {
-			return false
-		}
-
 		metaKey := string(vals[2].Str)
 		entry, err := ms.GetMetadata(entityType, entityID, metaKey)
+		if storage.IsCorruption(err) {
+			scanErr = err
+			return false
+		}
 		if err == nil {
 			result[metaKey] = entry.Value
 		}
 
 		return true
-	})
+	}); err != nil {
+		return nil, err
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
 
 	return result, nil
 }
@@ -140,42 +272,151 @@ func (ms *MetadataStore) DeleteMetadata(entityType, entityID, key string) error
 		return err
 	}
 
+	indexes := ms.indexesFor(entityType, key)
+	var oldAttrs map[string]string
+	if len(indexes) > 0 {
+		if oldAttrs, err = ms.GetAllMetadata(entityType, entityID); err != nil {
+			return err
+		}
+	}
+
+	ms.writeMu.Lock()
+	defer ms.writeMu.Unlock()
+
 	tx := ms.kv.Begin()
+	deleteEntry(tx, entry)
+	if len(indexes) > 0 {
+		newAttrs := make(map[string]string, len(oldAttrs))
+		for k, v := range oldAttrs {
+			if k != key {
+				newAttrs[k] = v
+			}
+		}
+		updateCompound(tx, indexes, entityID, oldAttrs, newAttrs)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// The entity's remaining metadata changed, so this is a put, not a delete
+	ms.publish(changefeed.OpPut, entityType, entityID)
+	return nil
+}
+
+// DeleteAllMetadata removes every metadata entry of an entity in a single transaction
+func (ms *MetadataStore) DeleteAllMetadata(entityType, entityID string) error {
+	attrs, err := ms.GetAllMetadata(entityType, entityID)
+	if err != nil {
+		return err
+	}
+
+	ms.writeMu.Lock()
+	defer ms.writeMu.Unlock()
 
+	tx := ms.kv.Begin()
+	for key, value := range attrs {
+		deleteEntry(tx, &MetadataEntry{EntityType: entityType, EntityID: entityID, Key: key, Value: value})
+	}
+	updateCompound(tx, ms.indexesFor(entityType, ""), entityID, attrs, nil)
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if len(attrs) > 0 {
+		ms.publish(changefeed.OpDelete, entityType, entityID)
+	}
+	return nil
+}
+
+// publish reports a change to an entity's metadata on the change feed
+func (ms *MetadataStore) publish(op changefeed.Op, entityType, entityID string) {
+	ms.feed.Publish(changefeed.EntityMetadata, op, "", entityType+"/"+entityID)
+}
+
+// ListExpiredEntities returns entities of a type whose entries were all last
+// updated before the cutoff, in entity ID order. limit <= 0 returns all.
+func (ms *MetadataStore) ListExpiredEntities(entityType string, cutoff time.Time, limit int) ([]string, error) {
+	startKey := storage.EncodeKey(PREFIX_METADATA, []storage.Value{
+		storage.NewBytesValue([]byte(entityType)),
+	})
+
+	var expired []string
+	currentID := ""
+	currentExpired := false
+	flush := func() {
+		if currentID != "" && currentExpired {
+			expired = append(expired, currentID)
+		}
+	}
+
+	if err := storage.ScanPrefix(ms.kv, startKey, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 3 {
+			return true
+		}
+
+		entityID := string(vals[1].Str)
+		if entityID != currentID {
+			flush()
+			if limit > 0 && len(expired) >= limit {
+				currentID = ""
+				return false
+			}
+			currentID, currentExpired = entityID, true
+		}
+
+		entry, err := decodeEntry(val)
+		if err == nil && !entry.UpdatedAt.Before(cutoff) {
+			currentExpired = false
+		}
+
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	flush()
+
+	if limit > 0 && len(expired) > limit {
+		expired = expired[:limit]
+	}
+
+	return expired, nil
+}
+
+// deleteEntry removes a metadata entry and its index entries
+func deleteEntry(tx storage.Txn, entry *MetadataEntry) {
 	// Delete primary
 	primaryKey := storage.EncodeKey(PREFIX_METADATA, []storage.Value{
-		storage.NewBytesValue([]byte(entityType)),
-		storage.NewBytesValue([]byte(entityID)),
-		storage.NewBytesValue([]byte(key)),
+		storage.NewBytesValue([]byte(entry.EntityType)),
+		storage.NewBytesValue([]byte(entry.EntityID)),
+		storage.NewBytesValue([]byte(entry.Key)),
 	})
 	tx.Del(primaryKey)
 
 	// Delete entity index
 	entityKey := storage.EncodeKey(PREFIX_METADATA_ENTITY, []storage.Value{
-		storage.NewBytesValue([]byte(entityType)),
-		storage.NewBytesValue([]byte(entityID)),
-		storage.NewBytesValue([]byte(key)),
+		storage.NewBytesValue([]byte(entry.EntityType)),
+		storage.NewBytesValue([]byte(entry.EntityID)),
+		storage.NewBytesValue([]byte(entry.Key)),
 	})
 	tx.Del(entityKey)
 
 	// Delete key index
 	keyIndex := storage.EncodeKey(PREFIX_METADATA_KEY, []storage.Value{
-		storage.NewBytesValue([]byte(key)),
-		storage.NewBytesValue([]byte(entityType)),
-		storage.NewBytesValue([]byte(entityID)),
+		storage.NewBytesValue([]byte(entry.Key)),
+		storage.NewBytesValue([]byte(entry.EntityType)),
+		storage.NewBytesValue([]byte(entry.EntityID)),
 	})
 	tx.Del(keyIndex)
 
 	// Delete value index
 	valueIndex := storage.EncodeKey(PREFIX_METADATA_VALUE, []storage.Value{
-		storage.NewBytesValue([]byte(key)),
+		storage.NewBytesValue([]byte(entry.Key)),
 		storage.NewBytesValue([]byte(entry.Value)),
-		storage.NewBytesValue([]byte(entityType)),
-		storage.NewBytesValue([]byte(entityID)),
+		storage.NewBytesValue([]byte(entry.EntityType)),
+		storage.NewBytesValue([]byte(entry.EntityID)),
 	})
 	tx.Del(valueIndex)
-
-	return tx.Commit()
 }
 
 // QueryByKey finds all entities with a specific metadata key
@@ -195,7 +436,8 @@ func (ms *MetadataStore) QueryByKey(key string, entityType *string, limit int) (
 	var results []*MetadataEntry
 	count := 0
 
-	ms.kv.Scan(startKey, func(k, val []byte) bool {
+	var scanErr error
+	if err := storage.ScanPrefix(ms.kv, startKey, func(k, val []byte) bool {
 		if limit > 0 && count >= limit {
 			return false
 		}
@@ -205,27 +447,26 @@ func (ms *MetadataStore) QueryByKey(key string, entityType *string, limit int) (
 			return true
 		}
 
-		// Check if still in same key
-		if string(vals[0].Str) != key {
-			return false
-		}
-
-		// If entityType filter specified, check it
-		if entityType != nil && string(vals[1].Str) != *entityType {
-			return true
-		}
-
 		eType := string(vals[1].Str)
 		eID := string(vals[2].Str)
 
 		entry, err := ms.GetMetadata(eType, eID, key)
+		if storage.IsCorruption(err) {
+			scanErr = err
+			return false
+		}
 		if err == nil {
 			results = append(results, entry)
 			count++
 		}
 
 		return true
-	})
+	}); err != nil {
+		return nil, err
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
 
 	return results, nil
 }
@@ -249,7 +490,8 @@ func (ms *MetadataStore) QueryByKeyValue(key, value string, entityType *string,
 	var results []*MetadataEntry
 	count := 0
 
-	ms.kv.Scan(startKey, func(k, val []byte) bool {
+	var scanErr error
+	if err := storage.ScanPrefix(ms.kv, startKey, func(k, val []byte) bool {
 		if limit > 0 && count >= limit {
 			return false
 		}
@@ -259,27 +501,26 @@ func (ms *MetadataStore) QueryByKeyValue(key, value string, entityType *string,
 			return true
 		}
 
-		// Check if still in same key-value
-		if string(vals[0].Str) != key || string(vals[1].Str) != value {
-			return false
-		}
-
-		// If entityType filter specified, check it
-		if entityType != nil && string(vals[2].Str) != *entityType {
-			return true
-		}
-
 		eType := string(vals[2].Str)
 		eID := string(vals[3].Str)
 
 		entry, err := ms.GetMetadata(eType, eID, key)
+		if storage.IsCorruption(err) {
+			scanErr = err
+			return false
+		}
 		if err == nil {
 			results = append(results, entry)
 			count++
 		}
 
 		return true
-	})
+	}); err != nil {
+		return nil, err
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
 
 	return results, nil
 }
@@ -290,6 +531,13 @@ func (ms *MetadataStore) QueryMultiple(filters map[string]string, entityType *st
 		return []string{}, nil
 	}
 
+	// Prefer a compound index covering several filters
+	if entityType != nil {
+		if idx := ms.bestCompoundIndex(*entityType, filters); idx != nil {
+			return ms.queryCompound(idx, filters, *entityType, limit)
+		}
+	}
+
 	// Get entities for first filter
 	var firstKey, firstValue string
 	for k, v := range filters {
@@ -331,6 +579,9 @@ func (ms *MetadataStore) QueryMultiple(filters map[string]string, entityType *st
 			}
 
 			entry, err := ms.GetMetadata(eType, entityID, key)
+			if storage.IsCorruption(err) {
+				return nil, err
+			}
 			if err != nil || entry.Value != value {
 				delete(candidates, entityID)
 			}
@@ -349,20 +600,38 @@ func (ms *MetadataStore) QueryMultiple(filters map[string]string, entityType *st
 	return results, nil
 }
 
-// Helper functions
+// queryCompound answers QueryMultiple from a compound index, checking any
+// filters the index does not cover against each candidate
+func (ms *MetadataStore) queryCompound(idx *CompoundIndex, filters map[string]string, entityType string, limit int) ([]string, error) {
+	entityIDs, err := ms.scanCompound(idx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []string{}
+	for _, entityID := range entityIDs {
+		matched := true
+		for key, value := range filters {
+			if idx.covers(key) {
+				continue
+			}
+			entry, err := ms.GetMetadata(entityType, entityID, key)
+			if storage.IsCorruption(err) {
+				return nil, err
+			}
+			if err != nil || entry.Value != value {
+				matched = false
+				break
+			}
+		}
 
-func parseMetadataVals(vals []storage.Value) (*MetadataEntry, error) {
-	if len(vals) < 7 {
-		return nil, fmt.Errorf("incomplete metadata data")
+		if matched {
+			results = append(results, entityID)
+			if limit > 0 && len(results) >= limit {
+				break
+			}
+		}
 	}
 
-	return &MetadataEntry{
-		EntityType: string(vals[0].Str),
-		EntityID:   string(vals[1].Str),
-		Key:        string(vals[2].Str),
-		Value:      string(vals[3].Str),
-		ValueType:  string(vals[4].Str),
-		CreatedAt:  vals[5].Time,
-		UpdatedAt:  vals[6].Time,
-	}, nil
+	return results, nil
 }
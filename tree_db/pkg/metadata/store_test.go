@@ -4,6 +4,7 @@
 package metadata
 
 import (
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -12,7 +13,7 @@ import (
 )
 
 func setupTestMetadataStore(t *testing.T) (*MetadataStore, *storage.KV, string) {
-	path := "/tmp/test_metadatastore_" + t.Name() + ".db"
+	path := storage.MemoryPath
 	kv := &storage.KV{Path: path}
 	if err := kv.Open(); err != nil {
 		t.Fatalf("Failed to open: %v", err)
@@ -415,3 +416,112 @@ func TestMetadataNotFound(t *testing.T) {
 		t.Error("Expected error for non-existent metadata")
 	}
 }
+
+func TestSetMetadataBatchAndDeleteAll(t *testing.T) {
+	ms, kv, path := setupTestMetadataStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	var entries []*MetadataEntry
+	for _, attr := range [][2]string{{"category", "policy"}, {"status", "active"}, {"owner", "claims"}} {
+		entries = append(entries, &MetadataEntry{
+			EntityType: "document",
+			EntityID:   "doc1",
+			Key:        attr[0],
+			Value:      attr[1],
+			ValueType:  "string",
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+
+	if err := ms.SetMetadataBatch(entries); err != nil {
+		t.Fatalf("SetMetadataBatch failed: %v", err)
+	}
+
+	attrs, err := ms.GetAllMetadata("document", "doc1")
+	if err != nil {
+		t.Fatalf("GetAllMetadata failed: %v", err)
+	}
+	if len(attrs) != 3 || attrs["owner"] != "claims" {
+		t.Errorf("Unexpected attributes: %v", attrs)
+	}
+
+	if err := ms.DeleteAllMetadata("document", "doc1"); err != nil {
+		t.Fatalf("DeleteAllMetadata failed: %v", err)
+	}
+
+	attrs, _ = ms.GetAllMetadata("document", "doc1")
+	if len(attrs) != 0 {
+		t.Errorf("Expected no attributes after delete, got %v", attrs)
+	}
+
+	docType := "document"
+	if results, _ := ms.QueryByKey("status", &docType, 0); len(results) != 0 {
+		t.Errorf("Expected key index cleared, got %d entries", len(results))
+	}
+}
+
+func TestCompareAndSetMetadata(t *testing.T) {
+	ms, kv, path := setupTestMetadataStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	entry := func(key, value string, version uint64) *MetadataEntry {
+		return &MetadataEntry{
+			EntityType: "document",
+			EntityID:   "doc1",
+			Key:        key,
+			Value:      value,
+			ValueType:  "string",
+			Version:    version,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+	}
+
+	// Version 0 means the attribute must not exist yet
+	status := entry("status", "draft", 0)
+	if err := ms.CompareAndSetMetadata([]*MetadataEntry{status}); err != nil {
+		t.Fatalf("CompareAndSetMetadata failed: %v", err)
+	}
+	if status.Version != 1 {
+		t.Errorf("Expected version 1 after create, got %d", status.Version)
+	}
+	if err := ms.CompareAndSetMetadata([]*MetadataEntry{entry("status", "dup", 0)}); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Expected ErrVersionConflict for existing attribute, got %v", err)
+	}
+
+	// A stale entry fails the whole batch; nothing is written
+	batch := []*MetadataEntry{entry("owner", "claims", 0), entry("status", "published", 7)}
+	if err := ms.CompareAndSetMetadata(batch); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Expected ErrVersionConflict for stale version, got %v", err)
+	}
+	if _, err := ms.GetMetadata("document", "doc1", "owner"); err == nil {
+		t.Error("Expected owner not to be written by failed batch")
+	}
+
+	batch = []*MetadataEntry{entry("owner", "claims", 0), entry("status", "published", 1)}
+	if err := ms.CompareAndSetMetadata(batch); err != nil {
+		t.Fatalf("CompareAndSetMetadata failed: %v", err)
+	}
+
+	got, err := ms.GetMetadata("document", "doc1", "status")
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if got.Value != "published" || got.Version != 2 {
+		t.Errorf("Expected published at version 2, got %q at %d", got.Value, got.Version)
+	}
+
+	// Unconditional writes still bump the version
+	if err := ms.SetMetadata(entry("status", "archived", 0)); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	got, _ = ms.GetMetadata("document", "doc1", "status")
+	if got.Version != 3 {
+		t.Errorf("Expected version 3 after unconditional write, got %d", got.Version)
+	}
+}
@@ -0,0 +1,107 @@
+// ABOUTME: Storage of the steps of agent trajectories, one record per step beside the trajectory's metadata
+// ABOUTME: Steps are kept in the order recorded so retrieval calls can be replayed against later documents
+
+package metadata
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// PREFIX_TRAJECTORY_STEP keys trajectory steps by (trajectoryID, position)
+const PREFIX_TRAJECTORY_STEP = uint32(7950)
+
+// EntityTrajectory is the entity type of a trajectory's metadata entries
+const EntityTrajectory = "trajectory"
+
+// MaxStepBytes bounds the encoded size of one step, so its record stays
+// within the value size limit
+const MaxStepBytes = 2048
+
+// ErrStepTooLarge is returned when a step is over MaxStepBytes
+var ErrStepTooLarge = errors.New("metadata: trajectory step too large")
+
+// PutTrajectorySteps replaces the steps of a trajectory in one transaction
+func (ms *MetadataStore) PutTrajectorySteps(trajectoryID string, steps []*TrajectoryStep) error {
+	encoded := make([][]byte, len(steps))
+	for i, step := range steps {
+		encoded[i] = encodeStep(step)
+		if len(encoded[i]) > MaxStepBytes {
+			return fmt.Errorf("%w: step %d is %d bytes, over %d", ErrStepTooLarge, step.StepNumber, len(encoded[i]), MaxStepBytes)
+		}
+	}
+
+	ms.writeMu.Lock()
+	defer ms.writeMu.Unlock()
+
+	tx := ms.kv.Begin()
+	defer tx.Abort()
+
+	deleteSteps(tx, trajectoryID)
+	for i, val := range encoded {
+		tx.Set(stepKey(trajectoryID, i), val)
+	}
+	return tx.Commit()
+}
+
+// TrajectorySteps returns the steps of a trajectory in the order stored
+func (ms *MetadataStore) TrajectorySteps(trajectoryID string) ([]*TrajectoryStep, error) {
+	var steps []*TrajectoryStep
+	var decodeErr error
+	err := storage.ScanPrefix(ms.kv, stepPrefix(trajectoryID), func(key, val []byte) bool {
+		step, err := decodeStep(val)
+		if err != nil {
+			decodeErr = fmt.Errorf("trajectory %s: %w", trajectoryID, err)
+			return false
+		}
+		steps = append(steps, step)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return steps, decodeErr
+}
+
+// DeleteTrajectory removes a trajectory's steps and then its metadata, so a
+// failure part way leaves the trajectory for a later delete to find
+func (ms *MetadataStore) DeleteTrajectory(trajectoryID string) error {
+	ms.writeMu.Lock()
+	tx := ms.kv.Begin()
+	deleteSteps(tx, trajectoryID)
+	err := tx.Commit()
+	ms.writeMu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ms.DeleteAllMetadata(EntityTrajectory, trajectoryID)
+}
+
+// deleteSteps deletes every step of a trajectory within a transaction
+func deleteSteps(tx storage.Txn, trajectoryID string) {
+	var keys [][]byte
+	storage.ScanPrefix(tx, stepPrefix(trajectoryID), func(key, val []byte) bool {
+		keys = append(keys, append([]byte(nil), key...))
+		return true
+	})
+	for _, key := range keys {
+		tx.Del(key)
+	}
+}
+
+// stepKey returns the key of the step at a position of a trajectory
+func stepKey(trajectoryID string, position int) []byte {
+	return storage.EncodeKey(PREFIX_TRAJECTORY_STEP, []storage.Value{
+		storage.NewBytesValue([]byte(trajectoryID)),
+		storage.NewInt64Value(int64(position)),
+	})
+}
+
+// stepPrefix returns the prefix of the keys of a trajectory's steps
+func stepPrefix(trajectoryID string) []byte {
+	return storage.EncodeKey(PREFIX_TRAJECTORY_STEP, []storage.Value{
+		storage.NewBytesValue([]byte(trajectoryID)),
+	})
+}
@@ -0,0 +1,71 @@
+// ABOUTME: Tests for trajectory step storage
+// ABOUTME: Verifies ordering, replacement, the size limit and deletion with the trajectory's metadata
+
+package metadata
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrajectorySteps(t *testing.T) {
+	ms, kv, path := setupTestMetadataStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now().Truncate(time.Second)
+	steps := []*TrajectoryStep{
+		{StepNumber: 1, ToolName: "search", Action: `{"query":"imaging"}`, Observation: `{"results":[]}`, Thought: "Find the imaging section", Timestamp: now},
+		{StepNumber: 2, ToolName: "get_node", Action: `{"policy_id":"LCD-1","node_id":"n2"}`},
+	}
+	if err := ms.PutTrajectorySteps("traj-1", steps); err != nil {
+		t.Fatalf("PutTrajectorySteps failed: %v", err)
+	}
+	// A trajectory whose ID extends the first must not leak into it
+	if err := ms.PutTrajectorySteps("traj-10", []*TrajectoryStep{{StepNumber: 1}}); err != nil {
+		t.Fatalf("PutTrajectorySteps failed: %v", err)
+	}
+
+	got, err := ms.TrajectorySteps("traj-1")
+	if err != nil {
+		t.Fatalf("TrajectorySteps failed: %v", err)
+	}
+	if len(got) != 2 || got[0].Thought != steps[0].Thought || !got[0].Timestamp.Equal(now) || got[1].Action != steps[1].Action {
+		t.Fatalf("Unexpected steps %+v", got)
+	}
+
+	// Storing again replaces every step
+	if err := ms.PutTrajectorySteps("traj-1", steps[1:]); err != nil {
+		t.Fatalf("PutTrajectorySteps failed: %v", err)
+	}
+	if got, _ := ms.TrajectorySteps("traj-1"); len(got) != 1 || got[0].StepNumber != 2 {
+		t.Errorf("Expected the steps replaced, got %+v", got)
+	}
+
+	huge := &TrajectoryStep{StepNumber: 3, Observation: strings.Repeat("x", MaxStepBytes)}
+	if err := ms.PutTrajectorySteps("traj-1", []*TrajectoryStep{huge}); !errors.Is(err, ErrStepTooLarge) {
+		t.Errorf("Expected ErrStepTooLarge, got %v", err)
+	}
+	if got, _ := ms.TrajectorySteps("traj-1"); len(got) != 1 {
+		t.Errorf("Expected a rejected write to keep the old steps, got %+v", got)
+	}
+
+	if err := ms.SetMetadata(&MetadataEntry{EntityType: EntityTrajectory, EntityID: "traj-1", Key: "case_id", Value: "case-1"}); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	if err := ms.DeleteTrajectory("traj-1"); err != nil {
+		t.Fatalf("DeleteTrajectory failed: %v", err)
+	}
+	if got, _ := ms.TrajectorySteps("traj-1"); len(got) != 0 {
+		t.Errorf("Expected no steps after delete, got %+v", got)
+	}
+	if attrs, _ := ms.GetAllMetadata(EntityTrajectory, "traj-1"); len(attrs) != 0 {
+		t.Errorf("Expected no metadata after delete, got %v", attrs)
+	}
+	if got, _ := ms.TrajectorySteps("traj-10"); len(got) != 1 {
+		t.Errorf("Expected the other trajectory kept, got %+v", got)
+	}
+}
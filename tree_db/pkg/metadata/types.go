@@ -12,10 +12,45 @@ type MetadataEntry struct {
 	Key        string    // Metadata key
 	Value      string    // Metadata value
 	ValueType  string    // Type hint (string, number, boolean, date)
+	Version    uint64    // Incremented on every write; checked by CompareAndSetMetadata
 	CreatedAt  time.Time // When metadata was added
 	UpdatedAt  time.Time // Last update time
 }
 
+// CrossReference links a node of one policy to a node of another
+type CrossReference struct {
+	SourcePolicyID string
+	SourceNodeID   string
+	TargetPolicyID string
+	TargetNodeID   string
+	ReferenceType  string // "cites", "contradicts", "supports"
+	Context        string // Text around the reference
+	CreatedAt      time.Time
+}
+
+// Collection is a named set of policies that searches and tree reads can
+// cover together, such as "cardiology-2024"
+type Collection struct {
+	Name        string
+	Description string
+	PolicyIDs   []string          // Members, sorted
+	Metadata    map[string]string // Free-form attributes, e.g. specialty or year
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TrajectoryStep is one step of an agent's reasoning trajectory. Steps that
+// call a retrieval tool record its arguments as JSON in Action and its result
+// in Observation, so they can be replayed.
+type TrajectoryStep struct {
+	StepNumber  int
+	ToolName    string
+	Action      string
+	Observation string
+	Thought     string
+	Timestamp   time.Time
+}
+
 // MetadataQuery options for querying metadata
 type MetadataQuery struct {
 	EntityType *string            // Filter by entity type
@@ -0,0 +1,242 @@
+// ABOUTME: Conversation mutation and filtered listing
+// ABOUTME: Renaming, tag maintenance, archiving and ConversationQuery listings
+
+package prompt
+
+import (
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// UpdateConversationTitle renames a conversation
+func (ps *PromptStore) UpdateConversationTitle(conversationID, title string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	conv, err := ps.GetConversation(conversationID)
+	if err != nil {
+		return err
+	}
+
+	tx := ps.kv.Begin()
+	conv.Title = title
+	ps.updateConversation(tx, conv)
+	return tx.Commit()
+}
+
+// AddTag tags a conversation; adding a tag it already has is a no-op
+func (ps *PromptStore) AddTag(conversationID, tag string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	conv, err := ps.GetConversation(conversationID)
+	if err != nil {
+		return err
+	}
+
+	if hasTag(conv, tag) {
+		return nil
+	}
+
+	tx := ps.kv.Begin()
+	conv.Tags = append(conv.Tags, tag)
+	ps.updateConversation(tx, conv)
+	tx.Set(conversationTagKey(tag, conversationID), []byte{})
+	return tx.Commit()
+}
+
+// RemoveTag removes a tag from a conversation; removing a missing tag is a no-op
+func (ps *PromptStore) RemoveTag(conversationID, tag string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	conv, err := ps.GetConversation(conversationID)
+	if err != nil {
+		return err
+	}
+
+	tags := make([]string, 0, len(conv.Tags))
+	for _, existing := range conv.Tags {
+		if existing != tag {
+			tags = append(tags, existing)
+		}
+	}
+	if len(tags) == len(conv.Tags) {
+		return nil
+	}
+
+	tx := ps.kv.Begin()
+	conv.Tags = tags
+	ps.updateConversation(tx, conv)
+	tx.Del(conversationTagKey(tag, conversationID))
+	return tx.Commit()
+}
+
+// SetArchived archives or restores a conversation
+// Archived conversations keep their history but are hidden from ListConversations
+// unless IncludeArchived is set.
+func (ps *PromptStore) SetArchived(conversationID string, archived bool) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	conv, err := ps.GetConversation(conversationID)
+	if err != nil {
+		return err
+	}
+	if conv.Archived == archived {
+		return nil
+	}
+
+	tx := ps.kv.Begin()
+	conv.Archived = archived
+	ps.updateConversation(tx, conv)
+	return tx.Commit()
+}
+
+// ListConversations returns conversations matching every filter in the query
+// The user or tag index is used when that filter is set, otherwise conversations
+// are scanned by start time, oldest first.
+func (ps *PromptStore) ListConversations(q ConversationQuery) ([]*ConversationWithMessages, error) {
+	var prefix uint32
+	var scope []storage.Value
+	idColumn := 0
+
+	switch {
+	case q.UserID != nil:
+		prefix, idColumn = PREFIX_CONVERSATION_USER, 2
+		scope = []storage.Value{storage.NewBytesValue([]byte(*q.UserID))}
+	case q.Tag != nil:
+		prefix, idColumn = PREFIX_CONVERSATION_TAG, 1
+		scope = []storage.Value{storage.NewBytesValue([]byte(*q.Tag))}
+	default:
+		prefix, idColumn = PREFIX_CONVERSATION_TIME, 1
+	}
+
+	var results []*ConversationWithMessages
+	var scanErr error
+
+	if err := storage.ScanPrefix(ps.kv, storage.EncodeKey(prefix, scope), func(key, val []byte) bool {
+		if q.Limit > 0 && len(results) >= q.Limit {
+			return false
+		}
+
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) <= idColumn {
+			return true
+		}
+
+		conv, err := ps.GetConversation(string(vals[idColumn].Str))
+		if storage.IsCorruption(err) {
+			scanErr = err
+			return false
+		}
+		if err != nil || !q.matches(conv) {
+			return true
+		}
+
+		entry := &ConversationWithMessages{Conversation: conv}
+		if q.IncludeMessages {
+			entry.Messages, scanErr = ps.GetMessages(conv.ConversationID)
+			if scanErr != nil {
+				return false
+			}
+		}
+		results = append(results, entry)
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	return results, nil
+}
+
+// matches reports whether a conversation passes the query filters
+func (q ConversationQuery) matches(conv *Conversation) bool {
+	if conv.Archived && !q.IncludeArchived {
+		return false
+	}
+	if q.UserID != nil && conv.UserID != *q.UserID {
+		return false
+	}
+	if q.Tag != nil && !hasTag(conv, *q.Tag) {
+		return false
+	}
+	if q.StartTime != nil && conv.StartedAt.Before(*q.StartTime) {
+		return false
+	}
+	if q.EndTime != nil && conv.StartedAt.After(*q.EndTime) {
+		return false
+	}
+	return true
+}
+
+// hasTag reports whether a conversation carries a tag
+func hasTag(conv *Conversation, tag string) bool {
+	for _, existing := range conv.Tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// conversationTagKey builds the tag index key of a conversation
+func conversationTagKey(tag, conversationID string) []byte {
+	return storage.EncodeKey(PREFIX_CONVERSATION_TAG, []storage.Value{
+		storage.NewBytesValue([]byte(tag)),
+		storage.NewBytesValue([]byte(conversationID)),
+	})
+}
+
+// ListExpiredConversations returns conversations with no activity since the cutoff
+// Activity is the last message time, or the start time for empty conversations.
+// Conversations are visited by start time, so the scan stops at the cutoff.
+func (ps *PromptStore) ListExpiredConversations(cutoff time.Time, limit int) ([]string, error) {
+	startKey := storage.EncodeKey(PREFIX_CONVERSATION_TIME, nil)
+
+	var expired []string
+	var scanErr error
+	if err := storage.ScanPrefix(ps.kv, startKey, func(key, val []byte) bool {
+		if limit > 0 && len(expired) >= limit {
+			return false
+		}
+
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 2 {
+			return true
+		}
+		if !vals[0].Time.Before(cutoff) {
+			return false
+		}
+
+		conv, err := ps.GetConversation(string(vals[1].Str))
+		if storage.IsCorruption(err) {
+			scanErr = err
+			return false
+		}
+		if err != nil {
+			return true
+		}
+
+		lastActive := conv.LastMessageAt
+		if lastActive.IsZero() {
+			lastActive = conv.StartedAt
+		}
+		if lastActive.Before(cutoff) {
+			expired = append(expired, conv.ConversationID)
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,110 @@
+// ABOUTME: Tests for conversation mutation and filtered listing
+// ABOUTME: Verifies rename, tag index maintenance and archive filtering
+
+package prompt
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestUpdateConversationTitleAndTags(t *testing.T) {
+	ps, kv, path := setupTestPromptStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	if err := ps.CreateConversation(&Conversation{ConversationID: "conv1", UserID: "user1", Title: "Old", StartedAt: now, Tags: []string{"billing"}}); err != nil {
+		t.Fatalf("Failed to create conversation: %v", err)
+	}
+
+	if err := ps.UpdateConversationTitle("conv1", "New"); err != nil {
+		t.Fatalf("UpdateConversationTitle failed: %v", err)
+	}
+	if err := ps.AddTag("conv1", "urgent"); err != nil {
+		t.Fatalf("AddTag failed: %v", err)
+	}
+	if err := ps.AddTag("conv1", "urgent"); err != nil {
+		t.Fatalf("AddTag (duplicate) failed: %v", err)
+	}
+	if err := ps.RemoveTag("conv1", "billing"); err != nil {
+		t.Fatalf("RemoveTag failed: %v", err)
+	}
+
+	conv, err := ps.GetConversation("conv1")
+	if err != nil {
+		t.Fatalf("GetConversation failed: %v", err)
+	}
+	if conv.Title != "New" {
+		t.Errorf("Expected title New, got %s", conv.Title)
+	}
+	if len(conv.Tags) != 1 || conv.Tags[0] != "urgent" {
+		t.Errorf("Expected tags [urgent], got %v", conv.Tags)
+	}
+
+	if convs, _ := ps.ListConversationsByTag("billing", 0); len(convs) != 0 {
+		t.Errorf("Expected billing tag index removed, got %d", len(convs))
+	}
+	if convs, _ := ps.ListConversationsByTag("urgent", 0); len(convs) != 1 {
+		t.Errorf("Expected urgent tag index entry, got %d", len(convs))
+	}
+
+	if err := ps.AddTag("missing", "x"); err == nil {
+		t.Error("Expected error tagging missing conversation")
+	}
+}
+
+func TestListConversationsArchived(t *testing.T) {
+	ps, kv, path := setupTestPromptStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	base := time.Unix(1700000000, 0)
+	for i, id := range []string{"conv1", "conv2", "conv3"} {
+		user := "user1"
+		if id == "conv3" {
+			user = "user2"
+		}
+		if err := ps.CreateConversation(&Conversation{ConversationID: id, UserID: user, StartedAt: base.Add(time.Duration(i) * time.Hour)}); err != nil {
+			t.Fatalf("Failed to create conversation: %v", err)
+		}
+	}
+
+	if err := ps.SetArchived("conv1", true); err != nil {
+		t.Fatalf("SetArchived failed: %v", err)
+	}
+
+	all, err := ps.ListConversations(ConversationQuery{})
+	if err != nil {
+		t.Fatalf("ListConversations failed: %v", err)
+	}
+	if len(all) != 2 || all[0].Conversation.ConversationID != "conv2" {
+		t.Errorf("Expected conv2, conv3 without archived, got %d results", len(all))
+	}
+
+	user := "user1"
+	withArchived, err := ps.ListConversations(ConversationQuery{UserID: &user, IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("ListConversations failed: %v", err)
+	}
+	if len(withArchived) != 2 || !withArchived[0].Conversation.Archived {
+		t.Errorf("Expected both user1 conversations with conv1 archived, got %d", len(withArchived))
+	}
+
+	start := base.Add(90 * time.Minute)
+	recent, err := ps.ListConversations(ConversationQuery{StartTime: &start})
+	if err != nil {
+		t.Fatalf("ListConversations failed: %v", err)
+	}
+	if len(recent) != 1 || recent[0].Conversation.ConversationID != "conv3" {
+		t.Errorf("Expected only conv3 after start time, got %d", len(recent))
+	}
+
+	if err := ps.SetArchived("conv1", false); err != nil {
+		t.Fatalf("SetArchived failed: %v", err)
+	}
+	if all, _ := ps.ListConversations(ConversationQuery{Limit: 10}); len(all) != 3 {
+		t.Errorf("Expected 3 conversations after restore, got %d", len(all))
+	}
+}
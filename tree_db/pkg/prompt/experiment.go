@@ -0,0 +1,247 @@
+// ABOUTME: Prompt experiments that split traffic between template variants by stable buckets
+// ABOUTME: Usages are recorded with outcome labels and aggregated into per-variant success rates
+
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// Prefixes for experiment storage
+const (
+	PREFIX_EXPERIMENT       = uint32(8800) // Experiments by (experimentID)
+	PREFIX_EXPERIMENT_USAGE = uint32(8900) // Usages by (experimentID, usageID)
+)
+
+// Errors returned by experiment operations
+var (
+	ErrExperimentNotFound = errors.New("prompt: experiment not found")
+	ErrUsageNotFound      = errors.New("prompt: experiment usage not found")
+	ErrUnknownVariant     = errors.New("prompt: unknown variant")
+	ErrInvalidExperiment  = errors.New("prompt: invalid experiment")
+)
+
+// PutExperiment creates or replaces an experiment. Usages already recorded
+// keep their variant; replacing the weights reassigns future traffic.
+func (ps *PromptStore) PutExperiment(exp *Experiment) error {
+	if exp.ExperimentID == "" {
+		return fmt.Errorf("%w: experiment_id is required", ErrInvalidExperiment)
+	}
+	if len(exp.Variants) == 0 {
+		return fmt.Errorf("%w: at least one variant is required", ErrInvalidExperiment)
+	}
+	seen := make(map[string]bool)
+	for _, v := range exp.Variants {
+		switch {
+		case v.Name == "":
+			return fmt.Errorf("%w: variants need a name", ErrInvalidExperiment)
+		case seen[v.Name]:
+			return fmt.Errorf("%w: variant %q is named twice", ErrInvalidExperiment, v.Name)
+		case v.Weight <= 0:
+			return fmt.Errorf("%w: variant %q needs a positive weight", ErrInvalidExperiment, v.Name)
+		}
+		seen[v.Name] = true
+	}
+
+	tx := ps.kv.Begin()
+	tx.Set(experimentKey(exp.ExperimentID), encodeExperiment(exp))
+	return tx.Commit()
+}
+
+// GetExperiment retrieves an experiment by ID
+func (ps *PromptStore) GetExperiment(experimentID string) (*Experiment, error) {
+	val, ok, err := ps.kv.Lookup(experimentKey(experimentID))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrExperimentNotFound, experimentID)
+	}
+	return decodeExperiment(val)
+}
+
+// AssignVariant returns the variant serving a unit of traffic, such as a user
+// or case ID. The unit hashes to a bucket, so it gets the same variant for as
+// long as the weights stay the same.
+func (ps *PromptStore) AssignVariant(experimentID, unit string) (*Variant, error) {
+	exp, err := ps.GetExperiment(experimentID)
+	if err != nil {
+		return nil, err
+	}
+	return exp.Assign(unit), nil
+}
+
+// Assign returns the variant whose buckets hold the unit's bucket
+func (exp *Experiment) Assign(unit string) *Variant {
+	total := 0
+	for _, v := range exp.Variants {
+		total += v.Weight
+	}
+	h := fnv.New32a()
+	h.Write([]byte(exp.ExperimentID))
+	h.Write([]byte{0})
+	h.Write([]byte(unit))
+	bucket := int(h.Sum32() % uint32(total))
+
+	for _, v := range exp.Variants {
+		if bucket < v.Weight {
+			return v
+		}
+		bucket -= v.Weight
+	}
+	return exp.Variants[len(exp.Variants)-1]
+}
+
+// variant returns the experiment's variant with the given name
+func (exp *Experiment) variant(name string) *Variant {
+	for _, v := range exp.Variants {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// RecordExperimentUsage records a use of one of an experiment's variants,
+// replacing any usage with the same ID. An empty PromptID is filled with the
+// variant's.
+func (ps *PromptStore) RecordExperimentUsage(u *ExperimentUsage) error {
+	if u.UsageID == "" {
+		return fmt.Errorf("%w: usage_id is required", ErrInvalidExperiment)
+	}
+	exp, err := ps.GetExperiment(u.ExperimentID)
+	if err != nil {
+		return err
+	}
+	v := exp.variant(u.Variant)
+	if v == nil {
+		return fmt.Errorf("%w: %q in experiment %s", ErrUnknownVariant, u.Variant, u.ExperimentID)
+	}
+	if u.PromptID == "" {
+		u.PromptID = v.PromptID
+	}
+
+	tx := ps.kv.Begin()
+	tx.Set(usageKey(u.ExperimentID, u.UsageID), encodeUsage(u))
+	return tx.Commit()
+}
+
+// LabelUsage sets the outcome of a recorded usage, for outcomes known only
+// after the response was used
+func (ps *PromptStore) LabelUsage(experimentID, usageID, outcome string) error {
+	key := usageKey(experimentID, usageID)
+
+	tx := ps.kv.Begin()
+	defer tx.Abort()
+
+	val, ok := tx.Get(key)
+	if err := tx.Err(); err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s in experiment %s", ErrUsageNotFound, usageID, experimentID)
+	}
+	u, err := decodeUsage(val)
+	if err != nil {
+		return err
+	}
+	u.Outcome = outcome
+	tx.Set(key, encodeUsage(u))
+	return tx.Commit()
+}
+
+// CompareVariants aggregates an experiment's usages by variant, in the
+// experiment's variant order followed by variants since removed. A zero since
+// or until leaves that end of the time range open.
+func (ps *PromptStore) CompareVariants(experimentID string, since, until time.Time) ([]*VariantStats, error) {
+	exp, err := ps.GetExperiment(experimentID)
+	if err != nil {
+		return nil, err
+	}
+	successes := exp.SuccessOutcomes
+	if len(successes) == 0 {
+		successes = []string{OutcomeSuccess}
+	}
+	isSuccess := make(map[string]bool)
+	for _, outcome := range successes {
+		isSuccess[outcome] = true
+	}
+
+	var stats []*VariantStats
+	byVariant := make(map[string]*VariantStats)
+	add := func(name, promptID string) *VariantStats {
+		vs := &VariantStats{Variant: name, PromptID: promptID, Outcomes: make(map[string]int)}
+		stats = append(stats, vs)
+		byVariant[name] = vs
+		return vs
+	}
+	for _, v := range exp.Variants {
+		add(v.Name, v.PromptID)
+	}
+
+	var scanErr error
+	err = storage.ScanPrefix(ps.kv, usagePrefix(experimentID), func(key, val []byte) bool {
+		u, err := decodeUsage(val)
+		if err != nil {
+			scanErr = err
+			return false
+		}
+		if (!since.IsZero() && u.UsedAt.Before(since)) || (!until.IsZero() && !u.UsedAt.Before(until)) {
+			return true
+		}
+
+		vs := byVariant[u.Variant]
+		if vs == nil {
+			vs = add(u.Variant, u.PromptID)
+		}
+		vs.Usages++
+		if u.Outcome != "" {
+			vs.Labeled++
+			vs.Outcomes[u.Outcome]++
+			if isSuccess[u.Outcome] {
+				vs.Successes++
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	for _, vs := range stats {
+		if vs.Labeled > 0 {
+			vs.SuccessRate = float64(vs.Successes) / float64(vs.Labeled)
+		}
+	}
+	return stats, nil
+}
+
+// experimentKey returns the key of an experiment
+func experimentKey(experimentID string) []byte {
+	return storage.EncodeKey(PREFIX_EXPERIMENT, []storage.Value{
+		storage.NewBytesValue([]byte(experimentID)),
+	})
+}
+
+// usageKey returns the key of an experiment usage
+func usageKey(experimentID, usageID string) []byte {
+	return storage.EncodeKey(PREFIX_EXPERIMENT_USAGE, []storage.Value{
+		storage.NewBytesValue([]byte(experimentID)),
+		storage.NewBytesValue([]byte(usageID)),
+	})
+}
+
+// usagePrefix returns the prefix of the keys of an experiment's usages
+func usagePrefix(experimentID string) []byte {
+	return storage.EncodeKey(PREFIX_EXPERIMENT_USAGE, []storage.Value{
+		storage.NewBytesValue([]byte(experimentID)),
+	})
+}
@@ -0,0 +1,129 @@
+// ABOUTME: Tests for prompt experiments
+// ABOUTME: Verifies stable bucket assignment, usage labeling and per-variant aggregation
+
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExperimentAssignment(t *testing.T) {
+	ps, kv, path := setupTestPromptStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	exp := &Experiment{
+		ExperimentID: "exp1",
+		Name:         "Summary prompt",
+		Variants: []*Variant{
+			{Name: "control", PromptID: "p-v1", Weight: 3},
+			{Name: "concise", PromptID: "p-v2", Weight: 1},
+		},
+		CreatedAt: time.Now(),
+	}
+	if err := ps.PutExperiment(exp); err != nil {
+		t.Fatalf("PutExperiment failed: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		unit := fmt.Sprintf("user-%d", i)
+		v, err := ps.AssignVariant("exp1", unit)
+		if err != nil {
+			t.Fatalf("AssignVariant failed: %v", err)
+		}
+		if again, _ := ps.AssignVariant("exp1", unit); again.Name != v.Name {
+			t.Fatalf("Expected %s to keep variant %s, got %s", unit, v.Name, again.Name)
+		}
+		counts[v.Name]++
+	}
+	if share := float64(counts["control"]) / 2000; math.Abs(share-0.75) > 0.05 {
+		t.Errorf("Expected about 75%% control traffic, got %v", counts)
+	}
+
+	if _, err := ps.AssignVariant("missing", "user-1"); !errors.Is(err, ErrExperimentNotFound) {
+		t.Errorf("Expected ErrExperimentNotFound, got %v", err)
+	}
+	for _, bad := range []*Experiment{
+		{ExperimentID: "bad"},
+		{ExperimentID: "bad", Variants: []*Variant{{Name: "a", Weight: 1}, {Name: "a", Weight: 1}}},
+		{ExperimentID: "bad", Variants: []*Variant{{Name: "a"}}},
+	} {
+		if err := ps.PutExperiment(bad); !errors.Is(err, ErrInvalidExperiment) {
+			t.Errorf("Expected ErrInvalidExperiment for %+v, got %v", bad, err)
+		}
+	}
+}
+
+func TestCompareVariants(t *testing.T) {
+	ps, kv, path := setupTestPromptStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now().Truncate(time.Second)
+	err := ps.PutExperiment(&Experiment{
+		ExperimentID: "exp1",
+		Variants: []*Variant{
+			{Name: "control", PromptID: "p-v1", Weight: 1},
+			{Name: "concise", PromptID: "p-v2", Weight: 1},
+		},
+		SuccessOutcomes: []string{"accepted"},
+	})
+	if err != nil {
+		t.Fatalf("PutExperiment failed: %v", err)
+	}
+
+	usages := []*ExperimentUsage{
+		{UsageID: "u1", Variant: "control", Outcome: "accepted", UsedAt: now},
+		{UsageID: "u2", Variant: "control", Outcome: "rejected", UsedAt: now},
+		{UsageID: "u3", Variant: "control", UsedAt: now},
+		{UsageID: "u4", Variant: "concise", Outcome: "accepted", UsedAt: now},
+		{UsageID: "u5", Variant: "concise", UsedAt: now.Add(-time.Hour)},
+	}
+	for _, u := range usages {
+		u.ExperimentID = "exp1"
+		if err := ps.RecordExperimentUsage(u); err != nil {
+			t.Fatalf("RecordExperimentUsage failed: %v", err)
+		}
+	}
+	if usages[0].PromptID != "p-v1" {
+		t.Errorf("Expected the variant's prompt filled in, got %q", usages[0].PromptID)
+	}
+	if err := ps.RecordExperimentUsage(&ExperimentUsage{UsageID: "u6", ExperimentID: "exp1", Variant: "verbose"}); !errors.Is(err, ErrUnknownVariant) {
+		t.Errorf("Expected ErrUnknownVariant, got %v", err)
+	}
+
+	// The outcome of u5 arrives later
+	if err := ps.LabelUsage("exp1", "u5", "rejected"); err != nil {
+		t.Fatalf("LabelUsage failed: %v", err)
+	}
+	if err := ps.LabelUsage("exp1", "missing", "accepted"); !errors.Is(err, ErrUsageNotFound) {
+		t.Errorf("Expected ErrUsageNotFound, got %v", err)
+	}
+
+	stats, err := ps.CompareVariants("exp1", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("CompareVariants failed: %v", err)
+	}
+	if len(stats) != 2 || stats[0].Variant != "control" || stats[1].Variant != "concise" {
+		t.Fatalf("Unexpected stats %+v", stats)
+	}
+	control, concise := stats[0], stats[1]
+	if control.Usages != 3 || control.Labeled != 2 || control.Successes != 1 || control.SuccessRate != 0.5 {
+		t.Errorf("Unexpected control stats %+v", control)
+	}
+	if concise.Usages != 2 || concise.Outcomes["rejected"] != 1 || concise.SuccessRate != 0.5 {
+		t.Errorf("Unexpected concise stats %+v", concise)
+	}
+
+	// The last half hour excludes u5
+	stats, _ = ps.CompareVariants("exp1", now.Add(-30*time.Minute), time.Time{})
+	if stats[1].Usages != 1 || stats[1].SuccessRate != 1 {
+		t.Errorf("Expected only u4 for concise, got %+v", stats[1])
+	}
+}
@@ -0,0 +1,127 @@
+// ABOUTME: Export of conversations as the message arrays of the OpenAI and Anthropic chat APIs
+// ABOUTME: Feeds stored conversations to fine-tuning and evaluation pipelines; deleted messages are left out
+
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Export formats
+const (
+	FormatOpenAI    = "openai"    // {"messages": [...]}, system messages inline
+	FormatAnthropic = "anthropic" // {"system": "...", "messages": [...]}, user and assistant turns only
+)
+
+// Errors returned by ExportConversation
+var (
+	ErrUnsupportedFormat = errors.New("prompt: unsupported export format")
+	ErrUnsupportedRole   = errors.New("prompt: role not supported by export format")
+)
+
+// ExportOptions controls how a conversation is exported
+type ExportOptions struct {
+	Format       string // FormatOpenAI (default) or FormatAnthropic
+	OmitMetadata bool   // Leave out metadata, for payloads an API accepts as they are
+}
+
+// ConversationExport is a conversation in a chat API's message format,
+// marshaled as that API's request body
+type ConversationExport struct {
+	System   string            `json:"system,omitempty"` // Anthropic: the system messages, joined
+	Messages []ExportedMessage `json:"messages"`
+	Metadata map[string]string `json:"metadata,omitempty"` // The conversation's ID, user, title, tags and metadata
+}
+
+// ExportedMessage is one message of an export
+type ExportedMessage struct {
+	Role     string            `json:"role"`
+	Content  string            `json:"content"`
+	Metadata map[string]string `json:"metadata,omitempty"` // The message's ID, times and metadata
+}
+
+// ExportConversation returns a conversation's messages in a chat API's format.
+// Anthropic exports move system messages to System and fail on roles other
+// than user and assistant; OpenAI exports keep every role as stored.
+func (ps *PromptStore) ExportConversation(conversationID string, opts ExportOptions) (*ConversationExport, error) {
+	format := opts.Format
+	if format == "" {
+		format = FormatOpenAI
+	}
+	if format != FormatOpenAI && format != FormatAnthropic {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, opts.Format)
+	}
+
+	cwm, err := ps.GetConversationWithMessages(conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &ConversationExport{Messages: []ExportedMessage{}}
+	var system []string
+	for _, msg := range cwm.Messages {
+		if msg.Deleted {
+			continue
+		}
+		if format == FormatAnthropic {
+			switch msg.Role {
+			case "system":
+				system = append(system, msg.Content)
+				continue
+			case "user", "assistant":
+			default:
+				return nil, fmt.Errorf("%w: %q in message %s", ErrUnsupportedRole, msg.Role, msg.MessageID)
+			}
+		}
+
+		out := ExportedMessage{Role: msg.Role, Content: msg.Content}
+		if !opts.OmitMetadata {
+			out.Metadata = messageExportMetadata(msg)
+		}
+		export.Messages = append(export.Messages, out)
+	}
+	export.System = strings.Join(system, "\n\n")
+	if !opts.OmitMetadata {
+		export.Metadata = conversationExportMetadata(cwm.Conversation)
+	}
+	return export, nil
+}
+
+// conversationExportMetadata flattens a conversation's fields into its
+// metadata; stored metadata keys do not override the fields
+func conversationExportMetadata(conv *Conversation) map[string]string {
+	md := make(map[string]string, len(conv.Metadata)+4)
+	for k, v := range conv.Metadata {
+		md[k] = v
+	}
+	md["conversation_id"] = conv.ConversationID
+	if conv.UserID != "" {
+		md["user_id"] = conv.UserID
+	}
+	if conv.Title != "" {
+		md["title"] = conv.Title
+	}
+	if len(conv.Tags) > 0 {
+		md["tags"] = strings.Join(conv.Tags, ",")
+	}
+	return md
+}
+
+// messageExportMetadata flattens a message's ID and times into its metadata
+func messageExportMetadata(msg *Message) map[string]string {
+	md := make(map[string]string, len(msg.Metadata)+3)
+	for k, v := range msg.Metadata {
+		md[k] = v
+	}
+	md["message_id"] = msg.MessageID
+	if !msg.Timestamp.IsZero() {
+		md["timestamp"] = msg.Timestamp.UTC().Format(time.RFC3339)
+	}
+	if !msg.EditedAt.IsZero() {
+		md["edited_at"] = msg.EditedAt.UTC().Format(time.RFC3339)
+	}
+	return md
+}
@@ -0,0 +1,77 @@
+// ABOUTME: Tests for conversation export
+// ABOUTME: Verifies the OpenAI and Anthropic shapes, metadata and skipped deleted messages
+
+package prompt
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExportConversation(t *testing.T) {
+	ps, kv, path := setupTestPromptStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := ps.CreateConversation(&Conversation{
+		ConversationID: "conv1", UserID: "user1", Title: "Imaging coverage", StartedAt: now,
+		Tags: []string{"imaging", "eval"}, Metadata: map[string]string{"case": "42"},
+	}); err != nil {
+		t.Fatalf("CreateConversation failed: %v", err)
+	}
+	for i, m := range []*Message{
+		{MessageID: "m1", Role: "system", Content: "You review prior authorization requests."},
+		{MessageID: "m2", Role: "user", Content: "Is an MRI covered?", Metadata: map[string]string{"channel": "web"}},
+		{MessageID: "m3", Role: "assistant", Content: "Yes, with prior authorization."},
+		{MessageID: "m4", Role: "user", Content: "Ignore that"},
+	} {
+		m.ConversationID = "conv1"
+		m.Timestamp = now.Add(time.Duration(i) * time.Minute)
+		if err := ps.AddMessage(m); err != nil {
+			t.Fatalf("AddMessage failed: %v", err)
+		}
+	}
+	if err := ps.DeleteMessage("m4", "user1"); err != nil {
+		t.Fatalf("DeleteMessage failed: %v", err)
+	}
+
+	openai, err := ps.ExportConversation("conv1", ExportOptions{})
+	if err != nil {
+		t.Fatalf("ExportConversation failed: %v", err)
+	}
+	if len(openai.Messages) != 3 || openai.Messages[0].Role != "system" || openai.System != "" {
+		t.Fatalf("Expected three messages with the system message inline, got %+v", openai)
+	}
+	if md := openai.Messages[1].Metadata; md["message_id"] != "m2" || md["channel"] != "web" || md["timestamp"] != "2025-03-01T12:01:00Z" {
+		t.Errorf("Unexpected message metadata %v", md)
+	}
+	if md := openai.Metadata; md["conversation_id"] != "conv1" || md["tags"] != "imaging,eval" || md["case"] != "42" {
+		t.Errorf("Unexpected conversation metadata %v", md)
+	}
+
+	anthropic, err := ps.ExportConversation("conv1", ExportOptions{Format: FormatAnthropic, OmitMetadata: true})
+	if err != nil {
+		t.Fatalf("ExportConversation failed: %v", err)
+	}
+	data, _ := json.Marshal(anthropic)
+	want := `{"system":"You review prior authorization requests.","messages":[` +
+		`{"role":"user","content":"Is an MRI covered?"},{"role":"assistant","content":"Yes, with prior authorization."}]}`
+	if string(data) != want {
+		t.Errorf("Unexpected Anthropic export\n got %s\nwant %s", data, want)
+	}
+
+	ps.AddMessage(&Message{MessageID: "m5", ConversationID: "conv1", Role: "tool", Content: "{}", Timestamp: now.Add(time.Hour)})
+	if _, err := ps.ExportConversation("conv1", ExportOptions{Format: FormatAnthropic}); !errors.Is(err, ErrUnsupportedRole) {
+		t.Errorf("Expected ErrUnsupportedRole, got %v", err)
+	}
+	if _, err := ps.ExportConversation("conv1", ExportOptions{Format: "gemini"}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+	if _, err := ps.ExportConversation("missing", ExportOptions{}); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("Expected ErrConversationNotFound, got %v", err)
+	}
+}
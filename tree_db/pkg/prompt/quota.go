@@ -0,0 +1,216 @@
+// ABOUTME: Per-user conversation and message counters with optional quotas
+// ABOUTME: Counters change in the same transaction as the conversations they count; quotas cap runaway sessions
+
+package prompt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// PREFIX_USER_USAGE holds counters by (userID). A user without one, such as
+// one whose conversations predate counting, is counted from the user index
+// on first use.
+const PREFIX_USER_USAGE = uint32(8250)
+
+// ErrQuotaExceeded is returned when a conversation or message would take a
+// user past their quota
+var ErrQuotaExceeded = errors.New("prompt: quota exceeded")
+
+// SetDefaultQuota sets the quota of users without one of their own; the zero
+// Quota, the default, is unlimited
+func (ps *PromptStore) SetDefaultQuota(q Quota) {
+	ps.quota = q
+}
+
+// DefaultQuota returns the quota of users without one of their own
+func (ps *PromptStore) DefaultQuota() Quota {
+	return ps.quota
+}
+
+// SetUserQuota gives a user a quota of their own in place of the default;
+// nil returns them to the default. Conversations and messages already over
+// a lowered quota are kept, but no more are accepted.
+func (ps *PromptStore) SetUserQuota(userID string, q *Quota) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	tx := ps.kv.Begin()
+	defer tx.Abort()
+
+	u, err := ps.loadUserUsage(tx, userID)
+	if err != nil {
+		return err
+	}
+	u.CustomQuota = q != nil
+	if q != nil {
+		u.Quota = *q
+	}
+	tx.Set(userUsageKey(userID), encodeUserUsage(u))
+	return tx.Commit()
+}
+
+// GetUserUsage returns a user's counters and the quota that applies to them
+func (ps *PromptStore) GetUserUsage(userID string) (*UserUsage, error) {
+	tx := ps.kv.Begin()
+	defer tx.Abort()
+
+	return ps.loadUserUsage(tx, userID)
+}
+
+// ListConversationsByUserPage retrieves up to limit of a user's conversations
+// following afterConversationID, oldest first; an empty cursor starts at the
+// beginning
+func (ps *PromptStore) ListConversationsByUserPage(userID, afterConversationID string, limit int) (*ConversationPage, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	prefix := storage.EncodeKey(PREFIX_CONVERSATION_USER, []storage.Value{
+		storage.NewBytesValue([]byte(userID)),
+	})
+	startKey := prefix
+	if afterConversationID != "" {
+		after, err := ps.GetConversation(afterConversationID)
+		if err != nil {
+			return nil, err
+		}
+		if after.UserID != userID {
+			return nil, fmt.Errorf("conversation %s does not belong to user %s", afterConversationID, userID)
+		}
+		startKey = storage.EncodeKeyPartial(PREFIX_CONVERSATION_USER, []storage.Value{
+			storage.NewBytesValue([]byte(userID)),
+			storage.NewTimeValue(after.StartedAt),
+			storage.NewBytesValue([]byte(after.ConversationID)),
+		}, storage.CMP_GT)
+	}
+
+	// Read one extra entry to learn whether another page follows
+	var conversationIDs []string
+	if err := storage.ScanPrefixFrom(ps.kv, prefix, startKey, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 3 {
+			return true
+		}
+
+		conversationIDs = append(conversationIDs, string(vals[2].Str))
+		return len(conversationIDs) <= limit
+	}); err != nil {
+		return nil, err
+	}
+
+	page := &ConversationPage{}
+	if len(conversationIDs) > limit {
+		page.HasMore = true
+		conversationIDs = conversationIDs[:limit]
+	}
+	for _, id := range conversationIDs {
+		conv, err := ps.GetConversation(id)
+		if errors.Is(err, ErrConversationNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		page.Conversations = append(page.Conversations, conv)
+	}
+
+	return page, nil
+}
+
+// loadUserUsage reads a user's counters within tx, counting them from the
+// user index when none are stored, and fills in the default quota
+func (ps *PromptStore) loadUserUsage(tx storage.Txn, userID string) (*UserUsage, error) {
+	val, ok := tx.Get(userUsageKey(userID))
+	if err := tx.Err(); err != nil {
+		return nil, err
+	}
+
+	var u *UserUsage
+	if ok {
+		var err error
+		if u, err = decodeUserUsage(val); err != nil {
+			return nil, err
+		}
+	} else {
+		u = &UserUsage{UserID: userID}
+		if err := ps.countUserUsage(tx, u); err != nil {
+			return nil, err
+		}
+	}
+
+	if !u.CustomQuota {
+		u.Quota = ps.quota
+	}
+	return u, nil
+}
+
+// countUserUsage counts a user's conversations and their messages from the
+// user index
+func (ps *PromptStore) countUserUsage(tx storage.Txn, u *UserUsage) error {
+	startKey := storage.EncodeKey(PREFIX_CONVERSATION_USER, []storage.Value{
+		storage.NewBytesValue([]byte(u.UserID)),
+	})
+
+	var scanErr error
+	if err := storage.ScanPrefix(tx, startKey, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 3 {
+			return true
+		}
+
+		convVal, ok := tx.Get(conversationKey(string(vals[2].Str)))
+		if !ok {
+			return true
+		}
+		conv, err := decodeConversation(convVal)
+		if err != nil {
+			scanErr = err
+			return false
+		}
+		u.Conversations++
+		u.Messages += int64(conv.MessageCount)
+		return true
+	}); err != nil {
+		return err
+	}
+	if scanErr != nil {
+		return scanErr
+	}
+	return tx.Err()
+}
+
+// checkConversationQuota reports whether the user may start another conversation
+func (u *UserUsage) checkConversationQuota() error {
+	if limit := u.Quota.MaxConversations; limit > 0 && u.Conversations >= limit {
+		return fmt.Errorf("%w: user %q has %d of %d conversations", ErrQuotaExceeded, u.UserID, u.Conversations, limit)
+	}
+	return nil
+}
+
+// checkMessageQuota reports whether the user may add another message to conv
+func (u *UserUsage) checkMessageQuota(conv *Conversation) error {
+	if limit := u.Quota.MaxMessages; limit > 0 && u.Messages >= limit {
+		return fmt.Errorf("%w: user %q has %d of %d messages", ErrQuotaExceeded, u.UserID, u.Messages, limit)
+	}
+	if limit := u.Quota.MaxConversationMessages; limit > 0 && int64(conv.MessageCount) >= limit {
+		return fmt.Errorf("%w: conversation %s has %d of %d messages", ErrQuotaExceeded, conv.ConversationID, conv.MessageCount, limit)
+	}
+	return nil
+}
+
+// userUsageKey returns the key of a user's counters
+func userUsageKey(userID string) []byte {
+	return storage.EncodeKey(PREFIX_USER_USAGE, []storage.Value{
+		storage.NewBytesValue([]byte(userID)),
+	})
+}
+
+// conversationKey returns the primary key of a conversation
+func conversationKey(conversationID string) []byte {
+	return storage.EncodeKey(PREFIX_CONVERSATION, []storage.Value{
+		storage.NewBytesValue([]byte(conversationID)),
+	})
+}
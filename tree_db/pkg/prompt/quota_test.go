@@ -0,0 +1,190 @@
+// ABOUTME: Tests for per-user counters, quotas and paged conversation listing
+// ABOUTME: Verifies counting through create, add and delete, lazy counting and quota enforcement
+
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUserUsageAndQuota(t *testing.T) {
+	ps, kv, path := setupTestPromptStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	addConversation := func(id string, messages int) error {
+		if err := ps.CreateConversation(&Conversation{ConversationID: id, UserID: "agent1", StartedAt: now}); err != nil {
+			return err
+		}
+		for i := 0; i < messages; i++ {
+			msg := &Message{MessageID: fmt.Sprintf("%s-m%d", id, i), ConversationID: id, Role: "user", Content: "hi", Timestamp: now}
+			if err := ps.AddMessage(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Counters written before counting began are rebuilt from the user index
+	if err := addConversation("c1", 2); err != nil {
+		t.Fatalf("addConversation failed: %v", err)
+	}
+	tx := kv.Begin()
+	tx.Del(userUsageKey("agent1"))
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if err := addConversation("c2", 1); err != nil {
+		t.Fatalf("addConversation failed: %v", err)
+	}
+
+	usage, err := ps.GetUserUsage("agent1")
+	if err != nil {
+		t.Fatalf("GetUserUsage failed: %v", err)
+	}
+	if usage.Conversations != 2 || usage.Messages != 3 || usage.CustomQuota {
+		t.Errorf("Expected 2 conversations and 3 messages, got %+v", usage)
+	}
+
+	ps.SetDefaultQuota(Quota{MaxConversations: 2, MaxConversationMessages: 3})
+	if err := addConversation("c3", 0); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for a third conversation, got %v", err)
+	}
+	if err := ps.AddMessage(&Message{MessageID: "c1-m2", ConversationID: "c1", Timestamp: now}); err != nil {
+		t.Errorf("AddMessage under the quota failed: %v", err)
+	}
+	if err := ps.AddMessage(&Message{MessageID: "c1-m3", ConversationID: "c1", Timestamp: now}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for a fourth message, got %v", err)
+	}
+	if _, err := ps.GetMessage("c1-m3"); err == nil {
+		t.Error("Expected the rejected message not to be stored")
+	}
+
+	// A user's own quota replaces the default until cleared
+	if err := ps.SetUserQuota("agent1", &Quota{MaxMessages: 4}); err != nil {
+		t.Fatalf("SetUserQuota failed: %v", err)
+	}
+	if err := addConversation("c3", 1); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for a fifth message, got %v", err)
+	}
+	if usage, _ := ps.GetUserUsage("agent1"); !usage.CustomQuota || usage.Quota.MaxMessages != 4 || usage.Conversations != 3 {
+		t.Errorf("Unexpected usage %+v", usage)
+	}
+
+	if err := ps.DeleteConversation("c1"); err != nil {
+		t.Fatalf("DeleteConversation failed: %v", err)
+	}
+	if err := ps.SetUserQuota("agent1", nil); err != nil {
+		t.Fatalf("SetUserQuota failed: %v", err)
+	}
+	usage, _ = ps.GetUserUsage("agent1")
+	if usage.Conversations != 2 || usage.Messages != 1 || usage.CustomQuota || usage.Quota.MaxConversations != 2 {
+		t.Errorf("Expected c1 uncounted and the default quota restored, got %+v", usage)
+	}
+}
+
+func TestConcurrentMessagesRespectQuota(t *testing.T) {
+	ps, kv, path := setupTestPromptStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	const conversations, writers, perWriter, limit = 4, 8, 10, 30
+	now := time.Now()
+	for c := 0; c < conversations; c++ {
+		if err := ps.CreateConversation(&Conversation{ConversationID: fmt.Sprintf("c%d", c), UserID: "agent1", StartedAt: now}); err != nil {
+			t.Fatalf("CreateConversation failed: %v", err)
+		}
+	}
+	ps.SetDefaultQuota(Quota{MaxMessages: limit})
+
+	// Writers race for the user's quota; rejected messages abort their transactions
+	var wg sync.WaitGroup
+	var accepted atomic.Int64
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				msg := &Message{
+					MessageID:      fmt.Sprintf("w%d-m%d", w, i),
+					ConversationID: fmt.Sprintf("c%d", (w+i)%conversations),
+					Role:           "user",
+					Content:        "hi",
+					Timestamp:      now,
+				}
+				err := ps.AddMessage(msg)
+				switch {
+				case err == nil:
+					accepted.Add(1)
+				case !errors.Is(err, ErrQuotaExceeded):
+					t.Errorf("AddMessage failed: %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if accepted.Load() != limit {
+		t.Errorf("Expected exactly %d messages accepted, got %d", limit, accepted.Load())
+	}
+	usage, err := ps.GetUserUsage("agent1")
+	if err != nil || usage.Messages != limit {
+		t.Errorf("Expected %d messages counted, got %+v (%v)", limit, usage, err)
+	}
+	stored := 0
+	for c := 0; c < conversations; c++ {
+		messages, err := ps.GetMessages(fmt.Sprintf("c%d", c))
+		if err != nil {
+			t.Fatalf("GetMessages failed: %v", err)
+		}
+		stored += len(messages)
+	}
+	if stored != limit {
+		t.Errorf("Expected every accepted message stored, found %d", stored)
+	}
+}
+
+func TestListConversationsByUserPage(t *testing.T) {
+	ps, kv, path := setupTestPromptStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		ps.CreateConversation(&Conversation{ConversationID: fmt.Sprintf("c%d", i), UserID: "user1", StartedAt: base.Add(time.Duration(i) * time.Hour)})
+	}
+	ps.CreateConversation(&Conversation{ConversationID: "other", UserID: "user2", StartedAt: base})
+
+	var ids []string
+	after := ""
+	for pages := 0; ; pages++ {
+		page, err := ps.ListConversationsByUserPage("user1", after, 2)
+		if err != nil {
+			t.Fatalf("ListConversationsByUserPage failed: %v", err)
+		}
+		for _, conv := range page.Conversations {
+			ids = append(ids, conv.ConversationID)
+		}
+		if !page.HasMore {
+			break
+		}
+		if pages > 3 {
+			t.Fatal("Paging did not end")
+		}
+		after = page.Conversations[len(page.Conversations)-1].ConversationID
+	}
+	if fmt.Sprint(ids) != "[c0 c1 c2 c3 c4]" {
+		t.Errorf("Expected every conversation of user1 in start order, got %v", ids)
+	}
+
+	if _, err := ps.ListConversationsByUserPage("user1", "other", 2); err == nil {
+		t.Error("Expected an error for a cursor of another user")
+	}
+}
@@ -0,0 +1,460 @@
+// ABOUTME: Encoding of conversations, messages, message revisions, user counters and prompt experiments as stored
+// ABOUTME: They are tag-length-value records; readers also accept the tuples of values written before them
+
+package prompt
+
+import (
+	"fmt"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// Schema versions of the records this package writes
+const (
+	conversationSchema = 1
+	messageSchema      = 1
+	revisionSchema     = 1
+	experimentSchema   = 1
+	variantSchema      = 1
+	usageSchema        = 1
+	userUsageSchema    = 1
+)
+
+// Field tags of a conversation record
+const (
+	conversationFieldID = iota + 1
+	conversationFieldUserID
+	conversationFieldTitle
+	conversationFieldStartedAt
+	conversationFieldLastMessageAt
+	conversationFieldMessageCount
+	conversationFieldTags
+	conversationFieldMetadata
+	conversationFieldArchived
+)
+
+// Field tags of a message record
+const (
+	messageFieldID = iota + 1
+	messageFieldConversationID
+	messageFieldRole
+	messageFieldContent
+	messageFieldTimestamp
+	messageFieldMetadata
+	messageFieldEditedAt
+	messageFieldDeleted
+)
+
+// Field tags of a message revision record
+const (
+	revisionFieldMessageID = iota + 1
+	revisionFieldRevision
+	revisionFieldAction
+	revisionFieldContent
+	revisionFieldChangedBy
+	revisionFieldChangedAt
+)
+
+// Field tags of an experiment record
+const (
+	experimentFieldID = iota + 1
+	experimentFieldName
+	experimentFieldVariants
+	experimentFieldSuccessOutcomes
+	experimentFieldCreatedAt
+)
+
+// Field tags of a variant record, nested in an experiment's
+const (
+	variantFieldName = iota + 1
+	variantFieldPromptID
+	variantFieldWeight
+)
+
+// Field tags of an experiment usage record
+const (
+	usageFieldID = iota + 1
+	usageFieldExperimentID
+	usageFieldVariant
+	usageFieldPromptID
+	usageFieldOutcome
+	usageFieldUsedAt
+)
+
+// Field tags of a user counter record
+const (
+	userUsageFieldUserID = iota + 1
+	userUsageFieldConversations
+	userUsageFieldMessages
+	userUsageFieldCustomQuota
+	userUsageFieldMaxConversations
+	userUsageFieldMaxMessages
+	userUsageFieldMaxConversationMessages
+)
+
+// encodeConversation encodes a conversation as a record
+func encodeConversation(conv *Conversation) []byte {
+	w := storage.NewRecordWriter(conversationSchema)
+	w.String(conversationFieldID, conv.ConversationID)
+	w.String(conversationFieldUserID, conv.UserID)
+	w.String(conversationFieldTitle, conv.Title)
+	w.Time(conversationFieldStartedAt, conv.StartedAt)
+	w.Time(conversationFieldLastMessageAt, conv.LastMessageAt)
+	w.Int(conversationFieldMessageCount, int64(conv.MessageCount))
+	w.Bytes(conversationFieldTags, storage.EncodeStrings(conv.Tags))
+	w.Bytes(conversationFieldMetadata, storage.EncodeStringMap(conv.Metadata))
+	w.Bool(conversationFieldArchived, conv.Archived)
+	return w.Encode()
+}
+
+// decodeConversation decodes a stored conversation, a record or a tuple
+// written before them. Tags and metadata that do not decode read as empty.
+func decodeConversation(val []byte) (*Conversation, error) {
+	if !storage.IsRecord(val) {
+		vals, err := storage.DecodeValues(val)
+		if err != nil {
+			return nil, err
+		}
+		return parseConversationVals(vals)
+	}
+
+	r, err := storage.DecodeRecord(val)
+	if err != nil {
+		return nil, err
+	}
+	tags, _ := storage.DecodeStrings(r.Bytes(conversationFieldTags))
+	metadata, _ := storage.DecodeStringMap(r.Bytes(conversationFieldMetadata))
+	return &Conversation{
+		ConversationID: r.String(conversationFieldID),
+		UserID:         r.String(conversationFieldUserID),
+		Title:          r.String(conversationFieldTitle),
+		StartedAt:      r.Time(conversationFieldStartedAt),
+		LastMessageAt:  r.Time(conversationFieldLastMessageAt),
+		MessageCount:   int(r.Int(conversationFieldMessageCount)),
+		Tags:           tags,
+		Metadata:       metadata,
+		Archived:       r.Bool(conversationFieldArchived),
+	}, nil
+}
+
+// encodeMessage encodes a message as a record
+func encodeMessage(msg *Message) []byte {
+	w := storage.NewRecordWriter(messageSchema)
+	w.String(messageFieldID, msg.MessageID)
+	w.String(messageFieldConversationID, msg.ConversationID)
+	w.String(messageFieldRole, msg.Role)
+	w.String(messageFieldContent, msg.Content)
+	w.Time(messageFieldTimestamp, msg.Timestamp)
+	w.Bytes(messageFieldMetadata, storage.EncodeStringMap(msg.Metadata))
+	w.Time(messageFieldEditedAt, msg.EditedAt)
+	w.Bool(messageFieldDeleted, msg.Deleted)
+	return w.Encode()
+}
+
+// decodeMessage decodes a stored message, a record or a tuple written before
+// them. Metadata that does not decode reads as empty.
+func decodeMessage(val []byte) (*Message, error) {
+	if !storage.IsRecord(val) {
+		vals, err := storage.DecodeValues(val)
+		if err != nil {
+			return nil, err
+		}
+		return parseMessageVals(vals)
+	}
+
+	r, err := storage.DecodeRecord(val)
+	if err != nil {
+		return nil, err
+	}
+	metadata, _ := storage.DecodeStringMap(r.Bytes(messageFieldMetadata))
+	return &Message{
+		MessageID:      r.String(messageFieldID),
+		ConversationID: r.String(messageFieldConversationID),
+		Role:           r.String(messageFieldRole),
+		Content:        r.String(messageFieldContent),
+		Timestamp:      r.Time(messageFieldTimestamp),
+		Metadata:       metadata,
+		EditedAt:       r.Time(messageFieldEditedAt),
+		Deleted:        r.Bool(messageFieldDeleted),
+	}, nil
+}
+
+// encodeRevision encodes a message revision as a record
+func encodeRevision(rev *MessageRevision) []byte {
+	w := storage.NewRecordWriter(revisionSchema)
+	w.String(revisionFieldMessageID, rev.MessageID)
+	w.Int(revisionFieldRevision, int64(rev.Revision))
+	w.String(revisionFieldAction, rev.Action)
+	w.String(revisionFieldContent, rev.Content)
+	w.String(revisionFieldChangedBy, rev.ChangedBy)
+	w.Time(revisionFieldChangedAt, rev.ChangedAt)
+	return w.Encode()
+}
+
+// decodeRevision decodes a stored message revision, a record or a tuple written before them
+func decodeRevision(val []byte) (*MessageRevision, error) {
+	if !storage.IsRecord(val) {
+		vals, err := storage.DecodeValues(val)
+		if err != nil {
+			return nil, err
+		}
+		return parseRevisionVals(vals)
+	}
+
+	r, err := storage.DecodeRecord(val)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageRevision{
+		MessageID: r.String(revisionFieldMessageID),
+		Revision:  int(r.Int(revisionFieldRevision)),
+		Action:    r.String(revisionFieldAction),
+		Content:   r.String(revisionFieldContent),
+		ChangedBy: r.String(revisionFieldChangedBy),
+		ChangedAt: r.Time(revisionFieldChangedAt),
+	}, nil
+}
+
+// encodeExperiment encodes an experiment as a record, its variants as a list
+// of nested records
+func encodeExperiment(exp *Experiment) []byte {
+	variants := make([]string, len(exp.Variants))
+	for i, v := range exp.Variants {
+		vw := storage.NewRecordWriter(variantSchema)
+		vw.String(variantFieldName, v.Name)
+		vw.String(variantFieldPromptID, v.PromptID)
+		vw.Int(variantFieldWeight, int64(v.Weight))
+		variants[i] = string(vw.Encode())
+	}
+
+	w := storage.NewRecordWriter(experimentSchema)
+	w.String(experimentFieldID, exp.ExperimentID)
+	w.String(experimentFieldName, exp.Name)
+	w.Bytes(experimentFieldVariants, storage.EncodeStrings(variants))
+	w.Bytes(experimentFieldSuccessOutcomes, storage.EncodeStrings(exp.SuccessOutcomes))
+	w.Time(experimentFieldCreatedAt, exp.CreatedAt)
+	return w.Encode()
+}
+
+// decodeExperiment decodes a stored experiment
+func decodeExperiment(val []byte) (*Experiment, error) {
+	r, err := storage.DecodeRecord(val)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := storage.DecodeStrings(r.Bytes(experimentFieldVariants))
+	if err != nil {
+		return nil, fmt.Errorf("variants: %w", err)
+	}
+	outcomes, _ := storage.DecodeStrings(r.Bytes(experimentFieldSuccessOutcomes))
+
+	exp := &Experiment{
+		ExperimentID:    r.String(experimentFieldID),
+		Name:            r.String(experimentFieldName),
+		SuccessOutcomes: outcomes,
+		CreatedAt:       r.Time(experimentFieldCreatedAt),
+	}
+	for _, data := range variants {
+		vr, err := storage.DecodeRecord([]byte(data))
+		if err != nil {
+			return nil, fmt.Errorf("variant: %w", err)
+		}
+		exp.Variants = append(exp.Variants, &Variant{
+			Name:     vr.String(variantFieldName),
+			PromptID: vr.String(variantFieldPromptID),
+			Weight:   int(vr.Int(variantFieldWeight)),
+		})
+	}
+	return exp, nil
+}
+
+// encodeUsage encodes an experiment usage as a record
+func encodeUsage(u *ExperimentUsage) []byte {
+	w := storage.NewRecordWriter(usageSchema)
+	w.String(usageFieldID, u.UsageID)
+	w.String(usageFieldExperimentID, u.ExperimentID)
+	w.String(usageFieldVariant, u.Variant)
+	w.String(usageFieldPromptID, u.PromptID)
+	w.String(usageFieldOutcome, u.Outcome)
+	w.Time(usageFieldUsedAt, u.UsedAt)
+	return w.Encode()
+}
+
+// decodeUsage decodes a stored experiment usage
+func decodeUsage(val []byte) (*ExperimentUsage, error) {
+	r, err := storage.DecodeRecord(val)
+	if err != nil {
+		return nil, err
+	}
+	return &ExperimentUsage{
+		UsageID:      r.String(usageFieldID),
+		ExperimentID: r.String(usageFieldExperimentID),
+		Variant:      r.String(usageFieldVariant),
+		PromptID:     r.String(usageFieldPromptID),
+		Outcome:      r.String(usageFieldOutcome),
+		UsedAt:       r.Time(usageFieldUsedAt),
+	}, nil
+}
+
+// CheckConversationRecord reports whether a stored conversation decodes
+// completely: its fields parse and its tags and metadata use up exactly their
+// bytes. Reads fall back to empty tags and metadata instead of failing, so
+// background checks use it to catch records that no longer decode.
+func CheckConversationRecord(val []byte) error {
+	var tags, metadata []byte
+	if storage.IsRecord(val) {
+		r, err := storage.DecodeRecord(val)
+		if err != nil {
+			return err
+		}
+		tags, metadata = r.Bytes(conversationFieldTags), r.Bytes(conversationFieldMetadata)
+	} else {
+		vals, err := storage.DecodeValues(val)
+		if err != nil {
+			return err
+		}
+		if _, err := parseConversationVals(vals); err != nil {
+			return err
+		}
+		tags, metadata = vals[6].Str, vals[7].Str
+	}
+
+	if _, err := storage.DecodeStrings(tags); err != nil {
+		return fmt.Errorf("tags: %w", err)
+	}
+	if _, err := storage.DecodeStringMap(metadata); err != nil {
+		return fmt.Errorf("metadata: %w", err)
+	}
+	return nil
+}
+
+// CheckMessageRecord is CheckConversationRecord for message records
+func CheckMessageRecord(val []byte) error {
+	var metadata []byte
+	if storage.IsRecord(val) {
+		r, err := storage.DecodeRecord(val)
+		if err != nil {
+			return err
+		}
+		metadata = r.Bytes(messageFieldMetadata)
+	} else {
+		vals, err := storage.DecodeValues(val)
+		if err != nil {
+			return err
+		}
+		if _, err := parseMessageVals(vals); err != nil {
+			return err
+		}
+		metadata = vals[5].Str
+	}
+
+	if _, err := storage.DecodeStringMap(metadata); err != nil {
+		return fmt.Errorf("metadata: %w", err)
+	}
+	return nil
+}
+
+// parseConversationVals reads a conversation stored as a tuple of values, before records
+func parseConversationVals(vals []storage.Value) (*Conversation, error) {
+	if len(vals) < 8 {
+		return nil, fmt.Errorf("incomplete conversation data")
+	}
+
+	tags, _ := storage.DecodeStrings(vals[6].Str)
+	metadata, _ := storage.DecodeStringMap(vals[7].Str)
+
+	conv := &Conversation{
+		ConversationID: string(vals[0].Str),
+		UserID:         string(vals[1].Str),
+		Title:          string(vals[2].Str),
+		StartedAt:      vals[3].Time,
+		LastMessageAt:  vals[4].Time,
+		MessageCount:   int(vals[5].I64),
+		Tags:           tags,
+		Metadata:       metadata,
+	}
+
+	// Archived was added later; older records have 8 values
+	if len(vals) > 8 {
+		conv.Archived = vals[8].I64 != 0
+	}
+
+	return conv, nil
+}
+
+// parseMessageVals reads a message stored as a tuple of values, before records
+func parseMessageVals(vals []storage.Value) (*Message, error) {
+	if len(vals) < 6 {
+		return nil, fmt.Errorf("incomplete message data")
+	}
+
+	metadata, _ := storage.DecodeStringMap(vals[5].Str)
+
+	msg := &Message{
+		MessageID:      string(vals[0].Str),
+		ConversationID: string(vals[1].Str),
+		Role:           string(vals[2].Str),
+		Content:        string(vals[3].Str),
+		Timestamp:      vals[4].Time,
+		Metadata:       metadata,
+	}
+
+	// Edit tracking was added later; older records have 6 values
+	if len(vals) > 7 {
+		msg.EditedAt = vals[6].Time
+		msg.Deleted = vals[7].I64 != 0
+	}
+
+	return msg, nil
+}
+
+// parseRevisionVals reads a revision stored as a tuple of values, before records
+func parseRevisionVals(vals []storage.Value) (*MessageRevision, error) {
+	if len(vals) < 6 {
+		return nil, fmt.Errorf("incomplete revision data")
+	}
+
+	return &MessageRevision{
+		MessageID: string(vals[0].Str),
+		Revision:  int(vals[1].I64),
+		Action:    string(vals[2].Str),
+		Content:   string(vals[3].Str),
+		ChangedBy: string(vals[4].Str),
+		ChangedAt: vals[5].Time,
+	}, nil
+}
+
+// encodeUserUsage encodes a user's counters as a record; the quota is kept
+// only when it is the user's own
+func encodeUserUsage(u *UserUsage) []byte {
+	w := storage.NewRecordWriter(userUsageSchema)
+	w.String(userUsageFieldUserID, u.UserID)
+	w.Int(userUsageFieldConversations, u.Conversations)
+	w.Int(userUsageFieldMessages, u.Messages)
+	w.Bool(userUsageFieldCustomQuota, u.CustomQuota)
+	if u.CustomQuota {
+		w.Int(userUsageFieldMaxConversations, u.Quota.MaxConversations)
+		w.Int(userUsageFieldMaxMessages, u.Quota.MaxMessages)
+		w.Int(userUsageFieldMaxConversationMessages, u.Quota.MaxConversationMessages)
+	}
+	return w.Encode()
+}
+
+// decodeUserUsage decodes a user counter record
+func decodeUserUsage(val []byte) (*UserUsage, error) {
+	r, err := storage.DecodeRecord(val)
+	if err != nil {
+		return nil, err
+	}
+	return &UserUsage{
+		UserID:        r.String(userUsageFieldUserID),
+		Conversations: r.Int(userUsageFieldConversations),
+		Messages:      r.Int(userUsageFieldMessages),
+		CustomQuota:   r.Bool(userUsageFieldCustomQuota),
+		Quota: Quota{
+			MaxConversations:        r.Int(userUsageFieldMaxConversations),
+			MaxMessages:             r.Int(userUsageFieldMaxMessages),
+			MaxConversationMessages: r.Int(userUsageFieldMaxConversationMessages),
+		},
+	}, nil
+}
@@ -0,0 +1,135 @@
+// ABOUTME: Message editing and soft deletion with a preserved revision history
+// ABOUTME: Every change records the prior content so conversations remain auditable
+
+package prompt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// EditMessage replaces a message's content, recording the previous content as a revision
+func (ps *PromptStore) EditMessage(messageID, content, editedBy string) error {
+	return ps.reviseMessage(messageID, RevisionEdit, editedBy, func(msg *Message) {
+		msg.Content = content
+	})
+}
+
+// DeleteMessage tombstones a message: the live record keeps its place in the
+// conversation with empty content and Deleted set, and the original content is
+// kept in the revision history.
+func (ps *PromptStore) DeleteMessage(messageID, deletedBy string) error {
+	return ps.reviseMessage(messageID, RevisionDelete, deletedBy, func(msg *Message) {
+		msg.Content = ""
+		msg.Deleted = true
+	})
+}
+
+// GetMessageHistory returns the revisions of a message, oldest first
+func (ps *PromptStore) GetMessageHistory(messageID string) ([]*MessageRevision, error) {
+	var revisions []*MessageRevision
+	var scanErr error
+
+	err := ps.scanRevisions(messageID, func(key, val []byte) {
+		if scanErr != nil {
+			return
+		}
+		rev, err := decodeRevision(val)
+		if err != nil {
+			scanErr = err
+			return
+		}
+		revisions = append(revisions, rev)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	return revisions, nil
+}
+
+// reviseMessage applies a change to a message after recording its current content
+func (ps *PromptStore) reviseMessage(messageID, action, changedBy string, apply func(*Message)) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	msg, err := ps.GetMessage(messageID)
+	if err != nil {
+		return err
+	}
+	if msg.Deleted {
+		return fmt.Errorf("message is deleted: %s", messageID)
+	}
+
+	userID := ""
+	if conv, err := ps.GetConversation(msg.ConversationID); err == nil {
+		userID = conv.UserID
+	}
+
+	previous, err := ps.revisionKeys(messageID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	rev := &MessageRevision{
+		MessageID: messageID,
+		Revision:  len(previous) + 1,
+		Action:    action,
+		Content:   msg.Content,
+		ChangedBy: changedBy,
+		ChangedAt: now,
+	}
+
+	tx := ps.kv.Begin()
+
+	oldWeights := messageTermWeights(msg)
+	apply(msg)
+	msg.EditedAt = now
+	ps.updateMessage(tx, msg)
+
+	var newWeights map[string]int64
+	if !msg.Deleted {
+		newWeights = messageTermWeights(msg)
+	}
+	messageIndex.Update(tx, messageEntity(userID, msg), oldWeights, newWeights)
+
+	tx.Set(revisionKey(messageID, rev.Revision), encodeRevision(rev))
+
+	return tx.Commit()
+}
+
+// revisionKeys returns the keys of every revision of a message
+func (ps *PromptStore) revisionKeys(messageID string) ([][]byte, error) {
+	var keys [][]byte
+	err := ps.scanRevisions(messageID, func(key, val []byte) {
+		keys = append(keys, append([]byte(nil), key...))
+	})
+	return keys, err
+}
+
+// scanRevisions calls fn for every revision of a message in revision order
+func (ps *PromptStore) scanRevisions(messageID string, fn func(key, val []byte)) error {
+	startKey := storage.EncodeKey(PREFIX_MESSAGE_REVISION, []storage.Value{
+		storage.NewBytesValue([]byte(messageID)),
+	})
+
+	return storage.ScanPrefix(ps.kv, startKey, func(key, val []byte) bool {
+		fn(key, val)
+		return true
+	})
+}
+
+// revisionKey builds the key of a message revision
+func revisionKey(messageID string, revision int) []byte {
+	return storage.EncodeKey(PREFIX_MESSAGE_REVISION, []storage.Value{
+		storage.NewBytesValue([]byte(messageID)),
+		storage.NewInt64Value(int64(revision)),
+	})
+}
@@ -0,0 +1,84 @@
+// ABOUTME: Tests for message editing and soft deletion
+// ABOUTME: Verifies revision history, tombstones and search index updates
+
+package prompt
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEditAndDeleteMessage(t *testing.T) {
+	ps, kv, path := setupTestPromptStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	if err := ps.CreateConversation(&Conversation{ConversationID: "conv1", UserID: "user1", StartedAt: now}); err != nil {
+		t.Fatalf("Failed to create conversation: %v", err)
+	}
+	if err := ps.AddMessage(&Message{MessageID: "m1", ConversationID: "conv1", Role: "user", Content: "knee surgery", Timestamp: now}); err != nil {
+		t.Fatalf("Failed to add message: %v", err)
+	}
+
+	if err := ps.EditMessage("m1", "hip surgery", "agent-7"); err != nil {
+		t.Fatalf("EditMessage failed: %v", err)
+	}
+
+	msg, err := ps.GetMessage("m1")
+	if err != nil {
+		t.Fatalf("GetMessage failed: %v", err)
+	}
+	if msg.Content != "hip surgery" || msg.EditedAt.IsZero() || msg.Deleted {
+		t.Errorf("Unexpected edited message: %+v", msg)
+	}
+
+	if results, _ := ps.SearchConversations("user1", "knee", 10); len(results) != 0 {
+		t.Errorf("Expected old content unindexed, got %d results", len(results))
+	}
+	if results, _ := ps.SearchConversations("user1", "hip", 10); len(results) != 1 {
+		t.Errorf("Expected new content indexed, got %d results", len(results))
+	}
+
+	if err := ps.DeleteMessage("m1", "agent-9"); err != nil {
+		t.Fatalf("DeleteMessage failed: %v", err)
+	}
+
+	messages, err := ps.GetMessages("conv1")
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(messages) != 1 || !messages[0].Deleted || messages[0].Content != "" {
+		t.Errorf("Expected tombstone in conversation, got %+v", messages)
+	}
+	if results, _ := ps.SearchConversations("user1", "hip", 10); len(results) != 0 {
+		t.Errorf("Expected deleted message unindexed, got %d results", len(results))
+	}
+
+	history, err := ps.GetMessageHistory("m1")
+	if err != nil {
+		t.Fatalf("GetMessageHistory failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 revisions, got %d", len(history))
+	}
+	if history[0].Action != RevisionEdit || history[0].Content != "knee surgery" || history[0].ChangedBy != "agent-7" {
+		t.Errorf("Unexpected first revision: %+v", history[0])
+	}
+	if history[1].Action != RevisionDelete || history[1].Content != "hip surgery" || history[1].Revision != 2 {
+		t.Errorf("Unexpected second revision: %+v", history[1])
+	}
+
+	if err := ps.EditMessage("m1", "again", "agent-7"); err == nil {
+		t.Error("Expected error editing deleted message")
+	}
+
+	// Deleting the conversation drops the history too
+	if err := ps.DeleteConversation("conv1"); err != nil {
+		t.Fatalf("DeleteConversation failed: %v", err)
+	}
+	if history, _ := ps.GetMessageHistory("m1"); len(history) != 0 {
+		t.Errorf("Expected history removed, got %d revisions", len(history))
+	}
+}
@@ -0,0 +1,119 @@
+// ABOUTME: Full-text search over message content using the shared term index
+// ABOUTME: Ranks conversations by how often their messages mention the query terms
+
+package prompt
+
+import (
+	"sort"
+
+	"github.com/nainya/treestore/pkg/storage"
+	"github.com/nainya/treestore/pkg/textindex"
+)
+
+// maxSearchMatches caps the matching messages returned per conversation
+const maxSearchMatches = 3
+
+// messageIndex holds message postings keyed by (userID, conversationID, messageID)
+var messageIndex = textindex.New(PREFIX_MESSAGE_TERM)
+
+// messageTermWeights counts the occurrences of every term in a message
+func messageTermWeights(msg *Message) map[string]int64 {
+	weights := make(map[string]int64)
+	for _, term := range textindex.Tokenize(msg.Content) {
+		weights[term]++
+	}
+	return weights
+}
+
+// messageEntity builds the posting entity key columns of a message
+func messageEntity(userID string, msg *Message) []storage.Value {
+	return []storage.Value{
+		storage.NewBytesValue([]byte(userID)),
+		storage.NewBytesValue([]byte(msg.ConversationID)),
+		storage.NewBytesValue([]byte(msg.MessageID)),
+	}
+}
+
+// SearchConversations finds conversations whose messages mention the query terms
+// An empty userID searches every user's conversations. Results are ordered by
+// score, highest first; limit <= 0 defaults to 20.
+func (ps *PromptStore) SearchConversations(userID, query string, limit int) ([]*ConversationSearchResult, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	var scope []storage.Value
+	if userID != "" {
+		scope = []storage.Value{storage.NewBytesValue([]byte(userID))}
+	}
+
+	convScores := make(map[string]float64)
+	msgScores := make(map[string]map[string]float64)
+	for _, term := range textindex.UniqueTerms(query) {
+		err := messageIndex.Postings(ps.kv, term, scope, func(entity []storage.Value, weight int64) bool {
+			if len(entity) < 3 {
+				return true
+			}
+			convID, msgID := string(entity[1].Str), string(entity[2].Str)
+			convScores[convID] += float64(weight)
+			if msgScores[convID] == nil {
+				msgScores[convID] = make(map[string]float64)
+			}
+			msgScores[convID][msgID] += float64(weight)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	convIDs := make([]string, 0, len(convScores))
+	for convID := range convScores {
+		convIDs = append(convIDs, convID)
+	}
+	sortByScore(convIDs, convScores)
+	if len(convIDs) > limit {
+		convIDs = convIDs[:limit]
+	}
+
+	results := make([]*ConversationSearchResult, 0, len(convIDs))
+	for _, convID := range convIDs {
+		conv, err := ps.GetConversation(convID)
+		if err != nil {
+			continue
+		}
+
+		msgIDs := make([]string, 0, len(msgScores[convID]))
+		for msgID := range msgScores[convID] {
+			msgIDs = append(msgIDs, msgID)
+		}
+		sortByScore(msgIDs, msgScores[convID])
+		if len(msgIDs) > maxSearchMatches {
+			msgIDs = msgIDs[:maxSearchMatches]
+		}
+
+		matches, err := ps.getMessagesBatch(msgIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, &ConversationSearchResult{
+			Conversation: conv,
+			Score:        convScores[convID],
+			Matches:      matches,
+		})
+	}
+
+	return results, nil
+}
+
+// sortByScore orders IDs by score, highest first, breaking ties by ID
+func sortByScore(ids []string, scores map[string]float64) {
+	sort.Slice(ids, func(i, j int) bool {
+		si, sj := scores[ids[i]], scores[ids[j]]
+		if si != sj {
+			return si > sj
+		}
+		return ids[i] < ids[j]
+	})
+}
@@ -0,0 +1,71 @@
+// ABOUTME: Tests for conversation full-text search
+// ABOUTME: Verifies ranking, user scoping and index cleanup on delete
+
+package prompt
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSearchConversations(t *testing.T) {
+	ps, kv, path := setupTestPromptStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	convs := []struct{ id, user string }{{"conv1", "alice"}, {"conv2", "alice"}, {"conv3", "bob"}}
+	for _, c := range convs {
+		if err := ps.CreateConversation(&Conversation{ConversationID: c.id, UserID: c.user, StartedAt: now, LastMessageAt: now}); err != nil {
+			t.Fatalf("Failed to create conversation: %v", err)
+		}
+	}
+
+	messages := []*Message{
+		{MessageID: "m1", ConversationID: "conv1", Role: "user", Content: "How do I appeal a prior authorization denial?", Timestamp: now},
+		{MessageID: "m2", ConversationID: "conv1", Role: "assistant", Content: "Appeal within 30 days; the appeal form is attached.", Timestamp: now.Add(time.Second)},
+		{MessageID: "m3", ConversationID: "conv2", Role: "user", Content: "What is the appeal deadline?", Timestamp: now},
+		{MessageID: "m4", ConversationID: "conv3", Role: "user", Content: "Appeal question from another user", Timestamp: now},
+	}
+	for _, msg := range messages {
+		if err := ps.AddMessage(msg); err != nil {
+			t.Fatalf("Failed to add message: %v", err)
+		}
+	}
+
+	results, err := ps.SearchConversations("alice", "Appeal", 10)
+	if err != nil {
+		t.Fatalf("SearchConversations failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results for alice, got %d", len(results))
+	}
+	if results[0].Conversation.ConversationID != "conv1" || results[0].Score != 3 {
+		t.Errorf("Expected conv1 with score 3 first, got %s with %v", results[0].Conversation.ConversationID, results[0].Score)
+	}
+	if len(results[0].Matches) != 2 || results[0].Matches[0].MessageID != "m2" {
+		t.Errorf("Expected m2 as best match of conv1, got %+v", results[0].Matches)
+	}
+
+	// Empty user searches everyone
+	results, err = ps.SearchConversations("", "appeal", 10)
+	if err != nil {
+		t.Fatalf("SearchConversations failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Errorf("Expected 3 results across users, got %d", len(results))
+	}
+
+	// Deleted conversations drop out of the index
+	if err := ps.DeleteConversation("conv3"); err != nil {
+		t.Fatalf("DeleteConversation failed: %v", err)
+	}
+	results, err = ps.SearchConversations("bob", "appeal", 10)
+	if err != nil {
+		t.Fatalf("SearchConversations failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results after delete, got %d", len(results))
+	}
+}
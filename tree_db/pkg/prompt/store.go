@@ -4,7 +4,10 @@
 package prompt
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/nainya/treestore/pkg/storage"
 )
@@ -17,39 +20,55 @@ const (
 	PREFIX_CONVERSATION_TIME   = uint32(8300) // Index by (startedAt, conversationID)
 	PREFIX_CONVERSATION_TAG    = uint32(8400) // Index by (tag, conversationID)
 	PREFIX_MESSAGE_CONV        = uint32(8500) // Index by (conversationID, timestamp, messageID)
+	PREFIX_MESSAGE_TERM        = uint32(8600) // Index by (term, userID, conversationID, messageID)
+	PREFIX_MESSAGE_REVISION    = uint32(8700) // History by (messageID, revision)
 )
 
+// ErrConversationNotFound is returned for a conversation that is not stored
+var ErrConversationNotFound = errors.New("conversation not found")
+
 // PromptStore manages conversations and messages
 type PromptStore struct {
-	kv *storage.KV
+	kv    storage.Engine
+	mu    *sync.Mutex // Serializes writes so usage counters and conversations cannot interleave; shared by views
+	quota Quota       // Applies to users without a quota of their own
 }
 
 // NewPromptStore creates a new prompt store
-func NewPromptStore(kv *storage.KV) *PromptStore {
-	return &PromptStore{kv: kv}
+func NewPromptStore(kv storage.Engine) *PromptStore {
+	return &PromptStore{kv: kv, mu: &sync.Mutex{}}
+}
+
+// WithContext returns a view of the store whose reads stop scanning once ctx
+// is done, failing with ctx.Err()
+func (ps *PromptStore) WithContext(ctx context.Context) *PromptStore {
+	return &PromptStore{kv: storage.WithContext(ctx, ps.kv), mu: ps.mu, quota: ps.quota}
 }
 
 // CreateConversation stores a new conversation
+// It fails with ErrQuotaExceeded when the user has their quota of conversations.
 func (ps *PromptStore) CreateConversation(conv *Conversation) error {
-	tx := ps.kv.Begin()
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
 
-	// Primary key: conversationID
-	key := storage.EncodeKey(PREFIX_CONVERSATION, []storage.Value{
-		storage.NewBytesValue([]byte(conv.ConversationID)),
-	})
+	tx := ps.kv.Begin()
+	defer tx.Abort()
 
-	val := storage.EncodeValues([]storage.Value{
-		storage.NewBytesValue([]byte(conv.ConversationID)),
-		storage.NewBytesValue([]byte(conv.UserID)),
-		storage.NewBytesValue([]byte(conv.Title)),
-		storage.NewTimeValue(conv.StartedAt),
-		storage.NewTimeValue(conv.LastMessageAt),
-		storage.NewInt64Value(int64(conv.MessageCount)),
-		storage.NewBytesValue(encodeStringArray(conv.Tags)),
-		storage.NewBytesValue(encodeMetadata(conv.Metadata)),
-	})
+	// Count the conversation against its user, unless it replaces one
+	usage, err := ps.loadUserUsage(tx, conv.UserID)
+	if err != nil {
+		return err
+	}
+	if _, exists := tx.Get(conversationKey(conv.ConversationID)); !exists {
+		if err := usage.checkConversationQuota(); err != nil {
+			return err
+		}
+		usage.Conversations++
+		tx.Set(userUsageKey(conv.UserID), encodeUserUsage(usage))
+	}
 
-	tx.Set(key, val)
+	// Primary key: conversationID
+	ps.updateConversation(tx, conv)
 
 	// User index: (userID, startedAt, conversationID)
 	userKey := storage.EncodeKey(PREFIX_CONVERSATION_USER, []storage.Value{
@@ -79,24 +98,29 @@ func (ps *PromptStore) CreateConversation(conv *Conversation) error {
 }
 
 // AddMessage appends a message to a conversation
+// It fails with ErrQuotaExceeded when the conversation's user or the
+// conversation itself has its quota of messages.
 func (ps *PromptStore) AddMessage(msg *Message) error {
-	tx := ps.kv.Begin()
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
 
-	// Primary key: messageID
-	key := storage.EncodeKey(PREFIX_MESSAGE, []storage.Value{
-		storage.NewBytesValue([]byte(msg.MessageID)),
-	})
+	tx := ps.kv.Begin()
+	defer tx.Abort()
 
-	val := storage.EncodeValues([]storage.Value{
-		storage.NewBytesValue([]byte(msg.MessageID)),
-		storage.NewBytesValue([]byte(msg.ConversationID)),
-		storage.NewBytesValue([]byte(msg.Role)),
-		storage.NewBytesValue([]byte(msg.Content)),
-		storage.NewTimeValue(msg.Timestamp),
-		storage.NewBytesValue(encodeMetadata(msg.Metadata)),
-	})
+	// Check the quotas before staging any write
+	conv, err := ps.GetConversation(msg.ConversationID)
+	var usage *UserUsage
+	if err == nil {
+		if usage, err = ps.loadUserUsage(tx, conv.UserID); err != nil {
+			return err
+		}
+		if err := usage.checkMessageQuota(conv); err != nil {
+			return err
+		}
+	}
 
-	tx.Set(key, val)
+	// Primary key: messageID
+	ps.updateMessage(tx, msg)
 
 	// Conversation message index: (conversationID, timestamp, messageID)
 	convKey := storage.EncodeKey(PREFIX_MESSAGE_CONV, []storage.Value{
@@ -107,13 +131,20 @@ func (ps *PromptStore) AddMessage(msg *Message) error {
 	tx.Set(convKey, []byte{})
 
 	// Update conversation's last message time and count
-	conv, err := ps.GetConversation(msg.ConversationID)
-	if err == nil {
+	userID := ""
+	if usage != nil {
+		usage.Messages++
+		tx.Set(userUsageKey(conv.UserID), encodeUserUsage(usage))
+
+		userID = conv.UserID
 		conv.LastMessageAt = msg.Timestamp
 		conv.MessageCount++
 		ps.updateConversation(tx, conv)
 	}
 
+	// Content term index, scoped by the conversation's user
+	messageIndex.Update(tx, messageEntity(userID, msg), nil, messageTermWeights(msg))
+
 	return tx.Commit()
 }
 
@@ -123,17 +154,15 @@ func (ps *PromptStore) GetConversation(conversationID string) (*Conversation, er
 		storage.NewBytesValue([]byte(conversationID)),
 	})
 
-	val, ok := ps.kv.Get(key)
-	if !ok {
-		return nil, fmt.Errorf("conversation not found: %s", conversationID)
-	}
-
-	vals, err := storage.DecodeValues(val)
+	val, ok, err := ps.kv.Lookup(key)
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
 
-	return parseConversationVals(vals)
+	return decodeConversation(val)
 }
 
 // GetMessage retrieves a message by ID
@@ -142,17 +171,15 @@ func (ps *PromptStore) GetMessage(messageID string) (*Message, error) {
 		storage.NewBytesValue([]byte(messageID)),
 	})
 
-	val, ok := ps.kv.Get(key)
-	if !ok {
-		return nil, fmt.Errorf("message not found: %s", messageID)
-	}
-
-	vals, err := storage.DecodeValues(val)
+	val, ok, err := ps.kv.Lookup(key)
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		return nil, fmt.Errorf("message not found: %s", messageID)
+	}
 
-	return parseMessageVals(vals)
+	return decodeMessage(val)
 }
 
 // GetMessages retrieves all messages for a conversation in chronological order
@@ -163,29 +190,126 @@ func (ps *PromptStore) GetMessages(conversationID string) ([]*Message, error) {
 
 	var messages []*Message
 
-	ps.kv.Scan(startKey, func(key, val []byte) bool {
+	var scanErr error
+	if err := storage.ScanPrefix(ps.kv, startKey, func(key, val []byte) bool {
 		vals, err := storage.ExtractValues(key)
 		if err != nil || len(vals) < 3 {
 			return true
 		}
 
-		// Check if still in same conversation
-		if string(vals[0].Str) != conversationID {
-			return false
-		}
-
 		messageID := string(vals[2].Str)
 		msg, err := ps.GetMessage(messageID)
+		if storage.IsCorruption(err) {
+			scanErr = err
+			return false
+		}
 		if err == nil {
 			messages = append(messages, msg)
 		}
 
 		return true
-	})
+	}); err != nil {
+		return nil, err
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
 
 	return messages, nil
 }
 
+// GetMessagesPage retrieves up to limit messages following the cursor, oldest first
+func (ps *PromptStore) GetMessagesPage(conversationID string, cursor MessageCursor, limit int) (*MessagePage, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	prefix := storage.EncodeKey(PREFIX_MESSAGE_CONV, []storage.Value{
+		storage.NewBytesValue([]byte(conversationID)),
+	})
+	startKey := prefix
+
+	if cursor.AfterMessageID != "" {
+		after, err := ps.GetMessage(cursor.AfterMessageID)
+		if err != nil {
+			return nil, err
+		}
+		if after.ConversationID != conversationID {
+			return nil, fmt.Errorf("message %s does not belong to conversation %s", cursor.AfterMessageID, conversationID)
+		}
+		startKey = storage.EncodeKeyPartial(PREFIX_MESSAGE_CONV, []storage.Value{
+			storage.NewBytesValue([]byte(conversationID)),
+			storage.NewTimeValue(after.Timestamp),
+			storage.NewBytesValue([]byte(after.MessageID)),
+		}, storage.CMP_GT)
+	} else if !cursor.AfterTimestamp.IsZero() {
+		startKey = storage.EncodeKeyPartial(PREFIX_MESSAGE_CONV, []storage.Value{
+			storage.NewBytesValue([]byte(conversationID)),
+			storage.NewTimeValue(cursor.AfterTimestamp),
+		}, storage.CMP_GT)
+	}
+
+	// Read one extra entry to learn whether another page follows
+	var messageIDs []string
+	if err := storage.ScanPrefixFrom(ps.kv, prefix, startKey, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 3 {
+			return true
+		}
+
+		messageIDs = append(messageIDs, string(vals[2].Str))
+		return len(messageIDs) <= limit
+	}); err != nil {
+		return nil, err
+	}
+
+	page := &MessagePage{}
+	if len(messageIDs) > limit {
+		page.HasMore = true
+		messageIDs = messageIDs[:limit]
+	}
+
+	messages, err := ps.getMessagesBatch(messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	page.Messages = messages
+
+	return page, nil
+}
+
+// GetRecentMessages retrieves the last n messages of a conversation, oldest first
+// The conversation index is walked backwards, so cost is independent of its length
+func (ps *PromptStore) GetRecentMessages(conversationID string, n int) ([]*Message, error) {
+	if n <= 0 {
+		return []*Message{}, nil
+	}
+
+	prefix := storage.EncodeKey(PREFIX_MESSAGE_CONV, []storage.Value{
+		storage.NewBytesValue([]byte(conversationID)),
+	})
+
+	messageIDs := make([]string, 0, n)
+	if err := storage.ScanPrefixReverse(ps.kv, prefix, nil, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 3 {
+			return true
+		}
+
+		messageIDs = append(messageIDs, string(vals[2].Str))
+		return len(messageIDs) < n
+	}); err != nil {
+		return nil, err
+	}
+
+	// Restore chronological order
+	for i, j := 0, len(messageIDs)-1; i < j; i, j = i+1, j-1 {
+		messageIDs[i], messageIDs[j] = messageIDs[j], messageIDs[i]
+	}
+
+	return ps.getMessagesBatch(messageIDs)
+}
+
 // GetConversationWithMessages retrieves a conversation with all its messages
 func (ps *PromptStore) GetConversationWithMessages(conversationID string) (*ConversationWithMessages, error) {
 	conv, err := ps.GetConversation(conversationID)
@@ -213,7 +337,8 @@ func (ps *PromptStore) ListConversationsByUser(userID string, limit int) ([]*Con
 	var conversations []*Conversation
 	count := 0
 
-	ps.kv.Scan(startKey, func(key, val []byte) bool {
+	var scanErr error
+	if err := storage.ScanPrefix(ps.kv, startKey, func(key, val []byte) bool {
 		if limit > 0 && count >= limit {
 			return false
 		}
@@ -223,20 +348,24 @@ func (ps *PromptStore) ListConversationsByUser(userID string, limit int) ([]*Con
 			return true
 		}
 
-		// Check if still in same user
-		if string(vals[0].Str) != userID {
-			return false
-		}
-
 		conversationID := string(vals[2].Str)
 		conv, err := ps.GetConversation(conversationID)
+		if storage.IsCorruption(err) {
+			scanErr = err
+			return false
+		}
 		if err == nil {
 			conversations = append(conversations, conv)
 			count++
 		}
 
 		return true
-	})
+	}); err != nil {
+		return nil, err
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
 
 	return conversations, nil
 }
@@ -250,7 +379,8 @@ func (ps *PromptStore) ListConversationsByTag(tag string, limit int) ([]*Convers
 	var conversations []*Conversation
 	count := 0
 
-	ps.kv.Scan(startKey, func(key, val []byte) bool {
+	var scanErr error
+	if err := storage.ScanPrefix(ps.kv, startKey, func(key, val []byte) bool {
 		if limit > 0 && count >= limit {
 			return false
 		}
@@ -260,26 +390,33 @@ func (ps *PromptStore) ListConversationsByTag(tag string, limit int) ([]*Convers
 			return true
 		}
 
-		// Check if still in same tag
-		if string(vals[0].Str) != tag {
-			return false
-		}
-
 		conversationID := string(vals[1].Str)
 		conv, err := ps.GetConversation(conversationID)
+		if storage.IsCorruption(err) {
+			scanErr = err
+			return false
+		}
 		if err == nil {
 			conversations = append(conversations, conv)
 			count++
 		}
 
 		return true
-	})
+	}); err != nil {
+		return nil, err
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
 
 	return conversations, nil
 }
 
 // DeleteConversation removes a conversation and all its messages
 func (ps *PromptStore) DeleteConversation(conversationID string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
 	// Get conversation first
 	conv, err := ps.GetConversation(conversationID)
 	if err != nil {
@@ -294,6 +431,16 @@ func (ps *PromptStore) DeleteConversation(conversationID string) error {
 
 	tx := ps.kv.Begin()
 
+	// Uncount the conversation and its messages
+	usage, err := ps.loadUserUsage(tx, conv.UserID)
+	if err != nil {
+		tx.Abort()
+		return err
+	}
+	usage.Conversations = max(usage.Conversations-1, 0)
+	usage.Messages = max(usage.Messages-int64(conv.MessageCount), 0)
+	tx.Set(userUsageKey(conv.UserID), encodeUserUsage(usage))
+
 	// Delete all messages
 	for _, msg := range messages {
 		msgKey := storage.EncodeKey(PREFIX_MESSAGE, []storage.Value{
@@ -307,6 +454,17 @@ func (ps *PromptStore) DeleteConversation(conversationID string) error {
 			storage.NewBytesValue([]byte(msg.MessageID)),
 		})
 		tx.Del(convMsgKey)
+
+		messageIndex.Update(tx, messageEntity(conv.UserID, msg), messageTermWeights(msg), nil)
+
+		revisions, err := ps.revisionKeys(msg.MessageID)
+		if err != nil {
+			tx.Abort()
+			return err
+		}
+		for _, key := range revisions {
+			tx.Del(key)
+		}
 	}
 
 	// Delete conversation
@@ -344,157 +502,48 @@ func (ps *PromptStore) DeleteConversation(conversationID string) error {
 
 // Helper functions
 
-func (ps *PromptStore) updateConversation(tx *storage.KVTX, conv *Conversation) {
-	key := storage.EncodeKey(PREFIX_CONVERSATION, []storage.Value{
-		storage.NewBytesValue([]byte(conv.ConversationID)),
-	})
-
-	val := storage.EncodeValues([]storage.Value{
-		storage.NewBytesValue([]byte(conv.ConversationID)),
-		storage.NewBytesValue([]byte(conv.UserID)),
-		storage.NewBytesValue([]byte(conv.Title)),
-		storage.NewTimeValue(conv.StartedAt),
-		storage.NewTimeValue(conv.LastMessageAt),
-		storage.NewInt64Value(int64(conv.MessageCount)),
-		storage.NewBytesValue(encodeStringArray(conv.Tags)),
-		storage.NewBytesValue(encodeMetadata(conv.Metadata)),
-	})
-
-	tx.Set(key, val)
-}
-
-func parseConversationVals(vals []storage.Value) (*Conversation, error) {
-	if len(vals) < 8 {
-		return nil, fmt.Errorf("incomplete conversation data")
-	}
-
-	tags, _ := decodeStringArray(vals[6].Str)
-	metadata, _ := decodeMetadata(vals[7].Str)
-
-	return &Conversation{
-		ConversationID: string(vals[0].Str),
-		UserID:         string(vals[1].Str),
-		Title:          string(vals[2].Str),
-		StartedAt:      vals[3].Time,
-		LastMessageAt:  vals[4].Time,
-		MessageCount:   int(vals[5].I64),
-		Tags:           tags,
-		Metadata:       metadata,
-	}, nil
-}
-
-func parseMessageVals(vals []storage.Value) (*Message, error) {
-	if len(vals) < 6 {
-		return nil, fmt.Errorf("incomplete message data")
-	}
-
-	metadata, _ := decodeMetadata(vals[5].Str)
-
-	return &Message{
-		MessageID:      string(vals[0].Str),
-		ConversationID: string(vals[1].Str),
-		Role:           string(vals[2].Str),
-		Content:        string(vals[3].Str),
-		Timestamp:      vals[4].Time,
-		Metadata:       metadata,
-	}, nil
-}
-
-func encodeStringArray(arr []string) []byte {
-	if len(arr) == 0 {
-		return []byte{}
-	}
-
-	result := []byte{byte(len(arr))}
-	for _, s := range arr {
-		result = append(result, byte(len(s)))
-		result = append(result, []byte(s)...)
+// getMessagesBatch loads messages by ID with one batched lookup, skipping missing ones
+func (ps *PromptStore) getMessagesBatch(messageIDs []string) ([]*Message, error) {
+	keys := make([][]byte, len(messageIDs))
+	for i, messageID := range messageIDs {
+		keys[i] = storage.EncodeKey(PREFIX_MESSAGE, []storage.Value{
+			storage.NewBytesValue([]byte(messageID)),
+		})
 	}
-	return result
-}
 
-func decodeStringArray(data []byte) ([]string, error) {
-	if len(data) == 0 {
-		return []string{}, nil
+	vals, found, err := ps.kv.LookupBatch(keys)
+	if err != nil {
+		return nil, err
 	}
 
-	pos := 0
-	count := int(data[pos])
-	pos++
-
-	result := make([]string, 0, count)
-	for i := 0; i < count; i++ {
-		if pos >= len(data) {
-			return nil, fmt.Errorf("incomplete string array")
+	messages := make([]*Message, 0, len(messageIDs))
+	for i := range messageIDs {
+		if !found[i] {
+			continue
 		}
 
-		length := int(data[pos])
-		pos++
-
-		if pos+length > len(data) {
-			return nil, fmt.Errorf("incomplete string at pos %d", pos)
+		msg, err := decodeMessage(vals[i])
+		if err != nil {
+			return nil, err
 		}
-
-		result = append(result, string(data[pos:pos+length]))
-		pos += length
+		messages = append(messages, msg)
 	}
 
-	return result, nil
+	return messages, nil
 }
 
-func encodeMetadata(m map[string]string) []byte {
-	if len(m) == 0 {
-		return []byte{}
-	}
+func (ps *PromptStore) updateConversation(tx storage.Txn, conv *Conversation) {
+	key := storage.EncodeKey(PREFIX_CONVERSATION, []storage.Value{
+		storage.NewBytesValue([]byte(conv.ConversationID)),
+	})
 
-	result := []byte{byte(len(m))}
-	for k, v := range m {
-		result = append(result, byte(len(k)))
-		result = append(result, []byte(k)...)
-		result = append(result, byte(len(v)))
-		result = append(result, []byte(v)...)
-	}
-	return result
+	tx.Set(key, encodeConversation(conv))
 }
 
-func decodeMetadata(data []byte) (map[string]string, error) {
-	if len(data) == 0 {
-		return make(map[string]string), nil
-	}
-
-	pos := 0
-	count := int(data[pos])
-	pos++
-
-	result := make(map[string]string)
-	for i := 0; i < count; i++ {
-		if pos >= len(data) {
-			return nil, fmt.Errorf("incomplete metadata")
-		}
-
-		// Read key
-		keyLen := int(data[pos])
-		pos++
-		if pos+keyLen > len(data) {
-			return nil, fmt.Errorf("incomplete key at pos %d", pos)
-		}
-		key := string(data[pos : pos+keyLen])
-		pos += keyLen
-
-		// Read value
-		if pos >= len(data) {
-			return nil, fmt.Errorf("incomplete value for key %s", key)
-		}
-		valLen := int(data[pos])
-		pos++
-		if pos+valLen > len(data) {
-			return nil, fmt.Errorf("incomplete value at pos %d", pos)
-		}
-		val := string(data[pos : pos+valLen])
-		pos += valLen
-
-		result[key] = val
-	}
+func (ps *PromptStore) updateMessage(tx storage.Txn, msg *Message) {
+	key := storage.EncodeKey(PREFIX_MESSAGE, []storage.Value{
+		storage.NewBytesValue([]byte(msg.MessageID)),
+	})
 
-	return result, nil
+	tx.Set(key, encodeMessage(msg))
 }
@@ -4,7 +4,9 @@
 package prompt
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,7 +14,7 @@ import (
 )
 
 func setupTestPromptStore(t *testing.T) (*PromptStore, *storage.KV, string) {
-	path := "/tmp/test_promptstore_" + t.Name() + ".db"
+	path := storage.MemoryPath
 	kv := &storage.KV{Path: path}
 	if err := kv.Open(); err != nil {
 		t.Fatalf("Failed to open: %v", err)
@@ -436,3 +438,157 @@ func TestConversationNotFound(t *testing.T) {
 		t.Error("Expected error for non-existent message")
 	}
 }
+
+func addTestMessages(t *testing.T, ps *PromptStore, convID string, n int, base time.Time) {
+	for i := 0; i < n; i++ {
+		msg := &Message{
+			MessageID:      fmt.Sprintf("%s-msg%03d", convID, i),
+			ConversationID: convID,
+			Role:           "user",
+			Content:        fmt.Sprintf("message %d", i),
+			Timestamp:      base.Add(time.Duration(i) * time.Second),
+		}
+		if err := ps.AddMessage(msg); err != nil {
+			t.Fatalf("Failed to add message: %v", err)
+		}
+	}
+}
+
+func TestGetMessagesPage(t *testing.T) {
+	ps, kv, path := setupTestPromptStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	base := time.Unix(1700000000, 0)
+	addTestMessages(t, ps, "conv1", 25, base)
+	addTestMessages(t, ps, "conv2", 3, base)
+
+	// Walk the conversation page by page using message cursors
+	var seen []string
+	cursor := MessageCursor{}
+	for pages := 0; ; pages++ {
+		page, err := ps.GetMessagesPage("conv1", cursor, 10)
+		if err != nil {
+			t.Fatalf("GetMessagesPage failed: %v", err)
+		}
+		for _, msg := range page.Messages {
+			seen = append(seen, msg.MessageID)
+		}
+		if !page.HasMore {
+			if len(page.Messages) != 5 {
+				t.Errorf("Expected 5 messages on last page, got %d", len(page.Messages))
+			}
+			break
+		}
+		if pages > 3 {
+			t.Fatal("Too many pages")
+		}
+		cursor = MessageCursor{AfterMessageID: page.Messages[len(page.Messages)-1].MessageID}
+	}
+
+	if len(seen) != 25 {
+		t.Fatalf("Expected 25 messages, got %d", len(seen))
+	}
+	for i, id := range seen {
+		if want := fmt.Sprintf("conv1-msg%03d", i); id != want {
+			t.Errorf("Position %d: expected %s, got %s", i, want, id)
+		}
+	}
+
+	// Timestamp cursor is exclusive
+	page, err := ps.GetMessagesPage("conv1", MessageCursor{AfterTimestamp: base.Add(20 * time.Second)}, 10)
+	if err != nil {
+		t.Fatalf("GetMessagesPage failed: %v", err)
+	}
+	if len(page.Messages) != 4 || page.Messages[0].MessageID != "conv1-msg021" {
+		t.Errorf("Expected msg021..msg024, got %d messages", len(page.Messages))
+	}
+
+	// Cursor from another conversation is rejected
+	if _, err := ps.GetMessagesPage("conv1", MessageCursor{AfterMessageID: "conv2-msg000"}, 10); err == nil {
+		t.Error("Expected error for foreign cursor")
+	}
+}
+
+func TestGetRecentMessages(t *testing.T) {
+	ps, kv, path := setupTestPromptStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	base := time.Unix(1700000000, 0)
+	addTestMessages(t, ps, "conv1", 20, base)
+	addTestMessages(t, ps, "conv2", 5, base)
+
+	recent, err := ps.GetRecentMessages("conv1", 3)
+	if err != nil {
+		t.Fatalf("GetRecentMessages failed: %v", err)
+	}
+
+	if len(recent) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(recent))
+	}
+	for i, want := range []string{"conv1-msg017", "conv1-msg018", "conv1-msg019"} {
+		if recent[i].MessageID != want {
+			t.Errorf("Position %d: expected %s, got %s", i, want, recent[i].MessageID)
+		}
+	}
+
+	// Asking for more than exist returns the whole conversation
+	recent, err = ps.GetRecentMessages("conv2", 50)
+	if err != nil {
+		t.Fatalf("GetRecentMessages failed: %v", err)
+	}
+	if len(recent) != 5 || recent[0].MessageID != "conv2-msg000" {
+		t.Errorf("Expected all 5 conv2 messages, got %d", len(recent))
+	}
+
+	recent, err = ps.GetRecentMessages("missing", 5)
+	if err != nil || len(recent) != 0 {
+		t.Errorf("Expected no messages for unknown conversation, got %d (%v)", len(recent), err)
+	}
+}
+
+func TestLongConversationTags(t *testing.T) {
+	ps, kv, _ := setupTestPromptStore(t)
+	defer kv.Close()
+
+	long := strings.Repeat("t", 300)
+	conv := &Conversation{ConversationID: "c1", UserID: "u1", StartedAt: time.Now(), Tags: []string{long}, Metadata: map[string]string{"case": long}}
+	if err := ps.CreateConversation(conv); err != nil {
+		t.Fatalf("CreateConversation failed: %v", err)
+	}
+	got, err := ps.GetConversation("c1")
+	if err != nil {
+		t.Fatalf("GetConversation failed: %v", err)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != long || got.Metadata["case"] != long {
+		t.Errorf("Expected a 300-byte tag and metadata value back, got %d tags and %d bytes", len(got.Tags), len(got.Metadata["case"]))
+	}
+}
+
+func TestLegacyMessageRecords(t *testing.T) {
+	ps, kv, _ := setupTestPromptStore(t)
+	defer kv.Close()
+
+	// A message as written before records and edit tracking: six values
+	key := storage.EncodeKey(PREFIX_MESSAGE, []storage.Value{storage.NewBytesValue([]byte("m1"))})
+	kv.Set(key, storage.EncodeValues([]storage.Value{
+		storage.NewBytesValue([]byte("m1")),
+		storage.NewBytesValue([]byte("c1")),
+		storage.NewBytesValue([]byte("user")),
+		storage.NewBytesValue([]byte("Is it covered?")),
+		storage.NewTimeValue(time.Unix(1700000000, 0)),
+		storage.NewBytesValue(nil),
+	}))
+
+	msg, err := ps.GetMessage("m1")
+	if err != nil {
+		t.Fatalf("GetMessage failed: %v", err)
+	}
+	if msg.ConversationID != "c1" || msg.Content != "Is it covered?" || msg.Timestamp.Unix() != 1700000000 || msg.Deleted {
+		t.Errorf("Expected the tuple read in full, got %+v", msg)
+	}
+	if err := CheckMessageRecord(storage.EncodeValues([]storage.Value{storage.NewBytesValue([]byte("m1"))})); err == nil {
+		t.Error("Expected a short tuple rejected")
+	}
+}
@@ -13,6 +13,24 @@ type Message struct {
 	Content       string            // Message content
 	Timestamp     time.Time         // Message timestamp
 	Metadata      map[string]string // Additional metadata
+	EditedAt      time.Time         // Last edit or deletion time (zero if never changed)
+	Deleted       bool              // Tombstoned; the original content lives in the revision history
+}
+
+// Message revision actions
+const (
+	RevisionEdit   = "edit"
+	RevisionDelete = "delete"
+)
+
+// MessageRevision records a message's content before an edit or deletion
+type MessageRevision struct {
+	MessageID string    // Revised message
+	Revision  int       // Sequence number, starting at 1
+	Action    string    // RevisionEdit or RevisionDelete
+	Content   string    // Content before the change
+	ChangedBy string    // Who made the change
+	ChangedAt time.Time // When the change was made
 }
 
 // Conversation represents a conversation thread
@@ -25,6 +43,7 @@ type Conversation struct {
 	MessageCount   int               // Total message count
 	Tags           []string          // Conversation tags
 	Metadata       map[string]string // Additional metadata
+	Archived       bool              // Hidden from listings unless requested
 }
 
 // ConversationWithMessages includes full message history
@@ -41,4 +60,87 @@ type ConversationQuery struct {
 	EndTime        *time.Time // Conversations started before
 	Limit          int       // Maximum results
 	IncludeMessages bool     // Include message history
+	IncludeArchived bool     // Include archived conversations
+}
+
+// MessageCursor marks where a page of messages starts
+// AfterMessageID takes precedence over AfterTimestamp; zero values start at the beginning
+type MessageCursor struct {
+	AfterMessageID string    // Last message already seen
+	AfterTimestamp time.Time // Only messages strictly after this time
+}
+
+// MessagePage is one page of a conversation's messages in chronological order
+type MessagePage struct {
+	Messages []*Message
+	HasMore  bool // More messages follow the last one in this page
+}
+
+// ConversationSearchResult is a conversation matching a content search
+type ConversationSearchResult struct {
+	Conversation *Conversation
+	Score        float64    // Sum of term occurrences across matching messages
+	Matches      []*Message // Best matching messages, highest score first
+}
+
+// OutcomeSuccess is the outcome label counted as a success when an experiment names none
+const OutcomeSuccess = "success"
+
+// Experiment splits traffic between prompt template variants
+type Experiment struct {
+	ExperimentID    string     // Unique experiment identifier
+	Name            string     // Experiment name
+	Variants        []*Variant // Variants in the order traffic buckets are assigned
+	SuccessOutcomes []string   // Outcome labels counted as successes; OutcomeSuccess if empty
+	CreatedAt       time.Time  // Experiment creation time
+}
+
+// Variant is one prompt template under test in an experiment
+type Variant struct {
+	Name     string // Unique within the experiment
+	PromptID string // Prompt template served to this variant
+	Weight   int    // Traffic buckets, out of the sum of the experiment's weights
+}
+
+// ExperimentUsage records one use of an experiment's variant and its outcome
+type ExperimentUsage struct {
+	UsageID      string    // Unique within the experiment
+	ExperimentID string    // Experiment the usage belongs to
+	Variant      string    // Variant served
+	PromptID     string    // The variant's prompt template when used
+	Outcome      string    // Outcome label; empty until known
+	UsedAt       time.Time // When the prompt was used
+}
+
+// VariantStats aggregates the usages of one variant
+type VariantStats struct {
+	Variant     string
+	PromptID    string
+	Usages      int            // Usages recorded
+	Labeled     int            // Usages with an outcome
+	Successes   int            // Usages with a success outcome
+	SuccessRate float64        // Successes over labeled usages; 0 with none labeled
+	Outcomes    map[string]int // Usages by outcome label
+}
+
+// ConversationPage is one page of a user's conversations, oldest first
+type ConversationPage struct {
+	Conversations []*Conversation
+	HasMore       bool // More conversations follow the last one in this page
+}
+
+// Quota caps what a user may store; a zero field is unlimited
+type Quota struct {
+	MaxConversations        int64 // Conversations the user may have at once
+	MaxMessages             int64 // Messages across all of the user's conversations
+	MaxConversationMessages int64 // Messages in any one conversation
+}
+
+// UserUsage counts a user's conversations and messages
+type UserUsage struct {
+	UserID        string
+	Conversations int64 // Conversations stored
+	Messages      int64 // Messages added to them, deleted ones included
+	Quota         Quota // The quota that applies: the user's own or the store default
+	CustomQuota   bool  // Quota was set for this user rather than defaulted
 }
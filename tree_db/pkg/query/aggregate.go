@@ -0,0 +1,370 @@
+// ABOUTME: Aggregation of query results into groups
+// ABOUTME: Counts, min/max/avg and date histograms keyed by result fields or entity metadata
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metadataFieldPrefix marks a field read from the row entity's metadata
+const metadataFieldPrefix = "metadata."
+
+// Label returns the key's name for ordering, e.g. "status" or "month(createdAt)"
+func (k GroupKey) Label() string {
+	if k.Interval == "" {
+		return k.Field
+	}
+	return fmt.Sprintf("%s(%s)", k.Interval, k.Field)
+}
+
+// Label returns the aggregation's key in Group.Values, e.g. "count" or "avg(metadata.pages)"
+func (a Aggregation) Label() string {
+	if a.Func == AggCount {
+		return string(AggCount)
+	}
+	return fmt.Sprintf("%s(%s)", a.Func, a.Field)
+}
+
+// readsMetadata reports whether a query groups or aggregates by entity metadata
+func readsMetadata(q Query) bool {
+	for _, k := range q.GroupBy {
+		if strings.HasPrefix(k.Field, metadataFieldPrefix) {
+			return true
+		}
+	}
+	for _, a := range q.Aggregations {
+		if strings.HasPrefix(a.Field, metadataFieldPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupJSON is the JSON form of a group; keys are tagged so histogram
+// buckets decode as times rather than strings
+type groupJSON struct {
+	Key    []groupKeyJSON
+	Count  int
+	Values map[string]float64
+}
+
+type groupKeyJSON struct {
+	Time  *time.Time  `json:",omitempty"`
+	Value interface{} `json:",omitempty"`
+}
+
+func (g *Group) MarshalJSON() ([]byte, error) {
+	out := groupJSON{Key: make([]groupKeyJSON, len(g.Key)), Count: g.Count, Values: g.Values}
+	for i, v := range g.Key {
+		if t, ok := v.(time.Time); ok {
+			out.Key[i].Time = &t
+		} else {
+			out.Key[i].Value = v
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (g *Group) UnmarshalJSON(data []byte) error {
+	var in groupJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	g.Key = make([]interface{}, len(in.Key))
+	for i, k := range in.Key {
+		if k.Time != nil {
+			g.Key[i] = *k.Time
+		} else {
+			g.Key[i] = k.Value
+		}
+	}
+	g.Count, g.Values = in.Count, in.Values
+	return nil
+}
+
+// validateAggregation rejects unknown functions and intervals
+func validateAggregation(q Query) error {
+	for _, k := range q.GroupBy {
+		if k.Field == "" {
+			return fmt.Errorf("group key needs a field")
+		}
+		switch k.Interval {
+		case "", IntervalHour, IntervalDay, IntervalWeek, IntervalMonth, IntervalYear:
+		default:
+			return fmt.Errorf("unknown histogram interval: %q", k.Interval)
+		}
+	}
+	for _, a := range q.Aggregations {
+		switch a.Func {
+		case AggCount:
+		case AggMin, AggMax, AggAvg:
+			if a.Field == "" {
+				return fmt.Errorf("%s needs a field", a.Func)
+			}
+		default:
+			return fmt.Errorf("unknown aggregate function: %q", a.Func)
+		}
+	}
+	return nil
+}
+
+// groupState accumulates one group
+type groupState struct {
+	group *Group
+	sums  map[string]float64
+	seen  map[string]int
+}
+
+// aggregate reads every row a query matches and returns its groups as rows,
+// ordered by OrderBy or else by key, before pagination
+func (e *Engine) aggregate(q Query) ([]Row, error) {
+	if err := validateAggregation(q); err != nil {
+		return nil, err
+	}
+
+	base := q
+	base.GroupBy, base.Aggregations = nil, nil
+	base.Limit, base.Offset, base.OrderBy = 0, 0, ""
+	cur, err := e.uncached().Cursor(base)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	states := make(map[string]*groupState)
+	var order []*groupState
+	for cur.Next() {
+		row := cur.Row()
+		field := e.aggregateField(row)
+
+		key := make([]interface{}, len(q.GroupBy))
+		for i, k := range q.GroupBy {
+			v, err := groupValue(field, k)
+			if err != nil {
+				return nil, err
+			}
+			key[i] = v
+		}
+
+		id := fmt.Sprintf("%#v", key)
+		st := states[id]
+		if st == nil {
+			st = &groupState{
+				group: &Group{Key: key, Values: make(map[string]float64)},
+				sums:  make(map[string]float64),
+				seen:  make(map[string]int),
+			}
+			states[id] = st
+			order = append(order, st)
+		}
+		st.group.Count++
+
+		for _, a := range q.Aggregations {
+			if a.Func == AggCount {
+				continue
+			}
+			n, ok := numericValue(field, a.Field)
+			if !ok {
+				continue
+			}
+			label := a.Label()
+			prev, had := st.group.Values[label]
+			switch {
+			case a.Func == AggAvg:
+				st.sums[label] += n
+			case !had, a.Func == AggMin && n < prev, a.Func == AggMax && n > prev:
+				st.group.Values[label] = n
+			}
+			st.seen[label]++
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	groups := make([]*Group, len(order))
+	for i, st := range order {
+		for _, a := range q.Aggregations {
+			label := a.Label()
+			switch {
+			case a.Func == AggCount:
+				st.group.Values[label] = float64(st.group.Count)
+			case a.Func == AggAvg && st.seen[label] > 0:
+				st.group.Values[label] = st.sums[label] / float64(st.seen[label])
+			}
+		}
+		groups[i] = st.group
+	}
+	// A query without group keys has one group, even over no rows
+	if len(q.GroupBy) == 0 && len(groups) == 0 {
+		g := &Group{Key: []interface{}{}, Values: make(map[string]float64)}
+		for _, a := range q.Aggregations {
+			if a.Func == AggCount {
+				g.Values[a.Label()] = 0
+			}
+		}
+		groups = append(groups, g)
+	}
+
+	if err := sortGroups(groups, q); err != nil {
+		return nil, err
+	}
+
+	rows := make([]Row, len(groups))
+	for i, g := range groups {
+		rows[i] = Row{Group: g}
+	}
+	return rows, nil
+}
+
+// aggregateField returns a field accessor for a row that also resolves
+// "metadata.<key>", loading the entity's metadata at most once
+func (e *Engine) aggregateField(row Row) func(field string) (interface{}, bool) {
+	var attrs map[string]string
+	loaded := false
+	return func(field string) (interface{}, bool) {
+		key, ok := strings.CutPrefix(field, metadataFieldPrefix)
+		if !ok {
+			return rowField(row, field)
+		}
+		if !loaded {
+			attrs, loaded = e.rowMetadata(row), true
+		}
+		if v, ok := attrs[key]; ok {
+			return v, true
+		}
+		return nil, true
+	}
+}
+
+// rowMetadata returns the metadata of a row's entity
+// Node metadata lives in the metadata store; versions and conversations carry theirs.
+func (e *Engine) rowMetadata(row Row) map[string]string {
+	switch {
+	case row.Document != nil:
+		attrs, err := e.metaStore.GetAllMetadata("node", row.Document.NodeID)
+		if err != nil {
+			return nil
+		}
+		return attrs
+	case row.Version != nil:
+		return row.Version.Metadata
+	case row.Conversation != nil:
+		return row.Conversation.Metadata
+	}
+	return nil
+}
+
+// groupValue returns a row's value for a group key
+func groupValue(field func(string) (interface{}, bool), k GroupKey) (interface{}, error) {
+	v, ok := field(k.Field)
+	if !ok {
+		return nil, fmt.Errorf("unknown group field: %s", k.Field)
+	}
+	if k.Interval == "" || v == nil {
+		return v, nil
+	}
+	t, ok := toTime(v)
+	if !ok {
+		return nil, fmt.Errorf("%s buckets need a time field, got %v", k.Interval, v)
+	}
+	return bucketStart(t, k.Interval), nil
+}
+
+// numericValue returns a row's field as a number, parsing metadata strings
+func numericValue(field func(string) (interface{}, bool), name string) (float64, bool) {
+	v, ok := field(name)
+	if !ok {
+		return 0, false
+	}
+	if s, ok := v.(string); ok {
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return n, err == nil
+	}
+	return toFloat(v)
+}
+
+// bucketStart returns the UTC start of the interval containing t
+func bucketStart(t time.Time, interval HistogramInterval) time.Time {
+	t = t.UTC()
+	switch interval {
+	case IntervalHour:
+		return t.Truncate(time.Hour)
+	case IntervalDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case IntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case IntervalYear:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t
+}
+
+// sortGroups orders groups by the query's OrderBy, which names a group key,
+// "count" or an aggregation, or else by key
+func sortGroups(groups []*Group, q Query) error {
+	value := groupOrderValue(q)
+	if q.OrderBy != "" && value == nil {
+		return fmt.Errorf("unknown order field for groups: %s", q.OrderBy)
+	}
+
+	var sortErr error
+	sort.SliceStable(groups, func(i, j int) bool {
+		if q.OrderBy != "" {
+			c, err := compareValues(value(groups[i]), value(groups[j]))
+			if err != nil {
+				sortErr = err
+			}
+			if c != 0 {
+				if q.Descending {
+					return c > 0
+				}
+				return c < 0
+			}
+		}
+		for k := range groups[i].Key {
+			c, err := compareValues(groups[i].Key[k], groups[j].Key[k])
+			if err != nil {
+				sortErr = err
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return sortErr
+}
+
+// groupOrderValue returns the accessor of the group key or aggregation named
+// by the query's OrderBy, or nil if there is none
+func groupOrderValue(q Query) func(g *Group) interface{} {
+	if q.OrderBy == string(AggCount) {
+		return func(g *Group) interface{} { return g.Count }
+	}
+	for i, k := range q.GroupBy {
+		if k.Label() == q.OrderBy {
+			return func(g *Group) interface{} { return g.Key[i] }
+		}
+	}
+	for _, a := range q.Aggregations {
+		if label := a.Label(); label == q.OrderBy {
+			return func(g *Group) interface{} {
+				if n, ok := g.Values[label]; ok {
+					return n
+				}
+				return nil
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// ABOUTME: Tests for aggregated queries
+// ABOUTME: Verifies metadata group-by, numeric aggregates, date histograms and group ordering
+
+package query
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/metadata"
+	"github.com/nainya/treestore/pkg/version"
+)
+
+func TestAggregateNodesByMetadata(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	rootID := "root"
+	nodes := []*document.Node{{NodeID: rootID, PolicyID: "LCD-1", Title: "Root"}}
+	attrs := map[string][3]string{ // department, status, pages
+		"a": {"cardiology", "active", "4"},
+		"b": {"cardiology", "active", "10"},
+		"c": {"cardiology", "retired", "n/a"},
+		"d": {"radiology", "active", "7"},
+	}
+	for id, a := range attrs {
+		nodes = append(nodes, &document.Node{NodeID: id, PolicyID: "LCD-1", ParentID: &rootID, Title: id, Depth: 1})
+		for i, key := range []string{"department", "status", "pages"} {
+			if err := engine.metaStore.SetMetadata(&metadata.MetadataEntry{EntityType: "node", EntityID: id, Key: key, Value: a[i]}); err != nil {
+				t.Fatalf("SetMetadata failed: %v", err)
+			}
+		}
+	}
+	if err := engine.docStore.StoreDocument(&document.Document{PolicyID: "LCD-1"}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	q, err := ParseQuery("SELECT count(*), avg(metadata.pages), max(metadata.pages) FROM nodes " +
+		"WHERE policyID = 'LCD-1' AND depth = 1 GROUP BY metadata.department, metadata.status")
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	result, err := engine.Execute(q)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	type row struct {
+		key    []interface{}
+		count  int
+		values map[string]float64
+	}
+	want := []row{
+		{[]interface{}{"cardiology", "active"}, 2, map[string]float64{"count": 2, "avg(metadata.pages)": 7, "max(metadata.pages)": 10}},
+		{[]interface{}{"cardiology", "retired"}, 1, map[string]float64{"count": 1}},
+		{[]interface{}{"radiology", "active"}, 1, map[string]float64{"count": 1, "avg(metadata.pages)": 7, "max(metadata.pages)": 7}},
+	}
+	if result.Total != len(want) || len(result.Groups) != len(want) {
+		t.Fatalf("Expected %d groups, got %d (total %d)", len(want), len(result.Groups), result.Total)
+	}
+	for i, g := range result.Groups {
+		if !reflect.DeepEqual(g.Key, want[i].key) || g.Count != want[i].count || !reflect.DeepEqual(g.Values, want[i].values) {
+			t.Errorf("Group %d = %+v, want %+v", i, *g, want[i])
+		}
+	}
+
+	// Groups order by an aggregate and paginate like rows
+	q.OrderBy, q.Descending, q.Limit = "count", true, 1
+	cur, err := engine.Cursor(q)
+	if err != nil {
+		t.Fatalf("Cursor failed: %v", err)
+	}
+	defer cur.Close()
+	var groups []*Group
+	for cur.Next() {
+		groups = append(groups, cur.Row().Group)
+	}
+	if cur.Err() != nil || len(groups) != 1 || groups[0].Count != 2 {
+		t.Errorf("Expected the largest group alone, got %v (%v)", groups, cur.Err())
+	}
+
+	// Without group keys a query has one group, even when nothing matches
+	total, err := engine.Execute(NewQueryBuilder(QueryDocument).Where("policyID", "LCD-9").Aggregate(AggCount, "").Build())
+	if err != nil || len(total.Groups) != 1 || total.Groups[0].Values["count"] != 0 {
+		t.Errorf("Expected a zero count, got %+v (%v)", total, err)
+	}
+}
+
+func TestAggregateVersionHistogram(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	for i, created := range []string{"2024-01-05", "2024-01-20", "2024-03-02"} {
+		at, _ := time.Parse("2006-01-02", created)
+		err := engine.verStore.CreateVersion(&version.Version{
+			PolicyID:  "LCD-1",
+			VersionID: "v" + string(rune('1'+i)),
+			CreatedAt: at,
+		})
+		if err != nil {
+			t.Fatalf("CreateVersion failed: %v", err)
+		}
+	}
+
+	q, err := ParseQuery(`{"select": ["count"], "from": "versions", "where": {"policyID": "LCD-1"}, "groupBy": ["month(createdAt)"]}`)
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	result, err := engine.Execute(q)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	if len(result.Groups) != 2 || result.Groups[0].Key[0] != jan || result.Groups[0].Count != 2 ||
+		result.Groups[1].Key[0] != mar || result.Groups[1].Count != 1 {
+		t.Fatalf("Unexpected histogram: %+v", result.Groups)
+	}
+
+	// Buckets survive materialization as times
+	data, err := json.Marshal(Row{Group: result.Groups[0]})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded Row
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Group.Key[0] != jan {
+		t.Errorf("Expected the bucket decoded as a time, got %+v (%v)", decoded.Group, err)
+	}
+
+	if got := bucketStart(time.Date(2024, 3, 7, 15, 0, 0, 0, time.UTC), IntervalWeek); !got.Equal(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected weeks to start on Monday, got %v", got)
+	}
+}
+
+func TestParseAggregationErrors(t *testing.T) {
+	for _, input := range []string{
+		"SELECT median(depth) FROM nodes WHERE policyID = 'X'",
+		"SELECT min FROM nodes WHERE policyID = 'X'",
+		"FROM nodes WHERE policyID = 'X' GROUP BY fortnight(createdAt)",
+		"SELECT count( FROM nodes",
+	} {
+		if _, err := ParseQuery(input); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
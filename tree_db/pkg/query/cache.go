@@ -0,0 +1,298 @@
+// ABOUTME: Optional cache of query results keyed by the normalized query
+// ABOUTME: Entries are dropped by change-feed events for the policy and store they read, or expire after a TTL
+
+package query
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+)
+
+// Result cache defaults
+const (
+	DefaultCacheEntries = 1024
+	DefaultCacheTTL     = time.Minute
+	DefaultCacheMaxRows = 1000
+)
+
+// CacheConfig configures the result cache
+type CacheConfig struct {
+	MaxEntries int           // Results kept before the least recently used is evicted (default DefaultCacheEntries)
+	TTL        time.Duration // Age at which a result is recomputed even without a change event (default DefaultCacheTTL)
+	MaxRows    int           // Cursors returning more rows are not cached (default DefaultCacheMaxRows)
+}
+
+// CacheStats counts result cache activity
+type CacheStats struct {
+	Hits          int64
+	Misses        int64
+	Invalidations int64 // Results dropped by change events
+	Evictions     int64 // Results dropped for space or age
+	Entries       int
+}
+
+// cacheDep is data a cached result was read from: an entity type of one
+// policy, or of every policy when policyID is empty
+type cacheDep struct {
+	entity   string
+	policyID string
+}
+
+// cacheEntry is one cached result: a Result from Execute or the rows of a cursor
+type cacheEntry struct {
+	key    string
+	deps   []cacheDep
+	stored time.Time
+	result *Result
+	rows   []Row
+}
+
+// resultCache is an LRU of query results shared by an engine and its views
+type resultCache struct {
+	cfg CacheConfig
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // Front is most recently used
+	deps    map[cacheDep]map[string]bool
+	gen     uint64 // Increased by every change event; results read across one are not stored
+
+	hits, misses, invalidations, evictions atomic.Int64
+}
+
+// EnableCache caches the results of Execute and Cursor until feed reports a
+// write to the store and policy a query reads, or cfg.TTL passes. Queries
+// over conversations publish no events and rely on the TTL alone, as do all
+// queries when feed is nil. Cached results are shared between callers, who
+// must not modify them. Call stop, or close feed, to stop invalidating.
+func (e *Engine) EnableCache(cfg CacheConfig, feed *changefeed.Feed) (stop func()) {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DefaultCacheEntries
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultCacheTTL
+	}
+	if cfg.MaxRows <= 0 {
+		cfg.MaxRows = DefaultCacheMaxRows
+	}
+
+	c := &resultCache{
+		cfg:     cfg,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		deps:    make(map[cacheDep]map[string]bool),
+	}
+	e.cache = c
+	return watchFeed(feed, c.invalidate, c.clear)
+}
+
+// CacheStats returns the result cache counters, all zero when it is disabled
+func (e *Engine) CacheStats() CacheStats {
+	c := e.cache
+	if c == nil {
+		return CacheStats{}
+	}
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+	return CacheStats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+		Evictions:     c.evictions.Load(),
+		Entries:       entries,
+	}
+}
+
+// uncached returns a view of the engine that bypasses the result cache
+func (e *Engine) uncached() *Engine {
+	if e.cache == nil {
+		return e
+	}
+	view := *e
+	view.cache = nil
+	return &view
+}
+
+// cursorRecord collects the rows a cursor returns so they can be cached
+type cursorRecord struct {
+	cache *resultCache
+	entry *cacheEntry
+	gen   uint64
+}
+
+// add records a row, giving up once the cursor returns too many to cache
+func (r *cursorRecord) add(row Row) *cursorRecord {
+	if r == nil || len(r.entry.rows) >= r.cache.cfg.MaxRows {
+		return nil
+	}
+	r.entry.rows = append(r.entry.rows, row)
+	return r
+}
+
+// store caches the recorded rows; a cursor calls it when exhausted
+func (r *cursorRecord) store() {
+	if r != nil {
+		r.cache.put(r.entry, r.gen)
+	}
+}
+
+// queryDeps returns what a query reads, as change feed entities and policies
+func queryDeps(q Query) []cacheDep {
+	var deps []cacheDep
+	policyID, _ := getStringFilter("policyID", q.Filters)
+	switch q.Type {
+	case QueryDocument:
+		deps = append(deps, cacheDep{changefeed.EntityDocument, policyID})
+	case QueryVersion:
+		deps = append(deps, cacheDep{changefeed.EntityVersion, policyID})
+	case QueryMetadata:
+		deps = append(deps, cacheDep{changefeed.EntityMetadata, ""})
+	}
+	if q.Type != QueryMetadata && readsMetadata(q) {
+		deps = append(deps, cacheDep{changefeed.EntityMetadata, ""})
+	}
+	return deps
+}
+
+// eventTouches reports whether a change event touches any of deps
+func eventTouches(deps []cacheDep, ev changefeed.Event) bool {
+	for _, dep := range deps {
+		if dep.entity == ev.Entity && (dep.policyID == "" || dep.policyID == ev.PolicyID) {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheKey normalizes a query into a cache key; kind separates Execute
+// results from cursor rows. Conditions are ANDed, so their order is dropped.
+func cacheKey(kind string, q Query) string {
+	conds := append([]Condition(nil), q.Conditions...)
+	sort.SliceStable(conds, func(i, j int) bool {
+		return fmt.Sprint(conds[i]) < fmt.Sprint(conds[j])
+	})
+	data, err := json.Marshal(struct {
+		Kind         string
+		Type         QueryType
+		Filters      map[string]interface{}
+		Conditions   []Condition
+		GroupBy      []GroupKey
+		Aggregations []Aggregation
+		Limit        int
+		Offset       int
+		OrderBy      string
+		Descending   bool
+	}{kind, q.Type, q.Filters, conds, q.GroupBy, q.Aggregations, q.Limit, q.Offset, q.OrderBy, q.Descending})
+	if err != nil {
+		// Unmarshalable values are never cached
+		return ""
+	}
+	return string(data)
+}
+
+// get returns the entry under key if it is present and fresh
+func (c *resultCache) get(key string) (*cacheEntry, bool) {
+	if key == "" {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if ok && time.Since(el.Value.(*cacheEntry).stored) > c.cfg.TTL {
+		c.remove(el)
+		c.evictions.Add(1)
+		ok = false
+	}
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	c.hits.Add(1)
+	return el.Value.(*cacheEntry), true
+}
+
+// generation returns the current change generation, to pass to put
+func (c *resultCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// put stores an entry computed from reads that began at generation gen,
+// unless a change event arrived since
+func (c *resultCache) put(entry *cacheEntry, gen uint64) {
+	if entry.key == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gen != gen {
+		return
+	}
+	if el, ok := c.entries[entry.key]; ok {
+		c.remove(el)
+	}
+	entry.stored = time.Now()
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	for _, dep := range entry.deps {
+		if c.deps[dep] == nil {
+			c.deps[dep] = make(map[string]bool)
+		}
+		c.deps[dep][entry.key] = true
+	}
+	for c.lru.Len() > c.cfg.MaxEntries {
+		c.remove(c.lru.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// invalidate drops the entries a change event touches
+func (c *resultCache) invalidate(ev changefeed.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	for _, dep := range []cacheDep{{ev.Entity, ev.PolicyID}, {ev.Entity, ""}} {
+		for key := range c.deps[dep] {
+			if el, ok := c.entries[key]; ok {
+				c.remove(el)
+				c.invalidations.Add(1)
+			}
+		}
+	}
+}
+
+// clear drops every entry, for when change events may have been missed
+func (c *resultCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.invalidations.Add(int64(len(c.entries)))
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.deps = make(map[cacheDep]map[string]bool)
+}
+
+// remove unlinks an entry; callers hold mu
+func (c *resultCache) remove(el *list.Element) {
+	entry := c.lru.Remove(el).(*cacheEntry)
+	delete(c.entries, entry.key)
+	for _, dep := range entry.deps {
+		delete(c.deps[dep], entry.key)
+		if len(c.deps[dep]) == 0 {
+			delete(c.deps, dep)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// ABOUTME: Tests for the query result cache
+// ABOUTME: Verifies hits, change-feed invalidation per policy, TTL expiry, LRU eviction and key normalization
+
+package query
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/document"
+)
+
+func storeCacheTestPolicy(t *testing.T, engine *Engine, policyID string) {
+	t.Helper()
+	rootID := "root"
+	nodes := []*document.Node{
+		{NodeID: rootID, PolicyID: policyID, Title: "Root"},
+		{NodeID: "a", PolicyID: policyID, ParentID: &rootID, Title: "A", Depth: 1},
+		{NodeID: "b", PolicyID: policyID, ParentID: &rootID, Title: "B", Depth: 1},
+	}
+	if err := engine.docStore.StoreDocument(&document.Document{PolicyID: policyID}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+}
+
+func TestQueryCache(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	storeCacheTestPolicy(t, engine, "LCD-1")
+	storeCacheTestPolicy(t, engine, "LCD-2")
+
+	feed := changefeed.NewFeed(0)
+	defer feed.Close()
+	engine.docStore.SetChangeFeed(feed)
+	sub := feed.Subscribe(nil)
+	defer sub.Close()
+
+	// Events are applied by hand below rather than by the watcher goroutine
+	engine.EnableCache(CacheConfig{}, nil)
+
+	children := func(policyID string) Query {
+		return NewQueryBuilder(QueryDocument).Where("policyID", policyID).Where("parentID", "root").Build()
+	}
+	titles := func(q Query) []string {
+		cur, err := engine.Cursor(q)
+		if err != nil {
+			t.Fatalf("Cursor failed: %v", err)
+		}
+		defer cur.Close()
+		var out []string
+		for cur.Next() {
+			out = append(out, cur.Row().Document.Title)
+		}
+		if err := cur.Err(); err != nil {
+			t.Fatalf("Cursor failed: %v", err)
+		}
+		return out
+	}
+
+	if got := titles(children("LCD-1")); len(got) != 2 {
+		t.Fatalf("Expected 2 children, got %v", got)
+	}
+	titles(children("LCD-2"))
+	if got := titles(children("LCD-1")); len(got) != 2 || got[0] != "A" {
+		t.Errorf("Expected the cached children, got %v", got)
+	}
+	if _, err := engine.Execute(children("LCD-1")); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	res, err := engine.Execute(children("LCD-1"))
+	if err != nil || len(res.Documents) != 2 {
+		t.Fatalf("Expected 2 cached nodes, got %+v (%v)", res, err)
+	}
+	stats := engine.CacheStats()
+	if stats.Hits != 2 || stats.Misses != 3 || stats.Entries != 3 {
+		t.Errorf("Expected 2 hits, 3 misses and 3 entries, got %+v", stats)
+	}
+
+	// A write to LCD-1 drops its results but keeps LCD-2's
+	node, err := engine.docStore.GetNode("LCD-1", "a")
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	node.Title = "Z"
+	if err := engine.docStore.UpdateNode(node); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	engine.cache.invalidate(<-sub.Events())
+
+	if got := titles(children("LCD-1")); len(got) != 2 || got[0] != "Z" {
+		t.Errorf("Expected the rename after invalidation, got %v", got)
+	}
+	titles(children("LCD-2"))
+	stats = engine.CacheStats()
+	if stats.Invalidations != 2 || stats.Hits != 3 {
+		t.Errorf("Expected 2 invalidations and a hit for LCD-2, got %+v", stats)
+	}
+
+	// Results read across a change event are not stored
+	gen := engine.cache.generation()
+	engine.cache.invalidate(changefeed.Event{Entity: changefeed.EntityVersion, PolicyID: "LCD-9"})
+	engine.cache.put(&cacheEntry{key: "late"}, gen)
+	if _, ok := engine.cache.get("late"); ok {
+		t.Error("Expected a result read before an event discarded")
+	}
+
+	// A cursor closed early is not cached
+	q := children("LCD-1")
+	q.Limit = 1
+	cur, err := engine.Cursor(q)
+	if err != nil {
+		t.Fatalf("Cursor failed: %v", err)
+	}
+	cur.Next()
+	cur.Close()
+	if _, ok := engine.cache.get(cacheKey("cursor", q)); ok {
+		t.Error("Expected an unfinished cursor left out of the cache")
+	}
+}
+
+func TestQueryCacheLimits(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	engine.EnableCache(CacheConfig{MaxEntries: 2, TTL: time.Hour, MaxRows: 1}, nil)
+	c := engine.cache
+
+	c.put(&cacheEntry{key: "a"}, c.generation())
+	c.put(&cacheEntry{key: "b"}, c.generation())
+	c.get("a")
+	c.put(&cacheEntry{key: "c"}, c.generation())
+	if _, ok := c.get("b"); ok {
+		t.Error("Expected the least recently used entry evicted")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Error("Expected the recently read entry kept")
+	}
+
+	c.lru.Front().Value.(*cacheEntry).stored = time.Now().Add(-2 * time.Hour)
+	if _, ok := c.get("a"); ok {
+		t.Error("Expected an entry past its TTL to expire")
+	}
+	if stats := engine.CacheStats(); stats.Evictions != 2 || stats.Entries != 1 {
+		t.Errorf("Expected 2 evictions and 1 entry, got %+v", stats)
+	}
+
+	// Cursors returning more than MaxRows are not cached
+	storeCacheTestPolicy(t, engine, "LCD-1")
+	q := NewQueryBuilder(QueryDocument).Where("policyID", "LCD-1").Where("parentID", "root").Build()
+	hits := engine.CacheStats().Hits
+	for i := 0; i < 2; i++ {
+		cur, err := engine.Cursor(q)
+		if err != nil {
+			t.Fatalf("Cursor failed: %v", err)
+		}
+		for cur.Next() {
+		}
+		cur.Close()
+	}
+	if stats := engine.CacheStats(); stats.Hits != hits {
+		t.Errorf("Expected an oversized cursor uncached, got %+v", stats)
+	}
+}
+
+func TestCacheKeyNormalization(t *testing.T) {
+	a := NewQueryBuilder(QueryDocument).Where("policyID", "LCD-1").
+		Compare("depth", OpGe, 1).Compare("title", OpEq, "A").Build()
+	b := NewQueryBuilder(QueryDocument).Where("policyID", "LCD-1").
+		Compare("title", OpEq, "A").Compare("depth", OpGe, 1).Build()
+	if cacheKey("execute", a) != cacheKey("execute", b) {
+		t.Error("Expected condition order ignored")
+	}
+	if cacheKey("execute", a) == cacheKey("cursor", a) {
+		t.Error("Expected Execute and cursor results kept apart")
+	}
+	b.Limit = 5
+	if cacheKey("execute", a) == cacheKey("execute", b) {
+		t.Error("Expected the limit in the key")
+	}
+
+	deps := queryDeps(NewQueryBuilder(QueryVersion).Where("policyID", "LCD-1").GroupBy("metadata.status").Build())
+	for _, tc := range []struct {
+		ev   changefeed.Event
+		want bool
+	}{
+		{changefeed.Event{Entity: changefeed.EntityVersion, PolicyID: "LCD-1"}, true},
+		{changefeed.Event{Entity: changefeed.EntityVersion, PolicyID: "LCD-2"}, false},
+		{changefeed.Event{Entity: changefeed.EntityMetadata}, true},
+		{changefeed.Event{Entity: changefeed.EntityDocument, PolicyID: "LCD-1"}, false},
+	} {
+		if got := eventTouches(deps, tc.ev); got != tc.want {
+			t.Errorf("eventTouches(%+v) = %v, want %v", tc.ev, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+// ABOUTME: Condition evaluation and ordering of query results
+// ABOUTME: Maps query field names to entity attributes for filtering and sorting
+
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/metadata"
+	"github.com/nainya/treestore/pkg/prompt"
+	"github.com/nainya/treestore/pkg/version"
+)
+
+// fieldFunc returns the value of a named field of a result
+type fieldFunc[T any] func(item T, field string) (interface{}, bool)
+
+// refine applies a query's conditions and ordering to store results
+func refine[T any](items []T, q Query, field fieldFunc[T]) ([]T, error) {
+	if len(q.Conditions) > 0 {
+		kept := make([]T, 0, len(items))
+		for _, item := range items {
+			ok, err := matchesConditions(item, q.Conditions, field)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				kept = append(kept, item)
+			}
+		}
+		items = kept
+	}
+
+	if q.OrderBy == "" || len(items) == 0 {
+		return items, nil
+	}
+	if _, ok := field(items[0], q.OrderBy); !ok {
+		return nil, fmt.Errorf("unknown order field: %s", q.OrderBy)
+	}
+
+	var sortErr error
+	sort.SliceStable(items, func(i, j int) bool {
+		a, _ := field(items[i], q.OrderBy)
+		b, _ := field(items[j], q.OrderBy)
+		c, err := compareValues(a, b)
+		if err != nil {
+			sortErr = err
+		}
+		if q.Descending {
+			return c > 0
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	return items, nil
+}
+
+// matchesConditions reports whether an item satisfies every condition
+func matchesConditions[T any](item T, conds []Condition, field fieldFunc[T]) (bool, error) {
+	for _, cond := range conds {
+		actual, ok := field(item, cond.Field)
+		if !ok {
+			return false, fmt.Errorf("unknown field: %s", cond.Field)
+		}
+
+		if actual == nil || cond.Value == nil {
+			equal := actual == nil && cond.Value == nil
+			switch cond.Op {
+			case OpEq:
+				if !equal {
+					return false, nil
+				}
+			case OpNe:
+				if equal {
+					return false, nil
+				}
+			default:
+				return false, fmt.Errorf("operator %s not supported for null on %s", cond.Op, cond.Field)
+			}
+			continue
+		}
+
+		c, err := compareValues(actual, cond.Value)
+		if err != nil {
+			return false, fmt.Errorf("field %s: %v", cond.Field, err)
+		}
+
+		var pass bool
+		switch cond.Op {
+		case OpEq:
+			pass = c == 0
+		case OpNe:
+			pass = c != 0
+		case OpLt:
+			pass = c < 0
+		case OpLe:
+			pass = c <= 0
+		case OpGt:
+			pass = c > 0
+		case OpGe:
+			pass = c >= 0
+		default:
+			return false, fmt.Errorf("unknown operator: %s", cond.Op)
+		}
+		if !pass {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// compareValues orders a field value against a literal, returning -1, 0 or 1
+// Numbers compare numerically and times accept RFC 3339 or YYYY-MM-DD strings.
+// Nil sorts before every other value.
+func compareValues(a, b interface{}) (int, error) {
+	if a == nil || b == nil {
+		switch {
+		case a == b:
+			return 0, nil
+		case a == nil:
+			return -1, nil
+		}
+		return 1, nil
+	}
+
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare string with %v", b)
+		}
+		return strings.Compare(av, bv), nil
+
+	case int, float64:
+		af, _ := toFloat(av)
+		bf, ok := toFloat(b)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare number with %v", b)
+		}
+		switch {
+		case af < bf:
+			return -1, nil
+		case af > bf:
+			return 1, nil
+		}
+		return 0, nil
+
+	case bool:
+		bv, ok := b.(bool)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare bool with %v", b)
+		}
+		switch {
+		case av == bv:
+			return 0, nil
+		case !av:
+			return -1, nil
+		}
+		return 1, nil
+
+	case time.Time:
+		bt, ok := toTime(b)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare time with %v", b)
+		}
+		return av.Compare(bt), nil
+	}
+
+	return 0, fmt.Errorf("unsupported value %v", a)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		if parsed, err := time.Parse(time.RFC3339, t); err == nil {
+			return parsed, true
+		}
+		if parsed, err := time.Parse("2006-01-02", t); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Field accessors by entity, keyed by the names used in queries
+
+func nodeField(n *document.Node, field string) (interface{}, bool) {
+	switch field {
+	case "nodeID":
+		return n.NodeID, true
+	case "policyID":
+		return n.PolicyID, true
+	case "parentID":
+		if n.ParentID == nil {
+			return nil, true
+		}
+		return *n.ParentID, true
+	case "title":
+		return n.Title, true
+	case "summary":
+		return n.Summary, true
+	case "text":
+		return n.Text, true
+	case "sectionPath":
+		return n.SectionPath, true
+	case "pageStart":
+		return n.PageStart, true
+	case "pageEnd":
+		return n.PageEnd, true
+	case "depth":
+		return n.Depth, true
+	case "createdAt":
+		return n.CreatedAt, true
+	case "updatedAt":
+		return n.UpdatedAt, true
+	}
+	return nil, false
+}
+
+func versionField(v *version.Version, field string) (interface{}, bool) {
+	switch field {
+	case "policyID":
+		return v.PolicyID, true
+	case "versionID":
+		return v.VersionID, true
+	case "documentID":
+		return v.DocumentID, true
+	case "createdAt":
+		return v.CreatedAt, true
+	case "createdBy":
+		return v.CreatedBy, true
+	case "description":
+		return v.Description, true
+	case "effectiveFrom":
+		return v.EffectiveFrom, true
+	case "effectiveTo":
+		if v.EffectiveTo.IsZero() {
+			return nil, true
+		}
+		return v.EffectiveTo, true
+	}
+	return nil, false
+}
+
+func metadataField(m *metadata.MetadataEntry, field string) (interface{}, bool) {
+	switch field {
+	case "entityType":
+		return m.EntityType, true
+	case "entityID":
+		return m.EntityID, true
+	case "key":
+		return m.Key, true
+	case "value":
+		return m.Value, true
+	case "valueType":
+		return m.ValueType, true
+	case "createdAt":
+		return m.CreatedAt, true
+	case "updatedAt":
+		return m.UpdatedAt, true
+	}
+	return nil, false
+}
+
+func conversationField(c *prompt.Conversation, field string) (interface{}, bool) {
+	switch field {
+	case "conversationID":
+		return c.ConversationID, true
+	case "userID":
+		return c.UserID, true
+	case "title":
+		return c.Title, true
+	case "startedAt":
+		return c.StartedAt, true
+	case "lastMessageAt":
+		return c.LastMessageAt, true
+	case "messageCount":
+		return c.MessageCount, true
+	case "archived":
+		return c.Archived, true
+	}
+	return nil, false
+}
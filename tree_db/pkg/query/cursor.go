@@ -0,0 +1,403 @@
+// ABOUTME: Lazy cursor over query results
+// ABOUTME: Pulls index entries in small batches so large queries stay memory-bounded
+
+package query
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/metadata"
+	"github.com/nainya/treestore/pkg/prompt"
+	"github.com/nainya/treestore/pkg/storage"
+	"github.com/nainya/treestore/pkg/version"
+)
+
+// cursorBatchSize is the number of index entries read per underlying scan
+const cursorBatchSize = 256
+
+// Row is one query result; exactly one field is set, matching the query
+// type, or Group for aggregated queries
+type Row struct {
+	Document     *document.Node
+	Version      *version.Version
+	Metadata     *metadata.MetadataEntry
+	Conversation *prompt.Conversation
+	Group        *Group
+}
+
+// ResultCursor iterates the results of a query without materializing them
+//
+//	cur, err := engine.Cursor(q)
+//	...
+//	defer cur.Close()
+//	for cur.Next() {
+//		row := cur.Row()
+//	}
+//	if err := cur.Err(); err != nil { ... }
+//
+// Index entries are read in batches between calls to Next, so writes made
+// while iterating may or may not be observed.
+type ResultCursor struct {
+	source func() (Row, bool, error) // Next candidate row before conditions
+	query  Query
+
+	skip      int // Matching rows still to skip for the offset
+	remaining int // Rows still to return (-1 for unlimited)
+
+	row    Row
+	err    error
+	closed bool
+
+	record *cursorRecord // Rows to cache once the cursor is exhausted, if any
+}
+
+// Cursor opens a lazy cursor over a query's results
+// Unlike Execute, a document query with only policyID iterates every node of
+// the policy. Ordering requires reading every match before the first row, as
+// does aggregation, whose rows are groups.
+func (e *Engine) Cursor(q Query) (*ResultCursor, error) {
+	if e.cache == nil {
+		return e.cursor(q)
+	}
+
+	key := cacheKey("cursor", q)
+	if entry, ok := e.cache.get(key); ok {
+		return &ResultCursor{source: sliceSource(entry.rows), remaining: -1}, nil
+	}
+	gen := e.cache.generation()
+	cur, err := e.cursor(q)
+	if err != nil {
+		return nil, err
+	}
+	cur.record = &cursorRecord{cache: e.cache, entry: &cacheEntry{key: key, deps: queryDeps(q)}, gen: gen}
+	return cur, nil
+}
+
+// cursor opens a cursor that reads the stores
+func (e *Engine) cursor(q Query) (*ResultCursor, error) {
+	if q.Aggregated() {
+		groups, err := e.aggregate(q)
+		if err != nil {
+			return nil, err
+		}
+		cur := &ResultCursor{source: sliceSource(groups), skip: q.Offset, remaining: -1}
+		if q.Limit > 0 {
+			cur.remaining = q.Limit
+		}
+		return cur, nil
+	}
+
+	source, err := e.rowSource(q)
+	if err != nil {
+		return nil, err
+	}
+
+	cur := &ResultCursor{source: source, query: q, skip: q.Offset, remaining: -1}
+	if q.Limit > 0 {
+		cur.remaining = q.Limit
+	}
+
+	if q.OrderBy != "" {
+		if err := cur.sortAll(); err != nil {
+			return nil, err
+		}
+	}
+
+	return cur, nil
+}
+
+// Next advances to the next row, returning false when results are exhausted or on error
+func (c *ResultCursor) Next() bool {
+	if c.closed || c.err != nil {
+		return false
+	}
+	if c.remaining == 0 {
+		c.record.store()
+		c.record = nil
+		return false
+	}
+
+	for {
+		row, ok, err := c.source()
+		if err != nil {
+			c.err = err
+			return false
+		}
+		if !ok {
+			c.record.store()
+			c.record = nil
+			return false
+		}
+
+		match, err := matchesConditions(row, c.query.Conditions, rowField)
+		if err != nil {
+			c.err = err
+			return false
+		}
+		if !match {
+			continue
+		}
+		if c.skip > 0 {
+			c.skip--
+			continue
+		}
+
+		c.row = row
+		c.record = c.record.add(row)
+		if c.remaining > 0 {
+			c.remaining--
+		}
+		return true
+	}
+}
+
+// Row returns the current row
+func (c *ResultCursor) Row() Row {
+	return c.row
+}
+
+// Err returns the error that stopped iteration, if any
+func (c *ResultCursor) Err() error {
+	return c.err
+}
+
+// Close releases the cursor; Next returns false afterwards
+func (c *ResultCursor) Close() {
+	c.closed = true
+	c.source = nil
+	c.record = nil
+}
+
+// sortAll replaces the source with every matching row in query order
+func (c *ResultCursor) sortAll() error {
+	var rows []Row
+	for {
+		row, ok, err := c.source()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		match, err := matchesConditions(row, c.query.Conditions, rowField)
+		if err != nil {
+			return err
+		}
+		if match {
+			rows = append(rows, row)
+		}
+	}
+
+	sorted, err := refine(rows, Query{OrderBy: c.query.OrderBy, Descending: c.query.Descending}, rowField)
+	if err != nil {
+		return err
+	}
+
+	c.query.Conditions = nil
+	c.source = sliceSource(sorted)
+	return nil
+}
+
+// rowSource builds the unfiltered row stream for a query
+func (e *Engine) rowSource(q Query) (func() (Row, bool, error), error) {
+	switch q.Type {
+	case QueryDocument:
+		policyID, ok := getStringFilter("policyID", q.Filters)
+		if !ok {
+			return nil, fmt.Errorf("policyID required for document query")
+		}
+
+		if nodeID, ok := getStringFilter("nodeID", q.Filters); ok {
+			node, err := e.docStore.GetNode(policyID, nodeID)
+			if err != nil {
+				return nil, err
+			}
+			return sliceSource([]Row{{Document: node}}), nil
+		}
+
+		prefix, scope, idColumn := document.PREFIX_NODE, []string{policyID}, 1
+		if parentID, ok := q.Filters["parentID"]; ok {
+			pid, _ := parentID.(string)
+			prefix, scope, idColumn = document.PREFIX_CHILDREN, []string{policyID, pid}, 2
+		}
+		return e.indexSource(prefix, scope, func(vals []storage.Value) (Row, error) {
+			node, err := e.docStore.GetNode(policyID, string(vals[idColumn].Str))
+			return Row{Document: node}, err
+		}), nil
+
+	case QueryVersion:
+		policyID, ok := getStringFilter("policyID", q.Filters)
+		if !ok {
+			return nil, fmt.Errorf("policyID required for version query")
+		}
+
+		var ver *version.Version
+		var err error
+		if versionID, ok := getStringFilter("versionID", q.Filters); ok {
+			ver, err = e.verStore.GetVersion(policyID, versionID)
+		} else if tag, ok := getStringFilter("tag", q.Filters); ok {
+			ver, err = e.verStore.GetVersionByTag(policyID, tag)
+		} else {
+			return e.indexSource(version.PREFIX_VERSION_TIME, []string{policyID}, func(vals []storage.Value) (Row, error) {
+				v, err := e.verStore.GetVersion(policyID, string(vals[2].Str))
+				return Row{Version: v}, err
+			}), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return sliceSource([]Row{{Version: ver}}), nil
+
+	case QueryMetadata:
+		key, ok := getStringFilter("key", q.Filters)
+		if !ok {
+			return nil, fmt.Errorf("key required for metadata query")
+		}
+
+		prefix, scope, typeColumn := metadata.PREFIX_METADATA_KEY, []string{key}, 1
+		if value, ok := getStringFilter("value", q.Filters); ok {
+			prefix, scope, typeColumn = metadata.PREFIX_METADATA_VALUE, []string{key, value}, 2
+		}
+		if entityType, ok := getStringFilter("entityType", q.Filters); ok {
+			scope = append(scope, entityType)
+		}
+		return e.indexSource(prefix, scope, func(vals []storage.Value) (Row, error) {
+			entry, err := e.metaStore.GetMetadata(string(vals[typeColumn].Str), string(vals[typeColumn+1].Str), key)
+			return Row{Metadata: entry}, err
+		}), nil
+
+	case QueryPrompt:
+		prefix, scope, idColumn := uint32(0), []string(nil), 0
+		if userID, ok := getStringFilter("userID", q.Filters); ok {
+			prefix, scope, idColumn = prompt.PREFIX_CONVERSATION_USER, []string{userID}, 2
+		} else if tag, ok := getStringFilter("tag", q.Filters); ok {
+			prefix, scope, idColumn = prompt.PREFIX_CONVERSATION_TAG, []string{tag}, 1
+		} else {
+			return nil, fmt.Errorf("userID or tag required for prompt query")
+		}
+		return e.indexSource(prefix, scope, func(vals []storage.Value) (Row, error) {
+			conv, err := e.promptStore.GetConversation(string(vals[idColumn].Str))
+			return Row{Conversation: conv}, err
+		}), nil
+	}
+
+	return nil, fmt.Errorf("unsupported query type: %d", q.Type)
+}
+
+// indexSource streams rows for the index entries whose leading columns equal scope
+// Entries whose entity can no longer be loaded are skipped; corrupt storage is returned.
+func (e *Engine) indexSource(prefix uint32, scope []string, load func(vals []storage.Value) (Row, error)) func() (Row, bool, error) {
+	scan := &indexScan{kv: e.kv, prefix: prefix, scope: scope}
+	return func() (Row, bool, error) {
+		for {
+			vals, ok, err := scan.next()
+			if err != nil || !ok {
+				return Row{}, false, err
+			}
+			row, err := load(vals)
+			if err == nil {
+				return row, true, nil
+			}
+			if storage.IsCorruption(err) {
+				return Row{}, false, err
+			}
+		}
+	}
+}
+
+// sliceSource streams rows that are already loaded
+func sliceSource(rows []Row) func() (Row, bool, error) {
+	return func() (Row, bool, error) {
+		if len(rows) == 0 {
+			return Row{}, false, nil
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return row, true, nil
+	}
+}
+
+// indexScan reads an index range in batches, resuming after the last key read
+type indexScan struct {
+	kv     storage.Engine
+	prefix uint32
+	scope  []string
+
+	resume []byte            // Last key read, nil before the first batch
+	buf    [][]storage.Value // Decoded key columns not yet returned
+	done   bool
+}
+
+// next returns the key columns of the next index entry
+func (s *indexScan) next() ([]storage.Value, bool, error) {
+	if len(s.buf) == 0 && !s.done {
+		if err := s.refill(); err != nil {
+			return nil, false, err
+		}
+	}
+	if len(s.buf) == 0 {
+		return nil, false, nil
+	}
+
+	vals := s.buf[0]
+	s.buf = s.buf[1:]
+	return vals, true, nil
+}
+
+// refill reads the next batch of entries
+func (s *indexScan) refill() error {
+	scopeVals := make([]storage.Value, len(s.scope))
+	for i, v := range s.scope {
+		scopeVals[i] = storage.NewBytesValue([]byte(v))
+	}
+	prefix := storage.EncodeKey(s.prefix, scopeVals)
+	start := s.resume
+	if start == nil {
+		start = prefix
+	}
+
+	full := false
+	err := storage.ScanPrefixFrom(s.kv, prefix, start, func(key, val []byte) bool {
+		if s.resume != nil && bytes.Equal(key, s.resume) {
+			return true
+		}
+
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) <= len(s.scope) {
+			return true
+		}
+
+		s.buf = append(s.buf, vals)
+		s.resume = append([]byte(nil), key...)
+		if len(s.buf) >= cursorBatchSize {
+			full = true
+			return false
+		}
+		return true
+	})
+
+	if err != nil {
+		return err
+	}
+	if !full {
+		s.done = true
+	}
+	return nil
+}
+
+// rowField returns a field of whichever entity a row holds
+func rowField(r Row, field string) (interface{}, bool) {
+	switch {
+	case r.Document != nil:
+		return nodeField(r.Document, field)
+	case r.Version != nil:
+		return versionField(r.Version, field)
+	case r.Metadata != nil:
+		return metadataField(r.Metadata, field)
+	case r.Conversation != nil:
+		return conversationField(r.Conversation, field)
+	}
+	return nil, false
+}
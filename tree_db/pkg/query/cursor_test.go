@@ -0,0 +1,151 @@
+// ABOUTME: Tests for lazy query cursors
+// ABOUTME: Verifies batched scans, conditions, pagination and ordering
+
+package query
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/metadata"
+)
+
+func drainCursor(t *testing.T, engine *Engine, q Query) []Row {
+	t.Helper()
+	cur, err := engine.Cursor(q)
+	if err != nil {
+		t.Fatalf("Cursor failed: %v", err)
+	}
+	defer cur.Close()
+
+	var rows []Row
+	for cur.Next() {
+		rows = append(rows, cur.Row())
+	}
+	if err := cur.Err(); err != nil {
+		t.Fatalf("Cursor iteration failed: %v", err)
+	}
+	return rows
+}
+
+func TestCursorDocuments(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	// More nodes than one scan batch, plus a policy whose ID shares a prefix
+	total := cursorBatchSize*2 + 10
+	nodes := make([]*document.Node, total)
+	for i := range nodes {
+		nodes[i] = &document.Node{NodeID: fmt.Sprintf("n%04d", i), Title: "Node", PageStart: i}
+	}
+	storeSearchNodes(t, engine, "P", nodes)
+	storeSearchNodes(t, engine, "PX", []*document.Node{{NodeID: "x1", Title: "Other"}})
+
+	rows := drainCursor(t, engine, NewQueryBuilder(QueryDocument).
+		Where("policyID", "P").
+		Where("parentID", nil).
+		Limit(0).
+		Build())
+	if len(rows) != total {
+		t.Fatalf("Expected %d rows, got %d", total, len(rows))
+	}
+	for i, row := range rows {
+		if row.Document == nil || row.Document.NodeID != fmt.Sprintf("n%04d", i) {
+			t.Fatalf("Row %d out of order: %+v", i, row)
+		}
+	}
+
+	// Without parentID the cursor walks every node of the policy
+	rows = drainCursor(t, engine, NewQueryBuilder(QueryDocument).Where("policyID", "PX").Build())
+	if len(rows) != 1 || rows[0].Document.NodeID != "x1" {
+		t.Errorf("Expected only x1 for PX, got %d rows", len(rows))
+	}
+
+	// Conditions are applied before offset and limit
+	rows = drainCursor(t, engine, NewQueryBuilder(QueryDocument).
+		Where("policyID", "P").
+		Compare("pageStart", OpGe, 300).
+		Offset(5).
+		Limit(3).
+		Build())
+	got := make([]string, len(rows))
+	for i, row := range rows {
+		got[i] = row.Document.NodeID
+	}
+	if fmt.Sprint(got) != "[n0305 n0306 n0307]" {
+		t.Errorf("Unexpected page: %v", got)
+	}
+
+	// Ordering materializes the matches and sorts them
+	rows = drainCursor(t, engine, NewQueryBuilder(QueryDocument).
+		Where("policyID", "P").
+		Compare("pageStart", OpLt, 10).
+		OrderBy("pageStart", true).
+		Limit(2).
+		Build())
+	if len(rows) != 2 || rows[0].Document.NodeID != "n0009" || rows[1].Document.NodeID != "n0008" {
+		t.Errorf("Unexpected ordered rows: %+v", rows)
+	}
+}
+
+func TestCursorMetadata(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	entries := []*metadata.MetadataEntry{
+		{EntityType: "document", EntityID: "d1", Key: "status", Value: "active"},
+		{EntityType: "document", EntityID: "d2", Key: "status", Value: "retired"},
+		{EntityType: "node", EntityID: "n1", Key: "status", Value: "active"},
+		{EntityType: "document", EntityID: "d3", Key: "statusx", Value: "active"},
+	}
+	for _, e := range entries {
+		e.ValueType, e.CreatedAt, e.UpdatedAt = "string", now, now
+		if err := engine.metaStore.SetMetadata(e); err != nil {
+			t.Fatalf("SetMetadata failed: %v", err)
+		}
+	}
+
+	rows := drainCursor(t, engine, NewQueryBuilder(QueryMetadata).Where("key", "status").Build())
+	if len(rows) != 3 {
+		t.Errorf("Expected 3 status entries, got %d", len(rows))
+	}
+
+	rows = drainCursor(t, engine, NewQueryBuilder(QueryMetadata).
+		Where("key", "status").
+		Where("value", "active").
+		Where("entityType", "document").
+		Build())
+	if len(rows) != 1 || rows[0].Metadata.EntityID != "d1" {
+		t.Errorf("Expected only d1, got %+v", rows)
+	}
+}
+
+func TestCursorClose(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	storeSearchNodes(t, engine, "P", []*document.Node{{NodeID: "a"}, {NodeID: "b"}})
+
+	cur, err := engine.Cursor(NewQueryBuilder(QueryDocument).Where("policyID", "P").Build())
+	if err != nil {
+		t.Fatalf("Cursor failed: %v", err)
+	}
+	if !cur.Next() {
+		t.Fatal("Expected a first row")
+	}
+	cur.Close()
+	if cur.Next() {
+		t.Error("Next should return false after Close")
+	}
+
+	if _, err := engine.Cursor(NewQueryBuilder(QueryPrompt).Build()); err == nil {
+		t.Error("Expected error for prompt query without userID or tag")
+	}
+}
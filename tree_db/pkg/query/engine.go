@@ -4,8 +4,11 @@
 package query
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"math"
+	"sort"
+	"sync"
 
 	"github.com/nainya/treestore/pkg/document"
 	"github.com/nainya/treestore/pkg/metadata"
@@ -16,26 +19,83 @@ import (
 
 // Engine provides unified query interface across all stores
 type Engine struct {
-	kv       *storage.KV
+	kv       storage.Engine
 	docStore *document.SimpleStore
 	verStore *version.VersionStore
 	metaStore *metadata.MetadataStore
 	promptStore *prompt.PromptStore
+	savedMu     *sync.Mutex // Serializes writes to saved queries; shared by views
+	cache       *resultCache // Nil unless EnableCache was called; shared by views
 }
 
 // NewEngine creates a new query engine
-func NewEngine(kv *storage.KV) *Engine {
+func NewEngine(kv storage.Engine) *Engine {
 	return &Engine{
 		kv:          kv,
 		docStore:    document.NewSimpleStore(kv),
 		verStore:    version.NewVersionStore(kv),
 		metaStore:   metadata.NewMetadataStore(kv),
 		promptStore: prompt.NewPromptStore(kv),
+		savedMu:     &sync.Mutex{},
 	}
 }
 
+// NewEngineWithStores creates a query engine over existing stores
+// Use it to share runtime registrations such as compound indexes with the caller.
+func NewEngineWithStores(kv storage.Engine, docStore *document.SimpleStore, verStore *version.VersionStore, metaStore *metadata.MetadataStore, promptStore *prompt.PromptStore) *Engine {
+	return &Engine{
+		kv:          kv,
+		docStore:    docStore,
+		verStore:    verStore,
+		metaStore:   metaStore,
+		promptStore: promptStore,
+		savedMu:     &sync.Mutex{},
+	}
+}
+
+// WithContext returns a view of the engine whose queries stop scanning once
+// ctx is done, failing with ctx.Err(). Metadata lookups are not interrupted.
+func (e *Engine) WithContext(ctx context.Context) *Engine {
+	view := *e
+	view.kv = storage.WithContext(ctx, e.kv)
+	if e.docStore != nil {
+		view.docStore = e.docStore.WithContext(ctx)
+	}
+	if e.verStore != nil {
+		view.verStore = e.verStore.WithContext(ctx)
+	}
+	if e.promptStore != nil {
+		view.promptStore = e.promptStore.WithContext(ctx)
+	}
+	return &view
+}
+
 // Execute runs a query and returns results
 func (e *Engine) Execute(q Query) (*Result, error) {
+	if e.cache == nil {
+		return e.execute(q)
+	}
+
+	key := cacheKey("execute", q)
+	if entry, ok := e.cache.get(key); ok {
+		res := *entry.result
+		return &res, nil
+	}
+	gen := e.cache.generation()
+	res, err := e.execute(q)
+	if err != nil {
+		return nil, err
+	}
+	e.cache.put(&cacheEntry{key: key, deps: queryDeps(q), result: res}, gen)
+	return res, nil
+}
+
+// execute runs a query against the stores
+func (e *Engine) execute(q Query) (*Result, error) {
+	if q.Aggregated() {
+		return e.executeAggregation(q)
+	}
+
 	switch q.Type {
 	case QueryDocument:
 		return e.executeDocumentQuery(q)
@@ -101,20 +161,35 @@ func (e *Engine) GetEnrichedConversation(conversationID string) (*EnrichedConver
 	return enriched, nil
 }
 
-// Search performs full-text search across document store
+// Search performs full-text search over the nodes of one policy
+// Hits are filtered, ranked by score and then paginated with Limit and Offset.
 func (e *Engine) Search(opts SearchOptions) ([]*SearchResult, error) {
+	if opts.PolicyID == "" {
+		return nil, fmt.Errorf("policyID required for search")
+	}
+	if opts.Query == "" {
+		return nil, fmt.Errorf("query required for search")
+	}
+	if opts.EntityType != nil && *opts.EntityType != "document" {
+		return nil, fmt.Errorf("unsupported search entity type: %s", *opts.EntityType)
+	}
 	if opts.Limit == 0 {
 		opts.Limit = 100
 	}
 
-	// Currently only supports document search
-	// Could be extended to search across other stores
-	policyID, ok := getStringFilter("policyID", map[string]interface{}{})
-	if !ok {
-		return nil, fmt.Errorf("policyID required for search")
+	filter := opts.Filter
+	if len(opts.Metadata) > 0 {
+		match := filter.Match
+		filter.Match = func(node *document.Node) bool {
+			if match != nil && !match(node) {
+				return false
+			}
+			return e.hasMetadata("node", node.NodeID, opts.Metadata)
+		}
 	}
 
-	docResults, err := e.docStore.Search(policyID, opts.Query, opts.Limit)
+	// Rank every filtered hit so that pages are stable
+	docResults, err := e.docStore.SearchFiltered(opts.PolicyID, opts.Query, math.MaxInt, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -122,17 +197,124 @@ func (e *Engine) Search(opts SearchOptions) ([]*SearchResult, error) {
 	results := make([]*SearchResult, 0, len(docResults))
 	for _, dr := range docResults {
 		if dr.Score >= opts.MinScore {
-			results = append(results, &SearchResult{
-				EntityType: "document",
-				EntityID:   dr.NodeID,
-				Title:      dr.Title,
-				Snippet:    dr.Summary,
-				Score:      dr.Score,
-			})
+			results = append(results, documentResult(dr))
+		}
+	}
+	sortSearchResults(results)
+
+	return applyPagination(results, opts.Limit, opts.Offset), nil
+}
+
+// HybridSearch ranks policy nodes by combining BM25 keyword and vector similarity scores
+func (e *Engine) HybridSearch(opts HybridSearchOptions) ([]*HybridResult, error) {
+	if opts.PolicyID == "" {
+		return nil, fmt.Errorf("policyID required for hybrid search")
+	}
+	if opts.Query == "" && len(opts.Vector) == 0 {
+		return nil, fmt.Errorf("query or vector required for hybrid search")
+	}
+	if opts.Limit == 0 {
+		opts.Limit = 10
+	}
+	if opts.KeywordWeight <= 0 || opts.KeywordWeight > 1 {
+		opts.KeywordWeight = 0.5
+	}
+	if opts.RRFK <= 0 {
+		opts.RRFK = 60
+	}
+
+	// Fetch deeper candidate lists than requested so fusion can reorder them
+	depth := opts.Limit * 4
+
+	var keyword, vector []*document.SearchResult
+	if opts.Query != "" {
+		results, err := e.docStore.BM25Search(opts.PolicyID, opts.Query, depth)
+		if err != nil {
+			return nil, err
+		}
+		keyword = results
+	}
+	if len(opts.Vector) > 0 {
+		results, err := e.docStore.VectorSearch(opts.PolicyID, opts.Vector, depth)
+		if err != nil {
+			return nil, err
+		}
+		vector = results
+	}
+
+	merged := make(map[string]*HybridResult)
+	collect := func(results []*document.SearchResult, apply func(hr *HybridResult, rank int, score float64)) {
+		for i, r := range results {
+			hr, ok := merged[r.NodeID]
+			if !ok {
+				hr = &HybridResult{
+					NodeID:   r.NodeID,
+					PolicyID: r.PolicyID,
+					Title:    r.Title,
+					Snippet:  r.Snippet,
+				}
+				merged[r.NodeID] = hr
+			}
+			if hr.Snippet == "" {
+				hr.Snippet = r.Snippet
+			}
+			apply(hr, i+1, r.Score)
+		}
+	}
+	collect(keyword, func(hr *HybridResult, rank int, score float64) {
+		hr.KeywordRank, hr.KeywordScore = rank, score
+	})
+	collect(vector, func(hr *HybridResult, rank int, score float64) {
+		hr.VectorRank, hr.VectorScore = rank, score
+	})
+
+	maxKeyword, maxVector := maxScore(keyword), maxScore(vector)
+	results := make([]*HybridResult, 0, len(merged))
+	for _, hr := range merged {
+		switch opts.Fusion {
+		case FusionRRF:
+			if hr.KeywordRank > 0 {
+				hr.Score += 1 / float64(opts.RRFK+hr.KeywordRank)
+			}
+			if hr.VectorRank > 0 {
+				hr.Score += 1 / float64(opts.RRFK+hr.VectorRank)
+			}
+		default:
+			keywordWeight := opts.KeywordWeight
+			if len(vector) == 0 {
+				keywordWeight = 1
+			} else if len(keyword) == 0 {
+				keywordWeight = 0
+			}
+			if maxKeyword > 0 {
+				hr.Score += keywordWeight * hr.KeywordScore / maxKeyword
+			}
+			if maxVector > 0 {
+				hr.Score += (1 - keywordWeight) * hr.VectorScore / maxVector
+			}
 		}
+		results = append(results, hr)
 	}
 
-	return results, nil
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].NodeID < results[j].NodeID
+	})
+
+	return applyPagination(results, opts.Limit, 0), nil
+}
+
+// maxScore returns the highest score in a result list, or 0 when empty
+func maxScore(results []*document.SearchResult) float64 {
+	best := 0.0
+	for _, r := range results {
+		if r.Score > best {
+			best = r.Score
+		}
+	}
+	return best
 }
 
 // FindRelated finds entities related to a given entity via metadata
@@ -171,14 +353,14 @@ func (e *Engine) executeDocumentQuery(q Query) (*Result, error) {
 
 	nodeID, hasNodeID := getStringFilter("nodeID", q.Filters)
 
+	var nodes []*document.Node
 	if hasNodeID {
 		// Single node query
 		node, err := e.docStore.GetNode(policyID, nodeID)
 		if err != nil {
 			return nil, err
 		}
-		result.Documents = []*document.Node{node}
-		result.Total = 1
+		nodes = []*document.Node{node}
 	} else if parentID, hasParent := q.Filters["parentID"]; hasParent {
 		// Children query
 		var pid *string
@@ -190,14 +372,19 @@ func (e *Engine) executeDocumentQuery(q Query) (*Result, error) {
 		if err != nil {
 			return nil, err
 		}
-		result.Documents = children
-		result.Total = len(children)
+		nodes = children
 	} else {
 		return nil, fmt.Errorf("nodeID or parentID required")
 	}
 
+	nodes, err := refine(nodes, q, nodeField)
+	if err != nil {
+		return nil, err
+	}
+
 	// Apply limit/offset
-	result.Documents = applyPagination(result.Documents, q.Limit, q.Offset)
+	result.Total = len(nodes)
+	result.Documents = paginate(nodes, q)
 	result.HasMore = result.Total > (q.Offset + len(result.Documents))
 
 	return result, nil
@@ -213,32 +400,37 @@ func (e *Engine) executeVersionQuery(q Query) (*Result, error) {
 
 	versionID, hasVersionID := getStringFilter("versionID", q.Filters)
 
+	var versions []*version.Version
 	if hasVersionID {
 		// Single version query
 		ver, err := e.verStore.GetVersion(policyID, versionID)
 		if err != nil {
 			return nil, err
 		}
-		result.Versions = []*version.Version{ver}
-		result.Total = 1
+		versions = []*version.Version{ver}
 	} else if tag, hasTag := getStringFilter("tag", q.Filters); hasTag {
 		// Tag query
 		ver, err := e.verStore.GetVersionByTag(policyID, tag)
 		if err != nil {
 			return nil, err
 		}
-		result.Versions = []*version.Version{ver}
-		result.Total = 1
+		versions = []*version.Version{ver}
 	} else {
 		// List versions
-		versions, err := e.verStore.ListVersions(policyID, q.Limit)
+		listed, err := e.verStore.ListVersions(policyID, storeLimit(q))
 		if err != nil {
 			return nil, err
 		}
-		result.Versions = versions
-		result.Total = len(versions)
+		versions = listed
+	}
+
+	versions, err := refine(versions, q, versionField)
+	if err != nil {
+		return nil, err
 	}
 
+	result.Total = len(versions)
+	result.Versions = paginate(versions, q)
 	result.HasMore = result.Total > (q.Offset + len(result.Versions))
 	return result, nil
 }
@@ -254,52 +446,75 @@ func (e *Engine) executeMetadataQuery(q Query) (*Result, error) {
 		entityType = &et
 	}
 
+	var entries []*metadata.MetadataEntry
+	var err error
 	if hasKey && hasValue {
 		// Key-value query
-		entries, err := e.metaStore.QueryByKeyValue(key, value, entityType, q.Limit)
-		if err != nil {
-			return nil, err
-		}
-		result.Metadata = entries
+		entries, err = e.metaStore.QueryByKeyValue(key, value, entityType, storeLimit(q))
 	} else if hasKey {
 		// Key-only query
-		entries, err := e.metaStore.QueryByKey(key, entityType, q.Limit)
-		if err != nil {
-			return nil, err
-		}
-		result.Metadata = entries
+		entries, err = e.metaStore.QueryByKey(key, entityType, storeLimit(q))
 	} else {
 		return nil, fmt.Errorf("key required for metadata query")
 	}
+	if err != nil {
+		return nil, err
+	}
 
-	result.Total = len(result.Metadata)
-	result.HasMore = false
+	entries, err = refine(entries, q, metadataField)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Total = len(entries)
+	result.Metadata = paginate(entries, q)
+	result.HasMore = result.Total > (q.Offset + len(result.Metadata))
 	return result, nil
 }
 
 func (e *Engine) executePromptQuery(q Query) (*Result, error) {
 	result := &Result{Conversations: []*prompt.Conversation{}}
 
+	var convs []*prompt.Conversation
+	var err error
 	if userID, ok := getStringFilter("userID", q.Filters); ok {
 		// User conversations
-		convs, err := e.promptStore.ListConversationsByUser(userID, q.Limit)
-		if err != nil {
-			return nil, err
-		}
-		result.Conversations = convs
+		convs, err = e.promptStore.ListConversationsByUser(userID, storeLimit(q))
 	} else if tag, ok := getStringFilter("tag", q.Filters); ok {
 		// Tag-based query
-		convs, err := e.promptStore.ListConversationsByTag(tag, q.Limit)
-		if err != nil {
-			return nil, err
-		}
-		result.Conversations = convs
+		convs, err = e.promptStore.ListConversationsByTag(tag, storeLimit(q))
 	} else {
 		return nil, fmt.Errorf("userID or tag required for prompt query")
 	}
+	if err != nil {
+		return nil, err
+	}
 
-	result.Total = len(result.Conversations)
-	result.HasMore = false
+	convs, err = refine(convs, q, conversationField)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Total = len(convs)
+	result.Conversations = paginate(convs, q)
+	result.HasMore = result.Total > (q.Offset + len(result.Conversations))
+	return result, nil
+}
+
+func (e *Engine) executeAggregation(q Query) (*Result, error) {
+	rows, err := e.aggregate(q)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]*Group, len(rows))
+	for i, row := range rows {
+		groups[i] = row.Group
+	}
+
+	result := &Result{Total: len(groups)}
+	result.Groups = paginate(groups, q)
+	result.HasMore = result.Total > (q.Offset + len(result.Groups))
 	return result, nil
 }
 
@@ -314,6 +529,26 @@ func getStringFilter(key string, filters map[string]interface{}) (string, bool)
 	return str, ok
 }
 
+// storeLimit is the limit to push down to a store lookup
+// Results that are refined or offset afterwards must be fetched in full.
+func storeLimit(q Query) int {
+	if len(q.Conditions) > 0 || q.OrderBy != "" || q.Offset > 0 {
+		return 0
+	}
+	return q.Limit
+}
+
+// paginate applies a query's offset and limit, treating limit <= 0 as unlimited
+func paginate[T any](items []T, q Query) []T {
+	if q.Limit <= 0 {
+		if q.Offset >= len(items) {
+			return []T{}
+		}
+		return items[q.Offset:]
+	}
+	return applyPagination(items, q.Limit, q.Offset)
+}
+
 func applyPagination[T any](items []T, limit, offset int) []T {
 	if offset >= len(items) {
 		return []T{}
@@ -330,11 +565,14 @@ func applyPagination[T any](items []T, limit, offset int) []T {
 
 // BatchGet retrieves multiple entities efficiently
 func (e *Engine) BatchGetNodes(policyID string, nodeIDs []string) ([]*document.Node, error) {
-	nodes := make([]*document.Node, 0, len(nodeIDs))
+	found, err := e.docStore.GetNodes(policyID, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, nodeID := range nodeIDs {
-		node, err := e.docStore.GetNode(policyID, nodeID)
-		if err == nil {
+	nodes := make([]*document.Node, 0, len(found))
+	for _, node := range found {
+		if node != nil {
 			nodes = append(nodes, node)
 		}
 	}
@@ -342,16 +580,79 @@ func (e *Engine) BatchGetNodes(policyID string, nodeIDs []string) ([]*document.N
 	return nodes, nil
 }
 
-// GlobalSearch searches across all text fields in all stores
+// SearchAllPolicies runs a keyword search across every policy
+// Results are grouped by policy with at most perPolicy hits in each group
+func (e *Engine) SearchAllPolicies(query string, perPolicy, maxPolicies int) ([]*document.PolicySearchResults, error) {
+	return e.docStore.SearchAll(query, perPolicy, maxPolicies)
+}
+
+// GlobalSearch searches the nodes of every policy through the term index
+// Results from all policies are ranked together by score.
 func (e *Engine) GlobalSearch(query string, limit int) ([]*SearchResult, error) {
+	if query == "" {
+		return nil, fmt.Errorf("query required for global search")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	// No policy can contribute more than limit hits to the overall top limit
+	groups, err := e.docStore.SearchAll(query, limit, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	results := make([]*SearchResult, 0)
-	query = strings.ToLower(query)
+	for _, group := range groups {
+		for _, dr := range group.Results {
+			results = append(results, documentResult(dr))
+		}
+	}
+	sortSearchResults(results)
 
-	// This is a simplified implementation
-	// In production, you'd want proper full-text indexing
+	return applyPagination(results, limit, 0), nil
+}
 
-	// Search would need to be expanded across stores
-	// For now, returning empty results as placeholder
+// documentResult converts a document search hit to a unified search result
+func documentResult(dr *document.SearchResult) *SearchResult {
+	snippet := dr.Snippet
+	if snippet == "" {
+		snippet = dr.Summary
+	}
+
+	return &SearchResult{
+		EntityType: "document",
+		EntityID:   dr.NodeID,
+		PolicyID:   dr.PolicyID,
+		Title:      dr.Title,
+		Snippet:    snippet,
+		Score:      dr.Score,
+	}
+}
 
-	return results, nil
+// sortSearchResults orders results by descending score, breaking ties by ID
+func sortSearchResults(results []*SearchResult) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].PolicyID != results[j].PolicyID {
+			return results[i].PolicyID < results[j].PolicyID
+		}
+		return results[i].EntityID < results[j].EntityID
+	})
+}
+
+// hasMetadata reports whether an entity carries every required metadata value
+func (e *Engine) hasMetadata(entityType, entityID string, required map[string]string) bool {
+	attrs, err := e.metaStore.GetAllMetadata(entityType, entityID)
+	if err != nil {
+		return false
+	}
+	for key, value := range required {
+		if attrs[key] != value {
+			return false
+		}
+	}
+	return true
 }
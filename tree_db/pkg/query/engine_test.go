@@ -16,7 +16,7 @@ import (
 )
 
 func setupTestEngine(t *testing.T) (*Engine, *storage.KV, string) {
-	path := "/tmp/test_queryengine_" + t.Name() + ".db"
+	path := storage.MemoryPath
 	kv := &storage.KV{Path: path}
 	if err := kv.Open(); err != nil {
 		t.Fatalf("Failed to open: %v", err)
@@ -460,3 +460,206 @@ func TestBatchGetNodes(t *testing.T) {
 		t.Errorf("Expected 3 nodes, got %d", len(retrieved))
 	}
 }
+
+func TestHybridSearch(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	nodes := []*document.Node{
+		{NodeID: "keyword", Title: "Prior authorization", Text: "Prior authorization for imaging"},
+		{NodeID: "both", Title: "Imaging review", Text: "Authorization review for imaging"},
+		{NodeID: "semantic", Title: "Pre-approval", Text: "Services needing approval in advance"},
+	}
+	for _, n := range nodes {
+		n.PolicyID = "policy1"
+		n.CreatedAt = now
+		n.UpdatedAt = now
+	}
+	if err := engine.docStore.StoreDocument(&document.Document{PolicyID: "policy1"}, nodes); err != nil {
+		t.Fatalf("Failed to store: %v", err)
+	}
+
+	vectors := map[string][]float32{
+		"keyword":  {0, 1},
+		"both":     {1, 0.1},
+		"semantic": {0.9, 0.5},
+	}
+	for nodeID, v := range vectors {
+		if err := engine.docStore.SetEmbedding("policy1", nodeID, v); err != nil {
+			t.Fatalf("SetEmbedding failed: %v", err)
+		}
+	}
+
+	for _, fusion := range []FusionMethod{FusionWeighted, FusionRRF} {
+		results, err := engine.HybridSearch(HybridSearchOptions{
+			PolicyID: "policy1",
+			Query:    "prior authorization",
+			Vector:   []float32{1, 0},
+			Limit:    3,
+			Fusion:   fusion,
+		})
+		if err != nil {
+			t.Fatalf("HybridSearch failed: %v", err)
+		}
+		if len(results) != 3 {
+			t.Fatalf("Expected 3 results, got %d", len(results))
+		}
+
+		// The node found by both retrievers wins under either fusion
+		if results[0].NodeID != "both" {
+			t.Errorf("Fusion %d: expected 'both' first, got %s", fusion, results[0].NodeID)
+		}
+		if results[0].KeywordRank == 0 || results[0].VectorRank == 0 {
+			t.Errorf("Fusion %d: expected both ranks set, got %+v", fusion, results[0])
+		}
+	}
+
+	// Keyword-only search ignores the vector side
+	results, err := engine.HybridSearch(HybridSearchOptions{PolicyID: "policy1", Query: "prior"})
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(results) != 1 || results[0].NodeID != "keyword" {
+		t.Errorf("Expected only 'keyword', got %v", results)
+	}
+
+	if _, err := engine.HybridSearch(HybridSearchOptions{PolicyID: "policy1"}); err == nil {
+		t.Error("Expected error without query or vector")
+	}
+}
+
+func storeSearchNodes(t *testing.T, engine *Engine, policyID string, nodes []*document.Node) {
+	t.Helper()
+	now := time.Now()
+	for _, n := range nodes {
+		n.PolicyID = policyID
+		n.CreatedAt = now
+		n.UpdatedAt = now
+	}
+	if err := engine.docStore.StoreDocument(&document.Document{PolicyID: policyID}, nodes); err != nil {
+		t.Fatalf("Failed to store: %v", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	storeSearchNodes(t, engine, "policy1", []*document.Node{
+		{NodeID: "a", Title: "Imaging", Text: "imaging criteria", PageStart: 1, PageEnd: 2},
+		{NodeID: "b", Title: "Imaging coverage", Summary: "imaging", Text: "imaging", PageStart: 3, PageEnd: 4},
+		{NodeID: "c", Title: "Appeals", Text: "imaging appeals", PageStart: 5, PageEnd: 6},
+		{NodeID: "d", Title: "Billing", Text: "billing codes", PageStart: 7, PageEnd: 8},
+	})
+	storeSearchNodes(t, engine, "policy2", []*document.Node{
+		{NodeID: "x", Title: "Imaging", Text: "imaging"},
+	})
+
+	results, err := engine.Search(SearchOptions{PolicyID: "policy1", Query: "imaging"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if ids := resultIDs(results); len(ids) != 3 || ids[0] != "b" || ids[1] != "a" || ids[2] != "c" {
+		t.Errorf("Expected [b a c] ranked by score, got %v", ids)
+	}
+	for _, r := range results {
+		if r.PolicyID != "policy1" || r.EntityType != "document" {
+			t.Errorf("Unexpected result %+v", r)
+		}
+	}
+
+	// Pagination applies after ranking
+	page, err := engine.Search(SearchOptions{PolicyID: "policy1", Query: "imaging", Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if ids := resultIDs(page); len(ids) != 1 || ids[0] != "a" {
+		t.Errorf("Expected second page [a], got %v", ids)
+	}
+
+	// Structural and metadata filters
+	filtered, err := engine.Search(SearchOptions{PolicyID: "policy1", Query: "imaging", Filter: document.SearchFilter{PageFrom: 3}})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if ids := resultIDs(filtered); len(ids) != 2 || ids[0] != "b" || ids[1] != "c" {
+		t.Errorf("Expected [b c] from page 3 on, got %v", ids)
+	}
+
+	now := time.Now()
+	if err := engine.metaStore.SetMetadata(&metadata.MetadataEntry{
+		EntityType: "node", EntityID: "c", Key: "status", Value: "active", ValueType: "string", CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	tagged, err := engine.Search(SearchOptions{PolicyID: "policy1", Query: "imaging", Metadata: map[string]string{"status": "active"}})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if ids := resultIDs(tagged); len(ids) != 1 || ids[0] != "c" {
+		t.Errorf("Expected [c] with status=active, got %v", ids)
+	}
+
+	minScored, err := engine.Search(SearchOptions{PolicyID: "policy1", Query: "imaging", MinScore: 5})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if ids := resultIDs(minScored); len(ids) != 1 || ids[0] != "b" {
+		t.Errorf("Expected [b] above min score, got %v", ids)
+	}
+
+	if _, err := engine.Search(SearchOptions{Query: "imaging"}); err == nil {
+		t.Error("Expected error without policyID")
+	}
+	conversation := "conversation"
+	if _, err := engine.Search(SearchOptions{PolicyID: "policy1", Query: "imaging", EntityType: &conversation}); err == nil {
+		t.Error("Expected error for unsupported entity type")
+	}
+}
+
+func TestGlobalSearch(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	storeSearchNodes(t, engine, "policy1", []*document.Node{
+		{NodeID: "a", Title: "Imaging", Text: "imaging criteria"},
+		{NodeID: "b", Title: "Billing", Text: "billing codes"},
+	})
+	storeSearchNodes(t, engine, "policy2", []*document.Node{
+		{NodeID: "x", Title: "Imaging imaging", Summary: "imaging", Text: "imaging imaging"},
+	})
+
+	results, err := engine.GlobalSearch("imaging", 10)
+	if err != nil {
+		t.Fatalf("GlobalSearch failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results across policies, got %d", len(results))
+	}
+	if results[0].PolicyID != "policy2" || results[0].EntityID != "x" || results[1].EntityID != "a" {
+		t.Errorf("Expected policy2/x ranked first, got %+v %+v", results[0], results[1])
+	}
+	if results[0].Score < results[1].Score {
+		t.Error("Expected results ordered by score")
+	}
+
+	limited, err := engine.GlobalSearch("imaging", 1)
+	if err != nil {
+		t.Fatalf("GlobalSearch failed: %v", err)
+	}
+	if len(limited) != 1 {
+		t.Errorf("Expected 1 result with limit 1, got %d", len(limited))
+	}
+}
+
+func resultIDs(results []*SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.EntityID
+	}
+	return ids
+}
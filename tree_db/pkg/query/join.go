@@ -0,0 +1,126 @@
+// ABOUTME: Cross-store node joins over document, metadata and reference indexes
+// ABOUTME: Intersects candidate node sets so callers get one answer in one call
+
+package query
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nainya/treestore/pkg/metadata"
+)
+
+// JoinNodes returns the nodes of a policy that satisfy every condition of a join
+// Each condition yields a candidate set from its own index; the sets are
+// intersected before any node is loaded. Results are ordered by page, then ID.
+func (e *Engine) JoinNodes(j NodeJoin) ([]*JoinedNode, error) {
+	if j.PolicyID == "" {
+		return nil, fmt.Errorf("policyID required for join")
+	}
+	if len(j.Metadata) == 0 && j.ReferencesTo == "" && j.ReferencedBy == "" {
+		return nil, fmt.Errorf("join needs metadata or reference conditions")
+	}
+	if j.Limit == 0 {
+		j.Limit = 100
+	}
+
+	var candidates map[string]bool
+	intersect := func(ids []string) {
+		next := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if candidates == nil || candidates[id] {
+				next[id] = true
+			}
+		}
+		candidates = next
+	}
+
+	// References that satisfied the join, by node
+	matchedRefs := make(map[string][]*metadata.CrossReference)
+
+	if j.ReferencesTo != "" {
+		refs, err := e.metaStore.ReferencesFrom(j.PolicyID, "")
+		if err != nil {
+			return nil, err
+		}
+		intersect(collectReferences(refs, matchedRefs, func(ref *metadata.CrossReference) (string, bool) {
+			return ref.SourceNodeID, ref.TargetPolicyID == j.ReferencesTo && (j.ReferenceType == "" || ref.ReferenceType == j.ReferenceType)
+		}))
+	}
+
+	if j.ReferencedBy != "" && (candidates == nil || len(candidates) > 0) {
+		refs, err := e.metaStore.ReferencesTo(j.PolicyID, "")
+		if err != nil {
+			return nil, err
+		}
+		intersect(collectReferences(refs, matchedRefs, func(ref *metadata.CrossReference) (string, bool) {
+			return ref.TargetNodeID, ref.SourcePolicyID == j.ReferencedBy && (j.ReferenceType == "" || ref.ReferenceType == j.ReferenceType)
+		}))
+	}
+
+	if len(j.Metadata) > 0 && (candidates == nil || len(candidates) > 0) {
+		nodeType := "node"
+		ids, err := e.metaStore.QueryMultiple(j.Metadata, &nodeType, 0)
+		if err != nil {
+			return nil, err
+		}
+		intersect(ids)
+	}
+
+	nodeIDs := make([]string, 0, len(candidates))
+	for id := range candidates {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Strings(nodeIDs)
+
+	// Loading by policy drops candidates that belong to other policies
+	nodes, err := e.docStore.GetNodes(j.PolicyID, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*JoinedNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		results = append(results, &JoinedNode{Node: node, References: matchedRefs[node.NodeID]})
+	}
+
+	sort.SliceStable(results, func(a, b int) bool {
+		if results[a].Node.PageStart != results[b].Node.PageStart {
+			return results[a].Node.PageStart < results[b].Node.PageStart
+		}
+		return results[a].Node.NodeID < results[b].Node.NodeID
+	})
+
+	results = applyPagination(results, j.Limit, j.Offset)
+	for _, r := range results {
+		attrs, err := e.metaStore.GetAllMetadata("node", r.Node.NodeID)
+		if err != nil {
+			return nil, err
+		}
+		r.Metadata = attrs
+	}
+
+	return results, nil
+}
+
+// collectReferences returns the node IDs of references accepted by match,
+// recording the accepted references per node
+func collectReferences(refs []*metadata.CrossReference, matched map[string][]*metadata.CrossReference, match func(*metadata.CrossReference) (string, bool)) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		nodeID, ok := match(ref)
+		if !ok {
+			continue
+		}
+		if !seen[nodeID] {
+			seen[nodeID] = true
+			ids = append(ids, nodeID)
+		}
+		matched[nodeID] = append(matched[nodeID], ref)
+	}
+	return ids
+}
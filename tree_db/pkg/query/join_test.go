@@ -0,0 +1,100 @@
+// ABOUTME: Tests for cross-store node joins
+// ABOUTME: Verifies intersection of metadata and reference conditions
+
+package query
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/document"
+	"github.com/nainya/treestore/pkg/metadata"
+)
+
+func TestJoinNodes(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	storeSearchNodes(t, engine, "P", []*document.Node{
+		{NodeID: "p1", Title: "One", PageStart: 3},
+		{NodeID: "p2", Title: "Two", PageStart: 1},
+		{NodeID: "p3", Title: "Three", PageStart: 2},
+	})
+	storeSearchNodes(t, engine, "Q", []*document.Node{{NodeID: "q1", Title: "Target"}})
+
+	for nodeID, status := range map[string]string{"p1": "active", "p2": "active", "p3": "retired", "q1": "active"} {
+		if err := engine.metaStore.SetMetadata(&metadata.MetadataEntry{
+			EntityType: "node", EntityID: nodeID, Key: "status", Value: status, ValueType: "string", CreatedAt: now, UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("SetMetadata failed: %v", err)
+		}
+	}
+
+	refs := []*metadata.CrossReference{
+		{SourcePolicyID: "P", SourceNodeID: "p1", TargetPolicyID: "Q", TargetNodeID: "q1", ReferenceType: "cites"},
+		{SourcePolicyID: "P", SourceNodeID: "p2", TargetPolicyID: "Q", TargetNodeID: "q1", ReferenceType: "contradicts"},
+		{SourcePolicyID: "P", SourceNodeID: "p3", TargetPolicyID: "Q", TargetNodeID: "q1", ReferenceType: "cites"},
+		{SourcePolicyID: "Q", SourceNodeID: "q1", TargetPolicyID: "P", TargetNodeID: "p1", ReferenceType: "supports"},
+	}
+	for _, ref := range refs {
+		if err := engine.metaStore.AddCrossReference(ref); err != nil {
+			t.Fatalf("AddCrossReference failed: %v", err)
+		}
+	}
+
+	results, err := engine.JoinNodes(NodeJoin{
+		PolicyID:     "P",
+		Metadata:     map[string]string{"status": "active"},
+		ReferencesTo: "Q",
+	})
+	if err != nil {
+		t.Fatalf("JoinNodes failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Node.NodeID != "p2" || results[1].Node.NodeID != "p1" {
+		t.Fatalf("Expected [p2 p1] in page order, got %v", joinedIDs(results))
+	}
+	if results[1].Metadata["status"] != "active" || len(results[1].References) != 1 || results[1].References[0].TargetNodeID != "q1" {
+		t.Errorf("Unexpected joined data for p1: %+v", results[1])
+	}
+
+	// Reference type and both directions narrow the set further
+	results, err = engine.JoinNodes(NodeJoin{PolicyID: "P", ReferencesTo: "Q", ReferencedBy: "Q"})
+	if err != nil {
+		t.Fatalf("JoinNodes failed: %v", err)
+	}
+	if ids := joinedIDs(results); len(ids) != 1 || ids[0] != "p1" || len(results[0].References) != 2 {
+		t.Errorf("Expected p1 with 2 references, got %v", ids)
+	}
+
+	results, err = engine.JoinNodes(NodeJoin{PolicyID: "P", ReferencesTo: "Q", ReferenceType: "cites"})
+	if err != nil {
+		t.Fatalf("JoinNodes failed: %v", err)
+	}
+	if ids := joinedIDs(results); len(ids) != 2 || ids[0] != "p3" || ids[1] != "p1" {
+		t.Errorf("Expected [p3 p1] citing Q, got %v", ids)
+	}
+
+	// Metadata matches on nodes of other policies are dropped
+	results, err = engine.JoinNodes(NodeJoin{PolicyID: "P", Metadata: map[string]string{"status": "active"}, Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("JoinNodes failed: %v", err)
+	}
+	if ids := joinedIDs(results); len(ids) != 1 || ids[0] != "p1" {
+		t.Errorf("Expected second page [p1], got %v", ids)
+	}
+
+	if _, err := engine.JoinNodes(NodeJoin{PolicyID: "P"}); err == nil {
+		t.Error("Expected error without join conditions")
+	}
+}
+
+func joinedIDs(results []*JoinedNode) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Node.NodeID
+	}
+	return ids
+}
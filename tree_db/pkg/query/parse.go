@@ -0,0 +1,544 @@
+// ABOUTME: Declarative query language for the query engine
+// ABOUTME: Parses SQL-like text or JSON into Query values
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query sources accepted after FROM (or in the JSON "from" field)
+var querySources = map[string]QueryType{
+	"documents":     QueryDocument,
+	"nodes":         QueryDocument,
+	"versions":      QueryVersion,
+	"metadata":      QueryMetadata,
+	"conversations": QueryPrompt,
+	"prompts":       QueryPrompt,
+}
+
+// lookupFilters are the fields each query type resolves through a store lookup
+// Equality on these becomes a filter; every other comparison is a condition.
+var lookupFilters = map[QueryType]map[string]bool{
+	QueryDocument: {"policyID": true, "nodeID": true, "parentID": true},
+	QueryVersion:  {"policyID": true, "versionID": true, "tag": true},
+	QueryMetadata: {"key": true, "value": true, "entityType": true},
+	QueryPrompt:   {"userID": true, "tag": true},
+}
+
+// JSONQuery is the JSON form of a query
+// Where maps a field to a value (equality) or to an object of operator to value,
+// e.g. {"policyID": "X", "depth": {"<=": 2}}. Select and GroupBy take the
+// aggregations and group keys as written in the text format, e.g.
+// "select": ["count", "avg(metadata.pages)"], "groupBy": ["month(createdAt)"].
+type JSONQuery struct {
+	Select  []string                   `json:"select"`
+	From    string                     `json:"from"`
+	Where   map[string]json.RawMessage `json:"where"`
+	GroupBy []string                   `json:"groupBy"`
+	OrderBy string                     `json:"orderBy"`
+	Desc    bool                       `json:"desc"`
+	Limit   *int                       `json:"limit"`
+	Offset  int                        `json:"offset"`
+}
+
+// ParseQuery parses a query in either the text or the JSON format
+// The text format is
+//
+//	[SELECT <aggregate>, ...] FROM <source> [WHERE <field> <op> <value> [AND ...]]
+//	[GROUP BY <key>, ...] [ORDER BY <field> [ASC|DESC]] [LIMIT n] [OFFSET n]
+//
+// where values are 'quoted strings', numbers, TRUE, FALSE or NULL. Aggregates
+// are count, count(*), min(<field>), max(<field>) and avg(<field>); keys are
+// fields or <interval>(<field>) date histograms, e.g. month(createdAt), and
+// either may name "metadata.<key>". Keywords are case-insensitive; field names
+// are not. Input starting with "{" is parsed as JSON.
+func ParseQuery(input string) (Query, error) {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "{") {
+		return ParseJSONQuery([]byte(input))
+	}
+
+	p := &parser{}
+	if err := p.tokenize(input); err != nil {
+		return Query{}, err
+	}
+	return p.parse()
+}
+
+// ParseJSONQuery parses the JSON form of a query
+func ParseJSONQuery(data []byte) (Query, error) {
+	var jq JSONQuery
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&jq); err != nil {
+		return Query{}, fmt.Errorf("invalid JSON query: %v", err)
+	}
+
+	qb, err := newSourceBuilder(jq.From)
+	if err != nil {
+		return Query{}, err
+	}
+
+	for _, s := range jq.Select {
+		fn, arg := splitCall(s)
+		addAggregation(qb, fn, arg)
+	}
+	for _, s := range jq.GroupBy {
+		fn, arg := splitCall(s)
+		addGroupKey(qb, fn, arg)
+	}
+
+	for field, raw := range jq.Where {
+		var ops map[string]interface{}
+		if err := json.Unmarshal(raw, &ops); err == nil {
+			for op, value := range ops {
+				if err := addCondition(qb, field, CompareOp(op), value); err != nil {
+					return Query{}, err
+				}
+			}
+			continue
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return Query{}, fmt.Errorf("invalid value for %s: %v", field, err)
+		}
+		if err := addCondition(qb, field, OpEq, value); err != nil {
+			return Query{}, err
+		}
+	}
+
+	if jq.OrderBy != "" {
+		qb.OrderBy(jq.OrderBy, jq.Desc)
+	}
+	if jq.Limit != nil {
+		qb.Limit(*jq.Limit)
+	}
+	qb.Offset(jq.Offset)
+
+	return buildQuery(qb)
+}
+
+// newSourceBuilder starts a query for a named source
+func newSourceBuilder(source string) (*QueryBuilder, error) {
+	qtype, ok := querySources[strings.ToLower(source)]
+	if !ok {
+		return nil, fmt.Errorf("unknown query source: %q", source)
+	}
+	return NewQueryBuilder(qtype), nil
+}
+
+// addCondition routes a comparison to a lookup filter or a result condition
+func addCondition(qb *QueryBuilder, field string, op CompareOp, value interface{}) error {
+	switch op {
+	case OpEq, OpNe, OpLt, OpLe, OpGt, OpGe:
+	case "<>":
+		op = OpNe
+	default:
+		return fmt.Errorf("unknown operator %q for %s", op, field)
+	}
+
+	// Integral JSON numbers compare like the int fields they usually target
+	if f, ok := value.(float64); ok && f == float64(int(f)) {
+		value = int(f)
+	}
+
+	_, filtered := qb.query.Filters[field]
+	if op == OpEq && lookupFilters[qb.query.Type][field] && !filtered {
+		if _, isString := value.(string); value != nil && !isString {
+			return fmt.Errorf("%s must be a string", field)
+		}
+		qb.Where(field, value)
+		return nil
+	}
+
+	qb.Compare(field, op, value)
+	return nil
+}
+
+// addAggregation adds an aggregate written as fn(arg), or as a bare count
+func addAggregation(qb *QueryBuilder, fn, arg string) {
+	if fn == "" {
+		fn, arg = arg, ""
+	}
+	fn = strings.ToLower(fn)
+	if fn == string(AggCount) && arg == "*" {
+		arg = ""
+	}
+	qb.Aggregate(AggregateFunc(fn), arg)
+}
+
+// addGroupKey adds a group key written as a field or as interval(field)
+func addGroupKey(qb *QueryBuilder, interval, field string) {
+	if interval == "" {
+		qb.GroupBy(field)
+		return
+	}
+	qb.Histogram(field, HistogramInterval(strings.ToLower(interval)))
+}
+
+// splitCall splits "fn(arg)" into its function and argument; anything else is
+// returned whole as the argument
+func splitCall(s string) (fn, arg string) {
+	s = strings.TrimSpace(s)
+	open := strings.IndexByte(s, '(')
+	if open <= 0 || !strings.HasSuffix(s, ")") {
+		return "", s
+	}
+	return strings.TrimSpace(s[:open]), strings.TrimSpace(s[open+1 : len(s)-1])
+}
+
+// buildQuery returns the built query after rejecting negative pagination and
+// malformed aggregations
+func buildQuery(qb *QueryBuilder) (Query, error) {
+	q := qb.Build()
+	if q.Limit < 0 || q.Offset < 0 {
+		return Query{}, fmt.Errorf("limit and offset must not be negative")
+	}
+	if err := validateAggregation(q); err != nil {
+		return Query{}, err
+	}
+	return q, nil
+}
+
+// Text format
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+	tokPunct // One of ( ) , *
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// tokenize splits the input into words, quoted strings, numbers and operators
+func (p *parser) tokenize(input string) error {
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '\'':
+			var sb strings.Builder
+			start := i
+			i++
+			for {
+				if i >= len(runes) {
+					return fmt.Errorf("unterminated string at position %d", start)
+				}
+				if runes[i] == '\'' {
+					// '' escapes a quote
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						sb.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind: tokString, text: sb.String(), pos: start})
+
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			i++
+			if i < len(runes) && (runes[i] == '=' || (r == '<' && runes[i] == '>')) {
+				i++
+			}
+			op := string(runes[start:i])
+			if op == "!" {
+				return fmt.Errorf("unexpected '!' at position %d", start)
+			}
+			p.tokens = append(p.tokens, token{kind: tokOp, text: op, pos: start})
+
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind: tokNumber, text: string(runes[start:i]), pos: start})
+
+		case strings.ContainsRune("(),*", r):
+			p.tokens = append(p.tokens, token{kind: tokPunct, text: string(r), pos: i})
+			i++
+
+		case unicode.IsLetter(r) || r == '_':
+			// Dots join the parts of names such as metadata.department
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind: tokWord, text: string(runes[start:i]), pos: start})
+
+		default:
+			return fmt.Errorf("unexpected %q at position %d", r, i)
+		}
+	}
+	return nil
+}
+
+// parse builds a query from the token stream
+func (p *parser) parse() (Query, error) {
+	var selected [][2]string
+	if p.acceptKeyword("SELECT") {
+		for {
+			fn, arg, err := p.call()
+			if err != nil {
+				return Query{}, err
+			}
+			selected = append(selected, [2]string{fn, arg})
+			if !p.acceptPunct(",") {
+				break
+			}
+		}
+	}
+
+	if err := p.keyword("FROM"); err != nil {
+		return Query{}, err
+	}
+	source, err := p.word()
+	if err != nil {
+		return Query{}, err
+	}
+	qb, err := newSourceBuilder(source)
+	if err != nil {
+		return Query{}, err
+	}
+	for _, s := range selected {
+		addAggregation(qb, s[0], s[1])
+	}
+
+	if p.acceptKeyword("WHERE") {
+		for {
+			if err := p.condition(qb); err != nil {
+				return Query{}, err
+			}
+			if !p.acceptKeyword("AND") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("GROUP") {
+		if err := p.keyword("BY"); err != nil {
+			return Query{}, err
+		}
+		for {
+			interval, field, err := p.call()
+			if err != nil {
+				return Query{}, err
+			}
+			addGroupKey(qb, interval, field)
+			if !p.acceptPunct(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if err := p.keyword("BY"); err != nil {
+			return Query{}, err
+		}
+		fn, arg, err := p.call()
+		if err != nil {
+			return Query{}, err
+		}
+		field := arg
+		if fn != "" {
+			field = fn + "(" + arg + ")"
+		}
+		descending := false
+		if p.acceptKeyword("DESC") {
+			descending = true
+		} else {
+			p.acceptKeyword("ASC")
+		}
+		qb.OrderBy(field, descending)
+	}
+
+	if p.acceptKeyword("LIMIT") {
+		n, err := p.integer()
+		if err != nil {
+			return Query{}, err
+		}
+		qb.Limit(n)
+	}
+	if p.acceptKeyword("OFFSET") {
+		n, err := p.integer()
+		if err != nil {
+			return Query{}, err
+		}
+		qb.Offset(n)
+	}
+
+	if p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		return Query{}, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+
+	return buildQuery(qb)
+}
+
+// condition parses "<field> <op> <value>"
+func (p *parser) condition(qb *QueryBuilder) error {
+	field, err := p.word()
+	if err != nil {
+		return err
+	}
+
+	if !p.peek(tokOp) {
+		return p.expected("comparison operator after " + field)
+	}
+	tok, _ := p.next()
+
+	value, err := p.literal()
+	if err != nil {
+		return err
+	}
+
+	return addCondition(qb, field, CompareOp(tok.text), value)
+}
+
+// literal parses a value
+func (p *parser) literal() (interface{}, error) {
+	tok, ok := p.next()
+	if !ok {
+		return nil, p.expected("value")
+	}
+
+	switch tok.kind {
+	case tokString:
+		return tok.text, nil
+	case tokNumber:
+		if n, err := strconv.Atoi(tok.text); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return f, nil
+	case tokWord:
+		switch strings.ToUpper(tok.text) {
+		case "TRUE":
+			return true, nil
+		case "FALSE":
+			return false, nil
+		case "NULL":
+			return nil, nil
+		}
+	}
+
+	return nil, fmt.Errorf("expected value at position %d, got %q", tok.pos, tok.text)
+}
+
+// call parses "<name>" or "<name>(<name>|*)", returning the function name
+// and its argument, or an empty function and the bare name
+func (p *parser) call() (fn, arg string, err error) {
+	name, err := p.word()
+	if err != nil {
+		return "", "", err
+	}
+	if !p.acceptPunct("(") {
+		return "", name, nil
+	}
+	if p.acceptPunct("*") {
+		arg = "*"
+	} else if arg, err = p.word(); err != nil {
+		return "", "", err
+	}
+	if !p.acceptPunct(")") {
+		return "", "", p.expected("')'")
+	}
+	return name, arg, nil
+}
+
+// acceptPunct consumes the punctuation s if it is next
+func (p *parser) acceptPunct(s string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokPunct && p.tokens[p.pos].text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) next() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok, true
+}
+
+// peek reports whether the current token has the given kind
+func (p *parser) peek(kind tokenKind) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == kind
+}
+
+func (p *parser) word() (string, error) {
+	if !p.peek(tokWord) {
+		return "", p.expected("name")
+	}
+	tok, _ := p.next()
+	return tok.text, nil
+}
+
+func (p *parser) integer() (int, error) {
+	if !p.peek(tokNumber) {
+		return 0, p.expected("integer")
+	}
+	tok, _ := p.next()
+	n, err := strconv.Atoi(tok.text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer %q at position %d", tok.text, tok.pos)
+	}
+	return n, nil
+}
+
+func (p *parser) keyword(kw string) error {
+	if !p.acceptKeyword(kw) {
+		return p.expected(kw)
+	}
+	return nil
+}
+
+func (p *parser) acceptKeyword(kw string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokWord && strings.EqualFold(p.tokens[p.pos].text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expected reports what the parser wanted at the current token
+func (p *parser) expected(what string) error {
+	if p.pos >= len(p.tokens) {
+		return fmt.Errorf("expected %s at end of query", what)
+	}
+	tok := p.tokens[p.pos]
+	return fmt.Errorf("expected %s at position %d, got %q", what, tok.pos, tok.text)
+}
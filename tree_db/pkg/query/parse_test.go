@@ -0,0 +1,143 @@
+// ABOUTME: Tests for the declarative query language
+// ABOUTME: Verifies text and JSON parsing and execution of parsed queries
+
+package query
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/document"
+)
+
+func TestParseTextQuery(t *testing.T) {
+	q, err := ParseQuery("from documents WHERE policyID='X' AND parentID = NULL AND depth<=2 and title != 'O''Brien' ORDER BY pageStart DESC LIMIT 20 OFFSET 5")
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+
+	if q.Type != QueryDocument {
+		t.Errorf("Expected QueryDocument, got %d", q.Type)
+	}
+	if q.Filters["policyID"] != "X" {
+		t.Errorf("Expected policyID filter X, got %v", q.Filters["policyID"])
+	}
+	if parentID, ok := q.Filters["parentID"]; !ok || parentID != nil {
+		t.Errorf("Expected nil parentID filter, got %v (%v)", parentID, ok)
+	}
+
+	expected := []Condition{
+		{Field: "depth", Op: OpLe, Value: 2},
+		{Field: "title", Op: OpNe, Value: "O'Brien"},
+	}
+	if len(q.Conditions) != len(expected) {
+		t.Fatalf("Expected %d conditions, got %v", len(expected), q.Conditions)
+	}
+	for i, cond := range expected {
+		if q.Conditions[i] != cond {
+			t.Errorf("Condition %d: expected %v, got %v", i, cond, q.Conditions[i])
+		}
+	}
+
+	if q.OrderBy != "pageStart" || !q.Descending || q.Limit != 20 || q.Offset != 5 {
+		t.Errorf("Unexpected ordering/pagination: %+v", q)
+	}
+}
+
+func TestParseJSONQuery(t *testing.T) {
+	q, err := ParseQuery(`{"from": "versions", "where": {"policyID": "X", "createdAt": {">=": "2025-01-01"}}, "orderBy": "createdAt", "desc": true, "limit": 5}`)
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+
+	if q.Type != QueryVersion || q.Filters["policyID"] != "X" {
+		t.Errorf("Unexpected query: %+v", q)
+	}
+	if len(q.Conditions) != 1 || q.Conditions[0] != (Condition{Field: "createdAt", Op: OpGe, Value: "2025-01-01"}) {
+		t.Errorf("Unexpected conditions: %v", q.Conditions)
+	}
+	if q.OrderBy != "createdAt" || !q.Descending || q.Limit != 5 {
+		t.Errorf("Unexpected ordering/pagination: %+v", q)
+	}
+
+	// Integral JSON numbers become ints
+	q, err = ParseQuery(`{"from": "documents", "where": {"policyID": "X", "depth": {"<": 3}}}`)
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	if len(q.Conditions) != 1 || q.Conditions[0].Value != 3 || q.Limit != 100 {
+		t.Errorf("Unexpected query: %+v", q)
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	invalid := []string{
+		"",
+		"FROM tables",
+		"FROM documents WHERE",
+		"FROM documents WHERE depth 2",
+		"FROM documents WHERE depth <= ",
+		"FROM documents WHERE title = 'unterminated",
+		"FROM documents ORDER pageStart",
+		"FROM documents LIMIT ten",
+		"FROM documents LIMIT -1",
+		"FROM documents LIMIT 5 extra",
+		"FROM documents WHERE policyID = 5",
+		`{"from": "documents", "where": {"depth": {"~": 1}}}`,
+		`{"from": "documents", "select": ["title"]}`,
+	}
+	for _, input := range invalid {
+		if _, err := ParseQuery(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestExecuteParsedQuery(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	now := time.Now()
+	rootID := "root"
+	nodes := []*document.Node{{NodeID: "root", Title: "Root", PageStart: 1, PageEnd: 40}}
+	for i, page := range []int{30, 10, 20} {
+		nodes = append(nodes, &document.Node{
+			NodeID:    string(rune('a' + i)),
+			ParentID:  &rootID,
+			Title:     "Section",
+			PageStart: page,
+			PageEnd:   page + 9,
+			Depth:     1,
+		})
+	}
+	for _, n := range nodes {
+		n.PolicyID = "X"
+		n.CreatedAt = now
+		n.UpdatedAt = now
+	}
+	if err := engine.docStore.StoreDocument(&document.Document{PolicyID: "X", RootNodeID: "root"}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	q, err := ParseQuery("FROM documents WHERE policyID='X' AND parentID='root' AND pageStart >= 15 ORDER BY pageStart LIMIT 1")
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	result, err := engine.Execute(q)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Total != 2 || !result.HasMore || len(result.Documents) != 1 || result.Documents[0].NodeID != "c" {
+		t.Errorf("Expected first of 2 matches to be c, got total %d docs %v", result.Total, result.Documents)
+	}
+
+	q, err = ParseQuery("FROM documents WHERE policyID='X' AND parentID='root' AND color = 'red'")
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	if _, err := engine.Execute(q); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+}
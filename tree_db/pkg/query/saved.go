@@ -0,0 +1,401 @@
+// ABOUTME: Saved queries whose results are materialized under their own prefix
+// ABOUTME: Results refresh on demand, or lazily after change-feed events touch what they read
+
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// Prefixes for saved queries
+const (
+	PREFIX_SAVED_QUERY = uint32(9300) // Saved queries by (name)
+	PREFIX_SAVED_ROW   = uint32(9400) // Materialized rows by (name, position)
+)
+
+// ErrSavedQueryNotFound is returned when no query is saved under a name
+var ErrSavedQueryNotFound = errors.New("query: saved query not found")
+
+// RefreshPolicy decides when a saved query's results are recomputed
+type RefreshPolicy int
+
+const (
+	RefreshOnDemand RefreshPolicy = iota // Only RefreshSaved recomputes the results
+	RefreshOnChange                      // Changes to the queried store mark the results stale; the next ExecuteSaved recomputes them
+)
+
+// SavedQuery describes a named query and its materialized results
+type SavedQuery struct {
+	Name        string
+	Query       string // Text or JSON form accepted by ParseQuery
+	Refresh     RefreshPolicy
+	RefreshedAt time.Time
+	Rows        int  // Number of materialized rows
+	Stale       bool // Set by change-feed events until the next refresh
+}
+
+// SavedResult is the materialized result of a saved query
+type SavedResult struct {
+	Saved     *SavedQuery
+	Rows      []Row
+	Refreshed bool // Whether this execution recomputed the results
+}
+
+// SaveQuery registers a query under a name, replacing any query saved there,
+// and materializes its results
+func (e *Engine) SaveQuery(name, text string, refresh RefreshPolicy) (*SavedQuery, error) {
+	if name == "" {
+		return nil, fmt.Errorf("saved query needs a name")
+	}
+	if refresh != RefreshOnDemand && refresh != RefreshOnChange {
+		return nil, fmt.Errorf("unknown refresh policy: %d", refresh)
+	}
+	if _, err := ParseQuery(text); err != nil {
+		return nil, err
+	}
+
+	e.savedMu.Lock()
+	defer e.savedMu.Unlock()
+
+	return e.materialize(&SavedQuery{Name: name, Query: text, Refresh: refresh})
+}
+
+// ExecuteSaved returns the materialized results of a saved query, refreshing
+// them first if they are stale
+func (e *Engine) ExecuteSaved(name string) (*SavedResult, error) {
+	sq, err := e.GetSaved(name)
+	if err != nil {
+		return nil, err
+	}
+	if !sq.Stale {
+		return e.ReadSaved(name)
+	}
+
+	if _, err := e.RefreshSaved(name); err != nil {
+		return nil, err
+	}
+	res, err := e.ReadSaved(name)
+	if err != nil {
+		return nil, err
+	}
+	res.Refreshed = true
+	return res, nil
+}
+
+// ReadSaved returns the materialized results of a saved query as stored,
+// even when they are stale
+func (e *Engine) ReadSaved(name string) (*SavedResult, error) {
+	sq, err := e.GetSaved(name)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := e.savedRows(name)
+	if err != nil {
+		return nil, err
+	}
+	return &SavedResult{Saved: sq, Rows: rows}, nil
+}
+
+// RefreshSaved re-runs a saved query and replaces its materialized results
+func (e *Engine) RefreshSaved(name string) (*SavedQuery, error) {
+	e.savedMu.Lock()
+	defer e.savedMu.Unlock()
+
+	sq, err := e.GetSaved(name)
+	if err != nil {
+		return nil, err
+	}
+	return e.materialize(sq)
+}
+
+// GetSaved returns a saved query without its results
+func (e *Engine) GetSaved(name string) (*SavedQuery, error) {
+	val, ok, err := e.kv.Lookup(savedQueryKey(name))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSavedQueryNotFound, name)
+	}
+	return parseSavedQuery(name, val)
+}
+
+// ListSaved returns every saved query in name order
+func (e *Engine) ListSaved() ([]*SavedQuery, error) {
+	var saved []*SavedQuery
+	var parseErr error
+	start := storage.EncodeKey(PREFIX_SAVED_QUERY, nil)
+	err := storage.ScanPrefix(e.kv, start, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) != 1 {
+			return true
+		}
+		sq, err := parseSavedQuery(string(vals[0].Str), val)
+		if err != nil {
+			parseErr = err
+			return false
+		}
+		saved = append(saved, sq)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, parseErr
+}
+
+// DeleteSaved removes a saved query and its materialized results
+func (e *Engine) DeleteSaved(name string) error {
+	e.savedMu.Lock()
+	defer e.savedMu.Unlock()
+
+	tx := e.kv.Begin()
+	defer tx.Abort()
+
+	if _, ok := tx.Get(savedQueryKey(name)); !ok {
+		if err := tx.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %s", ErrSavedQueryNotFound, name)
+	}
+	tx.Del(savedQueryKey(name))
+	deleteSavedRows(tx, name)
+	return tx.Commit()
+}
+
+// WatchSaved marks RefreshOnChange queries stale as feed reports writes to
+// the stores they read, until stop is called or the feed closes. A watcher
+// that falls behind marks every such query stale and resubscribes.
+// Conversations publish no events, so conversation queries never go stale.
+func (e *Engine) WatchSaved(feed *changefeed.Feed) (stop func()) {
+	return watchFeed(feed,
+		func(ev changefeed.Event) {
+			e.markStale(func(q Query) bool { return eventTouches(queryDeps(q), ev) })
+		},
+		func() {
+			e.markStale(func(Query) bool { return true })
+		})
+}
+
+// watchFeed calls onEvent for each event of feed until stop is called or the
+// feed closes. When the subscription falls behind it resubscribes and calls
+// onLag, since events were missed. A nil feed is never watched.
+func watchFeed(feed *changefeed.Feed, onEvent func(changefeed.Event), onLag func()) (stop func()) {
+	if feed == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var mu sync.Mutex
+	sub := feed.Subscribe(nil)
+
+	go func() {
+		for {
+			mu.Lock()
+			events := sub.Events()
+			mu.Unlock()
+
+			for ev := range events {
+				onEvent(ev)
+			}
+
+			mu.Lock()
+			lagged := errors.Is(sub.Err(), changefeed.ErrLagged)
+			if lagged {
+				select {
+				case <-done:
+					lagged = false
+				default:
+					sub = feed.Subscribe(nil)
+				}
+			}
+			mu.Unlock()
+			if !lagged {
+				return
+			}
+			onLag()
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			close(done)
+			sub.Close()
+		})
+	}
+}
+
+// markStale flags the fresh RefreshOnChange queries for which affected
+// reports true; queries that no longer parse are left alone
+func (e *Engine) markStale(affected func(q Query) bool) {
+	saved, err := e.ListSaved()
+	if err != nil {
+		return
+	}
+
+	e.savedMu.Lock()
+	defer e.savedMu.Unlock()
+
+	tx := e.kv.Begin()
+	defer tx.Abort()
+
+	marked := false
+	for _, sq := range saved {
+		if sq.Refresh != RefreshOnChange || sq.Stale {
+			continue
+		}
+		q, err := ParseQuery(sq.Query)
+		if err != nil || !affected(q) {
+			continue
+		}
+		// Re-read under the lock so a refresh that just finished is not lost
+		val, ok := tx.Get(savedQueryKey(sq.Name))
+		if !ok {
+			continue
+		}
+		cur, err := parseSavedQuery(sq.Name, val)
+		if err != nil || cur.Stale {
+			continue
+		}
+		cur.Stale = true
+		setSavedQuery(tx, cur)
+		marked = true
+	}
+	if marked {
+		tx.Commit()
+	}
+}
+
+// materialize runs a saved query and stores its rows and description in one
+// transaction; callers hold savedMu
+func (e *Engine) materialize(sq *SavedQuery) (*SavedQuery, error) {
+	q, err := ParseQuery(sq.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := e.uncached().Cursor(q)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	tx := e.kv.Begin()
+	defer tx.Abort()
+
+	deleteSavedRows(tx, sq.Name)
+	n := 0
+	for cur.Next() {
+		data, err := json.Marshal(cur.Row())
+		if err != nil {
+			return nil, err
+		}
+		tx.Set(savedRowKey(sq.Name, n), data)
+		n++
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	refreshed := *sq
+	refreshed.RefreshedAt = time.Now().Truncate(time.Second) // As stored
+	refreshed.Rows = n
+	refreshed.Stale = false
+	setSavedQuery(tx, &refreshed)
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &refreshed, nil
+}
+
+// savedRows reads the materialized rows of a saved query in order
+func (e *Engine) savedRows(name string) ([]Row, error) {
+	var rows []Row
+	var decodeErr error
+	start := storage.EncodeKey(PREFIX_SAVED_ROW, []storage.Value{storage.NewBytesValue([]byte(name))})
+	err := storage.ScanPrefix(e.kv, start, func(key, val []byte) bool {
+		var row Row
+		if err := json.Unmarshal(val, &row); err != nil {
+			decodeErr = fmt.Errorf("corrupt saved row of %s: %v", name, err)
+			return false
+		}
+		rows = append(rows, row)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, decodeErr
+}
+
+// deleteSavedRows removes the materialized rows of a saved query
+func deleteSavedRows(tx storage.Txn, name string) {
+	var keys [][]byte
+	start := storage.EncodeKey(PREFIX_SAVED_ROW, []storage.Value{storage.NewBytesValue([]byte(name))})
+	storage.ScanPrefix(tx, start, func(key, val []byte) bool {
+		keys = append(keys, append([]byte(nil), key...))
+		return true
+	})
+	for _, key := range keys {
+		tx.Del(key)
+	}
+}
+
+// setSavedQuery writes a saved query's record
+func setSavedQuery(tx storage.Txn, sq *SavedQuery) {
+	stale := int64(0)
+	if sq.Stale {
+		stale = 1
+	}
+	tx.Set(savedQueryKey(sq.Name), storage.EncodeValues([]storage.Value{
+		storage.NewBytesValue([]byte(sq.Query)),
+		storage.NewInt64Value(int64(sq.Refresh)),
+		storage.NewTimeValue(sq.RefreshedAt),
+		storage.NewInt64Value(int64(sq.Rows)),
+		storage.NewInt64Value(stale),
+	}))
+}
+
+// parseSavedQuery decodes a saved query's record
+func parseSavedQuery(name string, val []byte) (*SavedQuery, error) {
+	vals, err := storage.DecodeValues(val)
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) < 5 {
+		return nil, fmt.Errorf("incomplete saved query data")
+	}
+	return &SavedQuery{
+		Name:        name,
+		Query:       string(vals[0].Str),
+		Refresh:     RefreshPolicy(vals[1].I64),
+		RefreshedAt: vals[2].Time,
+		Rows:        int(vals[3].I64),
+		Stale:       vals[4].I64 != 0,
+	}, nil
+}
+
+// savedQueryKey returns the key of a saved query's record
+func savedQueryKey(name string) []byte {
+	return storage.EncodeKey(PREFIX_SAVED_QUERY, []storage.Value{
+		storage.NewBytesValue([]byte(name)),
+	})
+}
+
+// savedRowKey returns the key of a saved query's row at a position
+func savedRowKey(name string, position int) []byte {
+	return storage.EncodeKey(PREFIX_SAVED_ROW, []storage.Value{
+		storage.NewBytesValue([]byte(name)),
+		storage.NewInt64Value(int64(position)),
+	})
+}
@@ -0,0 +1,137 @@
+// ABOUTME: Tests for saved queries and their materialized results
+// ABOUTME: Verifies on-demand refreshes, change-feed staleness and deletion
+
+package query
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/document"
+)
+
+func TestSavedQueries(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	feed := changefeed.NewFeed(0)
+	defer feed.Close()
+	engine.docStore.SetChangeFeed(feed)
+	sub := feed.Subscribe([]string{"document/"})
+	defer sub.Close()
+
+	rootID := "root"
+	nodes := []*document.Node{
+		{NodeID: rootID, PolicyID: "LCD-1", Title: "Root"},
+		{NodeID: "b", PolicyID: "LCD-1", ParentID: &rootID, Title: "B", Depth: 1},
+		{NodeID: "a", PolicyID: "LCD-1", ParentID: &rootID, Title: "A", Depth: 1},
+	}
+	if err := engine.docStore.StoreDocument(&document.Document{PolicyID: "LCD-1"}, nodes); err != nil {
+		t.Fatalf("StoreDocument failed: %v", err)
+	}
+
+	text := "FROM nodes WHERE policyID = 'LCD-1' AND depth >= 1 ORDER BY title"
+	if _, err := engine.SaveQuery("sections", text, RefreshOnDemand); err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+	sq, err := engine.SaveQuery("sections-live", text, RefreshOnChange)
+	if err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+	if sq.Rows != 2 || sq.Stale || sq.RefreshedAt.IsZero() {
+		t.Errorf("Expected 2 fresh rows materialized, got %+v", sq)
+	}
+
+	titles := func(name string) ([]string, bool) {
+		res, err := engine.ExecuteSaved(name)
+		if err != nil {
+			t.Fatalf("ExecuteSaved(%s) failed: %v", name, err)
+		}
+		var out []string
+		for _, row := range res.Rows {
+			out = append(out, row.Document.Title)
+		}
+		return out, res.Refreshed
+	}
+	if got, refreshed := titles("sections"); len(got) != 2 || got[0] != "A" || got[1] != "B" || refreshed {
+		t.Errorf("Expected materialized [A B] without a refresh, got %v (refreshed %v)", got, refreshed)
+	}
+
+	// A renamed section marks only the change-driven query stale
+	node, err := engine.docStore.GetNode("LCD-1", "a")
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	node.Title = "Z"
+	if err := engine.docStore.UpdateNode(node); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	// Handle the event as WatchSaved would, without racing its goroutine
+	ev := <-sub.Events()
+	engine.markStale(func(q Query) bool { return eventTouches(queryDeps(q), ev) })
+	if sq, _ := engine.GetSaved("sections-live"); !sq.Stale {
+		t.Error("Expected the change event to mark sections-live stale")
+	}
+	if sq, _ := engine.GetSaved("sections"); sq.Stale {
+		t.Error("Expected the on-demand query left fresh")
+	}
+
+	if got, refreshed := titles("sections-live"); len(got) != 2 || got[1] != "Z" || !refreshed {
+		t.Errorf("Expected a refresh to [B Z], got %v (refreshed %v)", got, refreshed)
+	}
+	if got, _ := titles("sections"); len(got) != 2 || got[0] != "A" {
+		t.Errorf("Expected the on-demand query unchanged, got %v", got)
+	}
+	if _, err := engine.RefreshSaved("sections"); err != nil {
+		t.Fatalf("RefreshSaved failed: %v", err)
+	}
+	if got, _ := titles("sections"); len(got) != 2 || got[1] != "Z" {
+		t.Errorf("Expected RefreshSaved to pick up the rename, got %v", got)
+	}
+
+	saved, err := engine.ListSaved()
+	if err != nil || len(saved) != 2 || saved[0].Name != "sections" || saved[0].Query != text {
+		t.Errorf("Unexpected saved queries: %v (%v)", saved, err)
+	}
+
+	if err := engine.DeleteSaved("sections"); err != nil {
+		t.Fatalf("DeleteSaved failed: %v", err)
+	}
+	if _, err := engine.ExecuteSaved("sections"); !errors.Is(err, ErrSavedQueryNotFound) {
+		t.Errorf("Expected ErrSavedQueryNotFound, got %v", err)
+	}
+	if rows, _ := engine.savedRows("sections"); len(rows) != 0 {
+		t.Errorf("Expected the deleted query's rows removed, got %d", len(rows))
+	}
+	if got, _ := titles("sections-live"); len(got) != 2 {
+		t.Errorf("Expected the other query's rows kept, got %v", got)
+	}
+
+	if _, err := engine.SaveQuery("broken", "FROM nowhere", RefreshOnDemand); err == nil {
+		t.Error("Expected an unparseable query rejected")
+	}
+}
+
+func TestWatchSavedStop(t *testing.T) {
+	engine, kv, path := setupTestEngine(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	feed := changefeed.NewFeed(0)
+	defer feed.Close()
+
+	stop := engine.WatchSaved(feed)
+	if n := feed.Subscribers(); n != 1 {
+		t.Fatalf("Expected the watcher subscribed, got %d subscribers", n)
+	}
+	stop()
+	stop()
+	if n := feed.Subscribers(); n != 0 {
+		t.Errorf("Expected stop to unsubscribe, got %d subscribers", n)
+	}
+
+	engine.WatchSaved(nil)()
+}
@@ -24,13 +24,89 @@ const (
 )
 
 // Query represents a unified query across stores
+// Filters select the store lookup; Conditions and OrderBy refine its results.
+// With GroupBy or Aggregations the query returns groups instead of rows, and
+// OrderBy, Limit and Offset apply to the groups.
 type Query struct {
-	Type       QueryType
-	Filters    map[string]interface{}
-	Limit      int
-	Offset     int
-	OrderBy    string
-	Descending bool
+	Type         QueryType
+	Filters      map[string]interface{}
+	Conditions   []Condition
+	GroupBy      []GroupKey
+	Aggregations []Aggregation
+	Limit        int
+	Offset       int
+	OrderBy      string
+	Descending   bool
+}
+
+// Aggregated reports whether the query returns groups
+func (q Query) Aggregated() bool {
+	return len(q.GroupBy) > 0 || len(q.Aggregations) > 0
+}
+
+// HistogramInterval is the bucket width of a date histogram
+type HistogramInterval string
+
+const (
+	IntervalHour  HistogramInterval = "hour"
+	IntervalDay   HistogramInterval = "day"
+	IntervalWeek  HistogramInterval = "week" // Weeks start on Monday
+	IntervalMonth HistogramInterval = "month"
+	IntervalYear  HistogramInterval = "year"
+)
+
+// GroupKey is a value rows are grouped by
+// Field is a result field or "metadata.<key>" for the row's entity metadata.
+// With an Interval the field must be a time, and rows are grouped by the UTC
+// start of its bucket, making a date histogram.
+type GroupKey struct {
+	Field    string
+	Interval HistogramInterval
+}
+
+// AggregateFunc is a function computed over the rows of each group
+type AggregateFunc string
+
+const (
+	AggCount AggregateFunc = "count"
+	AggMin   AggregateFunc = "min"
+	AggMax   AggregateFunc = "max"
+	AggAvg   AggregateFunc = "avg"
+)
+
+// Aggregation computes one value per group
+// Field names a numeric result field or "metadata.<key>" holding a number;
+// count ignores it. Rows whose field is missing or not numeric are left out.
+type Aggregation struct {
+	Func  AggregateFunc
+	Field string
+}
+
+// Group is one group of an aggregated query's rows
+type Group struct {
+	Key    []interface{}      // Values of the query's GroupBy keys, in order; nil for missing metadata
+	Count  int                // Rows in the group
+	Values map[string]float64 // Aggregation results by label; absent when no row had a number
+}
+
+// CompareOp is a comparison operator in a query condition
+type CompareOp string
+
+const (
+	OpEq CompareOp = "="
+	OpNe CompareOp = "!="
+	OpLt CompareOp = "<"
+	OpLe CompareOp = "<="
+	OpGt CompareOp = ">"
+	OpGe CompareOp = ">="
+)
+
+// Condition compares a result field with a value
+// Values are strings, numbers (int or float64), bools, times or nil.
+type Condition struct {
+	Field string
+	Op    CompareOp
+	Value interface{}
 }
 
 // JoinQuery represents a cross-store join operation
@@ -41,6 +117,25 @@ type JoinQuery struct {
 	JoinType  JoinType
 }
 
+// NodeJoin selects nodes of one policy by intersecting node metadata and cross references
+// At least one of Metadata, ReferencesTo and ReferencedBy must be set.
+type NodeJoin struct {
+	PolicyID      string
+	Metadata      map[string]string // Required node metadata key/value pairs
+	ReferencesTo  string            // Nodes must reference a node of this policy
+	ReferencedBy  string            // Nodes must be referenced from a node of this policy
+	ReferenceType string            // Restricts ReferencesTo/ReferencedBy to one reference type
+	Limit         int
+	Offset        int
+}
+
+// JoinedNode is a node matched by a NodeJoin together with its joined data
+type JoinedNode struct {
+	Node       *document.Node
+	Metadata   map[string]string
+	References []*metadata.CrossReference // References that satisfied the join
+}
+
 // JoinType defines join operation type
 type JoinType int
 
@@ -56,6 +151,7 @@ type Result struct {
 	Metadata      []*metadata.MetadataEntry
 	Conversations []*prompt.Conversation
 	Messages      []*prompt.Message
+	Groups        []*Group // Set instead of rows for aggregated queries
 	Total         int
 	HasMore       bool
 }
@@ -90,12 +186,36 @@ func NewQueryBuilder(qtype QueryType) *QueryBuilder {
 	}
 }
 
+// GroupBy adds a key to group results by
+func (qb *QueryBuilder) GroupBy(field string) *QueryBuilder {
+	qb.query.GroupBy = append(qb.query.GroupBy, GroupKey{Field: field})
+	return qb
+}
+
+// Histogram groups results by the buckets of a time field
+func (qb *QueryBuilder) Histogram(field string, interval HistogramInterval) *QueryBuilder {
+	qb.query.GroupBy = append(qb.query.GroupBy, GroupKey{Field: field, Interval: interval})
+	return qb
+}
+
+// Aggregate adds a function computed over each group
+func (qb *QueryBuilder) Aggregate(fn AggregateFunc, field string) *QueryBuilder {
+	qb.query.Aggregations = append(qb.query.Aggregations, Aggregation{Func: fn, Field: field})
+	return qb
+}
+
 // Where adds a filter condition
 func (qb *QueryBuilder) Where(key string, value interface{}) *QueryBuilder {
 	qb.query.Filters[key] = value
 	return qb
 }
 
+// Compare adds a condition checked against each result
+func (qb *QueryBuilder) Compare(field string, op CompareOp, value interface{}) *QueryBuilder {
+	qb.query.Conditions = append(qb.query.Conditions, Condition{Field: field, Op: op, Value: value})
+	return qb
+}
+
 // Limit sets the result limit
 func (qb *QueryBuilder) Limit(limit int) *QueryBuilder {
 	qb.query.Limit = limit
@@ -126,11 +246,15 @@ type TimeRange struct {
 	End   *time.Time
 }
 
-// SearchOptions for full-text search across stores
+// SearchOptions for full-text search within a policy
 type SearchOptions struct {
+	PolicyID   string
 	Query      string
-	EntityType *string
+	EntityType *string               // Only "document" is searchable (nil for documents)
+	Filter     document.SearchFilter // Structural filters on matching nodes
+	Metadata   map[string]string     // Required node metadata key/value pairs
 	Limit      int
+	Offset     int
 	MinScore   float64
 }
 
@@ -138,8 +262,41 @@ type SearchOptions struct {
 type SearchResult struct {
 	EntityType string
 	EntityID   string
+	PolicyID   string
 	Title      string
 	Snippet    string
 	Score      float64
 	Metadata   map[string]string
 }
+
+// FusionMethod selects how keyword and vector rankings are combined
+type FusionMethod int
+
+const (
+	FusionWeighted FusionMethod = iota // Weighted sum of max-normalized scores
+	FusionRRF                          // Reciprocal rank fusion
+)
+
+// HybridSearchOptions configures a combined keyword and vector search
+type HybridSearchOptions struct {
+	PolicyID      string
+	Query         string    // Keyword query ranked with BM25 (empty for vector-only)
+	Vector        []float32 // Query embedding (nil for keyword-only)
+	Limit         int
+	Fusion        FusionMethod
+	KeywordWeight float64 // Keyword share for FusionWeighted, 0..1 (default 0.5)
+	RRFK          int     // Rank constant for FusionRRF (default 60)
+}
+
+// HybridResult is a node ranked by fused keyword and vector relevance
+type HybridResult struct {
+	NodeID       string
+	PolicyID     string
+	Title        string
+	Snippet      string
+	Score        float64 // Fused score
+	KeywordScore float64 // Raw BM25 score
+	VectorScore  float64 // Cosine similarity
+	KeywordRank  int     // 1-based rank in the keyword list, 0 if absent
+	VectorRank   int     // 1-based rank in the vector list, 0 if absent
+}
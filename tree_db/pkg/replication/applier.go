@@ -0,0 +1,114 @@
+// ABOUTME: Applies a leader's WAL entries to a follower database
+// ABOUTME: Buffers each transaction until its commit marker and records the applied LSN
+
+package replication
+
+import (
+	"fmt"
+
+	"github.com/nainya/treestore/pkg/storage"
+	"github.com/nainya/treestore/pkg/wal"
+)
+
+// PREFIX_REPLICATION keys applied LSNs as (source) -> LSN
+const PREFIX_REPLICATION = uint32(9000)
+
+// Applier replays leader transactions on a follower
+// Each leader transaction is applied in one follower transaction together with
+// its commit LSN, so a restarted follower resumes exactly after the last one.
+type Applier struct {
+	kv      *storage.KV
+	key     []byte // Where the applied LSN is persisted
+	applied uint64
+	pending map[uint64][]*wal.Entry // Uncommitted operations by leader transaction ID
+}
+
+// NewApplier creates an applier for a WAL source (e.g. "leader"), loading the
+// applied LSN persisted in kv for that source
+func NewApplier(kv *storage.KV, source string) (*Applier, error) {
+	a := &Applier{
+		kv: kv,
+		key: storage.EncodeKey(PREFIX_REPLICATION, []storage.Value{
+			storage.NewBytesValue([]byte(source)),
+		}),
+		pending: make(map[uint64][]*wal.Entry),
+	}
+
+	val, ok, err := kv.Lookup(a.key)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		vals, err := storage.DecodeValues(val)
+		if err != nil || len(vals) != 1 {
+			return nil, fmt.Errorf("corrupt replication state: %v", err)
+		}
+		a.applied = vals[0].U64
+	}
+
+	return a, nil
+}
+
+// AppliedLSN returns the leader LSN of the last applied commit
+func (a *Applier) AppliedLSN() uint64 {
+	return a.applied
+}
+
+// Apply consumes one leader entry in LSN order
+// Operations are held until their transaction commits; entries at or below the
+// applied LSN are ignored so a resent stream is harmless.
+func (a *Applier) Apply(entry *wal.Entry) error {
+	if entry.LSN <= a.applied {
+		return nil
+	}
+
+	switch entry.OpType {
+	case wal.OpInsert, wal.OpInsertSealed, wal.OpDelete:
+		a.pending[entry.TxnID] = append(a.pending[entry.TxnID], entry)
+		return nil
+
+	case wal.OpCommit:
+		ops := a.pending[entry.TxnID]
+		delete(a.pending, entry.TxnID)
+		return a.commit(entry.LSN, ops)
+
+	case wal.OpBegin, wal.OpAbort:
+		// An abort following the commit comes too late to undo it here
+		delete(a.pending, entry.TxnID)
+		return nil
+
+	case wal.OpCheckpoint:
+		return nil
+	}
+
+	return fmt.Errorf("unknown WAL operation %d at LSN %d", entry.OpType, entry.LSN)
+}
+
+// Reset drops partially received transactions, e.g. before resuming a broken stream
+func (a *Applier) Reset() {
+	a.pending = make(map[uint64][]*wal.Entry)
+}
+
+// commit applies a leader transaction and records its commit LSN
+func (a *Applier) commit(lsn uint64, ops []*wal.Entry) error {
+	tx := a.kv.BeginTx()
+	for _, op := range ops {
+		switch op.OpType {
+		case wal.OpInsert:
+			tx.Set(op.Key, op.Value)
+		case wal.OpInsertSealed:
+			// Followers need the leader's keys to read these
+			tx.SetSealed(op.Key, op.Value)
+		default:
+			tx.Del(op.Key)
+		}
+	}
+	tx.Set(a.key, storage.EncodeValues([]storage.Value{storage.NewUint64Value(lsn)}))
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("apply transaction at LSN %d: %w", lsn, err)
+	}
+
+	a.applied = lsn
+	return nil
+}
@@ -0,0 +1,161 @@
+// ABOUTME: Tests for the replication applier
+// ABOUTME: Verifies commit buffering, aborts, resume after restart and duplicate suppression
+
+package replication
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+	"github.com/nainya/treestore/pkg/wal"
+)
+
+func openTestKV(t *testing.T, path string) *storage.KV {
+	t.Helper()
+	kv := &storage.KV{Path: path}
+	if err := kv.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	return kv
+}
+
+func TestApplier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "follower.db")
+	kv := openTestKV(t, path)
+
+	applier, err := NewApplier(kv, "leader")
+	if err != nil {
+		t.Fatalf("NewApplier failed: %v", err)
+	}
+
+	now := time.Now()
+	entries := []*wal.Entry{
+		{LSN: 1, TxnID: 7, OpType: wal.OpInsert, Key: []byte("a"), Value: []byte("1"), Timestamp: now},
+		{LSN: 2, TxnID: 7, OpType: wal.OpInsert, Key: []byte("b"), Value: []byte("2"), Timestamp: now},
+		{LSN: 3, TxnID: 7, OpType: wal.OpCommit, Timestamp: now},
+		{LSN: 4, TxnID: 0, OpType: wal.OpCheckpoint, Timestamp: now},
+		{LSN: 5, TxnID: 8, OpType: wal.OpDelete, Key: []byte("a"), Timestamp: now},
+	}
+	for _, e := range entries {
+		if err := applier.Apply(e); err != nil {
+			t.Fatalf("Apply(%s) failed: %v", e, err)
+		}
+	}
+
+	// Transaction 8 has not committed yet
+	if _, ok := kv.Get([]byte("a")); !ok {
+		t.Error("Uncommitted delete was applied")
+	}
+	if applier.AppliedLSN() != 3 {
+		t.Errorf("AppliedLSN = %d, want 3", applier.AppliedLSN())
+	}
+	kv.Close()
+
+	// A restarted follower resumes after the last commit and ignores resent entries
+	kv = openTestKV(t, path)
+	defer kv.Close()
+
+	applier, err = NewApplier(kv, "leader")
+	if err != nil {
+		t.Fatalf("NewApplier failed: %v", err)
+	}
+	if applier.AppliedLSN() != 3 {
+		t.Fatalf("Persisted AppliedLSN = %d, want 3", applier.AppliedLSN())
+	}
+	if other, _ := NewApplier(kv, "backup"); other.AppliedLSN() != 0 {
+		t.Errorf("Sources should track LSNs independently, got %d", other.AppliedLSN())
+	}
+
+	resent := append(entries, &wal.Entry{LSN: 6, TxnID: 8, OpType: wal.OpCommit, Timestamp: now})
+	for _, e := range resent {
+		if err := applier.Apply(e); err != nil {
+			t.Fatalf("Apply(%s) failed: %v", e, err)
+		}
+	}
+
+	if _, ok := kv.Get([]byte("a")); ok {
+		t.Error("Committed delete was not applied")
+	}
+	if val, ok := kv.Get([]byte("b")); !ok || string(val) != "2" {
+		t.Errorf("Expected b=2, got %q", val)
+	}
+	if applier.AppliedLSN() != 6 {
+		t.Errorf("AppliedLSN = %d, want 6", applier.AppliedLSN())
+	}
+}
+
+func TestApplierSealedValues(t *testing.T) {
+	keys := &storage.StaticKeys{Current: 1, Keys: map[uint32][]byte{1: make([]byte, 32)}}
+
+	// The leader seals with its keys; the follower stores the ciphertext as-is
+	leaderEnc, _ := storage.NewEncryption(keys)
+	leader := &storage.KV{Path: filepath.Join(t.TempDir(), "leader.db"), Encryption: leaderEnc}
+	if err := leader.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer leader.Close()
+	if err := leader.Set([]byte("k"), []byte("secret")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	follower := openTestKV(t, filepath.Join(t.TempDir(), "follower.db"))
+	followerEnc, _ := storage.NewEncryption(keys)
+	follower.Encryption = followerEnc
+	defer follower.Close()
+	applier, err := NewApplier(follower, "leader")
+	if err != nil {
+		t.Fatalf("NewApplier failed: %v", err)
+	}
+
+	tail, err := leader.WAL().Tail(0)
+	if err != nil {
+		t.Fatalf("Tail failed: %v", err)
+	}
+	defer tail.Close()
+	for applier.AppliedLSN() == 0 {
+		entry, err := tail.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if err := applier.Apply(entry); err != nil {
+			t.Fatalf("Apply(%s) failed: %v", entry, err)
+		}
+	}
+
+	if val, ok := follower.Get([]byte("k")); !ok || string(val) != "secret" {
+		t.Errorf("Expected the follower to decrypt the value, got %q", val)
+	}
+}
+
+func TestApplierAbortedTransaction(t *testing.T) {
+	kv := openTestKV(t, filepath.Join(t.TempDir(), "follower.db"))
+	defer kv.Close()
+
+	applier, err := NewApplier(kv, "leader")
+	if err != nil {
+		t.Fatalf("NewApplier failed: %v", err)
+	}
+
+	now := time.Now()
+	for _, e := range []*wal.Entry{
+		{LSN: 1, TxnID: 7, OpType: wal.OpBegin, Timestamp: now},
+		{LSN: 2, TxnID: 7, OpType: wal.OpInsert, Key: []byte("a"), Value: []byte("1"), Timestamp: now},
+		{LSN: 3, TxnID: 7, OpType: wal.OpAbort, Timestamp: now},
+		{LSN: 4, TxnID: 8, OpType: wal.OpBegin, Timestamp: now},
+		{LSN: 5, TxnID: 8, OpType: wal.OpInsert, Key: []byte("b"), Value: []byte("2"), Timestamp: now},
+		{LSN: 6, TxnID: 8, OpType: wal.OpCommit, Timestamp: now},
+	} {
+		if err := applier.Apply(e); err != nil {
+			t.Fatalf("Apply(%s) failed: %v", e, err)
+		}
+	}
+
+	if _, ok := kv.Get([]byte("a")); ok {
+		t.Error("Aborted insert was applied")
+	}
+	if val, ok := kv.Get([]byte("b")); !ok || string(val) != "2" {
+		t.Errorf("Expected b=2, got %q", val)
+	}
+}
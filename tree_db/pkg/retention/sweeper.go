@@ -0,0 +1,226 @@
+// ABOUTME: Retention sweeper that deletes expired conversations, tool results and trajectories
+// ABOUTME: Runs in the background in bounded batches with per-type TTLs and a dry-run mode
+
+package retention
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nainya/treestore/pkg/metadata"
+	"github.com/nainya/treestore/pkg/prompt"
+)
+
+// Entity types covered by retention
+const (
+	EntityConversation = "conversation"
+	EntityToolResult   = "tool_result"
+	EntityTrajectory   = "trajectory"
+)
+
+// Defaults applied by NewSweeper
+const (
+	DefaultInterval  = time.Hour
+	DefaultBatchSize = 100
+)
+
+// Config configures a Sweeper
+type Config struct {
+	TTLs      map[string]time.Duration // Retention per entity type; missing or zero keeps forever
+	Interval  time.Duration            // Time between background sweeps
+	BatchSize int                      // Maximum deletions per entity type per sweep
+	DryRun    bool                     // Report expired entities without deleting them
+	OnSweep   func(*SweepReport)       // Called after every sweep, e.g. to export metrics
+	Lock      sync.Locker              // Held while deleting, e.g. to keep sweeps out of maintenance; nil takes none
+}
+
+// SweepReport describes the outcome of one sweep
+type SweepReport struct {
+	StartedAt time.Time
+	Duration  time.Duration
+	DryRun    bool
+	Reclaimed map[string]int // Entities deleted (or that would be, in dry-run) per type
+	Errors    map[string]error
+}
+
+// Stats accumulates sweeper activity since it was created
+type Stats struct {
+	Sweeps    int64
+	Reclaimed map[string]int64
+	LastSweep *SweepReport
+}
+
+// Sweeper periodically deletes entities older than their configured TTL
+type Sweeper struct {
+	prompts *prompt.PromptStore
+	meta    *metadata.MetadataStore
+	cfg     Config
+
+	mu    sync.Mutex
+	stats Stats
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSweeper creates a sweeper over the prompt and metadata stores
+func NewSweeper(prompts *prompt.PromptStore, meta *metadata.MetadataStore, cfg Config) *Sweeper {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+
+	return &Sweeper{
+		prompts: prompts,
+		meta:    meta,
+		cfg:     cfg,
+		stats:   Stats{Reclaimed: make(map[string]int64)},
+	}
+}
+
+// Start runs sweeps in a background goroutine until Stop is called
+func (s *Sweeper) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run(s.stop, s.done)
+}
+
+// Stop ends background sweeping and waits for an in-progress sweep to finish
+func (s *Sweeper) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// Stats returns a snapshot of the sweeper's activity
+func (s *Sweeper) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.stats
+	snapshot.Reclaimed = make(map[string]int64, len(s.stats.Reclaimed))
+	for entityType, n := range s.stats.Reclaimed {
+		snapshot.Reclaimed[entityType] = n
+	}
+	return snapshot
+}
+
+// SweepOnce deletes at most one batch of expired entities per type, as of now
+// Errors for one entity type do not stop the others; they are collected in the report.
+func (s *Sweeper) SweepOnce(now time.Time) *SweepReport {
+	report := &SweepReport{
+		StartedAt: now,
+		DryRun:    s.cfg.DryRun,
+		Reclaimed: make(map[string]int),
+		Errors:    make(map[string]error),
+	}
+	start := time.Now()
+
+	if s.cfg.Lock != nil {
+		s.cfg.Lock.Lock()
+	}
+	for _, entityType := range []string{EntityConversation, EntityToolResult, EntityTrajectory} {
+		ttl := s.cfg.TTLs[entityType]
+		if ttl <= 0 {
+			continue
+		}
+
+		n, err := s.sweepType(entityType, now.Add(-ttl))
+		report.Reclaimed[entityType] = n
+		if err != nil {
+			report.Errors[entityType] = err
+		}
+	}
+	if s.cfg.Lock != nil {
+		s.cfg.Lock.Unlock()
+	}
+
+	report.Duration = time.Since(start)
+
+	s.mu.Lock()
+	s.stats.Sweeps++
+	for entityType, n := range report.Reclaimed {
+		s.stats.Reclaimed[entityType] += int64(n)
+	}
+	s.stats.LastSweep = report
+	s.mu.Unlock()
+
+	if s.cfg.OnSweep != nil {
+		s.cfg.OnSweep(report)
+	}
+
+	return report
+}
+
+// run sweeps on every interval tick until stop is closed
+func (s *Sweeper) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			s.SweepOnce(now)
+		}
+	}
+}
+
+// sweepType deletes one batch of expired entities of a type
+func (s *Sweeper) sweepType(entityType string, cutoff time.Time) (int, error) {
+	var ids []string
+	var err error
+
+	switch entityType {
+	case EntityConversation:
+		ids, err = s.prompts.ListExpiredConversations(cutoff, s.cfg.BatchSize)
+	case EntityToolResult, EntityTrajectory:
+		ids, err = s.meta.ListExpiredEntities(entityType, cutoff, s.cfg.BatchSize)
+	default:
+		return 0, fmt.Errorf("unknown entity type: %s", entityType)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if s.cfg.DryRun {
+		return len(ids), nil
+	}
+
+	reclaimed := 0
+	for _, id := range ids {
+		switch entityType {
+		case EntityConversation:
+			err = s.prompts.DeleteConversation(id)
+		case EntityTrajectory:
+			err = s.meta.DeleteTrajectory(id)
+		default:
+			err = s.meta.DeleteAllMetadata(entityType, id)
+		}
+		if err != nil {
+			return reclaimed, fmt.Errorf("failed to delete %s %s: %w", entityType, id, err)
+		}
+		reclaimed++
+	}
+
+	return reclaimed, nil
+}
@@ -0,0 +1,166 @@
+// ABOUTME: Tests for the retention sweeper
+// ABOUTME: Verifies TTL expiry, batch limits, dry-run mode and background runs
+
+package retention
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/metadata"
+	"github.com/nainya/treestore/pkg/prompt"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+func setupTestSweeper(t *testing.T, cfg Config) (*Sweeper, *prompt.PromptStore, *metadata.MetadataStore, func()) {
+	path := storage.MemoryPath
+	kv := &storage.KV{Path: path}
+	if err := kv.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+
+	ps := prompt.NewPromptStore(kv)
+	ms := metadata.NewMetadataStore(kv)
+	cleanup := func() {
+		kv.Close()
+		os.Remove(path)
+	}
+	return NewSweeper(ps, ms, cfg), ps, ms, cleanup
+}
+
+func seedEntities(t *testing.T, ps *prompt.PromptStore, ms *metadata.MetadataStore, now time.Time) {
+	old := now.Add(-48 * time.Hour)
+
+	for _, c := range []struct {
+		id   string
+		last time.Time
+	}{{"conv-old", old}, {"conv-active", now}} {
+		if err := ps.CreateConversation(&prompt.Conversation{ConversationID: c.id, UserID: "u", StartedAt: old, LastMessageAt: c.last}); err != nil {
+			t.Fatalf("CreateConversation failed: %v", err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := ms.SetMetadata(&metadata.MetadataEntry{
+			EntityType: EntityToolResult,
+			EntityID:   fmt.Sprintf("exec-%d", i),
+			Key:        "tool_result",
+			Value:      "data",
+			CreatedAt:  old,
+			UpdatedAt:  old,
+		}); err != nil {
+			t.Fatalf("SetMetadata failed: %v", err)
+		}
+	}
+
+	if err := ms.SetMetadata(&metadata.MetadataEntry{
+		EntityType: EntityTrajectory,
+		EntityID:   "traj-new",
+		Key:        "case_id",
+		Value:      "case-1",
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+}
+
+func TestSweepOnce(t *testing.T) {
+	now := time.Now()
+	sweeper, ps, ms, cleanup := setupTestSweeper(t, Config{
+		TTLs: map[string]time.Duration{
+			EntityConversation: 24 * time.Hour,
+			EntityToolResult:   24 * time.Hour,
+			EntityTrajectory:   24 * time.Hour,
+		},
+		BatchSize: 2,
+	})
+	defer cleanup()
+	seedEntities(t, ps, ms, now)
+
+	report := sweeper.SweepOnce(now)
+	if len(report.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", report.Errors)
+	}
+	if report.Reclaimed[EntityConversation] != 1 {
+		t.Errorf("Expected 1 conversation reclaimed, got %d", report.Reclaimed[EntityConversation])
+	}
+	if report.Reclaimed[EntityToolResult] != 2 {
+		t.Errorf("Expected batch of 2 tool results, got %d", report.Reclaimed[EntityToolResult])
+	}
+	if report.Reclaimed[EntityTrajectory] != 0 {
+		t.Errorf("Expected fresh trajectory kept, got %d", report.Reclaimed[EntityTrajectory])
+	}
+
+	if _, err := ps.GetConversation("conv-old"); err == nil {
+		t.Error("Expected conv-old deleted")
+	}
+	if _, err := ps.GetConversation("conv-active"); err != nil {
+		t.Errorf("Expected conv-active kept: %v", err)
+	}
+
+	// The next sweep picks up the remaining tool result
+	report = sweeper.SweepOnce(now)
+	if report.Reclaimed[EntityToolResult] != 1 {
+		t.Errorf("Expected remaining tool result reclaimed, got %d", report.Reclaimed[EntityToolResult])
+	}
+
+	stats := sweeper.Stats()
+	if stats.Sweeps != 2 || stats.Reclaimed[EntityToolResult] != 3 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestSweepDryRun(t *testing.T) {
+	now := time.Now()
+	sweeper, ps, ms, cleanup := setupTestSweeper(t, Config{
+		TTLs:   map[string]time.Duration{EntityToolResult: time.Hour},
+		DryRun: true,
+	})
+	defer cleanup()
+	seedEntities(t, ps, ms, now)
+
+	report := sweeper.SweepOnce(now)
+	if !report.DryRun || report.Reclaimed[EntityToolResult] != 3 {
+		t.Errorf("Expected dry-run report of 3 tool results, got %+v", report)
+	}
+	if _, ok := report.Reclaimed[EntityConversation]; ok {
+		t.Error("Expected conversations skipped without a TTL")
+	}
+
+	if attrs, _ := ms.GetAllMetadata(EntityToolResult, "exec-0"); len(attrs) != 1 {
+		t.Error("Expected dry run to keep tool results")
+	}
+}
+
+func TestSweeperBackground(t *testing.T) {
+	now := time.Now()
+	swept := make(chan *SweepReport, 1)
+	sweeper, ps, ms, cleanup := setupTestSweeper(t, Config{
+		TTLs:     map[string]time.Duration{EntityConversation: time.Hour},
+		Interval: 10 * time.Millisecond,
+		OnSweep: func(r *SweepReport) {
+			select {
+			case swept <- r:
+			default:
+			}
+		},
+	})
+	defer cleanup()
+	seedEntities(t, ps, ms, now)
+
+	sweeper.Start()
+	select {
+	case <-swept:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for background sweep")
+	}
+	sweeper.Stop()
+	sweeper.Stop()
+
+	if _, err := ps.GetConversation("conv-old"); err == nil {
+		t.Error("Expected background sweep to delete conv-old")
+	}
+}
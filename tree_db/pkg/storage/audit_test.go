@@ -0,0 +1,109 @@
+// ABOUTME: Tests for the page accounting audit
+// ABOUTME: Verifies clean files account for every page and leaks are found and reclaimed
+
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditAccountsForEveryPage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.db")
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 300; i++ {
+		if err := db.Set([]byte(fmt.Sprintf("key%03d", i)), []byte(fmt.Sprintf("value%03d", i))); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	for i := 0; i < 300; i += 2 {
+		if _, err := db.Del([]byte(fmt.Sprintf("key%03d", i))); err != nil {
+			t.Fatalf("Del failed: %v", err)
+		}
+	}
+
+	// Pages allocated and replaced within one transaction
+	tx := db.Begin()
+	for i := 0; i < 300; i += 3 {
+		tx.Set([]byte(fmt.Sprintf("key%03d", i)), []byte("rewritten"))
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	report, err := db.Audit(false)
+	if err != nil {
+		t.Fatalf("Audit failed: %v", err)
+	}
+	if len(report.Leaked) != 0 || len(report.Conflicts) != 0 {
+		t.Errorf("Clean database: leaked %v, conflicts %v", report.Leaked, report.Conflicts)
+	}
+	if total := uint64(1 + report.Tree + report.FreeList + report.Free); total != report.Pages {
+		t.Errorf("Accounted for %d of %d pages", total, report.Pages)
+	}
+	if report.Free == 0 {
+		t.Error("Deletes should leave free pages")
+	}
+}
+
+func TestAuditReclaimsLeakedPages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leak.db")
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		db.Set([]byte(fmt.Sprintf("key%03d", i)), []byte("value"))
+	}
+
+	// Persist a page nothing refers to
+	leaked := db.pageAppend(make([]byte, BTREE_PAGE_SIZE))
+	if err := db.updateOrRevert(db.saveMeta()); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	report, err := db.Audit(false)
+	if err != nil {
+		t.Fatalf("Audit failed: %v", err)
+	}
+	if len(report.Leaked) != 1 || report.Leaked[0] != leaked {
+		t.Fatalf("Leaked = %v, want [%d]", report.Leaked, leaked)
+	}
+	if report.Reclaimed != 0 {
+		t.Errorf("Audit without reclaim reclaimed %d pages", report.Reclaimed)
+	}
+	free := report.Free
+
+	report, err = db.Audit(true)
+	if err != nil {
+		t.Fatalf("Audit with reclaim failed: %v", err)
+	}
+	if report.Reclaimed != 1 {
+		t.Errorf("Reclaimed %d pages, want 1", report.Reclaimed)
+	}
+	db.Close()
+
+	db = &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer db.Close()
+
+	report, err = db.Audit(false)
+	if err != nil {
+		t.Fatalf("Audit failed: %v", err)
+	}
+	if len(report.Leaked) != 0 || len(report.Conflicts) != 0 {
+		t.Errorf("After reclaim: leaked %v, conflicts %v", report.Leaked, report.Conflicts)
+	}
+	if report.Free != free+1 {
+		t.Errorf("Free pages = %d, want %d", report.Free, free+1)
+	}
+}
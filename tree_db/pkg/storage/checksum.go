@@ -0,0 +1,181 @@
+// ABOUTME: CRC32 checksums on B+Tree, free list and meta pages
+// ABOUTME: Detects silent disk corruption on read and verifies whole database files
+
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/nainya/treestore/pkg/btree"
+)
+
+const (
+	PAGE_CHECKSUM_SIZE   = 4 // CRC32 trailer of every page, matches btree.BTREE_PAGE_TRAILER
+	pageChecksumOffset   = BTREE_PAGE_SIZE - PAGE_CHECKSUM_SIZE
+	metaGenerationOffset = 72 // After the signature, root, flushed count and free list
+	metaFormatOffset     = 80 // After the generation
+	metaWALOffset        = 84 // After the format version
+	metaChecksumOffset   = 92 // After the flushed-through WAL LSN
+	metaChecksumOffsetV4 = 84 // Format 4 meta slots had no WAL LSN
+	metaChecksumOffsetV3 = 80 // TreeStore03 meta slots had no format version
+	metaChecksumOffsetV2 = 72 // TreeStore02 meta pages had no generation
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	// ErrChecksum indicates a page whose contents do not match its checksum
+	ErrChecksum = errors.New("storage: checksum mismatch")
+
+	// ErrBadPointer indicates a tree or free list pointer past the end of the file
+	ErrBadPointer = errors.New("storage: page pointer out of range")
+
+	// ErrLegacyFormat indicates a write to a database created before page checksums
+	ErrLegacyFormat = errors.New("storage: database predates page checksums and is read-only; run treestore-admin upgrade")
+)
+
+// CorruptPageError reports a page that cannot be read: it failed checksum
+// verification or its pointer lies beyond the end of the file.
+// Reads of such a page panic with this error, since tree traversal has no
+// error path; KV methods that return errors recover it, and Verify reports it.
+type CorruptPageError struct {
+	Path string
+	Ptr  uint64 // Page number, 0 for the meta page
+	Err  error  // ErrChecksum or ErrBadPointer; nil means ErrChecksum
+}
+
+func (e *CorruptPageError) Error() string {
+	if errors.Is(e, ErrBadPointer) {
+		return fmt.Sprintf("storage: page pointer %d out of range in %s", e.Ptr, e.Path)
+	}
+	if e.Ptr == 0 {
+		return fmt.Sprintf("storage: checksum mismatch on meta page of %s", e.Path)
+	}
+	return fmt.Sprintf("storage: checksum mismatch on page %d of %s", e.Ptr, e.Path)
+}
+
+func (e *CorruptPageError) Unwrap() error {
+	if e.Err != nil {
+		return e.Err
+	}
+	return ErrChecksum
+}
+
+// recoverCorruption turns a panic caused by bad data on disk into *errp,
+// re-panicking on anything else. Corrupt pages, corrupt nodes and values that
+// cannot be decrypted are data faults; any other panic is a programming error.
+func recoverCorruption(errp *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if err := storageFault(r); err != nil {
+		*errp = err
+		return
+	}
+	panic(r)
+}
+
+// storageFault returns the error carried by a recovered panic if it reports bad data
+func storageFault(r interface{}) error {
+	switch err := r.(type) {
+	case *CorruptPageError:
+		return err
+	case *SealError:
+		return err
+	case *btree.CorruptNodeError:
+		return err
+	}
+	return nil
+}
+
+// IsCorruption reports whether err comes from data that could not be read back:
+// a corrupt page or node, or a value that cannot be decrypted. Callers that skip
+// missing records use it to tell them apart from unreadable ones.
+func IsCorruption(err error) bool {
+	var page *CorruptPageError
+	var sealed *SealError
+	var node *btree.CorruptNodeError
+	return errors.As(err, &page) || errors.As(err, &sealed) || errors.As(err, &node)
+}
+
+// stampPage writes the checksum trailer of a page about to be persisted
+func stampPage(page []byte) {
+	binary.LittleEndian.PutUint32(page[pageChecksumOffset:], crc32.Checksum(page[:pageChecksumOffset], crcTable))
+}
+
+// pageChecksumOK reports whether a page matches its checksum trailer
+func pageChecksumOK(page []byte) bool {
+	return binary.LittleEndian.Uint32(page[pageChecksumOffset:]) == crc32.Checksum(page[:pageChecksumOffset], crcTable)
+}
+
+// stampMeta writes the checksum of a meta page
+func stampMeta(meta []byte) {
+	offset := metaChecksumAt(meta)
+	binary.LittleEndian.PutUint32(meta[offset:], crc32.Checksum(meta[:offset], crcTable))
+}
+
+// metaChecksumOK reports whether a meta slot matches its checksum
+func metaChecksumOK(meta []byte) bool {
+	offset := metaChecksumAt(meta)
+	return binary.LittleEndian.Uint32(meta[offset:]) == crc32.Checksum(meta[:offset], crcTable)
+}
+
+// metaChecksumAt returns the offset of the checksum of a DB_SIG meta slot,
+// which depends on the format it records
+func metaChecksumAt(meta []byte) int {
+	if binary.LittleEndian.Uint32(meta[metaFormatOffset:]) < 5 {
+		return metaChecksumOffsetV4
+	}
+	return metaChecksumOffset
+}
+
+// metaChecksumOKV3 reports whether a TreeStore03 meta slot matches its checksum
+func metaChecksumOKV3(meta []byte) bool {
+	return binary.LittleEndian.Uint32(meta[metaChecksumOffsetV3:]) == crc32.Checksum(meta[:metaChecksumOffsetV3], crcTable)
+}
+
+// metaChecksumOKV2 reports whether a TreeStore02 meta page matches its checksum
+func metaChecksumOKV2(meta []byte) bool {
+	return binary.LittleEndian.Uint32(meta[metaChecksumOffsetV2:]) == crc32.Checksum(meta[:metaChecksumOffsetV2], crcTable)
+}
+
+// VerifyReport summarizes a full scan of the database file
+type VerifyReport struct {
+	Pages   uint64   // Pages checked, excluding the meta page
+	Corrupt []uint64 // Pages whose checksum does not match
+	Legacy  bool     // File predates checksums, so nothing could be checked
+}
+
+// Verify checks the checksum of every flushed page
+// It reads the file as of the last commit and must not run concurrently with writes.
+func (db *KV) Verify() (*VerifyReport, error) {
+	report := &VerifyReport{Legacy: db.legacy}
+	if db.legacy {
+		return report, nil
+	}
+
+	start := uint64(0)
+	for _, chunk := range db.mmap.chunks {
+		end := start + uint64(len(chunk))/BTREE_PAGE_SIZE
+		for ptr := max64(start, 1); ptr < end && ptr < db.page.flushed; ptr++ {
+			offset := BTREE_PAGE_SIZE * (ptr - start)
+			if !pageChecksumOK(chunk[offset : offset+BTREE_PAGE_SIZE]) {
+				report.Corrupt = append(report.Corrupt, ptr)
+			}
+			report.Pages++
+		}
+		start = end
+	}
+	return report, nil
+}
+
+func max64(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,331 @@
+// ABOUTME: Tests for page and meta checksums
+// ABOUTME: Verifies corruption detection, Verify reports and legacy file upgrades
+
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeChecksumDB fills a database with enough keys to span several pages
+func writeChecksumDB(t *testing.T, path string) {
+	t.Helper()
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	tx := db.Begin()
+	for i := 0; i < 500; i++ {
+		tx.Set([]byte(fmt.Sprintf("key%04d", i)), []byte(fmt.Sprintf("value%04d", i)))
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+}
+
+// flipByte corrupts one byte of the file at offset
+func flipByte(t *testing.T, path string, offset int64) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer f.Close()
+
+	b := make([]byte, 1)
+	if _, err := f.ReadAt(b, offset); err != nil {
+		t.Fatalf("ReadAt failed: %v", err)
+	}
+	b[0] ^= 0xff
+	if _, err := f.WriteAt(b, offset); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+}
+
+func TestPageChecksumDetectsCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corrupt.db")
+	writeChecksumDB(t, path)
+
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	report, err := db.Verify()
+	if err != nil || len(report.Corrupt) != 0 || report.Pages == 0 {
+		t.Fatalf("Clean database: report %+v, err %v", report, err)
+	}
+	root := db.tree.GetRoot()
+	db.Close()
+
+	flipByte(t, path, int64(root*BTREE_PAGE_SIZE)+100)
+
+	db = &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	report, err = db.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(report.Corrupt) != 1 || report.Corrupt[0] != root {
+		t.Errorf("Corrupt pages = %v, want [%d]", report.Corrupt, root)
+	}
+
+	// Reads fail with a clear error instead of misinterpreting the page
+	func() {
+		defer func() {
+			r := recover()
+			err, ok := r.(error)
+			if !ok || !errors.Is(err, ErrChecksum) {
+				t.Errorf("Get on corrupt page panicked with %v, want checksum error", r)
+			}
+		}()
+		db.Get([]byte("key0001"))
+	}()
+}
+
+// newestMeta returns the newest meta slot of a closed database
+func newestMeta(t *testing.T, path string) []byte {
+	t.Helper()
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	offset := metaSlotOffset(db.generation)
+	db.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	return data[offset : offset+META_PAGE_SIZE]
+}
+
+// writeAt overwrites part of a file
+func writeAt(t *testing.T, path string, data []byte, offset int64) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteAt(data, offset); err != nil {
+		t.Fatalf("WriteAt failed: %v", err)
+	}
+}
+
+func TestMetaChecksumDetectsCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meta.db")
+	writeChecksumDB(t, path)
+
+	// Root pointer of both slots
+	flipByte(t, path, 16)
+	flipByte(t, path, META_SLOT_B+16)
+
+	db := &KV{Path: path}
+	err := db.Open()
+	var cpe *CorruptPageError
+	if !errors.As(err, &cpe) || cpe.Ptr != 0 {
+		t.Fatalf("Open error = %v, want meta page corruption", err)
+	}
+}
+
+func TestLegacyUpgrade(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "legacy.db")
+	writeChecksumDB(t, src)
+
+	// Pages written without checksums are only distinguished by the single
+	// meta page's signature
+	meta := newestMeta(t, src)
+	copy(meta, DB_SIG_V1)
+	writeAt(t, src, meta, 0)
+
+	db := &KV{Path: src}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open legacy file: %v", err)
+	}
+	if val, ok := db.Get([]byte("key0042")); !ok || string(val) != "value0042" {
+		t.Errorf("Legacy read = %q, %v", val, ok)
+	}
+	if err := db.Set([]byte("new"), []byte("value")); !errors.Is(err, ErrLegacyFormat) {
+		t.Errorf("Legacy Set error = %v, want ErrLegacyFormat", err)
+	}
+	tx := db.Begin()
+	tx.Set([]byte("new"), []byte("value"))
+	if err := tx.Commit(); !errors.Is(err, ErrLegacyFormat) {
+		t.Errorf("Legacy Commit error = %v, want ErrLegacyFormat", err)
+	}
+	db.Close()
+
+	dst := filepath.Join(tmp, "upgraded.db")
+	copied, err := Upgrade(src, dst)
+	if err != nil {
+		t.Fatalf("Upgrade failed: %v", err)
+	}
+	if copied != 500 {
+		t.Errorf("Upgrade copied %d keys, want 500", copied)
+	}
+	if _, err := Upgrade(src, dst); err == nil {
+		t.Error("Upgrade should refuse to overwrite its target")
+	}
+
+	db = &KV{Path: dst}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open upgraded file: %v", err)
+	}
+	defer db.Close()
+
+	if val, ok := db.Get([]byte("key0499")); !ok || string(val) != "value0499" {
+		t.Errorf("Upgraded read = %q, %v", val, ok)
+	}
+	if err := db.Set([]byte("new"), []byte("value")); err != nil {
+		t.Errorf("Set on upgraded file failed: %v", err)
+	}
+	report, err := db.Verify()
+	if err != nil || len(report.Corrupt) != 0 || report.Legacy {
+		t.Errorf("Upgraded file: report %+v, err %v", report, err)
+	}
+}
+
+func TestDualMetaFallsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dual.db")
+	writeChecksumDB(t, path)
+
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	if err := db.Set([]byte("key0000"), []byte("newer")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	gen := db.generation
+	db.Close()
+
+	// Tear the newest slot, as an interrupted meta write would
+	writeAt(t, path, make([]byte, META_PAGE_SIZE/2), metaSlotOffset(gen)+META_PAGE_SIZE/2)
+
+	db = &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Open with a torn meta slot failed: %v", err)
+	}
+	defer db.Close()
+
+	// The previous slot predates the commit, so the WAL replays it and the
+	// recovered state is written over the torn slot
+	if val, ok := db.Get([]byte("key0000")); !ok || string(val) != "newer" {
+		t.Errorf("key0000 = %q, %v; want the commit replayed from the WAL", val, ok)
+	}
+	if db.generation != gen {
+		t.Errorf("Generation after recovery = %d, want %d", db.generation, gen)
+	}
+	if meta := newestMeta(t, path); !metaChecksumOK(meta) {
+		t.Error("Torn slot not rewritten by recovery")
+	}
+}
+
+func TestSingleMetaUpgradesInPlace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v2.db")
+	writeChecksumDB(t, path)
+
+	// Rewrite page 0 as a TreeStore02 file: one meta page, no generation
+	meta := newestMeta(t, path)
+	copy(meta, DB_SIG_V2)
+	binary.LittleEndian.PutUint32(meta[metaChecksumOffsetV2:], crc32.Checksum(meta[:metaChecksumOffsetV2], crcTable))
+	copy(meta[metaChecksumOffsetV2+4:], make([]byte, META_PAGE_SIZE-metaChecksumOffsetV2-4))
+	writeAt(t, path, meta, 0)
+	writeAt(t, path, make([]byte, META_PAGE_SIZE), META_SLOT_B)
+
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open single meta file: %v", err)
+	}
+	if db.generation != 0 {
+		t.Errorf("Generation = %d, want 0", db.generation)
+	}
+	if err := db.Set([]byte("upgraded"), []byte("yes")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	db.Close()
+
+	if slot := newestMeta(t, path); string(slot[:16]) != DB_SIG {
+		t.Errorf("Newest slot signature = %q, want %q", slot[:16], DB_SIG)
+	}
+
+	db = &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer db.Close()
+	for _, key := range []string{"key0123", "upgraded"} {
+		if _, ok := db.Get([]byte(key)); !ok {
+			t.Errorf("%s missing after in-place upgrade", key)
+		}
+	}
+}
+
+func TestCorruptPageReturnsErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corrupt.db")
+	writeChecksumDB(t, path)
+
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	root := db.tree.GetRoot()
+	db.Close()
+	flipByte(t, path, int64(root*BTREE_PAGE_SIZE)+100)
+
+	db = &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	if _, _, err := db.Lookup([]byte("key0001")); !errors.Is(err, ErrChecksum) {
+		t.Errorf("Lookup: got %v, want checksum error", err)
+	}
+	if _, _, err := db.LookupBatch([][]byte{[]byte("key0001")}); !errors.Is(err, ErrChecksum) {
+		t.Errorf("LookupBatch: got %v, want checksum error", err)
+	}
+	if err := db.Scan([]byte("key"), func(key, val []byte) bool { return true }); !errors.Is(err, ErrChecksum) {
+		t.Errorf("Scan: got %v, want checksum error", err)
+	}
+	if err := db.Set([]byte("key0001"), []byte("new")); !errors.Is(err, ErrChecksum) {
+		t.Errorf("Set: got %v, want checksum error", err)
+	}
+	if _, err := db.Del([]byte("key0001")); !errors.Is(err, ErrChecksum) {
+		t.Errorf("Del: got %v, want checksum error", err)
+	}
+
+	// A failed read or write poisons its transaction, and nothing reaches the WAL
+	lsn := db.wal.LastLSN()
+	tx := db.Begin()
+	if _, ok := tx.Get([]byte("key0002")); ok {
+		t.Error("Get on a corrupt page reported the key as found")
+	}
+	tx.Set([]byte("key0002"), []byte("new"))
+	tx.Del([]byte("key0003"))
+	if !errors.Is(tx.Err(), ErrChecksum) {
+		t.Errorf("Err: got %v, want checksum error", tx.Err())
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrChecksum) {
+		t.Errorf("Commit: got %v, want checksum error", err)
+	}
+	if db.wal.LastLSN() != lsn {
+		t.Errorf("Failed writes were logged: LSN %d, want %d", db.wal.LastLSN(), lsn)
+	}
+	if db.tree.GetRoot() != root {
+		t.Errorf("Root changed to %d after failed writes, want %d", db.tree.GetRoot(), root)
+	}
+}
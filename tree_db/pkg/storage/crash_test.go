@@ -0,0 +1,243 @@
+// ABOUTME: Crash-safety tests using commit failpoints
+// ABOUTME: Kills or fails a commit at every phase and checks reopen sees the old or new state
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const (
+	// crashEnv tells a re-executed test binary to commit and die at "<phase>:<hit>:<path>"
+	crashEnv       = "TREESTORE_CRASH_AT"
+	crashExitCode  = 42
+	crashKeyCount  = 200
+	crashNewKeys   = 50
+	crashDeletedAt = 190 // Keys from here on are deleted by the new state
+)
+
+var errInjected = errors.New("injected fault")
+
+func crashKey(prefix string, i int) []byte {
+	return []byte(fmt.Sprintf("%s%03d", prefix, i))
+}
+
+// writeOldState fills a fresh database with the state every test starts from
+func writeOldState(t *testing.T, path string) {
+	t.Helper()
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	// Written twice so the free list holds pages the next commit will reuse
+	for _, fill := range []string{"-", "x"} {
+		tx := db.Begin()
+		for i := 0; i < crashKeyCount; i++ {
+			tx.Set(crashKey("k", i), []byte(fmt.Sprintf("old-%03d-%s", i, strings.Repeat(fill, 64))))
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("Failed to commit old state: %v", err)
+		}
+	}
+}
+
+// commitNewState rewrites every key, adds new ones and deletes a tail in one transaction
+func commitNewState(db *KV) error {
+	tx := db.Begin()
+	for i := 0; i < crashKeyCount; i++ {
+		tx.Set(crashKey("k", i), []byte(fmt.Sprintf("new-%03d-%s", i, strings.Repeat("y", 64))))
+	}
+	for i := 0; i < crashNewKeys; i++ {
+		tx.Set(crashKey("n", i), []byte("new"))
+	}
+	for i := crashDeletedAt; i < crashKeyCount; i++ {
+		tx.Del(crashKey("k", i))
+	}
+	return tx.Commit()
+}
+
+// committedState reports whether db holds exactly the old or the new state
+func committedState(db *KV) (string, error) {
+	got := make(map[string]string)
+	db.Scan(nil, func(key, val []byte) bool {
+		if len(key) == 0 {
+			return true // B+Tree sentinel
+		}
+		got[string(key)] = string(val)
+		return true
+	})
+
+	matches := func(state string) bool {
+		want := crashKeyCount
+		if state == "new" {
+			want = crashDeletedAt + crashNewKeys
+		}
+		if len(got) != want {
+			return false
+		}
+		for i := 0; i < crashKeyCount; i++ {
+			val, ok := got[string(crashKey("k", i))]
+			if state == "new" && i >= crashDeletedAt {
+				if ok {
+					return false
+				}
+				continue
+			}
+			if !ok || !strings.HasPrefix(val, fmt.Sprintf("%s-%03d-", state, i)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case matches("old"):
+		return "old", nil
+	case matches("new"):
+		return "new", nil
+	default:
+		return "", fmt.Errorf("torn state: %d keys visible", len(got))
+	}
+}
+
+// failAt returns a failpoint that runs action on the hit-th time phase is reached
+func failAt(phase CommitPhase, hit int, action func() error) Failpoint {
+	seen := 0
+	return func(p CommitPhase) error {
+		if p != phase {
+			return nil
+		}
+		seen++
+		if seen == hit {
+			return action()
+		}
+		return nil
+	}
+}
+
+// runCrashChild is the body of the re-executed process: commit, dying mid-way
+func runCrashChild(spec string) {
+	parts := strings.SplitN(spec, ":", 3)
+	phase, _ := strconv.Atoi(parts[0])
+	hit, _ := strconv.Atoi(parts[1])
+
+	db := &KV{Path: parts[2]}
+	if err := db.Open(); err != nil {
+		fmt.Fprintf(os.Stderr, "open: %v\n", err)
+		os.Exit(1)
+	}
+	db.SetFailpoint(failAt(CommitPhase(phase), hit, func() error {
+		os.Exit(crashExitCode)
+		return nil
+	}))
+	if err := commitNewState(db); err != nil {
+		fmt.Fprintf(os.Stderr, "commit: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The phase was reached fewer than hit times
+	os.Exit(0)
+}
+
+func TestCrashDuringCommit(t *testing.T) {
+	if spec := os.Getenv(crashEnv); spec != "" {
+		runCrashChild(spec)
+		return
+	}
+
+	for _, phase := range CommitPhases {
+		// Page writes repeat within a commit, so also die part-way through them
+		hits := []int{1}
+		if phase == PhaseWritePage {
+			hits = []int{1, 2, 5, 20}
+		}
+		for _, hit := range hits {
+			t.Run(fmt.Sprintf("%s/%d", phase, hit), func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "crash.db")
+				writeOldState(t, path)
+
+				cmd := exec.Command(os.Args[0], "-test.run=^TestCrashDuringCommit$")
+				cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%d:%d:%s", crashEnv, phase, hit, path))
+				out, err := cmd.CombinedOutput()
+				var exitErr *exec.ExitError
+				if err == nil {
+					t.Skipf("commit reached %s fewer than %d times", phase, hit)
+				}
+				if !errors.As(err, &exitErr) || exitErr.ExitCode() != crashExitCode {
+					t.Fatalf("Child failed before crashing: %v\n%s", err, out)
+				}
+
+				db := &KV{Path: path}
+				if err := db.Open(); err != nil {
+					t.Fatalf("Reopen after crash failed: %v", err)
+				}
+				defer db.Close()
+
+				state, err := committedState(db)
+				if err != nil {
+					t.Fatalf("After crash at %s: %v", phase, err)
+				}
+
+				// The recovered database must keep accepting commits
+				if err := commitNewState(db); err != nil {
+					t.Fatalf("Commit after recovery (%s) failed: %v", state, err)
+				}
+				if state, err := committedState(db); err != nil || state != "new" {
+					t.Errorf("After recommit: state %q, %v", state, err)
+				}
+			})
+		}
+	}
+}
+
+func TestCommitFailpointReverts(t *testing.T) {
+	for _, phase := range CommitPhases {
+		t.Run(phase.String(), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fail.db")
+			writeOldState(t, path)
+
+			db := &KV{Path: path}
+			if err := db.Open(); err != nil {
+				t.Fatalf("Failed to open: %v", err)
+			}
+
+			db.SetFailpoint(failAt(phase, 1, func() error { return errInjected }))
+			if err := commitNewState(db); !errors.Is(err, errInjected) {
+				t.Fatalf("Commit error = %v, want injected fault", err)
+			}
+			if state, err := committedState(db); err != nil || state != "old" {
+				t.Fatalf("After failed commit: state %q, %v; want old", state, err)
+			}
+
+			// The next commit must repair whatever the failed one left on disk
+			db.SetFailpoint(nil)
+			if err := db.Set([]byte("after"), []byte("ok")); err != nil {
+				t.Fatalf("Set after failed commit: %v", err)
+			}
+			db.Close()
+
+			db = &KV{Path: path}
+			if err := db.Open(); err != nil {
+				t.Fatalf("Reopen failed: %v", err)
+			}
+			defer db.Close()
+
+			if val, ok := db.Get([]byte("after")); !ok || string(val) != "ok" {
+				t.Errorf("Write after failed commit lost: %q, %v", val, ok)
+			}
+			db.Del([]byte("after"))
+			if _, err := committedState(db); err != nil {
+				t.Errorf("After reopen: %v", err)
+			}
+		})
+	}
+}
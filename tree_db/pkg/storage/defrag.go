@@ -0,0 +1,149 @@
+// ABOUTME: Leaf fragmentation measurement and incremental defragmentation
+// ABOUTME: Rewrites the most scattered run of leaves onto consecutive pages in one commit
+
+package storage
+
+import "time"
+
+// FragmentationReport describes how far the tree's leaves are from being laid
+// out in key order on consecutive pages
+type FragmentationReport struct {
+	Pages  uint64 // Pages in the file, including the meta page
+	Leaves int    // Leaf pages reachable from the root
+	Breaks int    // Leaves not on the page after the previous leaf in key order
+}
+
+// Ratio returns the share of leaves that break the run before them, from 0
+// for a tree whose leaves are all consecutive to 1 when none are
+func (r FragmentationReport) Ratio() float64 {
+	if r.Leaves < 2 {
+		return 0
+	}
+	return float64(r.Breaks) / float64(r.Leaves-1)
+}
+
+// DefragReport describes one call to Defragment
+type DefragReport struct {
+	Before FragmentationReport
+	After  FragmentationReport
+	Moved  int // Leaves copied onto new pages
+}
+
+// Fragmentation walks the tree's leaves and counts the breaks between them
+func (db *KV) Fragmentation() (report FragmentationReport, err error) {
+	defer recoverCorruption(&err)
+	_, report = db.leafLayout()
+	return report, nil
+}
+
+// leafLayout returns the tree's leaf pages in key order with their fragmentation
+func (db *KV) leafLayout() ([]uint64, FragmentationReport) {
+	db.treeMu.RLock()
+	defer db.treeMu.RUnlock()
+
+	var leaves []uint64
+	report := FragmentationReport{Pages: db.page.flushed}
+	db.tree.Leaves(func(ptr uint64) {
+		if n := len(leaves); n > 0 && ptr != leaves[n-1]+1 {
+			report.Breaks++
+		}
+		leaves = append(leaves, ptr)
+	})
+	report.Leaves = len(leaves)
+	return leaves, report
+}
+
+// Defragment copies the run of at most maxLeaves consecutive leaves, in key
+// order, whose copy removes the most breaks onto new pages at the end of the
+// file, followed by the internal nodes above them, and commits the result.
+// The pages left behind go to the free list, where later writes reuse them;
+// until then the file is larger by the pages copied. It does nothing when no
+// run gains more breaks than its copy opens at its ends. Like Audit it must
+// not run concurrently with writes or inside an open transaction.
+func (db *KV) Defragment(maxLeaves int) (report *DefragReport, err error) {
+	if db.legacy {
+		return nil, ErrLegacyFormat
+	}
+	defer recoverCorruption(&err)
+
+	leaves, before := db.leafLayout()
+	report = &DefragReport{Before: before, After: before}
+	start, end := worstRun(leaves, maxLeaves)
+	if start == end {
+		return report, nil
+	}
+	move := make(map[uint64]bool, end-start)
+	for _, ptr := range leaves[start:end] {
+		move[ptr] = true
+	}
+
+	db.writer.Lock()
+	db.flush.mu.Lock()
+	meta := db.treeMeta()
+	if err := db.mutate(meta, func() {
+		report.Moved = db.tree.Relocate(func(ptr uint64) bool { return move[ptr] }, db.pageAppend)
+	}); err != nil {
+		db.flush.mu.Unlock()
+		db.writer.Unlock()
+		return report, err
+	}
+	done, err := db.commitLocked(meta, db.flush.epoch, nil)
+	db.flush.mu.Unlock()
+	db.writer.Unlock()
+	if err != nil {
+		report.Moved = 0
+		return report, err
+	}
+	if err := db.awaitFlush(done); err != nil {
+		return report, err
+	}
+
+	_, report.After = db.leafLayout()
+	return report, nil
+}
+
+// worstRun returns the run of at most n leaves whose copy onto consecutive
+// pages removes the most breaks, net of the breaks it opens at its ends, or
+// an empty run when none removes any
+func worstRun(leaves []uint64, n int) (start, end int) {
+	if n <= 0 || n > len(leaves) {
+		n = len(leaves)
+	}
+	broken := func(i int) int {
+		if i > 0 && i < len(leaves) && leaves[i] != leaves[i-1]+1 {
+			return 1
+		}
+		return 0
+	}
+
+	// Breaks within the first run, then slide it one leaf at a time
+	inside := 0
+	for i := 1; i < n; i++ {
+		inside += broken(i)
+	}
+	best := 0
+	for s := 0; s+n <= len(leaves); s++ {
+		if s > 0 {
+			inside += broken(s+n-1) - broken(s)
+		}
+		gain := inside
+		if s > 0 {
+			gain -= 1 - broken(s)
+		}
+		if s+n < len(leaves) {
+			gain -= 1 - broken(s+n)
+		}
+		if gain > best {
+			best, start, end = gain, s, s+n
+		}
+	}
+	return start, end
+}
+
+// LastWrite returns when a commit last changed a key, zero if none has since
+// Open; maintenance such as Defragment uses it to wait for idle periods
+func (db *KV) LastWrite() time.Time {
+	db.flush.mu.Lock()
+	defer db.flush.mu.Unlock()
+	return db.flush.lastWrite
+}
@@ -0,0 +1,141 @@
+// ABOUTME: Tests for leaf fragmentation measurement and defragmentation
+// ABOUTME: Verifies scattered leaves are rewritten in key order without losing data or pages
+
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// scatterLeaves writes keys in an order that splits leaves all over the file
+func scatterLeaves(t *testing.T, db *KV, n int) {
+	t.Helper()
+	for round := 0; round < 4; round++ {
+		for i := round; i < n; i += 4 {
+			key := fmt.Sprintf("key%05d", (i*7919)%n)
+			if err := db.Set([]byte(key), []byte(fmt.Sprintf("value-%s-%d", key, round))); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+		}
+	}
+}
+
+func TestDefragmentRewritesLeavesInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "defrag.db")
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	scatterLeaves(t, db, 2000)
+	want := map[string]string{}
+	db.Scan(nil, func(key, val []byte) bool {
+		want[string(key)] = string(val)
+		return true
+	})
+
+	before, err := db.Fragmentation()
+	if err != nil {
+		t.Fatalf("Fragmentation failed: %v", err)
+	}
+	if before.Ratio() < 0.5 {
+		t.Fatalf("Expected scattered leaves, got %+v", before)
+	}
+
+	report, err := db.Defragment(0)
+	if err != nil {
+		t.Fatalf("Defragment failed: %v", err)
+	}
+	if report.Moved != before.Leaves || report.After.Breaks != 0 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	audit, err := db.Audit(false)
+	if err != nil {
+		t.Fatalf("Audit failed: %v", err)
+	}
+	if len(audit.Leaked) != 0 || len(audit.Conflicts) != 0 {
+		t.Errorf("Leaked %v, conflicts %v", audit.Leaked, audit.Conflicts)
+	}
+	db.Close()
+
+	// The rewritten tree is what the file holds
+	db = &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	defer db.Close()
+	got := 0
+	db.Scan(nil, func(key, val []byte) bool {
+		if want[string(key)] != string(val) {
+			t.Errorf("Key %s = %q, want %q", key, val, want[string(key)])
+		}
+		got++
+		return true
+	})
+	if got != len(want) {
+		t.Errorf("Scanned %d keys, want %d", got, len(want))
+	}
+	if after, _ := db.Fragmentation(); after.Breaks != 0 {
+		t.Errorf("Reopened file has %d breaks", after.Breaks)
+	}
+}
+
+func TestDefragmentBoundedRun(t *testing.T) {
+	db := &KV{Path: filepath.Join(t.TempDir(), "bounded.db")}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+	scatterLeaves(t, db, 2000)
+
+	// Each pass moves at most the run asked for and removes breaks
+	for pass := 0; pass < 3; pass++ {
+		report, err := db.Defragment(8)
+		if err != nil {
+			t.Fatalf("Defragment failed: %v", err)
+		}
+		if report.Moved == 0 || report.Moved > 8 {
+			t.Fatalf("Pass %d moved %d leaves", pass, report.Moved)
+		}
+		if report.After.Breaks >= report.Before.Breaks {
+			t.Errorf("Pass %d: breaks %d -> %d", pass, report.Before.Breaks, report.After.Breaks)
+		}
+	}
+
+	// A defragmented tree is left alone
+	if _, err := db.Defragment(0); err != nil {
+		t.Fatalf("Defragment failed: %v", err)
+	}
+	report, err := db.Defragment(8)
+	if err != nil {
+		t.Fatalf("Defragment failed: %v", err)
+	}
+	if report.Moved != 0 {
+		t.Errorf("Moved %d leaves of a defragmented tree", report.Moved)
+	}
+}
+
+func TestLastWriteIgnoresDefragment(t *testing.T) {
+	db := &KV{Path: filepath.Join(t.TempDir(), "idle.db")}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	if !db.LastWrite().IsZero() {
+		t.Error("Expected no write before the first commit")
+	}
+	scatterLeaves(t, db, 500)
+	last := db.LastWrite()
+	if last.IsZero() {
+		t.Fatal("Expected the writes to be recorded")
+	}
+	if _, err := db.Defragment(0); err != nil {
+		t.Fatalf("Defragment failed: %v", err)
+	}
+	if !db.LastWrite().Equal(last) {
+		t.Error("Defragment should not count as a write")
+	}
+}
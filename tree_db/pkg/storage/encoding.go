@@ -131,6 +131,12 @@ func unescapeString(s []byte) []byte {
 
 // DecodeValues decodes values from encoded format
 func DecodeValues(data []byte) ([]Value, error) {
+	return DecodeValuesSkipping(data, nil)
+}
+
+// DecodeValuesSkipping decodes values like DecodeValues, but leaves the bytes
+// of the values at positions skip reports empty, without copying them out
+func DecodeValuesSkipping(data []byte, skip func(i int) bool) ([]Value, error) {
 	vals := make([]Value, 0, 4)
 	pos := 0
 
@@ -178,6 +184,11 @@ func DecodeValues(data []byte) ([]Value, error) {
 			if end >= len(data) {
 				return nil, fmt.Errorf("unterminated string at pos %d", pos)
 			}
+			if skip != nil && skip(len(vals)) {
+				vals = append(vals, NewBytesValue(nil))
+				pos = end + 1
+				continue
+			}
 			str := unescapeString(data[pos:end])
 			vals = append(vals, NewBytesValue(str))
 			pos = end + 1 // Skip null terminator
@@ -239,3 +239,29 @@ func TestPartialKeyEncoding(t *testing.T) {
 		t.Error("Expected fullKey < key1")
 	}
 }
+
+func TestDecodeValuesSkipping(t *testing.T) {
+	data := EncodeValues([]Value{
+		NewBytesValue([]byte("kept")),
+		NewBytesValue([]byte("skipped text")),
+		NewInt64Value(7),
+	})
+
+	vals, err := DecodeValuesSkipping(data, func(i int) bool { return i == 1 || i == 2 })
+	if err != nil {
+		t.Fatalf("DecodeValuesSkipping failed: %v", err)
+	}
+	if len(vals) != 3 {
+		t.Fatalf("Expected 3 values, got %d", len(vals))
+	}
+	if string(vals[0].Str) != "kept" {
+		t.Errorf("Expected the first value decoded, got %q", vals[0].Str)
+	}
+	if vals[1].Type != TYPE_BYTES || len(vals[1].Str) != 0 {
+		t.Errorf("Expected the skipped string empty, got %+v", vals[1])
+	}
+	// Only strings are skipped; integers are cheap to decode
+	if vals[2].I64 != 7 {
+		t.Errorf("Expected the integer decoded, got %+v", vals[2])
+	}
+}
@@ -0,0 +1,293 @@
+// ABOUTME: Optional AES-GCM encryption of stored values and their WAL payloads
+// ABOUTME: Keys come from a KeyProvider; each value names its key so rotation re-encrypts on write
+
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nainya/treestore/pkg/wal"
+)
+
+// EncryptionKeysEnv names the environment variable read by EncryptionFromEnv
+// Its value is a comma-separated list of id:base64-key pairs, current key first,
+// e.g. "2:<new key>,1:<old key>". Older keys are needed until every value
+// sealed with them has been rewritten.
+const EncryptionKeysEnv = "TREESTORE_ENCRYPTION_KEYS"
+
+// Sealed value layout: [version(1)] [key ID(4)] [nonce(12)] [ciphertext] [GCM tag(16)]
+const (
+	sealVersion   = 1
+	sealHeader    = 1 + 4
+	sealNonceSize = 12
+	sealTagSize   = 16
+
+	// SealOverhead is the number of bytes encryption adds to a value
+	SealOverhead = sealHeader + sealNonceSize + sealTagSize
+)
+
+var (
+	// ErrUnknownKey indicates a key ID the KeyProvider does not have
+	ErrUnknownKey = errors.New("storage: unknown encryption key")
+
+	// ErrNotEncrypted indicates an encrypted value read without an Encryption configured
+	ErrNotEncrypted = errors.New("storage: value is encrypted but no encryption keys are configured")
+
+	// ErrSealedValue indicates an encrypted value that is malformed or fails authentication
+	ErrSealedValue = errors.New("storage: encrypted value failed authentication")
+)
+
+// KeyProvider supplies the AES keys (16, 24 or 32 bytes) used to seal values
+// Implementations may fetch or unwrap keys from a KMS; keys are cached once
+// loaded, so each ID must always map to the same key.
+type KeyProvider interface {
+	// CurrentKey returns the key new values are sealed with
+	CurrentKey() (id uint32, key []byte, err error)
+
+	// Key returns a key by ID, for values sealed before a rotation
+	Key(id uint32) ([]byte, error)
+}
+
+// StaticKeys is a KeyProvider over keys held in memory
+type StaticKeys struct {
+	Current uint32
+	Keys    map[uint32][]byte
+}
+
+// CurrentKey returns the key with ID Current
+func (s *StaticKeys) CurrentKey() (uint32, []byte, error) {
+	key, err := s.Key(s.Current)
+	return s.Current, key, err
+}
+
+// Key returns a key by ID
+func (s *StaticKeys) Key(id uint32) ([]byte, error) {
+	key, ok := s.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w %d", ErrUnknownKey, id)
+	}
+	return key, nil
+}
+
+// ParseKeys parses a key list in the format of EncryptionKeysEnv
+func ParseKeys(list string) (*StaticKeys, error) {
+	keys := &StaticKeys{Keys: make(map[uint32][]byte)}
+	for i, entry := range strings.Split(list, ",") {
+		idText, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("encryption key %d: want id:base64-key", i)
+		}
+		id, err := strconv.ParseUint(idText, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %d: bad id %q", i, idText)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %d: %w", id, err)
+		}
+		if _, dup := keys.Keys[uint32(id)]; dup {
+			return nil, fmt.Errorf("encryption key %d is listed twice", id)
+		}
+		if i == 0 {
+			keys.Current = uint32(id)
+		}
+		keys.Keys[uint32(id)] = key
+	}
+	return keys, nil
+}
+
+// EncryptionFromEnv returns an Encryption over the keys in EncryptionKeysEnv,
+// or nil when it is unset
+func EncryptionFromEnv() (*Encryption, error) {
+	list := os.Getenv(EncryptionKeysEnv)
+	if list == "" {
+		return nil, nil
+	}
+	keys, err := ParseKeys(list)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", EncryptionKeysEnv, err)
+	}
+	return NewEncryption(keys)
+}
+
+// Encryption seals values with AES-GCM under a KeyProvider's current key
+// Each sealed value records its key ID, so after a rotation old values stay
+// readable and are re-encrypted with the new key when next written.
+type Encryption struct {
+	provider KeyProvider
+
+	mu      sync.RWMutex
+	current uint32
+	aeads   map[uint32]cipher.AEAD
+}
+
+// NewEncryption loads the provider's current key
+func NewEncryption(provider KeyProvider) (*Encryption, error) {
+	e := &Encryption{
+		provider: provider,
+		aeads:    make(map[uint32]cipher.AEAD),
+	}
+	if err := e.Rotate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Rotate reloads the provider's current key, which seals every later write
+func (e *Encryption) Rotate() error {
+	id, key, err := e.provider.CurrentKey()
+	if err != nil {
+		return fmt.Errorf("load current encryption key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return fmt.Errorf("encryption key %d: %w", id, err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.current = id
+	e.aeads[id] = aead
+	return nil
+}
+
+// KeyID returns the ID of the key new values are sealed with
+func (e *Encryption) KeyID() uint32 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.current
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// aead returns the cipher for a key ID, loading it from the provider once
+func (e *Encryption) aead(id uint32) (cipher.AEAD, error) {
+	e.mu.RLock()
+	aead, ok := e.aeads[id]
+	e.mu.RUnlock()
+	if ok {
+		return aead, nil
+	}
+
+	key, err := e.provider.Key(id)
+	if err != nil {
+		return nil, err
+	}
+	if aead, err = newAEAD(key); err != nil {
+		return nil, fmt.Errorf("encryption key %d: %w", id, err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.aeads[id] = aead
+	return aead, nil
+}
+
+// seal encrypts a value under the current key
+// The record key is authenticated too, so a value cannot be moved to another key.
+func (e *Encryption) seal(key, val []byte) []byte {
+	e.mu.RLock()
+	id, aead := e.current, e.aeads[e.current]
+	e.mu.RUnlock()
+
+	out := make([]byte, sealHeader+sealNonceSize, SealOverhead+len(val))
+	out[0] = sealVersion
+	binary.LittleEndian.PutUint32(out[1:], id)
+	nonce := out[sealHeader:]
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("storage: read nonce: %v", err))
+	}
+	return aead.Seal(out, nonce, val, key)
+}
+
+// open decrypts a value sealed under any key the provider still has
+func (e *Encryption) open(key, sealed []byte) ([]byte, error) {
+	if len(sealed) < SealOverhead || sealed[0] != sealVersion {
+		return nil, ErrSealedValue
+	}
+	aead, err := e.aead(binary.LittleEndian.Uint32(sealed[1:]))
+	if err != nil {
+		return nil, err
+	}
+	nonce := sealed[sealHeader : sealHeader+sealNonceSize]
+	val, err := aead.Open(nil, nonce, sealed[sealHeader+sealNonceSize:], key)
+	if err != nil {
+		return nil, ErrSealedValue
+	}
+	return val, nil
+}
+
+// SealedKeyID returns the ID of the key a sealed value was encrypted with
+func SealedKeyID(sealed []byte) (uint32, bool) {
+	if len(sealed) < SealOverhead || sealed[0] != sealVersion {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(sealed[1:]), true
+}
+
+// SealError reports a value that could not be decrypted
+// Reads panic with this error, like CorruptPageError, since they have no error path.
+type SealError struct {
+	Key []byte
+	Err error
+}
+
+func (e *SealError) Error() string {
+	return fmt.Sprintf("storage: cannot decrypt value of key %x: %v", e.Key, e.Err)
+}
+
+func (e *SealError) Unwrap() error {
+	return e.Err
+}
+
+// unseal returns the plaintext of a value read from the tree
+// The caller owns it: a plaintext value is copied out of its page, which a
+// later commit may reuse once readers have let go of treeMu.
+func (db *KV) unseal(key, val []byte, sealed bool) []byte {
+	if !sealed {
+		return bytes.Clone(val)
+	}
+	if db.Encryption == nil {
+		panic(&SealError{Key: key, Err: ErrNotEncrypted})
+	}
+	plain, err := db.Encryption.open(key, val)
+	if err != nil {
+		panic(&SealError{Key: key, Err: err})
+	}
+	return plain
+}
+
+// insertEntry returns the WAL entry that stores val under key, sealing it
+// when encryption is configured
+func (db *KV) insertEntry(key, val []byte) wal.Entry {
+	if db.Encryption == nil {
+		return wal.Entry{OpType: wal.OpInsert, Key: key, Value: val}
+	}
+	return wal.Entry{OpType: wal.OpInsertSealed, Key: key, Value: db.Encryption.seal(key, val)}
+}
+
+// applyInsert writes an insert entry's value to the tree, keeping its seal
+func (db *KV) applyInsert(op wal.OpType, key, val []byte) {
+	if op == wal.OpInsertSealed {
+		db.tree.InsertSealed(key, val)
+	} else {
+		db.tree.Insert(key, val)
+	}
+}
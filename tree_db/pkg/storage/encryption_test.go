@@ -0,0 +1,186 @@
+// ABOUTME: Tests for encryption of stored values
+// ABOUTME: Verifies sealing on disk and in the WAL, lazy key rotation and missing keys
+
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKeys(current uint32, ids ...uint32) *StaticKeys {
+	keys := &StaticKeys{Current: current, Keys: make(map[uint32][]byte)}
+	for _, id := range ids {
+		keys.Keys[id] = bytes.Repeat([]byte{byte(id)}, 32)
+	}
+	return keys
+}
+
+func openEncrypted(t *testing.T, path string, keys KeyProvider) *KV {
+	db := &KV{Path: path}
+	if keys != nil {
+		enc, err := NewEncryption(keys)
+		if err != nil {
+			t.Fatalf("NewEncryption failed: %v", err)
+		}
+		db.Encryption = enc
+	}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	return db
+}
+
+// storedKeyID returns the key ID a value is sealed with, or 0 if it is plaintext
+func storedKeyID(db *KV, key []byte) uint32 {
+	tx := db.BeginTx()
+	defer tx.Abort()
+	iter := tx.NewIterator()
+	iter.SeekLE(key)
+	if !bytes.Equal(iter.Key(), key) || !iter.Sealed() {
+		return 0
+	}
+	id, _ := SealedKeyID(iter.Val())
+	return id
+}
+
+func TestEncryptedValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "encrypted.db")
+	secret := []byte("member SSN 078-05-1120")
+
+	db := openEncrypted(t, path, testKeys(1, 1))
+	if err := db.Set([]byte("plain-api"), secret); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	tx := db.Begin()
+	tx.Set([]byte("tx-api"), secret)
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	for _, key := range []string{"plain-api", "tx-api"} {
+		if val, ok := db.Get([]byte(key)); !ok || !bytes.Equal(val, secret) {
+			t.Errorf("%s: expected the plaintext back, got %q", key, val)
+		}
+	}
+	vals, found := db.GetBatch([][]byte{[]byte("tx-api"), []byte("missing")})
+	if !found[0] || found[1] || !bytes.Equal(vals[0], secret) {
+		t.Errorf("Unexpected batch result: %q %v", vals, found)
+	}
+	var scanned int
+	db.Scan([]byte("p"), func(key, val []byte) bool {
+		if !bytes.Equal(val, secret) {
+			t.Errorf("%s: scan returned %q", key, val)
+		}
+		scanned++
+		return true
+	})
+	if scanned != 2 {
+		t.Errorf("Expected to scan 2 keys, got %d", scanned)
+	}
+	db.Close()
+
+	// Neither the data file nor the WAL holds the plaintext
+	files, _ := filepath.Glob(path + "*")
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, secret) {
+			t.Errorf("%s contains the plaintext", file)
+		}
+	}
+
+	// Without the keys, reading a sealed value fails loudly
+	db = openEncrypted(t, path, nil)
+	defer db.Close()
+	func() {
+		defer func() {
+			se, ok := recover().(*SealError)
+			if !ok || !errors.Is(se, ErrNotEncrypted) {
+				t.Errorf("Expected a SealError for a missing key, got %v", se)
+			}
+		}()
+		db.Get([]byte("tx-api"))
+	}()
+}
+
+func TestEncryptionKeyRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rotation.db")
+
+	db := openEncrypted(t, path, nil)
+	db.Set([]byte("a"), []byte("written in plaintext"))
+	db.Close()
+
+	db = openEncrypted(t, path, testKeys(1, 1))
+	db.Set([]byte("b"), []byte("written under key 1"))
+	db.Set([]byte("c"), []byte("written under key 1"))
+	db.Close()
+
+	// Key 2 becomes current; existing values are re-encrypted only when written
+	db = openEncrypted(t, path, testKeys(2, 1, 2))
+	defer db.Close()
+	if val, ok := db.Get([]byte("a")); !ok || string(val) != "written in plaintext" {
+		t.Errorf("Plaintext value lost: %q", val)
+	}
+	if val, ok := db.Get([]byte("b")); !ok || string(val) != "written under key 1" {
+		t.Errorf("Value under the old key lost: %q", val)
+	}
+	db.Set([]byte("a"), []byte("rewritten"))
+	db.Set([]byte("b"), []byte("rewritten"))
+
+	for key, want := range map[string]uint32{"a": 2, "b": 2, "c": 1} {
+		if got := storedKeyID(db, []byte(key)); got != want {
+			t.Errorf("%s: expected key %d, got %d", key, want, got)
+		}
+	}
+
+	// A value cannot be moved to another record key
+	tx := db.BeginTx()
+	defer tx.Abort()
+	iter := tx.NewIterator()
+	iter.SeekLE([]byte("c"))
+	tx.SetSealed([]byte("d"), append([]byte(nil), iter.Val()...))
+	if _, ok := tx.Get([]byte("d")); ok {
+		t.Error("Expected the moved value to be unreadable")
+	}
+	var se *SealError
+	if !errors.As(tx.Err(), &se) || !errors.Is(se, ErrSealedValue) {
+		t.Errorf("Expected an authentication failure, got %v", tx.Err())
+	}
+	if _, _, err := db.Lookup([]byte("d")); !errors.Is(err, ErrSealedValue) {
+		t.Errorf("Expected Lookup to report the authentication failure, got %v", err)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	k2 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 16))
+
+	keys, err := ParseKeys("2:" + k2 + ", 1:" + k1)
+	if err != nil {
+		t.Fatalf("ParseKeys failed: %v", err)
+	}
+	if keys.Current != 2 || len(keys.Keys) != 2 || len(keys.Keys[1]) != 32 {
+		t.Errorf("Unexpected keys: %+v", keys)
+	}
+
+	for _, bad := range []string{"", k1, "x:" + k1, "1:not base64!", "1:" + k1 + ",1:" + k2} {
+		if _, err := ParseKeys(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+
+	// Keys must have an AES length
+	if _, err := NewEncryption(&StaticKeys{Current: 1, Keys: map[uint32][]byte{1: []byte("short")}}); err == nil {
+		t.Error("Expected an error for a 5-byte key")
+	}
+	if _, err := NewEncryption(&StaticKeys{Current: 3}); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey, got %v", err)
+	}
+}
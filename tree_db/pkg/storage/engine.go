@@ -0,0 +1,151 @@
+// ABOUTME: Storage engine interface the document, metadata, version and prompt stores build on
+// ABOUTME: KV, the copy-on-write B+Tree, is the default; other backends can stand in for it
+
+package storage
+
+import "context"
+
+// Engine is an ordered key-value store with atomic transactions. The stores
+// depend on it rather than on KV, so another backend can be swapped in to
+// benchmark against or to embed them in a process that already has one.
+//
+// Keys are ordered bytewise. Reads that reach corrupt data panic in Get and
+// GetBatch and return an error from Lookup, LookupBatch and the scans.
+type Engine interface {
+	Get(key []byte) ([]byte, bool)
+	Lookup(key []byte) ([]byte, bool, error)
+	GetBatch(keys [][]byte) ([][]byte, []bool)
+	LookupBatch(keys [][]byte) ([][]byte, []bool, error)
+
+	// Scan visits keys from start in ascending order, ScanReverse from start
+	// down, until callback returns false
+	Scan(start []byte, callback func(key, val []byte) bool) error
+	ScanReverse(start []byte, callback func(key, val []byte) bool) error
+
+	// NewIterator returns an unpositioned iterator that stops once ctx is done
+	NewIterator(ctx context.Context) Iterator
+
+	Set(key []byte, val []byte) error
+	Del(key []byte) (bool, error)
+
+	// Begin starts a transaction; its writes are visible to it at once and
+	// become durable together on Commit
+	Begin() Txn
+
+	Close() error
+}
+
+// Txn is a transaction of an Engine. A write or read that fails is recorded
+// rather than returned, reported by Err, and fails Commit.
+type Txn interface {
+	Get(key []byte) ([]byte, bool)
+	GetBatch(keys [][]byte) ([][]byte, []bool)
+	Scan(start []byte, callback func(key, val []byte) bool) error
+	ScanReverse(start []byte, callback func(key, val []byte) bool) error
+
+	Set(key []byte, val []byte)
+	Del(key []byte) bool
+
+	Err() error
+	Commit() error
+
+	// Abort rolls back the transaction; it does nothing once it is done
+	Abort()
+}
+
+// Iterator walks an Engine's keys in order, for reads that page, resume or
+// stop part way more easily than with a Scan callback:
+//
+//	it := e.NewIterator(ctx)
+//	defer it.Close()
+//	for ok := it.Seek(start); ok; ok = it.Next() {
+//		use(it.Key(), it.Value())
+//	}
+//	if err := it.Err(); err != nil { ... }
+//
+// Key and Value are valid until the next call that moves the iterator.
+type Iterator interface {
+	// Seek positions the iterator at the first key >= start and walks up
+	// from it; SeekReverse at the last key <= start and walks down. Both
+	// report whether there is such a key and can be called again to move.
+	Seek(start []byte) bool
+	SeekReverse(start []byte) bool
+
+	// Next moves to the following key in the direction of the last seek and
+	// reports whether there is one
+	Next() bool
+
+	Valid() bool
+	Key() []byte
+	Value() []byte
+
+	// Err returns the error that ended the walk: corrupt data, or the
+	// context's error once it is done
+	Err() error
+
+	// Close releases the iterator and returns Err
+	Close() error
+}
+
+var (
+	_ Engine = (*KV)(nil)
+	_ Txn    = (*KVTX)(nil)
+)
+
+// scanCheckEvery is how many keys a scan visits between checks of its context
+const scanCheckEvery = 64
+
+// WithContext returns a view of e whose scans stop once ctx is done, returning
+// ctx.Err(), so a read a caller gave up on stops early. Point reads, writes and
+// transactions are not interrupted, so a write that started always completes.
+func WithContext(ctx context.Context, e Engine) Engine {
+	if ctx.Done() == nil {
+		return e // Never canceled
+	}
+	if c, ok := e.(*contextEngine); ok {
+		e = c.Engine
+	}
+	return &contextEngine{Engine: e, ctx: ctx}
+}
+
+type contextEngine struct {
+	Engine
+	ctx context.Context
+}
+
+func (e *contextEngine) Scan(start []byte, callback func(key, val []byte) bool) error {
+	return scanContext(e.ctx, e.Engine.Scan, start, callback)
+}
+
+func (e *contextEngine) ScanReverse(start []byte, callback func(key, val []byte) bool) error {
+	return scanContext(e.ctx, e.Engine.ScanReverse, start, callback)
+}
+
+func (e *contextEngine) NewIterator(ctx context.Context) Iterator {
+	if ctx.Done() == nil {
+		ctx = e.ctx
+	}
+	return e.Engine.NewIterator(ctx)
+}
+
+// scanContext runs scan, stopping it once ctx is done
+func scanContext(ctx context.Context, scan func([]byte, func(key, val []byte) bool) error, start []byte, callback func(key, val []byte) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var ctxErr error
+	seen := 0
+	err := scan(start, func(key, val []byte) bool {
+		if seen++; seen%scanCheckEvery == 0 {
+			if ctxErr = ctx.Err(); ctxErr != nil {
+				return false
+			}
+		}
+		return callback(key, val)
+	})
+	if err != nil {
+		return err
+	}
+	return ctxErr
+}
@@ -0,0 +1,152 @@
+// ABOUTME: Behaviour every Engine must have, checked against KV on disk and in memory
+// ABOUTME: A new backend can be added to the table to run the same checks
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestEngines(t *testing.T) {
+	engines := map[string]func(t *testing.T) Engine{
+		"kv": func(t *testing.T) Engine {
+			db := &KV{Path: filepath.Join(t.TempDir(), "engine.db")}
+			if err := db.Open(); err != nil {
+				t.Fatalf("Failed to open: %v", err)
+			}
+			return db
+		},
+		"nommap": func(t *testing.T) Engine {
+			db := &KV{Path: filepath.Join(t.TempDir(), "engine.db"), NoMmap: true}
+			if err := db.Open(); err != nil {
+				t.Fatalf("Failed to open: %v", err)
+			}
+			return db
+		},
+		"memory": func(t *testing.T) Engine {
+			db := &KV{Path: MemoryPath}
+			if err := db.Open(); err != nil {
+				t.Fatalf("Failed to open: %v", err)
+			}
+			return db
+		},
+	}
+
+	for name, open := range engines {
+		t.Run(name, func(t *testing.T) {
+			e := open(t)
+			defer e.Close()
+			testEngine(t, e)
+		})
+	}
+}
+
+func testEngine(t *testing.T, e Engine) {
+	for i := 0; i < 20; i++ {
+		if err := e.Set([]byte(fmt.Sprintf("k%02d", i)), []byte(fmt.Sprint(i))); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	if deleted, err := e.Del([]byte("k05")); err != nil || !deleted {
+		t.Fatalf("Del = %v, %v", deleted, err)
+	}
+	if deleted, _ := e.Del([]byte("missing")); deleted {
+		t.Error("Expected Del of a missing key to report false")
+	}
+
+	if val, found, err := e.Lookup([]byte("k03")); err != nil || !found || string(val) != "3" {
+		t.Errorf("Lookup(k03) = %q, %v, %v", val, found, err)
+	}
+	vals, found, err := e.LookupBatch([][]byte{[]byte("k07"), []byte("k05")})
+	if err != nil || !found[0] || found[1] || string(vals[0]) != "7" {
+		t.Errorf("LookupBatch = %q, %v, %v", vals, found, err)
+	}
+
+	var keys []string
+	e.Scan([]byte("k03"), func(key, val []byte) bool {
+		keys = append(keys, string(key))
+		return len(keys) < 3
+	})
+	if fmt.Sprint(keys) != "[k03 k04 k06]" {
+		t.Errorf("Scan visited %v", keys)
+	}
+	keys = nil
+	e.ScanReverse([]byte("k06"), func(key, val []byte) bool {
+		keys = append(keys, string(key))
+		return len(keys) < 3
+	})
+	if fmt.Sprint(keys) != "[k06 k04 k03]" {
+		t.Errorf("ScanReverse visited %v", keys)
+	}
+
+	// A transaction sees its own writes, and Abort undoes them
+	tx := e.Begin()
+	tx.Set([]byte("k03"), []byte("aborted"))
+	tx.Del([]byte("k04"))
+	if val, _ := tx.Get([]byte("k03")); string(val) != "aborted" {
+		t.Errorf("Expected the transaction to see its write, got %q", val)
+	}
+	tx.Abort()
+	if val, _ := e.Get([]byte("k03")); string(val) != "3" {
+		t.Errorf("Expected the aborted write undone, got %q", val)
+	}
+	if _, ok := e.Get([]byte("k04")); !ok {
+		t.Error("Expected the aborted delete undone")
+	}
+
+	tx = e.Begin()
+	tx.Set([]byte("k03"), []byte("committed"))
+	tx.Del([]byte("k04"))
+	if err := tx.Commit(); err != nil || tx.Err() != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	tx.Abort() // No-op once done
+	if val, _ := e.Get([]byte("k03")); string(val) != "committed" {
+		t.Errorf("Expected the committed write, got %q", val)
+	}
+	if _, ok := e.Get([]byte("k04")); ok {
+		t.Error("Expected the committed delete")
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	db := &KV{Path: MemoryPath}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+	for i := 0; i < 500; i++ {
+		db.Set([]byte(fmt.Sprintf("k%03d", i)), nil)
+	}
+
+	if e := WithContext(context.Background(), db); e != Engine(db) {
+		t.Error("Expected a context that is never done to leave the engine as is")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := WithContext(ctx, db)
+	seen := 0
+	err := e.Scan(nil, func(key, val []byte) bool {
+		if seen++; seen == 100 {
+			cancel()
+		}
+		return true
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the scan canceled, got %v", err)
+	}
+	if seen >= 500 || seen < 100 {
+		t.Errorf("Expected the scan to stop soon after cancel, visited %d keys", seen)
+	}
+
+	if err := e.ScanReverse(nil, func(key, val []byte) bool { return true }); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a scan after cancel to fail, got %v", err)
+	}
+	if _, ok := e.Get([]byte("k001")); !ok {
+		t.Error("Expected point reads to keep working")
+	}
+}
@@ -0,0 +1,76 @@
+// ABOUTME: Approximate key counts from sampling the leaves of the B+Tree
+// ABOUTME: Lets statistics and per-policy counts avoid full scans, with a confidence bound on each count
+
+package storage
+
+import "math"
+
+// EstimateSamples is the number of leaves EstimateKeys reads between the
+// first and last leaf of a range
+const EstimateSamples = 64
+
+// KeyRange is the keys from Start up to, not including, End; a nil End runs
+// to the last key
+type KeyRange struct {
+	Start []byte
+	End   []byte
+}
+
+// PrefixRange returns the range of keys that begin with the encoding of
+// prefix and vals, such as every node of one policy
+func PrefixRange(prefix uint32, vals []Value) KeyRange {
+	return KeyRange{
+		Start: EncodeKey(prefix, vals),
+		End:   EncodeKeyPartial(prefix, vals, CMP_GT),
+	}
+}
+
+// KeyEstimate is an approximate count of the keys in a range
+type KeyEstimate struct {
+	Keys    int64 // Estimated keys in the range
+	Error   int64 // Half-width of a 95% confidence interval around Keys; 0 when exact
+	Leaves  int   // Leaves the range spans
+	Sampled int   // Leaves read, including the first and last
+}
+
+// Exact reports whether every leaf of the range was read
+func (e KeyEstimate) Exact() bool {
+	return e.Sampled == e.Leaves
+}
+
+// EstimateKeys estimates the keys in r without scanning it. The first and
+// last leaf of the range are counted exactly; of the leaves between, which
+// are listed from the internal nodes above them, EstimateSamples evenly
+// spaced ones are read and their mean count scaled to all of them. Ranges of
+// up to EstimateSamples+2 leaves are therefore counted exactly.
+func (db *KV) EstimateKeys(r KeyRange) (estimate KeyEstimate, err error) {
+	defer recoverCorruption(&err)
+	db.treeMu.RLock()
+	defer db.treeMu.RUnlock()
+
+	sample := db.tree.SampleRange(r.Start, r.End, EstimateSamples)
+	estimate.Keys = sample.Edge
+	estimate.Leaves = sample.Leaves
+	estimate.Sampled = sample.Leaves - sample.Interior + len(sample.Counts)
+	if len(sample.Counts) == 0 {
+		return estimate, nil
+	}
+
+	n, total := float64(len(sample.Counts)), float64(sample.Interior)
+	var sum, sumSq float64
+	for _, c := range sample.Counts {
+		sum += float64(c)
+		sumSq += float64(c) * float64(c)
+	}
+	mean := sum / n
+	estimate.Keys += int64(math.Round(mean * total))
+	if len(sample.Counts) == sample.Interior || len(sample.Counts) < 2 {
+		return estimate, nil
+	}
+
+	// Standard error of the scaled mean, with the finite population correction
+	variance := (sumSq - n*mean*mean) / (n - 1)
+	stderr := total * math.Sqrt(math.Max(variance, 0)/n*(1-n/total))
+	estimate.Error = int64(math.Ceil(1.96 * stderr))
+	return estimate, nil
+}
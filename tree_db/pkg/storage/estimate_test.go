@@ -0,0 +1,66 @@
+// ABOUTME: Tests for approximate key counts
+// ABOUTME: Verifies small ranges are exact and sampled estimates fall within their bounds
+
+package storage
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEstimateKeys(t *testing.T) {
+	db := &KV{Path: MemoryPath}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	// Two policies of very different sizes, written out of order
+	counts := map[string]int{"POL-BIG": 30000, "POL-SMALL": 40}
+	for policy, n := range counts {
+		tx := db.Begin()
+		for i := 0; i < n; i++ {
+			key := EncodeKey(2000, []Value{
+				NewBytesValue([]byte(policy)),
+				NewBytesValue([]byte(fmt.Sprintf("node-%06d", (i*7919)%n))),
+			})
+			tx.Set(key, []byte(fmt.Sprintf("value of %s/%d", policy, i)))
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("Commit failed: %v", err)
+		}
+	}
+
+	small, err := db.EstimateKeys(PrefixRange(2000, []Value{NewBytesValue([]byte("POL-SMALL"))}))
+	if err != nil {
+		t.Fatalf("EstimateKeys failed: %v", err)
+	}
+	if small.Keys != 40 || small.Error != 0 || !small.Exact() {
+		t.Errorf("Expected an exact count of 40, got %+v", small)
+	}
+
+	big, err := db.EstimateKeys(PrefixRange(2000, []Value{NewBytesValue([]byte("POL-BIG"))}))
+	if err != nil {
+		t.Fatalf("EstimateKeys failed: %v", err)
+	}
+	if big.Exact() || big.Sampled != EstimateSamples+2 || big.Leaves < 100 {
+		t.Errorf("Expected a sampled estimate over many leaves, got %+v", big)
+	}
+	if diff := big.Keys - 30000; diff < -3000 || diff > 3000 {
+		t.Errorf("Estimated %d keys for 30000", big.Keys)
+	}
+	if big.Error <= 0 || big.Error > 3000 {
+		t.Errorf("Expected a modest error bound, got %d", big.Error)
+	}
+
+	all, err := db.EstimateKeys(PrefixRange(2000, nil))
+	if err != nil {
+		t.Fatalf("EstimateKeys failed: %v", err)
+	}
+	if diff := all.Keys - 30040; diff < -3000 || diff > 3000 || all.Leaves < big.Leaves {
+		t.Errorf("Unexpected estimate of both policies %+v", all)
+	}
+	if none, _ := db.EstimateKeys(PrefixRange(3000, nil)); none.Keys != 0 {
+		t.Errorf("Expected no keys under an unused prefix, got %+v", none)
+	}
+}
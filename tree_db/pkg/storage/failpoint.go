@@ -0,0 +1,53 @@
+// ABOUTME: Failpoints for injecting faults into the commit path
+// ABOUTME: Lets crash-safety tests fail or kill a commit at any durable write
+
+package storage
+
+// CommitPhase identifies a durable write made while committing
+type CommitPhase int
+
+const (
+	PhaseWALSync   CommitPhase = iota // fsync of the WAL after a transaction's COMMIT marker
+	PhaseWritePage                    // pwrite of one data page (reached once per page)
+	PhaseSyncPages                    // fsync after the data pages
+	PhaseWriteMeta                    // pwrite of the meta page
+	PhaseSyncMeta                     // fsync after the meta page
+)
+
+// CommitPhases lists every phase in the order a commit reaches them
+var CommitPhases = []CommitPhase{PhaseWALSync, PhaseWritePage, PhaseSyncPages, PhaseWriteMeta, PhaseSyncMeta}
+
+func (p CommitPhase) String() string {
+	switch p {
+	case PhaseWALSync:
+		return "wal-sync"
+	case PhaseWritePage:
+		return "write-page"
+	case PhaseSyncPages:
+		return "sync-pages"
+	case PhaseWriteMeta:
+		return "write-meta"
+	case PhaseSyncMeta:
+		return "sync-meta"
+	default:
+		return "unknown"
+	}
+}
+
+// Failpoint is called before each durable write of a commit
+// Returning an error skips the write and fails the commit with that error.
+type Failpoint func(phase CommitPhase) error
+
+// SetFailpoint installs fp for subsequent commits; nil removes it
+// Only meant for tests.
+func (db *KV) SetFailpoint(fp Failpoint) {
+	db.failpoint = fp
+}
+
+// fail runs the installed failpoint, if any
+func (db *KV) fail(phase CommitPhase) error {
+	if db.failpoint == nil {
+		return nil
+	}
+	return db.failpoint(phase)
+}
@@ -0,0 +1,86 @@
+// ABOUTME: Portable access to the database file through os.File
+// ABOUTME: Pages are read from a mapping of the file where possible, else from a copy in memory
+
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// createFileSync creates/opens file with directory fsync
+func createFileSync(file string) (*os.File, error) {
+	// Open or create file
+	f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+
+	// Fsync directory
+	if err := syncDir(filepath.Dir(file)); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("fsync directory: %w", err)
+	}
+
+	return f, nil
+}
+
+// loadFile makes the first size bytes of an existing file readable through the
+// chunks, by mapping them or by reading them into memory
+func (db *KV) loadFile(size int) error {
+	if db.heap {
+		// Round up to whole pages, as page reads index the chunks by page
+		alloc := max((size+BTREE_PAGE_SIZE-1)/BTREE_PAGE_SIZE*BTREE_PAGE_SIZE, memoryChunkSize)
+		chunk := make([]byte, alloc)
+		if _, err := db.file.ReadAt(chunk[:size], 0); err != nil {
+			return fmt.Errorf("read file: %w", err)
+		}
+		db.mmap.total = alloc
+		db.mmap.chunks = append(db.mmap.chunks, chunk)
+		return nil
+	}
+
+	mmapSize := 64 << 20 // Start with 64MB
+	if size > mmapSize {
+		mmapSize = size
+	}
+	chunk, err := mmap(db.file, 0, mmapSize)
+	if err != nil {
+		return fmt.Errorf("mmap: %w", err)
+	}
+	db.mmap.total = mmapSize
+	db.mmap.chunks = append(db.mmap.chunks, chunk)
+	return nil
+}
+
+// writeAt writes data at an offset of the database file. Chunks held in
+// memory rather than mapped are updated to match, and grow to hold it.
+func (db *KV) writeAt(data []byte, offset int64) error {
+	if db.file != nil {
+		if _, err := db.file.WriteAt(data, offset); err != nil {
+			return err
+		}
+	}
+	if !db.heap {
+		return nil // The mapping shows the write
+	}
+
+	if err := db.extendMmap(int(offset) + len(data)); err != nil {
+		return err
+	}
+	off := int(offset)
+	for _, chunk := range db.mmap.chunks {
+		if len(data) == 0 {
+			break
+		}
+		if off >= len(chunk) {
+			off -= len(chunk)
+			continue
+		}
+		n := copy(chunk[off:], data)
+		data = data[n:]
+		off = 0
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+// ABOUTME: Tests for reading the database file without mmap
+// ABOUTME: Checks a file written either way reads back the same either way
+
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestNoMmapRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nommap.db")
+	val := make([]byte, 1000)
+
+	// Alternate between mapped and unmapped sessions on one file, each adding
+	// enough keys to outgrow the chunk it started with
+	for session, noMmap := range []bool{true, false, true} {
+		db := &KV{Path: path, NoMmap: noMmap}
+		if err := db.Open(); err != nil {
+			t.Fatalf("Session %d: open failed: %v", session, err)
+		}
+		tx := db.Begin()
+		for i := 0; i < 1500; i++ {
+			tx.Set([]byte(fmt.Sprintf("s%d-%04d", session, i)), val)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("Session %d: commit failed: %v", session, err)
+		}
+		for prev := 0; prev <= session; prev++ {
+			if _, ok := db.Get([]byte(fmt.Sprintf("s%d-1499", prev))); !ok {
+				t.Errorf("Session %d: missing key of session %d", session, prev)
+			}
+		}
+		if report, err := db.Verify(); err != nil || len(report.Corrupt) != 0 {
+			t.Errorf("Session %d: Verify found damage: %+v (%v)", session, report, err)
+		}
+		if err := db.Close(); err != nil {
+			t.Fatalf("Session %d: close failed: %v", session, err)
+		}
+	}
+}
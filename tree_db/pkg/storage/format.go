@@ -0,0 +1,194 @@
+// ABOUTME: On-disk format versions and the registry of migrations between them
+// ABOUTME: Open migrates older files, backing them up first when records are rewritten
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// FormatVersion is the format new files are created in and older files are
+// migrated to. Files from TreeStore01 to TreeStore03 carried their version in
+// the signature; later ones keep DB_SIG and record it in the meta page.
+const FormatVersion = 6
+
+var (
+	// ErrNewerFormat indicates a file written by a newer build
+	ErrNewerFormat = errors.New("storage: database uses a newer format than this build supports")
+
+	// ErrMigrationRequired indicates a file of an older format opened with NoMigrate
+	ErrMigrationRequired = errors.New("storage: database uses an older format; run treestore-admin migrate")
+)
+
+// Migration brings a database from the previous format version to To.
+// Apply rewrites records on the opened database with ordinary transactions;
+// it is nil for changes to the meta page alone, which the next commit writes.
+// A migration interrupted by a crash runs again from the start at the next
+// Open, so Apply must be idempotent.
+type Migration struct {
+	To          uint32
+	Description string
+	Apply       func(db *KV) error
+}
+
+// migrations lists every format change in order; the last is FormatVersion.
+// Files before format 2 (TreeStore01, without page checksums) cannot be
+// migrated in place and are copied by Upgrade instead.
+var migrations = []Migration{
+	{To: 3, Description: "two meta slots, so a torn meta write leaves the previous one"},
+	{To: 4, Description: "format version recorded in the meta page"},
+	{To: 5, Description: "flushed-through WAL LSN recorded in the meta page"},
+	{To: 6, Description: "prefix-compressed B+Tree pages"},
+}
+
+// MigrationReport describes the migration Open ran on an older file
+type MigrationReport struct {
+	From   uint32 // Format the file had; 0 if it needed no migration
+	To     uint32
+	Backup string // Copy of the file taken before records were rewritten, if any
+}
+
+// latestFormat returns the version of the last registered migration
+func latestFormat() uint32 {
+	return migrations[len(migrations)-1].To
+}
+
+// PendingMigrations returns the migrations a file of a format needs
+func PendingMigrations(from uint32) []Migration {
+	var pending []Migration
+	for _, m := range migrations {
+		if m.To > from {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// MigrationBackupPath returns where Open copies a file of the given format
+// before a migration rewrites its records
+func MigrationBackupPath(dbPath string, format uint32) string {
+	return fmt.Sprintf("%s.format%d.bak", dbPath, format)
+}
+
+// checkFormat decides after reading the meta page whether the file can be
+// opened, and in which format its meta is written until migrations finish
+func (db *KV) checkFormat() error {
+	if db.legacy {
+		return nil
+	}
+	latest := latestFormat()
+	if db.format > latest {
+		return fmt.Errorf("%w: %s has format %d, this build reads up to %d", ErrNewerFormat, db.Path, db.format, latest)
+	}
+	if db.format == latest {
+		return nil
+	}
+	if db.NoMigrate {
+		return fmt.Errorf("%w: %s has format %d, this build uses %d", ErrMigrationRequired, db.Path, db.format, latest)
+	}
+
+	// Until records are rewritten, commits must not claim the new format
+	for _, m := range PendingMigrations(db.format) {
+		if m.Apply != nil {
+			db.metaFormat = db.format
+			break
+		}
+	}
+	return nil
+}
+
+// migrateOnOpen runs the migrations of an older file once the WAL is
+// recovered. When one rewrites records, the recovered state is written out
+// and the file copied to MigrationBackupPath first; a copy left by an
+// interrupted attempt is kept, since it predates that attempt.
+func (db *KV) migrateOnOpen() error {
+	if db.legacy || db.format >= latestFormat() {
+		return nil
+	}
+	db.migration = MigrationReport{From: db.format, To: latestFormat()}
+	if db.metaFormat == 0 {
+		return nil // Meta changes only; the next commit writes them
+	}
+
+	if err := db.persist(); err != nil {
+		return fmt.Errorf("write database before migration: %w", err)
+	}
+	backup := MigrationBackupPath(db.Path, db.format)
+	if _, err := os.Stat(backup); errors.Is(err, os.ErrNotExist) {
+		if err := copyFile(db.Path, backup); err != nil {
+			return fmt.Errorf("back up database before migration: %w", err)
+		}
+	}
+	db.migration.Backup = backup
+
+	for _, m := range PendingMigrations(db.format) {
+		if m.Apply == nil {
+			continue
+		}
+		if err := m.Apply(db); err != nil {
+			return fmt.Errorf("migration to format %d (%s): %w", m.To, m.Description, err)
+		}
+	}
+
+	db.metaFormat = 0
+	db.lockTree()
+	db.tree.SetPrefixCompression(true)
+	db.treeMu.Unlock()
+	if err := db.persist(); err != nil {
+		return fmt.Errorf("write migrated database: %w", err)
+	}
+	return nil
+}
+
+// Migrate writes the meta page in the current format now rather than with
+// the next commit. Open has already run any migration rewriting records.
+func (db *KV) Migrate() error {
+	if db.legacy {
+		return ErrLegacyFormat
+	}
+	if db.memory || db.format == latestFormat() {
+		return nil
+	}
+	return db.persist()
+}
+
+// persist writes every change so far and a meta page pointing at them
+func (db *KV) persist() error {
+	db.writer.Lock()
+	db.flush.mu.Lock()
+	db.lockTree()
+	err := db.updateOrRevert(db.saveMeta())
+	db.treeMu.Unlock()
+	db.flush.mu.Unlock()
+	db.writer.Unlock()
+	if err != nil {
+		return err
+	}
+	return db.Flush()
+}
+
+// copyFile copies src to a new file at dst and syncs it
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,259 @@
+// ABOUTME: Tests for format versions and migrations
+// ABOUTME: Covers older and newer files, NoMigrate and record-rewriting migrations with backups
+
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"path/filepath"
+	"testing"
+)
+
+// rewriteMeta replaces the newest meta slot of a closed database with the
+// result of edit, clearing the other slot so it cannot be chosen instead
+func rewriteMeta(t *testing.T, path string, edit func(meta []byte)) {
+	t.Helper()
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	gen := db.generation
+	db.Close()
+
+	meta := newestMeta(t, path)
+	edit(meta)
+	writeAt(t, path, meta, metaSlotOffset(gen))
+	writeAt(t, path, make([]byte, META_PAGE_SIZE), metaSlotOffset(gen+1))
+}
+
+// asFormat3 rewrites a meta slot as TreeStore03 wrote it
+func asFormat3(meta []byte) {
+	copy(meta, DB_SIG_V3)
+	binary.LittleEndian.PutUint32(meta[metaChecksumOffsetV3:], crc32.Checksum(meta[:metaChecksumOffsetV3], crcTable))
+	copy(meta[metaChecksumOffsetV3+4:], make([]byte, META_PAGE_SIZE-metaChecksumOffsetV3-4))
+}
+
+// asFormat4 rewrites a meta slot as format 4 wrote it, without the WAL LSN
+func asFormat4(meta []byte) {
+	binary.LittleEndian.PutUint32(meta[metaFormatOffset:], 4)
+	copy(meta[metaWALOffset:], make([]byte, META_PAGE_SIZE-metaWALOffset))
+	stampMeta(meta)
+}
+
+func TestFormatVersionRecorded(t *testing.T) {
+	if latestFormat() != FormatVersion {
+		t.Fatalf("Last migration is to format %d, FormatVersion is %d", latestFormat(), FormatVersion)
+	}
+
+	path := filepath.Join(t.TempDir(), "format.db")
+	writeChecksumDB(t, path)
+	if meta := newestMeta(t, path); binary.LittleEndian.Uint32(meta[metaFormatOffset:]) != FormatVersion {
+		t.Errorf("Meta records format %d, want %d", binary.LittleEndian.Uint32(meta[metaFormatOffset:]), FormatVersion)
+	}
+
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+	if state := db.State(); state.Format != FormatVersion || state.Migration.From != 0 {
+		t.Errorf("Unexpected state format %d, migration %+v", state.Format, state.Migration)
+	}
+}
+
+func TestOlderFormatMigratesOnCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v3.db")
+	writeChecksumDB(t, path)
+	rewriteMeta(t, path, asFormat3)
+
+	db := &KV{Path: path, NoMigrate: true}
+	if err := db.Open(); !errors.Is(err, ErrMigrationRequired) {
+		t.Fatalf("Expected ErrMigrationRequired with NoMigrate, got %v", err)
+	}
+
+	db = &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open format 3 file: %v", err)
+	}
+	state := db.State()
+	if state.Format != 3 || state.Migration.From != 3 || state.Migration.To != FormatVersion || state.Migration.Backup != "" {
+		t.Errorf("Unexpected state format %d, migration %+v", state.Format, state.Migration)
+	}
+
+	// Only the meta changed, so the next commit completes the migration
+	if err := db.Set([]byte("after"), []byte("migration")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if format := db.State().Format; format != FormatVersion {
+		t.Errorf("Format after commit = %d, want %d", format, FormatVersion)
+	}
+	db.Close()
+
+	db = &KV{Path: path, NoMigrate: true}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Reopen of migrated file failed: %v", err)
+	}
+	defer db.Close()
+	for _, key := range []string{"key0042", "after"} {
+		if _, ok := db.Get([]byte(key)); !ok {
+			t.Errorf("%s missing after migration", key)
+		}
+	}
+}
+
+func TestExplicitMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v3.db")
+	writeChecksumDB(t, path)
+	rewriteMeta(t, path, asFormat3)
+
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	db.Close()
+
+	if meta := newestMeta(t, path); string(meta[:16]) != DB_SIG || metaSlotFormat(meta) != FormatVersion {
+		t.Errorf("Newest slot %q has format %d after Migrate", meta[:16], metaSlotFormat(meta))
+	}
+}
+
+func TestNewerFormatRefused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "future.db")
+	writeChecksumDB(t, path)
+	rewriteMeta(t, path, func(meta []byte) {
+		binary.LittleEndian.PutUint32(meta[metaFormatOffset:], FormatVersion+1)
+		stampMeta(meta)
+	})
+
+	db := &KV{Path: path}
+	if err := db.Open(); !errors.Is(err, ErrNewerFormat) {
+		t.Fatalf("Expected ErrNewerFormat, got %v", err)
+	}
+}
+
+func TestRewritingMigrationBacksUp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rewrite.db")
+	writeChecksumDB(t, path)
+
+	// A later build adds a migration rewriting records
+	applied := 0
+	saved := migrations
+	migrations = append(append([]Migration(nil), saved...), Migration{
+		To:          FormatVersion + 1,
+		Description: "test rewrite",
+		Apply: func(db *KV) error {
+			applied++
+			return db.Set([]byte("migrated"), []byte("yes"))
+		},
+	})
+	defer func() { migrations = saved }()
+
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	state := db.State()
+	if applied != 1 || state.Format != FormatVersion+1 || state.Migration.Backup != MigrationBackupPath(path, FormatVersion) {
+		t.Errorf("applied %d times, format %d, migration %+v", applied, state.Format, state.Migration)
+	}
+	if _, ok := db.Get([]byte("migrated")); !ok {
+		t.Error("Migration's write missing")
+	}
+	db.Close()
+
+	// Reopening finds the file migrated
+	db = &KV{Path: path, NoMigrate: true}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	db.Close()
+	if applied != 1 {
+		t.Errorf("Migration applied %d times", applied)
+	}
+
+	// The backup is the file as it was, and opens in the previous format
+	migrations = saved
+	backup := &KV{Path: state.Migration.Backup}
+	if err := backup.Open(); err != nil {
+		t.Fatalf("Failed to open backup: %v", err)
+	}
+	if _, ok := backup.Get([]byte("migrated")); ok || backup.State().Format != FormatVersion {
+		t.Errorf("Backup holds the migration's write or has format %d", backup.State().Format)
+	}
+	if _, ok := backup.Get([]byte("key0100")); !ok {
+		t.Error("Backup lacks the original records")
+	}
+	backup.Close()
+}
+
+func TestFlushedLSNRecorded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v4.db")
+	writeChecksumDB(t, path)
+	rewriteMeta(t, path, asFormat4)
+
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open format 4 file: %v", err)
+	}
+	if db.walLSN != db.wal.LastLSN() || db.wal.FlushedLSN() != 0 {
+		t.Errorf("Format 4 file: tree at LSN %d, flushed %d", db.walLSN, db.wal.FlushedLSN())
+	}
+	if err := db.Set([]byte("after"), []byte("flush")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	flushed := db.wal.FlushedLSN()
+	if flushed == 0 || db.State().Checkpoint.FlushedLSN != flushed {
+		t.Errorf("Flushed LSN %d, state %+v", flushed, db.State().Checkpoint)
+	}
+	db.Close()
+
+	meta := newestMeta(t, path)
+	if metaSlotFormat(meta) != FormatVersion || metaWALLSN(meta) != flushed {
+		t.Errorf("Meta records format %d, LSN %d; want %d, %d", metaSlotFormat(meta), metaWALLSN(meta), FormatVersion, flushed)
+	}
+}
+
+func TestFormat5PacksPagesAfterMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v5.db")
+	writeChecksumDB(t, path)
+	rewriteMeta(t, path, func(meta []byte) {
+		binary.LittleEndian.PutUint32(meta[metaFormatOffset:], 5)
+		stampMeta(meta)
+	})
+
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open format 5 file: %v", err)
+	}
+	if !db.tree.PrefixCompression() {
+		t.Error("Expected packed pages once only the meta page needs migrating")
+	}
+	for i := 0; i < 500; i++ {
+		key := fmt.Sprintf("policy-clinical-guidelines/node-%05d", i)
+		if err := db.Set([]byte(key), []byte("v")); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	db.Close()
+
+	if meta := newestMeta(t, path); metaSlotFormat(meta) != FormatVersion {
+		t.Fatalf("Meta records format %d after packed writes, want %d", metaSlotFormat(meta), FormatVersion)
+	}
+	db = &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	defer db.Close()
+	for i := 0; i < 500; i += 97 {
+		key := fmt.Sprintf("policy-clinical-guidelines/node-%05d", i)
+		if val, ok := db.Get([]byte(key)); !ok || string(val) != "v" {
+			t.Errorf("Get(%s) = %q, %v", key, val, ok)
+		}
+	}
+}
@@ -9,7 +9,7 @@ import (
 
 const (
 	FREE_LIST_HEADER = 8
-	FREE_LIST_CAP    = (BTREE_PAGE_SIZE - FREE_LIST_HEADER) / 8
+	FREE_LIST_CAP    = (BTREE_PAGE_SIZE - FREE_LIST_HEADER - PAGE_CHECKSUM_SIZE) / 8
 )
 
 // LNode represents a free list node (linked list node)
@@ -73,8 +73,9 @@ func (fl *FreeList) PopHead() uint64 {
 	// maxSeq controls which items can be popped:
 	// - During a transaction: maxSeq is frozen, preventing newly freed pages from being reused
 	// - After commit: maxSeq = tailSeq, allowing all pages to be reused
-	// Only block if maxSeq < tailSeq (there are new items) AND we've reached maxSeq
-	if fl.maxSeq > 0 && fl.maxSeq < fl.tailSeq && fl.headSeq >= fl.maxSeq {
+	// Items at or past maxSeq were freed by the open transaction and may still be
+	// referenced by the durable tree
+	if fl.headSeq >= fl.maxSeq {
 		return 0 // would consume newly added items not yet committed
 	}
 
@@ -110,6 +111,7 @@ func (fl *FreeList) PushTail(ptr uint64) {
 		node := LNode(page)
 		node.setNext(0)
 		fl.tailPage = fl.new(page)
+		fl.headPage = fl.tailPage
 	}
 
 	idx := int(fl.tailSeq % FREE_LIST_CAP)
@@ -143,6 +145,21 @@ func (fl *FreeList) PushTail(ptr uint64) {
 	fl.tailSeq++
 }
 
+// walk calls node for each page holding the list and item for each free page
+func (fl *FreeList) walk(node func(ptr uint64), item func(ptr uint64)) {
+	for ptr := fl.headPage; ptr != 0; ptr = LNode(fl.get(ptr)).getNext() {
+		node(ptr)
+	}
+
+	ptr := fl.headPage
+	for seq := fl.headSeq; seq < fl.tailSeq; seq++ {
+		if seq != fl.headSeq && seq%FREE_LIST_CAP == 0 {
+			ptr = LNode(fl.get(ptr)).getNext()
+		}
+		item(LNode(fl.get(ptr)).getPtr(int(seq % FREE_LIST_CAP)))
+	}
+}
+
 // SetMaxSeq sets the maximum sequence to prevent consuming newly added items
 func (fl *FreeList) SetMaxSeq() {
 	fl.maxSeq = fl.tailSeq
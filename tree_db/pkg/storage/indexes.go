@@ -4,6 +4,7 @@
 package storage
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/nainya/treestore/pkg/btree"
@@ -87,7 +88,7 @@ type IndexUpdate struct {
 func (im *IndexManager) Begin() *IndexedTx {
 	return &IndexedTx{
 		im:      im,
-		tx:      im.db.Begin(),
+		tx:      im.db.BeginTx(),
 		updates: make(map[string]IndexUpdate),
 	}
 }
@@ -185,10 +186,15 @@ func (itx *IndexedTx) ScanIndex(indexName string, start []Value, callback func(p
 		return fmt.Errorf("index %s not found", indexName)
 	}
 
+	prefix := EncodeKey(info.Def.Prefix, nil)
 	startKey := EncodeKey(info.Def.Prefix, start)
 
-	// Scan the secondary index
+	// Scan the secondary index, stopping at the next index's keys
 	info.Tree.Scan(startKey, func(indexKey, _ []byte) bool {
+		if !bytes.HasPrefix(indexKey, prefix) {
+			return false
+		}
+
 		// Extract primary key from index key
 		vals, err := ExtractValues(indexKey)
 		if err != nil {
@@ -0,0 +1,152 @@
+// ABOUTME: Pull-based iterator over the KV tree with seek, reverse walks and cancellation
+// ABOUTME: Scan and ScanReverse are built on it; corrupt data and a done context end it with Err
+
+package storage
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/nainya/treestore/pkg/btree"
+)
+
+// kvIterator walks the B+Tree of a KV, unsealing values as it lands on them
+type kvIterator struct {
+	db      *KV
+	ctx     context.Context
+	iter    *btree.BIter
+	reverse bool   // Walking down from a SeekReverse
+	valid   bool   // Positioned at a key
+	key     []byte // Copy of the current key, to find it again once the tree changed
+	val     []byte // Unsealed value of the current key
+	gen     uint64 // KV.treeGen when iter last read the tree
+	seen    int    // Keys visited since the seek, for context checks
+	err     error
+	closed  bool
+}
+
+// NewIterator returns an iterator over db's keys; call Seek or SeekReverse to
+// position it. Each step reads the tree as it is then: after a write, the
+// iterator seeks back to its key in the new tree, so it never reads pages a
+// commit has since let go of, and sees keys written ahead of it.
+func (db *KV) NewIterator(ctx context.Context) Iterator {
+	return &kvIterator{db: db, ctx: ctx, iter: db.tree.NewIterator()}
+}
+
+func (it *kvIterator) Seek(start []byte) bool {
+	return it.position(false, func() bool { return it.iter.Seek(start) })
+}
+
+func (it *kvIterator) SeekReverse(start []byte) bool {
+	return it.position(true, func() bool { return it.iter.SeekLE(start) })
+}
+
+func (it *kvIterator) Next() bool {
+	if !it.valid {
+		return false
+	}
+	if it.seen++; it.seen%scanCheckEvery == 0 {
+		if err := it.ctx.Err(); err != nil {
+			it.valid, it.val, it.err = false, nil, err
+			return false
+		}
+	}
+	if it.reverse {
+		return it.step(it.resume(it.iter.SeekLE, it.iter.Prev))
+	}
+	return it.step(it.resume(it.iter.Seek, it.iter.Next))
+}
+
+// resume returns the move past the current key: a plain step while the tree
+// is as iter last read it, else a seek to the key in the changed tree,
+// stepping over it if it is still there
+func (it *kvIterator) resume(seek func([]byte) bool, move func() bool) func() bool {
+	return func() bool {
+		if it.gen == it.db.treeGen {
+			return move()
+		}
+		if !seek(it.key) || !it.iter.Valid() {
+			return false
+		}
+		if bytes.Equal(it.iter.Key(), it.key) {
+			return move()
+		}
+		return true
+	}
+}
+
+func (it *kvIterator) Valid() bool {
+	return it.valid
+}
+
+func (it *kvIterator) Key() []byte {
+	if !it.valid {
+		return nil
+	}
+	return it.key
+}
+
+func (it *kvIterator) Value() []byte {
+	return it.val
+}
+
+func (it *kvIterator) Err() error {
+	return it.err
+}
+
+func (it *kvIterator) Close() error {
+	it.closed, it.valid, it.key, it.val = true, false, nil, nil
+	return it.err
+}
+
+// position starts a new walk in the given direction
+func (it *kvIterator) position(reverse bool, seek func() bool) bool {
+	it.valid, it.key, it.val, it.err = false, nil, nil, nil
+	if it.closed {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+	it.reverse, it.seen = reverse, 0
+	return it.step(seek)
+}
+
+// step moves the tree iterator and copies out the key and value it lands on,
+// recording a corrupt page or an undecryptable value in err. The tree is
+// read under treeMu, which is let go of before the caller sees the key.
+func (it *kvIterator) step(move func() bool) bool {
+	it.valid, it.val = false, nil
+	err := func() (err error) {
+		defer recoverCorruption(&err)
+		it.db.treeMu.RLock()
+		defer it.db.treeMu.RUnlock()
+
+		moved := move()
+		it.gen = it.db.treeGen
+		// The empty sentinel key is internal and marks the start of the tree
+		if !moved || !it.iter.Valid() || len(it.iter.Key()) == 0 {
+			return nil
+		}
+		key := bytes.Clone(it.iter.Key())
+		it.val = it.db.unseal(key, it.iter.Val(), it.iter.Sealed())
+		it.key, it.valid = key, true
+		return nil
+	}()
+	if err != nil {
+		it.err = err
+	}
+	return it.valid
+}
+
+// scanIterator runs a Scan or ScanReverse over an iterator
+func scanIterator(it Iterator, seek func([]byte) bool, start []byte, callback func(key, val []byte) bool) error {
+	defer it.Close()
+	for ok := seek(start); ok; ok = it.Next() {
+		if !callback(it.Key(), it.Value()) {
+			break
+		}
+	}
+	return it.Err()
+}
@@ -0,0 +1,101 @@
+// ABOUTME: Tests for the KV iterator
+// ABOUTME: Verifies seeks in both directions, resuming, cancellation and closing
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func openIteratorKV(t *testing.T, n int) *KV {
+	t.Helper()
+	db := &KV{Path: MemoryPath}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for i := 0; i < n; i += 2 {
+		if err := db.Set([]byte(fmt.Sprintf("k%04d", i)), []byte(fmt.Sprintf("v%04d", i))); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	return db
+}
+
+func TestIteratorSeekBothWays(t *testing.T) {
+	db := openIteratorKV(t, 1000)
+	it := db.NewIterator(context.Background())
+	defer it.Close()
+
+	// Forward from a key that is absent
+	var keys []string
+	for ok := it.Seek([]byte("k0101")); ok && len(keys) < 3; ok = it.Next() {
+		keys = append(keys, string(it.Key()))
+		if string(it.Value()) != "v"+string(it.Key())[1:] {
+			t.Errorf("Value of %s = %s", it.Key(), it.Value())
+		}
+	}
+	if fmt.Sprint(keys) != "[k0102 k0104 k0106]" {
+		t.Errorf("Forward from k0101: %v", keys)
+	}
+
+	// The same iterator seeks again, downwards
+	keys = nil
+	for ok := it.SeekReverse([]byte("k0005")); ok; ok = it.Next() {
+		keys = append(keys, string(it.Key()))
+	}
+	if fmt.Sprint(keys) != "[k0004 k0002 k0000]" {
+		t.Errorf("Reverse from k0005: %v", keys)
+	}
+	if it.Valid() || it.Err() != nil {
+		t.Errorf("Expected the walk to end cleanly, got valid %v, err %v", it.Valid(), it.Err())
+	}
+
+	// The sentinel is never returned
+	if !it.Seek(nil) || string(it.Key()) != "k0000" {
+		t.Errorf("Seek(nil) = %q", it.Key())
+	}
+	if it.Seek([]byte("k0999")) {
+		t.Errorf("Seek past the end found %s", it.Key())
+	}
+}
+
+func TestIteratorStopsWhenContextDone(t *testing.T) {
+	db := openIteratorKV(t, 1000)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := db.NewIterator(ctx)
+
+	seen := 0
+	for ok := it.Seek(nil); ok; ok = it.Next() {
+		if seen++; seen == 100 {
+			cancel()
+		}
+	}
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("Expected the walk canceled, got %v", it.Err())
+	}
+	if seen >= 500 || seen < 100 {
+		t.Errorf("Expected the walk to stop soon after cancel, visited %d keys", seen)
+	}
+	if it.SeekReverse(nil) || !errors.Is(it.Close(), context.Canceled) {
+		t.Error("Expected a seek after cancel to fail")
+	}
+}
+
+func TestIteratorClosed(t *testing.T) {
+	db := openIteratorKV(t, 10)
+	it := db.NewIterator(context.Background())
+	if !it.Seek(nil) {
+		t.Fatal("Expected keys")
+	}
+	if err := it.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if it.Valid() || it.Next() || it.Seek(nil) || it.Key() != nil {
+		t.Error("Expected a closed iterator to stay exhausted")
+	}
+}
@@ -4,12 +4,12 @@
 package storage
 
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
 	"os"
-	"path"
+	"sync"
 	"sync/atomic"
-	"syscall"
 	"time"
 
 	"github.com/nainya/treestore/pkg/btree"
@@ -17,25 +17,67 @@ import (
 )
 
 const (
-	DB_SIG          = "TreeStore01\x00\x00\x00\x00\x00" // Database signature (16 bytes)
+	DB_SIG          = "TreeStore04\x00\x00\x00\x00\x00" // Database signature (16 bytes); later formats keep it and raise the meta's format version
+	DB_SIG_V3       = "TreeStore03\x00\x00\x00\x00\x00" // Signature of files without a format version
+	DB_SIG_V2       = "TreeStore02\x00\x00\x00\x00\x00" // Signature of files with a single meta slot
+	DB_SIG_V1       = "TreeStore01\x00\x00\x00\x00\x00" // Signature of files without page checksums
 	BTREE_PAGE_SIZE = 4096                               // Must match btree package
-	META_PAGE_SIZE  = 80                                 // Meta page size (expanded for free list)
+	META_PAGE_SIZE  = 96                                 // Size of one meta slot
+	META_SLOT_B     = BTREE_PAGE_SIZE / 2                // Offset of the second meta slot, in another sector than the first
 )
 
 // KV represents a persistent key-value store
 type KV struct {
 	Path string
 
-	// File descriptor
-	fd int
+	// Encryption seals values written after Open; nil stores them in plaintext.
+	// Values sealed earlier stay readable only while it holds their keys.
+	Encryption *Encryption
+
+	// SyncPolicy chooses when commits reach stable storage; the zero value
+	// fsyncs every commit. SyncInterval is the flush period of SyncInterval,
+	// DefaultSyncInterval if zero. Both must be set before Open.
+	SyncPolicy   SyncPolicy
+	SyncInterval time.Duration
+
+	// NoMmap reads pages from a copy of the file held in memory instead of
+	// mapping it, which takes as much memory as the file is large. It is
+	// implied where mmap is unsupported, such as on Windows.
+	NoMmap bool
+
+	// WALArchive keeps log files the WAL retires, for point-in-time
+	// recovery; nil deletes them
+	WALArchive *wal.Archive
+
+	// Database file, nil in memory
+	file *os.File
+
+	// Opened with MemoryPath: pages live in heap chunks and there is no WAL
+	memory bool
+
+	// Chunks are allocated on the heap rather than mapped from the file
+	heap bool
 
 	// B+Tree
 	tree btree.BTree
 
+	// Readers hold treeMu shared while they walk the tree and its pages;
+	// anything changing the root, the pending pages or the mapping holds it
+	// exclusively, through lockTree. treeGen counts those changes, so an
+	// iterator can tell that the path it holds is stale.
+	treeMu  sync.RWMutex
+	treeGen uint64
+
+	// Transactions write straight into the tree, so one holds writer from
+	// Begin until Commit or Abort; Set, Del and maintenance that changes the
+	// tree take it too. It is let go of before a commit waits for its flush,
+	// so commits still share flushes.
+	writer sync.Mutex
+
 	// Free list for page recycling
 	free FreeList
 
-	// Memory-mapped file
+	// Memory-mapped file, or its copy in memory
 	mmap struct {
 		total  int      // Total mmap size
 		chunks [][]byte // Multiple mmap regions
@@ -46,70 +88,85 @@ type KV struct {
 		flushed uint64              // Number of pages flushed to disk
 		temp    [][]byte            // Temporary pages pending flush
 		updates map[uint64][]byte   // In-place updates
+		freed   []uint64            // Temporary pages the transaction freed again
 	}
 
 	// Error recovery
 	failed bool // Did last update fail?
 
+	// File predates page checksums; opened read-only
+	legacy bool
+
+	// NoMigrate makes Open fail with ErrMigrationRequired on a file of an
+	// older format instead of migrating it
+	NoMigrate bool
+
+	// Format version of the file on disk, and the migration Open ran
+	format    uint32
+	migration MigrationReport
+
+	// Format written to meta pages while a migration rewrites records; 0
+	// writes the newest
+	metaFormat uint32
+
+	// Generation of the newest meta slot on disk
+	generation uint64
+
+	// LSN of the last WAL entry the tree reflects, recorded in the meta page
+	// so that, once flushed, recovery and log retirement know what the file holds
+	walLSN uint64
+
 	// WAL for durability and crash recovery
 	wal *wal.WAL
 
+	// What Open recovered from the WAL, including any torn tail it cut off
+	recovery wal.RecoveryStats
+
 	// Checkpointer for background checkpointing
 	checkpointer *wal.Checkpointer
 
 	// currentTxnID for transaction tracking
 	currentTxnID uint64
+
+	// Fault injection for crash-safety tests
+	failpoint Failpoint
+
+	// Commits awaiting a flush under SyncInterval
+	flush flushState
 }
 
 // Open opens or creates a database file
-func (db *KV) Open() error {
-	// Create or open file with directory fsync
-	fd, err := createFileSync(db.Path)
-	if err != nil {
+func (db *KV) Open() (err error) {
+	// WAL replay traverses the tree, so it can reach a corrupt page
+	defer recoverCorruption(&err)
+
+	// An in-memory database starts out like an empty file
+	var fileSize int64
+	if db.Path == MemoryPath {
+		db.memory = true
+	} else if fileSize, err = db.openFile(); err != nil {
 		return err
 	}
-	db.fd = fd
-
-	// Initialize WAL
-	walPath := db.Path + ".wal"
-	db.wal = &wal.WAL{Path: walPath}
-	if err := db.wal.Open(); err != nil {
-		return fmt.Errorf("failed to open WAL: %w", err)
-	}
-
-	// Get file size
-	var stat syscall.Stat_t
-	if err := syscall.Fstat(db.fd, &stat); err != nil {
-		return fmt.Errorf("fstat: %w", err)
-	}
-	fileSize := stat.Size
+	db.heap = db.memory || db.NoMmap || !mmapSupported
 
 	// Initialize mmap
 	if fileSize == 0 {
 		// Empty file - reserve meta page
 		db.page.flushed = 1
+		db.format = FormatVersion
 	} else {
 		// Existing file - read meta page
-		mmapSize := 64 << 20 // Start with 64MB
-		if int(fileSize) > mmapSize {
-			mmapSize = int(fileSize)
-		}
-
-		chunk, err := syscall.Mmap(
-			db.fd, 0, mmapSize,
-			syscall.PROT_READ, syscall.MAP_SHARED,
-		)
-		if err != nil {
-			return fmt.Errorf("mmap: %w", err)
+		if err := db.loadFile(int(fileSize)); err != nil {
+			return err
 		}
 
-		db.mmap.total = mmapSize
-		db.mmap.chunks = append(db.mmap.chunks, chunk)
-
 		// Read meta page
 		if err := db.readMeta(); err != nil {
 			return err
 		}
+		if err := db.checkFormat(); err != nil {
+			return err
+		}
 	}
 
 	// Initialize page updates map
@@ -131,6 +188,8 @@ func (db *KV) Open() error {
 	if db.free.tailSeq > 0 {
 		db.free.maxSeq = db.free.tailSeq
 	}
+	db.flush.published = db.saveMeta()
+	db.flush.freeSeq = db.free.tailSeq
 
 	// Setup B+Tree callbacks
 	db.tree.SetCallbacks(
@@ -144,19 +203,68 @@ func (db *KV) Open() error {
 			db.pageFree(ptr)
 		},
 	)
+	// Packed pages can only be written under a meta claiming format 6
+	db.tree.SetPrefixCompression(!db.legacy && db.metaFormat == 0)
 
 	// Recover from WAL if needed
-	if err := db.recoverFromWAL(); err != nil {
-		return fmt.Errorf("WAL recovery failed: %w", err)
+	if db.wal != nil {
+		if err := db.recoverFromWAL(); err != nil {
+			return fmt.Errorf("WAL recovery failed: %w", err)
+		}
+	}
+
+	// Migrations that rewrite records run on the recovered database
+	if err := db.migrateOnOpen(); err != nil {
+		return err
 	}
 
-	// Start checkpointer
-	db.checkpointer = wal.NewCheckpointer(db.wal, db.checkpoint)
-	db.checkpointer.Start()
+	// Start checkpointer (legacy files are never written, so recovered
+	// transactions must stay in the WAL)
+	if !db.legacy {
+		if db.wal != nil {
+			db.checkpointer = wal.NewCheckpointer(db.wal, db.checkpoint)
+			db.checkpointer.Start()
+		}
+		if db.SyncPolicy == SyncInterval {
+			db.startFlusher()
+		}
+	}
 
 	return nil
 }
 
+// openFile opens the database file and its WAL, returning the file's size
+func (db *KV) openFile() (int64, error) {
+	// Create or open file with directory fsync
+	file, err := createFileSync(db.Path)
+	if err != nil {
+		return 0, err
+	}
+	db.file = file
+
+	// Initialize WAL
+	db.wal = &wal.WAL{Path: WALPath(db.Path), Archive: db.WALArchive}
+	if err := db.wal.Open(); err != nil {
+		return 0, fmt.Errorf("failed to open WAL: %w", err)
+	}
+
+	// Every logged transaction uses at least two LSNs, so starting transaction
+	// IDs at the last LSN keeps them unique across restarts
+	db.currentTxnID = db.wal.LastLSN()
+
+	// Get file size
+	stat, err := db.file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("fstat: %w", err)
+	}
+	return stat.Size(), nil
+}
+
+// WALPath returns the base path of the write-ahead log for a database file
+func WALPath(dbPath string) string {
+	return dbPath + ".wal"
+}
+
 // Close closes the database
 func (db *KV) Close() error {
 	// Stop checkpointer
@@ -164,6 +272,11 @@ func (db *KV) Close() error {
 		db.checkpointer.Stop()
 	}
 
+	// Flush commits still waiting
+	if err := db.stopFlusher(); err != nil {
+		return err
+	}
+
 	// Close WAL
 	if db.wal != nil {
 		if err := db.wal.Close(); err != nil {
@@ -171,117 +284,229 @@ func (db *KV) Close() error {
 		}
 	}
 
-	// Unmap all chunks
-	for _, chunk := range db.mmap.chunks {
-		if err := syscall.Munmap(chunk); err != nil {
-			return err
+	// Unmap all chunks; those in memory are left to the garbage collector
+	if !db.heap {
+		for _, chunk := range db.mmap.chunks {
+			if err := munmap(chunk); err != nil {
+				return err
+			}
 		}
 	}
+	db.mmap.chunks, db.mmap.total = nil, 0
 
 	// Close file
-	return syscall.Close(db.fd)
+	if db.file == nil {
+		return nil
+	}
+	return db.file.Close()
 }
 
 // Get retrieves a value by key
+// It panics if the read reaches a corrupt page or an undecryptable value;
+// Lookup reports those as errors instead.
 func (db *KV) Get(key []byte) ([]byte, bool) {
-	return db.tree.Get(key)
+	db.treeMu.RLock()
+	defer db.treeMu.RUnlock()
+
+	val, sealed, found := db.tree.GetSealed(key)
+	if !found {
+		return nil, false
+	}
+	return db.unseal(key, val, sealed), true
+}
+
+// Lookup retrieves a value by key, returning corrupt or undecryptable data as an error
+func (db *KV) Lookup(key []byte) (val []byte, found bool, err error) {
+	defer recoverCorruption(&err)
+	val, found = db.Get(key)
+	return val, found, nil
+}
+
+// GetBatch retrieves several keys with one sorted pass over the tree
+// Results are returned in the same order as the input keys. Like Get, it
+// panics on corrupt data; LookupBatch returns an error instead.
+func (db *KV) GetBatch(keys [][]byte) ([][]byte, []bool) {
+	db.treeMu.RLock()
+	defer db.treeMu.RUnlock()
+
+	vals, sealed, found := db.tree.GetBatchSealed(keys)
+	for i := range vals {
+		if found[i] {
+			vals[i] = db.unseal(keys[i], vals[i], sealed[i])
+		}
+	}
+	return vals, found
+}
+
+// LookupBatch is GetBatch returning corrupt or undecryptable data as an error
+func (db *KV) LookupBatch(keys [][]byte) (vals [][]byte, found []bool, err error) {
+	defer recoverCorruption(&err)
+	vals, found = db.GetBatch(keys)
+	return vals, found, nil
+}
+
+// WAL returns the write-ahead log, e.g. for tailing by a replication follower
+// It is nil for an in-memory database.
+func (db *KV) WAL() *wal.WAL {
+	return db.wal
 }
 
 // Set inserts or updates a key-value pair
 func (db *KV) Set(key []byte, val []byte) error {
-	// Save current meta state for potential rollback
-	meta := db.saveMeta()
+	if db.legacy {
+		return ErrLegacyFormat
+	}
 
-	// Get transaction ID
-	txnID := atomic.AddUint64(&db.currentTxnID, 1)
+	// Set and Del apply one at a time, and never inside an open transaction
+	db.writer.Lock()
+	db.flush.mu.Lock()
 
-	// Write to WAL FIRST
-	entry := wal.Entry{
-		LSN:       db.wal.NextLSN(),
-		TxnID:     txnID,
-		OpType:    wal.OpInsert,
-		Key:       key,
-		Value:     val,
-		Timestamp: time.Now(),
-	}
-	if err := db.wal.Write(entry); err != nil {
+	// Save current meta state for potential rollback
+	meta := db.treeMeta()
+
+	// Update the B+Tree in memory first, so a corrupt page reached on the way
+	// down fails the write before anything is logged
+	entry := db.insertEntry(key, val)
+	if err := db.mutate(meta, func() { db.applyInsert(entry.OpType, key, entry.Value) }); err != nil {
+		db.flush.mu.Unlock()
+		db.writer.Unlock()
 		return err
 	}
 
-	// Fsync WAL
-	if err := db.wal.Fsync(); err != nil {
+	// Write to WAL before any page reaches the file
+	done, err := db.commitLocked(meta, db.flush.epoch, []wal.Entry{entry})
+	db.flush.mu.Unlock()
+	db.writer.Unlock()
+	if err != nil {
 		return err
 	}
+	return db.awaitFlush(done)
+}
 
-	// Write COMMIT marker
-	commitEntry := wal.Entry{
-		LSN:       db.wal.NextLSN(),
-		TxnID:     txnID,
-		OpType:    wal.OpCommit,
-		Timestamp: time.Now(),
+// Del deletes a key
+func (db *KV) Del(key []byte) (bool, error) {
+	if db.legacy {
+		return false, ErrLegacyFormat
 	}
-	if err := db.wal.Write(commitEntry); err != nil {
-		return err
+
+	db.writer.Lock()
+	db.flush.mu.Lock()
+	meta := db.treeMeta()
+
+	// Update tree
+	var deleted bool
+	if err := db.mutate(meta, func() { deleted = db.tree.Delete(key) }); err != nil {
+		db.flush.mu.Unlock()
+		db.writer.Unlock()
+		return false, err
 	}
-	if err := db.wal.Fsync(); err != nil {
-		return err
+	if !deleted {
+		db.flush.mu.Unlock()
+		db.writer.Unlock()
+		return false, nil
 	}
 
-	// Now update B+Tree
-	db.tree.Insert(key, val)
+	// Write DELETE to WAL
+	done, err := db.commitLocked(meta, db.flush.epoch, []wal.Entry{{OpType: wal.OpDelete, Key: key}})
+	db.flush.mu.Unlock()
+	db.writer.Unlock()
+	if err != nil {
+		return false, err
+	}
+	return deleted, db.awaitFlush(done)
+}
 
-	// Two-phase update
-	return db.updateOrRevert(meta)
+// mutate runs an in-memory tree update, reverting to meta if it reaches corrupt data
+func (db *KV) mutate(meta []byte, update func()) (err error) {
+	db.lockTree()
+	defer db.treeMu.Unlock()
+	defer func() {
+		if err != nil {
+			db.revert(meta)
+		}
+	}()
+	defer recoverCorruption(&err)
+	update()
+	return nil
 }
 
-// Del deletes a key
-func (db *KV) Del(key []byte) (bool, error) {
-	meta := db.saveMeta()
+// lockTree takes treeMu exclusively for a change to the tree or its pages
+func (db *KV) lockTree() {
+	db.treeMu.Lock()
+	db.treeGen++
+}
 
-	txnID := atomic.AddUint64(&db.currentTxnID, 1)
+// revert discards in-memory changes made since meta was saved
+// Callers hold treeMu exclusively.
+func (db *KV) revert(meta []byte) {
+	db.loadMeta(meta)
+	db.page.temp = db.page.temp[:0]
+	db.page.updates = make(map[uint64][]byte)
+	db.page.freed = db.page.freed[:0]
+}
 
-	// Write DELETE to WAL
-	entry := wal.Entry{
-		LSN:       db.wal.NextLSN(),
-		TxnID:     txnID,
-		OpType:    wal.OpDelete,
-		Key:       key,
-		Timestamp: time.Now(),
+// logTxn writes a transaction to the WAL between BEGIN and COMMIT markers,
+// returning its ID once anything was written
+// The COMMIT marker follows the operations in the log, so the fsync of the
+// flush publishing the commit makes the whole transaction durable; a torn
+// write leaves it uncommitted.
+func (db *KV) logTxn(ops []wal.Entry) (uint64, error) {
+	if db.wal == nil {
+		return 0, nil // In memory a commit cannot outlive the process, so nothing is logged
 	}
-	if err := db.wal.Write(entry); err != nil {
-		return false, err
+	txnID := atomic.AddUint64(&db.currentTxnID, 1)
+	now := time.Now()
+
+	begin := wal.Entry{LSN: db.wal.NextLSN(), TxnID: txnID, OpType: wal.OpBegin, Timestamp: now}
+	if err := db.wal.Write(begin); err != nil {
+		return 0, err
 	}
-	if err := db.wal.Fsync(); err != nil {
-		return false, err
+	for _, op := range ops {
+		op.LSN = db.wal.NextLSN()
+		op.TxnID = txnID
+		op.Timestamp = now
+		if err := db.wal.Write(op); err != nil {
+			return txnID, err
+		}
 	}
 
-	// Write COMMIT
 	commitEntry := wal.Entry{
 		LSN:       db.wal.NextLSN(),
 		TxnID:     txnID,
 		OpType:    wal.OpCommit,
-		Timestamp: time.Now(),
-	}
-	if err := db.wal.Write(commitEntry); err != nil {
-		return false, err
-	}
-	if err := db.wal.Fsync(); err != nil {
-		return false, err
+		Timestamp: now,
 	}
+	return txnID, db.wal.Write(commitEntry)
+}
 
-	// Update tree
-	deleted := db.tree.Delete(key)
-	if !deleted {
-		return false, nil
+// abortTxn writes the ABORT marker of a logged transaction whose commit was
+// rolled back, so recovery leaves it out even if its COMMIT marker was
+// written. It is synced with the next flush; a crash before then loses
+// nothing, as the rolled-back commit was never acknowledged durable.
+func (db *KV) abortTxn(txnID uint64) error {
+	entry := wal.Entry{
+		LSN:       db.wal.NextLSN(),
+		TxnID:     txnID,
+		OpType:    wal.OpAbort,
+		Timestamp: time.Now(),
 	}
-
-	err := db.updateOrRevert(meta)
-	return deleted, err
+	if err := db.wal.Write(entry); err != nil {
+		return fmt.Errorf("write abort marker of transaction %d: %w", txnID, err)
+	}
+	return nil
 }
 
 // Scan performs a range scan starting from the given key
-func (db *KV) Scan(start []byte, callback func(key, val []byte) bool) {
-	db.tree.Scan(start, callback)
+// Reaching a corrupt page or an undecryptable value stops the scan with an error.
+func (db *KV) Scan(start []byte, callback func(key, val []byte) bool) error {
+	it := db.NewIterator(context.Background())
+	return scanIterator(it, it.Seek, start, callback)
+}
+
+// ScanReverse performs a descending range scan starting from the given key
+func (db *KV) ScanReverse(start []byte, callback func(key, val []byte) bool) error {
+	it := db.NewIterator(context.Background())
+	return scanIterator(it, it.SeekReverse, start, callback)
 }
 
 // pageRead reads a page by pointer
@@ -305,11 +530,15 @@ func (db *KV) pageRead(ptr uint64) []byte {
 		end := start + uint64(len(chunk))/BTREE_PAGE_SIZE
 		if ptr < end {
 			offset := BTREE_PAGE_SIZE * (ptr - start)
-			return chunk[offset : offset+BTREE_PAGE_SIZE]
+			page := chunk[offset : offset+BTREE_PAGE_SIZE]
+			if !db.legacy && !pageChecksumOK(page) {
+				panic(&CorruptPageError{Path: db.Path, Ptr: ptr})
+			}
+			return page
 		}
 		start = end
 	}
-	panic(fmt.Sprintf("bad page pointer: %d (flushed: %d, temp: %d)", ptr, db.page.flushed, len(db.page.temp)))
+	panic(&CorruptPageError{Path: db.Path, Ptr: ptr, Err: ErrBadPointer})
 }
 
 // pageAlloc allocates a new page (tries free list first)
@@ -318,8 +547,12 @@ func (db *KV) pageAlloc(node []byte) uint64 {
 		panic("page size mismatch")
 	}
 
-	// Try to get a page from free list
-	ptr := db.free.PopHead()
+	// Try to get a page from free list (legacy free list nodes have another
+	// capacity, so legacy files only append)
+	ptr := uint64(0)
+	if !db.legacy {
+		ptr = db.free.PopHead()
+	}
 	if ptr != 0 {
 		// Reuse freed page
 		db.page.updates[ptr] = node
@@ -346,18 +579,35 @@ func (db *KV) pageWrite(ptr uint64, node []byte) {
 	if len(node) != BTREE_PAGE_SIZE {
 		panic("page size mismatch")
 	}
+
+	// Pages appended by this transaction are written from temp, after updates
+	if ptr >= db.page.flushed {
+		db.page.temp[ptr-db.page.flushed] = node
+		return
+	}
 	db.page.updates[ptr] = node
 }
 
 // pageFree adds a page to the free list
 func (db *KV) pageFree(ptr uint64) {
-	// Only free pages that were already flushed to disk
 	// Temp pages can't be reused until they're committed
+	if db.legacy {
+		return
+	}
 	if ptr < db.page.flushed {
 		db.free.PushTail(ptr)
+	} else {
+		db.page.freed = append(db.page.freed, ptr)
 	}
 }
 
+// treeMeta saves the meta state of the tree as readers see it
+func (db *KV) treeMeta() []byte {
+	db.treeMu.RLock()
+	defer db.treeMu.RUnlock()
+	return db.saveMeta()
+}
+
 // saveMeta saves current meta state to byte slice
 func (db *KV) saveMeta() []byte {
 	var data [META_PAGE_SIZE]byte
@@ -369,6 +619,16 @@ func (db *KV) saveMeta() []byte {
 	freeData := db.free.Serialize()
 	copy(data[32:], freeData)
 
+	// Meta slots are always laid out in the newest format
+	format := latestFormat()
+	if db.metaFormat != 0 {
+		format = db.metaFormat
+	}
+	binary.LittleEndian.PutUint32(data[metaFormatOffset:], format)
+	if format >= 5 {
+		binary.LittleEndian.PutUint64(data[metaWALOffset:], db.walLSN)
+	}
+
 	return data[:]
 }
 
@@ -379,40 +639,122 @@ func (db *KV) loadMeta(data []byte) {
 
 	// Load free list metadata
 	db.free.Deserialize(data[32:72])
+	db.walLSN = metaWALLSN(data)
+}
+
+// metaWALLSN returns the flushed-through WAL LSN a meta slot records, 0 for
+// formats before 5
+func metaWALLSN(slot []byte) uint64 {
+	if string(slot[:16]) != DB_SIG || metaSlotFormat(slot) < 5 {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(slot[metaWALOffset:])
 }
 
-// readMeta reads and validates meta page from disk
+// readMeta loads the newest valid meta slot from disk
 func (db *KV) readMeta() error {
-	data := db.mmap.chunks[0][:META_PAGE_SIZE]
+	page := db.mmap.chunks[0]
 
-	// Verify signature
-	sig := string(data[:16])
-	if sig != DB_SIG {
+	sig := string(page[:16])
+	if sig == DB_SIG_V1 {
+		db.legacy = true
+		db.format = 1
+		db.loadMeta(page[:META_PAGE_SIZE])
+		return nil
+	}
+
+	var newest []byte
+	signed := false // Some slot carries a known signature
+	for _, offset := range []int{0, META_SLOT_B} {
+		slot := page[offset : offset+META_PAGE_SIZE]
+		if s := string(slot[:16]); s == DB_SIG || s == DB_SIG_V3 || s == DB_SIG_V2 {
+			signed = true
+		}
+		gen, ok := metaSlotGeneration(slot)
+		if ok && (newest == nil || gen > db.generation) {
+			newest = slot
+			db.generation = gen
+		}
+	}
+
+	switch {
+	case newest != nil:
+		db.loadMeta(newest)
+		db.format = metaSlotFormat(newest)
+		return nil
+	case signed:
+		return &CorruptPageError{Path: db.Path}
+	default:
 		return fmt.Errorf("invalid database signature: %s", sig)
 	}
+}
 
-	db.loadMeta(data)
-	return nil
+// metaSlotGeneration returns the generation of a meta slot, if it is valid
+// The single meta page of TreeStore02 files counts as generation 0 in slot A,
+// so the first commit upgrades it by writing slot B.
+func metaSlotGeneration(slot []byte) (uint64, bool) {
+	switch string(slot[:16]) {
+	case DB_SIG:
+		if metaChecksumOK(slot) {
+			return binary.LittleEndian.Uint64(slot[metaGenerationOffset:]), true
+		}
+	case DB_SIG_V3:
+		if metaChecksumOKV3(slot) {
+			return binary.LittleEndian.Uint64(slot[metaGenerationOffset:]), true
+		}
+	case DB_SIG_V2:
+		if metaChecksumOKV2(slot) {
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+// metaSlotFormat returns the format version of a valid meta slot
+func metaSlotFormat(slot []byte) uint32 {
+	switch string(slot[:16]) {
+	case DB_SIG_V3:
+		return 3
+	case DB_SIG_V2:
+		return 2
+	}
+	return binary.LittleEndian.Uint32(slot[metaFormatOffset:])
+}
+
+// metaSlotOffset returns the file offset of the slot holding a generation
+func metaSlotOffset(gen uint64) int64 {
+	if gen%2 == 0 {
+		return 0
+	}
+	return META_SLOT_B
 }
 
 // updateOrRevert performs two-phase update with error recovery
+// Callers hold treeMu exclusively.
 func (db *KV) updateOrRevert(meta []byte) error {
 	// Recover from previous failure
 	if db.failed {
 		if err := db.writeMeta(meta); err != nil {
 			return err
 		}
-		if err := syscall.Fsync(db.fd); err != nil {
+		if err := db.fsync(PhaseSyncMeta); err != nil {
 			return err
 		}
 		db.failed = false
 	}
 
+	// Temporary pages are written even when the transaction freed them again,
+	// so list them as free once committed rather than leaking them
+	for _, ptr := range db.page.freed {
+		db.free.PushTail(ptr)
+	}
+	db.page.freed = db.page.freed[:0]
+
 	// Save current tailSeq and freeze free list for this transaction
 	savedMaxSeq := db.free.maxSeq
 	db.free.SetMaxSeq()
 
-	// Two-phase update
+	// Write the pages; the meta follows now under SyncNever, else with the next flush
 	err := db.updateFile()
 
 	if err != nil {
@@ -421,7 +763,12 @@ func (db *KV) updateOrRevert(meta []byte) error {
 		db.page.temp = db.page.temp[:0]
 		db.page.updates = make(map[uint64][]byte)
 		db.free.maxSeq = savedMaxSeq
-		db.failed = true
+		db.failed = db.SyncPolicy == SyncNever // Otherwise only flushes write the meta
+	} else if db.SyncPolicy != SyncNever {
+		// The meta on disk still references pages freed since the last flush
+		db.flush.meta = db.saveMeta()
+		db.flush.pending++
+		db.free.maxSeq = db.flush.freeSeq
 	} else {
 		// Success - all freed pages including newly freed ones are now available
 		db.free.maxSeq = db.free.tailSeq
@@ -455,6 +802,9 @@ func (db *KV) writeCheckpointMarker() {
 		return
 	}
 
+	if db.SyncPolicy == SyncNever {
+		return
+	}
 	if err := db.wal.Fsync(); err != nil {
 		// TODO: Add structured logging when logging infrastructure is available
 		fmt.Printf("WARNING: checkpoint marker fsync failed: %v\n", err)
@@ -468,39 +818,107 @@ func (db *KV) updateFile() error {
 		return err
 	}
 
+	// The meta waits for the next flush, which syncs the pages first
+	if db.SyncPolicy != SyncNever {
+		return nil
+	}
+
 	// Phase 2: fsync to ensure pages are durable
-	if err := syscall.Fsync(db.fd); err != nil {
+	if err := db.fsync(PhaseSyncPages); err != nil {
 		return err
 	}
 
 	// Phase 3: Update meta page atomically
-	if err := db.writeMeta(db.saveMeta()); err != nil {
+	meta := db.saveMeta()
+	if err := db.writeMeta(meta); err != nil {
 		return err
 	}
 
 	// Phase 4: fsync to make meta page durable
-	return syscall.Fsync(db.fd)
+	if err := db.fsync(PhaseSyncMeta); err != nil {
+		return err
+	}
+	db.noteFlushed(meta)
+	return nil
+}
+
+// noteFlushed tells the WAL which entries a meta page just made durable
+// holds, so it keeps the log files holding later ones
+func (db *KV) noteFlushed(meta []byte) {
+	if lsn := metaWALLSN(meta); lsn != 0 && db.wal != nil {
+		db.wal.SetFlushedLSN(lsn)
+	}
+}
+
+// fsync flushes the database file, unless a failpoint rejects the phase
+func (db *KV) fsync(phase CommitPhase) error {
+	if err := db.fail(phase); err != nil {
+		return err
+	}
+	if db.SyncPolicy == SyncNever || db.file == nil {
+		return nil
+	}
+	return db.file.Sync()
 }
 
 // recoverFromWAL replays the WAL to recover from crashes
+// Replay starts after the LSN the meta page on disk records, if any. The
+// replayed commits are written out at once, so the file covers them before
+// a checkpoint marker or log retirement could pass over them.
 func (db *KV) recoverFromWAL() error {
 	recovery := wal.NewRecovery(db.wal)
+	recovery.FlushedLSN = db.walLSN
+	if db.walLSN != 0 {
+		db.wal.SetFlushedLSN(db.walLSN)
+	}
 
-	return recovery.Recover(func(op wal.OpType, key, value []byte) error {
+	stats, err := recovery.RecoverWithStats(func(op wal.OpType, key, value []byte) error {
 		switch op {
-		case wal.OpInsert:
-			db.tree.Insert(key, value)
+		case wal.OpInsert, wal.OpInsertSealed:
+			db.applyInsert(op, key, value)
 		case wal.OpDelete:
 			db.tree.Delete(key)
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	db.recovery = *stats
+	db.walLSN = db.wal.LastLSN()
+	if stats.ReplayedOperations == 0 || db.legacy {
+		return nil
+	}
+	return db.persist()
 }
 
-// checkpoint flushes current state to disk
-func (db *KV) checkpoint() error {
-	// Flush current state to disk
-	return db.updateFile()
+// checkpoint flushes current state to disk, returning the WAL LSN through
+// which the file holds every entry
+func (db *KV) checkpoint() (uint64, error) {
+	if db.legacy {
+		return 0, nil
+	}
+
+	// Commits are already written; publish them
+	if db.SyncPolicy != SyncNever {
+		if err := db.Flush(); err != nil {
+			return 0, err
+		}
+		return db.wal.FlushedLSN(), nil
+	}
+
+	// Flush current state to disk, which an open transaction must not reach
+	db.writer.Lock()
+	db.flush.mu.Lock()
+	db.lockTree()
+	err := db.updateFile()
+	db.treeMu.Unlock()
+	db.flush.mu.Unlock()
+	db.writer.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	return db.wal.FlushedLSN(), nil
 }
 
 // writePages writes temporary pages to disk
@@ -508,7 +926,11 @@ func (db *KV) writePages() error {
 	// Write in-place updates first
 	for ptr, page := range db.page.updates {
 		offset := int64(ptr * BTREE_PAGE_SIZE)
-		if _, err := syscall.Pwrite(db.fd, page, offset); err != nil {
+		if err := db.fail(PhaseWritePage); err != nil {
+			return err
+		}
+		stampPage(page)
+		if err := db.writeAt(page, offset); err != nil {
 			return err
 		}
 	}
@@ -530,7 +952,11 @@ func (db *KV) writePages() error {
 	// Write pages
 	offset := int64(db.page.flushed * BTREE_PAGE_SIZE)
 	for _, page := range db.page.temp {
-		if _, err := syscall.Pwrite(db.fd, page, offset); err != nil {
+		if err := db.fail(PhaseWritePage); err != nil {
+			return err
+		}
+		stampPage(page)
+		if err := db.writeAt(page, offset); err != nil {
 			return err
 		}
 		offset += BTREE_PAGE_SIZE
@@ -543,12 +969,24 @@ func (db *KV) writePages() error {
 	return nil
 }
 
-// writeMeta writes meta page at offset 0
+// writeMeta writes the next generation into the slot not holding the newest one
+// A torn write only damages that slot, so the previous meta stays readable.
 func (db *KV) writeMeta(data []byte) error {
-	_, err := syscall.Pwrite(db.fd, data, 0)
-	if err != nil {
+	if err := db.fail(PhaseWriteMeta); err != nil {
+		return err
+	}
+
+	gen := db.generation + 1
+	slot := make([]byte, META_PAGE_SIZE)
+	copy(slot, data)
+	binary.LittleEndian.PutUint64(slot[metaGenerationOffset:], gen)
+	stampMeta(slot)
+
+	if err := db.writeAt(slot, metaSlotOffset(gen)); err != nil {
 		return fmt.Errorf("write meta page: %w", err)
 	}
+	db.generation = gen
+	db.format = binary.LittleEndian.Uint32(slot[metaFormatOffset:])
 	return nil
 }
 
@@ -559,16 +997,23 @@ func (db *KV) extendMmap(size int) error {
 	}
 
 	// Double the allocation size
-	alloc := max(db.mmap.total, 64<<20)
+	first := 64 << 20
+	if db.heap {
+		first = memoryChunkSize
+	}
+	alloc := max(db.mmap.total, first)
 	for db.mmap.total+alloc < size {
 		alloc *= 2
 	}
 
+	if db.heap {
+		db.mmap.total += alloc
+		db.mmap.chunks = append(db.mmap.chunks, make([]byte, alloc))
+		return nil
+	}
+
 	// Create new mapping
-	chunk, err := syscall.Mmap(
-		db.fd, int64(db.mmap.total), alloc,
-		syscall.PROT_READ, syscall.MAP_SHARED,
-	)
+	chunk, err := mmap(db.file, int64(db.mmap.total), alloc)
 	if err != nil {
 		return fmt.Errorf("mmap: %w", err)
 	}
@@ -579,32 +1024,6 @@ func (db *KV) extendMmap(size int) error {
 	return nil
 }
 
-// createFileSync creates/opens file with directory fsync
-func createFileSync(file string) (int, error) {
-	// Open or create file
-	flags := os.O_RDWR | os.O_CREATE
-	fd, err := syscall.Open(file, flags, 0o644)
-	if err != nil {
-		return -1, fmt.Errorf("open file: %w", err)
-	}
-
-	// Open directory for fsync
-	dirfd, err := syscall.Open(path.Dir(file), os.O_RDONLY, 0)
-	if err != nil {
-		_ = syscall.Close(fd)
-		return -1, fmt.Errorf("open directory: %w", err)
-	}
-	defer syscall.Close(dirfd)
-
-	// Fsync directory
-	if err = syscall.Fsync(dirfd); err != nil {
-		_ = syscall.Close(fd)
-		return -1, fmt.Errorf("fsync directory: %w", err)
-	}
-
-	return fd, nil
-}
-
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -269,3 +269,47 @@ func TestKVReopenAfterWrites(t *testing.T) {
 		}
 	}
 }
+
+func TestKVGetBatch(t *testing.T) {
+	path := "/tmp/test_kv_getbatch.db"
+	defer os.Remove(path)
+
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx := db.Begin()
+	for i := 0; i < 300; i++ {
+		tx.Set([]byte(fmt.Sprintf("key%05d", i)), []byte(fmt.Sprintf("value%05d", i)))
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	keys := [][]byte{
+		[]byte("key00250"),
+		[]byte("key00001"),
+		[]byte("nokey"),
+		[]byte("key00120"),
+	}
+	vals, found := db.GetBatch(keys)
+
+	expected := []string{"value00250", "value00001", "", "value00120"}
+	for i, want := range expected {
+		if want == "" {
+			if found[i] {
+				t.Errorf("Key %s should not be found", keys[i])
+			}
+			continue
+		}
+		if !found[i] {
+			t.Errorf("Key %s not found", keys[i])
+			continue
+		}
+		if string(vals[i]) != want {
+			t.Errorf("Key %s: expected %s, got %s", keys[i], want, vals[i])
+		}
+	}
+}
@@ -0,0 +1,23 @@
+// ABOUTME: In-memory databases keeping their pages on the heap instead of in a file
+// ABOUTME: Selected with the MemoryPath path, for tests and ephemeral stores
+
+package storage
+
+import "errors"
+
+// MemoryPath opens a database held entirely in memory. It has no file and no
+// WAL, so it starts empty and its contents are lost on Close. Every KV opened
+// with it is a separate database.
+const MemoryPath = ":memory:"
+
+// ErrInMemory indicates an operation that needs the database file or its WAL
+var ErrInMemory = errors.New("storage: in-memory database has no file or WAL")
+
+// memoryChunkSize is the first heap allocation of an in-memory database, or of
+// one not mapped from its file. Later ones double like the mappings of a file.
+const memoryChunkSize = 1 << 20
+
+// InMemory reports whether the database was opened with MemoryPath
+func (db *KV) InMemory() bool {
+	return db.memory
+}
@@ -0,0 +1,107 @@
+// ABOUTME: Tests for in-memory databases
+// ABOUTME: Checks they behave like a file without touching the disk and start empty every time
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestMemoryKV(t *testing.T) {
+	db := &KV{Path: MemoryPath}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	if !db.InMemory() || db.WAL() != nil {
+		t.Fatal("Expected an in-memory database without a WAL")
+	}
+
+	// Enough data to outgrow the first chunk
+	val := make([]byte, 1000)
+	for i := 0; i < 3000; i++ {
+		if err := db.Set([]byte(fmt.Sprintf("key%05d", i)), val); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	for i := 0; i < 3000; i += 2 {
+		if _, err := db.Del([]byte(fmt.Sprintf("key%05d", i))); err != nil {
+			t.Fatalf("Del failed: %v", err)
+		}
+	}
+
+	tx := db.Begin()
+	tx.Set([]byte("txn"), []byte("committed"))
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if _, ok := db.Get([]byte("key00000")); ok {
+		t.Error("Expected deleted key to be gone")
+	}
+	if got, ok := db.Get([]byte("key00001")); !ok || len(got) != len(val) {
+		t.Errorf("Expected key00001, got %d bytes (%v)", len(got), ok)
+	}
+	if got, _ := db.Get([]byte("txn")); string(got) != "committed" {
+		t.Errorf("Expected the transaction's value, got %q", got)
+	}
+	if report, err := db.Verify(); err != nil || len(report.Corrupt) != 0 {
+		t.Errorf("Verify found damage: %+v (%v)", report, err)
+	}
+
+	if _, err := os.Stat(MemoryPath); !os.IsNotExist(err) {
+		t.Errorf("Expected no file named %s, got %v", MemoryPath, err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Each open is a new, empty database
+	db = &KV{Path: MemoryPath}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	defer db.Close()
+	if _, ok := db.Get([]byte("key00001")); ok {
+		t.Error("Expected a reopened in-memory database to be empty")
+	}
+}
+
+func TestMemoryKVSyncInterval(t *testing.T) {
+	db := &KV{Path: MemoryPath, SyncPolicy: SyncInterval}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	db.Set([]byte("a"), []byte("1"))
+	if err := db.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if stats := db.SyncStats(); stats.Pending != 0 || stats.Flushes == 0 {
+		t.Errorf("Expected the commit flushed, got %+v", stats)
+	}
+	if got, _ := db.Get([]byte("a")); string(got) != "1" {
+		t.Errorf("Expected 1, got %q", got)
+	}
+}
+
+func TestMemoryKVFailedFlush(t *testing.T) {
+	db := &KV{Path: MemoryPath}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	db.Set([]byte("a"), []byte("old"))
+	db.SetFailpoint(failAt(PhaseSyncMeta, 1, func() error { return errInjected }))
+	if err := db.Set([]byte("a"), []byte("new")); !errors.Is(err, errInjected) {
+		t.Fatalf("Set error = %v, want injected fault", err)
+	}
+	db.SetFailpoint(nil)
+	if got, _ := db.Get([]byte("a")); string(got) != "old" {
+		t.Errorf("Expected the failed commit rolled back, got %q", got)
+	}
+}
@@ -0,0 +1,27 @@
+// ABOUTME: Fallbacks for platforms without mmap, such as Windows
+// ABOUTME: Pages are read from a copy of the file kept in memory instead
+
+//go:build !unix
+
+package storage
+
+import (
+	"errors"
+	"os"
+)
+
+const mmapSupported = false
+
+func mmap(f *os.File, offset int64, size int) ([]byte, error) {
+	return nil, errors.New("mmap is not supported on this platform")
+}
+
+func munmap(chunk []byte) error {
+	return nil
+}
+
+// syncDir does nothing: directories cannot be synced here, and the file
+// systems journal their entries
+func syncDir(dir string) error {
+	return nil
+}
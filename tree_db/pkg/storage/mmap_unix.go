@@ -0,0 +1,36 @@
+// ABOUTME: Memory mapping of the database file on platforms that support it
+// ABOUTME: Also syncs directories, so a created file survives a crash
+
+//go:build unix
+
+package storage
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapSupported reports whether pages can be read through a mapping of the file
+const mmapSupported = true
+
+// mmap maps size bytes of f from offset read-only
+// The mapping may extend past the end of the file; pages written there later
+// become visible through it.
+func mmap(f *os.File, offset int64, size int) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), offset, size, syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+// munmap releases a mapping returned by mmap
+func munmap(chunk []byte) error {
+	return syscall.Munmap(chunk)
+}
+
+// syncDir makes the entries of a directory durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,64 @@
+// ABOUTME: Keys of the most recent inserts, kept so background checks can re-read them
+// ABOUTME: A ring of fixed size filled by commits; tracking is off until TrackWrites sizes it
+
+package storage
+
+import "github.com/nainya/treestore/pkg/wal"
+
+// recentWrites is a ring of the keys most recently inserted, guarded by flush.mu
+type recentWrites struct {
+	keys [][]byte
+	next int // Slot of the next key
+	full bool
+}
+
+// TrackWrites keeps the keys of the last n inserts for RecentWrites, dropping
+// any tracked so far; 0 stops tracking
+func (db *KV) TrackWrites(n int) {
+	db.flush.mu.Lock()
+	defer db.flush.mu.Unlock()
+	db.flush.recent = recentWrites{keys: make([][]byte, max(n, 0))}
+}
+
+// RecentWrites returns the tracked keys, oldest first, once each. Keys deleted
+// since they were written are still listed.
+func (db *KV) RecentWrites() [][]byte {
+	db.flush.mu.Lock()
+	defer db.flush.mu.Unlock()
+
+	r := &db.flush.recent
+	var keys [][]byte
+	if r.full {
+		keys = append(keys, r.keys[r.next:]...)
+	}
+	keys = append(keys, r.keys[:r.next]...)
+
+	// Keep the newest write of keys written more than once
+	seen := make(map[string]bool, len(keys))
+	out := make([][]byte, 0, len(keys))
+	for i := len(keys) - 1; i >= 0; i-- {
+		if !seen[string(keys[i])] {
+			seen[string(keys[i])] = true
+			out = append(out, keys[i])
+		}
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// note records the keys a commit inserted. Callers hold flush.mu.
+func (r *recentWrites) note(ops []wal.Entry) {
+	if len(r.keys) == 0 {
+		return
+	}
+	for _, op := range ops {
+		if op.OpType != wal.OpInsert && op.OpType != wal.OpInsertSealed {
+			continue
+		}
+		r.keys[r.next] = append([]byte(nil), op.Key...)
+		r.next = (r.next + 1) % len(r.keys)
+		r.full = r.full || r.next == 0
+	}
+}
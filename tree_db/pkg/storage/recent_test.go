@@ -0,0 +1,45 @@
+// ABOUTME: Tests for tracking the keys of recent inserts
+// ABOUTME: Verifies the ring keeps the newest keys once each, covers transactions and skips deletes
+
+package storage
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRecentWrites(t *testing.T) {
+	db := &KV{Path: MemoryPath}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	db.Set([]byte("untracked"), []byte("v"))
+	if keys := db.RecentWrites(); len(keys) != 0 {
+		t.Errorf("Expected no keys before tracking, got %q", keys)
+	}
+
+	db.TrackWrites(3)
+	for i := 0; i < 4; i++ {
+		db.Set([]byte(fmt.Sprintf("k%d", i)), []byte("v"))
+	}
+	db.Del([]byte("k3"))
+	tx := db.Begin()
+	tx.Set([]byte("k1"), []byte("again"))
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	// k0 fell out of the ring; k1 was rewritten last and is listed once
+	got := fmt.Sprintf("%q", db.RecentWrites())
+	if want := `["k2" "k3" "k1"]`; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+
+	db.TrackWrites(0)
+	db.Set([]byte("k4"), []byte("v"))
+	if keys := db.RecentWrites(); len(keys) != 0 {
+		t.Errorf("Expected tracking stopped, got %q", keys)
+	}
+}
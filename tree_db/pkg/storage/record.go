@@ -0,0 +1,171 @@
+// ABOUTME: Tag-length-value records for the values stores keep under their keys
+// ABOUTME: Fields are found by tag, so readers default the fields a record lacks and skip those they do not know
+
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// RECORD_MARKER starts every record. Values encoded with EncodeValues start
+// with a type tag instead, so readers can tell records from those written
+// before stores moved to them.
+const RECORD_MARKER = 0xFE
+
+// maxRecordTag bounds field tags, so a damaged record cannot make a reader
+// allocate for a huge one
+const maxRecordTag = 1024
+
+// RecordWriter builds a record field by field. Each field is its tag and
+// length as uvarints followed by its bytes; integers are varints and times
+// Unix seconds, as in keys.
+type RecordWriter struct {
+	buf []byte
+}
+
+// NewRecordWriter starts a record of the given schema version, which readers
+// see as Record.Schema
+func NewRecordWriter(schema uint8) *RecordWriter {
+	return &RecordWriter{buf: append(make([]byte, 0, 128), RECORD_MARKER, schema)}
+}
+
+// Bytes adds a bytes field
+func (w *RecordWriter) Bytes(tag int, b []byte) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(tag))
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+// String adds a string field
+func (w *RecordWriter) String(tag int, s string) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(tag))
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+// Int adds a signed integer field
+func (w *RecordWriter) Int(tag int, i int64) {
+	w.Bytes(tag, binary.AppendVarint(nil, i))
+}
+
+// Uint adds an unsigned integer field
+func (w *RecordWriter) Uint(tag int, u uint64) {
+	w.Bytes(tag, binary.AppendUvarint(nil, u))
+}
+
+// Bool adds a boolean field
+func (w *RecordWriter) Bool(tag int, b bool) {
+	var u uint64
+	if b {
+		u = 1
+	}
+	w.Uint(tag, u)
+}
+
+// Time adds a time field with second precision
+func (w *RecordWriter) Time(tag int, t time.Time) {
+	w.Int(tag, t.Unix())
+}
+
+// Encode returns the record
+func (w *RecordWriter) Encode() []byte {
+	return w.buf
+}
+
+// IsRecord reports whether val was written by a RecordWriter rather than
+// EncodeValues
+func IsRecord(val []byte) bool {
+	return len(val) > 0 && val[0] == RECORD_MARKER
+}
+
+// Record is a decoded record. Getters return the zero value for fields the
+// record lacks, and for integer fields that do not decode.
+type Record struct {
+	Schema uint8    // Schema version the record was written with
+	fields [][]byte // Field bytes by tag, nil where absent
+}
+
+// DecodeRecord decodes a record written by a RecordWriter. The record keeps
+// references to val, so val must not change while it is in use.
+func DecodeRecord(val []byte) (*Record, error) {
+	if !IsRecord(val) || len(val) < 2 {
+		return nil, fmt.Errorf("not a record")
+	}
+
+	r := &Record{Schema: val[1]}
+	pos := 2
+	for pos < len(val) {
+		tag, n := binary.Uvarint(val[pos:])
+		if n <= 0 || tag > maxRecordTag {
+			return nil, fmt.Errorf("bad field tag at byte %d", pos)
+		}
+		pos += n
+
+		length, n := binary.Uvarint(val[pos:])
+		if n <= 0 || length > uint64(len(val)-pos-n) {
+			return nil, fmt.Errorf("bad length of field %d at byte %d", tag, pos)
+		}
+		pos += n
+
+		if int(tag) >= len(r.fields) {
+			r.fields = append(r.fields, make([][]byte, int(tag)+1-len(r.fields))...)
+		}
+		r.fields[tag] = val[pos : pos+int(length) : pos+int(length)]
+		pos += int(length)
+	}
+	return r, nil
+}
+
+// Has reports whether the record holds a field
+func (r *Record) Has(tag int) bool {
+	return tag < len(r.fields) && r.fields[tag] != nil
+}
+
+// Bytes returns a copy of a bytes field
+func (r *Record) Bytes(tag int) []byte {
+	if !r.Has(tag) {
+		return nil
+	}
+	return append([]byte{}, r.fields[tag]...)
+}
+
+// String returns a string field
+func (r *Record) String(tag int) string {
+	if !r.Has(tag) {
+		return ""
+	}
+	return string(r.fields[tag])
+}
+
+// Int returns a signed integer field
+func (r *Record) Int(tag int) int64 {
+	if !r.Has(tag) {
+		return 0
+	}
+	i, _ := binary.Varint(r.fields[tag])
+	return i
+}
+
+// Uint returns an unsigned integer field
+func (r *Record) Uint(tag int) uint64 {
+	if !r.Has(tag) {
+		return 0
+	}
+	u, _ := binary.Uvarint(r.fields[tag])
+	return u
+}
+
+// Bool returns a boolean field
+func (r *Record) Bool(tag int) bool {
+	return r.Uint(tag) != 0
+}
+
+// Time returns a time field, the zero time if absent
+func (r *Record) Time(tag int) time.Time {
+	if !r.Has(tag) {
+		return time.Time{}
+	}
+	return time.Unix(r.Int(tag), 0)
+}
@@ -0,0 +1,67 @@
+// ABOUTME: Tests for tag-length-value records
+// ABOUTME: Verifies field round trips, defaults for missing fields, unknown fields and damaged records
+
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	w := NewRecordWriter(3)
+	w.String(1, "LCD-1")
+	w.Bytes(2, []byte{0, 0xFE, 0xFF})
+	w.Int(3, -42)
+	w.Uint(4, 1<<40)
+	w.Bool(5, true)
+	w.Time(6, at)
+	w.Time(7, time.Time{})
+	w.String(900, "from a newer schema")
+	val := w.Encode()
+
+	if !IsRecord(val) || IsRecord(EncodeValues([]Value{NewBytesValue([]byte("x"))})) {
+		t.Fatal("Expected records told apart from encoded values")
+	}
+	r, err := DecodeRecord(val)
+	if err != nil {
+		t.Fatalf("DecodeRecord failed: %v", err)
+	}
+	if r.Schema != 3 || r.String(1) != "LCD-1" || string(r.Bytes(2)) != "\x00\xfe\xff" || r.Int(3) != -42 ||
+		r.Uint(4) != 1<<40 || !r.Bool(5) || !r.Time(6).Equal(at) || !r.Time(7).IsZero() {
+		t.Errorf("Fields changed in the round trip: %+v", r)
+	}
+
+	// Fields the record lacks read as zero values
+	if r.Has(8) || r.String(8) != "" || r.Int(8) != 0 || r.Bool(8) || !r.Time(8).IsZero() || r.Bytes(8) != nil {
+		t.Error("Expected zero values for a missing field")
+	}
+	// An empty field is present
+	w = NewRecordWriter(1)
+	w.String(1, "")
+	if r, _ := DecodeRecord(w.Encode()); !r.Has(1) {
+		t.Error("Expected an empty field to be present")
+	}
+}
+
+func TestRecordDamaged(t *testing.T) {
+	w := NewRecordWriter(1)
+	w.String(1, "abc")
+	good := w.Encode()
+
+	tests := map[string][]byte{
+		"empty":       {},
+		"tuple":       EncodeValues([]Value{NewInt64Value(1)}),
+		"no schema":   {RECORD_MARKER},
+		"truncated":   good[:len(good)-1],
+		"no length":   {RECORD_MARKER, 1, 1},
+		"huge tag":    {RECORD_MARKER, 1, 0xff, 0xff, 0x7f, 0},
+		"long length": {RECORD_MARKER, 1, 1, 0xff, 0x7f},
+	}
+	for name, val := range tests {
+		if _, err := DecodeRecord(val); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
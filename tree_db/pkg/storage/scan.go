@@ -0,0 +1,55 @@
+// ABOUTME: Scans bounded by a key prefix, such as one table or one policy's entries in it
+// ABOUTME: They stop at the prefix boundary themselves, so callbacks see only keys that begin with it
+
+package storage
+
+import "bytes"
+
+// Scanner is what the prefix scans read: an Engine or a Txn
+type Scanner interface {
+	Scan(start []byte, callback func(key, val []byte) bool) error
+	ScanReverse(start []byte, callback func(key, val []byte) bool) error
+}
+
+// ScanPrefix visits the keys that begin with prefix in ascending order until
+// callback returns false. A prefix from EncodeKey selects the keys of a
+// prefix whose leading columns equal its values:
+//
+//	storage.ScanPrefix(kv, storage.EncodeKey(PREFIX_NODE, []storage.Value{policy}), fn)
+//
+// visits every node of one policy and none of the next prefix's keys, even
+// those that begin with the same values.
+func ScanPrefix(s Scanner, prefix []byte, callback func(key, val []byte) bool) error {
+	return ScanPrefixFrom(s, prefix, prefix, callback)
+}
+
+// ScanPrefixFrom is ScanPrefix starting at start, such as a cursor within
+// the prefix; a start before the prefix starts at its first key
+func ScanPrefixFrom(s Scanner, prefix, start []byte, callback func(key, val []byte) bool) error {
+	if bytes.Compare(start, prefix) < 0 {
+		start = prefix
+	}
+	return s.Scan(start, func(key, val []byte) bool {
+		if !bytes.HasPrefix(key, prefix) {
+			return false
+		}
+		return callback(key, val)
+	})
+}
+
+// ScanPrefixReverse visits the keys that begin with prefix in descending
+// order from start, or from the last of them when start is nil or past them,
+// until callback returns false. prefix must come from EncodeKey, whose keys
+// never continue with 0xFF.
+func ScanPrefixReverse(s Scanner, prefix, start []byte, callback func(key, val []byte) bool) error {
+	end := append(append([]byte(nil), prefix...), 0xFF)
+	if start == nil || bytes.Compare(start, end) > 0 {
+		start = end
+	}
+	return s.ScanReverse(start, func(key, val []byte) bool {
+		if !bytes.HasPrefix(key, prefix) {
+			return false
+		}
+		return callback(key, val)
+	})
+}
@@ -0,0 +1,69 @@
+// ABOUTME: Tests for prefix-bounded scans
+// ABOUTME: Verifies they stop at the prefix boundary in both directions, in KV and in transactions
+
+package storage
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScanPrefixBoundary(t *testing.T) {
+	db := &KV{Path: MemoryPath}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	key := func(prefix uint32, policy, node string) []byte {
+		return EncodeKey(prefix, []Value{NewBytesValue([]byte(policy)), NewBytesValue([]byte(node))})
+	}
+	// The next prefix holds keys of the same policy, and another policy
+	// shares the first policy's name as a leading substring
+	for _, k := range [][]byte{
+		key(1000, "p1", "a"), key(1000, "p1", "b"), key(1000, "p1", "c"),
+		key(1000, "p10", "a"), key(1001, "p1", "a"), key(999, "p1", "z"),
+	} {
+		if err := db.Set(k, []byte("v")); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	policy := EncodeKey(1000, []Value{NewBytesValue([]byte("p1"))})
+	collect := func(scan func(fn func(key, val []byte) bool) error) string {
+		var nodes []string
+		if err := scan(func(key, val []byte) bool {
+			vals, _ := ExtractValues(key)
+			nodes = append(nodes, fmt.Sprintf("%d/%s/%s", ExtractPrefix(key), vals[0].Str, vals[1].Str))
+			return true
+		}); err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		return fmt.Sprint(nodes)
+	}
+
+	if got := collect(func(fn func(key, val []byte) bool) error { return ScanPrefix(db, policy, fn) }); got != "[1000/p1/a 1000/p1/b 1000/p1/c]" {
+		t.Errorf("ScanPrefix visited %s", got)
+	}
+	if got := collect(func(fn func(key, val []byte) bool) error {
+		return ScanPrefixFrom(db, policy, EncodeKeyPartial(1000, []Value{NewBytesValue([]byte("p1")), NewBytesValue([]byte("a"))}, CMP_GT), fn)
+	}); got != "[1000/p1/b 1000/p1/c]" {
+		t.Errorf("ScanPrefixFrom visited %s", got)
+	}
+	if got := collect(func(fn func(key, val []byte) bool) error { return ScanPrefixReverse(db, policy, nil, fn) }); got != "[1000/p1/c 1000/p1/b 1000/p1/a]" {
+		t.Errorf("ScanPrefixReverse visited %s", got)
+	}
+	if got := collect(func(fn func(key, val []byte) bool) error {
+		return ScanPrefixReverse(db, policy, EncodeKey(1000, []Value{NewBytesValue([]byte("p1")), NewBytesValue([]byte("b"))}), fn)
+	}); got != "[1000/p1/b 1000/p1/a]" {
+		t.Errorf("ScanPrefixReverse from b visited %s", got)
+	}
+
+	// A whole prefix, read within a transaction that has added to it
+	tx := db.Begin()
+	defer tx.Abort()
+	tx.Set(key(1000, "p2", "a"), []byte("v"))
+	if got := collect(func(fn func(key, val []byte) bool) error { return ScanPrefix(tx, EncodeKey(1000, nil), fn) }); got != "[1000/p1/a 1000/p1/b 1000/p1/c 1000/p10/a 1000/p2/a]" {
+		t.Errorf("ScanPrefix in a transaction visited %s", got)
+	}
+}
@@ -0,0 +1,67 @@
+// ABOUTME: Operator controls of a running database: on-demand checkpoints and state dumps
+// ABOUTME: Lets an admin service act without waiting for background timers or a restart
+
+package storage
+
+import "github.com/nainya/treestore/pkg/wal"
+
+// State describes a database's internals at one moment
+type State struct {
+	Path        string
+	InMemory    bool   // Opened with MemoryPath
+	Heap        bool   // Pages held on the heap rather than mapped from the file
+	Legacy      bool   // Predates page checksums; read-only
+	Encrypted   bool   // New values are sealed
+	Pages       uint64 // Pages in the file, including the meta page
+	MappedBytes int    // Bytes mapped or copied into memory
+	Generation  uint64 // Generation of the newest meta slot on disk
+	LastLSN     uint64 // Last WAL entry written, 0 in memory
+	Format      uint32 // Format version of the file on disk
+	Migration   MigrationReport
+	Recovery    wal.RecoveryStats // WAL recovery run by Open, zero in memory
+	Sync        SyncStats
+	WALArchive  wal.ArchiveStats // Log files retired through WALArchive
+	Checkpoint  wal.CheckpointStats
+}
+
+// State reports the database's internals for diagnostics
+func (db *KV) State() State {
+	db.flush.mu.Lock()
+	state := State{
+		Path:        db.Path,
+		InMemory:    db.memory,
+		Heap:        db.heap,
+		Legacy:      db.legacy,
+		Encrypted:   db.Encryption != nil,
+		Pages:       db.page.flushed,
+		MappedBytes: db.mmap.total,
+		Generation:  db.generation,
+		Format:      db.format,
+		Migration:   db.migration,
+		Recovery:    db.recovery,
+	}
+	db.flush.mu.Unlock()
+
+	if db.wal != nil {
+		state.LastLSN = db.wal.LastLSN()
+		state.WALArchive = db.wal.ArchiveStats()
+	}
+	if db.checkpointer != nil {
+		state.Checkpoint = db.checkpointer.Stats()
+	}
+	state.Sync = db.SyncStats()
+	return state
+}
+
+// Checkpoint does at once what the background checkpointer does periodically:
+// it makes every commit durable in the database file, marks the WAL and
+// removes log files older than the few it keeps once the file covers them
+func (db *KV) Checkpoint() error {
+	if db.memory {
+		return ErrInMemory
+	}
+	if db.checkpointer == nil {
+		return ErrLegacyFormat
+	}
+	return db.checkpointer.Checkpoint()
+}
@@ -0,0 +1,82 @@
+// ABOUTME: Tests for on-demand checkpoints and state dumps
+// ABOUTME: Checks both on a database file and in memory
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/wal"
+)
+
+func TestCheckpointAndState(t *testing.T) {
+	db := &KV{Path: filepath.Join(t.TempDir(), "state.db"), SyncPolicy: SyncInterval}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Set([]byte("k"), []byte("v")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := db.Checkpoint(); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+
+	state := db.State()
+	if state.InMemory || state.Pages < 2 || state.MappedBytes == 0 || state.LastLSN == 0 {
+		t.Errorf("Unexpected state %+v", state)
+	}
+	if state.Sync.Pending != 0 || state.Sync.Policy != SyncInterval {
+		t.Errorf("Expected the checkpoint to flush pending commits, got %+v", state.Sync)
+	}
+
+	mem := &KV{Path: MemoryPath}
+	if err := mem.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer mem.Close()
+	if err := mem.Checkpoint(); !errors.Is(err, ErrInMemory) {
+		t.Errorf("Expected ErrInMemory, got %v", err)
+	}
+	if state := mem.State(); !state.InMemory || !state.Heap || state.LastLSN != 0 {
+		t.Errorf("Unexpected in-memory state %+v", state)
+	}
+}
+
+func TestStateReportsTornWALTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "torn.db")
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	if err := db.Set([]byte("k"), []byte("v")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	db.Close()
+
+	// A crash leaves the first half of an entry at the end of the log
+	torn := (&wal.Entry{LSN: 100, TxnID: 1, OpType: wal.OpInsert, Key: []byte("k"), Timestamp: time.Now()}).Encode()
+	fd, err := os.OpenFile(WALPath(path)+".000", os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("Failed to open the log: %v", err)
+	}
+	fd.Write(torn[:20])
+	fd.Close()
+
+	db = &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	defer db.Close()
+	if n := db.State().Recovery.TruncatedBytes; n != 20 {
+		t.Errorf("Expected 20 torn bytes reported, got %d", n)
+	}
+	if val, ok := db.Get([]byte("k")); !ok || string(val) != "v" {
+		t.Errorf("Expected the committed value kept, got %q", val)
+	}
+}
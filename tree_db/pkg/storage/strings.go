@@ -0,0 +1,211 @@
+// ABOUTME: Encoding of string lists and maps held in a single value of a record
+// ABOUTME: Decimal lengths behind a format marker; reads also accept the older single-byte lengths
+
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Formats of an encoded string list or map. Newer encodings start with
+// stringsMarker and a format byte; legacy ones start with their count as one
+// byte. The encoding is stored as a bytes value, which cannot hold 0x00 or a
+// lone 0xFE, so counts and lengths are written in decimal, each ended by ':'.
+const (
+	stringsMarker  = 0xFF
+	stringsDecimal = 'd' // Count and lengths as decimal digits
+)
+
+// EncodeStrings encodes a list of strings of any length and count
+// An empty or nil list encodes to no bytes.
+func EncodeStrings(list []string) []byte {
+	if len(list) == 0 {
+		return []byte{}
+	}
+
+	out := []byte{stringsMarker, stringsDecimal}
+	out = appendLength(out, len(list))
+	for _, s := range list {
+		out = appendString(out, s)
+	}
+	return out
+}
+
+// DecodeStrings decodes a list encoded by EncodeStrings or in the legacy
+// format, failing unless it uses up exactly data. Empty data decodes to an
+// empty list.
+func DecodeStrings(data []byte) ([]string, error) {
+	if len(data) == 0 {
+		return []string{}, nil
+	}
+
+	return decodeEither(data, readStrings)
+}
+
+// readStrings reads a list from r
+func readStrings(r *stringsReader) ([]string, error) {
+	count, err := r.length()
+	if err != nil {
+		return nil, fmt.Errorf("string list count: %w", err)
+	}
+
+	list := make([]string, 0, min(count, len(r.data)))
+	for i := 0; i < count; i++ {
+		s, err := r.string()
+		if err != nil {
+			return nil, fmt.Errorf("string %d of %d: %w", i, count, err)
+		}
+		list = append(list, s)
+	}
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// EncodeStringMap encodes a map of strings of any length and size, in key
+// order so equal maps encode alike. An empty or nil map encodes to no bytes.
+func EncodeStringMap(m map[string]string) []byte {
+	if len(m) == 0 {
+		return []byte{}
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := []byte{stringsMarker, stringsDecimal}
+	out = appendLength(out, len(m))
+	for _, k := range keys {
+		out = appendString(out, k)
+		out = appendString(out, m[k])
+	}
+	return out
+}
+
+// DecodeStringMap decodes a map encoded by EncodeStringMap or in the legacy
+// format, failing unless it uses up exactly data. Empty data decodes to an
+// empty map.
+func DecodeStringMap(data []byte) (map[string]string, error) {
+	if len(data) == 0 {
+		return make(map[string]string), nil
+	}
+
+	return decodeEither(data, readStringMap)
+}
+
+// readStringMap reads a map from r
+func readStringMap(r *stringsReader) (map[string]string, error) {
+	count, err := r.length()
+	if err != nil {
+		return nil, fmt.Errorf("string map size: %w", err)
+	}
+
+	m := make(map[string]string, min(count, len(r.data)))
+	for i := 0; i < count; i++ {
+		k, err := r.string()
+		if err != nil {
+			return nil, fmt.Errorf("key %d of %d: %w", i, count, err)
+		}
+		v, err := r.string()
+		if err != nil {
+			return nil, fmt.Errorf("value of key %q: %w", k, err)
+		}
+		m[k] = v
+	}
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// appendLength appends a count or length in decimal, ended by ':'
+func appendLength(out []byte, n int) []byte {
+	out = strconv.AppendInt(out, int64(n), 10)
+	return append(out, ':')
+}
+
+// appendString appends s after its length
+func appendString(out []byte, s string) []byte {
+	return append(appendLength(out, len(s)), s...)
+}
+
+// stringsReader reads the counts, lengths and strings of either format
+type stringsReader struct {
+	data   []byte
+	pos    int
+	legacy bool // Counts and lengths are single bytes
+}
+
+// decodeEither reads non-empty data with read in the format its header names
+// A legacy encoding of 255 strings also starts with stringsMarker, so data
+// that does not read as the newer format is tried as legacy before failing.
+func decodeEither[T any](data []byte, read func(*stringsReader) (T, error)) (T, error) {
+	if data[0] != stringsMarker {
+		return read(&stringsReader{data: data, legacy: true})
+	}
+
+	var v T
+	err := fmt.Errorf("unknown string encoding format")
+	if len(data) > 1 && data[1] == stringsDecimal {
+		if v, err = read(&stringsReader{data: data, pos: 2}); err == nil {
+			return v, nil
+		}
+	}
+	if legacy, legacyErr := read(&stringsReader{data: data, legacy: true}); legacyErr == nil {
+		return legacy, nil
+	}
+	return v, err
+}
+
+// length reads a count or a string length
+func (r *stringsReader) length() (int, error) {
+	if r.legacy {
+		if r.pos >= len(r.data) {
+			return 0, fmt.Errorf("truncated at byte %d", r.pos)
+		}
+		r.pos++
+		return int(r.data[r.pos-1]), nil
+	}
+
+	n, start := 0, r.pos
+	for r.pos < len(r.data) && r.data[r.pos] != ':' {
+		c := r.data[r.pos]
+		if c < '0' || c > '9' || n > len(r.data) {
+			return 0, fmt.Errorf("bad length at byte %d", start)
+		}
+		n = n*10 + int(c-'0')
+		r.pos++
+	}
+	if r.pos == start || r.pos == len(r.data) {
+		return 0, fmt.Errorf("bad length at byte %d", start)
+	}
+	r.pos++ // Skip ':'
+	return n, nil
+}
+
+// string reads one length-prefixed string
+func (r *stringsReader) string() (string, error) {
+	n, err := r.length()
+	if err != nil {
+		return "", err
+	}
+	if r.pos+n > len(r.data) {
+		return "", fmt.Errorf("truncated at byte %d", r.pos)
+	}
+	s := string(r.data[r.pos : r.pos+n])
+	r.pos += n
+	return s, nil
+}
+
+// done fails if bytes remain after the last string
+func (r *stringsReader) done() error {
+	if r.pos != len(r.data) {
+		return fmt.Errorf("%d trailing bytes", len(r.data)-r.pos)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// ABOUTME: Tests for the encoding of string lists and maps
+// ABOUTME: Verifies long strings and large counts survive, legacy encodings still read and damage is rejected
+
+package storage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStringsRoundTrip(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	many := make([]string, 300)
+	big := make(map[string]string, 300)
+	for i := range many {
+		many[i] = strings.Repeat("y", i)
+		big[many[i]+"k"] = long
+	}
+
+	// Lists are stored as bytes values, which must carry them unchanged
+	for _, list := range [][]string{{"latest", "stable"}, {long}, many, {""}, {strings.Repeat("z", 254)}} {
+		vals, err := DecodeValues(EncodeValues([]Value{NewBytesValue(EncodeStrings(list))}))
+		if err != nil {
+			t.Fatalf("List of %d did not survive as a value: %v", len(list), err)
+		}
+		got, err := DecodeStrings(vals[0].Str)
+		if err != nil || !reflect.DeepEqual(got, list) {
+			t.Errorf("List of %d round trip failed: %v", len(list), err)
+		}
+	}
+	for _, m := range []map[string]string{{"source": "cms"}, {long: long}, big} {
+		got, err := DecodeStringMap(EncodeStringMap(m))
+		if err != nil || !reflect.DeepEqual(got, m) {
+			t.Errorf("Map of %d round trip failed: %v", len(m), err)
+		}
+	}
+
+	// Empty values encode to nothing and decode to empty, non-nil values
+	if len(EncodeStrings(nil)) != 0 || len(EncodeStringMap(nil)) != 0 {
+		t.Error("Expected empty values to encode to no bytes")
+	}
+	if list, _ := DecodeStrings(nil); list == nil || len(list) != 0 {
+		t.Errorf("Expected an empty list, got %#v", list)
+	}
+	if m, _ := DecodeStringMap(nil); m == nil || len(m) != 0 {
+		t.Errorf("Expected an empty map, got %#v", m)
+	}
+
+	// Maps encode in key order
+	a := EncodeStringMap(map[string]string{"a": "1", "b": "2", "c": "3"})
+	b := EncodeStringMap(map[string]string{"c": "3", "b": "2", "a": "1"})
+	if string(a) != string(b) {
+		t.Error("Expected equal maps to encode alike")
+	}
+}
+
+func TestStringsLegacy(t *testing.T) {
+	// Single-byte counts and lengths, as written before the format marker
+	list, err := DecodeStrings([]byte{2, 1, 'a', 2, 'b', 'c'})
+	if err != nil || !reflect.DeepEqual(list, []string{"a", "bc"}) {
+		t.Errorf("Expected the legacy list, got %q (%v)", list, err)
+	}
+	m, err := DecodeStringMap([]byte{1, 1, 'k', 2, 'v', 'w'})
+	if err != nil || !reflect.DeepEqual(m, map[string]string{"k": "vw"}) {
+		t.Errorf("Expected the legacy map, got %q (%v)", m, err)
+	}
+
+	// A legacy list of 255 strings starts like the newer format
+	legacy := []byte{255}
+	for i := 0; i < 255; i++ {
+		legacy = append(legacy, 1, 'a')
+	}
+	if list, err := DecodeStrings(legacy); err != nil || len(list) != 255 {
+		t.Errorf("Expected 255 legacy strings, got %d (%v)", len(list), err)
+	}
+}
+
+func TestStringsDamaged(t *testing.T) {
+	good := EncodeStrings([]string{"abc"})
+	tests := map[string][]byte{
+		"unknown format":   {stringsMarker, 9, 1, 1, 'a'},
+		"missing format":   {stringsMarker},
+		"truncated string": good[:len(good)-1],
+		"trailing bytes":   append(append([]byte(nil), good...), 'x'),
+		"huge count":       append([]byte{stringsMarker, stringsDecimal}, "99999999999999999999:"...),
+		// A 300-byte tag written with single-byte lengths leaves 256 bytes over
+		"overflowed legacy length": append([]byte{1, 300 % 256}, strings.Repeat("x", 300)...),
+	}
+	for name, data := range tests {
+		if _, err := DecodeStrings(data); err == nil {
+			t.Errorf("%s: expected the list rejected", name)
+		}
+	}
+	if _, err := DecodeStringMap([]byte{1, 1, 'k'}); err == nil {
+		t.Error("Expected a map missing its value rejected")
+	}
+}
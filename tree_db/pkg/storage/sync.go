@@ -0,0 +1,363 @@
+// ABOUTME: Durability policies trading commit latency against crash safety
+// ABOUTME: Group commit and background flushing publish written commits with shared fsyncs
+
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nainya/treestore/pkg/wal"
+)
+
+// ErrCommitDiscarded indicates a transaction that began on commits a failed
+// flush discarded; it is rolled back and may be retried
+var ErrCommitDiscarded = errors.New("storage: transaction built on commits discarded by a failed flush")
+
+// DefaultSyncInterval is the flush period of SyncInterval when none is set
+const DefaultSyncInterval = 100 * time.Millisecond
+
+// SyncPolicy chooses when commits are forced to stable storage.
+// Every policy survives a crash of the process, since written pages sit in the
+// OS page cache; they differ in what an OS crash or power loss can take.
+type SyncPolicy int
+
+const (
+	// SyncAlways makes a commit durable before it returns, so a committed
+	// transaction is never lost. This is the default. Commits that arrive while
+	// another is being flushed are flushed together, sharing the fsyncs of the
+	// WAL, the pages and the meta page (group commit).
+	SyncAlways SyncPolicy = iota
+
+	// SyncInterval writes commits without fsync and flushes them in the
+	// background every SyncInterval. Until a flush the meta page on disk keeps
+	// pointing at the last flushed tree, and pages it references are not
+	// reused, so a power loss rolls back at most one interval of commits and
+	// never damages the file.
+	SyncInterval
+
+	// SyncNever writes pages and the meta page without fsync and leaves write
+	// back to the OS. A power loss can lose any number of commits and, as pages
+	// may reach the disk after the meta page pointing at them, corrupt the
+	// file. Meant for ephemeral test databases and bulk loads that can be redone.
+	SyncNever
+)
+
+func (p SyncPolicy) String() string {
+	switch p {
+	case SyncAlways:
+		return "always"
+	case SyncInterval:
+		return "interval"
+	case SyncNever:
+		return "never"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSyncPolicy parses the name of a policy as returned by String
+func ParseSyncPolicy(name string) (SyncPolicy, error) {
+	for _, p := range []SyncPolicy{SyncAlways, SyncInterval, SyncNever} {
+		if p.String() == name {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown sync policy %q (want always, interval or never)", name)
+}
+
+// SyncStats describes how far the database's commits have been made durable
+type SyncStats struct {
+	Policy    SyncPolicy
+	Interval  time.Duration // Flush period, 0 unless Policy is SyncInterval
+	Pending   int           // Commits written but not yet flushed
+	Flushes   uint64        // Flushes that published commits; Commits/Flushes is the mean group size
+	Commits   uint64        // Commits published by those flushes
+	LastFlush time.Time     // Zero before the first flush
+	LastError error         // Error of the last flush, nil once one succeeds
+}
+
+// flushState tracks commits written to the file but not yet published by a
+// flush. Under SyncNever the meta is written by every commit and none wait.
+type flushState struct {
+	mu        sync.Mutex   // Held by commits while writing, and by flushes to take or settle their group
+	publishMu sync.Mutex   // Held by the flush in progress
+	meta      []byte       // Meta of the newest unflushed commit, nil when all are flushed
+	published []byte       // Meta on disk, restored when a group commit fails
+	pending   int          // Commits since published
+	logged    []uint64     // Transactions logged since published, under SyncAlways
+	waiters   []chan error // Commits under SyncAlways waiting for a flush
+	epoch     uint64       // Bumped when a failed flush discards commits
+	freeSeq   uint64       // Free list tail of published; later entries stay reserved
+	broken    error        // The file could not be repaired after a failed flush
+	flushes   uint64
+	commits   uint64
+	last      time.Time
+	err       error
+	lastWrite time.Time    // Last commit that logged writes
+	recent    recentWrites // Keys of the latest inserts, when tracked
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// SyncStats reports the sync policy and the commits awaiting a flush
+func (db *KV) SyncStats() SyncStats {
+	db.flush.mu.Lock()
+	defer db.flush.mu.Unlock()
+
+	stats := SyncStats{
+		Policy:    db.SyncPolicy,
+		Pending:   db.flush.pending,
+		Flushes:   db.flush.flushes,
+		Commits:   db.flush.commits,
+		LastFlush: db.flush.last,
+		LastError: db.flush.err,
+	}
+	if db.SyncPolicy == SyncInterval {
+		stats.Interval = db.syncInterval()
+	}
+	return stats
+}
+
+// Flush makes every commit so far durable: it syncs the WAL and the pages,
+// then publishes the meta of the newest commit. Under SyncNever, and under
+// SyncAlways once commits have returned, nothing is waiting.
+func (db *KV) Flush() error {
+	db.flush.publishMu.Lock()
+	defer db.flush.publishMu.Unlock()
+	return db.publish()
+}
+
+// commitLocked logs a transaction's operations and writes its pages, reverting
+// to meta if either fails. Callers hold flush.mu and, under SyncAlways, pass the
+// returned channel to awaitFlush once they release it.
+func (db *KV) commitLocked(meta []byte, epoch uint64, ops []wal.Entry) (chan error, error) {
+	db.lockTree()
+	defer db.treeMu.Unlock()
+
+	if err := db.flush.broken; err != nil {
+		db.revert(db.committedMeta())
+		return nil, err
+	}
+	if epoch != db.flush.epoch {
+		db.revert(db.committedMeta())
+		return nil, ErrCommitDiscarded
+	}
+
+	var txnID uint64
+	if len(ops) > 0 {
+		var err error
+		if txnID, err = db.logTxn(ops); err != nil {
+			db.revert(meta)
+			if txnID != 0 {
+				err = errors.Join(err, db.abortTxn(txnID))
+			}
+			return nil, err
+		}
+		if txnID != 0 {
+			db.walLSN = db.wal.LastLSN()
+		}
+	}
+	if err := db.updateOrRevert(meta); err != nil {
+		if txnID != 0 {
+			err = errors.Join(err, db.abortTxn(txnID))
+		}
+		return nil, err
+	}
+
+	if len(ops) > 0 {
+		db.flush.lastWrite = time.Now()
+		db.flush.recent.note(ops)
+	}
+
+	if db.SyncPolicy != SyncAlways {
+		return nil, nil
+	}
+	if txnID != 0 {
+		db.flush.logged = append(db.flush.logged, txnID)
+	}
+	done := make(chan error, 1)
+	db.flush.waiters = append(db.flush.waiters, done)
+	return done, nil
+}
+
+// awaitFlush waits until a commit is durable. The first waiter flushes every
+// commit written so far; the commits written meanwhile wait for it and are
+// flushed together by the next.
+func (db *KV) awaitFlush(done chan error) error {
+	if done == nil {
+		return nil
+	}
+
+	db.flush.publishMu.Lock()
+	defer db.flush.publishMu.Unlock()
+	select {
+	case err := <-done:
+		return err // Flushed by an earlier waiter
+	default:
+	}
+	db.publish()
+	return <-done
+}
+
+// publish flushes the commits written so far and settles their waiters
+// Callers hold flush.publishMu. Commits continue while the fsyncs run.
+func (db *KV) publish() error {
+	db.flush.mu.Lock()
+	meta, waiters, group := db.flush.meta, db.flush.waiters, db.flush.pending
+	logged := len(db.flush.logged)
+	db.flush.waiters = nil
+	db.flush.mu.Unlock()
+	if meta == nil {
+		return nil
+	}
+
+	err := db.publishMeta(meta)
+
+	db.flush.mu.Lock()
+	defer db.flush.mu.Unlock()
+	if err != nil && db.SyncPolicy == SyncAlways {
+		discarded, abortErr := db.discardUnflushed()
+		waiters = append(waiters, discarded...)
+		err = errors.Join(err, abortErr)
+	}
+	db.flush.err = err
+	if err != nil {
+		for _, done := range waiters {
+			done <- err
+		}
+		return err
+	}
+
+	db.flush.published = meta
+	db.flush.freeSeq = binary.LittleEndian.Uint64(meta[56:]) // Free list tailSeq
+	db.flush.pending -= group
+	db.flush.logged = append(db.flush.logged[:0], db.flush.logged[logged:]...)
+	db.flush.flushes++
+	db.flush.commits += uint64(group)
+	db.flush.last = time.Now()
+	if db.flush.pending == 0 {
+		// Nothing was logged after the group, so the marker covers exactly it
+		db.flush.meta = nil
+		db.writeCheckpointMarker()
+	}
+	for _, done := range waiters {
+		done <- nil
+	}
+	return nil
+}
+
+// discardUnflushed rolls back every commit since the last flush after a group
+// commit failed, returning the waiters of commits written during the flush
+// and the error of any abort marker it could not write, which recovery would
+// then replay past. Callers hold flush.mu. The failed flush may have left its meta on disk, so
+// the published one is written over it before any page it references is reused.
+func (db *KV) discardUnflushed() ([]chan error, error) {
+	db.lockTree()
+	db.revert(db.flush.published)
+	db.treeMu.Unlock()
+	db.free.maxSeq = db.flush.freeSeq
+	db.flush.meta = nil
+	db.flush.pending = 0
+	db.flush.epoch++
+
+	// Their commit markers may be in the WAL; recovery must not replay them
+	var abortErr error
+	for _, txnID := range db.flush.logged {
+		abortErr = errors.Join(abortErr, db.abortTxn(txnID))
+	}
+	if len(db.flush.logged) > 0 {
+		db.wal.Fsync() // Best effort: the failed flush may have been the WAL's
+	}
+	db.flush.logged = nil
+
+	if err := db.writeMeta(db.flush.published); err != nil {
+		db.flush.broken = err
+	} else if err := db.fsync(PhaseSyncMeta); err != nil {
+		db.flush.broken = err
+	}
+
+	waiters := db.flush.waiters
+	db.flush.waiters = nil
+	return waiters, abortErr
+}
+
+// committedMeta returns the meta of the newest commit, flushed or not
+func (db *KV) committedMeta() []byte {
+	if db.flush.meta != nil {
+		return db.flush.meta
+	}
+	return db.flush.published
+}
+
+// currentEpoch returns the epoch a new transaction builds on
+func (db *KV) currentEpoch() uint64 {
+	db.flush.mu.Lock()
+	defer db.flush.mu.Unlock()
+	return db.flush.epoch
+}
+
+// publishMeta syncs the WAL and the pages, then writes and syncs meta
+func (db *KV) publishMeta(meta []byte) error {
+	if err := db.fail(PhaseWALSync); err != nil {
+		return err
+	}
+	if db.wal != nil {
+		if err := db.wal.Fsync(); err != nil {
+			return err
+		}
+	}
+	if err := db.fsync(PhaseSyncPages); err != nil {
+		return err
+	}
+	if err := db.writeMeta(meta); err != nil {
+		return err
+	}
+	if err := db.fsync(PhaseSyncMeta); err != nil {
+		return err
+	}
+	db.noteFlushed(meta)
+	return nil
+}
+
+// startFlusher flushes commits every SyncInterval until stopFlusher
+func (db *KV) startFlusher() {
+	db.flush.stop = make(chan struct{})
+	db.flush.done = make(chan struct{})
+
+	go func() {
+		defer close(db.flush.done)
+		ticker := time.NewTicker(db.syncInterval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				db.Flush() // A failure is kept for SyncStats and retried next tick
+			case <-db.flush.stop:
+				return
+			}
+		}
+	}()
+}
+
+// stopFlusher stops the background flusher, if running, and flushes what is left
+func (db *KV) stopFlusher() error {
+	if db.flush.stop != nil {
+		close(db.flush.stop)
+		<-db.flush.done
+		db.flush.stop = nil
+	}
+	return db.Flush()
+}
+
+// syncInterval returns the flush period of SyncInterval
+func (db *KV) syncInterval() time.Duration {
+	if db.SyncInterval > 0 {
+		return db.SyncInterval
+	}
+	return DefaultSyncInterval
+}
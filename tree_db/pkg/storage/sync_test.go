@@ -0,0 +1,309 @@
+// ABOUTME: Tests for the durability policies and group commit
+// ABOUTME: Checks the meta on disk only moves on flush, commits share flushes and failed groups roll back
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// snapshotFile copies a database file without its WAL, as a power loss would
+// leave it if only what the meta page references survived
+func snapshotFile(t *testing.T, src string) *KV {
+	t.Helper()
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", src, err)
+	}
+	dst := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := os.WriteFile(dst, data, 0o644); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+	db := &KV{Path: dst}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open snapshot: %v", err)
+	}
+	return db
+}
+
+func TestSyncIntervalDefersMeta(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "interval.db")
+	db := &KV{Path: path, SyncPolicy: SyncInterval, SyncInterval: time.Hour}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+
+	if err := db.Set([]byte("a"), []byte("flushed")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	// Rewrites free the flushed tree's pages; none may be reused before a flush
+	for i := 0; i < 50; i++ {
+		if err := db.Set([]byte("a"), []byte(fmt.Sprintf("pending-%d", i))); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if err := db.Set([]byte(fmt.Sprintf("k%03d", i)), make([]byte, 500)); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	stats := db.SyncStats()
+	if stats.Policy != SyncInterval || stats.Interval != time.Hour || stats.Pending != 100 || stats.LastFlush.IsZero() {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+
+	snap := snapshotFile(t, path)
+	if val, ok := snap.Get([]byte("a")); !ok || string(val) != "flushed" {
+		t.Errorf("Expected the flushed value on disk, got %q (%v)", val, ok)
+	}
+	if report, _ := snap.Verify(); len(report.Corrupt) != 0 {
+		t.Errorf("Flushed tree was overwritten: %+v", report)
+	}
+	snap.Close()
+
+	if err := db.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if stats := db.SyncStats(); stats.Pending != 0 || stats.LastError != nil {
+		t.Errorf("Expected nothing pending after flush, got %+v", stats)
+	}
+	snap = snapshotFile(t, path)
+	if val, _ := snap.Get([]byte("a")); string(val) != "pending-49" {
+		t.Errorf("Expected the last value after flush, got %q", val)
+	}
+	snap.Close()
+
+	// Close flushes what the background flusher has not
+	db.Set([]byte("a"), []byte("closed"))
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	snap = snapshotFile(t, path)
+	defer snap.Close()
+	if val, _ := snap.Get([]byte("a")); string(val) != "closed" {
+		t.Errorf("Expected Close to flush, got %q", val)
+	}
+}
+
+func TestSyncPolicies(t *testing.T) {
+	for _, policy := range []SyncPolicy{SyncAlways, SyncInterval, SyncNever} {
+		path := filepath.Join(t.TempDir(), policy.String()+".db")
+		db := &KV{Path: path, SyncPolicy: policy, SyncInterval: time.Millisecond}
+		if err := db.Open(); err != nil {
+			t.Fatalf("%s: open failed: %v", policy, err)
+		}
+		tx := db.Begin()
+		for i := 0; i < 100; i++ {
+			tx.Set([]byte(fmt.Sprintf("k%03d", i)), []byte(policy.String()))
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("%s: commit failed: %v", policy, err)
+		}
+		if err := db.Close(); err != nil {
+			t.Fatalf("%s: close failed: %v", policy, err)
+		}
+
+		db = &KV{Path: path}
+		if err := db.Open(); err != nil {
+			t.Fatalf("%s: reopen failed: %v", policy, err)
+		}
+		if val, ok := db.Get([]byte("k099")); !ok || string(val) != policy.String() {
+			t.Errorf("%s: expected the commit to persist, got %q (%v)", policy, val, ok)
+		}
+		db.Close()
+	}
+}
+
+func TestGroupCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "group.db")
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+
+	// Hold the first flush until every writer has committed, so the rest
+	// must wait for it and go out together in the second
+	const writers = 8
+	db.SetFailpoint(failAt(PhaseSyncPages, 1, func() error {
+		deadline := time.Now().Add(5 * time.Second)
+		for db.SyncStats().Pending < writers && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		return nil
+	}))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			if err := db.Set([]byte(fmt.Sprintf("w%d", w)), []byte("v")); err != nil {
+				errs <- err
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Concurrent Set failed: %v", err)
+	}
+
+	stats := db.SyncStats()
+	if stats.Commits != writers || stats.Pending != 0 || stats.Flushes > 2 {
+		t.Errorf("Expected %d commits in at most 2 flushes, got %+v", writers, stats)
+	}
+
+	// Every commit returned durable, so the file alone holds them all
+	snap := snapshotFile(t, path)
+	defer snap.Close()
+	for w := 0; w < writers; w++ {
+		if _, ok := snap.Get([]byte(fmt.Sprintf("w%d", w))); !ok {
+			t.Errorf("Writer %d's commit is not on disk", w)
+		}
+	}
+	db.Close()
+}
+
+func TestFailedGroupCommitDiscards(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "discard.db")
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+	db.Set([]byte("a"), []byte("old"))
+
+	// A transaction begun while the flush runs builds on the commit it fails
+	var late *KVTX
+	db.SetFailpoint(failAt(PhaseSyncPages, 1, func() error {
+		late = db.BeginTx()
+		late.Set([]byte("b"), []byte("late"))
+		return errInjected
+	}))
+	if err := db.Set([]byte("a"), []byte("new")); !errors.Is(err, errInjected) {
+		t.Fatalf("Set error = %v, want injected fault", err)
+	}
+	if err := late.Commit(); !errors.Is(err, ErrCommitDiscarded) {
+		t.Errorf("Late commit error = %v, want ErrCommitDiscarded", err)
+	}
+	db.SetFailpoint(nil)
+
+	if val, _ := db.Get([]byte("a")); string(val) != "old" {
+		t.Errorf("Expected the failed commit rolled back, got %q", val)
+	}
+	if _, ok := db.Get([]byte("b")); ok {
+		t.Error("Expected the discarded transaction rolled back")
+	}
+	if stats := db.SyncStats(); !errors.Is(stats.LastError, errInjected) {
+		t.Errorf("Expected the flush error in stats, got %v", stats.LastError)
+	}
+
+	if err := db.Set([]byte("c"), []byte("after")); err != nil {
+		t.Fatalf("Set after failed flush: %v", err)
+	}
+	snap := snapshotFile(t, path)
+	defer snap.Close()
+	if val, _ := snap.Get([]byte("c")); string(val) != "after" {
+		t.Errorf("Expected commits to resume after a failed flush, got %q", val)
+	}
+}
+
+func TestParseSyncPolicy(t *testing.T) {
+	for _, policy := range []SyncPolicy{SyncAlways, SyncInterval, SyncNever} {
+		if parsed, err := ParseSyncPolicy(policy.String()); err != nil || parsed != policy {
+			t.Errorf("%s: got %v (%v)", policy, parsed, err)
+		}
+	}
+	if _, err := ParseSyncPolicy("sometimes"); err == nil {
+		t.Error("Expected an error for an unknown policy")
+	}
+}
+
+func TestConcurrentReadsAndWrites(t *testing.T) {
+	for _, policy := range []SyncPolicy{SyncAlways, SyncNever} {
+		t.Run(policy.String(), func(t *testing.T) {
+			db := &KV{Path: filepath.Join(t.TempDir(), "concurrent.db"), SyncPolicy: policy}
+			if err := db.Open(); err != nil {
+				t.Fatalf("Failed to open: %v", err)
+			}
+			defer db.Close()
+
+			const existing = 200
+			key := func(i int) []byte { return []byte(fmt.Sprintf("key-%05d", i)) }
+			for i := 0; i < existing; i++ {
+				if err := db.Set(key(i), []byte("v0")); err != nil {
+					t.Fatalf("Set failed: %v", err)
+				}
+			}
+
+			stop := make(chan struct{})
+			var writers sync.WaitGroup
+			writers.Add(1)
+			go func() {
+				defer writers.Done()
+				for round := 1; round <= 20; round++ {
+					for i := 0; i < existing; i += 7 {
+						if err := db.Set(key(i), []byte(fmt.Sprintf("v%d", round))); err != nil {
+							t.Errorf("Set failed: %v", err)
+							return
+						}
+					}
+					if err := db.Set(key(existing+round), []byte("new")); err != nil {
+						t.Errorf("Set failed: %v", err)
+						return
+					}
+					if _, err := db.Del(key(existing + round - 1)); err != nil {
+						t.Errorf("Del failed: %v", err)
+						return
+					}
+				}
+			}()
+
+			var readers sync.WaitGroup
+			for r := 0; r < 4; r++ {
+				readers.Add(1)
+				go func(r int) {
+					defer readers.Done()
+					for n := 0; ; n++ {
+						select {
+						case <-stop:
+							return
+						default:
+						}
+						i := (n*31 + r) % existing
+						val, found, err := db.Lookup(key(i))
+						if err != nil || !found || len(val) < 2 || val[0] != 'v' {
+							t.Errorf("Lookup of %s during writes: %q, %v, %v", key(i), val, found, err)
+							return
+						}
+						if n%50 != 0 {
+							continue
+						}
+						seen := 0
+						err = ScanPrefix(db, []byte("key-"), func(k, v []byte) bool {
+							seen++
+							return true
+						})
+						if err != nil || seen < existing {
+							t.Errorf("Scan during writes saw %d keys (%v), expected at least %d", seen, err, existing)
+							return
+						}
+					}
+				}(r)
+			}
+
+			writers.Wait()
+			close(stop)
+			readers.Wait()
+		})
+	}
+}
@@ -1,63 +1,282 @@
 // ABOUTME: Transaction support for atomic multi-key operations
-// ABOUTME: Implements Begin/Commit/Abort with copy-on-write atomicity
+// ABOUTME: Implements Begin/Commit/Abort and nested savepoints with copy-on-write atomicity
 
 package storage
 
 import (
+	"errors"
+
 	"github.com/nainya/treestore/pkg/btree"
+	"github.com/nainya/treestore/pkg/wal"
+)
+
+var (
+	// ErrTxDone indicates a commit of a transaction already committed or aborted
+	ErrTxDone = errors.New("storage: transaction already committed or aborted")
+
+	// ErrUnknownSavepoint indicates a savepoint released or rolled back past
+	ErrUnknownSavepoint = errors.New("storage: unknown savepoint")
 )
 
 // KVTX represents a key-value transaction
 type KVTX struct {
-	db   *KV
-	meta []byte // Saved meta for rollback
+	db         *KV
+	meta       []byte       // Saved meta for rollback
+	epoch      uint64       // Flush epoch the transaction builds on
+	ops        []wal.Entry  // Writes to log on commit
+	savepoints []*Savepoint // Active savepoints, oldest first
+	done       bool         // Committed or aborted
+	err        error        // First write that reached corrupt data; fails Commit
+}
+
+// Savepoint marks a point within a transaction that it can roll back to
+// Staged pages are never modified once allocated, so copying the page lists
+// is enough to restore them.
+type Savepoint struct {
+	meta    []byte
+	temp    [][]byte
+	updates map[uint64][]byte
+	freed   int
+	ops     int
 }
 
 // Begin starts a new transaction
-func (db *KV) Begin() *KVTX {
+func (db *KV) Begin() Txn {
+	return db.BeginTx()
+}
+
+// BeginTx starts a new transaction with the operations particular to KV, such
+// as savepoints and storing sealed values
+// Transactions write into the shared tree, so one is open at a time: BeginTx
+// waits until the open one commits or aborts. A goroutine holding a
+// transaction must not begin another, nor call Set or Del.
+func (db *KV) BeginTx() *KVTX {
+	db.writer.Lock()
 	tx := &KVTX{
-		db:   db,
-		meta: db.saveMeta(),
+		db:    db,
+		meta:  db.treeMeta(),
+		epoch: db.currentEpoch(),
 	}
 	return tx
 }
 
 // Commit commits the transaction atomically
+// Its writes are logged to the WAL before the tree is persisted.
 func (tx *KVTX) Commit() error {
-	return tx.db.updateOrRevert(tx.meta)
+	if tx.done {
+		return ErrTxDone
+	}
+	if tx.err != nil {
+		tx.Abort()
+		return tx.err
+	}
+	if tx.db.legacy {
+		tx.Abort()
+		return ErrLegacyFormat
+	}
+	ops := tx.ops
+	tx.ops = nil
+	tx.done = true
+	tx.savepoints = nil
+
+	tx.db.flush.mu.Lock()
+	done, err := tx.db.commitLocked(tx.meta, tx.epoch, ops)
+	tx.db.flush.mu.Unlock()
+	tx.db.writer.Unlock() // The next transaction may begin while this one's flush runs
+	if err != nil {
+		return err
+	}
+	return tx.db.awaitFlush(done)
 }
 
 // Abort rolls back the transaction
+// It does nothing once the transaction is done, so it can be deferred. Writes
+// reach the WAL only when Commit logs them, so nothing is logged here; a
+// commit rolled back after logging is marked aborted by commitLocked.
 func (tx *KVTX) Abort() {
-	// Revert in-memory state
-	tx.db.loadMeta(tx.meta)
+	if tx.done {
+		return
+	}
+
+	// Revert in-memory state and discard temporary pages; holding writer,
+	// the transaction made every change since it began. Commits it built on
+	// may have been discarded since by a failed flush.
+	tx.db.flush.mu.Lock()
+	tx.db.lockTree()
+	if tx.epoch == tx.db.flush.epoch {
+		tx.db.revert(tx.meta)
+	} else {
+		tx.db.revert(tx.db.committedMeta())
+	}
+	tx.db.treeMu.Unlock()
+	tx.db.flush.mu.Unlock()
+	tx.db.writer.Unlock()
+	tx.ops = nil
+	tx.savepoints = nil
+	tx.done = true
+}
+
+// Savepoint records the transaction's current state
+// Savepoints nest: rolling back to one discards those taken after it.
+func (tx *KVTX) Savepoint() *Savepoint {
+	tx.db.treeMu.RLock()
+	defer tx.db.treeMu.RUnlock()
+
+	sp := &Savepoint{
+		meta:    tx.db.saveMeta(),
+		temp:    append([][]byte(nil), tx.db.page.temp...),
+		updates: make(map[uint64][]byte, len(tx.db.page.updates)),
+		freed:   len(tx.db.page.freed),
+		ops:     len(tx.ops),
+	}
+	for ptr, page := range tx.db.page.updates {
+		sp.updates[ptr] = page
+	}
+	tx.savepoints = append(tx.savepoints, sp)
+	return sp
+}
+
+// RollbackTo undoes every write staged since sp was taken
+// sp stays usable, so a failed step can be retried and rolled back again. No
+// other transaction writes while this one is open, so restoring the tree as
+// it was at sp discards this transaction's writes alone.
+func (tx *KVTX) RollbackTo(sp *Savepoint) error {
+	i := tx.savepointIndex(sp)
+	if i < 0 {
+		return ErrUnknownSavepoint
+	}
+
+	tx.db.lockTree()
+	defer tx.db.treeMu.Unlock()
+	tx.db.loadMeta(sp.meta)
+	tx.db.page.temp = append(tx.db.page.temp[:0], sp.temp...)
+	tx.db.page.updates = make(map[uint64][]byte, len(sp.updates))
+	for ptr, page := range sp.updates {
+		tx.db.page.updates[ptr] = page
+	}
+	tx.db.page.freed = tx.db.page.freed[:sp.freed]
+	tx.ops = tx.ops[:sp.ops]
+	tx.savepoints = tx.savepoints[:i+1]
+	return nil
+}
 
-	// Discard temporary pages
-	tx.db.page.temp = tx.db.page.temp[:0]
-	tx.db.page.updates = make(map[uint64][]byte)
+// Release forgets sp and the savepoints taken after it, keeping their writes
+func (tx *KVTX) Release(sp *Savepoint) error {
+	i := tx.savepointIndex(sp)
+	if i < 0 {
+		return ErrUnknownSavepoint
+	}
+	tx.savepoints = tx.savepoints[:i]
+	return nil
+}
+
+// savepointIndex returns the position of an active savepoint, or -1
+func (tx *KVTX) savepointIndex(sp *Savepoint) int {
+	for i, active := range tx.savepoints {
+		if active == sp {
+			return i
+		}
+	}
+	return -1
 }
 
 // Get retrieves a value within the transaction
+// A read that reaches corrupt data reports the key as absent and fails Commit.
 func (tx *KVTX) Get(key []byte) ([]byte, bool) {
-	return tx.db.tree.Get(key)
+	var val []byte
+	var found bool
+	tx.guard(func() { val, found = tx.db.Get(key) })
+	return val, found
+}
+
+// GetBatch retrieves several keys within the transaction
+func (tx *KVTX) GetBatch(keys [][]byte) ([][]byte, []bool) {
+	var vals [][]byte
+	found := make([]bool, len(keys))
+	tx.guard(func() { vals, found = tx.db.GetBatch(keys) })
+	if vals == nil {
+		vals = make([][]byte, len(keys))
+	}
+	return vals, found
 }
 
 // Set inserts or updates a key-value pair within the transaction
+// The value is encrypted when the database has Encryption configured. A write
+// that reaches corrupt data is dropped and fails Commit, as are later writes.
 func (tx *KVTX) Set(key []byte, val []byte) {
-	tx.db.tree.Insert(key, val)
+	entry := tx.db.insertEntry(key, val)
+	if tx.write(func() { tx.db.applyInsert(entry.OpType, key, entry.Value) }) {
+		tx.ops = append(tx.ops, entry)
+	}
+}
+
+// SetSealed stores a value that is already encrypted, such as one replicated
+// from a leader; it is read back with whichever keys the database holds
+func (tx *KVTX) SetSealed(key []byte, sealed []byte) {
+	if tx.write(func() { tx.db.tree.InsertSealed(key, sealed) }) {
+		tx.ops = append(tx.ops, wal.Entry{OpType: wal.OpInsertSealed, Key: key, Value: sealed})
+	}
 }
 
 // Del deletes a key within the transaction
 func (tx *KVTX) Del(key []byte) bool {
-	return tx.db.tree.Delete(key)
+	var deleted bool
+	if tx.write(func() { deleted = tx.db.tree.Delete(key) }) && deleted {
+		tx.ops = append(tx.ops, wal.Entry{OpType: wal.OpDelete, Key: key})
+	}
+	return deleted
+}
+
+// Err returns the error of the first read or write that reached corrupt data, if any
+func (tx *KVTX) Err() error {
+	return tx.err
+}
+
+// write applies one tree update unless an earlier operation failed
+// It reports whether the update was applied.
+func (tx *KVTX) write(update func()) bool {
+	return tx.err == nil && tx.guard(func() {
+		tx.db.lockTree()
+		defer tx.db.treeMu.Unlock()
+		update()
+	})
+}
+
+// guard runs op, recording a corrupt data panic in tx.err
+// It reports whether op completed.
+func (tx *KVTX) guard(op func()) bool {
+	err := func() (err error) {
+		defer recoverCorruption(&err)
+		op()
+		return nil
+	}()
+	if err != nil && tx.err == nil {
+		tx.err = err
+	}
+	return err == nil
 }
 
 // Scan performs a range scan within the transaction
-func (tx *KVTX) Scan(start []byte, callback func(key, val []byte) bool) {
-	tx.db.tree.Scan(start, callback)
+// Reaching corrupt data stops the scan with an error, which also fails Commit.
+func (tx *KVTX) Scan(start []byte, callback func(key, val []byte) bool) error {
+	err := tx.db.Scan(start, callback)
+	if err != nil && tx.err == nil {
+		tx.err = err
+	}
+	return err
+}
+
+// ScanReverse performs a descending range scan within the transaction
+func (tx *KVTX) ScanReverse(start []byte, callback func(key, val []byte) bool) error {
+	err := tx.db.ScanReverse(start, callback)
+	if err != nil && tx.err == nil {
+		tx.err = err
+	}
+	return err
 }
 
 // NewIterator creates an iterator within the transaction
+// It yields values as stored, so encrypted values are returned sealed.
 func (tx *KVTX) NewIterator() *btree.BIter {
 	return tx.db.tree.NewIterator()
 }
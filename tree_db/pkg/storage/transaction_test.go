@@ -4,9 +4,15 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/wal"
 )
 
 func TestTransactionBasic(t *testing.T) {
@@ -213,3 +219,348 @@ func TestTransactionPersistence(t *testing.T) {
 		}
 	}
 }
+
+func TestTransactionLogsToWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx_wal.db")
+
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+
+	start := db.WAL().LastLSN()
+
+	tx := db.Begin()
+	tx.Set([]byte("key1"), []byte("value1"))
+	tx.Set([]byte("key2"), []byte("value2"))
+	tx.Del([]byte("key1"))
+	tx.Del([]byte("missing")) // Not logged: nothing was deleted
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	aborted := db.Begin()
+	aborted.Set([]byte("key3"), []byte("value3"))
+	aborted.Abort()
+
+	tailer, err := db.WAL().Tail(start)
+	if err != nil {
+		t.Fatalf("Failed to tail WAL: %v", err)
+	}
+	defer tailer.Close()
+
+	var got []string
+	var txnID uint64
+	for {
+		entry, err := tailer.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read WAL: %v", err)
+		}
+		switch entry.OpType {
+		case wal.OpCheckpoint:
+			continue
+		case wal.OpBegin, wal.OpInsert, wal.OpDelete, wal.OpCommit:
+			if txnID == 0 {
+				txnID = entry.TxnID
+			} else if entry.TxnID != txnID {
+				t.Errorf("Entry %s logged under a different transaction", entry)
+			}
+		}
+		got = append(got, fmt.Sprintf("%d:%s", entry.OpType, entry.Key))
+	}
+
+	want := "[6: 1:key1 1:key2 2:key1 3:]"
+	if fmt.Sprint(got) != want {
+		t.Errorf("WAL entries = %v, want %v", got, want)
+	}
+}
+
+func TestRolledBackCommitNotRecovered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx_rollback.db")
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+	db.Set([]byte("a"), []byte("old"))
+	start := db.WAL().LastLSN()
+
+	// The commit is logged, then its flush fails and rolls it back
+	db.SetFailpoint(failAt(PhaseSyncPages, 1, func() error { return errInjected }))
+	if err := db.Set([]byte("a"), []byte("new")); !errors.Is(err, errInjected) {
+		t.Fatalf("Set error = %v, want injected fault", err)
+	}
+	db.SetFailpoint(nil)
+
+	entries, err := wal.ReadAll(walFiles(t, path))
+	if err != nil {
+		t.Fatalf("Failed to read WAL: %v", err)
+	}
+	var ops []wal.OpType
+	for _, entry := range entries {
+		if entry.LSN > start && entry.OpType != wal.OpCheckpoint {
+			ops = append(ops, entry.OpType)
+		}
+	}
+	if want := []wal.OpType{wal.OpBegin, wal.OpInsert, wal.OpCommit, wal.OpAbort}; fmt.Sprint(ops) != fmt.Sprint(want) {
+		t.Errorf("Logged %v, want %v", ops, want)
+	}
+
+	// A crash now replays the WAL over the file, skipping the rolled-back commit
+	dir := t.TempDir()
+	for _, src := range append(walFiles(t, path), path) {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(src)), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	crashed := &KV{Path: filepath.Join(dir, filepath.Base(path))}
+	if err := crashed.Open(); err != nil {
+		t.Fatalf("Failed to open crash image: %v", err)
+	}
+	defer crashed.Close()
+	if val, _ := crashed.Get([]byte("a")); string(val) != "old" {
+		t.Errorf("Recovery replayed a rolled-back commit: a = %q", val)
+	}
+}
+
+func TestFailedAbortMarkerReported(t *testing.T) {
+	db := &KV{Path: filepath.Join(t.TempDir(), "tx_abort_marker.db")}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer db.Close()
+	db.Set([]byte("a"), []byte("old"))
+
+	// The flush fails with the log gone, so the rollback cannot be logged
+	db.SetFailpoint(failAt(PhaseSyncPages, 1, func() error {
+		db.WAL().Close()
+		return errInjected
+	}))
+	err := db.Set([]byte("a"), []byte("new"))
+	db.SetFailpoint(nil)
+	if !errors.Is(err, errInjected) || !errors.Is(err, wal.ErrLogClosed) {
+		t.Errorf("Set error = %v, want the fault and the abort marker's failure", err)
+	}
+	if stats := db.SyncStats(); !errors.Is(stats.LastError, wal.ErrLogClosed) {
+		t.Errorf("Expected the abort marker's failure in stats, got %v", stats.LastError)
+	}
+}
+
+// walFiles returns the live WAL files of the database at path
+func walFiles(t *testing.T, path string) []string {
+	t.Helper()
+	files, err := wal.LogFiles(WALPath(path))
+	if err != nil {
+		t.Fatalf("Failed to list WAL files: %v", err)
+	}
+	return files
+}
+
+func TestTransactionSavepoints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx_savepoint.db")
+
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+
+	// Enough committed keys that staged writes reuse free pages and split nodes
+	for i := 0; i < 200; i++ {
+		db.Set([]byte(fmt.Sprintf("base%03d", i)), []byte("value"))
+	}
+
+	tx := db.BeginTx()
+	tx.Set([]byte("a"), []byte("1"))
+	outer := tx.Savepoint()
+
+	for i := 0; i < 200; i++ {
+		tx.Set([]byte(fmt.Sprintf("outer%03d", i)), []byte("value"))
+	}
+	tx.Del([]byte("a"))
+	inner := tx.Savepoint()
+
+	for i := 0; i < 200; i++ {
+		tx.Del([]byte(fmt.Sprintf("base%03d", i)))
+	}
+
+	// Undo the inner step only
+	if err := tx.RollbackTo(inner); err != nil {
+		t.Fatalf("RollbackTo(inner) failed: %v", err)
+	}
+	if _, ok := tx.Get([]byte("base100")); !ok {
+		t.Error("Delete after inner savepoint survived rollback")
+	}
+	if _, ok := tx.Get([]byte("outer100")); !ok {
+		t.Error("Write before inner savepoint was rolled back")
+	}
+
+	// Retry, then undo both steps
+	tx.Set([]byte("retried"), []byte("1"))
+	if err := tx.RollbackTo(outer); err != nil {
+		t.Fatalf("RollbackTo(outer) failed: %v", err)
+	}
+	if err := tx.RollbackTo(inner); err != ErrUnknownSavepoint {
+		t.Errorf("RollbackTo(discarded) error = %v, want ErrUnknownSavepoint", err)
+	}
+	for _, key := range []string{"outer100", "retried"} {
+		if _, ok := tx.Get([]byte(key)); ok {
+			t.Errorf("%s survived rollback to outer savepoint", key)
+		}
+	}
+
+	released := tx.Savepoint()
+	tx.Set([]byte("b"), []byte("2"))
+	if err := tx.Release(released); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if err := tx.RollbackTo(released); err != ErrUnknownSavepoint {
+		t.Errorf("RollbackTo(released) error = %v, want ErrUnknownSavepoint", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if err := tx.Commit(); err != ErrTxDone {
+		t.Errorf("Second Commit error = %v, want ErrTxDone", err)
+	}
+	tx.Abort() // No effect once committed
+
+	report, err := db.Audit(false)
+	if err != nil {
+		t.Fatalf("Audit failed: %v", err)
+	}
+	if len(report.Leaked) != 0 || len(report.Conflicts) != 0 {
+		t.Errorf("Rolled back pages leaked %v, conflicts %v", report.Leaked, report.Conflicts)
+	}
+	db.Close()
+
+	// Only the surviving writes were logged and persisted
+	db = &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	defer db.Close()
+
+	want := map[string]bool{"a": true, "b": true, "base100": true, "outer100": false, "retried": false}
+	for key, present := range want {
+		if _, ok := db.Get([]byte(key)); ok != present {
+			t.Errorf("%s present = %v, want %v", key, ok, present)
+		}
+	}
+}
+
+func TestTransactionAbortKeepsOtherCommits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx_concurrent.db")
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+
+	aborted := db.Begin()
+	aborted.Set([]byte("a"), []byte("aborted"))
+
+	// A second transaction waits for the first rather than sharing its tree
+	committed := make(chan error)
+	go func() {
+		tx := db.Begin()
+		tx.Set([]byte("b"), []byte("committed"))
+		committed <- tx.Commit()
+	}()
+	select {
+	case err := <-committed:
+		t.Fatalf("Second transaction committed while the first was open: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	aborted.Abort()
+	if err := <-committed; err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	check := func(db *KV, when string) {
+		if _, ok := db.Get([]byte("a")); ok {
+			t.Errorf("%s: aborted write is visible", when)
+		}
+		if val, ok := db.Get([]byte("b")); !ok || string(val) != "committed" {
+			t.Errorf("%s: committed write lost, got %q", when, val)
+		}
+	}
+	check(db, "before reopening")
+	db.Close()
+
+	db = &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	defer db.Close()
+	check(db, "after reopening")
+}
+
+func TestSavepointRollbackKeepsOtherCommits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx_interleaved.db")
+	db := &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+
+	first := db.BeginTx()
+	first.Set([]byte("first"), []byte("kept"))
+
+	// The second transaction rolls back a step after the first commits
+	committed := make(chan error)
+	go func() {
+		tx := db.BeginTx()
+		tx.Set([]byte("second"), []byte("kept"))
+		sp := tx.Savepoint()
+		tx.Set([]byte("second-step"), []byte("undone"))
+		tx.Del([]byte("first"))
+		if err := tx.RollbackTo(sp); err != nil {
+			committed <- err
+			return
+		}
+		committed <- tx.Commit()
+	}()
+
+	sp := first.Savepoint()
+	first.Set([]byte("first-step"), []byte("undone"))
+	if err := first.RollbackTo(sp); err != nil {
+		t.Fatalf("RollbackTo failed: %v", err)
+	}
+	if err := first.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if err := <-committed; err != nil {
+		t.Fatalf("Second transaction failed: %v", err)
+	}
+
+	// A rollback of either transaction undoes none of the other's writes
+	check := func(db *KV, when string) {
+		for _, key := range []string{"first", "second"} {
+			if val, ok := db.Get([]byte(key)); !ok || string(val) != "kept" {
+				t.Errorf("%s: %s lost, got %q", when, key, val)
+			}
+		}
+		for _, key := range []string{"first-step", "second-step"} {
+			if _, ok := db.Get([]byte(key)); ok {
+				t.Errorf("%s: rolled back %s is visible", when, key)
+			}
+		}
+	}
+	check(db, "before reopening")
+	db.Close()
+
+	db = &KV{Path: path}
+	if err := db.Open(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	defer db.Close()
+	check(db, "after reopening")
+}
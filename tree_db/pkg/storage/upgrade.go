@@ -0,0 +1,65 @@
+// ABOUTME: Upgrades databases created before page checksums
+// ABOUTME: Copies every key of a legacy file into a new checksummed file
+
+package storage
+
+import (
+	"fmt"
+	"os"
+)
+
+// upgradeBatchSize is the number of keys copied per transaction
+const upgradeBatchSize = 1000
+
+// Upgrade copies the legacy database at src into a new database at dst
+// Transactions still in src's WAL are replayed first, so dst holds every
+// committed write. It returns the number of keys copied.
+func Upgrade(src, dst string) (int, error) {
+	if _, err := os.Stat(dst); err == nil {
+		return 0, fmt.Errorf("upgrade target %s already exists", dst)
+	}
+
+	old := &KV{Path: src}
+	if err := old.Open(); err != nil {
+		return 0, err
+	}
+	defer old.Close()
+	if !old.legacy {
+		return 0, fmt.Errorf("%s already uses the current format", src)
+	}
+
+	db := &KV{Path: dst}
+	if err := db.Open(); err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	copied := 0
+	var err error
+	tx := db.Begin()
+	scanErr := old.Scan(nil, func(key, val []byte) bool {
+		if len(key) == 0 {
+			return true // B+Tree sentinel
+		}
+		tx.Set(key, val)
+		copied++
+		if copied%upgradeBatchSize == 0 {
+			if err = tx.Commit(); err != nil {
+				return false
+			}
+			tx = db.Begin()
+		}
+		return true
+	})
+	if err == nil {
+		err = scanErr
+	}
+	if err != nil {
+		tx.Abort()
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return copied, nil
+}
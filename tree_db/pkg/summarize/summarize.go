@@ -0,0 +1,167 @@
+// ABOUTME: Clients of external summarization services that RefreshSummaries sends node text to
+// ABOUTME: Services are reached over HTTP with JSON or over gRPC with the Summarizer service
+
+package summarize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/nainya/treestore/pkg/convert"
+	"github.com/nainya/treestore/pkg/document"
+	pb "github.com/nainya/treestore/proto"
+)
+
+// DefaultTimeout bounds one call to a summarization service
+const DefaultTimeout = 2 * time.Minute
+
+// maxResponse bounds the response read from an HTTP service
+const maxResponse = 4 << 20
+
+// Client is a summarization service that holds a connection until closed
+type Client interface {
+	document.Summarizer
+	Close() error
+}
+
+// New connects to the service at target: an http:// or https:// URL, or a
+// grpc://host:port address. Each call is limited to timeout; 0 uses
+// DefaultTimeout.
+func New(target string, timeout time.Duration) (Client, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	switch {
+	case strings.HasPrefix(target, "http://"), strings.HasPrefix(target, "https://"):
+		return &HTTPSummarizer{URL: target, Timeout: timeout}, nil
+	case strings.HasPrefix(target, "grpc://"):
+		conn, err := grpc.NewClient(strings.TrimPrefix(target, "grpc://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+		return &GRPCSummarizer{Client: pb.NewSummarizerClient(conn), Timeout: timeout, conn: conn}, nil
+	default:
+		return nil, fmt.Errorf("summarizer %q is not an http://, https:// or grpc:// address", target)
+	}
+}
+
+// httpNode is the JSON form of a node sent to an HTTP service
+type httpNode struct {
+	PolicyID    string `json:"policy_id"`
+	NodeID      string `json:"node_id"`
+	Title       string `json:"title"`
+	SectionPath string `json:"section_path"`
+	Language    string `json:"language,omitempty"`
+	Text        string `json:"text"`
+}
+
+// HTTPSummarizer posts {"nodes": [...]} to a URL and reads the service's
+// {"summaries": [...]} answer, one per node in order. Any status but 200
+// fails the call.
+type HTTPSummarizer struct {
+	URL     string
+	Timeout time.Duration // 0 leaves calls bounded by the caller's context alone
+	Client  *http.Client  // nil uses http.DefaultClient
+}
+
+// Summarize asks the service to summarize nodes
+func (h *HTTPSummarizer) Summarize(ctx context.Context, nodes []*document.Node) ([]string, error) {
+	req := struct {
+		Nodes []httpNode `json:"nodes"`
+	}{Nodes: make([]httpNode, len(nodes))}
+	for i, node := range nodes {
+		req.Nodes[i] = httpNode{
+			PolicyID:    node.PolicyID,
+			NodeID:      node.NodeID,
+			Title:       node.Title,
+			SectionPath: node.SectionPath,
+			Language:    node.Language,
+			Text:        node.Text,
+		}
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(data) > 200 {
+			data = data[:200]
+		}
+		return nil, fmt.Errorf("%s answered %s: %s", h.URL, resp.Status, bytes.TrimSpace(data))
+	}
+	var out struct {
+		Summaries []string `json:"summaries"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("%s answered with invalid summaries: %v", h.URL, err)
+	}
+	return out.Summaries, nil
+}
+
+// Close does nothing; HTTP connections are pooled by the client
+func (h *HTTPSummarizer) Close() error { return nil }
+
+// GRPCSummarizer calls a service implementing the Summarizer gRPC service
+type GRPCSummarizer struct {
+	Client  pb.SummarizerClient
+	Timeout time.Duration // 0 leaves calls bounded by the caller's context alone
+
+	conn *grpc.ClientConn // Closed by Close when New dialed it
+}
+
+// Summarize asks the service to summarize nodes
+func (g *GRPCSummarizer) Summarize(ctx context.Context, nodes []*document.Node) ([]string, error) {
+	if g.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.Timeout)
+		defer cancel()
+	}
+	resp, err := g.Client.Summarize(ctx, &pb.SummarizeRequest{Nodes: convert.NodesToPb(nodes)})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Summaries, nil
+}
+
+// Close closes the connection New dialed
+func (g *GRPCSummarizer) Close() error {
+	if g.conn == nil {
+		return nil
+	}
+	return g.conn.Close()
+}
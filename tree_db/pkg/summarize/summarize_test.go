@@ -0,0 +1,97 @@
+// ABOUTME: Tests for the HTTP and gRPC summarization clients
+// ABOUTME: Verifies nodes are sent with their text and summaries come back in order, and errors fail the call
+
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/nainya/treestore/pkg/document"
+	pb "github.com/nainya/treestore/proto"
+)
+
+var testNodes = []*document.Node{
+	{PolicyID: "LCD-1", NodeID: "a", Title: "Scope", Text: "covered services"},
+	{PolicyID: "LCD-1", NodeID: "b", Title: "Codes", Text: "billing codes"},
+}
+
+func TestHTTPSummarizer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			Nodes []httpNode `json:"nodes"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		if len(body.Nodes) == 0 {
+			http.Error(w, "no nodes", http.StatusBadRequest)
+			return
+		}
+		summaries := make([]string, len(body.Nodes))
+		for i, node := range body.Nodes {
+			summaries[i] = node.Title + ": " + node.Text
+		}
+		json.NewEncoder(w).Encode(map[string][]string{"summaries": summaries})
+	}))
+	defer srv.Close()
+
+	s, err := New(srv.URL, 0)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer s.Close()
+	summaries, err := s.Summarize(context.Background(), testNodes)
+	if err != nil || len(summaries) != 2 || summaries[1] != "Codes: billing codes" {
+		t.Errorf("Expected a summary per node, got %v (%v)", summaries, err)
+	}
+	if _, err := s.Summarize(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "no nodes") {
+		t.Errorf("Expected the service's error, got %v", err)
+	}
+
+	if _, err := New("ftp://summaries", 0); err == nil {
+		t.Error("Expected an unsupported scheme rejected")
+	}
+}
+
+// upperServer summarizes a node as its upper-cased text
+type upperServer struct {
+	pb.UnimplementedSummarizerServer
+}
+
+func (upperServer) Summarize(ctx context.Context, req *pb.SummarizeRequest) (*pb.SummarizeResponse, error) {
+	resp := &pb.SummarizeResponse{}
+	for _, node := range req.Nodes {
+		resp.Summaries = append(resp.Summaries, strings.ToUpper(node.Text))
+	}
+	return resp, nil
+}
+
+func TestGRPCSummarizer(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterSummarizerServer(srv, upperServer{})
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	s := &GRPCSummarizer{Client: pb.NewSummarizerClient(conn), Timeout: DefaultTimeout}
+	summaries, err := s.Summarize(context.Background(), testNodes)
+	if err != nil || len(summaries) != 2 || summaries[0] != "COVERED SERVICES" {
+		t.Errorf("Expected a summary per node, got %v (%v)", summaries, err)
+	}
+}
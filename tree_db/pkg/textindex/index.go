@@ -0,0 +1,118 @@
+// ABOUTME: Reusable inverted index over KV storage for keyword search
+// ABOUTME: Postings are keyed as (term, entity key columns...) -> weight
+
+package textindex
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// Field is a piece of text contributing to an entity's postings with a weight
+type Field struct {
+	Text   string
+	Weight int64
+}
+
+// Index is an inverted index stored under a single key prefix
+type Index struct {
+	prefix uint32
+}
+
+// New creates an index whose postings live under the given prefix
+func New(prefix uint32) *Index {
+	return &Index{prefix: prefix}
+}
+
+// MaxTermBytes is the longest term that is indexed; longer runs of letters and
+// digits are dropped so posting keys stay within the B+Tree key limit
+const MaxTermBytes = 128
+
+// Tokenize splits text into lowercase terms on non-alphanumeric boundaries
+func Tokenize(text string) []string {
+	terms := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := terms[:0]
+	for _, term := range terms {
+		if len(term) <= MaxTermBytes {
+			out = append(out, term)
+		}
+	}
+	return out
+}
+
+// UniqueTerms tokenizes text and drops repeated terms, keeping first-seen order
+func UniqueTerms(text string) []string {
+	terms := Tokenize(text)
+	seen := make(map[string]bool, len(terms))
+	out := terms[:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			out = append(out, term)
+		}
+	}
+	return out
+}
+
+// Weights computes the posting weight of every distinct term across the fields
+// Each field adds its weight once per distinct term it contains
+func Weights(fields ...Field) map[string]int64 {
+	weights := make(map[string]int64)
+	for _, field := range fields {
+		seen := make(map[string]bool)
+		for _, term := range Tokenize(field.Text) {
+			if !seen[term] {
+				seen[term] = true
+				weights[term] += field.Weight
+			}
+		}
+	}
+	return weights
+}
+
+// Update replaces the postings of an entity: terms of old are removed, terms of
+// cur are written. Either map may be nil for inserts and deletes.
+func (ix *Index) Update(tx storage.Txn, entity []storage.Value, old, cur map[string]int64) {
+	for term := range old {
+		if _, ok := cur[term]; !ok {
+			tx.Del(ix.key(term, entity))
+		}
+	}
+
+	for term, weight := range cur {
+		val := storage.EncodeValues([]storage.Value{storage.NewInt64Value(weight)})
+		tx.Set(ix.key(term, entity), val)
+	}
+}
+
+// Postings calls fn for every posting of a term whose entity key starts with scope
+// fn receives the entity key columns and posting weight; returning false stops the scan
+func (ix *Index) Postings(kv storage.Engine, term string, scope []storage.Value, fn func(entity []storage.Value, weight int64) bool) error {
+	startKey := ix.key(term, scope)
+
+	return storage.ScanPrefix(kv, startKey, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 1+len(scope) {
+			return true
+		}
+
+		weight := int64(0)
+		if decoded, err := storage.DecodeValues(val); err == nil && len(decoded) > 0 {
+			weight = decoded[0].I64
+		}
+
+		return fn(vals[1:], weight)
+	})
+}
+
+// key builds the posting key for a term and entity
+func (ix *Index) key(term string, entity []storage.Value) []byte {
+	vals := make([]storage.Value, 0, 1+len(entity))
+	vals = append(vals, storage.NewBytesValue([]byte(term)))
+	vals = append(vals, entity...)
+	return storage.EncodeKey(ix.prefix, vals)
+}
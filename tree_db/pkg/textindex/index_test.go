@@ -0,0 +1,88 @@
+// ABOUTME: Tests for the shared inverted index
+// ABOUTME: Verifies tokenization, weighting and scoped posting scans
+
+package textindex
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+func TestTokenizeAndWeights(t *testing.T) {
+	terms := Tokenize("Prior-Auth, prior AUTH 2024!")
+	if len(terms) != 5 || terms[0] != "prior" || terms[4] != "2024" {
+		t.Errorf("Unexpected tokens: %v", terms)
+	}
+
+	long := strings.Repeat("x", MaxTermBytes+1)
+	if terms := Tokenize("keep " + long + " this"); len(terms) != 2 || terms[1] != "this" {
+		t.Errorf("Expected overlong term dropped, got %d terms", len(terms))
+	}
+
+	if unique := UniqueTerms("a b a c b"); len(unique) != 3 {
+		t.Errorf("Expected 3 unique terms, got %v", unique)
+	}
+
+	weights := Weights(Field{Text: "knee knee", Weight: 3}, Field{Text: "knee surgery", Weight: 1})
+	if weights["knee"] != 4 || weights["surgery"] != 1 {
+		t.Errorf("Unexpected weights: %v", weights)
+	}
+}
+
+func TestUpdateAndPostings(t *testing.T) {
+	path := "/tmp/test_textindex_" + t.Name() + ".db"
+	kv := &storage.KV{Path: path}
+	if err := kv.Open(); err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer os.Remove(path)
+	defer kv.Close()
+
+	ix := New(9900)
+	entity := func(scope, id string) []storage.Value {
+		return []storage.Value{storage.NewBytesValue([]byte(scope)), storage.NewBytesValue([]byte(id))}
+	}
+
+	tx := kv.Begin()
+	ix.Update(tx, entity("a", "1"), nil, map[string]int64{"knee": 2, "mri": 1})
+	ix.Update(tx, entity("b", "2"), nil, map[string]int64{"knee": 5})
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	total := int64(0)
+	ix.Postings(kv, "knee", nil, func(e []storage.Value, w int64) bool {
+		total += w
+		return true
+	})
+	if total != 7 {
+		t.Errorf("Expected total weight 7, got %d", total)
+	}
+
+	count := 0
+	ix.Postings(kv, "knee", entity("b", "")[:1], func(e []storage.Value, w int64) bool {
+		count++
+		return true
+	})
+	if count != 1 {
+		t.Errorf("Expected 1 scoped posting, got %d", count)
+	}
+
+	// Replacing postings drops terms no longer present
+	tx = kv.Begin()
+	ix.Update(tx, entity("a", "1"), map[string]int64{"knee": 2, "mri": 1}, map[string]int64{"knee": 1})
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	count = 0
+	ix.Postings(kv, "mri", nil, func(e []storage.Value, w int64) bool {
+		count++
+		return true
+	})
+	if count != 0 {
+		t.Errorf("Expected stale posting removed, got %d", count)
+	}
+}
@@ -0,0 +1,201 @@
+// ABOUTME: Effective date model for policy versions
+// ABOUTME: Indexes effective periods, resolves as-of lookups and rejects overlaps
+
+package version
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// ErrEffectiveOverlap is returned when a version's effective period overlaps another version
+var ErrEffectiveOverlap = errors.New("effective period overlaps an existing version")
+
+// effectiveKey builds the effective date index key of a version
+func effectiveKey(v *Version) []byte {
+	return storage.EncodeKey(PREFIX_VERSION_EFFECT, []storage.Value{
+		storage.NewBytesValue([]byte(v.PolicyID)),
+		storage.NewTimeValue(v.EffectiveFrom),
+		storage.NewBytesValue([]byte(v.VersionID)),
+	})
+}
+
+// versionAsOf returns the version in effect at asOfTime, or nil if there is none
+// The newest version that took effect at or before asOfTime supersedes earlier
+// ones; it is in effect unless asOfTime is past its EffectiveTo. A policy
+// stored before the effective date index, and not yet indexed by
+// IndexEffectiveDates, is resolved by creation time instead.
+func (vs *VersionStore) versionAsOf(policyID string, asOfTime time.Time) (*Version, error) {
+	prefix := effectivePrefix(policyID)
+	endKey := storage.EncodeKeyPartial(PREFIX_VERSION_EFFECT, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewTimeValue(asOfTime),
+	}, storage.CMP_LE)
+
+	versionID, err := firstIndexedVersion(storage.ScanPrefixReverse, vs.kv, prefix, endKey, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if versionID == "" {
+		indexed, err := firstIndexedVersion(storage.ScanPrefixFrom, vs.kv, prefix, prefix, "")
+		if err != nil {
+			return nil, err
+		}
+		if indexed != "" {
+			return nil, nil
+		}
+		if versionID, err = vs.versionIDCreatedBy(policyID, asOfTime); err != nil || versionID == "" {
+			return nil, err
+		}
+	}
+
+	v, err := vs.GetVersion(policyID, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if !v.EffectiveAt(asOfTime) {
+		return nil, nil
+	}
+
+	return v, nil
+}
+
+// versionIDCreatedBy finds the newest version created at or before asOfTime
+// from the time index, which every version has
+func (vs *VersionStore) versionIDCreatedBy(policyID string, asOfTime time.Time) (string, error) {
+	prefix := storage.EncodeKey(PREFIX_VERSION_TIME, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+	})
+	endKey := storage.EncodeKeyPartial(PREFIX_VERSION_TIME, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewTimeValue(asOfTime),
+	}, storage.CMP_LE)
+
+	return firstIndexedVersion(storage.ScanPrefixReverse, vs.kv, prefix, endKey, "")
+}
+
+// checkEffectiveOverlap validates a new version's effective period against the policy's versions
+// Of two versions, the one taking effect later supersedes the earlier one, so
+// they overlap only if they take effect at the same time or the earlier one has
+// an explicit EffectiveTo after the later one takes effect. As the stored
+// versions do not overlap each other, only the last one taking effect at or
+// before v and the first one after it are read. Callers hold vs.mu.
+func (vs *VersionStore) checkEffectiveOverlap(v *Version) error {
+	if !v.EffectiveTo.IsZero() && !v.EffectiveTo.After(v.EffectiveFrom) {
+		return fmt.Errorf("effective_to %s must be after effective_from %s", v.EffectiveTo, v.EffectiveFrom)
+	}
+
+	prefix := effectivePrefix(v.PolicyID)
+	from := []storage.Value{
+		storage.NewBytesValue([]byte(v.PolicyID)),
+		storage.NewTimeValue(v.EffectiveFrom),
+	}
+
+	beforeID, err := firstIndexedVersion(storage.ScanPrefixReverse, vs.kv, prefix,
+		storage.EncodeKeyPartial(PREFIX_VERSION_EFFECT, from, storage.CMP_LE), v.VersionID)
+	if err != nil {
+		return err
+	}
+	afterID, err := firstIndexedVersion(storage.ScanPrefixFrom, vs.kv, prefix,
+		storage.EncodeKeyPartial(PREFIX_VERSION_EFFECT, from, storage.CMP_GT), v.VersionID)
+	if err != nil {
+		return err
+	}
+
+	for _, otherID := range []string{beforeID, afterID} {
+		if otherID == "" {
+			continue
+		}
+		other, err := vs.GetVersion(v.PolicyID, otherID)
+		if err != nil {
+			return err
+		}
+
+		earlier, later := other, v
+		if v.EffectiveFrom.Before(other.EffectiveFrom) {
+			earlier, later = v, other
+		}
+
+		if earlier.EffectiveFrom.Unix() == later.EffectiveFrom.Unix() ||
+			(!earlier.EffectiveTo.IsZero() && later.EffectiveFrom.Before(earlier.EffectiveTo)) {
+			return fmt.Errorf("%w: %s/%s and %s", ErrEffectiveOverlap, v.PolicyID, v.VersionID, other.VersionID)
+		}
+	}
+
+	return nil
+}
+
+// effectivePrefix returns the effective date index prefix of a policy
+func effectivePrefix(policyID string) []byte {
+	return storage.EncodeKey(PREFIX_VERSION_EFFECT, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+	})
+}
+
+// firstIndexedVersion returns the version ID of the first index entry scan
+// visits under prefix from start, skipping skipID; "" if there is none
+func firstIndexedVersion(
+	scan func(s storage.Scanner, prefix, start []byte, callback func(key, val []byte) bool) error,
+	s storage.Scanner, prefix, start []byte, skipID string,
+) (string, error) {
+	var versionID string
+	err := scan(s, prefix, start, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 3 || string(vals[2].Str) == skipID {
+			return true
+		}
+
+		versionID = string(vals[2].Str)
+		return false
+	})
+
+	return versionID, err
+}
+
+// IndexEffectiveDates adds the versions stored before the effective date
+// index to it, returning how many it added. As-of lookups of a policy none of
+// whose versions are indexed fall back to creation times, but a policy with
+// older versions and newer ones needs them indexed; treestore-admin migrate
+// runs it. Versions already indexed are left alone, so it can run again.
+func (vs *VersionStore) IndexEffectiveDates() (int, error) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	tx := vs.kv.Begin()
+	defer tx.Abort()
+
+	var missing [][]byte
+	var decodeErr error
+	err := storage.ScanPrefix(tx, storage.EncodeKey(PREFIX_VERSION, nil), func(key, val []byte) bool {
+		v, err := decodeVersion(val)
+		if err != nil {
+			decodeErr = fmt.Errorf("version %x: %w", key, err)
+			return false
+		}
+		if _, ok := tx.Get(effectiveKey(v)); !ok {
+			missing = append(missing, effectiveKey(v))
+		}
+		return true
+	})
+	if err == nil {
+		err = decodeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+
+	for _, key := range missing {
+		tx.Set(key, []byte{})
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(missing), nil
+}
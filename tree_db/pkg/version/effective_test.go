@@ -0,0 +1,208 @@
+// ABOUTME: Tests for effective-date version resolution
+// ABOUTME: Verifies as-of lookups by effective period and overlap validation
+
+package version
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+func TestEffectiveDates(t *testing.T) {
+	vs, kv, path := setupTestVersionStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	jan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// v1 is effective from creation; v2 is entered in January but effective in February
+	if err := vs.CreateVersion(&Version{PolicyID: "policy1", VersionID: "v1", CreatedAt: jan}); err != nil {
+		t.Fatalf("CreateVersion failed: %v", err)
+	}
+	if err := vs.CreateVersion(&Version{PolicyID: "policy1", VersionID: "v2", CreatedAt: jan.Add(time.Hour), EffectiveFrom: feb}); err != nil {
+		t.Fatalf("CreateVersion failed: %v", err)
+	}
+
+	cases := []struct {
+		asOf     time.Time
+		expected string
+	}{
+		{jan.Add(2 * time.Hour), "v1"},
+		{feb.Add(-time.Second), "v1"},
+		{feb, "v2"},
+		{mar, "v2"},
+	}
+	for _, tc := range cases {
+		v, err := vs.GetVersionAsOf("policy1", tc.asOf)
+		if err != nil || v.VersionID != tc.expected {
+			t.Errorf("As of %s expected %s, got %v (%v)", tc.asOf, tc.expected, v, err)
+		}
+	}
+
+	stored, err := vs.GetVersion("policy1", "v2")
+	if err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if !stored.EffectiveFrom.Equal(feb) || !stored.EffectiveTo.IsZero() {
+		t.Errorf("Expected effective period [%s, open), got [%s, %s)", feb, stored.EffectiveFrom, stored.EffectiveTo)
+	}
+
+	// A bounded version leaves a gap after it ends
+	if err := vs.CreateVersion(&Version{PolicyID: "policy2", VersionID: "v1", CreatedAt: jan, EffectiveTo: feb}); err != nil {
+		t.Fatalf("CreateVersion failed: %v", err)
+	}
+	if _, err := vs.GetVersionAsOf("policy2", mar); err == nil {
+		t.Error("Expected no version in effect after EffectiveTo")
+	}
+
+	// Overlaps are rejected
+	overlapping := []*Version{
+		{PolicyID: "policy1", VersionID: "v3", CreatedAt: mar, EffectiveFrom: feb},
+		{PolicyID: "policy2", VersionID: "v2", CreatedAt: mar, EffectiveFrom: jan.Add(24 * time.Hour)},
+		{PolicyID: "policy1", VersionID: "v4", CreatedAt: mar, EffectiveFrom: jan.Add(-24 * time.Hour), EffectiveTo: mar},
+	}
+	for _, v := range overlapping {
+		if err := vs.CreateVersion(v); !errors.Is(err, ErrEffectiveOverlap) {
+			t.Errorf("Expected ErrEffectiveOverlap for %s/%s, got %v", v.PolicyID, v.VersionID, err)
+		}
+	}
+
+	if err := vs.CreateVersion(&Version{PolicyID: "policy2", VersionID: "v2", CreatedAt: mar, EffectiveFrom: feb}); err != nil {
+		t.Errorf("Expected version starting at previous EffectiveTo to be accepted: %v", err)
+	}
+	if err := vs.CreateVersion(&Version{PolicyID: "policy3", VersionID: "v1", CreatedAt: jan, EffectiveFrom: feb, EffectiveTo: jan}); err == nil {
+		t.Error("Expected error for EffectiveTo before EffectiveFrom")
+	}
+
+	// Deleting a version removes it from effective-date lookups
+	if err := vs.DeleteVersion("policy1", "v2"); err != nil {
+		t.Fatalf("DeleteVersion failed: %v", err)
+	}
+	if v, err := vs.GetVersionAsOf("policy1", mar); err != nil || v.VersionID != "v1" {
+		t.Errorf("Expected v1 after deleting v2, got %v (%v)", v, err)
+	}
+}
+
+func TestConcurrentOverlappingCreates(t *testing.T) {
+	vs, kv, _ := setupTestVersionStore(t)
+	defer kv.Close()
+
+	jan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = vs.CreateVersion(&Version{PolicyID: "policy1", VersionID: fmt.Sprintf("v%d", i), CreatedAt: jan})
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, ErrEffectiveOverlap):
+			t.Errorf("Expected ErrEffectiveOverlap, got %v", err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("Expected one of the versions taking effect together created, got %d", created)
+	}
+}
+
+// storeUnindexedVersion writes a version as stored before effective dates:
+// a tuple, the time index and the latest pointer, with no effective date entry
+func storeUnindexedVersion(t *testing.T, kv *storage.KV, policyID, versionID string, created time.Time) {
+	t.Helper()
+
+	tx := kv.Begin()
+	tx.Set(storage.EncodeKey(PREFIX_VERSION, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(versionID)),
+	}), storage.EncodeValues([]storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(versionID)),
+		storage.NewBytesValue([]byte("doc1")),
+		storage.NewTimeValue(created),
+		storage.NewBytesValue([]byte("ingest")),
+		storage.NewBytesValue(nil),
+		storage.NewBytesValue(nil),
+		storage.NewBytesValue(nil),
+	}))
+	tx.Set(storage.EncodeKey(PREFIX_VERSION_TIME, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewTimeValue(created),
+		storage.NewBytesValue([]byte(versionID)),
+	}), []byte{})
+	tx.Set(storage.EncodeKey(PREFIX_LATEST_VERSION, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+	}), []byte(versionID))
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+}
+
+func TestVersionsWithoutEffectiveIndex(t *testing.T) {
+	vs, kv, _ := setupTestVersionStore(t)
+	defer kv.Close()
+
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	storeUnindexedVersion(t, kv, "policy1", "v1", jan)
+	storeUnindexedVersion(t, kv, "policy1", "v2", feb)
+
+	expectAsOf := func(asOf time.Time, expected string) {
+		t.Helper()
+		v, err := vs.GetVersionAsOf("policy1", asOf)
+		if expected == "" {
+			if err == nil {
+				t.Errorf("As of %s expected no version, got %s", asOf, v.VersionID)
+			}
+			return
+		}
+		if err != nil || v.VersionID != expected {
+			t.Errorf("As of %s expected %s, got %v (%v)", asOf, expected, v, err)
+		}
+	}
+
+	// With none of the policy's versions indexed, creation times resolve them
+	expectAsOf(jan.Add(-time.Hour), "")
+	expectAsOf(jan.Add(time.Hour), "v1")
+	expectAsOf(feb.Add(time.Hour), "v2")
+
+	// Once a newer version is indexed, the older ones need indexing too
+	if err := vs.CreateVersion(&Version{PolicyID: "policy1", VersionID: "v3", CreatedAt: mar}); err != nil {
+		t.Fatalf("CreateVersion failed: %v", err)
+	}
+	expectAsOf(feb.Add(time.Hour), "")
+
+	n, err := vs.IndexEffectiveDates()
+	if err != nil || n != 2 {
+		t.Fatalf("Expected 2 versions indexed, got %d (%v)", n, err)
+	}
+	expectAsOf(jan.Add(time.Hour), "v1")
+	expectAsOf(feb.Add(time.Hour), "v2")
+	expectAsOf(mar.Add(time.Hour), "v3")
+
+	if n, err := vs.IndexEffectiveDates(); err != nil || n != 0 {
+		t.Errorf("Expected nothing left to index, got %d (%v)", n, err)
+	}
+
+	// Indexed versions take part in overlap checks
+	err = vs.CreateVersion(&Version{PolicyID: "policy1", VersionID: "v4", CreatedAt: mar, EffectiveFrom: jan.Add(-time.Hour), EffectiveTo: feb})
+	if !errors.Is(err, ErrEffectiveOverlap) {
+		t.Errorf("Expected ErrEffectiveOverlap with an indexed older version, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+// ABOUTME: Version deletion and pruning of obsolete versions
+// ABOUTME: Removes primary records and indexes and keeps the latest pointer valid
+
+package version
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// PruneOptions selects versions to remove with PruneVersions
+// A version is pruned only if it is outside the newest KeepLast versions, older
+// than OlderThan (when set), carries none of ProtectTags and is not the latest.
+type PruneOptions struct {
+	KeepLast    int       // Newest versions always kept (0 keeps none beyond the latest)
+	OlderThan   time.Time // Only prune versions created before this time (zero for any age)
+	ProtectTags []string  // Versions with any of these tags are kept
+	DryRun      bool      // Report what would be pruned without deleting
+}
+
+// timelineEntry is one version in a policy's creation-time index
+type timelineEntry struct {
+	versionID string
+	createdAt time.Time
+}
+
+// DeleteVersion removes a version and its index entries
+// If it was the latest version, the latest pointer moves to the newest remaining
+// version, or is removed when none remain.
+func (vs *VersionStore) DeleteVersion(policyID, versionID string) error {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	v, err := vs.GetVersion(policyID, versionID)
+	if err != nil {
+		return err
+	}
+
+	tx := vs.kv.Begin()
+	deleteVersionKeys(tx, v)
+	if err := vs.repointLatest(tx, policyID, map[string]bool{versionID: true}); err != nil {
+		tx.Abort()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	vs.feed.Publish(changefeed.EntityVersion, changefeed.OpDelete, policyID, versionID)
+	return nil
+}
+
+// PruneVersions deletes the versions of a policy selected by opts in one transaction
+// It returns the IDs of the pruned versions, oldest first.
+func (vs *VersionStore) PruneVersions(policyID string, opts PruneOptions) ([]string, error) {
+	if opts.KeepLast < 0 {
+		return nil, fmt.Errorf("keep_last must not be negative")
+	}
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	timeline, err := vs.timeline(policyID)
+	if err != nil {
+		return nil, err
+	}
+	latestID, _, err := vs.latestID(policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	protected := make(map[string]bool, len(opts.ProtectTags))
+	for _, tag := range opts.ProtectTags {
+		protected[tag] = true
+	}
+
+	var victims []*Version
+	cutoff := len(timeline) - opts.KeepLast
+	for i := 0; i < cutoff; i++ {
+		entry := timeline[i]
+		if entry.versionID == latestID {
+			continue
+		}
+		if !opts.OlderThan.IsZero() && !entry.createdAt.Before(opts.OlderThan) {
+			continue
+		}
+
+		v, err := vs.GetVersion(policyID, entry.versionID)
+		if err != nil {
+			return nil, err
+		}
+		if hasProtectedTag(v, protected) {
+			continue
+		}
+		victims = append(victims, v)
+	}
+
+	pruned := make([]string, len(victims))
+	for i, v := range victims {
+		pruned[i] = v.VersionID
+	}
+	if opts.DryRun || len(victims) == 0 {
+		return pruned, nil
+	}
+
+	tx := vs.kv.Begin()
+	for _, v := range victims {
+		deleteVersionKeys(tx, v)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	for _, versionID := range pruned {
+		vs.feed.Publish(changefeed.EntityVersion, changefeed.OpDelete, policyID, versionID)
+	}
+	return pruned, nil
+}
+
+// deleteVersionKeys removes a version's primary record and all of its index entries
+func deleteVersionKeys(tx storage.Txn, v *Version) {
+	tx.Del(storage.EncodeKey(PREFIX_VERSION, []storage.Value{
+		storage.NewBytesValue([]byte(v.PolicyID)),
+		storage.NewBytesValue([]byte(v.VersionID)),
+	}))
+
+	tx.Del(storage.EncodeKey(PREFIX_VERSION_TIME, []storage.Value{
+		storage.NewBytesValue([]byte(v.PolicyID)),
+		storage.NewTimeValue(v.CreatedAt),
+		storage.NewBytesValue([]byte(v.VersionID)),
+	}))
+
+	tx.Del(effectiveKey(v))
+
+	for _, tag := range v.Tags {
+		tx.Del(tagKey(v.PolicyID, tag, v.VersionID))
+	}
+}
+
+// repointLatest moves the latest pointer off removed versions onto the newest remaining one
+func (vs *VersionStore) repointLatest(tx storage.Txn, policyID string, removed map[string]bool) error {
+	latestID, ok, err := vs.latestID(policyID)
+	if err != nil || !ok || !removed[latestID] {
+		return err
+	}
+
+	latestKey := storage.EncodeKey(PREFIX_LATEST_VERSION, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+	})
+
+	timeline, err := vs.timeline(policyID)
+	if err != nil {
+		return err
+	}
+	for i := len(timeline) - 1; i >= 0; i-- {
+		if !removed[timeline[i].versionID] {
+			tx.Set(latestKey, []byte(timeline[i].versionID))
+			return nil
+		}
+	}
+	tx.Del(latestKey)
+	return nil
+}
+
+// latestID returns the version ID of the latest pointer
+func (vs *VersionStore) latestID(policyID string) (string, bool, error) {
+	latestKey := storage.EncodeKey(PREFIX_LATEST_VERSION, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+	})
+
+	val, ok, err := vs.kv.Lookup(latestKey)
+	return string(val), ok, err
+}
+
+// timeline returns a policy's versions ordered by creation time, oldest first
+func (vs *VersionStore) timeline(policyID string) ([]timelineEntry, error) {
+	startKey := storage.EncodeKey(PREFIX_VERSION_TIME, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+	})
+
+	var entries []timelineEntry
+	err := storage.ScanPrefix(vs.kv, startKey, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 3 {
+			return true
+		}
+
+		entries = append(entries, timelineEntry{versionID: string(vals[2].Str), createdAt: vals[1].Time})
+		return true
+	})
+
+	return entries, err
+}
+
+// hasProtectedTag reports whether a version carries any protected tag
+func hasProtectedTag(v *Version, protected map[string]bool) bool {
+	for _, tag := range v.Tags {
+		if protected[tag] {
+			return true
+		}
+	}
+	return false
+}
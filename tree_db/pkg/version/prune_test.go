@@ -0,0 +1,119 @@
+// ABOUTME: Tests for version deletion and pruning
+// ABOUTME: Verifies index cleanup, latest pointer maintenance and prune selection
+
+package version
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func seedVersions(t *testing.T, vs *VersionStore, n int, base time.Time) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		v := &Version{
+			PolicyID:  "policy1",
+			VersionID: fmt.Sprintf("v%d", i),
+			CreatedAt: base.Add(time.Duration(i) * time.Hour),
+			Tags:      []string{"draft"},
+		}
+		if i == 1 {
+			v.Tags = []string{"stable"}
+		}
+		if err := vs.CreateVersion(v); err != nil {
+			t.Fatalf("CreateVersion failed: %v", err)
+		}
+	}
+}
+
+func TestDeleteVersion(t *testing.T) {
+	vs, kv, path := setupTestVersionStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	base := time.Unix(1700000000, 0)
+	seedVersions(t, vs, 3, base)
+
+	// Deleting the latest moves the pointer back
+	if err := vs.DeleteVersion("policy1", "v2"); err != nil {
+		t.Fatalf("DeleteVersion failed: %v", err)
+	}
+	latest, err := vs.GetLatestVersion("policy1")
+	if err != nil || latest.VersionID != "v1" {
+		t.Errorf("Expected latest v1, got %v (%v)", latest, err)
+	}
+
+	if _, err := vs.GetVersion("policy1", "v2"); err == nil {
+		t.Error("Expected v2 removed")
+	}
+	if versions, _ := vs.ListVersions("policy1", 0); len(versions) != 2 {
+		t.Errorf("Expected 2 versions in time index, got %d", len(versions))
+	}
+	if v, err := vs.GetVersionByTag("policy1", "draft"); err != nil || v.VersionID != "v0" {
+		t.Errorf("Expected draft tag to resolve to v0, got %v (%v)", v, err)
+	}
+
+	// Deleting everything removes the pointer
+	for _, id := range []string{"v0", "v1"} {
+		if err := vs.DeleteVersion("policy1", id); err != nil {
+			t.Fatalf("DeleteVersion failed: %v", err)
+		}
+	}
+	if _, err := vs.GetLatestVersion("policy1"); err == nil {
+		t.Error("Expected no latest version after deleting all")
+	}
+
+	if err := vs.DeleteVersion("policy1", "missing"); err == nil {
+		t.Error("Expected error deleting missing version")
+	}
+}
+
+func TestPruneVersions(t *testing.T) {
+	vs, kv, path := setupTestVersionStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	base := time.Unix(1700000000, 0)
+	seedVersions(t, vs, 6, base)
+
+	// Dry run reports without deleting; v1 is protected by its tag
+	pruned, err := vs.PruneVersions("policy1", PruneOptions{KeepLast: 2, ProtectTags: []string{"stable"}, DryRun: true})
+	if err != nil {
+		t.Fatalf("PruneVersions failed: %v", err)
+	}
+	if fmt.Sprint(pruned) != "[v0 v2 v3]" {
+		t.Errorf("Expected [v0 v2 v3], got %v", pruned)
+	}
+	if versions, _ := vs.ListVersions("policy1", 0); len(versions) != 6 {
+		t.Errorf("Expected dry run to keep all versions, got %d", len(versions))
+	}
+
+	// OlderThan narrows the selection
+	pruned, err = vs.PruneVersions("policy1", PruneOptions{KeepLast: 2, OlderThan: base.Add(150 * time.Minute)})
+	if err != nil {
+		t.Fatalf("PruneVersions failed: %v", err)
+	}
+	if fmt.Sprint(pruned) != "[v0 v1 v2]" {
+		t.Errorf("Expected [v0 v1 v2], got %v", pruned)
+	}
+
+	versions, _ := vs.ListVersions("policy1", 0)
+	if len(versions) != 3 || versions[0].VersionID != "v3" {
+		t.Errorf("Expected v3..v5 remaining, got %d versions", len(versions))
+	}
+
+	// The latest version survives even with KeepLast 0
+	if _, err := vs.PruneVersions("policy1", PruneOptions{}); err != nil {
+		t.Fatalf("PruneVersions failed: %v", err)
+	}
+	latest, err := vs.GetLatestVersion("policy1")
+	if err != nil || latest.VersionID != "v5" {
+		t.Errorf("Expected latest v5 kept, got %v (%v)", latest, err)
+	}
+
+	if _, err := vs.PruneVersions("policy1", PruneOptions{KeepLast: -1}); err == nil {
+		t.Error("Expected error for negative KeepLast")
+	}
+}
@@ -0,0 +1,158 @@
+// ABOUTME: Encoding of version records, and the reader of versions stored before records
+// ABOUTME: Versions are tag-length-value records; older ones are positional tuples of values
+
+package version
+
+import (
+	"fmt"
+
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// versionSchema is the schema version of the records encodeVersion writes
+const versionSchema = 1
+
+// Field tags of a version record
+const (
+	fieldPolicyID = iota + 1
+	fieldVersionID
+	fieldDocumentID
+	fieldCreatedAt
+	fieldCreatedBy
+	fieldDescription
+	fieldTags
+	fieldMetadata
+	fieldEffectiveFrom
+	fieldEffectiveTo
+)
+
+// encodeVersion encodes a version as a record
+func encodeVersion(v *Version) []byte {
+	w := storage.NewRecordWriter(versionSchema)
+	w.String(fieldPolicyID, v.PolicyID)
+	w.String(fieldVersionID, v.VersionID)
+	w.String(fieldDocumentID, v.DocumentID)
+	w.Time(fieldCreatedAt, v.CreatedAt)
+	w.String(fieldCreatedBy, v.CreatedBy)
+	w.String(fieldDescription, v.Description)
+	w.Bytes(fieldTags, storage.EncodeStrings(v.Tags))
+	w.Bytes(fieldMetadata, storage.EncodeStringMap(v.Metadata))
+	w.Time(fieldEffectiveFrom, v.EffectiveFrom)
+	w.Time(fieldEffectiveTo, v.EffectiveTo)
+	return w.Encode()
+}
+
+// decodeVersion decodes a stored version, a record or a tuple written before them
+// Tags and metadata that do not decode read as empty.
+func decodeVersion(val []byte) (*Version, error) {
+	if !storage.IsRecord(val) {
+		vals, err := storage.DecodeValues(val)
+		if err != nil {
+			return nil, err
+		}
+		return parseVersionVals(vals)
+	}
+
+	r, err := storage.DecodeRecord(val)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := storage.DecodeStrings(r.Bytes(fieldTags))
+	if err != nil {
+		tags = []string{}
+	}
+	metadata, err := storage.DecodeStringMap(r.Bytes(fieldMetadata))
+	if err != nil {
+		metadata = make(map[string]string)
+	}
+
+	v := &Version{
+		PolicyID:      r.String(fieldPolicyID),
+		VersionID:     r.String(fieldVersionID),
+		DocumentID:    r.String(fieldDocumentID),
+		CreatedAt:     r.Time(fieldCreatedAt),
+		CreatedBy:     r.String(fieldCreatedBy),
+		Description:   r.String(fieldDescription),
+		Tags:          tags,
+		Metadata:      metadata,
+		EffectiveFrom: r.Time(fieldEffectiveFrom),
+	}
+	if !r.Has(fieldEffectiveFrom) {
+		v.EffectiveFrom = v.CreatedAt
+	}
+	if effectiveTo := r.Time(fieldEffectiveTo); !effectiveTo.IsZero() {
+		v.EffectiveTo = effectiveTo
+	}
+	return v, nil
+}
+
+// CheckRecord reports whether a stored version decodes completely: its fields
+// parse and its tags and metadata use up exactly their bytes. Reads fall back
+// to empty tags and metadata instead of failing, so background checks use it
+// to catch records that no longer decode.
+func CheckRecord(val []byte) error {
+	var tags, metadata []byte
+	if storage.IsRecord(val) {
+		r, err := storage.DecodeRecord(val)
+		if err != nil {
+			return err
+		}
+		tags, metadata = r.Bytes(fieldTags), r.Bytes(fieldMetadata)
+	} else {
+		vals, err := storage.DecodeValues(val)
+		if err != nil {
+			return err
+		}
+		if _, err := parseVersionVals(vals); err != nil {
+			return err
+		}
+		tags, metadata = vals[6].Str, vals[7].Str
+	}
+
+	if _, err := storage.DecodeStrings(tags); err != nil {
+		return fmt.Errorf("tags: %w", err)
+	}
+	if _, err := storage.DecodeStringMap(metadata); err != nil {
+		return fmt.Errorf("metadata: %w", err)
+	}
+	return nil
+}
+
+// parseVersionVals reads a version stored as a tuple of values, before records
+func parseVersionVals(vals []storage.Value) (*Version, error) {
+	if len(vals) < 8 {
+		return nil, fmt.Errorf("incomplete version data")
+	}
+
+	tags, err := storage.DecodeStrings(vals[6].Str)
+	if err != nil {
+		tags = []string{}
+	}
+
+	metadata, err := storage.DecodeStringMap(vals[7].Str)
+	if err != nil {
+		metadata = make(map[string]string)
+	}
+
+	v := &Version{
+		PolicyID:      string(vals[0].Str),
+		VersionID:     string(vals[1].Str),
+		DocumentID:    string(vals[2].Str),
+		CreatedAt:     vals[3].Time,
+		CreatedBy:     string(vals[4].Str),
+		Description:   string(vals[5].Str),
+		Tags:          tags,
+		Metadata:      metadata,
+		EffectiveFrom: vals[3].Time,
+	}
+
+	// Versions written before effective dates default to their creation time
+	if len(vals) > 9 {
+		v.EffectiveFrom = vals[8].Time
+		if !vals[9].Time.IsZero() {
+			v.EffectiveTo = vals[9].Time
+		}
+	}
+
+	return v, nil
+}
@@ -4,9 +4,12 @@
 package version
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/nainya/treestore/pkg/changefeed"
 	"github.com/nainya/treestore/pkg/storage"
 )
 
@@ -16,41 +19,51 @@ const (
 	PREFIX_VERSION_TIME   = uint32(6100) // Index by (policyID, createdAt, versionID)
 	PREFIX_VERSION_TAG    = uint32(6200) // Index by (policyID, tag, versionID)
 	PREFIX_LATEST_VERSION = uint32(6300) // Track latest version per policy
+	PREFIX_VERSION_EFFECT = uint32(6400) // Index by (policyID, effectiveFrom, versionID)
 )
 
 // VersionStore manages document versions
 type VersionStore struct {
-	kv *storage.KV
+	kv   storage.Engine
+	mu   *sync.Mutex      // Serializes writes so overlap and tag checks cannot interleave; shared by views
+	feed *changefeed.Feed // Optional; nil publishes nothing
 }
 
 // NewVersionStore creates a new version store
-func NewVersionStore(kv *storage.KV) *VersionStore {
-	return &VersionStore{kv: kv}
+func NewVersionStore(kv storage.Engine) *VersionStore {
+	return &VersionStore{kv: kv, mu: &sync.Mutex{}}
+}
+
+// WithContext returns a view of the store whose reads stop scanning once ctx
+// is done, failing with ctx.Err()
+func (vs *VersionStore) WithContext(ctx context.Context) *VersionStore {
+	return &VersionStore{kv: storage.WithContext(ctx, vs.kv), mu: vs.mu, feed: vs.feed}
+}
+
+// SetChangeFeed publishes committed writes to feed; call before the store is shared
+func (vs *VersionStore) SetChangeFeed(feed *changefeed.Feed) {
+	vs.feed = feed
 }
 
 // CreateVersion stores a new version
+// An empty EffectiveFrom is set to CreatedAt. Versions whose effective periods
+// overlap an existing version of the policy are rejected with ErrEffectiveOverlap.
 func (vs *VersionStore) CreateVersion(v *Version) error {
-	tx := vs.kv.Begin()
+	if v.EffectiveFrom.IsZero() {
+		v.EffectiveFrom = v.CreatedAt
+	}
 
-	// Primary key: (policyID, versionID)
-	key := storage.EncodeKey(PREFIX_VERSION, []storage.Value{
-		storage.NewBytesValue([]byte(v.PolicyID)),
-		storage.NewBytesValue([]byte(v.VersionID)),
-	})
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
 
-	// Encode version data
-	val := storage.EncodeValues([]storage.Value{
-		storage.NewBytesValue([]byte(v.PolicyID)),
-		storage.NewBytesValue([]byte(v.VersionID)),
-		storage.NewBytesValue([]byte(v.DocumentID)),
-		storage.NewTimeValue(v.CreatedAt),
-		storage.NewBytesValue([]byte(v.CreatedBy)),
-		storage.NewBytesValue([]byte(v.Description)),
-		storage.NewBytesValue(encodeStringArray(v.Tags)),
-		storage.NewBytesValue(encodeMetadata(v.Metadata)),
-	})
+	if err := vs.checkEffectiveOverlap(v); err != nil {
+		return err
+	}
 
-	tx.Set(key, val)
+	tx := vs.kv.Begin()
+
+	// Primary key: (policyID, versionID)
+	putVersion(tx, v)
 
 	// Time-based index: (policyID, createdAt, versionID)
 	timeKey := storage.EncodeKey(PREFIX_VERSION_TIME, []storage.Value{
@@ -60,14 +73,12 @@ func (vs *VersionStore) CreateVersion(v *Version) error {
 	})
 	tx.Set(timeKey, []byte{})
 
+	// Effective date index: (policyID, effectiveFrom, versionID)
+	tx.Set(effectiveKey(v), []byte{})
+
 	// Tag indexes: (policyID, tag, versionID)
 	for _, tag := range v.Tags {
-		tagKey := storage.EncodeKey(PREFIX_VERSION_TAG, []storage.Value{
-			storage.NewBytesValue([]byte(v.PolicyID)),
-			storage.NewBytesValue([]byte(tag)),
-			storage.NewBytesValue([]byte(v.VersionID)),
-		})
-		tx.Set(tagKey, []byte{})
+		tx.Set(tagKey(v.PolicyID, tag, v.VersionID), []byte{})
 	}
 
 	// Update latest version pointer
@@ -76,7 +87,12 @@ func (vs *VersionStore) CreateVersion(v *Version) error {
 	})
 	tx.Set(latestKey, []byte(v.VersionID))
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	vs.feed.Publish(changefeed.EntityVersion, changefeed.OpPut, v.PolicyID, v.VersionID)
+	return nil
 }
 
 // GetVersion retrieves a specific version
@@ -86,17 +102,15 @@ func (vs *VersionStore) GetVersion(policyID, versionID string) (*Version, error)
 		storage.NewBytesValue([]byte(versionID)),
 	})
 
-	val, ok := vs.kv.Get(key)
-	if !ok {
-		return nil, fmt.Errorf("version not found: %s/%s", policyID, versionID)
-	}
-
-	vals, err := storage.DecodeValues(val)
+	val, ok, err := vs.kv.Lookup(key)
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		return nil, fmt.Errorf("version not found: %s/%s", policyID, versionID)
+	}
 
-	return parseVersionVals(vals)
+	return decodeVersion(val)
 }
 
 // GetLatestVersion returns the most recent version for a policy
@@ -105,7 +119,10 @@ func (vs *VersionStore) GetLatestVersion(policyID string) (*Version, error) {
 		storage.NewBytesValue([]byte(policyID)),
 	})
 
-	versionIDBytes, ok := vs.kv.Get(latestKey)
+	versionIDBytes, ok, err := vs.kv.Lookup(latestKey)
+	if err != nil {
+		return nil, err
+	}
 	if !ok {
 		return nil, fmt.Errorf("no versions found for policy: %s", policyID)
 	}
@@ -114,52 +131,40 @@ func (vs *VersionStore) GetLatestVersion(policyID string) (*Version, error) {
 	return vs.GetVersion(policyID, versionID)
 }
 
-// GetVersionAsOf returns the version that was current at a specific time
+// GetVersionAsOf returns the version that was in effect at a specific time
+// The effective date index is walked backwards from asOfTime, so only one entry
+// is read. Times in a gap after a version's EffectiveTo find no version.
 func (vs *VersionStore) GetVersionAsOf(policyID string, asOfTime time.Time) (*Version, error) {
-	// Scan time index to find the latest version before asOfTime
-	startKey := storage.EncodeKey(PREFIX_VERSION_TIME, []storage.Value{
-		storage.NewBytesValue([]byte(policyID)),
-	})
-
-	var latestVersion *Version
-	var latestTime time.Time
+	v, err := vs.versionAsOf(policyID, asOfTime)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("no version found for %s as of %s", policyID, asOfTime)
+	}
 
-	vs.kv.Scan(startKey, func(key, val []byte) bool {
-		vals, err := storage.ExtractValues(key)
-		if err != nil || len(vals) < 3 {
-			return true
-		}
+	return v, nil
+}
 
-		// Check if still in same policy
-		if string(vals[0].Str) != policyID {
-			return false
+// GetVersionsAsOf resolves the current version of many policies at one point in time
+// Policies without a version at asOfTime are omitted from the result.
+func (vs *VersionStore) GetVersionsAsOf(policyIDs []string, asOfTime time.Time) (map[string]*Version, error) {
+	result := make(map[string]*Version, len(policyIDs))
+	for _, policyID := range policyIDs {
+		if _, seen := result[policyID]; seen {
+			continue
 		}
 
-		createdAt := vals[1].Time
-		versionID := string(vals[2].Str)
-
-		// Only consider versions created before or at asOfTime
-		if createdAt.After(asOfTime) {
-			return true
+		v, err := vs.versionAsOf(policyID, asOfTime)
+		if err != nil {
+			return nil, err
 		}
-
-		// Track the latest version before asOfTime
-		if latestVersion == nil || createdAt.After(latestTime) {
-			version, err := vs.GetVersion(policyID, versionID)
-			if err == nil {
-				latestVersion = version
-				latestTime = createdAt
-			}
+		if v != nil {
+			result[policyID] = v
 		}
-
-		return true
-	})
-
-	if latestVersion == nil {
-		return nil, fmt.Errorf("no version found for %s as of %s", policyID, asOfTime)
 	}
 
-	return latestVersion, nil
+	return result, nil
 }
 
 // GetVersionByTag returns the version with a specific tag
@@ -172,21 +177,19 @@ func (vs *VersionStore) GetVersionByTag(policyID, tag string) (*Version, error)
 	var versionID string
 	found := false
 
-	vs.kv.Scan(startKey, func(key, val []byte) bool {
+	err := storage.ScanPrefix(vs.kv, startKey, func(key, val []byte) bool {
 		vals, err := storage.ExtractValues(key)
 		if err != nil || len(vals) < 3 {
 			return true
 		}
 
-		// Check if still in same policy/tag
-		if string(vals[0].Str) != policyID || string(vals[1].Str) != tag {
-			return false
-		}
-
 		versionID = string(vals[2].Str)
 		found = true
 		return false // Found it, stop scanning
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	if !found {
 		return nil, fmt.Errorf("no version found with tag %s for policy %s", tag, policyID)
@@ -195,6 +198,16 @@ func (vs *VersionStore) GetVersionByTag(policyID, tag string) (*Version, error)
 	return vs.GetVersion(policyID, versionID)
 }
 
+// Count returns the number of versions stored across all policies
+func (vs *VersionStore) Count() (int64, error) {
+	var count int64
+	err := storage.ScanPrefix(vs.kv, storage.EncodeKey(PREFIX_VERSION, nil), func(key, val []byte) bool {
+		count++
+		return true
+	})
+	return count, err
+}
+
 // ListVersions returns all versions for a policy, ordered by creation time
 func (vs *VersionStore) ListVersions(policyID string, limit int) ([]*Version, error) {
 	startKey := storage.EncodeKey(PREFIX_VERSION_TIME, []storage.Value{
@@ -202,9 +215,10 @@ func (vs *VersionStore) ListVersions(policyID string, limit int) ([]*Version, er
 	})
 
 	var versions []*Version
+	var versionErr error
 	count := 0
 
-	vs.kv.Scan(startKey, func(key, val []byte) bool {
+	err := storage.ScanPrefix(vs.kv, startKey, func(key, val []byte) bool {
 		if limit > 0 && count >= limit {
 			return false
 		}
@@ -214,13 +228,12 @@ func (vs *VersionStore) ListVersions(policyID string, limit int) ([]*Version, er
 			return true
 		}
 
-		// Check if still in same policy
-		if string(vals[0].Str) != policyID {
-			return false
-		}
-
 		versionID := string(vals[2].Str)
 		version, err := vs.GetVersion(policyID, versionID)
+		if storage.IsCorruption(err) {
+			versionErr = err
+			return false
+		}
 		if err == nil {
 			versions = append(versions, version)
 			count++
@@ -228,6 +241,12 @@ func (vs *VersionStore) ListVersions(policyID string, limit int) ([]*Version, er
 
 		return true
 	})
+	if err == nil {
+		err = versionErr
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	return versions, nil
 }
@@ -247,129 +266,21 @@ func (vs *VersionStore) GetVersionHistory(policyID string) (*VersionHistory, err
 
 // Helper functions
 
-func parseVersionVals(vals []storage.Value) (*Version, error) {
-	if len(vals) < 8 {
-		return nil, fmt.Errorf("incomplete version data")
-	}
-
-	tags, err := decodeStringArray(vals[6].Str)
-	if err != nil {
-		tags = []string{}
-	}
-
-	metadata, err := decodeMetadata(vals[7].Str)
-	if err != nil {
-		metadata = make(map[string]string)
-	}
-
-	return &Version{
-		PolicyID:    string(vals[0].Str),
-		VersionID:   string(vals[1].Str),
-		DocumentID:  string(vals[2].Str),
-		CreatedAt:   vals[3].Time,
-		CreatedBy:   string(vals[4].Str),
-		Description: string(vals[5].Str),
-		Tags:        tags,
-		Metadata:    metadata,
-	}, nil
-}
-
-func encodeStringArray(arr []string) []byte {
-	if len(arr) == 0 {
-		return []byte{}
-	}
-
-	result := []byte{}
-	result = append(result, byte(len(arr)))
-	for _, s := range arr {
-		result = append(result, byte(len(s)))
-		result = append(result, []byte(s)...)
-	}
-	return result
-}
-
-func decodeStringArray(data []byte) ([]string, error) {
-	if len(data) == 0 {
-		return []string{}, nil
-	}
-
-	pos := 0
-	count := int(data[pos])
-	pos++
-
-	result := make([]string, 0, count)
-	for i := 0; i < count; i++ {
-		if pos >= len(data) {
-			return nil, fmt.Errorf("incomplete string array")
-		}
-
-		length := int(data[pos])
-		pos++
-
-		if pos+length > len(data) {
-			return nil, fmt.Errorf("incomplete string at pos %d", pos)
-		}
-
-		result = append(result, string(data[pos:pos+length]))
-		pos += length
-	}
-
-	return result, nil
-}
-
-func encodeMetadata(m map[string]string) []byte {
-	if len(m) == 0 {
-		return []byte{}
-	}
+// putVersion writes a version's primary record
+func putVersion(tx storage.Txn, v *Version) {
+	key := storage.EncodeKey(PREFIX_VERSION, []storage.Value{
+		storage.NewBytesValue([]byte(v.PolicyID)),
+		storage.NewBytesValue([]byte(v.VersionID)),
+	})
 
-	result := []byte{byte(len(m))}
-	for k, v := range m {
-		result = append(result, byte(len(k)))
-		result = append(result, []byte(k)...)
-		result = append(result, byte(len(v)))
-		result = append(result, []byte(v)...)
-	}
-	return result
+	tx.Set(key, encodeVersion(v))
 }
 
-func decodeMetadata(data []byte) (map[string]string, error) {
-	if len(data) == 0 {
-		return make(map[string]string), nil
-	}
-
-	pos := 0
-	count := int(data[pos])
-	pos++
-
-	result := make(map[string]string)
-	for i := 0; i < count; i++ {
-		if pos >= len(data) {
-			return nil, fmt.Errorf("incomplete metadata")
-		}
-
-		// Read key
-		keyLen := int(data[pos])
-		pos++
-		if pos+keyLen > len(data) {
-			return nil, fmt.Errorf("incomplete key at pos %d", pos)
-		}
-		key := string(data[pos : pos+keyLen])
-		pos += keyLen
-
-		// Read value
-		if pos >= len(data) {
-			return nil, fmt.Errorf("incomplete value for key %s", key)
-		}
-		valLen := int(data[pos])
-		pos++
-		if pos+valLen > len(data) {
-			return nil, fmt.Errorf("incomplete value at pos %d", pos)
-		}
-		val := string(data[pos : pos+valLen])
-		pos += valLen
-
-		result[key] = val
-	}
-
-	return result, nil
+// tagKey builds the tag index key of a version
+func tagKey(policyID, tag, versionID string) []byte {
+	return storage.EncodeKey(PREFIX_VERSION_TAG, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(tag)),
+		storage.NewBytesValue([]byte(versionID)),
+	})
 }
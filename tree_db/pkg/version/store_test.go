@@ -4,7 +4,10 @@
 package version
 
 import (
+	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,7 +15,7 @@ import (
 )
 
 func setupTestVersionStore(t *testing.T) (*VersionStore, *storage.KV, string) {
-	path := "/tmp/test_versionstore_" + t.Name() + ".db"
+	path := storage.MemoryPath
 	kv := &storage.KV{Path: path}
 	if err := kv.Open(); err != nil {
 		t.Fatalf("Failed to open: %v", err)
@@ -361,3 +364,160 @@ func TestVersionNotFound(t *testing.T) {
 		t.Error("Expected error for non-existent tag")
 	}
 }
+
+func TestGetVersionsAsOf(t *testing.T) {
+	vs, kv, path := setupTestVersionStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	base := time.Unix(1700000000, 0)
+	for i, policyID := range []string{"policy1", "policy10", "policy2"} {
+		for j := 0; j < 3; j++ {
+			v := &Version{
+				PolicyID:  policyID,
+				VersionID: fmt.Sprintf("v%d", j),
+				CreatedAt: base.Add(time.Duration(i+2*j) * time.Hour),
+			}
+			if err := vs.CreateVersion(v); err != nil {
+				t.Fatalf("CreateVersion failed: %v", err)
+			}
+		}
+	}
+
+	// policy1 versions at +0h,+2h,+4h; policy10 at +1h,+3h,+5h; policy2 at +2h,+4h,+6h
+	asOf := base.Add(3 * time.Hour)
+	versions, err := vs.GetVersionsAsOf([]string{"policy1", "policy10", "policy2", "missing"}, asOf)
+	if err != nil {
+		t.Fatalf("GetVersionsAsOf failed: %v", err)
+	}
+
+	expected := map[string]string{"policy1": "v1", "policy10": "v1", "policy2": "v0"}
+	if len(versions) != len(expected) {
+		t.Fatalf("Expected %d policies, got %d", len(expected), len(versions))
+	}
+	for policyID, versionID := range expected {
+		if v := versions[policyID]; v == nil || v.VersionID != versionID {
+			t.Errorf("Expected %s as of +3h to be %s, got %v", policyID, versionID, v)
+		}
+	}
+
+	// Exact creation time is inclusive; earlier than any version finds nothing
+	if v, err := vs.GetVersionAsOf("policy2", base.Add(2*time.Hour)); err != nil || v.VersionID != "v0" {
+		t.Errorf("Expected v0 at its creation time, got %v (%v)", v, err)
+	}
+	if _, err := vs.GetVersionAsOf("policy2", base.Add(time.Hour)); err == nil {
+		t.Error("Expected no version before the first one")
+	}
+}
+
+func TestCheckRecord(t *testing.T) {
+	vs, kv, _ := setupTestVersionStore(t)
+	defer kv.Close()
+
+	err := vs.CreateVersion(&Version{
+		PolicyID: "policy1", VersionID: "v1", DocumentID: "doc1", CreatedAt: time.Now(),
+		Tags: []string{"latest"}, Metadata: map[string]string{"source": "cms"},
+	})
+	if err != nil {
+		t.Fatalf("CreateVersion failed: %v", err)
+	}
+	var val []byte
+	kv.Scan(storage.EncodeKey(PREFIX_VERSION, nil), func(key, v []byte) bool {
+		val = v
+		return false
+	})
+	if err := CheckRecord(val); err != nil {
+		t.Fatalf("Expected the stored version to check out, got %v", err)
+	}
+
+	// Tags and metadata that do not use up their bytes fail, though reads accept them
+	tags := append(storage.EncodeStrings([]string{"latest"}), 'x')
+	legacy := legacyVersion("policy1", "v1", tags)
+	w := storage.NewRecordWriter(versionSchema)
+	w.Bytes(fieldTags, tags)
+	for name, val := range map[string][]byte{"record": w.Encode(), "tuple": legacy} {
+		if err := CheckRecord(val); err == nil {
+			t.Errorf("Expected trailing bytes in the tags of a %s to fail", name)
+		}
+	}
+	if err := CheckRecord([]byte{storage.TYPE_BYTES, 'a'}); err == nil {
+		t.Error("Expected an undecodable tuple to fail")
+	}
+	if err := CheckRecord([]byte{storage.RECORD_MARKER, versionSchema, 1}); err == nil {
+		t.Error("Expected an undecodable record to fail")
+	}
+}
+
+// legacyVersion encodes a version as a tuple, as stored before records
+func legacyVersion(policyID, versionID string, tags []byte) []byte {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return storage.EncodeValues([]storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(versionID)),
+		storage.NewBytesValue([]byte("doc1")),
+		storage.NewTimeValue(created),
+		storage.NewBytesValue([]byte("ingest")),
+		storage.NewBytesValue([]byte("Imported")),
+		storage.NewBytesValue(tags),
+		storage.NewBytesValue(nil),
+		storage.NewTimeValue(created),
+		storage.NewTimeValue(time.Time{}),
+	})
+}
+
+func TestLegacyVersionRecords(t *testing.T) {
+	vs, kv, _ := setupTestVersionStore(t)
+	defer kv.Close()
+
+	key := storage.EncodeKey(PREFIX_VERSION, []storage.Value{
+		storage.NewBytesValue([]byte("policy1")),
+		storage.NewBytesValue([]byte("v1")),
+	})
+	kv.Set(key, legacyVersion("policy1", "v1", []byte{1, 6, 'l', 'a', 't', 'e', 's', 't'}))
+
+	v, err := vs.GetVersion("policy1", "v1")
+	if err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if v.DocumentID != "doc1" || len(v.Tags) != 1 || v.Tags[0] != "latest" || !v.EffectiveTo.IsZero() {
+		t.Errorf("Expected the tuple read in full, got %+v", v)
+	}
+
+	// Rewriting it stores a record
+	v.Description = "Reimported"
+	if err := vs.CreateVersion(v); err != nil {
+		t.Fatalf("CreateVersion failed: %v", err)
+	}
+	val, _ := kv.Get(key)
+	if !storage.IsRecord(val) {
+		t.Error("Expected the version rewritten as a record")
+	}
+	if got, _ := vs.GetVersion("policy1", "v1"); got.Description != "Reimported" || got.Tags[0] != "latest" {
+		t.Errorf("Expected the rewritten version, got %+v", got)
+	}
+}
+
+func TestLongTagsAndMetadata(t *testing.T) {
+	vs, kv, _ := setupTestVersionStore(t)
+	defer kv.Close()
+
+	// Lengths and counts past 255 used to wrap around a single byte
+	long := strings.Repeat("t", 300)
+	metadata := map[string]string{"note": long}
+	for i := 0; i < 300; i++ {
+		metadata[fmt.Sprintf("k%03d", i)] = ""
+	}
+	v := &Version{PolicyID: "policy1", VersionID: "v1", CreatedAt: time.Now(), Tags: []string{long, "latest"}, Metadata: metadata}
+	if err := vs.CreateVersion(v); err != nil {
+		t.Fatalf("CreateVersion failed: %v", err)
+	}
+
+	got, err := vs.GetVersion("policy1", "v1")
+	if err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.Tags, v.Tags) || !reflect.DeepEqual(got.Metadata, metadata) {
+		t.Errorf("Expected %d tags and %d metadata entries back intact, got %d and %d",
+			len(v.Tags), len(metadata), len(got.Tags), len(got.Metadata))
+	}
+}
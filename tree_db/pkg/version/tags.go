@@ -0,0 +1,133 @@
+// ABOUTME: Version tag mutation with tag index maintenance
+// ABOUTME: Supports unique tags such as "approved" that move between versions
+
+package version
+
+import (
+	"github.com/nainya/treestore/pkg/changefeed"
+	"github.com/nainya/treestore/pkg/storage"
+)
+
+// TagVersion adds a tag to a version
+// With unique set, the tag is removed from every other version of the policy in
+// the same transaction, so it moves to this version. Re-tagging is a no-op
+// apart from enforcing uniqueness.
+func (vs *VersionStore) TagVersion(policyID, versionID, tag string, unique bool) error {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	v, err := vs.GetVersion(policyID, versionID)
+	if err != nil {
+		return err
+	}
+
+	var others []*Version
+	if unique {
+		otherIDs, err := vs.versionsWithTag(policyID, tag)
+		if err != nil {
+			return err
+		}
+		for _, otherID := range otherIDs {
+			if otherID == versionID {
+				continue
+			}
+			other, err := vs.GetVersion(policyID, otherID)
+			if err != nil {
+				return err
+			}
+			others = append(others, other)
+		}
+	}
+
+	tx := vs.kv.Begin()
+
+	for _, other := range others {
+		other.Tags = removeTag(other.Tags, tag)
+		putVersion(tx, other)
+		tx.Del(tagKey(policyID, tag, other.VersionID))
+	}
+
+	added := !containsTag(v.Tags, tag)
+	if added {
+		v.Tags = append(v.Tags, tag)
+		putVersion(tx, v)
+		tx.Set(tagKey(policyID, tag, versionID), []byte{})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, other := range others {
+		vs.feed.Publish(changefeed.EntityVersion, changefeed.OpPut, policyID, other.VersionID)
+	}
+	if added {
+		vs.feed.Publish(changefeed.EntityVersion, changefeed.OpPut, policyID, versionID)
+	}
+	return nil
+}
+
+// UntagVersion removes a tag from a version; removing a missing tag is a no-op
+func (vs *VersionStore) UntagVersion(policyID, versionID, tag string) error {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	v, err := vs.GetVersion(policyID, versionID)
+	if err != nil {
+		return err
+	}
+	if !containsTag(v.Tags, tag) {
+		return nil
+	}
+
+	tx := vs.kv.Begin()
+	v.Tags = removeTag(v.Tags, tag)
+	putVersion(tx, v)
+	tx.Del(tagKey(policyID, tag, versionID))
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	vs.feed.Publish(changefeed.EntityVersion, changefeed.OpPut, policyID, versionID)
+	return nil
+}
+
+// versionsWithTag lists the versions of a policy carrying a tag
+func (vs *VersionStore) versionsWithTag(policyID, tag string) ([]string, error) {
+	startKey := storage.EncodeKey(PREFIX_VERSION_TAG, []storage.Value{
+		storage.NewBytesValue([]byte(policyID)),
+		storage.NewBytesValue([]byte(tag)),
+	})
+
+	var versionIDs []string
+	err := storage.ScanPrefix(vs.kv, startKey, func(key, val []byte) bool {
+		vals, err := storage.ExtractValues(key)
+		if err != nil || len(vals) < 3 {
+			return true
+		}
+
+		versionIDs = append(versionIDs, string(vals[2].Str))
+		return true
+	})
+
+	return versionIDs, err
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func removeTag(tags []string, tag string) []string {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if t != tag {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+// ABOUTME: Tests for version tag mutation
+// ABOUTME: Verifies tag index maintenance and unique tag moves
+
+package version
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestTagAndUntagVersion(t *testing.T) {
+	vs, kv, path := setupTestVersionStore(t)
+	defer os.Remove(path)
+	defer kv.Close()
+
+	base := time.Unix(1700000000, 0)
+	seedVersions(t, vs, 3, base)
+
+	if err := vs.TagVersion("policy1", "v0", "approved", true); err != nil {
+		t.Fatalf("TagVersion failed: %v", err)
+	}
+	if v, err := vs.GetVersionByTag("policy1", "approved"); err != nil || v.VersionID != "v0" {
+		t.Errorf("Expected approved on v0, got %v (%v)", v, err)
+	}
+
+	// Unique tags move to the new version
+	if err := vs.TagVersion("policy1", "v2", "approved", true); err != nil {
+		t.Fatalf("TagVersion failed: %v", err)
+	}
+	if v, err := vs.GetVersionByTag("policy1", "approved"); err != nil || v.VersionID != "v2" {
+		t.Errorf("Expected approved moved to v2, got %v (%v)", v, err)
+	}
+	if v0, _ := vs.GetVersion("policy1", "v0"); containsTag(v0.Tags, "approved") {
+		t.Errorf("Expected approved removed from v0 record, got %v", v0.Tags)
+	}
+
+	// Non-unique tags accumulate
+	if err := vs.TagVersion("policy1", "v1", "reviewed", false); err != nil {
+		t.Fatalf("TagVersion failed: %v", err)
+	}
+	if err := vs.TagVersion("policy1", "v2", "reviewed", false); err != nil {
+		t.Fatalf("TagVersion failed: %v", err)
+	}
+	if ids, _ := vs.versionsWithTag("policy1", "reviewed"); len(ids) != 2 {
+		t.Errorf("Expected reviewed on 2 versions, got %v", ids)
+	}
+
+	if err := vs.UntagVersion("policy1", "v2", "approved"); err != nil {
+		t.Fatalf("UntagVersion failed: %v", err)
+	}
+	if _, err := vs.GetVersionByTag("policy1", "approved"); err == nil {
+		t.Error("Expected no approved version after untag")
+	}
+	if err := vs.UntagVersion("policy1", "v2", "approved"); err != nil {
+		t.Errorf("Expected untagging a missing tag to be a no-op: %v", err)
+	}
+
+	if err := vs.TagVersion("policy1", "missing", "approved", false); err == nil {
+		t.Error("Expected error tagging missing version")
+	}
+}
@@ -15,6 +15,21 @@ type Version struct {
 	Description string    // Version description/changelog
 	Tags        []string  // Version tags (e.g., "latest", "stable", "draft")
 	Metadata    map[string]string
+
+	// Effective period, distinct from CreatedAt: a version entered today may take
+	// effect next month. EffectiveFrom defaults to CreatedAt. A zero EffectiveTo
+	// means the version stays effective until a later version takes effect.
+	EffectiveFrom time.Time
+	EffectiveTo   time.Time
+}
+
+// EffectiveAt reports whether t falls within the version's own effective period
+// It does not account for supersession by later versions.
+func (v *Version) EffectiveAt(t time.Time) bool {
+	if t.Before(v.EffectiveFrom) {
+		return false
+	}
+	return v.EffectiveTo.IsZero() || t.Before(v.EffectiveTo)
 }
 
 // VersionQuery options for temporal queries
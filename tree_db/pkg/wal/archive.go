@@ -0,0 +1,248 @@
+package wal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveSuffix ends the name of every archived log file
+const ArchiveSuffix = ".walarchive"
+
+// Archive says what happens to log files retired by rotation or a checkpoint,
+// which are otherwise deleted. Each configured step runs in turn: Hook, then
+// Command, then the move into Dir. A file whose steps fail is kept in place
+// and retried at the next retirement, so nothing is lost while a destination
+// is unavailable. The steps run while writes wait, so Hook and Command should
+// be quick or hand the file off.
+type Archive struct {
+	// Dir receives retired files, named by their first LSN (see ArchiveName)
+	Dir string
+
+	// Command is run through sh before the file is moved or deleted; %p is
+	// replaced by the file's path and %f by its archive name
+	Command string
+
+	// Hook is called with the file's path before Command
+	Hook func(path string) error
+
+	// MaxAge and MaxBytes limit what Dir keeps; the oldest files are pruned
+	// first after each archive. Zero keeps files forever.
+	MaxAge   time.Duration
+	MaxBytes int64
+}
+
+// ArchiveStats counts the files retired through an Archive
+type ArchiveStats struct {
+	Archived  uint64
+	Failures  uint64
+	Pruned    uint64
+	LastError error
+}
+
+// ArchiveName names an archived log file of a database after the LSN of its
+// first entry, so names sort in log order across WAL restarts
+func ArchiveName(walPath string, firstLSN uint64) string {
+	return fmt.Sprintf("%s.%020d%s", filepath.Base(walPath), firstLSN, ArchiveSuffix)
+}
+
+// ArchivedFiles returns the archived log files of a database in dir, oldest first
+func ArchivedFiles(dir, walPath string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.Base(walPath) + "."
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ArchiveSuffix) {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// validate rejects an archive directory holding the log itself, where
+// archived files would be taken for live ones
+func (a *Archive) validate(walPath string) error {
+	if a == nil || a.Dir == "" {
+		return nil
+	}
+	dir, err := filepath.Abs(a.Dir)
+	if err != nil {
+		return err
+	}
+	logDir, err := filepath.Abs(filepath.Dir(walPath))
+	if err != nil {
+		return err
+	}
+	if dir == logDir {
+		return fmt.Errorf("wal: archive directory %s holds the log itself", a.Dir)
+	}
+	return nil
+}
+
+// ArchiveStats returns what the archive has done since Open
+func (w *WAL) ArchiveStats() ArchiveStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.archiveStats
+}
+
+// retireNoLock archives or deletes a log file no longer needed (caller must hold mu)
+func (w *WAL) retireNoLock(path string) error {
+	a := w.Archive
+	if a == nil {
+		return os.Remove(path)
+	}
+
+	if err := w.archiveFile(a, path); err != nil {
+		w.archiveStats.Failures++
+		w.archiveStats.LastError = err
+		return err
+	}
+	w.archiveStats.Archived++
+	w.archiveStats.LastError = nil
+
+	if a.Dir != "" {
+		pruned, err := pruneArchive(a, w.Path)
+		w.archiveStats.Pruned += uint64(pruned)
+		if err != nil {
+			w.archiveStats.LastError = err
+		}
+	}
+	return nil
+}
+
+// archiveFile runs the steps of a for one file, removing it once they succeed
+func (w *WAL) archiveFile(a *Archive, path string) error {
+	first, err := firstLSN(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	if first == 0 {
+		return os.Remove(path) // No entries to keep
+	}
+	name := ArchiveName(w.Path, first)
+
+	if a.Hook != nil {
+		if err := a.Hook(path); err != nil {
+			return fmt.Errorf("archive hook for %s: %w", path, err)
+		}
+	}
+	if a.Command != "" {
+		cmd := strings.NewReplacer("%p", path, "%f", name).Replace(a.Command)
+		if out, err := exec.Command("sh", "-c", cmd).CombinedOutput(); err != nil {
+			return fmt.Errorf("archive command for %s: %w: %s", path, err, strings.TrimSpace(string(out)))
+		}
+	}
+	if a.Dir == "" {
+		return os.Remove(path)
+	}
+	if err := os.MkdirAll(a.Dir, 0755); err != nil {
+		return err
+	}
+	return moveFile(path, filepath.Join(a.Dir, name))
+}
+
+// firstLSN returns the LSN of the first entry of a log file, or 0 if it has none
+func firstLSN(path string) (uint64, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer fd.Close()
+
+	entry, err := (&WAL{}).readEntry(fd)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return entry.LSN, nil
+}
+
+// moveFile renames src to dst, copying when they are on different file systems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// pruneArchive deletes the oldest archived files beyond a's age and size
+// limits, returning how many it deleted
+func pruneArchive(a *Archive, walPath string) (int, error) {
+	if a.MaxAge <= 0 && a.MaxBytes <= 0 {
+		return 0, nil
+	}
+	files, err := ArchivedFiles(a.Dir, walPath)
+	if err != nil {
+		return 0, err
+	}
+
+	infos := make([]os.FileInfo, len(files))
+	var total int64
+	for i, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return 0, err
+		}
+		infos[i] = info
+		total += info.Size()
+	}
+
+	pruned := 0
+	var errs []error
+	for i, f := range files {
+		expired := a.MaxAge > 0 && time.Since(infos[i].ModTime()) > a.MaxAge
+		oversize := a.MaxBytes > 0 && total > a.MaxBytes
+		if !expired && !oversize {
+			break
+		}
+		if err := os.Remove(f); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		total -= infos[i].Size()
+		pruned++
+	}
+	return pruned, errors.Join(errs...)
+}
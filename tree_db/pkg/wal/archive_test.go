@@ -0,0 +1,186 @@
+package wal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rotateTimes writes two entries to each of n log files
+func rotateTimes(t *testing.T, w *WAL, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		writeTestEntries(t, w, 2)
+		w.mu.Lock()
+		err := w.rotateNoLock()
+		w.mu.Unlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestArchiveDir(t *testing.T) {
+	dir := t.TempDir()
+	archiveDir := filepath.Join(dir, "archive")
+	var hooked []string
+	w := &WAL{
+		Path: filepath.Join(dir, "test.wal"),
+		Archive: &Archive{
+			Dir:  archiveDir,
+			Hook: func(path string) error { hooked = append(hooked, filepath.Base(path)); return nil },
+		},
+	}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	rotateTimes(t, w, MaxLogFiles+2)
+
+	files, err := ArchivedFiles(archiveDir, w.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{ArchiveName(w.Path, 1), ArchiveName(w.Path, 3), ArchiveName(w.Path, 5)}
+	if len(files) != len(want) {
+		t.Fatalf("archived %v, want %v", files, want)
+	}
+	for i, f := range files {
+		if filepath.Base(f) != want[i] {
+			t.Errorf("archive %d is %s, want %s", i, filepath.Base(f), want[i])
+		}
+	}
+	if len(hooked) != 3 || hooked[0] != "test.wal.000" {
+		t.Errorf("hook called for %v", hooked)
+	}
+	if _, err := os.Stat(w.logFilePath(0)); !os.IsNotExist(err) {
+		t.Error("archived file left in the log directory")
+	}
+	if stats := w.ArchiveStats(); stats.Archived != 3 || stats.Failures != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// Archived files still hold their entries
+	entries, err := ReadAll(files[:1])
+	if err != nil || len(entries) != 2 || entries[0].LSN != 1 {
+		t.Errorf("expected LSNs 1 and 2 in the first archive, got %v (%v)", entries, err)
+	}
+}
+
+func TestArchiveFailureKeepsFile(t *testing.T) {
+	dir := t.TempDir()
+	fail := true
+	w := &WAL{
+		Path: filepath.Join(dir, "test.wal"),
+		Archive: &Archive{Hook: func(string) error {
+			if fail {
+				return errors.New("destination unavailable")
+			}
+			return nil
+		}},
+	}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	rotateTimes(t, w, MaxLogFiles)
+	stats := w.ArchiveStats()
+	if stats.Failures != 1 || stats.LastError == nil || !strings.Contains(stats.LastError.Error(), "unavailable") {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if _, err := os.Stat(w.logFilePath(0)); err != nil {
+		t.Fatalf("file whose archiving failed was removed: %v", err)
+	}
+
+	// The next retirement archives it, then the next file, and deletes both
+	fail = false
+	rotateTimes(t, w, 1)
+	if stats := w.ArchiveStats(); stats.Archived != 2 || stats.LastError != nil {
+		t.Errorf("unexpected stats after recovery %+v", stats)
+	}
+	files, _ := w.findLogFiles()
+	if len(files) != MaxLogFiles {
+		t.Errorf("expected %d log files, got %v", MaxLogFiles, files)
+	}
+}
+
+func TestArchiveCommand(t *testing.T) {
+	dir := t.TempDir()
+	listing := filepath.Join(dir, "archived.txt")
+	w := &WAL{
+		Path:    filepath.Join(dir, "test.wal"),
+		Archive: &Archive{Command: "echo %f >> " + listing + " && test -f %p"},
+	}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	rotateTimes(t, w, MaxLogFiles)
+	data, err := os.ReadFile(listing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(data)) != ArchiveName(w.Path, 1) {
+		t.Errorf("command saw %q", data)
+	}
+	if _, err := os.Stat(w.logFilePath(0)); !os.IsNotExist(err) {
+		t.Error("file not deleted after the command succeeded")
+	}
+
+	w.Archive.Command = "exit 3"
+	rotateTimes(t, w, 1)
+	if stats := w.ArchiveStats(); stats.Failures != 1 {
+		t.Errorf("failing command not counted: %+v", stats)
+	}
+}
+
+func TestArchiveRetention(t *testing.T) {
+	dir := t.TempDir()
+	archiveDir := filepath.Join(dir, "archive")
+	w := &WAL{Path: filepath.Join(dir, "test.wal"), Archive: &Archive{Dir: archiveDir}}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	rotateTimes(t, w, MaxLogFiles+3)
+	files, _ := ArchivedFiles(archiveDir, w.Path)
+	if len(files) != 4 {
+		t.Fatalf("expected 4 archives, got %d", len(files))
+	}
+
+	// Age the two oldest, then cap the rest at the size of one
+	old := time.Now().Add(-2 * time.Hour)
+	for _, f := range files[:2] {
+		os.Chtimes(f, old, old)
+	}
+	w.Archive.MaxAge = time.Hour
+	pruned, err := pruneArchive(w.Archive, w.Path)
+	if err != nil || pruned != 2 {
+		t.Fatalf("age pruning removed %d (%v), want 2", pruned, err)
+	}
+
+	info, _ := os.Stat(files[3])
+	w.Archive.MaxBytes = info.Size()
+	if pruned, err := pruneArchive(w.Archive, w.Path); err != nil || pruned != 1 {
+		t.Fatalf("size pruning removed %d (%v), want 1", pruned, err)
+	}
+	if left, _ := ArchivedFiles(archiveDir, w.Path); len(left) != 1 || left[0] != files[3] {
+		t.Errorf("expected only the newest archive, got %v", left)
+	}
+}
+
+func TestArchiveDirMustDifferFromLog(t *testing.T) {
+	dir := t.TempDir()
+	w := &WAL{Path: filepath.Join(dir, "test.wal"), Archive: &Archive{Dir: dir}}
+	if err := w.Open(); err == nil {
+		w.Close()
+		t.Fatal("expected an error for an archive directory holding the log")
+	}
+}
@@ -2,7 +2,8 @@ package wal
 
 import (
 	"fmt"
-	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,13 +16,30 @@ const (
 type Checkpointer struct {
 	wal      *WAL
 	interval time.Duration
-	flushFn  func() error
+	flushFn  func() (uint64, error)
 	stopCh   chan struct{}
 	doneCh   chan struct{}
+
+	mu    sync.Mutex // Protects stats
+	stats CheckpointStats
+}
+
+// CheckpointStats describes the checkpoints taken and how far the log runs
+// ahead of the database file
+type CheckpointStats struct {
+	Checkpoints    uint64
+	LastCheckpoint time.Time     // Zero before the first
+	LastError      error         // Error of the last checkpoint, nil once one succeeds
+	FlushedLSN     uint64        // Entries through this LSN are durable in the database file
+	LagEntries     uint64        // LSNs assigned after FlushedLSN
+	LagAge         time.Duration // Time since FlushedLSN was last set, zero without lag
+	LogFiles       int           // Live log files; more than MaxLogFiles while flushes lag
 }
 
-// NewCheckpointer creates a checkpointer
-func NewCheckpointer(wal *WAL, flushFn func() error) *Checkpointer {
+// NewCheckpointer creates a checkpointer. flushFn makes the database file
+// durable and returns the LSN through which it holds every logged entry, or
+// 0 if unknown; log files are only retired once entirely at or below it.
+func NewCheckpointer(wal *WAL, flushFn func() (uint64, error)) *Checkpointer {
 	return &Checkpointer{
 		wal:      wal,
 		interval: DefaultCheckpointInterval,
@@ -65,10 +83,28 @@ func (c *Checkpointer) run() {
 
 // Checkpoint performs a checkpoint
 func (c *Checkpointer) Checkpoint() error {
+	err := c.checkpoint()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.LastError = err
+	if err == nil {
+		c.stats.Checkpoints++
+		c.stats.LastCheckpoint = time.Now()
+	}
+	return err
+}
+
+// checkpoint flushes the database file, marks the log and retires files the flush covers
+func (c *Checkpointer) checkpoint() error {
 	// 1. Flush in-memory state to disk
-	if err := c.flushFn(); err != nil {
+	lsn, err := c.flushFn()
+	if err != nil {
 		return fmt.Errorf("flush failed: %w", err)
 	}
+	if lsn != 0 {
+		c.wal.SetFlushedLSN(lsn)
+	}
 
 	// 2. Write checkpoint marker to WAL
 	entry := Entry{
@@ -94,31 +130,34 @@ func (c *Checkpointer) Checkpoint() error {
 	return nil
 }
 
-// truncateOldLogs removes log files before the last checkpoint
+// truncateOldLogs retires log files before the last checkpoint, keeping
+// the current file and the last 2, and any holding entries not yet flushed
 func (c *Checkpointer) truncateOldLogs() error {
 	c.wal.mu.Lock()
 	defer c.wal.mu.Unlock()
 
-	files, err := c.wal.findLogFiles()
-	if err != nil {
-		return err
-	}
-
-	// Keep current file + last 2 files
-	keepCount := 3
-	if len(files) <= keepCount {
-		return nil // Nothing to truncate
-	}
+	return c.wal.cleanOldLogsNoLock()
+}
 
-	// Remove old files
-	toRemove := files[:len(files)-keepCount]
-	for _, file := range toRemove {
-		if err := os.Remove(file); err != nil {
-			// Log error but continue
+// Stats returns the checkpoints taken and the current lag of the database file
+func (c *Checkpointer) Stats() CheckpointStats {
+	c.mu.Lock()
+	stats := c.stats
+	c.mu.Unlock()
+
+	w := c.wal
+	w.mu.Lock()
+	stats.FlushedLSN = w.flushed
+	if last := atomic.LoadUint64(&w.lsn); last > w.flushed {
+		stats.LagEntries = last - w.flushed
+		if !w.flushedAt.IsZero() {
+			stats.LagAge = time.Since(w.flushedAt)
 		}
 	}
-
-	return nil
+	files, _ := w.findLogFiles()
+	w.mu.Unlock()
+	stats.LogFiles = len(files)
+	return stats
 }
 
 // SetInterval changes the checkpoint interval
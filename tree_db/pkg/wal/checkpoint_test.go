@@ -28,9 +28,9 @@ func TestCheckpointCreation(t *testing.T) {
 	var flushCalled int32
 
 	// Create checkpointer
-	checkpointer := NewCheckpointer(w, func() error {
+	checkpointer := NewCheckpointer(w, func() (uint64, error) {
 		atomic.StoreInt32(&flushCalled, 1)
-		return nil
+		return w.LastLSN(), nil
 	})
 
 	// Manually trigger checkpoint
@@ -107,8 +107,8 @@ func TestCheckpointTruncation(t *testing.T) {
 	}
 
 	// Create checkpointer and trigger checkpoint
-	checkpointer := NewCheckpointer(w, func() error {
-		return nil
+	checkpointer := NewCheckpointer(w, func() (uint64, error) {
+		return w.LastLSN(), nil
 	})
 
 	err = checkpointer.Checkpoint()
@@ -145,9 +145,9 @@ func TestCheckpointInterval(t *testing.T) {
 	var checkpointCount int32
 
 	// Create checkpointer with short interval
-	checkpointer := NewCheckpointer(w, func() error {
+	checkpointer := NewCheckpointer(w, func() (uint64, error) {
 		atomic.AddInt32(&checkpointCount, 1)
-		return nil
+		return w.LastLSN(), nil
 	})
 	checkpointer.SetInterval(100 * time.Millisecond)
 	checkpointer.Start()
@@ -180,8 +180,8 @@ func TestCheckpointGracefulShutdown(t *testing.T) {
 	defer w.Close()
 
 	// Create checkpointer
-	checkpointer := NewCheckpointer(w, func() error {
-		return nil
+	checkpointer := NewCheckpointer(w, func() (uint64, error) {
+		return w.LastLSN(), nil
 	})
 	checkpointer.Start()
 
@@ -217,8 +217,8 @@ func TestCheckpointFlushError(t *testing.T) {
 	defer w.Close()
 
 	// Create checkpointer with flush function that returns error
-	checkpointer := NewCheckpointer(w, func() error {
-		return os.ErrPermission
+	checkpointer := NewCheckpointer(w, func() (uint64, error) {
+		return 0, os.ErrPermission
 	})
 
 	// Checkpoint should return error
@@ -264,7 +264,7 @@ func TestCheckpointMultipleFiles(t *testing.T) {
 	}
 
 	// Checkpoint
-	checkpointer := NewCheckpointer(w, func() error { return nil })
+	checkpointer := NewCheckpointer(w, func() (uint64, error) { return w.LastLSN(), nil })
 	err = checkpointer.Checkpoint()
 	if err != nil {
 		t.Fatal(err)
@@ -289,3 +289,41 @@ func TestCheckpointMultipleFiles(t *testing.T) {
 		t.Error("checkpoint marker not found after checkpoint")
 	}
 }
+
+func TestRetirementWaitsForFlush(t *testing.T) {
+	dir := t.TempDir()
+	w := &WAL{Path: filepath.Join(dir, "test.wal")}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// Only the first file's entries reach the database file
+	var flushed uint64 = 2
+	checkpointer := NewCheckpointer(w, func() (uint64, error) { return flushed, nil })
+	w.SetFlushedLSN(flushed)
+
+	rotateTimes(t, w, MaxLogFiles+2)
+	files, _ := w.findLogFiles()
+	if len(files) != MaxLogFiles+2 {
+		t.Fatalf("expected %d log files while flushes lag, got %d", MaxLogFiles+2, len(files))
+	}
+	stats := checkpointer.Stats()
+	if stats.FlushedLSN != 2 || stats.LagEntries != w.LastLSN()-2 || stats.LogFiles != MaxLogFiles+2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// A checkpoint flushing everything retires the extra files
+	flushed = w.LastLSN()
+	if err := checkpointer.Checkpoint(); err != nil {
+		t.Fatal(err)
+	}
+	files, _ = w.findLogFiles()
+	if len(files) != MaxLogFiles {
+		t.Errorf("expected %d log files after the checkpoint, got %d", MaxLogFiles, len(files))
+	}
+	stats = checkpointer.Stats()
+	if stats.Checkpoints != 1 || stats.LastCheckpoint.IsZero() || stats.LagEntries != 1 {
+		t.Errorf("unexpected stats after checkpoint %+v", stats)
+	}
+}
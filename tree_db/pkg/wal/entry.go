@@ -1,6 +1,7 @@
 package wal
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"hash/crc32"
@@ -22,14 +23,36 @@ const (
 
 	// OpCheckpoint represents a checkpoint marker
 	OpCheckpoint OpType = 4
+
+	// OpInsertSealed represents an insertion whose value is encrypted
+	OpInsertSealed OpType = 5
+
+	// OpBegin represents a transaction start marker
+	OpBegin OpType = 6
+
+	// OpAbort represents a transaction rollback marker; it cancels the
+	// transaction even when it follows the commit marker
+	OpAbort OpType = 7
 )
 
+// IsWrite reports whether the operation changes a key and is replayed on recovery
+func (op OpType) IsWrite() bool {
+	return op == OpInsert || op == OpInsertSealed || op == OpDelete
+}
+
 const (
 	// EntryHeaderSize is the fixed size of the entry header
-	// Layout: LSN(8) + TxnID(8) + OpType(1) + Reserved(7) + KeyLen(4) + ValLen(4) + Timestamp(8)
+	// Layout: LSN(8) + TxnID(8) + OpType(1) + Reserved(3) + Marker(4) + KeyLen(4) + ValLen(4) + Timestamp(8)
 	EntryHeaderSize = 40
+
+	// markerOffset is where the entry marker sits in the header
+	markerOffset = 20
 )
 
+// entryMarker tags every entry header so a reader can find the next entry
+// after damaged bytes. Entries written before it have zeros in its place.
+var entryMarker = []byte{'T', 'W', 'A', 'L'}
+
 // Entry represents a single WAL entry
 type Entry struct {
 	LSN       uint64    // Log Sequence Number (monotonically increasing)
@@ -53,7 +76,8 @@ func (e *Entry) Encode() []byte {
 	binary.LittleEndian.PutUint64(buf[0:8], e.LSN)
 	binary.LittleEndian.PutUint64(buf[8:16], e.TxnID)
 	buf[16] = byte(e.OpType)
-	// bytes 17-23 are reserved (padding)
+	// bytes 17-19 are reserved (padding)
+	copy(buf[markerOffset:], entryMarker)
 	binary.LittleEndian.PutUint32(buf[24:28], uint32(keyLen))
 	binary.LittleEndian.PutUint32(buf[28:32], uint32(valLen))
 	binary.LittleEndian.PutUint64(buf[32:40], uint64(e.Timestamp.Unix()))
@@ -85,6 +109,9 @@ func DecodeEntry(data []byte) (*Entry, error) {
 	if storedCRC != computedCRC {
 		return nil, ErrCorrupted
 	}
+	if marker := data[markerOffset : markerOffset+4]; !bytes.Equal(marker, entryMarker) && !bytes.Equal(marker, make([]byte, 4)) {
+		return nil, ErrCorrupted
+	}
 
 	// Decode header
 	entry := &Entry{
@@ -133,6 +160,8 @@ func (e *Entry) String() string {
 		opName = "INSERT"
 	case OpDelete:
 		opName = "DELETE"
+	case OpInsertSealed:
+		opName = "INSERT_SEALED"
 	case OpCommit:
 		opName = "COMMIT"
 	case OpCheckpoint:
@@ -21,4 +21,7 @@ var (
 
 	// ErrTruncated indicates a truncated WAL entry
 	ErrTruncated = errors.New("wal: truncated entry")
+
+	// ErrLSNUnavailable indicates the requested LSN was already rotated out of the log
+	ErrLSNUnavailable = errors.New("wal: LSN no longer available")
 )
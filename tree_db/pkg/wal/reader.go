@@ -1,17 +1,38 @@
 package wal
 
 import (
+	"bytes"
 	"encoding/binary"
 	"io"
 	"os"
 )
 
+// MaxResync bounds how far past a damaged entry a reader looks for the next
+// intact one before treating the rest of the file as a torn tail
+const MaxResync = 1 << 20
+
 // Reader reads WAL entries from log files
 type Reader struct {
 	files   []string // Log files to read
 	current int      // Current file index
 	fd      *os.File // Current file descriptor
+	size    int64    // Size of the current file
 	offset  int64    // Current offset in file
+	stats   ReadStats
+}
+
+// ReadStats counts the damaged bytes a Reader passed over
+type ReadStats struct {
+	// TruncatedBytes were at the end of a file with no intact entry after
+	// them, such as an entry torn by a crash
+	TruncatedBytes int64
+
+	// SkippedBytes were between intact entries and skipped by resyncing
+	SkippedBytes int64
+
+	// tornFile and tornAt locate the last torn tail seen
+	tornFile string
+	tornAt   int64
 }
 
 // NewReader creates a WAL reader for the given log files
@@ -28,81 +49,75 @@ func (r *Reader) Open() error {
 		return ErrLogNotFound
 	}
 
-	// Open first file
-	fd, err := os.Open(r.files[0])
+	r.current = 0
+	return r.openCurrent()
+}
+
+// openCurrent opens the file at the current index
+func (r *Reader) openCurrent() error {
+	fd, err := os.Open(r.files[r.current])
+	if err != nil {
+		return err
+	}
+	stat, err := fd.Stat()
 	if err != nil {
+		fd.Close()
 		return err
 	}
 
 	r.fd = fd
+	r.size = stat.Size()
 	r.offset = 0
 	return nil
 }
 
+// Stats returns the damaged bytes passed over so far
+func (r *Reader) Stats() ReadStats {
+	return r.stats
+}
+
 // Next reads the next entry
+// Damaged bytes are passed over: when an intact entry follows within
+// MaxResync it is found by its marker and the damage counts as skipped,
+// otherwise the rest of the file is a torn tail and reading moves on to the
+// next file.
 func (r *Reader) Next() (*Entry, error) {
 	for {
-		// Try reading from current file
-		entry, err := r.readEntryFromCurrent()
-		if err == nil {
-			return entry, nil
+		if r.fd == nil {
+			return nil, io.EOF
 		}
 
-		// EOF on current file - move to next
-		if err == io.EOF {
+		// End of current file - move to next
+		if r.offset >= r.size {
 			if err := r.nextFile(); err != nil {
 				return nil, err // No more files
 			}
 			continue
 		}
 
-		// Corrupted entry - skip and continue
-		if err == ErrCorrupted || err == ErrTruncated {
-			// Try to skip corrupted entry by finding next valid header
-			if err := r.skipToNextEntry(); err != nil {
-				return nil, err
-			}
-			continue
+		entry, err := readEntryAt(r.fd, r.offset, r.size)
+		if err == nil {
+			r.offset += int64(entry.Size())
+			return entry, nil
+		}
+		if err != ErrCorrupted && err != ErrTruncated {
+			return nil, err
 		}
 
-		return nil, err
-	}
-}
-
-// readEntryFromCurrent reads an entry from the current file
-func (r *Reader) readEntryFromCurrent() (*Entry, error) {
-	if r.fd == nil {
-		return nil, io.EOF
-	}
-
-	// Read header
-	header := make([]byte, EntryHeaderSize)
-	n, err := r.fd.Read(header)
-	if err != nil {
-		return nil, err
-	}
-	if n < EntryHeaderSize {
-		return nil, io.EOF
-	}
-
-	// Parse lengths
-	keyLen := binary.LittleEndian.Uint32(header[24:28])
-	valLen := binary.LittleEndian.Uint32(header[28:32])
-
-	// Read rest of entry
-	dataLen := int(keyLen) + int(valLen) + 4
-	data := make([]byte, EntryHeaderSize+dataLen)
-	copy(data, header)
-
-	n, err = io.ReadFull(r.fd, data[EntryHeaderSize:])
-	if err != nil {
-		return nil, err
+		next, err := resync(r.fd, r.offset, r.size)
+		if err != nil {
+			return nil, err
+		}
+		if next < 0 {
+			r.stats.TruncatedBytes += r.size - r.offset
+			r.stats.tornFile = r.files[r.current]
+			r.stats.tornAt = r.offset
+			r.offset = r.size
+			continue
+		}
+		r.stats.SkippedBytes += next - r.offset
+		r.offset = next
 	}
-
-	r.offset += int64(EntryHeaderSize + dataLen)
-
-	// Decode entry
-	return DecodeEntry(data)
 }
 
 // nextFile moves to the next log file
@@ -116,28 +131,65 @@ func (r *Reader) nextFile() error {
 	if r.current >= len(r.files) {
 		return io.EOF // No more files
 	}
+	return r.openCurrent()
+}
 
-	// Open next file
-	fd, err := os.Open(r.files[r.current])
-	if err != nil {
-		return err
+// readEntryAt decodes the entry at off in a file of the given size
+// An entry running past the end of the file is ErrTruncated.
+func readEntryAt(r io.ReaderAt, off, size int64) (*Entry, error) {
+	if size-off < EntryHeaderSize+4 {
+		return nil, ErrTruncated
+	}
+	header := make([]byte, EntryHeaderSize)
+	if _, err := r.ReadAt(header, off); err != nil {
+		if err == io.EOF {
+			return nil, ErrTruncated
+		}
+		return nil, err
 	}
 
-	r.fd = fd
-	r.offset = 0
-	return nil
+	keyLen := binary.LittleEndian.Uint32(header[24:28])
+	valLen := binary.LittleEndian.Uint32(header[28:32])
+	n := int64(EntryHeaderSize) + int64(keyLen) + int64(valLen) + 4
+	if off+n > size {
+		return nil, ErrTruncated
+	}
+
+	data := make([]byte, n)
+	if _, err := r.ReadAt(data, off); err != nil {
+		if err == io.EOF {
+			return nil, ErrTruncated
+		}
+		return nil, err
+	}
+	return DecodeEntry(data)
 }
 
-// skipToNextEntry attempts to skip corrupted data and find next valid entry
-func (r *Reader) skipToNextEntry() error {
-	// Simple strategy: skip 1KB and try again
-	// More sophisticated: scan for valid header magic
-	_, err := r.fd.Seek(1024, io.SeekCurrent)
-	if err != nil {
-		return err
+// resync returns the offset of the first intact entry within MaxResync bytes
+// after the damaged one at off, or -1 if there is none. Candidates are found
+// by their marker and confirmed by their checksum.
+func resync(r io.ReaderAt, off, size int64) (int64, error) {
+	window := make([]byte, min(MaxResync+EntryHeaderSize, size-off))
+	n, err := r.ReadAt(window, off)
+	if err != nil && err != io.EOF {
+		return -1, err
 	}
-	r.offset += 1024
-	return nil
+	window = window[:n]
+
+	for from := 1 + markerOffset; from < len(window); {
+		i := bytes.Index(window[from:], entryMarker)
+		if i < 0 {
+			break
+		}
+		start := off + int64(from+i-markerOffset)
+		if _, err := readEntryAt(r, start, size); err == nil {
+			return start, nil
+		} else if err != ErrCorrupted && err != ErrTruncated {
+			return -1, err
+		}
+		from += i + 1
+	}
+	return -1, nil
 }
 
 // Close closes the reader
@@ -150,9 +202,15 @@ func (r *Reader) Close() error {
 
 // ReadAll reads all entries from all files
 func ReadAll(files []string) ([]*Entry, error) {
+	entries, _, err := readAllStats(files)
+	return entries, err
+}
+
+// readAllStats reads all entries from all files, counting damaged bytes
+func readAllStats(files []string) ([]*Entry, ReadStats, error) {
 	reader := NewReader(files)
 	if err := reader.Open(); err != nil {
-		return nil, err
+		return nil, ReadStats{}, err
 	}
 	defer reader.Close()
 
@@ -163,10 +221,10 @@ func ReadAll(files []string) ([]*Entry, error) {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, reader.Stats(), err
 		}
 		entries = append(entries, entry)
 	}
 
-	return entries, nil
+	return entries, reader.Stats(), nil
 }
@@ -0,0 +1,161 @@
+package wal
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testEntrySize is the encoded size of an entry from writeTestEntries
+const testEntrySize = EntryHeaderSize + 1 + 4
+
+func TestTornTailTruncatedOnOpen(t *testing.T) {
+	dir := t.TempDir()
+	w := &WAL{Path: filepath.Join(dir, "test.wal")}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	writeTestEntries(t, w, 5)
+	w.Close()
+
+	// A crash leaves the first half of an entry at the end of the file
+	torn := (&Entry{LSN: 6, TxnID: 1, OpType: OpInsert, Key: []byte("k"), Timestamp: time.Now()}).Encode()
+	fd, err := os.OpenFile(w.logFilePath(0), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd.Write(torn[:20])
+	fd.Close()
+
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if n := w.TruncatedBytes(); n != 20 {
+		t.Errorf("truncated %d bytes, want 20", n)
+	}
+	if w.LastLSN() != 5 {
+		t.Errorf("last LSN %d, want 5", w.LastLSN())
+	}
+
+	// Entries written after reopening follow the last intact one
+	writeTestEntries(t, w, 1)
+	stats, err := NewRecovery(w).RecoverWithStats(func(OpType, []byte, []byte) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalEntries != 6 || stats.TruncatedBytes != 20 || stats.SkippedBytes != 0 {
+		t.Errorf("unexpected recovery stats %+v", stats)
+	}
+	w.Close()
+}
+
+func TestReaderResyncsPastDamage(t *testing.T) {
+	dir := t.TempDir()
+	w := &WAL{Path: filepath.Join(dir, "test.wal")}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	writeTestEntries(t, w, 5)
+	w.Close()
+
+	// Damage the key of the second entry
+	fd, err := os.OpenFile(w.logFilePath(0), os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd.WriteAt([]byte{0xFF}, testEntrySize+EntryHeaderSize)
+	fd.Close()
+
+	reader := NewReader([]string{w.logFilePath(0)})
+	if err := reader.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	var lsns []uint64
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		lsns = append(lsns, entry.LSN)
+	}
+	if len(lsns) != 4 || lsns[0] != 1 || lsns[1] != 3 {
+		t.Errorf("read LSNs %v, want 1 3 4 5", lsns)
+	}
+	if stats := reader.Stats(); stats.SkippedBytes != testEntrySize || stats.TruncatedBytes != 0 {
+		t.Errorf("unexpected read stats %+v", stats)
+	}
+
+	// Damage in the middle is kept on open, since intact entries follow it
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if n := w.TruncatedBytes(); n != 0 {
+		t.Errorf("truncated %d bytes of a file damaged in the middle", n)
+	}
+	if w.LastLSN() != 5 {
+		t.Errorf("last LSN %d, want 5", w.LastLSN())
+	}
+}
+
+func TestReaderTornTailInOlderFile(t *testing.T) {
+	dir := t.TempDir()
+	w := &WAL{Path: filepath.Join(dir, "test.wal")}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	rotateTimes(t, w, 1)
+	writeTestEntries(t, w, 2)
+	w.Close()
+
+	// Zeros where the last entry of the first file should be
+	fd, err := os.OpenFile(w.logFilePath(0), os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd.WriteAt(make([]byte, testEntrySize), testEntrySize)
+	fd.Close()
+
+	files, _ := w.findLogFiles()
+	entries, stats, err := readAllStats(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[1].LSN != 3 {
+		t.Errorf("expected LSNs 1 3 4, got %v", entries)
+	}
+	if stats.TruncatedBytes != testEntrySize || stats.SkippedBytes != 0 {
+		t.Errorf("unexpected read stats %+v", stats)
+	}
+}
+
+func TestDecodeEntryWithoutMarker(t *testing.T) {
+	// Entries written before the marker have zeros in its place
+	data := (&Entry{LSN: 7, TxnID: 2, OpType: OpDelete, Key: []byte("old")}).Encode()
+	copy(data[markerOffset:], make([]byte, 4))
+	binary.LittleEndian.PutUint32(data[len(data)-4:], crc32.ChecksumIEEE(data[:len(data)-4]))
+
+	entry, err := DecodeEntry(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.LSN != 7 || string(entry.Key) != "old" {
+		t.Errorf("decoded %v", entry)
+	}
+
+	// Any other value is damage, whatever the checksum says
+	copy(data[markerOffset:], "XXXX")
+	binary.LittleEndian.PutUint32(data[len(data)-4:], crc32.ChecksumIEEE(data[:len(data)-4]))
+	if _, err := DecodeEntry(data); err != ErrCorrupted {
+		t.Errorf("expected ErrCorrupted for a bad marker, got %v", err)
+	}
+}
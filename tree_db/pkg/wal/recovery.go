@@ -3,14 +3,50 @@ package wal
 import (
 	"fmt"
 	"os"
+	"time"
 )
 
 // ReplayFunc is called for each operation that needs to be replayed
 type ReplayFunc func(op OpType, key, value []byte) error
 
+// Target is a point in the log to recover to: through the commit at LSN, or
+// through the last commit at or before Time (entries carry whole seconds).
+// With both set, recovery stops at whichever comes first. The zero Target
+// replays everything.
+type Target struct {
+	LSN  uint64
+	Time time.Time
+}
+
+// IsZero reports whether the target replays everything
+func (t Target) IsZero() bool {
+	return t.LSN == 0 && t.Time.IsZero()
+}
+
+// Includes reports whether a commit marker falls within the target
+func (t Target) Includes(commit *Entry) bool {
+	if t.LSN != 0 && commit.LSN > t.LSN {
+		return false
+	}
+	if !t.Time.IsZero() && commit.Timestamp.After(t.Time) {
+		return false
+	}
+	return true
+}
+
 // Recovery manages crash recovery from WAL
 type Recovery struct {
 	wal *WAL
+
+	// Target stops replay at a point in time. Replay applies to the state
+	// of the last checkpoint, so a target only rewinds a copy of the
+	// database taken at or before it, such as a base backup.
+	Target Target
+
+	// FlushedLSN, when set, is the LSN through which the database file already
+	// holds every entry, as recorded in its meta page. Replay then starts
+	// after it rather than at the last checkpoint marker.
+	FlushedLSN uint64
 }
 
 // NewRecovery creates a recovery manager
@@ -40,22 +76,23 @@ func (r *Recovery) Recover(replay ReplayFunc) error {
 
 	// Find last checkpoint
 	lastCheckpoint := r.findLastCheckpoint(entries)
+	stop := r.stopLSN(entries)
 
 	// Replay committed transactions after last checkpoint
 	for _, txn := range transactions {
-		// Skip if transaction started before last checkpoint
-		if lastCheckpoint != nil && txn.StartLSN < lastCheckpoint.LSN {
+		// Skip if the database file already holds the transaction
+		if r.flushed(txn, lastCheckpoint) {
 			continue
 		}
 
-		// Only replay committed transactions
-		if !txn.Committed {
+		// Only replay committed transactions that were not rolled back, up to the target
+		if !txn.Replayable() || (stop != 0 && txn.CommitLSN >= stop) {
 			continue
 		}
 
 		// Replay all operations in this transaction
 		for _, entry := range txn.Entries {
-			if entry.OpType == OpInsert || entry.OpType == OpDelete {
+			if entry.OpType.IsWrite() {
 				if err := replay(entry.OpType, entry.Key, entry.Value); err != nil {
 					return fmt.Errorf("replay failed at LSN %d: %w", entry.LSN, err)
 				}
@@ -68,13 +105,23 @@ func (r *Recovery) Recover(replay ReplayFunc) error {
 
 // Transaction represents a group of WAL entries for a single transaction
 type Transaction struct {
-	TxnID     uint64
-	StartLSN  uint64
-	Entries   []*Entry
-	Committed bool
+	TxnID      uint64
+	StartLSN   uint64
+	Entries    []*Entry
+	Committed  bool
+	Aborted    bool      // Rolled back by an abort marker, before or after the commit marker
+	CommitLSN  uint64    // LSN of the commit marker, if committed
+	CommitTime time.Time // Timestamp of the commit marker
+}
+
+// Replayable reports whether recovery applies the transaction
+func (t *Transaction) Replayable() bool {
+	return t.Committed && !t.Aborted
 }
 
 // groupByTransaction groups WAL entries by transaction ID
+// A begin marker starts a transaction afresh, so entries left by an earlier
+// one with the same ID, such as one cut short by a crash, are dropped.
 func (r *Recovery) groupByTransaction(entries []*Entry) []*Transaction {
 	txnMap := make(map[uint64]*Transaction)
 	var txnList []*Transaction
@@ -87,7 +134,7 @@ func (r *Recovery) groupByTransaction(entries []*Entry) []*Transaction {
 
 		// Get or create transaction
 		txn, exists := txnMap[entry.TxnID]
-		if !exists {
+		if !exists || entry.OpType == OpBegin {
 			txn = &Transaction{
 				TxnID:    entry.TxnID,
 				StartLSN: entry.LSN,
@@ -98,9 +145,15 @@ func (r *Recovery) groupByTransaction(entries []*Entry) []*Transaction {
 		}
 
 		// Add entry to transaction
-		if entry.OpType == OpCommit {
+		switch entry.OpType {
+		case OpBegin:
+		case OpCommit:
 			txn.Committed = true
-		} else {
+			txn.CommitLSN = entry.LSN
+			txn.CommitTime = entry.Timestamp
+		case OpAbort:
+			txn.Aborted = true
+		default:
 			txn.Entries = append(txn.Entries, entry)
 		}
 	}
@@ -118,13 +171,45 @@ func (r *Recovery) findLastCheckpoint(entries []*Entry) *Entry {
 	return nil
 }
 
+// flushed reports whether the database file already holds what a transaction
+// logged: it committed, or without a commit started, at or before FlushedLSN,
+// or without one, it started before the last checkpoint marker
+func (r *Recovery) flushed(txn *Transaction, lastCheckpoint *Entry) bool {
+	if r.FlushedLSN != 0 {
+		if txn.Committed {
+			return txn.CommitLSN <= r.FlushedLSN
+		}
+		return txn.StartLSN <= r.FlushedLSN
+	}
+	return lastCheckpoint != nil && txn.StartLSN < lastCheckpoint.LSN
+}
+
+// stopLSN returns the LSN of the first commit past the target, or 0 when
+// every commit is within it. Later commits are past it too, even if their
+// timestamps step back.
+func (r *Recovery) stopLSN(entries []*Entry) uint64 {
+	if r.Target.IsZero() {
+		return 0
+	}
+	for _, entry := range entries {
+		if entry.OpType == OpCommit && !r.Target.Includes(entry) {
+			return entry.LSN
+		}
+	}
+	return 0
+}
+
 // Stats returns recovery statistics
 type RecoveryStats struct {
 	TotalEntries       int
 	CommittedTxns      int
 	UncommittedTxns    int
+	AbortedTxns        int
 	ReplayedOperations int
 	LastCheckpointLSN  uint64
+	StoppedAtLSN       uint64 // First commit past the target, left unreplayed
+	TruncatedBytes     int64  // Torn tails cut off at Open or passed over while reading
+	SkippedBytes       int64  // Damaged bytes between intact entries
 }
 
 // RecoverWithStats performs recovery and returns statistics
@@ -141,12 +226,14 @@ func (r *Recovery) RecoverWithStats(replay ReplayFunc) (*RecoveryStats, error) {
 	}
 
 	// Read all entries
-	entries, err := ReadAll(files)
+	entries, read, err := readAllStats(files)
 	if err != nil {
 		return nil, err
 	}
 
 	stats.TotalEntries = len(entries)
+	stats.TruncatedBytes = r.wal.TruncatedBytes() + read.TruncatedBytes
+	stats.SkippedBytes = read.SkippedBytes
 
 	// Group by transaction
 	transactions := r.groupByTransaction(entries)
@@ -156,17 +243,23 @@ func (r *Recovery) RecoverWithStats(replay ReplayFunc) (*RecoveryStats, error) {
 	if lastCheckpoint != nil {
 		stats.LastCheckpointLSN = lastCheckpoint.LSN
 	}
+	stats.StoppedAtLSN = r.stopLSN(entries)
 
 	// Count and replay
 	for _, txn := range transactions {
-		if lastCheckpoint != nil && txn.StartLSN < lastCheckpoint.LSN {
+		if r.flushed(txn, lastCheckpoint) {
+			continue
+		}
+		if stats.StoppedAtLSN != 0 && txn.Committed && txn.CommitLSN >= stats.StoppedAtLSN {
 			continue
 		}
 
-		if txn.Committed {
+		if txn.Aborted {
+			stats.AbortedTxns++
+		} else if txn.Committed {
 			stats.CommittedTxns++
 			for _, entry := range txn.Entries {
-				if entry.OpType == OpInsert || entry.OpType == OpDelete {
+				if entry.OpType.IsWrite() {
 					if err := replay(entry.OpType, entry.Key, entry.Value); err != nil {
 						return stats, err
 					}
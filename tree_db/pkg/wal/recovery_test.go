@@ -405,3 +405,155 @@ func TestRecoveryEmptyWAL(t *testing.T) {
 		t.Errorf("recovery of empty WAL should succeed, got error: %v", err)
 	}
 }
+
+func TestRecoveryToTarget(t *testing.T) {
+	walPath := filepath.Join(t.TempDir(), "test.wal")
+	w := &WAL{Path: walPath}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// One transaction a minute, the last at base
+	base := time.Now().Truncate(time.Second)
+	var commits []uint64
+	for txn := 0; txn < 4; txn++ {
+		at := base.Add(time.Duration(txn-3) * time.Minute)
+		w.Write(Entry{LSN: w.NextLSN(), TxnID: uint64(txn), OpType: OpInsert, Key: []byte(fmt.Sprintf("key-%d", txn)), Timestamp: at})
+		lsn := w.NextLSN()
+		w.Write(Entry{LSN: lsn, TxnID: uint64(txn), OpType: OpCommit, Timestamp: at})
+		commits = append(commits, lsn)
+	}
+	w.Close()
+
+	w2 := &WAL{Path: walPath}
+	w2.Open()
+	defer w2.Close()
+
+	cases := map[string]struct {
+		target Target
+		want   int
+	}{
+		"everything":      {Target{}, 4},
+		"through an LSN":  {Target{LSN: commits[1]}, 2},
+		"between commits": {Target{LSN: commits[1] + 1}, 2},
+		"at a time":       {Target{Time: base.Add(-2 * time.Minute)}, 2},
+		"before any":      {Target{Time: base.Add(-time.Hour)}, 0},
+		"earlier of both": {Target{LSN: commits[2], Time: base.Add(-3 * time.Minute)}, 1},
+	}
+	for name, c := range cases {
+		recovery := NewRecovery(w2)
+		recovery.Target = c.target
+		replayed := 0
+		stats, err := recovery.RecoverWithStats(func(OpType, []byte, []byte) error {
+			replayed++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if replayed != c.want {
+			t.Errorf("%s: replayed %d transactions, want %d", name, replayed, c.want)
+		}
+		if wantStop := c.want < 4; (stats.StoppedAtLSN != 0) != wantStop {
+			t.Errorf("%s: stopped at LSN %d", name, stats.StoppedAtLSN)
+		}
+	}
+}
+
+func TestRecoveryAbortedTransactions(t *testing.T) {
+	walPath := filepath.Join(t.TempDir(), "test.wal")
+	w := &WAL{Path: walPath}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	write := func(txnID uint64, op OpType, key string) {
+		entry := Entry{LSN: w.NextLSN(), TxnID: txnID, OpType: op, Timestamp: now}
+		if key != "" {
+			entry.Key, entry.Value = []byte(key), []byte("v")
+		}
+		if err := w.Write(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 1 commits; 2 is aborted before committing; 3 is rolled back after
+	// its commit marker; 4 never finishes
+	write(1, OpBegin, "")
+	write(1, OpInsert, "committed")
+	write(1, OpCommit, "")
+	write(2, OpBegin, "")
+	write(2, OpInsert, "aborted")
+	write(2, OpAbort, "")
+	write(3, OpBegin, "")
+	write(3, OpInsert, "rolled-back")
+	write(3, OpCommit, "")
+	write(3, OpAbort, "")
+	write(4, OpBegin, "")
+	write(4, OpInsert, "unfinished")
+	w.Close()
+
+	w2 := &WAL{Path: walPath}
+	w2.Open()
+	defer w2.Close()
+
+	var replayed []string
+	stats, err := NewRecovery(w2).RecoverWithStats(func(op OpType, key, value []byte) error {
+		replayed = append(replayed, string(key))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(replayed) != "[committed]" {
+		t.Errorf("replayed %v, want only the committed transaction", replayed)
+	}
+	if stats.CommittedTxns != 1 || stats.AbortedTxns != 2 || stats.UncommittedTxns != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRecoveryFromFlushedLSN(t *testing.T) {
+	walPath := filepath.Join(t.TempDir(), "test.wal")
+	w := &WAL{Path: walPath}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	now := time.Now()
+	write := func(txnID uint64, op OpType, key string) uint64 {
+		entry := Entry{LSN: w.NextLSN(), TxnID: txnID, OpType: op, Timestamp: now}
+		if key != "" {
+			entry.Key, entry.Value = []byte(key), []byte("v")
+		}
+		if err := w.Write(entry); err != nil {
+			t.Fatal(err)
+		}
+		return entry.LSN
+	}
+
+	// 1 is flushed; 2 began before a checkpoint marker but committed after
+	// the flush, so only the flushed LSN tells that the file lacks it
+	write(1, OpBegin, "")
+	write(1, OpInsert, "flushed")
+	flushed := write(1, OpCommit, "")
+	write(2, OpBegin, "")
+	write(2, OpInsert, "unflushed")
+	write(0, OpCheckpoint, "")
+	write(2, OpCommit, "")
+
+	var replayed []string
+	r := NewRecovery(w)
+	r.FlushedLSN = flushed
+	if err := r.Recover(func(op OpType, key, value []byte) error {
+		replayed = append(replayed, string(key))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(replayed) != "[unflushed]" {
+		t.Errorf("replayed %v, want only the transaction after the flushed LSN", replayed)
+	}
+}
@@ -0,0 +1,120 @@
+package wal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Tailer follows a live WAL, returning entries in log order as they are written
+type Tailer struct {
+	wal    *WAL
+	after  uint64   // Entries at or below this LSN are skipped
+	index  int      // Index of the current log file
+	fd     *os.File // Current log file
+	offset int64    // Offset of the next unread entry in fd
+}
+
+// Tail opens a tailer positioned after the given LSN
+// It fails with ErrLSNUnavailable when entries following afterLSN have been
+// rotated out, since the caller would otherwise silently miss writes.
+func (w *WAL) Tail(afterLSN uint64) (*Tailer, error) {
+	w.mu.Lock()
+	files, err := w.findLogFiles()
+	w.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, ErrLogNotFound
+	}
+
+	t := &Tailer{wal: w, after: afterLSN}
+	if _, err := fmt.Sscanf(filepath.Base(files[0]), w.baseName()+".%d", &t.index); err != nil {
+		return nil, err
+	}
+	if t.fd, err = os.Open(files[0]); err != nil {
+		return nil, err
+	}
+
+	// The oldest retained entry must not be past the first one the caller needs
+	first, err := t.peek()
+	if err != nil && err != io.EOF {
+		t.Close()
+		return nil, err
+	}
+	if first != nil && first.LSN > afterLSN+1 {
+		t.Close()
+		return nil, ErrLSNUnavailable
+	}
+
+	return t, nil
+}
+
+// Next returns the next entry, or io.EOF when the tailer has caught up with the writer
+// After io.EOF, wait on WAL.Changed (fetched before the call) and call Next again.
+func (t *Tailer) Next() (*Entry, error) {
+	for {
+		entry, err := t.peek()
+		if err == io.EOF {
+			// A newer file means this one was rotated and is complete
+			next, openErr := os.Open(t.wal.logFilePath(t.index + 1))
+			if openErr != nil {
+				if errors.Is(openErr, os.ErrNotExist) {
+					return nil, io.EOF
+				}
+				return nil, openErr
+			}
+			t.fd.Close()
+			t.fd, t.offset = next, 0
+			t.index++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		t.offset += int64(entry.Size())
+		if entry.LSN <= t.after {
+			continue
+		}
+		return entry, nil
+	}
+}
+
+// Close releases the current log file
+func (t *Tailer) Close() error {
+	if t.fd == nil {
+		return nil
+	}
+	err := t.fd.Close()
+	t.fd = nil
+	return err
+}
+
+// peek decodes the entry at the current offset without consuming it
+// A partially written entry reads as io.EOF so it is retried once complete.
+func (t *Tailer) peek() (*Entry, error) {
+	header := make([]byte, EntryHeaderSize)
+	if _, err := t.fd.ReadAt(header, t.offset); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	keyLen := binary.LittleEndian.Uint32(header[24:28])
+	valLen := binary.LittleEndian.Uint32(header[28:32])
+	data := make([]byte, EntryHeaderSize+int(keyLen)+int(valLen)+4)
+	if _, err := t.fd.ReadAt(data, t.offset); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	return DecodeEntry(data)
+}
@@ -0,0 +1,112 @@
+package wal
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestEntries(t *testing.T, w *WAL, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		entry := Entry{LSN: w.NextLSN(), TxnID: 1, OpType: OpInsert, Key: []byte("k"), Timestamp: time.Now()}
+		if err := w.Write(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTailFollowsWrites(t *testing.T) {
+	dir := t.TempDir()
+	w := &WAL{Path: filepath.Join(dir, "test.wal")}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	writeTestEntries(t, w, 3)
+
+	tailer, err := w.Tail(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tailer.Close()
+
+	for _, want := range []uint64{2, 3} {
+		entry, err := tailer.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if entry.LSN != want {
+			t.Errorf("got LSN %d, want %d", entry.LSN, want)
+		}
+	}
+
+	changed := w.Changed()
+	if _, err := tailer.Next(); err != io.EOF {
+		t.Fatalf("expected io.EOF when caught up, got %v", err)
+	}
+
+	// A rotation between reads moves the tailer to the next file
+	w.mu.Lock()
+	if err := w.rotateNoLock(); err != nil {
+		t.Fatal(err)
+	}
+	w.mu.Unlock()
+	writeTestEntries(t, w, 1)
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("Changed was not signalled by Write")
+	}
+
+	entry, err := tailer.Next()
+	if err != nil {
+		t.Fatalf("Next after write failed: %v", err)
+	}
+	if entry.LSN != 4 {
+		t.Errorf("got LSN %d, want 4", entry.LSN)
+	}
+	if w.LastLSN() != 4 {
+		t.Errorf("LastLSN = %d, want 4", w.LastLSN())
+	}
+}
+
+func TestTailRotatedOut(t *testing.T) {
+	dir := t.TempDir()
+	w := &WAL{Path: filepath.Join(dir, "test.wal")}
+	if err := w.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	writeTestEntries(t, w, 2)
+	w.mu.Lock()
+	if err := w.rotateNoLock(); err != nil {
+		t.Fatal(err)
+	}
+	w.mu.Unlock()
+	writeTestEntries(t, w, 2)
+
+	if err := os.Remove(w.logFilePath(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Tail(0); err != ErrLSNUnavailable {
+		t.Errorf("expected ErrLSNUnavailable, got %v", err)
+	}
+
+	tailer, err := w.Tail(2)
+	if err != nil {
+		t.Fatalf("Tail from a retained LSN failed: %v", err)
+	}
+	defer tailer.Close()
+
+	entry, err := tailer.Next()
+	if err != nil || entry.LSN != 3 {
+		t.Errorf("expected LSN 3, got %v (%v)", entry, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 const (
@@ -27,6 +28,9 @@ type WAL struct {
 	// Path is the base path for WAL files (e.g., "/data/db.wal")
 	Path string
 
+	// Archive keeps retired log files instead of deleting them (nil deletes them)
+	Archive *Archive
+
 	// fd is the current log file descriptor
 	fd *os.File
 
@@ -44,6 +48,21 @@ type WAL struct {
 
 	// closed indicates whether the WAL is closed
 	closed bool
+
+	// notify is closed by the next Write to wake tailers (nil until requested)
+	notify chan struct{}
+
+	// archiveStats counts files retired through Archive
+	archiveStats ArchiveStats
+
+	// truncated is the size of the torn tail cut off by Open
+	truncated int64
+
+	// flushed is the LSN through which entries are durable in the database
+	// file, set at flushedAt; tracksFlushed is false until it is first set
+	flushed       uint64
+	flushedAt     time.Time
+	tracksFlushed bool
 }
 
 // Open opens or creates the WAL
@@ -51,6 +70,12 @@ func (w *WAL) Open() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if err := w.Archive.validate(w.Path); err != nil {
+		return err
+	}
+
+	w.truncated = 0
+
 	// Find existing WAL files
 	files, err := w.findLogFiles()
 	if err != nil && !os.IsNotExist(err) {
@@ -81,7 +106,7 @@ func (w *WAL) Open() error {
 		}
 
 		// Scan for highest LSN
-		maxLSN, err := w.scanForHighestLSN(files)
+		maxLSN, err := w.scanLogs(files)
 		if err != nil {
 			return err
 		}
@@ -106,6 +131,36 @@ func (w *WAL) Open() error {
 	return nil
 }
 
+// TruncatedBytes returns the size of the torn tail Open cut off the latest
+// log file, left by a write interrupted by a crash
+func (w *WAL) TruncatedBytes() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.truncated
+}
+
+// SetFlushedLSN records that every entry through lsn is durable in the
+// database file. From then on, log files holding later entries are kept past
+// MaxLogFiles until a flush covers them; before, files are retired on count alone.
+func (w *WAL) SetFlushedLSN(lsn uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if lsn > w.flushed || !w.tracksFlushed {
+		w.flushed = lsn
+	}
+	w.flushedAt = time.Now()
+	w.tracksFlushed = true
+}
+
+// FlushedLSN returns the LSN through which entries are durable in the
+// database file, 0 if never set
+func (w *WAL) FlushedLSN() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flushed
+}
+
 // NextLSN returns the next Log Sequence Number
 func (w *WAL) NextLSN() uint64 {
 	return atomic.AddUint64(&w.lsn, 1)
@@ -137,9 +192,31 @@ func (w *WAL) Write(entry Entry) error {
 	}
 
 	w.fileSize += int64(n)
+
+	if w.notify != nil {
+		close(w.notify)
+		w.notify = nil
+	}
 	return nil
 }
 
+// Changed returns a channel that is closed by the next Write
+// Fetch it before reading to the end of the log so no write is missed.
+func (w *WAL) Changed() <-chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.notify == nil {
+		w.notify = make(chan struct{})
+	}
+	return w.notify
+}
+
+// LastLSN returns the most recently assigned Log Sequence Number
+func (w *WAL) LastLSN() uint64 {
+	return atomic.LoadUint64(&w.lsn)
+}
+
 // Fsync ensures all written data is persisted to disk
 func (w *WAL) Fsync() error {
 	w.mu.Lock()
@@ -193,24 +270,48 @@ func (w *WAL) rotateNoLock() error {
 	return w.cleanOldLogsNoLock()
 }
 
-// cleanOldLogsNoLock removes old log files (caller must hold mu)
+// cleanOldLogsNoLock retires old log files through the archive, if any
+// (caller must hold mu). Failures leave files in place for the next attempt
+// and are counted in ArchiveStats rather than failing the write.
 func (w *WAL) cleanOldLogsNoLock() error {
 	files, err := w.findLogFiles()
 	if err != nil {
 		return err
 	}
 
-	// Keep last MaxLogFiles
+	// Keep last MaxLogFiles, retiring oldest first so archives stay in order
 	if len(files) > MaxLogFiles {
 		toRemove := files[:len(files)-MaxLogFiles]
-		for _, f := range toRemove {
-			os.Remove(f) // Ignore errors
+		for i, f := range toRemove {
+			if !w.flushedThroughNoLock(files[i+1]) {
+				break
+			}
+			if err := w.retireNoLock(f); err != nil && w.Archive != nil {
+				break
+			}
 		}
 	}
 
 	return nil
 }
 
+// flushedThroughNoLock reports whether every entry before the log file next
+// is durable in the database file, judging by the first LSN of next (caller
+// must hold mu)
+func (w *WAL) flushedThroughNoLock(next string) bool {
+	if !w.tracksFlushed {
+		return true
+	}
+	first, err := firstLSN(next)
+	if err != nil {
+		return false
+	}
+	if first == 0 {
+		first = atomic.LoadUint64(&w.lsn) + 1 // Nothing written to next yet
+	}
+	return first-1 <= w.flushed
+}
+
 // baseName returns the base filename for WAL files (e.g., "mydb.db.wal" from "/path/to/mydb.db.wal")
 func (w *WAL) baseName() string {
 	return filepath.Base(w.Path)
@@ -223,6 +324,12 @@ func (w *WAL) logFilePath(index int) string {
 	return filepath.Join(dir, name)
 }
 
+// LogFiles returns the live log files of the WAL at path, oldest first,
+// without opening it
+func LogFiles(path string) ([]string, error) {
+	return (&WAL{Path: path}).findLogFiles()
+}
+
 // findLogFiles returns all WAL files sorted by index
 func (w *WAL) findLogFiles() ([]string, error) {
 	dir := filepath.Dir(w.Path)
@@ -259,37 +366,41 @@ func (w *WAL) isWALFile(name string) bool {
 	return err == nil
 }
 
-// scanForHighestLSN scans all WAL files and returns the highest LSN
-func (w *WAL) scanForHighestLSN(files []string) (uint64, error) {
-	var maxLSN uint64
+// scanLogs returns the highest LSN in files and cuts a torn tail off the
+// latest one, which Open appends to, so new entries don't land after damage
+// that would hide them from readers (caller must hold mu)
+func (w *WAL) scanLogs(files []string) (uint64, error) {
+	reader := NewReader(files)
+	if err := reader.Open(); err != nil {
+		return 0, err
+	}
+	defer reader.Close()
 
-	for _, file := range files {
-		fd, err := os.Open(file)
+	var maxLSN uint64
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
 			return 0, err
 		}
-
-		// Read entries and track max LSN
-		for {
-			entry, err := w.readEntry(fd)
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				// Skip corrupted entries by seeking forward
-				// This prevents infinite loops when corruption occurs
-				fd.Seek(1024, io.SeekCurrent)
-				continue
-			}
-
-			if entry.LSN > maxLSN {
-				maxLSN = entry.LSN
-			}
+		if entry.LSN > maxLSN {
+			maxLSN = entry.LSN
 		}
-
-		fd.Close()
 	}
 
+	stats := reader.Stats()
+	if stats.tornFile == files[len(files)-1] {
+		if err := w.fd.Truncate(stats.tornAt); err != nil {
+			return 0, fmt.Errorf("truncate torn tail of %s: %w", stats.tornFile, err)
+		}
+		if err := w.fd.Sync(); err != nil {
+			return 0, err
+		}
+		w.truncated = w.fileSize - stats.tornAt
+		w.fileSize = stats.tornAt
+	}
 	return maxLSN, nil
 }
 
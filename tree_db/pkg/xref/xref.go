@@ -0,0 +1,183 @@
+// ABOUTME: Finds textual references such as "see Section 4.2" or "as defined in Policy ABC-12" in node text
+// ABOUTME: Rules are regular expressions naming the section and policy they capture; callers resolve them to nodes
+
+package xref
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// DefaultType is the reference type of rules that set none
+const DefaultType = "cites"
+
+// DefaultContext is the number of bytes of text kept on each side of a mention
+const DefaultContext = 80
+
+// Rule finds one kind of reference. Its pattern captures the referenced
+// section path in a group named "section", the referenced policy in one named
+// "policy", or both; a mention without a policy refers to the node's own.
+type Rule struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+	Type    string `yaml:"type"` // Type of the references it finds; DefaultType if empty
+}
+
+// Rules is a rule set as loaded from YAML
+type Rules struct {
+	Rules   []Rule `yaml:"rules"`
+	Context int    `yaml:"context"` // Bytes kept around each mention; DefaultContext if 0
+}
+
+// DefaultRules finds section references ("see Section 4.2", "under § 3"),
+// policy references ("as defined in Policy ABC-12") and both together
+// ("Section 2.1 of Policy LCD-33")
+func DefaultRules() Rules {
+	return Rules{Rules: []Rule{
+		{
+			Name:    "section-of-policy",
+			Pattern: `(?i:section|§)\s*(?P<section>\d+(?:\.\d+)*)\s+(?i:of)\s+(?i:policy)\s+(?P<policy>[A-Z][A-Z0-9]*-[0-9]+)`,
+		},
+		{
+			Name:    "section",
+			Pattern: `\b(?i:see|in|under|per|refer to)\s+(?i:section\s*|§\s*)(?P<section>\d+(?:\.\d+)*)`,
+		},
+		{
+			Name:    "policy",
+			Pattern: `\b(?i:as defined in|defined in|see|per|under|refer to)\s+(?i:policy)\s+(?P<policy>[A-Z][A-Z0-9]*-[0-9]+)`,
+		},
+	}}
+}
+
+// LoadRules reads a rule set from a YAML file
+func LoadRules(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, err
+	}
+	var rules Rules
+	if err := yaml.UnmarshalStrict(data, &rules); err != nil {
+		return Rules{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Mention is a reference found in text, before it is resolved to a node
+type Mention struct {
+	Rule     string
+	Type     string
+	PolicyID string // Empty for the policy of the text
+	Section  string // Empty for the policy's root
+	Start    int    // Byte offsets of the match in the text
+	End      int
+	Context  string // The match with the text around it
+}
+
+// Extractor finds mentions with a rule set
+type Extractor struct {
+	rules   []rule
+	context int
+}
+
+type rule struct {
+	Rule
+	re      *regexp.Regexp
+	section int // Submatch index of the section group, or -1
+	policy  int // Submatch index of the policy group, or -1
+}
+
+// NewExtractor compiles a rule set
+func NewExtractor(rules Rules) (*Extractor, error) {
+	e := &Extractor{context: rules.Context}
+	if e.context <= 0 {
+		e.context = DefaultContext
+	}
+	if len(rules.Rules) == 0 {
+		return nil, fmt.Errorf("no reference rules")
+	}
+	for _, r := range rules.Rules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		compiled := rule{Rule: r, re: re, section: re.SubexpIndex("section"), policy: re.SubexpIndex("policy")}
+		if compiled.section < 0 && compiled.policy < 0 {
+			return nil, fmt.Errorf("rule %q captures neither a section nor a policy group", r.Name)
+		}
+		if compiled.Type == "" {
+			compiled.Type = DefaultType
+		}
+		e.rules = append(e.rules, compiled)
+	}
+	return e, nil
+}
+
+// Find returns the mentions in text, ordered by position. Rules are tried in
+// order, and a match overlapping one found by an earlier rule is skipped, so
+// list the more specific rules first.
+func (e *Extractor) Find(text string) []Mention {
+	var mentions []Mention
+	taken := func(start, end int) bool {
+		for _, m := range mentions {
+			if start < m.End && m.Start < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, r := range e.rules {
+		for _, loc := range r.re.FindAllStringSubmatchIndex(text, -1) {
+			if taken(loc[0], loc[1]) {
+				continue
+			}
+			m := Mention{
+				Rule:     r.Name,
+				Type:     r.Type,
+				PolicyID: group(text, loc, r.policy),
+				Section:  group(text, loc, r.section),
+				Start:    loc[0],
+				End:      loc[1],
+				Context:  e.around(text, loc[0], loc[1]),
+			}
+			if m.PolicyID == "" && m.Section == "" {
+				continue
+			}
+			mentions = append(mentions, m)
+		}
+	}
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].Start < mentions[j].Start })
+	return mentions
+}
+
+// group returns submatch i of a match, or "" if the rule has no such group or
+// it did not take part
+func group(text string, loc []int, i int) string {
+	if i < 0 || loc[2*i] < 0 {
+		return ""
+	}
+	return text[loc[2*i]:loc[2*i+1]]
+}
+
+// around returns text[start:end] with up to the extractor's context bytes on
+// each side, widened to whole UTF-8 characters
+func (e *Extractor) around(text string, start, end int) string {
+	from := max(start-e.context, 0)
+	for from > 0 && !utf8Start(text[from]) {
+		from--
+	}
+	to := min(end+e.context, len(text))
+	for to < len(text) && !utf8Start(text[to]) {
+		to++
+	}
+	return text[from:to]
+}
+
+// utf8Start reports whether b begins a UTF-8 character
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,79 @@
+// ABOUTME: Tests for finding textual references
+// ABOUTME: Verifies the default rules, overlap between rules, context and loading rules from YAML
+
+package xref
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultRules(t *testing.T) {
+	e, err := NewExtractor(DefaultRules())
+	if err != nil {
+		t.Fatalf("NewExtractor failed: %v", err)
+	}
+
+	text := "Coverage is limited (see Section 4.2). Terms are as defined in Policy ABC-12, " +
+		"and exclusions follow Section 2.1 of Policy LCD-33. Under § 3 no waiver applies."
+	got := e.Find(text)
+	want := []Mention{
+		{Rule: "section", Section: "4.2"},
+		{Rule: "policy", PolicyID: "ABC-12"},
+		{Rule: "section-of-policy", PolicyID: "LCD-33", Section: "2.1"},
+		{Rule: "section", Section: "3"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d mentions, got %+v", len(want), got)
+	}
+	for i, m := range got {
+		if m.Rule != want[i].Rule || m.PolicyID != want[i].PolicyID || m.Section != want[i].Section || m.Type != DefaultType {
+			t.Errorf("Mention %d: expected %+v, got %+v", i, want[i], m)
+		}
+		if text[m.Start:m.End] == "" || !strings.Contains(m.Context, text[m.Start:m.End]) {
+			t.Errorf("Mention %d: expected its context to hold the match, got %q", i, m.Context)
+		}
+	}
+
+	if got := e.Find("Section headings are bold; policy matters."); len(got) != 0 {
+		t.Errorf("Expected no mentions, got %+v", got)
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	yaml := `context: 4
+rules:
+  - name: article
+    pattern: 'Article (?P<section>[0-9]+)'
+    type: defines
+`
+	if err := os.WriteFile(path, []byte(yaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+	e, err := NewExtractor(rules)
+	if err != nil {
+		t.Fatalf("NewExtractor failed: %v", err)
+	}
+	got := e.Find("As in Article 7, fees apply.")
+	if len(got) != 1 || got[0].Section != "7" || got[0].Type != "defines" || got[0].Context != " in Article 7, fe" {
+		t.Errorf("Expected Article 7 with 4 bytes of context, got %+v", got)
+	}
+
+	if _, err := NewExtractor(Rules{Rules: []Rule{{Name: "bad", Pattern: `Article [0-9]+`}}}); err == nil {
+		t.Error("Expected a rule without groups rejected")
+	}
+	if _, err := NewExtractor(Rules{Rules: []Rule{{Name: "bad", Pattern: `(?P<section>`}}}); err == nil {
+		t.Error("Expected an invalid pattern rejected")
+	}
+	os.WriteFile(path, []byte("rule: []\n"), 0600)
+	if _, err := LoadRules(path); err == nil {
+		t.Error("Expected an unknown key rejected")
+	}
+}
@@ -115,22 +115,27 @@ func (x *Document) GetUpdatedAt() *timestamppb.Timestamp {
 }
 
 type Node struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
-	PolicyId      string                 `protobuf:"bytes,2,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
-	ParentId      string                 `protobuf:"bytes,3,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // Empty string for root
-	Title         string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
-	PageStart     int32                  `protobuf:"varint,5,opt,name=page_start,json=pageStart,proto3" json:"page_start,omitempty"`
-	PageEnd       int32                  `protobuf:"varint,6,opt,name=page_end,json=pageEnd,proto3" json:"page_end,omitempty"`
-	Summary       string                 `protobuf:"bytes,7,opt,name=summary,proto3" json:"summary,omitempty"`
-	Text          string                 `protobuf:"bytes,8,opt,name=text,proto3" json:"text,omitempty"`
-	SectionPath   string                 `protobuf:"bytes,9,opt,name=section_path,json=sectionPath,proto3" json:"section_path,omitempty"` // e.g., "1.2.3"
-	ChildIds      []string               `protobuf:"bytes,10,rep,name=child_ids,json=childIds,proto3" json:"child_ids,omitempty"`
-	Depth         int32                  `protobuf:"varint,11,opt,name=depth,proto3" json:"depth,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	NodeId          string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	PolicyId        string                 `protobuf:"bytes,2,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	ParentId        string                 `protobuf:"bytes,3,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // Empty string for root
+	Title           string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	PageStart       int32                  `protobuf:"varint,5,opt,name=page_start,json=pageStart,proto3" json:"page_start,omitempty"`
+	PageEnd         int32                  `protobuf:"varint,6,opt,name=page_end,json=pageEnd,proto3" json:"page_end,omitempty"`
+	Summary         string                 `protobuf:"bytes,7,opt,name=summary,proto3" json:"summary,omitempty"`
+	Text            string                 `protobuf:"bytes,8,opt,name=text,proto3" json:"text,omitempty"`
+	SectionPath     string                 `protobuf:"bytes,9,opt,name=section_path,json=sectionPath,proto3" json:"section_path,omitempty"` // e.g., "1.2.3"
+	ChildIds        []string               `protobuf:"bytes,10,rep,name=child_ids,json=childIds,proto3" json:"child_ids,omitempty"`
+	Depth           int32                  `protobuf:"varint,11,opt,name=depth,proto3" json:"depth,omitempty"`
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Version         uint64                 `protobuf:"varint,14,opt,name=version,proto3" json:"version,omitempty"`                                       // Incremented on every write
+	Language        string                 `protobuf:"bytes,15,opt,name=language,proto3" json:"language,omitempty"`                                      // e.g., "en"; empty if unknown
+	Checksum        string                 `protobuf:"bytes,16,opt,name=checksum,proto3" json:"checksum,omitempty"`                                      // Hash of the content; empty if not computed
+	TokenCount      int32                  `protobuf:"varint,17,opt,name=token_count,json=tokenCount,proto3" json:"token_count,omitempty"`               // Estimated tokens in the text; 0 if not counted
+	SummaryChecksum string                 `protobuf:"bytes,18,opt,name=summary_checksum,json=summaryChecksum,proto3" json:"summary_checksum,omitempty"` // Checksum of the text the summary was written for; set by the server
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *Node) Reset() {
@@ -254,6 +259,41 @@ func (x *Node) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Node) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Node) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Node) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *Node) GetTokenCount() int32 {
+	if x != nil {
+		return x.TokenCount
+	}
+	return 0
+}
+
+func (x *Node) GetSummaryChecksum() string {
+	if x != nil {
+		return x.SummaryChecksum
+	}
+	return ""
+}
+
 type PolicyVersion struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
@@ -263,6 +303,9 @@ type PolicyVersion struct {
 	CreatedBy     string                 `protobuf:"bytes,5,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
 	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
 	Tags          []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
+	EffectiveFrom *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=effective_from,json=effectiveFrom,proto3" json:"effective_from,omitempty"`
+	EffectiveTo   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=effective_to,json=effectiveTo,proto3" json:"effective_to,omitempty"` // Unset while effective until superseded
+	Metadata      map[string]string      `protobuf:"bytes,10,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -346,6 +389,27 @@ func (x *PolicyVersion) GetTags() []string {
 	return nil
 }
 
+func (x *PolicyVersion) GetEffectiveFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EffectiveFrom
+	}
+	return nil
+}
+
+func (x *PolicyVersion) GetEffectiveTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EffectiveTo
+	}
+	return nil
+}
+
+func (x *PolicyVersion) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
 type ToolResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ToolName      string                 `protobuf:"bytes,1,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
@@ -889,6 +953,9 @@ type PromptUsage struct {
 	FilledVariables map[string]string      `protobuf:"bytes,3,rep,name=filled_variables,json=filledVariables,proto3" json:"filled_variables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	Response        string                 `protobuf:"bytes,4,opt,name=response,proto3" json:"response,omitempty"`
 	UsedAt          *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=used_at,json=usedAt,proto3" json:"used_at,omitempty"`
+	ExperimentId    string                 `protobuf:"bytes,6,opt,name=experiment_id,json=experimentId,proto3" json:"experiment_id,omitempty"` // With variant: also counts the usage in this experiment
+	Variant         string                 `protobuf:"bytes,7,opt,name=variant,proto3" json:"variant,omitempty"`                               // As returned by AssignPromptVariant
+	Outcome         string                 `protobuf:"bytes,8,opt,name=outcome,proto3" json:"outcome,omitempty"`                               // Outcome label, or empty until LabelPromptUsage sets it
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -958,28 +1025,53 @@ func (x *PromptUsage) GetUsedAt() *timestamppb.Timestamp {
 	return nil
 }
 
-type StoreDocumentRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Document      *Document              `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`
-	Nodes         []*Node                `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *PromptUsage) GetExperimentId() string {
+	if x != nil {
+		return x.ExperimentId
+	}
+	return ""
 }
 
-func (x *StoreDocumentRequest) Reset() {
-	*x = StoreDocumentRequest{}
+func (x *PromptUsage) GetVariant() string {
+	if x != nil {
+		return x.Variant
+	}
+	return ""
+}
+
+func (x *PromptUsage) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+// Splits traffic between prompt template variants by stable buckets
+type PromptExperiment struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ExperimentId    string                 `protobuf:"bytes,1,opt,name=experiment_id,json=experimentId,proto3" json:"experiment_id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Variants        []*PromptVariant       `protobuf:"bytes,3,rep,name=variants,proto3" json:"variants,omitempty"`
+	SuccessOutcomes []string               `protobuf:"bytes,4,rep,name=success_outcomes,json=successOutcomes,proto3" json:"success_outcomes,omitempty"` // Outcome labels counted as successes; "success" if empty
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PromptExperiment) Reset() {
+	*x = PromptExperiment{}
 	mi := &file_proto_treestore_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StoreDocumentRequest) String() string {
+func (x *PromptExperiment) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StoreDocumentRequest) ProtoMessage() {}
+func (*PromptExperiment) ProtoMessage() {}
 
-func (x *StoreDocumentRequest) ProtoReflect() protoreflect.Message {
+func (x *PromptExperiment) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_treestore_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -991,47 +1083,69 @@ func (x *StoreDocumentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StoreDocumentRequest.ProtoReflect.Descriptor instead.
-func (*StoreDocumentRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use PromptExperiment.ProtoReflect.Descriptor instead.
+func (*PromptExperiment) Descriptor() ([]byte, []int) {
 	return file_proto_treestore_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *StoreDocumentRequest) GetDocument() *Document {
+func (x *PromptExperiment) GetExperimentId() string {
 	if x != nil {
-		return x.Document
+		return x.ExperimentId
+	}
+	return ""
+}
+
+func (x *PromptExperiment) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PromptExperiment) GetVariants() []*PromptVariant {
+	if x != nil {
+		return x.Variants
 	}
 	return nil
 }
 
-func (x *StoreDocumentRequest) GetNodes() []*Node {
+func (x *PromptExperiment) GetSuccessOutcomes() []string {
 	if x != nil {
-		return x.Nodes
+		return x.SuccessOutcomes
 	}
 	return nil
 }
 
-type StoreDocumentResponse struct {
+func (x *PromptExperiment) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type PromptVariant struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	PromptId      string                 `protobuf:"bytes,2,opt,name=prompt_id,json=promptId,proto3" json:"prompt_id,omitempty"`
+	Weight        int32                  `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"` // Traffic buckets, out of the sum of the experiment's weights
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StoreDocumentResponse) Reset() {
-	*x = StoreDocumentResponse{}
+func (x *PromptVariant) Reset() {
+	*x = PromptVariant{}
 	mi := &file_proto_treestore_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StoreDocumentResponse) String() string {
+func (x *PromptVariant) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StoreDocumentResponse) ProtoMessage() {}
+func (*PromptVariant) ProtoMessage() {}
 
-func (x *StoreDocumentResponse) ProtoReflect() protoreflect.Message {
+func (x *PromptVariant) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_treestore_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1043,46 +1157,59 @@ func (x *StoreDocumentResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StoreDocumentResponse.ProtoReflect.Descriptor instead.
-func (*StoreDocumentResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use PromptVariant.ProtoReflect.Descriptor instead.
+func (*PromptVariant) Descriptor() ([]byte, []int) {
 	return file_proto_treestore_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *StoreDocumentResponse) GetSuccess() bool {
+func (x *PromptVariant) GetName() string {
 	if x != nil {
-		return x.Success
+		return x.Name
 	}
-	return false
+	return ""
 }
 
-func (x *StoreDocumentResponse) GetMessage() string {
+func (x *PromptVariant) GetPromptId() string {
 	if x != nil {
-		return x.Message
+		return x.PromptId
 	}
 	return ""
 }
 
-type GetDocumentRequest struct {
+func (x *PromptVariant) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type VariantStats struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	Variant       string                 `protobuf:"bytes,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	PromptId      string                 `protobuf:"bytes,2,opt,name=prompt_id,json=promptId,proto3" json:"prompt_id,omitempty"`
+	Usages        int32                  `protobuf:"varint,3,opt,name=usages,proto3" json:"usages,omitempty"`
+	Labeled       int32                  `protobuf:"varint,4,opt,name=labeled,proto3" json:"labeled,omitempty"` // Usages with an outcome
+	Successes     int32                  `protobuf:"varint,5,opt,name=successes,proto3" json:"successes,omitempty"`
+	SuccessRate   float64                `protobuf:"fixed64,6,opt,name=success_rate,json=successRate,proto3" json:"success_rate,omitempty"`                                                 // Successes over labeled usages
+	Outcomes      map[string]int32       `protobuf:"bytes,7,rep,name=outcomes,proto3" json:"outcomes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // Usages by outcome label
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetDocumentRequest) Reset() {
-	*x = GetDocumentRequest{}
+func (x *VariantStats) Reset() {
+	*x = VariantStats{}
 	mi := &file_proto_treestore_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetDocumentRequest) String() string {
+func (x *VariantStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetDocumentRequest) ProtoMessage() {}
+func (*VariantStats) ProtoMessage() {}
 
-func (x *GetDocumentRequest) ProtoReflect() protoreflect.Message {
+func (x *VariantStats) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_treestore_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1094,40 +1221,88 @@ func (x *GetDocumentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetDocumentRequest.ProtoReflect.Descriptor instead.
-func (*GetDocumentRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use VariantStats.ProtoReflect.Descriptor instead.
+func (*VariantStats) Descriptor() ([]byte, []int) {
 	return file_proto_treestore_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *GetDocumentRequest) GetPolicyId() string {
+func (x *VariantStats) GetVariant() string {
 	if x != nil {
-		return x.PolicyId
+		return x.Variant
 	}
 	return ""
 }
 
-type GetDocumentResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Document      *Document              `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`
-	Nodes         []*Node                `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *VariantStats) GetPromptId() string {
+	if x != nil {
+		return x.PromptId
+	}
+	return ""
 }
 
-func (x *GetDocumentResponse) Reset() {
-	*x = GetDocumentResponse{}
+func (x *VariantStats) GetUsages() int32 {
+	if x != nil {
+		return x.Usages
+	}
+	return 0
+}
+
+func (x *VariantStats) GetLabeled() int32 {
+	if x != nil {
+		return x.Labeled
+	}
+	return 0
+}
+
+func (x *VariantStats) GetSuccesses() int32 {
+	if x != nil {
+		return x.Successes
+	}
+	return 0
+}
+
+func (x *VariantStats) GetSuccessRate() float64 {
+	if x != nil {
+		return x.SuccessRate
+	}
+	return 0
+}
+
+func (x *VariantStats) GetOutcomes() map[string]int32 {
+	if x != nil {
+		return x.Outcomes
+	}
+	return nil
+}
+
+type Message struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	MessageId      string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	ConversationId string                 `protobuf:"bytes,2,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Role           string                 `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"` // "user", "assistant", "system"
+	Content        string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	Timestamp      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Metadata       map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	EditedAt       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"` // Unset if never edited
+	Deleted        bool                   `protobuf:"varint,8,opt,name=deleted,proto3" json:"deleted,omitempty"`                  // Tombstone: content is cleared
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
 	mi := &file_proto_treestore_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetDocumentResponse) String() string {
+func (x *Message) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetDocumentResponse) ProtoMessage() {}
+func (*Message) ProtoMessage() {}
 
-func (x *GetDocumentResponse) ProtoReflect() protoreflect.Message {
+func (x *Message) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_treestore_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1139,92 +1314,97 @@ func (x *GetDocumentResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetDocumentResponse.ProtoReflect.Descriptor instead.
-func (*GetDocumentResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
 	return file_proto_treestore_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *GetDocumentResponse) GetDocument() *Document {
+func (x *Message) GetMessageId() string {
 	if x != nil {
-		return x.Document
+		return x.MessageId
 	}
-	return nil
+	return ""
 }
 
-func (x *GetDocumentResponse) GetNodes() []*Node {
+func (x *Message) GetConversationId() string {
 	if x != nil {
-		return x.Nodes
+		return x.ConversationId
 	}
-	return nil
+	return ""
 }
 
-type DeleteDocumentRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Message) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
 }
 
-func (x *DeleteDocumentRequest) Reset() {
-	*x = DeleteDocumentRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[14]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *Message) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
 }
 
-func (x *DeleteDocumentRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Message) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
 }
 
-func (*DeleteDocumentRequest) ProtoMessage() {}
-
-func (x *DeleteDocumentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[14]
+func (x *Message) GetMetadata() map[string]string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Metadata
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use DeleteDocumentRequest.ProtoReflect.Descriptor instead.
-func (*DeleteDocumentRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{14}
+func (x *Message) GetEditedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EditedAt
+	}
+	return nil
 }
 
-func (x *DeleteDocumentRequest) GetPolicyId() string {
+func (x *Message) GetDeleted() bool {
 	if x != nil {
-		return x.PolicyId
+		return x.Deleted
 	}
-	return ""
+	return false
 }
 
-type DeleteDocumentResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type Conversation struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	UserId         string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Title          string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	StartedAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	LastMessageAt  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_message_at,json=lastMessageAt,proto3" json:"last_message_at,omitempty"`
+	MessageCount   int32                  `protobuf:"varint,6,opt,name=message_count,json=messageCount,proto3" json:"message_count,omitempty"`
+	Tags           []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
+	Metadata       map[string]string      `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Archived       bool                   `protobuf:"varint,9,opt,name=archived,proto3" json:"archived,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *DeleteDocumentResponse) Reset() {
-	*x = DeleteDocumentResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[15]
+func (x *Conversation) Reset() {
+	*x = Conversation{}
+	mi := &file_proto_treestore_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteDocumentResponse) String() string {
+func (x *Conversation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteDocumentResponse) ProtoMessage() {}
+func (*Conversation) ProtoMessage() {}
 
-func (x *DeleteDocumentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[15]
+func (x *Conversation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1235,47 +1415,156 @@ func (x *DeleteDocumentResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteDocumentResponse.ProtoReflect.Descriptor instead.
-func (*DeleteDocumentResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use Conversation.ProtoReflect.Descriptor instead.
+func (*Conversation) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *DeleteDocumentResponse) GetSuccess() bool {
+func (x *Conversation) GetConversationId() string {
 	if x != nil {
-		return x.Success
+		return x.ConversationId
 	}
-	return false
+	return ""
 }
 
-func (x *DeleteDocumentResponse) GetMessage() string {
+func (x *Conversation) GetUserId() string {
 	if x != nil {
-		return x.Message
+		return x.UserId
 	}
 	return ""
 }
 
-type GetNodeRequest struct {
+func (x *Conversation) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Conversation) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Conversation) GetLastMessageAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastMessageAt
+	}
+	return nil
+}
+
+func (x *Conversation) GetMessageCount() int32 {
+	if x != nil {
+		return x.MessageCount
+	}
+	return 0
+}
+
+func (x *Conversation) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Conversation) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Conversation) GetArchived() bool {
+	if x != nil {
+		return x.Archived
+	}
+	return false
+}
+
+type ConversationQuota struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	MaxConversations        int64                  `protobuf:"varint,1,opt,name=max_conversations,json=maxConversations,proto3" json:"max_conversations,omitempty"`                        // Conversations a user may have at once; 0 is unlimited
+	MaxMessages             int64                  `protobuf:"varint,2,opt,name=max_messages,json=maxMessages,proto3" json:"max_messages,omitempty"`                                       // Messages across a user's conversations; 0 is unlimited
+	MaxConversationMessages int64                  `protobuf:"varint,3,opt,name=max_conversation_messages,json=maxConversationMessages,proto3" json:"max_conversation_messages,omitempty"` // Messages in any one conversation; 0 is unlimited
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *ConversationQuota) Reset() {
+	*x = ConversationQuota{}
+	mi := &file_proto_treestore_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationQuota) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationQuota) ProtoMessage() {}
+
+func (x *ConversationQuota) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationQuota.ProtoReflect.Descriptor instead.
+func (*ConversationQuota) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ConversationQuota) GetMaxConversations() int64 {
+	if x != nil {
+		return x.MaxConversations
+	}
+	return 0
+}
+
+func (x *ConversationQuota) GetMaxMessages() int64 {
+	if x != nil {
+		return x.MaxMessages
+	}
+	return 0
+}
+
+func (x *ConversationQuota) GetMaxConversationMessages() int64 {
+	if x != nil {
+		return x.MaxConversationMessages
+	}
+	return 0
+}
+
+type StoreDocumentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
-	NodeId        string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Document      *Document              `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`
+	Nodes         []*Node                `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetNodeRequest) Reset() {
-	*x = GetNodeRequest{}
+func (x *StoreDocumentRequest) Reset() {
+	*x = StoreDocumentRequest{}
 	mi := &file_proto_treestore_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetNodeRequest) String() string {
+func (x *StoreDocumentRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetNodeRequest) ProtoMessage() {}
+func (*StoreDocumentRequest) ProtoMessage() {}
 
-func (x *GetNodeRequest) ProtoReflect() protoreflect.Message {
+func (x *StoreDocumentRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_treestore_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1287,47 +1576,162 @@ func (x *GetNodeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetNodeRequest.ProtoReflect.Descriptor instead.
-func (*GetNodeRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use StoreDocumentRequest.ProtoReflect.Descriptor instead.
+func (*StoreDocumentRequest) Descriptor() ([]byte, []int) {
 	return file_proto_treestore_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *GetNodeRequest) GetPolicyId() string {
+func (x *StoreDocumentRequest) GetDocument() *Document {
 	if x != nil {
-		return x.PolicyId
+		return x.Document
+	}
+	return nil
+}
+
+func (x *StoreDocumentRequest) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type StoreDocumentResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Success             bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message             string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	ReferencesExtracted int32                  `protobuf:"varint,3,opt,name=references_extracted,json=referencesExtracted,proto3" json:"references_extracted,omitempty"` // Cross-references found in node text, with -extract-references
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *StoreDocumentResponse) Reset() {
+	*x = StoreDocumentResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreDocumentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreDocumentResponse) ProtoMessage() {}
+
+func (x *StoreDocumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreDocumentResponse.ProtoReflect.Descriptor instead.
+func (*StoreDocumentResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *StoreDocumentResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StoreDocumentResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
-func (x *GetNodeRequest) GetNodeId() string {
+func (x *StoreDocumentResponse) GetReferencesExtracted() int32 {
 	if x != nil {
-		return x.NodeId
+		return x.ReferencesExtracted
+	}
+	return 0
+}
+
+type GetDocumentRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	// Node fields to return, by name (e.g. "title", "page_start"); empty returns
+	// every field. node_id, policy_id and parent_id are always returned.
+	Fields        []string `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDocumentRequest) Reset() {
+	*x = GetDocumentRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDocumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDocumentRequest) ProtoMessage() {}
+
+func (x *GetDocumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDocumentRequest.ProtoReflect.Descriptor instead.
+func (*GetDocumentRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetDocumentRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
 	}
 	return ""
 }
 
-type GetNodeResponse struct {
+func (x *GetDocumentRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type GetDocumentResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Document      *Document              `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`
+	Nodes         []*Node                `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetNodeResponse) Reset() {
-	*x = GetNodeResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[17]
+func (x *GetDocumentResponse) Reset() {
+	*x = GetDocumentResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetNodeResponse) String() string {
+func (x *GetDocumentResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetNodeResponse) ProtoMessage() {}
+func (*GetDocumentResponse) ProtoMessage() {}
 
-func (x *GetNodeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[17]
+func (x *GetDocumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1338,92 +1742,8713 @@ func (x *GetNodeResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetNodeResponse.ProtoReflect.Descriptor instead.
-func (*GetNodeResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use GetDocumentResponse.ProtoReflect.Descriptor instead.
+func (*GetDocumentResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *GetNodeResponse) GetNode() *Node {
+func (x *GetDocumentResponse) GetDocument() *Document {
 	if x != nil {
-		return x.Node
+		return x.Document
+	}
+	return nil
+}
+
+func (x *GetDocumentResponse) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type DeleteDocumentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDocumentRequest) Reset() {
+	*x = DeleteDocumentRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDocumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDocumentRequest) ProtoMessage() {}
+
+func (x *DeleteDocumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDocumentRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDocumentRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DeleteDocumentRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+type DeleteDocumentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDocumentResponse) Reset() {
+	*x = DeleteDocumentResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDocumentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDocumentResponse) ProtoMessage() {}
+
+func (x *DeleteDocumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDocumentResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDocumentResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeleteDocumentResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeleteDocumentResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Copies a policy's node tree into a policy without nodes, under new node IDs
+type CloneDocumentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SrcPolicyId   string                 `protobuf:"bytes,1,opt,name=src_policy_id,json=srcPolicyId,proto3" json:"src_policy_id,omitempty"`
+	SrcVersionId  string                 `protobuf:"bytes,2,opt,name=src_version_id,json=srcVersionId,proto3" json:"src_version_id,omitempty"` // Optional; must be the source's latest version
+	DstPolicyId   string                 `protobuf:"bytes,3,opt,name=dst_policy_id,json=dstPolicyId,proto3" json:"dst_policy_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloneDocumentRequest) Reset() {
+	*x = CloneDocumentRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloneDocumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloneDocumentRequest) ProtoMessage() {}
+
+func (x *CloneDocumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloneDocumentRequest.ProtoReflect.Descriptor instead.
+func (*CloneDocumentRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CloneDocumentRequest) GetSrcPolicyId() string {
+	if x != nil {
+		return x.SrcPolicyId
+	}
+	return ""
+}
+
+func (x *CloneDocumentRequest) GetSrcVersionId() string {
+	if x != nil {
+		return x.SrcVersionId
+	}
+	return ""
+}
+
+func (x *CloneDocumentRequest) GetDstPolicyId() string {
+	if x != nil {
+		return x.DstPolicyId
+	}
+	return ""
+}
+
+type CloneDocumentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RootNodeIds   []string               `protobuf:"bytes,1,rep,name=root_node_ids,json=rootNodeIds,proto3" json:"root_node_ids,omitempty"`
+	NodeIdMap     map[string]string      `protobuf:"bytes,2,rep,name=node_id_map,json=nodeIdMap,proto3" json:"node_id_map,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // New node ID by source node ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloneDocumentResponse) Reset() {
+	*x = CloneDocumentResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloneDocumentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloneDocumentResponse) ProtoMessage() {}
+
+func (x *CloneDocumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloneDocumentResponse.ProtoReflect.Descriptor instead.
+func (*CloneDocumentResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CloneDocumentResponse) GetRootNodeIds() []string {
+	if x != nil {
+		return x.RootNodeIds
+	}
+	return nil
+}
+
+func (x *CloneDocumentResponse) GetNodeIdMap() map[string]string {
+	if x != nil {
+		return x.NodeIdMap
+	}
+	return nil
+}
+
+// Renumbers section paths from the tree's parent links
+type RecomputeSectionPathsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	DryRun        bool                   `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Only report mismatches
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecomputeSectionPathsRequest) Reset() {
+	*x = RecomputeSectionPathsRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecomputeSectionPathsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecomputeSectionPathsRequest) ProtoMessage() {}
+
+func (x *RecomputeSectionPathsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecomputeSectionPathsRequest.ProtoReflect.Descriptor instead.
+func (*RecomputeSectionPathsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RecomputeSectionPathsRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *RecomputeSectionPathsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type SectionPathChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Stored        string                 `protobuf:"bytes,2,opt,name=stored,proto3" json:"stored,omitempty"`
+	Expected      string                 `protobuf:"bytes,3,opt,name=expected,proto3" json:"expected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SectionPathChange) Reset() {
+	*x = SectionPathChange{}
+	mi := &file_proto_treestore_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SectionPathChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SectionPathChange) ProtoMessage() {}
+
+func (x *SectionPathChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SectionPathChange.ProtoReflect.Descriptor instead.
+func (*SectionPathChange) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SectionPathChange) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *SectionPathChange) GetStored() string {
+	if x != nil {
+		return x.Stored
+	}
+	return ""
+}
+
+func (x *SectionPathChange) GetExpected() string {
+	if x != nil {
+		return x.Expected
+	}
+	return ""
+}
+
+type RecomputeSectionPathsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodesChecked  int32                  `protobuf:"varint,1,opt,name=nodes_checked,json=nodesChecked,proto3" json:"nodes_checked,omitempty"`
+	Changes       []*SectionPathChange   `protobuf:"bytes,2,rep,name=changes,proto3" json:"changes,omitempty"` // In tree order
+	Applied       bool                   `protobuf:"varint,3,opt,name=applied,proto3" json:"applied,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecomputeSectionPathsResponse) Reset() {
+	*x = RecomputeSectionPathsResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecomputeSectionPathsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecomputeSectionPathsResponse) ProtoMessage() {}
+
+func (x *RecomputeSectionPathsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecomputeSectionPathsResponse.ProtoReflect.Descriptor instead.
+func (*RecomputeSectionPathsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RecomputeSectionPathsResponse) GetNodesChecked() int32 {
+	if x != nil {
+		return x.NodesChecked
+	}
+	return 0
+}
+
+func (x *RecomputeSectionPathsResponse) GetChanges() []*SectionPathChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *RecomputeSectionPathsResponse) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+// Checks a policy's tree for structural damage without changing it
+type ValidateDocumentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateDocumentRequest) Reset() {
+	*x = ValidateDocumentRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateDocumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateDocumentRequest) ProtoMessage() {}
+
+func (x *ValidateDocumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateDocumentRequest.ProtoReflect.Descriptor instead.
+func (*ValidateDocumentRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ValidateDocumentRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+type DocumentIssue struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kind  string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"` // orphan, cycle, duplicate_child, stale_child, missing_child,
+	// depth_mismatch, inverted_pages, overlapping_pages, pages_outside_parent
+	NodeId        string `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Detail        string `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DocumentIssue) Reset() {
+	*x = DocumentIssue{}
+	mi := &file_proto_treestore_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DocumentIssue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocumentIssue) ProtoMessage() {}
+
+func (x *DocumentIssue) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocumentIssue.ProtoReflect.Descriptor instead.
+func (*DocumentIssue) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *DocumentIssue) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *DocumentIssue) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *DocumentIssue) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+type ValidateDocumentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	NodesChecked  int32                  `protobuf:"varint,2,opt,name=nodes_checked,json=nodesChecked,proto3" json:"nodes_checked,omitempty"`
+	Issues        []*DocumentIssue       `protobuf:"bytes,3,rep,name=issues,proto3" json:"issues,omitempty"` // Ordered by node ID, then kind
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateDocumentResponse) Reset() {
+	*x = ValidateDocumentResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateDocumentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateDocumentResponse) ProtoMessage() {}
+
+func (x *ValidateDocumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateDocumentResponse.ProtoReflect.Descriptor instead.
+func (*ValidateDocumentResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ValidateDocumentResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateDocumentResponse) GetNodesChecked() int32 {
+	if x != nil {
+		return x.NodesChecked
+	}
+	return 0
+}
+
+func (x *ValidateDocumentResponse) GetIssues() []*DocumentIssue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+type FindDuplicateNodesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`           // Required unless cross_policy is set
+	CrossPolicy   bool                   `protobuf:"varint,2,opt,name=cross_policy,json=crossPolicy,proto3" json:"cross_policy,omitempty"` // Groups spanning policies, with one of policy_id's nodes if it is set
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindDuplicateNodesRequest) Reset() {
+	*x = FindDuplicateNodesRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindDuplicateNodesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindDuplicateNodesRequest) ProtoMessage() {}
+
+func (x *FindDuplicateNodesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindDuplicateNodesRequest.ProtoReflect.Descriptor instead.
+func (*FindDuplicateNodesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *FindDuplicateNodesRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *FindDuplicateNodesRequest) GetCrossPolicy() bool {
+	if x != nil {
+		return x.CrossPolicy
+	}
+	return false
+}
+
+type NodeRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	NodeId        string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeRef) Reset() {
+	*x = NodeRef{}
+	mi := &file_proto_treestore_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeRef) ProtoMessage() {}
+
+func (x *NodeRef) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeRef.ProtoReflect.Descriptor instead.
+func (*NodeRef) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *NodeRef) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *NodeRef) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+type DuplicateGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Checksum      string                 `protobuf:"bytes,1,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	Nodes         []*NodeRef             `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"` // Ordered by policy, then node ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DuplicateGroup) Reset() {
+	*x = DuplicateGroup{}
+	mi := &file_proto_treestore_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DuplicateGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DuplicateGroup) ProtoMessage() {}
+
+func (x *DuplicateGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DuplicateGroup.ProtoReflect.Descriptor instead.
+func (*DuplicateGroup) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DuplicateGroup) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *DuplicateGroup) GetNodes() []*NodeRef {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type FindDuplicateNodesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*DuplicateGroup      `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"` // Nodes with the same text, ordered by checksum
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindDuplicateNodesResponse) Reset() {
+	*x = FindDuplicateNodesResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindDuplicateNodesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindDuplicateNodesResponse) ProtoMessage() {}
+
+func (x *FindDuplicateNodesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindDuplicateNodesResponse.ProtoReflect.Descriptor instead.
+func (*FindDuplicateNodesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *FindDuplicateNodesResponse) GetGroups() []*DuplicateGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type RefreshSummariesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	NodeIds       []string               `protobuf:"bytes,2,rep,name=node_ids,json=nodeIds,proto3" json:"node_ids,omitempty"`             // Only these nodes; empty selects every node
+	SectionPath   string                 `protobuf:"bytes,3,opt,name=section_path,json=sectionPath,proto3" json:"section_path,omitempty"` // Only this section and those under it
+	Force         bool                   `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`                               // Refresh current summaries too, not just stale ones
+	BatchSize     int32                  `protobuf:"varint,5,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`      // Nodes per summarizer call; 0 uses the default
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshSummariesRequest) Reset() {
+	*x = RefreshSummariesRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshSummariesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshSummariesRequest) ProtoMessage() {}
+
+func (x *RefreshSummariesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshSummariesRequest.ProtoReflect.Descriptor instead.
+func (*RefreshSummariesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RefreshSummariesRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *RefreshSummariesRequest) GetNodeIds() []string {
+	if x != nil {
+		return x.NodeIds
+	}
+	return nil
+}
+
+func (x *RefreshSummariesRequest) GetSectionPath() string {
+	if x != nil {
+		return x.SectionPath
+	}
+	return ""
+}
+
+func (x *RefreshSummariesRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *RefreshSummariesRequest) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+// RefreshSummariesProgress is sent after each batch is written
+type RefreshSummariesProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`     // Nodes selected
+	Done          int32                  `protobuf:"varint,2,opt,name=done,proto3" json:"done,omitempty"`       // Nodes handled so far
+	Updated       int32                  `protobuf:"varint,3,opt,name=updated,proto3" json:"updated,omitempty"` // Summaries written
+	Skipped       int32                  `protobuf:"varint,4,opt,name=skipped,proto3" json:"skipped,omitempty"` // Nodes changed while being summarized
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshSummariesProgress) Reset() {
+	*x = RefreshSummariesProgress{}
+	mi := &file_proto_treestore_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshSummariesProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshSummariesProgress) ProtoMessage() {}
+
+func (x *RefreshSummariesProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshSummariesProgress.ProtoReflect.Descriptor instead.
+func (*RefreshSummariesProgress) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *RefreshSummariesProgress) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *RefreshSummariesProgress) GetDone() int32 {
+	if x != nil {
+		return x.Done
+	}
+	return 0
+}
+
+func (x *RefreshSummariesProgress) GetUpdated() int32 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *RefreshSummariesProgress) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+type SummarizeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*Node                `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"` // Nodes with their text
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SummarizeRequest) Reset() {
+	*x = SummarizeRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SummarizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SummarizeRequest) ProtoMessage() {}
+
+func (x *SummarizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SummarizeRequest.ProtoReflect.Descriptor instead.
+func (*SummarizeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *SummarizeRequest) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type SummarizeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Summaries     []string               `protobuf:"bytes,1,rep,name=summaries,proto3" json:"summaries,omitempty"` // One per node, in order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SummarizeResponse) Reset() {
+	*x = SummarizeResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SummarizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SummarizeResponse) ProtoMessage() {}
+
+func (x *SummarizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SummarizeResponse.ProtoReflect.Descriptor instead.
+func (*SummarizeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SummarizeResponse) GetSummaries() []string {
+	if x != nil {
+		return x.Summaries
+	}
+	return nil
+}
+
+type GetNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	NodeId        string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeRequest) Reset() {
+	*x = GetNodeRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeRequest) ProtoMessage() {}
+
+func (x *GetNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeRequest.ProtoReflect.Descriptor instead.
+func (*GetNodeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *GetNodeRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *GetNodeRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+type GetNodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeResponse) Reset() {
+	*x = GetNodeResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeResponse) ProtoMessage() {}
+
+func (x *GetNodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeResponse.ProtoReflect.Descriptor instead.
+func (*GetNodeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GetNodeResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+// Replaces a node only if node.version still matches the stored version;
+// fails with ABORTED otherwise
+type UpdateNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateNodeRequest) Reset() {
+	*x = UpdateNodeRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNodeRequest) ProtoMessage() {}
+
+func (x *UpdateNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNodeRequest.ProtoReflect.Descriptor instead.
+func (*UpdateNodeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *UpdateNodeRequest) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+type UpdateNodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"` // As stored, with its new version
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateNodeResponse) Reset() {
+	*x = UpdateNodeResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateNodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNodeResponse) ProtoMessage() {}
+
+func (x *UpdateNodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNodeResponse.ProtoReflect.Descriptor instead.
+func (*UpdateNodeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *UpdateNodeResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+type GetChildrenRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	ParentId string                 `protobuf:"bytes,2,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // Empty for root children
+	// Node fields to return, by name (e.g. "title", "page_start"); empty returns
+	// every field. node_id, policy_id and parent_id are always returned.
+	Fields        []string `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetChildrenRequest) Reset() {
+	*x = GetChildrenRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChildrenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChildrenRequest) ProtoMessage() {}
+
+func (x *GetChildrenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChildrenRequest.ProtoReflect.Descriptor instead.
+func (*GetChildrenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *GetChildrenRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *GetChildrenRequest) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *GetChildrenRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type GetChildrenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Children      []*Node                `protobuf:"bytes,1,rep,name=children,proto3" json:"children,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetChildrenResponse) Reset() {
+	*x = GetChildrenResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChildrenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChildrenResponse) ProtoMessage() {}
+
+func (x *GetChildrenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChildrenResponse.ProtoReflect.Descriptor instead.
+func (*GetChildrenResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *GetChildrenResponse) GetChildren() []*Node {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+type GetSubtreeRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	NodeId   string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	MaxDepth int32                  `protobuf:"varint,3,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"` // 0 = unlimited
+	// Node fields to return, by name (e.g. "title", "page_start"); empty returns
+	// every field. node_id, policy_id and parent_id are always returned.
+	Fields []string `protobuf:"bytes,4,rep,name=fields,proto3" json:"fields,omitempty"`
+	// Instead of policy_id and node_id: the trees of every member policy of
+	// this collection from their roots, members in order
+	Collection    string `protobuf:"bytes,5,opt,name=collection,proto3" json:"collection,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSubtreeRequest) Reset() {
+	*x = GetSubtreeRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSubtreeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSubtreeRequest) ProtoMessage() {}
+
+func (x *GetSubtreeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSubtreeRequest.ProtoReflect.Descriptor instead.
+func (*GetSubtreeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *GetSubtreeRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *GetSubtreeRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *GetSubtreeRequest) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *GetSubtreeRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *GetSubtreeRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+type GetSubtreeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*Node                `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSubtreeResponse) Reset() {
+	*x = GetSubtreeResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSubtreeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSubtreeResponse) ProtoMessage() {}
+
+func (x *GetSubtreeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSubtreeResponse.ProtoReflect.Descriptor instead.
+func (*GetSubtreeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *GetSubtreeResponse) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type GetSubtreeWithinBudgetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	NodeId        string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	MaxTokens     int32                  `protobuf:"varint,3,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"` // Estimated tokens of titles, texts and summaries returned
+	MaxDepth      int32                  `protobuf:"varint,4,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`    // 0 = unlimited
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSubtreeWithinBudgetRequest) Reset() {
+	*x = GetSubtreeWithinBudgetRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSubtreeWithinBudgetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSubtreeWithinBudgetRequest) ProtoMessage() {}
+
+func (x *GetSubtreeWithinBudgetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSubtreeWithinBudgetRequest.ProtoReflect.Descriptor instead.
+func (*GetSubtreeWithinBudgetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *GetSubtreeWithinBudgetRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *GetSubtreeWithinBudgetRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *GetSubtreeWithinBudgetRequest) GetMaxTokens() int32 {
+	if x != nil {
+		return x.MaxTokens
+	}
+	return 0
+}
+
+func (x *GetSubtreeWithinBudgetRequest) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+type GetSubtreeWithinBudgetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*Node                `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`                                      // Level by level; each has its text or its summary, not both
+	SummarizedIds []string               `protobuf:"bytes,2,rep,name=summarized_ids,json=summarizedIds,proto3" json:"summarized_ids,omitempty"` // Nodes returned with their summary in place of their text
+	Depth         int32                  `protobuf:"varint,3,opt,name=depth,proto3" json:"depth,omitempty"`                                     // Deepest level returned, 0 for the node alone
+	TokenCount    int32                  `protobuf:"varint,4,opt,name=token_count,json=tokenCount,proto3" json:"token_count,omitempty"`
+	Truncated     bool                   `protobuf:"varint,5,opt,name=truncated,proto3" json:"truncated,omitempty"` // Levels were left out or text replaced by summaries
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSubtreeWithinBudgetResponse) Reset() {
+	*x = GetSubtreeWithinBudgetResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSubtreeWithinBudgetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSubtreeWithinBudgetResponse) ProtoMessage() {}
+
+func (x *GetSubtreeWithinBudgetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSubtreeWithinBudgetResponse.ProtoReflect.Descriptor instead.
+func (*GetSubtreeWithinBudgetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *GetSubtreeWithinBudgetResponse) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *GetSubtreeWithinBudgetResponse) GetSummarizedIds() []string {
+	if x != nil {
+		return x.SummarizedIds
+	}
+	return nil
+}
+
+func (x *GetSubtreeWithinBudgetResponse) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *GetSubtreeWithinBudgetResponse) GetTokenCount() int32 {
+	if x != nil {
+		return x.TokenCount
+	}
+	return 0
+}
+
+func (x *GetSubtreeWithinBudgetResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type GetAncestorPathRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	NodeId        string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAncestorPathRequest) Reset() {
+	*x = GetAncestorPathRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAncestorPathRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAncestorPathRequest) ProtoMessage() {}
+
+func (x *GetAncestorPathRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAncestorPathRequest.ProtoReflect.Descriptor instead.
+func (*GetAncestorPathRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *GetAncestorPathRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *GetAncestorPathRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+type GetAncestorPathResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ancestors     []*Node                `protobuf:"bytes,1,rep,name=ancestors,proto3" json:"ancestors,omitempty"` // From root to node
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAncestorPathResponse) Reset() {
+	*x = GetAncestorPathResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAncestorPathResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAncestorPathResponse) ProtoMessage() {}
+
+func (x *GetAncestorPathResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAncestorPathResponse.ProtoReflect.Descriptor instead.
+func (*GetAncestorPathResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *GetAncestorPathResponse) GetAncestors() []*Node {
+	if x != nil {
+		return x.Ancestors
+	}
+	return nil
+}
+
+// Paths of several nodes of a policy, such as search hits, read together
+type BatchGetAncestorPathsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	NodeIds  []string               `protobuf:"bytes,2,rep,name=node_ids,json=nodeIds,proto3" json:"node_ids,omitempty"`
+	// Node fields to return, by name (e.g. "title", "section_path"); empty returns
+	// every field. node_id, policy_id and parent_id are always returned.
+	Fields        []string `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetAncestorPathsRequest) Reset() {
+	*x = BatchGetAncestorPathsRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetAncestorPathsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetAncestorPathsRequest) ProtoMessage() {}
+
+func (x *BatchGetAncestorPathsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetAncestorPathsRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetAncestorPathsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *BatchGetAncestorPathsRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *BatchGetAncestorPathsRequest) GetNodeIds() []string {
+	if x != nil {
+		return x.NodeIds
+	}
+	return nil
+}
+
+func (x *BatchGetAncestorPathsRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type AncestorPath struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Ancestors     []*Node                `protobuf:"bytes,2,rep,name=ancestors,proto3" json:"ancestors,omitempty"` // From root to node
+	Found         bool                   `protobuf:"varint,3,opt,name=found,proto3" json:"found,omitempty"`        // False when the node or one of its ancestors does not exist
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AncestorPath) Reset() {
+	*x = AncestorPath{}
+	mi := &file_proto_treestore_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AncestorPath) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AncestorPath) ProtoMessage() {}
+
+func (x *AncestorPath) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AncestorPath.ProtoReflect.Descriptor instead.
+func (*AncestorPath) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *AncestorPath) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *AncestorPath) GetAncestors() []*Node {
+	if x != nil {
+		return x.Ancestors
+	}
+	return nil
+}
+
+func (x *AncestorPath) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+type BatchGetAncestorPathsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Paths         []*AncestorPath        `protobuf:"bytes,1,rep,name=paths,proto3" json:"paths,omitempty"` // Aligned with node_ids
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetAncestorPathsResponse) Reset() {
+	*x = BatchGetAncestorPathsResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetAncestorPathsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetAncestorPathsResponse) ProtoMessage() {}
+
+func (x *BatchGetAncestorPathsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetAncestorPathsResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetAncestorPathsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *BatchGetAncestorPathsResponse) GetPaths() []*AncestorPath {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+type GetSiblingsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	NodeId   string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	// Node fields to return, by name (e.g. "title", "page_start"); empty returns
+	// every field. node_id, policy_id and parent_id are always returned.
+	Fields        []string `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSiblingsRequest) Reset() {
+	*x = GetSiblingsRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSiblingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSiblingsRequest) ProtoMessage() {}
+
+func (x *GetSiblingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSiblingsRequest.ProtoReflect.Descriptor instead.
+func (*GetSiblingsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *GetSiblingsRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *GetSiblingsRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *GetSiblingsRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type GetSiblingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Siblings      []*Node                `protobuf:"bytes,1,rep,name=siblings,proto3" json:"siblings,omitempty"`  // The parent's children in document order, the node among them
+	Position      int32                  `protobuf:"varint,2,opt,name=position,proto3" json:"position,omitempty"` // Index of the node in siblings
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSiblingsResponse) Reset() {
+	*x = GetSiblingsResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSiblingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSiblingsResponse) ProtoMessage() {}
+
+func (x *GetSiblingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSiblingsResponse.ProtoReflect.Descriptor instead.
+func (*GetSiblingsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *GetSiblingsResponse) GetSiblings() []*Node {
+	if x != nil {
+		return x.Siblings
+	}
+	return nil
+}
+
+func (x *GetSiblingsResponse) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+// Steps from a node to the section before or after it in document order,
+// reading depth first
+type AdjacentSectionRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	NodeId   string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	// Node fields to return, by name (e.g. "title", "page_start"); empty returns
+	// every field. node_id, policy_id and parent_id are always returned.
+	Fields        []string `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjacentSectionRequest) Reset() {
+	*x = AdjacentSectionRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjacentSectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjacentSectionRequest) ProtoMessage() {}
+
+func (x *AdjacentSectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjacentSectionRequest.ProtoReflect.Descriptor instead.
+func (*AdjacentSectionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *AdjacentSectionRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *AdjacentSectionRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *AdjacentSectionRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type AdjacentSectionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"` // Unset at the start or end of the document
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjacentSectionResponse) Reset() {
+	*x = AdjacentSectionResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjacentSectionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjacentSectionResponse) ProtoMessage() {}
+
+func (x *AdjacentSectionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjacentSectionResponse.ProtoReflect.Descriptor instead.
+func (*AdjacentSectionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *AdjacentSectionResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+// Streams every node of a policy depth first in document order: a section,
+// its subsections, then the section after it
+type GetReadingOrderRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	// Node fields to return, by name (e.g. "title", "page_start"); empty returns
+	// every field. node_id, policy_id and parent_id are always returned.
+	Fields        []string `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReadingOrderRequest) Reset() {
+	*x = GetReadingOrderRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReadingOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReadingOrderRequest) ProtoMessage() {}
+
+func (x *GetReadingOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReadingOrderRequest.ProtoReflect.Descriptor instead.
+func (*GetReadingOrderRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *GetReadingOrderRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *GetReadingOrderRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type GetContextWindowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	NodeId        string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	TokenBudget   int32                  `protobuf:"varint,3,opt,name=token_budget,json=tokenBudget,proto3" json:"token_budget,omitempty"` // 0 for unlimited; estimated at ~4 bytes per token
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetContextWindowRequest) Reset() {
+	*x = GetContextWindowRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetContextWindowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetContextWindowRequest) ProtoMessage() {}
+
+func (x *GetContextWindowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetContextWindowRequest.ProtoReflect.Descriptor instead.
+func (*GetContextWindowRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *GetContextWindowRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *GetContextWindowRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *GetContextWindowRequest) GetTokenBudget() int32 {
+	if x != nil {
+		return x.TokenBudget
+	}
+	return 0
+}
+
+// Outline of a node related to the context window target
+type ContextEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Summary       string                 `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"` // Empty for ancestors
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContextEntry) Reset() {
+	*x = ContextEntry{}
+	mi := &file_proto_treestore_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContextEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContextEntry) ProtoMessage() {}
+
+func (x *ContextEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContextEntry.ProtoReflect.Descriptor instead.
+func (*ContextEntry) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *ContextEntry) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *ContextEntry) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ContextEntry) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+type GetContextWindowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`           // Text trimmed to the token budget
+	Ancestors     []*ContextEntry        `protobuf:"bytes,2,rep,name=ancestors,proto3" json:"ancestors,omitempty"` // From root to parent
+	Siblings      []*ContextEntry        `protobuf:"bytes,3,rep,name=siblings,proto3" json:"siblings,omitempty"`
+	Children      []*ContextEntry        `protobuf:"bytes,4,rep,name=children,proto3" json:"children,omitempty"`
+	TokenCount    int32                  `protobuf:"varint,5,opt,name=token_count,json=tokenCount,proto3" json:"token_count,omitempty"`
+	Truncated     bool                   `protobuf:"varint,6,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetContextWindowResponse) Reset() {
+	*x = GetContextWindowResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetContextWindowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetContextWindowResponse) ProtoMessage() {}
+
+func (x *GetContextWindowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetContextWindowResponse.ProtoReflect.Descriptor instead.
+func (*GetContextWindowResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *GetContextWindowResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *GetContextWindowResponse) GetAncestors() []*ContextEntry {
+	if x != nil {
+		return x.Ancestors
+	}
+	return nil
+}
+
+func (x *GetContextWindowResponse) GetSiblings() []*ContextEntry {
+	if x != nil {
+		return x.Siblings
+	}
+	return nil
+}
+
+func (x *GetContextWindowResponse) GetChildren() []*ContextEntry {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+func (x *GetContextWindowResponse) GetTokenCount() int32 {
+	if x != nil {
+		return x.TokenCount
+	}
+	return 0
+}
+
+func (x *GetContextWindowResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type ExportGraphRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId       string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	Graph          string                 `protobuf:"bytes,2,opt,name=graph,proto3" json:"graph,omitempty"`                                            // "tree" (default) or "references"
+	Format         string                 `protobuf:"bytes,3,opt,name=format,proto3" json:"format,omitempty"`                                          // "dot" (default) or "mermaid"
+	RootNodeId     string                 `protobuf:"bytes,4,opt,name=root_node_id,json=rootNodeId,proto3" json:"root_node_id,omitempty"`              // Tree: subtree to draw (default: the document root); references: start from this node only
+	MaxDepth       int32                  `protobuf:"varint,5,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`                     // Tree levels below the root, or reference hops from the start nodes; 0 = unlimited
+	Label          string                 `protobuf:"bytes,6,opt,name=label,proto3" json:"label,omitempty"`                                            // Node labels: "title" (default), "section_path" or "node_id"
+	MaxLabelLength int32                  `protobuf:"varint,7,opt,name=max_label_length,json=maxLabelLength,proto3" json:"max_label_length,omitempty"` // Longer labels are cut with "..."; 0 = no limit
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExportGraphRequest) Reset() {
+	*x = ExportGraphRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportGraphRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportGraphRequest) ProtoMessage() {}
+
+func (x *ExportGraphRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportGraphRequest.ProtoReflect.Descriptor instead.
+func (*ExportGraphRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *ExportGraphRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *ExportGraphRequest) GetGraph() string {
+	if x != nil {
+		return x.Graph
+	}
+	return ""
+}
+
+func (x *ExportGraphRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ExportGraphRequest) GetRootNodeId() string {
+	if x != nil {
+		return x.RootNodeId
+	}
+	return ""
+}
+
+func (x *ExportGraphRequest) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *ExportGraphRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *ExportGraphRequest) GetMaxLabelLength() int32 {
+	if x != nil {
+		return x.MaxLabelLength
+	}
+	return 0
+}
+
+type ExportGraphResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"` // DOT or Mermaid source
+	NodeCount     int32                  `protobuf:"varint,2,opt,name=node_count,json=nodeCount,proto3" json:"node_count,omitempty"`
+	EdgeCount     int32                  `protobuf:"varint,3,opt,name=edge_count,json=edgeCount,proto3" json:"edge_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportGraphResponse) Reset() {
+	*x = ExportGraphResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportGraphResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportGraphResponse) ProtoMessage() {}
+
+func (x *ExportGraphResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportGraphResponse.ProtoReflect.Descriptor instead.
+func (*ExportGraphResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *ExportGraphResponse) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *ExportGraphResponse) GetNodeCount() int32 {
+	if x != nil {
+		return x.NodeCount
+	}
+	return 0
+}
+
+func (x *ExportGraphResponse) GetEdgeCount() int32 {
+	if x != nil {
+		return x.EdgeCount
+	}
+	return 0
+}
+
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Filter        *SearchFilter          `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`                                     // Optional structural filters
+	SnippetLength int32                  `protobuf:"varint,5,opt,name=snippet_length,json=snippetLength,proto3" json:"snippet_length,omitempty"` // Snippet size in bytes (default 160)
+	Collection    string                 `protobuf:"bytes,6,opt,name=collection,proto3" json:"collection,omitempty"`                             // Instead of policy_id: searches every member, hits merged by score
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *SearchRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetFilter() *SearchFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetSnippetLength() int32 {
+	if x != nil {
+		return x.SnippetLength
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+// Restricts search hits; unset fields do not filter
+type SearchFilter struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	PageFrom          int32                  `protobuf:"varint,1,opt,name=page_from,json=pageFrom,proto3" json:"page_from,omitempty"` // Hits must end on or after this page
+	PageTo            int32                  `protobuf:"varint,2,opt,name=page_to,json=pageTo,proto3" json:"page_to,omitempty"`       // Hits must start on or before this page
+	MaxDepth          *int32                 `protobuf:"varint,3,opt,name=max_depth,json=maxDepth,proto3,oneof" json:"max_depth,omitempty"`
+	SectionPathPrefix string                 `protobuf:"bytes,4,opt,name=section_path_prefix,json=sectionPathPrefix,proto3" json:"section_path_prefix,omitempty"`                              // e.g., "3."
+	Metadata          map[string]string      `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Node metadata that must match exactly
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SearchFilter) Reset() {
+	*x = SearchFilter{}
+	mi := &file_proto_treestore_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchFilter) ProtoMessage() {}
+
+func (x *SearchFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchFilter.ProtoReflect.Descriptor instead.
+func (*SearchFilter) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *SearchFilter) GetPageFrom() int32 {
+	if x != nil {
+		return x.PageFrom
+	}
+	return 0
+}
+
+func (x *SearchFilter) GetPageTo() int32 {
+	if x != nil {
+		return x.PageTo
+	}
+	return 0
+}
+
+func (x *SearchFilter) GetMaxDepth() int32 {
+	if x != nil && x.MaxDepth != nil {
+		return *x.MaxDepth
+	}
+	return 0
+}
+
+func (x *SearchFilter) GetSectionPathPrefix() string {
+	if x != nil {
+		return x.SectionPathPrefix
+	}
+	return ""
+}
+
+func (x *SearchFilter) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *SearchResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Score         float32                `protobuf:"fixed32,2,opt,name=score,proto3" json:"score,omitempty"`
+	Snippet       string                 `protobuf:"bytes,3,opt,name=snippet,proto3" json:"snippet,omitempty"`       // Text window around the first match
+	Highlights    []*Highlight           `protobuf:"bytes,4,rep,name=highlights,proto3" json:"highlights,omitempty"` // Matched terms within the snippet
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_proto_treestore_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *SearchResult) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *SearchResult) GetScore() float32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SearchResult) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+func (x *SearchResult) GetHighlights() []*Highlight {
+	if x != nil {
+		return x.Highlights
+	}
+	return nil
+}
+
+// Byte range [start, end) of a matched term inside a snippet
+type Highlight struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int32                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Highlight) Reset() {
+	*x = Highlight{}
+	mi := &file_proto_treestore_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Highlight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Highlight) ProtoMessage() {}
+
+func (x *Highlight) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Highlight.ProtoReflect.Descriptor instead.
+func (*Highlight) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *Highlight) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Highlight) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+// Retrieval in the shape LangChain and LlamaIndex retrievers use
+type RetrieveRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Query string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	TopK  int32                  `protobuf:"varint,2,opt,name=top_k,json=topK,proto3" json:"top_k,omitempty"` // Documents returned (default 4)
+	// policy_id, collection, section_path_prefix, page_from, page_to and
+	// max_depth filter structurally; any other key must match node metadata
+	Filter        map[string]string `protobuf:"bytes,3,rep,name=filter,proto3" json:"filter,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetrieveRequest) Reset() {
+	*x = RetrieveRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetrieveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetrieveRequest) ProtoMessage() {}
+
+func (x *RetrieveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetrieveRequest.ProtoReflect.Descriptor instead.
+func (*RetrieveRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *RetrieveRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *RetrieveRequest) GetTopK() int32 {
+	if x != nil {
+		return x.TopK
+	}
+	return 0
+}
+
+func (x *RetrieveRequest) GetFilter() map[string]string {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type RetrieveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documents     []*RetrievedDocument   `protobuf:"bytes,1,rep,name=documents,proto3" json:"documents,omitempty"` // Best first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetrieveResponse) Reset() {
+	*x = RetrieveResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetrieveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetrieveResponse) ProtoMessage() {}
+
+func (x *RetrieveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetrieveResponse.ProtoReflect.Descriptor instead.
+func (*RetrieveResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *RetrieveResponse) GetDocuments() []*RetrievedDocument {
+	if x != nil {
+		return x.Documents
+	}
+	return nil
+}
+
+type RetrievedDocument struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                                                                       // "policy_id/node_id"
+	PageContent   string                 `protobuf:"bytes,2,opt,name=page_content,json=pageContent,proto3" json:"page_content,omitempty"`                                                  // Node text, or its summary or title when it has none
+	Metadata      map[string]string      `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // policy_id, node_id, title, section_path, page_start, page_end and source
+	Score         float32                `protobuf:"fixed32,4,opt,name=score,proto3" json:"score,omitempty"`                                                                               // BM25 score
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetrievedDocument) Reset() {
+	*x = RetrievedDocument{}
+	mi := &file_proto_treestore_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetrievedDocument) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetrievedDocument) ProtoMessage() {}
+
+func (x *RetrievedDocument) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetrievedDocument.ProtoReflect.Descriptor instead.
+func (*RetrievedDocument) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *RetrievedDocument) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RetrievedDocument) GetPageContent() string {
+	if x != nil {
+		return x.PageContent
+	}
+	return ""
+}
+
+func (x *RetrievedDocument) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *RetrievedDocument) GetScore() float32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// Searches every policy; results are grouped by policy
+type GlobalSearchRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Query          string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	PerPolicyLimit int32                  `protobuf:"varint,2,opt,name=per_policy_limit,json=perPolicyLimit,proto3" json:"per_policy_limit,omitempty"` // Top-k hits per policy (default 10)
+	MaxPolicies    int32                  `protobuf:"varint,3,opt,name=max_policies,json=maxPolicies,proto3" json:"max_policies,omitempty"`            // 0 returns every matching policy
+	SnippetLength  int32                  `protobuf:"varint,4,opt,name=snippet_length,json=snippetLength,proto3" json:"snippet_length,omitempty"`      // Snippet size in bytes (default 160)
+	Collection     string                 `protobuf:"bytes,5,opt,name=collection,proto3" json:"collection,omitempty"`                                  // Searches only the collection's member policies
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GlobalSearchRequest) Reset() {
+	*x = GlobalSearchRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GlobalSearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GlobalSearchRequest) ProtoMessage() {}
+
+func (x *GlobalSearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GlobalSearchRequest.ProtoReflect.Descriptor instead.
+func (*GlobalSearchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *GlobalSearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *GlobalSearchRequest) GetPerPolicyLimit() int32 {
+	if x != nil {
+		return x.PerPolicyLimit
+	}
+	return 0
+}
+
+func (x *GlobalSearchRequest) GetMaxPolicies() int32 {
+	if x != nil {
+		return x.MaxPolicies
+	}
+	return 0
+}
+
+func (x *GlobalSearchRequest) GetSnippetLength() int32 {
+	if x != nil {
+		return x.SnippetLength
+	}
+	return 0
+}
+
+func (x *GlobalSearchRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+type GlobalSearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Policies      []*PolicySearchResults `protobuf:"bytes,1,rep,name=policies,proto3" json:"policies,omitempty"` // Ordered by best score
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GlobalSearchResponse) Reset() {
+	*x = GlobalSearchResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GlobalSearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GlobalSearchResponse) ProtoMessage() {}
+
+func (x *GlobalSearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GlobalSearchResponse.ProtoReflect.Descriptor instead.
+func (*GlobalSearchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *GlobalSearchResponse) GetPolicies() []*PolicySearchResults {
+	if x != nil {
+		return x.Policies
+	}
+	return nil
+}
+
+type PolicySearchResults struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	Results       []*SearchResult        `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	TotalHits     int32                  `protobuf:"varint,3,opt,name=total_hits,json=totalHits,proto3" json:"total_hits,omitempty"` // Matching nodes before the per-policy limit
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PolicySearchResults) Reset() {
+	*x = PolicySearchResults{}
+	mi := &file_proto_treestore_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PolicySearchResults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolicySearchResults) ProtoMessage() {}
+
+func (x *PolicySearchResults) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PolicySearchResults.ProtoReflect.Descriptor instead.
+func (*PolicySearchResults) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *PolicySearchResults) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *PolicySearchResults) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *PolicySearchResults) GetTotalHits() int32 {
+	if x != nil {
+		return x.TotalHits
+	}
+	return 0
+}
+
+type JoinNodesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,2,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Required node metadata
+	ReferencesTo  string                 `protobuf:"bytes,3,opt,name=references_to,json=referencesTo,proto3" json:"references_to,omitempty"`                                               // Nodes must reference this policy
+	ReferencedBy  string                 `protobuf:"bytes,4,opt,name=referenced_by,json=referencedBy,proto3" json:"referenced_by,omitempty"`                                               // Nodes must be referenced from this policy
+	ReferenceType string                 `protobuf:"bytes,5,opt,name=reference_type,json=referenceType,proto3" json:"reference_type,omitempty"`                                            // Restricts reference conditions to one type
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinNodesRequest) Reset() {
+	*x = JoinNodesRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinNodesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinNodesRequest) ProtoMessage() {}
+
+func (x *JoinNodesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinNodesRequest.ProtoReflect.Descriptor instead.
+func (*JoinNodesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *JoinNodesRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *JoinNodesRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *JoinNodesRequest) GetReferencesTo() string {
+	if x != nil {
+		return x.ReferencesTo
+	}
+	return ""
+}
+
+func (x *JoinNodesRequest) GetReferencedBy() string {
+	if x != nil {
+		return x.ReferencedBy
+	}
+	return ""
+}
+
+func (x *JoinNodesRequest) GetReferenceType() string {
+	if x != nil {
+		return x.ReferenceType
+	}
+	return ""
+}
+
+func (x *JoinNodesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *JoinNodesRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type JoinNodesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*JoinedNode          `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // Ordered by page, then node ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinNodesResponse) Reset() {
+	*x = JoinNodesResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinNodesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinNodesResponse) ProtoMessage() {}
+
+func (x *JoinNodesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinNodesResponse.ProtoReflect.Descriptor instead.
+func (*JoinNodesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *JoinNodesResponse) GetResults() []*JoinedNode {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type JoinedNode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,2,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	References    []*CrossReference      `protobuf:"bytes,3,rep,name=references,proto3" json:"references,omitempty"` // References that satisfied the join
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinedNode) Reset() {
+	*x = JoinedNode{}
+	mi := &file_proto_treestore_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinedNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinedNode) ProtoMessage() {}
+
+func (x *JoinedNode) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinedNode.ProtoReflect.Descriptor instead.
+func (*JoinedNode) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *JoinedNode) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *JoinedNode) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *JoinedNode) GetReferences() []*CrossReference {
+	if x != nil {
+		return x.References
+	}
+	return nil
+}
+
+type GetNodesByPageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	PageNumber    int32                  `protobuf:"varint,2,opt,name=page_number,json=pageNumber,proto3" json:"page_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodesByPageRequest) Reset() {
+	*x = GetNodesByPageRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodesByPageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodesByPageRequest) ProtoMessage() {}
+
+func (x *GetNodesByPageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodesByPageRequest.ProtoReflect.Descriptor instead.
+func (*GetNodesByPageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *GetNodesByPageRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *GetNodesByPageRequest) GetPageNumber() int32 {
+	if x != nil {
+		return x.PageNumber
+	}
+	return 0
+}
+
+type GetNodesByPageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*Node                `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodesByPageResponse) Reset() {
+	*x = GetNodesByPageResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodesByPageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodesByPageResponse) ProtoMessage() {}
+
+func (x *GetNodesByPageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodesByPageResponse.ProtoReflect.Descriptor instead.
+func (*GetNodesByPageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *GetNodesByPageResponse) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type GetVersionAsOfRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	AsOfTime      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=as_of_time,json=asOfTime,proto3" json:"as_of_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVersionAsOfRequest) Reset() {
+	*x = GetVersionAsOfRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVersionAsOfRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVersionAsOfRequest) ProtoMessage() {}
+
+func (x *GetVersionAsOfRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVersionAsOfRequest.ProtoReflect.Descriptor instead.
+func (*GetVersionAsOfRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{79}
+}
+
+func (x *GetVersionAsOfRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *GetVersionAsOfRequest) GetAsOfTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOfTime
+	}
+	return nil
+}
+
+type BatchGetVersionsAsOfRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyIds     []string               `protobuf:"bytes,1,rep,name=policy_ids,json=policyIds,proto3" json:"policy_ids,omitempty"`
+	AsOfTime      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=as_of_time,json=asOfTime,proto3" json:"as_of_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetVersionsAsOfRequest) Reset() {
+	*x = BatchGetVersionsAsOfRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetVersionsAsOfRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetVersionsAsOfRequest) ProtoMessage() {}
+
+func (x *BatchGetVersionsAsOfRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetVersionsAsOfRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetVersionsAsOfRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *BatchGetVersionsAsOfRequest) GetPolicyIds() []string {
+	if x != nil {
+		return x.PolicyIds
+	}
+	return nil
+}
+
+func (x *BatchGetVersionsAsOfRequest) GetAsOfTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOfTime
+	}
+	return nil
+}
+
+type BatchGetVersionsAsOfResponse struct {
+	state            protoimpl.MessageState    `protogen:"open.v1"`
+	Versions         map[string]*PolicyVersion `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Keyed by policy ID
+	MissingPolicyIds []string                  `protobuf:"bytes,2,rep,name=missing_policy_ids,json=missingPolicyIds,proto3" json:"missing_policy_ids,omitempty"`                                 // Policies with no version at as_of_time
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BatchGetVersionsAsOfResponse) Reset() {
+	*x = BatchGetVersionsAsOfResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetVersionsAsOfResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetVersionsAsOfResponse) ProtoMessage() {}
+
+func (x *BatchGetVersionsAsOfResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetVersionsAsOfResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetVersionsAsOfResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{81}
+}
+
+func (x *BatchGetVersionsAsOfResponse) GetVersions() map[string]*PolicyVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+func (x *BatchGetVersionsAsOfResponse) GetMissingPolicyIds() []string {
+	if x != nil {
+		return x.MissingPolicyIds
+	}
+	return nil
+}
+
+type ListVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVersionsRequest) Reset() {
+	*x = ListVersionsRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVersionsRequest) ProtoMessage() {}
+
+func (x *ListVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVersionsRequest.ProtoReflect.Descriptor instead.
+func (*ListVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *ListVersionsRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *ListVersionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListVersionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Versions      []*PolicyVersion       `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVersionsResponse) Reset() {
+	*x = ListVersionsResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVersionsResponse) ProtoMessage() {}
+
+func (x *ListVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVersionsResponse.ProtoReflect.Descriptor instead.
+func (*ListVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *ListVersionsResponse) GetVersions() []*PolicyVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type DeleteVersionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	VersionId     string                 `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteVersionRequest) Reset() {
+	*x = DeleteVersionRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteVersionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteVersionRequest) ProtoMessage() {}
+
+func (x *DeleteVersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteVersionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteVersionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{84}
+}
+
+func (x *DeleteVersionRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *DeleteVersionRequest) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+type DeleteVersionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteVersionResponse) Reset() {
+	*x = DeleteVersionResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteVersionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteVersionResponse) ProtoMessage() {}
+
+func (x *DeleteVersionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[85]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteVersionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteVersionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{85}
+}
+
+func (x *DeleteVersionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type PruneVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	KeepLast      int32                  `protobuf:"varint,2,opt,name=keep_last,json=keepLast,proto3" json:"keep_last,omitempty"`         // Newest versions always kept
+	OlderThan     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=older_than,json=olderThan,proto3" json:"older_than,omitempty"`       // Only prune versions created before this (optional)
+	ProtectTags   []string               `protobuf:"bytes,4,rep,name=protect_tags,json=protectTags,proto3" json:"protect_tags,omitempty"` // Versions with these tags are kept
+	DryRun        bool                   `protobuf:"varint,5,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PruneVersionsRequest) Reset() {
+	*x = PruneVersionsRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PruneVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PruneVersionsRequest) ProtoMessage() {}
+
+func (x *PruneVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[86]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PruneVersionsRequest.ProtoReflect.Descriptor instead.
+func (*PruneVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{86}
+}
+
+func (x *PruneVersionsRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *PruneVersionsRequest) GetKeepLast() int32 {
+	if x != nil {
+		return x.KeepLast
+	}
+	return 0
+}
+
+func (x *PruneVersionsRequest) GetOlderThan() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OlderThan
+	}
+	return nil
+}
+
+func (x *PruneVersionsRequest) GetProtectTags() []string {
+	if x != nil {
+		return x.ProtectTags
+	}
+	return nil
+}
+
+func (x *PruneVersionsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type PruneVersionsResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PrunedVersionIds []string               `protobuf:"bytes,1,rep,name=pruned_version_ids,json=prunedVersionIds,proto3" json:"pruned_version_ids,omitempty"` // Oldest first
+	DryRun           bool                   `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PruneVersionsResponse) Reset() {
+	*x = PruneVersionsResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PruneVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PruneVersionsResponse) ProtoMessage() {}
+
+func (x *PruneVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[87]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PruneVersionsResponse.ProtoReflect.Descriptor instead.
+func (*PruneVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{87}
+}
+
+func (x *PruneVersionsResponse) GetPrunedVersionIds() []string {
+	if x != nil {
+		return x.PrunedVersionIds
+	}
+	return nil
+}
+
+func (x *PruneVersionsResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type TagVersionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	VersionId     string                 `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	Tag           string                 `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	Unique        bool                   `protobuf:"varint,4,opt,name=unique,proto3" json:"unique,omitempty"` // Move the tag: remove it from every other version of the policy
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagVersionRequest) Reset() {
+	*x = TagVersionRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[88]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagVersionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagVersionRequest) ProtoMessage() {}
+
+func (x *TagVersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[88]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagVersionRequest.ProtoReflect.Descriptor instead.
+func (*TagVersionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{88}
+}
+
+func (x *TagVersionRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *TagVersionRequest) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *TagVersionRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *TagVersionRequest) GetUnique() bool {
+	if x != nil {
+		return x.Unique
+	}
+	return false
+}
+
+type TagVersionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagVersionResponse) Reset() {
+	*x = TagVersionResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[89]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagVersionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagVersionResponse) ProtoMessage() {}
+
+func (x *TagVersionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[89]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagVersionResponse.ProtoReflect.Descriptor instead.
+func (*TagVersionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{89}
+}
+
+func (x *TagVersionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type UntagVersionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	VersionId     string                 `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	Tag           string                 `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UntagVersionRequest) Reset() {
+	*x = UntagVersionRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[90]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UntagVersionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UntagVersionRequest) ProtoMessage() {}
+
+func (x *UntagVersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[90]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UntagVersionRequest.ProtoReflect.Descriptor instead.
+func (*UntagVersionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{90}
+}
+
+func (x *UntagVersionRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *UntagVersionRequest) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *UntagVersionRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type UntagVersionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UntagVersionResponse) Reset() {
+	*x = UntagVersionResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[91]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UntagVersionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UntagVersionResponse) ProtoMessage() {}
+
+func (x *UntagVersionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[91]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UntagVersionResponse.ProtoReflect.Descriptor instead.
+func (*UntagVersionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{91}
+}
+
+func (x *UntagVersionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type StoreToolResultRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *ToolResult            `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoreToolResultRequest) Reset() {
+	*x = StoreToolResultRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[92]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreToolResultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreToolResultRequest) ProtoMessage() {}
+
+func (x *StoreToolResultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[92]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreToolResultRequest.ProtoReflect.Descriptor instead.
+func (*StoreToolResultRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{92}
+}
+
+func (x *StoreToolResultRequest) GetResult() *ToolResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type StoreToolResultResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoreToolResultResponse) Reset() {
+	*x = StoreToolResultResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[93]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreToolResultResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreToolResultResponse) ProtoMessage() {}
+
+func (x *StoreToolResultResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[93]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreToolResultResponse.ProtoReflect.Descriptor instead.
+func (*StoreToolResultResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{93}
+}
+
+func (x *StoreToolResultResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StoreToolResultResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetToolResultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	ToolName      string                 `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"` // Optional filter
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetToolResultsRequest) Reset() {
+	*x = GetToolResultsRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[94]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetToolResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetToolResultsRequest) ProtoMessage() {}
+
+func (x *GetToolResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[94]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetToolResultsRequest.ProtoReflect.Descriptor instead.
+func (*GetToolResultsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{94}
+}
+
+func (x *GetToolResultsRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *GetToolResultsRequest) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *GetToolResultsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetToolResultsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*ToolResult          `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetToolResultsResponse) Reset() {
+	*x = GetToolResultsResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[95]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetToolResultsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetToolResultsResponse) ProtoMessage() {}
+
+func (x *GetToolResultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[95]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetToolResultsResponse.ProtoReflect.Descriptor instead.
+func (*GetToolResultsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{95}
+}
+
+func (x *GetToolResultsResponse) GetResults() []*ToolResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type StoreTrajectoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trajectory    *Trajectory            `protobuf:"bytes,1,opt,name=trajectory,proto3" json:"trajectory,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoreTrajectoryRequest) Reset() {
+	*x = StoreTrajectoryRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[96]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreTrajectoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreTrajectoryRequest) ProtoMessage() {}
+
+func (x *StoreTrajectoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[96]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreTrajectoryRequest.ProtoReflect.Descriptor instead.
+func (*StoreTrajectoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{96}
+}
+
+func (x *StoreTrajectoryRequest) GetTrajectory() *Trajectory {
+	if x != nil {
+		return x.Trajectory
+	}
+	return nil
+}
+
+type StoreTrajectoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoreTrajectoryResponse) Reset() {
+	*x = StoreTrajectoryResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[97]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreTrajectoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreTrajectoryResponse) ProtoMessage() {}
+
+func (x *StoreTrajectoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[97]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreTrajectoryResponse.ProtoReflect.Descriptor instead.
+func (*StoreTrajectoryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{97}
+}
+
+func (x *StoreTrajectoryResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StoreTrajectoryResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetTrajectoriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CaseId        string                 `protobuf:"bytes,1,opt,name=case_id,json=caseId,proto3" json:"case_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTrajectoriesRequest) Reset() {
+	*x = GetTrajectoriesRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[98]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTrajectoriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrajectoriesRequest) ProtoMessage() {}
+
+func (x *GetTrajectoriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[98]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrajectoriesRequest.ProtoReflect.Descriptor instead.
+func (*GetTrajectoriesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{98}
+}
+
+func (x *GetTrajectoriesRequest) GetCaseId() string {
+	if x != nil {
+		return x.CaseId
+	}
+	return ""
+}
+
+func (x *GetTrajectoriesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetTrajectoriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trajectories  []*Trajectory          `protobuf:"bytes,1,rep,name=trajectories,proto3" json:"trajectories,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTrajectoriesResponse) Reset() {
+	*x = GetTrajectoriesResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[99]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTrajectoriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrajectoriesResponse) ProtoMessage() {}
+
+func (x *GetTrajectoriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[99]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrajectoriesResponse.ProtoReflect.Descriptor instead.
+func (*GetTrajectoriesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{99}
+}
+
+func (x *GetTrajectoriesResponse) GetTrajectories() []*Trajectory {
+	if x != nil {
+		return x.Trajectories
+	}
+	return nil
+}
+
+// Re-runs the retrieval steps of a stored trajectory: steps whose tool_name is
+// a retrieval tool (search, get_node, get_children, get_subtree, or the
+// matching RPC name) and whose action holds the tool's arguments as JSON.
+// Unset version fields replay against the current documents.
+type ReplayTrajectoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrajectoryId  string                 `protobuf:"bytes,1,opt,name=trajectory_id,json=trajectoryId,proto3" json:"trajectory_id,omitempty"`
+	PolicyId      string                 `protobuf:"bytes,2,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"` // With version_id: the policy whose steps run against that version
+	VersionId     string                 `protobuf:"bytes,3,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	AsOfTime      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=as_of_time,json=asOfTime,proto3" json:"as_of_time,omitempty"` // Instead: every policy as of this time
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayTrajectoryRequest) Reset() {
+	*x = ReplayTrajectoryRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[100]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayTrajectoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayTrajectoryRequest) ProtoMessage() {}
+
+func (x *ReplayTrajectoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[100]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayTrajectoryRequest.ProtoReflect.Descriptor instead.
+func (*ReplayTrajectoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{100}
+}
+
+func (x *ReplayTrajectoryRequest) GetTrajectoryId() string {
+	if x != nil {
+		return x.TrajectoryId
+	}
+	return ""
+}
+
+func (x *ReplayTrajectoryRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *ReplayTrajectoryRequest) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *ReplayTrajectoryRequest) GetAsOfTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOfTime
+	}
+	return nil
+}
+
+type ReplayTrajectoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Steps         []*StepReplay          `protobuf:"bytes,1,rep,name=steps,proto3" json:"steps,omitempty"`
+	Matched       int32                  `protobuf:"varint,2,opt,name=matched,proto3" json:"matched,omitempty"`
+	Diverged      int32                  `protobuf:"varint,3,opt,name=diverged,proto3" json:"diverged,omitempty"`
+	Failed        int32                  `protobuf:"varint,4,opt,name=failed,proto3" json:"failed,omitempty"`
+	Skipped       int32                  `protobuf:"varint,5,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Documents     map[string]string      `protobuf:"bytes,6,rep,name=documents,proto3" json:"documents,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Document replayed in place of each redirected policy
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayTrajectoryResponse) Reset() {
+	*x = ReplayTrajectoryResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[101]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayTrajectoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayTrajectoryResponse) ProtoMessage() {}
+
+func (x *ReplayTrajectoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[101]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayTrajectoryResponse.ProtoReflect.Descriptor instead.
+func (*ReplayTrajectoryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{101}
+}
+
+func (x *ReplayTrajectoryResponse) GetSteps() []*StepReplay {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *ReplayTrajectoryResponse) GetMatched() int32 {
+	if x != nil {
+		return x.Matched
+	}
+	return 0
+}
+
+func (x *ReplayTrajectoryResponse) GetDiverged() int32 {
+	if x != nil {
+		return x.Diverged
+	}
+	return 0
+}
+
+func (x *ReplayTrajectoryResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *ReplayTrajectoryResponse) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *ReplayTrajectoryResponse) GetDocuments() map[string]string {
+	if x != nil {
+		return x.Documents
+	}
+	return nil
+}
+
+type StepReplay struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	StepNumber     int32                  `protobuf:"varint,1,opt,name=step_number,json=stepNumber,proto3" json:"step_number,omitempty"`
+	ToolName       string                 `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	Outcome        string                 `protobuf:"bytes,3,opt,name=outcome,proto3" json:"outcome,omitempty"`                                       // matched, diverged, failed or skipped
+	Reason         string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`                                         // Why the step failed or was skipped
+	MissingNodeIds []string               `protobuf:"bytes,5,rep,name=missing_node_ids,json=missingNodeIds,proto3" json:"missing_node_ids,omitempty"` // Returned when recorded, but not now
+	AddedNodeIds   []string               `protobuf:"bytes,6,rep,name=added_node_ids,json=addedNodeIds,proto3" json:"added_node_ids,omitempty"`       // Returned now, but not when recorded
+	ChangedNodeIds []string               `protobuf:"bytes,7,rep,name=changed_node_ids,json=changedNodeIds,proto3" json:"changed_node_ids,omitempty"` // Returned both times with different checksums
+	Observation    string                 `protobuf:"bytes,8,opt,name=observation,proto3" json:"observation,omitempty"`                               // The replayed result as JSON
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StepReplay) Reset() {
+	*x = StepReplay{}
+	mi := &file_proto_treestore_proto_msgTypes[102]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepReplay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepReplay) ProtoMessage() {}
+
+func (x *StepReplay) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[102]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepReplay.ProtoReflect.Descriptor instead.
+func (*StepReplay) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{102}
+}
+
+func (x *StepReplay) GetStepNumber() int32 {
+	if x != nil {
+		return x.StepNumber
+	}
+	return 0
+}
+
+func (x *StepReplay) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *StepReplay) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *StepReplay) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *StepReplay) GetMissingNodeIds() []string {
+	if x != nil {
+		return x.MissingNodeIds
+	}
+	return nil
+}
+
+func (x *StepReplay) GetAddedNodeIds() []string {
+	if x != nil {
+		return x.AddedNodeIds
+	}
+	return nil
+}
+
+func (x *StepReplay) GetChangedNodeIds() []string {
+	if x != nil {
+		return x.ChangedNodeIds
+	}
+	return nil
+}
+
+func (x *StepReplay) GetObservation() string {
+	if x != nil {
+		return x.Observation
+	}
+	return ""
+}
+
+type StoreCrossReferenceRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CrossReference *CrossReference        `protobuf:"bytes,1,opt,name=cross_reference,json=crossReference,proto3" json:"cross_reference,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StoreCrossReferenceRequest) Reset() {
+	*x = StoreCrossReferenceRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[103]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreCrossReferenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreCrossReferenceRequest) ProtoMessage() {}
+
+func (x *StoreCrossReferenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[103]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreCrossReferenceRequest.ProtoReflect.Descriptor instead.
+func (*StoreCrossReferenceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{103}
+}
+
+func (x *StoreCrossReferenceRequest) GetCrossReference() *CrossReference {
+	if x != nil {
+		return x.CrossReference
+	}
+	return nil
+}
+
+type StoreCrossReferenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoreCrossReferenceResponse) Reset() {
+	*x = StoreCrossReferenceResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[104]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreCrossReferenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreCrossReferenceResponse) ProtoMessage() {}
+
+func (x *StoreCrossReferenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[104]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreCrossReferenceResponse.ProtoReflect.Descriptor instead.
+func (*StoreCrossReferenceResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{104}
+}
+
+func (x *StoreCrossReferenceResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StoreCrossReferenceResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetCrossReferencesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	NodeId        string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"` // References made by or pointing at this node
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCrossReferencesRequest) Reset() {
+	*x = GetCrossReferencesRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[105]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCrossReferencesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCrossReferencesRequest) ProtoMessage() {}
+
+func (x *GetCrossReferencesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[105]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCrossReferencesRequest.ProtoReflect.Descriptor instead.
+func (*GetCrossReferencesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{105}
+}
+
+func (x *GetCrossReferencesRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *GetCrossReferencesRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+type GetCrossReferencesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	References    []*CrossReference      `protobuf:"bytes,1,rep,name=references,proto3" json:"references,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCrossReferencesResponse) Reset() {
+	*x = GetCrossReferencesResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[106]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCrossReferencesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCrossReferencesResponse) ProtoMessage() {}
+
+func (x *GetCrossReferencesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[106]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCrossReferencesResponse.ProtoReflect.Descriptor instead.
+func (*GetCrossReferencesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{106}
+}
+
+func (x *GetCrossReferencesResponse) GetReferences() []*CrossReference {
+	if x != nil {
+		return x.References
+	}
+	return nil
+}
+
+type StoreContradictionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Contradiction *Contradiction         `protobuf:"bytes,1,opt,name=contradiction,proto3" json:"contradiction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoreContradictionRequest) Reset() {
+	*x = StoreContradictionRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[107]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreContradictionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreContradictionRequest) ProtoMessage() {}
+
+func (x *StoreContradictionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[107]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreContradictionRequest.ProtoReflect.Descriptor instead.
+func (*StoreContradictionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{107}
+}
+
+func (x *StoreContradictionRequest) GetContradiction() *Contradiction {
+	if x != nil {
+		return x.Contradiction
+	}
+	return nil
+}
+
+type StoreContradictionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoreContradictionResponse) Reset() {
+	*x = StoreContradictionResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[108]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreContradictionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreContradictionResponse) ProtoMessage() {}
+
+func (x *StoreContradictionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[108]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreContradictionResponse.ProtoReflect.Descriptor instead.
+func (*StoreContradictionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{108}
+}
+
+func (x *StoreContradictionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StoreContradictionResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type BatchSetMetadataRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	EntityType       string                 `protobuf:"bytes,1,opt,name=entity_type,json=entityType,proto3" json:"entity_type,omitempty"`
+	EntityId         string                 `protobuf:"bytes,2,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	Attributes       map[string]string      `protobuf:"bytes,3,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`                                      // Written in a single transaction
+	ValueType        string                 `protobuf:"bytes,4,opt,name=value_type,json=valueType,proto3" json:"value_type,omitempty"`                                                                                                 // Optional type hint; filled from the registered schema when empty
+	ExpectedVersions map[string]uint64      `protobuf:"bytes,5,rep,name=expected_versions,json=expectedVersions,proto3" json:"expected_versions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // If set, every attribute's current version (0 = absent); ABORTED on mismatch
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BatchSetMetadataRequest) Reset() {
+	*x = BatchSetMetadataRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[109]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchSetMetadataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchSetMetadataRequest) ProtoMessage() {}
+
+func (x *BatchSetMetadataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[109]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchSetMetadataRequest.ProtoReflect.Descriptor instead.
+func (*BatchSetMetadataRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{109}
+}
+
+func (x *BatchSetMetadataRequest) GetEntityType() string {
+	if x != nil {
+		return x.EntityType
+	}
+	return ""
+}
+
+func (x *BatchSetMetadataRequest) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *BatchSetMetadataRequest) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *BatchSetMetadataRequest) GetValueType() string {
+	if x != nil {
+		return x.ValueType
+	}
+	return ""
+}
+
+func (x *BatchSetMetadataRequest) GetExpectedVersions() map[string]uint64 {
+	if x != nil {
+		return x.ExpectedVersions
+	}
+	return nil
+}
+
+type BatchSetMetadataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Versions      map[string]uint64      `protobuf:"bytes,3,rep,name=versions,proto3" json:"versions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // New version of each attribute
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchSetMetadataResponse) Reset() {
+	*x = BatchSetMetadataResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[110]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchSetMetadataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchSetMetadataResponse) ProtoMessage() {}
+
+func (x *BatchSetMetadataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[110]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchSetMetadataResponse.ProtoReflect.Descriptor instead.
+func (*BatchSetMetadataResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{110}
+}
+
+func (x *BatchSetMetadataResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *BatchSetMetadataResponse) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *BatchSetMetadataResponse) GetVersions() map[string]uint64 {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+// A named set of policies that searches and subtree reads can cover together
+type Collection struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	PolicyIds     []string               `protobuf:"bytes,3,rep,name=policy_ids,json=policyIds,proto3" json:"policy_ids,omitempty"` // Sorted; policies need not be stored yet
+	Metadata      map[string]string      `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Collection) Reset() {
+	*x = Collection{}
+	mi := &file_proto_treestore_proto_msgTypes[111]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Collection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Collection) ProtoMessage() {}
+
+func (x *Collection) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[111]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Collection.ProtoReflect.Descriptor instead.
+func (*Collection) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{111}
+}
+
+func (x *Collection) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Collection) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Collection) GetPolicyIds() []string {
+	if x != nil {
+		return x.PolicyIds
+	}
+	return nil
+}
+
+func (x *Collection) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Collection) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Collection) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+// Creates a collection or replaces the description, metadata and members of one
+type PutCollectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    *Collection            `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutCollectionRequest) Reset() {
+	*x = PutCollectionRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[112]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutCollectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutCollectionRequest) ProtoMessage() {}
+
+func (x *PutCollectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[112]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutCollectionRequest.ProtoReflect.Descriptor instead.
+func (*PutCollectionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{112}
+}
+
+func (x *PutCollectionRequest) GetCollection() *Collection {
+	if x != nil {
+		return x.Collection
+	}
+	return nil
+}
+
+type PutCollectionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    *Collection            `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutCollectionResponse) Reset() {
+	*x = PutCollectionResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[113]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutCollectionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutCollectionResponse) ProtoMessage() {}
+
+func (x *PutCollectionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[113]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutCollectionResponse.ProtoReflect.Descriptor instead.
+func (*PutCollectionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{113}
+}
+
+func (x *PutCollectionResponse) GetCollection() *Collection {
+	if x != nil {
+		return x.Collection
+	}
+	return nil
+}
+
+type GetCollectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCollectionRequest) Reset() {
+	*x = GetCollectionRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[114]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCollectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCollectionRequest) ProtoMessage() {}
+
+func (x *GetCollectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[114]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCollectionRequest.ProtoReflect.Descriptor instead.
+func (*GetCollectionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{114}
+}
+
+func (x *GetCollectionRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type GetCollectionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    *Collection            `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCollectionResponse) Reset() {
+	*x = GetCollectionResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[115]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCollectionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCollectionResponse) ProtoMessage() {}
+
+func (x *GetCollectionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[115]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCollectionResponse.ProtoReflect.Descriptor instead.
+func (*GetCollectionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{115}
+}
+
+func (x *GetCollectionResponse) GetCollection() *Collection {
+	if x != nil {
+		return x.Collection
+	}
+	return nil
+}
+
+type ListCollectionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"` // Only collections containing this policy; empty lists all
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCollectionsRequest) Reset() {
+	*x = ListCollectionsRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[116]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCollectionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCollectionsRequest) ProtoMessage() {}
+
+func (x *ListCollectionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[116]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCollectionsRequest.ProtoReflect.Descriptor instead.
+func (*ListCollectionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{116}
+}
+
+func (x *ListCollectionsRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+type ListCollectionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collections   []*Collection          `protobuf:"bytes,1,rep,name=collections,proto3" json:"collections,omitempty"` // By name
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCollectionsResponse) Reset() {
+	*x = ListCollectionsResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[117]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCollectionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCollectionsResponse) ProtoMessage() {}
+
+func (x *ListCollectionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[117]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCollectionsResponse.ProtoReflect.Descriptor instead.
+func (*ListCollectionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{117}
+}
+
+func (x *ListCollectionsResponse) GetCollections() []*Collection {
+	if x != nil {
+		return x.Collections
+	}
+	return nil
+}
+
+// Adds, then removes, members of an existing collection
+type UpdateCollectionMembersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Add           []string               `protobuf:"bytes,2,rep,name=add,proto3" json:"add,omitempty"`
+	Remove        []string               `protobuf:"bytes,3,rep,name=remove,proto3" json:"remove,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCollectionMembersRequest) Reset() {
+	*x = UpdateCollectionMembersRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[118]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCollectionMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCollectionMembersRequest) ProtoMessage() {}
+
+func (x *UpdateCollectionMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[118]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCollectionMembersRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCollectionMembersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{118}
+}
+
+func (x *UpdateCollectionMembersRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateCollectionMembersRequest) GetAdd() []string {
+	if x != nil {
+		return x.Add
+	}
+	return nil
+}
+
+func (x *UpdateCollectionMembersRequest) GetRemove() []string {
+	if x != nil {
+		return x.Remove
+	}
+	return nil
+}
+
+type UpdateCollectionMembersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    *Collection            `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCollectionMembersResponse) Reset() {
+	*x = UpdateCollectionMembersResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[119]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCollectionMembersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCollectionMembersResponse) ProtoMessage() {}
+
+func (x *UpdateCollectionMembersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[119]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCollectionMembersResponse.ProtoReflect.Descriptor instead.
+func (*UpdateCollectionMembersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{119}
+}
+
+func (x *UpdateCollectionMembersResponse) GetCollection() *Collection {
+	if x != nil {
+		return x.Collection
+	}
+	return nil
+}
+
+type DeleteCollectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // The member policies are untouched
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCollectionRequest) Reset() {
+	*x = DeleteCollectionRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[120]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCollectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCollectionRequest) ProtoMessage() {}
+
+func (x *DeleteCollectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[120]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCollectionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCollectionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{120}
+}
+
+func (x *DeleteCollectionRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteCollectionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCollectionResponse) Reset() {
+	*x = DeleteCollectionResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[121]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCollectionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCollectionResponse) ProtoMessage() {}
+
+func (x *DeleteCollectionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[121]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCollectionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCollectionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{121}
+}
+
+func (x *DeleteCollectionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type StorePromptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prompt        *PromptTemplate        `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorePromptRequest) Reset() {
+	*x = StorePromptRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[122]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorePromptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorePromptRequest) ProtoMessage() {}
+
+func (x *StorePromptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[122]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorePromptRequest.ProtoReflect.Descriptor instead.
+func (*StorePromptRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{122}
+}
+
+func (x *StorePromptRequest) GetPrompt() *PromptTemplate {
+	if x != nil {
+		return x.Prompt
+	}
+	return nil
+}
+
+type StorePromptResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorePromptResponse) Reset() {
+	*x = StorePromptResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[123]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorePromptResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorePromptResponse) ProtoMessage() {}
+
+func (x *StorePromptResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[123]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorePromptResponse.ProtoReflect.Descriptor instead.
+func (*StorePromptResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{123}
+}
+
+func (x *StorePromptResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StorePromptResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetPromptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PromptId      string                 `protobuf:"bytes,1,opt,name=prompt_id,json=promptId,proto3" json:"prompt_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPromptRequest) Reset() {
+	*x = GetPromptRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[124]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPromptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPromptRequest) ProtoMessage() {}
+
+func (x *GetPromptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[124]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPromptRequest.ProtoReflect.Descriptor instead.
+func (*GetPromptRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{124}
+}
+
+func (x *GetPromptRequest) GetPromptId() string {
+	if x != nil {
+		return x.PromptId
+	}
+	return ""
+}
+
+type GetPromptResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prompt        *PromptTemplate        `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPromptResponse) Reset() {
+	*x = GetPromptResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[125]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPromptResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPromptResponse) ProtoMessage() {}
+
+func (x *GetPromptResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[125]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPromptResponse.ProtoReflect.Descriptor instead.
+func (*GetPromptResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{125}
+}
+
+func (x *GetPromptResponse) GetPrompt() *PromptTemplate {
+	if x != nil {
+		return x.Prompt
+	}
+	return nil
+}
+
+type RecordPromptUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Usage         *PromptUsage           `protobuf:"bytes,1,opt,name=usage,proto3" json:"usage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordPromptUsageRequest) Reset() {
+	*x = RecordPromptUsageRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[126]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordPromptUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordPromptUsageRequest) ProtoMessage() {}
+
+func (x *RecordPromptUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[126]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordPromptUsageRequest.ProtoReflect.Descriptor instead.
+func (*RecordPromptUsageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{126}
+}
+
+func (x *RecordPromptUsageRequest) GetUsage() *PromptUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+type RecordPromptUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordPromptUsageResponse) Reset() {
+	*x = RecordPromptUsageResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[127]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordPromptUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordPromptUsageResponse) ProtoMessage() {}
+
+func (x *RecordPromptUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[127]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordPromptUsageResponse.ProtoReflect.Descriptor instead.
+func (*RecordPromptUsageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{127}
+}
+
+func (x *RecordPromptUsageResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RecordPromptUsageResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type StorePromptExperimentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Experiment    *PromptExperiment      `protobuf:"bytes,1,opt,name=experiment,proto3" json:"experiment,omitempty"` // Replaces any experiment with the same ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorePromptExperimentRequest) Reset() {
+	*x = StorePromptExperimentRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[128]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorePromptExperimentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorePromptExperimentRequest) ProtoMessage() {}
+
+func (x *StorePromptExperimentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[128]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorePromptExperimentRequest.ProtoReflect.Descriptor instead.
+func (*StorePromptExperimentRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{128}
+}
+
+func (x *StorePromptExperimentRequest) GetExperiment() *PromptExperiment {
+	if x != nil {
+		return x.Experiment
+	}
+	return nil
+}
+
+type StorePromptExperimentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorePromptExperimentResponse) Reset() {
+	*x = StorePromptExperimentResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[129]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorePromptExperimentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorePromptExperimentResponse) ProtoMessage() {}
+
+func (x *StorePromptExperimentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[129]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorePromptExperimentResponse.ProtoReflect.Descriptor instead.
+func (*StorePromptExperimentResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{129}
+}
+
+func (x *StorePromptExperimentResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StorePromptExperimentResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetPromptExperimentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExperimentId  string                 `protobuf:"bytes,1,opt,name=experiment_id,json=experimentId,proto3" json:"experiment_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPromptExperimentRequest) Reset() {
+	*x = GetPromptExperimentRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[130]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPromptExperimentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPromptExperimentRequest) ProtoMessage() {}
+
+func (x *GetPromptExperimentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[130]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPromptExperimentRequest.ProtoReflect.Descriptor instead.
+func (*GetPromptExperimentRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{130}
+}
+
+func (x *GetPromptExperimentRequest) GetExperimentId() string {
+	if x != nil {
+		return x.ExperimentId
+	}
+	return ""
+}
+
+type GetPromptExperimentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Experiment    *PromptExperiment      `protobuf:"bytes,1,opt,name=experiment,proto3" json:"experiment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPromptExperimentResponse) Reset() {
+	*x = GetPromptExperimentResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[131]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPromptExperimentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPromptExperimentResponse) ProtoMessage() {}
+
+func (x *GetPromptExperimentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[131]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPromptExperimentResponse.ProtoReflect.Descriptor instead.
+func (*GetPromptExperimentResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{131}
+}
+
+func (x *GetPromptExperimentResponse) GetExperiment() *PromptExperiment {
+	if x != nil {
+		return x.Experiment
+	}
+	return nil
+}
+
+type AssignPromptVariantRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExperimentId  string                 `protobuf:"bytes,1,opt,name=experiment_id,json=experimentId,proto3" json:"experiment_id,omitempty"`
+	Unit          string                 `protobuf:"bytes,2,opt,name=unit,proto3" json:"unit,omitempty"` // User, case or session ID; the same unit keeps its variant
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssignPromptVariantRequest) Reset() {
+	*x = AssignPromptVariantRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[132]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignPromptVariantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignPromptVariantRequest) ProtoMessage() {}
+
+func (x *AssignPromptVariantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[132]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignPromptVariantRequest.ProtoReflect.Descriptor instead.
+func (*AssignPromptVariantRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{132}
+}
+
+func (x *AssignPromptVariantRequest) GetExperimentId() string {
+	if x != nil {
+		return x.ExperimentId
+	}
+	return ""
+}
+
+func (x *AssignPromptVariantRequest) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+type AssignPromptVariantResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Variant       *PromptVariant         `protobuf:"bytes,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssignPromptVariantResponse) Reset() {
+	*x = AssignPromptVariantResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[133]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignPromptVariantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignPromptVariantResponse) ProtoMessage() {}
+
+func (x *AssignPromptVariantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[133]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignPromptVariantResponse.ProtoReflect.Descriptor instead.
+func (*AssignPromptVariantResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{133}
+}
+
+func (x *AssignPromptVariantResponse) GetVariant() *PromptVariant {
+	if x != nil {
+		return x.Variant
+	}
+	return nil
+}
+
+type LabelPromptUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExperimentId  string                 `protobuf:"bytes,1,opt,name=experiment_id,json=experimentId,proto3" json:"experiment_id,omitempty"`
+	UsageId       string                 `protobuf:"bytes,2,opt,name=usage_id,json=usageId,proto3" json:"usage_id,omitempty"`
+	Outcome       string                 `protobuf:"bytes,3,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LabelPromptUsageRequest) Reset() {
+	*x = LabelPromptUsageRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[134]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LabelPromptUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LabelPromptUsageRequest) ProtoMessage() {}
+
+func (x *LabelPromptUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[134]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LabelPromptUsageRequest.ProtoReflect.Descriptor instead.
+func (*LabelPromptUsageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{134}
+}
+
+func (x *LabelPromptUsageRequest) GetExperimentId() string {
+	if x != nil {
+		return x.ExperimentId
+	}
+	return ""
+}
+
+func (x *LabelPromptUsageRequest) GetUsageId() string {
+	if x != nil {
+		return x.UsageId
+	}
+	return ""
+}
+
+func (x *LabelPromptUsageRequest) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+type LabelPromptUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LabelPromptUsageResponse) Reset() {
+	*x = LabelPromptUsageResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[135]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LabelPromptUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LabelPromptUsageResponse) ProtoMessage() {}
+
+func (x *LabelPromptUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[135]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LabelPromptUsageResponse.ProtoReflect.Descriptor instead.
+func (*LabelPromptUsageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{135}
+}
+
+func (x *LabelPromptUsageResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *LabelPromptUsageResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ComparePromptVariantsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExperimentId  string                 `protobuf:"bytes,1,opt,name=experiment_id,json=experimentId,proto3" json:"experiment_id,omitempty"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=since,proto3" json:"since,omitempty"` // Optional: usages at or after
+	Until         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=until,proto3" json:"until,omitempty"` // Optional: usages before
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComparePromptVariantsRequest) Reset() {
+	*x = ComparePromptVariantsRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[136]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComparePromptVariantsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComparePromptVariantsRequest) ProtoMessage() {}
+
+func (x *ComparePromptVariantsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[136]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComparePromptVariantsRequest.ProtoReflect.Descriptor instead.
+func (*ComparePromptVariantsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{136}
+}
+
+func (x *ComparePromptVariantsRequest) GetExperimentId() string {
+	if x != nil {
+		return x.ExperimentId
+	}
+	return ""
+}
+
+func (x *ComparePromptVariantsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *ComparePromptVariantsRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+type ComparePromptVariantsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Variants      []*VariantStats        `protobuf:"bytes,1,rep,name=variants,proto3" json:"variants,omitempty"` // In the experiment's variant order, then removed variants
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComparePromptVariantsResponse) Reset() {
+	*x = ComparePromptVariantsResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[137]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComparePromptVariantsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComparePromptVariantsResponse) ProtoMessage() {}
+
+func (x *ComparePromptVariantsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[137]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComparePromptVariantsResponse.ProtoReflect.Descriptor instead.
+func (*ComparePromptVariantsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{137}
+}
+
+func (x *ComparePromptVariantsResponse) GetVariants() []*VariantStats {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type GetMessagesPageRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	AfterMessageId string                 `protobuf:"bytes,2,opt,name=after_message_id,json=afterMessageId,proto3" json:"after_message_id,omitempty"` // Cursor: last message already seen
+	AfterTimestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=after_timestamp,json=afterTimestamp,proto3" json:"after_timestamp,omitempty"`   // Used when after_message_id is empty
+	Limit          int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`                                          // Default 50
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetMessagesPageRequest) Reset() {
+	*x = GetMessagesPageRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[138]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMessagesPageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMessagesPageRequest) ProtoMessage() {}
+
+func (x *GetMessagesPageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[138]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMessagesPageRequest.ProtoReflect.Descriptor instead.
+func (*GetMessagesPageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{138}
+}
+
+func (x *GetMessagesPageRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *GetMessagesPageRequest) GetAfterMessageId() string {
+	if x != nil {
+		return x.AfterMessageId
+	}
+	return ""
+}
+
+func (x *GetMessagesPageRequest) GetAfterTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AfterTimestamp
+	}
+	return nil
+}
+
+func (x *GetMessagesPageRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetMessagesPageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*Message             `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"` // Oldest first
+	HasMore       bool                   `protobuf:"varint,2,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // Pass as after_message_id to fetch the next page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMessagesPageResponse) Reset() {
+	*x = GetMessagesPageResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[139]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMessagesPageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMessagesPageResponse) ProtoMessage() {}
+
+func (x *GetMessagesPageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[139]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMessagesPageResponse.ProtoReflect.Descriptor instead.
+func (*GetMessagesPageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{139}
+}
+
+func (x *GetMessagesPageResponse) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *GetMessagesPageResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *GetMessagesPageResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type GetRecentMessagesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Count          int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetRecentMessagesRequest) Reset() {
+	*x = GetRecentMessagesRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[140]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRecentMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRecentMessagesRequest) ProtoMessage() {}
+
+func (x *GetRecentMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[140]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRecentMessagesRequest.ProtoReflect.Descriptor instead.
+func (*GetRecentMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{140}
+}
+
+func (x *GetRecentMessagesRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *GetRecentMessagesRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type GetRecentMessagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*Message             `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"` // Oldest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRecentMessagesResponse) Reset() {
+	*x = GetRecentMessagesResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[141]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRecentMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRecentMessagesResponse) ProtoMessage() {}
+
+func (x *GetRecentMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[141]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRecentMessagesResponse.ProtoReflect.Descriptor instead.
+func (*GetRecentMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{141}
+}
+
+func (x *GetRecentMessagesResponse) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type SearchConversationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // Empty searches all users
+	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"` // Default 20
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchConversationsRequest) Reset() {
+	*x = SearchConversationsRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[142]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchConversationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchConversationsRequest) ProtoMessage() {}
+
+func (x *SearchConversationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[142]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchConversationsRequest.ProtoReflect.Descriptor instead.
+func (*SearchConversationsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{142}
+}
+
+func (x *SearchConversationsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SearchConversationsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchConversationsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ConversationSearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversation  *Conversation          `protobuf:"bytes,1,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Matches       []*Message             `protobuf:"bytes,3,rep,name=matches,proto3" json:"matches,omitempty"` // Best matching messages first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConversationSearchResult) Reset() {
+	*x = ConversationSearchResult{}
+	mi := &file_proto_treestore_proto_msgTypes[143]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationSearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationSearchResult) ProtoMessage() {}
+
+func (x *ConversationSearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[143]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationSearchResult.ProtoReflect.Descriptor instead.
+func (*ConversationSearchResult) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{143}
+}
+
+func (x *ConversationSearchResult) GetConversation() *Conversation {
+	if x != nil {
+		return x.Conversation
+	}
+	return nil
+}
+
+func (x *ConversationSearchResult) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *ConversationSearchResult) GetMatches() []*Message {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+type SearchConversationsResponse struct {
+	state         protoimpl.MessageState      `protogen:"open.v1"`
+	Results       []*ConversationSearchResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchConversationsResponse) Reset() {
+	*x = SearchConversationsResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[144]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchConversationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchConversationsResponse) ProtoMessage() {}
+
+func (x *SearchConversationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[144]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchConversationsResponse.ProtoReflect.Descriptor instead.
+func (*SearchConversationsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{144}
+}
+
+func (x *SearchConversationsResponse) GetResults() []*ConversationSearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ExportConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Format         string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`                                  // "openai" (default) or "anthropic"
+	OmitMetadata   bool                   `protobuf:"varint,3,opt,name=omit_metadata,json=omitMetadata,proto3" json:"omit_metadata,omitempty"` // Leave out message and conversation metadata, for payloads an API accepts as they are
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExportConversationRequest) Reset() {
+	*x = ExportConversationRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[145]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportConversationRequest) ProtoMessage() {}
+
+func (x *ExportConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[145]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportConversationRequest.ProtoReflect.Descriptor instead.
+func (*ExportConversationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{145}
+}
+
+func (x *ExportConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *ExportConversationRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ExportConversationRequest) GetOmitMetadata() bool {
+	if x != nil {
+		return x.OmitMetadata
+	}
+	return false
+}
+
+type ExportConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`                                // JSON: {"messages": [...]}, with "system" for anthropic and "metadata" unless omitted
+	MessageCount  int32                  `protobuf:"varint,2,opt,name=message_count,json=messageCount,proto3" json:"message_count,omitempty"` // Messages exported; deleted and, for anthropic, system messages are not counted
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportConversationResponse) Reset() {
+	*x = ExportConversationResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[146]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportConversationResponse) ProtoMessage() {}
+
+func (x *ExportConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[146]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportConversationResponse.ProtoReflect.Descriptor instead.
+func (*ExportConversationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{146}
+}
+
+func (x *ExportConversationResponse) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *ExportConversationResponse) GetMessageCount() int32 {
+	if x != nil {
+		return x.MessageCount
+	}
+	return 0
+}
+
+type ListConversationsByUserRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	UserId              string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	AfterConversationId string                 `protobuf:"bytes,2,opt,name=after_conversation_id,json=afterConversationId,proto3" json:"after_conversation_id,omitempty"` // Cursor: last conversation already seen
+	Limit               int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                                                         // Default 50
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ListConversationsByUserRequest) Reset() {
+	*x = ListConversationsByUserRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[147]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversationsByUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversationsByUserRequest) ProtoMessage() {}
+
+func (x *ListConversationsByUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[147]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversationsByUserRequest.ProtoReflect.Descriptor instead.
+func (*ListConversationsByUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{147}
+}
+
+func (x *ListConversationsByUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListConversationsByUserRequest) GetAfterConversationId() string {
+	if x != nil {
+		return x.AfterConversationId
+	}
+	return ""
+}
+
+func (x *ListConversationsByUserRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListConversationsByUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversations []*Conversation        `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"` // Oldest first
+	HasMore       bool                   `protobuf:"varint,2,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // Pass as after_conversation_id to fetch the next page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversationsByUserResponse) Reset() {
+	*x = ListConversationsByUserResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[148]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversationsByUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversationsByUserResponse) ProtoMessage() {}
+
+func (x *ListConversationsByUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[148]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversationsByUserResponse.ProtoReflect.Descriptor instead.
+func (*ListConversationsByUserResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{148}
+}
+
+func (x *ListConversationsByUserResponse) GetConversations() []*Conversation {
+	if x != nil {
+		return x.Conversations
+	}
+	return nil
+}
+
+func (x *ListConversationsByUserResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *ListConversationsByUserResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type GetUserConversationUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserConversationUsageRequest) Reset() {
+	*x = GetUserConversationUsageRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[149]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserConversationUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserConversationUsageRequest) ProtoMessage() {}
+
+func (x *GetUserConversationUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[149]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserConversationUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetUserConversationUsageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{149}
+}
+
+func (x *GetUserConversationUsageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetUserConversationUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Conversations int64                  `protobuf:"varint,2,opt,name=conversations,proto3" json:"conversations,omitempty"`                // Conversations stored
+	Messages      int64                  `protobuf:"varint,3,opt,name=messages,proto3" json:"messages,omitempty"`                          // Messages added to them, deleted ones included
+	Quota         *ConversationQuota     `protobuf:"bytes,4,opt,name=quota,proto3" json:"quota,omitempty"`                                 // The quota that applies
+	CustomQuota   bool                   `protobuf:"varint,5,opt,name=custom_quota,json=customQuota,proto3" json:"custom_quota,omitempty"` // Quota was set for this user rather than the server default
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserConversationUsageResponse) Reset() {
+	*x = GetUserConversationUsageResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[150]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserConversationUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserConversationUsageResponse) ProtoMessage() {}
+
+func (x *GetUserConversationUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[150]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserConversationUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetUserConversationUsageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{150}
+}
+
+func (x *GetUserConversationUsageResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetUserConversationUsageResponse) GetConversations() int64 {
+	if x != nil {
+		return x.Conversations
+	}
+	return 0
+}
+
+func (x *GetUserConversationUsageResponse) GetMessages() int64 {
+	if x != nil {
+		return x.Messages
+	}
+	return 0
+}
+
+func (x *GetUserConversationUsageResponse) GetQuota() *ConversationQuota {
+	if x != nil {
+		return x.Quota
+	}
+	return nil
+}
+
+func (x *GetUserConversationUsageResponse) GetCustomQuota() bool {
+	if x != nil {
+		return x.CustomQuota
+	}
+	return false
+}
+
+type SetUserConversationQuotaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Quota         *ConversationQuota     `protobuf:"bytes,2,opt,name=quota,proto3" json:"quota,omitempty"` // Unset returns the user to the server default
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetUserConversationQuotaRequest) Reset() {
+	*x = SetUserConversationQuotaRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[151]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetUserConversationQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetUserConversationQuotaRequest) ProtoMessage() {}
+
+func (x *SetUserConversationQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[151]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetUserConversationQuotaRequest.ProtoReflect.Descriptor instead.
+func (*SetUserConversationQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{151}
+}
+
+func (x *SetUserConversationQuotaRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SetUserConversationQuotaRequest) GetQuota() *ConversationQuota {
+	if x != nil {
+		return x.Quota
+	}
+	return nil
+}
+
+type SetUserConversationQuotaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetUserConversationQuotaResponse) Reset() {
+	*x = SetUserConversationQuotaResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[152]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetUserConversationQuotaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetUserConversationQuotaResponse) ProtoMessage() {}
+
+func (x *SetUserConversationQuotaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[152]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetUserConversationQuotaResponse.ProtoReflect.Descriptor instead.
+func (*SetUserConversationQuotaResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{152}
+}
+
+func (x *SetUserConversationQuotaResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SetUserConversationQuotaResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type StreamQueryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"` // Text (FROM ... WHERE ...) or JSON query
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamQueryRequest) Reset() {
+	*x = StreamQueryRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[153]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamQueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamQueryRequest) ProtoMessage() {}
+
+func (x *StreamQueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[153]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamQueryRequest.ProtoReflect.Descriptor instead.
+func (*StreamQueryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{153}
+}
+
+func (x *StreamQueryRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+type MetadataEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntityType    string                 `protobuf:"bytes,1,opt,name=entity_type,json=entityType,proto3" json:"entity_type,omitempty"`
+	EntityId      string                 `protobuf:"bytes,2,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	Key           string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	ValueType     string                 `protobuf:"bytes,5,opt,name=value_type,json=valueType,proto3" json:"value_type,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Version       uint64                 `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetadataEntry) Reset() {
+	*x = MetadataEntry{}
+	mi := &file_proto_treestore_proto_msgTypes[154]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetadataEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetadataEntry) ProtoMessage() {}
+
+func (x *MetadataEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[154]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetadataEntry.ProtoReflect.Descriptor instead.
+func (*MetadataEntry) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{154}
+}
+
+func (x *MetadataEntry) GetEntityType() string {
+	if x != nil {
+		return x.EntityType
+	}
+	return ""
+}
+
+func (x *MetadataEntry) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *MetadataEntry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MetadataEntry) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *MetadataEntry) GetValueType() string {
+	if x != nil {
+		return x.ValueType
+	}
+	return ""
+}
+
+func (x *MetadataEntry) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *MetadataEntry) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *MetadataEntry) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type QueryRow struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Row:
+	//
+	//	*QueryRow_Node
+	//	*QueryRow_Version
+	//	*QueryRow_Metadata
+	//	*QueryRow_Conversation
+	//	*QueryRow_Group
+	Row           isQueryRow_Row `protobuf_oneof:"row"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryRow) Reset() {
+	*x = QueryRow{}
+	mi := &file_proto_treestore_proto_msgTypes[155]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryRow) ProtoMessage() {}
+
+func (x *QueryRow) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[155]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryRow.ProtoReflect.Descriptor instead.
+func (*QueryRow) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{155}
+}
+
+func (x *QueryRow) GetRow() isQueryRow_Row {
+	if x != nil {
+		return x.Row
+	}
+	return nil
+}
+
+func (x *QueryRow) GetNode() *Node {
+	if x != nil {
+		if x, ok := x.Row.(*QueryRow_Node); ok {
+			return x.Node
+		}
+	}
+	return nil
+}
+
+func (x *QueryRow) GetVersion() *PolicyVersion {
+	if x != nil {
+		if x, ok := x.Row.(*QueryRow_Version); ok {
+			return x.Version
+		}
+	}
+	return nil
+}
+
+func (x *QueryRow) GetMetadata() *MetadataEntry {
+	if x != nil {
+		if x, ok := x.Row.(*QueryRow_Metadata); ok {
+			return x.Metadata
+		}
+	}
+	return nil
+}
+
+func (x *QueryRow) GetConversation() *Conversation {
+	if x != nil {
+		if x, ok := x.Row.(*QueryRow_Conversation); ok {
+			return x.Conversation
+		}
+	}
+	return nil
+}
+
+func (x *QueryRow) GetGroup() *QueryGroup {
+	if x != nil {
+		if x, ok := x.Row.(*QueryRow_Group); ok {
+			return x.Group
+		}
+	}
+	return nil
+}
+
+type isQueryRow_Row interface {
+	isQueryRow_Row()
+}
+
+type QueryRow_Node struct {
+	Node *Node `protobuf:"bytes,1,opt,name=node,proto3,oneof"`
+}
+
+type QueryRow_Version struct {
+	Version *PolicyVersion `protobuf:"bytes,2,opt,name=version,proto3,oneof"`
+}
+
+type QueryRow_Metadata struct {
+	Metadata *MetadataEntry `protobuf:"bytes,3,opt,name=metadata,proto3,oneof"`
+}
+
+type QueryRow_Conversation struct {
+	Conversation *Conversation `protobuf:"bytes,4,opt,name=conversation,proto3,oneof"`
+}
+
+type QueryRow_Group struct {
+	Group *QueryGroup `protobuf:"bytes,5,opt,name=group,proto3,oneof"` // Rows of queries with SELECT or GROUP BY
+}
+
+func (*QueryRow_Node) isQueryRow_Row() {}
+
+func (*QueryRow_Version) isQueryRow_Row() {}
+
+func (*QueryRow_Metadata) isQueryRow_Row() {}
+
+func (*QueryRow_Conversation) isQueryRow_Row() {}
+
+func (*QueryRow_Group) isQueryRow_Row() {}
+
+// One group of an aggregated query
+type QueryGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           []string               `protobuf:"bytes,1,rep,name=key,proto3" json:"key,omitempty"` // GROUP BY values in order; times in RFC 3339, missing metadata empty
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Values        map[string]float64     `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // Aggregates by label, e.g. "avg(metadata.pages)"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryGroup) Reset() {
+	*x = QueryGroup{}
+	mi := &file_proto_treestore_proto_msgTypes[156]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryGroup) ProtoMessage() {}
+
+func (x *QueryGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[156]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryGroup.ProtoReflect.Descriptor instead.
+func (*QueryGroup) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{156}
+}
+
+func (x *QueryGroup) GetKey() []string {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *QueryGroup) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *QueryGroup) GetValues() map[string]float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// A named query whose results are materialized when saved or refreshed
+type SavedQuery struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Name            string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Query           string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`                                               // Text or JSON query, as for StreamQuery
+	RefreshOnChange bool                   `protobuf:"varint,3,opt,name=refresh_on_change,json=refreshOnChange,proto3" json:"refresh_on_change,omitempty"` // Writes to the queried store mark the results stale; otherwise only RefreshSavedQuery recomputes them
+	RefreshedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=refreshed_at,json=refreshedAt,proto3" json:"refreshed_at,omitempty"`
+	Rows            int64                  `protobuf:"varint,5,opt,name=rows,proto3" json:"rows,omitempty"`
+	Stale           bool                   `protobuf:"varint,6,opt,name=stale,proto3" json:"stale,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SavedQuery) Reset() {
+	*x = SavedQuery{}
+	mi := &file_proto_treestore_proto_msgTypes[157]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SavedQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SavedQuery) ProtoMessage() {}
+
+func (x *SavedQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[157]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SavedQuery.ProtoReflect.Descriptor instead.
+func (*SavedQuery) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{157}
+}
+
+func (x *SavedQuery) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SavedQuery) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SavedQuery) GetRefreshOnChange() bool {
+	if x != nil {
+		return x.RefreshOnChange
+	}
+	return false
+}
+
+func (x *SavedQuery) GetRefreshedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RefreshedAt
+	}
+	return nil
+}
+
+func (x *SavedQuery) GetRows() int64 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *SavedQuery) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+type SaveQueryRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Name            string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Query           string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	RefreshOnChange bool                   `protobuf:"varint,3,opt,name=refresh_on_change,json=refreshOnChange,proto3" json:"refresh_on_change,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SaveQueryRequest) Reset() {
+	*x = SaveQueryRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[158]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveQueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveQueryRequest) ProtoMessage() {}
+
+func (x *SaveQueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[158]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveQueryRequest.ProtoReflect.Descriptor instead.
+func (*SaveQueryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{158}
+}
+
+func (x *SaveQueryRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SaveQueryRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SaveQueryRequest) GetRefreshOnChange() bool {
+	if x != nil {
+		return x.RefreshOnChange
+	}
+	return false
+}
+
+type SaveQueryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Saved         *SavedQuery            `protobuf:"bytes,1,opt,name=saved,proto3" json:"saved,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveQueryResponse) Reset() {
+	*x = SaveQueryResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[159]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveQueryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveQueryResponse) ProtoMessage() {}
+
+func (x *SaveQueryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[159]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveQueryResponse.ProtoReflect.Descriptor instead.
+func (*SaveQueryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{159}
+}
+
+func (x *SaveQueryResponse) GetSaved() *SavedQuery {
+	if x != nil {
+		return x.Saved
+	}
+	return nil
+}
+
+type ExecuteSavedQueryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteSavedQueryRequest) Reset() {
+	*x = ExecuteSavedQueryRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[160]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteSavedQueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteSavedQueryRequest) ProtoMessage() {}
+
+func (x *ExecuteSavedQueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[160]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteSavedQueryRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteSavedQueryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{160}
+}
+
+func (x *ExecuteSavedQueryRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ExecuteSavedQueryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Saved         *SavedQuery            `protobuf:"bytes,1,opt,name=saved,proto3" json:"saved,omitempty"`
+	Rows          []*QueryRow            `protobuf:"bytes,2,rep,name=rows,proto3" json:"rows,omitempty"`
+	Refreshed     bool                   `protobuf:"varint,3,opt,name=refreshed,proto3" json:"refreshed,omitempty"` // Stale results were recomputed for this call
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteSavedQueryResponse) Reset() {
+	*x = ExecuteSavedQueryResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[161]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteSavedQueryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteSavedQueryResponse) ProtoMessage() {}
+
+func (x *ExecuteSavedQueryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[161]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteSavedQueryResponse.ProtoReflect.Descriptor instead.
+func (*ExecuteSavedQueryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{161}
+}
+
+func (x *ExecuteSavedQueryResponse) GetSaved() *SavedQuery {
+	if x != nil {
+		return x.Saved
+	}
+	return nil
+}
+
+func (x *ExecuteSavedQueryResponse) GetRows() []*QueryRow {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *ExecuteSavedQueryResponse) GetRefreshed() bool {
+	if x != nil {
+		return x.Refreshed
+	}
+	return false
+}
+
+type RefreshSavedQueryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshSavedQueryRequest) Reset() {
+	*x = RefreshSavedQueryRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[162]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshSavedQueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshSavedQueryRequest) ProtoMessage() {}
+
+func (x *RefreshSavedQueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[162]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshSavedQueryRequest.ProtoReflect.Descriptor instead.
+func (*RefreshSavedQueryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{162}
+}
+
+func (x *RefreshSavedQueryRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type RefreshSavedQueryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Saved         *SavedQuery            `protobuf:"bytes,1,opt,name=saved,proto3" json:"saved,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshSavedQueryResponse) Reset() {
+	*x = RefreshSavedQueryResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[163]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshSavedQueryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshSavedQueryResponse) ProtoMessage() {}
+
+func (x *RefreshSavedQueryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[163]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshSavedQueryResponse.ProtoReflect.Descriptor instead.
+func (*RefreshSavedQueryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{163}
+}
+
+func (x *RefreshSavedQueryResponse) GetSaved() *SavedQuery {
+	if x != nil {
+		return x.Saved
+	}
+	return nil
+}
+
+type ListSavedQueriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSavedQueriesRequest) Reset() {
+	*x = ListSavedQueriesRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[164]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSavedQueriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSavedQueriesRequest) ProtoMessage() {}
+
+func (x *ListSavedQueriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[164]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSavedQueriesRequest.ProtoReflect.Descriptor instead.
+func (*ListSavedQueriesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{164}
+}
+
+type ListSavedQueriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Saved         []*SavedQuery          `protobuf:"bytes,1,rep,name=saved,proto3" json:"saved,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSavedQueriesResponse) Reset() {
+	*x = ListSavedQueriesResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[165]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSavedQueriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSavedQueriesResponse) ProtoMessage() {}
+
+func (x *ListSavedQueriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[165]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSavedQueriesResponse.ProtoReflect.Descriptor instead.
+func (*ListSavedQueriesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{165}
+}
+
+func (x *ListSavedQueriesResponse) GetSaved() []*SavedQuery {
+	if x != nil {
+		return x.Saved
+	}
+	return nil
+}
+
+type DeleteSavedQueryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSavedQueryRequest) Reset() {
+	*x = DeleteSavedQueryRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[166]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSavedQueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSavedQueryRequest) ProtoMessage() {}
+
+func (x *DeleteSavedQueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[166]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSavedQueryRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSavedQueryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{166}
+}
+
+func (x *DeleteSavedQueryRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteSavedQueryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSavedQueryResponse) Reset() {
+	*x = DeleteSavedQueryResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[167]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSavedQueryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSavedQueryResponse) ProtoMessage() {}
+
+func (x *DeleteSavedQueryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[167]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSavedQueryResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSavedQueryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{167}
+}
+
+func (x *DeleteSavedQueryResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type WatchChangesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Topic prefixes such as "version/LCD-" or "metadata/tool_result/"; empty watches everything.
+	// Topics are entity, policy_id and entity_id joined by "/", skipping empty parts.
+	Prefixes      []string `protobuf:"bytes,1,rep,name=prefixes,proto3" json:"prefixes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchChangesRequest) Reset() {
+	*x = WatchChangesRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[168]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchChangesRequest) ProtoMessage() {}
+
+func (x *WatchChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[168]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchChangesRequest.ProtoReflect.Descriptor instead.
+func (*WatchChangesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{168}
+}
+
+func (x *WatchChangesRequest) GetPrefixes() []string {
+	if x != nil {
+		return x.Prefixes
+	}
+	return nil
+}
+
+type ChangeEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Entity        string                 `protobuf:"bytes,2,opt,name=entity,proto3" json:"entity,omitempty"`                     // document, embedding, version, metadata, reference or collection
+	Op            string                 `protobuf:"bytes,3,opt,name=op,proto3" json:"op,omitempty"`                             // put or delete
+	PolicyId      string                 `protobuf:"bytes,4,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"` // Empty for metadata and collections
+	EntityId      string                 `protobuf:"bytes,5,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Topic         string                 `protobuf:"bytes,7,opt,name=topic,proto3" json:"topic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeEvent) Reset() {
+	*x = ChangeEvent{}
+	mi := &file_proto_treestore_proto_msgTypes[169]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeEvent) ProtoMessage() {}
+
+func (x *ChangeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[169]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeEvent.ProtoReflect.Descriptor instead.
+func (*ChangeEvent) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{169}
+}
+
+func (x *ChangeEvent) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *ChangeEvent) GetEntity() string {
+	if x != nil {
+		return x.Entity
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *ChangeEvent) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+type StreamWALRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AfterLsn      uint64                 `protobuf:"varint,1,opt,name=after_lsn,json=afterLsn,proto3" json:"after_lsn,omitempty"` // Stream entries with a greater LSN
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamWALRequest) Reset() {
+	*x = StreamWALRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[170]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamWALRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamWALRequest) ProtoMessage() {}
+
+func (x *StreamWALRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[170]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamWALRequest.ProtoReflect.Descriptor instead.
+func (*StreamWALRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{170}
+}
+
+func (x *StreamWALRequest) GetAfterLsn() uint64 {
+	if x != nil {
+		return x.AfterLsn
+	}
+	return 0
+}
+
+type WALEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lsn           uint64                 `protobuf:"varint,1,opt,name=lsn,proto3" json:"lsn,omitempty"`
+	TxnId         uint64                 `protobuf:"varint,2,opt,name=txn_id,json=txnId,proto3" json:"txn_id,omitempty"`
+	Op            uint32                 `protobuf:"varint,3,opt,name=op,proto3" json:"op,omitempty"` // 1 insert, 2 delete, 3 commit, 4 checkpoint, 5 sealed insert, 6 begin, 7 abort
+	Key           []byte                 `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
+	Value         []byte                 `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WALEntry) Reset() {
+	*x = WALEntry{}
+	mi := &file_proto_treestore_proto_msgTypes[171]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WALEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WALEntry) ProtoMessage() {}
+
+func (x *WALEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[171]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WALEntry.ProtoReflect.Descriptor instead.
+func (*WALEntry) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{171}
+}
+
+func (x *WALEntry) GetLsn() uint64 {
+	if x != nil {
+		return x.Lsn
+	}
+	return 0
+}
+
+func (x *WALEntry) GetTxnId() uint64 {
+	if x != nil {
+		return x.TxnId
+	}
+	return 0
+}
+
+func (x *WALEntry) GetOp() uint32 {
+	if x != nil {
+		return x.Op
+	}
+	return 0
+}
+
+func (x *WALEntry) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *WALEntry) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *WALEntry) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type QueryAuditLogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // Inclusive; unset means the beginning
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // Exclusive; unset means now
+	Actor         string                 `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`                          // Empty matches every actor
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`                         // Default 100, at most 1000
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryAuditLogRequest) Reset() {
+	*x = QueryAuditLogRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[172]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryAuditLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryAuditLogRequest) ProtoMessage() {}
+
+func (x *QueryAuditLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[172]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryAuditLogRequest.ProtoReflect.Descriptor instead.
+func (*QueryAuditLogRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{172}
+}
+
+func (x *QueryAuditLogRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *QueryAuditLogRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *QueryAuditLogRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *QueryAuditLogRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type AuditRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Seq           uint64                 `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Method        string                 `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	Actor         string                 `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	Entities      []string               `protobuf:"bytes,5,rep,name=entities,proto3" json:"entities,omitempty"`                          // IDs named in the request, e.g. "policy_id=LCD-1"
+	RequestHash   string                 `protobuf:"bytes,6,opt,name=request_hash,json=requestHash,proto3" json:"request_hash,omitempty"` // Hex SHA-256 of the request
+	Code          string                 `protobuf:"bytes,7,opt,name=code,proto3" json:"code,omitempty"`                                  // gRPC status code name
+	Error         string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	RequestId     string                 `protobuf:"bytes,9,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // The call's x-request-id, also logged with it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditRecord) Reset() {
+	*x = AuditRecord{}
+	mi := &file_proto_treestore_proto_msgTypes[173]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditRecord) ProtoMessage() {}
+
+func (x *AuditRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[173]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditRecord.ProtoReflect.Descriptor instead.
+func (*AuditRecord) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{173}
+}
+
+func (x *AuditRecord) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
 	}
 	return nil
 }
 
-type GetChildrenRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
-	ParentId      string                 `protobuf:"bytes,2,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // Empty for root children
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *AuditRecord) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
 }
 
-func (x *GetChildrenRequest) Reset() {
-	*x = GetChildrenRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[18]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *AuditRecord) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
 }
 
-func (x *GetChildrenRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *AuditRecord) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
 }
 
-func (*GetChildrenRequest) ProtoMessage() {}
+func (x *AuditRecord) GetEntities() []string {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
 
-func (x *GetChildrenRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[18]
+func (x *AuditRecord) GetRequestHash() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.RequestHash
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use GetChildrenRequest.ProtoReflect.Descriptor instead.
-func (*GetChildrenRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{18}
+func (x *AuditRecord) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
 }
 
-func (x *GetChildrenRequest) GetPolicyId() string {
+func (x *AuditRecord) GetError() string {
 	if x != nil {
-		return x.PolicyId
+		return x.Error
 	}
 	return ""
 }
 
-func (x *GetChildrenRequest) GetParentId() string {
+func (x *AuditRecord) GetRequestId() string {
 	if x != nil {
-		return x.ParentId
+		return x.RequestId
 	}
 	return ""
 }
 
-type GetChildrenResponse struct {
+type QueryAuditLogResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Children      []*Node                `protobuf:"bytes,1,rep,name=children,proto3" json:"children,omitempty"`
+	Records       []*AuditRecord         `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetChildrenResponse) Reset() {
-	*x = GetChildrenResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[19]
+func (x *QueryAuditLogResponse) Reset() {
+	*x = QueryAuditLogResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[174]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetChildrenResponse) String() string {
+func (x *QueryAuditLogResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetChildrenResponse) ProtoMessage() {}
+func (*QueryAuditLogResponse) ProtoMessage() {}
 
-func (x *GetChildrenResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[19]
+func (x *QueryAuditLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[174]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1434,42 +10459,50 @@ func (x *GetChildrenResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetChildrenResponse.ProtoReflect.Descriptor instead.
-func (*GetChildrenResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use QueryAuditLogResponse.ProtoReflect.Descriptor instead.
+func (*QueryAuditLogResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{174}
 }
 
-func (x *GetChildrenResponse) GetChildren() []*Node {
+func (x *QueryAuditLogResponse) GetRecords() []*AuditRecord {
 	if x != nil {
-		return x.Children
+		return x.Records
 	}
 	return nil
 }
 
-type GetSubtreeRequest struct {
+type JobRun struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
-	NodeId        string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
-	MaxDepth      int32                  `protobuf:"varint,3,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"` // 0 = unlimited
+	Job           string                 `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	PolicyId      string                 `protobuf:"bytes,2,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	State         string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`                          // pending, running, retrying, succeeded or failed
+	Attempts      int32                  `protobuf:"varint,4,opt,name=attempts,proto3" json:"attempts,omitempty"`                   // Attempts since the run was last queued
+	LastError     string                 `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"` // Error of the last failed attempt
+	QueuedAt      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=queued_at,json=queuedAt,proto3" json:"queued_at,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	NextAttempt   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=next_attempt,json=nextAttempt,proto3" json:"next_attempt,omitempty"` // When a retrying run is due
+	Outputs       int32                  `protobuf:"varint,10,opt,name=outputs,proto3" json:"outputs,omitempty"`                          // Tool results and contradictions stored by the last success
+	Rerun         bool                   `protobuf:"varint,11,opt,name=rerun,proto3" json:"rerun,omitempty"`                              // The document changed while running; it runs again once done
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetSubtreeRequest) Reset() {
-	*x = GetSubtreeRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[20]
+func (x *JobRun) Reset() {
+	*x = JobRun{}
+	mi := &file_proto_treestore_proto_msgTypes[175]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetSubtreeRequest) String() string {
+func (x *JobRun) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetSubtreeRequest) ProtoMessage() {}
+func (*JobRun) ProtoMessage() {}
 
-func (x *GetSubtreeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[20]
+func (x *JobRun) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[175]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1480,99 +10513,112 @@ func (x *GetSubtreeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetSubtreeRequest.ProtoReflect.Descriptor instead.
-func (*GetSubtreeRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use JobRun.ProtoReflect.Descriptor instead.
+func (*JobRun) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{175}
 }
 
-func (x *GetSubtreeRequest) GetPolicyId() string {
+func (x *JobRun) GetJob() string {
+	if x != nil {
+		return x.Job
+	}
+	return ""
+}
+
+func (x *JobRun) GetPolicyId() string {
 	if x != nil {
 		return x.PolicyId
 	}
 	return ""
 }
 
-func (x *GetSubtreeRequest) GetNodeId() string {
+func (x *JobRun) GetState() string {
 	if x != nil {
-		return x.NodeId
+		return x.State
 	}
 	return ""
 }
 
-func (x *GetSubtreeRequest) GetMaxDepth() int32 {
+func (x *JobRun) GetAttempts() int32 {
 	if x != nil {
-		return x.MaxDepth
+		return x.Attempts
 	}
 	return 0
 }
 
-type GetSubtreeResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Nodes         []*Node                `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *JobRun) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
 }
 
-func (x *GetSubtreeResponse) Reset() {
-	*x = GetSubtreeResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[21]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *JobRun) GetQueuedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.QueuedAt
+	}
+	return nil
 }
 
-func (x *GetSubtreeResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *JobRun) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
 }
 
-func (*GetSubtreeResponse) ProtoMessage() {}
+func (x *JobRun) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
 
-func (x *GetSubtreeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[21]
+func (x *JobRun) GetNextAttempt() *timestamppb.Timestamp {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.NextAttempt
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use GetSubtreeResponse.ProtoReflect.Descriptor instead.
-func (*GetSubtreeResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{21}
+func (x *JobRun) GetOutputs() int32 {
+	if x != nil {
+		return x.Outputs
+	}
+	return 0
 }
 
-func (x *GetSubtreeResponse) GetNodes() []*Node {
+func (x *JobRun) GetRerun() bool {
 	if x != nil {
-		return x.Nodes
+		return x.Rerun
 	}
-	return nil
+	return false
 }
 
-type GetAncestorPathRequest struct {
+type ListJobRunsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
-	NodeId        string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Job           string                 `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`                           // Empty matches every job
+	PolicyId      string                 `protobuf:"bytes,2,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"` // Empty matches every policy
+	State         string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`                       // Empty matches every state
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetAncestorPathRequest) Reset() {
-	*x = GetAncestorPathRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[22]
+func (x *ListJobRunsRequest) Reset() {
+	*x = ListJobRunsRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[176]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAncestorPathRequest) String() string {
+func (x *ListJobRunsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAncestorPathRequest) ProtoMessage() {}
+func (*ListJobRunsRequest) ProtoMessage() {}
 
-func (x *GetAncestorPathRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[22]
+func (x *ListJobRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[176]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1583,47 +10629,55 @@ func (x *GetAncestorPathRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAncestorPathRequest.ProtoReflect.Descriptor instead.
-func (*GetAncestorPathRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use ListJobRunsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobRunsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{176}
 }
 
-func (x *GetAncestorPathRequest) GetPolicyId() string {
+func (x *ListJobRunsRequest) GetJob() string {
+	if x != nil {
+		return x.Job
+	}
+	return ""
+}
+
+func (x *ListJobRunsRequest) GetPolicyId() string {
 	if x != nil {
 		return x.PolicyId
 	}
 	return ""
 }
 
-func (x *GetAncestorPathRequest) GetNodeId() string {
+func (x *ListJobRunsRequest) GetState() string {
 	if x != nil {
-		return x.NodeId
+		return x.State
 	}
 	return ""
 }
 
-type GetAncestorPathResponse struct {
+type ListJobRunsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ancestors     []*Node                `protobuf:"bytes,1,rep,name=ancestors,proto3" json:"ancestors,omitempty"` // From root to node
+	Runs          []*JobRun              `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"` // The latest run of each job over each policy
+	Jobs          []string               `protobuf:"bytes,2,rep,name=jobs,proto3" json:"jobs,omitempty"` // Jobs this server runs; empty on followers
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetAncestorPathResponse) Reset() {
-	*x = GetAncestorPathResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[23]
+func (x *ListJobRunsResponse) Reset() {
+	*x = ListJobRunsResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[177]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAncestorPathResponse) String() string {
+func (x *ListJobRunsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAncestorPathResponse) ProtoMessage() {}
+func (*ListJobRunsResponse) ProtoMessage() {}
 
-func (x *GetAncestorPathResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[23]
+func (x *ListJobRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[177]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1634,42 +10688,48 @@ func (x *GetAncestorPathResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAncestorPathResponse.ProtoReflect.Descriptor instead.
-func (*GetAncestorPathResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use ListJobRunsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobRunsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{177}
 }
 
-func (x *GetAncestorPathResponse) GetAncestors() []*Node {
+func (x *ListJobRunsResponse) GetRuns() []*JobRun {
 	if x != nil {
-		return x.Ancestors
+		return x.Runs
 	}
 	return nil
 }
 
-type SearchRequest struct {
+func (x *ListJobRunsResponse) GetJobs() []string {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type TriggerJobRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
-	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
-	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Job           string                 `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	PolicyId      string                 `protobuf:"bytes,2,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchRequest) Reset() {
-	*x = SearchRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[24]
+func (x *TriggerJobRequest) Reset() {
+	*x = TriggerJobRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[178]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchRequest) String() string {
+func (x *TriggerJobRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchRequest) ProtoMessage() {}
+func (*TriggerJobRequest) ProtoMessage() {}
 
-func (x *SearchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[24]
+func (x *TriggerJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[178]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1680,54 +10740,47 @@ func (x *SearchRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
-func (*SearchRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use TriggerJobRequest.ProtoReflect.Descriptor instead.
+func (*TriggerJobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{178}
 }
 
-func (x *SearchRequest) GetPolicyId() string {
+func (x *TriggerJobRequest) GetJob() string {
 	if x != nil {
-		return x.PolicyId
+		return x.Job
 	}
 	return ""
 }
 
-func (x *SearchRequest) GetQuery() string {
+func (x *TriggerJobRequest) GetPolicyId() string {
 	if x != nil {
-		return x.Query
+		return x.PolicyId
 	}
 	return ""
 }
 
-func (x *SearchRequest) GetLimit() int32 {
-	if x != nil {
-		return x.Limit
-	}
-	return 0
-}
-
-type SearchResponse struct {
+type TriggerJobResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Run           *JobRun                `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"` // The run as queued
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchResponse) Reset() {
-	*x = SearchResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[25]
+func (x *TriggerJobResponse) Reset() {
+	*x = TriggerJobResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[179]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchResponse) String() string {
+func (x *TriggerJobResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchResponse) ProtoMessage() {}
+func (*TriggerJobResponse) ProtoMessage() {}
 
-func (x *SearchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[25]
+func (x *TriggerJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[179]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1738,41 +10791,39 @@ func (x *SearchResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
-func (*SearchResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{25}
+// Deprecated: Use TriggerJobResponse.ProtoReflect.Descriptor instead.
+func (*TriggerJobResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{179}
 }
 
-func (x *SearchResponse) GetResults() []*SearchResult {
+func (x *TriggerJobResponse) GetRun() *JobRun {
 	if x != nil {
-		return x.Results
+		return x.Run
 	}
 	return nil
 }
 
-type SearchResult struct {
+type HealthRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
-	Score         float32                `protobuf:"fixed32,2,opt,name=score,proto3" json:"score,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchResult) Reset() {
-	*x = SearchResult{}
-	mi := &file_proto_treestore_proto_msgTypes[26]
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[180]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchResult) String() string {
+func (x *HealthRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchResult) ProtoMessage() {}
+func (*HealthRequest) ProtoMessage() {}
 
-func (x *SearchResult) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[26]
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[180]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1783,48 +10834,35 @@ func (x *SearchResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
-func (*SearchResult) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{26}
-}
-
-func (x *SearchResult) GetNode() *Node {
-	if x != nil {
-		return x.Node
-	}
-	return nil
-}
-
-func (x *SearchResult) GetScore() float32 {
-	if x != nil {
-		return x.Score
-	}
-	return 0
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{180}
 }
 
-type GetNodesByPageRequest struct {
+type HealthResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
-	PageNumber    int32                  `protobuf:"varint,2,opt,name=page_number,json=pageNumber,proto3" json:"page_number,omitempty"`
+	Healthy       bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	UptimeSeconds int64                  `protobuf:"varint,3,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetNodesByPageRequest) Reset() {
-	*x = GetNodesByPageRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[27]
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[181]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetNodesByPageRequest) String() string {
+func (x *HealthResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetNodesByPageRequest) ProtoMessage() {}
+func (*HealthResponse) ProtoMessage() {}
 
-func (x *GetNodesByPageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[27]
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[181]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1835,47 +10873,57 @@ func (x *GetNodesByPageRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetNodesByPageRequest.ProtoReflect.Descriptor instead.
-func (*GetNodesByPageRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{27}
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{181}
 }
 
-func (x *GetNodesByPageRequest) GetPolicyId() string {
+func (x *HealthResponse) GetHealthy() bool {
 	if x != nil {
-		return x.PolicyId
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *HealthResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
 	}
 	return ""
 }
 
-func (x *GetNodesByPageRequest) GetPageNumber() int32 {
+func (x *HealthResponse) GetUptimeSeconds() int64 {
 	if x != nil {
-		return x.PageNumber
+		return x.UptimeSeconds
 	}
 	return 0
 }
 
-type GetNodesByPageResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Nodes         []*Node                `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+type StatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Estimate node and version counts from a sample of B+Tree leaves instead
+	// of scanning them; the *_error fields then bound the estimates
+	Approximate   bool   `protobuf:"varint,1,opt,name=approximate,proto3" json:"approximate,omitempty"`
+	PolicyId      string `protobuf:"bytes,2,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"` // Count only this policy; empty counts all
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetNodesByPageResponse) Reset() {
-	*x = GetNodesByPageResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[28]
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[182]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetNodesByPageResponse) String() string {
+func (x *StatsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetNodesByPageResponse) ProtoMessage() {}
+func (*StatsRequest) ProtoMessage() {}
 
-func (x *GetNodesByPageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[28]
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[182]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1886,41 +10934,58 @@ func (x *GetNodesByPageResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetNodesByPageResponse.ProtoReflect.Descriptor instead.
-func (*GetNodesByPageResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{28}
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{182}
 }
 
-func (x *GetNodesByPageResponse) GetNodes() []*Node {
+func (x *StatsRequest) GetApproximate() bool {
 	if x != nil {
-		return x.Nodes
+		return x.Approximate
 	}
-	return nil
+	return false
 }
 
-type GetVersionAsOfRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
-	AsOfTime      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=as_of_time,json=asOfTime,proto3" json:"as_of_time,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *StatsRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
 }
 
-func (x *GetVersionAsOfRequest) Reset() {
-	*x = GetVersionAsOfRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[29]
+type StatsResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	TotalDocuments     int64                  `protobuf:"varint,1,opt,name=total_documents,json=totalDocuments,proto3" json:"total_documents,omitempty"`
+	TotalNodes         int64                  `protobuf:"varint,2,opt,name=total_nodes,json=totalNodes,proto3" json:"total_nodes,omitempty"`
+	TotalVersions      int64                  `protobuf:"varint,3,opt,name=total_versions,json=totalVersions,proto3" json:"total_versions,omitempty"`
+	DbSizeBytes        int64                  `protobuf:"varint,4,opt,name=db_size_bytes,json=dbSizeBytes,proto3" json:"db_size_bytes,omitempty"`
+	OperationCounts    map[string]int64       `protobuf:"bytes,5,rep,name=operation_counts,json=operationCounts,proto3" json:"operation_counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	SyncPolicy         string                 `protobuf:"bytes,6,opt,name=sync_policy,json=syncPolicy,proto3" json:"sync_policy,omitempty"`                    // always, interval or never
+	SyncIntervalMs     int64                  `protobuf:"varint,7,opt,name=sync_interval_ms,json=syncIntervalMs,proto3" json:"sync_interval_ms,omitempty"`     // Flush period under the interval policy
+	UnflushedCommits   int64                  `protobuf:"varint,8,opt,name=unflushed_commits,json=unflushedCommits,proto3" json:"unflushed_commits,omitempty"` // Commits not yet fsynced under the interval policy
+	LastFlushError     string                 `protobuf:"bytes,9,opt,name=last_flush_error,json=lastFlushError,proto3" json:"last_flush_error,omitempty"`      // Empty unless the last background flush failed
+	Approximate        bool                   `protobuf:"varint,10,opt,name=approximate,proto3" json:"approximate,omitempty"`                                  // Node and version counts are estimates
+	TotalNodesError    int64                  `protobuf:"varint,11,opt,name=total_nodes_error,json=totalNodesError,proto3" json:"total_nodes_error,omitempty"` // Half-width of a 95% confidence interval around total_nodes
+	TotalVersionsError int64                  `protobuf:"varint,12,opt,name=total_versions_error,json=totalVersionsError,proto3" json:"total_versions_error,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *StatsResponse) Reset() {
+	*x = StatsResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[183]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetVersionAsOfRequest) String() string {
+func (x *StatsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetVersionAsOfRequest) ProtoMessage() {}
+func (*StatsResponse) ProtoMessage() {}
 
-func (x *GetVersionAsOfRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[29]
+func (x *StatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[183]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1931,143 +10996,120 @@ func (x *GetVersionAsOfRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetVersionAsOfRequest.ProtoReflect.Descriptor instead.
-func (*GetVersionAsOfRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{29}
+// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{183}
 }
 
-func (x *GetVersionAsOfRequest) GetPolicyId() string {
+func (x *StatsResponse) GetTotalDocuments() int64 {
 	if x != nil {
-		return x.PolicyId
+		return x.TotalDocuments
 	}
-	return ""
+	return 0
 }
 
-func (x *GetVersionAsOfRequest) GetAsOfTime() *timestamppb.Timestamp {
+func (x *StatsResponse) GetTotalNodes() int64 {
 	if x != nil {
-		return x.AsOfTime
+		return x.TotalNodes
 	}
-	return nil
-}
-
-type ListVersionsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
-	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *ListVersionsRequest) Reset() {
-	*x = ListVersionsRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[30]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+	return 0
 }
 
-func (x *ListVersionsRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *StatsResponse) GetTotalVersions() int64 {
+	if x != nil {
+		return x.TotalVersions
+	}
+	return 0
 }
 
-func (*ListVersionsRequest) ProtoMessage() {}
-
-func (x *ListVersionsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[30]
+func (x *StatsResponse) GetDbSizeBytes() int64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.DbSizeBytes
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use ListVersionsRequest.ProtoReflect.Descriptor instead.
-func (*ListVersionsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{30}
+func (x *StatsResponse) GetOperationCounts() map[string]int64 {
+	if x != nil {
+		return x.OperationCounts
+	}
+	return nil
 }
 
-func (x *ListVersionsRequest) GetPolicyId() string {
+func (x *StatsResponse) GetSyncPolicy() string {
 	if x != nil {
-		return x.PolicyId
+		return x.SyncPolicy
 	}
 	return ""
 }
 
-func (x *ListVersionsRequest) GetLimit() int32 {
+func (x *StatsResponse) GetSyncIntervalMs() int64 {
 	if x != nil {
-		return x.Limit
+		return x.SyncIntervalMs
 	}
 	return 0
 }
 
-type ListVersionsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Versions      []*PolicyVersion       `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *ListVersionsResponse) Reset() {
-	*x = ListVersionsResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[31]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *StatsResponse) GetUnflushedCommits() int64 {
+	if x != nil {
+		return x.UnflushedCommits
+	}
+	return 0
 }
 
-func (x *ListVersionsResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *StatsResponse) GetLastFlushError() string {
+	if x != nil {
+		return x.LastFlushError
+	}
+	return ""
 }
 
-func (*ListVersionsResponse) ProtoMessage() {}
-
-func (x *ListVersionsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[31]
+func (x *StatsResponse) GetApproximate() bool {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Approximate
 	}
-	return mi.MessageOf(x)
+	return false
 }
 
-// Deprecated: Use ListVersionsResponse.ProtoReflect.Descriptor instead.
-func (*ListVersionsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{31}
+func (x *StatsResponse) GetTotalNodesError() int64 {
+	if x != nil {
+		return x.TotalNodesError
+	}
+	return 0
 }
 
-func (x *ListVersionsResponse) GetVersions() []*PolicyVersion {
+func (x *StatsResponse) GetTotalVersionsError() int64 {
 	if x != nil {
-		return x.Versions
+		return x.TotalVersionsError
 	}
-	return nil
+	return 0
 }
 
-type StoreToolResultRequest struct {
+// Reports the bytes of keys and values each store and policy holds. It reads
+// every key, so it costs about as much as a full scan of the database.
+type StorageBreakdownRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Result        *ToolResult            `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"` // Report only this policy; empty reports all
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                      // Most policies to report, largest first; 0 reports all
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StoreToolResultRequest) Reset() {
-	*x = StoreToolResultRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[32]
+func (x *StorageBreakdownRequest) Reset() {
+	*x = StorageBreakdownRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[184]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StoreToolResultRequest) String() string {
+func (x *StorageBreakdownRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StoreToolResultRequest) ProtoMessage() {}
+func (*StorageBreakdownRequest) ProtoMessage() {}
 
-func (x *StoreToolResultRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[32]
+func (x *StorageBreakdownRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[184]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2078,41 +11120,49 @@ func (x *StoreToolResultRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StoreToolResultRequest.ProtoReflect.Descriptor instead.
-func (*StoreToolResultRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{32}
+// Deprecated: Use StorageBreakdownRequest.ProtoReflect.Descriptor instead.
+func (*StorageBreakdownRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{184}
 }
 
-func (x *StoreToolResultRequest) GetResult() *ToolResult {
+func (x *StorageBreakdownRequest) GetPolicyId() string {
 	if x != nil {
-		return x.Result
+		return x.PolicyId
 	}
-	return nil
+	return ""
 }
 
-type StoreToolResultResponse struct {
+func (x *StorageBreakdownRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type StoreUsage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Store         string                 `protobuf:"bytes,1,opt,name=store,proto3" json:"store,omitempty"` // documents, versions, metadata, conversations or other
+	Keys          int64                  `protobuf:"varint,2,opt,name=keys,proto3" json:"keys,omitempty"`
+	Bytes         int64                  `protobuf:"varint,3,opt,name=bytes,proto3" json:"bytes,omitempty"` // Key and value bytes, before page overhead
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StoreToolResultResponse) Reset() {
-	*x = StoreToolResultResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[33]
+func (x *StoreUsage) Reset() {
+	*x = StoreUsage{}
+	mi := &file_proto_treestore_proto_msgTypes[185]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StoreToolResultResponse) String() string {
+func (x *StoreUsage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StoreToolResultResponse) ProtoMessage() {}
+func (*StoreUsage) ProtoMessage() {}
 
-func (x *StoreToolResultResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[33]
+func (x *StoreUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[185]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2123,49 +11173,56 @@ func (x *StoreToolResultResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StoreToolResultResponse.ProtoReflect.Descriptor instead.
-func (*StoreToolResultResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{33}
+// Deprecated: Use StoreUsage.ProtoReflect.Descriptor instead.
+func (*StoreUsage) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{185}
 }
 
-func (x *StoreToolResultResponse) GetSuccess() bool {
+func (x *StoreUsage) GetStore() string {
 	if x != nil {
-		return x.Success
+		return x.Store
 	}
-	return false
+	return ""
 }
 
-func (x *StoreToolResultResponse) GetMessage() string {
+func (x *StoreUsage) GetKeys() int64 {
 	if x != nil {
-		return x.Message
+		return x.Keys
 	}
-	return ""
+	return 0
 }
 
-type GetToolResultsRequest struct {
+func (x *StoreUsage) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+type PolicyUsage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
-	ToolName      string                 `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"` // Optional filter
-	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Bytes         int64                  `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	Stores        []*StoreUsage          `protobuf:"bytes,3,rep,name=stores,proto3" json:"stores,omitempty"` // Stores holding records of the policy
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetToolResultsRequest) Reset() {
-	*x = GetToolResultsRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[34]
+func (x *PolicyUsage) Reset() {
+	*x = PolicyUsage{}
+	mi := &file_proto_treestore_proto_msgTypes[186]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetToolResultsRequest) String() string {
+func (x *PolicyUsage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetToolResultsRequest) ProtoMessage() {}
+func (*PolicyUsage) ProtoMessage() {}
 
-func (x *GetToolResultsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[34]
+func (x *PolicyUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[186]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2176,54 +11233,57 @@ func (x *GetToolResultsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetToolResultsRequest.ProtoReflect.Descriptor instead.
-func (*GetToolResultsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{34}
+// Deprecated: Use PolicyUsage.ProtoReflect.Descriptor instead.
+func (*PolicyUsage) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{186}
 }
 
-func (x *GetToolResultsRequest) GetPolicyId() string {
+func (x *PolicyUsage) GetPolicyId() string {
 	if x != nil {
 		return x.PolicyId
 	}
 	return ""
 }
 
-func (x *GetToolResultsRequest) GetToolName() string {
+func (x *PolicyUsage) GetBytes() int64 {
 	if x != nil {
-		return x.ToolName
+		return x.Bytes
 	}
-	return ""
+	return 0
 }
 
-func (x *GetToolResultsRequest) GetLimit() int32 {
+func (x *PolicyUsage) GetStores() []*StoreUsage {
 	if x != nil {
-		return x.Limit
+		return x.Stores
 	}
-	return 0
+	return nil
 }
 
-type GetToolResultsResponse struct {
+type StorageBreakdownResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Results       []*ToolResult          `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Stores        []*StoreUsage          `protobuf:"bytes,1,rep,name=stores,proto3" json:"stores,omitempty"`                                 // Whole database, including records of no policy
+	Policies      []*PolicyUsage         `protobuf:"bytes,2,rep,name=policies,proto3" json:"policies,omitempty"`                             // Largest first
+	TotalBytes    int64                  `protobuf:"varint,3,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`      // Sum of the stores
+	DbSizeBytes   int64                  `protobuf:"varint,4,opt,name=db_size_bytes,json=dbSizeBytes,proto3" json:"db_size_bytes,omitempty"` // File size; the difference is page overhead and free pages
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetToolResultsResponse) Reset() {
-	*x = GetToolResultsResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[35]
+func (x *StorageBreakdownResponse) Reset() {
+	*x = StorageBreakdownResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[187]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetToolResultsResponse) String() string {
+func (x *StorageBreakdownResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetToolResultsResponse) ProtoMessage() {}
+func (*StorageBreakdownResponse) ProtoMessage() {}
 
-func (x *GetToolResultsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[35]
+func (x *StorageBreakdownResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[187]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2234,40 +11294,62 @@ func (x *GetToolResultsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetToolResultsResponse.ProtoReflect.Descriptor instead.
-func (*GetToolResultsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{35}
+// Deprecated: Use StorageBreakdownResponse.ProtoReflect.Descriptor instead.
+func (*StorageBreakdownResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{187}
 }
 
-func (x *GetToolResultsResponse) GetResults() []*ToolResult {
+func (x *StorageBreakdownResponse) GetStores() []*StoreUsage {
 	if x != nil {
-		return x.Results
+		return x.Stores
 	}
 	return nil
 }
 
-type StoreTrajectoryRequest struct {
+func (x *StorageBreakdownResponse) GetPolicies() []*PolicyUsage {
+	if x != nil {
+		return x.Policies
+	}
+	return nil
+}
+
+func (x *StorageBreakdownResponse) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *StorageBreakdownResponse) GetDbSizeBytes() int64 {
+	if x != nil {
+		return x.DbSizeBytes
+	}
+	return 0
+}
+
+// Makes every commit durable in the database file and removes WAL files the
+// checkpoint makes unnecessary, as the background checkpointer does
+type CheckpointRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Trajectory    *Trajectory            `protobuf:"bytes,1,opt,name=trajectory,proto3" json:"trajectory,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StoreTrajectoryRequest) Reset() {
-	*x = StoreTrajectoryRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[36]
+func (x *CheckpointRequest) Reset() {
+	*x = CheckpointRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[188]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StoreTrajectoryRequest) String() string {
+func (x *CheckpointRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StoreTrajectoryRequest) ProtoMessage() {}
+func (*CheckpointRequest) ProtoMessage() {}
 
-func (x *StoreTrajectoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[36]
+func (x *CheckpointRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[188]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2278,41 +11360,34 @@ func (x *StoreTrajectoryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StoreTrajectoryRequest.ProtoReflect.Descriptor instead.
-func (*StoreTrajectoryRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{36}
-}
-
-func (x *StoreTrajectoryRequest) GetTrajectory() *Trajectory {
-	if x != nil {
-		return x.Trajectory
-	}
-	return nil
+// Deprecated: Use CheckpointRequest.ProtoReflect.Descriptor instead.
+func (*CheckpointRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{188}
 }
 
-type StoreTrajectoryResponse struct {
+type CheckpointResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	LastLsn       uint64                 `protobuf:"varint,1,opt,name=last_lsn,json=lastLsn,proto3" json:"last_lsn,omitempty"` // WAL entries up to here are in the database file
+	DurationMs    int64                  `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StoreTrajectoryResponse) Reset() {
-	*x = StoreTrajectoryResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[37]
+func (x *CheckpointResponse) Reset() {
+	*x = CheckpointResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[189]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StoreTrajectoryResponse) String() string {
+func (x *CheckpointResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StoreTrajectoryResponse) ProtoMessage() {}
+func (*CheckpointResponse) ProtoMessage() {}
 
-func (x *StoreTrajectoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[37]
+func (x *CheckpointResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[189]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2323,48 +11398,49 @@ func (x *StoreTrajectoryResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StoreTrajectoryResponse.ProtoReflect.Descriptor instead.
-func (*StoreTrajectoryResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{37}
+// Deprecated: Use CheckpointResponse.ProtoReflect.Descriptor instead.
+func (*CheckpointResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{189}
 }
 
-func (x *StoreTrajectoryResponse) GetSuccess() bool {
+func (x *CheckpointResponse) GetLastLsn() uint64 {
 	if x != nil {
-		return x.Success
+		return x.LastLsn
 	}
-	return false
+	return 0
 }
 
-func (x *StoreTrajectoryResponse) GetMessage() string {
+func (x *CheckpointResponse) GetDurationMs() int64 {
 	if x != nil {
-		return x.Message
+		return x.DurationMs
 	}
-	return ""
+	return 0
 }
 
-type GetTrajectoriesRequest struct {
+// Finds pages neither in the tree nor free, left by crashes or failed
+// commits, and returns them to the free list. Writes wait while it runs.
+type CompactRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	CaseId        string                 `protobuf:"bytes,1,opt,name=case_id,json=caseId,proto3" json:"case_id,omitempty"`
-	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	DryRun        bool                   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Report leaked pages without reclaiming them
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTrajectoriesRequest) Reset() {
-	*x = GetTrajectoriesRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[38]
+func (x *CompactRequest) Reset() {
+	*x = CompactRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[190]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTrajectoriesRequest) String() string {
+func (x *CompactRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTrajectoriesRequest) ProtoMessage() {}
+func (*CompactRequest) ProtoMessage() {}
 
-func (x *GetTrajectoriesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[38]
+func (x *CompactRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[190]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2375,47 +11451,45 @@ func (x *GetTrajectoriesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTrajectoriesRequest.ProtoReflect.Descriptor instead.
-func (*GetTrajectoriesRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{38}
-}
-
-func (x *GetTrajectoriesRequest) GetCaseId() string {
-	if x != nil {
-		return x.CaseId
-	}
-	return ""
+// Deprecated: Use CompactRequest.ProtoReflect.Descriptor instead.
+func (*CompactRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{190}
 }
 
-func (x *GetTrajectoriesRequest) GetLimit() int32 {
+func (x *CompactRequest) GetDryRun() bool {
 	if x != nil {
-		return x.Limit
+		return x.DryRun
 	}
-	return 0
+	return false
 }
 
-type GetTrajectoriesResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Trajectories  []*Trajectory          `protobuf:"bytes,1,rep,name=trajectories,proto3" json:"trajectories,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type CompactResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Pages            uint64                 `protobuf:"varint,1,opt,name=pages,proto3" json:"pages,omitempty"` // Pages in the file, including the meta page
+	TreePages        int64                  `protobuf:"varint,2,opt,name=tree_pages,json=treePages,proto3" json:"tree_pages,omitempty"`
+	FreePages        int64                  `protobuf:"varint,3,opt,name=free_pages,json=freePages,proto3" json:"free_pages,omitempty"`
+	LeakedPages      int64                  `protobuf:"varint,4,opt,name=leaked_pages,json=leakedPages,proto3" json:"leaked_pages,omitempty"`
+	ReclaimedPages   int64                  `protobuf:"varint,5,opt,name=reclaimed_pages,json=reclaimedPages,proto3" json:"reclaimed_pages,omitempty"`
+	ConflictingPages int64                  `protobuf:"varint,6,opt,name=conflicting_pages,json=conflictingPages,proto3" json:"conflicting_pages,omitempty"` // Counted more than once; reported, never reclaimed
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
-func (x *GetTrajectoriesResponse) Reset() {
-	*x = GetTrajectoriesResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[39]
+func (x *CompactResponse) Reset() {
+	*x = CompactResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[191]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTrajectoriesResponse) String() string {
+func (x *CompactResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTrajectoriesResponse) ProtoMessage() {}
+func (*CompactResponse) ProtoMessage() {}
 
-func (x *GetTrajectoriesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[39]
+func (x *CompactResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[191]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2426,40 +11500,76 @@ func (x *GetTrajectoriesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTrajectoriesResponse.ProtoReflect.Descriptor instead.
-func (*GetTrajectoriesResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{39}
+// Deprecated: Use CompactResponse.ProtoReflect.Descriptor instead.
+func (*CompactResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{191}
 }
 
-func (x *GetTrajectoriesResponse) GetTrajectories() []*Trajectory {
+func (x *CompactResponse) GetPages() uint64 {
 	if x != nil {
-		return x.Trajectories
+		return x.Pages
 	}
-	return nil
+	return 0
 }
 
-type StoreCrossReferenceRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	CrossReference *CrossReference        `protobuf:"bytes,1,opt,name=cross_reference,json=crossReference,proto3" json:"cross_reference,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+func (x *CompactResponse) GetTreePages() int64 {
+	if x != nil {
+		return x.TreePages
+	}
+	return 0
 }
 
-func (x *StoreCrossReferenceRequest) Reset() {
-	*x = StoreCrossReferenceRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[40]
+func (x *CompactResponse) GetFreePages() int64 {
+	if x != nil {
+		return x.FreePages
+	}
+	return 0
+}
+
+func (x *CompactResponse) GetLeakedPages() int64 {
+	if x != nil {
+		return x.LeakedPages
+	}
+	return 0
+}
+
+func (x *CompactResponse) GetReclaimedPages() int64 {
+	if x != nil {
+		return x.ReclaimedPages
+	}
+	return 0
+}
+
+func (x *CompactResponse) GetConflictingPages() int64 {
+	if x != nil {
+		return x.ConflictingPages
+	}
+	return 0
+}
+
+// Rebuilds the keyword index of document nodes used by GlobalSearch
+type ReindexRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"` // Empty rebuilds every policy
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReindexRequest) Reset() {
+	*x = ReindexRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[192]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StoreCrossReferenceRequest) String() string {
+func (x *ReindexRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StoreCrossReferenceRequest) ProtoMessage() {}
+func (*ReindexRequest) ProtoMessage() {}
 
-func (x *StoreCrossReferenceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[40]
+func (x *ReindexRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[192]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2470,41 +11580,40 @@ func (x *StoreCrossReferenceRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StoreCrossReferenceRequest.ProtoReflect.Descriptor instead.
-func (*StoreCrossReferenceRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{40}
+// Deprecated: Use ReindexRequest.ProtoReflect.Descriptor instead.
+func (*ReindexRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{192}
 }
 
-func (x *StoreCrossReferenceRequest) GetCrossReference() *CrossReference {
+func (x *ReindexRequest) GetPolicyId() string {
 	if x != nil {
-		return x.CrossReference
+		return x.PolicyId
 	}
-	return nil
+	return ""
 }
 
-type StoreCrossReferenceResponse struct {
+type ReindexResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	NodesIndexed  int64                  `protobuf:"varint,1,opt,name=nodes_indexed,json=nodesIndexed,proto3" json:"nodes_indexed,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StoreCrossReferenceResponse) Reset() {
-	*x = StoreCrossReferenceResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[41]
+func (x *ReindexResponse) Reset() {
+	*x = ReindexResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[193]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StoreCrossReferenceResponse) String() string {
+func (x *ReindexResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StoreCrossReferenceResponse) ProtoMessage() {}
+func (*ReindexResponse) ProtoMessage() {}
 
-func (x *StoreCrossReferenceResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[41]
+func (x *ReindexResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[193]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2515,48 +11624,40 @@ func (x *StoreCrossReferenceResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StoreCrossReferenceResponse.ProtoReflect.Descriptor instead.
-func (*StoreCrossReferenceResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{41}
-}
-
-func (x *StoreCrossReferenceResponse) GetSuccess() bool {
-	if x != nil {
-		return x.Success
-	}
-	return false
+// Deprecated: Use ReindexResponse.ProtoReflect.Descriptor instead.
+func (*ReindexResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{193}
 }
 
-func (x *StoreCrossReferenceResponse) GetMessage() string {
+func (x *ReindexResponse) GetNodesIndexed() int64 {
 	if x != nil {
-		return x.Message
+		return x.NodesIndexed
 	}
-	return ""
+	return 0
 }
 
-type GetCrossReferencesRequest struct {
+// Syncs commits written but not yet fsynced under the interval sync policy
+type FlushRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
-	NodeId        string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetCrossReferencesRequest) Reset() {
-	*x = GetCrossReferencesRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[42]
+func (x *FlushRequest) Reset() {
+	*x = FlushRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[194]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetCrossReferencesRequest) String() string {
+func (x *FlushRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetCrossReferencesRequest) ProtoMessage() {}
+func (*FlushRequest) ProtoMessage() {}
 
-func (x *GetCrossReferencesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[42]
+func (x *FlushRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[194]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2567,47 +11668,33 @@ func (x *GetCrossReferencesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetCrossReferencesRequest.ProtoReflect.Descriptor instead.
-func (*GetCrossReferencesRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{42}
-}
-
-func (x *GetCrossReferencesRequest) GetPolicyId() string {
-	if x != nil {
-		return x.PolicyId
-	}
-	return ""
-}
-
-func (x *GetCrossReferencesRequest) GetNodeId() string {
-	if x != nil {
-		return x.NodeId
-	}
-	return ""
+// Deprecated: Use FlushRequest.ProtoReflect.Descriptor instead.
+func (*FlushRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{194}
 }
 
-type GetCrossReferencesResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	References    []*CrossReference      `protobuf:"bytes,1,rep,name=references,proto3" json:"references,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type FlushResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	FlushedCommits int64                  `protobuf:"varint,1,opt,name=flushed_commits,json=flushedCommits,proto3" json:"flushed_commits,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *GetCrossReferencesResponse) Reset() {
-	*x = GetCrossReferencesResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[43]
+func (x *FlushResponse) Reset() {
+	*x = FlushResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[195]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetCrossReferencesResponse) String() string {
+func (x *FlushResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetCrossReferencesResponse) ProtoMessage() {}
+func (*FlushResponse) ProtoMessage() {}
 
-func (x *GetCrossReferencesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[43]
+func (x *FlushResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[195]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2618,40 +11705,44 @@ func (x *GetCrossReferencesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetCrossReferencesResponse.ProtoReflect.Descriptor instead.
-func (*GetCrossReferencesResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{43}
+// Deprecated: Use FlushResponse.ProtoReflect.Descriptor instead.
+func (*FlushResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{195}
 }
 
-func (x *GetCrossReferencesResponse) GetReferences() []*CrossReference {
+func (x *FlushResponse) GetFlushedCommits() int64 {
 	if x != nil {
-		return x.References
+		return x.FlushedCommits
 	}
-	return nil
+	return 0
 }
 
-type StoreContradictionRequest struct {
+// Writes a backup to a directory on the server's host. A full backup makes a
+// base snapshot, holding off writes while it copies the file; an incremental
+// one appends the WAL entries written since the last backup in dir.
+type BackupRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Contradiction *Contradiction         `protobuf:"bytes,1,opt,name=contradiction,proto3" json:"contradiction,omitempty"`
+	Dir           string                 `protobuf:"bytes,1,opt,name=dir,proto3" json:"dir,omitempty"`
+	Incremental   bool                   `protobuf:"varint,2,opt,name=incremental,proto3" json:"incremental,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StoreContradictionRequest) Reset() {
-	*x = StoreContradictionRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[44]
+func (x *BackupRequest) Reset() {
+	*x = BackupRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[196]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StoreContradictionRequest) String() string {
+func (x *BackupRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StoreContradictionRequest) ProtoMessage() {}
+func (*BackupRequest) ProtoMessage() {}
 
-func (x *StoreContradictionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[44]
+func (x *BackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[196]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2662,41 +11753,50 @@ func (x *StoreContradictionRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StoreContradictionRequest.ProtoReflect.Descriptor instead.
-func (*StoreContradictionRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{44}
+// Deprecated: Use BackupRequest.ProtoReflect.Descriptor instead.
+func (*BackupRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{196}
 }
 
-func (x *StoreContradictionRequest) GetContradiction() *Contradiction {
+func (x *BackupRequest) GetDir() string {
 	if x != nil {
-		return x.Contradiction
+		return x.Dir
 	}
-	return nil
+	return ""
 }
 
-type StoreContradictionResponse struct {
+func (x *BackupRequest) GetIncremental() bool {
+	if x != nil {
+		return x.Incremental
+	}
+	return false
+}
+
+type BackupResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	FromLsn       uint64                 `protobuf:"varint,1,opt,name=from_lsn,json=fromLsn,proto3" json:"from_lsn,omitempty"` // Incremental: first LSN after the previous backup
+	ToLsn         uint64                 `protobuf:"varint,2,opt,name=to_lsn,json=toLsn,proto3" json:"to_lsn,omitempty"`       // Last LSN the backup covers
+	Entries       int64                  `protobuf:"varint,3,opt,name=entries,proto3" json:"entries,omitempty"`                // Incremental: WAL entries written
+	File          string                 `protobuf:"bytes,4,opt,name=file,proto3" json:"file,omitempty"`                       // File written within dir
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StoreContradictionResponse) Reset() {
-	*x = StoreContradictionResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[45]
+func (x *BackupResponse) Reset() {
+	*x = BackupResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[197]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StoreContradictionResponse) String() string {
+func (x *BackupResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StoreContradictionResponse) ProtoMessage() {}
+func (*BackupResponse) ProtoMessage() {}
 
-func (x *StoreContradictionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[45]
+func (x *BackupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[197]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2707,47 +11807,61 @@ func (x *StoreContradictionResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StoreContradictionResponse.ProtoReflect.Descriptor instead.
-func (*StoreContradictionResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{45}
+// Deprecated: Use BackupResponse.ProtoReflect.Descriptor instead.
+func (*BackupResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{197}
 }
 
-func (x *StoreContradictionResponse) GetSuccess() bool {
+func (x *BackupResponse) GetFromLsn() uint64 {
 	if x != nil {
-		return x.Success
+		return x.FromLsn
 	}
-	return false
+	return 0
 }
 
-func (x *StoreContradictionResponse) GetMessage() string {
+func (x *BackupResponse) GetToLsn() uint64 {
 	if x != nil {
-		return x.Message
+		return x.ToLsn
+	}
+	return 0
+}
+
+func (x *BackupResponse) GetEntries() int64 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *BackupResponse) GetFile() string {
+	if x != nil {
+		return x.File
 	}
 	return ""
 }
 
-type StorePromptRequest struct {
+type SetLogLevelRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Prompt        *PromptTemplate        `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	Level         string                 `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"` // debug, info, warn or error
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StorePromptRequest) Reset() {
-	*x = StorePromptRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[46]
+func (x *SetLogLevelRequest) Reset() {
+	*x = SetLogLevelRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[198]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StorePromptRequest) String() string {
+func (x *SetLogLevelRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StorePromptRequest) ProtoMessage() {}
+func (*SetLogLevelRequest) ProtoMessage() {}
 
-func (x *StorePromptRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[46]
+func (x *SetLogLevelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[198]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2758,41 +11872,40 @@ func (x *StorePromptRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StorePromptRequest.ProtoReflect.Descriptor instead.
-func (*StorePromptRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{46}
+// Deprecated: Use SetLogLevelRequest.ProtoReflect.Descriptor instead.
+func (*SetLogLevelRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{198}
 }
 
-func (x *StorePromptRequest) GetPrompt() *PromptTemplate {
+func (x *SetLogLevelRequest) GetLevel() string {
 	if x != nil {
-		return x.Prompt
+		return x.Level
 	}
-	return nil
+	return ""
 }
 
-type StorePromptResponse struct {
+type SetLogLevelResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	PreviousLevel string                 `protobuf:"bytes,1,opt,name=previous_level,json=previousLevel,proto3" json:"previous_level,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StorePromptResponse) Reset() {
-	*x = StorePromptResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[47]
+func (x *SetLogLevelResponse) Reset() {
+	*x = SetLogLevelResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[199]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StorePromptResponse) String() string {
+func (x *SetLogLevelResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StorePromptResponse) ProtoMessage() {}
+func (*SetLogLevelResponse) ProtoMessage() {}
 
-func (x *StorePromptResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[47]
+func (x *SetLogLevelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[199]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2803,47 +11916,43 @@ func (x *StorePromptResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StorePromptResponse.ProtoReflect.Descriptor instead.
-func (*StorePromptResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{47}
-}
-
-func (x *StorePromptResponse) GetSuccess() bool {
-	if x != nil {
-		return x.Success
-	}
-	return false
+// Deprecated: Use SetLogLevelResponse.ProtoReflect.Descriptor instead.
+func (*SetLogLevelResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{199}
 }
 
-func (x *StorePromptResponse) GetMessage() string {
+func (x *SetLogLevelResponse) GetPreviousLevel() string {
 	if x != nil {
-		return x.Message
+		return x.PreviousLevel
 	}
 	return ""
 }
 
-type GetPromptRequest struct {
+type TailLogsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PromptId      string                 `protobuf:"bytes,1,opt,name=prompt_id,json=promptId,proto3" json:"prompt_id,omitempty"`
+	MinLevel      string                 `protobuf:"bytes,1,opt,name=min_level,json=minLevel,proto3" json:"min_level,omitempty"` // debug, info, warn or error; empty for every level
+	Method        string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`                     // Substring of the gRPC method, such as "GetNode"; empty for every event
+	Recent        int32                  `protobuf:"varint,3,opt,name=recent,proto3" json:"recent,omitempty"`                    // Kept events sent first, newest last: 0 for all, negative for none
+	Follow        bool                   `protobuf:"varint,4,opt,name=follow,proto3" json:"follow,omitempty"`                    // Keep streaming new events until the call is cancelled
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetPromptRequest) Reset() {
-	*x = GetPromptRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[48]
+func (x *TailLogsRequest) Reset() {
+	*x = TailLogsRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[200]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetPromptRequest) String() string {
+func (x *TailLogsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetPromptRequest) ProtoMessage() {}
+func (*TailLogsRequest) ProtoMessage() {}
 
-func (x *GetPromptRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[48]
+func (x *TailLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[200]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2854,40 +11963,66 @@ func (x *GetPromptRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetPromptRequest.ProtoReflect.Descriptor instead.
-func (*GetPromptRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{48}
+// Deprecated: Use TailLogsRequest.ProtoReflect.Descriptor instead.
+func (*TailLogsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{200}
 }
 
-func (x *GetPromptRequest) GetPromptId() string {
+func (x *TailLogsRequest) GetMinLevel() string {
 	if x != nil {
-		return x.PromptId
+		return x.MinLevel
 	}
 	return ""
 }
 
-type GetPromptResponse struct {
+func (x *TailLogsRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *TailLogsRequest) GetRecent() int32 {
+	if x != nil {
+		return x.Recent
+	}
+	return 0
+}
+
+func (x *TailLogsRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+type LogEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Prompt        *PromptTemplate        `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Level         string                 `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Method        string                 `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	Json          string                 `protobuf:"bytes,5,opt,name=json,proto3" json:"json,omitempty"`        // The event as logged, with every field
+	Dropped       uint64                 `protobuf:"varint,6,opt,name=dropped,proto3" json:"dropped,omitempty"` // Events dropped since the last report because the client read too slowly
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetPromptResponse) Reset() {
-	*x = GetPromptResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[49]
+func (x *LogEvent) Reset() {
+	*x = LogEvent{}
+	mi := &file_proto_treestore_proto_msgTypes[201]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetPromptResponse) String() string {
+func (x *LogEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetPromptResponse) ProtoMessage() {}
+func (*LogEvent) ProtoMessage() {}
 
-func (x *GetPromptResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[49]
+func (x *LogEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[201]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2898,40 +12033,74 @@ func (x *GetPromptResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetPromptResponse.ProtoReflect.Descriptor instead.
-func (*GetPromptResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{49}
+// Deprecated: Use LogEvent.ProtoReflect.Descriptor instead.
+func (*LogEvent) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{201}
 }
 
-func (x *GetPromptResponse) GetPrompt() *PromptTemplate {
+func (x *LogEvent) GetTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Prompt
+		return x.Time
 	}
 	return nil
 }
 
-type RecordPromptUsageRequest struct {
+func (x *LogEvent) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LogEvent) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *LogEvent) GetJson() string {
+	if x != nil {
+		return x.Json
+	}
+	return ""
+}
+
+func (x *LogEvent) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+type DumpStateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Usage         *PromptUsage           `protobuf:"bytes,1,opt,name=usage,proto3" json:"usage,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RecordPromptUsageRequest) Reset() {
-	*x = RecordPromptUsageRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[50]
+func (x *DumpStateRequest) Reset() {
+	*x = DumpStateRequest{}
+	mi := &file_proto_treestore_proto_msgTypes[202]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RecordPromptUsageRequest) String() string {
+func (x *DumpStateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RecordPromptUsageRequest) ProtoMessage() {}
+func (*DumpStateRequest) ProtoMessage() {}
 
-func (x *RecordPromptUsageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[50]
+func (x *DumpStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[202]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2942,41 +12111,74 @@ func (x *RecordPromptUsageRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RecordPromptUsageRequest.ProtoReflect.Descriptor instead.
-func (*RecordPromptUsageRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{50}
-}
-
-func (x *RecordPromptUsageRequest) GetUsage() *PromptUsage {
-	if x != nil {
-		return x.Usage
-	}
-	return nil
-}
-
-type RecordPromptUsageResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *RecordPromptUsageResponse) Reset() {
-	*x = RecordPromptUsageResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[51]
+// Deprecated: Use DumpStateRequest.ProtoReflect.Descriptor instead.
+func (*DumpStateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{202}
+}
+
+type DumpStateResponse struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	DbPath                  string                 `protobuf:"bytes,1,opt,name=db_path,json=dbPath,proto3" json:"db_path,omitempty"`
+	InMemory                bool                   `protobuf:"varint,2,opt,name=in_memory,json=inMemory,proto3" json:"in_memory,omitempty"`
+	Heap                    bool                   `protobuf:"varint,3,opt,name=heap,proto3" json:"heap,omitempty"`     // Pages held in memory rather than mapped from the file
+	Legacy                  bool                   `protobuf:"varint,4,opt,name=legacy,proto3" json:"legacy,omitempty"` // Predates page checksums; read-only
+	Encrypted               bool                   `protobuf:"varint,5,opt,name=encrypted,proto3" json:"encrypted,omitempty"`
+	Pages                   uint64                 `protobuf:"varint,6,opt,name=pages,proto3" json:"pages,omitempty"`
+	MappedBytes             int64                  `protobuf:"varint,7,opt,name=mapped_bytes,json=mappedBytes,proto3" json:"mapped_bytes,omitempty"`
+	MetaGeneration          uint64                 `protobuf:"varint,8,opt,name=meta_generation,json=metaGeneration,proto3" json:"meta_generation,omitempty"`
+	LastLsn                 uint64                 `protobuf:"varint,9,opt,name=last_lsn,json=lastLsn,proto3" json:"last_lsn,omitempty"`
+	SyncPolicy              string                 `protobuf:"bytes,10,opt,name=sync_policy,json=syncPolicy,proto3" json:"sync_policy,omitempty"`
+	UnflushedCommits        int64                  `protobuf:"varint,11,opt,name=unflushed_commits,json=unflushedCommits,proto3" json:"unflushed_commits,omitempty"`
+	Flushes                 uint64                 `protobuf:"varint,12,opt,name=flushes,proto3" json:"flushes,omitempty"`
+	LastFlush               *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=last_flush,json=lastFlush,proto3" json:"last_flush,omitempty"`
+	LastFlushError          string                 `protobuf:"bytes,14,opt,name=last_flush_error,json=lastFlushError,proto3" json:"last_flush_error,omitempty"`
+	ReadOnly                bool                   `protobuf:"varint,15,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"` // Following a leader
+	LogLevel                string                 `protobuf:"bytes,16,opt,name=log_level,json=logLevel,proto3" json:"log_level,omitempty"`
+	UptimeSeconds           int64                  `protobuf:"varint,17,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	Goroutines              int64                  `protobuf:"varint,18,opt,name=goroutines,proto3" json:"goroutines,omitempty"`
+	HeapAllocBytes          uint64                 `protobuf:"varint,19,opt,name=heap_alloc_bytes,json=heapAllocBytes,proto3" json:"heap_alloc_bytes,omitempty"`
+	RetentionSweeps         int64                  `protobuf:"varint,20,opt,name=retention_sweeps,json=retentionSweeps,proto3" json:"retention_sweeps,omitempty"`
+	OperationCounts         map[string]int64       `protobuf:"bytes,21,rep,name=operation_counts,json=operationCounts,proto3" json:"operation_counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	FormatVersion           uint32                 `protobuf:"varint,22,opt,name=format_version,json=formatVersion,proto3" json:"format_version,omitempty"`                  // On-disk format of the database file
+	MigratedFromFormat      uint32                 `protobuf:"varint,23,opt,name=migrated_from_format,json=migratedFromFormat,proto3" json:"migrated_from_format,omitempty"` // Format the file had when opened, if it was migrated
+	WalArchivedFiles        uint64                 `protobuf:"varint,24,opt,name=wal_archived_files,json=walArchivedFiles,proto3" json:"wal_archived_files,omitempty"`       // Retired WAL files archived since start
+	WalArchiveFailures      uint64                 `protobuf:"varint,25,opt,name=wal_archive_failures,json=walArchiveFailures,proto3" json:"wal_archive_failures,omitempty"`
+	WalArchiveError         string                 `protobuf:"bytes,26,opt,name=wal_archive_error,json=walArchiveError,proto3" json:"wal_archive_error,omitempty"`                 // Last archiving failure, cleared by the next success
+	FlushedLsn              uint64                 `protobuf:"varint,27,opt,name=flushed_lsn,json=flushedLsn,proto3" json:"flushed_lsn,omitempty"`                                 // WAL entries through this LSN are durable in the database file
+	CheckpointLagEntries    uint64                 `protobuf:"varint,28,opt,name=checkpoint_lag_entries,json=checkpointLagEntries,proto3" json:"checkpoint_lag_entries,omitempty"` // WAL entries written since flushed_lsn
+	CheckpointLagMs         int64                  `protobuf:"varint,29,opt,name=checkpoint_lag_ms,json=checkpointLagMs,proto3" json:"checkpoint_lag_ms,omitempty"`                // Time since the last flush while entries wait
+	Checkpoints             uint64                 `protobuf:"varint,30,opt,name=checkpoints,proto3" json:"checkpoints,omitempty"`
+	LastCheckpoint          *timestamppb.Timestamp `protobuf:"bytes,31,opt,name=last_checkpoint,json=lastCheckpoint,proto3" json:"last_checkpoint,omitempty"`
+	CheckpointError         string                 `protobuf:"bytes,32,opt,name=checkpoint_error,json=checkpointError,proto3" json:"checkpoint_error,omitempty"`     // Error of the last checkpoint, cleared by the next success
+	WalFiles                int64                  `protobuf:"varint,33,opt,name=wal_files,json=walFiles,proto3" json:"wal_files,omitempty"`                         // Live WAL files; more than the usual 3 while flushes lag
+	CompactionPasses        int64                  `protobuf:"varint,34,opt,name=compaction_passes,json=compactionPasses,proto3" json:"compaction_passes,omitempty"` // Background compaction passes that rewrote leaves
+	CompactionLeavesMoved   int64                  `protobuf:"varint,35,opt,name=compaction_leaves_moved,json=compactionLeavesMoved,proto3" json:"compaction_leaves_moved,omitempty"`
+	BloomChecks             int64                  `protobuf:"varint,36,opt,name=bloom_checks,json=bloomChecks,proto3" json:"bloom_checks,omitempty"`             // Node lookups checked against a policy's bloom filter
+	BloomRejections         int64                  `protobuf:"varint,37,opt,name=bloom_rejections,json=bloomRejections,proto3" json:"bloom_rejections,omitempty"` // Lookups the filter answered as missing without reading the tree
+	QueryCacheHits          int64                  `protobuf:"varint,38,opt,name=query_cache_hits,json=queryCacheHits,proto3" json:"query_cache_hits,omitempty"`  // Queries answered from the result cache
+	QueryCacheMisses        int64                  `protobuf:"varint,39,opt,name=query_cache_misses,json=queryCacheMisses,proto3" json:"query_cache_misses,omitempty"`
+	QueryCacheInvalidations int64                  `protobuf:"varint,40,opt,name=query_cache_invalidations,json=queryCacheInvalidations,proto3" json:"query_cache_invalidations,omitempty"` // Cached results dropped by writes
+	PathCacheHits           int64                  `protobuf:"varint,41,opt,name=path_cache_hits,json=pathCacheHits,proto3" json:"path_cache_hits,omitempty"`                               // Ancestor paths read from cached ancestor IDs
+	PathCacheMisses         int64                  `protobuf:"varint,42,opt,name=path_cache_misses,json=pathCacheMisses,proto3" json:"path_cache_misses,omitempty"`                         // Ancestor paths walked a level at a time
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *DumpStateResponse) Reset() {
+	*x = DumpStateResponse{}
+	mi := &file_proto_treestore_proto_msgTypes[203]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RecordPromptUsageResponse) String() string {
+func (x *DumpStateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RecordPromptUsageResponse) ProtoMessage() {}
+func (*DumpStateResponse) ProtoMessage() {}
 
-func (x *RecordPromptUsageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[51]
+func (x *DumpStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_treestore_proto_msgTypes[203]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2987,231 +12189,303 @@ func (x *RecordPromptUsageResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RecordPromptUsageResponse.ProtoReflect.Descriptor instead.
-func (*RecordPromptUsageResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{51}
+// Deprecated: Use DumpStateResponse.ProtoReflect.Descriptor instead.
+func (*DumpStateResponse) Descriptor() ([]byte, []int) {
+	return file_proto_treestore_proto_rawDescGZIP(), []int{203}
 }
 
-func (x *RecordPromptUsageResponse) GetSuccess() bool {
+func (x *DumpStateResponse) GetDbPath() string {
 	if x != nil {
-		return x.Success
+		return x.DbPath
+	}
+	return ""
+}
+
+func (x *DumpStateResponse) GetInMemory() bool {
+	if x != nil {
+		return x.InMemory
 	}
 	return false
 }
 
-func (x *RecordPromptUsageResponse) GetMessage() string {
+func (x *DumpStateResponse) GetHeap() bool {
 	if x != nil {
-		return x.Message
+		return x.Heap
+	}
+	return false
+}
+
+func (x *DumpStateResponse) GetLegacy() bool {
+	if x != nil {
+		return x.Legacy
+	}
+	return false
+}
+
+func (x *DumpStateResponse) GetEncrypted() bool {
+	if x != nil {
+		return x.Encrypted
+	}
+	return false
+}
+
+func (x *DumpStateResponse) GetPages() uint64 {
+	if x != nil {
+		return x.Pages
+	}
+	return 0
+}
+
+func (x *DumpStateResponse) GetMappedBytes() int64 {
+	if x != nil {
+		return x.MappedBytes
+	}
+	return 0
+}
+
+func (x *DumpStateResponse) GetMetaGeneration() uint64 {
+	if x != nil {
+		return x.MetaGeneration
+	}
+	return 0
+}
+
+func (x *DumpStateResponse) GetLastLsn() uint64 {
+	if x != nil {
+		return x.LastLsn
+	}
+	return 0
+}
+
+func (x *DumpStateResponse) GetSyncPolicy() string {
+	if x != nil {
+		return x.SyncPolicy
 	}
 	return ""
 }
 
-type HealthRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *DumpStateResponse) GetUnflushedCommits() int64 {
+	if x != nil {
+		return x.UnflushedCommits
+	}
+	return 0
 }
 
-func (x *HealthRequest) Reset() {
-	*x = HealthRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[52]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *DumpStateResponse) GetFlushes() uint64 {
+	if x != nil {
+		return x.Flushes
+	}
+	return 0
 }
 
-func (x *HealthRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *DumpStateResponse) GetLastFlush() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastFlush
+	}
+	return nil
 }
 
-func (*HealthRequest) ProtoMessage() {}
+func (x *DumpStateResponse) GetLastFlushError() string {
+	if x != nil {
+		return x.LastFlushError
+	}
+	return ""
+}
+
+func (x *DumpStateResponse) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+func (x *DumpStateResponse) GetLogLevel() string {
+	if x != nil {
+		return x.LogLevel
+	}
+	return ""
+}
+
+func (x *DumpStateResponse) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
 
-func (x *HealthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[52]
+func (x *DumpStateResponse) GetGoroutines() int64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Goroutines
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
-func (*HealthRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{52}
+func (x *DumpStateResponse) GetHeapAllocBytes() uint64 {
+	if x != nil {
+		return x.HeapAllocBytes
+	}
+	return 0
 }
 
-type HealthResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Healthy       bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	UptimeSeconds int64                  `protobuf:"varint,3,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *DumpStateResponse) GetRetentionSweeps() int64 {
+	if x != nil {
+		return x.RetentionSweeps
+	}
+	return 0
 }
 
-func (x *HealthResponse) Reset() {
-	*x = HealthResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[53]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *DumpStateResponse) GetOperationCounts() map[string]int64 {
+	if x != nil {
+		return x.OperationCounts
+	}
+	return nil
 }
 
-func (x *HealthResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *DumpStateResponse) GetFormatVersion() uint32 {
+	if x != nil {
+		return x.FormatVersion
+	}
+	return 0
 }
 
-func (*HealthResponse) ProtoMessage() {}
-
-func (x *HealthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[53]
+func (x *DumpStateResponse) GetMigratedFromFormat() uint32 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.MigratedFromFormat
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
-func (*HealthResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{53}
+func (x *DumpStateResponse) GetWalArchivedFiles() uint64 {
+	if x != nil {
+		return x.WalArchivedFiles
+	}
+	return 0
 }
 
-func (x *HealthResponse) GetHealthy() bool {
+func (x *DumpStateResponse) GetWalArchiveFailures() uint64 {
 	if x != nil {
-		return x.Healthy
+		return x.WalArchiveFailures
 	}
-	return false
+	return 0
 }
 
-func (x *HealthResponse) GetVersion() string {
+func (x *DumpStateResponse) GetWalArchiveError() string {
 	if x != nil {
-		return x.Version
+		return x.WalArchiveError
 	}
 	return ""
 }
 
-func (x *HealthResponse) GetUptimeSeconds() int64 {
+func (x *DumpStateResponse) GetFlushedLsn() uint64 {
 	if x != nil {
-		return x.UptimeSeconds
+		return x.FlushedLsn
 	}
 	return 0
 }
 
-type StatsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *DumpStateResponse) GetCheckpointLagEntries() uint64 {
+	if x != nil {
+		return x.CheckpointLagEntries
+	}
+	return 0
 }
 
-func (x *StatsRequest) Reset() {
-	*x = StatsRequest{}
-	mi := &file_proto_treestore_proto_msgTypes[54]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *DumpStateResponse) GetCheckpointLagMs() int64 {
+	if x != nil {
+		return x.CheckpointLagMs
+	}
+	return 0
 }
 
-func (x *StatsRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *DumpStateResponse) GetCheckpoints() uint64 {
+	if x != nil {
+		return x.Checkpoints
+	}
+	return 0
 }
 
-func (*StatsRequest) ProtoMessage() {}
-
-func (x *StatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[54]
+func (x *DumpStateResponse) GetLastCheckpoint() *timestamppb.Timestamp {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.LastCheckpoint
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
-func (*StatsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{54}
+func (x *DumpStateResponse) GetCheckpointError() string {
+	if x != nil {
+		return x.CheckpointError
+	}
+	return ""
 }
 
-type StatsResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	TotalDocuments  int64                  `protobuf:"varint,1,opt,name=total_documents,json=totalDocuments,proto3" json:"total_documents,omitempty"`
-	TotalNodes      int64                  `protobuf:"varint,2,opt,name=total_nodes,json=totalNodes,proto3" json:"total_nodes,omitempty"`
-	TotalVersions   int64                  `protobuf:"varint,3,opt,name=total_versions,json=totalVersions,proto3" json:"total_versions,omitempty"`
-	DbSizeBytes     int64                  `protobuf:"varint,4,opt,name=db_size_bytes,json=dbSizeBytes,proto3" json:"db_size_bytes,omitempty"`
-	OperationCounts map[string]int64       `protobuf:"bytes,5,rep,name=operation_counts,json=operationCounts,proto3" json:"operation_counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+func (x *DumpStateResponse) GetWalFiles() int64 {
+	if x != nil {
+		return x.WalFiles
+	}
+	return 0
 }
 
-func (x *StatsResponse) Reset() {
-	*x = StatsResponse{}
-	mi := &file_proto_treestore_proto_msgTypes[55]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *DumpStateResponse) GetCompactionPasses() int64 {
+	if x != nil {
+		return x.CompactionPasses
+	}
+	return 0
 }
 
-func (x *StatsResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *DumpStateResponse) GetCompactionLeavesMoved() int64 {
+	if x != nil {
+		return x.CompactionLeavesMoved
+	}
+	return 0
 }
 
-func (*StatsResponse) ProtoMessage() {}
-
-func (x *StatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_treestore_proto_msgTypes[55]
+func (x *DumpStateResponse) GetBloomChecks() int64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.BloomChecks
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
-func (*StatsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_treestore_proto_rawDescGZIP(), []int{55}
+func (x *DumpStateResponse) GetBloomRejections() int64 {
+	if x != nil {
+		return x.BloomRejections
+	}
+	return 0
 }
 
-func (x *StatsResponse) GetTotalDocuments() int64 {
+func (x *DumpStateResponse) GetQueryCacheHits() int64 {
 	if x != nil {
-		return x.TotalDocuments
+		return x.QueryCacheHits
 	}
 	return 0
 }
 
-func (x *StatsResponse) GetTotalNodes() int64 {
+func (x *DumpStateResponse) GetQueryCacheMisses() int64 {
 	if x != nil {
-		return x.TotalNodes
+		return x.QueryCacheMisses
 	}
 	return 0
 }
 
-func (x *StatsResponse) GetTotalVersions() int64 {
+func (x *DumpStateResponse) GetQueryCacheInvalidations() int64 {
 	if x != nil {
-		return x.TotalVersions
+		return x.QueryCacheInvalidations
 	}
 	return 0
 }
 
-func (x *StatsResponse) GetDbSizeBytes() int64 {
+func (x *DumpStateResponse) GetPathCacheHits() int64 {
 	if x != nil {
-		return x.DbSizeBytes
+		return x.PathCacheHits
 	}
 	return 0
 }
 
-func (x *StatsResponse) GetOperationCounts() map[string]int64 {
+func (x *DumpStateResponse) GetPathCacheMisses() int64 {
 	if x != nil {
-		return x.OperationCounts
+		return x.PathCacheMisses
 	}
-	return nil
+	return 0
 }
 
 var File_proto_treestore_proto protoreflect.FileDescriptor
@@ -3233,7 +12507,7 @@ const file_proto_treestore_proto_rawDesc = "" +
 	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa3\x03\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc1\x04\n" +
 	"\x04Node\x12\x17\n" +
 	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x1b\n" +
 	"\tpolicy_id\x18\x02 \x01(\tR\bpolicyId\x12\x1b\n" +
@@ -3251,7 +12525,13 @@ const file_proto_treestore_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xfc\x01\n" +
+	"updated_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x18\n" +
+	"\aversion\x18\x0e \x01(\x04R\aversion\x12\x1a\n" +
+	"\blanguage\x18\x0f \x01(\tR\blanguage\x12\x1a\n" +
+	"\bchecksum\x18\x10 \x01(\tR\bchecksum\x12\x1f\n" +
+	"\vtoken_count\x18\x11 \x01(\x05R\n" +
+	"tokenCount\x12)\n" +
+	"\x10summary_checksum\x18\x12 \x01(\tR\x0fsummaryChecksum\"\xff\x03\n" +
 	"\rPolicyVersion\x12\x1b\n" +
 	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x1d\n" +
 	"\n" +
@@ -3263,7 +12543,14 @@ const file_proto_treestore_proto_rawDesc = "" +
 	"\n" +
 	"created_by\x18\x05 \x01(\tR\tcreatedBy\x12 \n" +
 	"\vdescription\x18\x06 \x01(\tR\vdescription\x12\x12\n" +
-	"\x04tags\x18\a \x03(\tR\x04tags\"\x9f\x02\n" +
+	"\x04tags\x18\a \x03(\tR\x04tags\x12A\n" +
+	"\x0eeffective_from\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\reffectiveFrom\x12=\n" +
+	"\feffective_to\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\veffectiveTo\x12B\n" +
+	"\bmetadata\x18\n" +
+	" \x03(\v2&.treestore.PolicyVersion.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9f\x02\n" +
 	"\n" +
 	"ToolResult\x12\x1b\n" +
 	"\ttool_name\x18\x01 \x01(\tR\btoolName\x12!\n" +
@@ -3318,24 +12605,82 @@ const file_proto_treestore_proto_rawDesc = "" +
 	"\tvariables\x18\x04 \x03(\tR\tvariables\x12\x18\n" +
 	"\aversion\x18\x05 \x01(\tR\aversion\x129\n" +
 	"\n" +
-	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xb2\x02\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x8b\x03\n" +
 	"\vPromptUsage\x12\x19\n" +
 	"\busage_id\x18\x01 \x01(\tR\ausageId\x12\x1b\n" +
 	"\tprompt_id\x18\x02 \x01(\tR\bpromptId\x12V\n" +
 	"\x10filled_variables\x18\x03 \x03(\v2+.treestore.PromptUsage.FilledVariablesEntryR\x0ffilledVariables\x12\x1a\n" +
 	"\bresponse\x18\x04 \x01(\tR\bresponse\x123\n" +
-	"\aused_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x06usedAt\x1aB\n" +
+	"\aused_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x06usedAt\x12#\n" +
+	"\rexperiment_id\x18\x06 \x01(\tR\fexperimentId\x12\x18\n" +
+	"\avariant\x18\a \x01(\tR\avariant\x12\x18\n" +
+	"\aoutcome\x18\b \x01(\tR\aoutcome\x1aB\n" +
 	"\x14FilledVariablesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"n\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe7\x01\n" +
+	"\x10PromptExperiment\x12#\n" +
+	"\rexperiment_id\x18\x01 \x01(\tR\fexperimentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x124\n" +
+	"\bvariants\x18\x03 \x03(\v2\x18.treestore.PromptVariantR\bvariants\x12)\n" +
+	"\x10success_outcomes\x18\x04 \x03(\tR\x0fsuccessOutcomes\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"X\n" +
+	"\rPromptVariant\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1b\n" +
+	"\tprompt_id\x18\x02 \x01(\tR\bpromptId\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x05R\x06weight\"\xb8\x02\n" +
+	"\fVariantStats\x12\x18\n" +
+	"\avariant\x18\x01 \x01(\tR\avariant\x12\x1b\n" +
+	"\tprompt_id\x18\x02 \x01(\tR\bpromptId\x12\x16\n" +
+	"\x06usages\x18\x03 \x01(\x05R\x06usages\x12\x18\n" +
+	"\alabeled\x18\x04 \x01(\x05R\alabeled\x12\x1c\n" +
+	"\tsuccesses\x18\x05 \x01(\x05R\tsuccesses\x12!\n" +
+	"\fsuccess_rate\x18\x06 \x01(\x01R\vsuccessRate\x12A\n" +
+	"\boutcomes\x18\a \x03(\v2%.treestore.VariantStats.OutcomesEntryR\boutcomes\x1a;\n" +
+	"\rOutcomesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\x87\x03\n" +
+	"\aMessage\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12'\n" +
+	"\x0fconversation_id\x18\x02 \x01(\tR\x0econversationId\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x128\n" +
+	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12<\n" +
+	"\bmetadata\x18\x06 \x03(\v2 .treestore.Message.MetadataEntryR\bmetadata\x127\n" +
+	"\tedited_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\beditedAt\x12\x18\n" +
+	"\adeleted\x18\b \x01(\bR\adeleted\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xba\x03\n" +
+	"\fConversation\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x129\n" +
+	"\n" +
+	"started_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12B\n" +
+	"\x0flast_message_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\rlastMessageAt\x12#\n" +
+	"\rmessage_count\x18\x06 \x01(\x05R\fmessageCount\x12\x12\n" +
+	"\x04tags\x18\a \x03(\tR\x04tags\x12A\n" +
+	"\bmetadata\x18\b \x03(\v2%.treestore.Conversation.MetadataEntryR\bmetadata\x12\x1a\n" +
+	"\barchived\x18\t \x01(\bR\barchived\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9f\x01\n" +
+	"\x11ConversationQuota\x12+\n" +
+	"\x11max_conversations\x18\x01 \x01(\x03R\x10maxConversations\x12!\n" +
+	"\fmax_messages\x18\x02 \x01(\x03R\vmaxMessages\x12:\n" +
+	"\x19max_conversation_messages\x18\x03 \x01(\x03R\x17maxConversationMessages\"n\n" +
 	"\x14StoreDocumentRequest\x12/\n" +
 	"\bdocument\x18\x01 \x01(\v2\x13.treestore.DocumentR\bdocument\x12%\n" +
-	"\x05nodes\x18\x02 \x03(\v2\x0f.treestore.NodeR\x05nodes\"K\n" +
+	"\x05nodes\x18\x02 \x03(\v2\x0f.treestore.NodeR\x05nodes\"~\n" +
 	"\x15StoreDocumentResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"1\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x121\n" +
+	"\x14references_extracted\x18\x03 \x01(\x05R\x13referencesExtracted\"I\n" +
 	"\x12GetDocumentRequest\x12\x1b\n" +
-	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\"m\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x16\n" +
+	"\x06fields\x18\x02 \x03(\tR\x06fields\"m\n" +
 	"\x13GetDocumentResponse\x12/\n" +
 	"\bdocument\x18\x01 \x01(\v2\x13.treestore.DocumentR\bdocument\x12%\n" +
 	"\x05nodes\x18\x02 \x03(\v2\x0f.treestore.NodeR\x05nodes\"4\n" +
@@ -3343,37 +12688,252 @@ const file_proto_treestore_proto_rawDesc = "" +
 	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\"L\n" +
 	"\x16DeleteDocumentResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"F\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x84\x01\n" +
+	"\x14CloneDocumentRequest\x12\"\n" +
+	"\rsrc_policy_id\x18\x01 \x01(\tR\vsrcPolicyId\x12$\n" +
+	"\x0esrc_version_id\x18\x02 \x01(\tR\fsrcVersionId\x12\"\n" +
+	"\rdst_policy_id\x18\x03 \x01(\tR\vdstPolicyId\"\xca\x01\n" +
+	"\x15CloneDocumentResponse\x12\"\n" +
+	"\rroot_node_ids\x18\x01 \x03(\tR\vrootNodeIds\x12O\n" +
+	"\vnode_id_map\x18\x02 \x03(\v2/.treestore.CloneDocumentResponse.NodeIdMapEntryR\tnodeIdMap\x1a<\n" +
+	"\x0eNodeIdMapEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"T\n" +
+	"\x1cRecomputeSectionPathsRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"`\n" +
+	"\x11SectionPathChange\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x16\n" +
+	"\x06stored\x18\x02 \x01(\tR\x06stored\x12\x1a\n" +
+	"\bexpected\x18\x03 \x01(\tR\bexpected\"\x96\x01\n" +
+	"\x1dRecomputeSectionPathsResponse\x12#\n" +
+	"\rnodes_checked\x18\x01 \x01(\x05R\fnodesChecked\x126\n" +
+	"\achanges\x18\x02 \x03(\v2\x1c.treestore.SectionPathChangeR\achanges\x12\x18\n" +
+	"\aapplied\x18\x03 \x01(\bR\aapplied\"6\n" +
+	"\x17ValidateDocumentRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\"T\n" +
+	"\rDocumentIssue\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x17\n" +
+	"\anode_id\x18\x02 \x01(\tR\x06nodeId\x12\x16\n" +
+	"\x06detail\x18\x03 \x01(\tR\x06detail\"\x87\x01\n" +
+	"\x18ValidateDocumentResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12#\n" +
+	"\rnodes_checked\x18\x02 \x01(\x05R\fnodesChecked\x120\n" +
+	"\x06issues\x18\x03 \x03(\v2\x18.treestore.DocumentIssueR\x06issues\"[\n" +
+	"\x19FindDuplicateNodesRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12!\n" +
+	"\fcross_policy\x18\x02 \x01(\bR\vcrossPolicy\"?\n" +
+	"\aNodeRef\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x17\n" +
+	"\anode_id\x18\x02 \x01(\tR\x06nodeId\"V\n" +
+	"\x0eDuplicateGroup\x12\x1a\n" +
+	"\bchecksum\x18\x01 \x01(\tR\bchecksum\x12(\n" +
+	"\x05nodes\x18\x02 \x03(\v2\x12.treestore.NodeRefR\x05nodes\"O\n" +
+	"\x1aFindDuplicateNodesResponse\x121\n" +
+	"\x06groups\x18\x01 \x03(\v2\x19.treestore.DuplicateGroupR\x06groups\"\xa9\x01\n" +
+	"\x17RefreshSummariesRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x19\n" +
+	"\bnode_ids\x18\x02 \x03(\tR\anodeIds\x12!\n" +
+	"\fsection_path\x18\x03 \x01(\tR\vsectionPath\x12\x14\n" +
+	"\x05force\x18\x04 \x01(\bR\x05force\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x05 \x01(\x05R\tbatchSize\"x\n" +
+	"\x18RefreshSummariesProgress\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x05R\x05total\x12\x12\n" +
+	"\x04done\x18\x02 \x01(\x05R\x04done\x12\x18\n" +
+	"\aupdated\x18\x03 \x01(\x05R\aupdated\x12\x18\n" +
+	"\askipped\x18\x04 \x01(\x05R\askipped\"9\n" +
+	"\x10SummarizeRequest\x12%\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x0f.treestore.NodeR\x05nodes\"1\n" +
+	"\x11SummarizeResponse\x12\x1c\n" +
+	"\tsummaries\x18\x01 \x03(\tR\tsummaries\"F\n" +
 	"\x0eGetNodeRequest\x12\x1b\n" +
 	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x17\n" +
 	"\anode_id\x18\x02 \x01(\tR\x06nodeId\"6\n" +
 	"\x0fGetNodeResponse\x12#\n" +
-	"\x04node\x18\x01 \x01(\v2\x0f.treestore.NodeR\x04node\"N\n" +
+	"\x04node\x18\x01 \x01(\v2\x0f.treestore.NodeR\x04node\"8\n" +
+	"\x11UpdateNodeRequest\x12#\n" +
+	"\x04node\x18\x01 \x01(\v2\x0f.treestore.NodeR\x04node\"9\n" +
+	"\x12UpdateNodeResponse\x12#\n" +
+	"\x04node\x18\x01 \x01(\v2\x0f.treestore.NodeR\x04node\"f\n" +
 	"\x12GetChildrenRequest\x12\x1b\n" +
 	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x1b\n" +
-	"\tparent_id\x18\x02 \x01(\tR\bparentId\"B\n" +
+	"\tparent_id\x18\x02 \x01(\tR\bparentId\x12\x16\n" +
+	"\x06fields\x18\x03 \x03(\tR\x06fields\"B\n" +
 	"\x13GetChildrenResponse\x12+\n" +
-	"\bchildren\x18\x01 \x03(\v2\x0f.treestore.NodeR\bchildren\"f\n" +
+	"\bchildren\x18\x01 \x03(\v2\x0f.treestore.NodeR\bchildren\"\x9e\x01\n" +
 	"\x11GetSubtreeRequest\x12\x1b\n" +
 	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x17\n" +
 	"\anode_id\x18\x02 \x01(\tR\x06nodeId\x12\x1b\n" +
-	"\tmax_depth\x18\x03 \x01(\x05R\bmaxDepth\";\n" +
+	"\tmax_depth\x18\x03 \x01(\x05R\bmaxDepth\x12\x16\n" +
+	"\x06fields\x18\x04 \x03(\tR\x06fields\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x05 \x01(\tR\n" +
+	"collection\";\n" +
 	"\x12GetSubtreeResponse\x12%\n" +
-	"\x05nodes\x18\x01 \x03(\v2\x0f.treestore.NodeR\x05nodes\"N\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x0f.treestore.NodeR\x05nodes\"\x91\x01\n" +
+	"\x1dGetSubtreeWithinBudgetRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x17\n" +
+	"\anode_id\x18\x02 \x01(\tR\x06nodeId\x12\x1d\n" +
+	"\n" +
+	"max_tokens\x18\x03 \x01(\x05R\tmaxTokens\x12\x1b\n" +
+	"\tmax_depth\x18\x04 \x01(\x05R\bmaxDepth\"\xc3\x01\n" +
+	"\x1eGetSubtreeWithinBudgetResponse\x12%\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x0f.treestore.NodeR\x05nodes\x12%\n" +
+	"\x0esummarized_ids\x18\x02 \x03(\tR\rsummarizedIds\x12\x14\n" +
+	"\x05depth\x18\x03 \x01(\x05R\x05depth\x12\x1f\n" +
+	"\vtoken_count\x18\x04 \x01(\x05R\n" +
+	"tokenCount\x12\x1c\n" +
+	"\ttruncated\x18\x05 \x01(\bR\ttruncated\"N\n" +
 	"\x16GetAncestorPathRequest\x12\x1b\n" +
 	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x17\n" +
 	"\anode_id\x18\x02 \x01(\tR\x06nodeId\"H\n" +
 	"\x17GetAncestorPathResponse\x12-\n" +
-	"\tancestors\x18\x01 \x03(\v2\x0f.treestore.NodeR\tancestors\"X\n" +
+	"\tancestors\x18\x01 \x03(\v2\x0f.treestore.NodeR\tancestors\"n\n" +
+	"\x1cBatchGetAncestorPathsRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x19\n" +
+	"\bnode_ids\x18\x02 \x03(\tR\anodeIds\x12\x16\n" +
+	"\x06fields\x18\x03 \x03(\tR\x06fields\"l\n" +
+	"\fAncestorPath\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12-\n" +
+	"\tancestors\x18\x02 \x03(\v2\x0f.treestore.NodeR\tancestors\x12\x14\n" +
+	"\x05found\x18\x03 \x01(\bR\x05found\"N\n" +
+	"\x1dBatchGetAncestorPathsResponse\x12-\n" +
+	"\x05paths\x18\x01 \x03(\v2\x17.treestore.AncestorPathR\x05paths\"b\n" +
+	"\x12GetSiblingsRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x17\n" +
+	"\anode_id\x18\x02 \x01(\tR\x06nodeId\x12\x16\n" +
+	"\x06fields\x18\x03 \x03(\tR\x06fields\"^\n" +
+	"\x13GetSiblingsResponse\x12+\n" +
+	"\bsiblings\x18\x01 \x03(\v2\x0f.treestore.NodeR\bsiblings\x12\x1a\n" +
+	"\bposition\x18\x02 \x01(\x05R\bposition\"f\n" +
+	"\x16AdjacentSectionRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x17\n" +
+	"\anode_id\x18\x02 \x01(\tR\x06nodeId\x12\x16\n" +
+	"\x06fields\x18\x03 \x03(\tR\x06fields\">\n" +
+	"\x17AdjacentSectionResponse\x12#\n" +
+	"\x04node\x18\x01 \x01(\v2\x0f.treestore.NodeR\x04node\"M\n" +
+	"\x16GetReadingOrderRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x16\n" +
+	"\x06fields\x18\x02 \x03(\tR\x06fields\"r\n" +
+	"\x17GetContextWindowRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x17\n" +
+	"\anode_id\x18\x02 \x01(\tR\x06nodeId\x12!\n" +
+	"\ftoken_budget\x18\x03 \x01(\x05R\vtokenBudget\"W\n" +
+	"\fContextEntry\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
+	"\asummary\x18\x03 \x01(\tR\asummary\"\x9f\x02\n" +
+	"\x18GetContextWindowResponse\x12#\n" +
+	"\x04node\x18\x01 \x01(\v2\x0f.treestore.NodeR\x04node\x125\n" +
+	"\tancestors\x18\x02 \x03(\v2\x17.treestore.ContextEntryR\tancestors\x123\n" +
+	"\bsiblings\x18\x03 \x03(\v2\x17.treestore.ContextEntryR\bsiblings\x123\n" +
+	"\bchildren\x18\x04 \x03(\v2\x17.treestore.ContextEntryR\bchildren\x12\x1f\n" +
+	"\vtoken_count\x18\x05 \x01(\x05R\n" +
+	"tokenCount\x12\x1c\n" +
+	"\ttruncated\x18\x06 \x01(\bR\ttruncated\"\xde\x01\n" +
+	"\x12ExportGraphRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x14\n" +
+	"\x05graph\x18\x02 \x01(\tR\x05graph\x12\x16\n" +
+	"\x06format\x18\x03 \x01(\tR\x06format\x12 \n" +
+	"\froot_node_id\x18\x04 \x01(\tR\n" +
+	"rootNodeId\x12\x1b\n" +
+	"\tmax_depth\x18\x05 \x01(\x05R\bmaxDepth\x12\x14\n" +
+	"\x05label\x18\x06 \x01(\tR\x05label\x12(\n" +
+	"\x10max_label_length\x18\a \x01(\x05R\x0emaxLabelLength\"m\n" +
+	"\x13ExportGraphResponse\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\tR\acontent\x12\x1d\n" +
+	"\n" +
+	"node_count\x18\x02 \x01(\x05R\tnodeCount\x12\x1d\n" +
+	"\n" +
+	"edge_count\x18\x03 \x01(\x05R\tedgeCount\"\xd0\x01\n" +
 	"\rSearchRequest\x12\x1b\n" +
 	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x14\n" +
 	"\x05query\x18\x02 \x01(\tR\x05query\x12\x14\n" +
-	"\x05limit\x18\x03 \x01(\x05R\x05limit\"C\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12/\n" +
+	"\x06filter\x18\x04 \x01(\v2\x17.treestore.SearchFilterR\x06filter\x12%\n" +
+	"\x0esnippet_length\x18\x05 \x01(\x05R\rsnippetLength\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x06 \x01(\tR\n" +
+	"collection\"\xa4\x02\n" +
+	"\fSearchFilter\x12\x1b\n" +
+	"\tpage_from\x18\x01 \x01(\x05R\bpageFrom\x12\x17\n" +
+	"\apage_to\x18\x02 \x01(\x05R\x06pageTo\x12 \n" +
+	"\tmax_depth\x18\x03 \x01(\x05H\x00R\bmaxDepth\x88\x01\x01\x12.\n" +
+	"\x13section_path_prefix\x18\x04 \x01(\tR\x11sectionPathPrefix\x12A\n" +
+	"\bmetadata\x18\x05 \x03(\v2%.treestore.SearchFilter.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\f\n" +
+	"\n" +
+	"_max_depth\"C\n" +
 	"\x0eSearchResponse\x121\n" +
-	"\aresults\x18\x01 \x03(\v2\x17.treestore.SearchResultR\aresults\"I\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.treestore.SearchResultR\aresults\"\x99\x01\n" +
 	"\fSearchResult\x12#\n" +
 	"\x04node\x18\x01 \x01(\v2\x0f.treestore.NodeR\x04node\x12\x14\n" +
-	"\x05score\x18\x02 \x01(\x02R\x05score\"U\n" +
+	"\x05score\x18\x02 \x01(\x02R\x05score\x12\x18\n" +
+	"\asnippet\x18\x03 \x01(\tR\asnippet\x124\n" +
+	"\n" +
+	"highlights\x18\x04 \x03(\v2\x14.treestore.HighlightR\n" +
+	"highlights\"3\n" +
+	"\tHighlight\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x05R\x03end\"\xb7\x01\n" +
+	"\x0fRetrieveRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x13\n" +
+	"\x05top_k\x18\x02 \x01(\x05R\x04topK\x12>\n" +
+	"\x06filter\x18\x03 \x03(\v2&.treestore.RetrieveRequest.FilterEntryR\x06filter\x1a9\n" +
+	"\vFilterEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"N\n" +
+	"\x10RetrieveResponse\x12:\n" +
+	"\tdocuments\x18\x01 \x03(\v2\x1c.treestore.RetrievedDocumentR\tdocuments\"\xe1\x01\n" +
+	"\x11RetrievedDocument\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fpage_content\x18\x02 \x01(\tR\vpageContent\x12F\n" +
+	"\bmetadata\x18\x03 \x03(\v2*.treestore.RetrievedDocument.MetadataEntryR\bmetadata\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x02R\x05score\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xbf\x01\n" +
+	"\x13GlobalSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12(\n" +
+	"\x10per_policy_limit\x18\x02 \x01(\x05R\x0eperPolicyLimit\x12!\n" +
+	"\fmax_policies\x18\x03 \x01(\x05R\vmaxPolicies\x12%\n" +
+	"\x0esnippet_length\x18\x04 \x01(\x05R\rsnippetLength\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x05 \x01(\tR\n" +
+	"collection\"R\n" +
+	"\x14GlobalSearchResponse\x12:\n" +
+	"\bpolicies\x18\x01 \x03(\v2\x1e.treestore.PolicySearchResultsR\bpolicies\"\x84\x01\n" +
+	"\x13PolicySearchResults\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x121\n" +
+	"\aresults\x18\x02 \x03(\v2\x17.treestore.SearchResultR\aresults\x12\x1d\n" +
+	"\n" +
+	"total_hits\x18\x03 \x01(\x05R\ttotalHits\"\xd2\x02\n" +
+	"\x10JoinNodesRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12E\n" +
+	"\bmetadata\x18\x02 \x03(\v2).treestore.JoinNodesRequest.MetadataEntryR\bmetadata\x12#\n" +
+	"\rreferences_to\x18\x03 \x01(\tR\freferencesTo\x12#\n" +
+	"\rreferenced_by\x18\x04 \x01(\tR\freferencedBy\x12%\n" +
+	"\x0ereference_type\x18\x05 \x01(\tR\rreferenceType\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\a \x01(\x05R\x06offset\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"D\n" +
+	"\x11JoinNodesResponse\x12/\n" +
+	"\aresults\x18\x01 \x03(\v2\x15.treestore.JoinedNodeR\aresults\"\xea\x01\n" +
+	"\n" +
+	"JoinedNode\x12#\n" +
+	"\x04node\x18\x01 \x01(\v2\x0f.treestore.NodeR\x04node\x12?\n" +
+	"\bmetadata\x18\x02 \x03(\v2#.treestore.JoinedNode.MetadataEntryR\bmetadata\x129\n" +
+	"\n" +
+	"references\x18\x03 \x03(\v2\x19.treestore.CrossReferenceR\n" +
+	"references\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"U\n" +
 	"\x15GetNodesByPageRequest\x12\x1b\n" +
 	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x1f\n" +
 	"\vpage_number\x18\x02 \x01(\x05R\n" +
@@ -3383,12 +12943,54 @@ const file_proto_treestore_proto_rawDesc = "" +
 	"\x15GetVersionAsOfRequest\x12\x1b\n" +
 	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x128\n" +
 	"\n" +
-	"as_of_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\basOfTime\"H\n" +
+	"as_of_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\basOfTime\"v\n" +
+	"\x1bBatchGetVersionsAsOfRequest\x12\x1d\n" +
+	"\n" +
+	"policy_ids\x18\x01 \x03(\tR\tpolicyIds\x128\n" +
+	"\n" +
+	"as_of_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\basOfTime\"\xf6\x01\n" +
+	"\x1cBatchGetVersionsAsOfResponse\x12Q\n" +
+	"\bversions\x18\x01 \x03(\v25.treestore.BatchGetVersionsAsOfResponse.VersionsEntryR\bversions\x12,\n" +
+	"\x12missing_policy_ids\x18\x02 \x03(\tR\x10missingPolicyIds\x1aU\n" +
+	"\rVersionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12.\n" +
+	"\x05value\x18\x02 \x01(\v2\x18.treestore.PolicyVersionR\x05value:\x028\x01\"H\n" +
 	"\x13ListVersionsRequest\x12\x1b\n" +
 	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\x05R\x05limit\"L\n" +
 	"\x14ListVersionsResponse\x124\n" +
-	"\bversions\x18\x01 \x03(\v2\x18.treestore.PolicyVersionR\bversions\"G\n" +
+	"\bversions\x18\x01 \x03(\v2\x18.treestore.PolicyVersionR\bversions\"R\n" +
+	"\x14DeleteVersionRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x02 \x01(\tR\tversionId\"1\n" +
+	"\x15DeleteVersionResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xc7\x01\n" +
+	"\x14PruneVersionsRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x1b\n" +
+	"\tkeep_last\x18\x02 \x01(\x05R\bkeepLast\x129\n" +
+	"\n" +
+	"older_than\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tolderThan\x12!\n" +
+	"\fprotect_tags\x18\x04 \x03(\tR\vprotectTags\x12\x17\n" +
+	"\adry_run\x18\x05 \x01(\bR\x06dryRun\"^\n" +
+	"\x15PruneVersionsResponse\x12,\n" +
+	"\x12pruned_version_ids\x18\x01 \x03(\tR\x10prunedVersionIds\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"y\n" +
+	"\x11TagVersionRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x02 \x01(\tR\tversionId\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\x12\x16\n" +
+	"\x06unique\x18\x04 \x01(\bR\x06unique\".\n" +
+	"\x12TagVersionResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"c\n" +
+	"\x13UntagVersionRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x02 \x01(\tR\tversionId\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\"0\n" +
+	"\x14UntagVersionResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"G\n" +
 	"\x16StoreToolResultRequest\x12-\n" +
 	"\x06result\x18\x01 \x01(\v2\x15.treestore.ToolResultR\x06result\"M\n" +
 	"\x17StoreToolResultResponse\x12\x18\n" +
@@ -3411,7 +13013,35 @@ const file_proto_treestore_proto_rawDesc = "" +
 	"\acase_id\x18\x01 \x01(\tR\x06caseId\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\x05R\x05limit\"T\n" +
 	"\x17GetTrajectoriesResponse\x129\n" +
-	"\ftrajectories\x18\x01 \x03(\v2\x15.treestore.TrajectoryR\ftrajectories\"`\n" +
+	"\ftrajectories\x18\x01 \x03(\v2\x15.treestore.TrajectoryR\ftrajectories\"\xb4\x01\n" +
+	"\x17ReplayTrajectoryRequest\x12#\n" +
+	"\rtrajectory_id\x18\x01 \x01(\tR\ftrajectoryId\x12\x1b\n" +
+	"\tpolicy_id\x18\x02 \x01(\tR\bpolicyId\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x03 \x01(\tR\tversionId\x128\n" +
+	"\n" +
+	"as_of_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\basOfTime\"\xbf\x02\n" +
+	"\x18ReplayTrajectoryResponse\x12+\n" +
+	"\x05steps\x18\x01 \x03(\v2\x15.treestore.StepReplayR\x05steps\x12\x18\n" +
+	"\amatched\x18\x02 \x01(\x05R\amatched\x12\x1a\n" +
+	"\bdiverged\x18\x03 \x01(\x05R\bdiverged\x12\x16\n" +
+	"\x06failed\x18\x04 \x01(\x05R\x06failed\x12\x18\n" +
+	"\askipped\x18\x05 \x01(\x05R\askipped\x12P\n" +
+	"\tdocuments\x18\x06 \x03(\v22.treestore.ReplayTrajectoryResponse.DocumentsEntryR\tdocuments\x1a<\n" +
+	"\x0eDocumentsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x98\x02\n" +
+	"\n" +
+	"StepReplay\x12\x1f\n" +
+	"\vstep_number\x18\x01 \x01(\x05R\n" +
+	"stepNumber\x12\x1b\n" +
+	"\ttool_name\x18\x02 \x01(\tR\btoolName\x12\x18\n" +
+	"\aoutcome\x18\x03 \x01(\tR\aoutcome\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12(\n" +
+	"\x10missing_node_ids\x18\x05 \x03(\tR\x0emissingNodeIds\x12$\n" +
+	"\x0eadded_node_ids\x18\x06 \x03(\tR\faddedNodeIds\x12(\n" +
+	"\x10changed_node_ids\x18\a \x03(\tR\x0echangedNodeIds\x12 \n" +
+	"\vobservation\x18\b \x01(\tR\vobservation\"`\n" +
 	"\x1aStoreCrossReferenceRequest\x12B\n" +
 	"\x0fcross_reference\x18\x01 \x01(\v2\x19.treestore.CrossReferenceR\x0ecrossReference\"Q\n" +
 	"\x1bStoreCrossReferenceResponse\x12\x18\n" +
@@ -3428,7 +13058,74 @@ const file_proto_treestore_proto_rawDesc = "" +
 	"\rcontradiction\x18\x01 \x01(\v2\x18.treestore.ContradictionR\rcontradiction\"P\n" +
 	"\x1aStoreContradictionResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"G\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xb5\x03\n" +
+	"\x17BatchSetMetadataRequest\x12\x1f\n" +
+	"\ventity_type\x18\x01 \x01(\tR\n" +
+	"entityType\x12\x1b\n" +
+	"\tentity_id\x18\x02 \x01(\tR\bentityId\x12R\n" +
+	"\n" +
+	"attributes\x18\x03 \x03(\v22.treestore.BatchSetMetadataRequest.AttributesEntryR\n" +
+	"attributes\x12\x1d\n" +
+	"\n" +
+	"value_type\x18\x04 \x01(\tR\tvalueType\x12e\n" +
+	"\x11expected_versions\x18\x05 \x03(\v28.treestore.BatchSetMetadataRequest.ExpectedVersionsEntryR\x10expectedVersions\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aC\n" +
+	"\x15ExpectedVersionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x04R\x05value:\x028\x01\"\xd6\x01\n" +
+	"\x18BatchSetMetadataResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12M\n" +
+	"\bversions\x18\x03 \x03(\v21.treestore.BatchSetMetadataResponse.VersionsEntryR\bversions\x1a;\n" +
+	"\rVersionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x04R\x05value:\x028\x01\"\xd5\x02\n" +
+	"\n" +
+	"Collection\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1d\n" +
+	"\n" +
+	"policy_ids\x18\x03 \x03(\tR\tpolicyIds\x12?\n" +
+	"\bmetadata\x18\x04 \x03(\v2#.treestore.Collection.MetadataEntryR\bmetadata\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"M\n" +
+	"\x14PutCollectionRequest\x125\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\v2\x15.treestore.CollectionR\n" +
+	"collection\"N\n" +
+	"\x15PutCollectionResponse\x125\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\v2\x15.treestore.CollectionR\n" +
+	"collection\"*\n" +
+	"\x14GetCollectionRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"N\n" +
+	"\x15GetCollectionResponse\x125\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\v2\x15.treestore.CollectionR\n" +
+	"collection\"5\n" +
+	"\x16ListCollectionsRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\"R\n" +
+	"\x17ListCollectionsResponse\x127\n" +
+	"\vcollections\x18\x01 \x03(\v2\x15.treestore.CollectionR\vcollections\"^\n" +
+	"\x1eUpdateCollectionMembersRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03add\x18\x02 \x03(\tR\x03add\x12\x16\n" +
+	"\x06remove\x18\x03 \x03(\tR\x06remove\"X\n" +
+	"\x1fUpdateCollectionMembersResponse\x125\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\v2\x15.treestore.CollectionR\n" +
+	"collection\"-\n" +
+	"\x17DeleteCollectionRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"4\n" +
+	"\x18DeleteCollectionResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"G\n" +
 	"\x12StorePromptRequest\x121\n" +
 	"\x06prompt\x18\x01 \x01(\v2\x19.treestore.PromptTemplateR\x06prompt\"I\n" +
 	"\x13StorePromptResponse\x12\x18\n" +
@@ -3442,48 +13139,459 @@ const file_proto_treestore_proto_rawDesc = "" +
 	"\x05usage\x18\x01 \x01(\v2\x16.treestore.PromptUsageR\x05usage\"O\n" +
 	"\x19RecordPromptUsageResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\x0f\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"[\n" +
+	"\x1cStorePromptExperimentRequest\x12;\n" +
+	"\n" +
+	"experiment\x18\x01 \x01(\v2\x1b.treestore.PromptExperimentR\n" +
+	"experiment\"S\n" +
+	"\x1dStorePromptExperimentResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"A\n" +
+	"\x1aGetPromptExperimentRequest\x12#\n" +
+	"\rexperiment_id\x18\x01 \x01(\tR\fexperimentId\"Z\n" +
+	"\x1bGetPromptExperimentResponse\x12;\n" +
+	"\n" +
+	"experiment\x18\x01 \x01(\v2\x1b.treestore.PromptExperimentR\n" +
+	"experiment\"U\n" +
+	"\x1aAssignPromptVariantRequest\x12#\n" +
+	"\rexperiment_id\x18\x01 \x01(\tR\fexperimentId\x12\x12\n" +
+	"\x04unit\x18\x02 \x01(\tR\x04unit\"Q\n" +
+	"\x1bAssignPromptVariantResponse\x122\n" +
+	"\avariant\x18\x01 \x01(\v2\x18.treestore.PromptVariantR\avariant\"s\n" +
+	"\x17LabelPromptUsageRequest\x12#\n" +
+	"\rexperiment_id\x18\x01 \x01(\tR\fexperimentId\x12\x19\n" +
+	"\busage_id\x18\x02 \x01(\tR\ausageId\x12\x18\n" +
+	"\aoutcome\x18\x03 \x01(\tR\aoutcome\"N\n" +
+	"\x18LabelPromptUsageResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xa7\x01\n" +
+	"\x1cComparePromptVariantsRequest\x12#\n" +
+	"\rexperiment_id\x18\x01 \x01(\tR\fexperimentId\x120\n" +
+	"\x05since\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\"T\n" +
+	"\x1dComparePromptVariantsResponse\x123\n" +
+	"\bvariants\x18\x01 \x03(\v2\x17.treestore.VariantStatsR\bvariants\"\xc6\x01\n" +
+	"\x16GetMessagesPageRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12(\n" +
+	"\x10after_message_id\x18\x02 \x01(\tR\x0eafterMessageId\x12C\n" +
+	"\x0fafter_timestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x0eafterTimestamp\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"\x85\x01\n" +
+	"\x17GetMessagesPageResponse\x12.\n" +
+	"\bmessages\x18\x01 \x03(\v2\x12.treestore.MessageR\bmessages\x12\x19\n" +
+	"\bhas_more\x18\x02 \x01(\bR\ahasMore\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\"Y\n" +
+	"\x18GetRecentMessagesRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"K\n" +
+	"\x19GetRecentMessagesResponse\x12.\n" +
+	"\bmessages\x18\x01 \x03(\v2\x12.treestore.MessageR\bmessages\"a\n" +
+	"\x1aSearchConversationsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\x9b\x01\n" +
+	"\x18ConversationSearchResult\x12;\n" +
+	"\fconversation\x18\x01 \x01(\v2\x17.treestore.ConversationR\fconversation\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12,\n" +
+	"\amatches\x18\x03 \x03(\v2\x12.treestore.MessageR\amatches\"\\\n" +
+	"\x1bSearchConversationsResponse\x12=\n" +
+	"\aresults\x18\x01 \x03(\v2#.treestore.ConversationSearchResultR\aresults\"\x81\x01\n" +
+	"\x19ExportConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12#\n" +
+	"\romit_metadata\x18\x03 \x01(\bR\fomitMetadata\"[\n" +
+	"\x1aExportConversationResponse\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\tR\acontent\x12#\n" +
+	"\rmessage_count\x18\x02 \x01(\x05R\fmessageCount\"\x83\x01\n" +
+	"\x1eListConversationsByUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x122\n" +
+	"\x15after_conversation_id\x18\x02 \x01(\tR\x13afterConversationId\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\x9c\x01\n" +
+	"\x1fListConversationsByUserResponse\x12=\n" +
+	"\rconversations\x18\x01 \x03(\v2\x17.treestore.ConversationR\rconversations\x12\x19\n" +
+	"\bhas_more\x18\x02 \x01(\bR\ahasMore\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\":\n" +
+	"\x1fGetUserConversationUsageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\xd4\x01\n" +
+	" GetUserConversationUsageResponse\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12$\n" +
+	"\rconversations\x18\x02 \x01(\x03R\rconversations\x12\x1a\n" +
+	"\bmessages\x18\x03 \x01(\x03R\bmessages\x122\n" +
+	"\x05quota\x18\x04 \x01(\v2\x1c.treestore.ConversationQuotaR\x05quota\x12!\n" +
+	"\fcustom_quota\x18\x05 \x01(\bR\vcustomQuota\"n\n" +
+	"\x1fSetUserConversationQuotaRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x122\n" +
+	"\x05quota\x18\x02 \x01(\v2\x1c.treestore.ConversationQuotaR\x05quota\"V\n" +
+	" SetUserConversationQuotaResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"*\n" +
+	"\x12StreamQueryRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\"\xa4\x02\n" +
+	"\rMetadataEntry\x12\x1f\n" +
+	"\ventity_type\x18\x01 \x01(\tR\n" +
+	"entityType\x12\x1b\n" +
+	"\tentity_id\x18\x02 \x01(\tR\bentityId\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\tR\x05value\x12\x1d\n" +
+	"\n" +
+	"value_type\x18\x05 \x01(\tR\tvalueType\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x18\n" +
+	"\aversion\x18\b \x01(\x04R\aversion\"\x94\x02\n" +
+	"\bQueryRow\x12%\n" +
+	"\x04node\x18\x01 \x01(\v2\x0f.treestore.NodeH\x00R\x04node\x124\n" +
+	"\aversion\x18\x02 \x01(\v2\x18.treestore.PolicyVersionH\x00R\aversion\x126\n" +
+	"\bmetadata\x18\x03 \x01(\v2\x18.treestore.MetadataEntryH\x00R\bmetadata\x12=\n" +
+	"\fconversation\x18\x04 \x01(\v2\x17.treestore.ConversationH\x00R\fconversation\x12-\n" +
+	"\x05group\x18\x05 \x01(\v2\x15.treestore.QueryGroupH\x00R\x05groupB\x05\n" +
+	"\x03row\"\xaa\x01\n" +
+	"\n" +
+	"QueryGroup\x12\x10\n" +
+	"\x03key\x18\x01 \x03(\tR\x03key\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x129\n" +
+	"\x06values\x18\x03 \x03(\v2!.treestore.QueryGroup.ValuesEntryR\x06values\x1a9\n" +
+	"\vValuesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\xcb\x01\n" +
+	"\n" +
+	"SavedQuery\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12*\n" +
+	"\x11refresh_on_change\x18\x03 \x01(\bR\x0frefreshOnChange\x12=\n" +
+	"\frefreshed_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vrefreshedAt\x12\x12\n" +
+	"\x04rows\x18\x05 \x01(\x03R\x04rows\x12\x14\n" +
+	"\x05stale\x18\x06 \x01(\bR\x05stale\"h\n" +
+	"\x10SaveQueryRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12*\n" +
+	"\x11refresh_on_change\x18\x03 \x01(\bR\x0frefreshOnChange\"@\n" +
+	"\x11SaveQueryResponse\x12+\n" +
+	"\x05saved\x18\x01 \x01(\v2\x15.treestore.SavedQueryR\x05saved\".\n" +
+	"\x18ExecuteSavedQueryRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x8f\x01\n" +
+	"\x19ExecuteSavedQueryResponse\x12+\n" +
+	"\x05saved\x18\x01 \x01(\v2\x15.treestore.SavedQueryR\x05saved\x12'\n" +
+	"\x04rows\x18\x02 \x03(\v2\x13.treestore.QueryRowR\x04rows\x12\x1c\n" +
+	"\trefreshed\x18\x03 \x01(\bR\trefreshed\".\n" +
+	"\x18RefreshSavedQueryRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"H\n" +
+	"\x19RefreshSavedQueryResponse\x12+\n" +
+	"\x05saved\x18\x01 \x01(\v2\x15.treestore.SavedQueryR\x05saved\"\x19\n" +
+	"\x17ListSavedQueriesRequest\"G\n" +
+	"\x18ListSavedQueriesResponse\x12+\n" +
+	"\x05saved\x18\x01 \x03(\v2\x15.treestore.SavedQueryR\x05saved\"-\n" +
+	"\x17DeleteSavedQueryRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"4\n" +
+	"\x18DeleteSavedQueryResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"1\n" +
+	"\x13WatchChangesRequest\x12\x1a\n" +
+	"\bprefixes\x18\x01 \x03(\tR\bprefixes\"\xd1\x01\n" +
+	"\vChangeEvent\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x16\n" +
+	"\x06entity\x18\x02 \x01(\tR\x06entity\x12\x0e\n" +
+	"\x02op\x18\x03 \x01(\tR\x02op\x12\x1b\n" +
+	"\tpolicy_id\x18\x04 \x01(\tR\bpolicyId\x12\x1b\n" +
+	"\tentity_id\x18\x05 \x01(\tR\bentityId\x128\n" +
+	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x14\n" +
+	"\x05topic\x18\a \x01(\tR\x05topic\"/\n" +
+	"\x10StreamWALRequest\x12\x1b\n" +
+	"\tafter_lsn\x18\x01 \x01(\x04R\bafterLsn\"\xa5\x01\n" +
+	"\bWALEntry\x12\x10\n" +
+	"\x03lsn\x18\x01 \x01(\x04R\x03lsn\x12\x15\n" +
+	"\x06txn_id\x18\x02 \x01(\x04R\x05txnId\x12\x0e\n" +
+	"\x02op\x18\x03 \x01(\rR\x02op\x12\x10\n" +
+	"\x03key\x18\x04 \x01(\fR\x03key\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\fR\x05value\x128\n" +
+	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xb4\x01\n" +
+	"\x14QueryAuditLogRequest\x129\n" +
+	"\n" +
+	"start_time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"\x85\x02\n" +
+	"\vAuditRecord\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x04R\x03seq\x12\x16\n" +
+	"\x06method\x18\x03 \x01(\tR\x06method\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\x12\x1a\n" +
+	"\bentities\x18\x05 \x03(\tR\bentities\x12!\n" +
+	"\frequest_hash\x18\x06 \x01(\tR\vrequestHash\x12\x12\n" +
+	"\x04code\x18\a \x01(\tR\x04code\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\t \x01(\tR\trequestId\"I\n" +
+	"\x15QueryAuditLogResponse\x120\n" +
+	"\arecords\x18\x01 \x03(\v2\x16.treestore.AuditRecordR\arecords\"\xa8\x03\n" +
+	"\x06JobRun\x12\x10\n" +
+	"\x03job\x18\x01 \x01(\tR\x03job\x12\x1b\n" +
+	"\tpolicy_id\x18\x02 \x01(\tR\bpolicyId\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x1a\n" +
+	"\battempts\x18\x04 \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x05 \x01(\tR\tlastError\x127\n" +
+	"\tqueued_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\bqueuedAt\x129\n" +
+	"\n" +
+	"started_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x12=\n" +
+	"\fnext_attempt\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vnextAttempt\x12\x18\n" +
+	"\aoutputs\x18\n" +
+	" \x01(\x05R\aoutputs\x12\x14\n" +
+	"\x05rerun\x18\v \x01(\bR\x05rerun\"Y\n" +
+	"\x12ListJobRunsRequest\x12\x10\n" +
+	"\x03job\x18\x01 \x01(\tR\x03job\x12\x1b\n" +
+	"\tpolicy_id\x18\x02 \x01(\tR\bpolicyId\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\"P\n" +
+	"\x13ListJobRunsResponse\x12%\n" +
+	"\x04runs\x18\x01 \x03(\v2\x11.treestore.JobRunR\x04runs\x12\x12\n" +
+	"\x04jobs\x18\x02 \x03(\tR\x04jobs\"B\n" +
+	"\x11TriggerJobRequest\x12\x10\n" +
+	"\x03job\x18\x01 \x01(\tR\x03job\x12\x1b\n" +
+	"\tpolicy_id\x18\x02 \x01(\tR\bpolicyId\"9\n" +
+	"\x12TriggerJobResponse\x12#\n" +
+	"\x03run\x18\x01 \x01(\v2\x11.treestore.JobRunR\x03run\"\x0f\n" +
 	"\rHealthRequest\"k\n" +
 	"\x0eHealthResponse\x12\x18\n" +
 	"\ahealthy\x18\x01 \x01(\bR\ahealthy\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12%\n" +
-	"\x0euptime_seconds\x18\x03 \x01(\x03R\ruptimeSeconds\"\x0e\n" +
-	"\fStatsRequest\"\xc2\x02\n" +
+	"\x0euptime_seconds\x18\x03 \x01(\x03R\ruptimeSeconds\"M\n" +
+	"\fStatsRequest\x12 \n" +
+	"\vapproximate\x18\x01 \x01(\bR\vapproximate\x12\x1b\n" +
+	"\tpolicy_id\x18\x02 \x01(\tR\bpolicyId\"\xe4\x04\n" +
 	"\rStatsResponse\x12'\n" +
 	"\x0ftotal_documents\x18\x01 \x01(\x03R\x0etotalDocuments\x12\x1f\n" +
 	"\vtotal_nodes\x18\x02 \x01(\x03R\n" +
 	"totalNodes\x12%\n" +
 	"\x0etotal_versions\x18\x03 \x01(\x03R\rtotalVersions\x12\"\n" +
 	"\rdb_size_bytes\x18\x04 \x01(\x03R\vdbSizeBytes\x12X\n" +
-	"\x10operation_counts\x18\x05 \x03(\v2-.treestore.StatsResponse.OperationCountsEntryR\x0foperationCounts\x1aB\n" +
+	"\x10operation_counts\x18\x05 \x03(\v2-.treestore.StatsResponse.OperationCountsEntryR\x0foperationCounts\x12\x1f\n" +
+	"\vsync_policy\x18\x06 \x01(\tR\n" +
+	"syncPolicy\x12(\n" +
+	"\x10sync_interval_ms\x18\a \x01(\x03R\x0esyncIntervalMs\x12+\n" +
+	"\x11unflushed_commits\x18\b \x01(\x03R\x10unflushedCommits\x12(\n" +
+	"\x10last_flush_error\x18\t \x01(\tR\x0elastFlushError\x12 \n" +
+	"\vapproximate\x18\n" +
+	" \x01(\bR\vapproximate\x12*\n" +
+	"\x11total_nodes_error\x18\v \x01(\x03R\x0ftotalNodesError\x120\n" +
+	"\x14total_versions_error\x18\f \x01(\x03R\x12totalVersionsError\x1aB\n" +
 	"\x14OperationCountsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x012\x80\x0f\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"L\n" +
+	"\x17StorageBreakdownRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"L\n" +
+	"\n" +
+	"StoreUsage\x12\x14\n" +
+	"\x05store\x18\x01 \x01(\tR\x05store\x12\x12\n" +
+	"\x04keys\x18\x02 \x01(\x03R\x04keys\x12\x14\n" +
+	"\x05bytes\x18\x03 \x01(\x03R\x05bytes\"o\n" +
+	"\vPolicyUsage\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x03R\x05bytes\x12-\n" +
+	"\x06stores\x18\x03 \x03(\v2\x15.treestore.StoreUsageR\x06stores\"\xc2\x01\n" +
+	"\x18StorageBreakdownResponse\x12-\n" +
+	"\x06stores\x18\x01 \x03(\v2\x15.treestore.StoreUsageR\x06stores\x122\n" +
+	"\bpolicies\x18\x02 \x03(\v2\x16.treestore.PolicyUsageR\bpolicies\x12\x1f\n" +
+	"\vtotal_bytes\x18\x03 \x01(\x03R\n" +
+	"totalBytes\x12\"\n" +
+	"\rdb_size_bytes\x18\x04 \x01(\x03R\vdbSizeBytes\"\x13\n" +
+	"\x11CheckpointRequest\"P\n" +
+	"\x12CheckpointResponse\x12\x19\n" +
+	"\blast_lsn\x18\x01 \x01(\x04R\alastLsn\x12\x1f\n" +
+	"\vduration_ms\x18\x02 \x01(\x03R\n" +
+	"durationMs\")\n" +
+	"\x0eCompactRequest\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\"\xde\x01\n" +
+	"\x0fCompactResponse\x12\x14\n" +
+	"\x05pages\x18\x01 \x01(\x04R\x05pages\x12\x1d\n" +
+	"\n" +
+	"tree_pages\x18\x02 \x01(\x03R\ttreePages\x12\x1d\n" +
+	"\n" +
+	"free_pages\x18\x03 \x01(\x03R\tfreePages\x12!\n" +
+	"\fleaked_pages\x18\x04 \x01(\x03R\vleakedPages\x12'\n" +
+	"\x0freclaimed_pages\x18\x05 \x01(\x03R\x0ereclaimedPages\x12+\n" +
+	"\x11conflicting_pages\x18\x06 \x01(\x03R\x10conflictingPages\"-\n" +
+	"\x0eReindexRequest\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\"6\n" +
+	"\x0fReindexResponse\x12#\n" +
+	"\rnodes_indexed\x18\x01 \x01(\x03R\fnodesIndexed\"\x0e\n" +
+	"\fFlushRequest\"8\n" +
+	"\rFlushResponse\x12'\n" +
+	"\x0fflushed_commits\x18\x01 \x01(\x03R\x0eflushedCommits\"C\n" +
+	"\rBackupRequest\x12\x10\n" +
+	"\x03dir\x18\x01 \x01(\tR\x03dir\x12 \n" +
+	"\vincremental\x18\x02 \x01(\bR\vincremental\"p\n" +
+	"\x0eBackupResponse\x12\x19\n" +
+	"\bfrom_lsn\x18\x01 \x01(\x04R\afromLsn\x12\x15\n" +
+	"\x06to_lsn\x18\x02 \x01(\x04R\x05toLsn\x12\x18\n" +
+	"\aentries\x18\x03 \x01(\x03R\aentries\x12\x12\n" +
+	"\x04file\x18\x04 \x01(\tR\x04file\"*\n" +
+	"\x12SetLogLevelRequest\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\"<\n" +
+	"\x13SetLogLevelResponse\x12%\n" +
+	"\x0eprevious_level\x18\x01 \x01(\tR\rpreviousLevel\"v\n" +
+	"\x0fTailLogsRequest\x12\x1b\n" +
+	"\tmin_level\x18\x01 \x01(\tR\bminLevel\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12\x16\n" +
+	"\x06recent\x18\x03 \x01(\x05R\x06recent\x12\x16\n" +
+	"\x06follow\x18\x04 \x01(\bR\x06follow\"\xb0\x01\n" +
+	"\bLogEvent\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\tR\x05level\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x16\n" +
+	"\x06method\x18\x04 \x01(\tR\x06method\x12\x12\n" +
+	"\x04json\x18\x05 \x01(\tR\x04json\x12\x18\n" +
+	"\adropped\x18\x06 \x01(\x04R\adropped\"\x12\n" +
+	"\x10DumpStateRequest\"\x87\x0e\n" +
+	"\x11DumpStateResponse\x12\x17\n" +
+	"\adb_path\x18\x01 \x01(\tR\x06dbPath\x12\x1b\n" +
+	"\tin_memory\x18\x02 \x01(\bR\binMemory\x12\x12\n" +
+	"\x04heap\x18\x03 \x01(\bR\x04heap\x12\x16\n" +
+	"\x06legacy\x18\x04 \x01(\bR\x06legacy\x12\x1c\n" +
+	"\tencrypted\x18\x05 \x01(\bR\tencrypted\x12\x14\n" +
+	"\x05pages\x18\x06 \x01(\x04R\x05pages\x12!\n" +
+	"\fmapped_bytes\x18\a \x01(\x03R\vmappedBytes\x12'\n" +
+	"\x0fmeta_generation\x18\b \x01(\x04R\x0emetaGeneration\x12\x19\n" +
+	"\blast_lsn\x18\t \x01(\x04R\alastLsn\x12\x1f\n" +
+	"\vsync_policy\x18\n" +
+	" \x01(\tR\n" +
+	"syncPolicy\x12+\n" +
+	"\x11unflushed_commits\x18\v \x01(\x03R\x10unflushedCommits\x12\x18\n" +
+	"\aflushes\x18\f \x01(\x04R\aflushes\x129\n" +
+	"\n" +
+	"last_flush\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tlastFlush\x12(\n" +
+	"\x10last_flush_error\x18\x0e \x01(\tR\x0elastFlushError\x12\x1b\n" +
+	"\tread_only\x18\x0f \x01(\bR\breadOnly\x12\x1b\n" +
+	"\tlog_level\x18\x10 \x01(\tR\blogLevel\x12%\n" +
+	"\x0euptime_seconds\x18\x11 \x01(\x03R\ruptimeSeconds\x12\x1e\n" +
+	"\n" +
+	"goroutines\x18\x12 \x01(\x03R\n" +
+	"goroutines\x12(\n" +
+	"\x10heap_alloc_bytes\x18\x13 \x01(\x04R\x0eheapAllocBytes\x12)\n" +
+	"\x10retention_sweeps\x18\x14 \x01(\x03R\x0fretentionSweeps\x12\\\n" +
+	"\x10operation_counts\x18\x15 \x03(\v21.treestore.DumpStateResponse.OperationCountsEntryR\x0foperationCounts\x12%\n" +
+	"\x0eformat_version\x18\x16 \x01(\rR\rformatVersion\x120\n" +
+	"\x14migrated_from_format\x18\x17 \x01(\rR\x12migratedFromFormat\x12,\n" +
+	"\x12wal_archived_files\x18\x18 \x01(\x04R\x10walArchivedFiles\x120\n" +
+	"\x14wal_archive_failures\x18\x19 \x01(\x04R\x12walArchiveFailures\x12*\n" +
+	"\x11wal_archive_error\x18\x1a \x01(\tR\x0fwalArchiveError\x12\x1f\n" +
+	"\vflushed_lsn\x18\x1b \x01(\x04R\n" +
+	"flushedLsn\x124\n" +
+	"\x16checkpoint_lag_entries\x18\x1c \x01(\x04R\x14checkpointLagEntries\x12*\n" +
+	"\x11checkpoint_lag_ms\x18\x1d \x01(\x03R\x0fcheckpointLagMs\x12 \n" +
+	"\vcheckpoints\x18\x1e \x01(\x04R\vcheckpoints\x12C\n" +
+	"\x0flast_checkpoint\x18\x1f \x01(\v2\x1a.google.protobuf.TimestampR\x0elastCheckpoint\x12)\n" +
+	"\x10checkpoint_error\x18  \x01(\tR\x0fcheckpointError\x12\x1b\n" +
+	"\twal_files\x18! \x01(\x03R\bwalFiles\x12+\n" +
+	"\x11compaction_passes\x18\" \x01(\x03R\x10compactionPasses\x126\n" +
+	"\x17compaction_leaves_moved\x18# \x01(\x03R\x15compactionLeavesMoved\x12!\n" +
+	"\fbloom_checks\x18$ \x01(\x03R\vbloomChecks\x12)\n" +
+	"\x10bloom_rejections\x18% \x01(\x03R\x0fbloomRejections\x12(\n" +
+	"\x10query_cache_hits\x18& \x01(\x03R\x0equeryCacheHits\x12,\n" +
+	"\x12query_cache_misses\x18' \x01(\x03R\x10queryCacheMisses\x12:\n" +
+	"\x19query_cache_invalidations\x18( \x01(\x03R\x17queryCacheInvalidations\x12&\n" +
+	"\x0fpath_cache_hits\x18) \x01(\x03R\rpathCacheHits\x12*\n" +
+	"\x11path_cache_misses\x18* \x01(\x03R\x0fpathCacheMisses\x1aB\n" +
+	"\x14OperationCountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x012\xcf4\n" +
 	"\x10TreeStoreService\x12R\n" +
 	"\rStoreDocument\x12\x1f.treestore.StoreDocumentRequest\x1a .treestore.StoreDocumentResponse\x12L\n" +
 	"\vGetDocument\x12\x1d.treestore.GetDocumentRequest\x1a\x1e.treestore.GetDocumentResponse\x12U\n" +
-	"\x0eDeleteDocument\x12 .treestore.DeleteDocumentRequest\x1a!.treestore.DeleteDocumentResponse\x12@\n" +
-	"\aGetNode\x12\x19.treestore.GetNodeRequest\x1a\x1a.treestore.GetNodeResponse\x12L\n" +
+	"\x0eDeleteDocument\x12 .treestore.DeleteDocumentRequest\x1a!.treestore.DeleteDocumentResponse\x12R\n" +
+	"\rCloneDocument\x12\x1f.treestore.CloneDocumentRequest\x1a .treestore.CloneDocumentResponse\x12j\n" +
+	"\x15RecomputeSectionPaths\x12'.treestore.RecomputeSectionPathsRequest\x1a(.treestore.RecomputeSectionPathsResponse\x12[\n" +
+	"\x10ValidateDocument\x12\".treestore.ValidateDocumentRequest\x1a#.treestore.ValidateDocumentResponse\x12a\n" +
+	"\x12FindDuplicateNodes\x12$.treestore.FindDuplicateNodesRequest\x1a%.treestore.FindDuplicateNodesResponse\x12]\n" +
+	"\x10RefreshSummaries\x12\".treestore.RefreshSummariesRequest\x1a#.treestore.RefreshSummariesProgress0\x01\x12@\n" +
+	"\aGetNode\x12\x19.treestore.GetNodeRequest\x1a\x1a.treestore.GetNodeResponse\x12I\n" +
+	"\n" +
+	"UpdateNode\x12\x1c.treestore.UpdateNodeRequest\x1a\x1d.treestore.UpdateNodeResponse\x12L\n" +
 	"\vGetChildren\x12\x1d.treestore.GetChildrenRequest\x1a\x1e.treestore.GetChildrenResponse\x12I\n" +
 	"\n" +
-	"GetSubtree\x12\x1c.treestore.GetSubtreeRequest\x1a\x1d.treestore.GetSubtreeResponse\x12X\n" +
-	"\x0fGetAncestorPath\x12!.treestore.GetAncestorPathRequest\x1a\".treestore.GetAncestorPathResponse\x12F\n" +
+	"GetSubtree\x12\x1c.treestore.GetSubtreeRequest\x1a\x1d.treestore.GetSubtreeResponse\x12m\n" +
+	"\x16GetSubtreeWithinBudget\x12(.treestore.GetSubtreeWithinBudgetRequest\x1a).treestore.GetSubtreeWithinBudgetResponse\x12X\n" +
+	"\x0fGetAncestorPath\x12!.treestore.GetAncestorPathRequest\x1a\".treestore.GetAncestorPathResponse\x12j\n" +
+	"\x15BatchGetAncestorPaths\x12'.treestore.BatchGetAncestorPathsRequest\x1a(.treestore.BatchGetAncestorPathsResponse\x12L\n" +
+	"\vGetSiblings\x12\x1d.treestore.GetSiblingsRequest\x1a\x1e.treestore.GetSiblingsResponse\x12W\n" +
+	"\x0eGetNextSection\x12!.treestore.AdjacentSectionRequest\x1a\".treestore.AdjacentSectionResponse\x12[\n" +
+	"\x12GetPreviousSection\x12!.treestore.AdjacentSectionRequest\x1a\".treestore.AdjacentSectionResponse\x12G\n" +
+	"\x0fGetReadingOrder\x12!.treestore.GetReadingOrderRequest\x1a\x0f.treestore.Node0\x01\x12[\n" +
+	"\x10GetContextWindow\x12\".treestore.GetContextWindowRequest\x1a#.treestore.GetContextWindowResponse\x12L\n" +
+	"\vExportGraph\x12\x1d.treestore.ExportGraphRequest\x1a\x1e.treestore.ExportGraphResponse\x12F\n" +
 	"\x0fSearchByKeyword\x12\x18.treestore.SearchRequest\x1a\x19.treestore.SearchResponse\x12U\n" +
-	"\x0eGetNodesByPage\x12 .treestore.GetNodesByPageRequest\x1a!.treestore.GetNodesByPageResponse\x12L\n" +
-	"\x0eGetVersionAsOf\x12 .treestore.GetVersionAsOfRequest\x1a\x18.treestore.PolicyVersion\x12O\n" +
-	"\fListVersions\x12\x1e.treestore.ListVersionsRequest\x1a\x1f.treestore.ListVersionsResponse\x12X\n" +
+	"\x0eGetNodesByPage\x12 .treestore.GetNodesByPageRequest\x1a!.treestore.GetNodesByPageResponse\x12O\n" +
+	"\fGlobalSearch\x12\x1e.treestore.GlobalSearchRequest\x1a\x1f.treestore.GlobalSearchResponse\x12F\n" +
+	"\tJoinNodes\x12\x1b.treestore.JoinNodesRequest\x1a\x1c.treestore.JoinNodesResponse\x12C\n" +
+	"\bRetrieve\x12\x1a.treestore.RetrieveRequest\x1a\x1b.treestore.RetrieveResponse\x12L\n" +
+	"\x0eGetVersionAsOf\x12 .treestore.GetVersionAsOfRequest\x1a\x18.treestore.PolicyVersion\x12g\n" +
+	"\x14BatchGetVersionsAsOf\x12&.treestore.BatchGetVersionsAsOfRequest\x1a'.treestore.BatchGetVersionsAsOfResponse\x12O\n" +
+	"\fListVersions\x12\x1e.treestore.ListVersionsRequest\x1a\x1f.treestore.ListVersionsResponse\x12R\n" +
+	"\rDeleteVersion\x12\x1f.treestore.DeleteVersionRequest\x1a .treestore.DeleteVersionResponse\x12R\n" +
+	"\rPruneVersions\x12\x1f.treestore.PruneVersionsRequest\x1a .treestore.PruneVersionsResponse\x12I\n" +
+	"\n" +
+	"TagVersion\x12\x1c.treestore.TagVersionRequest\x1a\x1d.treestore.TagVersionResponse\x12O\n" +
+	"\fUntagVersion\x12\x1e.treestore.UntagVersionRequest\x1a\x1f.treestore.UntagVersionResponse\x12X\n" +
 	"\x0fStoreToolResult\x12!.treestore.StoreToolResultRequest\x1a\".treestore.StoreToolResultResponse\x12U\n" +
 	"\x0eGetToolResults\x12 .treestore.GetToolResultsRequest\x1a!.treestore.GetToolResultsResponse\x12X\n" +
 	"\x0fStoreTrajectory\x12!.treestore.StoreTrajectoryRequest\x1a\".treestore.StoreTrajectoryResponse\x12X\n" +
-	"\x0fGetTrajectories\x12!.treestore.GetTrajectoriesRequest\x1a\".treestore.GetTrajectoriesResponse\x12d\n" +
+	"\x0fGetTrajectories\x12!.treestore.GetTrajectoriesRequest\x1a\".treestore.GetTrajectoriesResponse\x12[\n" +
+	"\x10ReplayTrajectory\x12\".treestore.ReplayTrajectoryRequest\x1a#.treestore.ReplayTrajectoryResponse\x12d\n" +
 	"\x13StoreCrossReference\x12%.treestore.StoreCrossReferenceRequest\x1a&.treestore.StoreCrossReferenceResponse\x12a\n" +
 	"\x12GetCrossReferences\x12$.treestore.GetCrossReferencesRequest\x1a%.treestore.GetCrossReferencesResponse\x12a\n" +
-	"\x12StoreContradiction\x12$.treestore.StoreContradictionRequest\x1a%.treestore.StoreContradictionResponse\x12L\n" +
+	"\x12StoreContradiction\x12$.treestore.StoreContradictionRequest\x1a%.treestore.StoreContradictionResponse\x12[\n" +
+	"\x10BatchSetMetadata\x12\".treestore.BatchSetMetadataRequest\x1a#.treestore.BatchSetMetadataResponse\x12R\n" +
+	"\rPutCollection\x12\x1f.treestore.PutCollectionRequest\x1a .treestore.PutCollectionResponse\x12R\n" +
+	"\rGetCollection\x12\x1f.treestore.GetCollectionRequest\x1a .treestore.GetCollectionResponse\x12X\n" +
+	"\x0fListCollections\x12!.treestore.ListCollectionsRequest\x1a\".treestore.ListCollectionsResponse\x12p\n" +
+	"\x17UpdateCollectionMembers\x12).treestore.UpdateCollectionMembersRequest\x1a*.treestore.UpdateCollectionMembersResponse\x12[\n" +
+	"\x10DeleteCollection\x12\".treestore.DeleteCollectionRequest\x1a#.treestore.DeleteCollectionResponse\x12L\n" +
 	"\vStorePrompt\x12\x1d.treestore.StorePromptRequest\x1a\x1e.treestore.StorePromptResponse\x12F\n" +
 	"\tGetPrompt\x12\x1b.treestore.GetPromptRequest\x1a\x1c.treestore.GetPromptResponse\x12^\n" +
-	"\x11RecordPromptUsage\x12#.treestore.RecordPromptUsageRequest\x1a$.treestore.RecordPromptUsageResponse\x12=\n" +
+	"\x11RecordPromptUsage\x12#.treestore.RecordPromptUsageRequest\x1a$.treestore.RecordPromptUsageResponse\x12j\n" +
+	"\x15StorePromptExperiment\x12'.treestore.StorePromptExperimentRequest\x1a(.treestore.StorePromptExperimentResponse\x12d\n" +
+	"\x13GetPromptExperiment\x12%.treestore.GetPromptExperimentRequest\x1a&.treestore.GetPromptExperimentResponse\x12d\n" +
+	"\x13AssignPromptVariant\x12%.treestore.AssignPromptVariantRequest\x1a&.treestore.AssignPromptVariantResponse\x12[\n" +
+	"\x10LabelPromptUsage\x12\".treestore.LabelPromptUsageRequest\x1a#.treestore.LabelPromptUsageResponse\x12j\n" +
+	"\x15ComparePromptVariants\x12'.treestore.ComparePromptVariantsRequest\x1a(.treestore.ComparePromptVariantsResponse\x12X\n" +
+	"\x0fGetMessagesPage\x12!.treestore.GetMessagesPageRequest\x1a\".treestore.GetMessagesPageResponse\x12^\n" +
+	"\x11GetRecentMessages\x12#.treestore.GetRecentMessagesRequest\x1a$.treestore.GetRecentMessagesResponse\x12d\n" +
+	"\x13SearchConversations\x12%.treestore.SearchConversationsRequest\x1a&.treestore.SearchConversationsResponse\x12a\n" +
+	"\x12ExportConversation\x12$.treestore.ExportConversationRequest\x1a%.treestore.ExportConversationResponse\x12p\n" +
+	"\x17ListConversationsByUser\x12).treestore.ListConversationsByUserRequest\x1a*.treestore.ListConversationsByUserResponse\x12s\n" +
+	"\x18GetUserConversationUsage\x12*.treestore.GetUserConversationUsageRequest\x1a+.treestore.GetUserConversationUsageResponse\x12s\n" +
+	"\x18SetUserConversationQuota\x12*.treestore.SetUserConversationQuotaRequest\x1a+.treestore.SetUserConversationQuotaResponse\x12C\n" +
+	"\vStreamQuery\x12\x1d.treestore.StreamQueryRequest\x1a\x13.treestore.QueryRow0\x01\x12F\n" +
+	"\tSaveQuery\x12\x1b.treestore.SaveQueryRequest\x1a\x1c.treestore.SaveQueryResponse\x12^\n" +
+	"\x11ExecuteSavedQuery\x12#.treestore.ExecuteSavedQueryRequest\x1a$.treestore.ExecuteSavedQueryResponse\x12^\n" +
+	"\x11RefreshSavedQuery\x12#.treestore.RefreshSavedQueryRequest\x1a$.treestore.RefreshSavedQueryResponse\x12[\n" +
+	"\x10ListSavedQueries\x12\".treestore.ListSavedQueriesRequest\x1a#.treestore.ListSavedQueriesResponse\x12[\n" +
+	"\x10DeleteSavedQuery\x12\".treestore.DeleteSavedQueryRequest\x1a#.treestore.DeleteSavedQueryResponse\x12H\n" +
+	"\fWatchChanges\x12\x1e.treestore.WatchChangesRequest\x1a\x16.treestore.ChangeEvent0\x01\x12?\n" +
+	"\tStreamWAL\x12\x1b.treestore.StreamWALRequest\x1a\x13.treestore.WALEntry0\x01\x12R\n" +
+	"\rQueryAuditLog\x12\x1f.treestore.QueryAuditLogRequest\x1a .treestore.QueryAuditLogResponse\x12L\n" +
+	"\vListJobRuns\x12\x1d.treestore.ListJobRunsRequest\x1a\x1e.treestore.ListJobRunsResponse\x12I\n" +
+	"\n" +
+	"TriggerJob\x12\x1c.treestore.TriggerJobRequest\x1a\x1d.treestore.TriggerJobResponse\x12=\n" +
 	"\x06Health\x12\x18.treestore.HealthRequest\x1a\x19.treestore.HealthResponse\x12:\n" +
-	"\x05Stats\x12\x17.treestore.StatsRequest\x1a\x18.treestore.StatsResponseB#Z!github.com/nainya/treestore/protob\x06proto3"
+	"\x05Stats\x12\x17.treestore.StatsRequest\x1a\x18.treestore.StatsResponse\x12[\n" +
+	"\x10StorageBreakdown\x12\".treestore.StorageBreakdownRequest\x1a#.treestore.StorageBreakdownResponse2\xaf\x04\n" +
+	"\x0eTreeStoreAdmin\x12I\n" +
+	"\n" +
+	"Checkpoint\x12\x1c.treestore.CheckpointRequest\x1a\x1d.treestore.CheckpointResponse\x12@\n" +
+	"\aCompact\x12\x19.treestore.CompactRequest\x1a\x1a.treestore.CompactResponse\x12@\n" +
+	"\aReindex\x12\x19.treestore.ReindexRequest\x1a\x1a.treestore.ReindexResponse\x12:\n" +
+	"\x05Flush\x12\x17.treestore.FlushRequest\x1a\x18.treestore.FlushResponse\x12=\n" +
+	"\x06Backup\x12\x18.treestore.BackupRequest\x1a\x19.treestore.BackupResponse\x12L\n" +
+	"\vSetLogLevel\x12\x1d.treestore.SetLogLevelRequest\x1a\x1e.treestore.SetLogLevelResponse\x12F\n" +
+	"\tDumpState\x12\x1b.treestore.DumpStateRequest\x1a\x1c.treestore.DumpStateResponse\x12=\n" +
+	"\bTailLogs\x12\x1a.treestore.TailLogsRequest\x1a\x13.treestore.LogEvent0\x012T\n" +
+	"\n" +
+	"Summarizer\x12F\n" +
+	"\tSummarize\x12\x1b.treestore.SummarizeRequest\x1a\x1c.treestore.SummarizeResponseB#Z!github.com/nainya/treestore/protob\x06proto3"
 
 var (
 	file_proto_treestore_proto_rawDescOnce sync.Once
@@ -3497,161 +13605,559 @@ func file_proto_treestore_proto_rawDescGZIP() []byte {
 	return file_proto_treestore_proto_rawDescData
 }
 
-var file_proto_treestore_proto_msgTypes = make([]protoimpl.MessageInfo, 59)
+var file_proto_treestore_proto_msgTypes = make([]protoimpl.MessageInfo, 225)
 var file_proto_treestore_proto_goTypes = []any{
-	(*Document)(nil),                    // 0: treestore.Document
-	(*Node)(nil),                        // 1: treestore.Node
-	(*PolicyVersion)(nil),               // 2: treestore.PolicyVersion
-	(*ToolResult)(nil),                  // 3: treestore.ToolResult
-	(*Trajectory)(nil),                  // 4: treestore.Trajectory
-	(*TrajectoryStep)(nil),              // 5: treestore.TrajectoryStep
-	(*CrossReference)(nil),              // 6: treestore.CrossReference
-	(*Contradiction)(nil),               // 7: treestore.Contradiction
-	(*PromptTemplate)(nil),              // 8: treestore.PromptTemplate
-	(*PromptUsage)(nil),                 // 9: treestore.PromptUsage
-	(*StoreDocumentRequest)(nil),        // 10: treestore.StoreDocumentRequest
-	(*StoreDocumentResponse)(nil),       // 11: treestore.StoreDocumentResponse
-	(*GetDocumentRequest)(nil),          // 12: treestore.GetDocumentRequest
-	(*GetDocumentResponse)(nil),         // 13: treestore.GetDocumentResponse
-	(*DeleteDocumentRequest)(nil),       // 14: treestore.DeleteDocumentRequest
-	(*DeleteDocumentResponse)(nil),      // 15: treestore.DeleteDocumentResponse
-	(*GetNodeRequest)(nil),              // 16: treestore.GetNodeRequest
-	(*GetNodeResponse)(nil),             // 17: treestore.GetNodeResponse
-	(*GetChildrenRequest)(nil),          // 18: treestore.GetChildrenRequest
-	(*GetChildrenResponse)(nil),         // 19: treestore.GetChildrenResponse
-	(*GetSubtreeRequest)(nil),           // 20: treestore.GetSubtreeRequest
-	(*GetSubtreeResponse)(nil),          // 21: treestore.GetSubtreeResponse
-	(*GetAncestorPathRequest)(nil),      // 22: treestore.GetAncestorPathRequest
-	(*GetAncestorPathResponse)(nil),     // 23: treestore.GetAncestorPathResponse
-	(*SearchRequest)(nil),               // 24: treestore.SearchRequest
-	(*SearchResponse)(nil),              // 25: treestore.SearchResponse
-	(*SearchResult)(nil),                // 26: treestore.SearchResult
-	(*GetNodesByPageRequest)(nil),       // 27: treestore.GetNodesByPageRequest
-	(*GetNodesByPageResponse)(nil),      // 28: treestore.GetNodesByPageResponse
-	(*GetVersionAsOfRequest)(nil),       // 29: treestore.GetVersionAsOfRequest
-	(*ListVersionsRequest)(nil),         // 30: treestore.ListVersionsRequest
-	(*ListVersionsResponse)(nil),        // 31: treestore.ListVersionsResponse
-	(*StoreToolResultRequest)(nil),      // 32: treestore.StoreToolResultRequest
-	(*StoreToolResultResponse)(nil),     // 33: treestore.StoreToolResultResponse
-	(*GetToolResultsRequest)(nil),       // 34: treestore.GetToolResultsRequest
-	(*GetToolResultsResponse)(nil),      // 35: treestore.GetToolResultsResponse
-	(*StoreTrajectoryRequest)(nil),      // 36: treestore.StoreTrajectoryRequest
-	(*StoreTrajectoryResponse)(nil),     // 37: treestore.StoreTrajectoryResponse
-	(*GetTrajectoriesRequest)(nil),      // 38: treestore.GetTrajectoriesRequest
-	(*GetTrajectoriesResponse)(nil),     // 39: treestore.GetTrajectoriesResponse
-	(*StoreCrossReferenceRequest)(nil),  // 40: treestore.StoreCrossReferenceRequest
-	(*StoreCrossReferenceResponse)(nil), // 41: treestore.StoreCrossReferenceResponse
-	(*GetCrossReferencesRequest)(nil),   // 42: treestore.GetCrossReferencesRequest
-	(*GetCrossReferencesResponse)(nil),  // 43: treestore.GetCrossReferencesResponse
-	(*StoreContradictionRequest)(nil),   // 44: treestore.StoreContradictionRequest
-	(*StoreContradictionResponse)(nil),  // 45: treestore.StoreContradictionResponse
-	(*StorePromptRequest)(nil),          // 46: treestore.StorePromptRequest
-	(*StorePromptResponse)(nil),         // 47: treestore.StorePromptResponse
-	(*GetPromptRequest)(nil),            // 48: treestore.GetPromptRequest
-	(*GetPromptResponse)(nil),           // 49: treestore.GetPromptResponse
-	(*RecordPromptUsageRequest)(nil),    // 50: treestore.RecordPromptUsageRequest
-	(*RecordPromptUsageResponse)(nil),   // 51: treestore.RecordPromptUsageResponse
-	(*HealthRequest)(nil),               // 52: treestore.HealthRequest
-	(*HealthResponse)(nil),              // 53: treestore.HealthResponse
-	(*StatsRequest)(nil),                // 54: treestore.StatsRequest
-	(*StatsResponse)(nil),               // 55: treestore.StatsResponse
-	nil,                                 // 56: treestore.Document.MetadataEntry
-	nil,                                 // 57: treestore.PromptUsage.FilledVariablesEntry
-	nil,                                 // 58: treestore.StatsResponse.OperationCountsEntry
-	(*timestamppb.Timestamp)(nil),       // 59: google.protobuf.Timestamp
+	(*Document)(nil),                         // 0: treestore.Document
+	(*Node)(nil),                             // 1: treestore.Node
+	(*PolicyVersion)(nil),                    // 2: treestore.PolicyVersion
+	(*ToolResult)(nil),                       // 3: treestore.ToolResult
+	(*Trajectory)(nil),                       // 4: treestore.Trajectory
+	(*TrajectoryStep)(nil),                   // 5: treestore.TrajectoryStep
+	(*CrossReference)(nil),                   // 6: treestore.CrossReference
+	(*Contradiction)(nil),                    // 7: treestore.Contradiction
+	(*PromptTemplate)(nil),                   // 8: treestore.PromptTemplate
+	(*PromptUsage)(nil),                      // 9: treestore.PromptUsage
+	(*PromptExperiment)(nil),                 // 10: treestore.PromptExperiment
+	(*PromptVariant)(nil),                    // 11: treestore.PromptVariant
+	(*VariantStats)(nil),                     // 12: treestore.VariantStats
+	(*Message)(nil),                          // 13: treestore.Message
+	(*Conversation)(nil),                     // 14: treestore.Conversation
+	(*ConversationQuota)(nil),                // 15: treestore.ConversationQuota
+	(*StoreDocumentRequest)(nil),             // 16: treestore.StoreDocumentRequest
+	(*StoreDocumentResponse)(nil),            // 17: treestore.StoreDocumentResponse
+	(*GetDocumentRequest)(nil),               // 18: treestore.GetDocumentRequest
+	(*GetDocumentResponse)(nil),              // 19: treestore.GetDocumentResponse
+	(*DeleteDocumentRequest)(nil),            // 20: treestore.DeleteDocumentRequest
+	(*DeleteDocumentResponse)(nil),           // 21: treestore.DeleteDocumentResponse
+	(*CloneDocumentRequest)(nil),             // 22: treestore.CloneDocumentRequest
+	(*CloneDocumentResponse)(nil),            // 23: treestore.CloneDocumentResponse
+	(*RecomputeSectionPathsRequest)(nil),     // 24: treestore.RecomputeSectionPathsRequest
+	(*SectionPathChange)(nil),                // 25: treestore.SectionPathChange
+	(*RecomputeSectionPathsResponse)(nil),    // 26: treestore.RecomputeSectionPathsResponse
+	(*ValidateDocumentRequest)(nil),          // 27: treestore.ValidateDocumentRequest
+	(*DocumentIssue)(nil),                    // 28: treestore.DocumentIssue
+	(*ValidateDocumentResponse)(nil),         // 29: treestore.ValidateDocumentResponse
+	(*FindDuplicateNodesRequest)(nil),        // 30: treestore.FindDuplicateNodesRequest
+	(*NodeRef)(nil),                          // 31: treestore.NodeRef
+	(*DuplicateGroup)(nil),                   // 32: treestore.DuplicateGroup
+	(*FindDuplicateNodesResponse)(nil),       // 33: treestore.FindDuplicateNodesResponse
+	(*RefreshSummariesRequest)(nil),          // 34: treestore.RefreshSummariesRequest
+	(*RefreshSummariesProgress)(nil),         // 35: treestore.RefreshSummariesProgress
+	(*SummarizeRequest)(nil),                 // 36: treestore.SummarizeRequest
+	(*SummarizeResponse)(nil),                // 37: treestore.SummarizeResponse
+	(*GetNodeRequest)(nil),                   // 38: treestore.GetNodeRequest
+	(*GetNodeResponse)(nil),                  // 39: treestore.GetNodeResponse
+	(*UpdateNodeRequest)(nil),                // 40: treestore.UpdateNodeRequest
+	(*UpdateNodeResponse)(nil),               // 41: treestore.UpdateNodeResponse
+	(*GetChildrenRequest)(nil),               // 42: treestore.GetChildrenRequest
+	(*GetChildrenResponse)(nil),              // 43: treestore.GetChildrenResponse
+	(*GetSubtreeRequest)(nil),                // 44: treestore.GetSubtreeRequest
+	(*GetSubtreeResponse)(nil),               // 45: treestore.GetSubtreeResponse
+	(*GetSubtreeWithinBudgetRequest)(nil),    // 46: treestore.GetSubtreeWithinBudgetRequest
+	(*GetSubtreeWithinBudgetResponse)(nil),   // 47: treestore.GetSubtreeWithinBudgetResponse
+	(*GetAncestorPathRequest)(nil),           // 48: treestore.GetAncestorPathRequest
+	(*GetAncestorPathResponse)(nil),          // 49: treestore.GetAncestorPathResponse
+	(*BatchGetAncestorPathsRequest)(nil),     // 50: treestore.BatchGetAncestorPathsRequest
+	(*AncestorPath)(nil),                     // 51: treestore.AncestorPath
+	(*BatchGetAncestorPathsResponse)(nil),    // 52: treestore.BatchGetAncestorPathsResponse
+	(*GetSiblingsRequest)(nil),               // 53: treestore.GetSiblingsRequest
+	(*GetSiblingsResponse)(nil),              // 54: treestore.GetSiblingsResponse
+	(*AdjacentSectionRequest)(nil),           // 55: treestore.AdjacentSectionRequest
+	(*AdjacentSectionResponse)(nil),          // 56: treestore.AdjacentSectionResponse
+	(*GetReadingOrderRequest)(nil),           // 57: treestore.GetReadingOrderRequest
+	(*GetContextWindowRequest)(nil),          // 58: treestore.GetContextWindowRequest
+	(*ContextEntry)(nil),                     // 59: treestore.ContextEntry
+	(*GetContextWindowResponse)(nil),         // 60: treestore.GetContextWindowResponse
+	(*ExportGraphRequest)(nil),               // 61: treestore.ExportGraphRequest
+	(*ExportGraphResponse)(nil),              // 62: treestore.ExportGraphResponse
+	(*SearchRequest)(nil),                    // 63: treestore.SearchRequest
+	(*SearchFilter)(nil),                     // 64: treestore.SearchFilter
+	(*SearchResponse)(nil),                   // 65: treestore.SearchResponse
+	(*SearchResult)(nil),                     // 66: treestore.SearchResult
+	(*Highlight)(nil),                        // 67: treestore.Highlight
+	(*RetrieveRequest)(nil),                  // 68: treestore.RetrieveRequest
+	(*RetrieveResponse)(nil),                 // 69: treestore.RetrieveResponse
+	(*RetrievedDocument)(nil),                // 70: treestore.RetrievedDocument
+	(*GlobalSearchRequest)(nil),              // 71: treestore.GlobalSearchRequest
+	(*GlobalSearchResponse)(nil),             // 72: treestore.GlobalSearchResponse
+	(*PolicySearchResults)(nil),              // 73: treestore.PolicySearchResults
+	(*JoinNodesRequest)(nil),                 // 74: treestore.JoinNodesRequest
+	(*JoinNodesResponse)(nil),                // 75: treestore.JoinNodesResponse
+	(*JoinedNode)(nil),                       // 76: treestore.JoinedNode
+	(*GetNodesByPageRequest)(nil),            // 77: treestore.GetNodesByPageRequest
+	(*GetNodesByPageResponse)(nil),           // 78: treestore.GetNodesByPageResponse
+	(*GetVersionAsOfRequest)(nil),            // 79: treestore.GetVersionAsOfRequest
+	(*BatchGetVersionsAsOfRequest)(nil),      // 80: treestore.BatchGetVersionsAsOfRequest
+	(*BatchGetVersionsAsOfResponse)(nil),     // 81: treestore.BatchGetVersionsAsOfResponse
+	(*ListVersionsRequest)(nil),              // 82: treestore.ListVersionsRequest
+	(*ListVersionsResponse)(nil),             // 83: treestore.ListVersionsResponse
+	(*DeleteVersionRequest)(nil),             // 84: treestore.DeleteVersionRequest
+	(*DeleteVersionResponse)(nil),            // 85: treestore.DeleteVersionResponse
+	(*PruneVersionsRequest)(nil),             // 86: treestore.PruneVersionsRequest
+	(*PruneVersionsResponse)(nil),            // 87: treestore.PruneVersionsResponse
+	(*TagVersionRequest)(nil),                // 88: treestore.TagVersionRequest
+	(*TagVersionResponse)(nil),               // 89: treestore.TagVersionResponse
+	(*UntagVersionRequest)(nil),              // 90: treestore.UntagVersionRequest
+	(*UntagVersionResponse)(nil),             // 91: treestore.UntagVersionResponse
+	(*StoreToolResultRequest)(nil),           // 92: treestore.StoreToolResultRequest
+	(*StoreToolResultResponse)(nil),          // 93: treestore.StoreToolResultResponse
+	(*GetToolResultsRequest)(nil),            // 94: treestore.GetToolResultsRequest
+	(*GetToolResultsResponse)(nil),           // 95: treestore.GetToolResultsResponse
+	(*StoreTrajectoryRequest)(nil),           // 96: treestore.StoreTrajectoryRequest
+	(*StoreTrajectoryResponse)(nil),          // 97: treestore.StoreTrajectoryResponse
+	(*GetTrajectoriesRequest)(nil),           // 98: treestore.GetTrajectoriesRequest
+	(*GetTrajectoriesResponse)(nil),          // 99: treestore.GetTrajectoriesResponse
+	(*ReplayTrajectoryRequest)(nil),          // 100: treestore.ReplayTrajectoryRequest
+	(*ReplayTrajectoryResponse)(nil),         // 101: treestore.ReplayTrajectoryResponse
+	(*StepReplay)(nil),                       // 102: treestore.StepReplay
+	(*StoreCrossReferenceRequest)(nil),       // 103: treestore.StoreCrossReferenceRequest
+	(*StoreCrossReferenceResponse)(nil),      // 104: treestore.StoreCrossReferenceResponse
+	(*GetCrossReferencesRequest)(nil),        // 105: treestore.GetCrossReferencesRequest
+	(*GetCrossReferencesResponse)(nil),       // 106: treestore.GetCrossReferencesResponse
+	(*StoreContradictionRequest)(nil),        // 107: treestore.StoreContradictionRequest
+	(*StoreContradictionResponse)(nil),       // 108: treestore.StoreContradictionResponse
+	(*BatchSetMetadataRequest)(nil),          // 109: treestore.BatchSetMetadataRequest
+	(*BatchSetMetadataResponse)(nil),         // 110: treestore.BatchSetMetadataResponse
+	(*Collection)(nil),                       // 111: treestore.Collection
+	(*PutCollectionRequest)(nil),             // 112: treestore.PutCollectionRequest
+	(*PutCollectionResponse)(nil),            // 113: treestore.PutCollectionResponse
+	(*GetCollectionRequest)(nil),             // 114: treestore.GetCollectionRequest
+	(*GetCollectionResponse)(nil),            // 115: treestore.GetCollectionResponse
+	(*ListCollectionsRequest)(nil),           // 116: treestore.ListCollectionsRequest
+	(*ListCollectionsResponse)(nil),          // 117: treestore.ListCollectionsResponse
+	(*UpdateCollectionMembersRequest)(nil),   // 118: treestore.UpdateCollectionMembersRequest
+	(*UpdateCollectionMembersResponse)(nil),  // 119: treestore.UpdateCollectionMembersResponse
+	(*DeleteCollectionRequest)(nil),          // 120: treestore.DeleteCollectionRequest
+	(*DeleteCollectionResponse)(nil),         // 121: treestore.DeleteCollectionResponse
+	(*StorePromptRequest)(nil),               // 122: treestore.StorePromptRequest
+	(*StorePromptResponse)(nil),              // 123: treestore.StorePromptResponse
+	(*GetPromptRequest)(nil),                 // 124: treestore.GetPromptRequest
+	(*GetPromptResponse)(nil),                // 125: treestore.GetPromptResponse
+	(*RecordPromptUsageRequest)(nil),         // 126: treestore.RecordPromptUsageRequest
+	(*RecordPromptUsageResponse)(nil),        // 127: treestore.RecordPromptUsageResponse
+	(*StorePromptExperimentRequest)(nil),     // 128: treestore.StorePromptExperimentRequest
+	(*StorePromptExperimentResponse)(nil),    // 129: treestore.StorePromptExperimentResponse
+	(*GetPromptExperimentRequest)(nil),       // 130: treestore.GetPromptExperimentRequest
+	(*GetPromptExperimentResponse)(nil),      // 131: treestore.GetPromptExperimentResponse
+	(*AssignPromptVariantRequest)(nil),       // 132: treestore.AssignPromptVariantRequest
+	(*AssignPromptVariantResponse)(nil),      // 133: treestore.AssignPromptVariantResponse
+	(*LabelPromptUsageRequest)(nil),          // 134: treestore.LabelPromptUsageRequest
+	(*LabelPromptUsageResponse)(nil),         // 135: treestore.LabelPromptUsageResponse
+	(*ComparePromptVariantsRequest)(nil),     // 136: treestore.ComparePromptVariantsRequest
+	(*ComparePromptVariantsResponse)(nil),    // 137: treestore.ComparePromptVariantsResponse
+	(*GetMessagesPageRequest)(nil),           // 138: treestore.GetMessagesPageRequest
+	(*GetMessagesPageResponse)(nil),          // 139: treestore.GetMessagesPageResponse
+	(*GetRecentMessagesRequest)(nil),         // 140: treestore.GetRecentMessagesRequest
+	(*GetRecentMessagesResponse)(nil),        // 141: treestore.GetRecentMessagesResponse
+	(*SearchConversationsRequest)(nil),       // 142: treestore.SearchConversationsRequest
+	(*ConversationSearchResult)(nil),         // 143: treestore.ConversationSearchResult
+	(*SearchConversationsResponse)(nil),      // 144: treestore.SearchConversationsResponse
+	(*ExportConversationRequest)(nil),        // 145: treestore.ExportConversationRequest
+	(*ExportConversationResponse)(nil),       // 146: treestore.ExportConversationResponse
+	(*ListConversationsByUserRequest)(nil),   // 147: treestore.ListConversationsByUserRequest
+	(*ListConversationsByUserResponse)(nil),  // 148: treestore.ListConversationsByUserResponse
+	(*GetUserConversationUsageRequest)(nil),  // 149: treestore.GetUserConversationUsageRequest
+	(*GetUserConversationUsageResponse)(nil), // 150: treestore.GetUserConversationUsageResponse
+	(*SetUserConversationQuotaRequest)(nil),  // 151: treestore.SetUserConversationQuotaRequest
+	(*SetUserConversationQuotaResponse)(nil), // 152: treestore.SetUserConversationQuotaResponse
+	(*StreamQueryRequest)(nil),               // 153: treestore.StreamQueryRequest
+	(*MetadataEntry)(nil),                    // 154: treestore.MetadataEntry
+	(*QueryRow)(nil),                         // 155: treestore.QueryRow
+	(*QueryGroup)(nil),                       // 156: treestore.QueryGroup
+	(*SavedQuery)(nil),                       // 157: treestore.SavedQuery
+	(*SaveQueryRequest)(nil),                 // 158: treestore.SaveQueryRequest
+	(*SaveQueryResponse)(nil),                // 159: treestore.SaveQueryResponse
+	(*ExecuteSavedQueryRequest)(nil),         // 160: treestore.ExecuteSavedQueryRequest
+	(*ExecuteSavedQueryResponse)(nil),        // 161: treestore.ExecuteSavedQueryResponse
+	(*RefreshSavedQueryRequest)(nil),         // 162: treestore.RefreshSavedQueryRequest
+	(*RefreshSavedQueryResponse)(nil),        // 163: treestore.RefreshSavedQueryResponse
+	(*ListSavedQueriesRequest)(nil),          // 164: treestore.ListSavedQueriesRequest
+	(*ListSavedQueriesResponse)(nil),         // 165: treestore.ListSavedQueriesResponse
+	(*DeleteSavedQueryRequest)(nil),          // 166: treestore.DeleteSavedQueryRequest
+	(*DeleteSavedQueryResponse)(nil),         // 167: treestore.DeleteSavedQueryResponse
+	(*WatchChangesRequest)(nil),              // 168: treestore.WatchChangesRequest
+	(*ChangeEvent)(nil),                      // 169: treestore.ChangeEvent
+	(*StreamWALRequest)(nil),                 // 170: treestore.StreamWALRequest
+	(*WALEntry)(nil),                         // 171: treestore.WALEntry
+	(*QueryAuditLogRequest)(nil),             // 172: treestore.QueryAuditLogRequest
+	(*AuditRecord)(nil),                      // 173: treestore.AuditRecord
+	(*QueryAuditLogResponse)(nil),            // 174: treestore.QueryAuditLogResponse
+	(*JobRun)(nil),                           // 175: treestore.JobRun
+	(*ListJobRunsRequest)(nil),               // 176: treestore.ListJobRunsRequest
+	(*ListJobRunsResponse)(nil),              // 177: treestore.ListJobRunsResponse
+	(*TriggerJobRequest)(nil),                // 178: treestore.TriggerJobRequest
+	(*TriggerJobResponse)(nil),               // 179: treestore.TriggerJobResponse
+	(*HealthRequest)(nil),                    // 180: treestore.HealthRequest
+	(*HealthResponse)(nil),                   // 181: treestore.HealthResponse
+	(*StatsRequest)(nil),                     // 182: treestore.StatsRequest
+	(*StatsResponse)(nil),                    // 183: treestore.StatsResponse
+	(*StorageBreakdownRequest)(nil),          // 184: treestore.StorageBreakdownRequest
+	(*StoreUsage)(nil),                       // 185: treestore.StoreUsage
+	(*PolicyUsage)(nil),                      // 186: treestore.PolicyUsage
+	(*StorageBreakdownResponse)(nil),         // 187: treestore.StorageBreakdownResponse
+	(*CheckpointRequest)(nil),                // 188: treestore.CheckpointRequest
+	(*CheckpointResponse)(nil),               // 189: treestore.CheckpointResponse
+	(*CompactRequest)(nil),                   // 190: treestore.CompactRequest
+	(*CompactResponse)(nil),                  // 191: treestore.CompactResponse
+	(*ReindexRequest)(nil),                   // 192: treestore.ReindexRequest
+	(*ReindexResponse)(nil),                  // 193: treestore.ReindexResponse
+	(*FlushRequest)(nil),                     // 194: treestore.FlushRequest
+	(*FlushResponse)(nil),                    // 195: treestore.FlushResponse
+	(*BackupRequest)(nil),                    // 196: treestore.BackupRequest
+	(*BackupResponse)(nil),                   // 197: treestore.BackupResponse
+	(*SetLogLevelRequest)(nil),               // 198: treestore.SetLogLevelRequest
+	(*SetLogLevelResponse)(nil),              // 199: treestore.SetLogLevelResponse
+	(*TailLogsRequest)(nil),                  // 200: treestore.TailLogsRequest
+	(*LogEvent)(nil),                         // 201: treestore.LogEvent
+	(*DumpStateRequest)(nil),                 // 202: treestore.DumpStateRequest
+	(*DumpStateResponse)(nil),                // 203: treestore.DumpStateResponse
+	nil,                                      // 204: treestore.Document.MetadataEntry
+	nil,                                      // 205: treestore.PolicyVersion.MetadataEntry
+	nil,                                      // 206: treestore.PromptUsage.FilledVariablesEntry
+	nil,                                      // 207: treestore.VariantStats.OutcomesEntry
+	nil,                                      // 208: treestore.Message.MetadataEntry
+	nil,                                      // 209: treestore.Conversation.MetadataEntry
+	nil,                                      // 210: treestore.CloneDocumentResponse.NodeIdMapEntry
+	nil,                                      // 211: treestore.SearchFilter.MetadataEntry
+	nil,                                      // 212: treestore.RetrieveRequest.FilterEntry
+	nil,                                      // 213: treestore.RetrievedDocument.MetadataEntry
+	nil,                                      // 214: treestore.JoinNodesRequest.MetadataEntry
+	nil,                                      // 215: treestore.JoinedNode.MetadataEntry
+	nil,                                      // 216: treestore.BatchGetVersionsAsOfResponse.VersionsEntry
+	nil,                                      // 217: treestore.ReplayTrajectoryResponse.DocumentsEntry
+	nil,                                      // 218: treestore.BatchSetMetadataRequest.AttributesEntry
+	nil,                                      // 219: treestore.BatchSetMetadataRequest.ExpectedVersionsEntry
+	nil,                                      // 220: treestore.BatchSetMetadataResponse.VersionsEntry
+	nil,                                      // 221: treestore.Collection.MetadataEntry
+	nil,                                      // 222: treestore.QueryGroup.ValuesEntry
+	nil,                                      // 223: treestore.StatsResponse.OperationCountsEntry
+	nil,                                      // 224: treestore.DumpStateResponse.OperationCountsEntry
+	(*timestamppb.Timestamp)(nil),            // 225: google.protobuf.Timestamp
 }
 var file_proto_treestore_proto_depIdxs = []int32{
-	56, // 0: treestore.Document.metadata:type_name -> treestore.Document.MetadataEntry
-	59, // 1: treestore.Document.created_at:type_name -> google.protobuf.Timestamp
-	59, // 2: treestore.Document.updated_at:type_name -> google.protobuf.Timestamp
-	59, // 3: treestore.Node.created_at:type_name -> google.protobuf.Timestamp
-	59, // 4: treestore.Node.updated_at:type_name -> google.protobuf.Timestamp
-	59, // 5: treestore.PolicyVersion.created_at:type_name -> google.protobuf.Timestamp
-	59, // 6: treestore.ToolResult.executed_at:type_name -> google.protobuf.Timestamp
-	5,  // 7: treestore.Trajectory.steps:type_name -> treestore.TrajectoryStep
-	59, // 8: treestore.Trajectory.started_at:type_name -> google.protobuf.Timestamp
-	59, // 9: treestore.Trajectory.completed_at:type_name -> google.protobuf.Timestamp
-	59, // 10: treestore.TrajectoryStep.timestamp:type_name -> google.protobuf.Timestamp
-	59, // 11: treestore.CrossReference.created_at:type_name -> google.protobuf.Timestamp
-	59, // 12: treestore.Contradiction.detected_at:type_name -> google.protobuf.Timestamp
-	59, // 13: treestore.PromptTemplate.created_at:type_name -> google.protobuf.Timestamp
-	57, // 14: treestore.PromptUsage.filled_variables:type_name -> treestore.PromptUsage.FilledVariablesEntry
-	59, // 15: treestore.PromptUsage.used_at:type_name -> google.protobuf.Timestamp
-	0,  // 16: treestore.StoreDocumentRequest.document:type_name -> treestore.Document
-	1,  // 17: treestore.StoreDocumentRequest.nodes:type_name -> treestore.Node
-	0,  // 18: treestore.GetDocumentResponse.document:type_name -> treestore.Document
-	1,  // 19: treestore.GetDocumentResponse.nodes:type_name -> treestore.Node
-	1,  // 20: treestore.GetNodeResponse.node:type_name -> treestore.Node
-	1,  // 21: treestore.GetChildrenResponse.children:type_name -> treestore.Node
-	1,  // 22: treestore.GetSubtreeResponse.nodes:type_name -> treestore.Node
-	1,  // 23: treestore.GetAncestorPathResponse.ancestors:type_name -> treestore.Node
-	26, // 24: treestore.SearchResponse.results:type_name -> treestore.SearchResult
-	1,  // 25: treestore.SearchResult.node:type_name -> treestore.Node
-	1,  // 26: treestore.GetNodesByPageResponse.nodes:type_name -> treestore.Node
-	59, // 27: treestore.GetVersionAsOfRequest.as_of_time:type_name -> google.protobuf.Timestamp
-	2,  // 28: treestore.ListVersionsResponse.versions:type_name -> treestore.PolicyVersion
-	3,  // 29: treestore.StoreToolResultRequest.result:type_name -> treestore.ToolResult
-	3,  // 30: treestore.GetToolResultsResponse.results:type_name -> treestore.ToolResult
-	4,  // 31: treestore.StoreTrajectoryRequest.trajectory:type_name -> treestore.Trajectory
-	4,  // 32: treestore.GetTrajectoriesResponse.trajectories:type_name -> treestore.Trajectory
-	6,  // 33: treestore.StoreCrossReferenceRequest.cross_reference:type_name -> treestore.CrossReference
-	6,  // 34: treestore.GetCrossReferencesResponse.references:type_name -> treestore.CrossReference
-	7,  // 35: treestore.StoreContradictionRequest.contradiction:type_name -> treestore.Contradiction
-	8,  // 36: treestore.StorePromptRequest.prompt:type_name -> treestore.PromptTemplate
-	8,  // 37: treestore.GetPromptResponse.prompt:type_name -> treestore.PromptTemplate
-	9,  // 38: treestore.RecordPromptUsageRequest.usage:type_name -> treestore.PromptUsage
-	58, // 39: treestore.StatsResponse.operation_counts:type_name -> treestore.StatsResponse.OperationCountsEntry
-	10, // 40: treestore.TreeStoreService.StoreDocument:input_type -> treestore.StoreDocumentRequest
-	12, // 41: treestore.TreeStoreService.GetDocument:input_type -> treestore.GetDocumentRequest
-	14, // 42: treestore.TreeStoreService.DeleteDocument:input_type -> treestore.DeleteDocumentRequest
-	16, // 43: treestore.TreeStoreService.GetNode:input_type -> treestore.GetNodeRequest
-	18, // 44: treestore.TreeStoreService.GetChildren:input_type -> treestore.GetChildrenRequest
-	20, // 45: treestore.TreeStoreService.GetSubtree:input_type -> treestore.GetSubtreeRequest
-	22, // 46: treestore.TreeStoreService.GetAncestorPath:input_type -> treestore.GetAncestorPathRequest
-	24, // 47: treestore.TreeStoreService.SearchByKeyword:input_type -> treestore.SearchRequest
-	27, // 48: treestore.TreeStoreService.GetNodesByPage:input_type -> treestore.GetNodesByPageRequest
-	29, // 49: treestore.TreeStoreService.GetVersionAsOf:input_type -> treestore.GetVersionAsOfRequest
-	30, // 50: treestore.TreeStoreService.ListVersions:input_type -> treestore.ListVersionsRequest
-	32, // 51: treestore.TreeStoreService.StoreToolResult:input_type -> treestore.StoreToolResultRequest
-	34, // 52: treestore.TreeStoreService.GetToolResults:input_type -> treestore.GetToolResultsRequest
-	36, // 53: treestore.TreeStoreService.StoreTrajectory:input_type -> treestore.StoreTrajectoryRequest
-	38, // 54: treestore.TreeStoreService.GetTrajectories:input_type -> treestore.GetTrajectoriesRequest
-	40, // 55: treestore.TreeStoreService.StoreCrossReference:input_type -> treestore.StoreCrossReferenceRequest
-	42, // 56: treestore.TreeStoreService.GetCrossReferences:input_type -> treestore.GetCrossReferencesRequest
-	44, // 57: treestore.TreeStoreService.StoreContradiction:input_type -> treestore.StoreContradictionRequest
-	46, // 58: treestore.TreeStoreService.StorePrompt:input_type -> treestore.StorePromptRequest
-	48, // 59: treestore.TreeStoreService.GetPrompt:input_type -> treestore.GetPromptRequest
-	50, // 60: treestore.TreeStoreService.RecordPromptUsage:input_type -> treestore.RecordPromptUsageRequest
-	52, // 61: treestore.TreeStoreService.Health:input_type -> treestore.HealthRequest
-	54, // 62: treestore.TreeStoreService.Stats:input_type -> treestore.StatsRequest
-	11, // 63: treestore.TreeStoreService.StoreDocument:output_type -> treestore.StoreDocumentResponse
-	13, // 64: treestore.TreeStoreService.GetDocument:output_type -> treestore.GetDocumentResponse
-	15, // 65: treestore.TreeStoreService.DeleteDocument:output_type -> treestore.DeleteDocumentResponse
-	17, // 66: treestore.TreeStoreService.GetNode:output_type -> treestore.GetNodeResponse
-	19, // 67: treestore.TreeStoreService.GetChildren:output_type -> treestore.GetChildrenResponse
-	21, // 68: treestore.TreeStoreService.GetSubtree:output_type -> treestore.GetSubtreeResponse
-	23, // 69: treestore.TreeStoreService.GetAncestorPath:output_type -> treestore.GetAncestorPathResponse
-	25, // 70: treestore.TreeStoreService.SearchByKeyword:output_type -> treestore.SearchResponse
-	28, // 71: treestore.TreeStoreService.GetNodesByPage:output_type -> treestore.GetNodesByPageResponse
-	2,  // 72: treestore.TreeStoreService.GetVersionAsOf:output_type -> treestore.PolicyVersion
-	31, // 73: treestore.TreeStoreService.ListVersions:output_type -> treestore.ListVersionsResponse
-	33, // 74: treestore.TreeStoreService.StoreToolResult:output_type -> treestore.StoreToolResultResponse
-	35, // 75: treestore.TreeStoreService.GetToolResults:output_type -> treestore.GetToolResultsResponse
-	37, // 76: treestore.TreeStoreService.StoreTrajectory:output_type -> treestore.StoreTrajectoryResponse
-	39, // 77: treestore.TreeStoreService.GetTrajectories:output_type -> treestore.GetTrajectoriesResponse
-	41, // 78: treestore.TreeStoreService.StoreCrossReference:output_type -> treestore.StoreCrossReferenceResponse
-	43, // 79: treestore.TreeStoreService.GetCrossReferences:output_type -> treestore.GetCrossReferencesResponse
-	45, // 80: treestore.TreeStoreService.StoreContradiction:output_type -> treestore.StoreContradictionResponse
-	47, // 81: treestore.TreeStoreService.StorePrompt:output_type -> treestore.StorePromptResponse
-	49, // 82: treestore.TreeStoreService.GetPrompt:output_type -> treestore.GetPromptResponse
-	51, // 83: treestore.TreeStoreService.RecordPromptUsage:output_type -> treestore.RecordPromptUsageResponse
-	53, // 84: treestore.TreeStoreService.Health:output_type -> treestore.HealthResponse
-	55, // 85: treestore.TreeStoreService.Stats:output_type -> treestore.StatsResponse
-	63, // [63:86] is the sub-list for method output_type
-	40, // [40:63] is the sub-list for method input_type
-	40, // [40:40] is the sub-list for extension type_name
-	40, // [40:40] is the sub-list for extension extendee
-	0,  // [0:40] is the sub-list for field type_name
+	204, // 0: treestore.Document.metadata:type_name -> treestore.Document.MetadataEntry
+	225, // 1: treestore.Document.created_at:type_name -> google.protobuf.Timestamp
+	225, // 2: treestore.Document.updated_at:type_name -> google.protobuf.Timestamp
+	225, // 3: treestore.Node.created_at:type_name -> google.protobuf.Timestamp
+	225, // 4: treestore.Node.updated_at:type_name -> google.protobuf.Timestamp
+	225, // 5: treestore.PolicyVersion.created_at:type_name -> google.protobuf.Timestamp
+	225, // 6: treestore.PolicyVersion.effective_from:type_name -> google.protobuf.Timestamp
+	225, // 7: treestore.PolicyVersion.effective_to:type_name -> google.protobuf.Timestamp
+	205, // 8: treestore.PolicyVersion.metadata:type_name -> treestore.PolicyVersion.MetadataEntry
+	225, // 9: treestore.ToolResult.executed_at:type_name -> google.protobuf.Timestamp
+	5,   // 10: treestore.Trajectory.steps:type_name -> treestore.TrajectoryStep
+	225, // 11: treestore.Trajectory.started_at:type_name -> google.protobuf.Timestamp
+	225, // 12: treestore.Trajectory.completed_at:type_name -> google.protobuf.Timestamp
+	225, // 13: treestore.TrajectoryStep.timestamp:type_name -> google.protobuf.Timestamp
+	225, // 14: treestore.CrossReference.created_at:type_name -> google.protobuf.Timestamp
+	225, // 15: treestore.Contradiction.detected_at:type_name -> google.protobuf.Timestamp
+	225, // 16: treestore.PromptTemplate.created_at:type_name -> google.protobuf.Timestamp
+	206, // 17: treestore.PromptUsage.filled_variables:type_name -> treestore.PromptUsage.FilledVariablesEntry
+	225, // 18: treestore.PromptUsage.used_at:type_name -> google.protobuf.Timestamp
+	11,  // 19: treestore.PromptExperiment.variants:type_name -> treestore.PromptVariant
+	225, // 20: treestore.PromptExperiment.created_at:type_name -> google.protobuf.Timestamp
+	207, // 21: treestore.VariantStats.outcomes:type_name -> treestore.VariantStats.OutcomesEntry
+	225, // 22: treestore.Message.timestamp:type_name -> google.protobuf.Timestamp
+	208, // 23: treestore.Message.metadata:type_name -> treestore.Message.MetadataEntry
+	225, // 24: treestore.Message.edited_at:type_name -> google.protobuf.Timestamp
+	225, // 25: treestore.Conversation.started_at:type_name -> google.protobuf.Timestamp
+	225, // 26: treestore.Conversation.last_message_at:type_name -> google.protobuf.Timestamp
+	209, // 27: treestore.Conversation.metadata:type_name -> treestore.Conversation.MetadataEntry
+	0,   // 28: treestore.StoreDocumentRequest.document:type_name -> treestore.Document
+	1,   // 29: treestore.StoreDocumentRequest.nodes:type_name -> treestore.Node
+	0,   // 30: treestore.GetDocumentResponse.document:type_name -> treestore.Document
+	1,   // 31: treestore.GetDocumentResponse.nodes:type_name -> treestore.Node
+	210, // 32: treestore.CloneDocumentResponse.node_id_map:type_name -> treestore.CloneDocumentResponse.NodeIdMapEntry
+	25,  // 33: treestore.RecomputeSectionPathsResponse.changes:type_name -> treestore.SectionPathChange
+	28,  // 34: treestore.ValidateDocumentResponse.issues:type_name -> treestore.DocumentIssue
+	31,  // 35: treestore.DuplicateGroup.nodes:type_name -> treestore.NodeRef
+	32,  // 36: treestore.FindDuplicateNodesResponse.groups:type_name -> treestore.DuplicateGroup
+	1,   // 37: treestore.SummarizeRequest.nodes:type_name -> treestore.Node
+	1,   // 38: treestore.GetNodeResponse.node:type_name -> treestore.Node
+	1,   // 39: treestore.UpdateNodeRequest.node:type_name -> treestore.Node
+	1,   // 40: treestore.UpdateNodeResponse.node:type_name -> treestore.Node
+	1,   // 41: treestore.GetChildrenResponse.children:type_name -> treestore.Node
+	1,   // 42: treestore.GetSubtreeResponse.nodes:type_name -> treestore.Node
+	1,   // 43: treestore.GetSubtreeWithinBudgetResponse.nodes:type_name -> treestore.Node
+	1,   // 44: treestore.GetAncestorPathResponse.ancestors:type_name -> treestore.Node
+	1,   // 45: treestore.AncestorPath.ancestors:type_name -> treestore.Node
+	51,  // 46: treestore.BatchGetAncestorPathsResponse.paths:type_name -> treestore.AncestorPath
+	1,   // 47: treestore.GetSiblingsResponse.siblings:type_name -> treestore.Node
+	1,   // 48: treestore.AdjacentSectionResponse.node:type_name -> treestore.Node
+	1,   // 49: treestore.GetContextWindowResponse.node:type_name -> treestore.Node
+	59,  // 50: treestore.GetContextWindowResponse.ancestors:type_name -> treestore.ContextEntry
+	59,  // 51: treestore.GetContextWindowResponse.siblings:type_name -> treestore.ContextEntry
+	59,  // 52: treestore.GetContextWindowResponse.children:type_name -> treestore.ContextEntry
+	64,  // 53: treestore.SearchRequest.filter:type_name -> treestore.SearchFilter
+	211, // 54: treestore.SearchFilter.metadata:type_name -> treestore.SearchFilter.MetadataEntry
+	66,  // 55: treestore.SearchResponse.results:type_name -> treestore.SearchResult
+	1,   // 56: treestore.SearchResult.node:type_name -> treestore.Node
+	67,  // 57: treestore.SearchResult.highlights:type_name -> treestore.Highlight
+	212, // 58: treestore.RetrieveRequest.filter:type_name -> treestore.RetrieveRequest.FilterEntry
+	70,  // 59: treestore.RetrieveResponse.documents:type_name -> treestore.RetrievedDocument
+	213, // 60: treestore.RetrievedDocument.metadata:type_name -> treestore.RetrievedDocument.MetadataEntry
+	73,  // 61: treestore.GlobalSearchResponse.policies:type_name -> treestore.PolicySearchResults
+	66,  // 62: treestore.PolicySearchResults.results:type_name -> treestore.SearchResult
+	214, // 63: treestore.JoinNodesRequest.metadata:type_name -> treestore.JoinNodesRequest.MetadataEntry
+	76,  // 64: treestore.JoinNodesResponse.results:type_name -> treestore.JoinedNode
+	1,   // 65: treestore.JoinedNode.node:type_name -> treestore.Node
+	215, // 66: treestore.JoinedNode.metadata:type_name -> treestore.JoinedNode.MetadataEntry
+	6,   // 67: treestore.JoinedNode.references:type_name -> treestore.CrossReference
+	1,   // 68: treestore.GetNodesByPageResponse.nodes:type_name -> treestore.Node
+	225, // 69: treestore.GetVersionAsOfRequest.as_of_time:type_name -> google.protobuf.Timestamp
+	225, // 70: treestore.BatchGetVersionsAsOfRequest.as_of_time:type_name -> google.protobuf.Timestamp
+	216, // 71: treestore.BatchGetVersionsAsOfResponse.versions:type_name -> treestore.BatchGetVersionsAsOfResponse.VersionsEntry
+	2,   // 72: treestore.ListVersionsResponse.versions:type_name -> treestore.PolicyVersion
+	225, // 73: treestore.PruneVersionsRequest.older_than:type_name -> google.protobuf.Timestamp
+	3,   // 74: treestore.StoreToolResultRequest.result:type_name -> treestore.ToolResult
+	3,   // 75: treestore.GetToolResultsResponse.results:type_name -> treestore.ToolResult
+	4,   // 76: treestore.StoreTrajectoryRequest.trajectory:type_name -> treestore.Trajectory
+	4,   // 77: treestore.GetTrajectoriesResponse.trajectories:type_name -> treestore.Trajectory
+	225, // 78: treestore.ReplayTrajectoryRequest.as_of_time:type_name -> google.protobuf.Timestamp
+	102, // 79: treestore.ReplayTrajectoryResponse.steps:type_name -> treestore.StepReplay
+	217, // 80: treestore.ReplayTrajectoryResponse.documents:type_name -> treestore.ReplayTrajectoryResponse.DocumentsEntry
+	6,   // 81: treestore.StoreCrossReferenceRequest.cross_reference:type_name -> treestore.CrossReference
+	6,   // 82: treestore.GetCrossReferencesResponse.references:type_name -> treestore.CrossReference
+	7,   // 83: treestore.StoreContradictionRequest.contradiction:type_name -> treestore.Contradiction
+	218, // 84: treestore.BatchSetMetadataRequest.attributes:type_name -> treestore.BatchSetMetadataRequest.AttributesEntry
+	219, // 85: treestore.BatchSetMetadataRequest.expected_versions:type_name -> treestore.BatchSetMetadataRequest.ExpectedVersionsEntry
+	220, // 86: treestore.BatchSetMetadataResponse.versions:type_name -> treestore.BatchSetMetadataResponse.VersionsEntry
+	221, // 87: treestore.Collection.metadata:type_name -> treestore.Collection.MetadataEntry
+	225, // 88: treestore.Collection.created_at:type_name -> google.protobuf.Timestamp
+	225, // 89: treestore.Collection.updated_at:type_name -> google.protobuf.Timestamp
+	111, // 90: treestore.PutCollectionRequest.collection:type_name -> treestore.Collection
+	111, // 91: treestore.PutCollectionResponse.collection:type_name -> treestore.Collection
+	111, // 92: treestore.GetCollectionResponse.collection:type_name -> treestore.Collection
+	111, // 93: treestore.ListCollectionsResponse.collections:type_name -> treestore.Collection
+	111, // 94: treestore.UpdateCollectionMembersResponse.collection:type_name -> treestore.Collection
+	8,   // 95: treestore.StorePromptRequest.prompt:type_name -> treestore.PromptTemplate
+	8,   // 96: treestore.GetPromptResponse.prompt:type_name -> treestore.PromptTemplate
+	9,   // 97: treestore.RecordPromptUsageRequest.usage:type_name -> treestore.PromptUsage
+	10,  // 98: treestore.StorePromptExperimentRequest.experiment:type_name -> treestore.PromptExperiment
+	10,  // 99: treestore.GetPromptExperimentResponse.experiment:type_name -> treestore.PromptExperiment
+	11,  // 100: treestore.AssignPromptVariantResponse.variant:type_name -> treestore.PromptVariant
+	225, // 101: treestore.ComparePromptVariantsRequest.since:type_name -> google.protobuf.Timestamp
+	225, // 102: treestore.ComparePromptVariantsRequest.until:type_name -> google.protobuf.Timestamp
+	12,  // 103: treestore.ComparePromptVariantsResponse.variants:type_name -> treestore.VariantStats
+	225, // 104: treestore.GetMessagesPageRequest.after_timestamp:type_name -> google.protobuf.Timestamp
+	13,  // 105: treestore.GetMessagesPageResponse.messages:type_name -> treestore.Message
+	13,  // 106: treestore.GetRecentMessagesResponse.messages:type_name -> treestore.Message
+	14,  // 107: treestore.ConversationSearchResult.conversation:type_name -> treestore.Conversation
+	13,  // 108: treestore.ConversationSearchResult.matches:type_name -> treestore.Message
+	143, // 109: treestore.SearchConversationsResponse.results:type_name -> treestore.ConversationSearchResult
+	14,  // 110: treestore.ListConversationsByUserResponse.conversations:type_name -> treestore.Conversation
+	15,  // 111: treestore.GetUserConversationUsageResponse.quota:type_name -> treestore.ConversationQuota
+	15,  // 112: treestore.SetUserConversationQuotaRequest.quota:type_name -> treestore.ConversationQuota
+	225, // 113: treestore.MetadataEntry.created_at:type_name -> google.protobuf.Timestamp
+	225, // 114: treestore.MetadataEntry.updated_at:type_name -> google.protobuf.Timestamp
+	1,   // 115: treestore.QueryRow.node:type_name -> treestore.Node
+	2,   // 116: treestore.QueryRow.version:type_name -> treestore.PolicyVersion
+	154, // 117: treestore.QueryRow.metadata:type_name -> treestore.MetadataEntry
+	14,  // 118: treestore.QueryRow.conversation:type_name -> treestore.Conversation
+	156, // 119: treestore.QueryRow.group:type_name -> treestore.QueryGroup
+	222, // 120: treestore.QueryGroup.values:type_name -> treestore.QueryGroup.ValuesEntry
+	225, // 121: treestore.SavedQuery.refreshed_at:type_name -> google.protobuf.Timestamp
+	157, // 122: treestore.SaveQueryResponse.saved:type_name -> treestore.SavedQuery
+	157, // 123: treestore.ExecuteSavedQueryResponse.saved:type_name -> treestore.SavedQuery
+	155, // 124: treestore.ExecuteSavedQueryResponse.rows:type_name -> treestore.QueryRow
+	157, // 125: treestore.RefreshSavedQueryResponse.saved:type_name -> treestore.SavedQuery
+	157, // 126: treestore.ListSavedQueriesResponse.saved:type_name -> treestore.SavedQuery
+	225, // 127: treestore.ChangeEvent.timestamp:type_name -> google.protobuf.Timestamp
+	225, // 128: treestore.WALEntry.timestamp:type_name -> google.protobuf.Timestamp
+	225, // 129: treestore.QueryAuditLogRequest.start_time:type_name -> google.protobuf.Timestamp
+	225, // 130: treestore.QueryAuditLogRequest.end_time:type_name -> google.protobuf.Timestamp
+	225, // 131: treestore.AuditRecord.time:type_name -> google.protobuf.Timestamp
+	173, // 132: treestore.QueryAuditLogResponse.records:type_name -> treestore.AuditRecord
+	225, // 133: treestore.JobRun.queued_at:type_name -> google.protobuf.Timestamp
+	225, // 134: treestore.JobRun.started_at:type_name -> google.protobuf.Timestamp
+	225, // 135: treestore.JobRun.finished_at:type_name -> google.protobuf.Timestamp
+	225, // 136: treestore.JobRun.next_attempt:type_name -> google.protobuf.Timestamp
+	175, // 137: treestore.ListJobRunsResponse.runs:type_name -> treestore.JobRun
+	175, // 138: treestore.TriggerJobResponse.run:type_name -> treestore.JobRun
+	223, // 139: treestore.StatsResponse.operation_counts:type_name -> treestore.StatsResponse.OperationCountsEntry
+	185, // 140: treestore.PolicyUsage.stores:type_name -> treestore.StoreUsage
+	185, // 141: treestore.StorageBreakdownResponse.stores:type_name -> treestore.StoreUsage
+	186, // 142: treestore.StorageBreakdownResponse.policies:type_name -> treestore.PolicyUsage
+	225, // 143: treestore.LogEvent.time:type_name -> google.protobuf.Timestamp
+	225, // 144: treestore.DumpStateResponse.last_flush:type_name -> google.protobuf.Timestamp
+	224, // 145: treestore.DumpStateResponse.operation_counts:type_name -> treestore.DumpStateResponse.OperationCountsEntry
+	225, // 146: treestore.DumpStateResponse.last_checkpoint:type_name -> google.protobuf.Timestamp
+	2,   // 147: treestore.BatchGetVersionsAsOfResponse.VersionsEntry.value:type_name -> treestore.PolicyVersion
+	16,  // 148: treestore.TreeStoreService.StoreDocument:input_type -> treestore.StoreDocumentRequest
+	18,  // 149: treestore.TreeStoreService.GetDocument:input_type -> treestore.GetDocumentRequest
+	20,  // 150: treestore.TreeStoreService.DeleteDocument:input_type -> treestore.DeleteDocumentRequest
+	22,  // 151: treestore.TreeStoreService.CloneDocument:input_type -> treestore.CloneDocumentRequest
+	24,  // 152: treestore.TreeStoreService.RecomputeSectionPaths:input_type -> treestore.RecomputeSectionPathsRequest
+	27,  // 153: treestore.TreeStoreService.ValidateDocument:input_type -> treestore.ValidateDocumentRequest
+	30,  // 154: treestore.TreeStoreService.FindDuplicateNodes:input_type -> treestore.FindDuplicateNodesRequest
+	34,  // 155: treestore.TreeStoreService.RefreshSummaries:input_type -> treestore.RefreshSummariesRequest
+	38,  // 156: treestore.TreeStoreService.GetNode:input_type -> treestore.GetNodeRequest
+	40,  // 157: treestore.TreeStoreService.UpdateNode:input_type -> treestore.UpdateNodeRequest
+	42,  // 158: treestore.TreeStoreService.GetChildren:input_type -> treestore.GetChildrenRequest
+	44,  // 159: treestore.TreeStoreService.GetSubtree:input_type -> treestore.GetSubtreeRequest
+	46,  // 160: treestore.TreeStoreService.GetSubtreeWithinBudget:input_type -> treestore.GetSubtreeWithinBudgetRequest
+	48,  // 161: treestore.TreeStoreService.GetAncestorPath:input_type -> treestore.GetAncestorPathRequest
+	50,  // 162: treestore.TreeStoreService.BatchGetAncestorPaths:input_type -> treestore.BatchGetAncestorPathsRequest
+	53,  // 163: treestore.TreeStoreService.GetSiblings:input_type -> treestore.GetSiblingsRequest
+	55,  // 164: treestore.TreeStoreService.GetNextSection:input_type -> treestore.AdjacentSectionRequest
+	55,  // 165: treestore.TreeStoreService.GetPreviousSection:input_type -> treestore.AdjacentSectionRequest
+	57,  // 166: treestore.TreeStoreService.GetReadingOrder:input_type -> treestore.GetReadingOrderRequest
+	58,  // 167: treestore.TreeStoreService.GetContextWindow:input_type -> treestore.GetContextWindowRequest
+	61,  // 168: treestore.TreeStoreService.ExportGraph:input_type -> treestore.ExportGraphRequest
+	63,  // 169: treestore.TreeStoreService.SearchByKeyword:input_type -> treestore.SearchRequest
+	77,  // 170: treestore.TreeStoreService.GetNodesByPage:input_type -> treestore.GetNodesByPageRequest
+	71,  // 171: treestore.TreeStoreService.GlobalSearch:input_type -> treestore.GlobalSearchRequest
+	74,  // 172: treestore.TreeStoreService.JoinNodes:input_type -> treestore.JoinNodesRequest
+	68,  // 173: treestore.TreeStoreService.Retrieve:input_type -> treestore.RetrieveRequest
+	79,  // 174: treestore.TreeStoreService.GetVersionAsOf:input_type -> treestore.GetVersionAsOfRequest
+	80,  // 175: treestore.TreeStoreService.BatchGetVersionsAsOf:input_type -> treestore.BatchGetVersionsAsOfRequest
+	82,  // 176: treestore.TreeStoreService.ListVersions:input_type -> treestore.ListVersionsRequest
+	84,  // 177: treestore.TreeStoreService.DeleteVersion:input_type -> treestore.DeleteVersionRequest
+	86,  // 178: treestore.TreeStoreService.PruneVersions:input_type -> treestore.PruneVersionsRequest
+	88,  // 179: treestore.TreeStoreService.TagVersion:input_type -> treestore.TagVersionRequest
+	90,  // 180: treestore.TreeStoreService.UntagVersion:input_type -> treestore.UntagVersionRequest
+	92,  // 181: treestore.TreeStoreService.StoreToolResult:input_type -> treestore.StoreToolResultRequest
+	94,  // 182: treestore.TreeStoreService.GetToolResults:input_type -> treestore.GetToolResultsRequest
+	96,  // 183: treestore.TreeStoreService.StoreTrajectory:input_type -> treestore.StoreTrajectoryRequest
+	98,  // 184: treestore.TreeStoreService.GetTrajectories:input_type -> treestore.GetTrajectoriesRequest
+	100, // 185: treestore.TreeStoreService.ReplayTrajectory:input_type -> treestore.ReplayTrajectoryRequest
+	103, // 186: treestore.TreeStoreService.StoreCrossReference:input_type -> treestore.StoreCrossReferenceRequest
+	105, // 187: treestore.TreeStoreService.GetCrossReferences:input_type -> treestore.GetCrossReferencesRequest
+	107, // 188: treestore.TreeStoreService.StoreContradiction:input_type -> treestore.StoreContradictionRequest
+	109, // 189: treestore.TreeStoreService.BatchSetMetadata:input_type -> treestore.BatchSetMetadataRequest
+	112, // 190: treestore.TreeStoreService.PutCollection:input_type -> treestore.PutCollectionRequest
+	114, // 191: treestore.TreeStoreService.GetCollection:input_type -> treestore.GetCollectionRequest
+	116, // 192: treestore.TreeStoreService.ListCollections:input_type -> treestore.ListCollectionsRequest
+	118, // 193: treestore.TreeStoreService.UpdateCollectionMembers:input_type -> treestore.UpdateCollectionMembersRequest
+	120, // 194: treestore.TreeStoreService.DeleteCollection:input_type -> treestore.DeleteCollectionRequest
+	122, // 195: treestore.TreeStoreService.StorePrompt:input_type -> treestore.StorePromptRequest
+	124, // 196: treestore.TreeStoreService.GetPrompt:input_type -> treestore.GetPromptRequest
+	126, // 197: treestore.TreeStoreService.RecordPromptUsage:input_type -> treestore.RecordPromptUsageRequest
+	128, // 198: treestore.TreeStoreService.StorePromptExperiment:input_type -> treestore.StorePromptExperimentRequest
+	130, // 199: treestore.TreeStoreService.GetPromptExperiment:input_type -> treestore.GetPromptExperimentRequest
+	132, // 200: treestore.TreeStoreService.AssignPromptVariant:input_type -> treestore.AssignPromptVariantRequest
+	134, // 201: treestore.TreeStoreService.LabelPromptUsage:input_type -> treestore.LabelPromptUsageRequest
+	136, // 202: treestore.TreeStoreService.ComparePromptVariants:input_type -> treestore.ComparePromptVariantsRequest
+	138, // 203: treestore.TreeStoreService.GetMessagesPage:input_type -> treestore.GetMessagesPageRequest
+	140, // 204: treestore.TreeStoreService.GetRecentMessages:input_type -> treestore.GetRecentMessagesRequest
+	142, // 205: treestore.TreeStoreService.SearchConversations:input_type -> treestore.SearchConversationsRequest
+	145, // 206: treestore.TreeStoreService.ExportConversation:input_type -> treestore.ExportConversationRequest
+	147, // 207: treestore.TreeStoreService.ListConversationsByUser:input_type -> treestore.ListConversationsByUserRequest
+	149, // 208: treestore.TreeStoreService.GetUserConversationUsage:input_type -> treestore.GetUserConversationUsageRequest
+	151, // 209: treestore.TreeStoreService.SetUserConversationQuota:input_type -> treestore.SetUserConversationQuotaRequest
+	153, // 210: treestore.TreeStoreService.StreamQuery:input_type -> treestore.StreamQueryRequest
+	158, // 211: treestore.TreeStoreService.SaveQuery:input_type -> treestore.SaveQueryRequest
+	160, // 212: treestore.TreeStoreService.ExecuteSavedQuery:input_type -> treestore.ExecuteSavedQueryRequest
+	162, // 213: treestore.TreeStoreService.RefreshSavedQuery:input_type -> treestore.RefreshSavedQueryRequest
+	164, // 214: treestore.TreeStoreService.ListSavedQueries:input_type -> treestore.ListSavedQueriesRequest
+	166, // 215: treestore.TreeStoreService.DeleteSavedQuery:input_type -> treestore.DeleteSavedQueryRequest
+	168, // 216: treestore.TreeStoreService.WatchChanges:input_type -> treestore.WatchChangesRequest
+	170, // 217: treestore.TreeStoreService.StreamWAL:input_type -> treestore.StreamWALRequest
+	172, // 218: treestore.TreeStoreService.QueryAuditLog:input_type -> treestore.QueryAuditLogRequest
+	176, // 219: treestore.TreeStoreService.ListJobRuns:input_type -> treestore.ListJobRunsRequest
+	178, // 220: treestore.TreeStoreService.TriggerJob:input_type -> treestore.TriggerJobRequest
+	180, // 221: treestore.TreeStoreService.Health:input_type -> treestore.HealthRequest
+	182, // 222: treestore.TreeStoreService.Stats:input_type -> treestore.StatsRequest
+	184, // 223: treestore.TreeStoreService.StorageBreakdown:input_type -> treestore.StorageBreakdownRequest
+	188, // 224: treestore.TreeStoreAdmin.Checkpoint:input_type -> treestore.CheckpointRequest
+	190, // 225: treestore.TreeStoreAdmin.Compact:input_type -> treestore.CompactRequest
+	192, // 226: treestore.TreeStoreAdmin.Reindex:input_type -> treestore.ReindexRequest
+	194, // 227: treestore.TreeStoreAdmin.Flush:input_type -> treestore.FlushRequest
+	196, // 228: treestore.TreeStoreAdmin.Backup:input_type -> treestore.BackupRequest
+	198, // 229: treestore.TreeStoreAdmin.SetLogLevel:input_type -> treestore.SetLogLevelRequest
+	202, // 230: treestore.TreeStoreAdmin.DumpState:input_type -> treestore.DumpStateRequest
+	200, // 231: treestore.TreeStoreAdmin.TailLogs:input_type -> treestore.TailLogsRequest
+	36,  // 232: treestore.Summarizer.Summarize:input_type -> treestore.SummarizeRequest
+	17,  // 233: treestore.TreeStoreService.StoreDocument:output_type -> treestore.StoreDocumentResponse
+	19,  // 234: treestore.TreeStoreService.GetDocument:output_type -> treestore.GetDocumentResponse
+	21,  // 235: treestore.TreeStoreService.DeleteDocument:output_type -> treestore.DeleteDocumentResponse
+	23,  // 236: treestore.TreeStoreService.CloneDocument:output_type -> treestore.CloneDocumentResponse
+	26,  // 237: treestore.TreeStoreService.RecomputeSectionPaths:output_type -> treestore.RecomputeSectionPathsResponse
+	29,  // 238: treestore.TreeStoreService.ValidateDocument:output_type -> treestore.ValidateDocumentResponse
+	33,  // 239: treestore.TreeStoreService.FindDuplicateNodes:output_type -> treestore.FindDuplicateNodesResponse
+	35,  // 240: treestore.TreeStoreService.RefreshSummaries:output_type -> treestore.RefreshSummariesProgress
+	39,  // 241: treestore.TreeStoreService.GetNode:output_type -> treestore.GetNodeResponse
+	41,  // 242: treestore.TreeStoreService.UpdateNode:output_type -> treestore.UpdateNodeResponse
+	43,  // 243: treestore.TreeStoreService.GetChildren:output_type -> treestore.GetChildrenResponse
+	45,  // 244: treestore.TreeStoreService.GetSubtree:output_type -> treestore.GetSubtreeResponse
+	47,  // 245: treestore.TreeStoreService.GetSubtreeWithinBudget:output_type -> treestore.GetSubtreeWithinBudgetResponse
+	49,  // 246: treestore.TreeStoreService.GetAncestorPath:output_type -> treestore.GetAncestorPathResponse
+	52,  // 247: treestore.TreeStoreService.BatchGetAncestorPaths:output_type -> treestore.BatchGetAncestorPathsResponse
+	54,  // 248: treestore.TreeStoreService.GetSiblings:output_type -> treestore.GetSiblingsResponse
+	56,  // 249: treestore.TreeStoreService.GetNextSection:output_type -> treestore.AdjacentSectionResponse
+	56,  // 250: treestore.TreeStoreService.GetPreviousSection:output_type -> treestore.AdjacentSectionResponse
+	1,   // 251: treestore.TreeStoreService.GetReadingOrder:output_type -> treestore.Node
+	60,  // 252: treestore.TreeStoreService.GetContextWindow:output_type -> treestore.GetContextWindowResponse
+	62,  // 253: treestore.TreeStoreService.ExportGraph:output_type -> treestore.ExportGraphResponse
+	65,  // 254: treestore.TreeStoreService.SearchByKeyword:output_type -> treestore.SearchResponse
+	78,  // 255: treestore.TreeStoreService.GetNodesByPage:output_type -> treestore.GetNodesByPageResponse
+	72,  // 256: treestore.TreeStoreService.GlobalSearch:output_type -> treestore.GlobalSearchResponse
+	75,  // 257: treestore.TreeStoreService.JoinNodes:output_type -> treestore.JoinNodesResponse
+	69,  // 258: treestore.TreeStoreService.Retrieve:output_type -> treestore.RetrieveResponse
+	2,   // 259: treestore.TreeStoreService.GetVersionAsOf:output_type -> treestore.PolicyVersion
+	81,  // 260: treestore.TreeStoreService.BatchGetVersionsAsOf:output_type -> treestore.BatchGetVersionsAsOfResponse
+	83,  // 261: treestore.TreeStoreService.ListVersions:output_type -> treestore.ListVersionsResponse
+	85,  // 262: treestore.TreeStoreService.DeleteVersion:output_type -> treestore.DeleteVersionResponse
+	87,  // 263: treestore.TreeStoreService.PruneVersions:output_type -> treestore.PruneVersionsResponse
+	89,  // 264: treestore.TreeStoreService.TagVersion:output_type -> treestore.TagVersionResponse
+	91,  // 265: treestore.TreeStoreService.UntagVersion:output_type -> treestore.UntagVersionResponse
+	93,  // 266: treestore.TreeStoreService.StoreToolResult:output_type -> treestore.StoreToolResultResponse
+	95,  // 267: treestore.TreeStoreService.GetToolResults:output_type -> treestore.GetToolResultsResponse
+	97,  // 268: treestore.TreeStoreService.StoreTrajectory:output_type -> treestore.StoreTrajectoryResponse
+	99,  // 269: treestore.TreeStoreService.GetTrajectories:output_type -> treestore.GetTrajectoriesResponse
+	101, // 270: treestore.TreeStoreService.ReplayTrajectory:output_type -> treestore.ReplayTrajectoryResponse
+	104, // 271: treestore.TreeStoreService.StoreCrossReference:output_type -> treestore.StoreCrossReferenceResponse
+	106, // 272: treestore.TreeStoreService.GetCrossReferences:output_type -> treestore.GetCrossReferencesResponse
+	108, // 273: treestore.TreeStoreService.StoreContradiction:output_type -> treestore.StoreContradictionResponse
+	110, // 274: treestore.TreeStoreService.BatchSetMetadata:output_type -> treestore.BatchSetMetadataResponse
+	113, // 275: treestore.TreeStoreService.PutCollection:output_type -> treestore.PutCollectionResponse
+	115, // 276: treestore.TreeStoreService.GetCollection:output_type -> treestore.GetCollectionResponse
+	117, // 277: treestore.TreeStoreService.ListCollections:output_type -> treestore.ListCollectionsResponse
+	119, // 278: treestore.TreeStoreService.UpdateCollectionMembers:output_type -> treestore.UpdateCollectionMembersResponse
+	121, // 279: treestore.TreeStoreService.DeleteCollection:output_type -> treestore.DeleteCollectionResponse
+	123, // 280: treestore.TreeStoreService.StorePrompt:output_type -> treestore.StorePromptResponse
+	125, // 281: treestore.TreeStoreService.GetPrompt:output_type -> treestore.GetPromptResponse
+	127, // 282: treestore.TreeStoreService.RecordPromptUsage:output_type -> treestore.RecordPromptUsageResponse
+	129, // 283: treestore.TreeStoreService.StorePromptExperiment:output_type -> treestore.StorePromptExperimentResponse
+	131, // 284: treestore.TreeStoreService.GetPromptExperiment:output_type -> treestore.GetPromptExperimentResponse
+	133, // 285: treestore.TreeStoreService.AssignPromptVariant:output_type -> treestore.AssignPromptVariantResponse
+	135, // 286: treestore.TreeStoreService.LabelPromptUsage:output_type -> treestore.LabelPromptUsageResponse
+	137, // 287: treestore.TreeStoreService.ComparePromptVariants:output_type -> treestore.ComparePromptVariantsResponse
+	139, // 288: treestore.TreeStoreService.GetMessagesPage:output_type -> treestore.GetMessagesPageResponse
+	141, // 289: treestore.TreeStoreService.GetRecentMessages:output_type -> treestore.GetRecentMessagesResponse
+	144, // 290: treestore.TreeStoreService.SearchConversations:output_type -> treestore.SearchConversationsResponse
+	146, // 291: treestore.TreeStoreService.ExportConversation:output_type -> treestore.ExportConversationResponse
+	148, // 292: treestore.TreeStoreService.ListConversationsByUser:output_type -> treestore.ListConversationsByUserResponse
+	150, // 293: treestore.TreeStoreService.GetUserConversationUsage:output_type -> treestore.GetUserConversationUsageResponse
+	152, // 294: treestore.TreeStoreService.SetUserConversationQuota:output_type -> treestore.SetUserConversationQuotaResponse
+	155, // 295: treestore.TreeStoreService.StreamQuery:output_type -> treestore.QueryRow
+	159, // 296: treestore.TreeStoreService.SaveQuery:output_type -> treestore.SaveQueryResponse
+	161, // 297: treestore.TreeStoreService.ExecuteSavedQuery:output_type -> treestore.ExecuteSavedQueryResponse
+	163, // 298: treestore.TreeStoreService.RefreshSavedQuery:output_type -> treestore.RefreshSavedQueryResponse
+	165, // 299: treestore.TreeStoreService.ListSavedQueries:output_type -> treestore.ListSavedQueriesResponse
+	167, // 300: treestore.TreeStoreService.DeleteSavedQuery:output_type -> treestore.DeleteSavedQueryResponse
+	169, // 301: treestore.TreeStoreService.WatchChanges:output_type -> treestore.ChangeEvent
+	171, // 302: treestore.TreeStoreService.StreamWAL:output_type -> treestore.WALEntry
+	174, // 303: treestore.TreeStoreService.QueryAuditLog:output_type -> treestore.QueryAuditLogResponse
+	177, // 304: treestore.TreeStoreService.ListJobRuns:output_type -> treestore.ListJobRunsResponse
+	179, // 305: treestore.TreeStoreService.TriggerJob:output_type -> treestore.TriggerJobResponse
+	181, // 306: treestore.TreeStoreService.Health:output_type -> treestore.HealthResponse
+	183, // 307: treestore.TreeStoreService.Stats:output_type -> treestore.StatsResponse
+	187, // 308: treestore.TreeStoreService.StorageBreakdown:output_type -> treestore.StorageBreakdownResponse
+	189, // 309: treestore.TreeStoreAdmin.Checkpoint:output_type -> treestore.CheckpointResponse
+	191, // 310: treestore.TreeStoreAdmin.Compact:output_type -> treestore.CompactResponse
+	193, // 311: treestore.TreeStoreAdmin.Reindex:output_type -> treestore.ReindexResponse
+	195, // 312: treestore.TreeStoreAdmin.Flush:output_type -> treestore.FlushResponse
+	197, // 313: treestore.TreeStoreAdmin.Backup:output_type -> treestore.BackupResponse
+	199, // 314: treestore.TreeStoreAdmin.SetLogLevel:output_type -> treestore.SetLogLevelResponse
+	203, // 315: treestore.TreeStoreAdmin.DumpState:output_type -> treestore.DumpStateResponse
+	201, // 316: treestore.TreeStoreAdmin.TailLogs:output_type -> treestore.LogEvent
+	37,  // 317: treestore.Summarizer.Summarize:output_type -> treestore.SummarizeResponse
+	233, // [233:318] is the sub-list for method output_type
+	148, // [148:233] is the sub-list for method input_type
+	148, // [148:148] is the sub-list for extension type_name
+	148, // [148:148] is the sub-list for extension extendee
+	0,   // [0:148] is the sub-list for field type_name
 }
 
 func init() { file_proto_treestore_proto_init() }
@@ -3659,15 +14165,23 @@ func file_proto_treestore_proto_init() {
 	if File_proto_treestore_proto != nil {
 		return
 	}
+	file_proto_treestore_proto_msgTypes[64].OneofWrappers = []any{}
+	file_proto_treestore_proto_msgTypes[155].OneofWrappers = []any{
+		(*QueryRow_Node)(nil),
+		(*QueryRow_Version)(nil),
+		(*QueryRow_Metadata)(nil),
+		(*QueryRow_Conversation)(nil),
+		(*QueryRow_Group)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_treestore_proto_rawDesc), len(file_proto_treestore_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   59,
+			NumMessages:   225,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   3,
 		},
 		GoTypes:           file_proto_treestore_proto_goTypes,
 		DependencyIndexes: file_proto_treestore_proto_depIdxs,
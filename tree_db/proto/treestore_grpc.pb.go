@@ -19,29 +19,82 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TreeStoreService_StoreDocument_FullMethodName       = "/treestore.TreeStoreService/StoreDocument"
-	TreeStoreService_GetDocument_FullMethodName         = "/treestore.TreeStoreService/GetDocument"
-	TreeStoreService_DeleteDocument_FullMethodName      = "/treestore.TreeStoreService/DeleteDocument"
-	TreeStoreService_GetNode_FullMethodName             = "/treestore.TreeStoreService/GetNode"
-	TreeStoreService_GetChildren_FullMethodName         = "/treestore.TreeStoreService/GetChildren"
-	TreeStoreService_GetSubtree_FullMethodName          = "/treestore.TreeStoreService/GetSubtree"
-	TreeStoreService_GetAncestorPath_FullMethodName     = "/treestore.TreeStoreService/GetAncestorPath"
-	TreeStoreService_SearchByKeyword_FullMethodName     = "/treestore.TreeStoreService/SearchByKeyword"
-	TreeStoreService_GetNodesByPage_FullMethodName      = "/treestore.TreeStoreService/GetNodesByPage"
-	TreeStoreService_GetVersionAsOf_FullMethodName      = "/treestore.TreeStoreService/GetVersionAsOf"
-	TreeStoreService_ListVersions_FullMethodName        = "/treestore.TreeStoreService/ListVersions"
-	TreeStoreService_StoreToolResult_FullMethodName     = "/treestore.TreeStoreService/StoreToolResult"
-	TreeStoreService_GetToolResults_FullMethodName      = "/treestore.TreeStoreService/GetToolResults"
-	TreeStoreService_StoreTrajectory_FullMethodName     = "/treestore.TreeStoreService/StoreTrajectory"
-	TreeStoreService_GetTrajectories_FullMethodName     = "/treestore.TreeStoreService/GetTrajectories"
-	TreeStoreService_StoreCrossReference_FullMethodName = "/treestore.TreeStoreService/StoreCrossReference"
-	TreeStoreService_GetCrossReferences_FullMethodName  = "/treestore.TreeStoreService/GetCrossReferences"
-	TreeStoreService_StoreContradiction_FullMethodName  = "/treestore.TreeStoreService/StoreContradiction"
-	TreeStoreService_StorePrompt_FullMethodName         = "/treestore.TreeStoreService/StorePrompt"
-	TreeStoreService_GetPrompt_FullMethodName           = "/treestore.TreeStoreService/GetPrompt"
-	TreeStoreService_RecordPromptUsage_FullMethodName   = "/treestore.TreeStoreService/RecordPromptUsage"
-	TreeStoreService_Health_FullMethodName              = "/treestore.TreeStoreService/Health"
-	TreeStoreService_Stats_FullMethodName               = "/treestore.TreeStoreService/Stats"
+	TreeStoreService_StoreDocument_FullMethodName            = "/treestore.TreeStoreService/StoreDocument"
+	TreeStoreService_GetDocument_FullMethodName              = "/treestore.TreeStoreService/GetDocument"
+	TreeStoreService_DeleteDocument_FullMethodName           = "/treestore.TreeStoreService/DeleteDocument"
+	TreeStoreService_CloneDocument_FullMethodName            = "/treestore.TreeStoreService/CloneDocument"
+	TreeStoreService_RecomputeSectionPaths_FullMethodName    = "/treestore.TreeStoreService/RecomputeSectionPaths"
+	TreeStoreService_ValidateDocument_FullMethodName         = "/treestore.TreeStoreService/ValidateDocument"
+	TreeStoreService_FindDuplicateNodes_FullMethodName       = "/treestore.TreeStoreService/FindDuplicateNodes"
+	TreeStoreService_RefreshSummaries_FullMethodName         = "/treestore.TreeStoreService/RefreshSummaries"
+	TreeStoreService_GetNode_FullMethodName                  = "/treestore.TreeStoreService/GetNode"
+	TreeStoreService_UpdateNode_FullMethodName               = "/treestore.TreeStoreService/UpdateNode"
+	TreeStoreService_GetChildren_FullMethodName              = "/treestore.TreeStoreService/GetChildren"
+	TreeStoreService_GetSubtree_FullMethodName               = "/treestore.TreeStoreService/GetSubtree"
+	TreeStoreService_GetSubtreeWithinBudget_FullMethodName   = "/treestore.TreeStoreService/GetSubtreeWithinBudget"
+	TreeStoreService_GetAncestorPath_FullMethodName          = "/treestore.TreeStoreService/GetAncestorPath"
+	TreeStoreService_BatchGetAncestorPaths_FullMethodName    = "/treestore.TreeStoreService/BatchGetAncestorPaths"
+	TreeStoreService_GetSiblings_FullMethodName              = "/treestore.TreeStoreService/GetSiblings"
+	TreeStoreService_GetNextSection_FullMethodName           = "/treestore.TreeStoreService/GetNextSection"
+	TreeStoreService_GetPreviousSection_FullMethodName       = "/treestore.TreeStoreService/GetPreviousSection"
+	TreeStoreService_GetReadingOrder_FullMethodName          = "/treestore.TreeStoreService/GetReadingOrder"
+	TreeStoreService_GetContextWindow_FullMethodName         = "/treestore.TreeStoreService/GetContextWindow"
+	TreeStoreService_ExportGraph_FullMethodName              = "/treestore.TreeStoreService/ExportGraph"
+	TreeStoreService_SearchByKeyword_FullMethodName          = "/treestore.TreeStoreService/SearchByKeyword"
+	TreeStoreService_GetNodesByPage_FullMethodName           = "/treestore.TreeStoreService/GetNodesByPage"
+	TreeStoreService_GlobalSearch_FullMethodName             = "/treestore.TreeStoreService/GlobalSearch"
+	TreeStoreService_JoinNodes_FullMethodName                = "/treestore.TreeStoreService/JoinNodes"
+	TreeStoreService_Retrieve_FullMethodName                 = "/treestore.TreeStoreService/Retrieve"
+	TreeStoreService_GetVersionAsOf_FullMethodName           = "/treestore.TreeStoreService/GetVersionAsOf"
+	TreeStoreService_BatchGetVersionsAsOf_FullMethodName     = "/treestore.TreeStoreService/BatchGetVersionsAsOf"
+	TreeStoreService_ListVersions_FullMethodName             = "/treestore.TreeStoreService/ListVersions"
+	TreeStoreService_DeleteVersion_FullMethodName            = "/treestore.TreeStoreService/DeleteVersion"
+	TreeStoreService_PruneVersions_FullMethodName            = "/treestore.TreeStoreService/PruneVersions"
+	TreeStoreService_TagVersion_FullMethodName               = "/treestore.TreeStoreService/TagVersion"
+	TreeStoreService_UntagVersion_FullMethodName             = "/treestore.TreeStoreService/UntagVersion"
+	TreeStoreService_StoreToolResult_FullMethodName          = "/treestore.TreeStoreService/StoreToolResult"
+	TreeStoreService_GetToolResults_FullMethodName           = "/treestore.TreeStoreService/GetToolResults"
+	TreeStoreService_StoreTrajectory_FullMethodName          = "/treestore.TreeStoreService/StoreTrajectory"
+	TreeStoreService_GetTrajectories_FullMethodName          = "/treestore.TreeStoreService/GetTrajectories"
+	TreeStoreService_ReplayTrajectory_FullMethodName         = "/treestore.TreeStoreService/ReplayTrajectory"
+	TreeStoreService_StoreCrossReference_FullMethodName      = "/treestore.TreeStoreService/StoreCrossReference"
+	TreeStoreService_GetCrossReferences_FullMethodName       = "/treestore.TreeStoreService/GetCrossReferences"
+	TreeStoreService_StoreContradiction_FullMethodName       = "/treestore.TreeStoreService/StoreContradiction"
+	TreeStoreService_BatchSetMetadata_FullMethodName         = "/treestore.TreeStoreService/BatchSetMetadata"
+	TreeStoreService_PutCollection_FullMethodName            = "/treestore.TreeStoreService/PutCollection"
+	TreeStoreService_GetCollection_FullMethodName            = "/treestore.TreeStoreService/GetCollection"
+	TreeStoreService_ListCollections_FullMethodName          = "/treestore.TreeStoreService/ListCollections"
+	TreeStoreService_UpdateCollectionMembers_FullMethodName  = "/treestore.TreeStoreService/UpdateCollectionMembers"
+	TreeStoreService_DeleteCollection_FullMethodName         = "/treestore.TreeStoreService/DeleteCollection"
+	TreeStoreService_StorePrompt_FullMethodName              = "/treestore.TreeStoreService/StorePrompt"
+	TreeStoreService_GetPrompt_FullMethodName                = "/treestore.TreeStoreService/GetPrompt"
+	TreeStoreService_RecordPromptUsage_FullMethodName        = "/treestore.TreeStoreService/RecordPromptUsage"
+	TreeStoreService_StorePromptExperiment_FullMethodName    = "/treestore.TreeStoreService/StorePromptExperiment"
+	TreeStoreService_GetPromptExperiment_FullMethodName      = "/treestore.TreeStoreService/GetPromptExperiment"
+	TreeStoreService_AssignPromptVariant_FullMethodName      = "/treestore.TreeStoreService/AssignPromptVariant"
+	TreeStoreService_LabelPromptUsage_FullMethodName         = "/treestore.TreeStoreService/LabelPromptUsage"
+	TreeStoreService_ComparePromptVariants_FullMethodName    = "/treestore.TreeStoreService/ComparePromptVariants"
+	TreeStoreService_GetMessagesPage_FullMethodName          = "/treestore.TreeStoreService/GetMessagesPage"
+	TreeStoreService_GetRecentMessages_FullMethodName        = "/treestore.TreeStoreService/GetRecentMessages"
+	TreeStoreService_SearchConversations_FullMethodName      = "/treestore.TreeStoreService/SearchConversations"
+	TreeStoreService_ExportConversation_FullMethodName       = "/treestore.TreeStoreService/ExportConversation"
+	TreeStoreService_ListConversationsByUser_FullMethodName  = "/treestore.TreeStoreService/ListConversationsByUser"
+	TreeStoreService_GetUserConversationUsage_FullMethodName = "/treestore.TreeStoreService/GetUserConversationUsage"
+	TreeStoreService_SetUserConversationQuota_FullMethodName = "/treestore.TreeStoreService/SetUserConversationQuota"
+	TreeStoreService_StreamQuery_FullMethodName              = "/treestore.TreeStoreService/StreamQuery"
+	TreeStoreService_SaveQuery_FullMethodName                = "/treestore.TreeStoreService/SaveQuery"
+	TreeStoreService_ExecuteSavedQuery_FullMethodName        = "/treestore.TreeStoreService/ExecuteSavedQuery"
+	TreeStoreService_RefreshSavedQuery_FullMethodName        = "/treestore.TreeStoreService/RefreshSavedQuery"
+	TreeStoreService_ListSavedQueries_FullMethodName         = "/treestore.TreeStoreService/ListSavedQueries"
+	TreeStoreService_DeleteSavedQuery_FullMethodName         = "/treestore.TreeStoreService/DeleteSavedQuery"
+	TreeStoreService_WatchChanges_FullMethodName             = "/treestore.TreeStoreService/WatchChanges"
+	TreeStoreService_StreamWAL_FullMethodName                = "/treestore.TreeStoreService/StreamWAL"
+	TreeStoreService_QueryAuditLog_FullMethodName            = "/treestore.TreeStoreService/QueryAuditLog"
+	TreeStoreService_ListJobRuns_FullMethodName              = "/treestore.TreeStoreService/ListJobRuns"
+	TreeStoreService_TriggerJob_FullMethodName               = "/treestore.TreeStoreService/TriggerJob"
+	TreeStoreService_Health_FullMethodName                   = "/treestore.TreeStoreService/Health"
+	TreeStoreService_Stats_FullMethodName                    = "/treestore.TreeStoreService/Stats"
+	TreeStoreService_StorageBreakdown_FullMethodName         = "/treestore.TreeStoreService/StorageBreakdown"
 )
 
 // TreeStoreServiceClient is the client API for TreeStoreService service.
@@ -50,36 +103,96 @@ const (
 //
 // TreeStoreService provides hierarchical document storage with versioning
 type TreeStoreServiceClient interface {
-	// ========== Document Operations (3 methods) ==========
+	// ========== Document Operations (8 methods) ==========
 	StoreDocument(ctx context.Context, in *StoreDocumentRequest, opts ...grpc.CallOption) (*StoreDocumentResponse, error)
 	GetDocument(ctx context.Context, in *GetDocumentRequest, opts ...grpc.CallOption) (*GetDocumentResponse, error)
 	DeleteDocument(ctx context.Context, in *DeleteDocumentRequest, opts ...grpc.CallOption) (*DeleteDocumentResponse, error)
-	// ========== Node Operations (4 methods) ==========
+	CloneDocument(ctx context.Context, in *CloneDocumentRequest, opts ...grpc.CallOption) (*CloneDocumentResponse, error)
+	RecomputeSectionPaths(ctx context.Context, in *RecomputeSectionPathsRequest, opts ...grpc.CallOption) (*RecomputeSectionPathsResponse, error)
+	ValidateDocument(ctx context.Context, in *ValidateDocumentRequest, opts ...grpc.CallOption) (*ValidateDocumentResponse, error)
+	FindDuplicateNodes(ctx context.Context, in *FindDuplicateNodesRequest, opts ...grpc.CallOption) (*FindDuplicateNodesResponse, error)
+	RefreshSummaries(ctx context.Context, in *RefreshSummariesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RefreshSummariesProgress], error)
+	// ========== Node Operations (13 methods) ==========
 	GetNode(ctx context.Context, in *GetNodeRequest, opts ...grpc.CallOption) (*GetNodeResponse, error)
+	UpdateNode(ctx context.Context, in *UpdateNodeRequest, opts ...grpc.CallOption) (*UpdateNodeResponse, error)
 	GetChildren(ctx context.Context, in *GetChildrenRequest, opts ...grpc.CallOption) (*GetChildrenResponse, error)
 	GetSubtree(ctx context.Context, in *GetSubtreeRequest, opts ...grpc.CallOption) (*GetSubtreeResponse, error)
+	GetSubtreeWithinBudget(ctx context.Context, in *GetSubtreeWithinBudgetRequest, opts ...grpc.CallOption) (*GetSubtreeWithinBudgetResponse, error)
 	GetAncestorPath(ctx context.Context, in *GetAncestorPathRequest, opts ...grpc.CallOption) (*GetAncestorPathResponse, error)
-	// ========== Search Operations (2 methods) ==========
+	BatchGetAncestorPaths(ctx context.Context, in *BatchGetAncestorPathsRequest, opts ...grpc.CallOption) (*BatchGetAncestorPathsResponse, error)
+	GetSiblings(ctx context.Context, in *GetSiblingsRequest, opts ...grpc.CallOption) (*GetSiblingsResponse, error)
+	GetNextSection(ctx context.Context, in *AdjacentSectionRequest, opts ...grpc.CallOption) (*AdjacentSectionResponse, error)
+	GetPreviousSection(ctx context.Context, in *AdjacentSectionRequest, opts ...grpc.CallOption) (*AdjacentSectionResponse, error)
+	GetReadingOrder(ctx context.Context, in *GetReadingOrderRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Node], error)
+	GetContextWindow(ctx context.Context, in *GetContextWindowRequest, opts ...grpc.CallOption) (*GetContextWindowResponse, error)
+	ExportGraph(ctx context.Context, in *ExportGraphRequest, opts ...grpc.CallOption) (*ExportGraphResponse, error)
+	// ========== Search Operations (5 methods) ==========
 	SearchByKeyword(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 	GetNodesByPage(ctx context.Context, in *GetNodesByPageRequest, opts ...grpc.CallOption) (*GetNodesByPageResponse, error)
-	// ========== Version Operations (2 methods) ==========
+	GlobalSearch(ctx context.Context, in *GlobalSearchRequest, opts ...grpc.CallOption) (*GlobalSearchResponse, error)
+	JoinNodes(ctx context.Context, in *JoinNodesRequest, opts ...grpc.CallOption) (*JoinNodesResponse, error)
+	Retrieve(ctx context.Context, in *RetrieveRequest, opts ...grpc.CallOption) (*RetrieveResponse, error)
+	// ========== Version Operations (7 methods) ==========
 	GetVersionAsOf(ctx context.Context, in *GetVersionAsOfRequest, opts ...grpc.CallOption) (*PolicyVersion, error)
+	BatchGetVersionsAsOf(ctx context.Context, in *BatchGetVersionsAsOfRequest, opts ...grpc.CallOption) (*BatchGetVersionsAsOfResponse, error)
 	ListVersions(ctx context.Context, in *ListVersionsRequest, opts ...grpc.CallOption) (*ListVersionsResponse, error)
-	// ========== Metadata Operations (7 methods) ==========
+	DeleteVersion(ctx context.Context, in *DeleteVersionRequest, opts ...grpc.CallOption) (*DeleteVersionResponse, error)
+	PruneVersions(ctx context.Context, in *PruneVersionsRequest, opts ...grpc.CallOption) (*PruneVersionsResponse, error)
+	TagVersion(ctx context.Context, in *TagVersionRequest, opts ...grpc.CallOption) (*TagVersionResponse, error)
+	UntagVersion(ctx context.Context, in *UntagVersionRequest, opts ...grpc.CallOption) (*UntagVersionResponse, error)
+	// ========== Metadata Operations (8 methods) ==========
 	StoreToolResult(ctx context.Context, in *StoreToolResultRequest, opts ...grpc.CallOption) (*StoreToolResultResponse, error)
 	GetToolResults(ctx context.Context, in *GetToolResultsRequest, opts ...grpc.CallOption) (*GetToolResultsResponse, error)
 	StoreTrajectory(ctx context.Context, in *StoreTrajectoryRequest, opts ...grpc.CallOption) (*StoreTrajectoryResponse, error)
 	GetTrajectories(ctx context.Context, in *GetTrajectoriesRequest, opts ...grpc.CallOption) (*GetTrajectoriesResponse, error)
+	ReplayTrajectory(ctx context.Context, in *ReplayTrajectoryRequest, opts ...grpc.CallOption) (*ReplayTrajectoryResponse, error)
 	StoreCrossReference(ctx context.Context, in *StoreCrossReferenceRequest, opts ...grpc.CallOption) (*StoreCrossReferenceResponse, error)
 	GetCrossReferences(ctx context.Context, in *GetCrossReferencesRequest, opts ...grpc.CallOption) (*GetCrossReferencesResponse, error)
 	StoreContradiction(ctx context.Context, in *StoreContradictionRequest, opts ...grpc.CallOption) (*StoreContradictionResponse, error)
-	// ========== Prompt Operations (3 methods) ==========
+	BatchSetMetadata(ctx context.Context, in *BatchSetMetadataRequest, opts ...grpc.CallOption) (*BatchSetMetadataResponse, error)
+	// ========== Collection Operations (5 methods) ==========
+	PutCollection(ctx context.Context, in *PutCollectionRequest, opts ...grpc.CallOption) (*PutCollectionResponse, error)
+	GetCollection(ctx context.Context, in *GetCollectionRequest, opts ...grpc.CallOption) (*GetCollectionResponse, error)
+	ListCollections(ctx context.Context, in *ListCollectionsRequest, opts ...grpc.CallOption) (*ListCollectionsResponse, error)
+	UpdateCollectionMembers(ctx context.Context, in *UpdateCollectionMembersRequest, opts ...grpc.CallOption) (*UpdateCollectionMembersResponse, error)
+	DeleteCollection(ctx context.Context, in *DeleteCollectionRequest, opts ...grpc.CallOption) (*DeleteCollectionResponse, error)
+	// ========== Prompt Operations (8 methods) ==========
 	StorePrompt(ctx context.Context, in *StorePromptRequest, opts ...grpc.CallOption) (*StorePromptResponse, error)
 	GetPrompt(ctx context.Context, in *GetPromptRequest, opts ...grpc.CallOption) (*GetPromptResponse, error)
 	RecordPromptUsage(ctx context.Context, in *RecordPromptUsageRequest, opts ...grpc.CallOption) (*RecordPromptUsageResponse, error)
-	// ========== Health & Status (2 methods) ==========
+	StorePromptExperiment(ctx context.Context, in *StorePromptExperimentRequest, opts ...grpc.CallOption) (*StorePromptExperimentResponse, error)
+	GetPromptExperiment(ctx context.Context, in *GetPromptExperimentRequest, opts ...grpc.CallOption) (*GetPromptExperimentResponse, error)
+	AssignPromptVariant(ctx context.Context, in *AssignPromptVariantRequest, opts ...grpc.CallOption) (*AssignPromptVariantResponse, error)
+	LabelPromptUsage(ctx context.Context, in *LabelPromptUsageRequest, opts ...grpc.CallOption) (*LabelPromptUsageResponse, error)
+	ComparePromptVariants(ctx context.Context, in *ComparePromptVariantsRequest, opts ...grpc.CallOption) (*ComparePromptVariantsResponse, error)
+	// ========== Conversation Operations (7 methods) ==========
+	GetMessagesPage(ctx context.Context, in *GetMessagesPageRequest, opts ...grpc.CallOption) (*GetMessagesPageResponse, error)
+	GetRecentMessages(ctx context.Context, in *GetRecentMessagesRequest, opts ...grpc.CallOption) (*GetRecentMessagesResponse, error)
+	SearchConversations(ctx context.Context, in *SearchConversationsRequest, opts ...grpc.CallOption) (*SearchConversationsResponse, error)
+	ExportConversation(ctx context.Context, in *ExportConversationRequest, opts ...grpc.CallOption) (*ExportConversationResponse, error)
+	ListConversationsByUser(ctx context.Context, in *ListConversationsByUserRequest, opts ...grpc.CallOption) (*ListConversationsByUserResponse, error)
+	GetUserConversationUsage(ctx context.Context, in *GetUserConversationUsageRequest, opts ...grpc.CallOption) (*GetUserConversationUsageResponse, error)
+	SetUserConversationQuota(ctx context.Context, in *SetUserConversationQuotaRequest, opts ...grpc.CallOption) (*SetUserConversationQuotaResponse, error)
+	// ========== Query Operations (6 methods) ==========
+	StreamQuery(ctx context.Context, in *StreamQueryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[QueryRow], error)
+	SaveQuery(ctx context.Context, in *SaveQueryRequest, opts ...grpc.CallOption) (*SaveQueryResponse, error)
+	ExecuteSavedQuery(ctx context.Context, in *ExecuteSavedQueryRequest, opts ...grpc.CallOption) (*ExecuteSavedQueryResponse, error)
+	RefreshSavedQuery(ctx context.Context, in *RefreshSavedQueryRequest, opts ...grpc.CallOption) (*RefreshSavedQueryResponse, error)
+	ListSavedQueries(ctx context.Context, in *ListSavedQueriesRequest, opts ...grpc.CallOption) (*ListSavedQueriesResponse, error)
+	DeleteSavedQuery(ctx context.Context, in *DeleteSavedQueryRequest, opts ...grpc.CallOption) (*DeleteSavedQueryResponse, error)
+	// ========== Change Feed (1 method) ==========
+	WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChangeEvent], error)
+	// ========== Replication (1 method) ==========
+	StreamWAL(ctx context.Context, in *StreamWALRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WALEntry], error)
+	// ========== Audit (1 method) ==========
+	QueryAuditLog(ctx context.Context, in *QueryAuditLogRequest, opts ...grpc.CallOption) (*QueryAuditLogResponse, error)
+	// ========== Analysis Jobs (2 methods) ==========
+	ListJobRuns(ctx context.Context, in *ListJobRunsRequest, opts ...grpc.CallOption) (*ListJobRunsResponse, error)
+	TriggerJob(ctx context.Context, in *TriggerJobRequest, opts ...grpc.CallOption) (*TriggerJobResponse, error)
+	// ========== Health & Status (3 methods) ==========
 	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	StorageBreakdown(ctx context.Context, in *StorageBreakdownRequest, opts ...grpc.CallOption) (*StorageBreakdownResponse, error)
 }
 
 type treeStoreServiceClient struct {
@@ -120,6 +233,65 @@ func (c *treeStoreServiceClient) DeleteDocument(ctx context.Context, in *DeleteD
 	return out, nil
 }
 
+func (c *treeStoreServiceClient) CloneDocument(ctx context.Context, in *CloneDocumentRequest, opts ...grpc.CallOption) (*CloneDocumentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CloneDocumentResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_CloneDocument_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) RecomputeSectionPaths(ctx context.Context, in *RecomputeSectionPathsRequest, opts ...grpc.CallOption) (*RecomputeSectionPathsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecomputeSectionPathsResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_RecomputeSectionPaths_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) ValidateDocument(ctx context.Context, in *ValidateDocumentRequest, opts ...grpc.CallOption) (*ValidateDocumentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateDocumentResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_ValidateDocument_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) FindDuplicateNodes(ctx context.Context, in *FindDuplicateNodesRequest, opts ...grpc.CallOption) (*FindDuplicateNodesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindDuplicateNodesResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_FindDuplicateNodes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) RefreshSummaries(ctx context.Context, in *RefreshSummariesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RefreshSummariesProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TreeStoreService_ServiceDesc.Streams[0], TreeStoreService_RefreshSummaries_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RefreshSummariesRequest, RefreshSummariesProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TreeStoreService_RefreshSummariesClient = grpc.ServerStreamingClient[RefreshSummariesProgress]
+
 func (c *treeStoreServiceClient) GetNode(ctx context.Context, in *GetNodeRequest, opts ...grpc.CallOption) (*GetNodeResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetNodeResponse)
@@ -130,6 +302,16 @@ func (c *treeStoreServiceClient) GetNode(ctx context.Context, in *GetNodeRequest
 	return out, nil
 }
 
+func (c *treeStoreServiceClient) UpdateNode(ctx context.Context, in *UpdateNodeRequest, opts ...grpc.CallOption) (*UpdateNodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateNodeResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_UpdateNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *treeStoreServiceClient) GetChildren(ctx context.Context, in *GetChildrenRequest, opts ...grpc.CallOption) (*GetChildrenResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetChildrenResponse)
@@ -150,6 +332,16 @@ func (c *treeStoreServiceClient) GetSubtree(ctx context.Context, in *GetSubtreeR
 	return out, nil
 }
 
+func (c *treeStoreServiceClient) GetSubtreeWithinBudget(ctx context.Context, in *GetSubtreeWithinBudgetRequest, opts ...grpc.CallOption) (*GetSubtreeWithinBudgetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSubtreeWithinBudgetResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_GetSubtreeWithinBudget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *treeStoreServiceClient) GetAncestorPath(ctx context.Context, in *GetAncestorPathRequest, opts ...grpc.CallOption) (*GetAncestorPathResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetAncestorPathResponse)
@@ -160,6 +352,85 @@ func (c *treeStoreServiceClient) GetAncestorPath(ctx context.Context, in *GetAnc
 	return out, nil
 }
 
+func (c *treeStoreServiceClient) BatchGetAncestorPaths(ctx context.Context, in *BatchGetAncestorPathsRequest, opts ...grpc.CallOption) (*BatchGetAncestorPathsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetAncestorPathsResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_BatchGetAncestorPaths_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) GetSiblings(ctx context.Context, in *GetSiblingsRequest, opts ...grpc.CallOption) (*GetSiblingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSiblingsResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_GetSiblings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) GetNextSection(ctx context.Context, in *AdjacentSectionRequest, opts ...grpc.CallOption) (*AdjacentSectionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdjacentSectionResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_GetNextSection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) GetPreviousSection(ctx context.Context, in *AdjacentSectionRequest, opts ...grpc.CallOption) (*AdjacentSectionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdjacentSectionResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_GetPreviousSection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) GetReadingOrder(ctx context.Context, in *GetReadingOrderRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Node], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TreeStoreService_ServiceDesc.Streams[1], TreeStoreService_GetReadingOrder_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetReadingOrderRequest, Node]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TreeStoreService_GetReadingOrderClient = grpc.ServerStreamingClient[Node]
+
+func (c *treeStoreServiceClient) GetContextWindow(ctx context.Context, in *GetContextWindowRequest, opts ...grpc.CallOption) (*GetContextWindowResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetContextWindowResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_GetContextWindow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) ExportGraph(ctx context.Context, in *ExportGraphRequest, opts ...grpc.CallOption) (*ExportGraphResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportGraphResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_ExportGraph_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *treeStoreServiceClient) SearchByKeyword(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SearchResponse)
@@ -180,6 +451,36 @@ func (c *treeStoreServiceClient) GetNodesByPage(ctx context.Context, in *GetNode
 	return out, nil
 }
 
+func (c *treeStoreServiceClient) GlobalSearch(ctx context.Context, in *GlobalSearchRequest, opts ...grpc.CallOption) (*GlobalSearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GlobalSearchResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_GlobalSearch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) JoinNodes(ctx context.Context, in *JoinNodesRequest, opts ...grpc.CallOption) (*JoinNodesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JoinNodesResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_JoinNodes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) Retrieve(ctx context.Context, in *RetrieveRequest, opts ...grpc.CallOption) (*RetrieveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RetrieveResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_Retrieve_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *treeStoreServiceClient) GetVersionAsOf(ctx context.Context, in *GetVersionAsOfRequest, opts ...grpc.CallOption) (*PolicyVersion, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PolicyVersion)
@@ -190,6 +491,16 @@ func (c *treeStoreServiceClient) GetVersionAsOf(ctx context.Context, in *GetVers
 	return out, nil
 }
 
+func (c *treeStoreServiceClient) BatchGetVersionsAsOf(ctx context.Context, in *BatchGetVersionsAsOfRequest, opts ...grpc.CallOption) (*BatchGetVersionsAsOfResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetVersionsAsOfResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_BatchGetVersionsAsOf_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *treeStoreServiceClient) ListVersions(ctx context.Context, in *ListVersionsRequest, opts ...grpc.CallOption) (*ListVersionsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListVersionsResponse)
@@ -200,6 +511,46 @@ func (c *treeStoreServiceClient) ListVersions(ctx context.Context, in *ListVersi
 	return out, nil
 }
 
+func (c *treeStoreServiceClient) DeleteVersion(ctx context.Context, in *DeleteVersionRequest, opts ...grpc.CallOption) (*DeleteVersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteVersionResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_DeleteVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) PruneVersions(ctx context.Context, in *PruneVersionsRequest, opts ...grpc.CallOption) (*PruneVersionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PruneVersionsResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_PruneVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) TagVersion(ctx context.Context, in *TagVersionRequest, opts ...grpc.CallOption) (*TagVersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TagVersionResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_TagVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) UntagVersion(ctx context.Context, in *UntagVersionRequest, opts ...grpc.CallOption) (*UntagVersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UntagVersionResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_UntagVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *treeStoreServiceClient) StoreToolResult(ctx context.Context, in *StoreToolResultRequest, opts ...grpc.CallOption) (*StoreToolResultResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StoreToolResultResponse)
@@ -240,6 +591,16 @@ func (c *treeStoreServiceClient) GetTrajectories(ctx context.Context, in *GetTra
 	return out, nil
 }
 
+func (c *treeStoreServiceClient) ReplayTrajectory(ctx context.Context, in *ReplayTrajectoryRequest, opts ...grpc.CallOption) (*ReplayTrajectoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplayTrajectoryResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_ReplayTrajectory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *treeStoreServiceClient) StoreCrossReference(ctx context.Context, in *StoreCrossReferenceRequest, opts ...grpc.CallOption) (*StoreCrossReferenceResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StoreCrossReferenceResponse)
@@ -270,6 +631,66 @@ func (c *treeStoreServiceClient) StoreContradiction(ctx context.Context, in *Sto
 	return out, nil
 }
 
+func (c *treeStoreServiceClient) BatchSetMetadata(ctx context.Context, in *BatchSetMetadataRequest, opts ...grpc.CallOption) (*BatchSetMetadataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchSetMetadataResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_BatchSetMetadata_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) PutCollection(ctx context.Context, in *PutCollectionRequest, opts ...grpc.CallOption) (*PutCollectionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutCollectionResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_PutCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) GetCollection(ctx context.Context, in *GetCollectionRequest, opts ...grpc.CallOption) (*GetCollectionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCollectionResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_GetCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) ListCollections(ctx context.Context, in *ListCollectionsRequest, opts ...grpc.CallOption) (*ListCollectionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCollectionsResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_ListCollections_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) UpdateCollectionMembers(ctx context.Context, in *UpdateCollectionMembersRequest, opts ...grpc.CallOption) (*UpdateCollectionMembersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateCollectionMembersResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_UpdateCollectionMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) DeleteCollection(ctx context.Context, in *DeleteCollectionRequest, opts ...grpc.CallOption) (*DeleteCollectionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteCollectionResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_DeleteCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *treeStoreServiceClient) StorePrompt(ctx context.Context, in *StorePromptRequest, opts ...grpc.CallOption) (*StorePromptResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StorePromptResponse)
@@ -300,69 +721,396 @@ func (c *treeStoreServiceClient) RecordPromptUsage(ctx context.Context, in *Reco
 	return out, nil
 }
 
-func (c *treeStoreServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+func (c *treeStoreServiceClient) StorePromptExperiment(ctx context.Context, in *StorePromptExperimentRequest, opts ...grpc.CallOption) (*StorePromptExperimentResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(HealthResponse)
-	err := c.cc.Invoke(ctx, TreeStoreService_Health_FullMethodName, in, out, cOpts...)
+	out := new(StorePromptExperimentResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_StorePromptExperiment_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *treeStoreServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+func (c *treeStoreServiceClient) GetPromptExperiment(ctx context.Context, in *GetPromptExperimentRequest, opts ...grpc.CallOption) (*GetPromptExperimentResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(StatsResponse)
-	err := c.cc.Invoke(ctx, TreeStoreService_Stats_FullMethodName, in, out, cOpts...)
+	out := new(GetPromptExperimentResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_GetPromptExperiment_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// TreeStoreServiceServer is the server API for TreeStoreService service.
-// All implementations must embed UnimplementedTreeStoreServiceServer
-// for forward compatibility.
-//
-// TreeStoreService provides hierarchical document storage with versioning
-type TreeStoreServiceServer interface {
-	// ========== Document Operations (3 methods) ==========
-	StoreDocument(context.Context, *StoreDocumentRequest) (*StoreDocumentResponse, error)
-	GetDocument(context.Context, *GetDocumentRequest) (*GetDocumentResponse, error)
-	DeleteDocument(context.Context, *DeleteDocumentRequest) (*DeleteDocumentResponse, error)
-	// ========== Node Operations (4 methods) ==========
-	GetNode(context.Context, *GetNodeRequest) (*GetNodeResponse, error)
-	GetChildren(context.Context, *GetChildrenRequest) (*GetChildrenResponse, error)
-	GetSubtree(context.Context, *GetSubtreeRequest) (*GetSubtreeResponse, error)
-	GetAncestorPath(context.Context, *GetAncestorPathRequest) (*GetAncestorPathResponse, error)
-	// ========== Search Operations (2 methods) ==========
-	SearchByKeyword(context.Context, *SearchRequest) (*SearchResponse, error)
-	GetNodesByPage(context.Context, *GetNodesByPageRequest) (*GetNodesByPageResponse, error)
-	// ========== Version Operations (2 methods) ==========
-	GetVersionAsOf(context.Context, *GetVersionAsOfRequest) (*PolicyVersion, error)
-	ListVersions(context.Context, *ListVersionsRequest) (*ListVersionsResponse, error)
-	// ========== Metadata Operations (7 methods) ==========
-	StoreToolResult(context.Context, *StoreToolResultRequest) (*StoreToolResultResponse, error)
-	GetToolResults(context.Context, *GetToolResultsRequest) (*GetToolResultsResponse, error)
-	StoreTrajectory(context.Context, *StoreTrajectoryRequest) (*StoreTrajectoryResponse, error)
-	GetTrajectories(context.Context, *GetTrajectoriesRequest) (*GetTrajectoriesResponse, error)
-	StoreCrossReference(context.Context, *StoreCrossReferenceRequest) (*StoreCrossReferenceResponse, error)
-	GetCrossReferences(context.Context, *GetCrossReferencesRequest) (*GetCrossReferencesResponse, error)
-	StoreContradiction(context.Context, *StoreContradictionRequest) (*StoreContradictionResponse, error)
-	// ========== Prompt Operations (3 methods) ==========
-	StorePrompt(context.Context, *StorePromptRequest) (*StorePromptResponse, error)
-	GetPrompt(context.Context, *GetPromptRequest) (*GetPromptResponse, error)
-	RecordPromptUsage(context.Context, *RecordPromptUsageRequest) (*RecordPromptUsageResponse, error)
-	// ========== Health & Status (2 methods) ==========
-	Health(context.Context, *HealthRequest) (*HealthResponse, error)
-	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
-	mustEmbedUnimplementedTreeStoreServiceServer()
+func (c *treeStoreServiceClient) AssignPromptVariant(ctx context.Context, in *AssignPromptVariantRequest, opts ...grpc.CallOption) (*AssignPromptVariantResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AssignPromptVariantResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_AssignPromptVariant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-// UnimplementedTreeStoreServiceServer must be embedded to have
-// forward compatible implementations.
-//
-// NOTE: this should be embedded by value instead of pointer to avoid a nil
+func (c *treeStoreServiceClient) LabelPromptUsage(ctx context.Context, in *LabelPromptUsageRequest, opts ...grpc.CallOption) (*LabelPromptUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LabelPromptUsageResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_LabelPromptUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) ComparePromptVariants(ctx context.Context, in *ComparePromptVariantsRequest, opts ...grpc.CallOption) (*ComparePromptVariantsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ComparePromptVariantsResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_ComparePromptVariants_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) GetMessagesPage(ctx context.Context, in *GetMessagesPageRequest, opts ...grpc.CallOption) (*GetMessagesPageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMessagesPageResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_GetMessagesPage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) GetRecentMessages(ctx context.Context, in *GetRecentMessagesRequest, opts ...grpc.CallOption) (*GetRecentMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRecentMessagesResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_GetRecentMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) SearchConversations(ctx context.Context, in *SearchConversationsRequest, opts ...grpc.CallOption) (*SearchConversationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchConversationsResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_SearchConversations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) ExportConversation(ctx context.Context, in *ExportConversationRequest, opts ...grpc.CallOption) (*ExportConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportConversationResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_ExportConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) ListConversationsByUser(ctx context.Context, in *ListConversationsByUserRequest, opts ...grpc.CallOption) (*ListConversationsByUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListConversationsByUserResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_ListConversationsByUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) GetUserConversationUsage(ctx context.Context, in *GetUserConversationUsageRequest, opts ...grpc.CallOption) (*GetUserConversationUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserConversationUsageResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_GetUserConversationUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) SetUserConversationQuota(ctx context.Context, in *SetUserConversationQuotaRequest, opts ...grpc.CallOption) (*SetUserConversationQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetUserConversationQuotaResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_SetUserConversationQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) StreamQuery(ctx context.Context, in *StreamQueryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[QueryRow], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TreeStoreService_ServiceDesc.Streams[2], TreeStoreService_StreamQuery_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamQueryRequest, QueryRow]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TreeStoreService_StreamQueryClient = grpc.ServerStreamingClient[QueryRow]
+
+func (c *treeStoreServiceClient) SaveQuery(ctx context.Context, in *SaveQueryRequest, opts ...grpc.CallOption) (*SaveQueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SaveQueryResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_SaveQuery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) ExecuteSavedQuery(ctx context.Context, in *ExecuteSavedQueryRequest, opts ...grpc.CallOption) (*ExecuteSavedQueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecuteSavedQueryResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_ExecuteSavedQuery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) RefreshSavedQuery(ctx context.Context, in *RefreshSavedQueryRequest, opts ...grpc.CallOption) (*RefreshSavedQueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefreshSavedQueryResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_RefreshSavedQuery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) ListSavedQueries(ctx context.Context, in *ListSavedQueriesRequest, opts ...grpc.CallOption) (*ListSavedQueriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSavedQueriesResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_ListSavedQueries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) DeleteSavedQuery(ctx context.Context, in *DeleteSavedQueryRequest, opts ...grpc.CallOption) (*DeleteSavedQueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSavedQueryResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_DeleteSavedQuery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChangeEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TreeStoreService_ServiceDesc.Streams[3], TreeStoreService_WatchChanges_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchChangesRequest, ChangeEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TreeStoreService_WatchChangesClient = grpc.ServerStreamingClient[ChangeEvent]
+
+func (c *treeStoreServiceClient) StreamWAL(ctx context.Context, in *StreamWALRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WALEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TreeStoreService_ServiceDesc.Streams[4], TreeStoreService_StreamWAL_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamWALRequest, WALEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TreeStoreService_StreamWALClient = grpc.ServerStreamingClient[WALEntry]
+
+func (c *treeStoreServiceClient) QueryAuditLog(ctx context.Context, in *QueryAuditLogRequest, opts ...grpc.CallOption) (*QueryAuditLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryAuditLogResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_QueryAuditLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) ListJobRuns(ctx context.Context, in *ListJobRunsRequest, opts ...grpc.CallOption) (*ListJobRunsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListJobRunsResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_ListJobRuns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) TriggerJob(ctx context.Context, in *TriggerJobRequest, opts ...grpc.CallOption) (*TriggerJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TriggerJobResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_TriggerJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_Health_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreServiceClient) StorageBreakdown(ctx context.Context, in *StorageBreakdownRequest, opts ...grpc.CallOption) (*StorageBreakdownResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StorageBreakdownResponse)
+	err := c.cc.Invoke(ctx, TreeStoreService_StorageBreakdown_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TreeStoreServiceServer is the server API for TreeStoreService service.
+// All implementations must embed UnimplementedTreeStoreServiceServer
+// for forward compatibility.
+//
+// TreeStoreService provides hierarchical document storage with versioning
+type TreeStoreServiceServer interface {
+	// ========== Document Operations (8 methods) ==========
+	StoreDocument(context.Context, *StoreDocumentRequest) (*StoreDocumentResponse, error)
+	GetDocument(context.Context, *GetDocumentRequest) (*GetDocumentResponse, error)
+	DeleteDocument(context.Context, *DeleteDocumentRequest) (*DeleteDocumentResponse, error)
+	CloneDocument(context.Context, *CloneDocumentRequest) (*CloneDocumentResponse, error)
+	RecomputeSectionPaths(context.Context, *RecomputeSectionPathsRequest) (*RecomputeSectionPathsResponse, error)
+	ValidateDocument(context.Context, *ValidateDocumentRequest) (*ValidateDocumentResponse, error)
+	FindDuplicateNodes(context.Context, *FindDuplicateNodesRequest) (*FindDuplicateNodesResponse, error)
+	RefreshSummaries(*RefreshSummariesRequest, grpc.ServerStreamingServer[RefreshSummariesProgress]) error
+	// ========== Node Operations (13 methods) ==========
+	GetNode(context.Context, *GetNodeRequest) (*GetNodeResponse, error)
+	UpdateNode(context.Context, *UpdateNodeRequest) (*UpdateNodeResponse, error)
+	GetChildren(context.Context, *GetChildrenRequest) (*GetChildrenResponse, error)
+	GetSubtree(context.Context, *GetSubtreeRequest) (*GetSubtreeResponse, error)
+	GetSubtreeWithinBudget(context.Context, *GetSubtreeWithinBudgetRequest) (*GetSubtreeWithinBudgetResponse, error)
+	GetAncestorPath(context.Context, *GetAncestorPathRequest) (*GetAncestorPathResponse, error)
+	BatchGetAncestorPaths(context.Context, *BatchGetAncestorPathsRequest) (*BatchGetAncestorPathsResponse, error)
+	GetSiblings(context.Context, *GetSiblingsRequest) (*GetSiblingsResponse, error)
+	GetNextSection(context.Context, *AdjacentSectionRequest) (*AdjacentSectionResponse, error)
+	GetPreviousSection(context.Context, *AdjacentSectionRequest) (*AdjacentSectionResponse, error)
+	GetReadingOrder(*GetReadingOrderRequest, grpc.ServerStreamingServer[Node]) error
+	GetContextWindow(context.Context, *GetContextWindowRequest) (*GetContextWindowResponse, error)
+	ExportGraph(context.Context, *ExportGraphRequest) (*ExportGraphResponse, error)
+	// ========== Search Operations (5 methods) ==========
+	SearchByKeyword(context.Context, *SearchRequest) (*SearchResponse, error)
+	GetNodesByPage(context.Context, *GetNodesByPageRequest) (*GetNodesByPageResponse, error)
+	GlobalSearch(context.Context, *GlobalSearchRequest) (*GlobalSearchResponse, error)
+	JoinNodes(context.Context, *JoinNodesRequest) (*JoinNodesResponse, error)
+	Retrieve(context.Context, *RetrieveRequest) (*RetrieveResponse, error)
+	// ========== Version Operations (7 methods) ==========
+	GetVersionAsOf(context.Context, *GetVersionAsOfRequest) (*PolicyVersion, error)
+	BatchGetVersionsAsOf(context.Context, *BatchGetVersionsAsOfRequest) (*BatchGetVersionsAsOfResponse, error)
+	ListVersions(context.Context, *ListVersionsRequest) (*ListVersionsResponse, error)
+	DeleteVersion(context.Context, *DeleteVersionRequest) (*DeleteVersionResponse, error)
+	PruneVersions(context.Context, *PruneVersionsRequest) (*PruneVersionsResponse, error)
+	TagVersion(context.Context, *TagVersionRequest) (*TagVersionResponse, error)
+	UntagVersion(context.Context, *UntagVersionRequest) (*UntagVersionResponse, error)
+	// ========== Metadata Operations (8 methods) ==========
+	StoreToolResult(context.Context, *StoreToolResultRequest) (*StoreToolResultResponse, error)
+	GetToolResults(context.Context, *GetToolResultsRequest) (*GetToolResultsResponse, error)
+	StoreTrajectory(context.Context, *StoreTrajectoryRequest) (*StoreTrajectoryResponse, error)
+	GetTrajectories(context.Context, *GetTrajectoriesRequest) (*GetTrajectoriesResponse, error)
+	ReplayTrajectory(context.Context, *ReplayTrajectoryRequest) (*ReplayTrajectoryResponse, error)
+	StoreCrossReference(context.Context, *StoreCrossReferenceRequest) (*StoreCrossReferenceResponse, error)
+	GetCrossReferences(context.Context, *GetCrossReferencesRequest) (*GetCrossReferencesResponse, error)
+	StoreContradiction(context.Context, *StoreContradictionRequest) (*StoreContradictionResponse, error)
+	BatchSetMetadata(context.Context, *BatchSetMetadataRequest) (*BatchSetMetadataResponse, error)
+	// ========== Collection Operations (5 methods) ==========
+	PutCollection(context.Context, *PutCollectionRequest) (*PutCollectionResponse, error)
+	GetCollection(context.Context, *GetCollectionRequest) (*GetCollectionResponse, error)
+	ListCollections(context.Context, *ListCollectionsRequest) (*ListCollectionsResponse, error)
+	UpdateCollectionMembers(context.Context, *UpdateCollectionMembersRequest) (*UpdateCollectionMembersResponse, error)
+	DeleteCollection(context.Context, *DeleteCollectionRequest) (*DeleteCollectionResponse, error)
+	// ========== Prompt Operations (8 methods) ==========
+	StorePrompt(context.Context, *StorePromptRequest) (*StorePromptResponse, error)
+	GetPrompt(context.Context, *GetPromptRequest) (*GetPromptResponse, error)
+	RecordPromptUsage(context.Context, *RecordPromptUsageRequest) (*RecordPromptUsageResponse, error)
+	StorePromptExperiment(context.Context, *StorePromptExperimentRequest) (*StorePromptExperimentResponse, error)
+	GetPromptExperiment(context.Context, *GetPromptExperimentRequest) (*GetPromptExperimentResponse, error)
+	AssignPromptVariant(context.Context, *AssignPromptVariantRequest) (*AssignPromptVariantResponse, error)
+	LabelPromptUsage(context.Context, *LabelPromptUsageRequest) (*LabelPromptUsageResponse, error)
+	ComparePromptVariants(context.Context, *ComparePromptVariantsRequest) (*ComparePromptVariantsResponse, error)
+	// ========== Conversation Operations (7 methods) ==========
+	GetMessagesPage(context.Context, *GetMessagesPageRequest) (*GetMessagesPageResponse, error)
+	GetRecentMessages(context.Context, *GetRecentMessagesRequest) (*GetRecentMessagesResponse, error)
+	SearchConversations(context.Context, *SearchConversationsRequest) (*SearchConversationsResponse, error)
+	ExportConversation(context.Context, *ExportConversationRequest) (*ExportConversationResponse, error)
+	ListConversationsByUser(context.Context, *ListConversationsByUserRequest) (*ListConversationsByUserResponse, error)
+	GetUserConversationUsage(context.Context, *GetUserConversationUsageRequest) (*GetUserConversationUsageResponse, error)
+	SetUserConversationQuota(context.Context, *SetUserConversationQuotaRequest) (*SetUserConversationQuotaResponse, error)
+	// ========== Query Operations (6 methods) ==========
+	StreamQuery(*StreamQueryRequest, grpc.ServerStreamingServer[QueryRow]) error
+	SaveQuery(context.Context, *SaveQueryRequest) (*SaveQueryResponse, error)
+	ExecuteSavedQuery(context.Context, *ExecuteSavedQueryRequest) (*ExecuteSavedQueryResponse, error)
+	RefreshSavedQuery(context.Context, *RefreshSavedQueryRequest) (*RefreshSavedQueryResponse, error)
+	ListSavedQueries(context.Context, *ListSavedQueriesRequest) (*ListSavedQueriesResponse, error)
+	DeleteSavedQuery(context.Context, *DeleteSavedQueryRequest) (*DeleteSavedQueryResponse, error)
+	// ========== Change Feed (1 method) ==========
+	WatchChanges(*WatchChangesRequest, grpc.ServerStreamingServer[ChangeEvent]) error
+	// ========== Replication (1 method) ==========
+	StreamWAL(*StreamWALRequest, grpc.ServerStreamingServer[WALEntry]) error
+	// ========== Audit (1 method) ==========
+	QueryAuditLog(context.Context, *QueryAuditLogRequest) (*QueryAuditLogResponse, error)
+	// ========== Analysis Jobs (2 methods) ==========
+	ListJobRuns(context.Context, *ListJobRunsRequest) (*ListJobRunsResponse, error)
+	TriggerJob(context.Context, *TriggerJobRequest) (*TriggerJobResponse, error)
+	// ========== Health & Status (3 methods) ==========
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	StorageBreakdown(context.Context, *StorageBreakdownRequest) (*StorageBreakdownResponse, error)
+	mustEmbedUnimplementedTreeStoreServiceServer()
+}
+
+// UnimplementedTreeStoreServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
 // pointer dereference when methods are called.
 type UnimplementedTreeStoreServiceServer struct{}
 
@@ -375,30 +1123,96 @@ func (UnimplementedTreeStoreServiceServer) GetDocument(context.Context, *GetDocu
 func (UnimplementedTreeStoreServiceServer) DeleteDocument(context.Context, *DeleteDocumentRequest) (*DeleteDocumentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteDocument not implemented")
 }
+func (UnimplementedTreeStoreServiceServer) CloneDocument(context.Context, *CloneDocumentRequest) (*CloneDocumentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloneDocument not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) RecomputeSectionPaths(context.Context, *RecomputeSectionPathsRequest) (*RecomputeSectionPathsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecomputeSectionPaths not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) ValidateDocument(context.Context, *ValidateDocumentRequest) (*ValidateDocumentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateDocument not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) FindDuplicateNodes(context.Context, *FindDuplicateNodesRequest) (*FindDuplicateNodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindDuplicateNodes not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) RefreshSummaries(*RefreshSummariesRequest, grpc.ServerStreamingServer[RefreshSummariesProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method RefreshSummaries not implemented")
+}
 func (UnimplementedTreeStoreServiceServer) GetNode(context.Context, *GetNodeRequest) (*GetNodeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetNode not implemented")
 }
+func (UnimplementedTreeStoreServiceServer) UpdateNode(context.Context, *UpdateNodeRequest) (*UpdateNodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateNode not implemented")
+}
 func (UnimplementedTreeStoreServiceServer) GetChildren(context.Context, *GetChildrenRequest) (*GetChildrenResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetChildren not implemented")
 }
 func (UnimplementedTreeStoreServiceServer) GetSubtree(context.Context, *GetSubtreeRequest) (*GetSubtreeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetSubtree not implemented")
 }
+func (UnimplementedTreeStoreServiceServer) GetSubtreeWithinBudget(context.Context, *GetSubtreeWithinBudgetRequest) (*GetSubtreeWithinBudgetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSubtreeWithinBudget not implemented")
+}
 func (UnimplementedTreeStoreServiceServer) GetAncestorPath(context.Context, *GetAncestorPathRequest) (*GetAncestorPathResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAncestorPath not implemented")
 }
+func (UnimplementedTreeStoreServiceServer) BatchGetAncestorPaths(context.Context, *BatchGetAncestorPathsRequest) (*BatchGetAncestorPathsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetAncestorPaths not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) GetSiblings(context.Context, *GetSiblingsRequest) (*GetSiblingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSiblings not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) GetNextSection(context.Context, *AdjacentSectionRequest) (*AdjacentSectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNextSection not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) GetPreviousSection(context.Context, *AdjacentSectionRequest) (*AdjacentSectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPreviousSection not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) GetReadingOrder(*GetReadingOrderRequest, grpc.ServerStreamingServer[Node]) error {
+	return status.Errorf(codes.Unimplemented, "method GetReadingOrder not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) GetContextWindow(context.Context, *GetContextWindowRequest) (*GetContextWindowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContextWindow not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) ExportGraph(context.Context, *ExportGraphRequest) (*ExportGraphResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportGraph not implemented")
+}
 func (UnimplementedTreeStoreServiceServer) SearchByKeyword(context.Context, *SearchRequest) (*SearchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SearchByKeyword not implemented")
 }
 func (UnimplementedTreeStoreServiceServer) GetNodesByPage(context.Context, *GetNodesByPageRequest) (*GetNodesByPageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetNodesByPage not implemented")
 }
+func (UnimplementedTreeStoreServiceServer) GlobalSearch(context.Context, *GlobalSearchRequest) (*GlobalSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GlobalSearch not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) JoinNodes(context.Context, *JoinNodesRequest) (*JoinNodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinNodes not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) Retrieve(context.Context, *RetrieveRequest) (*RetrieveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Retrieve not implemented")
+}
 func (UnimplementedTreeStoreServiceServer) GetVersionAsOf(context.Context, *GetVersionAsOfRequest) (*PolicyVersion, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetVersionAsOf not implemented")
 }
+func (UnimplementedTreeStoreServiceServer) BatchGetVersionsAsOf(context.Context, *BatchGetVersionsAsOfRequest) (*BatchGetVersionsAsOfResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetVersionsAsOf not implemented")
+}
 func (UnimplementedTreeStoreServiceServer) ListVersions(context.Context, *ListVersionsRequest) (*ListVersionsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListVersions not implemented")
 }
+func (UnimplementedTreeStoreServiceServer) DeleteVersion(context.Context, *DeleteVersionRequest) (*DeleteVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteVersion not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) PruneVersions(context.Context, *PruneVersionsRequest) (*PruneVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PruneVersions not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) TagVersion(context.Context, *TagVersionRequest) (*TagVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TagVersion not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) UntagVersion(context.Context, *UntagVersionRequest) (*UntagVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UntagVersion not implemented")
+}
 func (UnimplementedTreeStoreServiceServer) StoreToolResult(context.Context, *StoreToolResultRequest) (*StoreToolResultResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StoreToolResult not implemented")
 }
@@ -411,6 +1225,9 @@ func (UnimplementedTreeStoreServiceServer) StoreTrajectory(context.Context, *Sto
 func (UnimplementedTreeStoreServiceServer) GetTrajectories(context.Context, *GetTrajectoriesRequest) (*GetTrajectoriesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTrajectories not implemented")
 }
+func (UnimplementedTreeStoreServiceServer) ReplayTrajectory(context.Context, *ReplayTrajectoryRequest) (*ReplayTrajectoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayTrajectory not implemented")
+}
 func (UnimplementedTreeStoreServiceServer) StoreCrossReference(context.Context, *StoreCrossReferenceRequest) (*StoreCrossReferenceResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StoreCrossReference not implemented")
 }
@@ -420,6 +1237,24 @@ func (UnimplementedTreeStoreServiceServer) GetCrossReferences(context.Context, *
 func (UnimplementedTreeStoreServiceServer) StoreContradiction(context.Context, *StoreContradictionRequest) (*StoreContradictionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StoreContradiction not implemented")
 }
+func (UnimplementedTreeStoreServiceServer) BatchSetMetadata(context.Context, *BatchSetMetadataRequest) (*BatchSetMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchSetMetadata not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) PutCollection(context.Context, *PutCollectionRequest) (*PutCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutCollection not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) GetCollection(context.Context, *GetCollectionRequest) (*GetCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCollection not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) ListCollections(context.Context, *ListCollectionsRequest) (*ListCollectionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCollections not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) UpdateCollectionMembers(context.Context, *UpdateCollectionMembersRequest) (*UpdateCollectionMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCollectionMembers not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) DeleteCollection(context.Context, *DeleteCollectionRequest) (*DeleteCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCollection not implemented")
+}
 func (UnimplementedTreeStoreServiceServer) StorePrompt(context.Context, *StorePromptRequest) (*StorePromptResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StorePrompt not implemented")
 }
@@ -429,20 +1264,92 @@ func (UnimplementedTreeStoreServiceServer) GetPrompt(context.Context, *GetPrompt
 func (UnimplementedTreeStoreServiceServer) RecordPromptUsage(context.Context, *RecordPromptUsageRequest) (*RecordPromptUsageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RecordPromptUsage not implemented")
 }
-func (UnimplementedTreeStoreServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+func (UnimplementedTreeStoreServiceServer) StorePromptExperiment(context.Context, *StorePromptExperimentRequest) (*StorePromptExperimentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StorePromptExperiment not implemented")
 }
-func (UnimplementedTreeStoreServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+func (UnimplementedTreeStoreServiceServer) GetPromptExperiment(context.Context, *GetPromptExperimentRequest) (*GetPromptExperimentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPromptExperiment not implemented")
 }
-func (UnimplementedTreeStoreServiceServer) mustEmbedUnimplementedTreeStoreServiceServer() {}
-func (UnimplementedTreeStoreServiceServer) testEmbeddedByValue()                          {}
-
-// UnsafeTreeStoreServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to TreeStoreServiceServer will
-// result in compilation errors.
-type UnsafeTreeStoreServiceServer interface {
-	mustEmbedUnimplementedTreeStoreServiceServer()
+func (UnimplementedTreeStoreServiceServer) AssignPromptVariant(context.Context, *AssignPromptVariantRequest) (*AssignPromptVariantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AssignPromptVariant not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) LabelPromptUsage(context.Context, *LabelPromptUsageRequest) (*LabelPromptUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LabelPromptUsage not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) ComparePromptVariants(context.Context, *ComparePromptVariantsRequest) (*ComparePromptVariantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ComparePromptVariants not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) GetMessagesPage(context.Context, *GetMessagesPageRequest) (*GetMessagesPageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMessagesPage not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) GetRecentMessages(context.Context, *GetRecentMessagesRequest) (*GetRecentMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecentMessages not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) SearchConversations(context.Context, *SearchConversationsRequest) (*SearchConversationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchConversations not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) ExportConversation(context.Context, *ExportConversationRequest) (*ExportConversationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportConversation not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) ListConversationsByUser(context.Context, *ListConversationsByUserRequest) (*ListConversationsByUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConversationsByUser not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) GetUserConversationUsage(context.Context, *GetUserConversationUsageRequest) (*GetUserConversationUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserConversationUsage not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) SetUserConversationQuota(context.Context, *SetUserConversationQuotaRequest) (*SetUserConversationQuotaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetUserConversationQuota not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) StreamQuery(*StreamQueryRequest, grpc.ServerStreamingServer[QueryRow]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamQuery not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) SaveQuery(context.Context, *SaveQueryRequest) (*SaveQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveQuery not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) ExecuteSavedQuery(context.Context, *ExecuteSavedQueryRequest) (*ExecuteSavedQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteSavedQuery not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) RefreshSavedQuery(context.Context, *RefreshSavedQueryRequest) (*RefreshSavedQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshSavedQuery not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) ListSavedQueries(context.Context, *ListSavedQueriesRequest) (*ListSavedQueriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSavedQueries not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) DeleteSavedQuery(context.Context, *DeleteSavedQueryRequest) (*DeleteSavedQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSavedQuery not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) WatchChanges(*WatchChangesRequest, grpc.ServerStreamingServer[ChangeEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchChanges not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) StreamWAL(*StreamWALRequest, grpc.ServerStreamingServer[WALEntry]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamWAL not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) QueryAuditLog(context.Context, *QueryAuditLogRequest) (*QueryAuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryAuditLog not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) ListJobRuns(context.Context, *ListJobRunsRequest) (*ListJobRunsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListJobRuns not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) TriggerJob(context.Context, *TriggerJobRequest) (*TriggerJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerJob not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) StorageBreakdown(context.Context, *StorageBreakdownRequest) (*StorageBreakdownResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StorageBreakdown not implemented")
+}
+func (UnimplementedTreeStoreServiceServer) mustEmbedUnimplementedTreeStoreServiceServer() {}
+func (UnimplementedTreeStoreServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeTreeStoreServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TreeStoreServiceServer will
+// result in compilation errors.
+type UnsafeTreeStoreServiceServer interface {
+	mustEmbedUnimplementedTreeStoreServiceServer()
 }
 
 func RegisterTreeStoreServiceServer(s grpc.ServiceRegistrar, srv TreeStoreServiceServer) {
@@ -510,6 +1417,89 @@ func _TreeStoreService_DeleteDocument_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TreeStoreService_CloneDocument_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloneDocumentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).CloneDocument(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_CloneDocument_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).CloneDocument(ctx, req.(*CloneDocumentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_RecomputeSectionPaths_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecomputeSectionPathsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).RecomputeSectionPaths(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_RecomputeSectionPaths_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).RecomputeSectionPaths(ctx, req.(*RecomputeSectionPathsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_ValidateDocument_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateDocumentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).ValidateDocument(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_ValidateDocument_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).ValidateDocument(ctx, req.(*ValidateDocumentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_FindDuplicateNodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindDuplicateNodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).FindDuplicateNodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_FindDuplicateNodes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).FindDuplicateNodes(ctx, req.(*FindDuplicateNodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_RefreshSummaries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RefreshSummariesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TreeStoreServiceServer).RefreshSummaries(m, &grpc.GenericServerStream[RefreshSummariesRequest, RefreshSummariesProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TreeStoreService_RefreshSummariesServer = grpc.ServerStreamingServer[RefreshSummariesProgress]
+
 func _TreeStoreService_GetNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetNodeRequest)
 	if err := dec(in); err != nil {
@@ -528,6 +1518,24 @@ func _TreeStoreService_GetNode_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TreeStoreService_UpdateNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).UpdateNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_UpdateNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).UpdateNode(ctx, req.(*UpdateNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TreeStoreService_GetChildren_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetChildrenRequest)
 	if err := dec(in); err != nil {
@@ -564,6 +1572,24 @@ func _TreeStoreService_GetSubtree_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TreeStoreService_GetSubtreeWithinBudget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSubtreeWithinBudgetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetSubtreeWithinBudget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetSubtreeWithinBudget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetSubtreeWithinBudget(ctx, req.(*GetSubtreeWithinBudgetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TreeStoreService_GetAncestorPath_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetAncestorPathRequest)
 	if err := dec(in); err != nil {
@@ -582,6 +1608,125 @@ func _TreeStoreService_GetAncestorPath_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TreeStoreService_BatchGetAncestorPaths_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetAncestorPathsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).BatchGetAncestorPaths(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_BatchGetAncestorPaths_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).BatchGetAncestorPaths(ctx, req.(*BatchGetAncestorPathsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetSiblings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSiblingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetSiblings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetSiblings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetSiblings(ctx, req.(*GetSiblingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetNextSection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdjacentSectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetNextSection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetNextSection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetNextSection(ctx, req.(*AdjacentSectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetPreviousSection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdjacentSectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetPreviousSection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetPreviousSection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetPreviousSection(ctx, req.(*AdjacentSectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetReadingOrder_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetReadingOrderRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TreeStoreServiceServer).GetReadingOrder(m, &grpc.GenericServerStream[GetReadingOrderRequest, Node]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TreeStoreService_GetReadingOrderServer = grpc.ServerStreamingServer[Node]
+
+func _TreeStoreService_GetContextWindow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetContextWindowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetContextWindow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetContextWindow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetContextWindow(ctx, req.(*GetContextWindowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_ExportGraph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportGraphRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).ExportGraph(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_ExportGraph_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).ExportGraph(ctx, req.(*ExportGraphRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TreeStoreService_SearchByKeyword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SearchRequest)
 	if err := dec(in); err != nil {
@@ -618,356 +1763,1747 @@ func _TreeStoreService_GetNodesByPage_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TreeStoreService_GetVersionAsOf_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetVersionAsOfRequest)
+func _TreeStoreService_GlobalSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GlobalSearchRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).GetVersionAsOf(ctx, in)
+		return srv.(TreeStoreServiceServer).GlobalSearch(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TreeStoreService_GetVersionAsOf_FullMethodName,
+		FullMethod: TreeStoreService_GlobalSearch_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).GetVersionAsOf(ctx, req.(*GetVersionAsOfRequest))
+		return srv.(TreeStoreServiceServer).GlobalSearch(ctx, req.(*GlobalSearchRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TreeStoreService_ListVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListVersionsRequest)
+func _TreeStoreService_JoinNodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinNodesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).ListVersions(ctx, in)
+		return srv.(TreeStoreServiceServer).JoinNodes(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TreeStoreService_ListVersions_FullMethodName,
+		FullMethod: TreeStoreService_JoinNodes_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).ListVersions(ctx, req.(*ListVersionsRequest))
+		return srv.(TreeStoreServiceServer).JoinNodes(ctx, req.(*JoinNodesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TreeStoreService_StoreToolResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StoreToolResultRequest)
+func _TreeStoreService_Retrieve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetrieveRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).StoreToolResult(ctx, in)
+		return srv.(TreeStoreServiceServer).Retrieve(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TreeStoreService_StoreToolResult_FullMethodName,
+		FullMethod: TreeStoreService_Retrieve_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).StoreToolResult(ctx, req.(*StoreToolResultRequest))
+		return srv.(TreeStoreServiceServer).Retrieve(ctx, req.(*RetrieveRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TreeStoreService_GetToolResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetToolResultsRequest)
+func _TreeStoreService_GetVersionAsOf_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVersionAsOfRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).GetToolResults(ctx, in)
+		return srv.(TreeStoreServiceServer).GetVersionAsOf(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TreeStoreService_GetToolResults_FullMethodName,
+		FullMethod: TreeStoreService_GetVersionAsOf_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).GetToolResults(ctx, req.(*GetToolResultsRequest))
+		return srv.(TreeStoreServiceServer).GetVersionAsOf(ctx, req.(*GetVersionAsOfRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_BatchGetVersionsAsOf_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetVersionsAsOfRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).BatchGetVersionsAsOf(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_BatchGetVersionsAsOf_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).BatchGetVersionsAsOf(ctx, req.(*BatchGetVersionsAsOfRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_ListVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).ListVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_ListVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).ListVersions(ctx, req.(*ListVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_DeleteVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).DeleteVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_DeleteVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).DeleteVersion(ctx, req.(*DeleteVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_PruneVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PruneVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).PruneVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_PruneVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).PruneVersions(ctx, req.(*PruneVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_TagVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TagVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).TagVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_TagVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).TagVersion(ctx, req.(*TagVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_UntagVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UntagVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).UntagVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_UntagVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).UntagVersion(ctx, req.(*UntagVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_StoreToolResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoreToolResultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).StoreToolResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_StoreToolResult_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).StoreToolResult(ctx, req.(*StoreToolResultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetToolResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetToolResultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetToolResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetToolResults_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetToolResults(ctx, req.(*GetToolResultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_StoreTrajectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoreTrajectoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).StoreTrajectory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_StoreTrajectory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).StoreTrajectory(ctx, req.(*StoreTrajectoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetTrajectories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTrajectoriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetTrajectories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetTrajectories_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetTrajectories(ctx, req.(*GetTrajectoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_ReplayTrajectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayTrajectoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).ReplayTrajectory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_ReplayTrajectory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).ReplayTrajectory(ctx, req.(*ReplayTrajectoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_StoreCrossReference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoreCrossReferenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).StoreCrossReference(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_StoreCrossReference_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).StoreCrossReference(ctx, req.(*StoreCrossReferenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetCrossReferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCrossReferencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetCrossReferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetCrossReferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetCrossReferences(ctx, req.(*GetCrossReferencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_StoreContradiction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoreContradictionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).StoreContradiction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_StoreContradiction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).StoreContradiction(ctx, req.(*StoreContradictionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_BatchSetMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchSetMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).BatchSetMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_BatchSetMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).BatchSetMetadata(ctx, req.(*BatchSetMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_PutCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).PutCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_PutCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).PutCollection(ctx, req.(*PutCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetCollection(ctx, req.(*GetCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_ListCollections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCollectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).ListCollections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_ListCollections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).ListCollections(ctx, req.(*ListCollectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_UpdateCollectionMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCollectionMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).UpdateCollectionMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_UpdateCollectionMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).UpdateCollectionMembers(ctx, req.(*UpdateCollectionMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_DeleteCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).DeleteCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_DeleteCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).DeleteCollection(ctx, req.(*DeleteCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_StorePrompt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorePromptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).StorePrompt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_StorePrompt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).StorePrompt(ctx, req.(*StorePromptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetPrompt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPromptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetPrompt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetPrompt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetPrompt(ctx, req.(*GetPromptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_RecordPromptUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordPromptUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).RecordPromptUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_RecordPromptUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).RecordPromptUsage(ctx, req.(*RecordPromptUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_StorePromptExperiment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorePromptExperimentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).StorePromptExperiment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_StorePromptExperiment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).StorePromptExperiment(ctx, req.(*StorePromptExperimentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetPromptExperiment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPromptExperimentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetPromptExperiment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetPromptExperiment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetPromptExperiment(ctx, req.(*GetPromptExperimentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_AssignPromptVariant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AssignPromptVariantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).AssignPromptVariant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_AssignPromptVariant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).AssignPromptVariant(ctx, req.(*AssignPromptVariantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_LabelPromptUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LabelPromptUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).LabelPromptUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_LabelPromptUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).LabelPromptUsage(ctx, req.(*LabelPromptUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_ComparePromptVariants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ComparePromptVariantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).ComparePromptVariants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_ComparePromptVariants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).ComparePromptVariants(ctx, req.(*ComparePromptVariantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetMessagesPage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessagesPageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetMessagesPage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetMessagesPage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetMessagesPage(ctx, req.(*GetMessagesPageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetRecentMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRecentMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetRecentMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetRecentMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetRecentMessages(ctx, req.(*GetRecentMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_SearchConversations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchConversationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).SearchConversations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_SearchConversations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).SearchConversations(ctx, req.(*SearchConversationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_ExportConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).ExportConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_ExportConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).ExportConversation(ctx, req.(*ExportConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_ListConversationsByUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConversationsByUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).ListConversationsByUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_ListConversationsByUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).ListConversationsByUser(ctx, req.(*ListConversationsByUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_GetUserConversationUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserConversationUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).GetUserConversationUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_GetUserConversationUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).GetUserConversationUsage(ctx, req.(*GetUserConversationUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_SetUserConversationQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUserConversationQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).SetUserConversationQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_SetUserConversationQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).SetUserConversationQuota(ctx, req.(*SetUserConversationQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_StreamQuery_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamQueryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TreeStoreServiceServer).StreamQuery(m, &grpc.GenericServerStream[StreamQueryRequest, QueryRow]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TreeStoreService_StreamQueryServer = grpc.ServerStreamingServer[QueryRow]
+
+func _TreeStoreService_SaveQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).SaveQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_SaveQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).SaveQuery(ctx, req.(*SaveQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_ExecuteSavedQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteSavedQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).ExecuteSavedQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_ExecuteSavedQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).ExecuteSavedQuery(ctx, req.(*ExecuteSavedQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_RefreshSavedQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshSavedQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).RefreshSavedQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_RefreshSavedQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).RefreshSavedQuery(ctx, req.(*RefreshSavedQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_ListSavedQueries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSavedQueriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).ListSavedQueries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_ListSavedQueries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).ListSavedQueries(ctx, req.(*ListSavedQueriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_DeleteSavedQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSavedQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).DeleteSavedQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_DeleteSavedQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).DeleteSavedQuery(ctx, req.(*DeleteSavedQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_WatchChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TreeStoreServiceServer).WatchChanges(m, &grpc.GenericServerStream[WatchChangesRequest, ChangeEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TreeStoreService_WatchChangesServer = grpc.ServerStreamingServer[ChangeEvent]
+
+func _TreeStoreService_StreamWAL_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamWALRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TreeStoreServiceServer).StreamWAL(m, &grpc.GenericServerStream[StreamWALRequest, WALEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TreeStoreService_StreamWALServer = grpc.ServerStreamingServer[WALEntry]
+
+func _TreeStoreService_QueryAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).QueryAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_QueryAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).QueryAuditLog(ctx, req.(*QueryAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_ListJobRuns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobRunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).ListJobRuns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_ListJobRuns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).ListJobRuns(ctx, req.(*ListJobRunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_TriggerJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).TriggerJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_TriggerJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).TriggerJob(ctx, req.(*TriggerJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeStoreService_StorageBreakdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageBreakdownRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeStoreServiceServer).StorageBreakdown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeStoreService_StorageBreakdown_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeStoreServiceServer).StorageBreakdown(ctx, req.(*StorageBreakdownRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TreeStoreService_ServiceDesc is the grpc.ServiceDesc for TreeStoreService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TreeStoreService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "treestore.TreeStoreService",
+	HandlerType: (*TreeStoreServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StoreDocument",
+			Handler:    _TreeStoreService_StoreDocument_Handler,
+		},
+		{
+			MethodName: "GetDocument",
+			Handler:    _TreeStoreService_GetDocument_Handler,
+		},
+		{
+			MethodName: "DeleteDocument",
+			Handler:    _TreeStoreService_DeleteDocument_Handler,
+		},
+		{
+			MethodName: "CloneDocument",
+			Handler:    _TreeStoreService_CloneDocument_Handler,
+		},
+		{
+			MethodName: "RecomputeSectionPaths",
+			Handler:    _TreeStoreService_RecomputeSectionPaths_Handler,
+		},
+		{
+			MethodName: "ValidateDocument",
+			Handler:    _TreeStoreService_ValidateDocument_Handler,
+		},
+		{
+			MethodName: "FindDuplicateNodes",
+			Handler:    _TreeStoreService_FindDuplicateNodes_Handler,
+		},
+		{
+			MethodName: "GetNode",
+			Handler:    _TreeStoreService_GetNode_Handler,
+		},
+		{
+			MethodName: "UpdateNode",
+			Handler:    _TreeStoreService_UpdateNode_Handler,
+		},
+		{
+			MethodName: "GetChildren",
+			Handler:    _TreeStoreService_GetChildren_Handler,
+		},
+		{
+			MethodName: "GetSubtree",
+			Handler:    _TreeStoreService_GetSubtree_Handler,
+		},
+		{
+			MethodName: "GetSubtreeWithinBudget",
+			Handler:    _TreeStoreService_GetSubtreeWithinBudget_Handler,
+		},
+		{
+			MethodName: "GetAncestorPath",
+			Handler:    _TreeStoreService_GetAncestorPath_Handler,
+		},
+		{
+			MethodName: "BatchGetAncestorPaths",
+			Handler:    _TreeStoreService_BatchGetAncestorPaths_Handler,
+		},
+		{
+			MethodName: "GetSiblings",
+			Handler:    _TreeStoreService_GetSiblings_Handler,
+		},
+		{
+			MethodName: "GetNextSection",
+			Handler:    _TreeStoreService_GetNextSection_Handler,
+		},
+		{
+			MethodName: "GetPreviousSection",
+			Handler:    _TreeStoreService_GetPreviousSection_Handler,
+		},
+		{
+			MethodName: "GetContextWindow",
+			Handler:    _TreeStoreService_GetContextWindow_Handler,
+		},
+		{
+			MethodName: "ExportGraph",
+			Handler:    _TreeStoreService_ExportGraph_Handler,
+		},
+		{
+			MethodName: "SearchByKeyword",
+			Handler:    _TreeStoreService_SearchByKeyword_Handler,
+		},
+		{
+			MethodName: "GetNodesByPage",
+			Handler:    _TreeStoreService_GetNodesByPage_Handler,
+		},
+		{
+			MethodName: "GlobalSearch",
+			Handler:    _TreeStoreService_GlobalSearch_Handler,
+		},
+		{
+			MethodName: "JoinNodes",
+			Handler:    _TreeStoreService_JoinNodes_Handler,
+		},
+		{
+			MethodName: "Retrieve",
+			Handler:    _TreeStoreService_Retrieve_Handler,
+		},
+		{
+			MethodName: "GetVersionAsOf",
+			Handler:    _TreeStoreService_GetVersionAsOf_Handler,
+		},
+		{
+			MethodName: "BatchGetVersionsAsOf",
+			Handler:    _TreeStoreService_BatchGetVersionsAsOf_Handler,
+		},
+		{
+			MethodName: "ListVersions",
+			Handler:    _TreeStoreService_ListVersions_Handler,
+		},
+		{
+			MethodName: "DeleteVersion",
+			Handler:    _TreeStoreService_DeleteVersion_Handler,
+		},
+		{
+			MethodName: "PruneVersions",
+			Handler:    _TreeStoreService_PruneVersions_Handler,
+		},
+		{
+			MethodName: "TagVersion",
+			Handler:    _TreeStoreService_TagVersion_Handler,
+		},
+		{
+			MethodName: "UntagVersion",
+			Handler:    _TreeStoreService_UntagVersion_Handler,
+		},
+		{
+			MethodName: "StoreToolResult",
+			Handler:    _TreeStoreService_StoreToolResult_Handler,
+		},
+		{
+			MethodName: "GetToolResults",
+			Handler:    _TreeStoreService_GetToolResults_Handler,
+		},
+		{
+			MethodName: "StoreTrajectory",
+			Handler:    _TreeStoreService_StoreTrajectory_Handler,
+		},
+		{
+			MethodName: "GetTrajectories",
+			Handler:    _TreeStoreService_GetTrajectories_Handler,
+		},
+		{
+			MethodName: "ReplayTrajectory",
+			Handler:    _TreeStoreService_ReplayTrajectory_Handler,
+		},
+		{
+			MethodName: "StoreCrossReference",
+			Handler:    _TreeStoreService_StoreCrossReference_Handler,
+		},
+		{
+			MethodName: "GetCrossReferences",
+			Handler:    _TreeStoreService_GetCrossReferences_Handler,
+		},
+		{
+			MethodName: "StoreContradiction",
+			Handler:    _TreeStoreService_StoreContradiction_Handler,
+		},
+		{
+			MethodName: "BatchSetMetadata",
+			Handler:    _TreeStoreService_BatchSetMetadata_Handler,
+		},
+		{
+			MethodName: "PutCollection",
+			Handler:    _TreeStoreService_PutCollection_Handler,
+		},
+		{
+			MethodName: "GetCollection",
+			Handler:    _TreeStoreService_GetCollection_Handler,
+		},
+		{
+			MethodName: "ListCollections",
+			Handler:    _TreeStoreService_ListCollections_Handler,
+		},
+		{
+			MethodName: "UpdateCollectionMembers",
+			Handler:    _TreeStoreService_UpdateCollectionMembers_Handler,
+		},
+		{
+			MethodName: "DeleteCollection",
+			Handler:    _TreeStoreService_DeleteCollection_Handler,
+		},
+		{
+			MethodName: "StorePrompt",
+			Handler:    _TreeStoreService_StorePrompt_Handler,
+		},
+		{
+			MethodName: "GetPrompt",
+			Handler:    _TreeStoreService_GetPrompt_Handler,
+		},
+		{
+			MethodName: "RecordPromptUsage",
+			Handler:    _TreeStoreService_RecordPromptUsage_Handler,
+		},
+		{
+			MethodName: "StorePromptExperiment",
+			Handler:    _TreeStoreService_StorePromptExperiment_Handler,
+		},
+		{
+			MethodName: "GetPromptExperiment",
+			Handler:    _TreeStoreService_GetPromptExperiment_Handler,
+		},
+		{
+			MethodName: "AssignPromptVariant",
+			Handler:    _TreeStoreService_AssignPromptVariant_Handler,
+		},
+		{
+			MethodName: "LabelPromptUsage",
+			Handler:    _TreeStoreService_LabelPromptUsage_Handler,
+		},
+		{
+			MethodName: "ComparePromptVariants",
+			Handler:    _TreeStoreService_ComparePromptVariants_Handler,
+		},
+		{
+			MethodName: "GetMessagesPage",
+			Handler:    _TreeStoreService_GetMessagesPage_Handler,
+		},
+		{
+			MethodName: "GetRecentMessages",
+			Handler:    _TreeStoreService_GetRecentMessages_Handler,
+		},
+		{
+			MethodName: "SearchConversations",
+			Handler:    _TreeStoreService_SearchConversations_Handler,
+		},
+		{
+			MethodName: "ExportConversation",
+			Handler:    _TreeStoreService_ExportConversation_Handler,
+		},
+		{
+			MethodName: "ListConversationsByUser",
+			Handler:    _TreeStoreService_ListConversationsByUser_Handler,
+		},
+		{
+			MethodName: "GetUserConversationUsage",
+			Handler:    _TreeStoreService_GetUserConversationUsage_Handler,
+		},
+		{
+			MethodName: "SetUserConversationQuota",
+			Handler:    _TreeStoreService_SetUserConversationQuota_Handler,
+		},
+		{
+			MethodName: "SaveQuery",
+			Handler:    _TreeStoreService_SaveQuery_Handler,
+		},
+		{
+			MethodName: "ExecuteSavedQuery",
+			Handler:    _TreeStoreService_ExecuteSavedQuery_Handler,
+		},
+		{
+			MethodName: "RefreshSavedQuery",
+			Handler:    _TreeStoreService_RefreshSavedQuery_Handler,
+		},
+		{
+			MethodName: "ListSavedQueries",
+			Handler:    _TreeStoreService_ListSavedQueries_Handler,
+		},
+		{
+			MethodName: "DeleteSavedQuery",
+			Handler:    _TreeStoreService_DeleteSavedQuery_Handler,
+		},
+		{
+			MethodName: "QueryAuditLog",
+			Handler:    _TreeStoreService_QueryAuditLog_Handler,
+		},
+		{
+			MethodName: "ListJobRuns",
+			Handler:    _TreeStoreService_ListJobRuns_Handler,
+		},
+		{
+			MethodName: "TriggerJob",
+			Handler:    _TreeStoreService_TriggerJob_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _TreeStoreService_Health_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _TreeStoreService_Stats_Handler,
+		},
+		{
+			MethodName: "StorageBreakdown",
+			Handler:    _TreeStoreService_StorageBreakdown_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RefreshSummaries",
+			Handler:       _TreeStoreService_RefreshSummaries_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetReadingOrder",
+			Handler:       _TreeStoreService_GetReadingOrder_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamQuery",
+			Handler:       _TreeStoreService_StreamQuery_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchChanges",
+			Handler:       _TreeStoreService_WatchChanges_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamWAL",
+			Handler:       _TreeStoreService_StreamWAL_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/treestore.proto",
+}
+
+const (
+	TreeStoreAdmin_Checkpoint_FullMethodName  = "/treestore.TreeStoreAdmin/Checkpoint"
+	TreeStoreAdmin_Compact_FullMethodName     = "/treestore.TreeStoreAdmin/Compact"
+	TreeStoreAdmin_Reindex_FullMethodName     = "/treestore.TreeStoreAdmin/Reindex"
+	TreeStoreAdmin_Flush_FullMethodName       = "/treestore.TreeStoreAdmin/Flush"
+	TreeStoreAdmin_Backup_FullMethodName      = "/treestore.TreeStoreAdmin/Backup"
+	TreeStoreAdmin_SetLogLevel_FullMethodName = "/treestore.TreeStoreAdmin/SetLogLevel"
+	TreeStoreAdmin_DumpState_FullMethodName   = "/treestore.TreeStoreAdmin/DumpState"
+	TreeStoreAdmin_TailLogs_FullMethodName    = "/treestore.TreeStoreAdmin/TailLogs"
+)
+
+// TreeStoreAdminClient is the client API for TreeStoreAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TreeStoreAdmin runs operational commands on a live server. It is served on
+// the admin port, apart from client traffic.
+type TreeStoreAdminClient interface {
+	// ========== Storage Maintenance (4 methods) ==========
+	Checkpoint(ctx context.Context, in *CheckpointRequest, opts ...grpc.CallOption) (*CheckpointResponse, error)
+	Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error)
+	Reindex(ctx context.Context, in *ReindexRequest, opts ...grpc.CallOption) (*ReindexResponse, error)
+	Flush(ctx context.Context, in *FlushRequest, opts ...grpc.CallOption) (*FlushResponse, error)
+	// ========== Backup (1 method) ==========
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*BackupResponse, error)
+	// ========== Diagnostics (3 methods) ==========
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error)
+	DumpState(ctx context.Context, in *DumpStateRequest, opts ...grpc.CallOption) (*DumpStateResponse, error)
+	TailLogs(ctx context.Context, in *TailLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEvent], error)
+}
+
+type treeStoreAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTreeStoreAdminClient(cc grpc.ClientConnInterface) TreeStoreAdminClient {
+	return &treeStoreAdminClient{cc}
+}
+
+func (c *treeStoreAdminClient) Checkpoint(ctx context.Context, in *CheckpointRequest, opts ...grpc.CallOption) (*CheckpointResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckpointResponse)
+	err := c.cc.Invoke(ctx, TreeStoreAdmin_Checkpoint_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreAdminClient) Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompactResponse)
+	err := c.cc.Invoke(ctx, TreeStoreAdmin_Compact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreAdminClient) Reindex(ctx context.Context, in *ReindexRequest, opts ...grpc.CallOption) (*ReindexResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReindexResponse)
+	err := c.cc.Invoke(ctx, TreeStoreAdmin_Reindex_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreAdminClient) Flush(ctx context.Context, in *FlushRequest, opts ...grpc.CallOption) (*FlushResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FlushResponse)
+	err := c.cc.Invoke(ctx, TreeStoreAdmin_Flush_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreAdminClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*BackupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BackupResponse)
+	err := c.cc.Invoke(ctx, TreeStoreAdmin_Backup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeStoreAdminClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLogLevelResponse)
+	err := c.cc.Invoke(ctx, TreeStoreAdmin_SetLogLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
 	}
-	return interceptor(ctx, in, info, handler)
+	return out, nil
 }
 
-func _TreeStoreService_StoreTrajectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StoreTrajectoryRequest)
-	if err := dec(in); err != nil {
+func (c *treeStoreAdminClient) DumpState(ctx context.Context, in *DumpStateRequest, opts ...grpc.CallOption) (*DumpStateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DumpStateResponse)
+	err := c.cc.Invoke(ctx, TreeStoreAdmin_DumpState_FullMethodName, in, out, cOpts...)
+	if err != nil {
 		return nil, err
 	}
-	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).StoreTrajectory(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: TreeStoreService_StoreTrajectory_FullMethodName,
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).StoreTrajectory(ctx, req.(*StoreTrajectoryRequest))
-	}
-	return interceptor(ctx, in, info, handler)
+	return out, nil
 }
 
-func _TreeStoreService_GetTrajectories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetTrajectoriesRequest)
-	if err := dec(in); err != nil {
+func (c *treeStoreAdminClient) TailLogs(ctx context.Context, in *TailLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TreeStoreAdmin_ServiceDesc.Streams[0], TreeStoreAdmin_TailLogs_FullMethodName, cOpts...)
+	if err != nil {
 		return nil, err
 	}
-	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).GetTrajectories(ctx, in)
+	x := &grpc.GenericClientStream[TailLogsRequest, LogEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: TreeStoreService_GetTrajectories_FullMethodName,
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).GetTrajectories(ctx, req.(*GetTrajectoriesRequest))
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TreeStoreAdmin_TailLogsClient = grpc.ServerStreamingClient[LogEvent]
+
+// TreeStoreAdminServer is the server API for TreeStoreAdmin service.
+// All implementations must embed UnimplementedTreeStoreAdminServer
+// for forward compatibility.
+//
+// TreeStoreAdmin runs operational commands on a live server. It is served on
+// the admin port, apart from client traffic.
+type TreeStoreAdminServer interface {
+	// ========== Storage Maintenance (4 methods) ==========
+	Checkpoint(context.Context, *CheckpointRequest) (*CheckpointResponse, error)
+	Compact(context.Context, *CompactRequest) (*CompactResponse, error)
+	Reindex(context.Context, *ReindexRequest) (*ReindexResponse, error)
+	Flush(context.Context, *FlushRequest) (*FlushResponse, error)
+	// ========== Backup (1 method) ==========
+	Backup(context.Context, *BackupRequest) (*BackupResponse, error)
+	// ========== Diagnostics (3 methods) ==========
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error)
+	DumpState(context.Context, *DumpStateRequest) (*DumpStateResponse, error)
+	TailLogs(*TailLogsRequest, grpc.ServerStreamingServer[LogEvent]) error
+	mustEmbedUnimplementedTreeStoreAdminServer()
+}
+
+// UnimplementedTreeStoreAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTreeStoreAdminServer struct{}
+
+func (UnimplementedTreeStoreAdminServer) Checkpoint(context.Context, *CheckpointRequest) (*CheckpointResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Checkpoint not implemented")
+}
+func (UnimplementedTreeStoreAdminServer) Compact(context.Context, *CompactRequest) (*CompactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Compact not implemented")
+}
+func (UnimplementedTreeStoreAdminServer) Reindex(context.Context, *ReindexRequest) (*ReindexResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reindex not implemented")
+}
+func (UnimplementedTreeStoreAdminServer) Flush(context.Context, *FlushRequest) (*FlushResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Flush not implemented")
+}
+func (UnimplementedTreeStoreAdminServer) Backup(context.Context, *BackupRequest) (*BackupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Backup not implemented")
+}
+func (UnimplementedTreeStoreAdminServer) SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedTreeStoreAdminServer) DumpState(context.Context, *DumpStateRequest) (*DumpStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DumpState not implemented")
+}
+func (UnimplementedTreeStoreAdminServer) TailLogs(*TailLogsRequest, grpc.ServerStreamingServer[LogEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method TailLogs not implemented")
+}
+func (UnimplementedTreeStoreAdminServer) mustEmbedUnimplementedTreeStoreAdminServer() {}
+func (UnimplementedTreeStoreAdminServer) testEmbeddedByValue()                        {}
+
+// UnsafeTreeStoreAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TreeStoreAdminServer will
+// result in compilation errors.
+type UnsafeTreeStoreAdminServer interface {
+	mustEmbedUnimplementedTreeStoreAdminServer()
+}
+
+func RegisterTreeStoreAdminServer(s grpc.ServiceRegistrar, srv TreeStoreAdminServer) {
+	// If the following call pancis, it indicates UnimplementedTreeStoreAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
 	}
-	return interceptor(ctx, in, info, handler)
+	s.RegisterService(&TreeStoreAdmin_ServiceDesc, srv)
 }
 
-func _TreeStoreService_StoreCrossReference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StoreCrossReferenceRequest)
+func _TreeStoreAdmin_Checkpoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckpointRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).StoreCrossReference(ctx, in)
+		return srv.(TreeStoreAdminServer).Checkpoint(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TreeStoreService_StoreCrossReference_FullMethodName,
+		FullMethod: TreeStoreAdmin_Checkpoint_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).StoreCrossReference(ctx, req.(*StoreCrossReferenceRequest))
+		return srv.(TreeStoreAdminServer).Checkpoint(ctx, req.(*CheckpointRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TreeStoreService_GetCrossReferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetCrossReferencesRequest)
+func _TreeStoreAdmin_Compact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).GetCrossReferences(ctx, in)
+		return srv.(TreeStoreAdminServer).Compact(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TreeStoreService_GetCrossReferences_FullMethodName,
+		FullMethod: TreeStoreAdmin_Compact_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).GetCrossReferences(ctx, req.(*GetCrossReferencesRequest))
+		return srv.(TreeStoreAdminServer).Compact(ctx, req.(*CompactRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TreeStoreService_StoreContradiction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StoreContradictionRequest)
+func _TreeStoreAdmin_Reindex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReindexRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).StoreContradiction(ctx, in)
+		return srv.(TreeStoreAdminServer).Reindex(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TreeStoreService_StoreContradiction_FullMethodName,
+		FullMethod: TreeStoreAdmin_Reindex_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).StoreContradiction(ctx, req.(*StoreContradictionRequest))
+		return srv.(TreeStoreAdminServer).Reindex(ctx, req.(*ReindexRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TreeStoreService_StorePrompt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StorePromptRequest)
+func _TreeStoreAdmin_Flush_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FlushRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).StorePrompt(ctx, in)
+		return srv.(TreeStoreAdminServer).Flush(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TreeStoreService_StorePrompt_FullMethodName,
+		FullMethod: TreeStoreAdmin_Flush_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).StorePrompt(ctx, req.(*StorePromptRequest))
+		return srv.(TreeStoreAdminServer).Flush(ctx, req.(*FlushRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TreeStoreService_GetPrompt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetPromptRequest)
+func _TreeStoreAdmin_Backup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).GetPrompt(ctx, in)
+		return srv.(TreeStoreAdminServer).Backup(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TreeStoreService_GetPrompt_FullMethodName,
+		FullMethod: TreeStoreAdmin_Backup_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).GetPrompt(ctx, req.(*GetPromptRequest))
+		return srv.(TreeStoreAdminServer).Backup(ctx, req.(*BackupRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TreeStoreService_RecordPromptUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RecordPromptUsageRequest)
+func _TreeStoreAdmin_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).RecordPromptUsage(ctx, in)
+		return srv.(TreeStoreAdminServer).SetLogLevel(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TreeStoreService_RecordPromptUsage_FullMethodName,
+		FullMethod: TreeStoreAdmin_SetLogLevel_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).RecordPromptUsage(ctx, req.(*RecordPromptUsageRequest))
+		return srv.(TreeStoreAdminServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TreeStoreService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(HealthRequest)
+func _TreeStoreAdmin_DumpState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DumpStateRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).Health(ctx, in)
+		return srv.(TreeStoreAdminServer).DumpState(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TreeStoreService_Health_FullMethodName,
+		FullMethod: TreeStoreAdmin_DumpState_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).Health(ctx, req.(*HealthRequest))
+		return srv.(TreeStoreAdminServer).DumpState(ctx, req.(*DumpStateRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TreeStoreService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StatsRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(TreeStoreServiceServer).Stats(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: TreeStoreService_Stats_FullMethodName,
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TreeStoreServiceServer).Stats(ctx, req.(*StatsRequest))
+func _TreeStoreAdmin_TailLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
 	}
-	return interceptor(ctx, in, info, handler)
+	return srv.(TreeStoreAdminServer).TailLogs(m, &grpc.GenericServerStream[TailLogsRequest, LogEvent]{ServerStream: stream})
 }
 
-// TreeStoreService_ServiceDesc is the grpc.ServiceDesc for TreeStoreService service.
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TreeStoreAdmin_TailLogsServer = grpc.ServerStreamingServer[LogEvent]
+
+// TreeStoreAdmin_ServiceDesc is the grpc.ServiceDesc for TreeStoreAdmin service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var TreeStoreService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "treestore.TreeStoreService",
-	HandlerType: (*TreeStoreServiceServer)(nil),
+var TreeStoreAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "treestore.TreeStoreAdmin",
+	HandlerType: (*TreeStoreAdminServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "StoreDocument",
-			Handler:    _TreeStoreService_StoreDocument_Handler,
-		},
-		{
-			MethodName: "GetDocument",
-			Handler:    _TreeStoreService_GetDocument_Handler,
-		},
-		{
-			MethodName: "DeleteDocument",
-			Handler:    _TreeStoreService_DeleteDocument_Handler,
-		},
-		{
-			MethodName: "GetNode",
-			Handler:    _TreeStoreService_GetNode_Handler,
-		},
-		{
-			MethodName: "GetChildren",
-			Handler:    _TreeStoreService_GetChildren_Handler,
-		},
-		{
-			MethodName: "GetSubtree",
-			Handler:    _TreeStoreService_GetSubtree_Handler,
-		},
-		{
-			MethodName: "GetAncestorPath",
-			Handler:    _TreeStoreService_GetAncestorPath_Handler,
-		},
-		{
-			MethodName: "SearchByKeyword",
-			Handler:    _TreeStoreService_SearchByKeyword_Handler,
-		},
-		{
-			MethodName: "GetNodesByPage",
-			Handler:    _TreeStoreService_GetNodesByPage_Handler,
-		},
-		{
-			MethodName: "GetVersionAsOf",
-			Handler:    _TreeStoreService_GetVersionAsOf_Handler,
-		},
-		{
-			MethodName: "ListVersions",
-			Handler:    _TreeStoreService_ListVersions_Handler,
-		},
-		{
-			MethodName: "StoreToolResult",
-			Handler:    _TreeStoreService_StoreToolResult_Handler,
-		},
-		{
-			MethodName: "GetToolResults",
-			Handler:    _TreeStoreService_GetToolResults_Handler,
-		},
-		{
-			MethodName: "StoreTrajectory",
-			Handler:    _TreeStoreService_StoreTrajectory_Handler,
-		},
-		{
-			MethodName: "GetTrajectories",
-			Handler:    _TreeStoreService_GetTrajectories_Handler,
+			MethodName: "Checkpoint",
+			Handler:    _TreeStoreAdmin_Checkpoint_Handler,
 		},
 		{
-			MethodName: "StoreCrossReference",
-			Handler:    _TreeStoreService_StoreCrossReference_Handler,
+			MethodName: "Compact",
+			Handler:    _TreeStoreAdmin_Compact_Handler,
 		},
 		{
-			MethodName: "GetCrossReferences",
-			Handler:    _TreeStoreService_GetCrossReferences_Handler,
+			MethodName: "Reindex",
+			Handler:    _TreeStoreAdmin_Reindex_Handler,
 		},
 		{
-			MethodName: "StoreContradiction",
-			Handler:    _TreeStoreService_StoreContradiction_Handler,
+			MethodName: "Flush",
+			Handler:    _TreeStoreAdmin_Flush_Handler,
 		},
 		{
-			MethodName: "StorePrompt",
-			Handler:    _TreeStoreService_StorePrompt_Handler,
+			MethodName: "Backup",
+			Handler:    _TreeStoreAdmin_Backup_Handler,
 		},
 		{
-			MethodName: "GetPrompt",
-			Handler:    _TreeStoreService_GetPrompt_Handler,
+			MethodName: "SetLogLevel",
+			Handler:    _TreeStoreAdmin_SetLogLevel_Handler,
 		},
 		{
-			MethodName: "RecordPromptUsage",
-			Handler:    _TreeStoreService_RecordPromptUsage_Handler,
+			MethodName: "DumpState",
+			Handler:    _TreeStoreAdmin_DumpState_Handler,
 		},
+	},
+	Streams: []grpc.StreamDesc{
 		{
-			MethodName: "Health",
-			Handler:    _TreeStoreService_Health_Handler,
+			StreamName:    "TailLogs",
+			Handler:       _TreeStoreAdmin_TailLogs_Handler,
+			ServerStreams: true,
 		},
+	},
+	Metadata: "proto/treestore.proto",
+}
+
+const (
+	Summarizer_Summarize_FullMethodName = "/treestore.Summarizer/Summarize"
+)
+
+// SummarizerClient is the client API for Summarizer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Summarizer is implemented by external summarization services that
+// RefreshSummaries calls with a grpc:// summarizer target
+type SummarizerClient interface {
+	Summarize(ctx context.Context, in *SummarizeRequest, opts ...grpc.CallOption) (*SummarizeResponse, error)
+}
+
+type summarizerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSummarizerClient(cc grpc.ClientConnInterface) SummarizerClient {
+	return &summarizerClient{cc}
+}
+
+func (c *summarizerClient) Summarize(ctx context.Context, in *SummarizeRequest, opts ...grpc.CallOption) (*SummarizeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SummarizeResponse)
+	err := c.cc.Invoke(ctx, Summarizer_Summarize_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SummarizerServer is the server API for Summarizer service.
+// All implementations must embed UnimplementedSummarizerServer
+// for forward compatibility.
+//
+// Summarizer is implemented by external summarization services that
+// RefreshSummaries calls with a grpc:// summarizer target
+type SummarizerServer interface {
+	Summarize(context.Context, *SummarizeRequest) (*SummarizeResponse, error)
+	mustEmbedUnimplementedSummarizerServer()
+}
+
+// UnimplementedSummarizerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSummarizerServer struct{}
+
+func (UnimplementedSummarizerServer) Summarize(context.Context, *SummarizeRequest) (*SummarizeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Summarize not implemented")
+}
+func (UnimplementedSummarizerServer) mustEmbedUnimplementedSummarizerServer() {}
+func (UnimplementedSummarizerServer) testEmbeddedByValue()                    {}
+
+// UnsafeSummarizerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SummarizerServer will
+// result in compilation errors.
+type UnsafeSummarizerServer interface {
+	mustEmbedUnimplementedSummarizerServer()
+}
+
+func RegisterSummarizerServer(s grpc.ServiceRegistrar, srv SummarizerServer) {
+	// If the following call pancis, it indicates UnimplementedSummarizerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Summarizer_ServiceDesc, srv)
+}
+
+func _Summarizer_Summarize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SummarizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SummarizerServer).Summarize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Summarizer_Summarize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SummarizerServer).Summarize(ctx, req.(*SummarizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Summarizer_ServiceDesc is the grpc.ServiceDesc for Summarizer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Summarizer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "treestore.Summarizer",
+	HandlerType: (*SummarizerServer)(nil),
+	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Stats",
-			Handler:    _TreeStoreService_Stats_Handler,
+			MethodName: "Summarize",
+			Handler:    _Summarizer_Summarize_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},